@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/bojand/ghz/printer"
 	"github.com/bojand/ghz/runner"
@@ -28,29 +30,51 @@ var (
 	dkvSvcHost   string
 	benchmark    string
 	protoDir     string
+	targetQPS    uint
+	histFile     string
+	skipLoad     bool
+	warmUp       time.Duration
+	slaveSvcHost string
+	slaveSvcPort uint
+	pollInterval time.Duration
+	catchUpTime  time.Duration
+	lagFile      string
 )
 
 func init() {
-	flag.StringVar(&benchmark, "name", "", "Benchmark to run [Insert|Update|Get|GetAll]")
+	flag.StringVar(&benchmark, "name", "", "Benchmark to run [Insert|Update|Put|Get|GetAll|Workload|Lag]")
 	flag.StringVar(&dkvSvcHost, "dkvSvcHost", "localhost", "DKV service host")
 	flag.UintVar(&dkvSvcPort, "dkvSvcPort", 8080, "DKV service port")
 	flag.UintVar(&parallelism, "parallelism", 2, "Number of requests to run concurrently")
 	flag.UintVar(&totalNumKeys, "totalNumKeys", 1000, "Total number of keys")
+	flag.UintVar(&targetQPS, "targetQPS", 0, "Rate at which the requests of the Workload and Lag benchmarks are made, 0 for as fast as possible")
+	flag.StringVar(&histFile, "histogramFile", "", "File to dump the latency histograms of the Workload benchmark to, as JSON if named *.json and as CSV otherwise")
+	flag.BoolVar(&skipLoad, "skipLoad", false, "Skip pre-loading the key space before the Get, GetAll and Workload benchmarks, after verifying that it is loaded")
+	flag.DurationVar(&warmUp, "warmUpDuration", 0, "Duration for which requests are made before those measured")
+	flag.StringVar(&slaveSvcHost, "slaveSvcHost", "localhost", "DKV slave service host, whose replication lag is measured by the Lag benchmark")
+	flag.UintVar(&slaveSvcPort, "slaveSvcPort", 8081, "DKV slave service port")
+	flag.DurationVar(&pollInterval, "pollInterval", 100*time.Millisecond, "Interval at which the Lag benchmark polls the change numbers of the master and slave services")
+	flag.DurationVar(&catchUpTime, "catchUpTimeout", time.Minute, "Time for which the Lag benchmark waits for the slave service to catch up after the writes")
+	flag.StringVar(&lagFile, "lagFile", "", "CSV file to write the replication lag polled by the Lag benchmark to")
 	flag.StringVar(&protoDir, "protoDir", "./pkg/serverpb", "Folder path that contains the DKV's api.proto file")
 }
 
 func launchBenchmark(bm bench.Benchmark) {
-	report, err := runner.Run(
-		bm.APIName(),
-		fmt.Sprintf("%s:%d", dkvSvcHost, dkvSvcPort),
+	opts := []runner.Option{
 		runner.WithProtoFile(protoFile, []string{protoDir}),
 		runner.WithData(bm.CreateRequests(totalNumKeys)),
 		runner.WithInsecure(true),
 		runner.WithCPUs(8),
 		runner.WithConcurrency(parallelism),
 		runner.WithConnections(1),
-		runner.WithTotalRequests(totalNumKeys),
-	)
+	}
+	if warmUp > 0 {
+		// Report of the warm-up run is discarded
+		if _, err := runner.Run(bm.APIName(), dkvSvcAddr(), append(opts, runner.WithRunDuration(warmUp))...); err != nil {
+			panic(err)
+		}
+	}
+	report, err := runner.Run(bm.APIName(), dkvSvcAddr(), append(opts, runner.WithTotalRequests(totalNumKeys))...)
 
 	if err != nil {
 		panic(err)
@@ -65,6 +89,74 @@ func launchBenchmark(bm bench.Benchmark) {
 	printer.Print("summary")
 }
 
+func dkvSvcAddr() string {
+	return fmt.Sprintf("%s:%d", dkvSvcHost, dkvSvcPort)
+}
+
+// preLoad writes the key space read by the benchmarks, unless skipped.
+func preLoad() {
+	client, err := ctl.NewInSecureDKVClient(dkvSvcAddr())
+	if err != nil {
+		panic(err)
+	}
+	defer client.Close()
+	start := time.Now()
+	if err = bench.DefaultLoadKeySpace(context.Background(), client, parallelism, skipLoad); err != nil {
+		panic(err)
+	}
+	if !skipLoad {
+		fmt.Printf("Key space loaded in %v\n\n", time.Since(start))
+	}
+}
+
+// launchWorkload runs the mix of reads and writes given by the flags,
+// with as many requests in flight as the parallelism.
+func launchWorkload(wl *bench.Workload) {
+	client, err := ctl.NewInSecureDKVClient(dkvSvcAddr())
+	if err != nil {
+		panic(err)
+	}
+	defer client.Close()
+	report := bench.RunWorkload(context.Background(), client, wl.CreateRequests(totalNumKeys), parallelism, targetQPS, warmUp)
+	fmt.Println(wl)
+	fmt.Print(report)
+	if histFile != "" {
+		if err := report.WriteHistograms(histFile); err != nil {
+			panic(err)
+		}
+		fmt.Printf("Latency histograms written to %s\n", histFile)
+	}
+}
+
+// launchLagBenchmark writes onto the DKV service at the target QPS,
+// while polling the replication lag of the DKV slave service.
+func launchLagBenchmark(lb *bench.LagBenchmark) {
+	master, err := ctl.NewInSecureDKVClient(dkvSvcAddr())
+	if err != nil {
+		panic(err)
+	}
+	defer master.Close()
+	slave, err := ctl.NewInSecureDKVClient(fmt.Sprintf("%s:%d", slaveSvcHost, slaveSvcPort))
+	if err != nil {
+		panic(err)
+	}
+	defer slave.Close()
+	report := lb.Run(context.Background(), master, slave)
+	fmt.Println(lb)
+	fmt.Print(report)
+	if lagFile != "" {
+		f, err := os.Create(lagFile)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		if err = report.WriteCSV(f); err != nil {
+			panic(err)
+		}
+		fmt.Printf("Replication lag written to %s\n", lagFile)
+	}
+}
+
 func main() {
 	flag.Parse()
 	printFlags()
@@ -74,10 +166,19 @@ func main() {
 		launchBenchmark(bench.DefaultPutNewKeysBenchmark())
 	case "update":
 		launchBenchmark(bench.DefaultPutModifyKeysBenchmark())
+	case "put":
+		launchBenchmark(bench.DefaultPutBenchmark())
 	case "get":
+		preLoad()
 		launchBenchmark(bench.DefaultGetHotKeysBenchmark())
 	case "getall":
+		preLoad()
 		launchBenchmark(bench.DefaultMultiGetHotKeysBenchmark())
+	case "workload":
+		preLoad()
+		launchWorkload(bench.DefaultWorkload())
+	case "lag":
+		launchLagBenchmark(bench.DefaultLagBenchmark(totalNumKeys, targetQPS, parallelism, pollInterval, catchUpTime))
 	default:
 		panic(fmt.Sprintf("Unknown or invalid benchmark name given: '%s'", benchmark))
 	}
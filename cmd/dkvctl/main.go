@@ -1,12 +1,21 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
 )
 
 type cmd struct {
@@ -20,34 +29,277 @@ type cmd struct {
 var cmds = []*cmd{
 	{"set", "<key> <value>", "Set a key value pair", (*cmd).set, ""},
 	{"get", "<key>", "Get value for the given key", (*cmd).get, ""},
-	{"backup", "<path>", "Backs up data to the given path", (*cmd).backup, ""},
-	{"restore", "<path>", "Restores data from the given path", (*cmd).restore, ""},
+	{"mget", "<key>... | -", "Get values for the given keys, or for the keys read from stdin one per line", (*cmd).mget, ""},
+	{"del", "<key>... | -", "Delete the given keys, or the keys read from stdin one per line", (*cmd).del, ""},
+	{"undelete", "<key>...", "Restore the given keys deleted within the tombstone retention of the server", (*cmd).undelete, ""},
+	{"incr", "<key> <delta>", "Increment the numeric value of the given key by the given delta", (*cmd).incr, ""},
+	{"iter", "<prefix> [<startKey>]", "Iterate keys matching the given prefix", (*cmd).iter, ""},
+	{"backup", "<path|uri>", "Backs up data to the given path or object storage URI", (*cmd).backup, ""},
+	{"restore", "<path|uri>", "Restores data from the given path or object storage URI", (*cmd).restore, ""},
+	{"verifyRestore", "<path|uri>", "Verifies the backup at the given path or object storage URI without restoring it", (*cmd).verifyRestore, ""},
+	{"backupTo", "<file>", "Streams a backup of the data into the given local file", (*cmd).backupTo, ""},
+	{"restoreFrom", "<file>", "Restores data from a backup streamed into the given local file", (*cmd).restoreFrom, ""},
+	{"bulkLoad", "<file> | -", "Bulk load the key value pairs read from the given local file or stdin, one space separated pair per line, onto a DKV node started with -dbBulkLoad", (*cmd).bulkLoad, ""},
+	{"clusterBackup", "<path|uri>", "Backs up every DKV node of the cluster as of a common change number to the given path or object storage URI", (*cmd).clusterBackup, ""},
+	{"clusterRestore", "<path|uri>", "Restores every DKV node of a fresh cluster from the given path or object storage URI", (*cmd).clusterRestore, ""},
 	{"addNode", "<nodeId> <nodeUrl>", "Add a DKV node to cluster", (*cmd).addNode, ""},
 	{"removeNode", "<nodeId", "Remove a DKV node from cluster", (*cmd).removeNode, ""},
+	{"decommissionNode", "<nodeId>", "Decommission a DKV node from cluster once its leadership and changes are taken over, see -wait", (*cmd).decommissionNode, ""},
+	{"nodes", "", "List the DKV nodes of the cluster along with their roles", (*cmd).nodes, ""},
+	{"pauseRepl", "<autoResumeAfterSecs>", "Pause replication on a DKV slave node, 0 to pause indefinitely", (*cmd).pauseRepl, ""},
+	{"resumeRepl", "", "Resume replication on a DKV slave node", (*cmd).resumeRepl, ""},
+	{"promote", "", "Promote a DKV slave node to master", (*cmd).promote, ""},
+	{"replStatus", "", "Get the status of replication on a DKV slave node", (*cmd).replStatus, ""},
+	{"verifyRange", "<fromChangeNum> <toChangeNum>", "Compute the checksum of the given range of changes on a DKV node, for comparing it across the nodes, see -timeout", (*cmd).verifyRange, ""},
+	{"replicas", "", "List the DKV slave nodes replicating from a DKV master node, along with their replication lag", (*cmd).replicas, ""},
+	{"changeLogInfo", "", "Get the range of changes retained by a DKV master node, along with the progress of its replicas", (*cmd).changeLogInfo, ""},
+	{"truncateChangeLog", "<beforeChangeNum> | policy", "Discard the changes retained by a DKV master node before the given change number, or as per its retention policy", (*cmd).truncateChangeLog, ""},
+	{"compareReplicas", "<slaveAddr>", "Compare the keyspace of a DKV master node with that of the given slave node, listing the keys that differ, see -timeout", (*cmd).compareReplicas, ""},
+	{"enterMaintenance", "<reason>", "Switch a DKV node into the read-only maintenance mode, rejecting keyspace mutations until it exits it, even across restarts", (*cmd).enterMaintenance, ""},
+	{"exitMaintenance", "", "Switch a DKV node out of the maintenance mode, accepting keyspace mutations again", (*cmd).exitMaintenance, ""},
+	{"limits", "", "Get the limits on the calls served by a DKV node", (*cmd).limits, ""},
+	{"info", "", "Describe a DKV node, like its role, storage engine, build and uptime", (*cmd).info, ""},
+	{"storageStats", "", "Get the statistics of the storage engine of a DKV node, along with the status of its latest compaction", (*cmd).storageStats, ""},
+	{"compactRange", "<startKey> [<endKey>] | all", "Compact the keys of a DKV node from the given start key to the given end key, or all of its keys, in the background", (*cmd).compactRange, ""},
+	{"prefixStats", "<prefix> | all [exact]", "Get the approximate number of keys with the given prefix on a DKV node and the bytes these occupy, or exactly by iterating over them, see -timeout", (*cmd).prefixStats, ""},
+	{"flush", "", "Flush the keys buffered in memory by the storage engine of a DKV node onto its files", (*cmd).flush, ""},
+	{"setQuota", "<namespace> <maxKeys> <maxBytes>", "Limit the keys of the given namespace on a DKV master node and the bytes these occupy, 0 for no limit, removing its quota if both are 0", (*cmd).setQuota, ""},
+	{"quotas", "", "List the quotas of the namespaces on a DKV master node, along with their usage", (*cmd).quotas, ""},
+	{"setLimits", "<name>=<value>[,<name>=<value>...]", "Update the given limits on the calls served by a DKV node, with names among methodRate|methodBurst|tokenRate|tokenBurst|replRate|replBurst|maxInFlight", (*cmd).setLimits, ""},
+	{"reloadSecurity", "", "Reload the TLS certificate, key and CA files along with the token file of a DKV node, applying all of them or none of them if any is invalid", (*cmd).reloadSecurity, ""},
 }
 
 func (c *cmd) usage() {
 	fmt.Printf("  -%s %s - %s\n", c.name, c.argDesc, c.cmdDesc)
+	exitCode = 2
+}
+
+// printErr reports a failed command on stderr, so that the output of
+// the commands can be consumed by scripts, while failing the program.
+func printErr(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+	exitCode = 1
+}
+
+// printJSON prints the given value as a line of JSON.
+func printJSON(v interface{}) {
+	if out, err := json.Marshal(v); err != nil {
+		printErr("Unable to convert the output into JSON. Error: %v\n", err)
+	} else {
+		fmt.Println(string(out))
+	}
+}
+
+// decode converts the given keys or values from the command line into
+// byte arrays as per the given encoding.
+func decode(strs ...string) ([][]byte, error) {
+	res := make([][]byte, len(strs))
+	for i, str := range strs {
+		var err error
+		switch encoding {
+		case "raw":
+			res[i] = []byte(str)
+		case "hex":
+			res[i], err = hex.DecodeString(str)
+		case "base64":
+			res[i], err = base64.StdEncoding.DecodeString(str)
+		default:
+			err = fmt.Errorf("unknown encoding: %s", encoding)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode %s as %s: %w", str, encoding, err)
+		}
+	}
+	return res, nil
+}
+
+// encode converts the given key or value into a string as per the
+// given encoding, to be printed.
+func encode(data []byte) string {
+	switch encoding {
+	case "hex":
+		return hex.EncodeToString(data)
+	case "base64":
+		return base64.StdEncoding.EncodeToString(data)
+	default:
+		return string(data)
+	}
+}
+
+// bulkArgs returns the given arguments, or the lines read from stdin
+// if the only argument is "-".
+func bulkArgs(args []string) ([]string, error) {
+	if len(args) != 1 || args[0] != "-" {
+		return args, nil
+	}
+	var lines []string
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+type kvJSON struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Found     bool   `json:"found"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Error     string `json:"error,omitempty"`
 }
 
 func (c *cmd) set(client *ctl.DKVClient, args ...string) {
 	if len(args) != 2 {
 		c.usage()
-	} else {
-		if err := client.Put([]byte(args[0]), []byte(args[1])); err != nil {
-			fmt.Printf("Unable to perform SET. Error: %v\n", err)
-		}
+	} else if kv, err := decode(args...); err != nil {
+		printErr("Unable to perform SET. Error: %v\n", err)
+	} else if err := client.Put(kv[0], kv[1]); err != nil {
+		printErr("Unable to perform SET. Error: %v\n", err)
 	}
 }
 
 func (c *cmd) get(client *ctl.DKVClient, args ...string) {
 	if len(args) != 1 {
 		c.usage()
+	} else if keys, err := decode(args...); err != nil {
+		printErr("Unable to perform GET. Error: %v\n", err)
+	} else if res, err := client.Get(keys[0]); err != nil {
+		printErr("Unable to perform GET. Error: %v\n", err)
+	} else if jsonOut {
+		printJSON(&kvJSON{Key: args[0], Value: encode(res.Value), Found: true})
+	} else {
+		fmt.Println(encode(res.Value))
+	}
+}
+
+func (c *cmd) mget(client *ctl.DKVClient, args ...string) {
+	keyStrs, err := bulkArgs(args)
+	if err != nil {
+		printErr("Unable to read keys. Error: %v\n", err)
+		return
+	}
+	if len(keyStrs) == 0 {
+		c.usage()
+	} else if keys, err := decode(keyStrs...); err != nil {
+		printErr("Unable to perform MGET. Error: %v\n", err)
+	} else if results, err := client.MultiGetDetailed(keys...); err != nil {
+		printErr("Unable to perform MGET. Error: %v\n", err)
+	} else {
+		kvs := make([]*kvJSON, len(keys))
+		for i, res := range results {
+			kvs[i] = &kvJSON{Key: keyStrs[i], Value: encode(res.Value), Found: res.Found}
+			if res.Err != nil {
+				kvs[i].Error = res.Err.Error()
+			}
+			if !jsonOut {
+				switch {
+				case res.Err != nil:
+					printErr("Unable to get key: %s. Error: %v\n", keyStrs[i], res.Err)
+				case !res.Found:
+					printErr("Key not found: %s\n", keyStrs[i])
+				default:
+					fmt.Printf("%s => %s\n", keyStrs[i], kvs[i].Value)
+				}
+			}
+		}
+		if jsonOut {
+			printJSON(kvs)
+		}
+	}
+}
+
+func (c *cmd) del(client *ctl.DKVClient, args ...string) {
+	keyStrs, err := bulkArgs(args)
+	if err != nil {
+		printErr("Unable to read keys. Error: %v\n", err)
+		return
+	}
+	if len(keyStrs) == 0 {
+		c.usage()
+	} else if keys, err := decode(keyStrs...); err != nil {
+		printErr("Unable to perform DEL. Error: %v\n", err)
+	} else {
+		for i, key := range keys {
+			if err := client.Delete(key); err != nil {
+				printErr("Unable to perform DEL of key: %s. Error: %v\n", keyStrs[i], err)
+			}
+		}
+	}
+}
+
+func (c *cmd) undelete(client *ctl.DKVClient, args ...string) {
+	if len(args) == 0 {
+		c.usage()
+	} else if keys, err := decode(args...); err != nil {
+		printErr("Unable to perform UNDELETE. Error: %v\n", err)
 	} else {
-		if res, err := client.Get([]byte(args[0])); err != nil {
-			fmt.Printf("Unable to perform GET. Error: %v\n", err)
+		for i, key := range keys {
+			if err := client.Undelete(key); err != nil {
+				printErr("Unable to perform UNDELETE of key: %s. Error: %v\n", args[i], err)
+			}
+		}
+	}
+}
+
+func (c *cmd) incr(client *ctl.DKVClient, args ...string) {
+	if len(args) != 2 {
+		c.usage()
+	} else {
+		if delta, err := strconv.ParseInt(args[1], 10, 64); err != nil {
+			printErr("Unable to convert %s into a 64-bit integer\n", args[1])
+		} else if keys, err := decode(args[0]); err != nil {
+			printErr("Unable to perform INCR. Error: %v\n", err)
+		} else {
+			if value, err := client.Increment(keys[0], delta); err != nil {
+				printErr("Unable to perform INCR. Error: %v\n", err)
+			} else {
+				fmt.Println(value)
+			}
+		}
+	}
+}
+
+func (c *cmd) iter(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 && len(args) != 2 {
+		c.usage()
+	} else {
+		keys, err := decode(args...)
+		if err != nil {
+			printErr("Unable to perform iteration. Error: %v\n", err)
+			return
+		}
+		var startKey []byte
+		if len(keys) == 2 {
+			startKey = keys[1]
+		}
+		var opts []ctl.IterateOption
+		if keysOnly {
+			opts = append(opts, ctl.WithKeysOnly())
+		}
+		if maxValueSize > 0 {
+			opts = append(opts, ctl.WithMaxValueSize(uint32(maxValueSize)))
+		}
+		if pairs, err := client.Iterate(keys[0], startKey, opts...); err != nil {
+			printErr("Unable to perform iteration. Error: %v\n", err)
 		} else {
-			fmt.Println(string(res.Value))
+			// Pairs are printed as they stream, as JSON lines if needed
+			for pair := range pairs {
+				switch {
+				case pair.Err != nil:
+					printErr("Error during iteration. Error: %v\n", pair.Err)
+				case jsonOut:
+					printJSON(&kvJSON{Key: encode(pair.Key), Value: encode(pair.Value), Found: true, Truncated: pair.Truncated})
+				case keysOnly:
+					fmt.Println(encode(pair.Key))
+				case pair.Truncated:
+					fmt.Printf("%s => %s (truncated)\n", encode(pair.Key), encode(pair.Value))
+				default:
+					fmt.Printf("%s => %s\n", encode(pair.Key), encode(pair.Value))
+				}
+			}
 		}
 	}
 }
@@ -56,8 +308,8 @@ func (c *cmd) backup(client *ctl.DKVClient, args ...string) {
 	if len(args) != 1 {
 		c.usage()
 	} else {
-		if err := client.Backup(args[0]); err != nil {
-			fmt.Printf("Unable to perform backup. Error: %v\n", err)
+		if err := client.BackupWithProgress(args[0], printBackupProgress); err != nil {
+			printErr("Unable to perform backup. Error: %v\n", err)
 		} else {
 			fmt.Println("Successfully backed up")
 		}
@@ -68,23 +320,165 @@ func (c *cmd) restore(client *ctl.DKVClient, args ...string) {
 	if len(args) != 1 {
 		c.usage()
 	} else {
-		if err := client.Restore(args[0]); err != nil {
-			fmt.Printf("Unable to perform restore. Error: %v\n", err)
+		if err := client.RestoreWithProgress(args[0], printBackupProgress); err != nil {
+			printErr("Unable to perform restore. Error: %v\n", err)
 		} else {
 			fmt.Println("Successfully restored")
 		}
 	}
 }
 
+func (c *cmd) verifyRestore(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else {
+		if info, err := client.VerifyRestore(args[0]); err != nil {
+			printErr("Unable to verify backup. Error: %v\n", err)
+		} else {
+			fmt.Printf("Backup is restorable. Engine: %s, Change number: %d (currently %d), Keys: %d, Size: %d bytes\n",
+				info.Engine, info.ChangeNumber, info.CurrentChangeNumber, info.NumberOfKeys, info.Size)
+		}
+	}
+}
+
+func printBackupProgress(res *serverpb.GetBackupStatusResponse) {
+	switch {
+	case res.State != serverpb.BackupJobState_Running:
+	case res.Restore && res.TotalBytes > 0:
+		fmt.Printf("Job: %d, Bytes restored: %d of %d (%.1f%%), Keys processed: %d\n", res.JobID, res.BytesRestored, res.TotalBytes, 100*float64(res.BytesRestored)/float64(res.TotalBytes), res.KeysProcessed)
+	default:
+		fmt.Printf("Job: %d, Bytes written: %d, Keys processed: %d\n", res.JobID, res.BytesWritten, res.KeysProcessed)
+	}
+}
+
+func (c *cmd) backupTo(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else {
+		if err := streamBackup(client, args[0]); err != nil {
+			printErr("Unable to perform backup. Error: %v\n", err)
+		} else {
+			fmt.Println("Successfully backed up")
+		}
+	}
+}
+
+func streamBackup(client *ctl.DKVClient, file string) error {
+	// Fails for existing files rather than overwriting a backup
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	if err = client.BackupTo(bw); err == nil {
+		err = bw.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+	}
+	return err
+}
+
+func (c *cmd) restoreFrom(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else {
+		if f, err := os.Open(args[0]); err != nil {
+			printErr("Unable to perform restore. Error: %v\n", err)
+		} else {
+			defer f.Close()
+			if err := client.RestoreFrom(f); err != nil {
+				printErr("Unable to perform restore. Error: %v\n", err)
+			} else {
+				fmt.Println("Successfully restored")
+			}
+		}
+	}
+}
+
+func (c *cmd) bulkLoad(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+		return
+	}
+	in := os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			printErr("Unable to perform bulk load. Error: %v\n", err)
+			return
+		}
+		defer f.Close()
+		in = f
+	}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	lineNum := 0
+	res, err := client.BulkLoad(func() (*serverpb.PutRequest, error) {
+		for scanner.Scan() {
+			lineNum++
+			line := scanner.Text()
+			if line == "" {
+				continue
+			}
+			sep := strings.IndexByte(line, ' ')
+			if sep < 0 {
+				return nil, fmt.Errorf("line %d is not a space separated key value pair", lineNum)
+			}
+			kv, err := decode(line[:sep], line[sep+1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			return &serverpb.PutRequest{Key: kv[0], Value: kv[1]}, nil
+		}
+		return nil, scanner.Err()
+	})
+	if err != nil {
+		printErr("Unable to perform bulk load. Error: %v\n", err)
+	} else {
+		fmt.Printf("Successfully bulk loaded %d keys as of change number: %d\n", res.NumberOfKeys, res.ChangeNumber)
+	}
+}
+
+func (c *cmd) clusterBackup(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else {
+		if mnfst, err := client.ClusterBackup(args[0]); err != nil {
+			printErr("Unable to perform cluster backup. Error: %v\n", err)
+		} else {
+			fmt.Printf("Successfully backed up the cluster as of change number: %d\n", mnfst.ChangeNumber)
+			for _, artf := range mnfst.Artifacts {
+				fmt.Printf("Node: %d, Location: %s, Size: %d bytes\n", artf.NodeId, artf.Location, artf.Info.GetSize())
+			}
+		}
+	}
+}
+
+func (c *cmd) clusterRestore(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else {
+		if err := client.ClusterRestore(args[0]); err != nil {
+			printErr("Unable to perform cluster restore. Error: %v\n", err)
+		} else {
+			fmt.Println("Successfully restored the cluster")
+		}
+	}
+}
+
 func (c *cmd) addNode(client *ctl.DKVClient, args ...string) {
 	if len(args) != 2 {
 		c.usage()
 	} else {
 		if nodeID, err := strconv.ParseUint(args[0], 10, 32); err != nil {
-			fmt.Printf("Unable to convert %s into an unsigned 32-bit integer\n", args[0])
+			printErr("Unable to convert %s into an unsigned 32-bit integer\n", args[0])
 		} else {
 			if err := client.AddNode(uint32(nodeID), args[1]); err != nil {
-				fmt.Printf("Unable to add node with ID: %d and URL: %s\n", nodeID, args[1])
+				printErr("Unable to add node with ID: %d and URL: %s\n", nodeID, args[1])
 			}
 		}
 	}
@@ -95,38 +489,766 @@ func (c *cmd) removeNode(client *ctl.DKVClient, args ...string) {
 		c.usage()
 	} else {
 		if nodeID, err := strconv.ParseUint(args[0], 10, 32); err != nil {
-			fmt.Printf("Unable to convert %s into an unsigned 32-bit integer\n", args[0])
+			printErr("Unable to convert %s into an unsigned 32-bit integer\n", args[0])
 		} else {
 			if err := client.RemoveNode(uint32(nodeID)); err != nil {
-				fmt.Printf("Unable to remove node with ID: %d\n", nodeID)
+				printErr("Unable to remove node with ID: %d\n", nodeID)
+			}
+		}
+	}
+}
+
+func (c *cmd) decommissionNode(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else {
+		if nodeID, err := strconv.ParseUint(args[0], 10, 32); err != nil {
+			printErr("Unable to convert %s into an unsigned 32-bit integer\n", args[0])
+		} else if err := client.DecommissionNode(uint32(nodeID), 0); err != nil {
+			printErr("Unable to decommission node with ID: %d. Error: %v\n", nodeID, err)
+		} else if !wait {
+			fmt.Printf("Decommissioning node with ID: %d\n", nodeID)
+		} else if err := client.WaitForDecommission(context.Background(), uint32(nodeID), printDecommissionProgress); err != nil {
+			printErr("Unable to decommission node with ID: %d. Error: %v\n", nodeID, err)
+		} else {
+			fmt.Printf("Successfully decommissioned node with ID: %d\n", nodeID)
+		}
+	}
+}
+
+func printDecommissionProgress(res *serverpb.GetDecommissionStatusResponse) {
+	switch res.State {
+	case serverpb.DecommissionState_TransferringLeadership, serverpb.DecommissionState_Removing:
+		fmt.Printf("Node: %d, State: %s\n", res.NodeId, res.State)
+	case serverpb.DecommissionState_CatchingUp:
+		fmt.Printf("Node: %d, State: %s, Target change number: %d, Members: %v\n", res.NodeId, res.State, res.TargetChangeNumber, res.MemberChangeNumbers)
+	}
+}
+
+func (c *cmd) nodes(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else if leader, nodes, err := client.ListNodes(); err != nil {
+		printErr("Unable to list nodes. Error: %v\n", err)
+	} else if jsonOut {
+		type nodeJSON struct {
+			NodeID        uint32 `json:"nodeId"`
+			NodeURL       string `json:"nodeUrl"`
+			Role          string `json:"role"`
+			LastContactTS uint64 `json:"lastContactTS"`
+			DkvAddr       string `json:"dkvAddr,omitempty"`
+		}
+		out := struct {
+			Leader uint32      `json:"leader"`
+			Nodes  []*nodeJSON `json:"nodes"`
+		}{Leader: leader}
+		for _, node := range nodes {
+			out.Nodes = append(out.Nodes, &nodeJSON{node.NodeId, node.NodeUrl, node.Role.String(), node.LastContactTS, node.DkvAddr})
+		}
+		printJSON(&out)
+	} else {
+		for _, node := range nodes {
+			lastContact := "never"
+			if node.LastContactTS > 0 {
+				lastContact = time.Unix(int64(node.LastContactTS), 0).Format(time.RFC3339)
+			}
+			fmt.Printf("%d\t%s\t%s\t%s\t%s\n", node.NodeId, node.NodeUrl, node.Role, lastContact, node.DkvAddr)
+		}
+	}
+}
+
+func (c *cmd) pauseRepl(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else {
+		if autoResumeAfterSecs, err := strconv.ParseUint(args[0], 10, 32); err != nil {
+			printErr("Unable to convert %s into an unsigned 32-bit integer\n", args[0])
+		} else {
+			if err := client.PauseReplication(uint32(autoResumeAfterSecs)); err != nil {
+				printErr("Unable to pause replication. Error: %v\n", err)
+			} else {
+				fmt.Println("Successfully paused replication")
 			}
 		}
 	}
 }
 
-var dkvAddr string
+func (c *cmd) resumeRepl(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else if err := client.ResumeReplication(); err != nil {
+		printErr("Unable to resume replication. Error: %v\n", err)
+	} else {
+		fmt.Println("Successfully resumed replication")
+	}
+}
+
+func (c *cmd) promote(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else if chngNum, err := client.PromoteToMaster(); err != nil {
+		printErr("Unable to promote to master. Error: %v\n", err)
+	} else {
+		fmt.Printf("Successfully promoted to master at change number: %d\n", chngNum)
+	}
+}
+
+func (c *cmd) replStatus(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else if status, err := client.ReplicationStatus(); err != nil {
+		printErr("Unable to get replication status. Error: %v\n", err)
+	} else if jsonOut {
+		type replicationState struct {
+			MasterAddr          string `json:"masterAddr"`
+			ReplicaID           string `json:"replicaID"`
+			AppliedChangeNumber uint64 `json:"appliedChangeNumber"`
+			LastPollTimeMillis  int64  `json:"lastPollTimeMillis"`
+		}
+		var persistedState *replicationState
+		if state := status.PersistedState; state != nil {
+			persistedState = &replicationState{state.MasterAddr, state.ReplicaID, state.AppliedChangeNumber, state.LastPollTimeMillis}
+		}
+		printJSON(&struct {
+			MasterAddr          string            `json:"masterAddr"`
+			ReplicaID           string            `json:"replicaID"`
+			AppliedChangeNumber uint64            `json:"appliedChangeNumber"`
+			MasterChangeNumber  uint64            `json:"masterChangeNumber"`
+			ReplicationLag      uint64            `json:"replicationLag"`
+			LastPollTimeMillis  int64             `json:"lastPollTimeMillis"`
+			NumErrors           uint64            `json:"numErrors"`
+			Healthy             bool              `json:"healthy"`
+			Paused              bool              `json:"paused"`
+			ApplyLatencyMicros  uint64            `json:"applyLatencyMicros"`
+			BatchSize           uint32            `json:"effectiveBatchSize"`
+			PollIntervalMillis  int64             `json:"effectivePollIntervalMillis"`
+			PersistedState      *replicationState `json:"persistedState,omitempty"`
+		}{status.MasterAddr, status.ReplicaID, status.AppliedChangeNumber, status.MasterChangeNumber, status.ReplicationLag,
+			status.LastPollTimeMillis, status.NumErrors, status.Healthy, status.Paused,
+			status.ApplyLatencyMicros, status.EffectiveBatchSize, status.EffectivePollIntervalMillis, persistedState})
+	} else {
+		fmt.Printf("Master: %s, Replica ID: %s, Applied change number: %d, Master change number: %d, Lag: %d, Last poll: %s, Errors: %d, Healthy: %t, Paused: %t, Apply latency: %v, Batch size: %d, Poll interval: %v\n",
+			status.MasterAddr, status.ReplicaID, status.AppliedChangeNumber, status.MasterChangeNumber, status.ReplicationLag, formatPollTime(status.LastPollTimeMillis), status.NumErrors, status.Healthy, status.Paused,
+			time.Duration(status.ApplyLatencyMicros)*time.Microsecond, status.EffectiveBatchSize, time.Duration(status.EffectivePollIntervalMillis)*time.Millisecond)
+		if state := status.PersistedState; state != nil {
+			fmt.Printf("Persisted master: %s, Replica ID: %s, Applied change number: %d, Last poll: %s\n",
+				state.MasterAddr, state.ReplicaID, state.AppliedChangeNumber, formatPollTime(state.LastPollTimeMillis))
+		}
+	}
+}
+
+func formatPollTime(pollTimeMillis int64) string {
+	if pollTimeMillis <= 0 {
+		return "never"
+	}
+	return time.Unix(0, pollTimeMillis*int64(time.Millisecond)).Format(time.RFC3339)
+}
+
+func (c *cmd) verifyRange(client *ctl.DKVClient, args ...string) {
+	if len(args) != 2 {
+		c.usage()
+	} else if fromChngNum, err := strconv.ParseUint(args[0], 10, 64); err != nil {
+		printErr("Unable to convert %s into an unsigned 64-bit integer\n", args[0])
+	} else if toChngNum, err := strconv.ParseUint(args[1], 10, 64); err != nil {
+		printErr("Unable to convert %s into an unsigned 64-bit integer\n", args[1])
+	} else if res, err := client.VerifyRange(fromChngNum, toChngNum); err != nil {
+		printErr("Unable to verify the range of changes. Error: %v\n", err)
+	} else if jsonOut {
+		printJSON(&struct {
+			Checksum      string `json:"checksum"`
+			NumberOfTrxns uint64 `json:"numberOfTrxns"`
+		}{fmt.Sprintf("%08x", res.Checksum), res.NumberOfTrxns})
+	} else {
+		fmt.Printf("Checksum: %08x, Transactions: %d\n", res.Checksum, res.NumberOfTrxns)
+	}
+}
+
+type replicaJSON struct {
+	ReplicaID            string `json:"replicaID"`
+	ConsumedChangeNumber uint64 `json:"consumedChangeNumber"`
+	ReplicationLag       uint64 `json:"replicationLag"`
+	LastSeenTimeMillis   int64  `json:"lastSeenTimeMillis"`
+}
+
+func toReplicasJSON(replicas []*serverpb.ReplicaProgress) []replicaJSON {
+	replJSONs := make([]replicaJSON, len(replicas))
+	for i, replica := range replicas {
+		replJSONs[i] = replicaJSON{replica.ReplicaID, replica.ConsumedChangeNumber, replica.ReplicationLag, replica.LastSeenTimeMillis}
+	}
+	return replJSONs
+}
+
+func printReplicas(replicas []*serverpb.ReplicaProgress) {
+	for _, replica := range replicas {
+		lastSeen := time.Unix(0, replica.LastSeenTimeMillis*int64(time.Millisecond)).Format(time.RFC3339)
+		fmt.Printf("Replica: %s, Consumed change number: %d, Lag: %d, Last seen: %s\n", replica.ReplicaID, replica.ConsumedChangeNumber, replica.ReplicationLag, lastSeen)
+	}
+}
+
+func (c *cmd) replicas(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else if res, err := client.ListReplicas(); err != nil {
+		printErr("Unable to list replicas. Error: %v\n", err)
+	} else if jsonOut {
+		printJSON(&struct {
+			MasterChangeNumber uint64        `json:"masterChangeNumber"`
+			Replicas           []replicaJSON `json:"replicas"`
+		}{res.MasterChangeNumber, toReplicasJSON(res.Replicas)})
+	} else {
+		fmt.Printf("Master change number: %d, Replicas: %d\n", res.MasterChangeNumber, len(res.Replicas))
+		printReplicas(res.Replicas)
+	}
+}
+
+func (c *cmd) changeLogInfo(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else if info, err := client.GetChangeLogInfo(); err != nil {
+		printErr("Unable to get the change log info. Error: %v\n", err)
+	} else if jsonOut {
+		printJSON(&struct {
+			FirstChangeNumber uint64        `json:"firstChangeNumber"`
+			LastChangeNumber  uint64        `json:"lastChangeNumber"`
+			Replicas          []replicaJSON `json:"replicas"`
+		}{info.FirstChangeNumber, info.LastChangeNumber, toReplicasJSON(info.Replicas)})
+	} else {
+		if info.FirstChangeNumber > info.LastChangeNumber {
+			fmt.Printf("No changes retained, Latest change number: %d\n", info.LastChangeNumber)
+		} else {
+			fmt.Printf("Retained changes: %d-%d\n", info.FirstChangeNumber, info.LastChangeNumber)
+		}
+		printReplicas(info.Replicas)
+	}
+}
+
+func (c *cmd) truncateChangeLog(client *ctl.DKVClient, args ...string) {
+	var beforeChngNum uint64
+	if len(args) != 1 {
+		c.usage()
+		return
+	} else if args[0] != "policy" {
+		var err error
+		if beforeChngNum, err = strconv.ParseUint(args[0], 10, 64); err != nil || beforeChngNum == 0 {
+			printErr("Unable to convert %s into a positive 64-bit integer\n", args[0])
+			return
+		}
+	}
+	if firstChngNum, err := client.TruncateChangeLog(beforeChngNum); err != nil {
+		printErr("Unable to truncate the change log. Error: %v\n", err)
+	} else {
+		fmt.Printf("Successfully truncated the change log, Oldest change retained: %d\n", firstChngNum)
+	}
+}
+
+func (c *cmd) compareReplicas(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+		return
+	}
+	slave, err := newClient(args[0])
+	if err != nil {
+		printErr("Unable to create DKV client for the slave node. Error: %v\n", err)
+		return
+	}
+	defer slave.Close()
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	diff, err := ctl.CompareReplicas(ctx, client, slave, ctl.DefaultCompareOptions)
+	if err != nil {
+		printErr("Unable to compare the replicas. Error: %v\n", err)
+		return
+	}
+	if jsonOut {
+		type keyMismatch struct {
+			Key      string `json:"key"`
+			InMaster bool   `json:"inMaster"`
+			InSlave  bool   `json:"inSlave"`
+		}
+		mismatches := make([]keyMismatch, len(diff.Mismatches))
+		for i, mismatch := range diff.Mismatches {
+			mismatches[i] = keyMismatch{encode(mismatch.Key), mismatch.InMaster, mismatch.InSlave}
+		}
+		printJSON(&struct {
+			Mismatches []keyMismatch `json:"mismatches"`
+			Truncated  bool          `json:"truncated"`
+		}{mismatches, diff.Truncated})
+		return
+	}
+	for _, mismatch := range diff.Mismatches {
+		switch {
+		case !mismatch.InSlave:
+			fmt.Printf("%s: missing on slave\n", encode(mismatch.Key))
+		case !mismatch.InMaster:
+			fmt.Printf("%s: missing on master\n", encode(mismatch.Key))
+		default:
+			fmt.Printf("%s: values differ\n", encode(mismatch.Key))
+		}
+	}
+	switch {
+	case diff.Truncated:
+		fmt.Printf("Listed the first %d keys that differ\n", len(diff.Mismatches))
+	case diff.Consistent():
+		fmt.Println("Replicas are consistent")
+	default:
+		fmt.Printf("%d keys differ\n", len(diff.Mismatches))
+	}
+}
+
+func (c *cmd) limits(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else if lims, err := client.GetLimits(); err != nil {
+		printErr("Unable to get limits. Error: %v\n", err)
+	} else if jsonOut {
+		printJSON(&struct {
+			MethodRate       float64 `json:"methodRate"`
+			MethodBurst      uint32  `json:"methodBurst"`
+			TokenRate        float64 `json:"tokenRate"`
+			TokenBurst       uint32  `json:"tokenBurst"`
+			ReplicationRate  float64 `json:"replRate"`
+			ReplicationBurst uint32  `json:"replBurst"`
+			MaxInFlight      uint32  `json:"maxInFlight"`
+		}{lims.MethodRate, lims.MethodBurst, lims.TokenRate, lims.TokenBurst, lims.ReplicationRate, lims.ReplicationBurst, lims.MaxInFlight})
+	} else {
+		fmt.Printf("Method rate: %v, Method burst: %d, Token rate: %v, Token burst: %d, Replication rate: %v, Replication burst: %d, Max in flight: %d\n",
+			lims.MethodRate, lims.MethodBurst, lims.TokenRate, lims.TokenBurst, lims.ReplicationRate, lims.ReplicationBurst, lims.MaxInFlight)
+	}
+}
+
+func (c *cmd) enterMaintenance(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else if err := client.EnterMaintenanceMode(args[0]); err != nil {
+		printErr("Unable to enter maintenance mode. Error: %v\n", err)
+	} else {
+		fmt.Println("Successfully entered maintenance mode")
+	}
+}
+
+func (c *cmd) exitMaintenance(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else if err := client.ExitMaintenanceMode(); err != nil {
+		printErr("Unable to exit maintenance mode. Error: %v\n", err)
+	} else {
+		fmt.Println("Successfully exited maintenance mode")
+	}
+}
+
+func (c *cmd) reloadSecurity(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+		return
+	}
+	res, err := client.ReloadSecurityConfig()
+	if res == nil {
+		printErr("Unable to reload the security config. Error: %v\n", err)
+		return
+	}
+	var certNotAfter string
+	if res.CertificateNotAfter > 0 {
+		certNotAfter = time.Unix(res.CertificateNotAfter, 0).UTC().Format(time.RFC3339)
+	}
+	if jsonOut {
+		printJSON(&struct {
+			Reloaded            []string `json:"reloaded"`
+			Errors              []string `json:"errors,omitempty"`
+			CertificateNotAfter string   `json:"certificateNotAfter,omitempty"`
+			NumberOfTokens      uint32   `json:"numberOfTokens"`
+		}{res.Reloaded, res.Errors, certNotAfter, res.NumberOfTokens})
+	} else if err == nil {
+		fmt.Printf("Reloaded: %s\n", strings.Join(res.Reloaded, ", "))
+		if certNotAfter != "" {
+			fmt.Printf("Certificate expires at: %s\n", certNotAfter)
+		}
+		fmt.Printf("Number of tokens: %d\n", res.NumberOfTokens)
+	}
+	if err != nil {
+		printErr("Unable to reload the security config. Error: %v\n", err)
+	}
+}
+
+func (c *cmd) info(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+		return
+	}
+	info, err := client.GetServerInfo()
+	if err != nil {
+		printErr("Unable to get server info. Error: %v\n", err)
+		return
+	}
+	role := strings.ToLower(info.Role.String())
+	uptime := time.Duration(info.UptimeMillis) * time.Millisecond
+	if jsonOut {
+		printJSON(&struct {
+			Role                 string            `json:"role"`
+			StorageEngine        string            `json:"storageEngine"`
+			StorageEngineVersion string            `json:"storageEngineVersion,omitempty"`
+			Version              string            `json:"version"`
+			Commit               string            `json:"commit,omitempty"`
+			UptimeMillis         int64             `json:"uptimeMillis"`
+			ListenAddrs          map[string]string `json:"listenAddrs,omitempty"`
+			Maintenance          bool              `json:"maintenance"`
+			MaintenanceReason    string            `json:"maintenanceReason,omitempty"`
+		}{role, info.StorageEngine, info.StorageEngineVersion, info.Version, info.Commit, info.UptimeMillis, info.ListenAddrs, info.Maintenance, info.MaintenanceReason})
+		return
+	}
+	fmt.Printf("Role: %s, Storage engine: %s %s, Version: %s, Commit: %s, Uptime: %v\n",
+		role, info.StorageEngine, info.StorageEngineVersion, info.Version, info.Commit, uptime)
+	if info.Maintenance {
+		fmt.Printf("In maintenance mode, reason: %s\n", info.MaintenanceReason)
+	}
+	protocols := make([]string, 0, len(info.ListenAddrs))
+	for protocol := range info.ListenAddrs {
+		protocols = append(protocols, protocol)
+	}
+	sort.Strings(protocols)
+	for _, protocol := range protocols {
+		fmt.Printf("%s: %s\n", protocol, info.ListenAddrs[protocol])
+	}
+}
+
+func (c *cmd) storageStats(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+		return
+	}
+	stats, err := client.GetStorageStats()
+	if err != nil {
+		printErr("Unable to get storage stats. Error: %v\n", err)
+		return
+	}
+	lastCompaction := formatMillis(stats.LastCompactionTimeMillis)
+	var cmpctn *compactionJSON
+	if c := stats.Compaction; c != nil {
+		cmpctn = &compactionJSON{encode(c.StartKey), encode(c.EndKey), c.Running, formatMillis(c.StartTimeMillis), formatMillis(c.EndTimeMillis), c.Error}
+	}
+	if jsonOut {
+		printJSON(&struct {
+			Engine         string            `json:"engine"`
+			ApproxNumKeys  uint64            `json:"approxNumKeys"`
+			DiskUsage      uint64            `json:"diskUsageBytes"`
+			LastCompaction string            `json:"lastCompaction,omitempty"`
+			EngineStats    map[string]string `json:"engineStats,omitempty"`
+			Compaction     *compactionJSON   `json:"compaction,omitempty"`
+		}{stats.Engine, stats.ApproximateNumberOfKeys, stats.DiskUsageBytes, lastCompaction, stats.EngineStats, cmpctn})
+		return
+	}
+	if lastCompaction == "" {
+		lastCompaction = "unknown"
+	}
+	fmt.Printf("Engine: %s, Approximate number of keys: %d, Disk usage: %d bytes, Last compaction: %s\n",
+		stats.Engine, stats.ApproximateNumberOfKeys, stats.DiskUsageBytes, lastCompaction)
+	switch {
+	case cmpctn == nil:
+	case cmpctn.Running:
+		fmt.Printf("Compaction of [%s, %s] running since %s\n", cmpctn.StartKey, cmpctn.EndKey, cmpctn.StartTime)
+	case cmpctn.Error != "":
+		fmt.Printf("Compaction of [%s, %s] failed at %s. Error: %s\n", cmpctn.StartKey, cmpctn.EndKey, cmpctn.EndTime, cmpctn.Error)
+	default:
+		fmt.Printf("Compaction of [%s, %s] completed at %s\n", cmpctn.StartKey, cmpctn.EndKey, cmpctn.EndTime)
+	}
+	names := make([]string, 0, len(stats.EngineStats))
+	for name := range stats.EngineStats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s: %s\n", name, stats.EngineStats[name])
+	}
+}
+
+type compactionJSON struct {
+	StartKey  string `json:"startKey,omitempty"`
+	EndKey    string `json:"endKey,omitempty"`
+	Running   bool   `json:"running"`
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func formatMillis(millis int64) string {
+	if millis <= 0 {
+		return ""
+	}
+	return time.Unix(0, millis*int64(time.Millisecond)).Format(time.RFC3339)
+}
+
+func (c *cmd) compactRange(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 && len(args) != 2 {
+		c.usage()
+		return
+	}
+	var startKey, endKey []byte
+	if len(args) == 2 || args[0] != "all" {
+		keys, err := decode(args...)
+		if err != nil {
+			printErr("Unable to compact. Error: %v\n", err)
+			return
+		}
+		startKey = keys[0]
+		if len(keys) == 2 {
+			endKey = keys[1]
+		}
+	}
+	if err := client.CompactRange(startKey, endKey); err != nil {
+		printErr("Unable to compact. Error: %v\n", err)
+	} else {
+		fmt.Println("Started compaction, see -storageStats for its progress")
+	}
+}
+
+func (c *cmd) prefixStats(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 && (len(args) != 2 || args[1] != "exact") {
+		c.usage()
+		return
+	}
+	req := &serverpb.PrefixStatsRequest{Exact: len(args) == 2}
+	if args[0] != "all" {
+		prefixes, err := decode(args[0])
+		if err != nil {
+			printErr("Unable to get prefix stats. Error: %v\n", err)
+			return
+		}
+		req.Prefix = prefixes[0]
+	}
+	// Exact stats take as long as iterating over the keys
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	stats, err := client.PrefixStatsWithCtx(ctx, req)
+	if err != nil {
+		printErr("Unable to get prefix stats. Error: %v\n", err)
+		return
+	}
+	if jsonOut {
+		printJSON(&struct {
+			NumKeys   uint64 `json:"numKeys"`
+			SizeBytes uint64 `json:"sizeBytes"`
+			Exact     bool   `json:"exact"`
+		}{stats.NumberOfKeys, stats.SizeBytes, stats.Exact})
+	} else if stats.Exact {
+		fmt.Printf("Number of keys: %d, Size: %d bytes\n", stats.NumberOfKeys, stats.SizeBytes)
+	} else {
+		fmt.Printf("Approximate number of keys: %d, Approximate size: %d bytes\n", stats.NumberOfKeys, stats.SizeBytes)
+	}
+}
+
+func (c *cmd) flush(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+	} else if err := client.Flush(); err != nil {
+		printErr("Unable to flush. Error: %v\n", err)
+	} else {
+		fmt.Println("Successfully flushed")
+	}
+}
+
+func (c *cmd) setQuota(client *ctl.DKVClient, args ...string) {
+	if len(args) != 3 {
+		c.usage()
+	} else if maxKeys, err := strconv.ParseUint(args[1], 10, 64); err != nil {
+		printErr("Unable to convert %s into an unsigned 64-bit integer\n", args[1])
+	} else if maxBytes, err := strconv.ParseUint(args[2], 10, 64); err != nil {
+		printErr("Unable to convert %s into an unsigned 64-bit integer\n", args[2])
+	} else if err := client.SetQuota(args[0], maxKeys, maxBytes); err != nil {
+		printErr("Unable to set quota. Error: %v\n", err)
+	} else {
+		fmt.Println("Successfully set quota")
+	}
+}
+
+type quotaJSON struct {
+	Namespace           string `json:"namespace"`
+	MaxKeys             uint64 `json:"maxKeys"`
+	MaxBytes            uint64 `json:"maxBytes"`
+	NumKeys             uint64 `json:"numKeys"`
+	SizeBytes           uint64 `json:"sizeBytes"`
+	ReconcileTimeMillis int64  `json:"reconcileTimeMillis"`
+}
+
+func (c *cmd) quotas(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+		return
+	}
+	quotas, err := client.ListQuotas()
+	if err != nil {
+		printErr("Unable to list quotas. Error: %v\n", err)
+		return
+	}
+	if jsonOut {
+		quotasJSON := make([]quotaJSON, len(quotas))
+		for i, q := range quotas {
+			quotasJSON[i] = quotaJSON{q.Namespace, q.MaxKeys, q.MaxBytes, q.NumberOfKeys, q.SizeBytes, q.ReconcileTimeMillis}
+		}
+		printJSON(quotasJSON)
+		return
+	}
+	for _, q := range quotas {
+		reconciled := "never"
+		if q.ReconcileTimeMillis > 0 {
+			reconciled = time.Unix(0, q.ReconcileTimeMillis*int64(time.Millisecond)).Format(time.RFC3339)
+		}
+		fmt.Printf("Namespace: %s, Keys: %d of %d, Size: %d of %d bytes, Reconciled: %s\n", q.Namespace, q.NumberOfKeys, q.MaxKeys, q.SizeBytes, q.MaxBytes, reconciled)
+	}
+}
+
+func (c *cmd) setLimits(client *ctl.DKVClient, args ...string) {
+	if len(args) != 1 {
+		c.usage()
+		return
+	}
+	// Limits not given are retained as they are
+	lims, err := client.GetLimits()
+	if err != nil {
+		printErr("Unable to get limits. Error: %v\n", err)
+		return
+	}
+	for _, entry := range strings.Split(args[0], ",") {
+		kv := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(kv) != 2 {
+			printErr("Invalid limit: %s, expected <name>=<value>\n", entry)
+			return
+		}
+		if err = setLimit(lims, kv[0], kv[1]); err != nil {
+			printErr("Invalid limit: %s. Error: %v\n", entry, err)
+			return
+		}
+	}
+	if err = client.SetLimits(lims); err != nil {
+		printErr("Unable to set limits. Error: %v\n", err)
+	} else {
+		fmt.Println("Successfully set limits")
+	}
+}
+
+func setLimit(lims *serverpb.Limits, name, value string) error {
+	var err error
+	switch name {
+	case "methodRate":
+		lims.MethodRate, err = strconv.ParseFloat(value, 64)
+	case "tokenRate":
+		lims.TokenRate, err = strconv.ParseFloat(value, 64)
+	case "replRate":
+		lims.ReplicationRate, err = strconv.ParseFloat(value, 64)
+	case "methodBurst":
+		lims.MethodBurst, err = parseUint32(value)
+	case "tokenBurst":
+		lims.TokenBurst, err = parseUint32(value)
+	case "replBurst":
+		lims.ReplicationBurst, err = parseUint32(value)
+	case "maxInFlight":
+		lims.MaxInFlight, err = parseUint32(value)
+	default:
+		err = fmt.Errorf("unknown limit: %s", name)
+	}
+	return err
+}
+
+func parseUint32(value string) (uint32, error) {
+	num, err := strconv.ParseUint(value, 10, 32)
+	return uint32(num), err
+}
+
+// noArgCmd is a boolean flag for commands that take no arguments,
+// so that they can be invoked without having to pass a value.
+type noArgCmd cmd
+
+func (c *noArgCmd) String() string   { return "" }
+func (c *noArgCmd) IsBoolFlag() bool { return true }
+
+func (c *noArgCmd) Set(value string) error {
+	if value == "true" {
+		c.value = value
+	}
+	return nil
+}
+
+var dkvAddr, authToken string
+
+var tlsCertFile, tlsKeyFile, tlsCAFile string
+
+var encoding string
+
+var timeout time.Duration
+
+var wait, useTLS, jsonOut, keysOnly bool
+
+var maxValueSize uint
+
+var maxRecvMsgSize int
+
+// exitCode is that of the program, set once a command fails
+var exitCode int
+
+// globalFlags are those listed by the usage ahead of the commands
+var globalFlags = []string{"dkvAddr", "authToken", "tls", "tlsCertFile", "tlsKeyFile", "tlsCAFile", "timeout", "encoding", "json", "wait", "keysOnly", "maxValueSize", "maxRecvMsgSize"}
 
 func init() {
 	flag.StringVar(&dkvAddr, "dkvAddr", "127.0.0.1:8080", "<host>:<port> - DKV server address")
+	flag.StringVar(&authToken, "authToken", "", "<token> - Bearer token presented to the DKV server")
+	flag.BoolVar(&useTLS, "tls", false, "Connect to the DKV server over TLS, implied by any of the TLS files")
+	flag.StringVar(&tlsCertFile, "tlsCertFile", "", "<file> - Client certificate presented to the DKV server for mutual TLS")
+	flag.StringVar(&tlsKeyFile, "tlsKeyFile", "", "<file> - Key of the client certificate")
+	flag.StringVar(&tlsCAFile, "tlsCAFile", "", "<file> - CA certificate used for verifying the DKV server, instead of the system CAs")
+	flag.DurationVar(&timeout, "timeout", 0, "<duration> - Timeout of every request to the DKV server, such as 5s")
+	flag.StringVar(&encoding, "encoding", "raw", "<raw|hex|base64> - Encoding of the keys and values given and printed")
+	flag.BoolVar(&jsonOut, "json", false, "Print the output of get, mget, iter, nodes, info, replStatus, replicas, verifyRange, changeLogInfo, compareReplicas, limits, storageStats, prefixStats and quotas as JSON")
+	flag.BoolVar(&wait, "wait", false, "Wait for the decommission of the node to complete")
+	flag.BoolVar(&keysOnly, "keysOnly", false, "Iterate the keys alone, without their values")
+	flag.UintVar(&maxValueSize, "maxValueSize", 0, "<bytes> - Size beyond which the values iterated are truncated, 0 for no limit")
+	flag.IntVar(&maxRecvMsgSize, "maxRecvMsgSize", ctl.DefaultMaxRecvMsgSize, "<bytes> - Maximum size of the responses received from the DKV server, such as of mget with large values")
 	for _, c := range cmds {
-		flag.StringVar(&c.value, c.name, c.value, c.cmdDesc)
+		if c.argDesc == "" {
+			flag.Var((*noArgCmd)(c), c.name, c.cmdDesc)
+		} else {
+			flag.StringVar(&c.value, c.name, c.value, c.cmdDesc)
+		}
 	}
 	flag.Usage = func() {
 		fmt.Printf("Usage of %s:\n", os.Args[0])
-		fmt.Printf("  -dkvAddr %s\n", flag.Lookup("dkvAddr").Usage)
+		for _, name := range globalFlags {
+			fmt.Printf("  -%s %s\n", name, flag.Lookup(name).Usage)
+		}
 		for _, cmd := range cmds {
 			cmd.usage()
 		}
 	}
 }
 
+// newClient creates a client of the DKV node at the given address,
+// as per the global flags.
+func newClient(addr string) (*ctl.DKVClient, error) {
+	cliOpts := []ctl.DKVClientOption{ctl.WithMaxRecvMsgSize(maxRecvMsgSize)}
+	if authToken != "" {
+		cliOpts = append(cliOpts, ctl.WithAuthToken(authToken))
+	}
+	if timeout > 0 {
+		cliOpts = append(cliOpts, ctl.WithTimeout(timeout))
+	}
+	if useTLS || tlsCertFile != "" || tlsKeyFile != "" || tlsCAFile != "" {
+		return ctl.NewTLSDKVClient(addr, tlsCertFile, tlsKeyFile, tlsCAFile, cliOpts...)
+	}
+	return ctl.NewInSecureDKVClient(addr, cliOpts...)
+}
+
 func main() {
 	flag.Parse()
-	client, err := ctl.NewInSecureDKVClient(dkvAddr)
+	client, err := newClient(dkvAddr)
 	if err != nil {
-		fmt.Printf("Unable to create DKV client. Error: %v\n", err)
+		printErr("Unable to create DKV client. Error: %v\n", err)
+		os.Exit(exitCode)
 	}
-	defer client.Close()
 
 	for _, c := range cmds {
 		if c.value != "" {
@@ -136,4 +1258,6 @@ func main() {
 			break
 		}
 	}
+	client.Close()
+	os.Exit(exitCode)
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage/badger"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/migrate"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/rocksdb"
+	"go.uber.org/zap"
+)
+
+const cacheSize = 1 << 30
+
+var (
+	srcEngine  string
+	srcFolder  string
+	dstEngine  string
+	dstFolder  string
+	batchSize  int
+	sampleRate int
+	verbose    bool
+)
+
+func init() {
+	flag.StringVar(&srcEngine, "srcEngine", "rocksdb", "Storage engine of the DKV node being migrated - badger|rocksdb")
+	flag.StringVar(&srcFolder, "srcFolder", "", "DB folder of the DKV node being migrated, as per its 'dbFolder', which is opened read only")
+	flag.StringVar(&dstEngine, "dstEngine", "badger", "Storage engine that the keyspace is migrated onto - badger|rocksdb")
+	flag.StringVar(&dstFolder, "dstFolder", "", "DB folder that the keyspace is migrated onto, which the DKV node then serves as its 'dbFolder'")
+	flag.IntVar(&batchSize, "batchSize", migrate.DefaultBatchSize, "Number of keys copied together")
+	flag.IntVar(&sampleRate, "sampleRate", migrate.DefaultSampleRate, "Verify one in every these many keys using their checksums, 1 for verifying every key")
+	flag.BoolVar(&verbose, "verbose", false, "Log the progress of every batch")
+}
+
+func main() {
+	flag.Parse()
+	if srcFolder == "" || dstFolder == "" {
+		usage("Both 'srcFolder' and 'dstFolder' must be given.")
+	}
+	if absSrc, absDst := absPath(srcFolder), absPath(dstFolder); absSrc == absDst {
+		usage("'srcFolder' and 'dstFolder' must differ.")
+	}
+
+	lgr := newLogger()
+	defer lgr.Sync()
+	if err := run(lgr); err != nil {
+		lgr.Error("Unable to migrate the keyspace, run again for resuming the migration", zap.Error(err))
+		lgr.Sync()
+		os.Exit(1)
+	}
+}
+
+func run(lgr *zap.Logger) error {
+	src, err := openStore(srcEngine, srcFolder, true, lgr)
+	if err != nil {
+		return fmt.Errorf("unable to open the source engine: %w", err)
+	}
+	defer src.Close()
+	if err = os.MkdirAll(dstFolder, 0777); err != nil {
+		return err
+	}
+	dst, err := openStore(dstEngine, dstFolder, false, lgr)
+	if err != nil {
+		return fmt.Errorf("unable to open the destination engine: %w", err)
+	}
+	defer dst.Close()
+
+	lgr.Info("Migrating the keyspace", zap.String("srcEngine", srcEngine), zap.String("srcFolder", srcFolder), zap.String("dstEngine", dstEngine), zap.String("dstFolder", dstFolder))
+	summ, err := migrate.Migrate(src, dst, dstEngine, dstFolder, migrate.WithBatchSize(batchSize), migrate.WithSampleRate(sampleRate), migrate.WithLogger(lgr))
+	if err != nil {
+		return err
+	}
+	lgr.Info("Migrated the keyspace", zap.Uint64("numKeys", summ.NumKeys), zap.Uint64("numVerifiedKeys", summ.NumVerifiedKeys),
+		zap.Uint64("numSampledKeys", summ.NumSampledKeys), zap.String("checksum", summ.Checksum), zap.Uint64("changeNumber", summ.ChangeNumber))
+	return nil
+}
+
+// openStore opens the given engine within the given DB folder as per
+// the layout of the DKV nodes.
+func openStore(engine, folder string, readOnly bool, lgr *zap.Logger) (migrate.Store, error) {
+	dbDir := path.Join(folder, "data")
+	switch engine {
+	case "rocksdb":
+		return rocksdb.Open(rocksdb.NewOptions().CreateDBFolderIfMissing(!readOnly).ReadOnly(readOnly).DBFolder(dbDir).CacheSize(cacheSize).Logger(lgr.Named("rocksdb")))
+	case "badger":
+		return badger.Open(badger.NewOptions(dbDir).ReadOnly(readOnly).Logger(lgr.Named("badger")))
+	default:
+		return nil, fmt.Errorf("unknown storage engine: %s", engine)
+	}
+}
+
+func absPath(folder string) string {
+	if abs, err := filepath.Abs(folder); err == nil {
+		return filepath.Clean(abs)
+	}
+	return folder
+}
+
+func newLogger() *zap.Logger {
+	lvl := zap.InfoLevel
+	if verbose {
+		lvl = zap.DebugLevel
+	}
+	cfg := zap.NewDevelopmentConfig()
+	cfg.Level = zap.NewAtomicLevelAt(lvl)
+	cfg.DisableStacktrace = true
+	lgr, err := cfg.Build()
+	if err != nil {
+		panic(err)
+	}
+	return lgr
+}
+
+func usage(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	flag.Usage()
+	os.Exit(2)
+}
@@ -1,111 +1,708 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"expvar"
 	"flag"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/metrics"
+	"github.com/flipkart-incubator/dkv/internal/server/backup"
+	"github.com/flipkart-incubator/dkv/internal/server/config"
+	"github.com/flipkart-incubator/dkv/internal/server/gateway"
+	"github.com/flipkart-incubator/dkv/internal/server/info"
+	"github.com/flipkart-incubator/dkv/internal/server/maintenance"
 	"github.com/flipkart-incubator/dkv/internal/server/master"
+	"github.com/flipkart-incubator/dkv/internal/server/ratelimit"
+	"github.com/flipkart-incubator/dkv/internal/server/resp"
+	"github.com/flipkart-incubator/dkv/internal/server/security"
 	"github.com/flipkart-incubator/dkv/internal/server/slave"
 	"github.com/flipkart-incubator/dkv/internal/server/storage"
 	"github.com/flipkart-incubator/dkv/internal/server/storage/badger"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/migrate"
 	"github.com/flipkart-incubator/dkv/internal/server/storage/rocksdb"
 	"github.com/flipkart-incubator/dkv/internal/server/sync"
+	"github.com/flipkart-incubator/dkv/internal/tracing"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/flipkart-incubator/dkv/version"
 	nexus_api "github.com/flipkart-incubator/nexus/pkg/api"
 	nexus "github.com/flipkart-incubator/nexus/pkg/raft"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
 )
 
 var (
-	dbEngine         string
-	dbFolder         string
-	dbListenAddr     string
-	dbRole           string
-	replMasterAddr   string
-	replPollInterval uint
+	// Settings of this node, loaded from the config file if any and
+	// overridden by the flags bound to them
+	cfg = config.Default()
+	// Flags bound to the settings of the config
+	configFlags = flag.NewFlagSet("config", flag.ExitOnError)
+
+	configFile     string
+	validateConfig bool
+
+	dbClusterAddrs string
+	dbDrainTimeout time.Duration
+
+	backupStagingDir, backupS3Endpoint, backupS3Region string
+	backupS3PathStyle                                  bool
+
+	traceExporter, traceOTLPEndpoint string
+	traceSampleRatio                 float64
+
+	logLevel, logFormat string
+	lgr                 *zap.Logger
 
 	nexusLogDirFlag, nexusSnapDirFlag *flag.Flag
 )
 
 func init() {
-	flag.StringVar(&dbFolder, "dbFolder", "/tmp/dkvsrv", "DB folder path for storing data files")
-	flag.StringVar(&dbListenAddr, "dbListenAddr", "127.0.0.1:8080", "Address on which the DKV service binds")
-	flag.StringVar(&dbEngine, "dbEngine", "rocksdb", "Underlying DB engine for storing data - badger|rocksdb")
-	flag.StringVar(&dbRole, "dbRole", "none", "DB role of this node - none|master|slave")
-	flag.StringVar(&replMasterAddr, "replMasterAddr", "", "Service address of DKV master node for replication")
-	flag.UintVar(&replPollInterval, "replPollInterval", 5, "Interval (in seconds) used by the replication poller of this node")
+	bindConfigFlags(configFlags, cfg)
+	configFlags.VisitAll(func(f *flag.Flag) {
+		flag.Var(f.Value, f.Name, f.Usage)
+	})
+	flag.StringVar(&configFile, "config", "", "YAML file of the settings of this node, which the flags given explicitly override")
+	flag.BoolVar(&validateConfig, "validateConfig", false, "Validate the settings of this node, including those of the config file, and exit")
+	flag.StringVar(&dbClusterAddrs, "dbClusterAddrs", "", "Comma separated service addresses of the DKV nodes of the Nexus cluster, in the order of -nexusClusterUrl, used for hinting the leader to clients")
+	flag.DurationVar(&dbDrainTimeout, "dbDrainTimeout", 30*time.Second, "Time given to the requests in flight to complete upon shutdown, after which they are cancelled")
+	flag.StringVar(&backupStagingDir, "backupStagingDir", "", "Folder where backups are staged while transferred to or from object storage, defaults to the temporary folder of the OS")
+	flag.StringVar(&backupS3Endpoint, "backupS3Endpoint", "", "Endpoint of the S3 compatible object storage used for s3:// backups, defaults to that of AWS")
+	flag.StringVar(&backupS3Region, "backupS3Region", "", "Region of the S3 buckets used for s3:// backups, defaults to us-east-1")
+	flag.BoolVar(&backupS3PathStyle, "backupS3PathStyle", false, "Address the S3 buckets in the path rather than the host name, as required by MinIO")
+	flag.StringVar(&traceExporter, "traceExporter", "none", "Exporter of the OpenTelemetry spans traced by this node - none|stdout|otlp")
+	flag.StringVar(&traceOTLPEndpoint, "traceOTLPEndpoint", "localhost:55680", "Address of the OTLP collector onto which the spans are exported over GRPC")
+	flag.Float64Var(&traceSampleRatio, "traceSampleRatio", 1, "Ratio of the traces begun by this node that are sampled, while those sampled by the callers are always sampled")
+	flag.StringVar(&logLevel, "logLevel", "info", "Minimum level of the logs written by this node - debug|info|warn|error")
+	flag.StringVar(&logFormat, "logFormat", "console", "Format of the logs written by this node - console|json")
 	initFlagsForNexusDirs()
 }
 
+// bindConfigFlags defines the flags of the settings of the given config
+// onto the given flag set, defaulting to the current settings.
+func bindConfigFlags(fs *flag.FlagSet, cfg *config.Config) {
+	fs.StringVar(&cfg.Storage.Folder, "dbFolder", cfg.Storage.Folder, "DB folder path for storing data files")
+	fs.StringVar(&cfg.Listen.GRPC, "dbListenAddr", cfg.Listen.GRPC, "Address on which the DKV service binds")
+	fs.StringVar(&cfg.Storage.Engine, "dbEngine", cfg.Storage.Engine, "Underlying DB engine for storing data - badger|memory|rocksdb")
+	fs.StringVar(&cfg.Role, "dbRole", cfg.Role, "DB role of this node - none|standalone|master|slave, where standalone serves its changes to slave nodes without Nexus")
+	fs.IntVar(&cfg.Limits.MaxKeySize, "dbMaxKeySize", cfg.Limits.MaxKeySize, "Maximum size (in bytes) of the keys accepted by this node, 0 for no limit")
+	fs.IntVar(&cfg.Limits.MaxValueSize, "dbMaxValueSize", cfg.Limits.MaxValueSize, "Maximum size (in bytes) of the values accepted by this node, 0 for no limit")
+	fs.StringVar(&cfg.Storage.KeyPolicy.Pattern, "dbKeyPattern", cfg.Storage.KeyPolicy.Pattern, "Regular expression that the keys mutated through this node must match, anchored with ^ and $ for matching keys as a whole")
+	fs.BoolVar(&cfg.Storage.KeyPolicy.UTF8, "dbKeyUTF8", cfg.Storage.KeyPolicy.UTF8, "Reject the mutations of keys that are not valid UTF-8 strings")
+	fs.BoolVar(&cfg.Storage.KeyPolicy.RejectEmpty, "dbRejectEmptyKeys", cfg.Storage.KeyPolicy.RejectEmpty, "Reject the mutations of empty keys")
+	fs.IntVar(&cfg.Limits.MaxRecvMsgSize, "dbMaxRecvMsgSize", cfg.Limits.MaxRecvMsgSize, "Maximum size (in bytes) of the GRPC messages received by this node, including the responses of its DKV master node")
+	fs.IntVar(&cfg.Limits.MaxSendMsgSize, "dbMaxSendMsgSize", cfg.Limits.MaxSendMsgSize, "Maximum size (in bytes) of the GRPC messages sent by this node, which limits the batches of changes it serves")
+	fs.Uint64Var(&cfg.Limits.MaxChangesSize, "dbMaxChangesSize", cfg.Limits.MaxChangesSize, "Maximum size (in bytes) of the changes served by this node per batch, which bounds its memory while slaves catch up")
+	fs.StringVar(&cfg.Storage.Compression, "dbCompression", cfg.Storage.Compression, "Codec used for compressing the values stored by this node - none|snappy|zstd")
+	fs.StringVar(&cfg.Metrics.Addr, "dbMetricsAddr", cfg.Metrics.Addr, "Address on which the metrics of this node are served over HTTP at /metrics in the Prometheus format")
+	fs.BoolVar(&cfg.Storage.VerifyOnStart, "dbVerifyOnStart", cfg.Storage.VerifyOnStart, "Verify the checksums of the files of the storage engine along with its change numbers before serving, failing to start if corrupted")
+	fs.BoolVar(&cfg.Storage.Repair, "dbRepair", cfg.Storage.Repair, "Repair the storage engine before opening it, such as after an unclean shutdown, logging the files fixed")
+	fs.BoolVar(&cfg.Storage.BulkLoad, "dbBulkLoad", cfg.Storage.BulkLoad, "Accept bulk loads that bypass the change log onto this standalone node, forcing its slave nodes to bootstrap again afterwards")
+	fs.BoolVar(&cfg.Storage.AsyncPuts, "dbAsyncPuts", cfg.Storage.AsyncPuts, "Acknowledge the puts onto this standalone node before syncing them onto the disk, unless they request otherwise, losing them upon a crash of the node")
+	fs.IntVar(&cfg.Storage.RestoreParallelism, "dbRestoreParallelism", cfg.Storage.RestoreParallelism, "Number of workers ingesting the backups restored onto this node at once, where the storage engine allows")
+	fs.DurationVar(&cfg.Storage.ChangeLog.MaxAge, "dbChangeLogMaxAge", cfg.Storage.ChangeLog.MaxAge, "Duration for which the changes of this master node are retained for replication, like 24h, 0 for no limit")
+	fs.Uint64Var(&cfg.Storage.ChangeLog.MaxChanges, "dbChangeLogMaxChanges", cfg.Storage.ChangeLog.MaxChanges, "Number of latest changes of this master node retained for replication, 0 for no limit")
+	fs.BoolVar(&cfg.Storage.ChangeLog.RetainUnconsumed, "dbChangeLogRetainUnconsumed", cfg.Storage.ChangeLog.RetainUnconsumed, "Retain the changes of this master node yet to be consumed by its slave nodes, beyond 'dbChangeLogMaxAge' and 'dbChangeLogMaxChanges', unsupported by rocksdb")
+	fs.DurationVar(&cfg.Storage.QuotaReconcileInterval, "dbQuotaReconcileInterval", cfg.Storage.QuotaReconcileInterval, "Interval at which the usage of the namespaces with quotas on this standalone or master node is reconciled by iterating over their keys")
+	fs.DurationVar(&cfg.Storage.TombstoneRetention, "dbTombstoneRetention", cfg.Storage.TombstoneRetention, "Duration for which the keys deleted through this node are retained under tombstones, restorable through Undelete, like 24h, 0 for deleting them outright")
+	fs.StringVar(&cfg.Listen.HTTP, "dbHTTPAddr", cfg.Listen.HTTP, "Address on which the DKV service is served over HTTP with JSON at /v1, as per the TLS and auth flags")
+	fs.StringVar(&cfg.Listen.Redis, "dbRedisAddr", cfg.Listen.Redis, "Address on which the GET, SET, MGET, DEL and EXISTS commands are served over the Redis protocol, as per the TLS and auth flags")
+	fs.Var(&listFlag{&cfg.Replication.MasterAddrs, ","}, "replMasterAddr", "Comma separated service addresses of candidate DKV master nodes for replication")
+	fs.Var((*durationOrSecs)(&cfg.Replication.PollInterval), "replPollInterval", "Interval used by the replication poller of this node, like 250ms, with plain numbers taken as seconds")
+	fs.UintVar(&cfg.Replication.BatchSize, "replBatchSize", cfg.Replication.BatchSize, "Maximum number of changes replicated from DKV master node in a single batch")
+	fs.Uint64Var(&cfg.Replication.BatchBytes, "replBatchBytes", cfg.Replication.BatchBytes, "Maximum size (in bytes) of changes replicated from DKV master node in a single batch, 0 for no limit")
+	fs.StringVar(&cfg.Replication.KeyPrefix, "replKeyPrefix", cfg.Replication.KeyPrefix, "Prefix of the keys replicated from DKV master node, with the changes on all the other keys skipped by the master")
+	fs.StringVar(&cfg.Replication.Namespace, "replNamespace", cfg.Replication.Namespace, "Namespace of the keys replicated from DKV master node, within which 'replKeyPrefix' applies")
+	fs.DurationVar(&cfg.Replication.MaxApplyLatency, "replMaxApplyLatency", cfg.Replication.MaxApplyLatency, "Average time for applying a batch of replicated changes, like 500ms, beyond which the replication is throttled, 0 for no throttling")
+	fs.DurationVar(&cfg.Replication.KeepaliveTime, "replKeepaliveTime", cfg.Replication.KeepaliveTime, "Duration of inactivity after which the connection to DKV master node is pinged, detecting silently dropped connections, 0 for no pings")
+	fs.StringVar(&cfg.Replication.TLS.CertFile, "replTLSCertFile", cfg.Replication.TLS.CertFile, "Client certificate file used for mutual TLS with the DKV master node")
+	fs.StringVar(&cfg.Replication.TLS.KeyFile, "replTLSKeyFile", cfg.Replication.TLS.KeyFile, "Client private key file used for mutual TLS with the DKV master node")
+	fs.StringVar(&cfg.Replication.TLS.CAFile, "replTLSCAFile", cfg.Replication.TLS.CAFile, "CA certificate file used for verifying the DKV master node over TLS")
+	fs.Uint64Var(&cfg.Replication.HealthMaxLag, "replHealthMaxLag", cfg.Replication.HealthMaxLag, "Maximum replication lag (in changes) beyond which this node is reported unhealthy, 0 for no limit")
+	fs.Var((*durationOrSecs)(&cfg.Replication.HealthMaxFailTime), "replHealthMaxFailSecs", "Maximum duration for which replication can fail before this node is reported unhealthy, like 90s, with plain numbers taken as seconds, 0 for no limit")
+	fs.StringVar(&cfg.Metrics.ReplicationStatsAddr, "replStatsAddr", cfg.Metrics.ReplicationStatsAddr, "Address on which the replication status of this node is served over HTTP at /debug/vars")
+	fs.StringVar(&cfg.Replication.AuthToken, "replAuthToken", cfg.Replication.AuthToken, "Bearer token, with the replication scope, presented to the DKV master node")
+	fs.StringVar(&cfg.Replication.ReplicaID, "replReplicaID", cfg.Replication.ReplicaID, "ID identifying this node to the DKV master node, which tracks the changes it consumed, defaults to 'dbListenAddr'")
+	fs.StringVar(&cfg.TLS.CertFile, "tlsCertFile", cfg.TLS.CertFile, "Certificate file used for serving DKV over TLS")
+	fs.StringVar(&cfg.TLS.KeyFile, "tlsKeyFile", cfg.TLS.KeyFile, "Private key file used for serving DKV over TLS")
+	fs.StringVar(&cfg.TLS.CAFile, "tlsCAFile", cfg.TLS.CAFile, "CA certificate file used for verifying clients over mutual TLS")
+	fs.Var(&listFlag{&cfg.Auth.Tokens, ";"}, "authTokens", "Semicolon separated bearer tokens accepted by this node, each as <token>:<scope>[,<scope>...] with scopes among read|write|replication|admin")
+	fs.StringVar(&cfg.Auth.TokenFile, "authTokenFile", cfg.Auth.TokenFile, "File of bearer tokens accepted by this node, one <token>:<scope>[,<scope>...] per line, reloaded when modified")
+	fs.Float64Var(&cfg.Limits.MethodRate, "limitMethodRate", cfg.Limits.MethodRate, "Number of calls of every GRPC method admitted per second, 0 for no limit")
+	fs.IntVar(&cfg.Limits.MethodBurst, "limitMethodBurst", cfg.Limits.MethodBurst, "Number of calls of every GRPC method admitted at once beyond 'limitMethodRate', defaults to the rate")
+	fs.Float64Var(&cfg.Limits.TokenRate, "limitTokenRate", cfg.Limits.TokenRate, "Number of GRPC calls carrying every bearer token admitted per second, 0 for no limit")
+	fs.IntVar(&cfg.Limits.TokenBurst, "limitTokenBurst", cfg.Limits.TokenBurst, "Number of GRPC calls carrying every bearer token admitted at once beyond 'limitTokenRate', defaults to the rate")
+	fs.Float64Var(&cfg.Limits.ReplicationRate, "limitReplRate", cfg.Limits.ReplicationRate, "Number of calls of the replication methods admitted per second, which are exempt from the other limits, 0 for no limit")
+	fs.IntVar(&cfg.Limits.ReplicationBurst, "limitReplBurst", cfg.Limits.ReplicationBurst, "Number of calls of the replication methods admitted at once beyond 'limitReplRate', defaults to the rate")
+	fs.IntVar(&cfg.Limits.MaxInFlight, "limitMaxInFlight", cfg.Limits.MaxInFlight, "Maximum number of GRPC calls, other than those of the replication methods, served concurrently, 0 for no limit")
+}
+
+// loadConfig loads the config file given through the config flag, if
+// any, overriding its settings with the flags given explicitly, and
+// validates the resulting config. With the validateConfig flag, the
+// outcome of the validation is printed before exiting.
+func loadConfig() {
+	err := mergeConfigFile()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if validateConfig {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println("Config is valid.")
+		os.Exit(0)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load the config. Error: %v", err))
+	}
+}
+
+// mergeConfigFile replaces the settings of the config with those of
+// the config file, other than the settings whose flags are given.
+func mergeConfigFile() error {
+	if configFile == "" {
+		return nil
+	}
+	fileCfg, err := config.Load(configFile)
+	if err != nil {
+		return err
+	}
+	explicitFlags := make(map[string]string)
+	flag.Visit(func(f *flag.Flag) {
+		if configFlags.Lookup(f.Name) != nil {
+			explicitFlags[f.Name] = f.Value.String()
+		}
+	})
+	// Flags remain bound to the settings, hence they are given again
+	*cfg = *fileCfg
+	for name, value := range explicitFlags {
+		if err = flag.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type dkvSrvrRole string
 
 const (
-	noRole     dkvSrvrRole = "none"
-	masterRole             = "master"
-	slaveRole              = "slave"
+	noRole         dkvSrvrRole = "none"
+	standaloneRole             = "standalone"
+	masterRole                 = "master"
+	slaveRole                  = "slave"
 )
 
 func main() {
 	flag.Parse()
+	loadConfig()
 	setFlagsForNexusDirs()
+	lgr = newLogger()
+	defer lgr.Sync()
+	stopTracing, err := tracing.Setup(tracing.Config{Exporter: traceExporter, Endpoint: traceOTLPEndpoint, SampleRatio: traceSampleRatio, ServiceName: "dkvsrv"})
+	if err != nil {
+		panic(fmt.Sprintf("Unable to setup tracing. Error: %v", err))
+	}
+	defer stopTracing()
 
 	kvs, cp, ca, br := newKVStore()
-	grpcSrvr, lstnr := newGrpcServerListener()
-	defer grpcSrvr.GracefulStop()
-	srvrRole := toDKVSrvrRole(dbRole)
+	srvrRole := toDKVSrvrRole(cfg.Role)
+	// Quotas are enforced by the nodes other than the slaves
+	var quotas *storage.Quotas
+	if srvrRole == noRole || srvrRole == standaloneRole || srvrRole == masterRole {
+		if quotas, err = storage.NewQuotas(kvs, cfg.Storage.QuotaReconcileInterval, lgr.Named("quotas")); err != nil {
+			panic(err)
+		}
+		quotas.Start()
+		defer quotas.Stop()
+	}
+	// Served by the store itself, whose optional capabilities are looked up
+	storSrvr := storage.NewStorageServer(kvs, storage.WithQuotas(quotas))
+	kvs = metrics.NewKVStore(kvs)
+	srvrTLS := newServerTLS()
+	auth := newTokenAuthenticator()
+	limiter := newLimiter(auth)
+	drainCtx, startDrain := context.WithCancel(context.Background())
+	grpcSrvr, lstnr := newGrpcServerListener(srvrTLS, auth, limiter, drainCtx)
+	serverpb.RegisterDKVLimitsServer(grpcSrvr, limiter)
+	reloader := security.NewReloader(srvrTLS, auth, lgr.Named("security"))
+	serverpb.RegisterDKVSecurityServer(grpcSrvr, reloader)
+	reloadOnHangup(reloader)
+	serverpb.RegisterDKVStorageServer(grpcSrvr, storSrvr)
+	// Lets tools like grpcurl discover the services registered
+	reflection.Register(grpcSrvr)
 	srvrRole.printFlags()
 
+	bckpTrnsfr := newBackupTransfer()
+	ssOpts := []master.DKVServiceOption{master.WithConfig(cfg), master.WithBackupTransfer(bckpTrnsfr), master.WithLogger(lgr.Named("master"))}
+	if quotas != nil {
+		ssOpts = append(ssOpts, master.WithQuotas(quotas))
+	}
+
+	// Maintenance mode of the writable nodes, retained across restarts
+	var maintMode *maintenance.Mode
+	if srvrRole == noRole || srvrRole == standaloneRole || srvrRole == masterRole {
+		if maintMode, err = maintenance.NewMode(cfg.Storage.Folder); err != nil {
+			panic(err)
+		}
+		if inMaint, reason := maintMode.Status(); inMaint {
+			lgr.Warn("Node is in maintenance mode, rejecting keyspace mutations", zap.String("reason", reason))
+		}
+		ssOpts = append(ssOpts, master.WithMaintenanceMode(maintMode))
+		serverpb.RegisterDKVMaintenanceServer(grpcSrvr, maintenance.NewServer(maintMode, lgr.Named("maintenance")))
+	}
+
+	// Service served over HTTP and the Redis protocol alongside GRPC
+	var httpSvc serverpb.DKVServer
+	// Service shutdown once all of its servers are drained
+	var svc interface{ Shutdown(context.Context) error }
 	switch srvrRole {
 	case noRole:
-		dkvSvc := master.NewStandaloneService(kvs, nil, br)
-		defer dkvSvc.Close()
+		dkvSvc := master.NewStandaloneService(kvs, nil, br, ssOpts...)
 		serverpb.RegisterDKVServer(grpcSrvr, dkvSvc)
 		serverpb.RegisterDKVBackupRestoreServer(grpcSrvr, dkvSvc)
-	case masterRole:
+		grpc_health_v1.RegisterHealthServer(grpcSrvr, dkvSvc)
+		serverpb.RegisterDKVInfoServer(grpcSrvr, newInfoServer(fixedRole(serverpb.ServerRole_Standalone), maintMode))
+		httpSvc, svc = dkvSvc, dkvSvc
+	case standaloneRole, masterRole:
 		if cp == nil {
-			panic(fmt.Sprintf("Storage engine %s is not supported for DKV master role.", dbEngine))
+			panic(fmt.Sprintf("Storage engine %s is not supported for DKV %s role.", cfg.Storage.Engine, srvrRole))
+		}
+		if srvrRole == standaloneRole && haveFlagsWithPrefix("nexus") {
+			panic("Nexus flags are not supported for DKV standalone role, which does not replicate over Nexus. Use the master role instead.")
 		}
 		var dkvSvc master.DKVService
 		if haveFlagsWithPrefix("nexus") {
-			dkvSvc = master.NewDistributedService(kvs, cp, br, newDKVReplicator(kvs))
+			dkvSvc = master.NewDistributedService(kvs, cp, br, newDKVReplicator(kvs), master.WithConfig(cfg), master.WithBackupTransfer(bckpTrnsfr), master.WithMaintenanceMode(maintMode), master.WithQuotas(quotas), master.WithLogger(lgr.Named("master")), newClusterNodesOption(), newClusterAddrsOption(), master.WithMemberDialer(newReplicationClient))
 			serverpb.RegisterDKVClusterServer(grpcSrvr, dkvSvc.(master.DKVClusterService))
 		} else {
-			dkvSvc = master.NewStandaloneService(kvs, cp, br)
+			dkvSvc = master.NewStandaloneService(kvs, cp, br, ssOpts...)
 			serverpb.RegisterDKVBackupRestoreServer(grpcSrvr, dkvSvc)
+			serverpb.RegisterDKVClusterServer(grpcSrvr, master.NewStandaloneClusterServer())
 		}
-		defer dkvSvc.Close()
 		serverpb.RegisterDKVServer(grpcSrvr, dkvSvc)
 		serverpb.RegisterDKVReplicationServer(grpcSrvr, dkvSvc)
+		grpc_health_v1.RegisterHealthServer(grpcSrvr, dkvSvc)
+		serverpb.RegisterDKVInfoServer(grpcSrvr, newInfoServer(fixedRole(serverpb.ServerRole_Master), maintMode))
+		httpSvc, svc = dkvSvc, dkvSvc
 	case slaveRole:
-		if replCli, err := ctl.NewInSecureDKVClient(replMasterAddr); err != nil {
+		if replClis, err := newReplicationClients(); err != nil {
 			panic(err)
 		} else {
-			defer replCli.Close()
-			dkvSvc, _ := slave.NewService(kvs, ca, replCli, replPollInterval)
-			defer dkvSvc.Close()
+			dkvSvc, err := slave.NewService(kvs, metrics.NewChangeApplier(ca), replClis, cfg.Replication.PollInterval, uint32(cfg.Replication.BatchSize), cfg.Replication.BatchBytes, slave.WithConfig(cfg), slave.WithChangeLog(cp), slave.WithLogger(lgr.Named("slave")))
+			if err != nil {
+				panic(err)
+			}
 			serverpb.RegisterDKVServer(grpcSrvr, dkvSvc)
+			serverpb.RegisterDKVReplicationStatusServer(grpcSrvr, dkvSvc)
+			serverpb.RegisterDKVReplicationServer(grpcSrvr, slave.ReplicationServer(dkvSvc))
+			serverpb.RegisterDKVFailoverServer(grpcSrvr, dkvSvc)
+			serverpb.RegisterDKVReplicationControlServer(grpcSrvr, dkvSvc)
+			grpc_health_v1.RegisterHealthServer(grpcSrvr, dkvSvc)
+			serverpb.RegisterDKVInfoServer(grpcSrvr, newInfoServer(dkvSvc.Role, nil))
+			serveReplicationStats(dkvSvc)
+			httpSvc, svc = dkvSvc, dkvSvc
 		}
 	default:
-		panic("Invalid 'dbRole'. Allowed values are none|master|slave.")
+		panic("Invalid 'dbRole'. Allowed values are none|standalone|master|slave.")
 	}
+	serveMetrics()
+	httpSrvr := serveHTTP(httpSvc, srvrTLS, auth)
+	redisSrvr := serveRedis(httpSvc, srvrTLS, auth)
 	go grpcSrvr.Serve(lstnr)
 	sig := <-setupSignalHandler()
-	fmt.Printf("[WARN] Caught signal: %v. Shutting down...\n", sig)
+	lgr.Warn("Caught signal. Shutting down...", zap.Stringer("signal", sig))
+	shutdownHTTP(httpSrvr)
+	if redisSrvr != nil {
+		redisSrvr.Close()
+	}
+	startDrain()
+	drainGrpc(grpcSrvr)
+	shutdownService(svc)
 }
 
-func newGrpcServerListener() (*grpc.Server, net.Listener) {
-	return grpc.NewServer(), newListener()
+// Modules of the storage engines whose versions are reported by
+// GetServerInfo, with the rest reporting the version of DKV itself.
+var storageEngineModules = map[string]string{
+	"rocksdb": "github.com/tecbot/gorocksdb",
+	"badger":  "github.com/dgraph-io/badger",
+}
+
+// newInfoServer creates the server describing this node through
+// GetServerInfo, in the role reported by the given function and along
+// with its maintenance mode, if any.
+func newInfoServer(role info.RoleFunc, maintMode *maintenance.Mode) serverpb.DKVInfoServer {
+	engineVersion := version.Version
+	if module, present := storageEngineModules[cfg.Storage.Engine]; present {
+		engineVersion = version.ModuleVersion(module)
+	}
+	listenAddrs := map[string]string{"grpc": cfg.Listen.GRPC}
+	for protocol, addr := range map[string]string{"http": cfg.Listen.HTTP, "redis": cfg.Listen.Redis, "metrics": cfg.Metrics.Addr, "replStats": cfg.Metrics.ReplicationStatsAddr} {
+		if addr != "" {
+			listenAddrs[protocol] = addr
+		}
+	}
+	return info.NewServer(role, info.ServerInfo{StorageEngine: cfg.Storage.Engine, StorageEngineVersion: engineVersion, ListenAddrs: listenAddrs, Maintenance: maintMode})
+}
+
+func fixedRole(role serverpb.ServerRole) info.RoleFunc {
+	return func() serverpb.ServerRole { return role }
+}
+
+// drainGrpc stops the given GRPC server once the requests in flight
+// complete, and cancels those that do not within the drain timeout.
+func drainGrpc(grpcSrvr *grpc.Server) {
+	drained := make(chan struct{})
+	go func() {
+		grpcSrvr.GracefulStop()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(dbDrainTimeout):
+		lgr.Warn("Unable to drain the GRPC server in time, cancelling the requests in flight", zap.Duration("drainTimeout", dbDrainTimeout))
+		grpcSrvr.Stop()
+	}
+}
+
+// shutdownService shuts down the given service, which persists the
+// writes buffered by its storage, waiting atmost for the drain timeout.
+func shutdownService(svc interface{ Shutdown(context.Context) error }) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbDrainTimeout)
+	defer cancel()
+	if err := svc.Shutdown(ctx); err != nil {
+		lgr.Error("Unable to shutdown the DKV service gracefully", zap.Error(err))
+	}
+}
+
+// serveHTTP serves the given DKV service over HTTP when its listen
+// address is configured, over TLS and with the given authentication if any,
+// same as the GRPC services. Returns nil when not served.
+func serveHTTP(dkvSvc serverpb.DKVServer, srvrTLS *security.ReloadableTLS, auth *security.TokenAuthenticator) *http.Server {
+	if cfg.Listen.HTTP == "" {
+		return nil
+	}
+	var gwOpts []gateway.Option
+	if auth != nil {
+		gwOpts = append(gwOpts, gateway.WithAuthorizer(auth))
+	}
+	httpSrvr := &http.Server{Handler: gateway.NewHandler(dkvSvc, gwOpts...)}
+	lis := newFrontEndListener(cfg.Listen.HTTP, srvrTLS)
+	go func() {
+		if err := httpSrvr.Serve(lis); err != nil && err != http.ErrServerClosed {
+			lgr.Warn("Unable to serve over HTTP", zap.String("addr", cfg.Listen.HTTP), zap.Error(err))
+		}
+	}()
+	return httpSrvr
+}
+
+// serveRedis serves the given DKV service over the Redis protocol when
+// its listen address is configured, returning its server if so.
+func serveRedis(dkvSvc serverpb.DKVServer, srvrTLS *security.ReloadableTLS, auth *security.TokenAuthenticator) *resp.Server {
+	if cfg.Listen.Redis == "" {
+		return nil
+	}
+	respOpts := []resp.Option{resp.WithLogger(lgr.Named("resp"))}
+	if auth != nil {
+		respOpts = append(respOpts, resp.WithAuthorizer(auth))
+	}
+	redisSrvr := resp.NewServer(dkvSvc, respOpts...)
+	lis := newFrontEndListener(cfg.Listen.Redis, srvrTLS)
+	go func() {
+		if err := redisSrvr.Serve(lis); err != nil && err != resp.ErrServerClosed {
+			lgr.Warn("Unable to serve over the Redis protocol", zap.String("addr", cfg.Listen.Redis), zap.Error(err))
+		}
+	}()
+	return redisSrvr
+}
+
+// newFrontEndListener listens on the given address of a front-end that
+// serves the DKV service besides GRPC, over TLS when GRPC is.
+func newFrontEndListener(addr string, srvrTLS *security.ReloadableTLS) net.Listener {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		panic(fmt.Sprintf("failed to listen: %v", err))
+	}
+	if srvrTLS != nil {
+		lis = tls.NewListener(lis, srvrTLS.Config())
+	}
+	return lis
+}
+
+func shutdownHTTP(httpSrvr *http.Server) {
+	if httpSrvr == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dbDrainTimeout)
+	defer cancel()
+	if err := httpSrvr.Shutdown(ctx); err != nil {
+		lgr.Warn("Unable to shutdown the HTTP server gracefully", zap.Error(err))
+	}
+}
+
+// Method of the streams of changes, which never end by themselves.
+const streamChangesMethod = "/dkv.serverpb.DKVReplication/StreamChanges"
+
+// endChangeStreams returns an interceptor that ends the streams of
+// changes being served once the given context is done, since these
+// streams would otherwise hold up the drain of the GRPC server. Their
+// receivers resume from where they left on another master anyway.
+func endChangeStreams(drainCtx context.Context) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if info.FullMethod != streamChangesMethod {
+			return handler(srv, ss)
+		}
+		ctx, cancel := context.WithCancel(ss.Context())
+		defer cancel()
+		go func() {
+			select {
+			case <-drainCtx.Done():
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		return handler(srv, &serverStream{ss, ctx})
+	}
+}
+
+// serverStream is a GRPC server stream with the given context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+// Minimum interval between the keepalive pings of clients, beyond
+// which their connections are closed
+const minKeepaliveTime = 5 * time.Second
+
+func tracingEnabled() bool {
+	return traceExporter != "" && traceExporter != tracing.NoExporter
+}
+
+func newGrpcServerListener(srvrTLS *security.ReloadableTLS, auth *security.TokenAuthenticator, limiter *ratelimit.Limiter, drainCtx context.Context) (*grpc.Server, net.Listener) {
+	var srvrOpts []grpc.ServerOption
+	if srvrTLS != nil {
+		srvrOpts = append(srvrOpts, grpc.Creds(srvrTLS.Credentials()))
+	}
+	var unaryIntrcptrs []grpc.UnaryServerInterceptor
+	var streamIntrcptrs []grpc.StreamServerInterceptor
+	if tracingEnabled() {
+		unaryIntrcptrs = append(unaryIntrcptrs, tracing.UnaryServerInterceptor())
+		streamIntrcptrs = append(streamIntrcptrs, tracing.StreamServerInterceptor())
+	}
+	unaryIntrcptrs = append(unaryIntrcptrs, metrics.UnaryServerInterceptor())
+	streamIntrcptrs = append(streamIntrcptrs, metrics.StreamServerInterceptor())
+	if auth != nil {
+		unaryIntrcptrs = append(unaryIntrcptrs, auth.UnaryServerInterceptor())
+		streamIntrcptrs = append(streamIntrcptrs, auth.StreamServerInterceptor())
+	}
+	// Calls are limited only once authorized, so that unknown tokens are not tracked
+	unaryIntrcptrs = append(unaryIntrcptrs, limiter.UnaryServerInterceptor())
+	streamIntrcptrs = append(streamIntrcptrs, limiter.StreamServerInterceptor(), endChangeStreams(drainCtx))
+	srvrOpts = append(srvrOpts, grpc.ChainUnaryInterceptor(unaryIntrcptrs...), grpc.ChainStreamInterceptor(streamIntrcptrs...))
+	// Permit the keepalive pings of idle clients, like the slave nodes
+	srvrOpts = append(srvrOpts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: minKeepaliveTime, PermitWithoutStream: true}))
+	srvrOpts = append(srvrOpts, grpc.MaxRecvMsgSize(cfg.Limits.MaxRecvMsgSize), grpc.MaxSendMsgSize(cfg.Limits.MaxSendMsgSize))
+	return grpc.NewServer(srvrOpts...), newListener()
+}
+
+// newLimiter creates the limiter of the GRPC calls as per the limits
+// configured. Limits per token are validated to require the calls to be
+// authorized first.
+func newLimiter(auth *security.TokenAuthenticator) *ratelimit.Limiter {
+	limiter, err := ratelimit.NewLimiter(cfg.Limits.RateLimits())
+	if err != nil {
+		panic(fmt.Sprintf("Invalid limit flags. Error: %v", err))
+	}
+	return limiter
+}
+
+// newServerTLS returns nil when neither the certificate nor the key of
+// TLS is configured, in which case DKV is served without TLS.
+func newServerTLS() *security.ReloadableTLS {
+	if cfg.TLS.CertFile == "" && cfg.TLS.KeyFile == "" {
+		return nil
+	}
+	srvrTLS, err := security.NewReloadableTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.CAFile)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to setup TLS. Error: %v", err))
+	}
+	return srvrTLS
+}
+
+// reloadOnHangup reloads the TLS files and the token file whenever a
+// SIGHUP is caught, same as the ReloadSecurityConfig method, with the
+// outcome logged by the reloader.
+func reloadOnHangup(reloader *security.Reloader) {
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			reloader.Reload()
+		}
+	}()
+}
+
+// Interval at which the token file is checked for modifications.
+const authTokenFileReloadInterval = 10 * time.Second
+
+// newTokenAuthenticator returns nil when neither tokens nor a token
+// file is configured, in which case calls are not authorized at all.
+func newTokenAuthenticator() *security.TokenAuthenticator {
+	switch {
+	case len(cfg.Auth.Tokens) > 0:
+		tokens, err := security.ParseTokens(strings.Join(cfg.Auth.Tokens, ";"))
+		if err != nil {
+			panic(fmt.Sprintf("Invalid 'authTokens'. Error: %v", err))
+		}
+		return security.NewTokenAuthenticator(tokens)
+	case cfg.Auth.TokenFile != "":
+		auth, err := security.NewFileTokenAuthenticator(cfg.Auth.TokenFile, authTokenFileReloadInterval, lgr.Named("auth"))
+		if err != nil {
+			panic(fmt.Sprintf("Unable to load 'authTokenFile'. Error: %v", err))
+		}
+		return auth
+	default:
+		return nil
+	}
+}
+
+// Environment variables holding the HMAC keys used for gcs:// backups,
+// which are kept out of the flags so that they are never printed.
+const (
+	gcsAccessKeyIDEnv     = "DKV_GCS_ACCESS_KEY_ID"
+	gcsSecretAccessKeyEnv = "DKV_GCS_SECRET_ACCESS_KEY"
+)
+
+// newBackupTransfer resolves the credentials of S3 the way the
+// AWS CLI does, i.e., from the environment, the shared credentials
+// file or the instance role of this node.
+func newBackupTransfer() *backup.Transfer {
+	bckpTrnsfr, err := backup.NewTransfer(backup.Config{
+		StagingDir: backupStagingDir,
+		S3:         backup.StoreConfig{Endpoint: backupS3Endpoint, Region: backupS3Region, ForcePathStyle: backupS3PathStyle},
+		GCS:        backup.StoreConfig{AccessKeyID: os.Getenv(gcsAccessKeyIDEnv), SecretAccessKey: os.Getenv(gcsSecretAccessKeyEnv)},
+	}, lgr.Named("backup"))
+	if err != nil {
+		panic(fmt.Sprintf("Unable to setup backups onto object storage. Error: %v", err))
+	}
+	return bckpTrnsfr
+}
+
+func serveMetrics() {
+	if cfg.Metrics.Addr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	go func() {
+		if err := http.ListenAndServe(cfg.Metrics.Addr, mux); err != nil {
+			lgr.Warn("Unable to serve metrics", zap.String("addr", cfg.Metrics.Addr), zap.Error(err))
+		}
+	}()
+}
+
+func serveReplicationStats(dkvSvc slave.DKVService) {
+	expvar.Publish("replication", expvar.Func(func() interface{} {
+		res, _ := dkvSvc.GetStatus(context.Background(), &serverpb.GetStatusRequest{})
+		return res
+	}))
+	if cfg.Metrics.ReplicationStatsAddr != "" {
+		// expvar registers the /debug/vars handler on the default mux
+		go func() {
+			if err := http.ListenAndServe(cfg.Metrics.ReplicationStatsAddr, nil); err != nil {
+				lgr.Warn("Unable to serve replication stats", zap.String("addr", cfg.Metrics.ReplicationStatsAddr), zap.Error(err))
+			}
+		}()
+	}
+}
+
+// newReplicationClients connects to all the candidate masters without
+// waiting for them to be reachable, since the slave fails over onto
+// another candidate when the current one is unreachable.
+func newReplicationClients() ([]*ctl.DKVClient, error) {
+	var replClis []*ctl.DKVClient
+	for _, masterAddr := range cfg.Replication.MasterAddrs {
+		replCli, err := newReplicationClient(masterAddr)
+		if err != nil {
+			for _, cli := range replClis {
+				cli.Close()
+			}
+			return nil, err
+		}
+		replClis = append(replClis, replCli)
+	}
+	return replClis, nil
+}
+
+func newReplicationClient(masterAddr string) (*ctl.DKVClient, error) {
+	replicaID := cfg.Replication.ReplicaID
+	if replicaID == "" {
+		replicaID = cfg.Listen.GRPC
+	}
+	cliOpts := []ctl.DKVClientOption{ctl.WithNonBlockingDial(), ctl.WithReplicaID(replicaID), ctl.WithMaxRecvMsgSize(cfg.Limits.MaxRecvMsgSize), ctl.WithLogger(lgr.Named("ctl").With(zap.String("masterAddr", masterAddr)))}
+	if cfg.Replication.AuthToken != "" {
+		cliOpts = append(cliOpts, ctl.WithAuthToken(cfg.Replication.AuthToken))
+	}
+	if cfg.Replication.KeepaliveTime > 0 {
+		cliOpts = append(cliOpts, ctl.WithKeepalive(cfg.Replication.KeepaliveTime, ctl.ReplicationKeepaliveTimeout, true))
+	}
+	if tracingEnabled() {
+		cliOpts = append(cliOpts, ctl.WithTracing())
+	}
+	if cfg.Replication.TLS.CertFile != "" || cfg.Replication.TLS.KeyFile != "" || cfg.Replication.TLS.CAFile != "" {
+		return ctl.NewTLSDKVClient(masterAddr, cfg.Replication.TLS.CertFile, cfg.Replication.TLS.KeyFile, cfg.Replication.TLS.CAFile, cliOpts...)
+	}
+	return ctl.NewInSecureDKVClient(masterAddr, cliOpts...)
+}
+
+// newLogger builds the root logger of this node from the logLevel
+// and logFormat flags. Every component logs onto a named child of it.
+func newLogger() *zap.Logger {
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(logLevel)); err != nil {
+		panic(fmt.Sprintf("Invalid 'logLevel': %s. Allowed values are debug|info|warn|error.", logLevel))
+	}
+	if logFormat != "console" && logFormat != "json" {
+		panic(fmt.Sprintf("Invalid 'logFormat': %s. Allowed values are console|json.", logFormat))
+	}
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(lvl)
+	cfg.Encoding = logFormat
+	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.Sampling = nil
+	if logFormat == "console" {
+		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	}
+	lgr, err := cfg.Build()
+	if err != nil {
+		panic(err)
+	}
+	return lgr
 }
 
 func newListener() net.Listener {
-	if lis, err := net.Listen("tcp", dbListenAddr); err != nil {
+	if lis, err := net.Listen("tcp", cfg.Listen.GRPC); err != nil {
 		panic(fmt.Sprintf("failed to listen: %v", err))
 	} else {
 		return lis
@@ -119,6 +716,52 @@ func setupSignalHandler() <-chan os.Signal {
 	return stopChan
 }
 
+// durationOrSecs is a duration flag that also accepts plain numbers
+// as seconds, which retains the compatibility of flags that used to be
+// given in seconds.
+type durationOrSecs time.Duration
+
+func (d *durationOrSecs) String() string {
+	return time.Duration(*d).String()
+}
+
+func (d *durationOrSecs) Set(value string) error {
+	if secs, err := strconv.ParseUint(value, 10, 64); err == nil {
+		*d = durationOrSecs(time.Duration(secs) * time.Second)
+		return nil
+	}
+	dur, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = durationOrSecs(dur)
+	return nil
+}
+
+// listFlag is a flag of a list of values, which are given separated by
+// the separator.
+type listFlag struct {
+	values *[]string
+	sep    string
+}
+
+func (lf *listFlag) String() string {
+	if lf.values == nil {
+		return ""
+	}
+	return strings.Join(*lf.values, lf.sep)
+}
+
+func (lf *listFlag) Set(value string) error {
+	*lf.values = nil
+	for _, v := range strings.Split(value, lf.sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			*lf.values = append(*lf.values, v)
+		}
+	}
+	return nil
+}
+
 func haveFlagsWithPrefix(prefix string) bool {
 	res := false
 	flag.Visit(func(f *flag.Flag) {
@@ -129,33 +772,40 @@ func haveFlagsWithPrefix(prefix string) bool {
 	return res
 }
 
+// Flags whose values are secrets, hence never printed.
+var secretFlags = map[string]bool{"authTokens": true, "replAuthToken": true}
+
 func printFlagsWithPrefix(prefixes ...string) {
 	fmt.Println("Launching DKV server with following flags:")
 	flag.VisitAll(func(f *flag.Flag) {
 		for _, pf := range prefixes {
 			if strings.HasPrefix(f.Name, pf) {
-				fmt.Printf("%s (%s): %v\n", f.Name, f.Usage, f.Value)
+				if secretFlags[f.Name] && f.Value.String() != "" {
+					fmt.Printf("%s (%s): <redacted>\n", f.Name, f.Usage)
+				} else {
+					fmt.Printf("%s (%s): %v\n", f.Name, f.Usage, f.Value)
+				}
 			}
 		}
 	})
 }
 
 func toDKVSrvrRole(role string) dkvSrvrRole {
-	return dkvSrvrRole(strings.TrimSpace(strings.ToLower(dbRole)))
+	return dkvSrvrRole(strings.TrimSpace(strings.ToLower(role)))
 }
 
 func (role dkvSrvrRole) printFlags() {
 	switch role {
 	case noRole:
-		printFlagsWithPrefix("db")
-	case masterRole:
+		printFlagsWithPrefix("db", "log", "tls", "auth", "limit", "backup", "trace")
+	case standaloneRole, masterRole:
 		if haveFlagsWithPrefix("nexus") {
-			printFlagsWithPrefix("db", "nexus")
+			printFlagsWithPrefix("db", "log", "tls", "auth", "limit", "nexus", "trace")
 		} else {
-			printFlagsWithPrefix("db")
+			printFlagsWithPrefix("db", "log", "tls", "auth", "limit", "backup", "trace")
 		}
 	case slaveRole:
-		printFlagsWithPrefix("db", "repl")
+		printFlagsWithPrefix("db", "log", "tls", "auth", "limit", "repl", "trace")
 	}
 }
 
@@ -169,31 +819,90 @@ func initFlagsForNexusDirs() {
 
 func setFlagsForNexusDirs() {
 	if nexusLogDirFlag.Value.String() == "" {
-		nexusLogDirFlag.Value.Set(path.Join(dbFolder, "logs"))
+		nexusLogDirFlag.Value.Set(path.Join(cfg.Storage.Folder, "logs"))
 	}
 	if nexusSnapDirFlag.Value.String() == "" {
-		nexusSnapDirFlag.Value.Set(path.Join(dbFolder, "snap"))
+		nexusSnapDirFlag.Value.Set(path.Join(cfg.Storage.Folder, "snap"))
 	}
 }
 
 const cacheSize = 3 << 30
 
 func newKVStore() (storage.KVStore, storage.ChangePropagator, storage.ChangeApplier, storage.Backupable) {
-	if err := os.MkdirAll(dbFolder, 0777); err != nil {
+	if err := os.MkdirAll(cfg.Storage.Folder, 0777); err != nil {
+		panic(err)
+	}
+	// Keyspaces being migrated from another engine must not be served
+	if err := migrate.Check(cfg.Storage.Folder, cfg.Storage.Engine); err != nil {
 		panic(err)
 	}
 
-	dbDir := path.Join(dbFolder, "data")
-	switch dbEngine {
+	// Stores must not be opened by two nodes at once
+	var err error
+	if dbFolderLock, err = storage.LockFolder(cfg.Storage.Folder); err != nil {
+		panic(fmt.Sprintf("Unable to lock 'dbFolder'. Error: %v", err))
+	}
+
+	dbDir := path.Join(cfg.Storage.Folder, "data")
+	switch cfg.Storage.Engine {
 	case "rocksdb":
-		rocksDb := rocksdb.OpenDB(dbDir, cacheSize)
+		opts := rocksdb.NewOptions().CreateDBFolderIfMissing(true).DBFolder(dbDir).CacheSize(cacheSize).ParanoidChecks(cfg.Storage.VerifyOnStart).Logger(lgr.Named("rocksdb"))
+		if cfg.Storage.Repair {
+			logRepair(rocksdb.Repair(opts))
+		}
+		rocksDb, err := rocksdb.Open(opts)
+		if err != nil {
+			panic(err)
+		}
+		verifyStore(rocksDb)
 		return rocksDb, rocksDb, rocksDb, rocksDb
 	case "badger":
-		badgerDb := badger.OpenDB(dbDir)
+		opts := badger.NewOptions(dbDir).Logger(lgr.Named("badger"))
+		if cfg.Storage.Repair {
+			logRepair(badger.Repair(opts))
+		}
+		badgerDb, err := badger.Open(opts)
+		if err != nil {
+			panic(err)
+		}
+		verifyStore(badgerDb)
 		return badgerDb, nil, badgerDb, badgerDb
+	case "memory":
+		// Bounds the change log alone, while the keys are never evicted
+		memDb := memory.OpenDB(memory.DefaultMaxChangeLogSize)
+		return memDb, memDb, memDb, memDb
 	default:
-		panic(fmt.Sprintf("Unknown storage engine: %s", dbEngine))
+		panic(fmt.Sprintf("Unknown storage engine: %s", cfg.Storage.Engine))
+	}
+}
+
+// Held until the node exits, which releases it
+var dbFolderLock *storage.FolderLock
+
+func logRepair(fixes []string, err error) {
+	if err != nil {
+		panic(fmt.Sprintf("Unable to repair the %s storage. Error: %v", cfg.Storage.Engine, err))
+	}
+	for _, fix := range fixes {
+		lgr.Warn("Repaired the storage", zap.String("engine", cfg.Storage.Engine), zap.String("fix", fix))
+	}
+	lgr.Info("Storage repaired", zap.String("engine", cfg.Storage.Engine), zap.Int("numFixes", len(fixes)))
+}
+
+// verifyStore fails the startup of the node with a store that fails the
+// verification, rather than serving it until the first corrupted read.
+func verifyStore(kvs storage.KVStore) {
+	if !cfg.Storage.VerifyOnStart {
+		return
+	}
+	start := time.Now()
+	report, err := storage.Verify(context.Background(), kvs)
+	if err != nil {
+		kvs.Close()
+		panic(fmt.Sprintf("Unable to verify the %s storage at %s, which can be repaired through 'dbRepair'. Error: %v", cfg.Storage.Engine, cfg.Storage.Folder, err))
 	}
+	lgr.Info("Storage verified", zap.String("engine", cfg.Storage.Engine), zap.Uint64("numKeys", report.NumKeys), zap.Uint64("appliedChangeNum", report.AppliedChangeNumber),
+		zap.Uint64("committedChangeNum", report.CommittedChangeNumber), zap.Duration("duration", time.Since(start)))
 }
 
 func mkdirNexusDirs() {
@@ -205,6 +914,24 @@ func mkdirNexusDirs() {
 	}
 }
 
+func newClusterNodesOption() master.DKVServiceOption {
+	nexusOpts, err := nexus.NewOptions(nexus.OptionsFromFlags()...)
+	if err != nil {
+		panic(err)
+	}
+	return master.WithClusterNodes(uint64(nexusOpts.NodeId()), nexusOpts.ClusterUrls())
+}
+
+func newClusterAddrsOption() master.DKVServiceOption {
+	var dkvAddrs []string
+	if dbClusterAddrs != "" {
+		for _, dkvAddr := range strings.Split(dbClusterAddrs, ",") {
+			dkvAddrs = append(dkvAddrs, strings.TrimSpace(dkvAddr))
+		}
+	}
+	return master.WithClusterAddrs(dkvAddrs)
+}
+
 func newDKVReplicator(kvs storage.KVStore) nexus_api.RaftReplicator {
 	mkdirNexusDirs()
 	replStore := sync.NewDKVReplStore(kvs)
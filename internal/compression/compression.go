@@ -0,0 +1,179 @@
+// Package compression implements the transparent compression of values
+// used by both the DKV clients and the DKV nodes. Every compressed value
+// is framed by a header identifying its codec along with the length and
+// the checksum of the original value, so that values can be decompressed
+// irrespective of the codec that compressed them, while uncompressed
+// values written by older clients are read verbatim.
+package compression
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// A Codec identifies the algorithm used for compressing values.
+type Codec byte
+
+const (
+	// None leaves the values uncompressed.
+	None Codec = iota
+	// Snappy compresses the values using Snappy, which favours speed.
+	Snappy
+	// Zstd compresses the values using Zstandard, which favours ratio.
+	Zstd
+)
+
+// The header of a compressed value begins with a magic whose first byte
+// never occurs in UTF-8 encoded text, such that textual values like JSON
+// are never mistaken for compressed values. It is followed by the codec,
+// the length of the original value as a big-endian uint32 and its CRC-32C
+// checksum, after which come the compressed bytes.
+var magic = []byte{0xF5, 'D', 'K', 'Z'}
+
+const headerLen = 13
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorrupt is returned by Decompress for values that begin with the
+// magic but do not decompress into the value described by their header.
+var ErrCorrupt = errors.New("compressed value is corrupt")
+
+var codecNames = map[Codec]string{None: "none", Snappy: "snappy", Zstd: "zstd"}
+
+// ParseCodec returns the codec with the given name, which is one
+// of `none`, `snappy` or `zstd`.
+func ParseCodec(name string) (Codec, error) {
+	for codec, codecName := range codecNames {
+		if strings.EqualFold(name, codecName) {
+			return codec, nil
+		}
+	}
+	return None, fmt.Errorf("unknown compression codec: %s", name)
+}
+
+func (codec Codec) String() string {
+	if name, present := codecNames[codec]; present {
+		return name
+	}
+	return fmt.Sprintf("Codec(%d)", byte(codec))
+}
+
+var (
+	zstdOnce sync.Once
+	zstdEnc  *zstd.Encoder
+	zstdDec  *zstd.Decoder
+)
+
+// Both the encoder and the decoder can be used concurrently
+// as long as only EncodeAll and DecodeAll are invoked.
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		zstdEnc, _ = zstd.NewWriter(nil)
+		zstdDec, _ = zstd.NewReader(nil)
+	})
+	return zstdEnc, zstdDec
+}
+
+// Compress compresses the given value using the given codec, framed by
+// the header of this codec. The value is returned verbatim if it is empty,
+// if it is already compressed or if compressing it does not reduce its
+// size. However, values that begin with the magic are always compressed,
+// so that they are never mistaken for compressed values. Since
+// compression is deterministic, compressing the same value using the
+// same codec always results in the same bytes.
+func Compress(codec Codec, value []byte) []byte {
+	if len(value) == 0 || IsCompressed(value) {
+		return value
+	}
+	header := make([]byte, headerLen)
+	copy(header, magic)
+	header[len(magic)] = byte(codec)
+	binary.BigEndian.PutUint32(header[len(magic)+1:], uint32(len(value)))
+	binary.BigEndian.PutUint32(header[len(magic)+5:], crc32.Checksum(value, crcTable))
+	var compVal []byte
+	switch codec {
+	case Snappy:
+		buf := make([]byte, headerLen+snappy.MaxEncodedLen(len(value)))
+		copy(buf, header)
+		compVal = buf[:headerLen+len(snappy.Encode(buf[headerLen:], value))]
+	case Zstd:
+		enc, _ := zstdCodec()
+		compVal = enc.EncodeAll(value, header)
+	default:
+		return value
+	}
+	if len(compVal) >= len(value) && !bytes.HasPrefix(value, magic) {
+		return value
+	}
+	return compVal
+}
+
+// IsCompressed checks if the given value is a compressed value, whose
+// header matches the value that it decompresses into.
+func IsCompressed(value []byte) bool {
+	_, err := Decompress(value)
+	return err == nil && bytes.HasPrefix(value, magic)
+}
+
+// Decompress decompresses the given value using the codec identified by
+// its header. Values that do not begin with the magic are returned
+// verbatim since they must have been written without compression, while
+// ErrCorrupt is returned for those that begin with it but do not match
+// their header.
+func Decompress(value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(value, magic) {
+		return value, nil
+	}
+	if len(value) < headerLen {
+		return nil, fmt.Errorf("header is truncated: %w", ErrCorrupt)
+	}
+	origLen := binary.BigEndian.Uint32(value[len(magic)+1:])
+	var decompVal []byte
+	var err error
+	switch Codec(value[len(magic)]) {
+	case Snappy:
+		// Guards against allocating beyond the length in the header
+		if decLen, lenErr := snappy.DecodedLen(value[headerLen:]); lenErr != nil || uint32(decLen) != origLen {
+			return nil, fmt.Errorf("header does not match the decompressed value: %w", ErrCorrupt)
+		}
+		decompVal, err = snappy.Decode(nil, value[headerLen:])
+	case Zstd:
+		_, dec := zstdCodec()
+		decompVal, err = dec.DecodeAll(value[headerLen:], nil)
+	default:
+		return nil, fmt.Errorf("unknown codec: %d: %w", value[len(magic)], ErrCorrupt)
+	}
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%v: %w", err, ErrCorrupt)
+	case uint32(len(decompVal)) != origLen || crc32.Checksum(decompVal, crcTable) != binary.BigEndian.Uint32(value[len(magic)+5:]):
+		return nil, fmt.Errorf("header does not match the decompressed value: %w", ErrCorrupt)
+	}
+	return decompVal, nil
+}
+
+// Equal checks whether the given values are equal once decompressed,
+// irrespective of the codecs that compressed them, if any. Values that
+// fail to decompress are compared as they are.
+func Equal(value, otherValue []byte) bool {
+	if bytes.Equal(value, otherValue) {
+		return true
+	}
+	decompVal, err := Decompress(value)
+	if err != nil {
+		decompVal = value
+	}
+	otherDecompVal, err := Decompress(otherValue)
+	if err != nil {
+		otherDecompVal = otherValue
+	}
+	return bytes.Equal(decompVal, otherDecompVal)
+}
@@ -0,0 +1,108 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var jsonValue = []byte(strings.Repeat(`{"name":"dkv","type":"distributed key value store"},`, 20))
+
+func TestCompressAndDecompress(t *testing.T) {
+	for _, codec := range []Codec{Snappy, Zstd} {
+		compVal := Compress(codec, jsonValue)
+		if !IsCompressed(compVal) || len(compVal) >= len(jsonValue) {
+			t.Errorf("Expected %s to compress the value. Compressed size: %d, Original size: %d", codec, len(compVal), len(jsonValue))
+		}
+		if !bytes.Equal(Compress(codec, jsonValue), compVal) {
+			t.Errorf("Expected %s to compress the same value into the same bytes", codec)
+		}
+		if decompVal, err := Decompress(compVal); err != nil || !bytes.Equal(decompVal, jsonValue) {
+			t.Errorf("Decompression mismatch for %s. Expected: %s, Actual: %s, Error: %v", codec, jsonValue, decompVal, err)
+		}
+	}
+}
+
+func TestCompressLeavesValuesVerbatim(t *testing.T) {
+	compVal := Compress(Zstd, jsonValue)
+	for name, value := range map[string][]byte{
+		"empty":        {},
+		"incompressed": []byte("tiny"),
+		"compressed":   compVal,
+	} {
+		for _, codec := range []Codec{None, Snappy, Zstd} {
+			if actVal := Compress(codec, value); !bytes.Equal(actVal, value) {
+				t.Errorf("Expected %s to leave the %s value verbatim. Actual: %q", codec, name, actVal)
+			}
+		}
+	}
+}
+
+func TestCompressFramesValuesWithMagic(t *testing.T) {
+	value := append(append([]byte{}, magic...), "tiny"...)
+	for _, codec := range []Codec{Snappy, Zstd} {
+		compVal := Compress(codec, value)
+		if !IsCompressed(compVal) {
+			t.Errorf("Expected %s to compress the value beginning with the magic. Actual: %q", codec, compVal)
+		}
+		if decompVal, err := Decompress(compVal); err != nil || !bytes.Equal(decompVal, value) {
+			t.Errorf("Decompression mismatch for %s. Expected: %q, Actual: %q, Error: %v", codec, value, decompVal, err)
+		}
+	}
+}
+
+func TestDecompressLeavesValuesVerbatim(t *testing.T) {
+	for _, value := range [][]byte{nil, jsonValue, {0xF5, 0xFF, 0xFF}, {0xF6, 'x'}} {
+		if actVal, err := Decompress(value); err != nil || !bytes.Equal(actVal, value) {
+			t.Errorf("Expected value: %q to be left verbatim. Actual: %q, Error: %v", value, actVal, err)
+		}
+	}
+}
+
+func TestDecompressRejectsCorruptValues(t *testing.T) {
+	for _, codec := range []Codec{Snappy, Zstd} {
+		compVal := Compress(codec, jsonValue)
+		for name, corrupt := range map[string]func([]byte){
+			"codec":    func(val []byte) { val[len(magic)] = 0x7F },
+			"length":   func(val []byte) { val[len(magic)+4]++ },
+			"checksum": func(val []byte) { val[len(magic)+8]++ },
+			"payload":  func(val []byte) { val[len(val)-1]++ },
+		} {
+			corruptVal := append([]byte{}, compVal...)
+			corrupt(corruptVal)
+			if _, err := Decompress(corruptVal); !errors.Is(err, ErrCorrupt) {
+				t.Errorf("Expected the value of %s with a corrupt %s to be rejected. Error: %v", codec, name, err)
+			}
+			if IsCompressed(corruptVal) {
+				t.Errorf("Expected the value of %s with a corrupt %s not to be deemed compressed", codec, name)
+			}
+		}
+		if _, err := Decompress(compVal[:headerLen-1]); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("Expected the value of %s with a truncated header to be rejected. Error: %v", codec, err)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	snappyVal, zstdVal := Compress(Snappy, jsonValue), Compress(Zstd, jsonValue)
+	for _, pair := range [][2][]byte{{jsonValue, snappyVal}, {snappyVal, zstdVal}, {zstdVal, jsonValue}, {nil, nil}} {
+		if !Equal(pair[0], pair[1]) {
+			t.Errorf("Expected values: %q and %q to be equal once decompressed", pair[0], pair[1])
+		}
+	}
+	if Equal(snappyVal, Compress(Zstd, append([]byte("x"), jsonValue...))) {
+		t.Error("Expected differing values to be unequal once decompressed")
+	}
+}
+
+func TestParseCodec(t *testing.T) {
+	for _, codec := range []Codec{None, Snappy, Zstd} {
+		if actCodec, err := ParseCodec(codec.String()); err != nil || actCodec != codec {
+			t.Errorf("Codec mismatch. Expected: %s, Actual: %s, Error: %v", codec, actCodec, err)
+		}
+	}
+	if _, err := ParseCodec("lz4"); err == nil {
+		t.Error("Expected an error for an unknown codec")
+	}
+}
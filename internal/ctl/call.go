@@ -0,0 +1,91 @@
+package ctl
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// A CallOption overrides one of the options of a DKVClient for the
+// calls made through the view of the client returned by ForCall.
+type CallOption func(*callOpts)
+
+type callOpts struct {
+	// Timeout of every call, overriding that of the client when non zero
+	timeout time.Duration
+	// Attempts made for every idempotent call, overriding those of the
+	// retry policy of the client when non zero
+	maxAttempts int
+}
+
+// WithDeadline sets the timeout of every call, in place of the Timeout
+// of the client, such as for a MultiGet of many keys that is expected
+// to take longer. Calls made with a context expiring earlier still fail
+// once the context expires.
+func WithDeadline(timeout time.Duration) CallOption {
+	return func(opts *callOpts) {
+		opts.timeout = timeout
+	}
+}
+
+// WithRetries sets the number of times every idempotent call is retried
+// upon transient errors, in place of the MaxAttempts of the RetryPolicy
+// of the client. Zero disables the retries, while the calls of clients
+// without a retry policy are retried as per the DefaultRetryPolicy.
+func WithRetries(retries int) CallOption {
+	return func(opts *callOpts) {
+		opts.maxAttempts = retries + 1
+	}
+}
+
+// ForCall returns a view of this client whose calls are made as per the
+// given options, overriding those of this client, so that a single call
+// can be customized like:
+//
+//	values, err := dkvClnt.ForCall(ctl.WithDeadline(30*time.Second), ctl.WithRetries(0)).MultiGet(keys...)
+//
+// The options apply to the unary calls alone, made with or without a
+// context, while streaming calls like Iterate are left as is. The view
+// shares the underlying connection, hence closing either of them closes
+// both.
+//
+// A view is used in place of CallOptions given to every method, which
+// the methods of a variable number of keys, like MultiGetWithCtx, can
+// not take alongside their keys.
+func (dkvClnt *DKVClient) ForCall(opts ...CallOption) *DKVClient {
+	callClnt := *dkvClnt
+	callClnt.callOpts = &callOpts{}
+	if dkvClnt.callOpts != nil {
+		*callClnt.callOpts = *dkvClnt.callOpts
+	}
+	for _, opt := range opts {
+		opt(callClnt.callOpts)
+	}
+	callClnt.setStubs(&callConn{dkvClnt.cliConn, callClnt.callOpts})
+	return &callClnt
+}
+
+// callConn makes the unary calls over the connection as per the options
+type callConn struct {
+	*grpc.ClientConn
+	opts *callOpts
+}
+
+func (cc *callConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	if cc.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cc.opts.timeout)
+		defer cancel()
+	}
+	return cc.ClientConn.Invoke(context.WithValue(ctx, callOptsKey{}, cc.opts), method, args, reply, opts...)
+}
+
+type callOptsKey struct{}
+
+// callOptsFrom returns the options of the call made with the given
+// context, which is nil for calls made without any.
+func callOptsFrom(ctx context.Context) *callOpts {
+	opts, _ := ctx.Value(callOptsKey{}).(*callOpts)
+	return opts
+}
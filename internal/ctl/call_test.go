@@ -0,0 +1,96 @@
+package ctl
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCallDeadline(t *testing.T) {
+	grpcSrvr := serveDKV(t, &slowDKVServer{delay: 200 * time.Millisecond})
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithTimeout(50*time.Millisecond))
+	defer client.Close()
+
+	if _, err := client.Get([]byte("K1")); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected the GET to exceed the timeout of the client. Error: %v", err)
+	}
+	callClient := client.ForCall(WithDeadline(time.Second))
+	expectGet(t, callClient, "K1")
+
+	// Contexts expiring earlier than the deadline still fail the calls
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := callClient.GetWithCtx(ctx, []byte("K1")); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected the GET to exceed the deadline of its context. Error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("Expected the GET to fail once its context expired. Elapsed: %v", elapsed)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.ForCall(WithDeadline(50*time.Millisecond)).GetWithCtx(ctx, []byte("K1")); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected the GET to exceed the deadline of the call. Error: %v", err)
+	}
+}
+
+func TestCallDeadlineOverridesDefaultTimeout(t *testing.T) {
+	grpcSrvr := serveDKV(t, &slowDKVServer{delay: 200 * time.Millisecond})
+	defer grpcSrvr.Stop()
+
+	// Client is bound by the DefaultTimeout, which the GET is well within
+	client := newDKVClient(t)
+	defer client.Close()
+	expectGet(t, client, "K1")
+
+	callClient := client.ForCall(WithDeadline(50 * time.Millisecond))
+	for name, get := range map[string]func() error{
+		"Get": func() error {
+			_, err := callClient.Get([]byte("K1"))
+			return err
+		},
+		"GetWithCtx": func() error {
+			_, err := callClient.GetWithCtx(context.Background(), []byte("K1"))
+			return err
+		},
+	} {
+		start := time.Now()
+		if err := get(); status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("Expected %s to exceed the deadline of the call. Error: %v", name, err)
+		}
+		if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+			t.Errorf("Expected %s to fail once the deadline of the call expired. Elapsed: %v", name, elapsed)
+		}
+	}
+}
+
+func TestCallRetries(t *testing.T) {
+	flakySrvr := &flakyDKVServer{numFailures: 2}
+	grpcSrvr := serveDKV(t, flakySrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithRetryPolicy(testRetryPolicy))
+	defer client.Close()
+	if _, err := client.ForCall(WithRetries(0)).Get([]byte("hello")); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected the GET without retries to fail. Error: %v", err)
+	}
+	if numCalls := atomic.LoadUint32(&flakySrvr.numCalls); numCalls != 1 {
+		t.Errorf("Expected a single attempt for GET. Actual: %d", numCalls)
+	}
+	client.Close()
+
+	// Calls of clients without a retry policy are retried on demand
+	atomic.StoreUint32(&flakySrvr.numCalls, 0)
+	client = newDKVClient(t)
+	defer client.Close()
+	expectGet(t, client.ForCall(WithRetries(2)), "hello")
+	if numCalls := atomic.LoadUint32(&flakySrvr.numCalls); numCalls != 3 {
+		t.Errorf("Expected 3 attempts for GET. Actual: %d", numCalls)
+	}
+}
@@ -0,0 +1,158 @@
+package ctl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// Default limits of the batches of changes retrieved by ChangesIterator.
+const (
+	DefaultChangesBatchSize  = 1000
+	DefaultChangesBatchBytes = 16 << 20
+)
+
+// ErrMasterDiverged is returned by ChangesIterator when the change number
+// of the master node is lesser than that of the changes already returned,
+// which happens when the master node is restored from an older backup.
+var ErrMasterDiverged = errors.New("change number of the master node can not be lesser than the change number of the delivered changes")
+
+// A ChangesIterator iterates over the changes of a DKV node in the order
+// of their change numbers, retrieving them in batches through the GRPC
+// GetChanges method as they are consumed. It is not safe for concurrent
+// use.
+type ChangesIterator struct {
+	dkvClnt     *DKVClient
+	newCtx      func() (context.Context, context.CancelFunc)
+	maxNumChngs uint32
+	maxNumBytes uint64
+	keyPrefix   []byte
+
+	// Changes retrieved yet to be returned
+	chngs       []*serverpb.ChangeRecord
+	nextChngNum uint64
+	// Change number following the retrieved changes, as per the node
+	batchEnd    uint64
+	mstrChngNum uint64
+	caughtUp    bool
+}
+
+// A ChangesIteratorOption is used to customize a specific aspect of
+// the ChangesIterator.
+type ChangesIteratorOption func(*ChangesIterator)
+
+// WithChangesBatch limits the batches of changes retrieved at once to
+// the given number of changes and, if positive, the given total size.
+// They default to DefaultChangesBatchSize and DefaultChangesBatchBytes.
+func WithChangesBatch(maxNumChanges uint32, maxNumBytes uint64) ChangesIteratorOption {
+	return func(it *ChangesIterator) {
+		it.maxNumChngs, it.maxNumBytes = maxNumChanges, maxNumBytes
+	}
+}
+
+// WithChangesKeyPrefix retrieves only the transactions on the keys with
+// the given prefix, as per GetChangesWithPrefixWithCtx, skipping the
+// changes left without any of them.
+func WithChangesKeyPrefix(keyPrefix []byte) ChangesIteratorOption {
+	return func(it *ChangesIterator) {
+		it.keyPrefix = keyPrefix
+	}
+}
+
+// ChangesIterator returns a ChangesIterator over the changes since the
+// given change number, each batch of which is retrieved within the
+// timeout of the client. Iterators of the views returned by ForNamespace
+// retrieve only the transactions on the keys of their namespace.
+func (dkvClnt *DKVClient) ChangesIterator(fromChangeNum uint64, opts ...ChangesIteratorOption) *ChangesIterator {
+	return newChangesIterator(dkvClnt, dkvClnt.newTimeoutContext, fromChangeNum, opts)
+}
+
+// ChangesIteratorWithCtx is same as ChangesIterator except that every
+// batch is retrieved using the given context.
+func (dkvClnt *DKVClient) ChangesIteratorWithCtx(ctx context.Context, fromChangeNum uint64, opts ...ChangesIteratorOption) *ChangesIterator {
+	return newChangesIterator(dkvClnt, func() (context.Context, context.CancelFunc) { return ctx, func() {} }, fromChangeNum, opts)
+}
+
+func newChangesIterator(dkvClnt *DKVClient, newCtx func() (context.Context, context.CancelFunc), fromChangeNum uint64, opts []ChangesIteratorOption) *ChangesIterator {
+	it := &ChangesIterator{dkvClnt: dkvClnt, newCtx: newCtx, maxNumChngs: DefaultChangesBatchSize, maxNumBytes: DefaultChangesBatchBytes, nextChngNum: fromChangeNum}
+	for _, opt := range opts {
+		opt(it)
+	}
+	return it
+}
+
+// Next returns the following change, retrieving the next batch of changes
+// once those retrieved earlier are all returned. It returns nil once the
+// changes upto the latest change number of the node are all returned, as
+// per Caughtup, while a later call retrieves the changes committed since.
+// Failures to retrieve the changes, such as ErrChangesUnavailable and
+// ErrMasterDiverged, leave the iterator at the same position, so that
+// calling Next again retries the retrieval.
+func (it *ChangesIterator) Next() (*serverpb.ChangeRecord, error) {
+	for len(it.chngs) == 0 {
+		fromChngNum := it.nextChngNum
+		if err := it.fetch(); err != nil {
+			return nil, err
+		}
+		// Retrieval resumes right away past the changes skipped due
+		// to the key prefix, unless caught up with the node
+		if len(it.chngs) == 0 && (it.nextChngNum == fromChngNum || it.nextChngNum > it.mstrChngNum) {
+			it.caughtUp = true
+			return nil, nil
+		}
+	}
+	chng := it.chngs[0]
+	it.chngs = it.chngs[1:]
+	it.nextChngNum = chng.ChangeNumber + 1
+	if len(it.chngs) == 0 && it.batchEnd > it.nextChngNum {
+		it.nextChngNum = it.batchEnd
+	}
+	it.caughtUp = len(it.chngs) == 0 && it.nextChngNum > it.mstrChngNum
+	return chng, nil
+}
+
+// fetch retrieves the next batch of changes.
+func (it *ChangesIterator) fetch() error {
+	ctx, cancel := it.newCtx()
+	defer cancel()
+	res, err := it.dkvClnt.GetChangesWithPrefixWithCtx(ctx, it.nextChngNum, it.maxNumChngs, it.maxNumBytes, it.keyPrefix)
+	if err = errorFromStatus(res.GetStatus(), err); err != nil {
+		return err
+	}
+	// Slaves serving changes may lag behind, like after a failover onto them
+	if it.nextChngNum > 0 && res.MasterChangeNumber < it.nextChngNum-1 && !res.ServedBySlave {
+		return ErrMasterDiverged
+	}
+	it.mstrChngNum = res.MasterChangeNumber
+	for _, chng := range res.Changes {
+		// Changes preceding the requested one are already returned
+		if chng.ChangeNumber >= it.nextChngNum {
+			it.chngs = append(it.chngs, chng)
+		}
+	}
+	it.batchEnd = res.NextChangeNumber
+	if len(it.chngs) == 0 && it.batchEnd > it.nextChngNum {
+		it.nextChngNum = it.batchEnd
+	}
+	return nil
+}
+
+// Caughtup reports whether the changes upto the latest change number of
+// the node, as of the latest retrieval, are all returned by Next.
+func (it *ChangesIterator) Caughtup() bool {
+	return it.caughtUp
+}
+
+// MasterChangeNumber returns the latest change number of the node, as
+// of the latest retrieval. It is the latest change number applied by the
+// node when it is a slave.
+func (it *ChangesIterator) MasterChangeNumber() uint64 {
+	return it.mstrChngNum
+}
+
+// NextChangeNumber returns the change number from which the iteration
+// resumes, which callers can persist for iterating afresh later.
+func (it *ChangesIterator) NextChangeNumber() uint64 {
+	return it.nextChngNum
+}
@@ -0,0 +1,146 @@
+package ctl
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc"
+)
+
+// changesReplServer serves its changes in batches, with the numbers of
+// the changes absent from them skipped as if due to the key prefix
+type changesReplServer struct {
+	serverpb.UnimplementedDKVReplicationServer
+	mu          sync.Mutex
+	chngs       []*serverpb.ChangeRecord
+	mstrChngNum uint64
+	minChngNum  uint64
+	numCalls    int
+}
+
+func (crs *changesReplServer) GetChanges(ctx context.Context, req *serverpb.GetChangesRequest) (*serverpb.GetChangesResponse, error) {
+	crs.mu.Lock()
+	defer crs.mu.Unlock()
+	crs.numCalls++
+	if req.FromChangeNumber < crs.minChngNum {
+		return &serverpb.GetChangesResponse{Status: dkverrors.NewStatus(dkverrors.ErrChangesUnavailable)}, nil
+	}
+	res := &serverpb.GetChangesResponse{Status: &serverpb.Status{}, MasterChangeNumber: crs.mstrChngNum, NextChangeNumber: crs.mstrChngNum + 1}
+	for _, chng := range crs.chngs {
+		if chng.ChangeNumber < req.FromChangeNumber {
+			continue
+		}
+		if len(res.Changes) == int(req.MaxNumberOfChanges) {
+			res.NextChangeNumber = res.Changes[len(res.Changes)-1].ChangeNumber + 1
+			break
+		}
+		res.Changes = append(res.Changes, chng)
+	}
+	res.NumberOfChanges = uint32(len(res.Changes))
+	return res, nil
+}
+
+func (crs *changesReplServer) commit(mstrChngNum uint64, chngNums ...uint64) {
+	crs.mu.Lock()
+	defer crs.mu.Unlock()
+	for _, chngNum := range chngNums {
+		crs.chngs = append(crs.chngs, &serverpb.ChangeRecord{ChangeNumber: chngNum})
+	}
+	crs.mstrChngNum = mstrChngNum
+}
+
+func serveChanges(t *testing.T, replSrvr *changesReplServer) *DKVClient {
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	grpcSrvr := grpc.NewServer()
+	serverpb.RegisterDKVReplicationServer(grpcSrvr, replSrvr)
+	go grpcSrvr.Serve(lis)
+	t.Cleanup(grpcSrvr.Stop)
+	client, err := NewInSecureDKVClient(lis.Addr().String(), WithReadBufSize(testBufSize), WithWriteBufSize(testBufSize))
+	if err != nil {
+		t.Fatalf("Unable to create DKV client. Error: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// iterateChanges returns the numbers of the changes returned by the
+// given iterator until it is caught up
+func iterateChanges(t *testing.T, it *ChangesIterator) []uint64 {
+	t.Helper()
+	var chngNums []uint64
+	for {
+		chng, err := it.Next()
+		if err != nil {
+			t.Fatalf("Unable to iterate over the changes. Error: %v", err)
+		}
+		if chng == nil {
+			if !it.Caughtup() {
+				t.Error("Expected the iterator to be caught up once it returns no change")
+			}
+			return chngNums
+		}
+		chngNums = append(chngNums, chng.ChangeNumber)
+	}
+}
+
+func TestChangesIterator(t *testing.T) {
+	replSrvr := &changesReplServer{}
+	replSrvr.commit(10, 1, 2, 3, 5, 6, 9)
+	client := serveChanges(t, replSrvr)
+
+	it := client.ChangesIterator(2, WithChangesBatch(2, 0))
+	if chngNums := iterateChanges(t, it); len(chngNums) != 5 || chngNums[0] != 2 || chngNums[4] != 9 {
+		t.Errorf("Expected the changes from 2 onwards. Actual: %v", chngNums)
+	}
+	if it.NextChangeNumber() != 11 || it.MasterChangeNumber() != 10 {
+		t.Errorf("Expected the iterator to be past the master change number: 10. Next: %d, Master: %d", it.NextChangeNumber(), it.MasterChangeNumber())
+	}
+	// Followed by a retrieval finding no further changes
+	if replSrvr.numCalls != 4 {
+		t.Errorf("Expected the changes to be retrieved in 3 batches. Retrievals: %d", replSrvr.numCalls)
+	}
+
+	// Changes committed later are returned by the same iterator
+	replSrvr.commit(13, 13)
+	if chng, err := it.Next(); err != nil || chng.GetChangeNumber() != 13 || !it.Caughtup() {
+		t.Errorf("Expected the latest change to be returned. Change: %v, Caught up: %t, Error: %v", chng, it.Caughtup(), err)
+	}
+	if chng, err := it.Next(); err != nil || chng != nil {
+		t.Errorf("Expected no further change. Change: %v, Error: %v", chng, err)
+	}
+}
+
+func TestChangesIteratorSkipsEmptyBatches(t *testing.T) {
+	replSrvr := &changesReplServer{}
+	// Batches skipping all their changes carry none of them
+	replSrvr.commit(20)
+	client := serveChanges(t, replSrvr)
+
+	it := client.ChangesIteratorWithCtx(context.Background(), 1)
+	if chng, err := it.Next(); err != nil || chng != nil || !it.Caughtup() || it.NextChangeNumber() != 21 {
+		t.Errorf("Expected the skipped changes to be moved past. Change: %v, Next: %d, Error: %v", chng, it.NextChangeNumber(), err)
+	}
+}
+
+func TestChangesIteratorFailures(t *testing.T) {
+	replSrvr := &changesReplServer{minChngNum: 5}
+	replSrvr.commit(6, 5, 6)
+	client := serveChanges(t, replSrvr)
+
+	it := client.ChangesIterator(1)
+	if _, err := it.Next(); !errors.Is(err, dkverrors.ErrChangesUnavailable) || it.NextChangeNumber() != 1 {
+		t.Errorf("Expected error: %v retaining the position. Actual: %v, Next: %d", dkverrors.ErrChangesUnavailable, err, it.NextChangeNumber())
+	}
+	it = client.ChangesIterator(9)
+	if _, err := it.Next(); err != ErrMasterDiverged {
+		t.Errorf("Expected error: %v. Actual: %v", ErrMasterDiverged, err)
+	}
+}
@@ -1,12 +1,47 @@
+// Package ctl provides the DKVClient for the GRPC services of DKV
+// nodes. Every method of the client comes in two forms, one bounded by
+// the timeout of the client and the other, suffixed WithCtx, by the
+// given context.
+//
+// Options of the client, like its timeout and retry policy, can be
+// overridden for individual calls through the view of the client
+// returned by ForCall, as in:
+//
+//	values, err := dkvClnt.ForCall(ctl.WithDeadline(30*time.Second)).MultiGetWithCtx(ctx, keys...)
+//
+// The CallOptions are taken by such a view, rather than by the WithCtx
+// methods themselves, since the methods taking a variable number of
+// keys or pairs can not take another variadic parameter, and since the
+// client must keep satisfying the interfaces of its WithCtx methods,
+// such as those of the cachedclient and subscriber packages.
 package ctl
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
 	"time"
 
+	"github.com/flipkart-incubator/dkv/internal/compression"
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/internal/tracing"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 )
 
 // A DKVClient instance is used to communicate with various DKV services
@@ -17,103 +52,1993 @@ type DKVClient struct {
 	cliConn    *grpc.ClientConn
 	dkvCli     serverpb.DKVClient
 	dkvReplCli serverpb.DKVReplicationClient
+	dkvRSCli   serverpb.DKVReplicationStatusClient
 	dkvBRCli   serverpb.DKVBackupRestoreClient
 	dkvClusCli serverpb.DKVClusterClient
+	dkvFOCli   serverpb.DKVFailoverClient
+	dkvRCCli   serverpb.DKVReplicationControlClient
+	dkvLimCli  serverpb.DKVLimitsClient
+	dkvStorCli serverpb.DKVStorageClient
+	dkvInfoCli serverpb.DKVInfoClient
+	dkvMntCli  serverpb.DKVMaintenanceClient
+	dkvSecCli  serverpb.DKVSecurityClient
+	hlthCli    grpc_health_v1.HealthClient
+	opts       *DKVClientOpts
+	namespace  string
+	ldrFlwr    *leaderFollower
+	callOpts   *callOpts
 }
 
-// TODO: Should these be paramterised ?
+// Default values used by DKVClient unless overridden
+// through one of the DKVClientOption instances.
 const (
-	ReadBufSize  = 10 << 30
-	WriteBufSize = 10 << 30
-	Timeout      = 5 * time.Second
+	DefaultReadBufSize         = 10 << 30
+	DefaultWriteBufSize        = 10 << 30
+	DefaultTimeout             = 5 * time.Second
+	DefaultHealthCheckInterval = 5 * time.Second
+	DefaultBackupTimeout       = time.Hour
+	DefaultDialTimeout         = 10 * time.Second
+	// DefaultMaxRecvMsgSize and DefaultMaxSendMsgSize are the maximum
+	// sizes of the messages received and sent by GRPC clients by default.
+	DefaultMaxRecvMsgSize = 4 << 20
+	DefaultMaxSendMsgSize = math.MaxInt32
+	// DefaultMultiGetMaxKeys and DefaultMultiGetMaxBytes keep every
+	// MultiGet request well within the 4 MB message size that GRPC
+	// servers accept by default, which DKV nodes retain unless their
+	// grpcMaxRecvMsgSize is raised.
+	DefaultMultiGetMaxKeys     = 1000
+	DefaultMultiGetMaxBytes    = 1 << 20
+	DefaultMultiGetConcurrency = 4
+	// ReplicationKeepaliveTime and ReplicationKeepaliveTimeout are the
+	// aggressive keepalives suited for the long lived connections of
+	// slave nodes to their master nodes, which DKV nodes permit.
+	ReplicationKeepaliveTime    = 10 * time.Second
+	ReplicationKeepaliveTimeout = 5 * time.Second
 )
 
+// Former names of the defaults, retained for the existing callers.
+const (
+	// Deprecated: Use DefaultReadBufSize instead.
+	ReadBufSize = DefaultReadBufSize
+	// Deprecated: Use DefaultWriteBufSize instead.
+	WriteBufSize = DefaultWriteBufSize
+	// Deprecated: Use DefaultTimeout instead.
+	Timeout = DefaultTimeout
+)
+
+// DKVClientOpts holds the various options used for configuring
+// a DKVClient instance.
+type DKVClientOpts struct {
+	// ReadBufSize is the size of the read buffer of the underlying
+	// GRPC connection.
+	ReadBufSize int
+	// WriteBufSize is the size of the write buffer of the underlying
+	// GRPC connection.
+	WriteBufSize int
+	// MaxRecvMsgSize is the maximum size of the responses received over
+	// the underlying GRPC connection, beyond which calls fail with
+	// RESOURCE_EXHAUSTED errors. Changes are retrieved in batches that
+	// fit within it.
+	MaxRecvMsgSize int
+	// MaxSendMsgSize is the maximum size of the requests sent over the
+	// underlying GRPC connection.
+	MaxSendMsgSize int
+	// Timeout is the default timeout applied on every call made by
+	// the DKVClient.
+	Timeout time.Duration
+	// BackupTimeout is the timeout within which the blocking variants
+	// of Backup and Restore wait for their jobs to complete.
+	BackupTimeout time.Duration
+	// RetryPolicy is the policy used for retrying calls that fail
+	// due to transient errors. Calls are not retried if its nil.
+	RetryPolicy *RetryPolicy
+	// HealthCheckInterval is the interval at which a DKVShardClient
+	// checks the health of every replica.
+	HealthCheckInterval time.Duration
+	// HedgePolicy is the policy used by a DKVShardClient for hedging
+	// its reads. Reads are not hedged if its nil.
+	HedgePolicy *HedgePolicy
+	// NonBlockingDial indicates whether the DKVClient must be created
+	// without waiting for the DKV service to be reachable.
+	NonBlockingDial bool
+	// DialTimeout is the timeout within which the DKV service must be
+	// reachable, unless the DKVClient is created with NonBlockingDial.
+	// The DKVClient waits indefinitely if its zero.
+	DialTimeout time.Duration
+	// Compression is the codec used for compressing the values before
+	// they are written, and for decompressing them once they are read.
+	// Values are neither compressed nor decompressed if its None.
+	Compression compression.Codec
+	// Logger is used for logging the retries of calls along with the
+	// changes in health of the replicas of a DKVShardClient.
+	Logger *zap.Logger
+	// AuthToken is the bearer token attached to every call made by the
+	// DKVClient. No token is attached if its empty.
+	AuthToken string
+	// FollowLeader indicates whether the DKVClient retries the changes
+	// rejected by master nodes that do not lead their cluster, on the
+	// leader hinted by them.
+	FollowLeader bool
+	// MultiGetMaxKeys and MultiGetMaxBytes bound the number of keys
+	// and their total size in every MultiGet request, beyond which
+	// the keys are split across multiple requests. Zero disables the
+	// respective bound.
+	MultiGetMaxKeys  int
+	MultiGetMaxBytes int
+	// MultiGetConcurrency is the number of requests a MultiGet split
+	// thus issues concurrently.
+	MultiGetConcurrency int
+	// ReplicaID identifies the replica retrieving changes through the
+	// DKVClient, whose progress is then tracked by the master node. The
+	// replica remains anonymous if its empty.
+	ReplicaID string
+	// KeepaliveTime is the duration of inactivity after which the
+	// underlying GRPC connection is pinged, so that silently broken
+	// connections are detected before they are used. The connection
+	// is never pinged if its zero.
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is the duration for which a ping waits to be
+	// acknowledged, beyond which the connection is closed.
+	KeepaliveTimeout time.Duration
+	// KeepalivePermitWithoutStream indicates whether the connection is
+	// pinged even while no calls are in progress.
+	KeepalivePermitWithoutStream bool
+	// WaitForReady indicates whether the idempotent calls wait for the
+	// underlying GRPC connection to be reestablished within their
+	// timeout, instead of failing right away with UNAVAILABLE errors.
+	WaitForReady bool
+	// Tracing indicates whether every call is traced as an OpenTelemetry
+	// span through the global trace provider, continuing the trace of
+	// the context of the call onto the DKV node.
+	Tracing bool
+}
+
+// A DKVClientOption is used to customize a specific aspect of
+// a DKVClient instance.
+type DKVClientOption func(*DKVClientOpts)
+
+// WithReadBufSize sets the read buffer size of the underlying
+// GRPC connection.
+func WithReadBufSize(size int) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.ReadBufSize = size
+	}
+}
+
+// WithWriteBufSize sets the write buffer size of the underlying
+// GRPC connection.
+func WithWriteBufSize(size int) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.WriteBufSize = size
+	}
+}
+
+// WithMaxRecvMsgSize sets the maximum size of the responses received
+// over the underlying GRPC connection, such as for the MultiGet of
+// large values.
+func WithMaxRecvMsgSize(size int) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.MaxRecvMsgSize = size
+	}
+}
+
+// WithMaxSendMsgSize sets the maximum size of the requests sent over
+// the underlying GRPC connection. DKV nodes accept requests only upto
+// the maximum size they receive.
+func WithMaxSendMsgSize(size int) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.MaxSendMsgSize = size
+	}
+}
+
+// WithTimeout sets the default timeout applied on every call
+// made by the DKVClient.
+func WithTimeout(timeout time.Duration) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.Timeout = timeout
+	}
+}
+
+// WithBackupTimeout sets the timeout within which the blocking
+// variants of Backup and Restore wait for their jobs to complete.
+func WithBackupTimeout(timeout time.Duration) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.BackupTimeout = timeout
+	}
+}
+
+// WithHealthCheckInterval sets the interval at which a DKVShardClient
+// checks the health of every replica.
+func WithHealthCheckInterval(interval time.Duration) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.HealthCheckInterval = interval
+	}
+}
+
+// WithNonBlockingDial creates the DKVClient without waiting for the
+// DKV service to be reachable. Calls made until the service becomes
+// reachable fail with UNAVAILABLE errors, which can be avoided by
+// waiting for it using Connect.
+func WithNonBlockingDial() DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.NonBlockingDial = true
+	}
+}
+
+// WithDialTimeout sets the timeout within which the DKV service must be
+// reachable for the DKVClient to be created, beyond which its creation
+// fails. It has no effect on clients created with WithNonBlockingDial.
+func WithDialTimeout(timeout time.Duration) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.DialTimeout = timeout
+	}
+}
+
+// WithCompression compresses the values using the given codec before
+// writing them, while the values read are decompressed using the codec
+// identified by their header. Values written by clients without this
+// option are read verbatim.
+func WithCompression(codec compression.Codec) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.Compression = codec
+	}
+}
+
+// WithLogger sets the logger used by the DKVClient. By default
+// nothing is logged.
+func WithLogger(lgr *zap.Logger) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.Logger = lgr
+	}
+}
+
+// WithAuthToken attaches the given bearer token to every call made
+// by the DKVClient, for DKV nodes that authorize calls using tokens.
+func WithAuthToken(token string) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.AuthToken = token
+	}
+}
+
+// WithMultiGetChunking splits the keys of every MultiGet into requests
+// of atmost the given number of keys and the given total size of keys,
+// where zero disables the respective bound. Keys larger than the given
+// size are requested alone.
+func WithMultiGetChunking(maxKeys, maxBytes int) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.MultiGetMaxKeys, opts.MultiGetMaxBytes = maxKeys, maxBytes
+	}
+}
+
+// WithMultiGetConcurrency sets the number of requests issued concurrently
+// by a MultiGet whose keys are split across multiple requests.
+func WithMultiGetConcurrency(concurrency int) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.MultiGetConcurrency = concurrency
+	}
+}
+
+// WithReplicaID identifies the caller of GetChanges and StreamChanges to
+// the master node by the given ID, so that the changes it is yet to
+// consume are retained as per the change log retention policy. IDs
+// must be unique amongst the replicas of a master node.
+func WithReplicaID(replicaID string) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.ReplicaID = replicaID
+	}
+}
+
+// WithKeepalive pings the DKV service over the underlying GRPC connection
+// after the given duration of inactivity, closing the connection unless
+// the ping is acknowledged within the given timeout. Connections are
+// pinged even while no calls are in progress if permitWithoutStream is
+// set, which detects connections silently dropped by NATs and load
+// balancers while the client is idle. DKV nodes reject pings more
+// frequent than once every 5 seconds.
+func WithKeepalive(keepaliveTime, timeout time.Duration, permitWithoutStream bool) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.KeepaliveTime, opts.KeepaliveTimeout = keepaliveTime, timeout
+		opts.KeepalivePermitWithoutStream = permitWithoutStream
+	}
+}
+
+// WithWaitForReady makes idempotent calls like Get, MultiGet, GetChanges
+// and Exists wait for the underlying GRPC connection to be reestablished
+// within their timeout, rather than failing right away while the DKV
+// service is unreachable.
+func WithWaitForReady() DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.WaitForReady = true
+	}
+}
+
+// WithTracing traces every call as an OpenTelemetry span, which is the
+// child of the span of the context of the call, if any, and the parent
+// of the spans of the DKV node serving it. Spans are recorded as per the
+// global trace provider, which the application must set up.
+func WithTracing() DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.Tracing = true
+	}
+}
+
+func newDKVClientOpts(opts ...DKVClientOption) *DKVClientOpts {
+	dkvCliOpts := &DKVClientOpts{
+		ReadBufSize:         DefaultReadBufSize,
+		WriteBufSize:        DefaultWriteBufSize,
+		MaxRecvMsgSize:      DefaultMaxRecvMsgSize,
+		MaxSendMsgSize:      DefaultMaxSendMsgSize,
+		Timeout:             DefaultTimeout,
+		BackupTimeout:       DefaultBackupTimeout,
+		DialTimeout:         DefaultDialTimeout,
+		HealthCheckInterval: DefaultHealthCheckInterval,
+		Logger:              zap.NewNop(),
+		MultiGetMaxKeys:     DefaultMultiGetMaxKeys,
+		MultiGetMaxBytes:    DefaultMultiGetMaxBytes,
+		MultiGetConcurrency: DefaultMultiGetConcurrency,
+	}
+	for _, opt := range opts {
+		opt(dkvCliOpts)
+	}
+	return dkvCliOpts
+}
+
 // NewInSecureDKVClient creates an insecure GRPC client against the
-// given DKV service address.
-func NewInSecureDKVClient(svcAddr string) (*DKVClient, error) {
+// given DKV service address. Default options are used unless they
+// are overridden by the given DKVClientOption instances.
+func NewInSecureDKVClient(svcAddr string, opts ...DKVClientOption) (*DKVClient, error) {
+	return dialDKVClient(svcAddr, grpc.WithInsecure(), opts...)
+}
+
+// NewTLSDKVClient creates a GRPC client that communicates over TLS with
+// the given DKV service address. The server certificate is verified
+// against the CAs in `caFile` or the system CAs if `caFile` is empty.
+// If both `certFile` and `keyFile` are provided, they are presented to
+// the server as the client certificate for mutual TLS.
+func NewTLSDKVClient(svcAddr, certFile, keyFile, caFile string, opts ...DKVClientOption) (*DKVClient, error) {
+	tlsConf, err := newClientTLSConfig(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return dialDKVClient(svcAddr, grpc.WithTransportCredentials(credentials.NewTLS(tlsConf)), opts...)
+}
+
+func dialDKVClient(svcAddr string, transportOpt grpc.DialOption, opts ...DKVClientOption) (*DKVClient, error) {
+	dkvCliOpts := newDKVClientOpts(opts...)
+	if dkvCliOpts.NonBlockingDial {
+		return connectDKVClient(svcAddr, dkvCliOpts, transportOpt)
+	}
+	return connectDKVClient(svcAddr, dkvCliOpts, transportOpt, grpc.WithBlock())
+}
+
+func connectDKVClient(svcAddr string, dkvCliOpts *DKVClientOpts, dialOpts ...grpc.DialOption) (*DKVClient, error) {
 	var dkvClnt *DKVClient
-	conn, err := grpc.Dial(svcAddr, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithReadBufferSize(ReadBufSize), grpc.WithWriteBufferSize(WriteBufSize))
-	if err == nil {
-		dkvCli := serverpb.NewDKVClient(conn)
-		dkvReplCli := serverpb.NewDKVReplicationClient(conn)
-		dkvBRCli := serverpb.NewDKVBackupRestoreClient(conn)
-		dkvClusCli := serverpb.NewDKVClusterClient(conn)
-		dkvClnt = &DKVClient{conn, dkvCli, dkvReplCli, dkvBRCli, dkvClusCli}
+	dialOpts = append(dialOpts, grpc.WithReadBufferSize(dkvCliOpts.ReadBufSize), grpc.WithWriteBufferSize(dkvCliOpts.WriteBufSize))
+	dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(dkvCliOpts.MaxRecvMsgSize), grpc.MaxCallSendMsgSize(dkvCliOpts.MaxSendMsgSize)))
+	if dkvCliOpts.KeepaliveTime > 0 {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                dkvCliOpts.KeepaliveTime,
+			Timeout:             dkvCliOpts.KeepaliveTimeout,
+			PermitWithoutStream: dkvCliOpts.KeepalivePermitWithoutStream,
+		}))
+	}
+	// Retries of a call are traced within its span
+	if dkvCliOpts.Tracing {
+		dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor()), grpc.WithChainStreamInterceptor(tracing.StreamClientInterceptor()))
+	}
+	if dkvCliOpts.WaitForReady {
+		dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(waitForReadyInterceptor))
+	}
+	// Calls are retried only as per the options of their views otherwise
+	retryPolicy := dkvCliOpts.RetryPolicy
+	if retryPolicy == nil {
+		noRetryPolicy := DefaultRetryPolicy
+		noRetryPolicy.MaxAttempts = 1
+		retryPolicy = &noRetryPolicy
+	}
+	dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(retryPolicy.unaryInterceptor(dkvCliOpts.Logger)))
+	if dkvCliOpts.AuthToken != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(tokenCredentials(dkvCliOpts.AuthToken)))
+	}
+	// Leaders are dialed alike, except that they are not followed any further
+	var ldrFlwr *leaderFollower
+	if dkvCliOpts.FollowLeader {
+		ldrFlwr = newLeaderFollower(dialOpts, dkvCliOpts.Logger)
+		dialOpts = append([]grpc.DialOption{grpc.WithChainUnaryInterceptor(ldrFlwr.unaryInterceptor)}, dialOpts...)
+	}
+	ctx := context.Background()
+	if dkvCliOpts.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dkvCliOpts.DialTimeout)
+		defer cancel()
+	}
+	// Only blocking dials are bounded by the context
+	conn, err := grpc.DialContext(ctx, svcAddr, dialOpts...)
+	if err != nil {
+		err = fmt.Errorf("unable to connect to DKV service at %s: %w", svcAddr, err)
+	} else {
+		dkvClnt = &DKVClient{cliConn: conn, opts: dkvCliOpts, ldrFlwr: ldrFlwr}
+		dkvClnt.setStubs(conn)
+	}
+	return dkvClnt, err
+}
+
+// setStubs creates the clients of the various DKV services, which make
+// their calls over the given connection.
+func (dkvClnt *DKVClient) setStubs(conn grpc.ClientConnInterface) {
+	dkvClnt.dkvCli = serverpb.NewDKVClient(conn)
+	dkvClnt.dkvReplCli = serverpb.NewDKVReplicationClient(conn)
+	dkvClnt.dkvRSCli = serverpb.NewDKVReplicationStatusClient(conn)
+	dkvClnt.dkvBRCli = serverpb.NewDKVBackupRestoreClient(conn)
+	dkvClnt.dkvClusCli = serverpb.NewDKVClusterClient(conn)
+	dkvClnt.dkvFOCli = serverpb.NewDKVFailoverClient(conn)
+	dkvClnt.dkvRCCli = serverpb.NewDKVReplicationControlClient(conn)
+	dkvClnt.dkvLimCli = serverpb.NewDKVLimitsClient(conn)
+	dkvClnt.dkvStorCli = serverpb.NewDKVStorageClient(conn)
+	dkvClnt.dkvInfoCli = serverpb.NewDKVInfoClient(conn)
+	dkvClnt.dkvMntCli = serverpb.NewDKVMaintenanceClient(conn)
+	dkvClnt.dkvSecCli = serverpb.NewDKVSecurityClient(conn)
+	dkvClnt.hlthCli = grpc_health_v1.NewHealthClient(conn)
+}
+
+// waitForReadyInterceptor makes the idempotent calls wait for the
+// connection to be ready, since they are safe to be sent late.
+func waitForReadyInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if idempotentMethods[method] {
+		opts = append(opts, grpc.WaitForReady(true))
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func newClientTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	tlsConf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		caPEM, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("unable to load any certificates from the given CA file")
+		}
+		tlsConf.RootCAs = certPool
+	}
+	switch {
+	case certFile != "" && keyFile != "":
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	case certFile != "" || keyFile != "":
+		return nil, errors.New("both certificate and key files are required for mutual TLS")
+	}
+	return tlsConf, nil
+}
+
+// tokenCredentials attaches the bearer token onto the metadata of
+// every call. The token is sent over insecure connections as well,
+// since DKV nodes may be served without TLS within trusted networks.
+type tokenCredentials string
+
+func (tc tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(tc)}, nil
+}
+
+func (tc tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// Put takes the key and value as byte arrays and invokes the
+// GRPC Put method. This is a convenience wrapper.
+func (dkvClnt *DKVClient) Put(key []byte, value []byte) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.PutWithCtx(ctx, key, value)
+}
+
+// PutWithCtx is same as Put except that the GRPC Put method is
+// invoked using the given context.
+func (dkvClnt *DKVClient) PutWithCtx(ctx context.Context, key []byte, value []byte) error {
+	return dkvClnt.put(ctx, &serverpb.PutRequest{Key: key, Value: value, Namespace: dkvClnt.namespace})
+}
+
+// PutTTL is same as Put except that the given key expires after the
+// given TTL, beyond which it is no longer visible to any of the reads.
+// Since the expiry is recorded as an absolute time in unix seconds, it
+// is rounded up to the next whole second. This is a convenience wrapper.
+func (dkvClnt *DKVClient) PutTTL(key, value []byte, ttl time.Duration) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.PutTTLWithCtx(ctx, key, value, ttl)
+}
+
+// PutTTLWithCtx is same as PutTTL except that the GRPC Put method is
+// invoked using the given context.
+func (dkvClnt *DKVClient) PutTTLWithCtx(ctx context.Context, key, value []byte, ttl time.Duration) error {
+	expireTS := time.Now().Add(ttl + time.Second - 1).Unix()
+	return dkvClnt.put(ctx, &serverpb.PutRequest{Key: key, Value: value, ExpireTS: uint64(expireTS), Namespace: dkvClnt.namespace})
+}
+
+// PutSync is same as Put except that the master node persists the key
+// onto its disk before acknowledging it, irrespective of its default
+// sync mode. This is a convenience wrapper.
+func (dkvClnt *DKVClient) PutSync(key []byte, value []byte) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.PutSyncWithCtx(ctx, key, value)
+}
+
+// PutSyncWithCtx is same as PutSync except that the GRPC Put method is
+// invoked using the given context.
+func (dkvClnt *DKVClient) PutSyncWithCtx(ctx context.Context, key []byte, value []byte) error {
+	return dkvClnt.put(ctx, &serverpb.PutRequest{Key: key, Value: value, Namespace: dkvClnt.namespace, SyncMode: serverpb.SyncMode_Sync})
+}
+
+// PutAsync is same as Put except that the master node acknowledges the
+// key before persisting it onto its disk, so that the key may be lost
+// upon a crash of the node. The key is replicated all the same. This
+// is a convenience wrapper.
+func (dkvClnt *DKVClient) PutAsync(key []byte, value []byte) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.PutAsyncWithCtx(ctx, key, value)
+}
+
+// PutAsyncWithCtx is same as PutAsync except that the GRPC Put method
+// is invoked using the given context.
+func (dkvClnt *DKVClient) PutAsyncWithCtx(ctx context.Context, key []byte, value []byte) error {
+	return dkvClnt.put(ctx, &serverpb.PutRequest{Key: key, Value: value, Namespace: dkvClnt.namespace, SyncMode: serverpb.SyncMode_Async})
+}
+
+func (dkvClnt *DKVClient) put(ctx context.Context, putReq *serverpb.PutRequest) error {
+	putReq.Value = dkvClnt.compress(putReq.Value)
+	res, err := dkvClnt.dkvCli.Put(ctx, putReq)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	return errorFromStatus(status, err)
+}
+
+// A KVPair represents an association between a key and its
+// value, both captured as byte arrays. Err is set only on the
+// pairs streamed by Iterate, to indicate a failed iteration.
+// Truncated is set on the pairs streamed by Iterate whose values
+// exceed the size given to WithMaxValueSize.
+type KVPair struct {
+	Key       []byte
+	Value     []byte
+	Err       error
+	Truncated bool
+}
+
+// MultiPut takes the given key value pairs and invokes the GRPC
+// MultiPut method, so that all of them are stored atomically.
+// This is a convenience wrapper.
+func (dkvClnt *DKVClient) MultiPut(pairs ...KVPair) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.MultiPutWithCtx(ctx, pairs...)
+}
+
+// MultiPutWithCtx is same as MultiPut except that the GRPC MultiPut
+// method is invoked using the given context.
+func (dkvClnt *DKVClient) MultiPutWithCtx(ctx context.Context, pairs ...KVPair) error {
+	putReqs := make([]*serverpb.PutRequest, len(pairs))
+	for i, pair := range pairs {
+		putReqs[i] = &serverpb.PutRequest{Key: pair.Key, Value: dkvClnt.compress(pair.Value), Namespace: dkvClnt.namespace}
+	}
+	multiPutReq := &serverpb.MultiPutRequest{PutRequests: putReqs}
+	res, err := dkvClnt.dkvCli.MultiPut(ctx, multiPutReq)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	return errorFromStatus(status, err)
+}
+
+// CompareAndSet takes the key along with the expected and new
+// values as byte arrays and invokes the GRPC CompareAndSet method.
+// It returns true only if the current value of the key matched the
+// expected value and the new value has been set. A nil expected
+// value sets the new value only if the key is absent. With compression,
+// the expected value must have been written using the same codec since
+// it is matched in its compressed form. This is a convenience wrapper.
+func (dkvClnt *DKVClient) CompareAndSet(key, expectedValue, newValue []byte) (bool, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.CompareAndSetWithCtx(ctx, key, expectedValue, newValue)
+}
+
+// CompareAndSetWithCtx is same as CompareAndSet except that the GRPC
+// CompareAndSet method is invoked using the given context.
+func (dkvClnt *DKVClient) CompareAndSetWithCtx(ctx context.Context, key, expectedValue, newValue []byte) (bool, error) {
+	casReq := &serverpb.CompareAndSetRequest{Key: key, ExpectedValue: dkvClnt.compress(expectedValue), NewValue: dkvClnt.compress(newValue), Namespace: dkvClnt.namespace}
+	res, err := dkvClnt.dkvCli.CompareAndSet(ctx, casReq)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return false, err
+	}
+	return res.Updated, nil
+}
+
+// ErrNonNumericValue is returned by Increment when the current value
+// of the key is not a big-endian encoded 64 bit integer.
+var ErrNonNumericValue = dkverrors.ErrNonNumericValue
+
+// ErrKeyTooLarge and ErrValueTooLarge are returned by the mutations
+// whose key or value exceeds the maximum size permitted by the DKV node.
+var (
+	ErrKeyTooLarge   = dkverrors.ErrKeyTooLarge
+	ErrValueTooLarge = dkverrors.ErrValueTooLarge
+)
+
+// ErrInvalidKey is returned by the mutations whose key violates the key
+// policy of the DKV node, such as by not matching its pattern.
+var ErrInvalidKey = dkverrors.ErrInvalidKey
+
+// ErrQuotaExceeded is returned by the mutations that would take their
+// namespace beyond its quota on the DKV node.
+var ErrQuotaExceeded = dkverrors.ErrQuotaExceeded
+
+// Increment takes the key as byte array along with the delta and
+// invokes the GRPC Increment method. It returns the value of the key
+// after atomically adding the delta to it, considering absent keys to
+// be zero. This is a convenience wrapper.
+func (dkvClnt *DKVClient) Increment(key []byte, delta int64) (int64, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.IncrementWithCtx(ctx, key, delta)
+}
+
+// IncrementWithCtx is same as Increment except that the GRPC
+// Increment method is invoked using the given context.
+func (dkvClnt *DKVClient) IncrementWithCtx(ctx context.Context, key []byte, delta int64) (int64, error) {
+	incReq := &serverpb.IncrementRequest{Key: key, Delta: delta, Namespace: dkvClnt.namespace}
+	res, err := dkvClnt.dkvCli.Increment(ctx, incReq)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return 0, err
+	}
+	return res.Value, nil
+}
+
+// Delete takes the key as byte array and invokes the
+// GRPC Delete method. This is a convenience wrapper.
+func (dkvClnt *DKVClient) Delete(key []byte) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.DeleteWithCtx(ctx, key)
+}
+
+// DeleteWithCtx is same as Delete except that the GRPC Delete
+// method is invoked using the given context.
+func (dkvClnt *DKVClient) DeleteWithCtx(ctx context.Context, key []byte) error {
+	delReq := &serverpb.DeleteRequest{Key: key, Namespace: dkvClnt.namespace}
+	res, err := dkvClnt.dkvCli.Delete(ctx, delReq)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	return errorFromStatus(status, err)
+}
+
+// Undelete takes the key as byte array and invokes the
+// GRPC Undelete method, restoring the key deleted within
+// the tombstone retention of the server. This is a
+// convenience wrapper.
+func (dkvClnt *DKVClient) Undelete(key []byte) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.UndeleteWithCtx(ctx, key)
+}
+
+// UndeleteWithCtx is same as Undelete except that the GRPC
+// Undelete method is invoked using the given context.
+func (dkvClnt *DKVClient) UndeleteWithCtx(ctx context.Context, key []byte) error {
+	undelReq := &serverpb.UndeleteRequest{Key: key, Namespace: dkvClnt.namespace}
+	res, err := dkvClnt.dkvCli.Undelete(ctx, undelReq)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	return errorFromStatus(status, err)
+}
+
+// Get takes the key as byte array and invokes the
+// GRPC Get method. ErrKeyNotFound is returned if the
+// key is absent. This is a convenience wrapper.
+func (dkvClnt *DKVClient) Get(key []byte) (*serverpb.GetResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.GetWithCtx(ctx, key)
+}
+
+// GetWithCtx is same as Get except that the GRPC Get method is
+// invoked using the given context.
+func (dkvClnt *DKVClient) GetWithCtx(ctx context.Context, key []byte) (*serverpb.GetResponse, error) {
+	getReq := &serverpb.GetRequest{Key: key, Namespace: dkvClnt.namespace}
+	return dkvClnt.getResult(dkvClnt.dkvCli.Get(ctx, getReq))
+}
+
+// ErrKeyNotFound is returned by the reads of a single key when the
+// key is absent, as opposed to being associated with an empty value.
+var ErrKeyNotFound = dkverrors.ErrKeyNotFound
+
+func (dkvClnt *DKVClient) getResult(res *serverpb.GetResponse, err error) (*serverpb.GetResponse, error) {
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	switch err = errorFromStatus(status, err); {
+	case err != nil:
+		return nil, err
+	case !res.Found:
+		return nil, ErrKeyNotFound
+	default:
+		if res.Value, err = dkvClnt.decompress(res.Value); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+}
+
+// ErrStaleRead is returned for reads bounded by a permissible lag
+// when the slave node lags behind its master node by more than this
+// lag. Such reads can instead be served by the master node.
+var ErrStaleRead = dkverrors.ErrStaleRead
+
+// GetWithConsistency takes the key as byte array and invokes the GRPC
+// Get method, such that a slave node serves it only if it lags behind
+// its master node by atmost maxLag changes. Otherwise ErrStaleRead is
+// returned. A zero maxLag reads only the slave nodes that have caught
+// up with their master node. This is a convenience wrapper.
+func (dkvClnt *DKVClient) GetWithConsistency(key []byte, maxLag uint64) (*serverpb.GetResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.GetWithConsistencyWithCtx(ctx, key, maxLag)
+}
+
+// GetWithConsistencyWithCtx is same as GetWithConsistency except that
+// the GRPC Get method is invoked using the given context.
+func (dkvClnt *DKVClient) GetWithConsistencyWithCtx(ctx context.Context, key []byte, maxLag uint64) (*serverpb.GetResponse, error) {
+	getReq := &serverpb.GetRequest{Key: key, MaxLag: maxLag, LagBounded: true, Namespace: dkvClnt.namespace}
+	return dkvClnt.getResult(dkvClnt.dkvCli.Get(ctx, getReq))
+}
+
+// ErrLinearizableReadUnsupported is returned for linearizable reads
+// by slave nodes, which can not serve them unless promoted. Such reads
+// can instead be served by the master node.
+var ErrLinearizableReadUnsupported = dkverrors.ErrLinearizableReadUnsupported
+
+// GetLinearizable takes the key as byte array and invokes the GRPC Get
+// method, such that the value read reflects every change committed by
+// the cluster before the read began, including those not yet applied
+// onto the DKV node being read. Slave nodes reject such reads with
+// ErrLinearizableReadUnsupported. This is a convenience wrapper.
+func (dkvClnt *DKVClient) GetLinearizable(key []byte) (*serverpb.GetResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.GetLinearizableWithCtx(ctx, key)
+}
+
+// GetLinearizableWithCtx is same as GetLinearizable except that the
+// GRPC Get method is invoked using the given context.
+func (dkvClnt *DKVClient) GetLinearizableWithCtx(ctx context.Context, key []byte) (*serverpb.GetResponse, error) {
+	getReq := &serverpb.GetRequest{Key: key, Namespace: dkvClnt.namespace, ReadConsistency: serverpb.ReadConsistency_Linearizable}
+	return dkvClnt.getResult(dkvClnt.dkvCli.Get(ctx, getReq))
+}
+
+// MultiGet takes the keys as byte arrays and invokes the
+// GRPC MultiGet method, returning the values of every given key
+// in the same order. Values of the absent keys are nil, whereas
+// those of the keys with empty values are empty yet non-nil.
+// Large sets of keys are split across multiple concurrent requests
+// as per the MultiGet options of the client, in which case the
+// values are not read from a single snapshot. This is a convenience
+// wrapper.
+func (dkvClnt *DKVClient) MultiGet(keys ...[]byte) ([][]byte, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.MultiGetWithCtx(ctx, keys...)
+}
+
+// ErrMalformedResponse is returned by the reads of multiple keys
+// when the DKV node does not respond with the results of every key.
+var ErrMalformedResponse = dkverrors.ErrMalformedResponse
+
+// MultiGetWithCtx is same as MultiGet except that the GRPC MultiGet
+// method is invoked using the given context.
+func (dkvClnt *DKVClient) MultiGetWithCtx(ctx context.Context, keys ...[]byte) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	err := dkvClnt.forEachChunk(ctx, keys, func(ctx context.Context, chunk keyChunk) error {
+		vals, err := dkvClnt.multiGet(ctx, keys[chunk.start:chunk.end])
+		copy(values[chunk.start:chunk.end], vals)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// A KVResult represents the result of reading a key through
+// MultiGetDetailed. Value is nil for the absent keys, whereas Err
+// is set only for the keys that failed to be read.
+type KVResult struct {
+	Key   []byte
+	Value []byte
+	Found bool
+	Err   error
+}
+
+// MultiGetDetailed is same as MultiGet except that it returns the
+// result of every given key in the same order, such that the keys
+// failing to be read do not fail the reads of the others. Only the
+// failure of the call as a whole is returned as an error. This is
+// a convenience wrapper.
+func (dkvClnt *DKVClient) MultiGetDetailed(keys ...[]byte) ([]KVResult, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.MultiGetDetailedWithCtx(ctx, keys...)
+}
+
+// MultiGetDetailedWithCtx is same as MultiGetDetailed except that
+// the GRPC MultiGet method is invoked using the given context.
+func (dkvClnt *DKVClient) MultiGetDetailedWithCtx(ctx context.Context, keys ...[]byte) ([]KVResult, error) {
+	results := make([]KVResult, len(keys))
+	err := dkvClnt.forEachChunk(ctx, keys, func(ctx context.Context, chunk keyChunk) error {
+		return dkvClnt.multiGetDetailed(ctx, keys[chunk.start:chunk.end], results[chunk.start:chunk.end])
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// forEachChunk splits the given keys into chunks as per the MultiGet
+// options of the client, invoking the given read for every chunk
+// concurrently. Remaining chunks are abandoned upon the first failure,
+// which is returned.
+func (dkvClnt *DKVClient) forEachChunk(ctx context.Context, keys [][]byte, read func(context.Context, keyChunk) error) error {
+	chunks := chunkKeys(keys, dkvClnt.opts.MultiGetMaxKeys, dkvClnt.opts.MultiGetMaxBytes)
+	if len(chunks) <= 1 {
+		return read(ctx, keyChunk{0, len(keys)})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	concurrency := dkvClnt.opts.MultiGetConcurrency
+	if concurrency <= 0 || concurrency > len(chunks) {
+		concurrency = len(chunks)
+	}
+	pending := make(chan keyChunk, len(chunks))
+	for _, chunk := range chunks {
+		pending <- chunk
+	}
+	close(pending)
+	errs := make(chan error, concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			for chunk := range pending {
+				if err := read(ctx, chunk); err != nil {
+					cancel()
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	var firstErr error
+	for i := 0; i < concurrency; i++ {
+		// Errors of the chunks abandoned are merely due to the cancellation
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// keyChunk is the range [start, end) of the keys of a MultiGet
+// that are sent in a single request.
+type keyChunk struct {
+	start, end int
+}
+
+// chunkKeys splits the given keys into consecutive chunks of atmost
+// maxKeys keys and maxBytes bytes of keys each, where zero disables
+// the respective bound. Every chunk has atleast one key.
+func chunkKeys(keys [][]byte, maxKeys, maxBytes int) []keyChunk {
+	var chunks []keyChunk
+	start, numBytes := 0, 0
+	for i, key := range keys {
+		// Accounts for the tag and the length prefix of every key
+		keyBytes := len(key) + 1 + varintSize(len(key))
+		if i > start && ((maxKeys > 0 && i-start >= maxKeys) || (maxBytes > 0 && numBytes+keyBytes > maxBytes)) {
+			chunks = append(chunks, keyChunk{start, i})
+			start, numBytes = i, 0
+		}
+		numBytes += keyBytes
+	}
+	if start < len(keys) {
+		chunks = append(chunks, keyChunk{start, len(keys)})
+	}
+	return chunks
+}
+
+func varintSize(n int) int {
+	size := 1
+	for ; n >= 0x80; n >>= 7 {
+		size++
+	}
+	return size
+}
+
+func (dkvClnt *DKVClient) multiGet(ctx context.Context, keys [][]byte) ([][]byte, error) {
+	multiGetReq := &serverpb.MultiGetRequest{Keys: keys, Namespace: dkvClnt.namespace}
+	res, err := dkvClnt.dkvCli.MultiGet(ctx, multiGetReq)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	if len(res.Values) != len(keys) || len(res.Found) != len(keys) {
+		return nil, fmt.Errorf("MultiGet response carries %d values for %d keys: %w", len(res.Values), len(keys), ErrMalformedResponse)
+	}
+	values := make([][]byte, len(keys))
+	for i := range values {
+		if res.Found[i] {
+			if values[i], err = dkvClnt.foundValue(res.Values[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return values, nil
+}
+
+// multiGetDetailed reads the given keys through a single detailed
+// MultiGet request, setting their results onto the given ones.
+// DKV nodes unaware of detailed requests respond with the values
+// of the keys instead, which are read together or not at all.
+func (dkvClnt *DKVClient) multiGetDetailed(ctx context.Context, keys [][]byte, results []KVResult) error {
+	multiGetReq := &serverpb.MultiGetRequest{Keys: keys, Namespace: dkvClnt.namespace, Detailed: true}
+	res, err := dkvClnt.dkvCli.MultiGet(ctx, multiGetReq)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return err
+	}
+	if len(res.Results) == 0 && len(keys) > 0 {
+		if len(res.Values) != len(keys) || len(res.Found) != len(keys) {
+			return fmt.Errorf("MultiGet response carries %d values for %d keys: %w", len(res.Values), len(keys), ErrMalformedResponse)
+		}
+		for i, key := range keys {
+			results[i] = KVResult{Key: key, Found: res.Found[i]}
+			if res.Found[i] {
+				results[i].Value, results[i].Err = dkvClnt.foundValue(res.Values[i])
+			}
+		}
+		return nil
+	}
+	if len(res.Results) != len(keys) {
+		return fmt.Errorf("MultiGet response carries %d results for %d keys: %w", len(res.Results), len(keys), ErrMalformedResponse)
+	}
+	for i, key := range keys {
+		result := res.Results[i]
+		results[i] = KVResult{Key: key, Found: result.GetFound(), Err: dkverrors.FromStatus(result.GetStatus())}
+		if results[i].Found && results[i].Err == nil {
+			results[i].Value, results[i].Err = dkvClnt.foundValue(result.Value)
+		}
+	}
+	return nil
+}
+
+// foundValue decompresses the value of a key that is present, which
+// is empty yet non-nil for the keys with empty values.
+func (dkvClnt *DKVClient) foundValue(value []byte) ([]byte, error) {
+	value, err := dkvClnt.decompress(value)
+	if err == nil && value == nil {
+		value = []byte{}
+	}
+	return value, err
+}
+
+// Exists takes the keys as byte arrays and invokes the GRPC
+// Exists method, returning the presence of every given key
+// in the same order. This is a convenience wrapper.
+func (dkvClnt *DKVClient) Exists(keys ...[]byte) ([]bool, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.ExistsWithCtx(ctx, keys...)
+}
+
+// ExistsWithCtx is same as Exists except that the GRPC Exists
+// method is invoked using the given context.
+func (dkvClnt *DKVClient) ExistsWithCtx(ctx context.Context, keys ...[]byte) ([]bool, error) {
+	existsReq := &serverpb.ExistsRequest{Keys: keys, Namespace: dkvClnt.namespace}
+	res, err := dkvClnt.dkvCli.Exists(ctx, existsReq)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	if len(res.Exists) != len(keys) {
+		return nil, fmt.Errorf("Exists response carries %d results for %d keys: %w", len(res.Exists), len(keys), ErrMalformedResponse)
+	}
+	return res.Exists, nil
+}
+
+// An IterateOption restricts what is streamed by Iterate.
+type IterateOption func(*serverpb.IterateRequest)
+
+// WithKeysOnly streams the keys alone, with nil values, so that the
+// values are not even read by the DKV node.
+func WithKeysOnly() IterateOption {
+	return func(iterReq *serverpb.IterateRequest) {
+		iterReq.KeysOnly = true
+	}
+}
+
+// WithMaxValueSize truncates the values streamed to the given size,
+// setting Truncated on the pairs whose values are truncated. Such
+// values are not decompressed even if the client compresses them.
+func WithMaxValueSize(maxSize uint32) IterateOption {
+	return func(iterReq *serverpb.IterateRequest) {
+		iterReq.MaxValueSize = maxSize
+	}
+}
+
+// Iterate streams all the key value pairs whose keys match the given
+// prefix, beginning with the given start key if its not empty. Pairs
+// are streamed over the returned channel which is closed once the
+// iteration completes. Any error during iteration is streamed as a
+// pair with its Err field set. Note that the returned channel must be
+// drained fully, or IterateWithCtx must be used instead for cancelling
+// the iteration midway. This is a convenience wrapper.
+func (dkvClnt *DKVClient) Iterate(keyPrefix, startKey []byte, opts ...IterateOption) (<-chan KVPair, error) {
+	return dkvClnt.IterateWithCtx(context.Background(), keyPrefix, startKey, opts...)
+}
+
+// IterateWithCtx is same as Iterate except that the GRPC Iterate
+// method is invoked using the given context. Cancelling this context
+// stops the iteration and closes the returned channel.
+func (dkvClnt *DKVClient) IterateWithCtx(ctx context.Context, keyPrefix, startKey []byte, opts ...IterateOption) (<-chan KVPair, error) {
+	iterReq := &serverpb.IterateRequest{KeyPrefix: keyPrefix, StartKey: startKey, Namespace: dkvClnt.namespace}
+	for _, opt := range opts {
+		opt(iterReq)
+	}
+	iterCli, err := dkvClnt.dkvCli.Iterate(ctx, iterReq)
+	if err != nil {
+		return nil, err
+	}
+	pairs := make(chan KVPair)
+	go func() {
+		defer close(pairs)
+		for {
+			itRes, err := iterCli.Recv()
+			if err == io.EOF {
+				return
+			}
+			var status *serverpb.Status
+			if itRes != nil {
+				status = itRes.Status
+			}
+			pair := KVPair{Err: errorFromStatus(status, err)}
+			if pair.Err == nil {
+				pair.Key, pair.Value, pair.Truncated = itRes.Key, itRes.Value, itRes.Truncated
+				if !pair.Truncated {
+					pair.Value, pair.Err = dkvClnt.decompress(pair.Value)
+				}
+			}
+			select {
+			case pairs <- pair:
+			case <-ctx.Done():
+				return
+			}
+			if pair.Err != nil {
+				return
+			}
+		}
+	}()
+	return pairs, nil
+}
+
+// GetChanges retrieves changes since the given change number
+// using the underlying GRPC GetChanges method. One can limit the
+// number of changes retrieved using the maxNumChanges parameter.
+// Their total size can also be limited using a positive value for
+// the maxNumBytes parameter. Unlike the other wrappers, failures
+// conveyed by the status of the response are left to the callers,
+// such as slaves acting upon ChangesUnavailable. Views returned by
+// ForNamespace retrieve only the transactions on the keys of their
+// namespace. This is a convenience wrapper.
+func (dkvClnt *DKVClient) GetChanges(fromChangeNum uint64, maxNumChanges uint32, maxNumBytes uint64) (*serverpb.GetChangesResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.GetChangesWithCtx(ctx, fromChangeNum, maxNumChanges, maxNumBytes)
+}
+
+// GetChangesWithCtx is same as GetChanges except that the GRPC
+// GetChanges method is invoked using the given context.
+func (dkvClnt *DKVClient) GetChangesWithCtx(ctx context.Context, fromChangeNum uint64, maxNumChanges uint32, maxNumBytes uint64) (*serverpb.GetChangesResponse, error) {
+	return dkvClnt.GetChangesWithPrefixWithCtx(ctx, fromChangeNum, maxNumChanges, maxNumBytes, nil)
+}
+
+// GetChangesWithPrefixWithCtx is same as GetChangesWithCtx except that
+// only the transactions on the keys with the given prefix are retrieved,
+// with the changes left without any of them skipped altogether. Callers
+// resume from the NextChangeNumber of the response, which moves past
+// such skipped changes.
+func (dkvClnt *DKVClient) GetChangesWithPrefixWithCtx(ctx context.Context, fromChangeNum uint64, maxNumChanges uint32, maxNumBytes uint64, keyPrefix []byte) (*serverpb.GetChangesResponse, error) {
+	return dkvClnt.dkvReplCli.GetChanges(ctx, dkvClnt.newGetChangesRequest(fromChangeNum, maxNumChanges, maxNumBytes, keyPrefix))
+}
+
+// GetChunkedChangesWithCtx is same as GetChangesWithPrefixWithCtx except
+// that the changes too large for a response by themselves are retrieved
+// in chunks, beginning from the given offset within the change numbered
+// fromChangeNum. Callers reassemble such changes from their chunks, like
+// through storage.ChangeAssembler, resuming from the NextChunkOffset of
+// the response.
+func (dkvClnt *DKVClient) GetChunkedChangesWithCtx(ctx context.Context, fromChangeNum, fromChunkOffset uint64, maxNumChanges uint32, maxNumBytes uint64, keyPrefix []byte) (*serverpb.GetChangesResponse, error) {
+	getChngsReq := dkvClnt.newGetChangesRequest(fromChangeNum, maxNumChanges, maxNumBytes, keyPrefix)
+	getChngsReq.Chunked, getChngsReq.FromChunkOffset = true, fromChunkOffset
+	return dkvClnt.dkvReplCli.GetChanges(ctx, getChngsReq)
+}
+
+// StreamChangesWithCtx streams changes since the given change number
+// using the underlying GRPC StreamChanges method, in batches limited
+// by maxNumChanges and maxNumBytes similar to GetChanges. Since the
+// stream does not terminate on its own, cancelling the given context
+// is the means to close it.
+func (dkvClnt *DKVClient) StreamChangesWithCtx(ctx context.Context, fromChangeNum uint64, maxNumChanges uint32, maxNumBytes uint64) (serverpb.DKVReplication_StreamChangesClient, error) {
+	return dkvClnt.StreamChangesWithPrefixWithCtx(ctx, fromChangeNum, maxNumChanges, maxNumBytes, nil)
+}
+
+// StreamChangesWithPrefixWithCtx is same as StreamChangesWithCtx except
+// that only the transactions on the keys with the given prefix are
+// streamed, as per GetChangesWithPrefixWithCtx.
+func (dkvClnt *DKVClient) StreamChangesWithPrefixWithCtx(ctx context.Context, fromChangeNum uint64, maxNumChanges uint32, maxNumBytes uint64, keyPrefix []byte) (serverpb.DKVReplication_StreamChangesClient, error) {
+	return dkvClnt.dkvReplCli.StreamChanges(ctx, dkvClnt.newGetChangesRequest(fromChangeNum, maxNumChanges, maxNumBytes, keyPrefix))
+}
+
+// StreamChunkedChangesWithCtx is same as StreamChangesWithPrefixWithCtx
+// except that the changes too large for a response by themselves are
+// streamed in chunks, as per GetChunkedChangesWithCtx.
+func (dkvClnt *DKVClient) StreamChunkedChangesWithCtx(ctx context.Context, fromChangeNum, fromChunkOffset uint64, maxNumChanges uint32, maxNumBytes uint64, keyPrefix []byte) (serverpb.DKVReplication_StreamChangesClient, error) {
+	getChngsReq := dkvClnt.newGetChangesRequest(fromChangeNum, maxNumChanges, maxNumBytes, keyPrefix)
+	getChngsReq.Chunked, getChngsReq.FromChunkOffset = true, fromChunkOffset
+	return dkvClnt.dkvReplCli.StreamChanges(ctx, getChngsReq)
+}
+
+// newGetChangesRequest creates a request for the changes of the namespace
+// of this client, limited to the responses it can receive.
+func (dkvClnt *DKVClient) newGetChangesRequest(fromChangeNum uint64, maxNumChanges uint32, maxNumBytes uint64, keyPrefix []byte) *serverpb.GetChangesRequest {
+	return &serverpb.GetChangesRequest{FromChangeNumber: fromChangeNum, MaxNumberOfChanges: maxNumChanges, MaxNumberOfBytes: maxNumBytes, Namespace: dkvClnt.namespace, KeyPrefix: keyPrefix, ReplicaID: dkvClnt.opts.ReplicaID, MaxResponseSize: uint64(dkvClnt.opts.MaxRecvMsgSize)}
+}
+
+// GetCheckpointWithCtx streams a consistent checkpoint of the entire
+// keyspace using the underlying GRPC GetCheckpoint method. The first
+// response carries the change number as of which the checkpoint is
+// captured, followed by responses carrying the key value pairs.
+func (dkvClnt *DKVClient) GetCheckpointWithCtx(ctx context.Context) (serverpb.DKVReplication_GetCheckpointClient, error) {
+	return dkvClnt.dkvReplCli.GetCheckpoint(ctx, &serverpb.GetCheckpointRequest{})
+}
+
+// VerifyRange computes the checksum of the transactions of all the
+// changes from `fromChangeNum` upto `toChangeNum`, both inclusive, on
+// a DKV master or slave node using the underlying GRPC VerifyRange
+// method. Comparing these across nodes reveals any divergence amongst
+// them, without transferring their changes. This is a convenience
+// wrapper, for which large ranges may need a longer timeout.
+func (dkvClnt *DKVClient) VerifyRange(fromChangeNum, toChangeNum uint64) (*serverpb.VerifyRangeResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.VerifyRangeWithCtx(ctx, fromChangeNum, toChangeNum)
+}
+
+// VerifyRangeWithCtx is same as VerifyRange except that the GRPC
+// VerifyRange method is invoked using the given context.
+func (dkvClnt *DKVClient) VerifyRangeWithCtx(ctx context.Context, fromChangeNum, toChangeNum uint64) (*serverpb.VerifyRangeResponse, error) {
+	res, err := dkvClnt.dkvReplCli.VerifyRange(ctx, &serverpb.VerifyRangeRequest{FromChangeNumber: fromChangeNum, ToChangeNumber: toChangeNum})
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetChangeLogInfo retrieves the range of changes retained by a DKV
+// master node along with the progress of its replicas, using the
+// underlying GRPC GetChangeLogInfo method. This is a convenience wrapper.
+func (dkvClnt *DKVClient) GetChangeLogInfo() (*serverpb.GetChangeLogInfoResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.GetChangeLogInfoWithCtx(ctx)
+}
+
+// GetChangeLogInfoWithCtx is same as GetChangeLogInfo except that the
+// GRPC GetChangeLogInfo method is invoked using the given context.
+func (dkvClnt *DKVClient) GetChangeLogInfoWithCtx(ctx context.Context) (*serverpb.GetChangeLogInfoResponse, error) {
+	res, err := dkvClnt.dkvReplCli.GetChangeLogInfo(ctx, &serverpb.GetChangeLogInfoRequest{})
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListReplicas lists the replicas that identified themselves to a DKV
+// master node while retrieving its changes, along with their replication
+// lag, using the underlying GRPC ListReplicas method. This is a
+// convenience wrapper.
+func (dkvClnt *DKVClient) ListReplicas() (*serverpb.ListReplicasResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.ListReplicasWithCtx(ctx)
+}
+
+// ListReplicasWithCtx is same as ListReplicas except that the GRPC
+// ListReplicas method is invoked using the given context.
+func (dkvClnt *DKVClient) ListReplicasWithCtx(ctx context.Context) (*serverpb.ListReplicasResponse, error) {
+	res, err := dkvClnt.dkvReplCli.ListReplicas(ctx, &serverpb.ListReplicasRequest{})
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// TruncateChangeLog discards the changes retained by a DKV master node
+// before the given change number, or as per its retention policy if it
+// is zero, using the underlying GRPC TruncateChangeLog method. Returns
+// the change number of the oldest change retained thereafter. This is a
+// convenience wrapper.
+func (dkvClnt *DKVClient) TruncateChangeLog(beforeChangeNum uint64) (uint64, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.TruncateChangeLogWithCtx(ctx, beforeChangeNum)
+}
+
+// TruncateChangeLogWithCtx is same as TruncateChangeLog except that the
+// GRPC TruncateChangeLog method is invoked using the given context.
+func (dkvClnt *DKVClient) TruncateChangeLogWithCtx(ctx context.Context, beforeChangeNum uint64) (uint64, error) {
+	res, err := dkvClnt.dkvReplCli.TruncateChangeLog(ctx, &serverpb.TruncateChangeLogRequest{BeforeChangeNumber: beforeChangeNum})
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return 0, err
+	}
+	return res.FirstChangeNumber, nil
+}
+
+// KeyspaceDigest computes the digests of the ranges of keys of a DKV
+// master or slave node, as per the given request, using the underlying
+// GRPC KeyspaceDigest method. CompareReplicas uses these for comparing
+// nodes. This is a convenience wrapper.
+func (dkvClnt *DKVClient) KeyspaceDigest(req *serverpb.KeyspaceDigestRequest) (*serverpb.KeyspaceDigestResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.KeyspaceDigestWithCtx(ctx, req)
+}
+
+// KeyspaceDigestWithCtx is same as KeyspaceDigest except that the GRPC
+// KeyspaceDigest method is invoked using the given context.
+func (dkvClnt *DKVClient) KeyspaceDigestWithCtx(ctx context.Context, req *serverpb.KeyspaceDigestRequest) (*serverpb.KeyspaceDigestResponse, error) {
+	res, err := dkvClnt.dkvReplCli.KeyspaceDigest(ctx, req)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ReplicationStatus retrieves the status of replication from a DKV
+// slave node using the underlying GRPC GetStatus method. This is a
+// convenience wrapper.
+func (dkvClnt *DKVClient) ReplicationStatus() (*serverpb.GetStatusResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.ReplicationStatusWithCtx(ctx)
+}
+
+// ReplicationStatusWithCtx is same as ReplicationStatus except that
+// the GRPC GetStatus method is invoked using the given context.
+func (dkvClnt *DKVClient) ReplicationStatusWithCtx(ctx context.Context) (*serverpb.GetStatusResponse, error) {
+	res, err := dkvClnt.dkvRSCli.GetStatus(ctx, &serverpb.GetStatusRequest{})
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// PauseReplication pauses the replication on the slave node using the
+// underlying GRPC PauseReplication method. Replication is automatically
+// resumed after `autoResumeAfterSecs` seconds, unless it is zero. This
+// is a convenience wrapper.
+func (dkvClnt *DKVClient) PauseReplication(autoResumeAfterSecs uint32) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.PauseReplicationWithCtx(ctx, autoResumeAfterSecs)
+}
+
+// PauseReplicationWithCtx is same as PauseReplication except that the
+// GRPC PauseReplication method is invoked using the given context.
+func (dkvClnt *DKVClient) PauseReplicationWithCtx(ctx context.Context, autoResumeAfterSecs uint32) error {
+	pauseReq := &serverpb.PauseReplicationRequest{AutoResumeAfterSecs: autoResumeAfterSecs}
+	res, err := dkvClnt.dkvRCCli.PauseReplication(ctx, pauseReq)
+	return errorFromStatus(res, err)
+}
+
+// ResumeReplication resumes the paused replication on the slave node
+// using the underlying GRPC ResumeReplication method. This is a
+// convenience wrapper.
+func (dkvClnt *DKVClient) ResumeReplication() error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.ResumeReplicationWithCtx(ctx)
+}
+
+// ResumeReplicationWithCtx is same as ResumeReplication except that the
+// GRPC ResumeReplication method is invoked using the given context.
+func (dkvClnt *DKVClient) ResumeReplicationWithCtx(ctx context.Context) error {
+	res, err := dkvClnt.dkvRCCli.ResumeReplication(ctx, &serverpb.ResumeReplicationRequest{})
+	return errorFromStatus(res, err)
+}
+
+// EnterMaintenanceMode switches the DKV node into the read-only
+// maintenance mode for the given reason using the underlying GRPC
+// EnterMaintenanceMode method. Keyspace mutations are rejected with
+// ErrMaintenance until the node exits it, even across its restarts.
+// This is a convenience wrapper.
+func (dkvClnt *DKVClient) EnterMaintenanceMode(reason string) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.EnterMaintenanceModeWithCtx(ctx, reason)
+}
+
+// EnterMaintenanceModeWithCtx is same as EnterMaintenanceMode except that
+// the GRPC EnterMaintenanceMode method is invoked using the given context.
+func (dkvClnt *DKVClient) EnterMaintenanceModeWithCtx(ctx context.Context, reason string) error {
+	res, err := dkvClnt.dkvMntCli.EnterMaintenanceMode(ctx, &serverpb.EnterMaintenanceModeRequest{Reason: reason})
+	return errorFromStatus(res, err)
+}
+
+// ExitMaintenanceMode lets the DKV node accept keyspace mutations
+// again using the underlying GRPC ExitMaintenanceMode method. This
+// is a convenience wrapper.
+func (dkvClnt *DKVClient) ExitMaintenanceMode() error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.ExitMaintenanceModeWithCtx(ctx)
+}
+
+// ExitMaintenanceModeWithCtx is same as ExitMaintenanceMode except that
+// the GRPC ExitMaintenanceMode method is invoked using the given context.
+func (dkvClnt *DKVClient) ExitMaintenanceModeWithCtx(ctx context.Context) error {
+	res, err := dkvClnt.dkvMntCli.ExitMaintenanceMode(ctx, &serverpb.ExitMaintenanceModeRequest{})
+	return errorFromStatus(res, err)
+}
+
+// ReloadSecurityConfig makes the DKV node reload its TLS files and its
+// token file using the underlying GRPC ReloadSecurityConfig method. The
+// response, reporting what was reloaded, is returned along with the
+// error upon any validation errors, with none of the files reloaded
+// then. This is a convenience wrapper.
+func (dkvClnt *DKVClient) ReloadSecurityConfig() (*serverpb.ReloadSecurityConfigResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.ReloadSecurityConfigWithCtx(ctx)
+}
+
+// ReloadSecurityConfigWithCtx is same as ReloadSecurityConfig except that
+// the GRPC ReloadSecurityConfig method is invoked using the given context.
+func (dkvClnt *DKVClient) ReloadSecurityConfigWithCtx(ctx context.Context) (*serverpb.ReloadSecurityConfigResponse, error) {
+	res, err := dkvClnt.dkvSecCli.ReloadSecurityConfig(ctx, &serverpb.ReloadSecurityConfigRequest{})
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
 	}
-	return dkvClnt, err
+	return res, errorFromStatus(status, err)
 }
 
-// Put takes the key and value as byte arrays and invokes the
-// GRPC Put method. This is a convenience wrapper.
-func (dkvClnt *DKVClient) Put(key []byte, value []byte) error {
-	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+// PromoteToMaster promotes the slave node into a writable master
+// using the underlying GRPC PromoteToMaster method. It returns the
+// change number of the latest change applied on the slave node
+// before its promotion. This is a convenience wrapper.
+func (dkvClnt *DKVClient) PromoteToMaster() (uint64, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
 	defer cancel()
-	putReq := &serverpb.PutRequest{Key: key, Value: value}
-	res, err := dkvClnt.dkvCli.Put(ctx, putReq)
+	return dkvClnt.PromoteToMasterWithCtx(ctx)
+}
+
+// PromoteToMasterWithCtx is same as PromoteToMaster except that the
+// GRPC PromoteToMaster method is invoked using the given context.
+func (dkvClnt *DKVClient) PromoteToMasterWithCtx(ctx context.Context) (uint64, error) {
+	res, err := dkvClnt.dkvFOCli.PromoteToMaster(ctx, &serverpb.PromoteToMasterRequest{})
 	var status *serverpb.Status
 	if res != nil {
 		status = res.Status
 	}
-	return errorFromStatus(status, err)
+	if err = errorFromStatus(status, err); err != nil {
+		return 0, err
+	}
+	return res.AppliedChangeNumber, nil
 }
 
-// Get takes the key as byte array and invokes the
-// GRPC Get method. This is a convenience wrapper.
-func (dkvClnt *DKVClient) Get(key []byte) (*serverpb.GetResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+// GetLimits retrieves the limits on the GRPC calls served by the DKV
+// node using the underlying GRPC GetLimits method. This is a
+// convenience wrapper.
+func (dkvClnt *DKVClient) GetLimits() (*serverpb.Limits, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
 	defer cancel()
-	getReq := &serverpb.GetRequest{Key: key}
-	return dkvClnt.dkvCli.Get(ctx, getReq)
+	return dkvClnt.GetLimitsWithCtx(ctx)
 }
 
-// MultiGet takes the keys as byte arrays and invokes the
-// GRPC MultiGet method. This is a convenience wrapper.
-func (dkvClnt *DKVClient) MultiGet(keys ...[]byte) ([][]byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+// GetLimitsWithCtx is same as GetLimits except that the GRPC
+// GetLimits method is invoked using the given context.
+func (dkvClnt *DKVClient) GetLimitsWithCtx(ctx context.Context) (*serverpb.Limits, error) {
+	res, err := dkvClnt.dkvLimCli.GetLimits(ctx, &serverpb.GetLimitsRequest{})
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	return res.Limits, nil
+}
+
+// SetLimits replaces the limits on the GRPC calls served by the DKV
+// node using the underlying GRPC SetLimits method. This is a
+// convenience wrapper.
+func (dkvClnt *DKVClient) SetLimits(limits *serverpb.Limits) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
 	defer cancel()
-	multiGetReq := &serverpb.MultiGetRequest{Keys: keys}
-	res, err := dkvClnt.dkvCli.MultiGet(ctx, multiGetReq)
-	return res.Values, err
+	return dkvClnt.SetLimitsWithCtx(ctx, limits)
 }
 
-// GetChanges retrieves changes since the given change number
-// using the underlying GRPC GetChanges method. One can limit the
-// number of changes retrieved using the maxNumChanges parameter.
+// SetLimitsWithCtx is same as SetLimits except that the GRPC
+// SetLimits method is invoked using the given context.
+func (dkvClnt *DKVClient) SetLimitsWithCtx(ctx context.Context, limits *serverpb.Limits) error {
+	res, err := dkvClnt.dkvLimCli.SetLimits(ctx, &serverpb.SetLimitsRequest{Limits: limits})
+	return errorFromStatus(res, err)
+}
+
+// GetStorageStats retrieves the statistics of the storage engine of
+// the DKV node using the underlying GRPC GetStorageStats method. This
+// is a convenience wrapper.
+func (dkvClnt *DKVClient) GetStorageStats() (*serverpb.GetStorageStatsResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.GetStorageStatsWithCtx(ctx)
+}
+
+// GetStorageStatsWithCtx is same as GetStorageStats except that the
+// GRPC GetStorageStats method is invoked using the given context.
+func (dkvClnt *DKVClient) GetStorageStatsWithCtx(ctx context.Context) (*serverpb.GetStorageStatsResponse, error) {
+	res, err := dkvClnt.dkvStorCli.GetStorageStats(ctx, &serverpb.GetStorageStatsRequest{})
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ErrCompactionInProgress is returned when a compaction is requested
+// while another one is running on the DKV node.
+var ErrCompactionInProgress = dkverrors.ErrCompactionInProgress
+
+// CompactRange starts compacting the keys from the given start key to
+// the given end key on the DKV node, either end being unbounded if empty,
+// using the underlying GRPC CompactRange method. The compaction runs in
+// the background, whose progress is reported by GetStorageStats. This
+// is a convenience wrapper.
+func (dkvClnt *DKVClient) CompactRange(startKey, endKey []byte) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.CompactRangeWithCtx(ctx, startKey, endKey)
+}
+
+// CompactRangeWithCtx is same as CompactRange except that the GRPC
+// CompactRange method is invoked using the given context.
+func (dkvClnt *DKVClient) CompactRangeWithCtx(ctx context.Context, startKey, endKey []byte) error {
+	res, err := dkvClnt.dkvStorCli.CompactRange(ctx, &serverpb.CompactRangeRequest{StartKey: startKey, EndKey: endKey})
+	return errorFromStatus(res, err)
+}
+
+// Flush writes the keys buffered in the memory of the storage engine of
+// the DKV node onto its files using the underlying GRPC Flush method.
 // This is a convenience wrapper.
-func (dkvClnt *DKVClient) GetChanges(fromChangeNum uint64, maxNumChanges uint32) (*serverpb.GetChangesResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+func (dkvClnt *DKVClient) Flush() error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
 	defer cancel()
-	getChngsReq := &serverpb.GetChangesRequest{FromChangeNumber: fromChangeNum, MaxNumberOfChanges: maxNumChanges}
-	return dkvClnt.dkvReplCli.GetChanges(ctx, getChngsReq)
+	return dkvClnt.FlushWithCtx(ctx)
+}
+
+// FlushWithCtx is same as Flush except that the GRPC Flush method
+// is invoked using the given context.
+func (dkvClnt *DKVClient) FlushWithCtx(ctx context.Context) error {
+	res, err := dkvClnt.dkvStorCli.Flush(ctx, &serverpb.FlushRequest{})
+	return errorFromStatus(res, err)
+}
+
+// PrefixStats retrieves the number of keys with the given prefix on the
+// DKV node, along with the number of bytes these occupy, using the GRPC
+// PrefixStats method. Unless exact, these are estimated by the storage
+// engine. Exact stats iterate over the keys, for at most the BackupTimeout.
+// Views returned by ForNamespace retrieve the stats of the keys of their
+// namespace, along with its quota. This is a convenience wrapper.
+func (dkvClnt *DKVClient) PrefixStats(prefix []byte, exact bool) (*serverpb.PrefixStatsResponse, error) {
+	timeout := dkvClnt.opts.Timeout
+	if exact {
+		timeout = dkvClnt.opts.BackupTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return dkvClnt.PrefixStatsWithCtx(ctx, &serverpb.PrefixStatsRequest{Prefix: prefix, Exact: exact, Namespace: dkvClnt.namespace})
+}
+
+// PrefixStatsWithCtx is same as PrefixStats except that the GRPC
+// PrefixStats method is invoked with the given request and context.
+func (dkvClnt *DKVClient) PrefixStatsWithCtx(ctx context.Context, req *serverpb.PrefixStatsRequest) (*serverpb.PrefixStatsResponse, error) {
+	res, err := dkvClnt.dkvStorCli.PrefixStats(ctx, req)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SetQuota limits the given namespace to the given number of keys and
+// bytes, either of which is unlimited if zero, using the GRPC SetQuota
+// method. The quota of the namespace is removed if both are zero. This
+// is a convenience wrapper.
+func (dkvClnt *DKVClient) SetQuota(ns string, maxKeys, maxBytes uint64) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.SetQuotaWithCtx(ctx, ns, maxKeys, maxBytes)
+}
+
+// SetQuotaWithCtx is same as SetQuota except that the GRPC SetQuota
+// method is invoked using the given context.
+func (dkvClnt *DKVClient) SetQuotaWithCtx(ctx context.Context, ns string, maxKeys, maxBytes uint64) error {
+	res, err := dkvClnt.dkvStorCli.SetQuota(ctx, &serverpb.SetQuotaRequest{Namespace: ns, MaxKeys: maxKeys, MaxBytes: maxBytes})
+	return errorFromStatus(res, err)
+}
+
+// ListQuotas retrieves the quotas of all the namespaces along with their
+// usage, using the GRPC ListQuotas method. This is a convenience wrapper.
+func (dkvClnt *DKVClient) ListQuotas() ([]*serverpb.NamespaceQuota, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.ListQuotasWithCtx(ctx)
+}
+
+// ListQuotasWithCtx is same as ListQuotas except that the GRPC
+// ListQuotas method is invoked using the given context.
+func (dkvClnt *DKVClient) ListQuotasWithCtx(ctx context.Context) ([]*serverpb.NamespaceQuota, error) {
+	res, err := dkvClnt.dkvStorCli.ListQuotas(ctx, &serverpb.ListQuotasRequest{})
+	if err = errorFromStatus(res.GetStatus(), err); err != nil {
+		return nil, err
+	}
+	return res.Quotas, nil
+}
+
+// GetServerInfo describes the DKV node, like its role, storage engine
+// and build, using the underlying GRPC GetServerInfo method. This is a
+// convenience wrapper.
+func (dkvClnt *DKVClient) GetServerInfo() (*serverpb.GetServerInfoResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.GetServerInfoWithCtx(ctx)
+}
+
+// GetServerInfoWithCtx is same as GetServerInfo except that the GRPC
+// GetServerInfo method is invoked using the given context.
+func (dkvClnt *DKVClient) GetServerInfoWithCtx(ctx context.Context) (*serverpb.GetServerInfoResponse, error) {
+	res, err := dkvClnt.dkvInfoCli.GetServerInfo(ctx, &serverpb.GetServerInfoRequest{})
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return nil, err
+	}
+	return res, nil
 }
 
+// ErrBackupInProgress is returned when a backup or restore is
+// requested while another one is running on the DKV node.
+var ErrBackupInProgress = dkverrors.ErrBackupInProgress
+
+// Interval at which the status of backup and restore jobs, along with
+// that of decommissions, is polled.
+const statusPollInterval = 500 * time.Millisecond
+
 // Backup backs up the entire keyspace into the given filesystem
-// location using the underlying GRPC Backup method. This is a
+// location or object storage URI, such as s3://bucket/prefix, using
+// the underlying GRPC StartBackup method. Views returned by ForNamespace
+// back up only the keys of their namespace. It blocks until the
+// backup job completes, for at most the BackupTimeout. This is a
 // convenience wrapper.
 func (dkvClnt *DKVClient) Backup(path string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	return dkvClnt.BackupWithProgress(path, nil)
+}
+
+// BackupWithProgress is same as Backup except that the given function,
+// when not nil, is periodically invoked with the status of the job.
+func (dkvClnt *DKVClient) BackupWithProgress(path string, progress func(*serverpb.GetBackupStatusResponse)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dkvClnt.opts.BackupTimeout)
 	defer cancel()
-	backupReq := &serverpb.BackupRequest{BackupPath: path}
-	res, err := dkvClnt.dkvBRCli.Backup(ctx, backupReq)
-	return errorFromStatus(res, err)
+	return dkvClnt.BackupWithCtx(ctx, path, progress)
+}
+
+// BackupWithCtx is same as BackupWithProgress except that it waits
+// for the backup job until the given context is done.
+func (dkvClnt *DKVClient) BackupWithCtx(ctx context.Context, path string, progress func(*serverpb.GetBackupStatusResponse)) error {
+	jobID, err := dkvClnt.StartBackupWithCtx(ctx, path)
+	if err != nil {
+		return err
+	}
+	return dkvClnt.WaitForBackupJob(ctx, jobID, progress)
+}
+
+// StartBackup starts backing up the entire keyspace into the given
+// location, as per the semantics of Backup, without waiting for the
+// backup to complete. Its progress can be tracked using the returned
+// job ID. This is a convenience wrapper.
+func (dkvClnt *DKVClient) StartBackup(path string) (uint64, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.StartBackupWithCtx(ctx, path)
+}
+
+// StartBackupWithCtx is same as StartBackup except that the GRPC
+// StartBackup method is invoked using the given context.
+func (dkvClnt *DKVClient) StartBackupWithCtx(ctx context.Context, path string) (uint64, error) {
+	backupReq := &serverpb.BackupRequest{BackupPath: path, Namespace: dkvClnt.namespace}
+	res, err := dkvClnt.dkvBRCli.StartBackup(ctx, backupReq)
+	if err = errorFromStatus(res.GetStatus(), err); err != nil {
+		return 0, err
+	}
+	return res.JobID, nil
 }
 
 // Restore restores the entire keyspace from the given filesystem
-// location using the underlying GRPC Restore method. This is a
-// convenience wrapper.
+// location or object storage URI using the underlying GRPC
+// StartRestore method. Views returned by ForNamespace restore only the keys of
+// their namespace, from a backup taken by any such view. It blocks
+// until the restore job completes, for at most the BackupTimeout.
+// This is a convenience wrapper.
 func (dkvClnt *DKVClient) Restore(path string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	return dkvClnt.RestoreWithProgress(path, nil)
+}
+
+// RestoreWithProgress is same as Restore except that the given function,
+// when not nil, is periodically invoked with the status of the job.
+func (dkvClnt *DKVClient) RestoreWithProgress(path string, progress func(*serverpb.GetBackupStatusResponse)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dkvClnt.opts.BackupTimeout)
+	defer cancel()
+	return dkvClnt.RestoreWithCtx(ctx, path, progress)
+}
+
+// RestoreWithCtx is same as RestoreWithProgress except that it waits
+// for the restore job until the given context is done.
+func (dkvClnt *DKVClient) RestoreWithCtx(ctx context.Context, path string, progress func(*serverpb.GetBackupStatusResponse)) error {
+	jobID, err := dkvClnt.StartRestoreWithCtx(ctx, path)
+	if err != nil {
+		return err
+	}
+	return dkvClnt.WaitForBackupJob(ctx, jobID, progress)
+}
+
+// StartRestore starts restoring the entire keyspace from the given
+// location, as per the semantics of Restore, without waiting for the
+// restore to complete. Its progress can be tracked using the returned
+// job ID. This is a convenience wrapper.
+func (dkvClnt *DKVClient) StartRestore(path string) (uint64, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.StartRestoreWithCtx(ctx, path)
+}
+
+// StartRestoreWithCtx is same as StartRestore except that the GRPC
+// StartRestore method is invoked using the given context.
+func (dkvClnt *DKVClient) StartRestoreWithCtx(ctx context.Context, path string) (uint64, error) {
+	return dkvClnt.startRestore(ctx, &serverpb.RestoreRequest{RestorePath: path, Namespace: dkvClnt.namespace})
+}
+
+// VerifyRestore verifies the backup at the given location without
+// restoring it, by running a dry run of Restore using the underlying
+// GRPC StartRestore method, and describes the backup. It blocks until the
+// verification completes, for at most the BackupTimeout. This is a
+// convenience wrapper.
+func (dkvClnt *DKVClient) VerifyRestore(path string) (*serverpb.BackupInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dkvClnt.opts.BackupTimeout)
+	defer cancel()
+	return dkvClnt.VerifyRestoreWithCtx(ctx, path)
+}
+
+// VerifyRestoreWithCtx is same as VerifyRestore except that it waits
+// for the verification until the given context is done.
+func (dkvClnt *DKVClient) VerifyRestoreWithCtx(ctx context.Context, path string) (*serverpb.BackupInfo, error) {
+	jobID, err := dkvClnt.startRestore(ctx, &serverpb.RestoreRequest{RestorePath: path, Namespace: dkvClnt.namespace, DryRun: true})
+	if err != nil {
+		return nil, err
+	}
+	res, err := dkvClnt.waitForBackupJob(ctx, jobID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.BackupInfo, nil
+}
+
+func (dkvClnt *DKVClient) startRestore(ctx context.Context, restoreReq *serverpb.RestoreRequest) (uint64, error) {
+	res, err := dkvClnt.dkvBRCli.StartRestore(ctx, restoreReq)
+	if err = errorFromStatus(res.GetStatus(), err); err != nil {
+		return 0, err
+	}
+	return res.JobID, nil
+}
+
+// BackupStatus returns the status of the backup or restore job with
+// the given ID using the underlying GRPC GetBackupStatus method. This
+// is a convenience wrapper.
+func (dkvClnt *DKVClient) BackupStatus(jobID uint64) (*serverpb.GetBackupStatusResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
 	defer cancel()
-	restoreReq := &serverpb.RestoreRequest{RestorePath: path}
-	res, err := dkvClnt.dkvBRCli.Restore(ctx, restoreReq)
+	return dkvClnt.BackupStatusWithCtx(ctx, jobID)
+}
+
+// BackupStatusWithCtx is same as BackupStatus except that the GRPC
+// GetBackupStatus method is invoked using the given context.
+func (dkvClnt *DKVClient) BackupStatusWithCtx(ctx context.Context, jobID uint64) (*serverpb.GetBackupStatusResponse, error) {
+	res, err := dkvClnt.dkvBRCli.GetBackupStatus(ctx, &serverpb.GetBackupStatusRequest{JobID: jobID})
+	if err = errorFromStatus(res.GetStatus(), err); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WaitForBackupJob polls the status of the backup or restore job with
+// the given ID until it completes or the given context is done. The
+// given function, when not nil, is invoked with every status polled.
+// The error of the job is returned if it fails.
+func (dkvClnt *DKVClient) WaitForBackupJob(ctx context.Context, jobID uint64, progress func(*serverpb.GetBackupStatusResponse)) error {
+	_, err := dkvClnt.waitForBackupJob(ctx, jobID, progress)
+	return err
+}
+
+func (dkvClnt *DKVClient) waitForBackupJob(ctx context.Context, jobID uint64, progress func(*serverpb.GetBackupStatusResponse)) (*serverpb.GetBackupStatusResponse, error) {
+	for {
+		callCtx, cancel := context.WithTimeout(ctx, dkvClnt.opts.Timeout)
+		res, err := dkvClnt.BackupStatusWithCtx(callCtx, jobID)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(res)
+		}
+		switch res.State {
+		case serverpb.BackupJobState_Done:
+			return res, nil
+		case serverpb.BackupJobState_Failed:
+			return nil, fmt.Errorf("job: %d failed: %s", jobID, res.Error)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(statusPollInterval):
+		}
+	}
+}
+
+// Magic bytes at the beginning of every backup written by BackupTo.
+var streamedBackupMagic = []byte("DKVSTBK1")
+
+// BackupTo streams a backup of the entire keyspace into the given
+// writer using the underlying GRPC StreamBackup method. Views returned
+// by ForNamespace back up only the keys of their namespace. The backup
+// is written as a sequence of length delimited chunks, each carrying
+// a checksum of its entries, that can be restored using RestoreFrom.
+// This is a convenience wrapper.
+func (dkvClnt *DKVClient) BackupTo(w io.Writer) error {
+	return dkvClnt.BackupToWithCtx(context.Background(), w)
+}
+
+// BackupToWithCtx is same as BackupTo except that the GRPC StreamBackup
+// method is invoked using the given context.
+func (dkvClnt *DKVClient) BackupToWithCtx(ctx context.Context, w io.Writer) error {
+	bckpCli, err := dkvClnt.dkvBRCli.StreamBackup(ctx, &serverpb.StreamBackupRequest{Namespace: dkvClnt.namespace})
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(streamedBackupMagic); err != nil {
+		return err
+	}
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	for {
+		chunk, err := bckpCli.Recv()
+		if err == io.EOF {
+			return errors.New("streamed backup ended before its last chunk")
+		}
+		if err != nil {
+			return err
+		}
+		data, err := proto.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(lenBuf[:binary.PutUvarint(lenBuf, uint64(len(data)))]); err != nil {
+			return err
+		}
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+		if chunk.Last {
+			return nil
+		}
+	}
+}
+
+// RestoreFrom restores the entire keyspace from the backup read from
+// the given reader, which must have been written by BackupTo, using the
+// underlying GRPC StreamRestore method. Views returned by ForNamespace
+// restore only the keys of their namespace, from a backup written by
+// any such view. The keyspace is left untouched if the backup is found
+// to be truncated or corrupt. This is a convenience wrapper.
+func (dkvClnt *DKVClient) RestoreFrom(r io.Reader) error {
+	return dkvClnt.RestoreFromWithCtx(context.Background(), r)
+}
+
+// RestoreFromWithCtx is same as RestoreFrom except that the GRPC
+// StreamRestore method is invoked using the given context.
+func (dkvClnt *DKVClient) RestoreFromWithCtx(ctx context.Context, r io.Reader) error {
+	rdr := bufio.NewReader(r)
+	magic := make([]byte, len(streamedBackupMagic))
+	if _, err := io.ReadFull(rdr, magic); err != nil || !bytes.Equal(magic, streamedBackupMagic) {
+		return fmt.Errorf("given reader does not carry a streamed backup: %w", dkverrors.ErrInvalidArgument)
+	}
+	// Cancelling the stream prevents the restore of partial backups
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rstrCli, err := dkvClnt.dkvBRCli.StreamRestore(ctx)
+	if err != nil {
+		return err
+	}
+	for ns := dkvClnt.namespace; ; ns = "" {
+		chunkLen, err := binary.ReadUvarint(rdr)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data := make([]byte, chunkLen)
+		if _, err = io.ReadFull(rdr, data); err != nil {
+			return fmt.Errorf("truncated streamed backup: %w", dkverrors.ErrInvalidArgument)
+		}
+		chunk := new(serverpb.BackupChunk)
+		if err = proto.Unmarshal(data, chunk); err != nil {
+			return fmt.Errorf("corrupt streamed backup: %v: %w", err, dkverrors.ErrInvalidArgument)
+		}
+		if err = rstrCli.Send(&serverpb.StreamRestoreRequest{Namespace: ns, Chunk: chunk}); err != nil {
+			// Actual error is only reported on closing the stream
+			break
+		}
+	}
+	res, err := rstrCli.CloseAndRecv()
 	return errorFromStatus(res, err)
 }
 
+// Limits of every batch of entries sent by BulkLoad, which are kept
+// well within the default limits of GRPC on the size of messages.
+const (
+	bulkLoadBatchSize  = 1000
+	bulkLoadBatchBytes = 1 << 20
+)
+
+// BulkLoad writes the entries returned by the given function, until it
+// returns nil, using the underlying GRPC BulkLoad method. Entries are
+// written directly onto the storage of the master node without being
+// committed as changes, hence its slave nodes bootstrap themselves again
+// once the load finishes. Views returned by ForNamespace load the entries
+// onto their namespace. Entries written before the given function or the
+// load fails are retained. This is a convenience wrapper.
+func (dkvClnt *DKVClient) BulkLoad(next func() (*serverpb.PutRequest, error)) (*serverpb.BulkLoadResponse, error) {
+	return dkvClnt.BulkLoadWithCtx(context.Background(), next)
+}
+
+// BulkLoadWithCtx is same as BulkLoad except that the GRPC BulkLoad
+// method is invoked using the given context.
+func (dkvClnt *DKVClient) BulkLoadWithCtx(ctx context.Context, next func() (*serverpb.PutRequest, error)) (*serverpb.BulkLoadResponse, error) {
+	// Cancelling the stream aborts the load upon failures
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	loadCli, err := dkvClnt.dkvBRCli.BulkLoad(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*serverpb.PutRequest
+	numBytes := 0
+	for {
+		entry, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, &serverpb.PutRequest{Key: entry.Key, Value: dkvClnt.compress(entry.Value), ExpireTS: entry.ExpireTS, Namespace: dkvClnt.namespace})
+			numBytes += len(entry.Key) + len(entry.Value)
+		}
+		if len(entries) > 0 && (entry == nil || len(entries) >= bulkLoadBatchSize || numBytes >= bulkLoadBatchBytes) {
+			if err = loadCli.Send(&serverpb.BulkLoadRequest{Entries: entries}); err != nil {
+				// Actual error is only reported on closing the stream
+				break
+			}
+			entries, numBytes = nil, 0
+		}
+		if entry == nil {
+			break
+		}
+	}
+	res, err := loadCli.CloseAndRecv()
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	return res, errorFromStatus(status, err)
+}
+
 // AddNode adds the node with the given identifier and Nexus URL to
 // the Nexus cluster of which the current node is a member of.
 func (dkvClnt *DKVClient) AddNode(nodeID uint32, nodeURL string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	ctx, cancel := dkvClnt.newTimeoutContext()
 	defer cancel()
+	return dkvClnt.AddNodeWithCtx(ctx, nodeID, nodeURL)
+}
+
+// AddNodeWithCtx is same as AddNode except that the GRPC AddNode
+// method is invoked using the given context.
+func (dkvClnt *DKVClient) AddNodeWithCtx(ctx context.Context, nodeID uint32, nodeURL string) error {
 	addNodeReq := &serverpb.AddNodeRequest{NodeId: nodeID, NodeUrl: nodeURL}
 	res, err := dkvClnt.dkvClusCli.AddNode(ctx, addNodeReq)
 	return errorFromStatus(res, err)
@@ -122,25 +2047,322 @@ func (dkvClnt *DKVClient) AddNode(nodeID uint32, nodeURL string) error {
 // RemoveNode removes the node with the given identifier from the
 // Nexus cluster of which the current node is a member of.
 func (dkvClnt *DKVClient) RemoveNode(nodeID uint32) error {
-	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	ctx, cancel := dkvClnt.newTimeoutContext()
 	defer cancel()
+	return dkvClnt.RemoveNodeWithCtx(ctx, nodeID)
+}
+
+// RemoveNodeWithCtx is same as RemoveNode except that the GRPC
+// RemoveNode method is invoked using the given context.
+func (dkvClnt *DKVClient) RemoveNodeWithCtx(ctx context.Context, nodeID uint32) error {
 	remNodeReq := &serverpb.RemoveNodeRequest{NodeId: nodeID}
 	res, err := dkvClnt.dkvClusCli.RemoveNode(ctx, remNodeReq)
 	return errorFromStatus(res, err)
 }
 
+// DecommissionNode starts decommissioning the node with the given
+// identifier from the Nexus cluster of which the current node is a
+// member of. The node is removed once the leadership is transferred away
+// from it, if needed, and the remaining members catch up with its
+// changes, which must happen within the given timeout, zero for the
+// default timeout of the node. Its progress is tracked by
+// DecommissionStatus or WaitForDecommission. This is a convenience
+// wrapper.
+func (dkvClnt *DKVClient) DecommissionNode(nodeID uint32, timeout time.Duration) error {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.DecommissionNodeWithCtx(ctx, nodeID, timeout)
+}
+
+// DecommissionNodeWithCtx is same as DecommissionNode except that the
+// GRPC DecommissionNode method is invoked using the given context.
+func (dkvClnt *DKVClient) DecommissionNodeWithCtx(ctx context.Context, nodeID uint32, timeout time.Duration) error {
+	decomReq := &serverpb.DecommissionNodeRequest{NodeId: nodeID, TimeoutSecs: uint32((timeout + time.Second - 1) / time.Second)}
+	res, err := dkvClnt.dkvClusCli.DecommissionNode(ctx, decomReq)
+	return errorFromStatus(res, err)
+}
+
+// DecommissionStatus retrieves the progress of the latest decommission
+// of the node with the given identifier. This is a convenience wrapper.
+func (dkvClnt *DKVClient) DecommissionStatus(nodeID uint32) (*serverpb.GetDecommissionStatusResponse, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.DecommissionStatusWithCtx(ctx, nodeID)
+}
+
+// DecommissionStatusWithCtx is same as DecommissionStatus except that
+// the GRPC GetDecommissionStatus method is invoked using the given
+// context.
+func (dkvClnt *DKVClient) DecommissionStatusWithCtx(ctx context.Context, nodeID uint32) (*serverpb.GetDecommissionStatusResponse, error) {
+	res, err := dkvClnt.dkvClusCli.GetDecommissionStatus(ctx, &serverpb.GetDecommissionStatusRequest{NodeId: nodeID})
+	if err = errorFromStatus(res.GetStatus(), err); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WaitForDecommission polls the progress of the latest decommission of
+// the node with the given identifier until it completes or the given
+// context is done. The given function, when not nil, is invoked with
+// every status polled. The error of the decommission is returned if
+// it fails.
+func (dkvClnt *DKVClient) WaitForDecommission(ctx context.Context, nodeID uint32, progress func(*serverpb.GetDecommissionStatusResponse)) error {
+	for {
+		callCtx, cancel := context.WithTimeout(ctx, dkvClnt.opts.Timeout)
+		res, err := dkvClnt.DecommissionStatusWithCtx(callCtx, nodeID)
+		cancel()
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(res)
+		}
+		switch res.State {
+		case serverpb.DecommissionState_Decommissioned:
+			return nil
+		case serverpb.DecommissionState_DecommissionFailed:
+			return fmt.Errorf("decommission of node: %d failed: %s", nodeID, res.Error)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(statusPollInterval):
+		}
+	}
+}
+
+// ClusterBackup backs up every member of the Nexus cluster of which
+// the current node is a member of as of a common change number, under
+// the given filesystem location or object storage URI, using the
+// underlying GRPC ClusterBackup method. It must be invoked on the
+// leader of the cluster, and the location must be reachable from every
+// member. Writes onto the cluster are rejected with ErrBackupInProgress
+// until the backup completes, for at most the BackupTimeout. Returns
+// the manifest of the backups of the members. This is a convenience
+// wrapper.
+func (dkvClnt *DKVClient) ClusterBackup(location string) (*serverpb.ClusterBackupManifest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dkvClnt.opts.BackupTimeout)
+	defer cancel()
+	return dkvClnt.ClusterBackupWithCtx(ctx, location)
+}
+
+// ClusterBackupWithCtx is same as ClusterBackup except that the GRPC
+// ClusterBackup method is invoked using the given context, whose
+// deadline bounds the duration for which the writes are fenced.
+func (dkvClnt *DKVClient) ClusterBackupWithCtx(ctx context.Context, location string) (*serverpb.ClusterBackupManifest, error) {
+	res, err := dkvClnt.dkvClusCli.ClusterBackup(ctx, &serverpb.ClusterBackupRequest{Location: location})
+	if err = errorFromStatus(res.GetStatus(), err); err != nil {
+		return nil, err
+	}
+	return res.Manifest, nil
+}
+
+// ClusterRestore restores every member of a fresh Nexus cluster, of
+// which the current node is a member of, from the backups written by
+// ClusterBackup under the given location, using the underlying GRPC
+// ClusterRestore method. It must be invoked on the leader of the
+// cluster, whose members must have the same IDs as those backed up.
+// It blocks for at most the BackupTimeout. This is a convenience
+// wrapper.
+func (dkvClnt *DKVClient) ClusterRestore(location string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dkvClnt.opts.BackupTimeout)
+	defer cancel()
+	return dkvClnt.ClusterRestoreWithCtx(ctx, location)
+}
+
+// ClusterRestoreWithCtx is same as ClusterRestore except that the GRPC
+// ClusterRestore method is invoked using the given context.
+func (dkvClnt *DKVClient) ClusterRestoreWithCtx(ctx context.Context, location string) error {
+	res, err := dkvClnt.dkvClusCli.ClusterRestore(ctx, &serverpb.ClusterRestoreRequest{Location: location})
+	return errorFromStatus(res, err)
+}
+
+// FenceWritesWithCtx raises the given fence onto the current node for
+// the given TTL using the underlying GRPC FenceWrites method, and
+// returns the latest committed change number of the node. It is used
+// by the node coordinating ClusterBackup and ClusterRestore.
+func (dkvClnt *DKVClient) FenceWritesWithCtx(ctx context.Context, fenceID string, ttl time.Duration) (uint64, error) {
+	fenceReq := &serverpb.FenceWritesRequest{FenceId: fenceID, TtlSecs: uint32((ttl + time.Second - 1) / time.Second)}
+	res, err := dkvClnt.dkvClusCli.FenceWrites(ctx, fenceReq)
+	if err = errorFromStatus(res.GetStatus(), err); err != nil {
+		return 0, err
+	}
+	return res.ChangeNumber, nil
+}
+
+// UnfenceWritesWithCtx lifts the given fence from the current node
+// using the underlying GRPC UnfenceWrites method, along with removing
+// the backups written under it if asked to. It is used by the node
+// coordinating ClusterBackup and ClusterRestore.
+func (dkvClnt *DKVClient) UnfenceWritesWithCtx(ctx context.Context, fenceID string, removeBackups bool) error {
+	res, err := dkvClnt.dkvClusCli.UnfenceWrites(ctx, &serverpb.UnfenceWritesRequest{FenceId: fenceID, RemoveBackups: removeBackups})
+	return errorFromStatus(res, err)
+}
+
+// BackupMemberWithCtx backs up the current node into the given location
+// as of the given change number, under the given fence, using the
+// underlying GRPC BackupMember method. It is used by the node
+// coordinating ClusterBackup.
+func (dkvClnt *DKVClient) BackupMemberWithCtx(ctx context.Context, fenceID string, changeNum uint64, location string) (*serverpb.BackupInfo, error) {
+	bckpReq := &serverpb.BackupMemberRequest{FenceId: fenceID, ChangeNumber: changeNum, Location: location}
+	res, err := dkvClnt.dkvClusCli.BackupMember(ctx, bckpReq)
+	if err = errorFromStatus(res.GetStatus(), err); err != nil {
+		return nil, err
+	}
+	return res.Info, nil
+}
+
+// RestoreMemberWithCtx restores the current node from the backup at the
+// given location, which must be as of the given change number, under
+// the given fence, using the underlying GRPC RestoreMember method. It
+// is used by the node coordinating ClusterRestore.
+func (dkvClnt *DKVClient) RestoreMemberWithCtx(ctx context.Context, fenceID string, changeNum uint64, location string) error {
+	rstrReq := &serverpb.RestoreMemberRequest{FenceId: fenceID, ChangeNumber: changeNum, Location: location}
+	res, err := dkvClnt.dkvClusCli.RestoreMember(ctx, rstrReq)
+	return errorFromStatus(res, err)
+}
+
+// ListNodes lists the members of the Nexus cluster of which the
+// current node is a member of, in the order of their identifiers,
+// along with the identifier of its leader, which is zero if unknown
+// to the current node. This is a convenience wrapper.
+func (dkvClnt *DKVClient) ListNodes() (uint32, []*serverpb.NodeInfo, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.ListNodesWithCtx(ctx)
+}
+
+// ListNodesWithCtx is same as ListNodes except that the GRPC
+// ListNodes method is invoked using the given context.
+func (dkvClnt *DKVClient) ListNodesWithCtx(ctx context.Context) (uint32, []*serverpb.NodeInfo, error) {
+	res, err := dkvClnt.dkvClusCli.ListNodes(ctx, &serverpb.ListNodesRequest{})
+	if err = errorFromStatus(res.GetStatus(), err); err != nil {
+		return 0, nil, err
+	}
+	return res.LeaderId, res.Nodes, nil
+}
+
+// HealthCheck retrieves the serving status of the DKV node using the
+// underlying GRPC Check method of the standard health service. This
+// is a convenience wrapper.
+func (dkvClnt *DKVClient) HealthCheck() (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+	ctx, cancel := dkvClnt.newTimeoutContext()
+	defer cancel()
+	return dkvClnt.HealthCheckWithCtx(ctx)
+}
+
+// HealthCheckWithCtx is same as HealthCheck except that the GRPC
+// Check method is invoked using the given context.
+func (dkvClnt *DKVClient) HealthCheckWithCtx(ctx context.Context) (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+	res, err := dkvClnt.hlthCli.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return grpc_health_v1.HealthCheckResponse_UNKNOWN, err
+	}
+	return res.Status, nil
+}
+
+// ServiceAddr returns the address of the DKV service that this
+// client communicates with.
+func (dkvClnt *DKVClient) ServiceAddr() string {
+	return dkvClnt.cliConn.Target()
+}
+
+// ReplicaID returns the identity under which this client retrieves
+// changes, as set through WithReplicaID.
+func (dkvClnt *DKVClient) ReplicaID() string {
+	return dkvClnt.opts.ReplicaID
+}
+
+// ForNamespace returns a view of this client whose calls operate on
+// the keys of the given namespace alone, in isolation from the keys of
+// all the other namespaces. The empty namespace is the default one,
+// which is used by the clients not created through this method. The
+// view shares the underlying connection, hence closing either of them
+// closes both.
+func (dkvClnt *DKVClient) ForNamespace(ns string) *DKVClient {
+	nsClnt := *dkvClnt
+	nsClnt.namespace = ns
+	return &nsClnt
+}
+
+// Namespace returns the namespace on whose keys this client operates.
+func (dkvClnt *DKVClient) Namespace() string {
+	return dkvClnt.namespace
+}
+
+// KeyInNamespace returns the given key as stored by the DKV node, such
+// as the key of a change, without the prefix of the namespace of this
+// client, reporting whether it belongs to this namespace at all.
+func (dkvClnt *DKVClient) KeyInNamespace(key []byte) ([]byte, bool) {
+	return storage.InNamespace(dkvClnt.namespace, key)
+}
+
+// Connect waits for the DKVClient to connect to the DKV service within
+// the given context, which is useful for the clients created with
+// WithNonBlockingDial that need to wait for the service to be up.
+func (dkvClnt *DKVClient) Connect(ctx context.Context) error {
+	if err := dkvClnt.WaitForReady(ctx); err != nil {
+		return fmt.Errorf("unable to connect to DKV service at %s: %w", dkvClnt.cliConn.Target(), err)
+	}
+	return nil
+}
+
+// State returns the current state of the underlying GRPC connection.
+func (dkvClnt *DKVClient) State() connectivity.State {
+	return dkvClnt.cliConn.GetState()
+}
+
+// WaitForReady waits until the underlying GRPC connection is ready,
+// reconnecting if needed, or until the given context is done, in which
+// case the error of the context is returned.
+func (dkvClnt *DKVClient) WaitForReady(ctx context.Context) error {
+	for {
+		state := dkvClnt.cliConn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return errors.New("client is closed")
+		}
+		if !dkvClnt.cliConn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
+
 // Close closes the underlying GRPC client connection to DKV service
 func (dkvClnt *DKVClient) Close() error {
+	if dkvClnt.ldrFlwr != nil {
+		dkvClnt.ldrFlwr.close()
+	}
 	return dkvClnt.cliConn.Close()
 }
 
+func (dkvClnt *DKVClient) compress(value []byte) []byte {
+	return compression.Compress(dkvClnt.opts.Compression, value)
+}
+
+func (dkvClnt *DKVClient) decompress(value []byte) ([]byte, error) {
+	if dkvClnt.opts.Compression == compression.None {
+		return value, nil
+	}
+	return compression.Decompress(value)
+}
+
+func (dkvClnt *DKVClient) newTimeoutContext() (context.Context, context.CancelFunc) {
+	timeout := dkvClnt.opts.Timeout
+	if dkvClnt.callOpts != nil && dkvClnt.callOpts.timeout > 0 {
+		timeout = dkvClnt.callOpts.timeout
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// errorFromStatus returns the error of the failed GRPC call if any,
+// or else the error conveyed by the given status. The latter can be
+// checked against the errors of pkg/errors using errors.Is.
 func errorFromStatus(res *serverpb.Status, err error) error {
-	switch {
-	case err != nil:
+	if err != nil {
 		return err
-	case res != nil && res.Code != 0:
-		return errors.New(res.Message)
-	default:
-		return nil
 	}
+	return dkverrors.FromStatus(res)
 }
@@ -0,0 +1,957 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/compression"
+	"github.com/flipkart-incubator/dkv/internal/server/security"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	dkvSvcHost = "localhost"
+	dkvSvcPort = 8585
+	getDelay   = 2 * time.Second
+	// Keeps the memory footprint of the test clients low
+	testBufSize = 1 << 20
+)
+
+type slowDKVServer struct {
+	serverpb.UnimplementedDKVServer
+	delay time.Duration
+}
+
+func (sds *slowDKVServer) Get(ctx context.Context, getReq *serverpb.GetRequest) (*serverpb.GetResponse, error) {
+	select {
+	case <-time.After(sds.delay):
+		return &serverpb.GetResponse{Status: &serverpb.Status{}, Value: getReq.Key, Found: true}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// putRecordingDKVServer records the latest Put request it receives
+type putRecordingDKVServer struct {
+	serverpb.UnimplementedDKVServer
+	putReqs chan *serverpb.PutRequest
+}
+
+func (prs *putRecordingDKVServer) Put(ctx context.Context, putReq *serverpb.PutRequest) (*serverpb.PutResponse, error) {
+	prs.putReqs <- putReq
+	return &serverpb.PutResponse{Status: &serverpb.Status{}}, nil
+}
+
+// sizeLimitedDKVServer rejects every Put whose value is
+// larger than maxValueSize, as a DKV node with limits does
+type sizeLimitedDKVServer struct {
+	serverpb.UnimplementedDKVServer
+	maxValueSize int
+}
+
+func (sls *sizeLimitedDKVServer) Put(ctx context.Context, putReq *serverpb.PutRequest) (*serverpb.PutResponse, error) {
+	if len(putReq.Value) > sls.maxValueSize {
+		msg := fmt.Sprintf("value of %d bytes exceeds the limit", len(putReq.Value))
+		return &serverpb.PutResponse{Status: &serverpb.Status{Code: int32(serverpb.StatusCode_ValueTooLarge), Message: msg}}, nil
+	}
+	return &serverpb.PutResponse{Status: &serverpb.Status{}}, nil
+}
+
+// malformedDKVServer responds to every read of multiple
+// keys with the result of only the first key
+type malformedDKVServer struct {
+	serverpb.UnimplementedDKVServer
+}
+
+func (mds *malformedDKVServer) MultiGet(ctx context.Context, multiGetReq *serverpb.MultiGetRequest) (*serverpb.MultiGetResponse, error) {
+	return &serverpb.MultiGetResponse{Status: &serverpb.Status{}, Values: multiGetReq.Keys[:1], Found: []bool{true}}, nil
+}
+
+func (mds *malformedDKVServer) Exists(ctx context.Context, existsReq *serverpb.ExistsRequest) (*serverpb.ExistsResponse, error) {
+	return &serverpb.ExistsResponse{Status: &serverpb.Status{}, Exists: []bool{true}}, nil
+}
+
+// storingDKVServer stores the values as they are put, which
+// makes them visible to its clients as they are transferred
+type storingDKVServer struct {
+	serverpb.UnimplementedDKVServer
+	mu   sync.Mutex
+	vals map[string][]byte
+}
+
+func (sds *storingDKVServer) Put(ctx context.Context, putReq *serverpb.PutRequest) (*serverpb.PutResponse, error) {
+	sds.mu.Lock()
+	defer sds.mu.Unlock()
+	sds.vals[string(putReq.Key)] = putReq.Value
+	return &serverpb.PutResponse{Status: &serverpb.Status{}}, nil
+}
+
+func (sds *storingDKVServer) Get(ctx context.Context, getReq *serverpb.GetRequest) (*serverpb.GetResponse, error) {
+	sds.mu.Lock()
+	defer sds.mu.Unlock()
+	val, found := sds.vals[string(getReq.Key)]
+	return &serverpb.GetResponse{Status: &serverpb.Status{}, Value: val, Found: found}, nil
+}
+
+func (sds *storingDKVServer) MultiGet(ctx context.Context, multiGetReq *serverpb.MultiGetRequest) (*serverpb.MultiGetResponse, error) {
+	sds.mu.Lock()
+	defer sds.mu.Unlock()
+	res := &serverpb.MultiGetResponse{Status: &serverpb.Status{}}
+	for _, key := range multiGetReq.Keys {
+		val, found := sds.vals[string(key)]
+		res.Values, res.Found = append(res.Values, val), append(res.Found, found)
+	}
+	return res, nil
+}
+
+// echoingDKVServer responds to every MultiGet with the keys as their
+// values, except for the keys absent and failing the requests with the
+// key failKey, while recording the number of keys of all the requests.
+// Detailed requests are responded with the results of the keys, where
+// only the key corruptKey fails to be read.
+type echoingDKVServer struct {
+	serverpb.UnimplementedDKVServer
+	mu         sync.Mutex
+	reqLens    []int
+	absent     string
+	failKey    string
+	corruptKey string
+}
+
+func (eds *echoingDKVServer) MultiGet(ctx context.Context, multiGetReq *serverpb.MultiGetRequest) (*serverpb.MultiGetResponse, error) {
+	eds.mu.Lock()
+	eds.reqLens = append(eds.reqLens, len(multiGetReq.Keys))
+	eds.mu.Unlock()
+	res := &serverpb.MultiGetResponse{Status: &serverpb.Status{}}
+	for _, key := range multiGetReq.Keys {
+		if string(key) == eds.failKey {
+			return nil, status.Error(codes.Internal, "failed to read")
+		}
+		found := string(key) != eds.absent
+		if multiGetReq.Detailed {
+			result := &serverpb.KVResult{Status: &serverpb.Status{}, Found: found}
+			if string(key) == eds.corruptKey {
+				result = &serverpb.KVResult{Status: &serverpb.Status{Code: -1, Message: "corrupt block"}}
+			} else if found {
+				result.Value = key
+			}
+			res.Results = append(res.Results, result)
+			continue
+		}
+		res.Found = append(res.Found, found)
+		if found {
+			res.Values = append(res.Values, key)
+		} else {
+			res.Values = append(res.Values, nil)
+		}
+	}
+	return res, nil
+}
+
+func TestDefaultClientOpts(t *testing.T) {
+	opts := newDKVClientOpts()
+	if opts.Timeout != DefaultTimeout {
+		t.Errorf("Timeout mismatch. Expected: %v, Actual: %v", DefaultTimeout, opts.Timeout)
+	}
+	if opts.ReadBufSize != DefaultReadBufSize {
+		t.Errorf("Read buffer size mismatch. Expected: %d, Actual: %d", DefaultReadBufSize, opts.ReadBufSize)
+	}
+	if opts.WriteBufSize != DefaultWriteBufSize {
+		t.Errorf("Write buffer size mismatch. Expected: %d, Actual: %d", DefaultWriteBufSize, opts.WriteBufSize)
+	}
+	if opts.RetryPolicy != nil {
+		t.Errorf("Expected no retry policy by default. Actual: %+v", opts.RetryPolicy)
+	}
+	if opts.MaxRecvMsgSize != DefaultMaxRecvMsgSize || opts.MaxSendMsgSize != DefaultMaxSendMsgSize {
+		t.Errorf("Maximum message size mismatch. Expected receive: %d, send: %d, Actual receive: %d, send: %d", DefaultMaxRecvMsgSize, DefaultMaxSendMsgSize, opts.MaxRecvMsgSize, opts.MaxSendMsgSize)
+	}
+	if opts.DialTimeout != DefaultDialTimeout {
+		t.Errorf("Dial timeout mismatch. Expected: %v, Actual: %v", DefaultDialTimeout, opts.DialTimeout)
+	}
+}
+
+func TestCustomClientOpts(t *testing.T) {
+	timeout, bufSize := 100*time.Millisecond, 1<<20
+	opts := newDKVClientOpts(WithTimeout(timeout), WithReadBufSize(bufSize), WithWriteBufSize(bufSize))
+	if opts.Timeout != timeout {
+		t.Errorf("Timeout mismatch. Expected: %v, Actual: %v", timeout, opts.Timeout)
+	}
+	if opts.ReadBufSize != bufSize || opts.WriteBufSize != bufSize {
+		t.Errorf("Buffer size mismatch. Expected: %d, Actual read: %d, write: %d", bufSize, opts.ReadBufSize, opts.WriteBufSize)
+	}
+}
+
+func TestKeepaliveOpts(t *testing.T) {
+	opts := newDKVClientOpts()
+	if opts.KeepaliveTime != 0 || opts.WaitForReady {
+		t.Errorf("Expected neither keepalives nor waiting for ready by default. Actual: %+v", opts)
+	}
+	opts = newDKVClientOpts(WithKeepalive(ReplicationKeepaliveTime, ReplicationKeepaliveTimeout, true), WithWaitForReady())
+	if opts.KeepaliveTime != ReplicationKeepaliveTime || opts.KeepaliveTimeout != ReplicationKeepaliveTimeout || !opts.KeepalivePermitWithoutStream || !opts.WaitForReady {
+		t.Errorf("Keepalive mismatch. Actual: %+v", opts)
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	client := newDKVClient(t, WithNonBlockingDial(), WithWaitForReady(), WithKeepalive(ReplicationKeepaliveTime, ReplicationKeepaliveTimeout, true))
+	defer client.Close()
+	if state := client.State(); state == connectivity.Ready {
+		t.Errorf("Expected the client to not be ready before the service is up. State: %v", state)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	if err := client.WaitForReady(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the wait to time out before the service is up. Error: %v", err)
+	}
+	cancel()
+
+	// Idempotent calls wait for the service to come up within their timeout
+	grpcSrvrs := make(chan *grpc.Server, 1)
+	go func() {
+		<-time.After(200 * time.Millisecond)
+		grpcSrvrs <- serveSlowDKV(t, 0)
+	}()
+	defer func() { (<-grpcSrvrs).Stop() }()
+	if res, err := client.Get([]byte("foo")); err != nil || string(res.Value) != "foo" {
+		t.Errorf("Expected the GET to wait for the service to come up. Value: %v, Error: %v", res, err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.WaitForReady(ctx); err != nil || client.State() != connectivity.Ready {
+		t.Errorf("Expected the client to be ready. State: %v, Error: %v", client.State(), err)
+	}
+
+	client.Close()
+	if err := client.WaitForReady(ctx); err == nil {
+		t.Error("Expected an error on waiting for a closed client")
+	}
+}
+
+func TestDialUnresponsiveService(t *testing.T) {
+	// Connections are accepted, but the GRPC handshake is never answered
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", dkvSvcPort))
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer lis.Close()
+
+	dialTimeout := 200 * time.Millisecond
+	start := time.Now()
+	if client, err := NewInSecureDKVClient(fmt.Sprintf("%s:%d", dkvSvcHost, dkvSvcPort), WithReadBufSize(testBufSize), WithWriteBufSize(testBufSize), WithDialTimeout(dialTimeout)); err == nil {
+		client.Close()
+		t.Error("Expected the client creation to fail against an unresponsive service")
+	} else if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the dial to time out. Error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= DefaultDialTimeout {
+		t.Errorf("Expected the dial to give up after %v, but it took %v", dialTimeout, elapsed)
+	}
+
+	client := newDKVClient(t, WithNonBlockingDial())
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	if err := client.Connect(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the connect to time out. Error: %v", err)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	grpcSrvr := serveSlowDKV(t, 0)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithNonBlockingDial())
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Unable to connect. Error: %v", err)
+	}
+	if client.State() != connectivity.Ready {
+		t.Errorf("Expected the client to be ready once connected. State: %v", client.State())
+	}
+	expectGet(t, client, "foo")
+}
+
+func TestShortTimeoutCancelsSlowGet(t *testing.T) {
+	grpcSrvr := serveSlowDKV(t, getDelay)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithTimeout(100*time.Millisecond))
+	defer client.Close()
+
+	start := time.Now()
+	if _, err := client.Get([]byte("foo")); err == nil {
+		t.Error("Expected the GET to time out, but it succeeded")
+	} else if code := status.Code(err); code != codes.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded error, but got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= getDelay {
+		t.Errorf("Expected the GET to be cancelled before %v, but it took %v", getDelay, elapsed)
+	}
+
+	longClient := newDKVClient(t, WithTimeout(2*getDelay))
+	defer longClient.Close()
+	if res, err := longClient.Get([]byte("foo")); err != nil {
+		t.Errorf("Unable to GET with a lenient timeout. Error: %v", err)
+	} else if string(res.Value) != "foo" {
+		t.Errorf("GET mismatch. Expected: foo, Actual: %s", res.Value)
+	}
+}
+
+func TestCancelledContextAbortsSlowGet(t *testing.T) {
+	grpcSrvr := serveSlowDKV(t, getDelay)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t)
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-time.After(100 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := client.GetWithCtx(ctx, []byte("foo")); err == nil {
+		t.Error("Expected the GET to be cancelled, but it succeeded")
+	} else if code := status.Code(err); code != codes.Canceled {
+		t.Errorf("Expected Canceled error, but got %v", err)
+	}
+}
+
+func TestPutTTL(t *testing.T) {
+	putSrvr := &putRecordingDKVServer{putReqs: make(chan *serverpb.PutRequest, 2)}
+	grpcSrvr := serveDKV(t, putSrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t)
+	defer client.Close()
+
+	ttl := 10 * time.Second
+	minExpireTS := uint64(time.Now().Add(ttl).Unix())
+	if err := client.PutTTL([]byte("foo"), []byte("bar"), ttl); err != nil {
+		t.Fatalf("Unable to PUT with TTL. Error: %v", err)
+	}
+	maxExpireTS := uint64(time.Now().Add(ttl).Unix()) + 1
+	if putReq := <-putSrvr.putReqs; putReq.ExpireTS < minExpireTS || putReq.ExpireTS > maxExpireTS {
+		t.Errorf("Expiry mismatch. Expected between %d and %d, Actual: %d", minExpireTS, maxExpireTS, putReq.ExpireTS)
+	}
+
+	if err := client.Put([]byte("foo"), []byte("bar")); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+	if putReq := <-putSrvr.putReqs; putReq.ExpireTS != 0 {
+		t.Errorf("Expected no expiry for PUT. Actual: %d", putReq.ExpireTS)
+	}
+}
+
+func TestValueTooLarge(t *testing.T) {
+	grpcSrvr := serveDKV(t, &sizeLimitedDKVServer{maxValueSize: 4})
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t)
+	defer client.Close()
+
+	if err := client.Put([]byte("foo"), []byte("bar")); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+	err := client.Put([]byte("foo"), []byte("large"))
+	if !errors.Is(err, ErrValueTooLarge) || errors.Is(err, ErrKeyTooLarge) {
+		t.Errorf("Expected error: %v on PUT of a large value. Actual: %v", ErrValueTooLarge, err)
+	}
+	if expMsg := "value of 5 bytes exceeds the limit"; err == nil || err.Error() != expMsg {
+		t.Errorf("Error message mismatch. Expected: %s, Actual: %v", expMsg, err)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	storeSrvr := &storingDKVServer{vals: make(map[string][]byte)}
+	grpcSrvr := serveDKV(t, storeSrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithCompression(compression.Snappy))
+	defer client.Close()
+
+	value := []byte(strings.Repeat("bar", 20))
+	if err := client.Put([]byte("foo"), value); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+	if compVal := storeSrvr.vals["foo"]; !compression.IsCompressed(compVal) || len(compVal) >= len(value) {
+		t.Errorf("Expected the value to be compressed over the wire. Transferred value: %q", compVal)
+	}
+	if res, err := client.Get([]byte("foo")); err != nil || !bytes.Equal(res.Value, value) {
+		t.Errorf("GET mismatch. Expected Value: %s, Actual Value: %v, Error: %v", value, res, err)
+	}
+
+	// Uncompressed values are read verbatim
+	plainCli := newDKVClient(t)
+	defer plainCli.Close()
+	if err := plainCli.Put([]byte("plain"), value); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+	if res, err := client.Get([]byte("plain")); err != nil || !bytes.Equal(res.Value, value) {
+		t.Errorf("GET mismatch. Expected Value: %s, Actual Value: %v, Error: %v", value, res, err)
+	}
+}
+
+func TestForNamespace(t *testing.T) {
+	putRecSrvr := &putRecordingDKVServer{putReqs: make(chan *serverpb.PutRequest, 2)}
+	grpcSrvr := serveDKV(t, putRecSrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t)
+	defer client.Close()
+	nsClient := client.ForNamespace("tenant")
+	for _, cli := range []*DKVClient{nsClient, client} {
+		if err := cli.Put([]byte("foo"), []byte("bar")); err != nil {
+			t.Fatalf("Unable to PUT. Error: %v", err)
+		}
+		if putReq := <-putRecSrvr.putReqs; putReq.Namespace != cli.Namespace() {
+			t.Errorf("Namespace mismatch. Expected: %q, Actual: %q", cli.Namespace(), putReq.Namespace)
+		}
+	}
+	if client.Namespace() != "" {
+		t.Errorf("Expected the namespaced view to leave the client in the default namespace. Actual: %q", client.Namespace())
+	}
+}
+
+func TestFailedCallsReturnErrors(t *testing.T) {
+	// None of the methods are implemented by this server
+	grpcSrvr := serveDKV(t, &serverpb.UnimplementedDKVServer{})
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t)
+	defer client.Close()
+
+	key := []byte("hello")
+	calls := map[string]func() error{
+		"Put":    func() error { return client.Put(key, key) },
+		"PutTTL": func() error { return client.PutTTL(key, key, time.Minute) },
+		"MultiPut": func() error {
+			return client.MultiPut(KVPair{Key: key, Value: key})
+		},
+		"CompareAndSet": func() error {
+			_, err := client.CompareAndSet(key, nil, key)
+			return err
+		},
+		"Increment": func() error {
+			_, err := client.Increment(key, 1)
+			return err
+		},
+		"Txn": func() error {
+			_, err := client.Txn().If(KeyAbsent(key)).Then(OpPut(key, key)).Commit()
+			return err
+		},
+		"Delete": func() error { return client.Delete(key) },
+		"Get": func() error {
+			_, err := client.Get(key)
+			return err
+		},
+		"GetWithConsistency": func() error {
+			_, err := client.GetWithConsistency(key, 1)
+			return err
+		},
+		"MultiGet": func() error {
+			_, err := client.MultiGet(key, key)
+			return err
+		},
+		"Exists": func() error {
+			_, err := client.Exists(key)
+			return err
+		},
+		"Iterate": func() error {
+			pairs, err := client.Iterate(key, nil)
+			if err != nil {
+				return err
+			}
+			for pair := range pairs {
+				if pair.Err != nil {
+					return pair.Err
+				}
+			}
+			return nil
+		},
+		"HealthCheck": func() error {
+			_, err := client.HealthCheck()
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); status.Code(err) != codes.Unimplemented {
+			t.Errorf("Expected %s to fail with an Unimplemented error. Actual: %v", name, err)
+		}
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", dkvSvcPort))
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	hlthSrvr, grpcSrvr := health.NewServer(), grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcSrvr, hlthSrvr)
+	go grpcSrvr.Serve(lis)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t)
+	defer client.Close()
+
+	for _, expStatus := range []grpc_health_v1.HealthCheckResponse_ServingStatus{grpc_health_v1.HealthCheckResponse_NOT_SERVING, grpc_health_v1.HealthCheckResponse_SERVING} {
+		hlthSrvr.SetServingStatus("", expStatus)
+		if actStatus, err := client.HealthCheck(); err != nil || actStatus != expStatus {
+			t.Errorf("Serving status mismatch. Expected: %v, Actual: %v, Error: %v", expStatus, actStatus, err)
+		}
+	}
+}
+
+func TestAuthToken(t *testing.T) {
+	auth := security.NewTokenAuthenticator(map[string]security.Scope{"reader": security.ReadScope})
+	grpcSrvr := serveSlowDKV(t, 0, grpc.UnaryInterceptor(auth.UnaryServerInterceptor()))
+	defer grpcSrvr.Stop()
+
+	reader := newDKVClient(t, WithAuthToken("reader"))
+	defer reader.Close()
+	if _, err := reader.Get([]byte("K1")); err != nil {
+		t.Errorf("Expected the read scoped token to be permitted to GET. Error: %v", err)
+	}
+	if err := reader.Put([]byte("K1"), []byte("V1")); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected the read scoped token to be denied PUT. Error: %v", err)
+	}
+
+	for _, client := range []*DKVClient{newDKVClient(t), newDKVClient(t, WithAuthToken("unknown"))} {
+		if _, err := client.Get([]byte("K1")); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected calls without a valid token to be denied. Error: %v", err)
+		}
+		client.Close()
+	}
+}
+
+func TestMalformedResponses(t *testing.T) {
+	grpcSrvr := serveDKV(t, &malformedDKVServer{})
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t)
+	defer client.Close()
+
+	if vals, err := client.MultiGet([]byte("K1"), []byte("K2")); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("Expected error: %v on MultiGet. Values: %q, Error: %v", ErrMalformedResponse, vals, err)
+	}
+	if results, err := client.Exists([]byte("K1"), []byte("K2")); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("Expected error: %v on Exists. Results: %v, Error: %v", ErrMalformedResponse, results, err)
+	}
+}
+
+func TestChunkKeys(t *testing.T) {
+	keys := [][]byte{[]byte("k1"), []byte("k2"), []byte("k3"), make([]byte, 100), []byte("k5")}
+	testCases := []struct {
+		maxKeys, maxBytes int
+		expected          []keyChunk
+	}{
+		{0, 0, []keyChunk{{0, 5}}},
+		{2, 0, []keyChunk{{0, 2}, {2, 4}, {4, 5}}},
+		// Every key of 2 bytes takes 4 bytes within the request
+		{0, 8, []keyChunk{{0, 2}, {2, 3}, {3, 4}, {4, 5}}},
+		{10, 1000, []keyChunk{{0, 5}}},
+	}
+	for _, tc := range testCases {
+		if chunks := chunkKeys(keys, tc.maxKeys, tc.maxBytes); fmt.Sprint(chunks) != fmt.Sprint(tc.expected) {
+			t.Errorf("Chunks mismatch for max keys: %d, max bytes: %d. Expected: %v, Actual: %v", tc.maxKeys, tc.maxBytes, tc.expected, chunks)
+		}
+	}
+	if chunks := chunkKeys(nil, 2, 8); len(chunks) != 0 {
+		t.Errorf("Expected no chunks for no keys. Actual: %v", chunks)
+	}
+}
+
+func TestMultiGetChunking(t *testing.T) {
+	echoSrvr := &echoingDKVServer{absent: "key_42"}
+	grpcSrvr := serveDKV(t, echoSrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithMultiGetChunking(10, 0), WithMultiGetConcurrency(3))
+	defer client.Close()
+	keys := make([][]byte, 95)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("key_%d", i))
+	}
+	vals, err := client.MultiGet(keys...)
+	if err != nil {
+		t.Fatalf("Unable to MultiGet. Error: %v", err)
+	}
+	for i, key := range keys {
+		if i == 42 {
+			if vals[i] != nil {
+				t.Errorf("Expected a nil value for the absent key. Actual: %q", vals[i])
+			}
+		} else if !bytes.Equal(vals[i], key) {
+			t.Errorf("Value mismatch for key: %s. Actual: %q", key, vals[i])
+		}
+	}
+	if len(echoSrvr.reqLens) != 10 {
+		t.Errorf("Expected the keys to be split across 10 requests. Actual: %v", echoSrvr.reqLens)
+	}
+	for _, reqLen := range echoSrvr.reqLens {
+		if reqLen > 10 {
+			t.Errorf("Expected atmost 10 keys in every request. Actual: %v", echoSrvr.reqLens)
+		}
+	}
+
+	echoSrvr.failKey = "key_57"
+	if vals, err = client.MultiGet(keys...); status.Code(err) != codes.Internal {
+		t.Errorf("Expected the failure of a chunk to fail the MultiGet. Values: %q, Error: %v", vals, err)
+	}
+}
+
+func TestMultiGetDetailed(t *testing.T) {
+	echoSrvr := &echoingDKVServer{absent: "key_42", corruptKey: "key_57"}
+	grpcSrvr := serveDKV(t, echoSrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithMultiGetChunking(10, 0), WithMultiGetConcurrency(3))
+	defer client.Close()
+	keys := make([][]byte, 95)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("key_%d", i))
+	}
+	results, err := client.MultiGetDetailed(keys...)
+	if err != nil {
+		t.Fatalf("Unable to MultiGet. Error: %v", err)
+	}
+	for i, key := range keys {
+		res := results[i]
+		switch {
+		case !bytes.Equal(res.Key, key):
+			t.Errorf("Expected the results in the order of the keys. Expected: %s, Actual: %s", key, res.Key)
+		case i == 42:
+			if res.Found || res.Value != nil || res.Err != nil {
+				t.Errorf("Expected the key to be absent. Actual: %+v", res)
+			}
+		case i == 57:
+			if res.Err == nil || res.Found {
+				t.Errorf("Expected the read of the corrupt key to fail. Actual: %+v", res)
+			}
+		case !res.Found || !bytes.Equal(res.Value, key) || res.Err != nil:
+			t.Errorf("Value mismatch for key: %s. Actual: %+v", key, res)
+		}
+	}
+
+	echoSrvr.failKey = "key_63"
+	if results, err = client.MultiGetDetailed(keys...); status.Code(err) != codes.Internal {
+		t.Errorf("Expected the failure of a chunk to fail the MultiGet. Results: %v, Error: %v", results, err)
+	}
+}
+
+func TestMultiGetDetailedFromValues(t *testing.T) {
+	// Servers unaware of detailed requests respond with the values
+	grpcSrvr := serveDKV(t, &storingDKVServer{vals: map[string][]byte{"K1": []byte("V1"), "K2": {}}})
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t)
+	defer client.Close()
+	results, err := client.MultiGetDetailed([]byte("K1"), []byte("K2"), []byte("K3"))
+	if err != nil {
+		t.Fatalf("Unable to MultiGet. Error: %v", err)
+	}
+	if res := results[0]; !res.Found || string(res.Value) != "V1" || res.Err != nil {
+		t.Errorf("Value mismatch for key: K1. Actual: %+v", res)
+	}
+	if res := results[1]; !res.Found || res.Value == nil || len(res.Value) != 0 {
+		t.Errorf("Expected an empty yet non-nil value for key: K2. Actual: %+v", res)
+	}
+	if res := results[2]; res.Found || res.Value != nil {
+		t.Errorf("Expected key: K3 to be absent. Actual: %+v", res)
+	}
+}
+
+func TestMaxMsgSizes(t *testing.T) {
+	grpcSrvr := serveDKV(t, &storingDKVServer{vals: make(map[string][]byte)}, grpc.MaxRecvMsgSize(16<<20))
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithMaxRecvMsgSize(16<<20))
+	defer client.Close()
+	keys := [][]byte{[]byte("big_1"), []byte("big_2"), []byte("big_3")}
+	for i, key := range keys {
+		if err := client.Put(key, bytes.Repeat([]byte{byte(i)}, 3<<20)); err != nil {
+			t.Fatalf("Unable to PUT a value of 3 MB. Error: %v", err)
+		}
+	}
+	vals, err := client.MultiGet(keys...)
+	if err != nil {
+		t.Fatalf("Unable to MultiGet the values of 9 MB. Error: %v", err)
+	}
+	for i, val := range vals {
+		if len(val) != 3<<20 || val[0] != byte(i) {
+			t.Errorf("Value mismatch for key: %s. Actual size: %d", keys[i], len(val))
+		}
+	}
+
+	// By default, responses exceeding 4 MB are rejected
+	defClient := newDKVClient(t)
+	defer defClient.Close()
+	if _, err = defClient.MultiGet(keys...); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected the MultiGet to exceed the default maximum size. Error: %v", err)
+	}
+
+	smallClient := newDKVClient(t, WithMaxSendMsgSize(1<<20))
+	defer smallClient.Close()
+	if err = smallClient.Put([]byte("big_4"), make([]byte, 2<<20)); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected the PUT to exceed the maximum size sent. Error: %v", err)
+	}
+}
+
+func TestOneWayTLS(t *testing.T) {
+	certs := newTestCerts(t)
+	defer os.RemoveAll(certs.dir)
+
+	creds, err := security.NewServerTLSCredentials(certs.serverCert, certs.serverKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcSrvr := serveSlowDKV(t, 0, grpc.Creds(creds))
+	defer grpcSrvr.Stop()
+
+	client := newTLSDKVClient(t, "", "", certs.caCert)
+	defer client.Close()
+	expectGet(t, client, "foo")
+}
+
+func TestMutualTLS(t *testing.T) {
+	certs := newTestCerts(t)
+	defer os.RemoveAll(certs.dir)
+
+	creds, err := security.NewServerTLSCredentials(certs.serverCert, certs.serverKey, certs.caCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcSrvr := serveSlowDKV(t, 0, grpc.Creds(creds))
+	defer grpcSrvr.Stop()
+
+	client := newTLSDKVClient(t, certs.clientCert, certs.clientKey, certs.caCert)
+	defer client.Close()
+	expectGet(t, client, "foo")
+
+	// Clients without a certificate must be rejected
+	expectTLSFailure(t, "", "", certs.caCert)
+}
+
+func TestReloadTLS(t *testing.T) {
+	oldCerts, newCerts := newTestCerts(t), newTestCerts(t)
+	defer os.RemoveAll(oldCerts.dir)
+	defer os.RemoveAll(newCerts.dir)
+
+	// Files served are rotated in place, as by the tools renewing them
+	certFile, keyFile, caFile := path.Join(oldCerts.dir, "served.crt"), path.Join(oldCerts.dir, "served.key"), path.Join(oldCerts.dir, "served-ca.crt")
+	copyCerts := func(certs *testCerts) {
+		for src, dst := range map[string]string{certs.serverCert: certFile, certs.serverKey: keyFile, certs.caCert: caFile} {
+			data, err := ioutil.ReadFile(src)
+			if err == nil {
+				err = ioutil.WriteFile(dst, data, 0600)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	copyCerts(oldCerts)
+	srvrTLS, err := security.NewReloadableTLS(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcSrvr := serveSlowDKV(t, 0, grpc.Creds(srvrTLS.Credentials()))
+	defer grpcSrvr.Stop()
+	reloader := security.NewReloader(srvrTLS, nil, zap.NewNop())
+
+	oldClient := newTLSDKVClient(t, oldCerts.clientCert, oldCerts.clientKey, oldCerts.caCert)
+	defer oldClient.Close()
+	expectGet(t, oldClient, "foo")
+
+	copyCerts(newCerts)
+	report := reloader.Reload()
+	if len(report.Errors) > 0 || strings.Join(report.Reloaded, ",") != "certificate,ca" {
+		t.Fatalf("Expected the certificate and the CA to be reloaded. Reloaded: %v, Errors: %v", report.Reloaded, report.Errors)
+	}
+	// Existing connections are served as is
+	expectGet(t, oldClient, "foo")
+	newClient := newTLSDKVClient(t, newCerts.clientCert, newCerts.clientKey, newCerts.caCert)
+	defer newClient.Close()
+	expectGet(t, newClient, "foo")
+
+	// Clients trusting the old CA, or trusted only by it, must be rejected
+	expectTLSFailure(t, oldCerts.clientCert, oldCerts.clientKey, oldCerts.caCert)
+	expectTLSFailure(t, oldCerts.clientCert, oldCerts.clientKey, newCerts.caCert)
+}
+
+func TestTLSClientArgs(t *testing.T) {
+	dkvSvcAddr := fmt.Sprintf("%s:%d", dkvSvcHost, dkvSvcPort)
+	if _, err := NewTLSDKVClient(dkvSvcAddr, "/missing/cert.pem", "", ""); err == nil {
+		t.Error("Expected an error when only the certificate file is given")
+	}
+	if _, err := NewTLSDKVClient(dkvSvcAddr, "", "", "/missing/ca.pem"); err == nil {
+		t.Error("Expected an error when the CA file is missing")
+	}
+}
+
+func expectGet(t *testing.T, client *DKVClient, key string) {
+	if res, err := client.Get([]byte(key)); err != nil {
+		t.Errorf("Unable to GET. Key: %s, Error: %v", key, err)
+	} else if string(res.Value) != key {
+		t.Errorf("GET mismatch. Expected: %s, Actual: %s", key, res.Value)
+	}
+}
+
+func expectTLSFailure(t *testing.T, certFile, keyFile, caFile string) {
+	dkvSvcAddr := fmt.Sprintf("%s:%d", dkvSvcHost, dkvSvcPort)
+	clientCh := make(chan *DKVClient, 1)
+	go func() {
+		// Dialing blocks until the handshake succeeds, so failures
+		// are detected either during the dial or on the first call
+		if client, err := NewTLSDKVClient(dkvSvcAddr, certFile, keyFile, caFile, WithReadBufSize(testBufSize), WithWriteBufSize(testBufSize)); err == nil {
+			clientCh <- client
+		} else {
+			clientCh <- nil
+		}
+	}()
+	select {
+	case client := <-clientCh:
+		if client != nil {
+			defer client.Close()
+			if _, err := client.Get([]byte("foo")); err == nil {
+				t.Error("Expected the TLS handshake to fail, but GET succeeded")
+			}
+		}
+	case <-time.After(3 * time.Second):
+	}
+}
+
+type testCerts struct {
+	dir                   string
+	caCert                string
+	serverCert, serverKey string
+	clientCert, clientKey string
+}
+
+func newTestCerts(t *testing.T) *testCerts {
+	dir, err := ioutil.TempDir("", "dkv-tls-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs := &testCerts{dir: dir}
+	caKey := newPrivateKey(t)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "DKV Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	certs.caCert, _ = writeCert(t, dir, "ca", caTmpl, caTmpl, caKey, caKey)
+	serverTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dkvSvcHost},
+		DNSNames:     []string{dkvSvcHost},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certs.serverCert, certs.serverKey = writeCert(t, dir, "server", serverTmpl, caTmpl, newPrivateKey(t), caKey)
+	clientTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "dkv-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certs.clientCert, certs.clientKey = writeCert(t, dir, "client", clientTmpl, caTmpl, newPrivateKey(t), caKey)
+	return certs
+}
+
+func newPrivateKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writeCert(t *testing.T, dir, name string, tmpl, parent *x509.Certificate, key, parentKey *ecdsa.PrivateKey) (string, string) {
+	certDER, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := path.Join(dir, name+".crt"), path.Join(dir, name+".key")
+	writePEM(t, certFile, "CERTIFICATE", certDER)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, file, blockType string, data []byte) {
+	pemBts := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data})
+	if err := ioutil.WriteFile(file, pemBts, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func serveSlowDKV(t *testing.T, delay time.Duration, opts ...grpc.ServerOption) *grpc.Server {
+	return serveDKV(t, &slowDKVServer{delay: delay}, opts...)
+}
+
+func serveDKV(t *testing.T, dkvSrvr serverpb.DKVServer, opts ...grpc.ServerOption) *grpc.Server {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", dkvSvcPort))
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	grpcSrvr := grpc.NewServer(opts...)
+	serverpb.RegisterDKVServer(grpcSrvr, dkvSrvr)
+	go grpcSrvr.Serve(lis)
+	return grpcSrvr
+}
+
+func newTLSDKVClient(t *testing.T, certFile, keyFile, caFile string) *DKVClient {
+	dkvSvcAddr := fmt.Sprintf("%s:%d", dkvSvcHost, dkvSvcPort)
+	client, err := NewTLSDKVClient(dkvSvcAddr, certFile, keyFile, caFile, WithReadBufSize(testBufSize), WithWriteBufSize(testBufSize))
+	if err != nil {
+		t.Fatalf("Unable to create TLS DKV client. Error: %v", err)
+	}
+	return client
+}
+
+func newDKVClient(t *testing.T, opts ...DKVClientOption) *DKVClient {
+	dkvSvcAddr := fmt.Sprintf("%s:%d", dkvSvcHost, dkvSvcPort)
+	opts = append([]DKVClientOption{WithReadBufSize(testBufSize), WithWriteBufSize(testBufSize)}, opts...)
+	client, err := NewInSecureDKVClient(dkvSvcAddr, opts...)
+	if err != nil {
+		t.Fatalf("Unable to create DKV client. Error: %v", err)
+	}
+	return client
+}
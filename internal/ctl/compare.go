@@ -0,0 +1,214 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// CompareOptions capture how CompareReplicas walks the keyspaces
+// of the nodes being compared.
+type CompareOptions struct {
+	// FanOut is the number of sub ranges into which every divergent
+	// range of keys is split, while descending into it.
+	FanOut int
+	// LeafSize is the number of keys upto which the keys of a divergent
+	// range are listed and compared, instead of splitting it further.
+	LeafSize int
+	// MaxMismatches is the maximum number of mismatching keys reported,
+	// beyond which the comparison stops.
+	MaxMismatches int
+}
+
+// DefaultCompareOptions are reasonable options that can be customized
+// and supplied to CompareReplicas.
+var DefaultCompareOptions = CompareOptions{
+	FanOut:        16,
+	LeafSize:      256,
+	MaxMismatches: 1000,
+}
+
+func (opts CompareOptions) validate() error {
+	switch {
+	case opts.FanOut < 2:
+		return fmt.Errorf("fan out must be atleast 2, given: %d", opts.FanOut)
+	case opts.LeafSize < 1:
+		return fmt.Errorf("leaf size must be atleast 1, given: %d", opts.LeafSize)
+	case opts.MaxMismatches < 1:
+		return fmt.Errorf("maximum number of mismatches must be atleast 1, given: %d", opts.MaxMismatches)
+	}
+	return nil
+}
+
+// KeyRange is the range of keys from StartKey upto, but excluding,
+// EndKey. Empty keys leave the respective end of the range unbounded.
+type KeyRange struct {
+	StartKey []byte
+	EndKey   []byte
+}
+
+// KeyMismatch is a key whose entries differ across the nodes compared.
+// The key is either absent on one of the nodes, or present on both with
+// different values or expiry times.
+type KeyMismatch struct {
+	Key      []byte
+	InMaster bool
+	InSlave  bool
+}
+
+// ReplicaDiff captures the differences found by CompareReplicas.
+type ReplicaDiff struct {
+	// Ranges are the ranges of keys found to diverge, whose keys
+	// are listed in the Mismatches.
+	Ranges []KeyRange
+	// Mismatches are the keys that differ, in the order of keys.
+	Mismatches []KeyMismatch
+	// Truncated indicates whether the comparison stopped upon
+	// reaching the maximum number of mismatches.
+	Truncated bool
+}
+
+// Consistent checks whether no differences were found.
+func (diff *ReplicaDiff) Consistent() bool {
+	return len(diff.Ranges) == 0 && len(diff.Mismatches) == 0
+}
+
+// CompareReplicas compares the keyspaces of the given master and slave
+// nodes through the digests of their ranges of keys, obtained using the
+// KeyspaceDigest method. Starting with the whole keyspace, it splits
+// every range whose digests differ across the nodes into sub ranges and
+// descends only into those that differ, until they are small enough for
+// their keys to be listed and compared. Hence only the digests of the
+// divergent parts of the keyspaces are transferred.
+//
+// Since the nodes are digested independently, writes made during the
+// comparison and the lag of the slave show up as differences. These are
+// best compared while writes are paused, or compared again to confirm.
+func CompareReplicas(ctx context.Context, master, slave *DKVClient, opts CompareOptions) (*ReplicaDiff, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	cmp := &replicaComparer{master: master, slave: slave, opts: opts, diff: &ReplicaDiff{}}
+	// The whole keyspace is first split as per the master
+	pending := []divergentRange{{mstrKeys: math.MaxUint64}}
+	for len(pending) > 0 && !cmp.diff.Truncated {
+		rng := pending[0]
+		pending = pending[1:]
+		subRngs, err := cmp.descend(ctx, rng)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, subRngs...)
+	}
+	return cmp.diff, nil
+}
+
+// divergentRange is a range of keys whose digests differ, along with the
+// number of its keys on every node.
+type divergentRange struct {
+	KeyRange
+	mstrKeys, slvKeys uint64
+	// listed indicates whether the range could not be split any further
+	listed bool
+}
+
+type replicaComparer struct {
+	master, slave *DKVClient
+	opts          CompareOptions
+	diff          *ReplicaDiff
+}
+
+// descend compares the sub ranges of the given range, returning those
+// that differ. The keys of the range are compared instead, if small
+// enough. The node with more keys splits the range evenly as per its
+// keys, while the other node digests the same sub ranges.
+func (cmp *replicaComparer) descend(ctx context.Context, rng divergentRange) ([]divergentRange, error) {
+	if rng.listed || (rng.mstrKeys <= uint64(cmp.opts.LeafSize) && rng.slvKeys <= uint64(cmp.opts.LeafSize)) {
+		return nil, cmp.compareKeys(ctx, rng.KeyRange)
+	}
+
+	splitter, other := cmp.master, cmp.slave
+	if rng.slvKeys > rng.mstrKeys {
+		splitter, other = cmp.slave, cmp.master
+	}
+	req := &serverpb.KeyspaceDigestRequest{StartKey: rng.StartKey, EndKey: rng.EndKey, FanOut: uint32(cmp.opts.FanOut)}
+	splitRes, err := splitter.KeyspaceDigestWithCtx(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	req = &serverpb.KeyspaceDigestRequest{StartKey: rng.StartKey, EndKey: rng.EndKey, FanOut: 1}
+	for _, subRng := range splitRes.Ranges[1:] {
+		req.SplitKeys = append(req.SplitKeys, subRng.StartKey)
+	}
+	otherRes, err := other.KeyspaceDigestWithCtx(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(otherRes.Ranges) != len(splitRes.Ranges) {
+		return nil, fmt.Errorf("expected %d ranges, received %d: %w", len(splitRes.Ranges), len(otherRes.Ranges), ErrMalformedResponse)
+	}
+
+	var subRngs []divergentRange
+	for i, splitDgst := range splitRes.Ranges {
+		otherDgst := otherRes.Ranges[i]
+		if bytes.Equal(splitDgst.Digest, otherDgst.Digest) && splitDgst.NumberOfKeys == otherDgst.NumberOfKeys {
+			continue
+		}
+		subRng := divergentRange{KeyRange: KeyRange{StartKey: splitDgst.StartKey, EndKey: splitDgst.EndKey}}
+		subRng.mstrKeys, subRng.slvKeys = splitDgst.NumberOfKeys, otherDgst.NumberOfKeys
+		if splitter == cmp.slave {
+			subRng.mstrKeys, subRng.slvKeys = subRng.slvKeys, subRng.mstrKeys
+		}
+		// A range that is not split any further has its keys listed
+		subRng.listed = len(splitRes.Ranges) == 1
+		subRngs = append(subRngs, subRng)
+	}
+	return subRngs, nil
+}
+
+// compareKeys lists the keys of the given range on both the nodes and
+// records those that differ.
+func (cmp *replicaComparer) compareKeys(ctx context.Context, rng KeyRange) error {
+	req := &serverpb.KeyspaceDigestRequest{StartKey: rng.StartKey, EndKey: rng.EndKey, FanOut: 1, ListKeys: true}
+	mstrRes, err := cmp.master.KeyspaceDigestWithCtx(ctx, req)
+	if err != nil {
+		return err
+	}
+	slvRes, err := cmp.slave.KeyspaceDigestWithCtx(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	numMismatches := len(cmp.diff.Mismatches)
+	mstrKeys, slvKeys := mstrRes.Keys, slvRes.Keys
+	for len(mstrKeys) > 0 || len(slvKeys) > 0 {
+		var mismatch KeyMismatch
+		switch {
+		case len(slvKeys) == 0 || (len(mstrKeys) > 0 && bytes.Compare(mstrKeys[0].Key, slvKeys[0].Key) < 0):
+			mismatch = KeyMismatch{Key: mstrKeys[0].Key, InMaster: true}
+			mstrKeys = mstrKeys[1:]
+		case len(mstrKeys) == 0 || bytes.Compare(mstrKeys[0].Key, slvKeys[0].Key) > 0:
+			mismatch = KeyMismatch{Key: slvKeys[0].Key, InSlave: true}
+			slvKeys = slvKeys[1:]
+		default:
+			same := bytes.Equal(mstrKeys[0].Digest, slvKeys[0].Digest)
+			mismatch = KeyMismatch{Key: mstrKeys[0].Key, InMaster: true, InSlave: true}
+			mstrKeys, slvKeys = mstrKeys[1:], slvKeys[1:]
+			if same {
+				continue
+			}
+		}
+		if len(cmp.diff.Mismatches) >= cmp.opts.MaxMismatches {
+			cmp.diff.Truncated = true
+			break
+		}
+		cmp.diff.Mismatches = append(cmp.diff.Mismatches, mismatch)
+	}
+	if len(cmp.diff.Mismatches) > numMismatches || cmp.diff.Truncated {
+		cmp.diff.Ranges = append(cmp.diff.Ranges, rng)
+	}
+	return nil
+}
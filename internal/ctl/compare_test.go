@@ -0,0 +1,93 @@
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc"
+)
+
+// digestingReplServer digests the keyspace of its store
+type digestingReplServer struct {
+	serverpb.UnimplementedDKVReplicationServer
+	store    storage.KVStore
+	numCalls int32
+}
+
+func (drs *digestingReplServer) KeyspaceDigest(ctx context.Context, req *serverpb.KeyspaceDigestRequest) (*serverpb.KeyspaceDigestResponse, error) {
+	atomic.AddInt32(&drs.numCalls, 1)
+	ranges, keys, err := storage.KeyspaceDigest(ctx, drs.store, req)
+	if err != nil {
+		return &serverpb.KeyspaceDigestResponse{Status: dkverrors.NewStatus(err)}, nil
+	}
+	return &serverpb.KeyspaceDigestResponse{Status: &serverpb.Status{}, Ranges: ranges, Keys: keys}, nil
+}
+
+func serveDigests(t *testing.T, store storage.KVStore) (*digestingReplServer, *DKVClient, func()) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	replSrvr := &digestingReplServer{store: store}
+	grpcSrvr := grpc.NewServer()
+	serverpb.RegisterDKVReplicationServer(grpcSrvr, replSrvr)
+	go grpcSrvr.Serve(lis)
+	client, err := NewInSecureDKVClient(lis.Addr().String(), WithReadBufSize(testBufSize), WithWriteBufSize(testBufSize))
+	if err != nil {
+		t.Fatalf("Unable to create DKV client. Error: %v", err)
+	}
+	return replSrvr, client, func() {
+		client.Close()
+		grpcSrvr.Stop()
+	}
+}
+
+func TestCompareReplicas(t *testing.T) {
+	mstrStore, slvStore := memory.OpenDB(0), memory.OpenDB(0)
+	for i := 0; i < 1000; i++ {
+		key, value := fmt.Sprintf("K%03d", i), fmt.Sprintf("V%03d", i)
+		mstrStore.Put([]byte(key), []byte(value))
+		slvStore.Put([]byte(key), []byte(value))
+	}
+	slvStore.Delete([]byte("K123"))
+	slvStore.Put([]byte("K456"), []byte("Corrupted"))
+	slvStore.Put([]byte("K789A"), []byte("Extra"))
+	mstrSrvr, master, stopMaster := serveDigests(t, mstrStore)
+	defer stopMaster()
+	_, slave, stopSlave := serveDigests(t, slvStore)
+	defer stopSlave()
+
+	ctx := context.Background()
+	opts := CompareOptions{FanOut: 4, LeafSize: 10, MaxMismatches: 10}
+	diff, err := CompareReplicas(ctx, master, slave, opts)
+	if err != nil {
+		t.Fatalf("Unable to compare replicas. Error: %v", err)
+	}
+	expected := []KeyMismatch{
+		{Key: []byte("K123"), InMaster: true},
+		{Key: []byte("K456"), InMaster: true, InSlave: true},
+		{Key: []byte("K789A"), InSlave: true},
+	}
+	if diff.Truncated || len(diff.Ranges) != 3 || fmt.Sprint(diff.Mismatches) != fmt.Sprint(expected) {
+		t.Errorf("Expected mismatches: %v in 3 ranges. Actual: %v in %d ranges", expected, diff.Mismatches, len(diff.Ranges))
+	}
+	// Only the divergent ranges are descended into
+	if numCalls := atomic.LoadInt32(&mstrSrvr.numCalls); numCalls > 3*5+3 {
+		t.Errorf("Expected the comparison to descend only into divergent ranges. Calls: %d", numCalls)
+	}
+
+	opts.MaxMismatches = 1
+	if diff, err = CompareReplicas(ctx, master, slave, opts); err != nil || !diff.Truncated || len(diff.Mismatches) != 1 {
+		t.Errorf("Expected the comparison to stop at the first mismatch. Diff: %+v, Error: %v", diff, err)
+	}
+	if diff, err = CompareReplicas(ctx, master, master, opts); err != nil || !diff.Consistent() {
+		t.Errorf("Expected a node to be consistent with itself. Diff: %+v, Error: %v", diff, err)
+	}
+}
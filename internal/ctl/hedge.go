@@ -0,0 +1,172 @@
+package ctl
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// A HedgePolicy captures how a DKVShardClient hedges its reads against
+// slow replicas. A read that is yet to complete after the Delay is issued
+// once again onto another healthy replica, and the first of the two to
+// succeed is returned while the other one is cancelled. Only idempotent
+// reads, like Get, MultiGet and Exists, are ever hedged.
+type HedgePolicy struct {
+	// Delay is the duration after which a read is hedged, such as the
+	// 95th percentile of the latency of reads.
+	Delay time.Duration
+	// MaxRate is the number of hedged reads issued per second across
+	// all the reads of the client, beyond the MaxBurst, so that hedges
+	// do not pile onto replicas that are slow due to load. Reads beyond
+	// it are left to complete on their own. Zero disables the limit.
+	MaxRate float64
+	// MaxBurst is the number of hedged reads issued at once, which
+	// defaults to the MaxRate rounded up.
+	MaxBurst int
+}
+
+// DefaultHedgePolicy is a reasonable hedge policy that can be
+// customized and supplied to WithHedgePolicy.
+var DefaultHedgePolicy = HedgePolicy{
+	Delay:   20 * time.Millisecond,
+	MaxRate: 100,
+}
+
+// WithHedgePolicy sets the policy used by a DKVShardClient for hedging
+// its reads. By default reads are not hedged.
+func WithHedgePolicy(policy HedgePolicy) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.HedgePolicy = &policy
+	}
+}
+
+// HedgeStats are the counts of the reads hedged by a DKVShardClient,
+// which help tune the Delay of its HedgePolicy.
+type HedgeStats struct {
+	// Issued is the number of hedged reads issued.
+	Issued uint64
+	// Won is the number of hedged reads that completed before the
+	// reads they hedged.
+	Won uint64
+	// Throttled is the number of reads left unhedged due to the
+	// MaxRate of hedged reads.
+	Throttled uint64
+}
+
+// readFunc performs an idempotent read using the given client
+type readFunc func(ctx context.Context, cli *DKVClient) (interface{}, error)
+
+type readResult struct {
+	res    interface{}
+	err    error
+	cli    *DKVClient
+	hedged bool
+}
+
+type hedger struct {
+	policy HedgePolicy
+	burst  float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	// Shall be manipulated using atomics
+	issued, won, throttled uint64
+}
+
+func newHedger(policy HedgePolicy) *hedger {
+	burst := float64(policy.MaxBurst)
+	if burst <= 0 {
+		burst = math.Ceil(policy.MaxRate)
+	}
+	return &hedger{policy: policy, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// read performs the given read on the primary client, hedging it onto
+// the other client unless it completes within the delay of the policy.
+// It returns the client that served the read.
+func (hdgr *hedger) read(ctx context.Context, primary, hedge *DKVClient, read readFunc) (interface{}, *DKVClient, error) {
+	results := make(chan readResult, 2)
+	issue := func(cli *DKVClient, hedged bool) context.CancelFunc {
+		readCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			res, err := read(readCtx, cli)
+			results <- readResult{res, err, cli, hedged}
+		}()
+		return cancel
+	}
+	// Deferred cancellations abort the read that lost
+	defer issue(primary, false)()
+	timer := time.NewTimer(hdgr.policy.Delay)
+	defer timer.Stop()
+
+	var failed *readResult
+	for pending := 1; ; {
+		select {
+		case <-timer.C:
+			if !hdgr.allow() {
+				atomic.AddUint64(&hdgr.throttled, 1)
+				continue
+			}
+			atomic.AddUint64(&hdgr.issued, 1)
+			defer issue(hedge, true)()
+			pending++
+		case res := <-results:
+			pending--
+			// Failures of the transport are superseded by the other read
+			if res.err != nil && isTransportError(res.err) {
+				if failed == nil {
+					failed = &res
+				}
+				if pending > 0 {
+					continue
+				}
+				return failed.res, failed.cli, failed.err
+			}
+			if res.hedged {
+				atomic.AddUint64(&hdgr.won, 1)
+			}
+			return res.res, res.cli, res.err
+		}
+	}
+}
+
+// allow reports whether a hedged read can be issued within the maximum
+// rate of the policy, deducting it from the tokens available.
+func (hdgr *hedger) allow() bool {
+	if hdgr.policy.MaxRate <= 0 {
+		return true
+	}
+	hdgr.mu.Lock()
+	defer hdgr.mu.Unlock()
+	now := time.Now()
+	hdgr.tokens = math.Min(hdgr.burst, hdgr.tokens+now.Sub(hdgr.last).Seconds()*hdgr.policy.MaxRate)
+	hdgr.last = now
+	if hdgr.tokens < 1 {
+		return false
+	}
+	hdgr.tokens--
+	return true
+}
+
+func (hdgr *hedger) stats() HedgeStats {
+	return HedgeStats{
+		Issued:    atomic.LoadUint64(&hdgr.issued),
+		Won:       atomic.LoadUint64(&hdgr.won),
+		Throttled: atomic.LoadUint64(&hdgr.throttled),
+	}
+}
+
+// isTransportError reports whether the given error is that of a failed
+// GRPC call, as opposed to a failure conveyed by the status of the
+// response, like ErrKeyNotFound, which is as good as any response.
+func isTransportError(err error) bool {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	return errors.As(err, &grpcErr)
+}
@@ -0,0 +1,119 @@
+package ctl
+
+import (
+	"context"
+	"sync"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WithLeaderFollowing sets whether the DKVClient follows the leader
+// hinted by the master nodes that reject changes since they do not
+// lead their cluster. When following, the rejected call is retried
+// once on the hinted leader, which then serves all the subsequent
+// calls until it can no longer be reached. By default the rejections
+// are returned as errors matching ErrNotLeader, whose leader is
+// given by LeaderHint of pkg/errors.
+func WithLeaderFollowing(follow bool) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.FollowLeader = follow
+	}
+}
+
+// leaderFollower tracks the leader followed by a DKVClient, along with
+// the connections to every leader followed so far. Connections are
+// retained until the DKVClient is closed, since calls may be in flight
+// on an earlier leader while another one is followed.
+type leaderFollower struct {
+	mu       sync.Mutex
+	dialOpts []grpc.DialOption
+	lgr      *zap.Logger
+	addr     string
+	conns    map[string]*grpc.ClientConn
+}
+
+func newLeaderFollower(dialOpts []grpc.DialOption, lgr *zap.Logger) *leaderFollower {
+	return &leaderFollower{dialOpts: dialOpts, lgr: lgr, conns: make(map[string]*grpc.ClientConn)}
+}
+
+func (lf *leaderFollower) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	target, ldrConn := lf.leader()
+	var err error
+	if ldrConn != nil {
+		if err = ldrConn.Invoke(ctx, method, req, reply, opts...); status.Code(err) == codes.Unavailable {
+			lf.forget(target)
+		}
+	} else {
+		target = cc.Target()
+		err = invoker(ctx, method, req, reply, cc, opts...)
+	}
+	ldrAddr := hintedLeader(reply)
+	if err != nil || ldrAddr == "" || ldrAddr == target {
+		return err
+	}
+	if ldrConn, err = lf.follow(ctx, ldrAddr); err != nil {
+		// Leave the rejection along with its hint to the caller
+		lf.lgr.Warn("Unable to follow the leader", zap.String("method", method), zap.String("leaderAddr", ldrAddr), zap.Error(err))
+		return nil
+	}
+	return ldrConn.Invoke(ctx, method, req, reply, opts...)
+}
+
+func (lf *leaderFollower) leader() (string, *grpc.ClientConn) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.addr, lf.conns[lf.addr]
+}
+
+func (lf *leaderFollower) follow(ctx context.Context, addr string) (*grpc.ClientConn, error) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	conn, present := lf.conns[addr]
+	if !present {
+		var err error
+		if conn, err = grpc.DialContext(ctx, addr, lf.dialOpts...); err != nil {
+			return nil, err
+		}
+		lf.conns[addr] = conn
+	}
+	lf.lgr.Info("Following the leader", zap.String("leaderAddr", addr))
+	lf.addr = addr
+	return conn, nil
+}
+
+// forget stops following the given leader, so that the subsequent
+// calls are made on the DKV service given to the DKVClient.
+func (lf *leaderFollower) forget(addr string) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	if lf.addr == addr {
+		lf.addr = ""
+	}
+}
+
+func (lf *leaderFollower) close() error {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	var err error
+	for _, conn := range lf.conns {
+		if closeErr := conn.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// hintedLeader returns the address of the leader hinted by the status
+// of the given reply of a rejected change, if any.
+func hintedLeader(reply interface{}) string {
+	if res, ok := reply.(interface{ GetStatus() *serverpb.Status }); ok {
+		if res.GetStatus().GetCode() == int32(serverpb.StatusCode_NotLeader) {
+			return res.GetStatus().GetLeader().GetDkvAddr()
+		}
+	}
+	return ""
+}
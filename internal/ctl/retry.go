@@ -0,0 +1,111 @@
+package ctl
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// A RetryPolicy captures how the DKVClient retries its calls when
+// they fail due to transient transport level errors, like the ones
+// seen during leader elections. Application level errors conveyed
+// through the status of a response are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts made for a
+	// call, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the duration to wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponentially growing backoff duration.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, in the range [0, 1], by which every
+	// backoff duration is randomly increased or decreased.
+	Jitter float64
+	// RetryPuts indicates whether Put calls must also be retried.
+	// Note that a retried Put may get applied more than once.
+	RetryPuts bool
+}
+
+// DefaultRetryPolicy is a reasonable retry policy that can be
+// customized and supplied to WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Jitter:         0.2,
+}
+
+// WithRetryPolicy sets the policy used for retrying idempotent
+// calls like Get, MultiGet, GetChanges and Exists. By default
+// calls are not retried.
+func WithRetryPolicy(policy RetryPolicy) DKVClientOption {
+	return func(opts *DKVClientOpts) {
+		opts.RetryPolicy = &policy
+	}
+}
+
+var idempotentMethods = map[string]bool{
+	"/dkv.serverpb.DKV/Get":                   true,
+	"/dkv.serverpb.DKV/MultiGet":              true,
+	"/dkv.serverpb.DKV/Exists":                true,
+	"/dkv.serverpb.DKVReplication/GetChanges": true,
+}
+
+const putMethod = "/dkv.serverpb.DKV/Put"
+
+// retryableCodes are the GRPC codes that represent transient
+// transport level failures.
+var retryableCodes = map[codes.Code]bool{
+	codes.Unavailable: true,
+}
+
+func (policy *RetryPolicy) isRetryable(method string) bool {
+	return idempotentMethods[method] || (policy.RetryPuts && method == putMethod)
+}
+
+func (policy *RetryPolicy) backoff(retry int) time.Duration {
+	backoff := policy.InitialBackoff
+	for i := 1; i < retry && backoff < policy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+		backoff = policy.MaxBackoff
+	}
+	jitter := policy.Jitter * (2*rand.Float64() - 1)
+	return time.Duration(float64(backoff) * (1 + jitter))
+}
+
+func (policy *RetryPolicy) unaryInterceptor(lgr *zap.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !policy.isRetryable(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		maxAttempts := policy.MaxAttempts
+		if callOpts := callOptsFrom(ctx); callOpts != nil && callOpts.maxAttempts > 0 {
+			maxAttempts = callOpts.maxAttempts
+		}
+		var err error
+		for attempt := 1; ; attempt++ {
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || !retryableCodes[status.Code(err)] || attempt >= maxAttempts {
+				return err
+			}
+			backoff := policy.backoff(attempt)
+			// Give up if the overall deadline expires before the next attempt
+			if deadline, present := ctx.Deadline(); present && time.Until(deadline) <= backoff {
+				return err
+			}
+			lgr.Debug("Retrying failed call", zap.String("method", method), zap.Int("attempt", attempt), zap.Duration("backoff", backoff), zap.Error(err))
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return err
+			}
+		}
+	}
+}
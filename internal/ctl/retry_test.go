@@ -0,0 +1,170 @@
+package ctl
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyDKVServer fails the first `numFailures` calls of every
+// method with a transient UNAVAILABLE error.
+type flakyDKVServer struct {
+	serverpb.UnimplementedDKVServer
+	numFailures uint32
+	numCalls    uint32
+}
+
+func (fds *flakyDKVServer) call() error {
+	if atomic.AddUint32(&fds.numCalls, 1) <= fds.numFailures {
+		return status.Error(codes.Unavailable, "leader election in progress")
+	}
+	return nil
+}
+
+func (fds *flakyDKVServer) Get(ctx context.Context, getReq *serverpb.GetRequest) (*serverpb.GetResponse, error) {
+	if err := fds.call(); err != nil {
+		return nil, err
+	}
+	return &serverpb.GetResponse{Status: &serverpb.Status{}, Value: getReq.Key, Found: true}, nil
+}
+
+func (fds *flakyDKVServer) Put(ctx context.Context, putReq *serverpb.PutRequest) (*serverpb.PutResponse, error) {
+	if err := fds.call(); err != nil {
+		return nil, err
+	}
+	return &serverpb.PutResponse{Status: &serverpb.Status{}}, nil
+}
+
+func (fds *flakyDKVServer) MultiGet(ctx context.Context, multiGetReq *serverpb.MultiGetRequest) (*serverpb.MultiGetResponse, error) {
+	atomic.AddUint32(&fds.numCalls, 1)
+	return &serverpb.MultiGetResponse{Status: &serverpb.Status{Code: -1, Message: "application error"}}, nil
+}
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     50 * time.Millisecond,
+	Jitter:         0.2,
+}
+
+func TestRetryOnTransientErrors(t *testing.T) {
+	flakySrvr := &flakyDKVServer{numFailures: 2}
+	grpcSrvr := serveDKV(t, flakySrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithRetryPolicy(testRetryPolicy))
+	defer client.Close()
+
+	expectGet(t, client, "hello")
+	if numCalls := atomic.LoadUint32(&flakySrvr.numCalls); numCalls != 3 {
+		t.Errorf("Expected 3 attempts for GET. Actual: %d", numCalls)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	flakySrvr := &flakyDKVServer{numFailures: 10}
+	grpcSrvr := serveDKV(t, flakySrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithRetryPolicy(testRetryPolicy))
+	defer client.Close()
+
+	if _, err := client.Get([]byte("hello")); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected UNAVAILABLE error. Actual: %v", err)
+	}
+	if numCalls := atomic.LoadUint32(&flakySrvr.numCalls); numCalls != uint32(testRetryPolicy.MaxAttempts) {
+		t.Errorf("Expected %d attempts for GET. Actual: %d", testRetryPolicy.MaxAttempts, numCalls)
+	}
+}
+
+func TestNoRetryWithoutPolicy(t *testing.T) {
+	flakySrvr := &flakyDKVServer{numFailures: 1}
+	grpcSrvr := serveDKV(t, flakySrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t)
+	defer client.Close()
+
+	if _, err := client.Get([]byte("hello")); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected UNAVAILABLE error. Actual: %v", err)
+	}
+	if numCalls := atomic.LoadUint32(&flakySrvr.numCalls); numCalls != 1 {
+		t.Errorf("Expected a single attempt for GET. Actual: %d", numCalls)
+	}
+}
+
+func TestRetryPutsOnlyWhenOptedIn(t *testing.T) {
+	flakySrvr := &flakyDKVServer{numFailures: 1}
+	grpcSrvr := serveDKV(t, flakySrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithRetryPolicy(testRetryPolicy))
+	if err := client.Put([]byte("hello"), []byte("world")); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected UNAVAILABLE error for PUT without opting in. Actual: %v", err)
+	}
+	client.Close()
+
+	putRetryPolicy := testRetryPolicy
+	putRetryPolicy.RetryPuts = true
+	atomic.StoreUint32(&flakySrvr.numCalls, 0)
+	client = newDKVClient(t, WithRetryPolicy(putRetryPolicy))
+	defer client.Close()
+	if err := client.Put([]byte("hello"), []byte("world")); err != nil {
+		t.Errorf("Expected PUT to succeed after a retry. Error: %v", err)
+	}
+	if numCalls := atomic.LoadUint32(&flakySrvr.numCalls); numCalls != 2 {
+		t.Errorf("Expected 2 attempts for PUT. Actual: %d", numCalls)
+	}
+}
+
+func TestNoRetryOnApplicationErrors(t *testing.T) {
+	flakySrvr := &flakyDKVServer{}
+	grpcSrvr := serveDKV(t, flakySrvr)
+	defer grpcSrvr.Stop()
+
+	client := newDKVClient(t, WithRetryPolicy(testRetryPolicy))
+	defer client.Close()
+
+	if res, err := client.dkvCli.MultiGet(context.Background(), &serverpb.MultiGetRequest{}); err != nil {
+		t.Errorf("Expected no transport error for MULTIGET. Error: %v", err)
+	} else if res.Status.Code == 0 {
+		t.Errorf("Expected an application error status for MULTIGET")
+	}
+	if numCalls := atomic.LoadUint32(&flakySrvr.numCalls); numCalls != 1 {
+		t.Errorf("Expected a single attempt for MULTIGET. Actual: %d", numCalls)
+	}
+}
+
+func TestRetryRespectsDeadline(t *testing.T) {
+	flakySrvr := &flakyDKVServer{numFailures: 10}
+	grpcSrvr := serveDKV(t, flakySrvr)
+	defer grpcSrvr.Stop()
+
+	slowRetryPolicy := testRetryPolicy
+	slowRetryPolicy.InitialBackoff, slowRetryPolicy.MaxBackoff = time.Second, time.Second
+	client := newDKVClient(t, WithRetryPolicy(slowRetryPolicy), WithTimeout(200*time.Millisecond))
+	defer client.Close()
+
+	start := time.Now()
+	if _, err := client.Get([]byte("hello")); err == nil {
+		t.Error("Expected an error for GET")
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("Expected GET to give up within the deadline. But it took %v", elapsed)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 40 * time.Millisecond}
+	expBackoffs := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond}
+	for i, expBackoff := range expBackoffs {
+		if backoff := policy.backoff(i + 1); backoff != expBackoff {
+			t.Errorf("Backoff mismatch for retry %d. Expected: %v, Actual: %v", i+1, expBackoff, backoff)
+		}
+	}
+}
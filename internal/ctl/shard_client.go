@@ -0,0 +1,378 @@
+package ctl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// A DKVShardClient maintains connections to all the nodes of a DKV
+// shard, i.e., a master along with its replicas. All the writes are
+// routed to the master while the reads are balanced in a round robin
+// manner across all the healthy replicas. Reads fall back onto the
+// master when none of the replicas are healthy. Reads can also be
+// hedged onto another healthy replica as per WithHedgePolicy.
+type DKVShardClient struct {
+	master *DKVClient
+	opts   *DKVClientOpts
+	hdgr   *hedger
+	// Secures the connections to all the nodes alike
+	transportOpt grpc.DialOption
+
+	mu       sync.RWMutex
+	replicas []*replicaClient
+	nextRepl uint32
+	// Serializes the updates to replicas
+	updMu sync.Mutex
+
+	hlthTckr *time.Ticker
+	hlthStop chan struct{}
+}
+
+type replicaClient struct {
+	addr string
+	cli  *DKVClient
+	// Shall be manipulated using atomics
+	healthy uint32
+}
+
+// NewDKVShardClient creates an insecure client against the given DKV
+// shard addresses. The first address must belong to the master node
+// onto which all the writes are routed, while the remaining addresses
+// belong to the replicas that serve the reads. While the client waits
+// for the master to be reachable, it does not wait for the replicas
+// and reads from them only once they are found healthy.
+func NewDKVShardClient(addrs []string, opts ...DKVClientOption) (*DKVShardClient, error) {
+	return newDKVShardClient(addrs, grpc.WithInsecure(), opts...)
+}
+
+// NewTLSDKVShardClient creates a client that communicates over TLS with
+// all the nodes of the given DKV shard addresses, which are as given to
+// NewDKVShardClient. The certificate files are as given to
+// NewTLSDKVClient.
+func NewTLSDKVShardClient(addrs []string, certFile, keyFile, caFile string, opts ...DKVClientOption) (*DKVShardClient, error) {
+	tlsConf, err := newClientTLSConfig(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return newDKVShardClient(addrs, grpc.WithTransportCredentials(credentials.NewTLS(tlsConf)), opts...)
+}
+
+func newDKVShardClient(addrs []string, transportOpt grpc.DialOption, opts ...DKVClientOption) (*DKVShardClient, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("atleast the DKV master address must be provided")
+	}
+	dkvCliOpts := newDKVClientOpts(opts...)
+	master, err := connectDKVClient(addrs[0], dkvCliOpts, transportOpt, grpc.WithBlock())
+	if err != nil {
+		return nil, err
+	}
+	shardCli := &DKVShardClient{master: master, opts: dkvCliOpts, transportOpt: transportOpt}
+	if dkvCliOpts.HedgePolicy != nil {
+		shardCli.hdgr = newHedger(*dkvCliOpts.HedgePolicy)
+	}
+	if err = shardCli.UpdateReplicas(addrs[1:]); err != nil {
+		shardCli.Close()
+		return nil, err
+	}
+	shardCli.hlthTckr = time.NewTicker(dkvCliOpts.HealthCheckInterval)
+	shardCli.hlthStop = make(chan struct{})
+	go shardCli.checkHealthPeriodically()
+	return shardCli, nil
+}
+
+// Master returns the client connected to the master node of the
+// shard. It can be used for operations not exposed by DKVShardClient.
+func (shardCli *DKVShardClient) Master() *DKVClient {
+	return shardCli.master
+}
+
+// UpdateReplicas refreshes the set of replicas with the given addresses.
+// Connections to replicas that are retained are reused, while those of
+// the removed replicas are closed.
+func (shardCli *DKVShardClient) UpdateReplicas(replicaAddrs []string) error {
+	shardCli.updMu.Lock()
+	defer shardCli.updMu.Unlock()
+
+	shardCli.mu.RLock()
+	currRepls := make(map[string]*replicaClient, len(shardCli.replicas))
+	for _, repl := range shardCli.replicas {
+		currRepls[repl.addr] = repl
+	}
+	shardCli.mu.RUnlock()
+
+	var newRepls, addedRepls []*replicaClient
+	for _, addr := range replicaAddrs {
+		if repl, present := currRepls[addr]; present {
+			newRepls = append(newRepls, repl)
+			delete(currRepls, addr)
+			continue
+		}
+		cli, err := connectDKVClient(addr, shardCli.opts, shardCli.transportOpt)
+		if err != nil {
+			for _, repl := range addedRepls {
+				repl.cli.Close()
+			}
+			return err
+		}
+		repl := &replicaClient{addr: addr, cli: cli}
+		newRepls = append(newRepls, repl)
+		addedRepls = append(addedRepls, repl)
+	}
+	checkHealth(addedRepls, shardCli.opts)
+
+	shardCli.mu.Lock()
+	shardCli.replicas = newRepls
+	shardCli.mu.Unlock()
+
+	// Remaining ones are no longer part of the shard
+	for _, repl := range currRepls {
+		repl.cli.Close()
+	}
+	return nil
+}
+
+// HealthyReplicas returns the addresses of all the replicas that
+// passed their latest health check.
+func (shardCli *DKVShardClient) HealthyReplicas() []string {
+	shardCli.mu.RLock()
+	defer shardCli.mu.RUnlock()
+	var addrs []string
+	for _, repl := range shardCli.replicas {
+		if repl.isHealthy() {
+			addrs = append(addrs, repl.addr)
+		}
+	}
+	return addrs
+}
+
+// Put routes the GRPC Put method to the master node.
+func (shardCli *DKVShardClient) Put(key []byte, value []byte) error {
+	return shardCli.master.Put(key, value)
+}
+
+// PutTTL routes the GRPC Put method for an expiring key to the master node.
+func (shardCli *DKVShardClient) PutTTL(key, value []byte, ttl time.Duration) error {
+	return shardCli.master.PutTTL(key, value, ttl)
+}
+
+// MultiPut routes the GRPC MultiPut method to the master node.
+func (shardCli *DKVShardClient) MultiPut(pairs ...KVPair) error {
+	return shardCli.master.MultiPut(pairs...)
+}
+
+// CompareAndSet routes the GRPC CompareAndSet method to the master node.
+func (shardCli *DKVShardClient) CompareAndSet(key, expectedValue, newValue []byte) (bool, error) {
+	return shardCli.master.CompareAndSet(key, expectedValue, newValue)
+}
+
+// Increment routes the GRPC Increment method to the master node.
+func (shardCli *DKVShardClient) Increment(key []byte, delta int64) (int64, error) {
+	return shardCli.master.Increment(key, delta)
+}
+
+// Txn begins a transaction that is committed on the master node.
+func (shardCli *DKVShardClient) Txn() *Txn {
+	return shardCli.master.Txn()
+}
+
+// Delete routes the GRPC Delete method to the master node.
+func (shardCli *DKVShardClient) Delete(key []byte) error {
+	return shardCli.master.Delete(key)
+}
+
+// Undelete routes the GRPC Undelete method to the master node.
+func (shardCli *DKVShardClient) Undelete(key []byte) error {
+	return shardCli.master.Undelete(key)
+}
+
+// Get routes the GRPC Get method to one of the healthy replicas.
+func (shardCli *DKVShardClient) Get(key []byte) (*serverpb.GetResponse, error) {
+	res, _, err := shardCli.hedgedRead(func(ctx context.Context, cli *DKVClient) (interface{}, error) {
+		return cli.GetWithCtx(ctx, key)
+	})
+	getRes, _ := res.(*serverpb.GetResponse)
+	return getRes, err
+}
+
+// GetWithConsistency routes the GRPC Get method to one of the healthy
+// replicas, falling back onto the master node if this replica lags
+// behind by more than the given maxLag changes.
+func (shardCli *DKVShardClient) GetWithConsistency(key []byte, maxLag uint64) (*serverpb.GetResponse, error) {
+	res, readCli, err := shardCli.hedgedRead(func(ctx context.Context, cli *DKVClient) (interface{}, error) {
+		return cli.GetWithConsistencyWithCtx(ctx, key, maxLag)
+	})
+	if errors.Is(err, ErrStaleRead) && readCli != shardCli.master {
+		return shardCli.master.GetWithConsistency(key, maxLag)
+	}
+	getRes, _ := res.(*serverpb.GetResponse)
+	return getRes, err
+}
+
+// MultiGet routes the GRPC MultiGet method to one of the healthy replicas.
+func (shardCli *DKVShardClient) MultiGet(keys ...[]byte) ([][]byte, error) {
+	res, _, err := shardCli.hedgedRead(func(ctx context.Context, cli *DKVClient) (interface{}, error) {
+		return cli.MultiGetWithCtx(ctx, keys...)
+	})
+	vals, _ := res.([][]byte)
+	return vals, err
+}
+
+// MultiGetDetailed routes the detailed GRPC MultiGet method to one of
+// the healthy replicas.
+func (shardCli *DKVShardClient) MultiGetDetailed(keys ...[]byte) ([]KVResult, error) {
+	res, _, err := shardCli.hedgedRead(func(ctx context.Context, cli *DKVClient) (interface{}, error) {
+		return cli.MultiGetDetailedWithCtx(ctx, keys...)
+	})
+	results, _ := res.([]KVResult)
+	return results, err
+}
+
+// Exists routes the GRPC Exists method to one of the healthy replicas.
+func (shardCli *DKVShardClient) Exists(keys ...[]byte) ([]bool, error) {
+	res, _, err := shardCli.hedgedRead(func(ctx context.Context, cli *DKVClient) (interface{}, error) {
+		return cli.ExistsWithCtx(ctx, keys...)
+	})
+	presence, _ := res.([]bool)
+	return presence, err
+}
+
+// Iterate routes the GRPC Iterate method to one of the healthy replicas.
+func (shardCli *DKVShardClient) Iterate(keyPrefix, startKey []byte, opts ...IterateOption) (<-chan KVPair, error) {
+	return shardCli.readClient().Iterate(keyPrefix, startKey, opts...)
+}
+
+// HedgeStats returns the counts of the reads hedged so far, which are
+// all zero unless the client is created with WithHedgePolicy.
+func (shardCli *DKVShardClient) HedgeStats() HedgeStats {
+	if shardCli.hdgr == nil {
+		return HedgeStats{}
+	}
+	return shardCli.hdgr.stats()
+}
+
+// Close closes the connections to all the nodes of the shard.
+func (shardCli *DKVShardClient) Close() error {
+	if shardCli.hlthStop != nil {
+		shardCli.hlthTckr.Stop()
+		close(shardCli.hlthStop)
+	}
+	shardCli.mu.Lock()
+	for _, repl := range shardCli.replicas {
+		repl.cli.Close()
+	}
+	shardCli.replicas = nil
+	shardCli.mu.Unlock()
+	return shardCli.master.Close()
+}
+
+func (shardCli *DKVShardClient) readClient() *DKVClient {
+	readCli, _ := shardCli.readClients()
+	return readCli
+}
+
+// readClients returns the client of the replica next in the round robin
+// order, along with that of the healthy replica following it, if any,
+// onto which the reads are hedged.
+func (shardCli *DKVShardClient) readClients() (*DKVClient, *DKVClient) {
+	shardCli.mu.RLock()
+	defer shardCli.mu.RUnlock()
+	numRepls := uint32(len(shardCli.replicas))
+	for i := uint32(0); i < numRepls; i++ {
+		next := atomic.AddUint32(&shardCli.nextRepl, 1)
+		if repl := shardCli.replicas[next%numRepls]; repl.isHealthy() {
+			for j := uint32(1); j < numRepls; j++ {
+				if hedgeRepl := shardCli.replicas[(next+j)%numRepls]; hedgeRepl.isHealthy() {
+					return repl.cli, hedgeRepl.cli
+				}
+			}
+			return repl.cli, nil
+		}
+	}
+	return shardCli.master, nil
+}
+
+// hedgedRead performs the given read on one of the healthy replicas,
+// hedging it onto another one as per the hedge policy, and returns the
+// client that served it.
+func (shardCli *DKVShardClient) hedgedRead(read readFunc) (interface{}, *DKVClient, error) {
+	readCli, hedgeCli := shardCli.readClients()
+	ctx, cancel := readCli.newTimeoutContext()
+	defer cancel()
+	if shardCli.hdgr == nil || hedgeCli == nil {
+		res, err := read(ctx, readCli)
+		return res, readCli, err
+	}
+	return shardCli.hdgr.read(ctx, readCli, hedgeCli, read)
+}
+
+func (shardCli *DKVShardClient) checkHealthPeriodically() {
+	for {
+		select {
+		case <-shardCli.hlthTckr.C:
+			shardCli.mu.RLock()
+			repls := shardCli.replicas
+			shardCli.mu.RUnlock()
+			checkHealth(repls, shardCli.opts)
+		case <-shardCli.hlthStop:
+			return
+		}
+	}
+}
+
+func checkHealth(repls []*replicaClient, opts *DKVClientOpts) {
+	var wg sync.WaitGroup
+	for _, repl := range repls {
+		wg.Add(1)
+		go func(repl *replicaClient) {
+			defer wg.Done()
+			repl.checkHealth(opts.Timeout, opts.Logger)
+		}(repl)
+	}
+	wg.Wait()
+}
+
+func (repl *replicaClient) isHealthy() bool {
+	return atomic.LoadUint32(&repl.healthy) == 1
+}
+
+func (repl *replicaClient) checkHealth(timeout time.Duration, lgr *zap.Logger) {
+	healthy := uint32(0)
+	if repl.isServing(timeout) {
+		healthy = 1
+	}
+	if prevHealthy := atomic.SwapUint32(&repl.healthy, healthy); prevHealthy != healthy {
+		lgr.Info("Health of replica changed", zap.String("addr", repl.addr), zap.Bool("healthy", healthy == 1))
+	}
+}
+
+func (repl *replicaClient) isServing(timeout time.Duration) bool {
+	switch repl.cli.cliConn.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	servingStatus, err := repl.cli.HealthCheckWithCtx(ctx)
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		// Nodes without the health service are considered
+		// healthy as long as they are reachable
+		return true
+	case err != nil:
+		return false
+	default:
+		return servingStatus == grpc_health_v1.HealthCheckResponse_SERVING
+	}
+}
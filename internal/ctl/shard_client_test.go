@@ -0,0 +1,300 @@
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/server/security"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	shardMasterPort   = 8586
+	shardReplicaPort1 = 8587
+	shardReplicaPort2 = 8588
+	shardHlthInterval = 100 * time.Millisecond
+)
+
+// nodeDKVServer responds to every Get with its own name
+// so that the routing of reads can be verified.
+// Nodes that are stale reject all the reads with a bounded lag,
+// while slow nodes respond only after their delay.
+type nodeDKVServer struct {
+	serverpb.UnimplementedDKVServer
+	name    string
+	numPuts uint32
+	stale   bool
+	delay   time.Duration
+	// Number of reads cancelled before the delay
+	numCancelled uint32
+}
+
+func (nds *nodeDKVServer) Get(ctx context.Context, getReq *serverpb.GetRequest) (*serverpb.GetResponse, error) {
+	if nds.delay > 0 {
+		select {
+		case <-time.After(nds.delay):
+		case <-ctx.Done():
+			atomic.AddUint32(&nds.numCancelled, 1)
+			return nil, ctx.Err()
+		}
+	}
+	if nds.stale && getReq.LagBounded {
+		return &serverpb.GetResponse{Status: &serverpb.Status{Code: int32(serverpb.StatusCode_StaleRead), Message: "stale"}}, nil
+	}
+	return &serverpb.GetResponse{Status: &serverpb.Status{}, Value: []byte(nds.name), Found: true}, nil
+}
+
+func (nds *nodeDKVServer) Put(ctx context.Context, putReq *serverpb.PutRequest) (*serverpb.PutResponse, error) {
+	atomic.AddUint32(&nds.numPuts, 1)
+	return &serverpb.PutResponse{Status: &serverpb.Status{}}, nil
+}
+
+type shardNode struct {
+	grpcSrvr *grpc.Server
+	hlthSrvr *health.Server
+	dkvSrvr  *nodeDKVServer
+}
+
+func TestShardClientRoutesWritesToMaster(t *testing.T) {
+	master, repl1, repl2 := serveShardNode(t, shardMasterPort), serveShardNode(t, shardReplicaPort1), serveShardNode(t, shardReplicaPort2)
+	defer stopShardNodes(master, repl1, repl2)
+
+	shardCli := newShardClient(t, shardMasterPort, shardReplicaPort1, shardReplicaPort2)
+	defer shardCli.Close()
+
+	numPuts := 5
+	for i := 0; i < numPuts; i++ {
+		if err := shardCli.Put([]byte("hello"), []byte("world")); err != nil {
+			t.Fatalf("Unable to PUT. Error: %v", err)
+		}
+	}
+	if actPuts := atomic.LoadUint32(&master.dkvSrvr.numPuts); actPuts != uint32(numPuts) {
+		t.Errorf("Expected all the %d PUTs on master. Actual: %d", numPuts, actPuts)
+	}
+	if atomic.LoadUint32(&repl1.dkvSrvr.numPuts) != 0 || atomic.LoadUint32(&repl2.dkvSrvr.numPuts) != 0 {
+		t.Errorf("Expected no PUTs on replicas")
+	}
+}
+
+func TestShardClientBalancesReads(t *testing.T) {
+	master, repl1, repl2 := serveShardNode(t, shardMasterPort), serveShardNode(t, shardReplicaPort1), serveShardNode(t, shardReplicaPort2)
+	defer stopShardNodes(master, repl1, repl2)
+
+	shardCli := newShardClient(t, shardMasterPort, shardReplicaPort1, shardReplicaPort2)
+	defer shardCli.Close()
+
+	reads := readsPerNode(t, shardCli, 10)
+	if reads[repl1.dkvSrvr.name] != 5 || reads[repl2.dkvSrvr.name] != 5 {
+		t.Errorf("Expected reads to be balanced across replicas. Actual: %v", reads)
+	}
+}
+
+func TestShardClientSkipsUnhealthyReplicas(t *testing.T) {
+	master, repl1, repl2 := serveShardNode(t, shardMasterPort), serveShardNode(t, shardReplicaPort1), serveShardNode(t, shardReplicaPort2)
+	defer stopShardNodes(master, repl1, repl2)
+
+	shardCli := newShardClient(t, shardMasterPort, shardReplicaPort1, shardReplicaPort2)
+	defer shardCli.Close()
+
+	repl1.hlthSrvr.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	time.Sleep(3 * shardHlthInterval)
+	if reads := readsPerNode(t, shardCli, 10); reads[repl2.dkvSrvr.name] != 10 {
+		t.Errorf("Expected all reads on the healthy replica. Actual: %v", reads)
+	}
+
+	repl2.grpcSrvr.Stop()
+	time.Sleep(3 * shardHlthInterval)
+	if reads := readsPerNode(t, shardCli, 10); reads[master.dkvSrvr.name] != 10 {
+		t.Errorf("Expected all reads on master without healthy replicas. Actual: %v", reads)
+	}
+
+	repl1.hlthSrvr.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	time.Sleep(3 * shardHlthInterval)
+	if reads := readsPerNode(t, shardCli, 10); reads[repl1.dkvSrvr.name] != 10 {
+		t.Errorf("Expected all reads on the recovered replica. Actual: %v", reads)
+	}
+}
+
+func TestShardClientUpdateReplicas(t *testing.T) {
+	master, repl1, repl2 := serveShardNode(t, shardMasterPort), serveShardNode(t, shardReplicaPort1), serveShardNode(t, shardReplicaPort2)
+	defer stopShardNodes(master, repl1, repl2)
+
+	shardCli := newShardClient(t, shardMasterPort, shardReplicaPort1)
+	defer shardCli.Close()
+
+	if reads := readsPerNode(t, shardCli, 10); reads[repl1.dkvSrvr.name] != 10 {
+		t.Errorf("Expected all reads on the only replica. Actual: %v", reads)
+	}
+
+	if err := shardCli.UpdateReplicas([]string{shardAddr(shardReplicaPort2)}); err != nil {
+		t.Fatalf("Unable to update replicas. Error: %v", err)
+	}
+	if healthyRepls := shardCli.HealthyReplicas(); len(healthyRepls) != 1 || healthyRepls[0] != shardAddr(shardReplicaPort2) {
+		t.Errorf("Healthy replicas mismatch. Expected: [%s], Actual: %v", shardAddr(shardReplicaPort2), healthyRepls)
+	}
+	if reads := readsPerNode(t, shardCli, 10); reads[repl2.dkvSrvr.name] != 10 {
+		t.Errorf("Expected all reads on the updated replica. Actual: %v", reads)
+	}
+}
+
+func TestShardClientFallsBackToMasterOnStaleReads(t *testing.T) {
+	master, repl1 := serveShardNode(t, shardMasterPort), serveShardNode(t, shardReplicaPort1)
+	defer stopShardNodes(master, repl1)
+	repl1.dkvSrvr.stale = true
+
+	shardCli := newShardClient(t, shardMasterPort, shardReplicaPort1)
+	defer shardCli.Close()
+
+	if res, err := shardCli.GetWithConsistency([]byte("hello"), 10); err != nil {
+		t.Fatalf("Unable to GET with consistency. Error: %v", err)
+	} else if string(res.Value) != master.dkvSrvr.name {
+		t.Errorf("Expected stale read to be served by master. Actual: %s", res.Value)
+	}
+	if res, err := shardCli.Get([]byte("hello")); err != nil {
+		t.Fatalf("Unable to GET. Error: %v", err)
+	} else if string(res.Value) != repl1.dkvSrvr.name {
+		t.Errorf("Expected unbounded read to be served by replica. Actual: %s", res.Value)
+	}
+}
+
+func TestShardClientHedgesSlowReads(t *testing.T) {
+	master, repl1, repl2 := serveShardNode(t, shardMasterPort), serveShardNode(t, shardReplicaPort1), serveShardNode(t, shardReplicaPort2)
+	defer stopShardNodes(master, repl1, repl2)
+	repl1.dkvSrvr.delay = time.Second
+
+	shardCli := newShardClientWithOpts(t, []int{shardMasterPort, shardReplicaPort1, shardReplicaPort2}, WithHedgePolicy(HedgePolicy{Delay: 20 * time.Millisecond}))
+	defer shardCli.Close()
+
+	start := time.Now()
+	if reads := readsPerNode(t, shardCli, 10); reads[repl2.dkvSrvr.name] != 10 {
+		t.Errorf("Expected all reads to be served by the fast replica. Actual: %v", reads)
+	}
+	if elapsed := time.Since(start); elapsed >= repl1.dkvSrvr.delay {
+		t.Errorf("Expected the hedged reads to complete before the slow replica. Elapsed: %v", elapsed)
+	}
+	if stats := shardCli.HedgeStats(); stats.Issued != 5 || stats.Won != 5 || stats.Throttled != 0 {
+		t.Errorf("Expected 5 hedged reads issued and won. Actual: %+v", stats)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if numCancelled := atomic.LoadUint32(&repl1.dkvSrvr.numCancelled); numCancelled != 5 {
+		t.Errorf("Expected the 5 reads on the slow replica to be cancelled. Actual: %d", numCancelled)
+	}
+}
+
+func TestShardClientThrottlesHedgedReads(t *testing.T) {
+	master, repl1, repl2 := serveShardNode(t, shardMasterPort), serveShardNode(t, shardReplicaPort1), serveShardNode(t, shardReplicaPort2)
+	defer stopShardNodes(master, repl1, repl2)
+	repl1.dkvSrvr.delay = 200 * time.Millisecond
+
+	hedgePolicy := HedgePolicy{Delay: 20 * time.Millisecond, MaxRate: 0.01, MaxBurst: 1}
+	shardCli := newShardClientWithOpts(t, []int{shardMasterPort, shardReplicaPort1, shardReplicaPort2}, WithHedgePolicy(hedgePolicy))
+	defer shardCli.Close()
+
+	if reads := readsPerNode(t, shardCli, 10); reads[repl1.dkvSrvr.name] != 4 || reads[repl2.dkvSrvr.name] != 6 {
+		t.Errorf("Expected a single read of the slow replica to be hedged. Actual: %v", reads)
+	}
+	if stats := shardCli.HedgeStats(); stats.Issued != 1 || stats.Won != 1 || stats.Throttled != 4 {
+		t.Errorf("Expected 1 hedged read issued and 4 throttled. Actual: %+v", stats)
+	}
+}
+
+func TestShardClientHedgesOntoReplicasAlone(t *testing.T) {
+	master, repl1 := serveShardNode(t, shardMasterPort), serveShardNode(t, shardReplicaPort1)
+	defer stopShardNodes(master, repl1)
+	repl1.dkvSrvr.delay = 100 * time.Millisecond
+
+	shardCli := newShardClientWithOpts(t, []int{shardMasterPort, shardReplicaPort1}, WithHedgePolicy(HedgePolicy{Delay: 10 * time.Millisecond}))
+	defer shardCli.Close()
+
+	if reads := readsPerNode(t, shardCli, 3); reads[repl1.dkvSrvr.name] != 3 {
+		t.Errorf("Expected all reads on the only replica. Actual: %v", reads)
+	}
+	if stats := shardCli.HedgeStats(); stats.Issued != 0 {
+		t.Errorf("Expected no hedged reads without another replica. Actual: %+v", stats)
+	}
+}
+
+func TestTLSShardClient(t *testing.T) {
+	certs := newTestCerts(t)
+	defer os.RemoveAll(certs.dir)
+
+	creds, err := security.NewServerTLSCredentials(certs.serverCert, certs.serverKey, certs.caCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	master, repl := serveShardNode(t, shardMasterPort, grpc.Creds(creds)), serveShardNode(t, shardReplicaPort1, grpc.Creds(creds))
+	defer stopShardNodes(master, repl)
+
+	addrs := []string{shardAddr(shardMasterPort), shardAddr(shardReplicaPort1)}
+	shardCli, err := NewTLSDKVShardClient(addrs, certs.clientCert, certs.clientKey, certs.caCert, WithReadBufSize(testBufSize), WithWriteBufSize(testBufSize), WithHealthCheckInterval(shardHlthInterval))
+	if err != nil {
+		t.Fatalf("Unable to create TLS DKV shard client. Error: %v", err)
+	}
+	defer shardCli.Close()
+
+	if err := shardCli.Put([]byte("hello"), []byte("world")); err != nil {
+		t.Errorf("Unable to PUT onto master over TLS. Error: %v", err)
+	}
+	if reads := readsPerNode(t, shardCli, 2); reads[repl.dkvSrvr.name] != 2 {
+		t.Errorf("Expected all reads on the replica over TLS. Actual: %v", reads)
+	}
+}
+
+func readsPerNode(t *testing.T, shardCli *DKVShardClient, numReads int) map[string]int {
+	reads := make(map[string]int)
+	for i := 0; i < numReads; i++ {
+		if res, err := shardCli.Get([]byte("hello")); err != nil {
+			t.Fatalf("Unable to GET. Error: %v", err)
+		} else {
+			reads[string(res.Value)]++
+		}
+	}
+	return reads
+}
+
+func shardAddr(port int) string {
+	return fmt.Sprintf("%s:%d", dkvSvcHost, port)
+}
+
+func serveShardNode(t *testing.T, port int, opts ...grpc.ServerOption) *shardNode {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	node := &shardNode{grpc.NewServer(opts...), health.NewServer(), &nodeDKVServer{name: shardAddr(port)}}
+	serverpb.RegisterDKVServer(node.grpcSrvr, node.dkvSrvr)
+	grpc_health_v1.RegisterHealthServer(node.grpcSrvr, node.hlthSrvr)
+	go node.grpcSrvr.Serve(lis)
+	return node
+}
+
+func stopShardNodes(nodes ...*shardNode) {
+	for _, node := range nodes {
+		node.grpcSrvr.Stop()
+	}
+}
+
+func newShardClient(t *testing.T, ports ...int) *DKVShardClient {
+	return newShardClientWithOpts(t, ports)
+}
+
+func newShardClientWithOpts(t *testing.T, ports []int, opts ...DKVClientOption) *DKVShardClient {
+	var addrs []string
+	for _, port := range ports {
+		addrs = append(addrs, shardAddr(port))
+	}
+	opts = append([]DKVClientOption{WithReadBufSize(testBufSize), WithWriteBufSize(testBufSize), WithHealthCheckInterval(shardHlthInterval)}, opts...)
+	shardCli, err := NewDKVShardClient(addrs, opts...)
+	if err != nil {
+		t.Fatalf("Unable to create DKV shard client. Error: %v", err)
+	}
+	return shardCli
+}
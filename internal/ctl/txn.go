@@ -0,0 +1,117 @@
+package ctl
+
+import (
+	"context"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// ValueEquals is a Txn condition that holds when the given key is
+// present with the given value.
+func ValueEquals(key, value []byte) *serverpb.TxnCondition {
+	return &serverpb.TxnCondition{Type: serverpb.TxnCondition_Equals, Key: key, Value: value}
+}
+
+// KeyAbsent is a Txn condition that holds when the given key is absent.
+func KeyAbsent(key []byte) *serverpb.TxnCondition {
+	return &serverpb.TxnCondition{Type: serverpb.TxnCondition_Absent, Key: key}
+}
+
+// OpPut is a Txn mutation that associates the given value with the given key.
+func OpPut(key, value []byte) *serverpb.TrxnRecord {
+	return &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: key, Value: value}
+}
+
+// OpPutTTL is same as OpPut except that the key expires after the given
+// TTL, which is rounded up to the next whole second as with PutTTL.
+func OpPutTTL(key, value []byte, ttl time.Duration) *serverpb.TrxnRecord {
+	expireTS := time.Now().Add(ttl + time.Second - 1).Unix()
+	return &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: key, Value: value, ExpireTS: uint64(expireTS)}
+}
+
+// OpDelete is a Txn mutation that deletes the given key.
+func OpDelete(key []byte) *serverpb.TrxnRecord {
+	return &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Delete, Key: key}
+}
+
+// Txn builds a transaction that is committed using the GRPC Txn method.
+// If all of its conditions hold, its Then mutations are applied, else its
+// Else mutations are applied. Either way, the conditions are checked and
+// the mutations applied atomically. A Txn is not safe for concurrent use.
+type Txn struct {
+	dkvClnt *DKVClient
+	txnReq  *serverpb.TxnRequest
+}
+
+// Txn begins a transaction on the keys of the namespace of this client.
+// For example:
+//
+//	succeeded, err := dkvClnt.Txn().
+//	    If(ctl.ValueEquals(key, oldValue)).
+//	    Then(ctl.OpPut(key, newValue), ctl.OpDelete(lockKey)).
+//	    Commit()
+func (dkvClnt *DKVClient) Txn() *Txn {
+	return &Txn{dkvClnt: dkvClnt, txnReq: &serverpb.TxnRequest{}}
+}
+
+// If adds the given conditions, all of which must hold for the Then
+// mutations to be applied.
+func (txn *Txn) If(conds ...*serverpb.TxnCondition) *Txn {
+	txn.txnReq.Conditions = append(txn.txnReq.Conditions, conds...)
+	return txn
+}
+
+// Then adds the given mutations to those applied when all the
+// conditions hold, in their order.
+func (txn *Txn) Then(muts ...*serverpb.TrxnRecord) *Txn {
+	txn.txnReq.ThenMutations = append(txn.txnReq.ThenMutations, muts...)
+	return txn
+}
+
+// Else adds the given mutations to those applied when any of the
+// conditions does not hold, in their order.
+func (txn *Txn) Else(muts ...*serverpb.TrxnRecord) *Txn {
+	txn.txnReq.ElseMutations = append(txn.txnReq.ElseMutations, muts...)
+	return txn
+}
+
+// Commit invokes the GRPC Txn method and returns whether all the conditions
+// held. With compression, the values of the conditions are matched in their
+// compressed form. This is a convenience wrapper.
+func (txn *Txn) Commit() (bool, error) {
+	ctx, cancel := txn.dkvClnt.newTimeoutContext()
+	defer cancel()
+	return txn.CommitWithCtx(ctx)
+}
+
+// CommitWithCtx is same as Commit except that the GRPC Txn method is
+// invoked using the given context.
+func (txn *Txn) CommitWithCtx(ctx context.Context) (bool, error) {
+	dkvClnt := txn.dkvClnt
+	txnReq := &serverpb.TxnRequest{Namespace: dkvClnt.namespace}
+	for _, cond := range txn.txnReq.Conditions {
+		txnReq.Conditions = append(txnReq.Conditions, &serverpb.TxnCondition{Type: cond.Type, Key: cond.Key, Value: dkvClnt.compress(cond.Value)})
+	}
+	txnReq.ThenMutations = dkvClnt.compressMutations(txn.txnReq.ThenMutations)
+	txnReq.ElseMutations = dkvClnt.compressMutations(txn.txnReq.ElseMutations)
+	res, err := dkvClnt.dkvCli.Txn(ctx, txnReq)
+	var status *serverpb.Status
+	if res != nil {
+		status = res.Status
+	}
+	if err = errorFromStatus(status, err); err != nil {
+		return false, err
+	}
+	return res.Succeeded, nil
+}
+
+func (dkvClnt *DKVClient) compressMutations(muts []*serverpb.TrxnRecord) []*serverpb.TrxnRecord {
+	var cmpMuts []*serverpb.TrxnRecord
+	for _, mut := range muts {
+		cmpMut := *mut
+		cmpMut.Value = dkvClnt.compress(mut.Value)
+		cmpMuts = append(cmpMuts, &cmpMut)
+	}
+	return cmpMuts
+}
@@ -0,0 +1,135 @@
+package itest
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+)
+
+// BinaryOptions describe the keys and values written by RunBinaryRoundTrip.
+type BinaryOptions struct {
+	ClusterOptions
+	// Seed seeds the generation of the keys and values, so that the
+	// failures can be reproduced.
+	Seed int64
+	// NumKeys is the number of keys written within every namespace.
+	NumKeys int
+	// MaxKeyLen and MaxValueLen limit the lengths of the keys and values.
+	MaxKeyLen, MaxValueLen int
+	// Namespaces are those in which the keys are written, with the
+	// empty one being the default namespace.
+	Namespaces []string
+	// Timeout limits the time taken by the slave nodes to apply the
+	// changes of the master node.
+	Timeout time.Duration
+}
+
+// DefaultBinaryOptions are reasonable options that can be customized and
+// supplied to RunBinaryRoundTrip.
+var DefaultBinaryOptions = BinaryOptions{
+	ClusterOptions: DefaultClusterOptions,
+	Seed:           1,
+	NumKeys:        500,
+	MaxKeyLen:      64,
+	MaxValueLen:    4 << 10,
+	Namespaces:     []string{"", "bin"},
+	Timeout:        10 * time.Second,
+}
+
+// RunBinaryRoundTrip checks that arbitrary bytes survive the replication
+// of their changes. It writes random keys and values, including empty
+// ones and those full of 0x00 and 0xFF bytes, onto the master node of a
+// Cluster, deleting some of them, and then checks that every slave node
+// reads back exactly the same keys and values once it applies these
+// changes through GetChanges and SaveChanges. Keys of the default
+// namespace are generated such that they avoid the reserved prefixes,
+// which the master node must reject.
+func RunBinaryRoundTrip(t *testing.T, newStore StoreFactory, opts BinaryOptions) {
+	t.Helper()
+	c := NewCluster(t, newStore, opts.ClusterOptions)
+	rnd := rand.New(rand.NewSource(opts.Seed))
+
+	for _, key := range [][]byte{{0x00, 'k'}, append(append([]byte(nil), storage.ReservedKeyPrefix...), "Expiring::k"...)} {
+		if err := c.Master.Client.Put(key, []byte("v")); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+			t.Errorf("Expected the master node to reject the key %q of the default namespace. Error: %v", key, err)
+		}
+	}
+
+	expected := make(map[string]map[string][]byte)
+	for _, ns := range opts.Namespaces {
+		client, kvs := c.Master.Client.ForNamespace(ns), make(map[string][]byte)
+		for i := 0; i < opts.NumKeys; i++ {
+			key := randomBytes(rnd, rnd.Intn(opts.MaxKeyLen+1))
+			if _, err := storage.NamespacedKey(ns, key); err != nil {
+				continue
+			}
+			if _, present := kvs[string(key)]; present && rnd.Intn(4) == 0 {
+				if err := client.Delete(key); err != nil {
+					t.Fatalf("Unable to delete the key %q in namespace %q. Error: %v", key, ns, err)
+				}
+				delete(kvs, string(key))
+				continue
+			}
+			val := randomBytes(rnd, rnd.Intn(opts.MaxValueLen+1))
+			if err := client.Put(key, val); err != nil {
+				t.Fatalf("Unable to put the key %q in namespace %q. Error: %v", key, ns, err)
+			}
+			kvs[string(key)] = val
+		}
+		expected[ns] = kvs
+	}
+
+	chngNum := c.ChangeNumber(c.Master)
+	for _, node := range append([]*Node{c.Master}, c.Slaves...) {
+		if node != c.Master {
+			c.AwaitApplied(node, chngNum, opts.Timeout)
+		}
+		for ns, kvs := range expected {
+			checkBinaryKeys(t, node, ns, kvs)
+		}
+	}
+}
+
+// randomBytes returns the given number of random bytes, which are biased
+// towards 0x00 and 0xFF at times.
+func randomBytes(rnd *rand.Rand, n int) []byte {
+	bts := make([]byte, n)
+	switch rnd.Intn(3) {
+	case 0:
+		rnd.Read(bts)
+	default:
+		for i := range bts {
+			switch rnd.Intn(4) {
+			case 0:
+				bts[i] = byte(rnd.Intn(256))
+			case 1:
+				bts[i] = 0x00
+			default:
+				bts[i] = 0xFF
+			}
+		}
+	}
+	return bts
+}
+
+func checkBinaryKeys(t *testing.T, node *Node, ns string, kvs map[string][]byte) {
+	t.Helper()
+	keys := make([][]byte, 0, len(kvs))
+	for key := range kvs {
+		keys = append(keys, []byte(key))
+	}
+	results, err := node.Client.ForNamespace(ns).MultiGetDetailed(keys...)
+	if err != nil {
+		t.Fatalf("Unable to read the keys of namespace %q from %s. Error: %v", ns, node.Name, err)
+	}
+	for i, res := range results {
+		if expVal := kvs[string(keys[i])]; res.Err != nil || !res.Found || !bytes.Equal(res.Value, expVal) {
+			t.Errorf("Mismatch of key %q of namespace %q on %s. Expected value of %d bytes, Found: %t, Actual: %d bytes, Error: %v", keys[i], ns, node.Name, len(expVal), res.Found, len(res.Value), res.Err)
+		}
+	}
+}
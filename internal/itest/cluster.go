@@ -0,0 +1,393 @@
+// Package itest provides the harness for the integration tests of DKV,
+// which serves a master node along with its slave nodes in-process over
+// GRPC on the loopback interface, each backed by a store in a folder of
+// its own. Tests drive writes onto the master node through Writers and
+// act upon the Cluster much like operators do, such as by promoting a
+// slave node once the master node is killed.
+//
+// RunFailoverDrill drills such a failover from start to end, so that the
+// applications backing DKV by storage engines of their own can check that
+// their engines fail over without losing any of the acknowledged writes.
+package itest
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/server/maintenance"
+	"github.com/flipkart-incubator/dkv/internal/server/master"
+	"github.com/flipkart-incubator/dkv/internal/server/slave"
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// A Store is the storage of a node of the Cluster, which retains its
+// changes so that the slave nodes can replicate from it, both as the
+// master node and once promoted.
+type Store interface {
+	storage.KVStore
+	storage.ChangePropagator
+	storage.ChangeApplier
+}
+
+// A StoreFactory opens a fresh store for a node of the Cluster within
+// the given empty folder, which the Cluster closes once the test is done.
+type StoreFactory func(t *testing.T, folder string) Store
+
+// ClusterOptions describe the nodes of a Cluster.
+type ClusterOptions struct {
+	// NumSlaves is the number of slave nodes replicating from the
+	// master node.
+	NumSlaves int
+	// PollInterval is the interval at which the slave nodes poll for
+	// changes whenever they are not streaming them.
+	PollInterval time.Duration
+	// MaxNumChanges is the maximum number of changes replicated by the
+	// slave nodes in a batch.
+	MaxNumChanges uint32
+}
+
+// DefaultClusterOptions are reasonable options that can be customized
+// and supplied to NewCluster.
+var DefaultClusterOptions = ClusterOptions{
+	NumSlaves:     2,
+	PollInterval:  50 * time.Millisecond,
+	MaxNumChanges: 100,
+}
+
+// Size of the buffers of the clients of the nodes
+const clientBufSize = 1 << 20
+
+// A Node is a node of the Cluster, served at Addr.
+type Node struct {
+	Name string
+	Addr string
+	// Client is connected to the node for as long as it is served.
+	Client *ctl.DKVClient
+
+	store    Store
+	srvr     *grpc.Server
+	mstrSvc  master.DKVService
+	slaveSvc slave.DKVService
+	// Client the slave node replicates through
+	replCli *ctl.DKVClient
+}
+
+// A Cluster is a master node along with the slave nodes replicating
+// from it.
+type Cluster struct {
+	Master *Node
+	Slaves []*Node
+
+	t      *testing.T
+	opts   ClusterOptions
+	stores []Store
+}
+
+// NewCluster serves a master node along with the given number of slave
+// nodes, each backed by a store opened through the given factory. The
+// nodes are stopped and their stores closed once the test is done.
+func NewCluster(t *testing.T, newStore StoreFactory, opts ClusterOptions) *Cluster {
+	t.Helper()
+	c := &Cluster{t: t, opts: opts}
+	t.Cleanup(c.close)
+
+	c.Master = &Node{Name: "master", store: c.openStore(newStore)}
+	mode, err := maintenance.NewMode(c.tempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stores outlive the services serving them
+	c.Master.mstrSvc = master.NewStandaloneService(unclosable{c.Master.store}, c.Master.store, nil, master.WithMaintenanceMode(mode))
+	c.serve(c.Master, "127.0.0.1:0", func(srvr *grpc.Server) {
+		serverpb.RegisterDKVServer(srvr, c.Master.mstrSvc)
+		serverpb.RegisterDKVReplicationServer(srvr, c.Master.mstrSvc)
+		serverpb.RegisterDKVMaintenanceServer(srvr, maintenance.NewServer(mode, zap.NewNop()))
+	})
+
+	for i := 0; i < opts.NumSlaves; i++ {
+		slv := &Node{Name: fmt.Sprintf("slave%d", i), store: c.openStore(newStore)}
+		c.startSlave(slv, "127.0.0.1:0")
+		c.Slaves = append(c.Slaves, slv)
+	}
+	return c
+}
+
+// unclosable is a store that is closed only by the Cluster, so that its
+// nodes can be restarted as the same store.
+type unclosable struct {
+	Store
+}
+
+func (unclosable) Close() error { return nil }
+
+func (c *Cluster) tempDir() string {
+	dir, err := ioutil.TempDir("", "dkv_itest")
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func (c *Cluster) openStore(newStore StoreFactory) Store {
+	store := newStore(c.t, c.tempDir())
+	c.stores = append(c.stores, store)
+	return store
+}
+
+// serve serves the given node at the given address, with the services
+// registered by the given function.
+func (c *Cluster) serve(node *Node, addr string, register func(*grpc.Server)) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		c.t.Fatalf("Unable to serve %s. Error: %v", node.Name, err)
+	}
+	node.Addr = lis.Addr().String()
+	node.srvr = grpc.NewServer()
+	register(node.srvr)
+	go node.srvr.Serve(lis)
+	if node.Client, err = ctl.NewInSecureDKVClient(node.Addr, ctl.WithReadBufSize(clientBufSize), ctl.WithWriteBufSize(clientBufSize)); err != nil {
+		c.t.Fatalf("Unable to connect to %s. Error: %v", node.Name, err)
+	}
+}
+
+// startSlave serves the given slave node at the given address, which
+// replicates from the current master node.
+func (c *Cluster) startSlave(slv *Node, addr string) {
+	var err error
+	if slv.replCli, err = ctl.NewInSecureDKVClient(c.Master.Addr, ctl.WithReadBufSize(clientBufSize), ctl.WithWriteBufSize(clientBufSize)); err != nil {
+		c.t.Fatalf("Unable to connect to the master node. Error: %v", err)
+	}
+	slv.slaveSvc, err = slave.NewService(unclosable{slv.store}, slv.store, []*ctl.DKVClient{slv.replCli}, c.opts.PollInterval, c.opts.MaxNumChanges, 0, slave.WithChangeLog(slv.store))
+	if err != nil {
+		c.t.Fatalf("Unable to create %s. Error: %v", slv.Name, err)
+	}
+	c.serve(slv, addr, func(srvr *grpc.Server) {
+		serverpb.RegisterDKVServer(srvr, slv.slaveSvc)
+		serverpb.RegisterDKVReplicationStatusServer(srvr, slv.slaveSvc)
+		serverpb.RegisterDKVFailoverServer(srvr, slv.slaveSvc)
+		serverpb.RegisterDKVReplicationServer(srvr, slave.ReplicationServer(slv.slaveSvc))
+	})
+}
+
+// stop stops serving the given node abruptly.
+func (node *Node) stop() {
+	if node.srvr == nil {
+		return
+	}
+	node.srvr.Stop()
+	node.srvr = nil
+	node.Client.Close()
+	if node.mstrSvc != nil {
+		node.mstrSvc.Close()
+	}
+	if node.slaveSvc != nil {
+		node.slaveSvc.Close()
+		node.replCli.Close()
+	}
+}
+
+func (c *Cluster) close() {
+	c.Master.stop()
+	for _, slv := range c.Slaves {
+		slv.stop()
+	}
+	for _, store := range c.stores {
+		store.Close()
+	}
+}
+
+// ChangeNumber returns the latest change number committed by the given
+// node.
+func (c *Cluster) ChangeNumber(node *Node) uint64 {
+	c.t.Helper()
+	chngNum, err := node.store.GetLatestCommittedChangeNumber()
+	if err != nil {
+		c.t.Fatalf("Unable to get the change number of %s. Error: %v", node.Name, err)
+	}
+	return chngNum
+}
+
+// FenceMaster switches the master node into maintenance mode, so that
+// it rejects all the writes that follow.
+func (c *Cluster) FenceMaster() {
+	c.t.Helper()
+	if err := c.Master.Client.EnterMaintenanceMode("failover"); err != nil {
+		c.t.Fatalf("Unable to fence the master node. Error: %v", err)
+	}
+}
+
+// KillMaster stops serving the master node abruptly, leaving its slave
+// nodes unable to replicate from it.
+func (c *Cluster) KillMaster() {
+	c.Master.stop()
+}
+
+// AwaitApplied waits until the given slave node applies the changes upto
+// the given change number, as reported through its replication status.
+func (c *Cluster) AwaitApplied(slv *Node, chngNum uint64, timeout time.Duration) {
+	c.t.Helper()
+	var appldChngNum uint64
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(c.opts.PollInterval) {
+		if status, err := slv.Client.ReplicationStatus(); err == nil {
+			if appldChngNum = status.AppliedChangeNumber; appldChngNum >= chngNum {
+				return
+			}
+		}
+	}
+	c.t.Fatalf("Expected %s to apply the changes upto %d within %v. Applied: %d", slv.Name, chngNum, timeout, appldChngNum)
+}
+
+// Promote promotes the given slave node into the master node through
+// the PromoteToMaster method, returning the change number of the latest
+// change it applied. The node is no longer one of the slave nodes.
+func (c *Cluster) Promote(slv *Node) uint64 {
+	c.t.Helper()
+	appldChngNum, err := slv.Client.PromoteToMaster()
+	if err != nil {
+		c.t.Fatalf("Unable to promote %s. Error: %v", slv.Name, err)
+	}
+	for i, node := range c.Slaves {
+		if node == slv {
+			c.Slaves = append(c.Slaves[:i:i], c.Slaves[i+1:]...)
+		}
+	}
+	c.Master = slv
+	return appldChngNum
+}
+
+// Repoint restarts the given slave node at its address, such that it
+// resumes replicating from the current master node past the changes it
+// already applied, as an operator does by pointing the node to the new
+// master node.
+func (c *Cluster) Repoint(slv *Node) {
+	c.t.Helper()
+	addr := slv.Addr
+	slv.stop()
+	c.startSlave(slv, addr)
+}
+
+// AwaitConverged waits until every slave node applies all the changes
+// committed by the master node, and then checks that their keyspaces
+// are identical to that of the master node. Writes must be paused for
+// the keyspaces to converge.
+func (c *Cluster) AwaitConverged(timeout time.Duration) {
+	c.t.Helper()
+	chngNum := c.ChangeNumber(c.Master)
+	for _, slv := range c.Slaves {
+		c.AwaitApplied(slv, chngNum, timeout)
+		diff, err := ctl.CompareReplicas(context.Background(), c.Master.Client, slv.Client, ctl.DefaultCompareOptions)
+		if err != nil {
+			c.t.Fatalf("Unable to compare %s with the master node. Error: %v", slv.Name, err)
+		}
+		if !diff.Consistent() {
+			c.t.Errorf("Expected %s to converge with the master node. Mismatches: %d, Ranges: %d", slv.Name, len(diff.Mismatches), len(diff.Ranges))
+		}
+	}
+}
+
+// CheckWrites checks that every node holds all the writes acknowledged
+// to the given writers.
+func (c *Cluster) CheckWrites(writers ...*Writer) {
+	c.t.Helper()
+	var keys, vals [][]byte
+	for _, w := range writers {
+		w.mu.Lock()
+		for key, val := range w.acked {
+			keys, vals = append(keys, []byte(key)), append(vals, val)
+		}
+		w.mu.Unlock()
+	}
+	for _, node := range append([]*Node{c.Master}, c.Slaves...) {
+		results, err := node.Client.MultiGetDetailed(keys...)
+		if err != nil {
+			c.t.Fatalf("Unable to read the writes from %s. Error: %v", node.Name, err)
+		}
+		lost := 0
+		for i, res := range results {
+			if res.Err != nil || !res.Found || string(res.Value) != string(vals[i]) {
+				lost++
+			}
+		}
+		if lost > 0 {
+			c.t.Errorf("Expected %s to hold every acknowledged write. Lost: %d of %d", node.Name, lost, len(keys))
+		}
+	}
+}
+
+// A Writer puts keys onto the master node of a Cluster from concurrent
+// goroutines, each of which stops upon its first failed write. It tracks
+// the writes acknowledged by the master node.
+type Writer struct {
+	stop chan struct{}
+	wg   sync.WaitGroup
+
+	mu    sync.Mutex
+	acked map[string][]byte
+	errs  []error
+}
+
+// StartWriters begins writing keys with the given prefix onto the
+// current master node from the given number of goroutines, until the
+// returned Writer is stopped.
+func (c *Cluster) StartWriters(keyPrefix string, numWriters int) *Writer {
+	w := &Writer{stop: make(chan struct{}), acked: make(map[string][]byte)}
+	client := c.Master.Client
+	for i := 0; i < numWriters; i++ {
+		w.wg.Add(1)
+		go func(i int) {
+			defer w.wg.Done()
+			for seq := 0; ; seq++ {
+				select {
+				case <-w.stop:
+					return
+				default:
+				}
+				key, val := fmt.Sprintf("%s_%d_%d", keyPrefix, i, seq), []byte(fmt.Sprintf("val_%d_%d", i, seq))
+				if err := client.Put([]byte(key), val); err != nil {
+					w.mu.Lock()
+					w.errs = append(w.errs, err)
+					w.mu.Unlock()
+					return
+				}
+				w.mu.Lock()
+				w.acked[key] = val
+				w.mu.Unlock()
+			}
+		}(i)
+	}
+	return w
+}
+
+// Wait waits for every goroutine of the Writer to stop upon a failed
+// write, returning those failures.
+func (w *Writer) Wait() []error {
+	w.wg.Wait()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.errs
+}
+
+// Stop stops the Writer, returning the number of writes acknowledged.
+func (w *Writer) Stop() int {
+	close(w.stop)
+	w.wg.Wait()
+	return w.NumAcked()
+}
+
+// NumAcked returns the number of writes acknowledged so far.
+func (w *Writer) NumAcked() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.acked)
+}
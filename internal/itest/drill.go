@@ -0,0 +1,92 @@
+package itest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+)
+
+// DrillOptions describe how RunFailoverDrill drives the Cluster.
+type DrillOptions struct {
+	ClusterOptions
+	// NumWriters is the number of goroutines writing concurrently onto
+	// the master node, both before and after the failover.
+	NumWriters int
+	// WriteDuration is the duration for which the writes are driven
+	// onto the master node, both before and after the failover.
+	WriteDuration time.Duration
+	// Timeout bounds the wait for the slave nodes to catch up with
+	// their master node.
+	Timeout time.Duration
+}
+
+// DefaultDrillOptions are reasonable options that can be customized
+// and supplied to RunFailoverDrill.
+var DefaultDrillOptions = DrillOptions{
+	ClusterOptions: DefaultClusterOptions,
+	NumWriters:     4,
+	WriteDuration:  500 * time.Millisecond,
+	Timeout:        10 * time.Second,
+}
+
+// RunFailoverDrill drills the failover of the master node of a Cluster,
+// whose nodes are backed by the stores opened through the given factory,
+// onto the first of its slave nodes, which is kept as a warm standby:
+//
+//  1. Writes are driven onto the master node.
+//  2. The master node is fenced through its maintenance mode, failing
+//     the writes that follow, and killed once the standby applies all
+//     the changes it committed.
+//  3. The standby is promoted through the PromoteToMaster method, upon
+//     which the other slave nodes are repointed to it.
+//  4. Writes are driven onto the promoted node.
+//
+// The drill fails unless every node holds all the writes acknowledged,
+// both before and after the failover, and the keyspaces of the slave
+// nodes eventually converge with that of the promoted node. Since the
+// replication is asynchronous, the fence is what spares the writes the
+// standby is yet to apply when the master node is killed.
+func RunFailoverDrill(t *testing.T, newStore StoreFactory, opts DrillOptions) {
+	t.Helper()
+	if opts.NumSlaves < 1 {
+		t.Fatalf("Failover requires atleast one slave node. Given: %d", opts.NumSlaves)
+	}
+	c := NewCluster(t, newStore, opts.ClusterOptions)
+	standby := c.Slaves[0]
+
+	before := c.StartWriters("before", opts.NumWriters)
+	time.Sleep(opts.WriteDuration)
+	c.FenceMaster()
+	for _, err := range before.Wait() {
+		if !errors.Is(err, dkverrors.ErrMaintenance) {
+			t.Fatalf("Expected the writes to fail only once the master node is fenced. Error: %v", err)
+		}
+	}
+	if before.NumAcked() == 0 {
+		t.Fatal("Expected the master node to acknowledge writes before the failover")
+	}
+	mstrChngNum := c.ChangeNumber(c.Master)
+	c.AwaitApplied(standby, mstrChngNum, opts.Timeout)
+	c.KillMaster()
+
+	if appldChngNum := c.Promote(standby); appldChngNum != mstrChngNum {
+		t.Errorf("Expected the standby to be promoted with every change of the master node. Expected: %d, Actual: %d", mstrChngNum, appldChngNum)
+	}
+	for _, slv := range c.Slaves {
+		c.Repoint(slv)
+	}
+
+	after := c.StartWriters("after", opts.NumWriters)
+	time.Sleep(opts.WriteDuration)
+	if after.Stop() == 0 {
+		t.Error("Expected the promoted node to acknowledge writes after the failover")
+	}
+	for _, err := range after.Wait() {
+		t.Errorf("Expected the promoted node to accept writes. Error: %v", err)
+	}
+
+	c.AwaitConverged(opts.Timeout)
+	c.CheckWrites(before, after)
+}
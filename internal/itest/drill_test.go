@@ -0,0 +1,25 @@
+package itest
+
+import (
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+)
+
+func openMemoryStore(t *testing.T, folder string) Store {
+	return memory.OpenDB(0)
+}
+
+func TestFailoverDrill(t *testing.T) {
+	RunFailoverDrill(t, openMemoryStore, DefaultDrillOptions)
+}
+
+func TestFailoverDrillWithoutOtherSlaves(t *testing.T) {
+	opts := DefaultDrillOptions
+	opts.NumSlaves = 1
+	RunFailoverDrill(t, openMemoryStore, opts)
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	RunBinaryRoundTrip(t, openMemoryStore, DefaultBinaryOptions)
+}
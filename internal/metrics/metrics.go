@@ -0,0 +1,155 @@
+// Package metrics exposes the operational metrics of a DKV node in the
+// Prometheus format. GRPC methods are instrumented through interceptors
+// so that every method is covered without any changes to it, while the
+// storage is instrumented by wrapping it. Replication progress of slave
+// nodes is reported by the slave service itself.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+)
+
+const namespace = "dkv"
+
+var (
+	requests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "requests_total",
+		Help:      "Number of GRPC requests received.",
+	}, []string{"service", "method"})
+	requestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "errors_total",
+		Help:      "Number of GRPC requests that failed, either with an error or with an error status.",
+	}, []string{"service", "method"})
+	requestLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "duration_seconds",
+		Help:      "Latency of GRPC requests in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method"})
+
+	keysWritten = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "keys_written_total",
+		Help:      "Number of keys written onto the storage, including deletions.",
+	})
+	bytesWritten = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "bytes_written_total",
+		Help:      "Number of bytes of keys and values written onto the storage.",
+	})
+	writeBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "write_batch_size",
+		Help:      "Number of keys written onto the storage in a single batch.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+	})
+
+	replLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "replication",
+		Name:      "lag",
+		Help:      "Number of changes by which the slave node lags behind its master node.",
+	})
+	replFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "replication",
+		Name:      "failures_total",
+		Help:      "Number of failed attempts to replicate changes from the master node.",
+	})
+	replChanges = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "replication",
+		Name:      "changes_applied_total",
+		Help:      "Number of changes replicated from the master node and applied onto the storage.",
+	})
+)
+
+// Handler returns the HTTP handler that serves all the metrics
+// in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// UnaryServerInterceptor records the count, errors and latency of
+// every unary GRPC method. Besides the GRPC errors, responses with
+// a non-zero status code are counted as errors as well.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		observeRequest(info.FullMethod, start, err != nil || hasErrorStatus(res))
+		return res, err
+	}
+}
+
+// StreamServerInterceptor records the count, errors and latency of
+// every streaming GRPC method, where the latency covers the whole
+// lifetime of the stream.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observeRequest(info.FullMethod, start, err != nil)
+		return err
+	}
+}
+
+func observeRequest(fullMethod string, start time.Time, failed bool) {
+	service, method := splitMethodName(fullMethod)
+	requests.WithLabelValues(service, method).Inc()
+	if failed {
+		requestErrors.WithLabelValues(service, method).Inc()
+	}
+	requestLatency.WithLabelValues(service, method).Observe(time.Since(start).Seconds())
+}
+
+// splitMethodName splits the full GRPC method name, which is of the
+// form /package.Service/Method, into its service and method names.
+func splitMethodName(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
+
+func hasErrorStatus(res interface{}) bool {
+	switch res := res.(type) {
+	case *serverpb.Status:
+		return res.GetCode() != 0
+	case interface{ GetStatus() *serverpb.Status }:
+		return res.GetStatus().GetCode() != 0
+	default:
+		return false
+	}
+}
+
+// ObserveReplication records the replication lag of the slave node,
+// along with the number of changes it applied from the latest batch.
+func ObserveReplication(lag uint64, numChngsApplied int) {
+	replLag.Set(float64(lag))
+	replChanges.Add(float64(numChngsApplied))
+}
+
+// ObserveReplicationFailure records a failed attempt of the slave
+// node to replicate changes from its master node.
+func ObserveReplicationFailure() {
+	replFailures.Inc()
+}
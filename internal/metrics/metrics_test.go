@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	intercept := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/dkv.serverpb.DKV/Put"}
+	responses := []struct {
+		res interface{}
+		err error
+	}{
+		{&serverpb.PutResponse{Status: &serverpb.Status{}}, nil},
+		{&serverpb.PutResponse{Status: &serverpb.Status{Code: -1, Message: "failed"}}, nil},
+		{&serverpb.Status{Code: -1, Message: "failed"}, nil},
+		{nil, errors.New("failed")},
+	}
+	for _, resp := range responses {
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) { return resp.res, resp.err }
+		if res, err := intercept(context.Background(), nil, info, handler); res != resp.res || err != resp.err {
+			t.Errorf("Expected the response to be passed through. Actual: %v, Error: %v", res, err)
+		}
+	}
+	if numReqs := testutil.ToFloat64(requests.WithLabelValues("dkv.serverpb.DKV", "Put")); numReqs != 4 {
+		t.Errorf("Request count mismatch. Expected: 4, Actual: %v", numReqs)
+	}
+	if numErrs := testutil.ToFloat64(requestErrors.WithLabelValues("dkv.serverpb.DKV", "Put")); numErrs != 3 {
+		t.Errorf("Error count mismatch. Expected: 3, Actual: %v", numErrs)
+	}
+}
+
+func TestKVStoreRecordsWrites(t *testing.T) {
+	kvs := NewKVStore(memory.OpenDB(0))
+	defer kvs.Close()
+
+	prevKeys, prevBytes := testutil.ToFloat64(keysWritten), testutil.ToFloat64(bytesWritten)
+	kvs.Put([]byte("K1"), []byte("V1"))
+	kvs.MultiPut(&serverpb.PutRequest{Key: []byte("K2"), Value: []byte("V2")}, &serverpb.PutRequest{Key: []byte("K3"), Value: []byte("V3")})
+	kvs.CompareAndSet([]byte("K1"), []byte("Mismatch"), []byte("V4"))
+	kvs.Delete([]byte("K1"))
+	if numKeys := testutil.ToFloat64(keysWritten) - prevKeys; numKeys != 4 {
+		t.Errorf("Keys written mismatch. Expected: 4, Actual: %v", numKeys)
+	}
+	if numBytes := testutil.ToFloat64(bytesWritten) - prevBytes; numBytes != 14 {
+		t.Errorf("Bytes written mismatch. Expected: 14, Actual: %v", numBytes)
+	}
+}
+
+func TestHandlerServesMetrics(t *testing.T) {
+	ObserveReplication(5, 10)
+	ObserveReplicationFailure()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	for _, name := range []string{"dkv_replication_lag 5", "dkv_replication_failures_total", "dkv_replication_changes_applied_total"} {
+		if !strings.Contains(rec.Body.String(), name) {
+			t.Errorf("Expected metric: %s to be served", name)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// Size of the big-endian encoded values written by Increment.
+const counterSize = 8
+
+type kvStore struct {
+	storage.KVStore
+}
+
+// NewKVStore wraps the given store so that the keys and bytes written
+// onto it are recorded, along with the sizes of the batches written.
+// Only the successful writes are recorded.
+func NewKVStore(kvs storage.KVStore) storage.KVStore {
+	return &kvStore{kvs}
+}
+
+func (ks *kvStore) GetWithContext(ctx context.Context, keys ...[]byte) ([][]byte, []bool, error) {
+	return storage.GetWithContext(ctx, ks.KVStore, keys...)
+}
+
+func (ks *kvStore) IterateKeys(keyPrefix, startKey []byte) storage.Iterator {
+	return storage.IterateKeys(ks.KVStore, keyPrefix, startKey)
+}
+
+func (ks *kvStore) Sync() error {
+	return storage.Sync(ks.KVStore)
+}
+
+func (ks *kvStore) GetStorageStats() (*storage.Stats, error) {
+	return storage.GetStorageStats(ks.KVStore)
+}
+
+func (ks *kvStore) MultiPutWithSync(sync bool, puts ...*serverpb.PutRequest) error {
+	err := storage.MultiPutWithSync(ks.KVStore, sync, puts...)
+	if err == nil {
+		observePuts(puts)
+	}
+	return err
+}
+
+func (ks *kvStore) BulkLoad(entries ...*serverpb.PutRequest) error {
+	err := storage.BulkLoad(ks.KVStore, entries...)
+	if err == nil {
+		observePuts(entries)
+	}
+	return err
+}
+
+func (ks *kvStore) FinishBulkLoad() (uint64, error) {
+	return storage.FinishBulkLoad(ks.KVStore)
+}
+
+func (ks *kvStore) Put(key []byte, value []byte) error {
+	err := ks.KVStore.Put(key, value)
+	if err == nil {
+		observeWrite(1, len(key)+len(value))
+	}
+	return err
+}
+
+func (ks *kvStore) MultiPut(puts ...*serverpb.PutRequest) error {
+	err := ks.KVStore.MultiPut(puts...)
+	if err == nil {
+		observePuts(puts)
+	}
+	return err
+}
+
+func observePuts(puts []*serverpb.PutRequest) {
+	numBytes := 0
+	for _, put := range puts {
+		numBytes += len(put.Key) + len(put.Value)
+	}
+	observeWrite(len(puts), numBytes)
+}
+
+func (ks *kvStore) CompareAndSet(key, expectedValue, newValue []byte) (bool, error) {
+	updated, err := ks.KVStore.CompareAndSet(key, expectedValue, newValue)
+	if updated {
+		observeWrite(1, len(key)+len(newValue))
+	}
+	return updated, err
+}
+
+func (ks *kvStore) Increment(key []byte, delta int64) (int64, error) {
+	value, err := ks.KVStore.Increment(key, delta)
+	if err == nil {
+		observeWrite(1, len(key)+counterSize)
+	}
+	return value, err
+}
+
+func (ks *kvStore) Txn(txnReq *serverpb.TxnRequest) (bool, error) {
+	succeeded, err := ks.KVStore.Txn(txnReq)
+	if muts := storage.TxnMutations(txnReq, succeeded); err == nil && len(muts) > 0 {
+		numBytes := 0
+		for _, mut := range muts {
+			numBytes += len(mut.Key) + len(mut.Value)
+		}
+		observeWrite(len(muts), numBytes)
+	}
+	return succeeded, err
+}
+
+func (ks *kvStore) Delete(keys ...[]byte) error {
+	err := ks.KVStore.Delete(keys...)
+	if err == nil {
+		numBytes := 0
+		for _, key := range keys {
+			numBytes += len(key)
+		}
+		observeWrite(len(keys), numBytes)
+	}
+	return err
+}
+
+type changeApplier struct {
+	storage.ChangeApplier
+}
+
+// NewChangeApplier wraps the given change applier so that the keys
+// and bytes written by the applied changes are recorded, along with
+// the sizes of the batches written.
+func NewChangeApplier(ca storage.ChangeApplier) storage.ChangeApplier {
+	return &changeApplier{ca}
+}
+
+func (ca *changeApplier) SaveChanges(changes []*serverpb.ChangeRecord) (uint64, error) {
+	appldChngNum, err := ca.ChangeApplier.SaveChanges(changes)
+	observeChanges(changes, appldChngNum)
+	return appldChngNum, err
+}
+
+func (ca *changeApplier) SaveChangeBatch(changes []*serverpb.ChangeRecord) (uint64, error) {
+	appldChngNum, err := ca.ChangeApplier.SaveChangeBatch(changes)
+	observeChanges(changes, appldChngNum)
+	return appldChngNum, err
+}
+
+func (ca *changeApplier) SaveChangeBatchWithState(changes []*serverpb.ChangeRecord, state []byte) (uint64, error) {
+	appldChngNum, err := storage.SaveChangeBatchWithState(ca.ChangeApplier, changes, state)
+	observeChanges(changes, appldChngNum)
+	return appldChngNum, err
+}
+
+func (ca *changeApplier) LoadReplicationState() ([]byte, error) {
+	return storage.LoadReplicationState(ca.ChangeApplier)
+}
+
+// observeChanges records the writes of all the given changes
+// that are applied, i.e., upto the given change number.
+func observeChanges(changes []*serverpb.ChangeRecord, appldChngNum uint64) {
+	numKeys, numBytes := 0, 0
+	for _, chng := range changes {
+		if chng.ChangeNumber > appldChngNum {
+			break
+		}
+		for _, trxn := range chng.Trxns {
+			numKeys++
+			numBytes += len(trxn.Key) + len(trxn.Value)
+		}
+	}
+	if numKeys > 0 {
+		observeWrite(numKeys, numBytes)
+	}
+}
+
+func observeWrite(numKeys, numBytes int) {
+	keysWritten.Add(float64(numKeys))
+	bytesWritten.Add(float64(numBytes))
+	writeBatchSize.Observe(float64(numKeys))
+}
@@ -0,0 +1,471 @@
+// Package backup transfers the backups of DKV nodes to and from object
+// storage, so that they outlive the disks of these nodes.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"go.uber.org/zap"
+)
+
+// Schemes of the backup locations supported by a Transfer.
+const (
+	FileScheme = "file"
+	S3Scheme   = "s3"
+	GCSScheme  = "gcs"
+)
+
+// DefaultGCSEndpoint is the endpoint of the S3 compatible XML API of
+// Google Cloud Storage, which is used for all the gcs:// locations.
+const DefaultGCSEndpoint = "https://storage.googleapis.com"
+
+// Location identifies where a backup is kept, either as a Path on the
+// local filesystem or as the objects under a Prefix within a Bucket.
+type Location struct {
+	Scheme string
+	Path   string
+	Bucket string
+	Prefix string
+}
+
+// ParseLocation parses the given backup location, which is either a
+// path on the local filesystem, optionally as a file:// URI, or an
+// object storage URI such as s3://bucket/prefix or gcs://bucket/prefix.
+func ParseLocation(loc string) (*Location, error) {
+	if !strings.Contains(loc, "://") {
+		if loc == "" {
+			return nil, errors.New("backup location must not be empty")
+		}
+		return &Location{Scheme: FileScheme, Path: loc}, nil
+	}
+	u, err := url.Parse(loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup location: %v", err)
+	}
+	switch u.Scheme {
+	case FileScheme:
+		if u.Host != "" || u.Path == "" {
+			return nil, fmt.Errorf("invalid backup location: %s, file locations must be of the form file:///<path>", loc)
+		}
+		return &Location{Scheme: FileScheme, Path: u.Path}, nil
+	case S3Scheme, GCSScheme:
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid backup location: %s, object storage locations must be of the form %s://<bucket>/<prefix>", loc, u.Scheme)
+		}
+		return &Location{Scheme: u.Scheme, Bucket: u.Host, Prefix: strings.Trim(u.Path, "/")}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme: %s of backup location: %s, allowed schemes are file|s3|gcs", u.Scheme, loc)
+	}
+}
+
+// IsLocal checks whether the location is on the local filesystem.
+func (loc *Location) IsLocal() bool {
+	return loc.Scheme == FileScheme
+}
+
+func (loc *Location) String() string {
+	if loc.IsLocal() {
+		return loc.Path
+	}
+	return fmt.Sprintf("%s://%s/%s", loc.Scheme, loc.Bucket, loc.Prefix)
+}
+
+// JoinLocation returns the location of the given name within the given
+// backup location, such as a backup within a folder of backups.
+func JoinLocation(loc, name string) (string, error) {
+	bckpLoc, err := ParseLocation(loc)
+	if err != nil {
+		return "", err
+	}
+	if bckpLoc.IsLocal() {
+		bckpLoc.Path = filepath.Join(bckpLoc.Path, name)
+	} else {
+		bckpLoc.Prefix = bckpLoc.key(name)
+	}
+	return bckpLoc.String(), nil
+}
+
+func (loc *Location) key(name string) string {
+	if loc.Prefix == "" {
+		return name
+	}
+	return loc.Prefix + "/" + name
+}
+
+// StoreConfig holds the connection details of an object store. Unless
+// given explicitly, S3 credentials are resolved from the environment,
+// the shared credentials file or the instance role of the node, as done
+// by the AWS CLI.
+type StoreConfig struct {
+	// Endpoint overrides the default endpoint, e.g. for MinIO.
+	Endpoint string
+	// Region of the buckets, which defaults to us-east-1.
+	Region string
+	// ForcePathStyle addresses the buckets as a part of the path
+	// rather than the host name, as required by most S3 compatible stores.
+	ForcePathStyle bool
+	// AccessKeyID and SecretAccessKey are the static credentials, if any.
+	AccessKeyID, SecretAccessKey string
+}
+
+// Config of a Transfer.
+type Config struct {
+	// StagingDir is the folder on the local filesystem where backups
+	// are staged while being transferred, which defaults to the
+	// temporary folder of the OS.
+	StagingDir string
+	// PartSize is the size (in bytes) of every part of the multipart
+	// uploads, which defaults to and must be atleast 5 MB.
+	PartSize int64
+	S3       StoreConfig
+	// GCS stores can only be accessed through their
+	// HMAC keys given as the static credentials.
+	GCS StoreConfig
+}
+
+// A Transfer runs backups and restores of a DKV node against locations
+// on either the local filesystem or object storage. Backups headed for
+// object storage are first staged on the local filesystem and then
+// uploaded, while restores are downloaded before being staged.
+type Transfer struct {
+	stagingDir string
+	partSize   int64
+	clients    map[string]*s3.S3
+	lgr        *zap.Logger
+}
+
+// LocalOnly is a Transfer that only supports locations on the local filesystem.
+var LocalOnly = &Transfer{lgr: zap.NewNop()}
+
+// NewTransfer creates a Transfer with the given configuration. The
+// gcs:// locations are only supported if the GCS credentials are given.
+func NewTransfer(cfg Config, lgr *zap.Logger) (*Transfer, error) {
+	if cfg.PartSize == 0 {
+		cfg.PartSize = s3manager.DefaultUploadPartSize
+	}
+	if cfg.PartSize < s3manager.MinUploadPartSize {
+		return nil, fmt.Errorf("part size: %d must be atleast %d bytes", cfg.PartSize, s3manager.MinUploadPartSize)
+	}
+	if cfg.StagingDir == "" {
+		cfg.StagingDir = os.TempDir()
+	}
+	if cfg.GCS.Endpoint == "" {
+		cfg.GCS.Endpoint = DefaultGCSEndpoint
+	}
+	// Keeps the credentials of S3 from ever being sent to GCS
+	stores := map[string]StoreConfig{S3Scheme: cfg.S3}
+	if cfg.GCS.AccessKeyID != "" {
+		stores[GCSScheme] = cfg.GCS
+	}
+	clients := make(map[string]*s3.S3, len(stores))
+	for scheme, store := range stores {
+		client, err := newS3Client(store)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create the client for %s: %v", scheme, err)
+		}
+		clients[scheme] = client
+	}
+	return &Transfer{stagingDir: cfg.StagingDir, partSize: cfg.PartSize, clients: clients, lgr: lgr}, nil
+}
+
+func newS3Client(store StoreConfig) (*s3.S3, error) {
+	awsCfg := aws.NewConfig().WithS3ForcePathStyle(store.ForcePathStyle)
+	awsCfg = awsCfg.WithRegion("us-east-1")
+	if store.Region != "" {
+		awsCfg = awsCfg.WithRegion(store.Region)
+	}
+	if store.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(store.Endpoint)
+	}
+	if store.AccessKeyID != "" {
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(store.AccessKeyID, store.SecretAccessKey, ""))
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+	return s3.New(sess), nil
+}
+
+// Name of the object describing a backup on object storage, which is
+// uploaded after all the other objects of the backup. Hence backups
+// without manifests are incomplete and cannot be restored.
+const manifestName = "MANIFEST"
+
+// Name of the staged backup, under which its objects are uploaded.
+const artifactName = "backup"
+
+// manifest lists the files of a backup along with their checksums.
+type manifest struct {
+	// Dir is set for backups that are folders rather than a single file
+	Dir   bool           `json:"dir"`
+	Files []manifestFile `json:"files"`
+}
+
+type manifestFile struct {
+	// Path of the file relative to the backup folder, if any
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+const sha256MetadataKey = "Sha256"
+
+// Backup backs up onto the given location by invoking the given backup
+// function, which must write the backup onto the local path it is given.
+// Backups onto object storage fail if the location already holds one.
+func (t *Transfer) Backup(ctx context.Context, loc string, backupTo func(path string) error) error {
+	bckpLoc, err := ParseLocation(loc)
+	if err != nil {
+		return err
+	}
+	if bckpLoc.IsLocal() {
+		return backupTo(bckpLoc.Path)
+	}
+	client, err := t.client(bckpLoc)
+	if err != nil {
+		return err
+	}
+	if _, err = client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{Bucket: aws.String(bckpLoc.Bucket), Key: aws.String(bckpLoc.key(manifestName))}); err == nil {
+		return fmt.Errorf("backup already exists at %s", bckpLoc)
+	} else if !isNotFound(err) {
+		return err
+	}
+
+	stgDir, err := ioutil.TempDir(t.stagingDir, "dkv-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stgDir)
+	stgPath := filepath.Join(stgDir, artifactName)
+	if err = backupTo(stgPath); err != nil {
+		return err
+	}
+	info, err := os.Stat(stgPath)
+	if err != nil {
+		return err
+	}
+
+	mnfst := &manifest{Dir: info.IsDir()}
+	uploader := s3manager.NewUploaderWithClient(client, func(u *s3manager.Uploader) { u.PartSize = t.partSize })
+	err = filepath.Walk(stgPath, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, _ := filepath.Rel(stgPath, file)
+		if !mnfst.Dir {
+			relPath = ""
+		}
+		mnfstFile, err := t.upload(ctx, uploader, bckpLoc, file, filepath.ToSlash(relPath))
+		if err != nil {
+			return fmt.Errorf("unable to upload %s: %v", file, err)
+		}
+		mnfst.Files = append(mnfst.Files, *mnfstFile)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	mnfstData, err := json.Marshal(mnfst)
+	if err != nil {
+		return err
+	}
+	_, err = uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(bckpLoc.Bucket),
+		Key:    aws.String(bckpLoc.key(manifestName)),
+		Body:   bytes.NewReader(mnfstData),
+	})
+	if err != nil {
+		return err
+	}
+	t.lgr.Info("Uploaded backup", zap.Stringer("location", bckpLoc), zap.Int("numFiles", len(mnfst.Files)))
+	return nil
+}
+
+func (t *Transfer) upload(ctx context.Context, uploader *s3manager.Uploader, bckpLoc *Location, file, relPath string) (*manifestFile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	_, err = uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:   aws.String(bckpLoc.Bucket),
+		Key:      aws.String(bckpLoc.key(path.Join(artifactName, relPath))),
+		Body:     f,
+		Metadata: map[string]*string{sha256MetadataKey: aws.String(sum)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.lgr.Debug("Uploaded backup file", zap.Stringer("location", bckpLoc), zap.String("path", relPath), zap.Int64("size", size))
+	return &manifestFile{Path: relPath, Size: size, SHA256: sum}, nil
+}
+
+// Restore restores from the given location by invoking the given restore
+// function with the local path of the backup. Backups on object storage
+// are downloaded and verified against their checksums before the restore
+// function is invoked.
+func (t *Transfer) Restore(ctx context.Context, loc string, restoreFrom func(path string) error) error {
+	rstrLoc, err := ParseLocation(loc)
+	if err != nil {
+		return err
+	}
+	if rstrLoc.IsLocal() {
+		return restoreFrom(rstrLoc.Path)
+	}
+	client, err := t.client(rstrLoc)
+	if err != nil {
+		return err
+	}
+	mnfstObj, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{Bucket: aws.String(rstrLoc.Bucket), Key: aws.String(rstrLoc.key(manifestName))})
+	if err != nil {
+		if isNotFound(err) {
+			return fmt.Errorf("no complete backup exists at %s", rstrLoc)
+		}
+		return err
+	}
+	mnfst := new(manifest)
+	err = json.NewDecoder(mnfstObj.Body).Decode(mnfst)
+	mnfstObj.Body.Close()
+	if err != nil {
+		return fmt.Errorf("invalid manifest of backup at %s: %v", rstrLoc, err)
+	}
+
+	stgDir, err := ioutil.TempDir(t.stagingDir, "dkv-restore-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stgDir)
+	stgPath := filepath.Join(stgDir, artifactName)
+	downloader := s3manager.NewDownloaderWithClient(client, func(d *s3manager.Downloader) { d.PartSize = t.partSize })
+	for _, mnfstFile := range mnfst.Files {
+		if err = t.download(ctx, downloader, rstrLoc, stgPath, mnfstFile); err != nil {
+			return fmt.Errorf("unable to download %s: %v", mnfstFile.Path, err)
+		}
+	}
+	if mnfst.Dir {
+		// Backups of empty folders carry no files
+		if err = os.MkdirAll(stgPath, 0755); err != nil {
+			return err
+		}
+	}
+	t.lgr.Info("Downloaded backup", zap.Stringer("location", rstrLoc), zap.Int("numFiles", len(mnfst.Files)))
+	return restoreFrom(stgPath)
+}
+
+func (t *Transfer) download(ctx context.Context, downloader *s3manager.Downloader, rstrLoc *Location, stgPath string, mnfstFile manifestFile) error {
+	file := filepath.Join(stgPath, filepath.FromSlash(mnfstFile.Path))
+	if file != stgPath && !strings.HasPrefix(file, stgPath+string(filepath.Separator)) {
+		return errors.New("path lies outside the backup")
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = downloader.DownloadWithContext(ctx, f, &s3.GetObjectInput{
+		Bucket: aws.String(rstrLoc.Bucket),
+		Key:    aws.String(rstrLoc.key(path.Join(artifactName, mnfstFile.Path))),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); size != mnfstFile.Size || sum != mnfstFile.SHA256 {
+		return fmt.Errorf("checksum mismatch. Expected %d bytes with SHA-256: %s, Actual %d bytes with SHA-256: %s", mnfstFile.Size, mnfstFile.SHA256, size, sum)
+	}
+	return f.Sync()
+}
+
+// Remove removes the backup at the given location, including the objects
+// of incomplete backups onto object storage. Removing a missing backup
+// succeeds.
+func (t *Transfer) Remove(ctx context.Context, loc string) error {
+	rmLoc, err := ParseLocation(loc)
+	if err != nil {
+		return err
+	}
+	if rmLoc.IsLocal() {
+		return os.RemoveAll(rmLoc.Path)
+	}
+	if rmLoc.Prefix == "" {
+		return fmt.Errorf("backup at %s spans the entire bucket, hence it is not removed", rmLoc)
+	}
+	client, err := t.client(rmLoc)
+	if err != nil {
+		return err
+	}
+	var delErr error
+	err = client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{Bucket: aws.String(rmLoc.Bucket), Prefix: aws.String(rmLoc.key(""))}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		if len(page.Contents) == 0 {
+			return true
+		}
+		objs := make([]*s3.ObjectIdentifier, len(page.Contents))
+		for i, obj := range page.Contents {
+			objs[i] = &s3.ObjectIdentifier{Key: obj.Key}
+		}
+		_, delErr = client.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{Bucket: aws.String(rmLoc.Bucket), Delete: &s3.Delete{Objects: objs, Quiet: aws.Bool(true)}})
+		return delErr == nil
+	})
+	if err == nil {
+		err = delErr
+	}
+	if err != nil {
+		return err
+	}
+	t.lgr.Info("Removed backup", zap.Stringer("location", rmLoc))
+	return nil
+}
+
+func (t *Transfer) client(loc *Location) (*s3.S3, error) {
+	client, present := t.clients[loc.Scheme]
+	if !present {
+		return nil, fmt.Errorf("object storage for %s:// locations is not configured on this node", loc.Scheme)
+	}
+	return client, nil
+}
+
+func isNotFound(err error) bool {
+	if aerr, ok := err.(awserr.RequestFailure); ok {
+		return aerr.StatusCode() == 404
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+)
+
+func TestParseLocation(t *testing.T) {
+	checks := []struct {
+		loc    string
+		expLoc Location
+	}{
+		{"/tmp/bckp", Location{Scheme: FileScheme, Path: "/tmp/bckp"}},
+		{"file:///tmp/bckp", Location{Scheme: FileScheme, Path: "/tmp/bckp"}},
+		{"s3://bucket/daily/node1/", Location{Scheme: S3Scheme, Bucket: "bucket", Prefix: "daily/node1"}},
+		{"gcs://bucket", Location{Scheme: GCSScheme, Bucket: "bucket"}},
+	}
+	for _, check := range checks {
+		if loc, err := ParseLocation(check.loc); err != nil {
+			t.Errorf("Unable to parse location: %s. Error: %v", check.loc, err)
+		} else if *loc != check.expLoc {
+			t.Errorf("Location mismatch for %s. Expected: %+v, Actual: %+v", check.loc, check.expLoc, *loc)
+		}
+	}
+	for _, loc := range []string{"", "file://host/tmp", "s3:///prefix", "hdfs://host/path"} {
+		if _, err := ParseLocation(loc); err == nil {
+			t.Errorf("Expected an error on parsing location: %q", loc)
+		}
+	}
+}
+
+func TestJoinLocation(t *testing.T) {
+	checks := []struct{ loc, name, expLoc string }{
+		{"/tmp/bckp", "node-1", "/tmp/bckp/node-1"},
+		{"file:///tmp/bckp/", "node-1", "/tmp/bckp/node-1"},
+		{"s3://bucket/daily/", "node-1", "s3://bucket/daily/node-1"},
+		{"gcs://bucket", "node-1", "gcs://bucket/node-1"},
+	}
+	for _, check := range checks {
+		if loc, err := JoinLocation(check.loc, check.name); err != nil || loc != check.expLoc {
+			t.Errorf("Location mismatch for %s. Expected: %s, Actual: %s, Error: %v", check.loc, check.expLoc, loc, err)
+		}
+	}
+}
+
+func TestUnconfiguredObjectStore(t *testing.T) {
+	backupTo := func(path string) error { return ioutil.WriteFile(path, []byte("data"), 0644) }
+	if err := LocalOnly.Backup(context.Background(), "s3://bucket/prefix", backupTo); err == nil {
+		t.Error("Expected an error for backups onto an unconfigured object store")
+	}
+
+	dir := newTempDir(t)
+	defer os.RemoveAll(dir)
+	bckpPath := path.Join(dir, "bckp")
+	if err := LocalOnly.Backup(context.Background(), "file://"+bckpPath, backupTo); err != nil {
+		t.Fatalf("Unable to backup onto the local filesystem. Error: %v", err)
+	}
+	if data, err := ioutil.ReadFile(bckpPath); err != nil || string(data) != "data" {
+		t.Errorf("Backup mismatch. Expected: data, Actual: %s, Error: %v", data, err)
+	}
+	if err := LocalOnly.Remove(context.Background(), bckpPath); err != nil {
+		t.Errorf("Unable to remove the backup. Error: %v", err)
+	}
+	if _, err := os.Stat(bckpPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the backup to be removed. Error: %v", err)
+	}
+	if err := LocalOnly.Remove(context.Background(), "s3://bucket/prefix"); err == nil {
+		t.Error("Expected an error for removing a backup from an unconfigured object store")
+	}
+}
+
+// TestObjectStoreBackupRestore runs against the S3 compatible store, such
+// as MinIO, at the endpoint given by DKV_TEST_S3_ENDPOINT and the bucket
+// given by DKV_TEST_S3_BUCKET, using the credentials in the environment.
+// Run `make test-minio` to run it against a MinIO container.
+func TestObjectStoreBackupRestore(t *testing.T) {
+	endpoint, bucket := os.Getenv("DKV_TEST_S3_ENDPOINT"), os.Getenv("DKV_TEST_S3_BUCKET")
+	if endpoint == "" || bucket == "" {
+		t.Skip("DKV_TEST_S3_ENDPOINT and DKV_TEST_S3_BUCKET are not set")
+	}
+	dir := newTempDir(t)
+	defer os.RemoveAll(dir)
+	trnsfr, err := NewTransfer(Config{StagingDir: dir, S3: StoreConfig{Endpoint: endpoint, ForcePathStyle: true}}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Unable to create the transfer. Error: %v", err)
+	}
+	if _, err = trnsfr.clients[S3Scheme].CreateBucket(&s3.CreateBucketInput{Bucket: aws.String(bucket)}); err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != s3.ErrCodeBucketAlreadyOwnedByYou {
+			t.Fatalf("Unable to create the bucket: %s. Error: %v", bucket, err)
+		}
+	}
+
+	// Spans multiple parts of the multipart upload
+	bigData := bytes.Repeat([]byte("0123456789"), 1<<20)
+	files := map[string][]byte{"CURRENT": []byte("MANIFEST-000001"), "shared/000001.sst": bigData}
+	prefix := fmt.Sprintf("dkv-test/%d", time.Now().UnixNano())
+	folderLoc := fmt.Sprintf("s3://%s/%s/folder", bucket, prefix)
+	err = trnsfr.Backup(context.Background(), folderLoc, func(bckpPath string) error {
+		for name, data := range files {
+			if err := os.MkdirAll(path.Dir(path.Join(bckpPath, name)), 0755); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(path.Join(bckpPath, name), data, 0644); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unable to backup a folder. Error: %v", err)
+	}
+	err = trnsfr.Restore(context.Background(), folderLoc, func(rstrPath string) error {
+		for name, data := range files {
+			if actData, err := ioutil.ReadFile(path.Join(rstrPath, name)); err != nil || !bytes.Equal(actData, data) {
+				t.Errorf("Restored file mismatch for %s. Error: %v", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unable to restore a folder. Error: %v", err)
+	}
+
+	fileLoc := fmt.Sprintf("s3://%s/%s/file", bucket, prefix)
+	backupTo := func(bckpPath string) error { return ioutil.WriteFile(bckpPath, []byte("data"), 0644) }
+	if err = trnsfr.Backup(context.Background(), fileLoc, backupTo); err != nil {
+		t.Fatalf("Unable to backup a file. Error: %v", err)
+	}
+	if err = trnsfr.Backup(context.Background(), fileLoc, backupTo); err == nil {
+		t.Error("Expected an error for a backup onto an existing backup")
+	}
+	err = trnsfr.Restore(context.Background(), fileLoc, func(rstrPath string) error {
+		if data, err := ioutil.ReadFile(rstrPath); err != nil || string(data) != "data" {
+			t.Errorf("Restored file mismatch. Expected: data, Actual: %s, Error: %v", data, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unable to restore a file. Error: %v", err)
+	}
+
+	if err = trnsfr.Restore(context.Background(), fmt.Sprintf("s3://%s/%s/missing", bucket, prefix), func(string) error { return nil }); err == nil {
+		t.Error("Expected an error for a restore from a missing backup")
+	}
+	if err = trnsfr.Remove(context.Background(), folderLoc); err != nil {
+		t.Fatalf("Unable to remove a backup. Error: %v", err)
+	}
+	if err = trnsfr.Restore(context.Background(), folderLoc, func(string) error { return nil }); err == nil {
+		t.Error("Expected an error for a restore from a removed backup")
+	}
+	if leftovers, _ := ioutil.ReadDir(dir); len(leftovers) > 0 {
+		t.Errorf("Expected the staged backups to be removed. Actual: %d left over", len(leftovers))
+	}
+}
+
+func newTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "dkv_backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
@@ -0,0 +1,277 @@
+// Package config describes the settings of a DKV node, which can be
+// loaded from a YAML file and are overridden by the flags of dkvsrv.
+// The master and slave services are created as per these settings.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/compression"
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/server/ratelimit"
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"gopkg.in/yaml.v2"
+)
+
+// Config holds the settings of a DKV node, as named in its YAML file.
+type Config struct {
+	// Role of the node - none|standalone|master|slave, where standalone
+	// serves its changes to slave nodes without Nexus.
+	Role        string      `yaml:"role"`
+	Listen      Listen      `yaml:"listen"`
+	Storage     Storage     `yaml:"storage"`
+	Replication Replication `yaml:"replication"`
+	TLS         TLS         `yaml:"tls"`
+	Auth        Auth        `yaml:"auth"`
+	Limits      Limits      `yaml:"limits"`
+	Metrics     Metrics     `yaml:"metrics"`
+}
+
+// Listen holds the addresses on which the DKV service is served, where
+// an empty address leaves the respective protocol unserved.
+type Listen struct {
+	// GRPC is the address on which the GRPC services bind.
+	GRPC string `yaml:"grpc"`
+	// HTTP is the address on which the DKV service is served over HTTP
+	// with JSON at /v1.
+	HTTP string `yaml:"http"`
+	// Redis is the address on which the GET, SET, MGET, DEL and EXISTS
+	// commands are served over the Redis protocol.
+	Redis string `yaml:"redis"`
+}
+
+// Storage holds the settings of the local storage of a DKV node.
+type Storage struct {
+	// Engine storing the data - badger|memory|rocksdb.
+	Engine string `yaml:"engine"`
+	// Folder under which the data files are stored.
+	Folder string `yaml:"folder"`
+	// Compression is the codec of the values at rest - none|snappy|zstd.
+	Compression string `yaml:"compression"`
+	// VerifyOnStart verifies the files of the storage engine along with
+	// its change numbers before serving.
+	VerifyOnStart bool `yaml:"verifyOnStart"`
+	// Repair repairs the storage engine before opening it.
+	Repair bool `yaml:"repair"`
+	// BulkLoad accepts bulk loads that bypass the change log.
+	BulkLoad bool `yaml:"bulkLoad"`
+	// AsyncPuts acknowledges the puts before syncing them onto the disk,
+	// unless they request otherwise.
+	AsyncPuts bool `yaml:"asyncPuts"`
+	// RestoreParallelism is the number of workers ingesting backups.
+	RestoreParallelism int `yaml:"restoreParallelism"`
+	// QuotaReconcileInterval is the interval at which the usage of the
+	// namespaces with quotas is reconciled.
+	QuotaReconcileInterval time.Duration `yaml:"quotaReconcileInterval"`
+	// TombstoneRetention is the duration for which deleted keys are
+	// retained under tombstones, zero for deleting them outright.
+	TombstoneRetention time.Duration `yaml:"tombstoneRetention"`
+	ChangeLog          ChangeLog     `yaml:"changeLog"`
+	KeyPolicy          KeyPolicy     `yaml:"keyPolicy"`
+}
+
+// ChangeLog holds the retention of the changes of a master node, as per
+// storage.RetentionPolicy, where zero disables the respective limit.
+type ChangeLog struct {
+	MaxAge           time.Duration `yaml:"maxAge"`
+	MaxChanges       uint64        `yaml:"maxChanges"`
+	RetainUnconsumed bool          `yaml:"retainUnconsumed"`
+}
+
+// KeyPolicy holds the policy that the keys mutated through a node must
+// satisfy, as per storage.KeyPolicy.
+type KeyPolicy struct {
+	// Pattern is the regular expression that keys must match, anchored
+	// with ^ and $ for matching keys as a whole.
+	Pattern     string `yaml:"pattern"`
+	UTF8        bool   `yaml:"utf8"`
+	RejectEmpty bool   `yaml:"rejectEmpty"`
+}
+
+// Replication holds the settings of a slave node for replicating the
+// changes of its master node.
+type Replication struct {
+	// MasterAddrs are the service addresses of the candidate masters.
+	MasterAddrs []string `yaml:"masterAddrs"`
+	// PollInterval is the interval at which changes are polled for
+	// whenever streaming them is not possible.
+	PollInterval time.Duration `yaml:"pollInterval"`
+	// BatchSize is the maximum number of changes replicated at once.
+	BatchSize uint `yaml:"batchSize"`
+	// BatchBytes is the maximum size of the changes replicated at once,
+	// zero for no limit.
+	BatchBytes uint64 `yaml:"batchBytes"`
+	// KeyPrefix restricts the replication to the keys with this prefix,
+	// within the Namespace if any.
+	KeyPrefix string `yaml:"keyPrefix"`
+	// Namespace restricts the replication to the keys of this namespace.
+	Namespace string `yaml:"namespace"`
+	// MaxApplyLatency is the average time for applying a batch beyond
+	// which the replication is throttled, zero for no throttling.
+	MaxApplyLatency time.Duration `yaml:"maxApplyLatency"`
+	// KeepaliveTime is the duration of inactivity after which the
+	// connection to the master is pinged, zero for no pings.
+	KeepaliveTime time.Duration `yaml:"keepaliveTime"`
+	// ReplicaID identifies this node to the master, defaulting to the
+	// GRPC listen address.
+	ReplicaID string `yaml:"replicaID"`
+	// AuthToken is the bearer token presented to the master.
+	AuthToken string `yaml:"authToken"`
+	// TLS holds the files used for mutual TLS with the master.
+	TLS TLS `yaml:"tls"`
+	// HealthMaxLag is the replication lag in changes beyond which the
+	// node is reported unhealthy, zero for no limit.
+	HealthMaxLag uint64 `yaml:"healthMaxLag"`
+	// HealthMaxFailTime is the duration for which replication can fail
+	// before the node is reported unhealthy, zero for no limit.
+	HealthMaxFailTime time.Duration `yaml:"healthMaxFailTime"`
+}
+
+// TLS holds the files of a certificate, its private key and the CA
+// certificate verifying the peers.
+type TLS struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	CAFile   string `yaml:"caFile"`
+}
+
+// Enabled reports whether any of the files is given.
+func (t *TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != "" || t.CAFile != ""
+}
+
+// Auth holds the bearer tokens accepted by a node, either given inline
+// or loaded from a file, each as <token>:<scope>[,<scope>...].
+type Auth struct {
+	Tokens    []string `yaml:"tokens"`
+	TokenFile string   `yaml:"tokenFile"`
+}
+
+// Enabled reports whether calls must be authorized.
+func (a *Auth) Enabled() bool {
+	return len(a.Tokens) > 0 || a.TokenFile != ""
+}
+
+// Limits holds the limits on the sizes of the keys, values and messages
+// handled by a node along with those on its GRPC calls, as per
+// ratelimit.Limits, where zero disables the respective limit.
+type Limits struct {
+	MaxKeySize     int    `yaml:"maxKeySize"`
+	MaxValueSize   int    `yaml:"maxValueSize"`
+	MaxRecvMsgSize int    `yaml:"maxRecvMsgSize"`
+	MaxSendMsgSize int    `yaml:"maxSendMsgSize"`
+	MaxChangesSize uint64 `yaml:"maxChangesSize"`
+
+	MethodRate       float64 `yaml:"methodRate"`
+	MethodBurst      int     `yaml:"methodBurst"`
+	TokenRate        float64 `yaml:"tokenRate"`
+	TokenBurst       int     `yaml:"tokenBurst"`
+	ReplicationRate  float64 `yaml:"replicationRate"`
+	ReplicationBurst int     `yaml:"replicationBurst"`
+	MaxInFlight      int     `yaml:"maxInFlight"`
+}
+
+// Metrics holds the addresses on which a node serves its metrics.
+type Metrics struct {
+	// Addr serves the metrics at /metrics in the Prometheus format.
+	Addr string `yaml:"addr"`
+	// ReplicationStatsAddr serves the replication status of a slave
+	// node at /debug/vars.
+	ReplicationStatsAddr string `yaml:"replicationStatsAddr"`
+}
+
+// MinReplPollInterval is the shortest interval at which a slave node
+// can poll for changes from its master node.
+const MinReplPollInterval = 10 * time.Millisecond
+
+// DefaultMaxChangesSize is the number of bytes of changes served by a
+// master node per batch, unless configured otherwise.
+const DefaultMaxChangesSize = 64 << 20
+
+// Default returns the config used in the absence of any settings.
+func Default() *Config {
+	return &Config{
+		Role:   "none",
+		Listen: Listen{GRPC: "127.0.0.1:8080"},
+		Storage: Storage{
+			Engine:                 "rocksdb",
+			Folder:                 "/tmp/dkvsrv",
+			Compression:            compression.None.String(),
+			RestoreParallelism:     storage.DefaultRestoreParallelism,
+			QuotaReconcileInterval: storage.DefaultQuotaReconcileInterval,
+		},
+		Replication: Replication{
+			PollInterval:      5 * time.Second,
+			BatchSize:         1000,
+			BatchBytes:        16 << 20,
+			KeepaliveTime:     ctl.ReplicationKeepaliveTime,
+			HealthMaxFailTime: time.Minute,
+		},
+		Limits: Limits{
+			MaxKeySize:     32 << 10,
+			MaxValueSize:   4 << 20,
+			MaxRecvMsgSize: ctl.DefaultMaxRecvMsgSize,
+			MaxSendMsgSize: ctl.DefaultMaxSendMsgSize,
+			MaxChangesSize: DefaultMaxChangesSize,
+		},
+	}
+}
+
+// Load reads the YAML file of the given name onto the defaults, with
+// the settings absent from the file retaining their defaults. Settings
+// unknown to Config fail the load, while the config loaded is left to
+// be validated by the caller, such as once overridden by flags.
+func Load(file string) (*Config, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	cfg := Default()
+	if err = yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse config file %s: %v", file, err)
+	}
+	return cfg, nil
+}
+
+// Codec returns the codec named by Compression, which is None for an
+// unknown name, as reported by Validate.
+func (s *Storage) Codec() compression.Codec {
+	codec, _ := compression.ParseCodec(s.Compression)
+	return codec
+}
+
+// KeyPolicies returns the key policy as the default of all namespaces,
+// without any Pattern that does not compile, as reported by Validate.
+func (s *Storage) KeyPolicies() storage.KeyPolicies {
+	keyPolicy := storage.KeyPolicy{UTF8: s.KeyPolicy.UTF8, RejectEmpty: s.KeyPolicy.RejectEmpty}
+	if s.KeyPolicy.Pattern != "" {
+		keyPolicy.Pattern, _ = regexp.Compile(s.KeyPolicy.Pattern)
+	}
+	return storage.KeyPolicies{Default: keyPolicy}
+}
+
+// RetentionPolicy returns the retention of the changes.
+func (cl *ChangeLog) RetentionPolicy() storage.RetentionPolicy {
+	return storage.RetentionPolicy{MaxAge: cl.MaxAge, MaxChanges: cl.MaxChanges, RetainUnconsumed: cl.RetainUnconsumed}
+}
+
+// SizeLimits returns the limits on the sizes of the keys and values.
+func (l *Limits) SizeLimits() storage.SizeLimits {
+	return storage.SizeLimits{MaxKeySize: l.MaxKeySize, MaxValueSize: l.MaxValueSize}
+}
+
+// RateLimits returns the limits on the GRPC calls.
+func (l *Limits) RateLimits() ratelimit.Limits {
+	return ratelimit.Limits{
+		MethodRate:       l.MethodRate,
+		MethodBurst:      l.MethodBurst,
+		TokenRate:        l.TokenRate,
+		TokenBurst:       l.TokenBurst,
+		ReplicationRate:  l.ReplicationRate,
+		ReplicationBurst: l.ReplicationBurst,
+		MaxInFlight:      l.MaxInFlight,
+	}
+}
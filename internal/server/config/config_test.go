@@ -0,0 +1,144 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/compression"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Errorf("Expected the default config to be valid. Error: %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file := writeConfigFile(t, `
+role: slave
+listen:
+  grpc: 127.0.0.1:9090
+  http: 127.0.0.1:9091
+storage:
+  engine: memory
+  compression: zstd
+  tombstoneRetention: 24h
+  changeLog:
+    maxChanges: 1000
+replication:
+  masterAddrs: [127.0.0.1:8080, 127.0.0.1:8081]
+  pollInterval: 250ms
+  healthMaxFailTime: 90s
+auth:
+  tokens: ["t1:read", "t2:read,write"]
+limits:
+  maxValueSize: 1048576
+  methodRate: 100.5
+`)
+	defer os.RemoveAll(path.Dir(file))
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cfg.Validate(); err != nil {
+		t.Fatalf("Expected the loaded config to be valid. Error: %v", err)
+	}
+
+	expCfg := Default()
+	expCfg.Role = "slave"
+	expCfg.Listen = Listen{GRPC: "127.0.0.1:9090", HTTP: "127.0.0.1:9091"}
+	expCfg.Storage.Engine, expCfg.Storage.Compression, expCfg.Storage.TombstoneRetention = "memory", "zstd", 24*time.Hour
+	expCfg.Storage.ChangeLog.MaxChanges = 1000
+	expCfg.Replication.MasterAddrs = []string{"127.0.0.1:8080", "127.0.0.1:8081"}
+	expCfg.Replication.PollInterval, expCfg.Replication.HealthMaxFailTime = 250*time.Millisecond, 90*time.Second
+	expCfg.Auth.Tokens = []string{"t1:read", "t2:read,write"}
+	expCfg.Limits.MaxValueSize, expCfg.Limits.MethodRate = 1<<20, 100.5
+	if !reflect.DeepEqual(cfg, expCfg) {
+		t.Errorf("Expected the settings absent from the file to retain their defaults. Expected: %+v, Actual: %+v", expCfg, cfg)
+	}
+	if codec := cfg.Storage.Codec(); codec != compression.Zstd {
+		t.Errorf("Expected the zstd codec. Actual: %v", codec)
+	}
+}
+
+func TestLoadRejectsUnknownSettings(t *testing.T) {
+	file := writeConfigFile(t, "storage:\n  engin: memory\n")
+	defer os.RemoveAll(path.Dir(file))
+	if _, err := Load(file); err == nil {
+		t.Error("Expected the unknown setting to fail the load")
+	}
+	if _, err := Load(file + ".absent"); err == nil {
+		t.Error("Expected the absent file to fail the load")
+	}
+}
+
+func TestValidateNamesInvalidFields(t *testing.T) {
+	cfg := Default()
+	cfg.Role = "replica"
+	cfg.Storage.Compression = "lz4"
+	cfg.Storage.KeyPolicy.Pattern = "[a-z"
+	cfg.TLS.CertFile = "server.crt"
+	cfg.Limits.MaxKeySize, cfg.Limits.TokenRate = -1, 10
+	expectInvalidFields(t, cfg, "role", "storage.compression", "storage.keyPolicy.pattern", "tls.keyFile", "limits.maxKeySize", "limits.tokenRate")
+
+	cfg = Default()
+	cfg.Role = "Slave"
+	cfg.Storage.Engine = "rocksdb"
+	cfg.Replication.KeyPrefix = "users/"
+	cfg.Replication.PollInterval, cfg.Replication.BatchSize = time.Millisecond, 0
+	cfg.Auth.Tokens, cfg.Auth.TokenFile = []string{"t1:read"}, "tokens"
+	expectInvalidFields(t, cfg, "storage.engine", "replication.masterAddrs", "replication.pollInterval", "replication.batchSize", "auth.tokenFile")
+
+	cfg = Default()
+	cfg.Role = "master"
+	cfg.Storage.Engine = "badger"
+	cfg.Replication.BatchSize = 0
+	cfg.Auth.Tokens = []string{"t1"}
+	expectInvalidFields(t, cfg, "storage.engine", "auth.tokens")
+
+	cfg = Default()
+	cfg.Role = "slave"
+	cfg.Replication.MasterAddrs, cfg.Replication.Namespace = []string{"master:8080"}, "users"
+	expectInvalidFields(t, cfg, "storage.engine")
+
+	cfg = Default()
+	cfg.Role = "master"
+	cfg.Storage.ChangeLog.MaxAge, cfg.Storage.ChangeLog.RetainUnconsumed = time.Hour, true
+	expectInvalidFields(t, cfg, "storage.changeLog.retainUnconsumed")
+	cfg.Storage.Engine = "memory"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected unconsumed changes to be retained by the memory engine. Error: %v", err)
+	}
+}
+
+func expectInvalidFields(t *testing.T, cfg *Config, fields ...string) {
+	t.Helper()
+	err := cfg.Validate()
+	ve, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("Expected a ValidationError. Actual: %v", err)
+	}
+	var actFields []string
+	for _, fe := range ve {
+		actFields = append(actFields, fe.Field)
+	}
+	if !reflect.DeepEqual(actFields, fields) {
+		t.Errorf("Expected the invalid fields %v. Actual: %v, Error: %v", fields, actFields, err)
+	}
+}
+
+func writeConfigFile(t *testing.T, data string) string {
+	dir, err := ioutil.TempDir("", "dkv_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := path.Join(dir, "dkv.yaml")
+	if err = ioutil.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
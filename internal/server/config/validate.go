@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/flipkart-incubator/dkv/internal/compression"
+	"github.com/flipkart-incubator/dkv/internal/server/security"
+)
+
+// A FieldError describes an invalid setting of a Config.
+type FieldError struct {
+	// Field is the path of the setting in the YAML file, like
+	// storage.engine.
+	Field string
+	// Reason describes why the setting is invalid.
+	Reason string
+}
+
+func (fe *FieldError) Error() string {
+	return fe.Field + ": " + fe.Reason
+}
+
+// ValidationError lists every invalid setting of a Config.
+type ValidationError []*FieldError
+
+func (ve ValidationError) Error() string {
+	msgs := make([]string, len(ve))
+	for i, fe := range ve {
+		msgs[i] = fe.Error()
+	}
+	return "invalid config - " + strings.Join(msgs, "; ")
+}
+
+// invalidFunc reports the given field as invalid for the given reason
+type invalidFunc func(field, format string, args ...interface{})
+
+var (
+	roles   = []string{"none", "standalone", "master", "slave"}
+	engines = []string{"badger", "memory", "rocksdb"}
+)
+
+// Validate checks all the settings, returning a ValidationError that
+// names every invalid one, or nil if there are none. The replication
+// settings are checked only for the slave role.
+func (cfg *Config) Validate() error {
+	var ve ValidationError
+	invalid := func(field, format string, args ...interface{}) {
+		ve = append(ve, &FieldError{Field: field, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	role := strings.ToLower(strings.TrimSpace(cfg.Role))
+	if !contains(roles, role) {
+		invalid("role", "must be one of %s, not %q", strings.Join(roles, "|"), cfg.Role)
+	}
+	if cfg.Listen.GRPC == "" {
+		invalid("listen.grpc", "is required")
+	}
+
+	strg := &cfg.Storage
+	switch {
+	case !contains(engines, strg.Engine):
+		invalid("storage.engine", "must be one of %s, not %q", strings.Join(engines, "|"), strg.Engine)
+	case strg.Engine == "badger" && (role == "standalone" || role == "master"):
+		invalid("storage.engine", "badger is not supported for the %s role", role)
+	case strg.Engine == "rocksdb" && role == "slave" && (cfg.Replication.KeyPrefix != "" || cfg.Replication.Namespace != ""):
+		invalid("storage.engine", "rocksdb is not supported for the slave role along with replication.keyPrefix or replication.namespace")
+	}
+	if strg.Folder == "" {
+		invalid("storage.folder", "is required")
+	}
+	if _, err := compression.ParseCodec(strg.Compression); err != nil {
+		invalid("storage.compression", "must be one of none|snappy|zstd, not %q", strg.Compression)
+	}
+	if strg.RestoreParallelism <= 0 {
+		invalid("storage.restoreParallelism", "must be positive")
+	}
+	if strg.QuotaReconcileInterval <= 0 {
+		invalid("storage.quotaReconcileInterval", "must be positive")
+	}
+	if strg.TombstoneRetention < 0 {
+		invalid("storage.tombstoneRetention", "can not be negative")
+	}
+	if strg.ChangeLog.MaxAge < 0 {
+		invalid("storage.changeLog.maxAge", "can not be negative")
+	}
+	if strg.Engine == "rocksdb" && strg.ChangeLog.RetainUnconsumed {
+		invalid("storage.changeLog.retainUnconsumed", "is not supported by rocksdb, which purges its WAL files on its own irrespective of the slave nodes")
+	}
+	if _, err := regexp.Compile(strg.KeyPolicy.Pattern); err != nil {
+		invalid("storage.keyPolicy.pattern", "%v", err)
+	}
+
+	if role == "slave" {
+		cfg.Replication.validate(invalid)
+	}
+	if cfg.TLS.CAFile != "" && cfg.TLS.CertFile == "" {
+		invalid("tls.certFile", "is required along with tls.caFile")
+	}
+	validateKeyPair("tls", &cfg.TLS, invalid)
+
+	switch {
+	case len(cfg.Auth.Tokens) > 0 && cfg.Auth.TokenFile != "":
+		invalid("auth.tokenFile", "can not be given along with auth.tokens")
+	case len(cfg.Auth.Tokens) > 0:
+		if _, err := security.ParseTokens(strings.Join(cfg.Auth.Tokens, ";")); err != nil {
+			invalid("auth.tokens", "%v", err)
+		}
+	}
+
+	cfg.Limits.validate(cfg.Auth.Enabled(), invalid)
+	if len(ve) > 0 {
+		return ve
+	}
+	return nil
+}
+
+func (repl *Replication) validate(invalid invalidFunc) {
+	if len(repl.MasterAddrs) == 0 {
+		invalid("replication.masterAddrs", "is required for the slave role")
+	}
+	for i, masterAddr := range repl.MasterAddrs {
+		if strings.TrimSpace(masterAddr) == "" {
+			invalid(fmt.Sprintf("replication.masterAddrs[%d]", i), "can not be empty")
+		}
+	}
+	if repl.PollInterval < MinReplPollInterval {
+		invalid("replication.pollInterval", "must be atleast %v", MinReplPollInterval)
+	}
+	if repl.BatchSize == 0 || repl.BatchSize > math.MaxUint32 {
+		invalid("replication.batchSize", "must be between 1 and %d", uint32(math.MaxUint32))
+	}
+	if repl.MaxApplyLatency < 0 {
+		invalid("replication.maxApplyLatency", "can not be negative")
+	}
+	if repl.KeepaliveTime < 0 {
+		invalid("replication.keepaliveTime", "can not be negative")
+	}
+	if repl.HealthMaxFailTime < 0 {
+		invalid("replication.healthMaxFailTime", "can not be negative")
+	}
+	validateKeyPair("replication.tls", &repl.TLS, invalid)
+}
+
+func (l *Limits) validate(authEnabled bool, invalid invalidFunc) {
+	for _, size := range []struct {
+		field string
+		value int
+	}{{"maxKeySize", l.MaxKeySize}, {"maxValueSize", l.MaxValueSize}, {"methodBurst", l.MethodBurst}, {"tokenBurst", l.TokenBurst}, {"replicationBurst", l.ReplicationBurst}, {"maxInFlight", l.MaxInFlight}} {
+		if size.value < 0 {
+			invalid("limits."+size.field, "can not be negative")
+		}
+	}
+	for _, rate := range []struct {
+		field string
+		value float64
+	}{{"methodRate", l.MethodRate}, {"tokenRate", l.TokenRate}, {"replicationRate", l.ReplicationRate}} {
+		if rate.value < 0 {
+			invalid("limits."+rate.field, "can not be negative")
+		}
+	}
+	if l.MaxRecvMsgSize <= 0 {
+		invalid("limits.maxRecvMsgSize", "must be positive")
+	}
+	if l.MaxSendMsgSize <= 0 {
+		invalid("limits.maxSendMsgSize", "must be positive")
+	}
+	if l.TokenRate > 0 && !authEnabled {
+		invalid("limits.tokenRate", "requires either auth.tokens or auth.tokenFile")
+	}
+}
+
+// validateKeyPair checks that the certificate and its private key are
+// given together, if at all.
+func validateKeyPair(field string, t *TLS, invalid invalidFunc) {
+	switch {
+	case t.CertFile != "" && t.KeyFile == "":
+		invalid(field+".keyFile", "is required along with %s.certFile", field)
+	case t.CertFile == "" && t.KeyFile != "":
+		invalid(field+".certFile", "is required along with %s.keyFile", field)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,376 @@
+// Package gateway serves the DKV service over HTTP with JSON, so that
+// keys can be read and written from shell scripts and browsers that
+// lack GRPC tooling. Requests are served by invoking the DKV service
+// directly, and authorized as the GRPC methods they stand for.
+//
+// Endpoints are:
+//
+//	GET    /v1/kv/{key}     - value of the key, as the response body
+//	PUT    /v1/kv/{key}     - sets the value of the key to the request body
+//	DELETE /v1/kv/{key}     - deletes the key
+//	POST   /v1/kv:multiget  - values of the keys of a JSON body {"keys": [...]}
+//	GET    /v1/status       - serving status and replication status, if any
+//
+// Keys in the path are URL escaped. With the `encoding=base64` query
+// parameter, the keys in the path are URL safe base64 encoded while
+// the values in the bodies are standard base64 encoded, so that binary
+// keys and values can be handled as text. Keys and values within JSON
+// are always standard base64 encoded. The `namespace` and `maxLag`
+// query parameters are those of the respective GRPC requests.
+//
+// Failures are conveyed by the HTTP status code, along with a JSON
+// body of the DKV status carrying the code, message and leader hint.
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// An Authorizer authorizes every HTTP request against the GRPC method
+// it stands for, such as security.TokenAuthenticator.
+type Authorizer interface {
+	AuthorizeHTTP(req *http.Request, fullMethod string) error
+}
+
+// Limit on the size of the request bodies, beyond the size limits of
+// the DKV service so that those limits are reported as such.
+const maxBodySize = 64 << 20
+
+const (
+	kvPath       = "/v1/kv/"
+	multiGetPath = "/v1/kv:multiget"
+	statusPath   = "/v1/status"
+)
+
+// GRPC methods for which the requests are authorized.
+const (
+	getMethod       = "/dkv.serverpb.DKV/Get"
+	putMethod       = "/dkv.serverpb.DKV/Put"
+	deleteMethod    = "/dkv.serverpb.DKV/Delete"
+	multiGetMethod  = "/dkv.serverpb.DKV/MultiGet"
+	getStatusMethod = "/dkv.serverpb.DKVReplicationStatus/GetStatus"
+)
+
+// An Option configures the gateway.
+type Option func(*gateway)
+
+// WithAuthorizer authorizes every request using the given Authorizer,
+// whereas requests are not authorized at all by default.
+func WithAuthorizer(auth Authorizer) Option {
+	return func(gw *gateway) {
+		gw.auth = auth
+	}
+}
+
+type gateway struct {
+	dkvSvc serverpb.DKVServer
+	auth   Authorizer
+}
+
+// NewHandler creates the HTTP handler that serves the given DKV service.
+// The serving status is that of its GRPC health service, and the
+// replication status is reported for the DKV slave services.
+func NewHandler(dkvSvc serverpb.DKVServer, opts ...Option) http.Handler {
+	gw := &gateway{dkvSvc: dkvSvc}
+	for _, opt := range opts {
+		opt(gw)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(kvPath, gw.serveKV)
+	mux.HandleFunc(multiGetPath, gw.serveMultiGet)
+	mux.HandleFunc(statusPath, gw.serveStatus)
+	return mux
+}
+
+func (gw *gateway) authorized(w http.ResponseWriter, req *http.Request, fullMethod string) bool {
+	if gw.auth == nil {
+		return true
+	}
+	if err := gw.auth.AuthorizeHTTP(req, fullMethod); err != nil {
+		msg := err.Error()
+		if st, ok := status.FromError(err); ok {
+			msg = st.Message()
+		}
+		writeJSON(w, http.StatusForbidden, &serverpb.Status{Code: dkverrors.UnknownStatusCode, Message: msg})
+		return false
+	}
+	return true
+}
+
+func (gw *gateway) serveKV(w http.ResponseWriter, req *http.Request) {
+	base64Enc := req.URL.Query().Get("encoding") == "base64"
+	key, err := pathKey(req, base64Enc)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	namespace := req.URL.Query().Get("namespace")
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		if !gw.authorized(w, req, getMethod) {
+			return
+		}
+		maxLag, lagBounded, err := maxLagParam(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		res, err := gw.dkvSvc.Get(req.Context(), &serverpb.GetRequest{Key: key, MaxLag: maxLag, LagBounded: lagBounded, Namespace: namespace})
+		switch st := resultStatus(res.GetStatus(), err); {
+		case failed(st):
+			writeStatus(w, st)
+		case !res.Found:
+			writeStatus(w, &serverpb.Status{Code: int32(serverpb.StatusCode_KeyNotFound), Message: "key not found"})
+		case base64Enc:
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(base64.StdEncoding.EncodeToString(res.Value)))
+		default:
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(res.Value)
+		}
+	case http.MethodPut:
+		if !gw.authorized(w, req, putMethod) {
+			return
+		}
+		value, err := readBody(req)
+		if err == nil && base64Enc {
+			value, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(value)))
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		res, err := gw.dkvSvc.Put(req.Context(), &serverpb.PutRequest{Key: key, Value: value, Namespace: namespace})
+		writeStatus(w, resultStatus(res.GetStatus(), err))
+	case http.MethodDelete:
+		if !gw.authorized(w, req, deleteMethod) {
+			return
+		}
+		res, err := gw.dkvSvc.Delete(req.Context(), &serverpb.DeleteRequest{Key: key, Namespace: namespace})
+		writeStatus(w, resultStatus(res.GetStatus(), err))
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed on keys", req.Method))
+	}
+}
+
+// MultiGetRequest is the JSON body of the requests for multiple keys.
+type MultiGetRequest struct {
+	Keys [][]byte `json:"keys"`
+}
+
+// KeyValue is the value of a key read by a MultiGet request, with
+// Found conveying whether the key is present.
+type KeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+	Found bool   `json:"found"`
+}
+
+// MultiGetResponse is the JSON body of the responses of MultiGet
+// requests, with the values of the keys in the order of the request.
+type MultiGetResponse struct {
+	Results []*KeyValue `json:"results"`
+}
+
+func (gw *gateway) serveMultiGet(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed on MultiGet", req.Method))
+		return
+	}
+	if !gw.authorized(w, req, multiGetMethod) {
+		return
+	}
+	maxLag, lagBounded, err := maxLagParam(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := readBody(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var multiGetReq MultiGetRequest
+	if err = json.Unmarshal(body, &multiGetReq); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid MultiGet request: %v", err))
+		return
+	}
+	res, err := gw.dkvSvc.MultiGet(req.Context(), &serverpb.MultiGetRequest{Keys: multiGetReq.Keys, MaxLag: maxLag, LagBounded: lagBounded, Namespace: req.URL.Query().Get("namespace")})
+	if st := resultStatus(res.GetStatus(), err); failed(st) {
+		writeStatus(w, st)
+		return
+	}
+	if len(res.Values) != len(multiGetReq.Keys) || len(res.Found) != len(multiGetReq.Keys) {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("MultiGet returned %d values for %d keys", len(res.Values), len(multiGetReq.Keys)))
+		return
+	}
+	multiGetRes := &MultiGetResponse{Results: make([]*KeyValue, len(multiGetReq.Keys))}
+	for i, key := range multiGetReq.Keys {
+		multiGetRes.Results[i] = &KeyValue{Key: key, Value: res.Values[i], Found: res.Found[i]}
+	}
+	writeJSON(w, http.StatusOK, multiGetRes)
+}
+
+// StatusResponse is the JSON body of the responses of status requests.
+type StatusResponse struct {
+	// Serving is the serving status of the GRPC health service,
+	// such as SERVING or NOT_SERVING
+	Serving string `json:"serving"`
+	// Replication is set only by the DKV slave services
+	Replication *serverpb.GetStatusResponse `json:"replication,omitempty"`
+}
+
+func (gw *gateway) serveStatus(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed on status", req.Method))
+		return
+	}
+	if !gw.authorized(w, req, getStatusMethod) {
+		return
+	}
+	statusRes := &StatusResponse{Serving: grpc_health_v1.HealthCheckResponse_UNKNOWN.String()}
+	if hlthSvc, ok := gw.dkvSvc.(grpc_health_v1.HealthServer); ok {
+		if res, err := hlthSvc.Check(req.Context(), &grpc_health_v1.HealthCheckRequest{}); err == nil {
+			statusRes.Serving = res.Status.String()
+		}
+	}
+	if replSvc, ok := gw.dkvSvc.(serverpb.DKVReplicationStatusServer); ok {
+		if res, err := replSvc.GetStatus(req.Context(), &serverpb.GetStatusRequest{}); err == nil {
+			statusRes.Replication = res
+		}
+	}
+	code := http.StatusOK
+	if statusRes.Serving != grpc_health_v1.HealthCheckResponse_SERVING.String() {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, statusRes)
+}
+
+// pathKey returns the key of the request path, which is taken as is
+// from the escaped path so that keys can carry escaped slashes.
+func pathKey(req *http.Request, base64Enc bool) ([]byte, error) {
+	escKey := strings.TrimPrefix(req.URL.EscapedPath(), kvPath)
+	key, err := url.PathUnescape(escKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key in path: %v", err)
+	}
+	if key == "" {
+		return nil, fmt.Errorf("key must be given in the path")
+	}
+	if !base64Enc {
+		return []byte(key), nil
+	}
+	decKey, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 key in path: %v", err)
+	}
+	return decKey, nil
+}
+
+// maxLagParam returns the lag bounding the read, if any, as given by
+// the maxLag query parameter, where zero reads only caught up slaves.
+func maxLagParam(req *http.Request) (uint64, bool, error) {
+	param := req.URL.Query().Get("maxLag")
+	if param == "" {
+		return 0, false, nil
+	}
+	maxLag, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid maxLag: %s", param)
+	}
+	return maxLag, true, nil
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, req.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the request body: %v", err)
+	}
+	return body, nil
+}
+
+// resultStatus is the status of a call onto the DKV service, which
+// conveys its error, if any.
+func resultStatus(st *serverpb.Status, err error) *serverpb.Status {
+	if err != nil {
+		if grpcSt, ok := status.FromError(err); ok {
+			return &serverpb.Status{Code: dkverrors.UnknownStatusCode, Message: grpcSt.Message()}
+		}
+		return dkverrors.NewStatus(err)
+	}
+	return st
+}
+
+func failed(st *serverpb.Status) bool {
+	return st != nil && st.Code != int32(serverpb.StatusCode_Ok)
+}
+
+// httpStatusCodes are the HTTP status codes of the failures that
+// callers can act upon, with all others being internal errors.
+var httpStatusCodes = map[serverpb.StatusCode]int{
+	serverpb.StatusCode_Ok:                 http.StatusOK,
+	serverpb.StatusCode_KeyNotFound:        http.StatusNotFound,
+	serverpb.StatusCode_InvalidArgument:    http.StatusBadRequest,
+	serverpb.StatusCode_NonNumericValue:    http.StatusBadRequest,
+	serverpb.StatusCode_InvalidKey:         http.StatusBadRequest,
+	serverpb.StatusCode_QuotaExceeded:      http.StatusInsufficientStorage,
+	serverpb.StatusCode_KeyTooLarge:        http.StatusRequestEntityTooLarge,
+	serverpb.StatusCode_ValueTooLarge:      http.StatusRequestEntityTooLarge,
+	serverpb.StatusCode_NotLeader:          http.StatusMisdirectedRequest,
+	serverpb.StatusCode_ReadOnlyReplica:    http.StatusMethodNotAllowed,
+	serverpb.StatusCode_StaleRead:          http.StatusServiceUnavailable,
+	serverpb.StatusCode_Maintenance:        http.StatusServiceUnavailable,
+	serverpb.StatusCode_BackupInProgress:   http.StatusConflict,
+	serverpb.StatusCode_KeyExists:          http.StatusConflict,
+	serverpb.StatusCode_ChangesUnavailable: http.StatusGone,
+	serverpb.StatusCode_ChangesTruncated:   http.StatusGone,
+}
+
+// writeStatus writes the given status of the DKV service, which is
+// empty upon success.
+func writeStatus(w http.ResponseWriter, st *serverpb.Status) {
+	if st == nil {
+		st = &serverpb.Status{}
+	}
+	code, present := httpStatusCodes[serverpb.StatusCode(st.Code)]
+	if !present {
+		code = http.StatusInternalServerError
+	}
+	if code == http.StatusOK {
+		w.WriteHeader(code)
+		return
+	}
+	writeJSON(w, code, st)
+}
+
+// writeError writes the given failure of the gateway itself, such as
+// that of an invalid request.
+func writeError(w http.ResponseWriter, code int, err error) {
+	st := &serverpb.Status{Code: dkverrors.UnknownStatusCode, Message: err.Error()}
+	if code == http.StatusBadRequest {
+		st.Code = int32(serverpb.StatusCode_InvalidArgument)
+	}
+	writeJSON(w, code, st)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,157 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/internal/server/master"
+	"github.com/flipkart-incubator/dkv/internal/server/security"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+const (
+	readToken  = "reader"
+	writeToken = "writer"
+)
+
+func newGateway(t *testing.T) (*httptest.Server, func()) {
+	store := memory.OpenDB(0)
+	dkvSvc := master.NewStandaloneService(store, nil, store)
+	auth := security.NewTokenAuthenticator(map[string]security.Scope{
+		readToken:  security.ReadScope,
+		writeToken: security.ReadScope | security.WriteScope,
+	})
+	srvr := httptest.NewServer(NewHandler(dkvSvc, WithAuthorizer(auth)))
+	return srvr, func() {
+		srvr.Close()
+		auth.Close()
+		dkvSvc.Close()
+	}
+}
+
+func call(t *testing.T, method, url, token string, body []byte) (int, []byte) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, resBody
+}
+
+func TestGatewayKeys(t *testing.T) {
+	srvr, stop := newGateway(t)
+	defer stop()
+
+	checks := []struct {
+		method, path, token string
+		body                []byte
+		expCode             int
+		expBody             []byte
+	}{
+		{http.MethodPut, "/v1/kv/foo", writeToken, []byte("bar"), http.StatusOK, nil},
+		{http.MethodGet, "/v1/kv/foo", readToken, nil, http.StatusOK, []byte("bar")},
+		// Keys carry escaped slashes
+		{http.MethodPut, "/v1/kv/a%2Fb", writeToken, []byte("c"), http.StatusOK, nil},
+		{http.MethodGet, "/v1/kv/a%2Fb", readToken, nil, http.StatusOK, []byte("c")},
+		// Binary keys and values as base64
+		{http.MethodPut, "/v1/kv/_wE?encoding=base64", writeToken, []byte(base64.StdEncoding.EncodeToString([]byte{1, 2})), http.StatusOK, nil},
+		{http.MethodGet, "/v1/kv/_wE?encoding=base64", readToken, nil, http.StatusOK, []byte("AQI=")},
+		// Namespaces isolate their keys
+		{http.MethodGet, "/v1/kv/foo?namespace=ns1", readToken, nil, http.StatusNotFound, nil},
+		{http.MethodDelete, "/v1/kv/foo", writeToken, nil, http.StatusOK, nil},
+		{http.MethodGet, "/v1/kv/foo", readToken, nil, http.StatusNotFound, nil},
+		{http.MethodGet, "/v1/kv/f?encoding=base64", readToken, nil, http.StatusBadRequest, nil},
+		{http.MethodGet, "/v1/kv/", readToken, nil, http.StatusBadRequest, nil},
+		{http.MethodPost, "/v1/kv/foo", writeToken, nil, http.StatusMethodNotAllowed, nil},
+		// Requests are authorized as their GRPC methods
+		{http.MethodGet, "/v1/kv/a%2Fb", "", nil, http.StatusForbidden, nil},
+		{http.MethodPut, "/v1/kv/foo", readToken, []byte("bar"), http.StatusForbidden, nil},
+		{http.MethodDelete, "/v1/kv/foo", "unknown", nil, http.StatusForbidden, nil},
+	}
+	for _, check := range checks {
+		code, body := call(t, check.method, srvr.URL+check.path, check.token, check.body)
+		if code != check.expCode {
+			t.Errorf("%s %s - Expected status code: %d. Actual: %d, Body: %s", check.method, check.path, check.expCode, code, body)
+		}
+		if check.expBody != nil && !bytes.Equal(check.expBody, body) {
+			t.Errorf("%s %s - Expected body: %q. Actual: %q", check.method, check.path, check.expBody, body)
+		}
+		if code != http.StatusOK {
+			var st serverpb.Status
+			if err := json.Unmarshal(body, &st); err != nil || st.Message == "" {
+				t.Errorf("%s %s - Expected a JSON status of the failure. Actual: %s", check.method, check.path, body)
+			}
+		}
+	}
+}
+
+func TestGatewayMultiGet(t *testing.T) {
+	srvr, stop := newGateway(t)
+	defer stop()
+
+	for _, key := range []string{"k1", "k3"} {
+		if code, body := call(t, http.MethodPut, srvr.URL+"/v1/kv/"+key, writeToken, []byte("v_"+key)); code != http.StatusOK {
+			t.Fatalf("Unable to put key: %s. Status code: %d, Body: %s", key, code, body)
+		}
+	}
+	reqBody, _ := json.Marshal(&MultiGetRequest{Keys: [][]byte{[]byte("k1"), []byte("k2"), []byte("k3")}})
+	code, body := call(t, http.MethodPost, srvr.URL+"/v1/kv:multiget", readToken, reqBody)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status code: %d. Actual: %d, Body: %s", http.StatusOK, code, body)
+	}
+	var res MultiGetResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		t.Fatal(err)
+	}
+	expResults := []*KeyValue{{[]byte("k1"), []byte("v_k1"), true}, {[]byte("k2"), nil, false}, {[]byte("k3"), []byte("v_k3"), true}}
+	if len(res.Results) != len(expResults) {
+		t.Fatalf("Expected %d results. Actual: %s", len(expResults), body)
+	}
+	for i, exp := range expResults {
+		if act := res.Results[i]; !bytes.Equal(exp.Key, act.Key) || !bytes.Equal(exp.Value, act.Value) || exp.Found != act.Found {
+			t.Errorf("Result mismatch at %d. Expected: %+v, Actual: %+v", i, exp, act)
+		}
+	}
+
+	if code, body = call(t, http.MethodPost, srvr.URL+"/v1/kv:multiget", readToken, []byte("{")); code != http.StatusBadRequest {
+		t.Errorf("Expected status code: %d for an invalid body. Actual: %d, Body: %s", http.StatusBadRequest, code, body)
+	}
+	if code, body = call(t, http.MethodPost, srvr.URL+"/v1/kv:multiget", "", reqBody); code != http.StatusForbidden {
+		t.Errorf("Expected status code: %d without a token. Actual: %d, Body: %s", http.StatusForbidden, code, body)
+	}
+}
+
+func TestGatewayStatus(t *testing.T) {
+	srvr, stop := newGateway(t)
+	defer stop()
+
+	code, body := call(t, http.MethodGet, srvr.URL+"/v1/status", readToken, nil)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status code: %d. Actual: %d, Body: %s", http.StatusOK, code, body)
+	}
+	var res StatusResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		t.Fatal(err)
+	}
+	// Replication status is reported only by slaves
+	if res.Serving != "SERVING" || res.Replication != nil {
+		t.Errorf("Expected a SERVING status without replication. Actual: %s", body)
+	}
+}
@@ -0,0 +1,53 @@
+// Package health implements the standard GRPC health checking service
+// (grpc.health.v1.Health) on top of the serving status reported by
+// the DKV services, so that load balancers and orchestrators can
+// route traffic only onto the nodes that are fit to serve it.
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// A StatusFunc reports the current serving status of a DKV node.
+type StatusFunc func() grpc_health_v1.HealthCheckResponse_ServingStatus
+
+// Interval at which the serving status is evaluated for every
+// Watch stream, with updates sent only when it changes.
+const watchInterval = time.Second
+
+type healthServer struct {
+	status StatusFunc
+}
+
+// NewServer creates a GRPC health server that reports the status
+// given by the StatusFunc. The status is reported for the node as
+// a whole, irrespective of the service name in the request.
+func NewServer(status StatusFunc) grpc_health_v1.HealthServer {
+	return &healthServer{status}
+}
+
+func (hs *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	return &grpc_health_v1.HealthCheckResponse{Status: hs.status()}, nil
+}
+
+func (hs *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, watchSrvr grpc_health_v1.Health_WatchServer) error {
+	tckr := time.NewTicker(watchInterval)
+	defer tckr.Stop()
+	lastStatus := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for sent := false; ; {
+		if currStatus := hs.status(); !sent || currStatus != lastStatus {
+			if err := watchSrvr.Send(&grpc_health_v1.HealthCheckResponse{Status: currStatus}); err != nil {
+				return err
+			}
+			lastStatus, sent = currStatus, true
+		}
+		select {
+		case <-tckr.C:
+		case <-watchSrvr.Context().Done():
+			return watchSrvr.Context().Err()
+		}
+	}
+}
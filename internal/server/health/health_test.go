@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const hlthSvcPort = 8686
+
+func TestCheckAndWatch(t *testing.T) {
+	var serving uint32 = 1
+	statusFunc := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		if atomic.LoadUint32(&serving) == 1 {
+			return grpc_health_v1.HealthCheckResponse_SERVING
+		}
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", hlthSvcPort))
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	grpcSrvr := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcSrvr, NewServer(statusFunc))
+	go grpcSrvr.Serve(lis)
+	defer grpcSrvr.Stop()
+
+	conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", hlthSvcPort), grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("Unable to connect to the health service. Error: %v", err)
+	}
+	defer conn.Close()
+	hlthCli := grpc_health_v1.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if res, err := hlthCli.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil || res.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("Expected a SERVING status. Actual: %v, Error: %v", res.GetStatus(), err)
+	}
+
+	watchStrm, err := hlthCli.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Unable to watch the health service. Error: %v", err)
+	}
+	if res, err := watchStrm.Recv(); err != nil || res.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("Expected the current SERVING status upfront. Actual: %v, Error: %v", res.GetStatus(), err)
+	}
+	atomic.StoreUint32(&serving, 0)
+	if res, err := watchStrm.Recv(); err != nil || res.Status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Expected the changed NOT_SERVING status. Actual: %v, Error: %v", res.GetStatus(), err)
+	}
+}
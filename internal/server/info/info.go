@@ -0,0 +1,57 @@
+// Package info implements the DKVInfo service, which describes a DKV
+// node along with its build, so that tools like grpcurl and clients
+// like the load balancing ones can tell the nodes apart.
+package info
+
+import (
+	"context"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/server/maintenance"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/flipkart-incubator/dkv/version"
+)
+
+// A RoleFunc reports the role in which a DKV node currently serves.
+type RoleFunc func() serverpb.ServerRole
+
+// ServerInfo captures the attributes of a DKV node that remain the
+// same for as long as it runs.
+type ServerInfo struct {
+	StorageEngine        string
+	StorageEngineVersion string
+	// ListenAddrs are the addresses listened on, by protocol
+	ListenAddrs map[string]string
+	// Maintenance is the maintenance mode of the node, whose status is
+	// reported as of every call. It is nil for nodes without one.
+	Maintenance *maintenance.Mode
+}
+
+type infoServer struct {
+	role      RoleFunc
+	info      ServerInfo
+	startTime time.Time
+}
+
+// NewServer creates a server of the DKVInfo service that describes
+// the node using the given info, along with the role reported by the
+// RoleFunc. The uptime of the node is counted from its creation.
+func NewServer(role RoleFunc, info ServerInfo) serverpb.DKVInfoServer {
+	return &infoServer{role: role, info: info, startTime: time.Now()}
+}
+
+func (is *infoServer) GetServerInfo(context.Context, *serverpb.GetServerInfoRequest) (*serverpb.GetServerInfoResponse, error) {
+	inMaint, maintReason := is.info.Maintenance.Status()
+	return &serverpb.GetServerInfoResponse{
+		Status:               &serverpb.Status{},
+		Role:                 is.role(),
+		StorageEngine:        is.info.StorageEngine,
+		StorageEngineVersion: is.info.StorageEngineVersion,
+		Version:              version.Version,
+		Commit:               version.Commit,
+		UptimeMillis:         int64(time.Since(is.startTime) / time.Millisecond),
+		ListenAddrs:          is.info.ListenAddrs,
+		Maintenance:          inMaint,
+		MaintenanceReason:    maintReason,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package info
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/flipkart-incubator/dkv/version"
+)
+
+func TestGetServerInfo(t *testing.T) {
+	role := serverpb.ServerRole_Slave
+	infoSrvr := NewServer(func() serverpb.ServerRole { return role }, ServerInfo{StorageEngine: "badger", StorageEngineVersion: "v1.6.0", ListenAddrs: map[string]string{"grpc": "127.0.0.1:8080"}})
+	time.Sleep(10 * time.Millisecond)
+	res, err := infoSrvr.GetServerInfo(context.Background(), &serverpb.GetServerInfoRequest{})
+	if err != nil {
+		t.Fatalf("Unable to get the server info. Error: %v", err)
+	}
+	if res.Role != serverpb.ServerRole_Slave || res.StorageEngine != "badger" || res.StorageEngineVersion != "v1.6.0" || res.ListenAddrs["grpc"] != "127.0.0.1:8080" {
+		t.Errorf("Server info mismatch. Actual: %+v", res)
+	}
+	if res.Version != version.Version || res.UptimeMillis < 10 {
+		t.Errorf("Expected the build and uptime to be reported. Actual: %+v", res)
+	}
+
+	if res.Maintenance || res.MaintenanceReason != "" {
+		t.Errorf("Expected the node without a maintenance mode to not be in maintenance. Actual: %+v", res)
+	}
+
+	// Role is reported as of every call
+	role = serverpb.ServerRole_Master
+	if res, _ = infoSrvr.GetServerInfo(context.Background(), &serverpb.GetServerInfoRequest{}); res.Role != serverpb.ServerRole_Master {
+		t.Errorf("Expected the node to be reported as a master once promoted. Actual: %v", res.Role)
+	}
+}
@@ -0,0 +1,144 @@
+// Package maintenance implements the maintenance mode of DKV nodes, in
+// which they keep serving reads while rejecting keyspace mutations, so
+// that operations like compactions or host maintenance can run without
+// removing the nodes from their clusters. It also implements the
+// DKVMaintenance service that switches the nodes in and out of it.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+)
+
+// Name of the marker file that records the maintenance mode, holding
+// its reason, so that it is retained across restarts
+const markerFile = "MAINTENANCE"
+
+// A Mode tracks whether a DKV node is in maintenance mode. Since the
+// mode is persisted onto the folder of the node, a node that crashes
+// while in maintenance remains in it once restarted. Its methods can
+// be called on nil, in which case the node is never in maintenance.
+type Mode struct {
+	marker string
+	mu     sync.RWMutex
+	on     bool
+	reason string
+}
+
+// NewMode creates the Mode of the node whose data is stored in the
+// given folder, which is in maintenance if it was when last stopped.
+func NewMode(folder string) (*Mode, error) {
+	mode := &Mode{marker: filepath.Join(folder, markerFile)}
+	reason, err := ioutil.ReadFile(mode.marker)
+	switch {
+	case err == nil:
+		mode.on, mode.reason = true, string(reason)
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+	return mode, nil
+}
+
+// Enter switches the node into maintenance for the given reason, or
+// updates the reason if it already is. Keyspace mutations that are in
+// progress may still complete.
+func (m *Mode) Enter(reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// The marker is written wholly before it replaces any previous one
+	tmpMarker := m.marker + ".tmp"
+	if err := writeSynced(tmpMarker, []byte(reason)); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpMarker, m.marker); err != nil {
+		os.Remove(tmpMarker)
+		return err
+	}
+	m.on, m.reason = true, reason
+	return nil
+}
+
+// Exit lets the node accept keyspace mutations again.
+func (m *Mode) Exit() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := os.Remove(m.marker); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	m.on, m.reason = false, ""
+	return nil
+}
+
+// Status reports whether the node is in maintenance, along with
+// the reason for it.
+func (m *Mode) Status() (bool, string) {
+	if m == nil {
+		return false, ""
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.on, m.reason
+}
+
+// CheckWritable returns ErrMaintenance while the node is in
+// maintenance, and nil otherwise.
+func (m *Mode) CheckWritable() error {
+	switch on, reason := m.Status(); {
+	case on && reason != "":
+		return fmt.Errorf("%w: %s", dkverrors.ErrMaintenance, reason)
+	case on:
+		return dkverrors.ErrMaintenance
+	}
+	return nil
+}
+
+func writeSynced(file string, data []byte) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+type maintenanceServer struct {
+	mode *Mode
+	lgr  *zap.Logger
+}
+
+// NewServer creates a server of the DKVMaintenance service that
+// switches the given Mode in and out of maintenance.
+func NewServer(mode *Mode, lgr *zap.Logger) serverpb.DKVMaintenanceServer {
+	return &maintenanceServer{mode, lgr}
+}
+
+func (ms *maintenanceServer) EnterMaintenanceMode(ctx context.Context, req *serverpb.EnterMaintenanceModeRequest) (*serverpb.Status, error) {
+	if err := ms.mode.Enter(req.Reason); err != nil {
+		ms.lgr.Error("Unable to enter maintenance mode", zap.String("reason", req.Reason), zap.Error(err))
+		return dkverrors.NewStatus(err), nil
+	}
+	ms.lgr.Warn("Entered maintenance mode, rejecting keyspace mutations", zap.String("reason", req.Reason))
+	return &serverpb.Status{}, nil
+}
+
+func (ms *maintenanceServer) ExitMaintenanceMode(ctx context.Context, req *serverpb.ExitMaintenanceModeRequest) (*serverpb.Status, error) {
+	if err := ms.mode.Exit(); err != nil {
+		ms.lgr.Error("Unable to exit maintenance mode", zap.Error(err))
+		return dkverrors.NewStatus(err), nil
+	}
+	ms.lgr.Info("Exited maintenance mode, accepting keyspace mutations")
+	return &serverpb.Status{}, nil
+}
@@ -0,0 +1,69 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dkv_maint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mode, err := NewMode(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if on, _ := mode.Status(); on || mode.CheckWritable() != nil {
+		t.Fatalf("Expected a new node to not be in maintenance")
+	}
+
+	srvr := NewServer(mode, zap.NewNop())
+	if res, err := srvr.EnterMaintenanceMode(context.Background(), &serverpb.EnterMaintenanceModeRequest{Reason: "disk swap"}); err != nil || res.Code != 0 {
+		t.Fatalf("Unable to enter maintenance mode. Status: %+v, Error: %v", res, err)
+	}
+	if on, reason := mode.Status(); !on || reason != "disk swap" {
+		t.Errorf("Expected the node to be in maintenance for disk swap. Actual: %t, %q", on, reason)
+	}
+	if err = mode.CheckWritable(); !errors.Is(err, dkverrors.ErrMaintenance) {
+		t.Errorf("Expected error: %v. Actual: %v", dkverrors.ErrMaintenance, err)
+	}
+
+	// Maintenance is retained across restarts
+	if mode, err = NewMode(dir); err != nil {
+		t.Fatal(err)
+	}
+	if on, reason := mode.Status(); !on || reason != "disk swap" {
+		t.Errorf("Expected the node to remain in maintenance once restarted. Actual: %t, %q", on, reason)
+	}
+
+	srvr = NewServer(mode, zap.NewNop())
+	if res, err := srvr.ExitMaintenanceMode(context.Background(), &serverpb.ExitMaintenanceModeRequest{}); err != nil || res.Code != 0 {
+		t.Fatalf("Unable to exit maintenance mode. Status: %+v, Error: %v", res, err)
+	}
+	if mode.CheckWritable() != nil {
+		t.Errorf("Expected the node to accept mutations once out of maintenance")
+	}
+	if mode, err = NewMode(dir); err != nil {
+		t.Fatal(err)
+	}
+	if on, _ := mode.Status(); on {
+		t.Errorf("Expected the node to remain out of maintenance once restarted")
+	}
+}
+
+func TestNilMode(t *testing.T) {
+	var mode *Mode
+	if on, _ := mode.Status(); on || mode.CheckWritable() != nil {
+		t.Errorf("Expected a nil mode to never be in maintenance")
+	}
+}
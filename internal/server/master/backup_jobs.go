@@ -0,0 +1,210 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// Number of the latest jobs whose status is retained once they complete.
+const maxRetainedBackupJobs = 16
+
+// backupJobs runs the backups and restores of a DKVService in the
+// background, one at a time, and tracks their progress.
+type backupJobs struct {
+	mu      sync.Mutex
+	lastID  uint64
+	jobs    map[uint64]*backupJob
+	running *backupJob
+	// Cancelled when the DKVService is closed
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Tracks the jobs running in the background
+	wg sync.WaitGroup
+}
+
+type backupJob struct {
+	id        uint64
+	restore   bool
+	dryRun    bool
+	path, ns  string
+	mu        sync.Mutex
+	info      *serverpb.BackupInfo
+	state     serverpb.BackupJobState
+	err       error
+	localPath string
+	bytes     uint64
+	// Shall be manipulated using atomics
+	keys uint64
+	// Updated by the workers of restore jobs
+	progress storage.RestoreProgress
+	// Closed once the job completes
+	done chan struct{}
+}
+
+func newBackupJobs() *backupJobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &backupJobs{jobs: make(map[uint64]*backupJob), ctx: ctx, cancel: cancel}
+}
+
+// start begins running the given function as the given job in the
+// background, as per the semantics of begin.
+func (bj *backupJobs) start(job *backupJob, run func(context.Context, *backupJob) error) (*backupJob, error) {
+	job, err := bj.begin(job)
+	if err != nil {
+		return nil, err
+	}
+	bj.wg.Add(1)
+	go func() {
+		defer bj.wg.Done()
+		bj.end(job, run(bj.ctx, job))
+	}()
+	return job, nil
+}
+
+// begin registers the given job under a new ID, unless another job is
+// already running, in which case it fails with ErrBackupInProgress.
+// Every job that begins must end.
+func (bj *backupJobs) begin(job *backupJob) (*backupJob, error) {
+	bj.mu.Lock()
+	defer bj.mu.Unlock()
+	if bj.running != nil {
+		return nil, fmt.Errorf("job: %d is running: %w", bj.running.id, dkverrors.ErrBackupInProgress)
+	}
+	bj.lastID++
+	job.id, job.done = bj.lastID, make(chan struct{})
+	bj.jobs[job.id], bj.running = job, job
+	delete(bj.jobs, job.id-maxRetainedBackupJobs)
+	return job, nil
+}
+
+func (bj *backupJobs) end(job *backupJob, err error) {
+	bj.mu.Lock()
+	defer bj.mu.Unlock()
+	job.complete(err)
+	bj.running = nil
+}
+
+func (bj *backupJobs) get(id uint64) (*backupJob, error) {
+	bj.mu.Lock()
+	defer bj.mu.Unlock()
+	job, present := bj.jobs[id]
+	if !present {
+		return nil, fmt.Errorf("unknown job: %d: %w", id, dkverrors.ErrInvalidArgument)
+	}
+	return job, nil
+}
+
+func (bj *backupJobs) close() {
+	bj.cancel()
+}
+
+// wait waits for the jobs running in the background to end, which
+// happens promptly once they are cancelled through close.
+func (bj *backupJobs) wait() {
+	bj.wg.Wait()
+}
+
+// writingTo records the path on the local filesystem onto which the
+// backup of the job is being written, so that its size can be tracked.
+// Once the backup is written, the empty path must be recorded instead,
+// which retains the final size since staged backups are removed later.
+func (job *backupJob) writingTo(localPath string) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.localPath != "" {
+		job.bytes = storage.PathSize(job.localPath)
+	}
+	job.localPath = localPath
+}
+
+// verified records the info of the backup verified by a restore job.
+func (job *backupJob) verified(info *storage.BackupInfo, curChngNum uint64) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.info = &serverpb.BackupInfo{
+		Engine:              info.Engine,
+		ChangeNumber:        info.ChangeNumber,
+		CurrentChangeNumber: curChngNum,
+		NumberOfKeys:        info.NumberOfKeys,
+		Size:                info.Size,
+	}
+}
+
+func (job *backupJob) complete(err error) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.state, job.err = serverpb.BackupJobState_Done, err
+	if err != nil {
+		job.state = serverpb.BackupJobState_Failed
+	}
+	close(job.done)
+}
+
+// wait waits for the job to complete, returning its error, unless the
+// given context is done first.
+func (job *backupJob) wait(ctx context.Context) error {
+	select {
+	case <-job.done:
+		job.mu.Lock()
+		defer job.mu.Unlock()
+		return job.err
+	case <-ctx.Done():
+		return fmt.Errorf("job: %d is still running: %w", job.id, ctx.Err())
+	}
+}
+
+func (job *backupJob) status() *serverpb.GetBackupStatusResponse {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.localPath != "" {
+		job.bytes = storage.PathSize(job.localPath)
+	}
+	res := &serverpb.GetBackupStatusResponse{
+		Status:        newEmptyStatus(),
+		JobID:         job.id,
+		Restore:       job.restore,
+		Path:          job.path,
+		Namespace:     job.ns,
+		State:         job.state,
+		BytesWritten:  job.bytes,
+		KeysProcessed: atomic.LoadUint64(&job.keys),
+		DryRun:        job.dryRun,
+		BackupInfo:    job.info,
+	}
+	if job.restore {
+		res.KeysProcessed = atomic.LoadUint64(&job.progress.Keys)
+		res.BytesRestored = atomic.LoadUint64(&job.progress.Bytes)
+		res.TotalBytes = atomic.LoadUint64(&job.progress.TotalBytes)
+	}
+	if job.err != nil {
+		res.Error = job.err.Error()
+	}
+	return res
+}
+
+// keyCountingStore counts the keys iterated over by backup jobs, which
+// tracks the progress of namespace backups.
+type keyCountingStore struct {
+	storage.KVStore
+	job *backupJob
+}
+
+func (kcs *keyCountingStore) Iterate(keyPrefix, startKey []byte) storage.Iterator {
+	return &keyCountingIter{kcs.KVStore.Iterate(keyPrefix, startKey), kcs.job}
+}
+
+type keyCountingIter struct {
+	storage.Iterator
+	job *backupJob
+}
+
+func (kci *keyCountingIter) Next() ([]byte, []byte) {
+	atomic.AddUint64(&kci.job.keys, 1)
+	return kci.Iterator.Next()
+}
@@ -0,0 +1,58 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+func TestBackupJobs(t *testing.T) {
+	bj := newBackupJobs()
+	defer bj.close()
+
+	release := make(chan struct{})
+	job, err := bj.start(&backupJob{path: "/tmp/bckp", ns: "ns1"}, func(ctx context.Context, job *backupJob) error {
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unable to start a job. Error: %v", err)
+	}
+	if res := job.status(); res.State != serverpb.BackupJobState_Running || res.Path != "/tmp/bckp" || res.Namespace != "ns1" {
+		t.Errorf("Expected the job to be running. Status: %+v", res)
+	}
+	if _, err = bj.start(&backupJob{restore: true, path: "/tmp/bckp"}, nil); !errors.Is(err, dkverrors.ErrBackupInProgress) {
+		t.Errorf("Expected a concurrent job to be rejected. Error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err = job.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait for a running job to time out. Error: %v", err)
+	}
+	close(release)
+	if err = job.wait(context.Background()); err != nil {
+		t.Errorf("Expected the job to complete. Error: %v", err)
+	}
+
+	failed, err := bj.begin(&backupJob{restore: true, path: "/tmp/bckp"})
+	if err != nil {
+		t.Fatalf("Unable to begin a job. Error: %v", err)
+	}
+	bj.end(failed, errors.New("restore failed"))
+	if err = failed.wait(context.Background()); err == nil || err.Error() != "restore failed" {
+		t.Errorf("Expected the wait to report the failure of the job. Error: %v", err)
+	}
+	if res, err := bj.get(job.id); err != nil || res.status().State != serverpb.BackupJobState_Done {
+		t.Errorf("Expected the job to be done. Job: %+v, Error: %v", res, err)
+	}
+	if res, err := bj.get(failed.id); err != nil || res.status().State != serverpb.BackupJobState_Failed || res.status().Error != "restore failed" {
+		t.Errorf("Expected the job to have failed. Job: %+v, Error: %v", res, err)
+	}
+	if _, err := bj.get(failed.id + 1); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected an error for an unknown job. Error: %v", err)
+	}
+}
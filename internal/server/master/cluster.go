@@ -0,0 +1,244 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// A membershipReporter is implemented by the RAFT replicators that
+// can list the members of their cluster, keyed by their IDs, along
+// with the ID of its leader, which is zero if unknown.
+type membershipReporter interface {
+	ListMembers() (uint64, map[uint64]string, error)
+}
+
+// Path on the Nexus URL of every node at which its RAFT transport
+// responds to probes.
+const raftProbingPath = "/raft/probing"
+
+// Timeout within which the Nexus service of a node must respond
+// to the probe made while listing the nodes of the cluster.
+const raftProbeTimeout = time.Second
+
+// clusterMembers tracks the members of the cluster of the local node
+// on behalf of RAFT replicators that cannot list them, starting from
+// the members given by WithClusterNodes and followed by those added
+// or removed through the local node. Moreover it tracks the time at
+// which every member was last reached by the local node, along with
+// the addresses of the DKV services of the members, when given.
+type clusterMembers struct {
+	mu       sync.Mutex
+	localID  uint64
+	urls     map[uint64]string
+	dkvAddrs map[uint64]string
+	contacts map[uint64]time.Time
+	httpCli  *http.Client
+}
+
+func newClusterMembers(localID uint64, nodeURLs, dkvAddrs []string) *clusterMembers {
+	cm := &clusterMembers{
+		localID:  localID,
+		urls:     make(map[uint64]string),
+		dkvAddrs: make(map[uint64]string),
+		contacts: make(map[uint64]time.Time),
+		httpCli:  &http.Client{Timeout: raftProbeTimeout},
+	}
+	// As per Nexus, IDs of the initial members are their positions
+	for i, nodeURL := range nodeURLs {
+		cm.urls[uint64(i+1)] = nodeURL
+	}
+	for i, dkvAddr := range dkvAddrs {
+		cm.dkvAddrs[uint64(i+1)] = dkvAddr
+	}
+	return cm
+}
+
+func (cm *clusterMembers) add(nodeID uint64, nodeURL string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.urls[nodeID] = nodeURL
+}
+
+func (cm *clusterMembers) remove(nodeID uint64) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	delete(cm.urls, nodeID)
+	delete(cm.dkvAddrs, nodeID)
+	delete(cm.contacts, nodeID)
+}
+
+// leaderHint returns the leader of the cluster along with the address
+// of its DKV service, if known. The leader is known only if the given
+// RAFT replicator reports it, hence the hint is nil otherwise.
+func (cm *clusterMembers) leaderHint(raftRepl interface{}) *serverpb.LeaderHint {
+	mr, ok := raftRepl.(membershipReporter)
+	if !ok {
+		return nil
+	}
+	leaderID, _, err := mr.ListMembers()
+	if err != nil || leaderID == 0 {
+		return nil
+	}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return &serverpb.LeaderHint{NodeId: uint32(leaderID), DkvAddr: cm.dkvAddrs[leaderID]}
+}
+
+// members returns the URLs of the members of the cluster keyed by their
+// IDs, along with the ID of its leader, which is zero if unknown. These
+// are reported by the given RAFT replicator if it can, failing which the
+// tracked members are returned and the leader is known only if the
+// replicator reports that the local node is its leader.
+func (cm *clusterMembers) members(raftRepl interface{}) (uint64, map[uint64]string, error) {
+	var leaderID uint64
+	var urls map[uint64]string
+	if mr, ok := raftRepl.(membershipReporter); ok {
+		var err error
+		if leaderID, urls, err = mr.ListMembers(); err != nil {
+			return 0, nil, err
+		}
+	} else {
+		cm.mu.Lock()
+		urls = make(map[uint64]string, len(cm.urls))
+		for id, nodeURL := range cm.urls {
+			urls[id] = nodeURL
+		}
+		cm.mu.Unlock()
+	}
+	if lr, ok := raftRepl.(leadershipReporter); ok && leaderID == 0 && lr.IsLeader() {
+		leaderID = cm.localID
+	}
+	return leaderID, urls, nil
+}
+
+// dkvAddr returns the address of the DKV service of the given member,
+// which is empty if unknown.
+func (cm *clusterMembers) dkvAddr(nodeID uint64) string {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.dkvAddrs[nodeID]
+}
+
+// list returns the members of the cluster in the order of their IDs,
+// as per members. Every member is probed for its last contact,
+// concurrently.
+func (cm *clusterMembers) list(ctx context.Context, raftRepl interface{}) (uint64, []*serverpb.NodeInfo, error) {
+	leaderID, urls, err := cm.members(raftRepl)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	nodes := make([]*serverpb.NodeInfo, 0, len(urls))
+	var wg sync.WaitGroup
+	for id, nodeURL := range urls {
+		node := &serverpb.NodeInfo{NodeId: uint32(id), NodeUrl: nodeURL, DkvAddr: cm.dkvAddr(id)}
+		switch {
+		case leaderID == 0:
+			node.Role = serverpb.NodeRole_UnknownRole
+		case id == leaderID:
+			node.Role = serverpb.NodeRole_Leader
+		default:
+			node.Role = serverpb.NodeRole_Follower
+		}
+		nodes = append(nodes, node)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			node.LastContactTS = cm.probe(ctx, node.NodeId, node.NodeUrl)
+		}()
+	}
+	wg.Wait()
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeId < nodes[j].NodeId })
+	return leaderID, nodes, nil
+}
+
+// probe probes the Nexus service at the given URL of the given member
+// and returns the time at which it was last reached, in seconds since
+// epoch. The local node is always considered reached.
+func (cm *clusterMembers) probe(ctx context.Context, nodeID uint32, nodeURL string) uint64 {
+	id, now := uint64(nodeID), time.Now()
+	reached := id == cm.localID
+	if !reached {
+		if req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(nodeURL, "/")+raftProbingPath, nil); err == nil {
+			if res, err := cm.httpCli.Do(req.WithContext(ctx)); err == nil {
+				res.Body.Close()
+				reached = res.StatusCode == http.StatusOK
+			}
+		}
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if reached {
+		cm.contacts[id] = now
+	}
+	if contact, present := cm.contacts[id]; present {
+		return uint64(contact.Unix())
+	}
+	return 0
+}
+
+// memberClients retains the clients of the DKV services of the members
+// reached by the local node, by their addresses.
+type memberClients struct {
+	dial func(dkvAddr string) (*ctl.DKVClient, error)
+	clis map[string]*ctl.DKVClient
+}
+
+func newMemberClients(dial func(dkvAddr string) (*ctl.DKVClient, error)) *memberClients {
+	return &memberClients{dial: dial, clis: make(map[string]*ctl.DKVClient)}
+}
+
+// client returns the client of the given member whose DKV service is
+// at the given address, dialing it if needed.
+func (mc *memberClients) client(nodeID uint64, dkvAddr string) (*ctl.DKVClient, error) {
+	if dkvAddr == "" {
+		return nil, fmt.Errorf("address of the DKV service of node: %d is unknown: %w", nodeID, dkverrors.ErrInvalidArgument)
+	}
+	cli, present := mc.clis[dkvAddr]
+	if !present {
+		var err error
+		if cli, err = mc.dial(dkvAddr); err != nil {
+			return nil, err
+		}
+		mc.clis[dkvAddr] = cli
+	}
+	return cli, nil
+}
+
+// changeNumber retrieves the latest committed change number of the
+// given member whose DKV service is at the given address, which is
+// reported by the followers as well.
+func (mc *memberClients) changeNumber(ctx context.Context, nodeID uint64, dkvAddr string) (uint64, error) {
+	cli, err := mc.client(nodeID, dkvAddr)
+	if err != nil {
+		return 0, err
+	}
+	callCtx, cancel := context.WithTimeout(ctx, ctl.DefaultTimeout)
+	defer cancel()
+	// Changes beyond the latest one are never loaded
+	res, err := cli.GetChangesWithCtx(callCtx, math.MaxUint64, 0, 0)
+	if err == nil {
+		if err = dkverrors.FromStatus(res.Status); errors.Is(err, dkverrors.ErrNotLeader) {
+			err = nil
+		}
+	}
+	return res.GetMasterChangeNumber(), err
+}
+
+func (mc *memberClients) close() {
+	for _, cli := range mc.clis {
+		cli.Close()
+	}
+}
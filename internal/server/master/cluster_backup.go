@@ -0,0 +1,523 @@
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/server/backup"
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+)
+
+// Default duration within which the cluster must be backed up or
+// restored, when the call carries no deadline.
+const defaultClusterBackupTimeout = 30 * time.Minute
+
+// Duration within which the fences raised onto the members must be
+// lifted once the cluster is backed up or restored, along with the
+// removal of the backups of a failed ClusterBackup.
+const unfenceTimeout = time.Minute
+
+// Interval at which the change number of a member is polled while it
+// catches up with the change number of a ClusterBackup.
+const clusterBackupPollInterval = 100 * time.Millisecond
+
+// Name of the manifest written by ClusterBackup under its location,
+// next to the backups of the members named by their IDs.
+const (
+	clusterManifestName = "cluster-manifest"
+	memberBackupPrefix  = "node-"
+)
+
+// writeFence rejects the writes onto the local node while it is raised
+// by the node coordinating a ClusterBackup or ClusterRestore. Raising
+// the fence waits for the writes in progress to complete, hence the
+// change number of the local node changes no further until the fence
+// is lowered, unless changes replicated by other nodes are applied.
+type writeFence struct {
+	// Held for reading by every write in progress
+	mu    sync.RWMutex
+	id    string
+	timer *time.Timer
+	// Backups written by the local node under the fence
+	bckpLocs []string
+}
+
+func newWriteFence() *writeFence {
+	return &writeFence{}
+}
+
+// enter admits a write unless the fence is raised. Every admitted
+// write must exit.
+func (wf *writeFence) enter() error {
+	wf.mu.RLock()
+	if id := wf.id; id != "" {
+		wf.mu.RUnlock()
+		return fmt.Errorf("writes are fenced by: %s: %w", id, dkverrors.ErrBackupInProgress)
+	}
+	return nil
+}
+
+func (wf *writeFence) exit() {
+	wf.mu.RUnlock()
+}
+
+// raise raises the given fence, unless another one is raised, and
+// lowers it once the given TTL elapses. Raising a fence again only
+// extends its TTL.
+func (wf *writeFence) raise(id string, ttl time.Duration) error {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	if wf.id != "" && wf.id != id {
+		return fmt.Errorf("writes are fenced by: %s: %w", wf.id, dkverrors.ErrBackupInProgress)
+	}
+	if wf.timer != nil {
+		wf.timer.Stop()
+	}
+	wf.id, wf.timer = id, time.AfterFunc(ttl, func() { wf.lower(id) })
+	return nil
+}
+
+// lower lowers the given fence, if raised, and returns the locations of
+// the backups written under it.
+func (wf *writeFence) lower(id string) []string {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	if wf.id != id {
+		return nil
+	}
+	wf.timer.Stop()
+	bckpLocs := wf.bckpLocs
+	wf.id, wf.timer, wf.bckpLocs = "", nil, nil
+	return bckpLocs
+}
+
+// check ensures that the given fence is raised.
+func (wf *writeFence) check(id string) error {
+	wf.mu.RLock()
+	defer wf.mu.RUnlock()
+	if id == "" || wf.id != id {
+		return fmt.Errorf("writes are not fenced by: %q: %w", id, dkverrors.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// wrote records the location of a backup being written under the
+// given fence, which must be raised.
+func (wf *writeFence) wrote(id, bckpLoc string) error {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	if id == "" || wf.id != id {
+		return fmt.Errorf("writes are not fenced by: %q: %w", id, dkverrors.ErrInvalidArgument)
+	}
+	wf.bckpLocs = append(wf.bckpLocs, bckpLoc)
+	return nil
+}
+
+func (ds *distributedService) ClusterBackup(ctx context.Context, req *serverpb.ClusterBackupRequest) (*serverpb.ClusterBackupResponse, error) {
+	mnfst, err := ds.clusterBackup(ctx, req.Location)
+	if err != nil {
+		ds.opts.lgr.Error("Unable to backup the cluster", zap.String("location", req.Location), zap.Error(err))
+		return &serverpb.ClusterBackupResponse{Status: newErrorStatus(err)}, nil
+	}
+	ds.opts.lgr.Info("Backed up the cluster", zap.String("location", req.Location), zap.Uint64("changeNumber", mnfst.ChangeNumber))
+	return &serverpb.ClusterBackupResponse{Status: newEmptyStatus(), Manifest: mnfst}, nil
+}
+
+func (ds *distributedService) ClusterRestore(ctx context.Context, req *serverpb.ClusterRestoreRequest) (*serverpb.Status, error) {
+	if err := ds.clusterRestore(ctx, req.Location); err != nil {
+		ds.opts.lgr.Error("Unable to restore the cluster", zap.String("location", req.Location), zap.Error(err))
+		return newErrorStatus(err), nil
+	}
+	ds.opts.lgr.Info("Restored the cluster", zap.String("location", req.Location))
+	return newEmptyStatus(), nil
+}
+
+// clusterBackup fences the writes onto every member, backs up each of
+// them once it catches up with the latest change number among them and
+// writes the manifest of these backups. Backups are written by the
+// members themselves, which remove them if any of the steps fail.
+func (ds *distributedService) clusterBackup(ctx context.Context, loc string) (_ *serverpb.ClusterBackupManifest, err error) {
+	mnfstLoc, err := backup.JoinLocation(loc, clusterManifestName)
+	if err != nil {
+		return nil, err
+	}
+	cf, err := ds.fenceCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { cf.lift(err != nil) }()
+
+	mnfst := &serverpb.ClusterBackupManifest{}
+	for _, chngNum := range cf.chngNums {
+		if chngNum > mnfst.ChangeNumber {
+			mnfst.ChangeNumber = chngNum
+		}
+	}
+	for _, id := range cf.ids {
+		bckpLoc, _ := backup.JoinLocation(loc, fmt.Sprintf("%s%d", memberBackupPrefix, id))
+		mnfst.Artifacts = append(mnfst.Artifacts, &serverpb.ClusterBackupArtifact{NodeId: uint32(id), Location: bckpLoc})
+	}
+	err = cf.each(func(i int, cli *ctl.DKVClient) error {
+		artf := mnfst.Artifacts[i]
+		info, err := cli.BackupMemberWithCtx(ctx, cf.id, mnfst.ChangeNumber, artf.Location)
+		artf.Info = info
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = ds.writeManifest(ctx, mnfstLoc, mnfst); err != nil {
+		return nil, err
+	}
+	return mnfst, nil
+}
+
+// clusterRestore fences the writes onto every member, ensures that all
+// of them are fresh and restores each of them from its backup as per
+// the manifest. The restores are verified by the members before any of
+// their keys are replaced.
+func (ds *distributedService) clusterRestore(ctx context.Context, loc string) error {
+	mnfstLoc, err := backup.JoinLocation(loc, clusterManifestName)
+	if err != nil {
+		return err
+	}
+	cf, err := ds.fenceCluster(ctx)
+	if err != nil {
+		return err
+	}
+	defer cf.lift(false)
+
+	mnfst, err := ds.readManifest(ctx, mnfstLoc)
+	if err != nil {
+		return err
+	}
+	artfs := make(map[uint64]bool, len(mnfst.Artifacts))
+	for _, artf := range mnfst.Artifacts {
+		artfs[uint64(artf.NodeId)] = true
+	}
+	if len(artfs) != len(cf.ids) {
+		return fmt.Errorf("manifest lists the backups of %d nodes for a cluster of %d nodes: %w", len(artfs), len(cf.ids), dkverrors.ErrInvalidArgument)
+	}
+	for i, id := range cf.ids {
+		if !artfs[id] {
+			return fmt.Errorf("manifest lists no backup of node: %d: %w", id, dkverrors.ErrInvalidArgument)
+		}
+		if cf.chngNums[i] != 0 {
+			return fmt.Errorf("node: %d is not fresh since its change number is: %d: %w", id, cf.chngNums[i], dkverrors.ErrInvalidArgument)
+		}
+	}
+	// Backups are located under the given location, in case they
+	// are moved elsewhere since they were written
+	return cf.each(func(i int, cli *ctl.DKVClient) error {
+		bckpLoc, _ := backup.JoinLocation(loc, fmt.Sprintf("%s%d", memberBackupPrefix, cf.ids[i]))
+		return cli.RestoreMemberWithCtx(ctx, cf.id, mnfst.ChangeNumber, bckpLoc)
+	})
+}
+
+// writeManifest writes the given manifest as JSON onto the given
+// location, which is removed if it cannot be written completely.
+func (ds *distributedService) writeManifest(ctx context.Context, loc string, mnfst *serverpb.ClusterBackupManifest) error {
+	data, err := json.MarshalIndent(mnfst, "", "  ")
+	if err != nil {
+		return err
+	}
+	writing := false
+	err = ds.opts.bckpTrnsfr.Backup(ctx, loc, func(path string) error {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		mnfstFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		writing = true
+		if _, err = mnfstFile.Write(data); err != nil {
+			mnfstFile.Close()
+			return err
+		}
+		return mnfstFile.Close()
+	})
+	if err != nil && writing {
+		rmCtx, cancel := context.WithTimeout(context.Background(), unfenceTimeout)
+		defer cancel()
+		if rmErr := ds.opts.bckpTrnsfr.Remove(rmCtx, loc); rmErr != nil {
+			ds.opts.lgr.Warn("Unable to remove the manifest of the cluster backup", zap.String("location", loc), zap.Error(rmErr))
+		}
+	}
+	return err
+}
+
+func (ds *distributedService) readManifest(ctx context.Context, loc string) (*serverpb.ClusterBackupManifest, error) {
+	mnfst := &serverpb.ClusterBackupManifest{}
+	err := ds.opts.bckpTrnsfr.Restore(ctx, loc, func(path string) error {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(data, mnfst); err != nil {
+			return fmt.Errorf("invalid manifest of cluster backup: %v: %w", err, dkverrors.ErrInvalidArgument)
+		}
+		return nil
+	})
+	return mnfst, err
+}
+
+// clusterFence is the fence raised onto every member of the cluster by
+// the local node while coordinating a ClusterBackup or ClusterRestore.
+type clusterFence struct {
+	id       string
+	ids      []uint64
+	clis     []*ctl.DKVClient
+	chngNums []uint64
+	mbrClis  *memberClients
+	lgr      *zap.Logger
+}
+
+// fenceCluster raises a new fence onto every member of the cluster, in
+// the order of their IDs, until the deadline of the given context. The
+// local node must lead the cluster if the RAFT replicator reports it.
+func (ds *distributedService) fenceCluster(ctx context.Context) (*clusterFence, error) {
+	if lr, ok := ds.raftRepl.(leadershipReporter); ok && !lr.IsLeader() {
+		err := errors.New("cluster backups and restores must be coordinated by the leader")
+		return nil, dkverrors.WithLeaderHint(err, ds.members.leaderHint(ds.raftRepl))
+	}
+	_, urls, err := ds.members.members(ds.raftRepl)
+	if err != nil {
+		return nil, err
+	}
+	ttl := defaultClusterBackupTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		ttl = time.Until(deadline)
+	}
+	// Fences outlive the coordination until the backups are removed
+	ttl += unfenceTimeout
+
+	cf := &clusterFence{
+		id:      fmt.Sprintf("%d-%d", ds.opts.nodeID, time.Now().UnixNano()),
+		mbrClis: newMemberClients(ds.opts.dialMember),
+		lgr:     ds.opts.lgr,
+	}
+	for id := range urls {
+		cf.ids = append(cf.ids, id)
+	}
+	sort.Slice(cf.ids, func(i, j int) bool { return cf.ids[i] < cf.ids[j] })
+	for _, id := range cf.ids {
+		cli, err := cf.mbrClis.client(id, ds.members.dkvAddr(id))
+		var chngNum uint64
+		if err == nil {
+			cf.clis = append(cf.clis, cli)
+			chngNum, err = cli.FenceWritesWithCtx(ctx, cf.id, ttl)
+		}
+		if err != nil {
+			cf.lift(false)
+			return nil, fmt.Errorf("unable to fence the writes onto node: %d: %w", id, err)
+		}
+		cf.chngNums = append(cf.chngNums, chngNum)
+	}
+	return cf, nil
+}
+
+// each invokes the given function concurrently for every member, by
+// its index, and returns the error of the first member that fails.
+func (cf *clusterFence) each(do func(i int, cli *ctl.DKVClient) error) error {
+	errs := make([]error, len(cf.clis))
+	var wg sync.WaitGroup
+	for i, cli := range cf.clis {
+		wg.Add(1)
+		go func(i int, cli *ctl.DKVClient) {
+			defer wg.Done()
+			errs[i] = do(i, cli)
+		}(i, cli)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("node: %d failed: %w", cf.ids[i], err)
+		}
+	}
+	return nil
+}
+
+// lift lifts the fence from every member that it was raised onto,
+// along with removing the backups written under it if asked to.
+func (cf *clusterFence) lift(removeBckps bool) {
+	defer cf.mbrClis.close()
+	for i, cli := range cf.clis {
+		ctx, cancel := context.WithTimeout(context.Background(), unfenceTimeout)
+		if err := cli.UnfenceWritesWithCtx(ctx, cf.id, removeBckps); err != nil {
+			cf.lgr.Warn("Unable to unfence the writes", zap.Uint64("nodeID", cf.ids[i]), zap.String("fenceID", cf.id), zap.Error(err))
+		}
+		cancel()
+	}
+}
+
+func (ds *distributedService) FenceWrites(ctx context.Context, req *serverpb.FenceWritesRequest) (*serverpb.FenceWritesResponse, error) {
+	chngNum, err := ds.fenceWrites(req.FenceId, time.Duration(req.TtlSecs)*time.Second)
+	if err != nil {
+		ds.opts.lgr.Error("Unable to fence the writes", zap.String("fenceID", req.FenceId), zap.Error(err))
+		return &serverpb.FenceWritesResponse{Status: newErrorStatus(err)}, nil
+	}
+	ds.opts.lgr.Info("Fenced the writes", zap.String("fenceID", req.FenceId), zap.Uint64("changeNumber", chngNum))
+	return &serverpb.FenceWritesResponse{Status: newEmptyStatus(), ChangeNumber: chngNum}, nil
+}
+
+func (ds *distributedService) fenceWrites(id string, ttl time.Duration) (uint64, error) {
+	if id == "" || ttl <= 0 {
+		return 0, fmt.Errorf("fence must be given an ID and a TTL: %w", dkverrors.ErrInvalidArgument)
+	}
+	if err := ds.fence.raise(id, ttl); err != nil {
+		return 0, err
+	}
+	chngNum, err := ds.changeNumber()
+	if err != nil {
+		ds.fence.lower(id)
+	}
+	return chngNum, err
+}
+
+func (ds *distributedService) UnfenceWrites(ctx context.Context, req *serverpb.UnfenceWritesRequest) (*serverpb.Status, error) {
+	bckpLocs := ds.fence.lower(req.FenceId)
+	ds.opts.lgr.Info("Unfenced the writes", zap.String("fenceID", req.FenceId))
+	if !req.RemoveBackups {
+		return newEmptyStatus(), nil
+	}
+	for _, bckpLoc := range bckpLocs {
+		if err := ds.opts.bckpTrnsfr.Remove(ctx, bckpLoc); err != nil {
+			ds.opts.lgr.Error("Unable to remove the backup", zap.String("fenceID", req.FenceId), zap.String("location", bckpLoc), zap.Error(err))
+			return newErrorStatus(err), nil
+		}
+	}
+	return newEmptyStatus(), nil
+}
+
+func (ds *distributedService) BackupMember(ctx context.Context, req *serverpb.BackupMemberRequest) (*serverpb.BackupMemberResponse, error) {
+	info, err := ds.backupMember(ctx, req)
+	if err != nil {
+		ds.opts.lgr.Error("Unable to backup as of the change number", zap.String("fenceID", req.FenceId), zap.Uint64("changeNumber", req.ChangeNumber), zap.String("location", req.Location), zap.Error(err))
+		return &serverpb.BackupMemberResponse{Status: newErrorStatus(err)}, nil
+	}
+	ds.opts.lgr.Info("Backed up as of the change number", zap.String("fenceID", req.FenceId), zap.Uint64("changeNumber", req.ChangeNumber), zap.String("location", req.Location))
+	return &serverpb.BackupMemberResponse{Status: newEmptyStatus(), Info: info}, nil
+}
+
+// backupMember backs up the local node once it catches up with the
+// change number of the given request, and verifies the backup.
+func (ds *distributedService) backupMember(ctx context.Context, req *serverpb.BackupMemberRequest) (*serverpb.BackupInfo, error) {
+	if err := ds.fence.check(req.FenceId); err != nil {
+		return nil, err
+	}
+	if err := ds.awaitChangeNumber(ctx, req.ChangeNumber); err != nil {
+		return nil, err
+	}
+	job, err := ds.local.bckpJobs.begin(&backupJob{path: req.Location})
+	if err != nil {
+		return nil, err
+	}
+	err = ds.opts.bckpTrnsfr.Backup(ctx, req.Location, func(path string) error {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("backup already exists at %s", path)
+		}
+		if err := ds.fence.wrote(req.FenceId, req.Location); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		job.writingTo(path)
+		defer job.writingTo("")
+		if err := ds.local.br.BackupTo(path); err != nil {
+			return err
+		}
+		return ds.local.verifyBackup(job, path)
+	})
+	ds.local.bckpJobs.end(job, err)
+	if err != nil {
+		return nil, err
+	}
+	info := job.status().BackupInfo
+	if info.ChangeNumber != req.ChangeNumber {
+		return nil, fmt.Errorf("backup is as of change number: %d instead of: %d", info.ChangeNumber, req.ChangeNumber)
+	}
+	return info, nil
+}
+
+func (ds *distributedService) RestoreMember(ctx context.Context, req *serverpb.RestoreMemberRequest) (*serverpb.Status, error) {
+	if err := ds.restoreMember(ctx, req); err != nil {
+		ds.opts.lgr.Error("Unable to restore as of the change number", zap.String("fenceID", req.FenceId), zap.Uint64("changeNumber", req.ChangeNumber), zap.String("location", req.Location), zap.Error(err))
+		return newErrorStatus(err), nil
+	}
+	ds.opts.lgr.Info("Restored as of the change number", zap.String("fenceID", req.FenceId), zap.Uint64("changeNumber", req.ChangeNumber), zap.String("location", req.Location))
+	return newEmptyStatus(), nil
+}
+
+// restoreMember restores the local node, which must be fresh, from the
+// backup of the given request once it is verified to be as of the
+// change number of the request.
+func (ds *distributedService) restoreMember(ctx context.Context, req *serverpb.RestoreMemberRequest) (err error) {
+	if err = ds.fence.check(req.FenceId); err != nil {
+		return err
+	}
+	chngNum, err := ds.changeNumber()
+	if err != nil {
+		return err
+	}
+	if chngNum != 0 {
+		return fmt.Errorf("node is not fresh since its change number is: %d: %w", chngNum, dkverrors.ErrInvalidArgument)
+	}
+	job, err := ds.local.bckpJobs.begin(&backupJob{restore: true, path: req.Location})
+	if err != nil {
+		return err
+	}
+	defer func() { ds.local.bckpJobs.end(job, err) }()
+	return ds.opts.bckpTrnsfr.Restore(ctx, req.Location, func(path string) error {
+		if err := ds.local.verifyBackup(job, path); err != nil {
+			return err
+		}
+		if bckpChngNum := job.status().BackupInfo.ChangeNumber; bckpChngNum != req.ChangeNumber {
+			return fmt.Errorf("backup is as of change number: %d instead of: %d: %w", bckpChngNum, req.ChangeNumber, dkverrors.ErrInvalidArgument)
+		}
+		return storage.RestoreFrom(ds.local.br, path, ds.opts.rstrPar, &job.progress)
+	})
+}
+
+// changeNumber returns the latest committed change number of the local
+// node, which must be tracked by its storage engine.
+func (ds *distributedService) changeNumber() (uint64, error) {
+	if ds.local.cp == nil {
+		return 0, errors.New("storage engine does not track change numbers, hence cannot be backed up with the cluster")
+	}
+	return ds.local.cp.GetLatestCommittedChangeNumber()
+}
+
+// awaitChangeNumber waits for the local node to catch up with the
+// given change number, which it must not go past.
+func (ds *distributedService) awaitChangeNumber(ctx context.Context, targetChngNum uint64) error {
+	for {
+		chngNum, err := ds.changeNumber()
+		switch {
+		case err != nil:
+			return err
+		case chngNum == targetChngNum:
+			return nil
+		case chngNum > targetChngNum:
+			return fmt.Errorf("change number: %d is past the change number: %d of the backup", chngNum, targetChngNum)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("change number: %d has not caught up with: %d: %w", chngNum, targetChngNum, ctx.Err())
+		case <-time.After(clusterBackupPollInterval):
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+)
+
+func TestClusterBackupRestore(t *testing.T) {
+	bckpDir, err := ioutil.TempDir("", "dkv-cluster-backup-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(bckpDir)
+	loc := filepath.Join(bckpDir, "bckp")
+
+	group := newRaftGroup(t)
+	defer group.close()
+	client, err := ctl.NewInSecureDKVClient(group.dkvAddrs[0], ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for i := 1; i <= 5; i++ {
+		if err = client.Put([]byte(fmt.Sprintf("K%d", i)), []byte("V")); err != nil {
+			t.Fatalf("Unable to PUT. Error: %v", err)
+		}
+	}
+
+	// Writes are rejected while fenced
+	if _, err = client.FenceWritesWithCtx(context.Background(), "fence", time.Minute); err != nil {
+		t.Fatalf("Unable to fence the writes. Error: %v", err)
+	}
+	if err = client.Put([]byte("K6"), []byte("V")); !errors.Is(err, dkverrors.ErrBackupInProgress) {
+		t.Errorf("Expected error: %v for a fenced write. Actual: %v", dkverrors.ErrBackupInProgress, err)
+	}
+	if _, err = client.ClusterBackup(loc); !errors.Is(err, dkverrors.ErrBackupInProgress) {
+		t.Errorf("Expected error: %v while the writes are fenced. Actual: %v", dkverrors.ErrBackupInProgress, err)
+	}
+	if err = client.UnfenceWritesWithCtx(context.Background(), "fence", false); err != nil {
+		t.Fatalf("Unable to unfence the writes. Error: %v", err)
+	}
+
+	// Fails if any member cannot back up, without leaving any of the
+	// backups written by the other members behind
+	if err = os.MkdirAll(loc, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(loc, "node-3"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.ClusterBackup(loc); err == nil {
+		t.Fatal("Expected the cluster backup to fail while a backup of a member exists")
+	}
+	if names, _ := ioutil.ReadDir(loc); len(names) != 1 || names[0].Name() != "node-3" {
+		t.Errorf("Expected the backups of the failed cluster backup to be removed. Found: %d", len(names))
+	}
+	if err = os.Remove(filepath.Join(loc, "node-3")); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Put([]byte("K6"), []byte("V")); err != nil {
+		t.Errorf("Expected the writes to be unfenced. Error: %v", err)
+	}
+
+	// Succeeds once the lagging member catches up
+	group.mu.Lock()
+	group.laggingID = 3
+	group.mu.Unlock()
+	if err = client.Put([]byte("K7"), []byte("V")); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+	follower, err := ctl.NewInSecureDKVClient(group.dkvAddrs[1], ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer follower.Close()
+	if _, err = follower.ClusterBackup(loc); !errors.Is(err, dkverrors.ErrNotLeader) {
+		t.Errorf("Expected error: %v for a cluster backup on a follower. Actual: %v", dkverrors.ErrNotLeader, err)
+	}
+	go func() {
+		<-time.After(200 * time.Millisecond)
+		group.catchUp()
+	}()
+	mnfst, err := client.ClusterBackup(loc)
+	if err != nil {
+		t.Fatalf("Unable to backup the cluster. Error: %v", err)
+	}
+	if mnfst.ChangeNumber != 7 || len(mnfst.Artifacts) != clusterSize {
+		t.Fatalf("Manifest mismatch. Change number: %d, Artifacts: %d", mnfst.ChangeNumber, len(mnfst.Artifacts))
+	}
+	for i, artf := range mnfst.Artifacts {
+		if artf.NodeId != uint32(i+1) || artf.Info.GetChangeNumber() != mnfst.ChangeNumber {
+			t.Errorf("Artifact mismatch. Node: %d, Change number: %d", artf.NodeId, artf.Info.GetChangeNumber())
+		}
+	}
+
+	// Restores onto a fresh cluster alone
+	freshGroup := newRaftGroup(t)
+	defer freshGroup.close()
+	freshClient, err := ctl.NewInSecureDKVClient(freshGroup.dkvAddrs[0], ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freshClient.Close()
+	if err = freshClient.ClusterRestore(filepath.Join(bckpDir, "missing")); err == nil {
+		t.Error("Expected an error for a cluster restore from a missing backup")
+	}
+	if err = freshClient.ClusterRestore(loc); err != nil {
+		t.Fatalf("Unable to restore the cluster. Error: %v", err)
+	}
+	for id, store := range freshGroup.stores {
+		if vals, found, err := store.Get([]byte("K1"), []byte("K7")); err != nil || !found[0] || !found[1] || string(vals[1]) != "V" {
+			t.Errorf("Expected K1 and K7 to be restored onto node: %d. Values: %q, Error: %v", id, vals, err)
+		}
+	}
+	if err = freshClient.Put([]byte("K8"), []byte("V")); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+	if err = freshClient.ClusterRestore(loc); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected error: %v for a cluster restore onto a cluster that is not fresh. Actual: %v", dkverrors.ErrInvalidArgument, err)
+	}
+}
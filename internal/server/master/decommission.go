@@ -0,0 +1,266 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+)
+
+// A leadershipTransferrer is implemented by the RAFT replicators
+// that can transfer the leadership of their cluster onto another
+// member.
+type leadershipTransferrer interface {
+	TransferLeadership(ctx context.Context, nodeID uint64) error
+}
+
+// Default duration within which the leadership must be transferred
+// away from a node being decommissioned, and the remaining members
+// must catch up with its changes.
+const defaultDecommissionTimeout = 10 * time.Minute
+
+// Interval at which the leadership of the cluster and the change
+// numbers of the members are polled while decommissioning a node.
+const decommissionPollInterval = 500 * time.Millisecond
+
+// decommissions runs the decommissions of the nodes in the background,
+// one at a time, and retains the status of the latest decommission of
+// every node.
+type decommissions struct {
+	mu      sync.Mutex
+	jobs    map[uint64]*decommissionJob
+	running *decommissionJob
+	// Cancelled when the DKVService is closed
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type decommissionJob struct {
+	nodeID         uint64
+	mu             sync.Mutex
+	state          serverpb.DecommissionState
+	err            error
+	targetChngNum  uint64
+	memberChngNums map[uint32]uint64
+}
+
+func newDecommissions() *decommissions {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &decommissions{jobs: make(map[uint64]*decommissionJob), ctx: ctx, cancel: cancel}
+}
+
+// start begins decommissioning the given node in the background using
+// the given function, unless another node is being decommissioned.
+func (dc *decommissions) start(nodeID uint64, run func(context.Context, *decommissionJob) error) error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	if dc.running != nil {
+		return fmt.Errorf("node: %d is being decommissioned: %w", dc.running.nodeID, dkverrors.ErrInvalidArgument)
+	}
+	job := &decommissionJob{nodeID: nodeID, state: serverpb.DecommissionState_TransferringLeadership}
+	dc.jobs[nodeID], dc.running = job, job
+	go func() {
+		err := run(dc.ctx, job)
+		dc.mu.Lock()
+		defer dc.mu.Unlock()
+		job.complete(err)
+		dc.running = nil
+	}()
+	return nil
+}
+
+func (dc *decommissions) get(nodeID uint64) (*decommissionJob, error) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	job, present := dc.jobs[nodeID]
+	if !present {
+		return nil, fmt.Errorf("node: %d has never been decommissioned: %w", nodeID, dkverrors.ErrInvalidArgument)
+	}
+	return job, nil
+}
+
+func (dc *decommissions) close() {
+	dc.cancel()
+}
+
+func (job *decommissionJob) progress(state serverpb.DecommissionState) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.state = state
+}
+
+// observed records the change numbers of the node being decommissioned
+// and of the remaining members.
+func (job *decommissionJob) observed(targetChngNum uint64, memberChngNums map[uint32]uint64) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.targetChngNum, job.memberChngNums = targetChngNum, memberChngNums
+}
+
+func (job *decommissionJob) complete(err error) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.state, job.err = serverpb.DecommissionState_Decommissioned, err
+	if err != nil {
+		job.state = serverpb.DecommissionState_DecommissionFailed
+	}
+}
+
+func (job *decommissionJob) status() *serverpb.GetDecommissionStatusResponse {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	res := &serverpb.GetDecommissionStatusResponse{
+		Status:              newEmptyStatus(),
+		NodeId:              uint32(job.nodeID),
+		State:               job.state,
+		TargetChangeNumber:  job.targetChngNum,
+		MemberChangeNumbers: job.memberChngNums,
+	}
+	if job.err != nil {
+		res.Error = job.err.Error()
+	}
+	return res
+}
+
+func (ds *distributedService) DecommissionNode(ctx context.Context, req *serverpb.DecommissionNodeRequest) (*serverpb.Status, error) {
+	nodeID := uint64(req.NodeId)
+	_, urls, err := ds.members.members(ds.raftRepl)
+	switch {
+	case err != nil:
+	case urls[nodeID] == "":
+		err = fmt.Errorf("node: %d is not a member of the cluster: %w", nodeID, dkverrors.ErrInvalidArgument)
+	case len(urls) == 1:
+		err = fmt.Errorf("node: %d is the only member of the cluster: %w", nodeID, dkverrors.ErrInvalidArgument)
+	default:
+		timeout := defaultDecommissionTimeout
+		if req.TimeoutSecs > 0 {
+			timeout = time.Duration(req.TimeoutSecs) * time.Second
+		}
+		err = ds.decoms.start(nodeID, func(ctx context.Context, job *decommissionJob) error {
+			return ds.decommission(ctx, job, timeout)
+		})
+	}
+	if err != nil {
+		ds.opts.lgr.Error("Unable to decommission node", zap.Uint32("nodeID", req.NodeId), zap.Error(err))
+		return newErrorStatus(err), nil
+	}
+	ds.opts.lgr.Info("Decommissioning node", zap.Uint32("nodeID", req.NodeId))
+	return newEmptyStatus(), nil
+}
+
+func (ds *distributedService) GetDecommissionStatus(ctx context.Context, req *serverpb.GetDecommissionStatusRequest) (*serverpb.GetDecommissionStatusResponse, error) {
+	job, err := ds.decoms.get(uint64(req.NodeId))
+	if err != nil {
+		return &serverpb.GetDecommissionStatusResponse{Status: newErrorStatus(err)}, nil
+	}
+	return job.status(), nil
+}
+
+// decommission transfers the leadership away from the node of the given
+// job if it leads the cluster, waits for the remaining members to catch
+// up with the changes of the node and then removes it from the cluster.
+// The node remains a member if any of these steps fail.
+func (ds *distributedService) decommission(ctx context.Context, job *decommissionJob, timeout time.Duration) error {
+	mbrClis := newMemberClients(ds.opts.dialMember)
+	defer mbrClis.close()
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	leaderID, urls, err := ds.members.members(ds.raftRepl)
+	if err != nil {
+		return err
+	}
+	var remIDs []uint64
+	for id := range urls {
+		if id != job.nodeID {
+			remIDs = append(remIDs, id)
+		}
+	}
+	sort.Slice(remIDs, func(i, j int) bool { return remIDs[i] < remIDs[j] })
+	if leaderID == job.nodeID {
+		if err = ds.transferLeadership(waitCtx, job, remIDs, mbrClis); err != nil {
+			return err
+		}
+	}
+
+	job.progress(serverpb.DecommissionState_CatchingUp)
+	if err = ds.awaitCatchUp(waitCtx, job, remIDs, mbrClis); err != nil {
+		return err
+	}
+
+	job.progress(serverpb.DecommissionState_Removing)
+	if err = ds.raftRepl.RemoveMember(ctx, int(job.nodeID)); err != nil {
+		return err
+	}
+	ds.members.remove(job.nodeID)
+	ds.opts.lgr.Info("Decommissioned node", zap.Uint64("nodeID", job.nodeID))
+	return nil
+}
+
+// transferLeadership transfers the leadership onto the most up to date
+// of the given remaining members and waits for it to take over.
+func (ds *distributedService) transferLeadership(ctx context.Context, job *decommissionJob, remIDs []uint64, mbrClis *memberClients) error {
+	lt, ok := ds.raftRepl.(leadershipTransferrer)
+	if !ok {
+		return fmt.Errorf("RAFT replicator cannot transfer the leadership away from node: %d", job.nodeID)
+	}
+	succID, succChngNum := uint64(0), uint64(0)
+	for _, id := range remIDs {
+		if chngNum, err := mbrClis.changeNumber(ctx, id, ds.members.dkvAddr(id)); err == nil && (succID == 0 || chngNum > succChngNum) {
+			succID, succChngNum = id, chngNum
+		}
+	}
+	if succID == 0 {
+		return fmt.Errorf("none of the remaining members can take over the leadership from node: %d", job.nodeID)
+	}
+	if err := lt.TransferLeadership(ctx, succID); err != nil {
+		return err
+	}
+	for {
+		if leaderID, _, err := ds.members.members(ds.raftRepl); err == nil && leaderID != 0 && leaderID != job.nodeID {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("leadership is not transferred onto node: %d: %w", succID, ctx.Err())
+		case <-time.After(decommissionPollInterval):
+		}
+	}
+}
+
+// awaitCatchUp waits for the given remaining members to catch up with
+// the change number of the node being decommissioned, as of when the
+// wait begins. Members that cannot be reached are retried until the
+// given context is done.
+func (ds *distributedService) awaitCatchUp(ctx context.Context, job *decommissionJob, remIDs []uint64, mbrClis *memberClients) error {
+	targetChngNum, err := mbrClis.changeNumber(ctx, job.nodeID, ds.members.dkvAddr(job.nodeID))
+	if err != nil {
+		return err
+	}
+	for {
+		caughtUp, mbrChngNums := true, make(map[uint32]uint64, len(remIDs))
+		for _, id := range remIDs {
+			dkvAddr := ds.members.dkvAddr(id)
+			chngNum, err := mbrClis.changeNumber(ctx, id, dkvAddr)
+			if dkvAddr == "" {
+				return err
+			}
+			mbrChngNums[uint32(id)] = chngNum
+			caughtUp = caughtUp && err == nil && chngNum >= targetChngNum
+		}
+		job.observed(targetChngNum, mbrChngNums)
+		if caughtUp {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("remaining members have not caught up with change number: %d: %w", targetChngNum, ctx.Err())
+		case <-time.After(decommissionPollInterval):
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+func TestDecommissionNode(t *testing.T) {
+	group := newRaftGroup(t)
+	defer group.close()
+	client, err := ctl.NewInSecureDKVClient(group.dkvAddrs[0], ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	group.mu.Lock()
+	group.laggingID = 3
+	group.mu.Unlock()
+	for i := 1; i <= 5; i++ {
+		if err = client.Put([]byte(fmt.Sprintf("K%d", i)), []byte("V")); err != nil {
+			t.Fatalf("Unable to PUT. Error: %v", err)
+		}
+	}
+
+	if err = client.DecommissionNode(7, 0); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected error: %v for an unknown node. Actual: %v", dkverrors.ErrInvalidArgument, err)
+	}
+	// Fails while the lagging member does not catch up
+	if err = client.DecommissionNode(1, time.Second); err != nil {
+		t.Fatalf("Unable to decommission node. Error: %v", err)
+	}
+	if err = client.DecommissionNode(2, 0); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected error: %v while another node is decommissioned. Actual: %v", dkverrors.ErrInvalidArgument, err)
+	}
+	if err = client.WaitForDecommission(context.Background(), 1, nil); err == nil {
+		t.Fatal("Expected the decommission to fail while a member lags behind")
+	}
+	group.mu.Lock()
+	leaderID, numMembers := group.leaderID, len(group.members)
+	group.mu.Unlock()
+	// Leadership is taken over by the up to date member
+	if leaderID != 2 || numMembers != clusterSize {
+		t.Errorf("Expected the leadership to be transferred while retaining the node. Leader: %d, Members: %d", leaderID, numMembers)
+	}
+
+	// Succeeds once the lagging member catches up
+	if err = client.DecommissionNode(1, 0); err != nil {
+		t.Fatalf("Unable to decommission node. Error: %v", err)
+	}
+	catchingUp := make(chan struct{})
+	go func() {
+		<-catchingUp
+		group.catchUp()
+	}()
+	err = client.WaitForDecommission(context.Background(), 1, func(res *serverpb.GetDecommissionStatusResponse) {
+		if res.State == serverpb.DecommissionState_CatchingUp && res.MemberChangeNumbers[3] < res.TargetChangeNumber && res.MemberChangeNumbers[2] >= res.TargetChangeNumber {
+			select {
+			case <-catchingUp:
+			default:
+				close(catchingUp)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("Unable to decommission node. Error: %v", err)
+	}
+	group.mu.Lock()
+	_, present := group.members[1]
+	group.mu.Unlock()
+	if present {
+		t.Error("Expected the node to be removed from the cluster")
+	}
+	if _, err = client.DecommissionStatus(2); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected error: %v for a node never decommissioned. Actual: %v", dkverrors.ErrInvalidArgument, err)
+	}
+}
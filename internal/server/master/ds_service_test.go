@@ -2,9 +2,13 @@ package master
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os/exec"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -12,12 +16,18 @@ import (
 	"github.com/flipkart-incubator/dkv/internal/ctl"
 	"github.com/flipkart-incubator/dkv/internal/server/storage"
 	"github.com/flipkart-incubator/dkv/internal/server/storage/badger"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
 	"github.com/flipkart-incubator/dkv/internal/server/storage/rocksdb"
 	dkv_sync "github.com/flipkart-incubator/dkv/internal/server/sync"
+	"github.com/flipkart-incubator/dkv/internal/server/sync/raftpb"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
 	nexus_api "github.com/flipkart-incubator/nexus/pkg/api"
 	nexus "github.com/flipkart-incubator/nexus/pkg/raft"
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 const (
@@ -51,6 +61,489 @@ func TestDistributedService(t *testing.T) {
 	t.Run("testNewDKVNodeJoiningAndLeaving", testNewDKVNodeJoiningAndLeaving)
 }
 
+// leadingReplicator is a RAFT replicator that only
+// reports whether it leads its cluster
+type leadingReplicator struct {
+	nexus_api.RaftReplicator
+	leader bool
+}
+
+func (lr *leadingReplicator) IsLeader() bool { return lr.leader }
+func (lr *leadingReplicator) Stop()          {}
+
+func TestDistributedServiceReportsHealth(t *testing.T) {
+	store := memory.OpenDB(0)
+	defer store.Close()
+	raftRepl := &leadingReplicator{leader: true}
+	svc := NewDistributedService(store, store, store, raftRepl)
+	checkServingStatus(t, svc, grpc_health_v1.HealthCheckResponse_SERVING, "leader")
+	raftRepl.leader = false
+	checkServingStatus(t, svc, grpc_health_v1.HealthCheckResponse_NOT_SERVING, "follower")
+	raftRepl.leader = true
+	svc.Close()
+	checkServingStatus(t, svc, grpc_health_v1.HealthCheckResponse_NOT_SERVING, "closed service")
+}
+
+// membersReplicator is a RAFT replicator that
+// lists the members of its cluster
+type membersReplicator struct {
+	nexus_api.RaftReplicator
+	leaderID uint64
+	members  map[uint64]string
+}
+
+func (mr *membersReplicator) ListMembers() (uint64, map[uint64]string, error) {
+	return mr.leaderID, mr.members, nil
+}
+func (mr *membersReplicator) Stop() {}
+
+func (lr *leadingReplicator) AddMember(context.Context, int, string) error { return nil }
+func (lr *leadingReplicator) RemoveMember(context.Context, int) error      { return nil }
+
+// resultReplicator is a RAFT replicator that
+// replies to every request with the same result
+type resultReplicator struct {
+	nexus_api.RaftReplicator
+	res []byte
+}
+
+func (rr *resultReplicator) Replicate(context.Context, []byte) ([]byte, error) { return rr.res, nil }
+func (rr *resultReplicator) Stop()                                             {}
+
+func TestDistributedIncrementRejectsMalformedResults(t *testing.T) {
+	store := memory.OpenDB(0)
+	defer store.Close()
+	svc := NewDistributedService(store, store, store, &resultReplicator{res: []byte("Short")})
+	defer svc.Close()
+	res, err := svc.Increment(context.Background(), &serverpb.IncrementRequest{Key: []byte("IncrKey"), Delta: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status.Code == 0 || res.Value != 0 || !strings.Contains(res.Status.Message, dkverrors.ErrMalformedResponse.Error()) {
+		t.Errorf("Expected the result of 5 bytes to be rejected. Status: %v, Value: %d", res.Status, res.Value)
+	}
+}
+
+func TestDistributedServiceListsNodes(t *testing.T) {
+	store := memory.OpenDB(0)
+	defer store.Close()
+	probed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != raftProbingPath {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer probed.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	defer unreachable.Close()
+
+	raftRepl := &membersReplicator{leaderID: 2, members: map[uint64]string{1: "http://local", 2: probed.URL, 3: unreachable.URL}}
+	svc := NewDistributedService(store, store, store, raftRepl, WithClusterNodes(1, nil), WithClusterAddrs([]string{"localhost:8081", "localhost:8082"}))
+	defer svc.Close()
+	checkNodes(t, svc, 2, map[uint32]serverpb.NodeRole{1: serverpb.NodeRole_Follower, 2: serverpb.NodeRole_Leader, 3: serverpb.NodeRole_Follower}, 3)
+	if res, _ := svc.ListNodes(context.Background(), &serverpb.ListNodesRequest{}); res.Nodes[1].DkvAddr != "localhost:8082" || res.Nodes[2].DkvAddr != "" {
+		t.Errorf("Expected the DKV addresses of only the given nodes. Actual: %v", res.Nodes)
+	}
+
+	// Falls back onto the members tracked by the service
+	lr := &leadingReplicator{leader: true}
+	svc = NewDistributedService(store, store, store, lr, WithClusterNodes(2, []string{unreachable.URL, "http://local"}))
+	defer svc.Close()
+	checkNodes(t, svc, 2, map[uint32]serverpb.NodeRole{1: serverpb.NodeRole_Follower, 2: serverpb.NodeRole_Leader}, 1)
+	if res, _ := svc.AddNode(context.Background(), &serverpb.AddNodeRequest{NodeId: 3, NodeUrl: probed.URL}); res.Code != 0 {
+		t.Fatalf("Unable to add node. Error: %s", res.Message)
+	}
+	if res, _ := svc.RemoveNode(context.Background(), &serverpb.RemoveNodeRequest{NodeId: 1}); res.Code != 0 {
+		t.Fatalf("Unable to remove node. Error: %s", res.Message)
+	}
+	checkNodes(t, svc, 2, map[uint32]serverpb.NodeRole{2: serverpb.NodeRole_Leader, 3: serverpb.NodeRole_Follower}, 0)
+	lr.leader = false
+	checkNodes(t, svc, 0, map[uint32]serverpb.NodeRole{2: serverpb.NodeRole_UnknownRole, 3: serverpb.NodeRole_UnknownRole}, 0)
+}
+
+// checkNodes checks the nodes listed by the given service, of which only
+// the given unreachable node, if any, must never have been contacted.
+func checkNodes(t *testing.T, svc DKVClusterService, expLeaderID uint32, expRoles map[uint32]serverpb.NodeRole, unreachableID uint32) {
+	res, err := svc.ListNodes(context.Background(), &serverpb.ListNodesRequest{})
+	if err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to list nodes. Error: %v, Status: %v", err, res.GetStatus())
+	}
+	if res.LeaderId != expLeaderID {
+		t.Errorf("Leader mismatch. Expected: %d, Actual: %d", expLeaderID, res.LeaderId)
+	}
+	if len(res.Nodes) != len(expRoles) {
+		t.Fatalf("Expected %d nodes. Actual: %v", len(expRoles), res.Nodes)
+	}
+	for i, node := range res.Nodes {
+		if i > 0 && res.Nodes[i-1].NodeId >= node.NodeId {
+			t.Errorf("Expected the nodes in the order of their IDs. Actual: %v", res.Nodes)
+		}
+		if node.Role != expRoles[node.NodeId] {
+			t.Errorf("Role mismatch for node: %d. Expected: %s, Actual: %s", node.NodeId, expRoles[node.NodeId], node.Role)
+		}
+		if contacted := node.LastContactTS > 0; contacted == (node.NodeId == unreachableID) {
+			t.Errorf("Last contact mismatch for node: %d. Actual: %d", node.NodeId, node.LastContactTS)
+		}
+	}
+}
+
+// raftGroup is an in-process RAFT group whose leader alone replicates
+// the changes onto the stores of all the members, while the others
+// reject them, unlike Nexus whose followers forward them to the leader
+type raftGroup struct {
+	mu       sync.Mutex
+	leaderID uint64
+	members  map[uint64]string
+	stores   map[uint64]storage.KVStore
+	// Changes withheld from the lagging member until it catches up
+	laggingID uint64
+	backlog   [][]byte
+	dkvAddrs  []string
+	closers   []func()
+}
+
+// newRaftGroup serves the DKV services of the members of a new group,
+// of which the first one leads, each with the options made for it
+func newRaftGroup(t *testing.T, memberOpts ...func(id uint64, store storage.KVStore) DKVServiceOption) *raftGroup {
+	group := &raftGroup{leaderID: 1, members: make(map[uint64]string), stores: make(map[uint64]storage.KVStore)}
+	var lises []net.Listener
+	for id := 1; id <= clusterSize; id++ {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		lises, group.dkvAddrs = append(lises, lis), append(group.dkvAddrs, lis.Addr().String())
+		group.members[uint64(id)] = fmt.Sprintf("http://node%d", id)
+	}
+	for id := 1; id <= clusterSize; id++ {
+		store := memory.OpenDB(0)
+		group.stores[uint64(id)] = store
+		opts := []DKVServiceOption{WithClusterAddrs(group.dkvAddrs)}
+		for _, memberOpt := range memberOpts {
+			opts = append(opts, memberOpt(uint64(id), store))
+		}
+		svc := NewDistributedService(store, store, store, &groupReplicator{group: group, id: uint64(id)}, opts...)
+		grpcSrv := grpc.NewServer()
+		serverpb.RegisterDKVServer(grpcSrv, svc)
+		serverpb.RegisterDKVReplicationServer(grpcSrv, svc)
+		serverpb.RegisterDKVClusterServer(grpcSrv, svc)
+		go grpcSrv.Serve(lises[id-1])
+		group.closers = append(group.closers, grpcSrv.Stop, func() { svc.Close() }, func() { store.Close() })
+	}
+	return group
+}
+
+func (rg *raftGroup) catchUp() {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+	for _, req := range rg.backlog {
+		dkv_sync.NewDKVReplStore(rg.stores[rg.laggingID]).Save(req)
+	}
+	rg.laggingID, rg.backlog = 0, nil
+}
+
+func (rg *raftGroup) close() {
+	for _, closer := range rg.closers {
+		closer()
+	}
+}
+
+type groupReplicator struct {
+	nexus_api.RaftReplicator
+	group *raftGroup
+	id    uint64
+}
+
+func (gr *groupReplicator) Replicate(ctx context.Context, req []byte) (res []byte, err error) {
+	gr.group.mu.Lock()
+	defer gr.group.mu.Unlock()
+	if gr.group.leaderID != gr.id {
+		return nil, fmt.Errorf("node: %d dropped the proposal", gr.id)
+	}
+	for id, kvs := range gr.group.stores {
+		if id == gr.group.laggingID {
+			gr.group.backlog = append(gr.group.backlog, req)
+		} else if res, err = dkv_sync.NewDKVReplStore(kvs).Save(req); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (gr *groupReplicator) IsLeader() bool {
+	gr.group.mu.Lock()
+	defer gr.group.mu.Unlock()
+	return gr.group.leaderID == gr.id
+}
+
+func (gr *groupReplicator) ListMembers() (uint64, map[uint64]string, error) {
+	gr.group.mu.Lock()
+	defer gr.group.mu.Unlock()
+	members := make(map[uint64]string, len(gr.group.members))
+	for id, nodeURL := range gr.group.members {
+		members[id] = nodeURL
+	}
+	return gr.group.leaderID, members, nil
+}
+
+func (gr *groupReplicator) TransferLeadership(ctx context.Context, nodeID uint64) error {
+	gr.group.mu.Lock()
+	defer gr.group.mu.Unlock()
+	if gr.group.leaderID != gr.id {
+		return fmt.Errorf("node: %d is not the leader", gr.id)
+	}
+	gr.group.leaderID = nodeID
+	return nil
+}
+
+func (gr *groupReplicator) RemoveMember(ctx context.Context, nodeID int) error {
+	gr.group.mu.Lock()
+	defer gr.group.mu.Unlock()
+	delete(gr.group.members, uint64(nodeID))
+	return nil
+}
+
+func (gr *groupReplicator) Stop() {}
+
+func TestDistributedServiceHintsLeader(t *testing.T) {
+	group := newRaftGroup(t)
+	defer group.close()
+	dkvAddrs := group.dkvAddrs
+
+	// Follower rejects with a hint of the leader
+	client, err := ctl.NewInSecureDKVClient(dkvAddrs[2], ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	err = client.Put([]byte("K1"), []byte("V1"))
+	if !errors.Is(err, dkverrors.ErrNotLeader) {
+		t.Fatalf("Expected error: %v. Actual: %v", dkverrors.ErrNotLeader, err)
+	}
+	if leader := dkverrors.LeaderHint(err); leader.GetNodeId() != 1 || leader.GetDkvAddr() != dkvAddrs[0] {
+		t.Errorf("Leader hint mismatch. Expected: %s, Actual: %v", dkvAddrs[0], leader)
+	}
+
+	// Follower is bypassed by following the leader
+	client, err = ctl.NewInSecureDKVClient(dkvAddrs[1], ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20), ctl.WithLeaderFollowing(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err = client.Put([]byte("K2"), []byte("V2")); err != nil {
+		t.Fatalf("Unable to PUT through a follower. Error: %v", err)
+	}
+	group.mu.Lock()
+	group.leaderID = 3
+	group.mu.Unlock()
+	if _, err = client.Increment([]byte("K3"), 5); err != nil {
+		t.Fatalf("Unable to increment after the leader changed. Error: %v", err)
+	}
+	for _, store := range group.stores {
+		if vals, found, err := store.Get([]byte("K2"), []byte("K3")); err != nil || !found[0] || !found[1] || string(vals[0]) != "V2" {
+			t.Errorf("Expected K2 and K3 to be replicated. Values: %q, Error: %v", vals, err)
+		}
+	}
+}
+
+func TestDistributedServiceServesLinearizableReads(t *testing.T) {
+	group := newRaftGroup(t)
+	defer group.close()
+	group.mu.Lock()
+	group.laggingID = 3
+	group.mu.Unlock()
+
+	leader, err := ctl.NewInSecureDKVClient(group.dkvAddrs[0], ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer leader.Close()
+	if err = leader.Put([]byte("K1"), []byte("V1")); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := leader.GetLinearizable([]byte("K1")); err != nil || string(res.Value) != "V1" {
+		t.Errorf("GET mismatch for linearizable read. Value: %s, Error: %v", res.GetValue(), err)
+	}
+	if _, err = leader.GetLinearizable([]byte("K2")); !errors.Is(err, dkverrors.ErrKeyNotFound) {
+		t.Errorf("Expected error: %v. Actual: %v", dkverrors.ErrKeyNotFound, err)
+	}
+	// Sequential reads never go through RAFT
+	if _, err = leader.Get([]byte("K1")); err != nil {
+		t.Fatal(err)
+	}
+	group.mu.Lock()
+	numBarriers := 0
+	for _, req := range group.backlog {
+		intReq := new(raftpb.InternalRaftRequest)
+		if err = proto.Unmarshal(req, intReq); err == nil && intReq.Get != nil {
+			numBarriers++
+		}
+	}
+	group.mu.Unlock()
+	if numBarriers != 2 {
+		t.Errorf("Expected 2 linearizable reads to be replicated. Actual: %d", numBarriers)
+	}
+
+	// Followers serve sequential reads from their local state, while
+	// deferring linearizable reads to the leader
+	follower, err := ctl.NewInSecureDKVClient(group.dkvAddrs[1], ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer follower.Close()
+	if res, err := follower.Get([]byte("K1")); err != nil || string(res.Value) != "V1" {
+		t.Errorf("GET mismatch on follower. Value: %s, Error: %v", res.GetValue(), err)
+	}
+	_, err = follower.GetLinearizable([]byte("K1"))
+	if !errors.Is(err, dkverrors.ErrNotLeader) {
+		t.Fatalf("Expected error: %v. Actual: %v", dkverrors.ErrNotLeader, err)
+	}
+	if leader := dkverrors.LeaderHint(err); leader.GetDkvAddr() != group.dkvAddrs[0] {
+		t.Errorf("Leader hint mismatch. Expected: %s, Actual: %v", group.dkvAddrs[0], leader)
+	}
+}
+
+func TestDistributedServiceServesChangesOnLeader(t *testing.T) {
+	group := newRaftGroup(t)
+	defer group.close()
+	newClient := func(addr string) *ctl.DKVClient {
+		client, err := ctl.NewInSecureDKVClient(addr, ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	leader, follower := newClient(group.dkvAddrs[0]), newClient(group.dkvAddrs[1])
+	defer leader.Close()
+	defer follower.Close()
+	if err := leader.Put([]byte("K1"), []byte("V1")); err != nil {
+		t.Fatal(err)
+	}
+
+	expectNotLeader := func(status *serverpb.Status, call string) {
+		t.Helper()
+		if status.Code != int32(serverpb.StatusCode_NotLeader) || status.Leader.GetDkvAddr() != group.dkvAddrs[0] {
+			t.Errorf("Expected %s on the follower to fail with a hint of the leader. Status: %v", call, status)
+		}
+	}
+	res, err := follower.GetChanges(1, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectNotLeader(res.Status, "GetChanges")
+	if res.MasterChangeNumber == 0 {
+		t.Errorf("Expected the follower to report its latest change number. Response: %v", res)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chngsStrm, err := follower.StreamChangesWithCtx(ctx, 1, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err = chngsStrm.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	expectNotLeader(res.Status, "StreamChanges")
+	chkptStrm, err := follower.GetCheckpointWithCtx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chkptRes, err := chkptStrm.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectNotLeader(chkptRes.Status, "GetCheckpoint")
+
+	// Streams of the leader end once it no longer leads
+	if res, err = leader.GetChanges(1, 10, 0); err != nil || res.Status.Code != 0 || len(res.Changes) != 1 {
+		t.Fatalf("Expected the leader to serve its changes. Response: %v, Error: %v", res, err)
+	}
+	if chngsStrm, err = leader.StreamChangesWithCtx(ctx, 1, 10, 0); err != nil {
+		t.Fatal(err)
+	}
+	if res, err = chngsStrm.Recv(); err != nil || res.Status.Code != 0 || len(res.Changes) != 1 {
+		t.Fatalf("Expected the leader to stream its changes. Response: %v, Error: %v", res, err)
+	}
+	group.mu.Lock()
+	group.leaderID = 2
+	group.mu.Unlock()
+	for {
+		if res, err = chngsStrm.Recv(); err != nil {
+			t.Fatalf("Expected the stream to end with the NotLeader status. Error: %v", err)
+		}
+		if res.Status.Code != 0 {
+			break
+		}
+	}
+	if res.Status.Code != int32(serverpb.StatusCode_NotLeader) || res.Status.Leader.GetDkvAddr() != group.dkvAddrs[1] {
+		t.Errorf("Expected the stream to end with a hint of the new leader. Status: %v", res.Status)
+	}
+}
+
+func TestDistributedServiceEnforcesQuotas(t *testing.T) {
+	quotas := make(map[uint64]*storage.Quotas)
+	group := newRaftGroup(t, func(id uint64, store storage.KVStore) DKVServiceOption {
+		q, err := storage.NewQuotas(store, 0, zap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+		quotas[id] = q
+		return WithQuotas(q)
+	})
+	defer group.close()
+	newClient := func(addr string) *ctl.DKVClient {
+		client, err := ctl.NewInSecureDKVClient(addr, ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	leader, follower := newClient(group.dkvAddrs[0]), newClient(group.dkvAddrs[1])
+	defer leader.Close()
+	defer follower.Close()
+
+	// Quotas set on the leader are retained by every member
+	if err := quotas[1].Set("ns1", storage.Quota{MaxKeys: 2}); err != nil {
+		t.Fatalf("Unable to set the quota. Error: %v", err)
+	}
+	if err := quotas[2].Set("ns1", storage.Quota{MaxKeys: 1}); !errors.Is(err, dkverrors.ErrNotLeader) {
+		t.Errorf("Expected the quota to be set only through the leader. Error: %v", err)
+	}
+	if err := quotas[2].Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if usage, present := quotas[2].Get("ns1"); !present || usage.MaxKeys != 2 {
+		t.Errorf("Expected the quota to be replicated onto the follower. Actual: %+v", usage)
+	}
+
+	nsLeader, nsFollower := leader.ForNamespace("ns1"), follower.ForNamespace("ns1")
+	if err := nsFollower.Put([]byte("K1"), []byte("V1")); !errors.Is(err, dkverrors.ErrNotLeader) {
+		t.Errorf("Expected the PUT on the follower to be rejected. Error: %v", err)
+	}
+	if usage, _ := quotas[2].Get("ns1"); usage.NumKeys != 0 {
+		t.Errorf("Expected the usage reserved by the rejected PUT to be released. Actual: %+v", usage.PrefixStats)
+	}
+	for _, key := range []string{"K1", "K2"} {
+		if err := nsLeader.Put([]byte(key), []byte("V")); err != nil {
+			t.Fatalf("Unable to PUT. Key: %s, Error: %v", key, err)
+		}
+	}
+	if err := nsLeader.Put([]byte("K3"), []byte("V")); !errors.Is(err, ctl.ErrQuotaExceeded) {
+		t.Errorf("Expected the PUT beyond the quota to be rejected. Error: %v", err)
+	}
+	if updated, err := nsLeader.CompareAndSet([]byte("K1"), []byte("Wrong"), []byte("V")); err != nil || updated {
+		t.Errorf("Expected the COMPAREANDSET to not update. Updated: %v, Error: %v", updated, err)
+	}
+	if usage, _ := quotas[1].Get("ns1"); usage.NumKeys != 2 {
+		t.Errorf("Expected the usage of 2 keys. Actual: %+v", usage.PrefixStats)
+	}
+	if err := nsLeader.Delete([]byte("K1")); err != nil {
+		t.Fatalf("Unable to DELETE. Error: %v", err)
+	}
+	if err := nsLeader.Put([]byte("K3"), []byte("V")); err != nil {
+		t.Errorf("Expected the PUT within the quota to be admitted. Error: %v", err)
+	}
+}
+
 func testDistributedPut(t *testing.T) {
 	for i := 1; i <= clusterSize; i++ {
 		key, value := fmt.Sprintf("K_CLI_%d", i), fmt.Sprintf("V_CLI_%d", i)
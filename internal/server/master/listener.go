@@ -0,0 +1,206 @@
+package master
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+)
+
+// A Listener is notified of the keys mutated through a DKVService,
+// like for publishing these mutations onto a message queue. Listeners
+// are registered through DKVService.RegisterListener.
+//
+// Every mutation is notified once it is committed onto the storage,
+// along with the storage key, which includes its namespace, if any, and
+// the change number of the latest change committed by then. The change
+// number is at least that of the mutation, and is shared by the keys
+// mutated together, such as through MultiPut or Txn. It is zero when
+// the service does not propagate its changes.
+//
+// Each listener is notified from a goroutine of its own, one mutation
+// at a time and in the order these were committed by the calls to the
+// service, so that mutations acknowledged one after another are notified
+// in that order, while those of concurrent calls are notified in either
+// order. The keys of a MultiPut or Txn are notified in the order of
+// its entries. Mutations are queued for the listener so that a slow
+// listener does not block the writes. Once the queue is full, further
+// mutations are dropped until it drains, and counted as such. Queued
+// mutations are lost if the node crashes, hence listeners that need
+// every mutation are better served by the replication stream.
+//
+// Only the mutations served by the node are notified. Replicated
+// changes, such as those applied on the followers of a cluster, along
+// with restores and bulk loads, are not.
+type Listener interface {
+	// OnPut is called once the given value is stored against the
+	// given key, such as through Put, CompareAndSet or Increment.
+	OnPut(key, value []byte, chngNum uint64)
+	// OnDelete is called once the given key is deleted.
+	OnDelete(key []byte, chngNum uint64)
+}
+
+// DefaultListenerQueueSize is the number of mutations queued for every
+// listener unless overridden through WithListenerQueueSize.
+const DefaultListenerQueueSize = 10000
+
+// Number of dropped mutations after which drops are logged again
+const dropLogInterval = 1000
+
+type keyMutation struct {
+	del     bool
+	key     []byte
+	value   []byte
+	chngNum uint64
+}
+
+// A listenerQueue delivers the queued mutations to its listener.
+type listenerQueue struct {
+	lstnr   Listener
+	muts    chan keyMutation
+	dropped uint64
+	done    chan struct{}
+}
+
+func (lq *listenerQueue) deliver() {
+	defer close(lq.done)
+	for mut := range lq.muts {
+		if mut.del {
+			lq.lstnr.OnDelete(mut.key, mut.chngNum)
+		} else {
+			lq.lstnr.OnPut(mut.key, mut.value, mut.chngNum)
+		}
+	}
+}
+
+// listeners notifies the registered listeners of the mutations made
+// through a DKVService.
+type listeners struct {
+	cp        storage.ChangePropagator
+	queueSize int
+	lgr       *zap.Logger
+	mu        sync.RWMutex
+	queues    []*listenerQueue
+	closed    bool
+}
+
+func newListeners(cp storage.ChangePropagator, opts *dkvServiceOpts) *listeners {
+	return &listeners{cp: cp, queueSize: opts.lstnrQueue, lgr: opts.lgr}
+}
+
+// register starts notifying the given listener of the mutations made
+// from now on, unless the listeners are closed.
+func (ls *listeners) register(lstnr Listener) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.closed {
+		return
+	}
+	lq := &listenerQueue{lstnr: lstnr, muts: make(chan keyMutation, ls.queueSize), done: make(chan struct{})}
+	ls.queues = append(ls.queues, lq)
+	go lq.deliver()
+}
+
+// notify queues the given mutations for every listener, dropping them
+// for the listeners whose queues are full.
+func (ls *listeners) notify(muts ...keyMutation) {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	if ls.closed || len(ls.queues) == 0 {
+		return
+	}
+	if ls.cp != nil {
+		chngNum, err := ls.cp.GetLatestCommittedChangeNumber()
+		if err != nil {
+			ls.lgr.Warn("Unable to get the change number of the mutations notified to listeners", zap.Error(err))
+		}
+		for i := range muts {
+			muts[i].chngNum = chngNum
+		}
+	}
+	for _, lq := range ls.queues {
+		for _, mut := range muts {
+			select {
+			case lq.muts <- mut:
+			default:
+				if dropped := atomic.AddUint64(&lq.dropped, 1); dropped%dropLogInterval == 1 {
+					ls.lgr.Warn("Listener queue is full, dropping mutations", zap.Uint64("dropped", dropped), zap.Int("queueSize", ls.queueSize))
+				}
+			}
+		}
+	}
+}
+
+func (ls *listeners) notifyPuts(puts ...*serverpb.PutRequest) {
+	muts := make([]keyMutation, len(puts))
+	for i, put := range puts {
+		muts[i] = keyMutation{key: put.Key, value: put.Value}
+	}
+	ls.notify(muts...)
+}
+
+// notifyTxn notifies the mutations of the given namespaced transaction,
+// along with the uncompressed values of the given transaction.
+func (ls *listeners) notifyTxn(txnReq, nsTxnReq *serverpb.TxnRequest, succeeded bool) {
+	txnMuts, nsTxnMuts := storage.TxnMutations(txnReq, succeeded), storage.TxnMutations(nsTxnReq, succeeded)
+	muts := make([]keyMutation, len(nsTxnMuts))
+	for i, mut := range nsTxnMuts {
+		muts[i] = keyMutation{del: mut.Type == serverpb.TrxnRecord_Delete, key: mut.Key, value: txnMuts[i].Value}
+	}
+	ls.notify(muts...)
+}
+
+// dropped returns the number of mutations dropped across listeners.
+func (ls *listeners) dropped() uint64 {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	var dropped uint64
+	for _, lq := range ls.queues {
+		dropped += atomic.LoadUint64(&lq.dropped)
+	}
+	return dropped
+}
+
+// close stops notifying the listeners, while the mutations queued
+// for them are still delivered.
+func (ls *listeners) close() {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if !ls.closed {
+		ls.closed = true
+		for _, lq := range ls.queues {
+			close(lq.muts)
+		}
+	}
+}
+
+// wait waits for the mutations queued for the listeners to be
+// delivered once these are closed.
+func (ls *listeners) wait() {
+	ls.mu.RLock()
+	queues := ls.queues
+	ls.mu.RUnlock()
+	for _, lq := range queues {
+		<-lq.done
+	}
+}
+
+type loggingListener struct {
+	lgr *zap.Logger
+}
+
+// NewLoggingListener creates a Listener that logs every mutation
+// at the debug level using the given logger.
+func NewLoggingListener(lgr *zap.Logger) Listener {
+	return &loggingListener{lgr}
+}
+
+func (ll *loggingListener) OnPut(key, value []byte, chngNum uint64) {
+	ll.lgr.Debug("Key put", zap.ByteString("key", key), zap.Int("valueSize", len(value)), zap.Uint64("changeNumber", chngNum))
+}
+
+func (ll *loggingListener) OnDelete(key []byte, chngNum uint64) {
+	ll.lgr.Debug("Key deleted", zap.ByteString("key", key), zap.Uint64("changeNumber", chngNum))
+}
@@ -0,0 +1,93 @@
+package master
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/compression"
+	"github.com/flipkart-incubator/dkv/internal/server/master/mastertest"
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+func TestListenerNotifiedOfMutations(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store, WithCompression(compression.Snappy))
+	defer svc.Close()
+	rec := mastertest.NewRecorder()
+	svc.RegisterListener(rec)
+
+	ctx := context.Background()
+	svc.Put(ctx, &serverpb.PutRequest{Key: []byte("LstnrKey1"), Value: []byte("LstnrVal1")})
+	svc.MultiPut(ctx, &serverpb.MultiPutRequest{PutRequests: []*serverpb.PutRequest{
+		{Key: []byte("LstnrKey2"), Value: []byte("LstnrVal2")},
+		{Key: []byte("LstnrKey3"), Value: []byte("LstnrVal3"), Namespace: "ns"},
+	}})
+	// Failed CompareAndSet is not notified
+	svc.CompareAndSet(ctx, &serverpb.CompareAndSetRequest{Key: []byte("LstnrKey1"), ExpectedValue: []byte("Other"), NewValue: []byte("Ignored")})
+	svc.CompareAndSet(ctx, &serverpb.CompareAndSetRequest{Key: []byte("LstnrKey1"), ExpectedValue: []byte("LstnrVal1"), NewValue: []byte("LstnrCASVal")})
+	svc.Increment(ctx, &serverpb.IncrementRequest{Key: []byte("LstnrCounter"), Delta: 5})
+	svc.Txn(ctx, &serverpb.TxnRequest{ThenMutations: []*serverpb.TrxnRecord{
+		{Type: serverpb.TrxnRecord_Delete, Key: []byte("LstnrKey2")},
+		{Type: serverpb.TrxnRecord_Put, Key: []byte("LstnrKey4"), Value: []byte("LstnrVal4")},
+	}})
+	svc.Delete(ctx, &serverpb.DeleteRequest{Key: []byte("LstnrKey1")})
+
+	nsKey, _ := storage.NamespacedKey("ns", []byte("LstnrKey3"))
+	expMuts := []mastertest.Mutation{
+		{Key: []byte("LstnrKey1"), Value: []byte("LstnrVal1")},
+		{Key: []byte("LstnrKey2"), Value: []byte("LstnrVal2")},
+		{Key: nsKey, Value: []byte("LstnrVal3")},
+		{Key: []byte("LstnrKey1"), Value: []byte("LstnrCASVal")},
+		{Key: []byte("LstnrCounter"), Value: encodeCounter(5)},
+		{Key: []byte("LstnrKey2"), Deleted: true},
+		{Key: []byte("LstnrKey4"), Value: []byte("LstnrVal4")},
+		{Key: []byte("LstnrKey1"), Deleted: true},
+	}
+	muts := rec.WaitFor(len(expMuts), 5*time.Second)
+	if len(muts) != len(expMuts) {
+		t.Fatalf("Expected %d mutations to be notified. Actual: %+v", len(expMuts), muts)
+	}
+	var prevChngNum uint64
+	for i, mut := range muts {
+		if !bytes.Equal(mut.Key, expMuts[i].Key) || !bytes.Equal(mut.Value, expMuts[i].Value) || mut.Deleted != expMuts[i].Deleted {
+			t.Errorf("Mutation mismatch at index %d. Expected: %+v, Actual: %+v", i, expMuts[i], mut)
+		}
+		if mut.ChangeNumber == 0 || mut.ChangeNumber < prevChngNum {
+			t.Errorf("Expected increasing change numbers. Previous: %d, Actual: %+v", prevChngNum, mut)
+		}
+		prevChngNum = mut.ChangeNumber
+	}
+	if dropped := svc.DroppedMutations(); dropped != 0 {
+		t.Errorf("Expected no mutations to be dropped. Actual: %d", dropped)
+	}
+}
+
+func TestSlowListenerDropsMutations(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store, WithListenerQueueSize(2))
+	defer svc.Close()
+	rec := mastertest.NewRecorder()
+	rec.Block()
+	svc.RegisterListener(rec)
+
+	const numPuts = 10
+	ctx := context.Background()
+	for i := 0; i < numPuts; i++ {
+		if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("SlowKey"), Value: []byte{byte(i)}}); err != nil || res.Status.Code != 0 {
+			t.Fatalf("Expected the puts to succeed regardless of the listener. Status: %+v, Error: %v", res.GetStatus(), err)
+		}
+	}
+	// Besides the queued mutations, the listener may be blocked on one
+	dropped := svc.DroppedMutations()
+	if dropped < numPuts-3 {
+		t.Errorf("Expected at least %d mutations to be dropped. Actual: %d", numPuts-3, dropped)
+	}
+	rec.Unblock()
+	if muts := rec.WaitFor(numPuts-int(dropped), 5*time.Second); len(muts) != numPuts-int(dropped) || muts[0].Value[0] != 0 {
+		t.Errorf("Expected the mutations queued to be notified in order. Actual: %+v", muts)
+	}
+}
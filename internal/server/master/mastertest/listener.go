@@ -0,0 +1,102 @@
+// Package mastertest provides the test doubles for the extension
+// points of the master service, so that embedders can test their
+// extensions without a DKV node.
+package mastertest
+
+import (
+	"sync"
+	"time"
+)
+
+// A Mutation is a key mutation notified to a Recorder.
+type Mutation struct {
+	Key          []byte
+	Value        []byte
+	Deleted      bool
+	ChangeNumber uint64
+}
+
+// A Recorder is a master.Listener that records the mutations it is
+// notified of, in the order these are notified. It can optionally be
+// blocked, like a slow listener, until it is unblocked.
+type Recorder struct {
+	mu      sync.Mutex
+	muts    []Mutation
+	changed chan struct{}
+	blocked chan struct{}
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{changed: make(chan struct{})}
+}
+
+// OnPut records the given put.
+func (r *Recorder) OnPut(key, value []byte, chngNum uint64) {
+	r.record(Mutation{Key: key, Value: value, ChangeNumber: chngNum})
+}
+
+// OnDelete records the given delete.
+func (r *Recorder) OnDelete(key []byte, chngNum uint64) {
+	r.record(Mutation{Key: key, Deleted: true, ChangeNumber: chngNum})
+}
+
+func (r *Recorder) record(mut Mutation) {
+	r.mu.Lock()
+	blocked := r.blocked
+	r.mu.Unlock()
+	if blocked != nil {
+		<-blocked
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.muts = append(r.muts, mut)
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// Block blocks the notifications made from now on until Unblock is
+// called.
+func (r *Recorder) Block() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.blocked == nil {
+		r.blocked = make(chan struct{})
+	}
+}
+
+// Unblock lets the blocked notifications, if any, be recorded.
+func (r *Recorder) Unblock() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.blocked != nil {
+		close(r.blocked)
+		r.blocked = nil
+	}
+}
+
+// Mutations returns the mutations recorded so far.
+func (r *Recorder) Mutations() []Mutation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Mutation(nil), r.muts...)
+}
+
+// WaitFor waits upto the given timeout for at least the given number
+// of mutations to be recorded, returning those recorded by then.
+func (r *Recorder) WaitFor(numMuts int, timeout time.Duration) []Mutation {
+	deadline := time.After(timeout)
+	for {
+		r.mu.Lock()
+		muts, changed := append([]Mutation(nil), r.muts...), r.changed
+		r.mu.Unlock()
+		if len(muts) >= numMuts {
+			return muts
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			return muts
+		}
+	}
+}
@@ -2,104 +2,1313 @@ package master
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"hash/crc32"
 	"io"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/flipkart-incubator/dkv/internal/compression"
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/server/backup"
+	"github.com/flipkart-incubator/dkv/internal/server/config"
+	"github.com/flipkart-incubator/dkv/internal/server/health"
+	"github.com/flipkart-incubator/dkv/internal/server/maintenance"
 	"github.com/flipkart-incubator/dkv/internal/server/storage"
 	"github.com/flipkart-incubator/dkv/internal/server/sync/raftpb"
+	"github.com/flipkart-incubator/dkv/internal/tracing"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
 	nexus_api "github.com/flipkart-incubator/nexus/pkg/api"
 	"github.com/gogo/protobuf/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // A DKVService represents a service for serving key value data
-// along with exposing all mutations as a replication stream.
+// along with exposing all mutations as a replication stream. It
+// also reports its serving status over the GRPC health service.
 type DKVService interface {
 	io.Closer
+	// Shutdown gracefully shuts down the service once the requests
+	// being served are drained, like through grpc.Server.GracefulStop,
+	// so that none of the writes accepted are lost.
+	Shutdown(ctx context.Context) error
+	// RegisterListener registers the given Listener to be notified of
+	// the keys mutated through the service from now on.
+	RegisterListener(lstnr Listener)
+	// DroppedMutations returns the number of mutations dropped since
+	// the queues of the registered listeners were full.
+	DroppedMutations() uint64
 	serverpb.DKVServer
 	serverpb.DKVReplicationServer
 	serverpb.DKVBackupRestoreServer
+	grpc_health_v1.HealthServer
 }
 
 type standaloneService struct {
-	store storage.KVStore
-	cp    storage.ChangePropagator
-	br    storage.Backupable
+	grpc_health_v1.HealthServer
+	store     storage.KVStore
+	cp        storage.ChangePropagator
+	br        storage.Backupable
+	chngNotif *changeNotifier
+	bckpJobs  *backupJobs
+	opts      *dkvServiceOpts
+	replicas  *storage.ReplicaTracker
+	lstnrs    *listeners
+	// Nil unless the change log of cp can be truncated
+	retainer *storage.ChangeLogRetainer
+	// Shall be manipulated using atomics
+	closed uint32
 }
 
+// A DKVServiceOption is used to customize a specific aspect
+// of the DKVService.
+type DKVServiceOption func(*dkvServiceOpts)
+
+type dkvServiceOpts struct {
+	sizeLimits storage.SizeLimits
+	keyPolicy  storage.KeyPolicies
+	quotas     *storage.Quotas
+	codec      compression.Codec
+	bckpTrnsfr *backup.Transfer
+	lgr        *zap.Logger
+	nodeID     uint64
+	nodeURLs   []string
+	dkvAddrs   []string
+	dialMember func(dkvAddr string) (*ctl.DKVClient, error)
+	bulkLoads  bool
+	retention  storage.RetentionPolicy
+	replExpiry time.Duration
+	asyncPuts  bool
+	rstrPar    int
+	maint      *maintenance.Mode
+	lstnrQueue int
+	maxResSize int
+	maxChngsSz uint64
+	tombRetain time.Duration
+}
+
+// WithSizeLimits sets the maximum sizes of the keys and values that
+// the DKVService accepts. Mutations exceeding them are rejected with
+// either the KeyTooLarge or the ValueTooLarge status code.
+func WithSizeLimits(sizeLimits storage.SizeLimits) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.sizeLimits = sizeLimits
+	}
+}
+
+// WithKeyPolicies sets the policies that the keys mutated through the
+// DKVService must satisfy, as per their namespaces. Mutations violating
+// them are rejected with the InvalidKey status code, along with the bulk
+// loads. Changes replicated onto the slave nodes are not checked again.
+func WithKeyPolicies(keyPolicies storage.KeyPolicies) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.keyPolicy = keyPolicies
+	}
+}
+
+// WithQuotas enforces the given Quotas on the mutations made through the
+// DKVService. Mutations taking a namespace beyond its quota are rejected
+// with the QuotaExceeded status code, while the changes replicated onto
+// the slave nodes are never checked. The distributed variant retains the
+// quotas set through the RAFT replicator, so that every member of the
+// cluster enforces them once it reconciles its Quotas.
+func WithQuotas(quotas *storage.Quotas) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.quotas = quotas
+	}
+}
+
+// WithCompression compresses the values at rest using the given codec,
+// irrespective of whether the clients compressed them. Such values are
+// decompressed before they are served, while the changes and checkpoints
+// streamed to the slave nodes carry them verbatim. Hence slave nodes must
+// be launched with compression as well.
+func WithCompression(codec compression.Codec) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.codec = codec
+	}
+}
+
+// WithBackupTransfer sets the Transfer through which the DKVService
+// runs its backups and restores, so that their paths can be object
+// storage URIs such as s3://bucket/prefix. By default only paths on
+// the local filesystem are supported.
+func WithBackupTransfer(bckpTrnsfr *backup.Transfer) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.bckpTrnsfr = bckpTrnsfr
+	}
+}
+
+// WithBulkLoads enables the BulkLoad method of the standalone variant of
+// the DKVService. Since bulk loaded entries are not committed as changes,
+// slave nodes can only catch up with them by bootstrapping themselves
+// afresh from a checkpoint of the master node.
+func WithBulkLoads() DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.bulkLoads = true
+	}
+}
+
+// WithRestoreParallelism sets the number of workers that ingest the
+// backups being restored at once, where the storage engine allows. It
+// defaults to storage.DefaultRestoreParallelism.
+func WithRestoreParallelism(parallelism int) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.rstrPar = parallelism
+	}
+}
+
+// WithMaintenanceMode rejects every keyspace mutation made through the
+// service with ErrMaintenance while the given Mode is in maintenance,
+// during which the service is also reported as not serving. Changes
+// replicated onto the distributed variant by the other members of its
+// cluster, along with restores, are still applied.
+func WithMaintenanceMode(mode *maintenance.Mode) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.maint = mode
+	}
+}
+
+// WithListenerQueueSize sets the number of mutations queued for every
+// Listener registered with the DKVService, beyond which further
+// mutations are dropped. It defaults to DefaultListenerQueueSize.
+func WithListenerQueueSize(size int) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.lstnrQueue = size
+	}
+}
+
+// WithMaxResponseSize limits the size of the GetChanges responses, and
+// of the batches of StreamChanges, to the given number of bytes, such
+// as to the maximum message size sent by the GRPC server. Changes that
+// do not fit within it are left for the subsequent calls, like those
+// beyond the MaxResponseSize of the requests. Responses are not limited
+// by default.
+func WithMaxResponseSize(size int) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.maxResSize = size
+	}
+}
+
+// DefaultMaxChangesSize is the number of bytes of changes served by
+// every GetChanges call, and by every batch of StreamChanges, unless
+// overridden through WithMaxChangesSize.
+const DefaultMaxChangesSize = config.DefaultMaxChangesSize
+
+// WithMaxChangesSize limits the total size of the changes served by
+// every GetChanges call, and by every batch of StreamChanges, to the
+// given number of bytes, in place of any larger MaxNumberOfBytes of the
+// requests. This bounds the memory taken by slaves catching up over
+// changes with large values, regardless of the number of changes they
+// request. It defaults to DefaultMaxChangesSize.
+func WithMaxChangesSize(size uint64) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.maxChngsSz = size
+	}
+}
+
+// WithTombstoneRetention makes the DKVService delete keys softly, such
+// that every deleted key is retained under a tombstone for the given
+// retention window, within which it can be restored through Undelete.
+// Tombstones are replicated and backed up along with the keys, while
+// being hidden from all the reads. Keys are deleted outright by default.
+func WithTombstoneRetention(retention time.Duration) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.tombRetain = retention
+	}
+}
+
+// WithChangeLogRetention sets the policy as per which the changes
+// retained for replication are periodically truncated, provided the
+// underlying ChangePropagator is a ChangeLogTruncater. Slave nodes that
+// fall behind the truncated changes bootstrap themselves afresh from a
+// checkpoint. By default the changes are truncated only on demand.
+func WithChangeLogRetention(policy storage.RetentionPolicy) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.retention = policy
+	}
+}
+
+// WithReplicaExpiry sets the duration after which the replicas that
+// retrieve no changes are no longer listed, nor are the changes they are
+// yet to consume retained any longer. It defaults to a day.
+func WithReplicaExpiry(expiry time.Duration) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.replExpiry = expiry
+	}
+}
+
+// WithDefaultSyncMode sets the sync mode of the puts made onto the
+// standalone variant of the DKVService that do not request one. By
+// default puts are Sync, hence persisted onto the disk before they are
+// acknowledged. Either way, the changes streamed to the slave nodes
+// remain the same.
+func WithDefaultSyncMode(mode serverpb.SyncMode) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.asyncPuts = mode == serverpb.SyncMode_Async
+	}
+}
+
+// syncPuts reports whether the given puts, written as a single batch,
+// must be synced onto the disk, which is the case if any of them is.
+func (opts *dkvServiceOpts) syncPuts(puts ...*serverpb.PutRequest) bool {
+	for _, put := range puts {
+		switch put.SyncMode {
+		case serverpb.SyncMode_Sync:
+			return true
+		case serverpb.SyncMode_DefaultSync:
+			if !opts.asyncPuts {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WithConfig applies the storage settings of the given config, along
+// with its limits on the sizes of the keys, values, responses and changes
+// served, as per the respective options. Options given after it override
+// these settings. The rest of the config, like the listen addresses and
+// the files of TLS, is left to the caller.
+func WithConfig(cfg *config.Config) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.sizeLimits = cfg.Limits.SizeLimits()
+		opts.keyPolicy = cfg.Storage.KeyPolicies()
+		opts.codec = cfg.Storage.Codec()
+		opts.bulkLoads = cfg.Storage.BulkLoad
+		opts.asyncPuts = cfg.Storage.AsyncPuts
+		opts.rstrPar = cfg.Storage.RestoreParallelism
+		opts.retention = cfg.Storage.ChangeLog.RetentionPolicy()
+		opts.tombRetain = cfg.Storage.TombstoneRetention
+		opts.maxResSize = cfg.Limits.MaxSendMsgSize
+		opts.maxChngsSz = cfg.Limits.MaxChangesSize
+	}
+}
+
+// WithLogger sets the logger used by the DKVService for logging
+// its backups, restores, checkpoints, change streams and cluster
+// membership changes. By default nothing is logged.
+func WithLogger(lgr *zap.Logger) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.lgr = lgr
+	}
+}
+
+// WithClusterNodes sets the ID of the local node within its Nexus
+// cluster along with the URLs of the initial members of this cluster,
+// whose IDs are their positions in the given list starting from 1, as
+// per Nexus. These are listed by the distributed variant of the
+// DKVService when its RAFT replicator cannot list the members itself.
+func WithClusterNodes(nodeID uint64, nodeURLs []string) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.nodeID, opts.nodeURLs = nodeID, nodeURLs
+	}
+}
+
+// WithClusterAddrs sets the addresses of the DKV services of the initial
+// members of the Nexus cluster, in the order of the URLs given to
+// WithClusterNodes. The distributed variant of the DKVService hints the
+// address of the leader to the callers whose changes it rejects.
+func WithClusterAddrs(dkvAddrs []string) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.dkvAddrs = dkvAddrs
+	}
+}
+
+// WithMemberDialer sets the function used for creating the clients of the
+// DKV services of the members of the Nexus cluster, whose change numbers
+// are tracked while decommissioning nodes. By default insecure clients
+// are created.
+func WithMemberDialer(dial func(dkvAddr string) (*ctl.DKVClient, error)) DKVServiceOption {
+	return func(opts *dkvServiceOpts) {
+		opts.dialMember = dial
+	}
+}
+
+// dialMember creates an insecure client of the DKV service of a member,
+// whose buffers are small since only its change number is retrieved.
+func dialMember(dkvAddr string) (*ctl.DKVClient, error) {
+	return ctl.NewInSecureDKVClient(dkvAddr, ctl.WithNonBlockingDial(), ctl.WithReadBufSize(64<<10), ctl.WithWriteBufSize(64<<10))
+}
+
+func newDKVServiceOpts(opts ...DKVServiceOption) *dkvServiceOpts {
+	dkvSvcOpts := &dkvServiceOpts{bckpTrnsfr: backup.LocalOnly, lgr: zap.NewNop(), dialMember: dialMember, rstrPar: storage.DefaultRestoreParallelism, lstnrQueue: DefaultListenerQueueSize, maxChngsSz: DefaultMaxChangesSize}
+	for _, opt := range opts {
+		opt(dkvSvcOpts)
+	}
+	return dkvSvcOpts
+}
+
+// Interval at which change streams look for newly committed changes
+// in the absence of notifications, which is the case when changes
+// are not made through this service (e.g., replicated over RAFT).
+const changeStreamPollInterval = 100 * time.Millisecond
+
+// Interval at which heartbeats are sent over change streams when
+// there are no new changes to be streamed.
+const changeStreamHeartbeatInterval = time.Second
+
+// Number of bytes of changes sent at once over change streams, as they
+// are read, so that batches of many changes are not held in memory.
+const changeStreamFlushSize = 4 << 20
+
 // NewStandaloneService creates a standalone variant of the DKVService
 // that works only with the local storage.
-func NewStandaloneService(store storage.KVStore, cp storage.ChangePropagator, br storage.Backupable) DKVService {
-	return &standaloneService{store, cp, br}
+func NewStandaloneService(store storage.KVStore, cp storage.ChangePropagator, br storage.Backupable, opts ...DKVServiceOption) DKVService {
+	return newStandaloneService(store, cp, br, newDKVServiceOpts(opts...))
+}
+
+func newStandaloneService(store storage.KVStore, cp storage.ChangePropagator, br storage.Backupable, opts *dkvServiceOpts) *standaloneService {
+	ss := &standaloneService{store: store, cp: cp, br: br, chngNotif: newChangeNotifier(), bckpJobs: newBackupJobs(), opts: opts}
+	ss.HealthServer = health.NewServer(ss.servingStatus)
+	ss.replicas = storage.NewReplicaTracker(opts.replExpiry)
+	ss.lstnrs = newListeners(cp, opts)
+	if clt, ok := cp.(storage.ChangeLogTruncater); ok {
+		ss.retainer = storage.NewChangeLogRetainer(clt, opts.retention, ss.replicas, opts.lgr)
+		ss.retainer.Start()
+	}
+	return ss
+}
+
+// servingStatus reports the standalone service as serving for
+// as long as it is neither closed nor in maintenance.
+func (ss *standaloneService) servingStatus() grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if inMaint, _ := ss.opts.maint.Status(); inMaint || atomic.LoadUint32(&ss.closed) == 1 {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING
 }
 
 func (ss *standaloneService) Put(ctx context.Context, putReq *serverpb.PutRequest) (*serverpb.PutResponse, error) {
-	if err := ss.store.Put(putReq.Key, putReq.Value); err != nil {
-		return &serverpb.PutResponse{Status: newErrorStatus(err)}, err
+	if err := ss.opts.maint.CheckWritable(); err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err := ss.opts.checkEntry(putReq.Namespace, putReq.Key, putReq.Value); err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	nsPuts, err := storage.NamespacedPuts(putReq)
+	if err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	compPuts := ss.opts.compressPuts(nsPuts...)
+	rsrv, err := ss.opts.quotas.Reserve(putQuotaMutations([]*serverpb.PutRequest{putReq}, compPuts)...)
+	if err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
 	}
+	// MultiPut also stores the expiry time of the given entry
+	_, span := tracing.StartStorageSpan(ctx, "MultiPut", len(nsPuts))
+	err = storage.MultiPutWithSync(ss.store, ss.opts.syncPuts(putReq), compPuts...)
+	if tracing.EndSpan(span, err); err != nil {
+		rsrv.Cancel()
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	ss.chngNotif.notify()
+	ss.lstnrs.notifyPuts(nsPuts...)
 	return &serverpb.PutResponse{Status: newEmptyStatus()}, nil
 }
 
+func (ss *standaloneService) MultiPut(ctx context.Context, multiPutReq *serverpb.MultiPutRequest) (*serverpb.MultiPutResponse, error) {
+	if err := ss.opts.maint.CheckWritable(); err != nil {
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err := validateMultiPut(multiPutReq, ss.opts); err != nil {
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	nsPuts, err := storage.NamespacedPuts(multiPutReq.PutRequests...)
+	if err != nil {
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	compPuts := ss.opts.compressPuts(nsPuts...)
+	rsrv, err := ss.opts.quotas.Reserve(putQuotaMutations(multiPutReq.PutRequests, compPuts)...)
+	if err != nil {
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	_, span := tracing.StartStorageSpan(ctx, "MultiPut", len(nsPuts))
+	err = storage.MultiPutWithSync(ss.store, ss.opts.syncPuts(multiPutReq.PutRequests...), compPuts...)
+	if tracing.EndSpan(span, err); err != nil {
+		rsrv.Cancel()
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	ss.chngNotif.notify()
+	ss.lstnrs.notifyPuts(nsPuts...)
+	return &serverpb.MultiPutResponse{Status: newEmptyStatus()}, nil
+}
+
+func (ss *standaloneService) CompareAndSet(ctx context.Context, casReq *serverpb.CompareAndSetRequest) (*serverpb.CompareAndSetResponse, error) {
+	if err := ss.opts.maint.CheckWritable(); err != nil {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err := ss.opts.checkEntry(casReq.Namespace, casReq.Key, casReq.NewValue); err != nil {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(casReq.Namespace, casReq.Key)
+	if err != nil {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(err)}, nil
+	}
+	newVal := ss.opts.compress(casReq.NewValue)
+	rsrv, err := ss.opts.quotas.Reserve(storage.QuotaMutation{Namespace: casReq.Namespace, Key: key, Value: newVal})
+	if err != nil {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(err)}, nil
+	}
+	_, span := tracing.StartStorageSpan(ctx, "CompareAndSet", 1)
+	updated, err := ss.store.CompareAndSet(key, casReq.ExpectedValue, newVal)
+	tracing.EndSpan(span, err)
+	if err != nil || !updated {
+		rsrv.Cancel()
+	}
+	res := &serverpb.CompareAndSetResponse{Status: newEmptyStatus(), Updated: updated}
+	if err != nil {
+		res.Status = newErrorStatus(err)
+	} else if updated {
+		ss.chngNotif.notify()
+		ss.lstnrs.notify(keyMutation{key: key, value: casReq.NewValue})
+	}
+	return res, nil
+}
+
+func (ss *standaloneService) Increment(ctx context.Context, incReq *serverpb.IncrementRequest) (*serverpb.IncrementResponse, error) {
+	if err := ss.opts.maint.CheckWritable(); err != nil {
+		return &serverpb.IncrementResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err := ss.opts.checkEntry(incReq.Namespace, incReq.Key, nil); err != nil {
+		return &serverpb.IncrementResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(incReq.Namespace, incReq.Key)
+	if err != nil {
+		return &serverpb.IncrementResponse{Status: newErrorStatus(err)}, nil
+	}
+	rsrv, err := ss.opts.quotas.Reserve(storage.QuotaMutation{Namespace: incReq.Namespace, Key: key, Value: encodeCounter(0)})
+	if err != nil {
+		return &serverpb.IncrementResponse{Status: newErrorStatus(err)}, nil
+	}
+	_, span := tracing.StartStorageSpan(ctx, "Increment", 1)
+	value, err := ss.store.Increment(key, incReq.Delta)
+	tracing.EndSpan(span, err)
+	res := &serverpb.IncrementResponse{Status: newEmptyStatus(), Value: value}
+	if err != nil {
+		rsrv.Cancel()
+		res.Status = newErrorStatus(err)
+	} else {
+		ss.chngNotif.notify()
+		ss.lstnrs.notify(keyMutation{key: key, value: encodeCounter(value)})
+	}
+	return res, nil
+}
+
+func (ss *standaloneService) Txn(ctx context.Context, txnReq *serverpb.TxnRequest) (*serverpb.TxnResponse, error) {
+	if err := ss.opts.maint.CheckWritable(); err != nil {
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err := ss.opts.validateTxn(txnReq); err != nil {
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	nsTxnReq, err := storage.NamespacedTxn(txnReq, ss.opts.compress)
+	if err != nil {
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	// Either branch may be applied, hence both must be admitted
+	thenRsrv, err := ss.opts.quotas.Reserve(storage.TxnQuotaMutations(txnReq.Namespace, nsTxnReq, true)...)
+	if err != nil {
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	elseRsrv, err := ss.opts.quotas.Reserve(storage.TxnQuotaMutations(txnReq.Namespace, nsTxnReq, false)...)
+	if err != nil {
+		thenRsrv.Cancel()
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	_, span := tracing.StartStorageSpan(ctx, "Txn", len(nsTxnReq.ThenMutations)+len(nsTxnReq.ElseMutations))
+	succeeded, err := ss.store.Txn(nsTxnReq)
+	tracing.EndSpan(span, err)
+	if err != nil || !succeeded {
+		thenRsrv.Cancel()
+	}
+	if err != nil || succeeded {
+		elseRsrv.Cancel()
+	}
+	res := &serverpb.TxnResponse{Status: newEmptyStatus(), Succeeded: succeeded}
+	if err != nil {
+		res.Status = newErrorStatus(err)
+	} else if len(storage.TxnMutations(nsTxnReq, succeeded)) > 0 {
+		ss.chngNotif.notify()
+		ss.lstnrs.notifyTxn(txnReq, nsTxnReq, succeeded)
+	}
+	return res, nil
+}
+
+func (ss *standaloneService) Delete(ctx context.Context, delReq *serverpb.DeleteRequest) (*serverpb.DeleteResponse, error) {
+	if err := ss.opts.maint.CheckWritable(); err != nil {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err := ss.opts.keyPolicy.Check(delReq.Namespace, delReq.Key); err != nil {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(delReq.Namespace, delReq.Key)
+	if err != nil {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	rsrv, err := ss.opts.quotas.Reserve(storage.QuotaMutation{Namespace: delReq.Namespace, Key: key, Delete: true})
+	if err != nil {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	_, span := tracing.StartStorageSpan(ctx, "Delete", 1)
+	err = ss.delete(key)
+	if tracing.EndSpan(span, err); err != nil {
+		rsrv.Cancel()
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	ss.chngNotif.notify()
+	ss.lstnrs.notify(keyMutation{del: true, key: key})
+	return &serverpb.DeleteResponse{Status: newEmptyStatus()}, nil
+}
+
+// delete deletes the given key, retaining it under a tombstone if the
+// service retains tombstones and the key is present.
+func (ss *standaloneService) delete(key []byte) error {
+	if ss.opts.tombRetain > 0 {
+		if deleted, err := storage.SoftDelete(ss.store, key, ss.opts.tombRetain, ss.store.Txn); err != nil || deleted {
+			return err
+		}
+	}
+	return ss.store.Delete(key)
+}
+
+func (ss *standaloneService) Undelete(ctx context.Context, undelReq *serverpb.UndeleteRequest) (*serverpb.UndeleteResponse, error) {
+	if err := ss.opts.maint.CheckWritable(); err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err := ss.opts.keyPolicy.Check(undelReq.Namespace, undelReq.Key); err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(undelReq.Namespace, undelReq.Key)
+	if err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	_, span := tracing.StartStorageSpan(ctx, "Undelete", 1)
+	value, err := storage.Undelete(ss.store, key, func(txnReq *serverpb.TxnRequest) (bool, error) {
+		// Restored key, put by the first mutation, is admitted against the
+		// quota while its tombstone is not accounted for by any namespace
+		restore := txnReq.ThenMutations[0]
+		rsrv, err := ss.opts.quotas.Reserve(storage.QuotaMutation{Namespace: undelReq.Namespace, Key: key, Value: restore.Value})
+		if err != nil {
+			return false, err
+		}
+		succeeded, err := ss.store.Txn(txnReq)
+		if err != nil || !succeeded {
+			rsrv.Cancel()
+		}
+		return succeeded, err
+	})
+	if tracing.EndSpan(span, err); err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	ss.chngNotif.notify()
+	if value, err = ss.opts.decompress(value); err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	ss.lstnrs.notify(keyMutation{key: key, value: value})
+	return &serverpb.UndeleteResponse{Status: newEmptyStatus()}, nil
+}
+
 func (ss *standaloneService) Get(ctx context.Context, getReq *serverpb.GetRequest) (*serverpb.GetResponse, error) {
-	readResults, err := ss.store.Get(getReq.Key)
+	key, err := storage.NamespacedKey(getReq.Namespace, getReq.Key)
+	if err != nil {
+		return &serverpb.GetResponse{Status: newErrorStatus(err)}, nil
+	}
+	_, span := tracing.StartStorageSpan(ctx, "Get", 1)
+	readResults, found, err := ss.store.Get(key)
+	tracing.EndSpan(span, err)
+	if err == nil {
+		readResults[0], err = ss.opts.decompress(readResults[0])
+	}
 	res := &serverpb.GetResponse{Status: newEmptyStatus()}
 	if err != nil {
 		res.Status = newErrorStatus(err)
 	} else {
-		res.Value = readResults[0]
+		res.Value, res.Found = readResults[0], found[0]
+	}
+	return res, nil
+}
+
+func (ss *standaloneService) MultiGet(ctx context.Context, multiGetReq *serverpb.MultiGetRequest) (*serverpb.MultiGetResponse, error) {
+	keys, err := storage.NamespacedKeys(multiGetReq.Namespace, multiGetReq.Keys...)
+	if err != nil {
+		return &serverpb.MultiGetResponse{Status: newErrorStatus(err)}, nil
+	}
+	// Keys of detailed reads are read even when others fail to be read
+	var readResults [][]byte
+	var found []bool
+	var errs []error
+	spanCtx, span := tracing.StartStorageSpan(ctx, "MultiGet", len(keys))
+	if multiGetReq.Detailed {
+		readResults, found, errs, err = storage.GetEach(spanCtx, ss.store, keys...)
+	} else {
+		readResults, found, err = storage.GetWithContext(spanCtx, ss.store, keys...)
+	}
+	tracing.EndSpan(span, err)
+	if ctxErr := dkverrors.GRPCContextError(err); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err == nil && !multiGetReq.Detailed {
+		for i := 0; i < len(readResults) && err == nil; i++ {
+			readResults[i], err = ss.opts.decompress(readResults[i])
+		}
+	}
+	res := &serverpb.MultiGetResponse{Status: newEmptyStatus()}
+	switch {
+	case err != nil:
+		res.Status = newErrorStatus(err)
+	case multiGetReq.Detailed:
+		res.Results = newKVResults(readResults, found, errs, ss.opts.decompress)
+	default:
+		res.Values, res.Found = readResults, found
+	}
+	return res, nil
+}
+
+func (ss *standaloneService) Exists(ctx context.Context, existsReq *serverpb.ExistsRequest) (*serverpb.ExistsResponse, error) {
+	keys, err := storage.NamespacedKeys(existsReq.Namespace, existsReq.Keys...)
+	if err != nil {
+		return &serverpb.ExistsResponse{Status: newErrorStatus(err)}, nil
+	}
+	_, span := tracing.StartStorageSpan(ctx, "Exists", len(keys))
+	results, err := ss.store.Exists(keys...)
+	tracing.EndSpan(span, err)
+	res := &serverpb.ExistsResponse{Status: newEmptyStatus()}
+	if err != nil {
+		res.Status = newErrorStatus(err)
+	} else {
+		res.Exists = results
+	}
+	return res, nil
+}
+
+func (ss *standaloneService) Iterate(iterReq *serverpb.IterateRequest, dkvIterSrvr serverpb.DKV_IterateServer) error {
+	iteration, err := storage.IterateNamespace(ss.store, iterReq.Namespace, iterReq.KeyPrefix, iterReq.StartKey, iterReq.KeysOnly)
+	if err != nil {
+		dkvIterSrvr.Send(&serverpb.IterateResponse{Status: newErrorStatus(err)})
+		return err
+	}
+	// Iteration stops as soon as the client gives up
+	iteration = storage.NewContextIterator(dkvIterSrvr.Context(), iteration)
+	defer iteration.Close()
+	for iteration.HasNext() {
+		key, val := iteration.Next()
+		itRes := &serverpb.IterateResponse{Status: newEmptyStatus(), Key: key}
+		if val, err := ss.opts.decompress(val); err != nil {
+			itRes.Status = newErrorStatus(err)
+		} else {
+			itRes.Value, itRes.Truncated = storage.TruncateValue(val, iterReq.MaxValueSize)
+		}
+		if err := dkvIterSrvr.Send(itRes); err != nil {
+			return err
+		}
+	}
+	if err := iteration.Err(); err != nil {
+		if ctxErr := dkverrors.GRPCContextError(err); ctxErr != nil {
+			return ctxErr
+		}
+		dkvIterSrvr.Send(&serverpb.IterateResponse{Status: newErrorStatus(err)})
+		return err
+	}
+	return nil
+}
+
+func (ss *standaloneService) GetChanges(ctx context.Context, getChngsReq *serverpb.GetChangesRequest) (*serverpb.GetChangesResponse, error) {
+	ss.replicas.Seen(getChngsReq)
+	return storage.LoadChangesResponse(ss.cp, ss.limitChangesRequest(getChngsReq)), nil
+}
+
+// limitChangesRequest limits the given request for changes as per the
+// maximum sizes of the responses and of the changes served.
+func (ss *standaloneService) limitChangesRequest(getChngsReq *serverpb.GetChangesRequest) *serverpb.GetChangesRequest {
+	if maxResSize := uint64(ss.opts.maxResSize); maxResSize > 0 && (getChngsReq.MaxResponseSize == 0 || getChngsReq.MaxResponseSize > maxResSize) {
+		getChngsReq.MaxResponseSize = maxResSize
+	}
+	if maxChngsSize := ss.opts.maxChngsSz; maxChngsSize > 0 && (getChngsReq.MaxNumberOfBytes == 0 || getChngsReq.MaxNumberOfBytes > maxChngsSize) {
+		getChngsReq.MaxNumberOfBytes = maxChngsSize
+	}
+	return getChngsReq
+}
+
+// ListReplicas lists the replicas that identified themselves while
+// retrieving changes, along with their lag behind the latest change.
+func (ss *standaloneService) ListReplicas(ctx context.Context, req *serverpb.ListReplicasRequest) (*serverpb.ListReplicasResponse, error) {
+	chngNum, err := ss.cp.GetLatestCommittedChangeNumber()
+	if err != nil {
+		return &serverpb.ListReplicasResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.ListReplicasResponse{Status: newEmptyStatus(), MasterChangeNumber: chngNum, Replicas: ss.replicas.Replicas(chngNum)}, nil
+}
+
+var errChangeLogUntruncatable = errors.New("change log of the storage engine can not be truncated")
+
+// GetChangeLogInfo reports the range of changes retained by the
+// underlying storage, along with the progress of the replicas.
+func (ss *standaloneService) GetChangeLogInfo(ctx context.Context, req *serverpb.GetChangeLogInfoRequest) (*serverpb.GetChangeLogInfoResponse, error) {
+	if ss.retainer == nil {
+		return &serverpb.GetChangeLogInfoResponse{Status: newErrorStatus(errChangeLogUntruncatable)}, nil
+	}
+	firstChngNum, lastChngNum, err := ss.cp.(storage.ChangeLogTruncater).RetainedChanges()
+	if err != nil {
+		return &serverpb.GetChangeLogInfoResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.GetChangeLogInfoResponse{Status: newEmptyStatus(), FirstChangeNumber: firstChngNum, LastChangeNumber: lastChngNum, Replicas: ss.replicas.Replicas(lastChngNum)}, nil
+}
+
+// TruncateChangeLog truncates the changes of the underlying storage
+// either before the given change number or as per the retention policy.
+func (ss *standaloneService) TruncateChangeLog(ctx context.Context, req *serverpb.TruncateChangeLogRequest) (*serverpb.TruncateChangeLogResponse, error) {
+	if ss.retainer == nil {
+		return &serverpb.TruncateChangeLogResponse{Status: newErrorStatus(errChangeLogUntruncatable)}, nil
+	}
+	var firstChngNum uint64
+	var err error
+	if req.BeforeChangeNumber > 0 {
+		firstChngNum, err = ss.retainer.TruncateBefore(req.BeforeChangeNumber)
+	} else {
+		firstChngNum, err = ss.retainer.Truncate()
+	}
+	if err != nil {
+		return &serverpb.TruncateChangeLogResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.TruncateChangeLogResponse{Status: newEmptyStatus(), FirstChangeNumber: firstChngNum}, nil
+}
+
+func (ss *standaloneService) StreamChanges(getChngsReq *serverpb.GetChangesRequest, chngsSrvr serverpb.DKVReplication_StreamChangesServer) error {
+	if getChngsReq.MaxNumberOfChanges == 0 {
+		err := errors.New("maximum number of changes per batch must be positive")
+		chngsSrvr.Send(&serverpb.GetChangesResponse{Status: newErrorStatus(err)})
+		return err
+	}
+
+	ctx := chngsSrvr.Context()
+	pollTckr := time.NewTicker(changeStreamPollInterval)
+	defer pollTckr.Stop()
+	fromChngNum, fromChunkOffset, lastSendTime := getChngsReq.FromChangeNumber, getChngsReq.FromChunkOffset, time.Time{}
+	ss.opts.lgr.Debug("Streaming changes", zap.Uint64("fromChangeNum", fromChngNum))
+	defer func() {
+		ss.opts.lgr.Debug("Stopped streaming changes", zap.Uint64("fromChangeNum", fromChngNum))
+	}()
+	for {
+		// Subscribe before loading changes so that none are missed
+		chngsAvail := ss.chngNotif.changes()
+		batchReq := &serverpb.GetChangesRequest{FromChangeNumber: fromChngNum, MaxNumberOfChanges: getChngsReq.MaxNumberOfChanges, MaxNumberOfBytes: getChngsReq.MaxNumberOfBytes, Namespace: getChngsReq.Namespace, KeyPrefix: getChngsReq.KeyPrefix, ReplicaID: getChngsReq.ReplicaID, MaxResponseSize: getChngsReq.MaxResponseSize, Chunked: getChngsReq.Chunked, FromChunkOffset: fromChunkOffset}
+		ss.replicas.Seen(batchReq)
+		// Changes are sent as they are read, with the rest of them sent
+		// once the batch is read
+		res, err := storage.StreamChangesResponse(ss.cp, ss.limitChangesRequest(batchReq), changeStreamFlushSize, func(res *serverpb.GetChangesResponse) error {
+			if err := chngsSrvr.Send(res); err != nil {
+				return err
+			}
+			fromChngNum, fromChunkOffset, lastSendTime = res.NextChangeNumber, res.NextChunkOffset, time.Now()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if res.Status.Code != 0 {
+			chngsSrvr.Send(res)
+			return nil
+		}
+		// Batches wholly skipped due to the key prefix are still sent,
+		// so that the receivers can record their progress
+		progressed := res.NextChangeNumber > fromChngNum
+		if progressed || time.Since(lastSendTime) >= changeStreamHeartbeatInterval {
+			if err = chngsSrvr.Send(res); err != nil {
+				return err
+			}
+			lastSendTime = time.Now()
+		}
+		if progressed {
+			// More changes may be pending, so load them right away
+			fromChngNum, fromChunkOffset = res.NextChangeNumber, res.NextChunkOffset
+			continue
+		}
+		select {
+		case <-chngsAvail:
+		case <-pollTckr.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// VerifyRange computes the checksum of the given range of changes, for
+// comparing it with that of the slave nodes that have applied them.
+func (ss *standaloneService) VerifyRange(ctx context.Context, req *serverpb.VerifyRangeRequest) (*serverpb.VerifyRangeResponse, error) {
+	checksum, numTrxns, err := storage.RangeChecksum(ss.cp, req.FromChangeNumber, req.ToChangeNumber)
+	if err != nil {
+		return &serverpb.VerifyRangeResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.VerifyRangeResponse{Status: newEmptyStatus(), Checksum: checksum, NumberOfTrxns: numTrxns}, nil
+}
+
+// KeyspaceDigest computes the digests of the requested ranges of keys,
+// for comparing the keyspace with those of the slave nodes.
+func (ss *standaloneService) KeyspaceDigest(ctx context.Context, req *serverpb.KeyspaceDigestRequest) (*serverpb.KeyspaceDigestResponse, error) {
+	ranges, keys, err := storage.KeyspaceDigest(ctx, ss.store, req)
+	if ctxErr := dkverrors.GRPCContextError(err); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return &serverpb.KeyspaceDigestResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.KeyspaceDigestResponse{Status: newEmptyStatus(), Ranges: ranges, Keys: keys}, nil
+}
+
+// Limits on the number and total size of key value pairs sent
+// in every response of a checkpoint stream.
+const (
+	checkpointBatchSize  = 1000
+	checkpointBatchBytes = 4 << 20
+)
+
+func (ss *standaloneService) GetCheckpoint(chkptReq *serverpb.GetCheckpointRequest, chkptSrvr serverpb.DKVReplication_GetCheckpointServer) error {
+	// Changes committed after this change number may also get captured
+	// by the iteration below. This is harmless since changes only carry
+	// absolute values, making their replay onto the checkpoint idempotent.
+	chngNum, err := ss.cp.GetLatestCommittedChangeNumber()
+	if err != nil {
+		chkptSrvr.Send(&serverpb.GetCheckpointResponse{Status: newErrorStatus(err)})
+		return err
+	}
+	if err = chkptSrvr.Send(&serverpb.GetCheckpointResponse{Status: newEmptyStatus(), ChangeNumber: chngNum}); err != nil {
+		return err
+	}
+	ss.opts.lgr.Info("Streaming checkpoint", zap.Uint64("changeNum", chngNum))
+
+	iteration := ss.store.Iterate(nil, nil)
+	defer iteration.Close()
+	res, numBytes := &serverpb.GetCheckpointResponse{Status: newEmptyStatus()}, 0
+	for iteration.HasNext() {
+		key, val := iteration.Next()
+		res.Entries = append(res.Entries, &serverpb.PutRequest{Key: key, Value: val, ExpireTS: iteration.ExpireTS()})
+		if numBytes += len(key) + len(val); len(res.Entries) >= checkpointBatchSize || numBytes >= checkpointBatchBytes {
+			if err = chkptSrvr.Send(res); err != nil {
+				return err
+			}
+			res, numBytes = &serverpb.GetCheckpointResponse{Status: newEmptyStatus()}, 0
+		}
+	}
+	if err = iteration.Err(); err != nil {
+		chkptSrvr.Send(&serverpb.GetCheckpointResponse{Status: newErrorStatus(err)})
+		return err
+	}
+	if len(res.Entries) > 0 {
+		return chkptSrvr.Send(res)
+	}
+	return nil
+}
+
+func (ss *standaloneService) Backup(ctx context.Context, backupReq *serverpb.BackupRequest) (*serverpb.Status, error) {
+	res, _ := ss.StartBackup(ctx, backupReq)
+	return ss.waitForBackupJob(ctx, res)
+}
+
+func (ss *standaloneService) Restore(ctx context.Context, restoreReq *serverpb.RestoreRequest) (*serverpb.Status, error) {
+	res, _ := ss.StartRestore(ctx, restoreReq)
+	return ss.waitForBackupJob(ctx, res)
+}
+
+// waitForBackupJob waits for the job begun with the given response
+// to complete, for as long as the caller waits, and reports its outcome.
+func (ss *standaloneService) waitForBackupJob(ctx context.Context, res *serverpb.BackupResponse) (*serverpb.Status, error) {
+	if res.Status.Code != 0 {
+		return res.Status, nil
+	}
+	job, err := ss.bckpJobs.get(res.JobID)
+	if err == nil {
+		err = job.wait(ctx)
+	}
+	if err != nil {
+		return newErrorStatus(err), nil
+	}
+	return newEmptyStatus(), nil
+}
+
+func (ss *standaloneService) StartBackup(ctx context.Context, backupReq *serverpb.BackupRequest) (*serverpb.BackupResponse, error) {
+	bckpPath, ns := backupReq.BackupPath, backupReq.Namespace
+	if _, err := backup.ParseLocation(bckpPath); err != nil {
+		return &serverpb.BackupResponse{Status: newErrorStatus(err)}, nil
+	}
+	job, err := ss.bckpJobs.start(&backupJob{path: bckpPath, ns: ns}, func(ctx context.Context, job *backupJob) error {
+		err := ss.opts.bckpTrnsfr.Backup(ctx, bckpPath, func(path string) error {
+			job.writingTo(path)
+			defer job.writingTo("")
+			if ns != "" {
+				return storage.BackupNamespace(&keyCountingStore{ss.store, job}, ns, path)
+			}
+			return ss.br.BackupTo(path)
+		})
+		if err != nil {
+			ss.opts.lgr.Error("Unable to backup", zap.Uint64("jobID", job.id), zap.String("path", bckpPath), zap.String("namespace", ns), zap.Error(err))
+			return err
+		}
+		ss.opts.lgr.Info("Backed up", zap.Uint64("jobID", job.id), zap.String("path", bckpPath), zap.String("namespace", ns))
+		return nil
+	})
+	if err != nil {
+		return &serverpb.BackupResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.BackupResponse{Status: newEmptyStatus(), JobID: job.id}, nil
+}
+
+func (ss *standaloneService) StartRestore(ctx context.Context, restoreReq *serverpb.RestoreRequest) (*serverpb.BackupResponse, error) {
+	rstrPath, ns, dryRun := restoreReq.RestorePath, restoreReq.Namespace, restoreReq.DryRun
+	if _, err := backup.ParseLocation(rstrPath); err != nil {
+		return &serverpb.BackupResponse{Status: newErrorStatus(err)}, nil
+	}
+	job, err := ss.bckpJobs.start(&backupJob{restore: true, dryRun: dryRun, path: rstrPath, ns: ns}, func(ctx context.Context, job *backupJob) error {
+		err := ss.opts.bckpTrnsfr.Restore(ctx, rstrPath, func(path string) error {
+			// The restores below verify the backup by themselves
+			// before replacing any key
+			if dryRun {
+				return ss.verifyBackup(job, path)
+			}
+			if ns == "" {
+				return storage.RestoreFrom(ss.br, path, ss.opts.rstrPar, &job.progress)
+			}
+			// Unlike full restores, the restored keys are committed
+			// as changes, hence get replicated onto the slave nodes
+			err := storage.RestoreNamespace(ss.store, ns, path, ss.opts.rstrPar, &job.progress)
+			if err == nil {
+				ss.chngNotif.notify()
+			}
+			return err
+		})
+		if err != nil {
+			ss.opts.lgr.Error("Unable to restore", zap.Uint64("jobID", job.id), zap.String("path", rstrPath), zap.String("namespace", ns), zap.Bool("dryRun", dryRun), zap.Error(err))
+			return err
+		}
+		ss.opts.lgr.Info("Restored", zap.Uint64("jobID", job.id), zap.String("path", rstrPath), zap.String("namespace", ns), zap.Bool("dryRun", dryRun))
+		return nil
+	})
+	if err != nil {
+		return &serverpb.BackupResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.BackupResponse{Status: newEmptyStatus(), JobID: job.id}, nil
+}
+
+func (ss *standaloneService) verifyBackup(job *backupJob, path string) error {
+	var info *storage.BackupInfo
+	var err error
+	if job.ns == "" {
+		info, err = ss.br.VerifyBackup(path)
+	} else {
+		info, err = storage.VerifyNamespaceBackup(job.ns, path)
+	}
+	if err != nil {
+		return err
+	}
+	var curChngNum uint64
+	if ss.cp != nil {
+		if curChngNum, err = ss.cp.GetLatestCommittedChangeNumber(); err != nil {
+			return err
+		}
+	}
+	job.verified(info, curChngNum)
+	return nil
+}
+
+func (ss *standaloneService) GetBackupStatus(ctx context.Context, statusReq *serverpb.GetBackupStatusRequest) (*serverpb.GetBackupStatusResponse, error) {
+	job, err := ss.bckpJobs.get(statusReq.JobID)
+	if err != nil {
+		return &serverpb.GetBackupStatusResponse{Status: newErrorStatus(err)}, nil
+	}
+	return job.status(), nil
+}
+
+const (
+	// Version of the format of the backups streamed by StreamBackup
+	streamBackupVersion = 1
+	// Limits of every chunk streamed by StreamBackup, which are kept
+	// well within the default limits of GRPC on the size of messages
+	backupChunkSize  = 1000
+	backupChunkBytes = 1 << 20
+	// Path recorded by the jobs of streamed backups and restores
+	streamJobPath = "<stream>"
+	// Path recorded by the jobs of bulk loads
+	bulkLoadJobPath = "<bulk load>"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// backupChunkChecksum computes the checksum of the given entries
+// over their fields rather than their serialised form, which is not
+// guaranteed to be stable across versions of the protobuf runtime.
+func backupChunkChecksum(entries []*serverpb.PutRequest) uint32 {
+	var crc uint32
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, entry := range entries {
+		crc = crc32.Update(crc, crc32cTable, buf[:binary.PutUvarint(buf, uint64(len(entry.Key)))])
+		crc = crc32.Update(crc, crc32cTable, entry.Key)
+		crc = crc32.Update(crc, crc32cTable, buf[:binary.PutUvarint(buf, uint64(len(entry.Value)))])
+		crc = crc32.Update(crc, crc32cTable, entry.Value)
+		crc = crc32.Update(crc, crc32cTable, buf[:binary.PutUvarint(buf, entry.ExpireTS)])
+	}
+	return crc
+}
+
+func (ss *standaloneService) StreamBackup(bckpReq *serverpb.StreamBackupRequest, bckpSrvr serverpb.DKVBackupRestore_StreamBackupServer) (err error) {
+	ns := bckpReq.Namespace
+	job, err := ss.bckpJobs.begin(&backupJob{path: streamJobPath, ns: ns})
+	if err != nil {
+		return err
+	}
+	defer func() { ss.bckpJobs.end(job, err) }()
+	// As with checkpoints, changes committed after this change
+	// number may also get captured by the iteration below
+	var chngNum uint64
+	if ss.cp != nil {
+		var err error
+		if chngNum, err = ss.cp.GetLatestCommittedChangeNumber(); err != nil {
+			return err
+		}
+	}
+	var iteration storage.Iterator
+	if ns == "" {
+		iteration = ss.store.Iterate(nil, nil)
+	} else {
+		var err error
+		if iteration, err = storage.IterateNamespace(ss.store, ns, nil, nil, false); err != nil {
+			return err
+		}
+	}
+	defer iteration.Close()
+
+	hdr := &serverpb.BackupHeader{Version: streamBackupVersion, ChangeNumber: chngNum, Namespace: ns}
+	if err := bckpSrvr.Send(&serverpb.BackupChunk{Header: hdr}); err != nil {
+		return err
+	}
+	seqNum, numEntries := uint64(1), uint64(0)
+	sendChunk := func(entries []*serverpb.PutRequest) error {
+		chunk := &serverpb.BackupChunk{SequenceNumber: seqNum, Entries: entries, Checksum: backupChunkChecksum(entries)}
+		seqNum, numEntries = seqNum+1, numEntries+uint64(len(entries))
+		return bckpSrvr.Send(chunk)
+	}
+	var entries []*serverpb.PutRequest
+	numBytes := 0
+	for iteration.HasNext() {
+		key, val := iteration.Next()
+		entries = append(entries, &serverpb.PutRequest{Key: key, Value: val, ExpireTS: iteration.ExpireTS()})
+		if numBytes += len(key) + len(val); len(entries) >= backupChunkSize || numBytes >= backupChunkBytes {
+			if err := sendChunk(entries); err != nil {
+				return err
+			}
+			entries, numBytes = nil, 0
+		}
 	}
-	return res, err
+	if err := iteration.Err(); err != nil {
+		ss.opts.lgr.Error("Unable to stream backup", zap.String("namespace", ns), zap.Error(err))
+		return err
+	}
+	if len(entries) > 0 {
+		if err := sendChunk(entries); err != nil {
+			return err
+		}
+	}
+	ss.opts.lgr.Info("Streamed backup", zap.String("namespace", ns), zap.Uint64("changeNum", chngNum), zap.Uint64("numEntries", numEntries))
+	return bckpSrvr.Send(&serverpb.BackupChunk{SequenceNumber: seqNum, Last: true, NumberOfEntries: numEntries})
 }
 
-func (ss *standaloneService) MultiGet(ctx context.Context, multiGetReq *serverpb.MultiGetRequest) (*serverpb.MultiGetResponse, error) {
-	readResults, err := ss.store.Get(multiGetReq.Keys...)
-	res := &serverpb.MultiGetResponse{Status: newEmptyStatus()}
+func (ss *standaloneService) StreamRestore(rstrSrvr serverpb.DKVBackupRestore_StreamRestoreServer) error {
+	job, err := ss.bckpJobs.begin(&backupJob{restore: true, path: streamJobPath})
 	if err != nil {
-		res.Status = newErrorStatus(err)
-	} else {
-		res.Values = readResults
+		return rstrSrvr.SendAndClose(newErrorStatus(err))
 	}
-	return res, err
+	ns, err := ss.streamRestore(rstrSrvr, job)
+	ss.bckpJobs.end(job, err)
+	if err != nil {
+		ss.opts.lgr.Error("Unable to restore streamed backup", zap.String("namespace", ns), zap.Error(err))
+		return rstrSrvr.SendAndClose(newErrorStatus(err))
+	}
+	ss.opts.lgr.Info("Restored streamed backup", zap.String("namespace", ns))
+	return rstrSrvr.SendAndClose(newEmptyStatus())
 }
 
-func (ss *standaloneService) GetChanges(ctx context.Context, getChngsReq *serverpb.GetChangesRequest) (*serverpb.GetChangesResponse, error) {
-	latestChngNum, _ := ss.cp.GetLatestCommittedChangeNumber()
-	res := &serverpb.GetChangesResponse{Status: newEmptyStatus(), MasterChangeNumber: latestChngNum}
-	if getChngsReq.FromChangeNumber > latestChngNum {
-		return res, nil
+// streamRestore stages all the received entries, and replaces the
+// keys of the store only once the final chunk has been received.
+func (ss *standaloneService) streamRestore(rstrSrvr serverpb.DKVBackupRestore_StreamRestoreServer, job *backupJob) (string, error) {
+	rstrReq, err := rstrSrvr.Recv()
+	if err != nil {
+		return "", err
+	}
+	ns, hdr := rstrReq.Namespace, rstrReq.GetChunk().GetHeader()
+	switch {
+	case hdr == nil:
+		return ns, fmt.Errorf("streamed backup must begin with its header: %w", dkverrors.ErrInvalidArgument)
+	case hdr.Version != streamBackupVersion:
+		return ns, fmt.Errorf("unsupported version: %d of streamed backup: %w", hdr.Version, dkverrors.ErrInvalidArgument)
+	case (hdr.Namespace == "") != (ns == ""):
+		return ns, fmt.Errorf("backups of the entire keyspace cannot be restored onto a namespace and vice versa: %w", dkverrors.ErrInvalidArgument)
 	}
 
-	chngs, err := ss.cp.LoadChanges(getChngsReq.FromChangeNumber, int(getChngsReq.MaxNumberOfChanges))
+	stage, err := storage.NewRestoreStage()
 	if err != nil {
-		res.Status = newErrorStatus(err)
-	} else {
-		res.NumberOfChanges = uint32(len(chngs))
-		res.Changes = chngs
+		return ns, err
+	}
+	defer stage.Close()
+	for seqNum, numEntries := uint64(1), uint64(0); ; seqNum++ {
+		if rstrReq, err = rstrSrvr.Recv(); err == io.EOF {
+			return ns, fmt.Errorf("streamed backup is truncated after %d chunks: %w", seqNum, dkverrors.ErrInvalidArgument)
+		}
+		if err != nil {
+			return ns, err
+		}
+		chunk := rstrReq.GetChunk()
+		switch {
+		case chunk.GetSequenceNumber() != seqNum:
+			return ns, fmt.Errorf("expected chunk: %d of streamed backup, but got chunk: %d: %w", seqNum, chunk.GetSequenceNumber(), dkverrors.ErrInvalidArgument)
+		case chunk.Last && chunk.NumberOfEntries != numEntries:
+			return ns, fmt.Errorf("streamed backup has %d entries, but carries %d entries: %w", chunk.NumberOfEntries, numEntries, dkverrors.ErrInvalidArgument)
+		case chunk.Last:
+			if err = stage.Apply(ss.store, ns, ss.opts.rstrPar, &job.progress); err != nil {
+				return ns, err
+			}
+			// Restored keys are committed as changes,
+			// hence get replicated onto the slave nodes
+			ss.chngNotif.notify()
+			return ns, nil
+		case backupChunkChecksum(chunk.Entries) != chunk.Checksum:
+			return ns, fmt.Errorf("checksum mismatch of chunk: %d of streamed backup: %w", seqNum, dkverrors.ErrInvalidArgument)
+		}
+		if err = stage.Add(chunk.Entries...); err != nil {
+			return ns, err
+		}
+		numEntries += uint64(len(chunk.Entries))
 	}
-	return res, err
 }
 
-func (ss *standaloneService) Backup(ctx context.Context, backupReq *serverpb.BackupRequest) (*serverpb.Status, error) {
-	bckpPath := backupReq.BackupPath
-	if err := ss.br.BackupTo(bckpPath); err != nil {
-		return newErrorStatus(err), err
+var errBulkLoadsDisabled = errors.New("bulk loads are not enabled on this DKV instance")
+
+func (ss *standaloneService) BulkLoad(loadSrvr serverpb.DKVBackupRestore_BulkLoadServer) error {
+	if !ss.opts.bulkLoads {
+		return loadSrvr.SendAndClose(&serverpb.BulkLoadResponse{Status: newErrorStatus(errBulkLoadsDisabled)})
 	}
-	return newEmptyStatus(), nil
+	if err := ss.opts.maint.CheckWritable(); err != nil {
+		return loadSrvr.SendAndClose(&serverpb.BulkLoadResponse{Status: newErrorStatus(err)})
+	}
+	job, err := ss.bckpJobs.begin(&backupJob{restore: true, path: bulkLoadJobPath})
+	if err != nil {
+		return loadSrvr.SendAndClose(&serverpb.BulkLoadResponse{Status: newErrorStatus(err)})
+	}
+	loaded, err := ss.bulkLoad(loadSrvr, job)
+	// Even a failed load is finished, so that the
+	// entries already written are not replicated as
+	// changes nor left without being persisted
+	var chngNum uint64
+	if loaded {
+		var finErr error
+		if chngNum, finErr = storage.FinishBulkLoad(ss.store); err == nil {
+			err = finErr
+		}
+		ss.chngNotif.notify()
+	}
+	ss.bckpJobs.end(job, err)
+	res := &serverpb.BulkLoadResponse{NumberOfKeys: atomic.LoadUint64(&job.keys), ChangeNumber: chngNum}
+	if err != nil {
+		ss.opts.lgr.Error("Unable to bulk load", zap.Uint64("numEntries", res.NumberOfKeys), zap.Error(err))
+		res.Status = newErrorStatus(err)
+		return loadSrvr.SendAndClose(res)
+	}
+	ss.opts.lgr.Info("Bulk loaded", zap.Uint64("numEntries", res.NumberOfKeys), zap.Uint64("changeNum", chngNum))
+	res.Status = newEmptyStatus()
+	return loadSrvr.SendAndClose(res)
 }
 
-func (ss *standaloneService) Restore(ctx context.Context, restoreReq *serverpb.RestoreRequest) (*serverpb.Status, error) {
-	rstrPath := restoreReq.RestorePath
-	if err := ss.br.RestoreFrom(rstrPath); err != nil {
-		return newErrorStatus(err), err
+// bulkLoad writes the received entries onto the store as they arrive,
+// and reports whether any of them were attempted to be written.
+func (ss *standaloneService) bulkLoad(loadSrvr serverpb.DKVBackupRestore_BulkLoadServer, job *backupJob) (bool, error) {
+	loaded := false
+	for {
+		loadReq, err := loadSrvr.Recv()
+		if err == io.EOF {
+			return loaded, nil
+		}
+		if err != nil {
+			return loaded, err
+		}
+		if err = validateMultiPut(&serverpb.MultiPutRequest{PutRequests: loadReq.Entries}, ss.opts); err != nil {
+			return loaded, err
+		}
+		nsPuts, err := storage.NamespacedPuts(loadReq.Entries...)
+		if err != nil {
+			return loaded, err
+		}
+		if len(nsPuts) == 0 {
+			continue
+		}
+		loaded = true
+		if err = storage.BulkLoad(ss.store, ss.opts.compressPuts(nsPuts...)...); err != nil {
+			return loaded, err
+		}
+		atomic.AddUint64(&job.keys, uint64(len(nsPuts)))
 	}
-	return newEmptyStatus(), nil
 }
 
 func (ss *standaloneService) Close() error {
+	atomic.StoreUint32(&ss.closed, 1)
+	ss.stopRetainer()
+	ss.lstnrs.close()
+	ss.bckpJobs.close()
+	ss.bckpJobs.wait()
 	ss.store.Close()
 	return nil
 }
 
+// Shutdown cancels the backup or restore job being run, if any, and
+// waits for it to end before persisting the writes buffered by the
+// underlying storage and closing it. The mutations queued for the
+// listeners are delivered in the meantime.
+func (ss *standaloneService) Shutdown(ctx context.Context) error {
+	atomic.StoreUint32(&ss.closed, 1)
+	ss.stopRetainer()
+	ss.lstnrs.close()
+	return awaitShutdown(ctx, func() error {
+		ss.bckpJobs.close()
+		ss.bckpJobs.wait()
+		ss.lstnrs.wait()
+		err := storage.Sync(ss.store)
+		ss.store.Close()
+		return err
+	})
+}
+
+func (ss *standaloneService) RegisterListener(lstnr Listener) {
+	ss.lstnrs.register(lstnr)
+}
+
+func (ss *standaloneService) DroppedMutations() uint64 {
+	return ss.lstnrs.dropped()
+}
+
+func (ss *standaloneService) stopRetainer() {
+	if ss.retainer != nil {
+		ss.retainer.Stop()
+	}
+}
+
+// awaitShutdown runs the given shutdown in the background and waits
+// for it to complete. Returns the error of the given context if it is
+// done first, while the shutdown still completes in the background.
+func awaitShutdown(ctx context.Context, shutdown func() error) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- shutdown()
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // A DKVClusterService represents a service for serving key value data
 // along with exposing all mutations as a replication stream. Moreover
 // it also provides means to add and remove DKV nodes onto the current
@@ -111,67 +1320,662 @@ type DKVClusterService interface {
 
 type distributedService struct {
 	DKVService
+	// Serves the backups and restores of the local node alone
+	local    *standaloneService
 	raftRepl nexus_api.RaftReplicator
+	opts     *dkvServiceOpts
+	hlthSrvr grpc_health_v1.HealthServer
+	members  *clusterMembers
+	decoms   *decommissions
+	fence    *writeFence
+	// Shall be manipulated using atomics
+	closed uint32
+}
+
+// A leadershipReporter is implemented by the RAFT replicators
+// that can tell whether the local node leads its cluster.
+type leadershipReporter interface {
+	IsLeader() bool
 }
 
 // NewDistributedService creates a distributed variant of the DKV service
 // that attempts to replicate data across multiple replicas over Nexus.
-func NewDistributedService(kvs storage.KVStore, cp storage.ChangePropagator, br storage.Backupable, raftRepl nexus_api.RaftReplicator) DKVClusterService {
-	return &distributedService{NewStandaloneService(kvs, cp, br), raftRepl}
+func NewDistributedService(kvs storage.KVStore, cp storage.ChangePropagator, br storage.Backupable, raftRepl nexus_api.RaftReplicator, opts ...DKVServiceOption) DKVClusterService {
+	dkvSvcOpts := newDKVServiceOpts(opts...)
+	local := newStandaloneService(kvs, cp, br, dkvSvcOpts)
+	ds := &distributedService{DKVService: local, local: local, raftRepl: raftRepl, opts: dkvSvcOpts}
+	ds.members = newClusterMembers(dkvSvcOpts.nodeID, dkvSvcOpts.nodeURLs, dkvSvcOpts.dkvAddrs)
+	ds.decoms = newDecommissions()
+	ds.fence = newWriteFence()
+	ds.hlthSrvr = health.NewServer(ds.servingStatus)
+	if dkvSvcOpts.quotas != nil {
+		dkvSvcOpts.quotas.RetainThrough(&replicatedStore{kvs, ds})
+	}
+	return ds
+}
+
+// replicatedStore replicates the puts and deletes of single keys across
+// the cluster, while reading from the local store.
+type replicatedStore struct {
+	storage.KVStore
+	ds *distributedService
+}
+
+func (rs *replicatedStore) Put(key []byte, value []byte) error {
+	reqBts, err := proto.Marshal(&raftpb.InternalRaftRequest{Put: &serverpb.PutRequest{Key: key, Value: value}})
+	if err != nil {
+		return err
+	}
+	_, err = rs.ds.replicate(context.Background(), reqBts)
+	return err
+}
+
+func (rs *replicatedStore) Delete(keys ...[]byte) error {
+	for _, key := range keys {
+		reqBts, err := proto.Marshal(&raftpb.InternalRaftRequest{Delete: &serverpb.DeleteRequest{Key: key}})
+		if err != nil {
+			return err
+		}
+		if _, err = rs.ds.replicate(context.Background(), reqBts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check reports the serving status of this node of the cluster.
+func (ds *distributedService) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	return ds.hlthSrvr.Check(ctx, req)
+}
+
+// Watch streams the serving status of this node of the cluster
+// whenever it changes.
+func (ds *distributedService) Watch(req *grpc_health_v1.HealthCheckRequest, watchSrvr grpc_health_v1.Health_WatchServer) error {
+	return ds.hlthSrvr.Watch(req, watchSrvr)
+}
+
+// servingStatus reports the distributed service as serving only
+// while it is open, not in maintenance and leads its cluster.
+// Leadership is known only if the RAFT replicator reports it,
+// failing which every such node is reported as serving.
+func (ds *distributedService) servingStatus() grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if inMaint, _ := ds.opts.maint.Status(); inMaint || atomic.LoadUint32(&ds.closed) == 1 {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	if lr, ok := ds.raftRepl.(leadershipReporter); ok && !lr.IsLeader() {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+// replicate replicates the given request across the cluster over Nexus,
+// unless the writes are fenced by a ClusterBackup or ClusterRestore, or
+// the local node is in maintenance.
+func (ds *distributedService) replicate(ctx context.Context, reqBts []byte) ([]byte, error) {
+	if err := ds.opts.maint.CheckWritable(); err != nil {
+		return nil, err
+	}
+	if err := ds.fence.enter(); err != nil {
+		return nil, err
+	}
+	defer ds.fence.exit()
+	return ds.raftReplicate(ctx, reqBts)
+}
+
+// raftReplicate replicates the given request across the cluster over
+// Nexus regardless of the write fence. Failures of the nodes that do not
+// lead their cluster are conveyed as ErrNotLeader, along with the leader
+// of the cluster if known, so that callers can retry on the leader.
+// Leadership is known only if the RAFT replicator reports it.
+func (ds *distributedService) raftReplicate(ctx context.Context, reqBts []byte) ([]byte, error) {
+	ctx, span := tracing.StartSpan(ctx, "nexus.Replicate")
+	res, err := ds.raftRepl.Replicate(ctx, reqBts)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		lr, ok := ds.raftRepl.(leadershipReporter)
+		if (ok && !lr.IsLeader()) || errors.Is(err, dkverrors.ErrNotLeader) {
+			err = dkverrors.WithLeaderHint(err, ds.members.leaderHint(ds.raftRepl))
+		}
+	}
+	return res, err
+}
+
+// checkLeader fails with ErrNotLeader, along with the leader of the
+// cluster if known, unless the local node leads its cluster. Changes and
+// checkpoints are served by the leader alone, so that the slave nodes
+// fail over onto it rather than replicating from a lagging follower.
+// Leadership is known only if the RAFT replicator reports it.
+func (ds *distributedService) checkLeader() error {
+	if lr, ok := ds.raftRepl.(leadershipReporter); ok && !lr.IsLeader() {
+		return dkverrors.WithLeaderHint(dkverrors.ErrNotLeader, ds.members.leaderHint(ds.raftRepl))
+	}
+	return nil
+}
+
+// GetChanges fails on the followers, which still report their latest
+// committed change number, through which the progress of every member
+// is tracked.
+func (ds *distributedService) GetChanges(ctx context.Context, getChngsReq *serverpb.GetChangesRequest) (*serverpb.GetChangesResponse, error) {
+	if err := ds.checkLeader(); err != nil {
+		res := &serverpb.GetChangesResponse{Status: newErrorStatus(err)}
+		if ds.local.cp != nil {
+			res.MasterChangeNumber, _ = ds.local.cp.GetLatestCommittedChangeNumber()
+		}
+		return res, nil
+	}
+	return ds.local.GetChanges(ctx, getChngsReq)
+}
+
+func (ds *distributedService) StreamChanges(getChngsReq *serverpb.GetChangesRequest, chngsSrvr serverpb.DKVReplication_StreamChangesServer) error {
+	if err := ds.checkLeader(); err != nil {
+		return chngsSrvr.Send(&serverpb.GetChangesResponse{Status: newErrorStatus(err)})
+	}
+	return ds.local.StreamChanges(getChngsReq, &leaderChangesStream{chngsSrvr, ds})
+}
+
+// leaderChangesStream ends the stream of changes once the local node no
+// longer leads its cluster, which is noticed within the interval of the
+// heartbeats, after sending the NotLeader status.
+type leaderChangesStream struct {
+	serverpb.DKVReplication_StreamChangesServer
+	ds *distributedService
+}
+
+func (lcs *leaderChangesStream) Send(res *serverpb.GetChangesResponse) error {
+	if err := lcs.ds.checkLeader(); err != nil {
+		lcs.DKVReplication_StreamChangesServer.Send(&serverpb.GetChangesResponse{Status: newErrorStatus(err)})
+		return err
+	}
+	return lcs.DKVReplication_StreamChangesServer.Send(res)
+}
+
+func (ds *distributedService) GetCheckpoint(chkptReq *serverpb.GetCheckpointRequest, chkptSrvr serverpb.DKVReplication_GetCheckpointServer) error {
+	if err := ds.checkLeader(); err != nil {
+		return chkptSrvr.Send(&serverpb.GetCheckpointResponse{Status: newErrorStatus(err)})
+	}
+	return ds.local.GetCheckpoint(chkptReq, chkptSrvr)
 }
 
 func (ds *distributedService) Put(ctx context.Context, putReq *serverpb.PutRequest) (*serverpb.PutResponse, error) {
-	reqBts, err := proto.Marshal(&raftpb.InternalRaftRequest{Put: putReq})
-	res := &serverpb.PutResponse{Status: newEmptyStatus()}
+	if err := ds.opts.checkEntry(putReq.Namespace, putReq.Key, putReq.Value); err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	nsPuts, err := storage.NamespacedPuts(putReq)
+	if err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	compPuts := ds.opts.compressPuts(nsPuts...)
+	rsrv, err := ds.opts.quotas.Reserve(putQuotaMutations([]*serverpb.PutRequest{putReq}, compPuts)...)
+	if err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	reqBts, err := proto.Marshal(&raftpb.InternalRaftRequest{Put: compPuts[0]})
+	if err == nil {
+		_, err = ds.replicate(ctx, reqBts)
+	}
+	if err != nil {
+		rsrv.Cancel()
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	ds.local.lstnrs.notifyPuts(nsPuts...)
+	return &serverpb.PutResponse{Status: newEmptyStatus()}, nil
+}
+
+func (ds *distributedService) MultiPut(ctx context.Context, multiPutReq *serverpb.MultiPutRequest) (*serverpb.MultiPutResponse, error) {
+	if err := validateMultiPut(multiPutReq, ds.opts); err != nil {
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	nsPuts, err := storage.NamespacedPuts(multiPutReq.PutRequests...)
+	if err != nil {
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	compPuts := ds.opts.compressPuts(nsPuts...)
+	rsrv, err := ds.opts.quotas.Reserve(putQuotaMutations(multiPutReq.PutRequests, compPuts)...)
+	if err != nil {
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	reqBts, err := proto.Marshal(&raftpb.InternalRaftRequest{MultiPut: &serverpb.MultiPutRequest{PutRequests: compPuts}})
+	if err == nil {
+		_, err = ds.replicate(ctx, reqBts)
+	}
+	if err != nil {
+		rsrv.Cancel()
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	ds.local.lstnrs.notifyPuts(nsPuts...)
+	return &serverpb.MultiPutResponse{Status: newEmptyStatus()}, nil
+}
+
+func (ds *distributedService) CompareAndSet(ctx context.Context, casReq *serverpb.CompareAndSetRequest) (*serverpb.CompareAndSetResponse, error) {
+	if err := ds.opts.checkEntry(casReq.Namespace, casReq.Key, casReq.NewValue); err != nil {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(casReq.Namespace, casReq.Key)
+	if err != nil {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(err)}, nil
+	}
+	nsCASReq := &serverpb.CompareAndSetRequest{Key: key, ExpectedValue: casReq.ExpectedValue, NewValue: ds.opts.compress(casReq.NewValue)}
+	rsrv, err := ds.opts.quotas.Reserve(storage.QuotaMutation{Namespace: casReq.Namespace, Key: key, Value: nsCASReq.NewValue})
+	if err != nil {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(err)}, nil
+	}
+	reqBts, err := proto.Marshal(&raftpb.InternalRaftRequest{CompareAndSet: nsCASReq})
+	res := &serverpb.CompareAndSetResponse{Status: newEmptyStatus()}
 	if err != nil {
 		res.Status = newErrorStatus(err)
 	} else {
-		if _, err = ds.raftRepl.Replicate(ctx, reqBts); err != nil {
+		var casRes []byte
+		if casRes, err = ds.replicate(ctx, reqBts); err != nil {
 			res.Status = newErrorStatus(err)
+		} else {
+			if res.Updated = len(casRes) == 1 && casRes[0] == 1; res.Updated {
+				ds.local.lstnrs.notify(keyMutation{key: key, value: casReq.NewValue})
+			}
 		}
 	}
-	return res, err
+	if !res.Updated {
+		rsrv.Cancel()
+	}
+	return res, nil
+}
+
+func (ds *distributedService) Increment(ctx context.Context, incReq *serverpb.IncrementRequest) (*serverpb.IncrementResponse, error) {
+	if err := ds.opts.checkEntry(incReq.Namespace, incReq.Key, nil); err != nil {
+		return &serverpb.IncrementResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(incReq.Namespace, incReq.Key)
+	if err != nil {
+		return &serverpb.IncrementResponse{Status: newErrorStatus(err)}, nil
+	}
+	rsrv, err := ds.opts.quotas.Reserve(storage.QuotaMutation{Namespace: incReq.Namespace, Key: key, Value: encodeCounter(0)})
+	if err != nil {
+		return &serverpb.IncrementResponse{Status: newErrorStatus(err)}, nil
+	}
+	reqBts, err := proto.Marshal(&raftpb.InternalRaftRequest{Increment: &serverpb.IncrementRequest{Key: key, Delta: incReq.Delta}})
+	res := &serverpb.IncrementResponse{Status: newEmptyStatus()}
+	if err != nil {
+		rsrv.Cancel()
+		res.Status = newErrorStatus(err)
+	} else {
+		var incRes []byte
+		if incRes, err = ds.replicate(ctx, reqBts); err != nil {
+			rsrv.Cancel()
+			res.Status = newErrorStatus(err)
+		} else if len(incRes) == 8 {
+			res.Value = int64(binary.BigEndian.Uint64(incRes))
+			ds.local.lstnrs.notify(keyMutation{key: key, value: incRes})
+		} else {
+			rsrv.Cancel()
+			res.Status = newErrorStatus(fmt.Errorf("increment of key %q applied with a result of %d bytes: %w", incReq.Key, len(incRes), dkverrors.ErrMalformedResponse))
+		}
+	}
+	return res, nil
+}
+
+func (ds *distributedService) Txn(ctx context.Context, txnReq *serverpb.TxnRequest) (*serverpb.TxnResponse, error) {
+	if err := ds.opts.validateTxn(txnReq); err != nil {
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	nsTxnReq, err := storage.NamespacedTxn(txnReq, ds.opts.compress)
+	if err != nil {
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	// Either branch may be applied, hence both must be admitted
+	thenRsrv, err := ds.opts.quotas.Reserve(storage.TxnQuotaMutations(txnReq.Namespace, nsTxnReq, true)...)
+	if err != nil {
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	elseRsrv, err := ds.opts.quotas.Reserve(storage.TxnQuotaMutations(txnReq.Namespace, nsTxnReq, false)...)
+	if err != nil {
+		thenRsrv.Cancel()
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	res := &serverpb.TxnResponse{Status: newEmptyStatus()}
+	res.Succeeded, err = ds.replicateTxn(ctx, nsTxnReq)
+	if err != nil || !res.Succeeded {
+		thenRsrv.Cancel()
+	}
+	if err != nil || res.Succeeded {
+		elseRsrv.Cancel()
+	}
+	if err != nil {
+		res.Status = newErrorStatus(err)
+	} else {
+		ds.local.lstnrs.notifyTxn(txnReq, nsTxnReq, res.Succeeded)
+	}
+	return res, nil
+}
+
+// replicateTxn replicates the given transaction, whose keys are those
+// stored, across the cluster and returns whether its conditions held.
+func (ds *distributedService) replicateTxn(ctx context.Context, nsTxnReq *serverpb.TxnRequest) (bool, error) {
+	reqBts, err := proto.Marshal(&raftpb.InternalRaftRequest{Txn: nsTxnReq})
+	if err != nil {
+		return false, err
+	}
+	txnRes, err := ds.replicate(ctx, reqBts)
+	return len(txnRes) == 1 && txnRes[0] == 1, err
+}
+
+func (ds *distributedService) Delete(ctx context.Context, delReq *serverpb.DeleteRequest) (*serverpb.DeleteResponse, error) {
+	if err := ds.opts.keyPolicy.Check(delReq.Namespace, delReq.Key); err != nil {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(delReq.Namespace, delReq.Key)
+	if err != nil {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	rsrv, err := ds.opts.quotas.Reserve(storage.QuotaMutation{Namespace: delReq.Namespace, Key: key, Delete: true})
+	if err != nil {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err = ds.delete(ctx, key); err != nil {
+		rsrv.Cancel()
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	ds.local.lstnrs.notify(keyMutation{del: true, key: key})
+	return &serverpb.DeleteResponse{Status: newEmptyStatus()}, nil
+}
+
+// delete deletes the given key across the cluster, retaining it under
+// a tombstone if the service retains tombstones and the key is present.
+// Since the tombstone is replicated as a transaction conditioned on the
+// value of the key, it is applied alike by all the members.
+func (ds *distributedService) delete(ctx context.Context, key []byte) error {
+	if ds.opts.tombRetain > 0 {
+		deleted, err := storage.SoftDelete(ds.local.store, key, ds.opts.tombRetain, func(txnReq *serverpb.TxnRequest) (bool, error) {
+			return ds.replicateTxn(ctx, txnReq)
+		})
+		if err != nil || deleted {
+			return err
+		}
+	}
+	reqBts, err := proto.Marshal(&raftpb.InternalRaftRequest{Delete: &serverpb.DeleteRequest{Key: key}})
+	if err != nil {
+		return err
+	}
+	_, err = ds.replicate(ctx, reqBts)
+	return err
+}
+
+func (ds *distributedService) Undelete(ctx context.Context, undelReq *serverpb.UndeleteRequest) (*serverpb.UndeleteResponse, error) {
+	if err := ds.opts.keyPolicy.Check(undelReq.Namespace, undelReq.Key); err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(undelReq.Namespace, undelReq.Key)
+	if err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	value, err := storage.Undelete(ds.local.store, key, func(txnReq *serverpb.TxnRequest) (bool, error) {
+		// Restored key, put by the first mutation, is admitted against the
+		// quota while its tombstone is not accounted for by any namespace
+		restore := txnReq.ThenMutations[0]
+		rsrv, err := ds.opts.quotas.Reserve(storage.QuotaMutation{Namespace: undelReq.Namespace, Key: key, Value: restore.Value})
+		if err != nil {
+			return false, err
+		}
+		succeeded, err := ds.replicateTxn(ctx, txnReq)
+		if err != nil || !succeeded {
+			rsrv.Cancel()
+		}
+		return succeeded, err
+	})
+	if err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	if value, err = ds.opts.decompress(value); err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	ds.local.lstnrs.notify(keyMutation{key: key, value: value})
+	return &serverpb.UndeleteResponse{Status: newEmptyStatus()}, nil
 }
 
 func (ds *distributedService) Get(ctx context.Context, getReq *serverpb.GetRequest) (*serverpb.GetResponse, error) {
-	// TODO: Check for consistency level of GetRequest and process this either via local state or RAFT
+	if getReq.ReadConsistency == serverpb.ReadConsistency_Linearizable {
+		if err := ds.readBarrier(ctx, getReq); err != nil {
+			return &serverpb.GetResponse{Status: newErrorStatus(err)}, nil
+		}
+	}
 	return ds.DKVService.Get(ctx, getReq)
 }
 
+// readBarrier replicates the given read across the cluster over Nexus,
+// which is applied onto this node only after every change committed
+// before it. Hence the local state read after this barrier reflects
+// all those changes. Reads are replicated despite the write fence,
+// since they leave the keyspace untouched.
+func (ds *distributedService) readBarrier(ctx context.Context, getReq *serverpb.GetRequest) error {
+	key, err := storage.NamespacedKey(getReq.Namespace, getReq.Key)
+	if err != nil {
+		return err
+	}
+	reqBts, err := proto.Marshal(&raftpb.InternalRaftRequest{Get: &serverpb.GetRequest{Key: key}})
+	if err != nil {
+		return err
+	}
+	_, err = ds.raftReplicate(ctx, reqBts)
+	return err
+}
+
 func (ds *distributedService) MultiGet(ctx context.Context, multiGetReq *serverpb.MultiGetRequest) (*serverpb.MultiGetResponse, error) {
 	// TODO: Check for consistency level of MultiGetRequest and process this either via local state or RAFT
 	return ds.DKVService.MultiGet(ctx, multiGetReq)
 }
 
+func (ds *distributedService) Exists(ctx context.Context, existsReq *serverpb.ExistsRequest) (*serverpb.ExistsResponse, error) {
+	// TODO: Check for consistency level of ExistsRequest and process this either via local state or RAFT
+	return ds.DKVService.Exists(ctx, existsReq)
+}
+
+func (ds *distributedService) Iterate(iterReq *serverpb.IterateRequest, dkvIterSrvr serverpb.DKV_IterateServer) error {
+	// TODO: Check for consistency level of IterateRequest and process this either via local state or RAFT
+	return ds.DKVService.Iterate(iterReq, dkvIterSrvr)
+}
+
 func (ds *distributedService) Restore(ctx context.Context, restoreReq *serverpb.RestoreRequest) (*serverpb.Status, error) {
 	err := errors.New("Current DKV instance does not support restores")
-	return newErrorStatus(err), err
+	return newErrorStatus(err), nil
+}
+
+func (ds *distributedService) StartRestore(ctx context.Context, restoreReq *serverpb.RestoreRequest) (*serverpb.BackupResponse, error) {
+	err := errors.New("Current DKV instance does not support restores")
+	return &serverpb.BackupResponse{Status: newErrorStatus(err)}, nil
+}
+
+func (ds *distributedService) StreamRestore(rstrSrvr serverpb.DKVBackupRestore_StreamRestoreServer) error {
+	err := errors.New("Current DKV instance does not support restores")
+	return rstrSrvr.SendAndClose(newErrorStatus(err))
+}
+
+func (ds *distributedService) BulkLoad(loadSrvr serverpb.DKVBackupRestore_BulkLoadServer) error {
+	err := errors.New("Current DKV instance does not support bulk loads")
+	return loadSrvr.SendAndClose(&serverpb.BulkLoadResponse{Status: newErrorStatus(err)})
 }
 
 func (ds *distributedService) AddNode(ctx context.Context, req *serverpb.AddNodeRequest) (*serverpb.Status, error) {
 	// TODO: We can include any relevant checks on the joining node - like reachability, storage engine compatibility, etc.
 	if err := ds.raftRepl.AddMember(ctx, int(req.NodeId), req.NodeUrl); err != nil {
-		return newErrorStatus(err), err
+		ds.opts.lgr.Error("Unable to add node", zap.Uint32("nodeID", req.NodeId), zap.String("nodeURL", req.NodeUrl), zap.Error(err))
+		return newErrorStatus(err), nil
 	}
+	ds.members.add(uint64(req.NodeId), req.NodeUrl)
+	ds.opts.lgr.Info("Added node", zap.Uint32("nodeID", req.NodeId), zap.String("nodeURL", req.NodeUrl))
 	return newEmptyStatus(), nil
 }
 
 func (ds *distributedService) RemoveNode(ctx context.Context, req *serverpb.RemoveNodeRequest) (*serverpb.Status, error) {
 	if err := ds.raftRepl.RemoveMember(ctx, int(req.NodeId)); err != nil {
-		return newErrorStatus(err), err
+		ds.opts.lgr.Error("Unable to remove node", zap.Uint32("nodeID", req.NodeId), zap.Error(err))
+		return newErrorStatus(err), nil
 	}
+	ds.members.remove(uint64(req.NodeId))
+	ds.opts.lgr.Info("Removed node", zap.Uint32("nodeID", req.NodeId))
 	return newEmptyStatus(), nil
 }
 
+func (ds *distributedService) ListNodes(ctx context.Context, req *serverpb.ListNodesRequest) (*serverpb.ListNodesResponse, error) {
+	leaderID, nodes, err := ds.members.list(ctx, ds.raftRepl)
+	if err != nil {
+		ds.opts.lgr.Error("Unable to list nodes", zap.Error(err))
+		return &serverpb.ListNodesResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.ListNodesResponse{Status: newEmptyStatus(), LeaderId: uint32(leaderID), Nodes: nodes}, nil
+}
+
 func (ds *distributedService) Close() error {
+	atomic.StoreUint32(&ds.closed, 1)
+	ds.decoms.close()
+	ds.local.stopRetainer()
+	ds.local.lstnrs.close()
+	ds.local.bckpJobs.close()
+	ds.local.bckpJobs.wait()
 	ds.raftRepl.Stop()
 	return nil
 }
 
+// Shutdown cancels the decommissions and the backup or restore job of
+// the local node that are in progress, and waits for the latter before
+// stopping the replicator. Stopping it persists the writes buffered by
+// the underlying storage before closing it.
+func (ds *distributedService) Shutdown(ctx context.Context) error {
+	atomic.StoreUint32(&ds.closed, 1)
+	ds.local.stopRetainer()
+	ds.local.lstnrs.close()
+	return awaitShutdown(ctx, func() error {
+		ds.decoms.close()
+		ds.local.bckpJobs.close()
+		ds.local.bckpJobs.wait()
+		ds.local.lstnrs.wait()
+		ds.raftRepl.Stop()
+		return nil
+	})
+}
+
+// validateMultiPut ensures every entry of the given request can be
+// stored, so that a bad entry is identified before any of the entries
+// are applied onto the underlying storage. Returns the error of the
+// first bad entry, if any.
+func validateMultiPut(multiPutReq *serverpb.MultiPutRequest, opts *dkvServiceOpts) error {
+	for i, putReq := range multiPutReq.PutRequests {
+		if putReq == nil || len(putReq.Key) == 0 {
+			return fmt.Errorf("MultiPut entry at index %d has an empty key: %w", i, dkverrors.ErrInvalidArgument)
+		}
+		if err := opts.checkEntry(putReq.Namespace, putReq.Key, putReq.Value); err != nil {
+			return fmt.Errorf("MultiPut entry at index %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// checkEntry ensures that the given entry of the given namespace
+// conforms to both the size limits and the key policies.
+func (opts *dkvServiceOpts) checkEntry(namespace string, key, value []byte) error {
+	if err := opts.sizeLimits.Check(key, value); err != nil {
+		return err
+	}
+	return opts.keyPolicy.Check(namespace, key)
+}
+
+// validateTxn ensures that the given transaction is well formed, and
+// that its mutations conform to both the size limits and key policies.
+func (opts *dkvServiceOpts) validateTxn(txnReq *serverpb.TxnRequest) error {
+	if err := storage.ValidateTxn(txnReq, opts.sizeLimits); err != nil {
+		return err
+	}
+	return opts.keyPolicy.CheckTxn(txnReq)
+}
+
+// putQuotaMutations returns the given entries as stored, which are those
+// of the given requests, as mutations of the namespaces of the requests.
+func putQuotaMutations(putReqs, storedPuts []*serverpb.PutRequest) []storage.QuotaMutation {
+	muts := make([]storage.QuotaMutation, len(storedPuts))
+	for i, put := range storedPuts {
+		muts[i] = storage.QuotaMutation{Namespace: putReqs[i].Namespace, Key: put.Key, Value: put.Value}
+	}
+	return muts
+}
+
+// compressPuts returns the given entries with their values compressed
+// as per the codec, without modifying the given entries themselves.
+func (opts *dkvServiceOpts) compressPuts(putReqs ...*serverpb.PutRequest) []*serverpb.PutRequest {
+	if opts.codec == compression.None {
+		return putReqs
+	}
+	compPutReqs := make([]*serverpb.PutRequest, len(putReqs))
+	for i, putReq := range putReqs {
+		compPutReqs[i] = &serverpb.PutRequest{Key: putReq.Key, Value: opts.compress(putReq.Value), ExpireTS: putReq.ExpireTS}
+	}
+	return compPutReqs
+}
+
+func (opts *dkvServiceOpts) compress(value []byte) []byte {
+	return compression.Compress(opts.codec, value)
+}
+
+func (opts *dkvServiceOpts) decompress(value []byte) ([]byte, error) {
+	if opts.codec == compression.None {
+		return value, nil
+	}
+	return compression.Decompress(value)
+}
+
+// newErrorStatus conveys the given error through the status code
+// of its kind, so that clients can act upon it.
 func newErrorStatus(err error) *serverpb.Status {
-	return &serverpb.Status{Code: -1, Message: err.Error()}
+	return dkverrors.NewStatus(err)
+}
+
+// encodeCounter encodes the given value of a counter the way it is
+// stored, as a big-endian encoded 64 bit integer.
+func encodeCounter(value int64) []byte {
+	encVal := make([]byte, 8)
+	binary.BigEndian.PutUint64(encVal, uint64(value))
+	return encVal
+}
+
+// newKVResults creates the results of the keys read through
+// storage.GetEach, decompressing the values read.
+func newKVResults(vals [][]byte, found []bool, errs []error, decompress func([]byte) ([]byte, error)) []*serverpb.KVResult {
+	results := make([]*serverpb.KVResult, len(vals))
+	for i, val := range vals {
+		err := errs[i]
+		if err == nil {
+			val, err = decompress(val)
+		}
+		if err != nil {
+			results[i] = &serverpb.KVResult{Status: newErrorStatus(err)}
+		} else {
+			results[i] = &serverpb.KVResult{Status: newEmptyStatus(), Value: val, Found: found[i]}
+		}
+	}
+	return results
 }
 
 func newEmptyStatus() *serverpb.Status {
 	return &serverpb.Status{Code: 0, Message: ""}
 }
+
+// A changeNotifier broadcasts the commit of new changes to all
+// the change streams waiting on them.
+type changeNotifier struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+func newChangeNotifier() *changeNotifier {
+	return &changeNotifier{ch: make(chan struct{})}
+}
+
+// changes returns a channel that is closed upon the next commit.
+func (cn *changeNotifier) changes() <-chan struct{} {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+	return cn.ch
+}
+
+func (cn *changeNotifier) notify() {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+	close(cn.ch)
+	cn.ch = make(chan struct{})
+}
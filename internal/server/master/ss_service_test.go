@@ -1,18 +1,36 @@
 package master
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
+	"os"
 	"os/exec"
+	"path"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/flipkart-incubator/dkv/internal/compression"
 	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/server/maintenance"
 	"github.com/flipkart-incubator/dkv/internal/server/storage"
 	"github.com/flipkart-incubator/dkv/internal/server/storage/badger"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
 	"github.com/flipkart-incubator/dkv/internal/server/storage/rocksdb"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -41,11 +59,866 @@ func TestStandaloneService(t *testing.T) {
 		t.Run("testPutAndGet", testPutAndGet)
 		t.Run("testMultiGet", testMultiGet)
 		t.Run("testMissingGet", testMissingGet)
+		t.Run("testMultiPut", testMultiPut)
+		t.Run("testExists", testExists)
+		t.Run("testCompareAndSet", testCompareAndSet)
+		t.Run("testIncrement", testIncrement)
+		t.Run("testTxn", testTxn)
+		t.Run("testDelete", testDelete)
+		t.Run("testIterate", testIterate)
 		t.Run("testGetChanges", testGetChanges)
+		t.Run("testStreamChanges", testStreamChanges)
 		t.Run("testBackupRestore", testBackupRestore)
 	}
 }
 
+func TestSizeLimits(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store, WithSizeLimits(storage.SizeLimits{MaxKeySize: 8, MaxValueSize: 8}))
+	defer svc.Close()
+
+	ctx := context.Background()
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("LargeKey1"), Value: []byte("V")}); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	} else if res.Status.Code != int32(serverpb.StatusCode_KeyTooLarge) {
+		t.Errorf("Expected a large key to be rejected. Status: %+v", res.Status)
+	}
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("K"), Value: []byte("LargeValue")}); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	} else if res.Status.Code != int32(serverpb.StatusCode_ValueTooLarge) {
+		t.Errorf("Expected a large value to be rejected. Status: %+v", res.Status)
+	}
+
+	multiPutReq := &serverpb.MultiPutRequest{PutRequests: []*serverpb.PutRequest{
+		{Key: []byte("MK1"), Value: []byte("MV1")},
+		{Key: []byte("MK2"), Value: []byte("LargeValue")},
+	}}
+	if res, err := svc.MultiPut(ctx, multiPutReq); err != nil {
+		t.Fatalf("Unable to MULTIPUT. Error: %v", err)
+	} else if res.Status.Code != int32(serverpb.StatusCode_ValueTooLarge) {
+		t.Errorf("Expected a MULTIPUT with a large value to be rejected. Status: %+v", res.Status)
+	}
+	if results, _ := store.Exists([]byte("K"), []byte("MK1")); results[0] || results[1] {
+		t.Errorf("Expected none of the rejected keys to be stored. Presence: %v", results)
+	}
+
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("K"), Value: []byte("V")}); err != nil || res.Status.Code != 0 {
+		t.Errorf("Expected an entry within the limits to be stored. Status: %+v, Error: %v", res.Status, err)
+	}
+}
+
+func TestKeyPolicies(t *testing.T) {
+	store := memory.OpenDB(0)
+	keyPolicies := storage.KeyPolicies{
+		Default:    storage.KeyPolicy{RejectEmpty: true, Pattern: regexp.MustCompile(`^/[a-z/]+$`)},
+		Namespaces: map[string]storage.KeyPolicy{"raw": {MaxLength: 4}},
+	}
+	svc := NewStandaloneService(store, store, store, WithKeyPolicies(keyPolicies), WithBulkLoads())
+	defer svc.Close()
+
+	ctx := context.Background()
+	checkInvalidKey := func(op string, status *serverpb.Status, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Unable to %s. Error: %v", op, err)
+		}
+		if status.Code != int32(serverpb.StatusCode_InvalidKey) {
+			t.Errorf("Expected %s of an invalid key to be rejected. Status: %+v", op, status)
+		}
+	}
+	putRes, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("NotAPath"), Value: []byte("V")})
+	checkInvalidKey("PUT", putRes.GetStatus(), err)
+	putRes, err = svc.Put(ctx, &serverpb.PutRequest{Key: []byte{}, Value: []byte("V")})
+	checkInvalidKey("PUT", putRes.GetStatus(), err)
+	putRes, err = svc.Put(ctx, &serverpb.PutRequest{Key: []byte("/raw/key"), Value: []byte("V"), Namespace: "raw"})
+	checkInvalidKey("PUT", putRes.GetStatus(), err)
+	multiPutRes, err := svc.MultiPut(ctx, &serverpb.MultiPutRequest{PutRequests: []*serverpb.PutRequest{
+		{Key: []byte("/users/alice"), Value: []byte("MV1")},
+		{Key: []byte("/users/Bob"), Value: []byte("MV2")},
+	}})
+	checkInvalidKey("MULTIPUT", multiPutRes.GetStatus(), err)
+	casRes, err := svc.CompareAndSet(ctx, &serverpb.CompareAndSetRequest{Key: []byte("NotAPath"), NewValue: []byte("V")})
+	checkInvalidKey("CAS", casRes.GetStatus(), err)
+	incRes, err := svc.Increment(ctx, &serverpb.IncrementRequest{Key: []byte("NotAPath"), Delta: 1})
+	checkInvalidKey("INCREMENT", incRes.GetStatus(), err)
+	txnRes, err := svc.Txn(ctx, &serverpb.TxnRequest{ThenMutations: []*serverpb.TrxnRecord{{Key: []byte("NotAPath"), Value: []byte("V"), Type: serverpb.TrxnRecord_Put}}})
+	checkInvalidKey("TXN", txnRes.GetStatus(), err)
+	delRes, err := svc.Delete(ctx, &serverpb.DeleteRequest{Key: []byte("NotAPath")})
+	checkInvalidKey("DELETE", delRes.GetStatus(), err)
+	loader := &bulkLoader{entries: []*serverpb.PutRequest{{Key: []byte("/users/carol"), Value: []byte("BV1")}, {Key: []byte("NotAPath"), Value: []byte("BV2")}}}
+	if err = svc.BulkLoad(loader); err != nil {
+		t.Fatalf("Unable to BULKLOAD. Error: %v", err)
+	}
+	checkInvalidKey("BULKLOAD", loader.res.GetStatus(), nil)
+	if results, _ := store.Exists([]byte("/users/alice"), []byte("/users/carol")); results[0] || results[1] {
+		t.Errorf("Expected none of the entries of the rejected requests to be stored. Presence: %v", results)
+	}
+
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("/users/alice"), Value: []byte("V")}); err != nil || res.Status.Code != 0 {
+		t.Errorf("Expected a valid key to be stored. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte{0x0a, 0xff}, Value: []byte("V"), Namespace: "raw"}); err != nil || res.Status.Code != 0 {
+		t.Errorf("Expected a key valid as per the policy of its namespace to be stored. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+}
+
+func TestNamespaceQuotas(t *testing.T) {
+	store := memory.OpenDB(0)
+	quotas, err := storage.NewQuotas(store, 0, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer quotas.Stop()
+	if err = quotas.Set("limited", storage.Quota{MaxKeys: 2}); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewStandaloneService(store, store, store, WithQuotas(quotas))
+	defer svc.Close()
+
+	ctx := context.Background()
+	checkQuotaExceeded := func(op string, status *serverpb.Status, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Unable to %s. Error: %v", op, err)
+		}
+		if status.Code != int32(serverpb.StatusCode_QuotaExceeded) {
+			t.Errorf("Expected %s beyond the quota to be rejected. Status: %+v", op, status)
+		}
+	}
+	multiPutRes, err := svc.MultiPut(ctx, &serverpb.MultiPutRequest{PutRequests: []*serverpb.PutRequest{
+		{Key: []byte("K1"), Value: []byte("V1"), Namespace: "limited"},
+		{Key: []byte("K2"), Value: []byte("V2"), Namespace: "limited"},
+	}})
+	if err != nil || multiPutRes.Status.Code != 0 {
+		t.Fatalf("Expected the keys within the quota to be stored. Status: %+v, Error: %v", multiPutRes.GetStatus(), err)
+	}
+	putRes, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("K3"), Value: []byte("V3"), Namespace: "limited"})
+	checkQuotaExceeded("PUT", putRes.GetStatus(), err)
+	incRes, err := svc.Increment(ctx, &serverpb.IncrementRequest{Key: []byte("K3"), Delta: 1, Namespace: "limited"})
+	checkQuotaExceeded("INCREMENT", incRes.GetStatus(), err)
+	txnRes, err := svc.Txn(ctx, &serverpb.TxnRequest{Namespace: "limited", ThenMutations: []*serverpb.TrxnRecord{{Key: []byte("K3"), Value: []byte("V3"), Type: serverpb.TrxnRecord_Put}}})
+	checkQuotaExceeded("TXN", txnRes.GetStatus(), err)
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("K1"), Value: []byte("V1.1"), Namespace: "limited"}); err != nil || res.Status.Code != 0 {
+		t.Errorf("Expected an update within the quota to be stored. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("K3"), Value: []byte("V3")}); err != nil || res.Status.Code != 0 {
+		t.Errorf("Expected a key of the default namespace to be stored. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+
+	// Deletes free up the quota
+	if res, err := svc.Delete(ctx, &serverpb.DeleteRequest{Key: []byte("K2"), Namespace: "limited"}); err != nil || res.Status.Code != 0 {
+		t.Fatalf("Expected the key to be deleted. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("K3"), Value: []byte("V3"), Namespace: "limited"}); err != nil || res.Status.Code != 0 {
+		t.Errorf("Expected the key to be stored once the quota is freed up. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if usage, _ := quotas.Get("limited"); usage.NumKeys != 2 {
+		t.Errorf("Expected the usage of 2 keys. Actual: %+v", usage.PrefixStats)
+	}
+}
+
+func TestSoftDeletes(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store, WithTombstoneRetention(time.Hour))
+	defer svc.Close()
+
+	ctx, key := context.Background(), []byte("K1")
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: key, Value: []byte("V1"), Namespace: "ns"}); err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to PUT. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if res, err := svc.Delete(ctx, &serverpb.DeleteRequest{Key: key, Namespace: "ns"}); err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to DELETE. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if res, err := svc.Get(ctx, &serverpb.GetRequest{Key: key, Namespace: "ns"}); err != nil || res.Found {
+		t.Errorf("Expected the deleted key to be absent. Response: %+v, Error: %v", res, err)
+	}
+	if res, err := svc.Undelete(ctx, &serverpb.UndeleteRequest{Key: key}); err != nil || res.Status.Code != int32(serverpb.StatusCode_KeyNotFound) {
+		t.Errorf("Expected the key of another namespace not to be restored. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if res, err := svc.Undelete(ctx, &serverpb.UndeleteRequest{Key: key, Namespace: "ns"}); err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to UNDELETE. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if res, err := svc.Get(ctx, &serverpb.GetRequest{Key: key, Namespace: "ns"}); err != nil || string(res.Value) != "V1" {
+		t.Errorf("Expected the restored value V1. Response: %+v, Error: %v", res, err)
+	}
+	if res, err := svc.Undelete(ctx, &serverpb.UndeleteRequest{Key: key, Namespace: "ns"}); err != nil || res.Status.Code != int32(serverpb.StatusCode_KeyExists) {
+		t.Errorf("Expected the restored key not to be restored again. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+
+	// Keys stored afresh since their deletion are left alone
+	if _, err := svc.Delete(ctx, &serverpb.DeleteRequest{Key: key, Namespace: "ns"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Put(ctx, &serverpb.PutRequest{Key: key, Value: []byte("V2"), Namespace: "ns"}); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := svc.Undelete(ctx, &serverpb.UndeleteRequest{Key: key, Namespace: "ns"}); err != nil || res.Status.Code != int32(serverpb.StatusCode_KeyExists) {
+		t.Errorf("Expected the key stored afresh not to be restored. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if res, err := svc.Get(ctx, &serverpb.GetRequest{Key: key, Namespace: "ns"}); err != nil || string(res.Value) != "V2" {
+		t.Errorf("Expected the value V2 stored afresh. Response: %+v, Error: %v", res, err)
+	}
+}
+
+// bulkLoader streams the given entries onto a BulkLoad call
+type bulkLoader struct {
+	grpc.ServerStream
+	entries []*serverpb.PutRequest
+	res     *serverpb.BulkLoadResponse
+}
+
+func (bl *bulkLoader) Recv() (*serverpb.BulkLoadRequest, error) {
+	if len(bl.entries) == 0 {
+		return nil, io.EOF
+	}
+	req := &serverpb.BulkLoadRequest{Entries: bl.entries}
+	bl.entries = nil
+	return req, nil
+}
+
+func (bl *bulkLoader) SendAndClose(res *serverpb.BulkLoadResponse) error {
+	bl.res = res
+	return nil
+}
+
+func TestStandaloneClusterServer(t *testing.T) {
+	clusterSrvr := NewStandaloneClusterServer()
+	if _, err := clusterSrvr.ListNodes(context.Background(), &serverpb.ListNodesRequest{}); status.Code(err) != codes.Unimplemented || !strings.Contains(err.Error(), "standalone") {
+		t.Errorf("Expected the cluster methods to be unimplemented on standalone nodes. Error: %v", err)
+	}
+	if _, err := clusterSrvr.AddNode(context.Background(), &serverpb.AddNodeRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected the cluster methods to be unimplemented on standalone nodes. Error: %v", err)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store, WithCompression(compression.Zstd))
+	defer svc.Close()
+
+	ctx := context.Background()
+	key, value := []byte("CompKey"), []byte(strings.Repeat("CompValue", 20))
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: key, Value: value}); err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to PUT. Status: %+v, Error: %v", res.Status, err)
+	}
+	if vals, _, _ := store.Get(key); !compression.IsCompressed(vals[0]) || len(vals[0]) >= len(value) {
+		t.Errorf("Expected the value to be compressed at rest. Stored value: %q", vals[0])
+	}
+	if res, err := svc.Get(ctx, &serverpb.GetRequest{Key: key}); err != nil || !bytes.Equal(res.Value, value) {
+		t.Errorf("GET mismatch. Expected Value: %s, Actual Value: %s, Error: %v", value, res.Value, err)
+	}
+
+	newValue := []byte(strings.Repeat("NewCompValue", 20))
+	if res, err := svc.CompareAndSet(ctx, &serverpb.CompareAndSetRequest{Key: key, ExpectedValue: value, NewValue: newValue}); err != nil || !res.Updated {
+		t.Errorf("Expected CAS to match the compressed value. Response: %+v, Error: %v", res, err)
+	}
+	if res, err := svc.MultiGet(ctx, &serverpb.MultiGetRequest{Keys: [][]byte{key}}); err != nil || !bytes.Equal(res.Values[0], newValue) {
+		t.Errorf("MULTIGET mismatch. Expected Value: %s, Actual Values: %q, Error: %v", newValue, res.Values, err)
+	}
+
+	// Values compressed by clients using another codec match once decompressed
+	clientValue := compression.Compress(compression.Snappy, newValue)
+	if res, err := svc.CompareAndSet(ctx, &serverpb.CompareAndSetRequest{Key: key, ExpectedValue: clientValue, NewValue: value}); err != nil || !res.Updated {
+		t.Errorf("Expected CAS to match the value compressed using another codec. Response: %+v, Error: %v", res, err)
+	}
+
+	// Values stored before the compression match as they are
+	rawKey := []byte("RawKey")
+	store.Put(rawKey, value)
+	txnReq := &serverpb.TxnRequest{
+		Conditions:    []*serverpb.TxnCondition{{Key: key, Value: value}, {Key: rawKey, Value: compression.Compress(compression.Snappy, value)}},
+		ThenMutations: []*serverpb.TrxnRecord{{Type: serverpb.TrxnRecord_Put, Key: rawKey, Value: newValue}},
+	}
+	if res, err := svc.Txn(ctx, txnReq); err != nil || !res.Succeeded {
+		t.Errorf("Expected the Txn conditions to match the decompressed values. Response: %+v, Error: %v", res, err)
+	}
+
+	// Corrupt values are reported rather than served as they are
+	corruptValue := compression.Compress(compression.Zstd, value)
+	corruptValue[len(corruptValue)-1]++
+	store.Put(key, corruptValue)
+	if res, err := svc.Get(ctx, &serverpb.GetRequest{Key: key}); err != nil || res.Status.Code == 0 {
+		t.Errorf("Expected GET of the corrupt value to fail. Response: %+v, Error: %v", res, err)
+	}
+}
+
+// syncRecorder records whether every batch of puts written onto it
+// is synced onto the disk
+type syncRecorder struct {
+	storage.KVStore
+	syncs []bool
+}
+
+func (sr *syncRecorder) MultiPutWithSync(sync bool, puts ...*serverpb.PutRequest) error {
+	sr.syncs = append(sr.syncs, sync)
+	return sr.KVStore.MultiPut(puts...)
+}
+
+func TestSyncModes(t *testing.T) {
+	ctx := context.Background()
+	put := func(key string, mode serverpb.SyncMode) *serverpb.PutRequest {
+		return &serverpb.PutRequest{Key: []byte(key), Value: []byte(key), SyncMode: mode}
+	}
+	for _, tc := range []struct {
+		name     string
+		opts     []DKVServiceOption
+		expSyncs []bool
+	}{
+		{"SyncByDefault", nil, []bool{true, true, false, true, false}},
+		{"AsyncByDefault", []DKVServiceOption{WithDefaultSyncMode(serverpb.SyncMode_Async)}, []bool{false, true, false, true, false}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := memory.OpenDB(0)
+			rec := &syncRecorder{KVStore: store}
+			svc := NewStandaloneService(rec, store, store, tc.opts...)
+			defer svc.Close()
+
+			for _, putReq := range []*serverpb.PutRequest{put("DefKey", serverpb.SyncMode_DefaultSync), put("SyncKey", serverpb.SyncMode_Sync), put("AsyncKey", serverpb.SyncMode_Async)} {
+				if res, err := svc.Put(ctx, putReq); err != nil || res.Status.Code != 0 {
+					t.Fatalf("Unable to PUT. Key: %s, Status: %+v, Error: %v", putReq.Key, res.Status, err)
+				}
+			}
+			// Batches are synced if any of their puts is
+			multiPuts := [][]*serverpb.PutRequest{
+				{put("MultiKey1", serverpb.SyncMode_Async), put("MultiKey2", serverpb.SyncMode_Sync)},
+				{put("MultiKey3", serverpb.SyncMode_Async), put("MultiKey4", serverpb.SyncMode_Async)},
+			}
+			for _, putReqs := range multiPuts {
+				if res, err := svc.MultiPut(ctx, &serverpb.MultiPutRequest{PutRequests: putReqs}); err != nil || res.Status.Code != 0 {
+					t.Fatalf("Unable to MULTIPUT. Status: %+v, Error: %v", res.Status, err)
+				}
+			}
+			if fmt.Sprint(rec.syncs) != fmt.Sprint(tc.expSyncs) {
+				t.Errorf("Sync mismatch. Expected: %v, Actual: %v", tc.expSyncs, rec.syncs)
+			}
+			// Async puts are committed as changes all the same
+			if chngNum, err := store.GetLatestCommittedChangeNumber(); err != nil || chngNum != 5 {
+				t.Errorf("Expected 5 changes to be committed. Actual: %d, Error: %v", chngNum, err)
+			}
+		})
+	}
+}
+
+func TestGetChangesWithinMaxResponseSize(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store, WithMaxResponseSize(5<<20))
+	defer svc.Close()
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		putReq := &serverpb.PutRequest{Key: []byte(fmt.Sprintf("BigKey%d", i)), Value: make([]byte, 2<<20)}
+		if res, err := svc.Put(ctx, putReq); err != nil || res.Status.Code != 0 {
+			t.Fatalf("Unable to PUT a value of 2 MB. Status: %+v, Error: %v", res.GetStatus(), err)
+		}
+	}
+
+	for reqMaxSize, expNumChngs := range map[uint64]int{0: 2, 3 << 20: 1, 100 << 20: 2} {
+		res, err := svc.GetChanges(ctx, &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 100, MaxResponseSize: reqMaxSize})
+		if err != nil || res.Status.Code != 0 {
+			t.Fatalf("Unable to get changes. Status: %+v, Error: %v", res.GetStatus(), err)
+		}
+		if len(res.Changes) != expNumChngs || res.NextChangeNumber != uint64(expNumChngs+1) {
+			t.Errorf("Expected a partial batch of %d changes for a requested limit of %d bytes. Actual: %d changes, next change number: %d", expNumChngs, reqMaxSize, len(res.Changes), res.NextChangeNumber)
+		}
+		if size := proto.Size(res); size > 5<<20 {
+			t.Errorf("Expected the response to fit within 5 MB. Actual: %d bytes", size)
+		}
+	}
+}
+
+// changeStream records the sizes of the changes streamed onto it,
+// until the given number of changes are streamed
+type changeStream struct {
+	grpc.ServerStream
+	ctx         context.Context
+	cancel      context.CancelFunc
+	numChngs    int
+	nextChngNum uint64
+	maxSize     int
+}
+
+func (cs *changeStream) Context() context.Context { return cs.ctx }
+
+func (cs *changeStream) Send(res *serverpb.GetChangesResponse) error {
+	if res.Status.Code != 0 {
+		return errors.New(res.Status.Message)
+	}
+	if size := proto.Size(res); size > cs.maxSize {
+		cs.maxSize = size
+	}
+	if len(res.Changes) > 0 {
+		if res.Changes[0].ChangeNumber != cs.nextChngNum {
+			return fmt.Errorf("expected changes from change %d. Actual: %d", cs.nextChngNum, res.Changes[0].ChangeNumber)
+		}
+		cs.nextChngNum = res.NextChangeNumber
+	}
+	if cs.nextChngNum > uint64(cs.numChngs) {
+		cs.cancel()
+	}
+	return nil
+}
+
+func TestStreamChangesOfLargeValues(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store, WithMaxResponseSize(64<<20))
+	defer svc.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	numChngs := 40
+	for i := 1; i <= numChngs; i++ {
+		putReq := &serverpb.PutRequest{Key: []byte(fmt.Sprintf("BigKey%d", i)), Value: make([]byte, 3<<20)}
+		if res, err := svc.Put(ctx, putReq); err != nil || res.Status.Code != 0 {
+			t.Fatalf("Unable to PUT a value of 3 MB. Status: %+v, Error: %v", res.GetStatus(), err)
+		}
+	}
+
+	// Cold slaves catch up over a single batch of all the changes, which
+	// are sent a few at a time
+	chngsStrm := &changeStream{ctx: ctx, cancel: cancel, numChngs: numChngs, nextChngNum: 1}
+	err := svc.StreamChanges(&serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 10000}, chngsStrm)
+	if err != context.Canceled {
+		t.Fatalf("Unable to stream all the changes. Next change number: %d, Error: %v", chngsStrm.nextChngNum, err)
+	}
+	if chngsStrm.maxSize > changeStreamFlushSize+(4<<20) {
+		t.Errorf("Expected the changes to be streamed in responses of about %d bytes. Largest: %d bytes", changeStreamFlushSize, chngsStrm.maxSize)
+	}
+
+	res, err := svc.GetChanges(context.Background(), &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 10000})
+	if err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to get changes. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if numBytes := proto.Size(res); numBytes > DefaultMaxChangesSize+(4<<20) || len(res.Changes) == numChngs {
+		t.Errorf("Expected the changes to be limited to %d bytes. Actual: %d changes, %d bytes", DefaultMaxChangesSize, len(res.Changes), numBytes)
+	}
+}
+
+func TestStandaloneServiceReportsHealth(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store)
+	checkServingStatus(t, svc, grpc_health_v1.HealthCheckResponse_SERVING, "open service")
+	svc.Close()
+	checkServingStatus(t, svc, grpc_health_v1.HealthCheckResponse_NOT_SERVING, "closed service")
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dkv_maint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mode, err := maintenance.NewMode(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store, WithMaintenanceMode(mode))
+	defer svc.Close()
+	ctx := context.Background()
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("MaintKey"), Value: []byte("MaintVal")}); err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to PUT. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+
+	if err = mode.Enter("compaction"); err != nil {
+		t.Fatal(err)
+	}
+	checkServingStatus(t, svc, grpc_health_v1.HealthCheckResponse_NOT_SERVING, "service in maintenance")
+	putRes, _ := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("MaintKey"), Value: []byte("NewVal")})
+	delRes, _ := svc.Delete(ctx, &serverpb.DeleteRequest{Key: []byte("MaintKey")})
+	incRes, _ := svc.Increment(ctx, &serverpb.IncrementRequest{Key: []byte("MaintCounter"), Delta: 1})
+	for name, status := range map[string]*serverpb.Status{"PUT": putRes.Status, "DELETE": delRes.Status, "INCREMENT": incRes.Status} {
+		if err = dkverrors.FromStatus(status); !errors.Is(err, dkverrors.ErrMaintenance) {
+			t.Errorf("Expected %s to fail with: %v. Actual: %v", name, dkverrors.ErrMaintenance, err)
+		}
+	}
+	if res, err := svc.Get(ctx, &serverpb.GetRequest{Key: []byte("MaintKey")}); err != nil || string(res.Value) != "MaintVal" {
+		t.Errorf("Expected the reads to be served in maintenance. Value: %s, Error: %v", res.GetValue(), err)
+	}
+
+	if err = mode.Exit(); err != nil {
+		t.Fatal(err)
+	}
+	checkServingStatus(t, svc, grpc_health_v1.HealthCheckResponse_SERVING, "service out of maintenance")
+	if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte("MaintKey"), Value: []byte("NewVal")}); err != nil || res.Status.Code != 0 {
+		t.Errorf("Unable to PUT once out of maintenance. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+}
+
+func checkServingStatus(t *testing.T, svc DKVService, expStatus grpc_health_v1.HealthCheckResponse_ServingStatus, desc string) {
+	if res, err := svc.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil || res.Status != expStatus {
+		t.Errorf("Serving status mismatch for %s. Expected: %v, Actual: %v, Error: %v", desc, expStatus, res.GetStatus(), err)
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store)
+	defer svc.Close()
+
+	ctx := context.Background()
+	for _, ns := range []string{"", "ns1", "ns2"} {
+		for _, key := range []string{"K1", "K2"} {
+			if res, err := svc.Put(ctx, &serverpb.PutRequest{Key: []byte(key), Value: []byte(ns + key), Namespace: ns}); err != nil || res.Status.Code != 0 {
+				t.Fatalf("Unable to PUT %s in namespace: %q. Status: %+v, Error: %v", key, ns, res.GetStatus(), err)
+			}
+		}
+	}
+	for _, ns := range []string{"", "ns1", "ns2"} {
+		if res, err := svc.Get(ctx, &serverpb.GetRequest{Key: []byte("K1"), Namespace: ns}); err != nil || string(res.Value) != ns+"K1" {
+			t.Errorf("GET mismatch in namespace: %q. Expected Value: %s, Actual Value: %s, Error: %v", ns, ns+"K1", res.GetValue(), err)
+		}
+		if keys := iterateKeys(t, svc, ns); keys != "K1,K2" {
+			t.Errorf("Iteration mismatch in namespace: %q. Expected Keys: K1,K2, Actual Keys: %s", ns, keys)
+		}
+	}
+	if res, _ := svc.Get(ctx, &serverpb.GetRequest{Key: []byte("K1"), Namespace: "ns3"}); res.Found {
+		t.Errorf("Expected no keys in an unused namespace. Actual Value: %s", res.Value)
+	}
+	reservedKey := storage.NamespacePrefix("ns1")
+	if res, _ := svc.Put(ctx, &serverpb.PutRequest{Key: append(reservedKey, "K1"...), Value: []byte("V")}); res.Status.Code != int32(serverpb.StatusCode_InvalidArgument) {
+		t.Errorf("Expected keys of the default namespace with the reserved prefix to be rejected. Status: %+v", res.Status)
+	}
+
+	if res, err := svc.GetChanges(ctx, &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 10, Namespace: "ns1"}); err != nil || res.NumberOfChanges != 6 {
+		t.Fatalf("Unable to get the changes of a namespace. Response: %+v, Error: %v", res, err)
+	} else {
+		// Only the 3rd and 4th changes are on the keys of ns1
+		for _, chng := range res.Changes {
+			expNumTrxns := uint32(0)
+			if chng.ChangeNumber == 3 || chng.ChangeNumber == 4 {
+				expNumTrxns = 1
+			}
+			if chng.NumberOfTrxns != expNumTrxns || len(chng.Trxns) != int(expNumTrxns) {
+				t.Errorf("Transactions mismatch for change: %d. Expected: %d, Actual: %d", chng.ChangeNumber, expNumTrxns, chng.NumberOfTrxns)
+			}
+		}
+	}
+
+	// Only the 4th change is on K2 of ns1, with those after it skipped
+	if res, err := svc.GetChanges(ctx, &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 10, Namespace: "ns1", KeyPrefix: []byte("K2")}); err != nil || res.NumberOfChanges != 1 {
+		t.Errorf("Unable to get the changes with a key prefix. Response: %+v, Error: %v", res, err)
+	} else if res.Changes[0].ChangeNumber != 4 || res.NextChangeNumber != 7 {
+		t.Errorf("Changes mismatch for the key prefix. Expected change: 4, next change: 7. Response: %+v", res)
+	}
+	if res, err := svc.GetChanges(ctx, &serverpb.GetChangesRequest{FromChangeNumber: 2, MaxNumberOfChanges: 3, KeyPrefix: []byte("K1")}); err != nil || res.NumberOfChanges != 0 || res.NextChangeNumber != 5 {
+		t.Errorf("Expected a wholly skipped batch to advance the next change. Response: %+v, Error: %v", res, err)
+	}
+
+	bckpPath := path.Join(os.TempDir(), "dkv_ns_backup")
+	defer os.Remove(bckpPath)
+	if res, err := svc.StartBackup(ctx, &serverpb.BackupRequest{BackupPath: bckpPath, Namespace: "ns1"}); err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to backup a namespace. Status: %+v, Error: %v", res, err)
+	} else if job := waitForBackupJob(t, svc, res.JobID); job.State != serverpb.BackupJobState_Done || job.KeysProcessed != 2 || job.BytesWritten == 0 {
+		t.Fatalf("Unable to backup a namespace. Job: %+v", job)
+	}
+	svc.Put(ctx, &serverpb.PutRequest{Key: []byte("K3"), Value: []byte("V3"), Namespace: "ns2"})
+	if res, err := svc.StartRestore(ctx, &serverpb.RestoreRequest{RestorePath: bckpPath, Namespace: "ns2", DryRun: true}); err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to verify the backup of a namespace. Status: %+v, Error: %v", res, err)
+	} else if job := waitForBackupJob(t, svc, res.JobID); job.State != serverpb.BackupJobState_Done || !job.DryRun || job.BackupInfo.GetNumberOfKeys() != 2 {
+		t.Fatalf("Unable to verify the backup of a namespace. Job: %+v", job)
+	}
+	corruptBckpPath := bckpPath + "_corrupt"
+	defer os.Remove(corruptBckpPath)
+	bckp, _ := ioutil.ReadFile(bckpPath)
+	ioutil.WriteFile(corruptBckpPath, bckp[:len(bckp)-1], 0644)
+	// Restore waits for its job to complete, unlike StartRestore
+	if status, err := svc.Restore(ctx, &serverpb.RestoreRequest{RestorePath: corruptBckpPath, Namespace: "ns2"}); err != nil || status.Code == 0 {
+		t.Errorf("Expected the restore of a truncated backup to fail. Status: %+v, Error: %v", status, err)
+	}
+	if keys := iterateKeys(t, svc, "ns2"); keys != "K1,K2,K3" {
+		t.Errorf("Expected the keys to be left untouched by the dry run and the failed restore. Actual Keys: %s", keys)
+	}
+	if res, err := svc.StartRestore(ctx, &serverpb.RestoreRequest{RestorePath: bckpPath, Namespace: "ns2"}); err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to restore a namespace. Status: %+v, Error: %v", res, err)
+	} else if job := waitForBackupJob(t, svc, res.JobID); job.State != serverpb.BackupJobState_Done || !job.Restore || job.KeysProcessed != 2 {
+		t.Fatalf("Unable to restore a namespace. Job: %+v", job)
+	}
+	if res, _ := svc.Get(ctx, &serverpb.GetRequest{Key: []byte("K1"), Namespace: "ns2"}); string(res.Value) != "ns1K1" {
+		t.Errorf("Expected the keys of the backed up namespace to be restored. Actual Value: %s", res.Value)
+	}
+	if keys := iterateKeys(t, svc, "ns2"); keys != "K1,K2" {
+		t.Errorf("Expected the keys absent in the backup to be removed. Actual Keys: %s", keys)
+	}
+	if res, _ := svc.Get(ctx, &serverpb.GetRequest{Key: []byte("K1")}); string(res.Value) != "K1" {
+		t.Errorf("Expected the other namespaces to be left untouched. Actual Value: %s", res.Value)
+	}
+}
+
+func TestStreamBackupRestore(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(store, store, store)
+	defer svc.Close()
+	grpcSrvr := grpc.NewServer()
+	serverpb.RegisterDKVServer(grpcSrvr, svc)
+	serverpb.RegisterDKVBackupRestoreServer(grpcSrvr, svc)
+	lstnr, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go grpcSrvr.Serve(lstnr)
+	defer grpcSrvr.Stop()
+	// Keeps the memory footprint of the client low
+	client, err := ctl.NewInSecureDKVClient(lstnr.Addr().String(), ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// Spans multiple chunks of the streamed backup
+	numKeys := 2*backupChunkSize + 1
+	for i := 0; i < numKeys; i++ {
+		store.Put([]byte(fmt.Sprintf("K%d", i)), []byte(fmt.Sprintf("V%d", i)))
+	}
+	client.ForNamespace("ns1").Put([]byte("K1"), []byte("ns1V1"))
+	var bckp bytes.Buffer
+	if err = client.BackupTo(&bckp); err != nil {
+		t.Fatalf("Unable to stream backup. Error: %v", err)
+	}
+	var nsBckp bytes.Buffer
+	if err = client.ForNamespace("ns1").BackupTo(&nsBckp); err != nil {
+		t.Fatalf("Unable to stream backup of a namespace. Error: %v", err)
+	}
+
+	client.Put([]byte("K1"), []byte("V"))
+	client.Put([]byte("MissingKey"), []byte("V"))
+	if truncErr := client.RestoreFrom(bytes.NewReader(bckp.Bytes()[:bckp.Len()-1])); truncErr == nil {
+		t.Error("Expected an error on restoring a truncated backup")
+	}
+	corruptBckp := append([]byte(nil), bckp.Bytes()...)
+	corruptBckp[bytes.Index(corruptBckp, []byte("V1000"))] = 'X'
+	if corruptErr := client.RestoreFrom(bytes.NewReader(corruptBckp)); !errors.Is(corruptErr, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected an error on restoring a corrupt backup. Error: %v", corruptErr)
+	}
+	if err = client.ForNamespace("ns2").RestoreFrom(bytes.NewReader(bckp.Bytes())); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected an error on restoring the entire keyspace onto a namespace. Error: %v", err)
+	}
+	if res, _ := client.Get([]byte("K1")); string(res.GetValue()) != "V" {
+		t.Errorf("Expected failed restores to leave the keyspace untouched. Actual Value: %s", res.GetValue())
+	}
+
+	if err = client.RestoreFrom(&bckp); err != nil {
+		t.Fatalf("Unable to restore streamed backup. Error: %v", err)
+	}
+	if res, _ := client.Get([]byte("K1")); string(res.GetValue()) != "V1" {
+		t.Errorf("Expected the backed up keys to be restored. Actual Value: %s", res.GetValue())
+	}
+	if keys, _ := client.Exists([]byte("MissingKey")); keys[0] {
+		t.Error("Expected the keys absent in the backup to be removed")
+	}
+	if res, _ := client.ForNamespace("ns1").Get([]byte("K1")); string(res.GetValue()) != "ns1V1" {
+		t.Errorf("Expected all namespaces to be restored. Actual Value: %s", res.GetValue())
+	}
+	if err = client.ForNamespace("ns2").RestoreFrom(&nsBckp); err != nil {
+		t.Fatalf("Unable to restore streamed backup of a namespace. Error: %v", err)
+	}
+	if res, _ := client.ForNamespace("ns2").Get([]byte("K1")); string(res.GetValue()) != "ns1V1" {
+		t.Errorf("Expected the keys of the backed up namespace to be restored. Actual Value: %s", res.GetValue())
+	}
+}
+
+func waitForBackupJob(t *testing.T, svc DKVService, jobID uint64) *serverpb.GetBackupStatusResponse {
+	for {
+		res, err := svc.GetBackupStatus(context.Background(), &serverpb.GetBackupStatusRequest{JobID: jobID})
+		if err != nil || res.Status.Code != 0 {
+			t.Fatalf("Unable to get the status of job: %d. Status: %+v, Error: %v", jobID, res, err)
+		}
+		if res.State != serverpb.BackupJobState_Running {
+			return res
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// iterRecorder records the keys streamed by an Iterate call
+type iterRecorder struct {
+	grpc.ServerStream
+	keys []string
+}
+
+func (ir *iterRecorder) Send(res *serverpb.IterateResponse) error {
+	ir.keys = append(ir.keys, string(res.Key))
+	return nil
+}
+
+func (ir *iterRecorder) Context() context.Context {
+	return context.Background()
+}
+
+// endlessStore serves iterations that never end by themselves
+type endlessStore struct {
+	storage.KVStore
+	closed chan struct{}
+}
+
+func (es *endlessStore) Iterate(keyPrefix, startKey []byte) storage.Iterator {
+	return &endlessIter{closed: es.closed}
+}
+
+type endlessIter struct {
+	closed chan struct{}
+	count  int
+}
+
+func (ei *endlessIter) HasNext() bool    { return true }
+func (ei *endlessIter) Err() error       { return nil }
+func (ei *endlessIter) ExpireTS() uint64 { return 0 }
+
+func (ei *endlessIter) Next() ([]byte, []byte) {
+	ei.count++
+	return []byte(fmt.Sprintf("K%d", ei.count)), []byte("V")
+}
+
+func (ei *endlessIter) Close() error {
+	close(ei.closed)
+	return nil
+}
+
+func TestIterateStopsOnCancellation(t *testing.T) {
+	store := memory.OpenDB(0)
+	kvs := &endlessStore{KVStore: store, closed: make(chan struct{})}
+	svc := NewStandaloneService(kvs, nil, store)
+	defer svc.Close()
+	grpcSrvr := grpc.NewServer()
+	defer grpcSrvr.Stop()
+	serverpb.RegisterDKVServer(grpcSrvr, svc)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go grpcSrvr.Serve(lis)
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	iterCli, err := serverpb.NewDKVClient(conn).Iterate(ctx, &serverpb.IterateRequest{})
+	if err != nil {
+		t.Fatalf("Unable to iterate. Error: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := iterCli.Recv(); err != nil {
+			t.Fatalf("Unable to receive entry. Error: %v", err)
+		}
+	}
+	cancel()
+	select {
+	case <-kvs.closed:
+	case <-time.After(time.Second):
+		t.Error("Expected the iteration to be closed upon cancellation")
+	}
+}
+
+// corruptStore fails the reads of the keys containing "bad", as a
+// store with corrupt blocks does
+type corruptStore struct {
+	storage.KVStore
+}
+
+func (cs *corruptStore) Get(keys ...[]byte) ([][]byte, []bool, error) {
+	for _, key := range keys {
+		if bytes.Contains(key, []byte("bad")) {
+			return nil, nil, errors.New("corrupt block")
+		}
+	}
+	return cs.KVStore.Get(keys...)
+}
+
+func TestMultiGetDetailed(t *testing.T) {
+	store := memory.OpenDB(0)
+	svc := NewStandaloneService(&corruptStore{store}, nil, store)
+	defer svc.Close()
+	for _, key := range []string{"K1", "K3", "badK"} {
+		if res, err := svc.Put(context.Background(), &serverpb.PutRequest{Key: []byte(key), Value: []byte("V" + key)}); err != nil || res.Status.Code != 0 {
+			t.Fatalf("Unable to PUT. Key: %s, Status: %v, Error: %v", key, res, err)
+		}
+	}
+
+	keys := [][]byte{[]byte("K1"), []byte("badK"), []byte("K2"), []byte("K3")}
+	if res, err := svc.MultiGet(context.Background(), &serverpb.MultiGetRequest{Keys: keys}); err != nil || res.Status.Code == 0 {
+		t.Errorf("Expected the corrupt key to fail the MultiGet. Response: %v, Error: %v", res, err)
+	}
+	res, err := svc.MultiGet(context.Background(), &serverpb.MultiGetRequest{Keys: keys, Detailed: true})
+	if err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to MultiGet. Status: %v, Error: %v", res, err)
+	}
+	if len(res.Results) != len(keys) || len(res.Values) != 0 {
+		t.Fatalf("Expected the results of every key in place of the values. Actual: %v", res)
+	}
+	for i, exp := range []struct {
+		val   string
+		found bool
+	}{{"VK1", true}, {"", false}, {"", false}, {"VK3", true}} {
+		result := res.Results[i]
+		if i == 1 {
+			if result.Status.Code == 0 || result.Found {
+				t.Errorf("Expected the read of the corrupt key to fail. Actual: %v", result)
+			}
+		} else if result.Status.Code != 0 || result.Found != exp.found || string(result.Value) != exp.val {
+			t.Errorf("Result mismatch for key: %s. Actual: %v", keys[i], result)
+		}
+	}
+}
+
+func TestShutdownRetainsWrites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dkv_shutdown_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := badger.OpenDB(dir)
+	svc := NewStandaloneService(store, nil, store)
+	grpcSrvr := grpc.NewServer()
+	serverpb.RegisterDKVServer(grpcSrvr, svc)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go grpcSrvr.Serve(lis)
+	client, err := ctl.NewInSecureDKVClient(lis.Addr().String(), ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// Writes acknowledged until the shutdown must all be retained
+	numAcked, done := 0, make(chan struct{})
+	go func() {
+		defer close(done)
+		for ; ; numAcked++ {
+			if err := client.Put([]byte(fmt.Sprintf("K%d", numAcked)), []byte(fmt.Sprintf("V%d", numAcked))); err != nil {
+				return
+			}
+		}
+	}()
+	time.Sleep(200 * time.Millisecond)
+	grpcSrvr.GracefulStop()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err = svc.Shutdown(ctx); err != nil {
+		t.Fatalf("Unable to shutdown. Error: %v", err)
+	}
+	<-done
+	if numAcked == 0 {
+		t.Fatal("Expected some writes to be acknowledged before the shutdown")
+	}
+
+	store = badger.OpenDB(dir)
+	defer store.Close()
+	for i := 0; i < numAcked; i++ {
+		if vals, _, err := store.Get([]byte(fmt.Sprintf("K%d", i))); err != nil || string(vals[0]) != fmt.Sprintf("V%d", i) {
+			t.Fatalf("Expected the acknowledged write of K%d to be retained. Values: %q, Error: %v", i, vals, err)
+		}
+	}
+}
+
+func iterateKeys(t *testing.T, svc DKVService, ns string) string {
+	iterRec := &iterRecorder{}
+	if err := svc.Iterate(&serverpb.IterateRequest{Namespace: ns}, iterRec); err != nil {
+		t.Fatalf("Unable to iterate namespace: %q. Error: %v", ns, err)
+	}
+	return strings.Join(iterRec.keys, ",")
+}
+
 func testPutAndGet(t *testing.T) {
 	numKeys, keyPrefix, valPrefix := 10, "K", "V"
 	putKeys(t, numKeys, keyPrefix, valPrefix)
@@ -85,11 +958,198 @@ func testMultiGet(t *testing.T) {
 }
 
 func testMissingGet(t *testing.T) {
-	key, expectedValue := "MissingKey", ""
-	if val, err := dkvCli.Get([]byte(key)); err != nil {
+	key := "MissingKey"
+	if val, err := dkvCli.Get([]byte(key)); !errors.Is(err, ctl.ErrKeyNotFound) {
+		t.Errorf("Expected error: %v on GET of a missing key. Value: %v, Error: %v", ctl.ErrKeyNotFound, val, err)
+	}
+
+	emptyKey := "EmptyValueKey"
+	if err := dkvCli.Put([]byte(emptyKey), nil); err != nil {
+		t.Fatalf("Unable to PUT. Key: %s, Error: %v", emptyKey, err)
+	}
+	if val, err := dkvCli.Get([]byte(emptyKey)); err != nil {
+		t.Fatalf("Unable to GET. Key: %s, Error: %v", emptyKey, err)
+	} else if !val.Found || len(val.Value) != 0 {
+		t.Errorf("Expected key: %s to be found with an empty value. Actual: %v", emptyKey, val)
+	}
+
+	if vals, err := dkvCli.MultiGet([]byte(key), []byte(emptyKey)); err != nil {
+		t.Fatalf("Unable to MultiGET. Error: %v", err)
+	} else if len(vals) != 2 || vals[0] != nil || vals[1] == nil || len(vals[1]) != 0 {
+		t.Errorf("Expected a nil value for the missing key and an empty value for key: %s. Actual: %q", emptyKey, vals)
+	}
+}
+
+func testMultiPut(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 10, "MPK", "MPV"
+	var pairs []ctl.KVPair
+	for i := 1; i <= numKeys; i++ {
+		key, value := fmt.Sprintf("%s%d", keyPrefix, i), fmt.Sprintf("%s%d", valPrefix, i)
+		pairs = append(pairs, ctl.KVPair{Key: []byte(key), Value: []byte(value)})
+	}
+	if err := dkvCli.MultiPut(pairs...); err != nil {
+		t.Fatalf("Unable to MULTIPUT. Error: %v", err)
+	}
+	for _, pair := range pairs {
+		if res, err := dkvCli.Get(pair.Key); err != nil {
+			t.Fatalf("Unable to GET. Key: %s, Error: %v", pair.Key, err)
+		} else if string(res.Value) != string(pair.Value) {
+			t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", pair.Key, pair.Value, res.Value)
+		}
+	}
+
+	badPairs := []ctl.KVPair{{Key: []byte("MPBadK1"), Value: []byte("MPBadV1")}, {Key: nil, Value: []byte("MPBadV2")}}
+	if err := dkvCli.MultiPut(badPairs...); err == nil {
+		t.Error("Expected an error for an empty key. But got none")
+	} else if !strings.Contains(err.Error(), "index 1") {
+		t.Errorf("Expected the error to identify the failed entry. Error: %v", err)
+	}
+	if res, err := dkvCli.Get(badPairs[0].Key); err == nil && len(res.Value) > 0 {
+		t.Errorf("Expected no entries of a failed MultiPut to be stored. But found key: %s", badPairs[0].Key)
+	}
+}
+
+func testExists(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 3, "ExK", "ExV"
+	putKeys(t, numKeys, keyPrefix, valPrefix)
+
+	keys := [][]byte{[]byte("ExK1"), []byte("MissingExK"), []byte("ExK3")}
+	if results, err := dkvCli.Exists(keys...); err != nil {
+		t.Fatalf("Unable to check EXISTS. Error: %v", err)
+	} else if len(results) != len(keys) || !results[0] || results[1] || !results[2] {
+		t.Errorf("Presence mismatch. Expected: [true false true], Actual: %v", results)
+	}
+}
+
+func testCompareAndSet(t *testing.T) {
+	key, val := []byte("CASK"), []byte("CASV")
+	if updated, err := dkvCli.CompareAndSet(key, nil, val); err != nil {
+		t.Fatalf("Unable to COMPAREANDSET. Key: %s, Error: %v", key, err)
+	} else if !updated {
+		t.Errorf("Expected the absent key: %s to be set", key)
+	}
+	if updated, err := dkvCli.CompareAndSet(key, []byte("CASWrongV"), []byte("CASNewV")); err != nil {
+		t.Fatalf("Unable to COMPAREANDSET. Key: %s, Error: %v", key, err)
+	} else if updated {
+		t.Errorf("Expected the key: %s to not be set for a mismatched expected value", key)
+	}
+	if updated, err := dkvCli.CompareAndSet(key, val, []byte("CASNewV")); err != nil {
+		t.Fatalf("Unable to COMPAREANDSET. Key: %s, Error: %v", key, err)
+	} else if !updated {
+		t.Errorf("Expected the key: %s to be set for a matching expected value", key)
+	}
+	if res, err := dkvCli.Get(key); err != nil {
 		t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
-	} else if string(val.Value) != "" {
-		t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expectedValue, val)
+	} else if string(res.Value) != "CASNewV" {
+		t.Errorf("GET mismatch. Key: %s, Expected Value: CASNewV, Actual Value: %s", key, res.Value)
+	}
+}
+
+func testTxn(t *testing.T) {
+	lockKey, key, val := []byte("TxnLockK"), []byte("TxnK"), []byte("TxnV")
+	if succeeded, err := dkvCli.Txn().If(ctl.KeyAbsent(lockKey)).Then(ctl.OpPut(lockKey, val), ctl.OpPut(key, val)).Commit(); err != nil {
+		t.Fatalf("Unable to TXN. Error: %v", err)
+	} else if !succeeded {
+		t.Errorf("Expected the TXN to succeed for the absent key: %s", lockKey)
+	}
+	if succeeded, err := dkvCli.Txn().If(ctl.KeyAbsent(lockKey)).Then(ctl.OpDelete(key)).Else(ctl.OpPut(key, []byte("TxnElseV"))).Commit(); err != nil {
+		t.Fatalf("Unable to TXN. Error: %v", err)
+	} else if succeeded {
+		t.Errorf("Expected the TXN to fail for the present key: %s", lockKey)
+	}
+	if res, err := dkvCli.Get(key); err != nil {
+		t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
+	} else if string(res.Value) != "TxnElseV" {
+		t.Errorf("GET mismatch. Key: %s, Expected Value: TxnElseV, Actual Value: %s", key, res.Value)
+	}
+	if _, err := dkvCli.Txn().If(ctl.ValueEquals(lockKey, val)).Then(ctl.OpDelete(nil)).Commit(); err == nil {
+		t.Errorf("Expected the TXN with an empty key to be rejected")
+	}
+}
+
+func testIncrement(t *testing.T) {
+	key := []byte("IncrK")
+	if value, err := dkvCli.Increment(key, 10); err != nil {
+		t.Fatalf("Unable to INCREMENT. Key: %s, Error: %v", key, err)
+	} else if value != 10 {
+		t.Errorf("INCREMENT mismatch. Key: %s, Expected Value: 10, Actual Value: %d", key, value)
+	}
+	if value, err := dkvCli.Increment(key, -15); err != nil {
+		t.Fatalf("Unable to INCREMENT. Key: %s, Error: %v", key, err)
+	} else if value != -5 {
+		t.Errorf("INCREMENT mismatch. Key: %s, Expected Value: -5, Actual Value: %d", key, value)
+	}
+
+	nonNumKey, nonNumVal := []byte("IncrNonNumK"), []byte("IncrNonNumV")
+	if err := dkvCli.Put(nonNumKey, nonNumVal); err != nil {
+		t.Fatalf("Unable to PUT. Key: %s, Error: %v", nonNumKey, err)
+	}
+	if _, err := dkvCli.Increment(nonNumKey, 1); !errors.Is(err, ctl.ErrNonNumericValue) {
+		t.Errorf("Expected error: %v on incrementing a non numeric value. Actual: %v", ctl.ErrNonNumericValue, err)
+	}
+	if res, err := dkvCli.Get(nonNumKey); err != nil {
+		t.Fatalf("Unable to GET. Key: %s, Error: %v", nonNumKey, err)
+	} else if string(res.Value) != string(nonNumVal) {
+		t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", nonNumKey, nonNumVal, res.Value)
+	}
+}
+
+func testDelete(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 10, "DK", "DV"
+	putKeys(t, numKeys, keyPrefix, valPrefix)
+	for i := 1; i <= numKeys; i++ {
+		key := fmt.Sprintf("%s%d", keyPrefix, i)
+		if err := dkvCli.Delete([]byte(key)); err != nil {
+			t.Fatalf("Unable to DELETE. Key: %s, Error: %v", key, err)
+		}
+		if res, err := dkvCli.Get([]byte(key)); !errors.Is(err, ctl.ErrKeyNotFound) {
+			t.Errorf("Expected key: %s to be deleted. Response: %v, Error: %v", key, res, err)
+		}
+	}
+
+	if err := dkvCli.Delete([]byte("MissingDeleteKey")); err != nil {
+		t.Errorf("Expected no error while deleting a missing key. Error: %v", err)
+	}
+
+	if chngsRes, err := dkvCli.GetChanges(1, 1000, 0); err != nil {
+		t.Fatalf("Unable to get changes. Error: %v", err)
+	} else {
+		chngs := chngsRes.Changes
+		// Last change is the delete of the missing key
+		for i, j := numKeys, len(chngs)-1; i >= 1; i, j = i-1, j-1 {
+			trxn := chngs[j-1].Trxns[0]
+			expKey := fmt.Sprintf("%s%d", keyPrefix, i)
+			if trxn.Type != serverpb.TrxnRecord_Delete {
+				t.Errorf("Expected DELETE transaction but found %s transaction", trxn.Type.String())
+			} else if string(trxn.Key) != expKey {
+				t.Errorf("Key mismatch. Expected %s, Actual %s", expKey, trxn.Key)
+			}
+		}
+	}
+}
+
+func testIterate(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 9, "ItK", "ItV"
+	putKeys(t, numKeys, keyPrefix, valPrefix)
+
+	startKey := fmt.Sprintf("%s%d", keyPrefix, 3)
+	pairs, err := dkvCli.Iterate([]byte(keyPrefix), []byte(startKey))
+	if err != nil {
+		t.Fatalf("Unable to ITERATE. Error: %v", err)
+	}
+	expKeyIdx := 3
+	for pair := range pairs {
+		if pair.Err != nil {
+			t.Fatalf("Error during iteration. Error: %v", pair.Err)
+		}
+		expKey, expVal := fmt.Sprintf("%s%d", keyPrefix, expKeyIdx), fmt.Sprintf("%s%d", valPrefix, expKeyIdx)
+		if string(pair.Key) != expKey || string(pair.Value) != expVal {
+			t.Errorf("Iteration mismatch. Expected: %s=%s, Actual: %s=%s", expKey, expVal, pair.Key, pair.Value)
+		}
+		expKeyIdx++
+	}
+	if expKeyIdx != numKeys+1 {
+		t.Errorf("Expected iteration to end at key index %d, but it ended at %d", numKeys+1, expKeyIdx)
 	}
 }
 
@@ -97,7 +1157,7 @@ func testGetChanges(t *testing.T) {
 	numKeys, keyPrefix, valPrefix := 10, "GCK", "GCV"
 	putKeys(t, numKeys, keyPrefix, valPrefix)
 
-	if chngsRes, err := dkvCli.GetChanges(0, 100); err != nil {
+	if chngsRes, err := dkvCli.GetChanges(0, 100, 0); err != nil {
 		t.Fatalf("Unable to get changes. Error: %v", err)
 	} else {
 		if chngsRes.MasterChangeNumber == 0 {
@@ -129,6 +1189,42 @@ func testGetChanges(t *testing.T) {
 	}
 }
 
+func testStreamChanges(t *testing.T) {
+	latestChngNum, _ := dkvSvc.(*standaloneService).cp.GetLatestCommittedChangeNumber()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chngsStrm, err := dkvCli.StreamChangesWithCtx(ctx, latestChngNum+1, 3, 0)
+	if err != nil {
+		t.Fatalf("Unable to stream changes. Error: %v", err)
+	}
+
+	// First response is a heartbeat since there are no new changes
+	if chngsRes, err := chngsStrm.Recv(); err != nil {
+		t.Fatalf("Unable to receive heartbeat. Error: %v", err)
+	} else if chngsRes.NumberOfChanges != 0 || chngsRes.MasterChangeNumber != latestChngNum {
+		t.Errorf("Expected heartbeat with master change number %d. Actual: %+v", latestChngNum, chngsRes)
+	}
+
+	numKeys, keyPrefix, valPrefix := 10, "SCK", "SCV"
+	putKeys(t, numKeys, keyPrefix, valPrefix)
+	for i := 1; i <= numKeys; {
+		chngsRes, err := chngsStrm.Recv()
+		if err != nil {
+			t.Fatalf("Unable to receive changes. Error: %v", err)
+		}
+		if chngsRes.NumberOfChanges > 3 {
+			t.Errorf("Expected atmost 3 changes per batch. Actual: %d", chngsRes.NumberOfChanges)
+		}
+		for _, chng := range chngsRes.Changes {
+			expKey := fmt.Sprintf("%s%d", keyPrefix, i)
+			if trxn := chng.Trxns[0]; string(trxn.Key) != expKey {
+				t.Errorf("Key mismatch. Expected %s, Actual %s", expKey, trxn.Key)
+			}
+			i++
+		}
+	}
+}
+
 func testBackupRestore(t *testing.T) {
 	numKeys, keyPrefix, valPrefix := 500, "BRK", "BRV"
 	putKeys(t, numKeys, keyPrefix, valPrefix)
@@ -191,10 +1287,8 @@ func putKeys(t *testing.T, numKeys int, keyPrefix, valPrefix string) {
 func noKeys(t *testing.T, numKeys int, keyPrefix string) {
 	for i := 1; i <= numKeys; i++ {
 		key := fmt.Sprintf("%s_%d", keyPrefix, i)
-		if res, err := dkvCli.Get([]byte(key)); err != nil {
-			t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
-		} else if string(res.Value) != "" {
-			t.Errorf("Expected missing for key: %s. But found it with value: %s", key, res.Value)
+		if res, err := dkvCli.Get([]byte(key)); !errors.Is(err, ctl.ErrKeyNotFound) {
+			t.Errorf("Expected missing for key: %s. Response: %v, Error: %v", key, res, err)
 		}
 	}
 }
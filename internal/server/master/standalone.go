@@ -0,0 +1,68 @@
+package master
+
+import (
+	"context"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errNotClustered fails the cluster methods of the standalone nodes
+var errNotClustered = status.Error(codes.Unimplemented, "DKV node runs in standalone mode without Nexus, hence is not a member of any cluster")
+
+// standaloneClusterServer serves the DKVCluster service of the nodes
+// running the standalone variant of the DKVService.
+type standaloneClusterServer struct{}
+
+// NewStandaloneClusterServer creates the DKVCluster service of a node
+// running the standalone variant of the DKVService, which is not a member
+// of any cluster. Every method fails with codes.Unimplemented, explaining
+// why, rather than leaving the callers with an unknown service.
+func NewStandaloneClusterServer() serverpb.DKVClusterServer {
+	return standaloneClusterServer{}
+}
+
+func (standaloneClusterServer) AddNode(context.Context, *serverpb.AddNodeRequest) (*serverpb.Status, error) {
+	return nil, errNotClustered
+}
+
+func (standaloneClusterServer) RemoveNode(context.Context, *serverpb.RemoveNodeRequest) (*serverpb.Status, error) {
+	return nil, errNotClustered
+}
+
+func (standaloneClusterServer) ListNodes(context.Context, *serverpb.ListNodesRequest) (*serverpb.ListNodesResponse, error) {
+	return nil, errNotClustered
+}
+
+func (standaloneClusterServer) DecommissionNode(context.Context, *serverpb.DecommissionNodeRequest) (*serverpb.Status, error) {
+	return nil, errNotClustered
+}
+
+func (standaloneClusterServer) GetDecommissionStatus(context.Context, *serverpb.GetDecommissionStatusRequest) (*serverpb.GetDecommissionStatusResponse, error) {
+	return nil, errNotClustered
+}
+
+func (standaloneClusterServer) ClusterBackup(context.Context, *serverpb.ClusterBackupRequest) (*serverpb.ClusterBackupResponse, error) {
+	return nil, errNotClustered
+}
+
+func (standaloneClusterServer) ClusterRestore(context.Context, *serverpb.ClusterRestoreRequest) (*serverpb.Status, error) {
+	return nil, errNotClustered
+}
+
+func (standaloneClusterServer) FenceWrites(context.Context, *serverpb.FenceWritesRequest) (*serverpb.FenceWritesResponse, error) {
+	return nil, errNotClustered
+}
+
+func (standaloneClusterServer) UnfenceWrites(context.Context, *serverpb.UnfenceWritesRequest) (*serverpb.Status, error) {
+	return nil, errNotClustered
+}
+
+func (standaloneClusterServer) BackupMember(context.Context, *serverpb.BackupMemberRequest) (*serverpb.BackupMemberResponse, error) {
+	return nil, errNotClustered
+}
+
+func (standaloneClusterServer) RestoreMember(context.Context, *serverpb.RestoreMemberRequest) (*serverpb.Status, error) {
+	return nil, errNotClustered
+}
@@ -0,0 +1,280 @@
+// Package ratelimit guards DKV nodes against clients that overwhelm
+// them, by limiting the rate and the concurrency of the GRPC calls
+// served by these nodes.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/server/security"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Limits on the GRPC calls served by a DKV node, where zero disables
+// the respective limit. A zero burst defaults to the respective rate
+// rounded up, so that a second worth of calls can be admitted at once.
+type Limits struct {
+	// MethodRate is the number of calls of every method admitted
+	// per second, beyond the MethodBurst.
+	MethodRate  float64
+	MethodBurst int
+	// TokenRate is the number of calls carrying every bearer token
+	// admitted per second across all the methods, beyond the TokenBurst.
+	TokenRate  float64
+	TokenBurst int
+	// ReplicationRate is the number of calls of the replication methods
+	// admitted per second, beyond the ReplicationBurst. These methods are
+	// exempt from all the other limits, so that slave nodes do not fall
+	// behind due to the load of the other clients.
+	ReplicationRate  float64
+	ReplicationBurst int
+	// MaxInFlight is the maximum number of calls served concurrently,
+	// other than those of the replication methods.
+	MaxInFlight int
+}
+
+func (lims Limits) validate() error {
+	switch {
+	case lims.MethodRate < 0, lims.TokenRate < 0, lims.ReplicationRate < 0:
+		return fmt.Errorf("rates can not be negative: %w", dkverrors.ErrInvalidArgument)
+	case lims.MethodBurst < 0, lims.TokenBurst < 0, lims.ReplicationBurst < 0:
+		return fmt.Errorf("bursts can not be negative: %w", dkverrors.ErrInvalidArgument)
+	case lims.MaxInFlight < 0:
+		return fmt.Errorf("maximum number of calls in flight can not be negative: %w", dkverrors.ErrInvalidArgument)
+	}
+	return nil
+}
+
+const (
+	healthServicePrefix      = "/grpc.health.v1.Health/"
+	limitsServicePrefix      = "/dkv.serverpb.DKVLimits/"
+	replicationServicePrefix = "/dkv.serverpb.DKVReplication/"
+)
+
+// Limiter rejects the GRPC calls exceeding its limits with the
+// RESOURCE_EXHAUSTED code. Calls of the health checking service are
+// never rejected, so that load balancers can always probe the nodes,
+// nor are those of the DKVLimits service, so that limits that turn out
+// to be too strict can always be relaxed.
+// Calls carrying bearer tokens are limited per token only after they
+// are authorized, since unknown tokens must not be tracked.
+//
+// Limiter also serves the DKVLimits service, through which its limits
+// can be adjusted at runtime.
+type Limiter struct {
+	mu       sync.Mutex
+	limits   Limits
+	methods  map[string]*bucket
+	tokens   map[string]*bucket
+	repl     *bucket
+	inFlight int
+	now      func() time.Time
+}
+
+// NewLimiter creates a Limiter that enforces the given limits.
+func NewLimiter(limits Limits) (*Limiter, error) {
+	lim := &Limiter{now: time.Now}
+	if err := lim.Update(limits); err != nil {
+		return nil, err
+	}
+	return lim, nil
+}
+
+// Limits returns the limits currently enforced.
+func (lim *Limiter) Limits() Limits {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+	return lim.limits
+}
+
+// Update replaces the limits enforced with the given ones, which take
+// effect immediately. Calls in flight are unaffected by the update,
+// while the rates begin afresh with full bursts.
+func (lim *Limiter) Update(limits Limits) error {
+	if err := limits.validate(); err != nil {
+		return err
+	}
+	limits.MethodBurst = defaultBurst(limits.MethodRate, limits.MethodBurst)
+	limits.TokenBurst = defaultBurst(limits.TokenRate, limits.TokenBurst)
+	limits.ReplicationBurst = defaultBurst(limits.ReplicationRate, limits.ReplicationBurst)
+
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+	lim.limits = limits
+	lim.methods, lim.tokens = make(map[string]*bucket), make(map[string]*bucket)
+	lim.repl = newBucket(limits.ReplicationBurst, lim.now())
+	return nil
+}
+
+func defaultBurst(rate float64, burst int) int {
+	if rate > 0 && burst == 0 {
+		return int(math.Ceil(rate))
+	}
+	return burst
+}
+
+// UnaryServerInterceptor limits every unary GRPC call.
+func (lim *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done, err := lim.admit(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor limits every streaming GRPC call, which
+// stays in flight for the whole lifetime of its stream.
+func (lim *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		done, err := lim.admit(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		defer done()
+		return handler(srv, ss)
+	}
+}
+
+// admit admits the call of the given method as per the limits, in
+// which case the returned function must be invoked once it completes.
+func (lim *Limiter) admit(ctx context.Context, fullMethod string) (func(), error) {
+	if strings.HasPrefix(fullMethod, healthServicePrefix) || strings.HasPrefix(fullMethod, limitsServicePrefix) {
+		return func() {}, nil
+	}
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+	now := lim.now()
+	if strings.HasPrefix(fullMethod, replicationServicePrefix) {
+		if !lim.repl.take(lim.limits.ReplicationRate, lim.limits.ReplicationBurst, now) {
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit of the replication methods exceeded by %s", fullMethod)
+		}
+		return func() {}, nil
+	}
+
+	if lim.limits.MaxInFlight > 0 && lim.inFlight >= lim.limits.MaxInFlight {
+		return nil, status.Errorf(codes.ResourceExhausted, "maximum of %d calls in flight reached", lim.limits.MaxInFlight)
+	}
+	mthdBkt := lim.bucket(lim.methods, fullMethod, lim.limits.MethodRate, lim.limits.MethodBurst, now)
+	var tknBkt *bucket
+	if token := security.BearerToken(ctx); token != "" {
+		tknBkt = lim.bucket(lim.tokens, token, lim.limits.TokenRate, lim.limits.TokenBurst, now)
+	}
+	// Tokens are taken only when both the buckets can admit the call
+	switch {
+	case mthdBkt != nil && !mthdBkt.available(lim.limits.MethodRate, lim.limits.MethodBurst, now):
+		return nil, status.Errorf(codes.ResourceExhausted, "rate limit of %s exceeded", fullMethod)
+	case tknBkt != nil && !tknBkt.available(lim.limits.TokenRate, lim.limits.TokenBurst, now):
+		return nil, status.Errorf(codes.ResourceExhausted, "rate limit of the bearer token exceeded by %s", fullMethod)
+	}
+	if mthdBkt != nil {
+		mthdBkt.tokens--
+	}
+	if tknBkt != nil {
+		tknBkt.tokens--
+	}
+	lim.inFlight++
+	return lim.release, nil
+}
+
+func (lim *Limiter) release() {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+	lim.inFlight--
+}
+
+// bucket returns the bucket of the given key from the given buckets,
+// creating a full one if absent. Returns nil when the rate is unlimited.
+// It must be invoked with the mutex held.
+func (lim *Limiter) bucket(bkts map[string]*bucket, key string, rate float64, burst int, now time.Time) *bucket {
+	if rate <= 0 {
+		return nil
+	}
+	bkt, present := bkts[key]
+	if !present {
+		bkt = newBucket(burst, now)
+		bkts[key] = bkt
+	}
+	return bkt
+}
+
+// bucket is a token bucket, which is refilled at a given rate upto a
+// given burst, with every call admitted taking a token from it.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(burst int, now time.Time) *bucket {
+	return &bucket{tokens: float64(burst), last: now}
+}
+
+// available refills the bucket as of the given time and checks
+// whether a token can be taken from it.
+func (bkt *bucket) available(rate float64, burst int, now time.Time) bool {
+	if elapsed := now.Sub(bkt.last); elapsed > 0 {
+		bkt.tokens = math.Min(float64(burst), bkt.tokens+elapsed.Seconds()*rate)
+		bkt.last = now
+	}
+	return bkt.tokens >= 1
+}
+
+// take takes a token from the bucket if available. Always succeeds
+// when the rate is unlimited.
+func (bkt *bucket) take(rate float64, burst int, now time.Time) bool {
+	if rate <= 0 {
+		return true
+	}
+	if !bkt.available(rate, burst, now) {
+		return false
+	}
+	bkt.tokens--
+	return true
+}
+
+// GetLimits retrieves the limits currently enforced.
+func (lim *Limiter) GetLimits(context.Context, *serverpb.GetLimitsRequest) (*serverpb.GetLimitsResponse, error) {
+	lims := lim.Limits()
+	res := &serverpb.GetLimitsResponse{Status: &serverpb.Status{}}
+	res.Limits = &serverpb.Limits{
+		MethodRate:       lims.MethodRate,
+		MethodBurst:      uint32(lims.MethodBurst),
+		TokenRate:        lims.TokenRate,
+		TokenBurst:       uint32(lims.TokenBurst),
+		ReplicationRate:  lims.ReplicationRate,
+		ReplicationBurst: uint32(lims.ReplicationBurst),
+		MaxInFlight:      uint32(lims.MaxInFlight),
+	}
+	return res, nil
+}
+
+// SetLimits replaces the limits enforced with the given ones.
+func (lim *Limiter) SetLimits(_ context.Context, req *serverpb.SetLimitsRequest) (*serverpb.Status, error) {
+	if req.Limits == nil {
+		return dkverrors.NewStatus(fmt.Errorf("limits must be given: %w", dkverrors.ErrInvalidArgument)), nil
+	}
+	lims := Limits{
+		MethodRate:       req.Limits.MethodRate,
+		MethodBurst:      int(req.Limits.MethodBurst),
+		TokenRate:        req.Limits.TokenRate,
+		TokenBurst:       int(req.Limits.TokenBurst),
+		ReplicationRate:  req.Limits.ReplicationRate,
+		ReplicationBurst: int(req.Limits.ReplicationBurst),
+		MaxInFlight:      int(req.Limits.MaxInFlight),
+	}
+	if err := lim.Update(lims); err != nil {
+		return dkverrors.NewStatus(err), nil
+	}
+	return &serverpb.Status{}, nil
+}
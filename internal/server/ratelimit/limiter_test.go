@@ -0,0 +1,193 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	getMethod        = "/dkv.serverpb.DKV/Get"
+	multiGetMethod   = "/dkv.serverpb.DKV/MultiGet"
+	getChangesMethod = "/dkv.serverpb.DKVReplication/GetChanges"
+	healthMethod     = "/grpc.health.v1.Health/Check"
+	setLimitsMethod  = "/dkv.serverpb.DKVLimits/SetLimits"
+)
+
+// fakeClock is advanced explicitly by the tests
+type fakeClock struct {
+	now time.Time
+}
+
+func (fc *fakeClock) advance(d time.Duration) {
+	fc.now = fc.now.Add(d)
+}
+
+func newTestLimiter(t *testing.T, limits Limits) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1600000000, 0)}
+	lim, err := NewLimiter(Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lim.now = func() time.Time { return clock.now }
+	if err = lim.Update(limits); err != nil {
+		t.Fatal(err)
+	}
+	return lim, clock
+}
+
+func call(ctx context.Context, lim *Limiter, method string, handler grpc.UnaryHandler) error {
+	_, err := lim.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	return err
+}
+
+func noopHandler(context.Context, interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+// admitted counts the calls of the given method admitted out of the given number
+func admitted(ctx context.Context, lim *Limiter, method string, numCalls int) int {
+	numAdmitted := 0
+	for i := 0; i < numCalls; i++ {
+		if err := call(ctx, lim, method, noopHandler); err == nil {
+			numAdmitted++
+		} else if status.Code(err) != codes.ResourceExhausted {
+			panic(err)
+		}
+	}
+	return numAdmitted
+}
+
+func TestLimiterLimitsRatePerMethod(t *testing.T) {
+	lim, clock := newTestLimiter(t, Limits{MethodRate: 10, MethodBurst: 5})
+	ctx := context.Background()
+	if n := admitted(ctx, lim, getMethod, 10); n != 5 {
+		t.Errorf("Expected only the burst of calls to be admitted. Actual: %d", n)
+	}
+	// Every method is limited independently
+	if n := admitted(ctx, lim, multiGetMethod, 10); n != 5 {
+		t.Errorf("Expected the burst of calls of another method to be admitted. Actual: %d", n)
+	}
+	clock.advance(200 * time.Millisecond)
+	if n := admitted(ctx, lim, getMethod, 10); n != 2 {
+		t.Errorf("Expected the calls refilled over 200ms to be admitted. Actual: %d", n)
+	}
+	if n := admitted(ctx, lim, healthMethod, 10); n != 10 {
+		t.Errorf("Expected health checks to be never limited. Actual: %d", n)
+	}
+}
+
+func TestLimiterLimitsRatePerToken(t *testing.T) {
+	lim, _ := newTestLimiter(t, Limits{TokenRate: 1, TokenBurst: 3})
+	ctx1 := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer t1"))
+	ctx2 := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer t2"))
+	if n := admitted(ctx1, lim, getMethod, 2) + admitted(ctx1, lim, multiGetMethod, 2); n != 3 {
+		t.Errorf("Expected the burst of a token to span all methods. Actual: %d", n)
+	}
+	if n := admitted(ctx2, lim, getMethod, 5); n != 3 {
+		t.Errorf("Expected every token to be limited independently. Actual: %d", n)
+	}
+	if n := admitted(context.Background(), lim, getMethod, 5); n != 5 {
+		t.Errorf("Expected calls without tokens to be not limited per token. Actual: %d", n)
+	}
+}
+
+func TestLimiterExemptsReplication(t *testing.T) {
+	lim, _ := newTestLimiter(t, Limits{MethodRate: 1, MethodBurst: 1, MaxInFlight: 1, ReplicationRate: 100, ReplicationBurst: 10})
+	ctx := context.Background()
+	// Replication keeps up while the only call allowed in flight blocks
+	release, inHandler := make(chan struct{}), make(chan struct{})
+	go call(ctx, lim, getMethod, func(context.Context, interface{}) (interface{}, error) {
+		close(inHandler)
+		<-release
+		return nil, nil
+	})
+	<-inHandler
+	if n := admitted(ctx, lim, multiGetMethod, 1); n != 0 {
+		t.Error("Expected calls beyond the maximum in flight to be rejected")
+	}
+	if n := admitted(ctx, lim, getChangesMethod, 20); n != 10 {
+		t.Errorf("Expected replication calls to be limited by their own burst alone. Actual: %d", n)
+	}
+	close(release)
+}
+
+func TestLimiterExemptsLimitsService(t *testing.T) {
+	lim, _ := newTestLimiter(t, Limits{MethodRate: 1, MethodBurst: 1, MaxInFlight: 1})
+	ctx := context.Background()
+	// Limits can be relaxed while the only call allowed in flight blocks
+	release, inHandler := make(chan struct{}), make(chan struct{})
+	go call(ctx, lim, getMethod, func(context.Context, interface{}) (interface{}, error) {
+		close(inHandler)
+		<-release
+		return nil, nil
+	})
+	<-inHandler
+	defer close(release)
+	if n := admitted(ctx, lim, multiGetMethod, 1); n != 0 {
+		t.Error("Expected calls beyond the maximum in flight to be rejected")
+	}
+	if n := admitted(ctx, lim, setLimitsMethod, 5); n != 5 {
+		t.Errorf("Expected the calls of the limits service to be never limited. Actual: %d", n)
+	}
+	err := call(ctx, lim, setLimitsMethod, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return lim.SetLimits(ctx, &serverpb.SetLimitsRequest{Limits: &serverpb.Limits{}})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := admitted(ctx, lim, multiGetMethod, 5); n != 5 {
+		t.Errorf("Expected the relaxed limits to admit every call. Actual: %d", n)
+	}
+}
+
+func TestLimiterLimitsCallsInFlight(t *testing.T) {
+	lim, _ := newTestLimiter(t, Limits{MaxInFlight: 2})
+	ctx := context.Background()
+	release, inHandler := make(chan struct{}), make(chan struct{}, 2)
+	blocking := func(context.Context, interface{}) (interface{}, error) {
+		inHandler <- struct{}{}
+		<-release
+		return nil, nil
+	}
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- call(ctx, lim, getMethod, blocking) }()
+		<-inHandler
+	}
+	if err := call(ctx, lim, getMethod, noopHandler); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected the call beyond the maximum in flight to be rejected. Error: %v", err)
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("Expected the calls in flight to succeed. Error: %v", err)
+		}
+	}
+	if err := call(ctx, lim, getMethod, noopHandler); err != nil {
+		t.Errorf("Expected calls to be admitted once the calls in flight complete. Error: %v", err)
+	}
+}
+
+func TestLimiterUpdatesLimitsAtRuntime(t *testing.T) {
+	lim, _ := newTestLimiter(t, Limits{})
+	ctx := context.Background()
+	if st, _ := lim.SetLimits(ctx, &serverpb.SetLimitsRequest{Limits: &serverpb.Limits{MethodRate: 2}}); st.Code != 0 {
+		t.Fatalf("Unable to set limits. Status: %+v", st)
+	}
+	if n := admitted(ctx, lim, getMethod, 5); n != 2 {
+		t.Errorf("Expected the limits set to take effect immediately. Actual: %d", n)
+	}
+	if res, _ := lim.GetLimits(ctx, &serverpb.GetLimitsRequest{}); res.Limits.MethodRate != 2 || res.Limits.MethodBurst != 2 {
+		t.Errorf("Expected the burst to default to the rate. Actual: %+v", res.Limits)
+	}
+	if st, _ := lim.SetLimits(ctx, &serverpb.SetLimitsRequest{Limits: &serverpb.Limits{MethodRate: -1}}); st.Code == 0 {
+		t.Error("Expected negative rates to be rejected")
+	}
+}
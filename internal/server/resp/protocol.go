@@ -0,0 +1,163 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Limits on the commands read, beyond the size limits of the DKV
+// service so that those limits are reported as such.
+const (
+	maxBulkSize   = 64 << 20
+	maxNumArgs    = 1 << 20
+	maxInlineSize = 64 << 10
+)
+
+// protocolError is a malformed command, upon which the connection is
+// closed since the commands that follow can not be delimited.
+type protocolError string
+
+func (pe protocolError) Error() string {
+	return "Protocol error: " + string(pe)
+}
+
+// readCommand reads the arguments of the next command, sent either as
+// an array of bulk strings or as an inline command of space separated
+// arguments, as typed onto a telnet session. Empty inline commands are
+// skipped.
+func readCommand(r *bufio.Reader) ([][]byte, error) {
+	for {
+		line, err := readLine(r, maxInlineSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != '*' {
+			if args := bytes.Fields(line); len(args) > 0 {
+				return args, nil
+			}
+			continue
+		}
+		numArgs, err := strconv.Atoi(string(line[1:]))
+		if err != nil || numArgs > maxNumArgs {
+			return nil, protocolError("invalid multibulk length")
+		}
+		if numArgs <= 0 {
+			continue
+		}
+		args := make([][]byte, numArgs)
+		for i := range args {
+			if args[i], err = readBulk(r); err != nil {
+				return nil, err
+			}
+		}
+		return args, nil
+	}
+}
+
+func readBulk(r *bufio.Reader) ([]byte, error) {
+	line, err := readLine(r, maxInlineSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '$' {
+		return nil, protocolError(fmt.Sprintf("expected '$', got %q", line))
+	}
+	size, err := strconv.Atoi(string(line[1:]))
+	if err != nil || size < 0 || size > maxBulkSize {
+		return nil, protocolError("invalid bulk length")
+	}
+	bulk := make([]byte, size+2)
+	if _, err = io.ReadFull(r, bulk); err != nil {
+		return nil, err
+	}
+	if bulk[size] != '\r' || bulk[size+1] != '\n' {
+		return nil, protocolError("bulk string not terminated by CRLF")
+	}
+	return bulk[:size], nil
+}
+
+// readLine reads a line upto the given size, without its line ending,
+// of either CRLF or LF.
+func readLine(r *bufio.Reader, maxSize int) ([]byte, error) {
+	var line []byte
+	for {
+		frag, err := r.ReadSlice('\n')
+		line = append(line, frag...)
+		if len(line) > maxSize {
+			return nil, protocolError("too big inline request")
+		}
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			if err == io.EOF && len(line) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+	line = line[:len(line)-1]
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	return line, nil
+}
+
+// replyWriter writes RESP replies, which are buffered until flushed
+// so that the replies of pipelined commands are written together.
+type replyWriter struct {
+	*bufio.Writer
+}
+
+func (rw replyWriter) writeSimple(str string) {
+	rw.WriteByte('+')
+	rw.WriteString(str)
+	rw.WriteString("\r\n")
+}
+
+// writeError writes an error reply, whose message begins with a code
+// such as ERR or READONLY.
+func (rw replyWriter) writeError(msg string) {
+	rw.WriteByte('-')
+	// Line breaks would end the reply prematurely
+	for _, c := range []byte(msg) {
+		if c == '\r' || c == '\n' {
+			c = ' '
+		}
+		rw.WriteByte(c)
+	}
+	rw.WriteString("\r\n")
+}
+
+func (rw replyWriter) writeInt(n int64) {
+	rw.WriteByte(':')
+	rw.WriteString(strconv.FormatInt(n, 10))
+	rw.WriteString("\r\n")
+}
+
+// writeBulk writes the given bulk string, with nil written as the null
+// bulk string conveying an absent key.
+func (rw replyWriter) writeBulk(bulk []byte) {
+	if bulk == nil {
+		rw.WriteString("$-1\r\n")
+		return
+	}
+	rw.WriteByte('$')
+	rw.WriteString(strconv.Itoa(len(bulk)))
+	rw.WriteString("\r\n")
+	rw.Write(bulk)
+	rw.WriteString("\r\n")
+}
+
+func (rw replyWriter) writeArrayLen(n int) {
+	rw.WriteByte('*')
+	rw.WriteString(strconv.Itoa(n))
+	rw.WriteString("\r\n")
+}
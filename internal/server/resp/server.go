@@ -0,0 +1,424 @@
+// Package resp serves the DKV service over the Redis serialization
+// protocol (RESP), so that redis-cli and the existing Redis client
+// libraries can read and write keys of DKV. Commands are served by
+// invoking the DKV service directly, and authorized as the GRPC
+// methods they stand for, with the bearer token given by AUTH.
+//
+// Commands served are:
+//
+//	GET key                       - value of the key, nil if absent
+//	SET key value [EX s | PX ms]  - sets the value of the key, expiring it if given
+//	MGET key [key ...]            - values of the keys, nil for the absent ones
+//	DEL key [key ...]             - deletes the keys, replying the number deleted
+//	EXISTS key [key ...]          - number of the keys present
+//	PING [message]                - PONG, or the given message
+//	AUTH [username] token         - bearer token of the commands that follow
+//	QUIT                          - closes the connection
+//
+// Replies to pipelined commands are written together once every
+// command read is served. Failures of the DKV service are replied as
+// errors, with writes onto slave nodes replied as READONLY errors.
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+)
+
+// ErrServerClosed is returned by Serve once the server is closed.
+var ErrServerClosed = errors.New("resp: server closed")
+
+// An Authorizer authorizes every command against the GRPC method it
+// stands for, such as security.TokenAuthenticator.
+type Authorizer interface {
+	AuthorizeToken(token, fullMethod string) error
+}
+
+// An Option configures the server.
+type Option func(*Server)
+
+// WithAuthorizer authorizes every command using the given Authorizer,
+// whereas commands are not authorized at all by default.
+func WithAuthorizer(auth Authorizer) Option {
+	return func(srvr *Server) {
+		srvr.auth = auth
+	}
+}
+
+// WithLogger sets the logger of the failures of the connections.
+func WithLogger(lgr *zap.Logger) Option {
+	return func(srvr *Server) {
+		srvr.lgr = lgr
+	}
+}
+
+// Server serves the DKV service over RESP connections.
+type Server struct {
+	dkvSvc serverpb.DKVServer
+	auth   Authorizer
+	lgr    *zap.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	lises  map[net.Listener]struct{}
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewServer creates the server of the given DKV service.
+func NewServer(dkvSvc serverpb.DKVServer, opts ...Option) *Server {
+	srvr := &Server{dkvSvc: dkvSvc, lgr: zap.NewNop(), lises: make(map[net.Listener]struct{}), conns: make(map[net.Conn]struct{})}
+	for _, opt := range opts {
+		opt(srvr)
+	}
+	srvr.ctx, srvr.cancel = context.WithCancel(context.Background())
+	return srvr
+}
+
+// Serve accepts connections on the given listener, serving each of
+// them on its own goroutine, until the server is closed.
+func (srvr *Server) Serve(lis net.Listener) error {
+	srvr.mu.Lock()
+	if srvr.closed {
+		srvr.mu.Unlock()
+		lis.Close()
+		return ErrServerClosed
+	}
+	srvr.lises[lis] = struct{}{}
+	srvr.mu.Unlock()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			srvr.mu.Lock()
+			closed := srvr.closed
+			delete(srvr.lises, lis)
+			srvr.mu.Unlock()
+			lis.Close()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		srvr.mu.Lock()
+		if srvr.closed {
+			srvr.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		srvr.conns[conn] = struct{}{}
+		srvr.wg.Add(1)
+		srvr.mu.Unlock()
+		go srvr.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections and closes the ones being served,
+// waiting for their commands in flight to complete.
+func (srvr *Server) Close() error {
+	srvr.mu.Lock()
+	srvr.closed = true
+	for lis := range srvr.lises {
+		lis.Close()
+	}
+	for conn := range srvr.conns {
+		conn.Close()
+	}
+	srvr.mu.Unlock()
+	srvr.cancel()
+	srvr.wg.Wait()
+	return nil
+}
+
+// session is the state of a connection.
+type session struct {
+	ctx   context.Context
+	token string
+	quit  bool
+}
+
+func (srvr *Server) serveConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		srvr.mu.Lock()
+		delete(srvr.conns, conn)
+		srvr.mu.Unlock()
+		srvr.wg.Done()
+	}()
+
+	r := bufio.NewReader(conn)
+	rw := replyWriter{bufio.NewWriter(conn)}
+	sess := &session{ctx: srvr.ctx}
+	for !sess.quit {
+		args, err := readCommand(r)
+		if err != nil {
+			var protoErr protocolError
+			if errors.As(err, &protoErr) {
+				rw.writeError("ERR " + protoErr.Error())
+				rw.Flush()
+			} else if err != io.EOF && !srvr.isClosed() {
+				srvr.lgr.Debug("Unable to read command", zap.Stringer("remoteAddr", conn.RemoteAddr()), zap.Error(err))
+			}
+			return
+		}
+		srvr.execute(sess, rw, args)
+		// Replies of the pipelined commands already read are flushed
+		// together, when the last of them is served
+		if r.Buffered() == 0 || sess.quit {
+			if err = rw.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (srvr *Server) isClosed() bool {
+	srvr.mu.Lock()
+	defer srvr.mu.Unlock()
+	return srvr.closed
+}
+
+// GRPC methods for which the commands are authorized.
+const (
+	getMethod      = "/dkv.serverpb.DKV/Get"
+	putMethod      = "/dkv.serverpb.DKV/Put"
+	deleteMethod   = "/dkv.serverpb.DKV/Delete"
+	multiGetMethod = "/dkv.serverpb.DKV/MultiGet"
+	existsMethod   = "/dkv.serverpb.DKV/Exists"
+	healthMethod   = "/grpc.health.v1.Health/Check"
+)
+
+// command is a Redis command, whose arity is its number of arguments
+// including its name, or the negation of the minimum number of them.
+type command struct {
+	arity   int
+	method  string
+	handler func(srvr *Server, sess *session, rw replyWriter, args [][]byte)
+}
+
+var commands = map[string]command{
+	"get":    {2, getMethod, (*Server).get},
+	"set":    {-3, putMethod, (*Server).set},
+	"mget":   {-2, multiGetMethod, (*Server).mget},
+	"del":    {-2, deleteMethod, (*Server).del},
+	"exists": {-2, existsMethod, (*Server).exists},
+	"ping":   {-1, healthMethod, (*Server).ping},
+}
+
+// Commands that are recognized but not supported, with the reasons.
+var unsupportedCommands = map[string]string{
+	"cluster": "DKV nodes are not Redis cluster nodes",
+	"select":  "DKV has no numbered databases",
+}
+
+func (srvr *Server) execute(sess *session, rw replyWriter, args [][]byte) {
+	name := strings.ToLower(string(args[0]))
+	switch name {
+	case "auth":
+		srvr.authenticate(sess, rw, args)
+		return
+	case "quit":
+		rw.writeSimple("OK")
+		sess.quit = true
+		return
+	}
+	if reason, present := unsupportedCommands[name]; present {
+		rw.writeError(fmt.Sprintf("ERR '%s' command is not supported, since %s", strings.ToUpper(name), reason))
+		return
+	}
+	cmd, present := commands[name]
+	if !present {
+		rw.writeError(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+		return
+	}
+	if (cmd.arity > 0 && len(args) != cmd.arity) || len(args) < -cmd.arity {
+		rw.writeError(fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+		return
+	}
+	if srvr.auth != nil {
+		if err := srvr.auth.AuthorizeToken(sess.token, cmd.method); err != nil {
+			if sess.token == "" {
+				rw.writeError("NOAUTH Authentication required.")
+			} else {
+				rw.writeError("NOPERM " + errorMessage(err))
+			}
+			return
+		}
+	}
+	cmd.handler(srvr, sess, rw, args)
+}
+
+// authenticate takes the token given by AUTH as the bearer token of the
+// commands that follow, while ignoring the username given along.
+func (srvr *Server) authenticate(sess *session, rw replyWriter, args [][]byte) {
+	if len(args) != 2 && len(args) != 3 {
+		rw.writeError("ERR wrong number of arguments for 'auth' command")
+		return
+	}
+	if srvr.auth == nil {
+		rw.writeError("ERR AUTH called without any bearer tokens configured")
+		return
+	}
+	sess.token = string(args[len(args)-1])
+	rw.writeSimple("OK")
+}
+
+func (srvr *Server) get(sess *session, rw replyWriter, args [][]byte) {
+	res, err := srvr.dkvSvc.Get(sess.ctx, &serverpb.GetRequest{Key: args[1]})
+	if failed(rw, res.GetStatus(), err) {
+		return
+	}
+	if !res.Found {
+		rw.writeBulk(nil)
+		return
+	}
+	rw.writeBulk(nonNil(res.Value))
+}
+
+func (srvr *Server) set(sess *session, rw replyWriter, args [][]byte) {
+	putReq := &serverpb.PutRequest{Key: args[1], Value: args[2]}
+	for i := 3; i < len(args); i++ {
+		opt := strings.ToLower(string(args[i]))
+		if opt != "ex" && opt != "px" {
+			rw.writeError(fmt.Sprintf("ERR SET option '%s' is not supported", args[i]))
+			return
+		}
+		if i++; i == len(args) || putReq.ExpireTS != 0 {
+			rw.writeError("ERR syntax error")
+			return
+		}
+		ttl, err := strconv.ParseInt(string(args[i]), 10, 64)
+		if err != nil {
+			rw.writeError("ERR value is not an integer or out of range")
+			return
+		}
+		if ttl <= 0 {
+			rw.writeError("ERR invalid expire time in 'set' command")
+			return
+		}
+		unit := time.Second
+		if opt == "px" {
+			unit = time.Millisecond
+		}
+		// Expiry is in whole seconds, hence rounded up
+		expireAt := time.Now().Add(time.Duration(ttl) * unit)
+		putReq.ExpireTS = uint64((expireAt.UnixNano() + int64(time.Second) - 1) / int64(time.Second))
+	}
+	res, err := srvr.dkvSvc.Put(sess.ctx, putReq)
+	if failed(rw, res.GetStatus(), err) {
+		return
+	}
+	rw.writeSimple("OK")
+}
+
+func (srvr *Server) mget(sess *session, rw replyWriter, args [][]byte) {
+	keys := args[1:]
+	res, err := srvr.dkvSvc.MultiGet(sess.ctx, &serverpb.MultiGetRequest{Keys: keys})
+	if failed(rw, res.GetStatus(), err) {
+		return
+	}
+	if len(res.Values) != len(keys) || len(res.Found) != len(keys) {
+		rw.writeError(fmt.Sprintf("ERR MGET returned %d values for %d keys", len(res.Values), len(keys)))
+		return
+	}
+	rw.writeArrayLen(len(keys))
+	for i, value := range res.Values {
+		if res.Found[i] {
+			rw.writeBulk(nonNil(value))
+		} else {
+			rw.writeBulk(nil)
+		}
+	}
+}
+
+// del deletes every key, replying the number of keys found present
+// before. Absent keys are deleted as well, so that deletes onto slave
+// nodes fail alike.
+func (srvr *Server) del(sess *session, rw replyWriter, args [][]byte) {
+	existsRes, err := srvr.dkvSvc.Exists(sess.ctx, &serverpb.ExistsRequest{Keys: args[1:]})
+	if failed(rw, existsRes.GetStatus(), err) {
+		return
+	}
+	var numDeleted int64
+	for i, key := range args[1:] {
+		res, err := srvr.dkvSvc.Delete(sess.ctx, &serverpb.DeleteRequest{Key: key})
+		if failed(rw, res.GetStatus(), err) {
+			return
+		}
+		if i < len(existsRes.Exists) && existsRes.Exists[i] {
+			numDeleted++
+		}
+	}
+	rw.writeInt(numDeleted)
+}
+
+func (srvr *Server) exists(sess *session, rw replyWriter, args [][]byte) {
+	res, err := srvr.dkvSvc.Exists(sess.ctx, &serverpb.ExistsRequest{Keys: args[1:]})
+	if failed(rw, res.GetStatus(), err) {
+		return
+	}
+	var numExists int64
+	for _, exists := range res.Exists {
+		if exists {
+			numExists++
+		}
+	}
+	rw.writeInt(numExists)
+}
+
+func (srvr *Server) ping(sess *session, rw replyWriter, args [][]byte) {
+	switch len(args) {
+	case 1:
+		rw.writeSimple("PONG")
+	case 2:
+		rw.writeBulk(args[1])
+	default:
+		rw.writeError("ERR wrong number of arguments for 'ping' command")
+	}
+}
+
+// failed writes the error reply of a call onto the DKV service that
+// failed, reporting whether it did.
+func failed(rw replyWriter, st *serverpb.Status, err error) bool {
+	switch {
+	case err != nil:
+		rw.writeError("ERR " + errorMessage(err))
+	case st != nil && st.Code == int32(serverpb.StatusCode_ReadOnlyReplica):
+		rw.writeError("READONLY You can't write against a read only replica.")
+	case st != nil && st.Code != int32(serverpb.StatusCode_Ok):
+		rw.writeError("ERR " + st.Message)
+	default:
+		return false
+	}
+	return true
+}
+
+func errorMessage(err error) string {
+	if st, ok := status.FromError(err); ok {
+		return st.Message()
+	}
+	return err.Error()
+}
+
+// nonNil returns an empty value for the given nil value of a present
+// key, so that it is not written as an absent key.
+func nonNil(value []byte) []byte {
+	if value == nil {
+		return []byte{}
+	}
+	return value
+}
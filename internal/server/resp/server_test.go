@@ -0,0 +1,221 @@
+package resp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/server/master"
+	"github.com/flipkart-incubator/dkv/internal/server/security"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/go-redis/redis"
+)
+
+const (
+	readToken  = "reader"
+	writeToken = "writer"
+)
+
+// readOnlyService fails the writes as slave nodes do.
+type readOnlyService struct {
+	master.DKVService
+}
+
+func (ros readOnlyService) Put(context.Context, *serverpb.PutRequest) (*serverpb.PutResponse, error) {
+	return &serverpb.PutResponse{Status: &serverpb.Status{Code: int32(serverpb.StatusCode_ReadOnlyReplica), Message: "read only replica"}}, nil
+}
+
+func (ros readOnlyService) Delete(context.Context, *serverpb.DeleteRequest) (*serverpb.DeleteResponse, error) {
+	return &serverpb.DeleteResponse{Status: &serverpb.Status{Code: int32(serverpb.StatusCode_ReadOnlyReplica), Message: "read only replica"}}, nil
+}
+
+func serve(t *testing.T, dkvSvc serverpb.DKVServer, opts ...Option) (string, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvr := NewServer(dkvSvc, opts...)
+	go srvr.Serve(lis)
+	return lis.Addr().String(), func() { srvr.Close() }
+}
+
+func newDKVService() master.DKVService {
+	store := memory.OpenDB(0)
+	return master.NewStandaloneService(store, nil, store)
+}
+
+func TestRedisClientCommands(t *testing.T) {
+	dkvSvc := newDKVService()
+	defer dkvSvc.Close()
+	addr, stop := serve(t, dkvSvc)
+	defer stop()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+
+	if pong, err := client.Ping().Result(); err != nil || pong != "PONG" {
+		t.Fatalf("Expected PONG. Actual: %q, Error: %v", pong, err)
+	}
+	if err := client.Set("k1", "v1", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Set("k2", "", time.Minute).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if val, err := client.Get("k1").Result(); err != nil || val != "v1" {
+		t.Errorf("Expected value: v1. Actual: %q, Error: %v", val, err)
+	}
+	// Empty values are told apart from absent keys
+	if val, err := client.Get("k2").Result(); err != nil || val != "" {
+		t.Errorf("Expected an empty value. Actual: %q, Error: %v", val, err)
+	}
+	if _, err := client.Get("k3").Result(); err != redis.Nil {
+		t.Errorf("Expected an absent key. Error: %v", err)
+	}
+	vals, err := client.MGet("k1", "k3", "k2").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expVals := []interface{}{"v1", nil, ""}; fmt.Sprint(vals) != fmt.Sprint(expVals) {
+		t.Errorf("Expected values: %v. Actual: %v", expVals, vals)
+	}
+	if n, err := client.Exists("k1", "k2", "k3", "k1").Result(); err != nil || n != 3 {
+		t.Errorf("Expected 3 keys to exist. Actual: %d, Error: %v", n, err)
+	}
+	if n, err := client.Del("k1", "k3").Result(); err != nil || n != 1 {
+		t.Errorf("Expected 1 key to be deleted. Actual: %d, Error: %v", n, err)
+	}
+	if n, err := client.Exists("k1").Result(); err != nil || n != 0 {
+		t.Errorf("Expected key to be deleted. Actual: %d, Error: %v", n, err)
+	}
+
+	pipe := client.Pipeline()
+	var gets []*redis.StringCmd
+	for i := 0; i < 100; i++ {
+		pipe.Set(fmt.Sprintf("p%d", i), i, 0)
+		gets = append(gets, pipe.Get(fmt.Sprintf("p%d", i)))
+	}
+	if _, err = pipe.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	for i, cmd := range gets {
+		if val := cmd.Val(); val != fmt.Sprint(i) {
+			t.Errorf("Expected pipelined value: %d. Actual: %q", i, val)
+		}
+	}
+
+	for _, cmd := range [][]interface{}{{"select", 1}, {"cluster", "info"}} {
+		if err := client.Do(cmd...).Err(); err == nil || !strings.Contains(err.Error(), "not supported") {
+			t.Errorf("Expected %v to be not supported. Error: %v", cmd, err)
+		}
+	}
+	if err := client.Do("incr", "k1").Err(); err == nil || !strings.HasPrefix(err.Error(), "ERR unknown command") {
+		t.Errorf("Expected an unknown command. Error: %v", err)
+	}
+	if err := client.Do("get").Err(); err == nil || !strings.Contains(err.Error(), "wrong number of arguments") {
+		t.Errorf("Expected wrong number of arguments. Error: %v", err)
+	}
+	if err := client.Do("set", "k1", "v1", "nx").Err(); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected SET option to be not supported. Error: %v", err)
+	}
+}
+
+func TestReadOnlyReplica(t *testing.T) {
+	dkvSvc := newDKVService()
+	defer dkvSvc.Close()
+	addr, stop := serve(t, readOnlyService{dkvSvc})
+	defer stop()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+
+	for _, cmd := range []redis.Cmder{client.Set("k1", "v1", 0), client.Del("k1")} {
+		if err := cmd.Err(); err == nil || !strings.HasPrefix(err.Error(), "READONLY") {
+			t.Errorf("Expected a READONLY error for %v. Error: %v", cmd.Args(), err)
+		}
+	}
+	if _, err := client.Get("k1").Result(); err != redis.Nil {
+		t.Errorf("Expected reads to be served. Error: %v", err)
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	dkvSvc := newDKVService()
+	defer dkvSvc.Close()
+	auth := security.NewTokenAuthenticator(map[string]security.Scope{
+		readToken:  security.ReadScope,
+		writeToken: security.ReadScope | security.WriteScope,
+	})
+	defer auth.Close()
+	addr, stop := serve(t, dkvSvc, WithAuthorizer(auth))
+	defer stop()
+
+	writer := redis.NewClient(&redis.Options{Addr: addr, Password: writeToken})
+	defer writer.Close()
+	if err := writer.Set("k1", "v1", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	reader := redis.NewClient(&redis.Options{Addr: addr, Password: readToken})
+	defer reader.Close()
+	if val, err := reader.Get("k1").Result(); err != nil || val != "v1" {
+		t.Errorf("Expected value: v1. Actual: %q, Error: %v", val, err)
+	}
+	if err := reader.Set("k1", "v2", 0).Err(); err == nil || !strings.HasPrefix(err.Error(), "NOPERM") {
+		t.Errorf("Expected a NOPERM error. Error: %v", err)
+	}
+	anon := redis.NewClient(&redis.Options{Addr: addr})
+	defer anon.Close()
+	if err := anon.Get("k1").Err(); err == nil || !strings.HasPrefix(err.Error(), "NOAUTH") {
+		t.Errorf("Expected a NOAUTH error. Error: %v", err)
+	}
+	// Health probes need no tokens
+	if err := anon.Ping().Err(); err != nil {
+		t.Errorf("Expected PING to be permitted. Error: %v", err)
+	}
+}
+
+func TestInlineAndPipelinedCommands(t *testing.T) {
+	dkvSvc := newDKVService()
+	defer dkvSvc.Close()
+	addr, stop := serve(t, dkvSvc)
+	defer stop()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Pipelined commands, inline as well as multibulk, sent at once
+	cmds := "SET k1 v1\r\n*2\r\n$3\r\nGET\r\n$2\r\nk1\r\n\r\nPING hello\nGET k2\r\nQUIT\r\n"
+	if _, err = conn.Write([]byte(cmds)); err != nil {
+		t.Fatal(err)
+	}
+	replies, err := ioutil.ReadAll(bufio.NewReader(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expReplies := "+OK\r\n$2\r\nv1\r\n$5\r\nhello\r\n$-1\r\n+OK\r\n"; string(replies) != expReplies {
+		t.Errorf("Expected replies: %q. Actual: %q", expReplies, replies)
+	}
+
+	conn2, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+	if _, err = conn2.Write([]byte("*1\r\n+PING\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	// Connection is closed upon protocol errors
+	replies, err = ioutil.ReadAll(conn2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(replies), "-ERR Protocol error") {
+		t.Errorf("Expected a protocol error. Actual: %q", replies)
+	}
+}
@@ -0,0 +1,299 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// A Scope grants access onto a group of DKV methods. A token can be
+// granted multiple scopes by combining them, as in ReadScope|WriteScope.
+type Scope uint8
+
+const (
+	// ReadScope grants access onto the methods that read the keyspace,
+	// namely Get, MultiGet, Exists and Iterate, along with the status
+	// of replication.
+	ReadScope Scope = 1 << iota
+	// WriteScope grants access onto the methods that mutate the keyspace.
+	WriteScope
+	// ReplicationScope grants access onto the methods that slave nodes
+	// use for replicating changes from their master node.
+	ReplicationScope
+	// AdminScope grants access onto the methods that manage a DKV node,
+	// such as backups, restores, cluster membership and failovers.
+	AdminScope
+)
+
+var scopesByName = map[string]Scope{
+	"read":        ReadScope,
+	"write":       WriteScope,
+	"replication": ReplicationScope,
+	"admin":       AdminScope,
+}
+
+// ParseScopes parses the comma separated names of scopes, which
+// are any of read|write|replication|admin.
+func ParseScopes(names string) (Scope, error) {
+	var scope Scope
+	for _, name := range strings.Split(names, ",") {
+		s, present := scopesByName[strings.ToLower(strings.TrimSpace(name))]
+		if !present {
+			return 0, fmt.Errorf("unknown scope: %s. Allowed values are read|write|replication|admin", name)
+		}
+		scope |= s
+	}
+	return scope, nil
+}
+
+// ParseTokens parses the tokens, along with their scopes, from the
+// given text. Every token is given as <token>:<scope>[,<scope>...]
+// and is separated from the next either by a newline or a semicolon.
+// Blank lines and lines starting with # are ignored.
+func ParseTokens(text string) (map[string]Scope, error) {
+	tokens := make(map[string]Scope)
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, entry := range strings.Split(line, ";") {
+			if entry = strings.TrimSpace(entry); entry == "" {
+				continue
+			}
+			i := strings.LastIndex(entry, ":")
+			if i <= 0 {
+				return nil, fmt.Errorf("invalid token entry, expected <token>:<scope>[,<scope>...]")
+			}
+			scope, err := ParseScopes(entry[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			tokens[strings.TrimSpace(entry[:i])] |= scope
+		}
+	}
+	return tokens, nil
+}
+
+// Scopes required by the methods of every DKV service. Methods absent
+// here require the AdminScope, except the health checking service
+// that is open to all so that load balancers can probe the nodes.
+var methodScopes = map[string]Scope{
+	"/dkv.serverpb.DKV/Put":           WriteScope,
+	"/dkv.serverpb.DKV/MultiPut":      WriteScope,
+	"/dkv.serverpb.DKV/Delete":        WriteScope,
+	"/dkv.serverpb.DKV/Undelete":      WriteScope,
+	"/dkv.serverpb.DKV/CompareAndSet": WriteScope,
+	"/dkv.serverpb.DKV/Increment":     WriteScope,
+	"/dkv.serverpb.DKV/Txn":           WriteScope,
+	"/dkv.serverpb.DKV/Get":           ReadScope,
+	"/dkv.serverpb.DKV/MultiGet":      ReadScope,
+	"/dkv.serverpb.DKV/Exists":        ReadScope,
+	"/dkv.serverpb.DKV/Iterate":       ReadScope,
+
+	"/dkv.serverpb.DKVReplication/GetChanges":    ReplicationScope,
+	"/dkv.serverpb.DKVReplication/StreamChanges": ReplicationScope,
+	"/dkv.serverpb.DKVReplication/GetCheckpoint": ReplicationScope,
+
+	"/dkv.serverpb.DKVReplicationStatus/GetStatus": ReadScope,
+
+	"/dkv.serverpb.DKVInfo/GetServerInfo": ReadScope,
+}
+
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// Name of the metadata key carrying the token of every call.
+const authMetadataKey = "authorization"
+
+const bearerPrefix = "Bearer "
+
+// TokenAuthenticator authorizes every GRPC call against the scopes
+// of the bearer token it carries in its `authorization` metadata.
+// Calls lacking a token, or carrying one that is unknown or lacks the
+// required scope, are rejected with the PERMISSION_DENIED code.
+type TokenAuthenticator struct {
+	tokens atomic.Value // map[string]Scope
+
+	tokenFile string
+	// Guards the loading of the token file, along with its modTime
+	mu        sync.Mutex
+	modTime   time.Time
+	lgr       *zap.Logger
+	stopChan  chan struct{}
+	closeOnce sync.Once
+}
+
+// NewTokenAuthenticator creates an authenticator that accepts only
+// the given tokens, each restricted to its scopes.
+func NewTokenAuthenticator(tokens map[string]Scope) *TokenAuthenticator {
+	ta := &TokenAuthenticator{stopChan: make(chan struct{})}
+	ta.tokens.Store(tokens)
+	return ta
+}
+
+// NewFileTokenAuthenticator creates an authenticator that accepts the
+// tokens given in the file, as per the format of ParseTokens. The file
+// is checked for modifications at the given interval and reloaded when
+// modified, so that tokens can be rotated without restarting the node.
+// Reload failures are logged, with the previous tokens left in effect.
+func NewFileTokenAuthenticator(tokenFile string, reloadInterval time.Duration, lgr *zap.Logger) (*TokenAuthenticator, error) {
+	ta := &TokenAuthenticator{tokenFile: tokenFile, lgr: lgr, stopChan: make(chan struct{})}
+	if err := ta.loadTokenFile(); err != nil {
+		return nil, err
+	}
+	go ta.reloadTokenFile(reloadInterval)
+	return ta, nil
+}
+
+func (ta *TokenAuthenticator) loadTokenFile() error {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+	info, err := os.Stat(ta.tokenFile)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(ta.modTime) {
+		return nil
+	}
+	tokens, modTime, err := ta.readTokenFile()
+	if err != nil {
+		return err
+	}
+	ta.setTokens(tokens, modTime)
+	return nil
+}
+
+// readTokenFile reads the token file regardless of its modifications,
+// returning its tokens along with its modification time.
+func (ta *TokenAuthenticator) readTokenFile() (map[string]Scope, time.Time, error) {
+	info, err := os.Stat(ta.tokenFile)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := ioutil.ReadFile(ta.tokenFile)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	tokens, err := ParseTokens(string(data))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid token file %s: %v", ta.tokenFile, err)
+	}
+	return tokens, info.ModTime(), nil
+}
+
+func (ta *TokenAuthenticator) setTokens(tokens map[string]Scope, modTime time.Time) {
+	ta.tokens.Store(tokens)
+	ta.modTime = modTime
+}
+
+func (ta *TokenAuthenticator) currentTokens() map[string]Scope {
+	return ta.tokens.Load().(map[string]Scope)
+}
+
+func (ta *TokenAuthenticator) reloadTokenFile(reloadInterval time.Duration) {
+	tckr := time.NewTicker(reloadInterval)
+	defer tckr.Stop()
+	for {
+		select {
+		case <-tckr.C:
+			if err := ta.loadTokenFile(); err != nil {
+				ta.lgr.Error("Unable to reload the tokens", zap.String("tokenFile", ta.tokenFile), zap.Error(err))
+			}
+		case <-ta.stopChan:
+			return
+		}
+	}
+}
+
+// Close stops reloading the token file, if any.
+func (ta *TokenAuthenticator) Close() {
+	ta.closeOnce.Do(func() { close(ta.stopChan) })
+}
+
+// UnaryServerInterceptor authorizes every unary GRPC call.
+func (ta *TokenAuthenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := ta.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authorizes every streaming GRPC call.
+func (ta *TokenAuthenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := ta.authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// AuthorizeHTTP authorizes an HTTP request that invokes the given GRPC
+// method, against the bearer token of its Authorization header, as if
+// the GRPC method were called with that token.
+func (ta *TokenAuthenticator) AuthorizeHTTP(req *http.Request, fullMethod string) error {
+	return ta.authorizeToken(bearerToken(req.Header[http.CanonicalHeaderKey(authMetadataKey)]), fullMethod)
+}
+
+// AuthorizeToken authorizes a request that invokes the given GRPC
+// method with the given bearer token, for front-ends carrying tokens
+// by other means, such as the AUTH command of the Redis protocol.
+func (ta *TokenAuthenticator) AuthorizeToken(token, fullMethod string) error {
+	return ta.authorizeToken(token, fullMethod)
+}
+
+func (ta *TokenAuthenticator) authorize(ctx context.Context, fullMethod string) error {
+	return ta.authorizeToken(BearerToken(ctx), fullMethod)
+}
+
+func (ta *TokenAuthenticator) authorizeToken(token, fullMethod string) error {
+	if strings.HasPrefix(fullMethod, healthServicePrefix) {
+		return nil
+	}
+	reqdScope, present := methodScopes[fullMethod]
+	if !present {
+		reqdScope = AdminScope
+	}
+	if token == "" {
+		return status.Error(codes.PermissionDenied, "missing bearer token")
+	}
+	scope, present := ta.currentTokens()[token]
+	if !present {
+		return status.Error(codes.PermissionDenied, "unknown bearer token")
+	}
+	if scope&reqdScope == 0 {
+		return status.Errorf(codes.PermissionDenied, "bearer token lacks the scope required by %s", fullMethod)
+	}
+	return nil
+}
+
+// BearerToken returns the bearer token carried in the `authorization`
+// metadata of the GRPC call of the given context, if any.
+func BearerToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return bearerToken(md.Get(authMetadataKey))
+}
+
+// bearerToken returns the token of the first of the given values of
+// the authorization metadata or header that carries a bearer token.
+func bearerToken(vals []string) string {
+	for _, val := range vals {
+		if strings.HasPrefix(val, bearerPrefix) {
+			return strings.TrimSpace(strings.TrimPrefix(val, bearerPrefix))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package security
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseTokens(t *testing.T) {
+	tokens, err := ParseTokens("# comment\nt1:read\n\n t2 : read, write ;t3:replication\n")
+	if err != nil {
+		t.Fatalf("Unable to parse tokens. Error: %v", err)
+	}
+	expTokens := map[string]Scope{"t1": ReadScope, "t2": ReadScope | WriteScope, "t3": ReplicationScope}
+	if len(tokens) != len(expTokens) {
+		t.Errorf("Tokens mismatch. Expected: %v, Actual: %v", expTokens, tokens)
+	}
+	for token, expScope := range expTokens {
+		if scope := tokens[token]; scope != expScope {
+			t.Errorf("Scope mismatch for token: %s. Expected: %v, Actual: %v", token, expScope, scope)
+		}
+	}
+	for _, text := range []string{"t1", ":read", "t1:delete"} {
+		if _, err := ParseTokens(text); err == nil {
+			t.Errorf("Expected an error on parsing tokens: %q", text)
+		}
+	}
+}
+
+func TestTokenAuthenticatorScopes(t *testing.T) {
+	auth := NewTokenAuthenticator(map[string]Scope{"reader": ReadScope, "replicator": ReplicationScope, "admin": AdminScope})
+	defer auth.Close()
+
+	checks := []struct {
+		token, method string
+		permitted     bool
+	}{
+		{"reader", "/dkv.serverpb.DKV/Get", true},
+		{"reader", "/dkv.serverpb.DKV/Iterate", true},
+		{"reader", "/dkv.serverpb.DKV/Put", false},
+		{"reader", "/dkv.serverpb.DKVReplication/GetChanges", false},
+		{"replicator", "/dkv.serverpb.DKVReplication/StreamChanges", true},
+		{"replicator", "/dkv.serverpb.DKV/Get", false},
+		{"admin", "/dkv.serverpb.DKVBackupRestore/Backup", true},
+		{"reader", "/dkv.serverpb.DKVFailover/PromoteToMaster", false},
+		{"reader", "/dkv.serverpb.DKVStorage/CompactRange", false},
+		{"admin", "/dkv.serverpb.DKVStorage/Flush", true},
+		{"", "/grpc.health.v1.Health/Check", true},
+		{"", "/dkv.serverpb.DKV/Get", false},
+		{"unknown", "/dkv.serverpb.DKV/Get", false},
+	}
+	for _, check := range checks {
+		err := authorize(auth, check.token, check.method)
+		if check.permitted && err != nil {
+			t.Errorf("Expected token: %q to be permitted onto %s. Error: %v", check.token, check.method, err)
+		}
+		if !check.permitted && status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected token: %q to be denied %s. Error: %v", check.token, check.method, err)
+		}
+		// HTTP requests are authorized alike
+		httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+		if check.token != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+check.token)
+		}
+		if err = auth.AuthorizeHTTP(httpReq, check.method); check.permitted != (err == nil) {
+			t.Errorf("Expected token: %q to be permitted: %t onto %s over HTTP. Error: %v", check.token, check.permitted, check.method, err)
+		}
+		if err = auth.AuthorizeToken(check.token, check.method); check.permitted != (err == nil) {
+			t.Errorf("Expected token: %q to be permitted: %t onto %s. Error: %v", check.token, check.permitted, check.method, err)
+		}
+	}
+}
+
+func TestFileTokenAuthenticatorReloads(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dkv_auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := path.Join(dir, "tokens")
+	if err := ioutil.WriteFile(tokenFile, []byte("old:read"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := NewFileTokenAuthenticator(tokenFile, 10*time.Millisecond, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Unable to create the authenticator. Error: %v", err)
+	}
+	defer auth.Close()
+	if err := authorize(auth, "old", "/dkv.serverpb.DKV/Get"); err != nil {
+		t.Errorf("Expected the token in the file to be permitted. Error: %v", err)
+	}
+
+	// Ensures a distinct modification time irrespective of its granularity
+	if err := ioutil.WriteFile(tokenFile, []byte("new:read"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(tokenFile, time.Now(), time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := authorize(auth, "old", "/dkv.serverpb.DKV/Get"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected the rotated token to be denied. Error: %v", err)
+	}
+	if err := authorize(auth, "new", "/dkv.serverpb.DKV/Get"); err != nil {
+		t.Errorf("Expected the reloaded token to be permitted. Error: %v", err)
+	}
+
+	if _, err := NewFileTokenAuthenticator(path.Join(dir, "missing"), time.Second, zap.NewNop()); err == nil {
+		t.Error("Expected an error for a missing token file")
+	}
+}
+
+// authorize invokes the unary interceptor of the authenticator
+// with the given token, as carried by a DKV client
+func authorize(auth *TokenAuthenticator, token, method string) error {
+	ctx := context.Background()
+	if token != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	_, err := auth.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	return err
+}
@@ -0,0 +1,131 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+)
+
+// Names of what is reported as reloaded by a Reloader.
+const (
+	reloadedCertificate = "certificate"
+	reloadedCA          = "ca"
+	reloadedTokens      = "tokens"
+)
+
+// A Reloader reloads the TLS files of a ReloadableTLS along with the
+// token file of a TokenAuthenticator, either of which may be nil, so
+// that they can be rotated without restarting the node. It serves the
+// DKVSecurity service, while Reload can be invoked directly as well,
+// such as upon a SIGHUP.
+type Reloader struct {
+	tls  *ReloadableTLS
+	auth *TokenAuthenticator
+	lgr  *zap.Logger
+	mu   sync.Mutex
+}
+
+// NewReloader creates a Reloader of the given TLS files and tokens.
+// Tokens that are not loaded from a file are left as is.
+func NewReloader(rt *ReloadableTLS, auth *TokenAuthenticator, lgr *zap.Logger) *Reloader {
+	return &Reloader{tls: rt, auth: auth, lgr: lgr}
+}
+
+// ReloadReport describes the outcome of Reloader.Reload.
+type ReloadReport struct {
+	// Reloaded lists what was reloaded, among certificate, ca and
+	// tokens, leaving out those whose files are unchanged.
+	Reloaded []string
+	// Errors are the validation errors of the files, upon which none
+	// of them are reloaded.
+	Errors []error
+	// CertificateNotAfter is the expiry of the certificate served,
+	// which is zero without TLS.
+	CertificateNotAfter time.Time
+	// NumTokens is the number of tokens accepted, which is zero
+	// without tokens.
+	NumTokens int
+}
+
+// Reload reads all the files and validates them, applying either all
+// of them or none of them upon any validation error, so that a partly
+// rotated certificate, key or CA is never served.
+func (r *Reloader) Reload() *ReloadReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := &ReloadReport{}
+	var files *tlsFiles
+	if r.tls != nil {
+		var err error
+		if files, err = r.tls.load(); err != nil {
+			report.Errors = append(report.Errors, err)
+		}
+	}
+	var tokens map[string]Scope
+	var modTime time.Time
+	if r.auth != nil && r.auth.tokenFile != "" {
+		// Blocks the periodic reloads of the token file meanwhile
+		r.auth.mu.Lock()
+		defer r.auth.mu.Unlock()
+		var err error
+		if tokens, modTime, err = r.auth.readTokenFile(); err != nil {
+			report.Errors = append(report.Errors, err)
+		}
+	}
+
+	if len(report.Errors) == 0 {
+		if files != nil {
+			prevFiles := r.tls.current()
+			if files.certSum != prevFiles.certSum {
+				report.Reloaded = append(report.Reloaded, reloadedCertificate)
+			}
+			if files.caSum != prevFiles.caSum {
+				report.Reloaded = append(report.Reloaded, reloadedCA)
+			}
+			r.tls.files.Store(files)
+		}
+		if tokens != nil {
+			if !reflect.DeepEqual(tokens, r.auth.currentTokens()) {
+				report.Reloaded = append(report.Reloaded, reloadedTokens)
+			}
+			r.auth.setTokens(tokens, modTime)
+		}
+	}
+	if r.tls != nil {
+		report.CertificateNotAfter = r.tls.current().leaf.NotAfter
+	}
+	if r.auth != nil {
+		report.NumTokens = len(r.auth.currentTokens())
+	}
+
+	if len(report.Errors) > 0 {
+		r.lgr.Error("Unable to reload the security config, retaining the current one", zap.Errors("errors", report.Errors))
+	} else {
+		r.lgr.Info("Reloaded the security config", zap.Strings("reloaded", report.Reloaded), zap.Time("certificateNotAfter", report.CertificateNotAfter), zap.Int("numTokens", report.NumTokens))
+	}
+	return report
+}
+
+// ReloadSecurityConfig reloads the files as per Reload, failing with
+// the InvalidArgument code upon any validation error.
+func (r *Reloader) ReloadSecurityConfig(context.Context, *serverpb.ReloadSecurityConfigRequest) (*serverpb.ReloadSecurityConfigResponse, error) {
+	report := r.Reload()
+	res := &serverpb.ReloadSecurityConfigResponse{Status: &serverpb.Status{}, Reloaded: report.Reloaded, NumberOfTokens: uint32(report.NumTokens)}
+	if !report.CertificateNotAfter.IsZero() {
+		res.CertificateNotAfter = report.CertificateNotAfter.Unix()
+	}
+	if len(report.Errors) > 0 {
+		for _, err := range report.Errors {
+			res.Errors = append(res.Errors, err.Error())
+		}
+		res.Status = dkverrors.NewStatus(fmt.Errorf("%w: %s", dkverrors.ErrInvalidArgument, strings.Join(res.Errors, "; ")))
+	}
+	return res, nil
+}
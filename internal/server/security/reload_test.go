@@ -0,0 +1,108 @@
+package security
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dkv_reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile, tokenFile := path.Join(dir, "server.crt"), path.Join(dir, "server.key"), path.Join(dir, "tokens")
+	notAfter := time.Now().Add(time.Hour).Truncate(time.Second)
+	writeSelfSignedCert(t, certFile, keyFile, notAfter)
+	writeFile(t, tokenFile, "old:read")
+
+	srvrTLS, err := NewReloadableTLS(certFile, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := NewFileTokenAuthenticator(tokenFile, time.Hour, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auth.Close()
+	reloader := NewReloader(srvrTLS, auth, zap.NewNop())
+	report := reloader.Reload()
+	if len(report.Reloaded) > 0 || len(report.Errors) > 0 || report.NumTokens != 1 || !report.CertificateNotAfter.Equal(notAfter) {
+		t.Errorf("Expected nothing to be reloaded from the unchanged files. Report: %+v", report)
+	}
+
+	// Tokens are not reloaded along with a key mismatching the certificate
+	writeFile(t, tokenFile, "new:read\nnewer:write")
+	prevCert, _ := ioutil.ReadFile(certFile)
+	writeSelfSignedCert(t, certFile, keyFile, notAfter.Add(time.Hour))
+	writeFile(t, certFile, string(prevCert))
+	if report = reloader.Reload(); len(report.Errors) != 1 || len(report.Reloaded) > 0 || report.NumTokens != 1 {
+		t.Errorf("Expected the mismatching key to fail the reload. Report: %+v", report)
+	}
+	if err = authorize(auth, "new", "/dkv.serverpb.DKV/Get"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected the token not to be reloaded. Error: %v", err)
+	}
+
+	writeSelfSignedCert(t, certFile, keyFile, notAfter.Add(time.Hour))
+	report = reloader.Reload()
+	if len(report.Errors) > 0 || strings.Join(report.Reloaded, ",") != "certificate,tokens" || report.NumTokens != 2 || !report.CertificateNotAfter.Equal(notAfter.Add(time.Hour)) {
+		t.Errorf("Expected the certificate and the tokens to be reloaded. Report: %+v", report)
+	}
+	if err = authorize(auth, "new", "/dkv.serverpb.DKV/Get"); err != nil {
+		t.Errorf("Expected the reloaded token to be permitted. Error: %v", err)
+	}
+
+	writeFile(t, tokenFile, "new:delete")
+	res, _ := reloader.ReloadSecurityConfig(context.Background(), &serverpb.ReloadSecurityConfigRequest{})
+	if res.Status.Code != int32(serverpb.StatusCode_InvalidArgument) || len(res.Errors) != 1 || res.NumberOfTokens != 2 {
+		t.Errorf("Expected the invalid token file to fail the reload. Response: %+v", res)
+	}
+}
+
+func writeSelfSignedCert(t *testing.T, certFile, keyFile string, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, certFile, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})))
+	writeFile(t, keyFile, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+}
+
+func writeFile(t *testing.T, file, data string) {
+	if err := ioutil.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
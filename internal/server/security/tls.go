@@ -0,0 +1,158 @@
+package security
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// NewServerTLSCredentials creates the GRPC transport credentials for
+// serving DKV over TLS using the given certificate and private key files.
+// If `caFile` is provided, mutual TLS is enforced by requiring every
+// client to present a certificate signed by one of the CAs in this file.
+func NewServerTLSCredentials(certFile, keyFile, caFile string) (credentials.TransportCredentials, error) {
+	tlsConf, err := NewServerTLSConfig(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConf), nil
+}
+
+// NewServerTLSConfig creates the TLS configuration used by the DKV
+// server. See `NewServerTLSCredentials` for the semantics of params.
+func NewServerTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	files, err := loadTLSFiles(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return files.conf, nil
+}
+
+// tlsFiles is the TLS configuration loaded from the given files, along
+// with the checksums of the files for telling whether they are modified.
+type tlsFiles struct {
+	conf    *tls.Config
+	leaf    *x509.Certificate
+	certSum [sha256.Size]byte
+	caSum   [sha256.Size]byte
+}
+
+func loadTLSFiles(certFile, keyFile, caFile string) (*tlsFiles, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("both certificate and key files are required for TLS")
+	}
+	certPEM, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	if now := time.Now(); now.After(leaf.NotAfter) || now.Before(leaf.NotBefore) {
+		return nil, fmt.Errorf("certificate %s is valid only from %v until %v", certFile, leaf.NotBefore, leaf.NotAfter)
+	}
+	files := &tlsFiles{
+		conf:    &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+		leaf:    leaf,
+		certSum: sha256.Sum256(append(certPEM, keyPEM...)),
+	}
+	if caFile != "" {
+		caPEM, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		certPool, err := newCertPool(caPEM)
+		if err != nil {
+			return nil, err
+		}
+		files.conf.ClientCAs = certPool
+		files.conf.ClientAuth = tls.RequireAndVerifyClientCert
+		files.caSum = sha256.Sum256(caPEM)
+	}
+	return files, nil
+}
+
+// LoadCertPool loads all the PEM encoded certificates present
+// in the given file into a new certificate pool.
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	caPEM, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	return newCertPool(caPEM)
+}
+
+func newCertPool(caPEM []byte) (*x509.CertPool, error) {
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("unable to load any certificates from the given CA file")
+	}
+	return certPool, nil
+}
+
+// ReloadableTLS serves TLS using the given certificate, private key and
+// CA files, as in NewServerTLSConfig, while letting these files be
+// reloaded through a Reloader so that they can be rotated without
+// restarting the node. Connections accepted after a reload are served
+// as per the reloaded files, while the existing ones are left as is.
+type ReloadableTLS struct {
+	certFile, keyFile, caFile string
+	files                     atomic.Value // *tlsFiles
+}
+
+// NewReloadableTLS loads the given files, failing as NewServerTLSConfig
+// does if they are invalid.
+func NewReloadableTLS(certFile, keyFile, caFile string) (*ReloadableTLS, error) {
+	files, err := loadTLSFiles(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	rt := &ReloadableTLS{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	rt.files.Store(files)
+	return rt, nil
+}
+
+func (rt *ReloadableTLS) load() (*tlsFiles, error) {
+	return loadTLSFiles(rt.certFile, rt.keyFile, rt.caFile)
+}
+
+func (rt *ReloadableTLS) current() *tlsFiles {
+	return rt.files.Load().(*tlsFiles)
+}
+
+// Config returns the TLS configuration that serves every connection as
+// per the files loaded at the time it is accepted, negotiating the given
+// application protocols, if any.
+func (rt *ReloadableTLS) Config(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			conf := rt.current().conf.Clone()
+			conf.NextProtos = nextProtos
+			return conf, nil
+		},
+	}
+}
+
+// Credentials returns the GRPC transport credentials that serve every
+// connection as per the files loaded at the time it is accepted.
+func (rt *ReloadableTLS) Credentials() credentials.TransportCredentials {
+	return credentials.NewTLS(rt.Config("h2"))
+}
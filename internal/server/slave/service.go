@@ -1,139 +1,1564 @@
 package slave
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
-	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/flipkart-incubator/dkv/internal/compression"
 	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/metrics"
+	"github.com/flipkart-incubator/dkv/internal/server/config"
+	"github.com/flipkart-incubator/dkv/internal/server/health"
 	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/internal/tracing"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
-// A DKVService represents a service for serving key value data.
+// A DKVService represents a service for serving key value data
+// along with the status of its replication from the master node.
+// It also reports its serving status over the GRPC health service.
 type DKVService interface {
 	io.Closer
+	// Shutdown gracefully shuts down the service once the requests
+	// being served are drained, like through grpc.Server.GracefulStop,
+	// so that none of the changes applied are lost.
+	Shutdown(ctx context.Context) error
 	serverpb.DKVServer
+	serverpb.DKVReplicationStatusServer
+	serverpb.DKVFailoverServer
+	serverpb.DKVReplicationControlServer
+	grpc_health_v1.HealthServer
+	// VerifyRange computes the checksum of a range of the changes applied
+	// by the slave, as served by the masters through DKVReplication.
+	VerifyRange(ctx context.Context, req *serverpb.VerifyRangeRequest) (*serverpb.VerifyRangeResponse, error)
+	// KeyspaceDigest computes the digests of ranges of the local keyspace,
+	// as served by the masters through DKVReplication.
+	KeyspaceDigest(ctx context.Context, req *serverpb.KeyspaceDigestRequest) (*serverpb.KeyspaceDigestResponse, error)
+	// GetChanges retrieves the changes applied by the slave, so that other
+	// slaves can replicate from it, as served by the masters through
+	// DKVReplication.
+	GetChanges(ctx context.Context, req *serverpb.GetChangesRequest) (*serverpb.GetChangesResponse, error)
+	// Role reports the slave as such until it is promoted to a master.
+	Role() serverpb.ServerRole
+}
+
+// ReplicationServer adapts the given slave DKVService into a server of
+// the DKVReplication service, through which other slaves can replicate
+// from it. It serves only GetChanges, VerifyRange and KeyspaceDigest, so
+// that slaves replicating from it poll for changes and can not bootstrap
+// themselves from its checkpoints.
+func ReplicationServer(svc DKVService) serverpb.DKVReplicationServer {
+	return &replicationServer{svc: svc}
+}
+
+type replicationServer struct {
+	serverpb.UnimplementedDKVReplicationServer
+	svc DKVService
+}
+
+func (rs *replicationServer) GetChanges(ctx context.Context, req *serverpb.GetChangesRequest) (*serverpb.GetChangesResponse, error) {
+	return rs.svc.GetChanges(ctx, req)
+}
+
+func (rs *replicationServer) VerifyRange(ctx context.Context, req *serverpb.VerifyRangeRequest) (*serverpb.VerifyRangeResponse, error) {
+	return rs.svc.VerifyRange(ctx, req)
+}
+
+func (rs *replicationServer) KeyspaceDigest(ctx context.Context, req *serverpb.KeyspaceDigestRequest) (*serverpb.KeyspaceDigestResponse, error) {
+	return rs.svc.KeyspaceDigest(ctx, req)
 }
 
 type dkvSlaveService struct {
+	grpc_health_v1.HealthServer
 	store       storage.KVStore
 	ca          storage.ChangeApplier
-	replCli     *ctl.DKVClient
+	chngLog     storage.ChangePropagator
+	replClis    []*ctl.DKVClient
 	replTckr    *time.Ticker
 	replStop    chan struct{}
-	replLag     uint64
-	fromChngNum uint64
+	replCtx     context.Context
+	replCancel  context.CancelFunc
+	replWg      sync.WaitGroup
+	stopOnce    sync.Once
+	closeOnce   sync.Once
 	maxNumChngs uint32
+	maxNumBytes uint64
+	keyPrefix   []byte
+	sizeLimits  storage.SizeLimits
+	keyPolicy   storage.KeyPolicies
+	codec       compression.Codec
+	tombRetain  time.Duration
+	startTime   time.Time
+	lgr         *zap.Logger
+
+	// Namespace to which the replication is restricted, along with the
+	// prefix of the keys replicated as stored, which spans the namespace
+	// and the key prefix within it
+	namespace    string
+	storedPrefix []byte
+	// Engine of the local storage as per WithConfig, where rocksdb
+	// can not apply the changes without their serialised form
+	engine string
+
+	// Thresholds beyond which the service is reported unhealthy
+	maxHealthyLag   uint64
+	maxReplFailTime time.Duration
+	// Apply latency beyond which the replication is throttled
+	maxApplyLatency time.Duration
+
+	// Used only by the replication poller
+	replPollInterval time.Duration
+	replConsecFails  uint
+	nextPollTime     time.Time
+	streamChngs      bool
+	replCli          *ctl.DKVClient
+	replCliIdx       int
+	lastFailoverTime time.Time
+	// Address of the leader hinted by a master that is not the leader
+	hintedMasterAddr string
+	// Chunks of the change too large for a response, assembled so far
+	chunks storage.ChangeAssembler
+
+	// Held while a batch of changes is being applied, so
+	// that a pause takes effect only at a batch boundary
+	applyMu sync.Mutex
+
+	// Guards the replication status that is updated on every poll
+	replStatMu    sync.RWMutex
+	replLag       uint64
+	fromChngNum   uint64
+	masterChngNum uint64
+	lastPollTime  time.Time
+	throttle      *applyThrottle
+	replErrs      uint64
+	replHalted    bool
+	masterAddr    string
+	// Set while the local storage is being rebuilt from a checkpoint
+	bootstrapping bool
+	// Set once a promotion begins, after which no bootstrap can begin
+	promoting  bool
+	replPaused bool
+	// Identifies the latest pause, so that a stale auto
+	// resume does not resume any of the subsequent pauses
+	pauseSeq uint64
+	pauseTmr *time.Timer
+
+	// Shall be manipulated using atomics
+	promoted uint32
+	closed   uint32
+}
+
+// Upper bound for the backoff between polls when polling
+// for changes from master fails consecutively.
+const maxReplPollBackoff = time.Minute
+
+// MinReplPollInterval is the shortest interval at which a slave
+// DKVService can poll for changes from master node, guarding the
+// master against a flood of polls.
+const MinReplPollInterval = config.MinReplPollInterval
+
+// Maximum duration for which a stream of changes from master can
+// be silent before it is considered broken. Master is expected to
+// send heartbeats well within this duration.
+const maxChangeStreamIdleTime = 10 * time.Second
+
+// Interval at which master sends heartbeats over the streams of
+// changes, and the number of these intervals, or of the intervals
+// between polls if longer, without a successful poll beyond which
+// the lag of the latest poll can no longer bound the reads.
+const (
+	changeStreamHeartbeatInterval = time.Second
+	maxMissedPolls                = 3
+)
+
+// Default duration for which replication can fail before
+// the slave is reported unhealthy.
+const defaultMaxReplFailTime = time.Minute
+
+// Minimum duration between consecutive failovers onto
+// another candidate master, so as to limit the churn.
+const minMasterFailoverInterval = time.Second
+
+// Number of keys removed at once while clearing the local
+// storage during a bootstrap.
+const bootstrapDeleteBatchSize = 1000
+
+var (
+	errMasterNotLeader     = errors.New("master node is not the leader of its cluster")
+	errMasterDiverged      = errors.New("change number of the master node can not be lesser than the change number of the slave node")
+	errChangesUnavailable  = errors.New("required changes are no longer available on the master node")
+	errReplicationPaused   = errors.New("replication from the master node is paused")
+	errBootstrapIncomplete = errors.New("slave node can not be promoted while its bootstrap from the master node is incomplete")
+	errPromotionInProgress = errors.New("slave node is being promoted to master")
+	errBatchResized        = errors.New("size of the batches of changes is resized as per the apply latency")
+)
+
+// NewService creates a slave DKVService that streams changes from
+// master node and replicates them onto its local storage. Whenever
+// streaming is not possible, it falls back to periodically polling
+// for these changes. As a result, it forbids changes to this local
+// storage through any of the other key value mutators, until it
+// is promoted to a master using PromoteToMaster.
+//
+// Changes are retrieved in batches of atmost `maxNumChngs` changes,
+// whose total size is further limited by `maxNumBytes` if positive.
+// When the slave is lagging behind, batches are retrieved one after
+// the other without waiting for the poll interval until it catches up.
+//
+// Replication begins from the first of the given candidate masters and
+// fails over onto the next one whenever the current master turns out to
+// be unreachable or not the leader of its cluster. Such failovers happen
+// atmost once every second.
+//
+// The poll interval can be as short as MinReplPollInterval, for slaves
+// serving latency sensitive reads that can not afford to lag behind the
+// master by a second whenever streaming is not possible.
+func NewService(store storage.KVStore, ca storage.ChangeApplier, replClis []*ctl.DKVClient, replPollInterval time.Duration, maxNumChngs uint32, maxNumBytes uint64, opts ...DKVServiceOption) (DKVService, error) {
+	if maxNumChngs == 0 || len(replClis) == 0 || store == nil || ca == nil {
+		return nil, errors.New("invalid args - params `store`, `ca`, `replClis`, `replPollInterval` and `maxNumChngs` are all mandatory")
+	}
+	if replPollInterval < MinReplPollInterval {
+		return nil, fmt.Errorf("invalid args - param `replPollInterval` must be atleast %v", MinReplPollInterval)
+	}
+	for _, replCli := range replClis {
+		if replCli == nil {
+			return nil, errors.New("invalid args - param `replClis` can not have nil clients")
+		}
+	}
+	dss := configureSlaveService(store, ca, replClis, maxNumChngs, maxNumBytes, opts...)
+	// Changes of some of the keys alone carry no serialised form
+	partial := len(dss.storedPrefix) > 0
+	for _, replCli := range replClis {
+		partial = partial || replCli.Namespace() != ""
+	}
+	if partial && dss.engine == "rocksdb" {
+		return nil, errors.New("invalid args - rocksdb engine can not replicate a namespace or the keys with a prefix alone")
+	}
+	dss.startReplication(replPollInterval)
+	return dss, nil
+}
+
+// NewServiceWithPollIntervalSecs is same as NewService, but with
+// the poll interval given in seconds.
+//
+// Deprecated: Use NewService instead, which allows for sub-second
+// poll intervals.
+func NewServiceWithPollIntervalSecs(store storage.KVStore, ca storage.ChangeApplier, replClis []*ctl.DKVClient, replPollIntervalSecs uint, maxNumChngs uint32, maxNumBytes uint64, opts ...DKVServiceOption) (DKVService, error) {
+	return NewService(store, ca, replClis, time.Duration(replPollIntervalSecs)*time.Second, maxNumChngs, maxNumBytes, opts...)
+}
+
+// A DKVServiceOption is used to customize a specific aspect
+// of the slave DKVService.
+type DKVServiceOption func(*dkvSlaveService)
+
+// WithSizeLimits sets the maximum sizes of the keys and values that
+// the slave DKVService accepts. This guards against a misconfigured
+// master node, since its changes exceeding these limits are not applied
+// and replication makes no further progress until they are rectified.
+// Once promoted, mutations exceeding them are rejected as well.
+func WithSizeLimits(sizeLimits storage.SizeLimits) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		dss.sizeLimits = sizeLimits
+	}
+}
+
+// WithKeyPolicies sets the policies that the keys mutated through the
+// slave DKVService must satisfy once it is promoted, as per the master
+// DKVService. The changes replicated from the master node are applied
+// without checking them, since the master node already did.
+func WithKeyPolicies(keyPolicies storage.KeyPolicies) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		dss.keyPolicy = keyPolicies
+	}
+}
+
+// WithCompression decompresses the values compressed at rest using
+// the given codec before they are served, which must match the codec
+// of the master node since its changes are replicated verbatim. Once
+// promoted, the values written are compressed using this codec too.
+func WithCompression(codec compression.Codec) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		dss.codec = codec
+	}
+}
+
+// WithTombstoneRetention makes the slave DKVService delete keys softly
+// once it is promoted, retaining them under tombstones for the given
+// retention window as per the master DKVService. Tombstones replicated
+// from the master node can be restored through Undelete once promoted.
+func WithTombstoneRetention(retention time.Duration) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		dss.tombRetain = retention
+	}
+}
+
+// WithKeyPrefix restricts the replication to the keys with the given
+// prefix, as stored on the master node unless given WithNamespace, which
+// then skips the changes on all the other keys. Such changes carry no
+// serialised form, hence the local storage must not use the rocksdb
+// engine.
+func WithKeyPrefix(keyPrefix []byte) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		dss.keyPrefix = keyPrefix
+	}
 }
 
-// TODO: check if this needs to be exposed as a flag
-const maxNumChangesRepl = 100
+// WithNamespace restricts the replication to the keys of the given
+// namespace, within which the prefix given WithKeyPrefix applies. As
+// with WithKeyPrefix, the local storage must not use the rocksdb engine.
+func WithNamespace(namespace string) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		dss.namespace = namespace
+	}
+}
+
+// WithChangeLog sets the change log of the local storage, through which
+// VerifyRange computes the checksums of the changes applied and GetChanges
+// serves them to the other slaves. Both fail unless this is set.
+func WithChangeLog(chngLog storage.ChangePropagator) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		dss.chngLog = chngLog
+	}
+}
 
-// NewService creates a slave DKVService that periodically polls
-// for changes from master node and replicates them onto its local
-// storage. As a result, it forbids changes to this local storage
-// through any of the other key value mutators.
-func NewService(store storage.KVStore, ca storage.ChangeApplier, replCli *ctl.DKVClient, replPollIntervalSecs uint) (DKVService, error) {
-	if replPollIntervalSecs == 0 || replCli == nil || store == nil || ca == nil {
-		return nil, errors.New("invalid args - params `store`, `ca`, `replCli` and `replPollIntervalSecs` are all mandatory")
+// WithHealthThresholds sets the bounds beyond which the slave DKVService
+// reports itself as not serving over the GRPC health service, which are
+// the replication lag in terms of changes and the duration for which
+// replication has been failing. The latter must exceed the poll interval,
+// while zero disables the respective bound. By default, the service is
+// reported unhealthy once replication fails for over a minute.
+func WithHealthThresholds(maxReplLag uint64, maxReplFailTime time.Duration) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		dss.maxHealthyLag, dss.maxReplFailTime = maxReplLag, maxReplFailTime
 	}
-	replPollInterval := time.Duration(replPollIntervalSecs) * time.Second
-	return newSlaveService(store, ca, replCli, replPollInterval), nil
 }
 
-func newSlaveService(store storage.KVStore, ca storage.ChangeApplier, replCli *ctl.DKVClient, pollInterval time.Duration) *dkvSlaveService {
-	dss := &dkvSlaveService{store: store, ca: ca, replCli: replCli}
+// WithApplyLatencyThreshold throttles the replication whenever the
+// moving average of the time taken for applying a batch of changes onto
+// the local storage exceeds the given threshold, by shrinking the batches
+// and lengthening the interval between them until it recovers. Zero, the
+// default, disables throttling, while the average is reported regardless.
+func WithApplyLatencyThreshold(threshold time.Duration) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		dss.maxApplyLatency = threshold
+	}
+}
+
+// WithConfig applies the storage settings of the given config, along
+// with its limits on the sizes of the keys and values and the health,
+// throttling and key restrictions of its replication, as per the
+// respective options. Options given after it override these settings.
+// The poll interval and batch limits of the replication are as given
+// to NewService, while the rest of the config, like the addresses of
+// the masters, is left to the caller.
+func WithConfig(cfg *config.Config) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		repl := &cfg.Replication
+		dss.engine = cfg.Storage.Engine
+		dss.sizeLimits = cfg.Limits.SizeLimits()
+		dss.keyPolicy = cfg.Storage.KeyPolicies()
+		dss.codec = cfg.Storage.Codec()
+		dss.tombRetain = cfg.Storage.TombstoneRetention
+		dss.maxHealthyLag, dss.maxReplFailTime = repl.HealthMaxLag, repl.HealthMaxFailTime
+		dss.maxApplyLatency = repl.MaxApplyLatency
+		if repl.KeyPrefix != "" {
+			dss.keyPrefix = []byte(repl.KeyPrefix)
+		}
+		dss.namespace = repl.Namespace
+	}
+}
+
+// WithLogger sets the logger used by the slave DKVService for
+// logging the replication events. By default nothing is logged.
+func WithLogger(lgr *zap.Logger) DKVServiceOption {
+	return func(dss *dkvSlaveService) {
+		dss.lgr = lgr
+	}
+}
+
+func newSlaveService(store storage.KVStore, ca storage.ChangeApplier, replClis []*ctl.DKVClient, pollInterval time.Duration, maxNumChngs uint32, maxNumBytes uint64, opts ...DKVServiceOption) *dkvSlaveService {
+	dss := configureSlaveService(store, ca, replClis, maxNumChngs, maxNumBytes, opts...)
 	dss.startReplication(pollInterval)
 	return dss
 }
 
+// configureSlaveService creates the slave DKVService as per the given
+// options, without starting its replication.
+func configureSlaveService(store storage.KVStore, ca storage.ChangeApplier, replClis []*ctl.DKVClient, maxNumChngs uint32, maxNumBytes uint64, opts ...DKVServiceOption) *dkvSlaveService {
+	dss := &dkvSlaveService{store: store, ca: ca, replClis: replClis, maxNumChngs: maxNumChngs, maxNumBytes: maxNumBytes, maxReplFailTime: defaultMaxReplFailTime, lgr: zap.NewNop()}
+	for _, opt := range opts {
+		opt(dss)
+	}
+	dss.storedPrefix = dss.keyPrefix
+	if dss.namespace != "" {
+		dss.storedPrefix, _ = storage.NamespacedKey(dss.namespace, dss.keyPrefix)
+		nsClis := make([]*ctl.DKVClient, len(replClis))
+		for i, replCli := range replClis {
+			nsClis[i] = replCli.ForNamespace(dss.namespace)
+		}
+		dss.replClis = nsClis
+	}
+	dss.HealthServer = health.NewServer(dss.servingStatus)
+	dss.startTime = time.Now()
+	return dss
+}
+
 func (dss *dkvSlaveService) Put(ctx context.Context, putReq *serverpb.PutRequest) (*serverpb.PutResponse, error) {
-	return nil, errors.New("DKV slave service does not support keyspace mutations")
+	if !dss.isPromoted() {
+		return &serverpb.PutResponse{Status: newErrorStatus(dkverrors.ErrReadOnlyReplica)}, nil
+	}
+	if err := dss.sizeLimits.Check(putReq.Key, putReq.Value); err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err := dss.keyPolicy.Check(putReq.Namespace, putReq.Key); err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	nsPuts, err := storage.NamespacedPuts(putReq)
+	if err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	// MultiPut also stores the expiry time of the given entry
+	if err = dss.store.MultiPut(dss.compressPuts(nsPuts...)...); err != nil {
+		return &serverpb.PutResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.PutResponse{Status: newEmptyStatus()}, nil
+}
+
+func (dss *dkvSlaveService) MultiPut(ctx context.Context, multiPutReq *serverpb.MultiPutRequest) (*serverpb.MultiPutResponse, error) {
+	if !dss.isPromoted() {
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(dkverrors.ErrReadOnlyReplica)}, nil
+	}
+	for i, putReq := range multiPutReq.PutRequests {
+		if err := dss.sizeLimits.Check(putReq.Key, putReq.Value); err != nil {
+			return &serverpb.MultiPutResponse{Status: newErrorStatus(fmt.Errorf("MultiPut entry at index %d: %w", i, err))}, nil
+		}
+		if err := dss.keyPolicy.Check(putReq.Namespace, putReq.Key); err != nil {
+			return &serverpb.MultiPutResponse{Status: newErrorStatus(fmt.Errorf("MultiPut entry at index %d: %w", i, err))}, nil
+		}
+	}
+	nsPuts, err := storage.NamespacedPuts(multiPutReq.PutRequests...)
+	if err != nil {
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err = dss.store.MultiPut(dss.compressPuts(nsPuts...)...); err != nil {
+		return &serverpb.MultiPutResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.MultiPutResponse{Status: newEmptyStatus()}, nil
+}
+
+func (dss *dkvSlaveService) CompareAndSet(ctx context.Context, casReq *serverpb.CompareAndSetRequest) (*serverpb.CompareAndSetResponse, error) {
+	if !dss.isPromoted() {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(dkverrors.ErrReadOnlyReplica)}, nil
+	}
+	if err := dss.sizeLimits.Check(casReq.Key, casReq.NewValue); err != nil {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err := dss.keyPolicy.Check(casReq.Namespace, casReq.Key); err != nil {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(casReq.Namespace, casReq.Key)
+	if err != nil {
+		return &serverpb.CompareAndSetResponse{Status: newErrorStatus(err)}, nil
+	}
+	updated, err := dss.store.CompareAndSet(key, casReq.ExpectedValue, compression.Compress(dss.codec, casReq.NewValue))
+	res := &serverpb.CompareAndSetResponse{Status: newEmptyStatus(), Updated: updated}
+	if err != nil {
+		res.Status = newErrorStatus(err)
+	}
+	return res, nil
+}
+
+func (dss *dkvSlaveService) Txn(ctx context.Context, txnReq *serverpb.TxnRequest) (*serverpb.TxnResponse, error) {
+	if !dss.isPromoted() {
+		return &serverpb.TxnResponse{Status: newErrorStatus(dkverrors.ErrReadOnlyReplica)}, nil
+	}
+	if err := storage.ValidateTxn(txnReq, dss.sizeLimits); err != nil {
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	if err := dss.keyPolicy.CheckTxn(txnReq); err != nil {
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	nsTxnReq, err := storage.NamespacedTxn(txnReq, func(value []byte) []byte { return compression.Compress(dss.codec, value) })
+	if err != nil {
+		return &serverpb.TxnResponse{Status: newErrorStatus(err)}, nil
+	}
+	succeeded, err := dss.store.Txn(nsTxnReq)
+	res := &serverpb.TxnResponse{Status: newEmptyStatus(), Succeeded: succeeded}
+	if err != nil {
+		res.Status = newErrorStatus(err)
+	}
+	return res, nil
+}
+
+func (dss *dkvSlaveService) Increment(ctx context.Context, incReq *serverpb.IncrementRequest) (*serverpb.IncrementResponse, error) {
+	if !dss.isPromoted() {
+		return &serverpb.IncrementResponse{Status: newErrorStatus(dkverrors.ErrReadOnlyReplica)}, nil
+	}
+	if err := dss.keyPolicy.Check(incReq.Namespace, incReq.Key); err != nil {
+		return &serverpb.IncrementResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(incReq.Namespace, incReq.Key)
+	if err != nil {
+		return &serverpb.IncrementResponse{Status: newErrorStatus(err)}, nil
+	}
+	value, err := dss.store.Increment(key, incReq.Delta)
+	res := &serverpb.IncrementResponse{Status: newEmptyStatus(), Value: value}
+	if err != nil {
+		res.Status = newErrorStatus(err)
+	}
+	return res, nil
+}
+
+func (dss *dkvSlaveService) Delete(ctx context.Context, delReq *serverpb.DeleteRequest) (*serverpb.DeleteResponse, error) {
+	if !dss.isPromoted() {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(dkverrors.ErrReadOnlyReplica)}, nil
+	}
+	if err := dss.keyPolicy.Check(delReq.Namespace, delReq.Key); err != nil {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(delReq.Namespace, delReq.Key)
+	if err != nil {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	if dss.tombRetain > 0 {
+		deleted, err := storage.SoftDelete(dss.store, key, dss.tombRetain, dss.store.Txn)
+		if err != nil {
+			return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+		}
+		if deleted {
+			return &serverpb.DeleteResponse{Status: newEmptyStatus()}, nil
+		}
+	}
+	if err = dss.store.Delete(key); err != nil {
+		return &serverpb.DeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.DeleteResponse{Status: newEmptyStatus()}, nil
+}
+
+func (dss *dkvSlaveService) Undelete(ctx context.Context, undelReq *serverpb.UndeleteRequest) (*serverpb.UndeleteResponse, error) {
+	if !dss.isPromoted() {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(dkverrors.ErrReadOnlyReplica)}, nil
+	}
+	if err := dss.keyPolicy.Check(undelReq.Namespace, undelReq.Key); err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	key, err := storage.NamespacedKey(undelReq.Namespace, undelReq.Key)
+	if err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	if _, err = storage.Undelete(dss.store, key, dss.store.Txn); err != nil {
+		return &serverpb.UndeleteResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.UndeleteResponse{Status: newEmptyStatus()}, nil
 }
 
 func (dss *dkvSlaveService) Get(ctx context.Context, getReq *serverpb.GetRequest) (*serverpb.GetResponse, error) {
-	readResults, err := dss.store.Get(getReq.Key)
+	// Changes committed by the master node may yet to be replicated,
+	// unless promoted upon which this node takes all the writes
+	if getReq.ReadConsistency == serverpb.ReadConsistency_Linearizable && !dss.isPromoted() {
+		return &serverpb.GetResponse{Status: newErrorStatus(dkverrors.ErrLinearizableReadUnsupported)}, nil
+	}
+	if status := dss.staleReadStatus(getReq.MaxLag, getReq.LagBounded); status != nil {
+		return &serverpb.GetResponse{Status: status}, nil
+	}
+	key, err := storage.NamespacedKey(getReq.Namespace, getReq.Key)
+	if err != nil {
+		return &serverpb.GetResponse{Status: newErrorStatus(err)}, nil
+	}
+	_, span := tracing.StartStorageSpan(ctx, "Get", 1)
+	readResults, found, err := dss.store.Get(key)
+	tracing.EndSpan(span, err)
+	if err == nil {
+		readResults[0], err = dss.decompress(readResults[0])
+	}
 	res := &serverpb.GetResponse{Status: newEmptyStatus()}
 	if err != nil {
 		res.Status = newErrorStatus(err)
 	} else {
-		res.Value = readResults[0]
+		res.Value, res.Found = readResults[0], found[0]
 	}
-	return res, err
+	return res, nil
 }
 
 func (dss *dkvSlaveService) MultiGet(ctx context.Context, multiGetReq *serverpb.MultiGetRequest) (*serverpb.MultiGetResponse, error) {
-	readResults, err := dss.store.Get(multiGetReq.Keys...)
+	if status := dss.staleReadStatus(multiGetReq.MaxLag, multiGetReq.LagBounded); status != nil {
+		return &serverpb.MultiGetResponse{Status: status}, nil
+	}
+	keys, err := storage.NamespacedKeys(multiGetReq.Namespace, multiGetReq.Keys...)
+	if err != nil {
+		return &serverpb.MultiGetResponse{Status: newErrorStatus(err)}, nil
+	}
+	// Keys of detailed reads are read even when others fail to be read
+	var readResults [][]byte
+	var found []bool
+	var errs []error
+	spanCtx, span := tracing.StartStorageSpan(ctx, "MultiGet", len(keys))
+	if multiGetReq.Detailed {
+		readResults, found, errs, err = storage.GetEach(spanCtx, dss.store, keys...)
+	} else {
+		readResults, found, err = storage.GetWithContext(spanCtx, dss.store, keys...)
+	}
+	tracing.EndSpan(span, err)
+	if ctxErr := dkverrors.GRPCContextError(err); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err == nil && !multiGetReq.Detailed {
+		for i := 0; i < len(readResults) && err == nil; i++ {
+			readResults[i], err = dss.decompress(readResults[i])
+		}
+	}
 	res := &serverpb.MultiGetResponse{Status: newEmptyStatus()}
+	switch {
+	case err != nil:
+		res.Status = newErrorStatus(err)
+	case multiGetReq.Detailed:
+		res.Results = newKVResults(readResults, found, errs, dss.decompress)
+	default:
+		res.Values, res.Found = readResults, found
+	}
+	return res, nil
+}
+
+// staleReadStatus returns a status with the StaleRead code if reads
+// bounded by the given lag cannot be served, owing to the replication
+// lag as of the latest poll exceeding it. Reads are bounded by a lag
+// if it is positive or if lagBounded is set. Slaves that are yet to
+// poll successfully, that halted or paused replication, or that missed
+// several polls since the latest successful one never serve bounded
+// reads, as their lag is unknown.
+// Returns nil if the read can be served, including for unbounded reads
+// and for all the reads once promoted.
+func (dss *dkvSlaveService) staleReadStatus(maxLag uint64, lagBounded bool) *serverpb.Status {
+	if (maxLag == 0 && !lagBounded) || dss.isPromoted() {
+		return nil
+	}
+	dss.replStatMu.RLock()
+	defer dss.replStatMu.RUnlock()
+	pollInterval := dss.replPollInterval + dss.throttle.delay
+	if pollInterval < changeStreamHeartbeatInterval {
+		pollInterval = changeStreamHeartbeatInterval
+	}
+	var msg string
+	switch {
+	case dss.replHalted:
+		msg = "replication from master is halted"
+	case dss.replPaused:
+		msg = "replication from master is paused"
+	case dss.lastPollTime.IsZero():
+		msg = "replication from master is yet to begin"
+	case time.Since(dss.lastPollTime) > maxMissedPolls*pollInterval:
+		msg = fmt.Sprintf("replication lag is unknown since the latest poll from master %v ago", time.Since(dss.lastPollTime).Truncate(time.Millisecond))
+	case dss.replLag > maxLag:
+		msg = fmt.Sprintf("replication lag of %d changes exceeds the permissible lag of %d changes", dss.replLag, maxLag)
+	default:
+		return nil
+	}
+	return &serverpb.Status{Code: int32(serverpb.StatusCode_StaleRead), Message: msg}
+}
+
+func (dss *dkvSlaveService) Exists(ctx context.Context, existsReq *serverpb.ExistsRequest) (*serverpb.ExistsResponse, error) {
+	keys, err := storage.NamespacedKeys(existsReq.Namespace, existsReq.Keys...)
+	if err != nil {
+		return &serverpb.ExistsResponse{Status: newErrorStatus(err)}, nil
+	}
+	_, span := tracing.StartStorageSpan(ctx, "Exists", len(keys))
+	results, err := dss.store.Exists(keys...)
+	tracing.EndSpan(span, err)
+	res := &serverpb.ExistsResponse{Status: newEmptyStatus()}
 	if err != nil {
 		res.Status = newErrorStatus(err)
 	} else {
-		res.Values = readResults
+		res.Exists = results
 	}
-	return res, err
+	return res, nil
 }
 
+func (dss *dkvSlaveService) Iterate(iterReq *serverpb.IterateRequest, dkvIterSrvr serverpb.DKV_IterateServer) error {
+	iteration, err := storage.IterateNamespace(dss.store, iterReq.Namespace, iterReq.KeyPrefix, iterReq.StartKey, iterReq.KeysOnly)
+	if err != nil {
+		dkvIterSrvr.Send(&serverpb.IterateResponse{Status: newErrorStatus(err)})
+		return err
+	}
+	// Iteration stops as soon as the client gives up
+	iteration = storage.NewContextIterator(dkvIterSrvr.Context(), iteration)
+	defer iteration.Close()
+	for iteration.HasNext() {
+		key, val := iteration.Next()
+		itRes := &serverpb.IterateResponse{Status: newEmptyStatus(), Key: key}
+		if val, err := dss.decompress(val); err != nil {
+			itRes.Status = newErrorStatus(err)
+		} else {
+			itRes.Value, itRes.Truncated = storage.TruncateValue(val, iterReq.MaxValueSize)
+		}
+		if err := dkvIterSrvr.Send(itRes); err != nil {
+			return err
+		}
+	}
+	if err := iteration.Err(); err != nil {
+		if ctxErr := dkverrors.GRPCContextError(err); ctxErr != nil {
+			return ctxErr
+		}
+		dkvIterSrvr.Send(&serverpb.IterateResponse{Status: newErrorStatus(err)})
+		return err
+	}
+	return nil
+}
+
+func (dss *dkvSlaveService) GetStatus(ctx context.Context, req *serverpb.GetStatusRequest) (*serverpb.GetStatusResponse, error) {
+	persistedState, err := dss.loadPersistedState()
+	if err != nil {
+		dss.lgr.Warn("Unable to load the persisted replication state", zap.Error(err))
+	}
+	dss.replStatMu.RLock()
+	defer dss.replStatMu.RUnlock()
+	res := &serverpb.GetStatusResponse{
+		Status:                      newEmptyStatus(),
+		AppliedChangeNumber:         dss.fromChngNum - 1,
+		MasterChangeNumber:          dss.masterChngNum,
+		ReplicationLag:              dss.replLag,
+		NumErrors:                   dss.replErrs,
+		Healthy:                     !dss.replHalted,
+		Paused:                      dss.replPaused,
+		MasterAddr:                  dss.masterAddr,
+		ApplyLatencyMicros:          uint64(dss.throttle.avgLatency / time.Microsecond),
+		EffectiveBatchSize:          dss.throttle.batchSize,
+		EffectivePollIntervalMillis: int64((dss.replPollInterval + dss.throttle.delay) / time.Millisecond),
+		ReplicaID:                   dss.replCli.ReplicaID(),
+		PersistedState:              persistedState,
+	}
+	if !dss.lastPollTime.IsZero() {
+		res.LastPollTimeMillis = dss.lastPollTime.UnixNano() / int64(time.Millisecond)
+	}
+	return res, nil
+}
+
+var (
+	errChangeLogMisaligned  = errors.New("changes applied by the slave node are not retained under the change numbers of the master node")
+	errChangeLogUnavailable = errors.New("local storage of the slave node does not retain its changes")
+	errPartialReplica       = errors.New("slave node replicating only a namespace or the keys with a prefix can not serve its changes")
+	errBootstrapInProgress  = errors.New("slave node is bootstrapping from a checkpoint of the master node")
+)
+
+// GetChanges serves the changes applied by the slave from its change log,
+// so that slaves can replicate from other slaves instead of the masters.
+// Since the changes are served under the change numbers of the master
+// node, it fails for slaves that can not retain them under these numbers,
+// as in VerifyRange, and for those replicating only a key prefix. The
+// MasterChangeNumber served is the latest change number applied by the
+// slave, which can be behind that of the slaves replicating from it, as
+// indicated by ServedBySlave.
+func (dss *dkvSlaveService) GetChanges(ctx context.Context, req *serverpb.GetChangesRequest) (*serverpb.GetChangesResponse, error) {
+	if err := dss.checkServesChanges(); err != nil {
+		return &serverpb.GetChangesResponse{Status: newErrorStatus(err)}, nil
+	}
+	res := storage.LoadChangesResponse(dss.chngLog, req)
+	res.ServedBySlave = !dss.isPromoted()
+	return res, nil
+}
+
+func (dss *dkvSlaveService) checkServesChanges() error {
+	switch {
+	case dss.chngLog == nil:
+		return errChangeLogUnavailable
+	case len(dss.storedPrefix) > 0:
+		return errPartialReplica
+	}
+	dss.replStatMu.RLock()
+	bootstrapping := dss.bootstrapping
+	dss.replStatMu.RUnlock()
+	if bootstrapping {
+		return errBootstrapInProgress
+	}
+	return dss.checkChangeLogAligned()
+}
+
+// VerifyRange computes the checksum of the given range of changes as
+// retained by the change log. Since this requires the change log to
+// retain the changes under the change numbers of the master node, it
+// fails for slaves that have skipped any of the changes, like when they
+// are bootstrapped from a checkpoint.
+func (dss *dkvSlaveService) VerifyRange(ctx context.Context, req *serverpb.VerifyRangeRequest) (*serverpb.VerifyRangeResponse, error) {
+	if dss.chngLog == nil {
+		return &serverpb.VerifyRangeResponse{Status: newErrorStatus(errChangeLogUnavailable)}, nil
+	}
+	if err := dss.checkChangeLogAligned(); err != nil {
+		return &serverpb.VerifyRangeResponse{Status: newErrorStatus(err)}, nil
+	}
+	checksum, numTrxns, err := storage.RangeChecksum(dss.chngLog, req.FromChangeNumber, req.ToChangeNumber)
+	if err != nil {
+		return &serverpb.VerifyRangeResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.VerifyRangeResponse{Status: newEmptyStatus(), Checksum: checksum, NumberOfTrxns: numTrxns}, nil
+}
+
+// KeyspaceDigest computes the digests of the requested ranges of keys,
+// for comparing the local keyspace with that of the master node. Since
+// the keyspace keeps changing with replication, the digests reflect the
+// changes applied so far.
+func (dss *dkvSlaveService) KeyspaceDigest(ctx context.Context, req *serverpb.KeyspaceDigestRequest) (*serverpb.KeyspaceDigestResponse, error) {
+	ranges, keys, err := storage.KeyspaceDigest(ctx, dss.store, req)
+	if ctxErr := dkverrors.GRPCContextError(err); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return &serverpb.KeyspaceDigestResponse{Status: newErrorStatus(err)}, nil
+	}
+	return &serverpb.KeyspaceDigestResponse{Status: newEmptyStatus(), Ranges: ranges, Keys: keys}, nil
+}
+
+func (dss *dkvSlaveService) checkChangeLogAligned() error {
+	// Change numbers move together only while no changes are being applied
+	dss.applyMu.Lock()
+	defer dss.applyMu.Unlock()
+	appldChngNum, err := dss.ca.GetLatestAppliedChangeNumber()
+	if err != nil {
+		return err
+	}
+	chngNum, err := dss.chngLog.GetLatestCommittedChangeNumber()
+	if err != nil {
+		return err
+	}
+	if appldChngNum != chngNum {
+		return errChangeLogMisaligned
+	}
+	return nil
+}
+
+// PauseReplication pauses the application of changes from master
+// until ResumeReplication is invoked or until the requested duration
+// elapses, whichever happens first. Since it waits for any batch of
+// changes being applied, no changes are applied once it returns.
+// Pausing an already paused service restarts its auto resume timer.
+func (dss *dkvSlaveService) PauseReplication(ctx context.Context, req *serverpb.PauseReplicationRequest) (*serverpb.Status, error) {
+	dss.applyMu.Lock()
+	defer dss.applyMu.Unlock()
+	dss.replStatMu.Lock()
+	defer dss.replStatMu.Unlock()
+	dss.replPaused = true
+	dss.pauseSeq++
+	if dss.pauseTmr != nil {
+		dss.pauseTmr.Stop()
+		dss.pauseTmr = nil
+	}
+	if req.AutoResumeAfterSecs > 0 {
+		autoResumeAfter, pauseSeq := time.Duration(req.AutoResumeAfterSecs)*time.Second, dss.pauseSeq
+		dss.pauseTmr = time.AfterFunc(autoResumeAfter, func() {
+			if dss.resumeReplication(pauseSeq) {
+				dss.lgr.Info("Automatically resumed replication from master", zap.Duration("pausedFor", autoResumeAfter))
+			}
+		})
+		dss.lgr.Info("Paused replication from master", zap.Duration("autoResumeAfter", autoResumeAfter))
+	} else {
+		dss.lgr.Info("Paused replication from master")
+	}
+	return newEmptyStatus(), nil
+}
+
+// ResumeReplication resumes the application of changes from master
+// on the next poll. Resuming a service that is not paused has no effect.
+func (dss *dkvSlaveService) ResumeReplication(ctx context.Context, req *serverpb.ResumeReplicationRequest) (*serverpb.Status, error) {
+	if dss.resumeReplication(0) {
+		dss.lgr.Info("Resumed replication from master")
+	}
+	return newEmptyStatus(), nil
+}
+
+// resumeReplication resumes a paused replication, provided the given
+// sequence identifies the latest pause or is zero. Returns true only
+// if the replication is resumed.
+func (dss *dkvSlaveService) resumeReplication(pauseSeq uint64) bool {
+	dss.replStatMu.Lock()
+	defer dss.replStatMu.Unlock()
+	if !dss.replPaused || (pauseSeq != 0 && pauseSeq != dss.pauseSeq) {
+		return false
+	}
+	dss.replPaused = false
+	if dss.pauseTmr != nil {
+		dss.pauseTmr.Stop()
+		dss.pauseTmr = nil
+	}
+	return true
+}
+
+func (dss *dkvSlaveService) isReplicationPaused() bool {
+	dss.replStatMu.RLock()
+	defer dss.replStatMu.RUnlock()
+	return dss.replPaused
+}
+
+// PromoteToMaster stops the replication from master and switches
+// this service into a writable master backed by the same storage.
+// Since it waits for the replication to terminate, any batch of
+// changes being applied is wholly applied before the promotion.
+// Promotion is refused while a bootstrap is in progress, leaving the
+// replication running so that the bootstrap completes, as the local
+// storage is only partially rebuilt until then. Promoting an already
+// promoted service has no effect.
+func (dss *dkvSlaveService) PromoteToMaster(ctx context.Context, req *serverpb.PromoteToMasterRequest) (*serverpb.PromoteToMasterResponse, error) {
+	dss.replStatMu.Lock()
+	bootstrapping := dss.bootstrapping
+	if !bootstrapping {
+		dss.promoting = true
+	}
+	dss.replStatMu.Unlock()
+	if bootstrapping {
+		return &serverpb.PromoteToMasterResponse{Status: newErrorStatus(errBootstrapIncomplete)}, nil
+	}
+	// Bootstraps can no longer begin, hence the storage stays intact
+	dss.stopReplication()
+	dss.replStatMu.RLock()
+	appliedChngNum := dss.fromChngNum - 1
+	dss.replStatMu.RUnlock()
+	if atomic.CompareAndSwapUint32(&dss.promoted, 0, 1) {
+		dss.lgr.Info("Promoted slave to master", zap.Uint64("appliedChangeNum", appliedChngNum))
+	}
+	return &serverpb.PromoteToMasterResponse{Status: newEmptyStatus(), AppliedChangeNumber: appliedChngNum}, nil
+}
+
+func (dss *dkvSlaveService) isPromoted() bool {
+	return atomic.LoadUint32(&dss.promoted) == 1
+}
+
+func (dss *dkvSlaveService) Role() serverpb.ServerRole {
+	if dss.isPromoted() {
+		return serverpb.ServerRole_Master
+	}
+	return serverpb.ServerRole_Slave
+}
+
+// servingStatus reports the slave as not serving once it is closed,
+// or while its replication is halted, bootstrapping, lagging beyond
+// the permissible lag or failing for longer than permissible. Paused
+// replication is not considered failing, while a promoted slave is
+// reported as serving for as long as it is open.
+func (dss *dkvSlaveService) servingStatus() grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if atomic.LoadUint32(&dss.closed) == 1 {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	if dss.isPromoted() {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	dss.replStatMu.RLock()
+	defer dss.replStatMu.RUnlock()
+	lastReplTime := dss.lastPollTime
+	if lastReplTime.IsZero() {
+		lastReplTime = dss.startTime
+	}
+	switch {
+	case dss.replHalted, dss.bootstrapping:
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	case dss.maxHealthyLag > 0 && dss.replLag > dss.maxHealthyLag:
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	case dss.maxReplFailTime > 0 && !dss.replPaused && time.Since(lastReplTime) > dss.maxReplFailTime:
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	default:
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+}
+
+// Close stops the replication from master and waits for it to
+// terminate before closing the underlying storage. It is safe to
+// invoke Close more than once.
 func (dss *dkvSlaveService) Close() error {
-	dss.replStop <- struct{}{}
-	dss.replTckr.Stop()
-	dss.replCli.Close()
-	dss.store.Close()
+	dss.closeOnce.Do(func() {
+		atomic.StoreUint32(&dss.closed, 1)
+		dss.stopReplication()
+		dss.store.Close()
+	})
 	return nil
 }
 
+// Shutdown stops the replication from master, which happens only once
+// the batch of changes being applied is wholly applied, before persisting
+// the writes buffered by the underlying storage and closing it. Returns
+// the error of the given context if it is done first, while the shutdown
+// still completes in the background. Once Shutdown is invoked, Close is
+// a no-op and vice versa.
+func (dss *dkvSlaveService) Shutdown(ctx context.Context) error {
+	errs := make(chan error, 1)
+	go func() {
+		var err error
+		dss.closeOnce.Do(func() {
+			atomic.StoreUint32(&dss.closed, 1)
+			dss.stopReplication()
+			err = storage.Sync(dss.store)
+			dss.store.Close()
+		})
+		errs <- err
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stopReplication stops the replication from master and waits for
+// it to terminate. It is safe to invoke stopReplication more than
+// once, with every invocation returning only after the termination.
+func (dss *dkvSlaveService) stopReplication() {
+	dss.stopOnce.Do(func() {
+		// Interrupts any in-flight poll for changes from master
+		dss.replCancel()
+		close(dss.replStop)
+		dss.replWg.Wait()
+		dss.replTckr.Stop()
+		for _, replCli := range dss.replClis {
+			replCli.Close()
+		}
+	})
+}
+
 func (dss *dkvSlaveService) startReplication(replPollInterval time.Duration) {
 	dss.replTckr = time.NewTicker(replPollInterval)
+	dss.replPollInterval = replPollInterval
+	dss.throttle = newApplyThrottle(dss.maxApplyLatency, dss.maxNumChngs, replPollInterval)
 	latestChngNum, _ := dss.ca.GetLatestAppliedChangeNumber()
 	dss.fromChngNum = 1 + latestChngNum
-	dss.maxNumChngs = maxNumChangesRepl
+	dss.streamChngs = true
+	dss.replCli = dss.replClis[0]
+	dss.masterAddr = dss.replCli.ServiceAddr()
+	dss.checkPersistedState()
 	dss.replStop = make(chan struct{})
+	dss.replCtx, dss.replCancel = context.WithCancel(context.Background())
+	dss.replWg.Add(1)
 	go dss.pollAndApplyChanges()
 }
 
+// checkPersistedState warns if the master node that the local storage
+// recorded as the one replicated from last is none of the candidate
+// masters, as when the slave is pointed at the masters of another shard.
+func (dss *dkvSlaveService) checkPersistedState() {
+	state, err := dss.loadPersistedState()
+	if err != nil {
+		dss.lgr.Warn("Unable to load the persisted replication state", zap.Error(err))
+		return
+	}
+	if state == nil || state.MasterAddr == "" {
+		return
+	}
+	masterAddrs := make([]string, len(dss.replClis))
+	for i, replCli := range dss.replClis {
+		if masterAddrs[i] = replCli.ServiceAddr(); masterAddrs[i] == state.MasterAddr {
+			return
+		}
+	}
+	lastPollTime := time.Unix(0, state.LastPollTimeMillis*int64(time.Millisecond))
+	dss.lgr.Warn("Configured masters differ from the master node replicated from last", zap.Strings("masterAddrs", masterAddrs), zap.String("persistedMasterAddr", state.MasterAddr),
+		zap.String("persistedReplicaID", state.ReplicaID), zap.Uint64("appliedChangeNum", state.AppliedChangeNumber), zap.Time("lastPollTime", lastPollTime))
+}
+
+// loadPersistedState loads the state of the replication recorded by the
+// local storage, which is nil unless the storage records it.
+func (dss *dkvSlaveService) loadPersistedState() (*serverpb.ReplicationState, error) {
+	stateBts, err := storage.LoadReplicationState(dss.ca)
+	if err != nil || stateBts == nil {
+		return nil, err
+	}
+	state := &serverpb.ReplicationState{}
+	if err = proto.Unmarshal(stateBts, state); err != nil {
+		return nil, err
+	}
+	// Storage may record the state apart from the changes, while the
+	// change number is always recorded along with them
+	if state.AppliedChangeNumber, err = dss.ca.GetLatestAppliedChangeNumber(); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// replicationState encodes the state of the replication as of the batch
+// of changes upto the given change number, so that it is recorded along
+// with this batch.
+func (dss *dkvSlaveService) replicationState(chngNum uint64) []byte {
+	state := &serverpb.ReplicationState{
+		MasterAddr:          dss.masterAddr,
+		ReplicaID:           dss.replCli.ReplicaID(),
+		AppliedChangeNumber: chngNum,
+		LastPollTimeMillis:  time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	stateBts, err := proto.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	return stateBts
+}
+
 func (dss *dkvSlaveService) pollAndApplyChanges() {
+	defer dss.replWg.Done()
 	for {
 		select {
 		case <-dss.replTckr.C:
-			if err := dss.applyChangesFromMaster(); err != nil {
-				log.Fatal(err)
+			if time.Now().Before(dss.nextPollTime) || dss.isReplicationPaused() {
+				continue
+			}
+			if err := dss.replicateChangesFromMaster(); err != nil {
+				// Poll interrupted due to the service being closed
+				if dss.replCtx.Err() != nil {
+					return
+				}
+				// Poll interrupted due to a pause, which is no failure
+				if err == errReplicationPaused {
+					continue
+				}
+				if halt := dss.onReplicationError(err); halt {
+					return
+				}
+			} else {
+				// Throttled polls are delayed beyond the next tick
+				dss.replConsecFails, dss.nextPollTime = 0, time.Now().Add(dss.throttle.delay)
 			}
 		case <-dss.replStop:
-			break
+			return
 		}
 	}
 }
 
-func (dss *dkvSlaveService) applyChangesFromMaster() error {
-	res, err := dss.replCli.GetChanges(dss.fromChngNum, dss.maxNumChngs)
-	if err == nil {
+// onReplicationError records the given replication error and
+// computes the backoff before the next poll. Returns true only
+// if the error is unrecoverable and replication must be halted.
+func (dss *dkvSlaveService) onReplicationError(err error) bool {
+	dss.replStatMu.Lock()
+	defer dss.replStatMu.Unlock()
+	dss.replErrs++
+	metrics.ObserveReplicationFailure()
+	if err == errMasterDiverged {
+		dss.lgr.Error("Halting replication from master", zap.String("masterAddr", dss.masterAddr), zap.Error(err))
+		dss.replHalted = true
+		dss.replTckr.Stop()
+		return true
+	}
+	// Other masters may hold uncorrupted copies of the changes mismatching their checksums
+	if err == errMasterNotLeader || status.Code(err) == codes.Unavailable || errors.Is(err, dkverrors.ErrChecksumMismatch) {
+		dss.failoverMaster()
+	}
+
+	dss.replConsecFails++
+	backoff := dss.replPollInterval
+	for i := uint(1); i < dss.replConsecFails && backoff < maxReplPollBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxReplPollBackoff {
+		backoff = maxReplPollBackoff
+	}
+	// Polls happen only on ticks, so the effective backoff is
+	// rounded up to the next tick after this duration
+	dss.nextPollTime = time.Now().Add(backoff - dss.replPollInterval)
+	dss.lgr.Warn("Unable to replicate from master", zap.String("masterAddr", dss.masterAddr), zap.Uint("consecutiveFailures", dss.replConsecFails), zap.Duration("retryIn", backoff), zap.Error(err))
+	return false
+}
+
+// failoverMaster switches the replication onto the next candidate
+// master, or onto the leader hinted by the current master if it is
+// among the candidates, unless the previous failover happened too
+// recently. It must be invoked with replStatMu held.
+func (dss *dkvSlaveService) failoverMaster() {
+	hintedMasterAddr := dss.hintedMasterAddr
+	dss.hintedMasterAddr = ""
+	if len(dss.replClis) < 2 || time.Since(dss.lastFailoverTime) < minMasterFailoverInterval {
+		return
+	}
+	dss.replCliIdx = (dss.replCliIdx + 1) % len(dss.replClis)
+	for i, replCli := range dss.replClis {
+		if hintedMasterAddr != "" && replCli.ServiceAddr() == hintedMasterAddr {
+			dss.replCliIdx = i
+		}
+	}
+	dss.replCli = dss.replClis[dss.replCliIdx]
+	dss.masterAddr = dss.replCli.ServiceAddr()
+	dss.lastFailoverTime = time.Now()
+	// Next poll need not wait for the backoff meant for the previous
+	// master, which may also have lacked the support for streaming
+	dss.replConsecFails, dss.streamChngs = 0, true
+	// Chunks of the other master may be split differently
+	dss.chunks.Reset()
+	dss.lgr.Info("Failing over replication onto another master", zap.String("masterAddr", dss.masterAddr), zap.Uint64("fromChangeNum", dss.fromChngNum))
+}
+
+// replicateChangesFromMaster prefers streaming changes from master,
+// which blocks for as long as the stream is healthy. Once the stream
+// breaks, it falls back to polling for changes, until the stream is
+// attempted again on the next tick.
+func (dss *dkvSlaveService) replicateChangesFromMaster() error {
+	if dss.streamChngs {
+		err := dss.streamChangesFromMaster()
+		switch {
+		case err == errMasterDiverged, err == errMasterNotLeader, err == errReplicationPaused, dss.replCtx.Err() != nil:
+			return err
+		case err == errChangesUnavailable:
+			return dss.bootstrapFromMaster()
+		// Streamed afresh on the next tick, with the batch size resized
+		case err == errBatchResized:
+			return nil
+		case status.Code(err) == codes.Unimplemented:
+			dss.lgr.Warn("Master does not support streaming of changes, falling back to polling", zap.String("masterAddr", dss.masterAddr), zap.Error(err))
+			dss.streamChngs = false
+		default:
+			dss.lgr.Warn("Stream of changes from master broke, falling back to polling", zap.String("masterAddr", dss.masterAddr), zap.Error(err))
+		}
+	}
+	if err := dss.applyChangesFromMaster(); err != errChangesUnavailable {
+		return err
+	}
+	return dss.bootstrapFromMaster()
+}
+
+// bootstrapFromMaster wholly replaces the local storage with a
+// checkpoint of the master node, so that replication can resume
+// from the change number as of which the checkpoint is captured.
+func (dss *dkvSlaveService) bootstrapFromMaster() error {
+	// The entire bootstrap is a single batch with respect to pauses
+	dss.applyMu.Lock()
+	defer dss.applyMu.Unlock()
+	if dss.isReplicationPaused() {
+		return errReplicationPaused
+	}
+	dss.lgr.Warn("Required changes are no longer available on master, bootstrapping from a checkpoint of master", zap.String("masterAddr", dss.masterAddr), zap.Uint64("fromChangeNum", dss.fromChngNum))
+	ctx, cancel := context.WithCancel(dss.replCtx)
+	defer cancel()
+	chkptStrm, err := dss.replCli.GetCheckpointWithCtx(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := chkptStrm.Recv()
+	if err != nil {
+		return err
+	}
+	switch {
+	case res.Status.Code == int32(serverpb.StatusCode_NotLeader):
+		dss.hintedMasterAddr = res.Status.Leader.GetDkvAddr()
+		return errMasterNotLeader
+	case res.Status.Code != 0:
+		return errors.New(res.Status.Message)
+	}
+	chkptChngNum := res.ChangeNumber
+
+	if !dss.beginBootstrap() {
+		return errPromotionInProgress
+	}
+	// Forget all the applied changes upfront so that an interrupted
+	// bootstrap is attempted again even after a restart
+	if err = dss.ca.SetLatestAppliedChangeNumber(0); err != nil {
+		return err
+	}
+	if err = dss.clearStore(); err != nil {
+		return err
+	}
+	for {
+		res, err = chkptStrm.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
 		if res.Status.Code != 0 {
-			err = errors.New(res.Status.Message)
-		} else {
-			if res.MasterChangeNumber < (dss.fromChngNum - 1) {
-				err = errors.New("change number of the master node can not be lesser than the change number of the slave node")
-			} else {
-				err = dss.applyChanges(res)
+			return errors.New(res.Status.Message)
+		}
+		if entries := dss.entriesWithKeyPrefix(res.Entries); len(entries) > 0 {
+			if err = dss.store.MultiPut(entries...); err != nil {
+				return err
 			}
 		}
 	}
-	return err
+	if err = dss.ca.SetLatestAppliedChangeNumber(chkptChngNum); err != nil {
+		return err
+	}
+
+	dss.replStatMu.Lock()
+	dss.fromChngNum = chkptChngNum + 1
+	dss.bootstrapping = false
+	dss.replStatMu.Unlock()
+	dss.lgr.Info("Bootstrapped from a checkpoint of master", zap.Uint64("checkpointChangeNum", chkptChngNum))
+	return nil
 }
 
-func (dss *dkvSlaveService) applyChanges(chngsRes *serverpb.GetChangesResponse) error {
-	if chngsRes.NumberOfChanges > 0 {
-		actChngNum, err := dss.ca.SaveChanges(chngsRes.Changes)
-		dss.fromChngNum = actChngNum + 1
-		dss.replLag = chngsRes.MasterChangeNumber - actChngNum
+// entriesWithKeyPrefix returns the given entries of a checkpoint that
+// have the key prefix of the replication within its namespace, which are
+// all of them if neither is given.
+func (dss *dkvSlaveService) entriesWithKeyPrefix(entries []*serverpb.PutRequest) []*serverpb.PutRequest {
+	if len(dss.storedPrefix) == 0 {
+		return entries
+	}
+	var prefixEntries []*serverpb.PutRequest
+	for _, entry := range entries {
+		if bytes.HasPrefix(entry.Key, dss.storedPrefix) {
+			prefixEntries = append(prefixEntries, entry)
+		}
+	}
+	return prefixEntries
+}
+
+// beginBootstrap marks the local storage as being rebuilt, unless a
+// promotion has begun, in which case the storage must be left intact.
+func (dss *dkvSlaveService) beginBootstrap() bool {
+	dss.replStatMu.Lock()
+	defer dss.replStatMu.Unlock()
+	if dss.promoting {
+		return false
+	}
+	dss.bootstrapping = true
+	return true
+}
+
+func (dss *dkvSlaveService) clearStore() error {
+	iteration := dss.store.Iterate(nil, nil)
+	defer iteration.Close()
+	var keys [][]byte
+	for iteration.HasNext() {
+		key, _ := iteration.Next()
+		if keys = append(keys, key); len(keys) == bootstrapDeleteBatchSize {
+			if err := dss.store.Delete(keys...); err != nil {
+				return err
+			}
+			keys = nil
+		}
+	}
+	if err := iteration.Err(); err != nil {
 		return err
 	}
+	if len(keys) > 0 {
+		return dss.store.Delete(keys...)
+	}
 	return nil
 }
 
+func (dss *dkvSlaveService) streamChangesFromMaster() error {
+	ctx, cancel := context.WithCancel(dss.replCtx)
+	defer cancel()
+	batchSize := dss.throttle.batchSize
+	chngsStrm, err := dss.replCli.StreamChunkedChangesWithCtx(ctx, dss.fromChngNum, dss.chunks.NextChunkOffset(dss.fromChngNum), batchSize, dss.maxNumBytes, dss.keyPrefix)
+	if err != nil {
+		return err
+	}
+	idleTmr := time.AfterFunc(maxChangeStreamIdleTime, cancel)
+	defer idleTmr.Stop()
+	for {
+		res, err := chngsStrm.Recv()
+		if err != nil {
+			return err
+		}
+		idleTmr.Reset(maxChangeStreamIdleTime)
+		if err = dss.applyChangesResponse(ctx, res); err != nil {
+			return err
+		}
+		// Successful stream resets the backoff due to earlier failures
+		dss.replConsecFails = 0
+		if dss.throttle.batchSize != batchSize {
+			return errBatchResized
+		}
+		// Master blocks on the flow control of the stream meanwhile
+		if !dss.awaitThrottle() {
+			return dss.replCtx.Err()
+		}
+	}
+}
+
+// awaitThrottle waits for the delay between the batches of changes, if
+// any. Returns false only if the replication is stopped meanwhile.
+func (dss *dkvSlaveService) awaitThrottle() bool {
+	if dss.throttle.delay <= 0 {
+		return true
+	}
+	tmr := time.NewTimer(dss.throttle.delay)
+	defer tmr.Stop()
+	select {
+	case <-tmr.C:
+		return true
+	case <-dss.replCtx.Done():
+		return false
+	}
+}
+
+// applyChangesFromMaster polls for changes from master and keeps
+// polling right away as long as the slave is lagging behind, even
+// when the changes polled are all skipped due to the key prefix or
+// are only the chunks of a change.
+func (dss *dkvSlaveService) applyChangesFromMaster() error {
+	for {
+		// Replication progress is updated only by this goroutine
+		prevFromChngNum, prevChunkOffset := dss.fromChngNum, dss.chunks.NextChunkOffset(dss.fromChngNum)
+		err := dss.applyChangeBatchFromMaster()
+		progressed := dss.fromChngNum != prevFromChngNum || dss.chunks.NextChunkOffset(dss.fromChngNum) != prevChunkOffset
+		if err != nil || dss.replLag == 0 || !progressed || dss.replCtx.Err() != nil {
+			return err
+		}
+		if !dss.awaitThrottle() {
+			return dss.replCtx.Err()
+		}
+	}
+}
+
+func (dss *dkvSlaveService) applyChangeBatchFromMaster() (err error) {
+	ctx, cancel := context.WithTimeout(dss.replCtx, ctl.DefaultTimeout)
+	defer cancel()
+	// Traces the retrieval of the changes along with their application
+	ctx, span := tracing.StartSpan(ctx, "slave.ReplicateChanges")
+	defer func() { tracing.EndSpan(span, err) }()
+	res, err := dss.replCli.GetChunkedChangesWithCtx(ctx, dss.fromChngNum, dss.chunks.NextChunkOffset(dss.fromChngNum), dss.throttle.batchSize, dss.maxNumBytes, dss.keyPrefix)
+	if err != nil {
+		return err
+	}
+	return dss.applyChangesResponse(ctx, res)
+}
+
+func (dss *dkvSlaveService) applyChangesResponse(ctx context.Context, res *serverpb.GetChangesResponse) error {
+	switch {
+	case res.Status.Code == int32(serverpb.StatusCode_ChangesUnavailable), res.Status.Code == int32(serverpb.StatusCode_ChangesTruncated):
+		return errChangesUnavailable
+	case res.Status.Code == int32(serverpb.StatusCode_NotLeader):
+		dss.hintedMasterAddr = res.Status.Leader.GetDkvAddr()
+		return errMasterNotLeader
+	case res.Status.Code != 0:
+		return errors.New(res.Status.Message)
+	// Slaves serving changes may lag behind, like after a failover onto them
+	case res.MasterChangeNumber < (dss.fromChngNum-1) && !res.ServedBySlave:
+		return errMasterDiverged
+	default:
+		return dss.applyChanges(ctx, res)
+	}
+}
+
+func (dss *dkvSlaveService) applyChanges(ctx context.Context, chngsRes *serverpb.GetChangesResponse) error {
+	dss.applyMu.Lock()
+	defer dss.applyMu.Unlock()
+	// Changes retrieved during a pause are retrieved again on resumption
+	if dss.isReplicationPaused() {
+		return errReplicationPaused
+	}
+
+	var err error
+	var applyLatency time.Duration
+	actChngNum := dss.fromChngNum - 1
+	if chngsRes.NumberOfChanges > 0 {
+		// Changes served in chunks are applied once assembled
+		chngs, chunkErr := dss.chunks.Assemble(chngsRes.Changes)
+		// Changes preceding the first one that is corrupted
+		// or exceeds the size limits are applied nevertheless
+		chngs, limitErr := dss.validChanges(chngs)
+		if len(chngs) > 0 {
+			var appldChngNum uint64
+			applyStart := time.Now()
+			_, span := tracing.StartSpan(ctx, "storage.SaveChanges", tracing.Changes(len(chngs)))
+			// Progress is retained as is when none of the changes are applied
+			state := dss.replicationState(chngs[len(chngs)-1].ChangeNumber)
+			if appldChngNum, err = storage.SaveChangeBatchWithState(dss.ca, chngs, state); appldChngNum > 0 {
+				actChngNum = appldChngNum
+			}
+			applyLatency = time.Since(applyStart)
+			tracing.EndSpan(span, err)
+		}
+		if err == nil {
+			err = limitErr
+		}
+		if err == nil {
+			err = chunkErr
+		}
+	}
+	numChngsApplied := int(actChngNum + 1 - dss.fromChngNum)
+	// Changes skipped by the master due to the key prefix are
+	// recorded as applied, so that they are not retrieved again
+	if err == nil && chngsRes.NextChangeNumber > actChngNum+1 {
+		if err = dss.ca.SetLatestAppliedChangeNumber(chngsRes.NextChangeNumber - 1); err == nil {
+			actChngNum = chngsRes.NextChangeNumber - 1
+		}
+	}
+
+	dss.replStatMu.Lock()
+	defer dss.replStatMu.Unlock()
+	prevFromChngNum := dss.fromChngNum
+	dss.fromChngNum = actChngNum + 1
+	dss.masterChngNum = chngsRes.MasterChangeNumber
+	if applyLatency > 0 {
+		prevBatchSize := dss.throttle.batchSize
+		dss.throttle.observe(applyLatency)
+		if batchSize := dss.throttle.batchSize; batchSize != prevBatchSize {
+			dss.lgr.Info("Resized batches of changes as per the apply latency", zap.Uint32("batchSize", batchSize), zap.Duration("delay", dss.throttle.delay), zap.Duration("applyLatency", dss.throttle.avgLatency))
+		}
+	}
+	if chngsRes.MasterChangeNumber > actChngNum {
+		dss.replLag = chngsRes.MasterChangeNumber - actChngNum
+	} else {
+		dss.replLag = 0
+	}
+	metrics.ObserveReplication(dss.replLag, numChngsApplied)
+	if numChngsApplied > 0 {
+		dss.lgr.Info("Applied batch of changes from master", zap.Uint64("fromChangeNum", prevFromChngNum), zap.Int("batchSize", numChngsApplied), zap.Uint64("lag", dss.replLag))
+	}
+	if err == nil {
+		dss.lastPollTime = time.Now()
+	}
+	return err
+}
+
+// validChanges returns the leading changes that match their checksums
+// and whose entries are all within the size limits, along with the error
+// of the first change that does not.
+func (dss *dkvSlaveService) validChanges(chngs []*serverpb.ChangeRecord) ([]*serverpb.ChangeRecord, error) {
+	for i, chng := range chngs {
+		if err := storage.VerifyChecksum(chng); err != nil {
+			return chngs[:i], fmt.Errorf("change from the master node is rejected: %w", err)
+		}
+		for _, trxn := range chng.Trxns {
+			if err := dss.sizeLimits.Check(trxn.Key, trxn.Value); err != nil {
+				return chngs[:i], fmt.Errorf("change %d from the master node is rejected: %w", chng.ChangeNumber, err)
+			}
+		}
+	}
+	return chngs, nil
+}
+
+func (dss *dkvSlaveService) compressPuts(putReqs ...*serverpb.PutRequest) []*serverpb.PutRequest {
+	if dss.codec == compression.None {
+		return putReqs
+	}
+	compPutReqs := make([]*serverpb.PutRequest, len(putReqs))
+	for i, putReq := range putReqs {
+		compPutReqs[i] = &serverpb.PutRequest{Key: putReq.Key, Value: compression.Compress(dss.codec, putReq.Value), ExpireTS: putReq.ExpireTS}
+	}
+	return compPutReqs
+}
+
+func (dss *dkvSlaveService) decompress(value []byte) ([]byte, error) {
+	if dss.codec == compression.None {
+		return value, nil
+	}
+	return compression.Decompress(value)
+}
+
 func newErrorStatus(err error) *serverpb.Status {
-	return &serverpb.Status{Code: -1, Message: err.Error()}
+	return dkverrors.NewStatus(err)
+}
+
+// newKVResults creates the results of the keys read through
+// storage.GetEach, decompressing the values read.
+func newKVResults(vals [][]byte, found []bool, errs []error, decompress func([]byte) ([]byte, error)) []*serverpb.KVResult {
+	results := make([]*serverpb.KVResult, len(vals))
+	for i, val := range vals {
+		err := errs[i]
+		if err == nil {
+			val, err = decompress(val)
+		}
+		if err != nil {
+			results[i] = &serverpb.KVResult{Status: newErrorStatus(err)}
+		} else {
+			results[i] = &serverpb.KVResult{Status: newEmptyStatus(), Value: val, Found: found[i]}
+		}
+	}
+	return results
 }
 
 func newEmptyStatus() *serverpb.Status {
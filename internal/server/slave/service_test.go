@@ -1,20 +1,33 @@
 package slave
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os/exec"
+	"regexp"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/server/config"
 	"github.com/flipkart-incubator/dkv/internal/server/master"
 	"github.com/flipkart-incubator/dkv/internal/server/storage"
 	"github.com/flipkart-incubator/dkv/internal/server/storage/badger"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
 	"github.com/flipkart-incubator/dkv/internal/server/storage/rocksdb"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -24,7 +37,21 @@ const (
 	slaveSvcPort         = 8282
 	dkvSvcHost           = "localhost"
 	cacheSize            = 3 << 30
-	replPollIntervalSecs = 1
+	replPollInterval     = time.Second
+	maxNumChngsRepl      = 100
+	maxNumBytesRepl      = 1 << 20
+	flakyMasterSvcPort   = 8383
+	leaderMasterSvcPort  = 8386
+	staleSlaveSvcPort    = 8484
+	promotedSlaveSvcPort = 8485
+	prefixMasterSvcPort  = 8387
+	chainedSlaveSvcPort  = 8486
+	bulkLoadMstrSvcPort  = 8388
+	truncMstrSvcPort     = 8389
+	iterMstrSvcPort      = 8390
+	iterSlaveSvcPort     = 8487
+	largeValMstrSvcPort  = 8391
+	maxOutageBackoff     = 2 * time.Second
 )
 
 var (
@@ -49,6 +76,12 @@ func TestMasterRocksDBSlaveBadger(t *testing.T) {
 	testMasterSlaveRepl(t, masterRDB, slaveRDB, masterRDB, slaveRDB, masterRDB, slaveRDB)
 }
 
+func TestMasterRocksDBSlaveMemory(t *testing.T) {
+	masterRDB := newRocksDBStore(masterDBFolder)
+	slaveMDB := memory.OpenDB(0)
+	testMasterSlaveRepl(t, masterRDB, slaveMDB, masterRDB, slaveMDB, masterRDB, slaveMDB)
+}
+
 func testMasterSlaveRepl(t *testing.T, masterStore, slaveStore storage.KVStore, cp storage.ChangePropagator, ca storage.ChangeApplier, masterBU, slaveBU storage.Backupable) {
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -75,6 +108,27 @@ func testMasterSlaveRepl(t *testing.T, masterStore, slaveStore storage.KVStore,
 	sleepInSecs(2)
 	getKeys(t, masterCli, numKeys, keyPrefix, valPrefix)
 	getKeys(t, slaveCli, numKeys, keyPrefix, valPrefix)
+	checkReplicationStatus(t)
+
+	if results, err := slaveCli.Exists([]byte(fmt.Sprintf("%s1", keyPrefix)), []byte("MissingKey")); err != nil {
+		t.Errorf("Unable to check EXISTS on slave. Error: %v", err)
+	} else if len(results) != 2 || !results[0] || results[1] {
+		t.Errorf("Presence mismatch on slave. Expected: [true false], Actual: %v", results)
+	}
+
+	if err := slaveCli.Delete([]byte(fmt.Sprintf("%s1", keyPrefix))); !errors.Is(err, dkverrors.ErrReadOnlyReplica) {
+		t.Errorf("Expected error: %v while deleting a key on the slave. Actual: %v", dkverrors.ErrReadOnlyReplica, err)
+	}
+	delKey := []byte(fmt.Sprintf("%s%d", keyPrefix, numKeys))
+	if err := masterCli.Delete(delKey); err != nil {
+		t.Fatalf("Unable to DELETE. Key: %s, Error: %v", delKey, err)
+	}
+	// wait for atleast one replPollInterval to ensure slave replication
+	sleepInSecs(2)
+	if res, err := slaveCli.Get(delKey); err == nil && len(res.Value) > 0 {
+		t.Errorf("Expected key: %s to be deleted on slave. But found it with value: %s", delKey, res.Value)
+	}
+	getKeys(t, slaveCli, numKeys-1, keyPrefix, valPrefix)
 
 	backupFolder := fmt.Sprintf("%s/backup", masterDBFolder)
 	if err := masterBU.BackupTo(backupFolder); err != nil {
@@ -119,6 +173,22 @@ func getKeys(t *testing.T, dkvCli *ctl.DKVClient, numKeys int, keyPrefix, valPre
 	}
 }
 
+func checkReplicationStatus(t *testing.T) {
+	if replStat, err := slaveCli.ReplicationStatus(); err != nil {
+		t.Errorf("Unable to get replication status. Error: %v", err)
+	} else {
+		if replStat.ReplicationLag != 0 {
+			t.Errorf("Expected no replication lag. Actual: %d", replStat.ReplicationLag)
+		}
+		if replStat.MasterChangeNumber == 0 || replStat.AppliedChangeNumber == 0 {
+			t.Errorf("Expected non zero change numbers. Actual: %+v", replStat)
+		}
+		if lastPollTime := time.Unix(0, replStat.LastPollTimeMillis*int64(time.Millisecond)); time.Since(lastPollTime) > 5*time.Second {
+			t.Errorf("Expected a recent poll from master. Actual last poll time: %v", lastPollTime)
+		}
+	}
+}
+
 func newDKVClient(port int) *ctl.DKVClient {
 	dkvSvcAddr := fmt.Sprintf("%s:%d", dkvSvcHost, port)
 	if client, err := ctl.NewInSecureDKVClient(dkvSvcAddr); err != nil {
@@ -154,12 +224,13 @@ func serveStandaloneDKVMaster(wg *sync.WaitGroup, store storage.KVStore, cp stor
 }
 
 func serveStandaloneDKVSlave(wg *sync.WaitGroup, store storage.KVStore, ca storage.ChangeApplier, masterCli *ctl.DKVClient) {
-	if ss, err := NewService(store, ca, masterCli, replPollIntervalSecs); err != nil {
+	if ss, err := NewService(store, ca, []*ctl.DKVClient{masterCli}, replPollInterval, maxNumChngsRepl, maxNumBytesRepl); err != nil {
 		panic(err)
 	} else {
 		slaveSvc = ss
 		slaveGrpcSrvr = grpc.NewServer()
 		serverpb.RegisterDKVServer(slaveGrpcSrvr, slaveSvc)
+		serverpb.RegisterDKVReplicationStatusServer(slaveGrpcSrvr, slaveSvc)
 		lis := listen(slaveSvcPort)
 		wg.Done()
 		slaveGrpcSrvr.Serve(lis)
@@ -177,3 +248,1252 @@ func listen(port int) net.Listener {
 func sleepInSecs(duration int) {
 	<-time.After(time.Duration(duration) * time.Second)
 }
+
+// flakyMaster serves the given changes only while it is
+// up and fails with UNAVAILABLE errors during an outage. It
+// responds with the NotLeader status while notLeader is set.
+// Changes are streamed only if streaming is set, in which
+// case the stream fails with streamErr if its not nil. Changes
+// before firstChngNum are considered to be compacted away.
+// Checkpoints are held back after their change number until
+// chkptHold is closed, if it is not nil.
+type flakyMaster struct {
+	serverpb.UnimplementedDKVReplicationServer
+	down      uint32
+	notLeader uint32
+	streaming bool
+	streamErr error
+	numPolls  uint32
+	numChkpts uint32
+	chkptHold chan struct{}
+
+	mu            sync.Mutex
+	masterChngNum uint64
+	firstChngNum  uint64
+	changes       []*serverpb.ChangeRecord
+}
+
+func (fm *flakyMaster) GetChanges(ctx context.Context, req *serverpb.GetChangesRequest) (*serverpb.GetChangesResponse, error) {
+	atomic.AddUint32(&fm.numPolls, 1)
+	if atomic.LoadUint32(&fm.down) == 1 {
+		return nil, status.Error(codes.Unavailable, "master is down")
+	}
+	if atomic.LoadUint32(&fm.notLeader) == 1 {
+		return &serverpb.GetChangesResponse{Status: &serverpb.Status{Code: int32(serverpb.StatusCode_NotLeader), Message: "not leader"}}, nil
+	}
+	return fm.changesFrom(req.FromChangeNumber, req.MaxNumberOfChanges), nil
+}
+
+func (fm *flakyMaster) StreamChanges(req *serverpb.GetChangesRequest, strm serverpb.DKVReplication_StreamChangesServer) error {
+	switch {
+	case !fm.streaming:
+		return status.Error(codes.Unimplemented, "streaming not supported")
+	case fm.streamErr != nil:
+		return fm.streamErr
+	}
+	fromChngNum := req.FromChangeNumber
+	for {
+		res := fm.changesFrom(fromChngNum, req.MaxNumberOfChanges)
+		if err := strm.Send(res); err != nil {
+			return err
+		}
+		if res.NumberOfChanges > 0 {
+			fromChngNum = res.Changes[res.NumberOfChanges-1].ChangeNumber + 1
+		}
+		select {
+		case <-time.After(20 * time.Millisecond):
+		case <-strm.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (fm *flakyMaster) changesFrom(fromChngNum uint64, maxNumChngs uint32) *serverpb.GetChangesResponse {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	res := &serverpb.GetChangesResponse{Status: &serverpb.Status{}, MasterChangeNumber: fm.masterChngNum}
+	if fromChngNum < fm.firstChngNum {
+		res.Status = &serverpb.Status{Code: int32(serverpb.StatusCode_ChangesUnavailable), Message: "changes compacted"}
+		return res
+	}
+	for _, chng := range fm.changes {
+		if chng.ChangeNumber >= fromChngNum && len(res.Changes) < int(maxNumChngs) {
+			res.Changes = append(res.Changes, chng)
+		}
+	}
+	res.NumberOfChanges = uint32(len(res.Changes))
+	return res
+}
+
+func (fm *flakyMaster) GetCheckpoint(req *serverpb.GetCheckpointRequest, strm serverpb.DKVReplication_GetCheckpointServer) error {
+	atomic.AddUint32(&fm.numChkpts, 1)
+	fm.mu.Lock()
+	res := &serverpb.GetCheckpointResponse{Status: &serverpb.Status{}}
+	for _, chng := range fm.changes {
+		trxn := chng.Trxns[0]
+		res.Entries = append(res.Entries, &serverpb.PutRequest{Key: trxn.Key, Value: trxn.Value, ExpireTS: trxn.ExpireTS})
+	}
+	chngNum := fm.masterChngNum
+	fm.mu.Unlock()
+
+	if err := strm.Send(&serverpb.GetCheckpointResponse{Status: &serverpb.Status{}, ChangeNumber: chngNum}); err != nil {
+		return err
+	}
+	if fm.chkptHold != nil {
+		select {
+		case <-fm.chkptHold:
+		case <-strm.Context().Done():
+			return strm.Context().Err()
+		}
+	}
+	return strm.Send(res)
+}
+
+// holdBack makes the master report the given number of
+// changes beyond the ones it actually serves
+func (fm *flakyMaster) holdBack(numChngs uint64) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.masterChngNum += numChngs
+}
+
+// compact discards all the changes committed so far
+func (fm *flakyMaster) compact() {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.firstChngNum = fm.masterChngNum + 1
+}
+
+func (fm *flakyMaster) putKeys(fromKey, toKey int, keyPrefix, valPrefix string) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	for i := fromKey; i <= toKey; i++ {
+		key, val := fmt.Sprintf("%s%d", keyPrefix, i), fmt.Sprintf("%s%d", valPrefix, i)
+		trxn := &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: []byte(key), Value: []byte(val)}
+		fm.masterChngNum++
+		fm.changes = append(fm.changes, &serverpb.ChangeRecord{ChangeNumber: fm.masterChngNum, NumberOfTrxns: 1, Trxns: []*serverpb.TrxnRecord{trxn}})
+	}
+}
+
+func (fm *flakyMaster) serve() *grpc.Server {
+	return fm.serveOn(flakyMasterSvcPort)
+}
+
+func (fm *flakyMaster) serveOn(port int) *grpc.Server {
+	grpcSrvr := grpc.NewServer()
+	serverpb.RegisterDKVReplicationServer(grpcSrvr, fm)
+	go grpcSrvr.Serve(listen(port))
+	return grpcSrvr
+}
+
+func checkSlaveKeys(t *testing.T, slaveStore storage.KVStore, fromKey, toKey int, keyPrefix, valPrefix string) {
+	for i := fromKey; i <= toKey; i++ {
+		key, expVal := fmt.Sprintf("%s%d", keyPrefix, i), fmt.Sprintf("%s%d", valPrefix, i)
+		if vals, _, err := slaveStore.Get([]byte(key)); err != nil {
+			t.Errorf("Unable to GET from slave. Key: %s, Error: %v", key, err)
+		} else if string(vals[0]) != expVal {
+			t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expVal, vals[0])
+		}
+	}
+}
+
+func checkSlaveKeyAbsent(t *testing.T, slaveStore storage.KVStore, key string) {
+	if vals, _, err := slaveStore.Get([]byte(key)); err == nil && len(vals) > 0 && len(vals[0]) > 0 {
+		t.Errorf("Expected key to be absent on slave. Key: %s, Value: %s", key, vals[0])
+	}
+}
+
+func TestSlaveRecoversFromMasterOutage(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "OK", "OV"
+	flakyMstr := &flakyMaster{down: 1}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	flakyMstrCli := newDKVClient(flakyMasterSvcPort)
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{flakyMstrCli}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+
+	// Slave must survive the outage while recording the failures
+	time.Sleep(time.Second)
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.NumErrors == 0 || !replStat.Healthy {
+		t.Errorf("Expected replication errors with a healthy slave during master outage. Actual: %+v", replStat)
+	}
+
+	atomic.StoreUint32(&flakyMstr.down, 0)
+	// Allow for the backoff accumulated during the outage
+	time.Sleep(maxOutageBackoff)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.ReplicationLag != 0 || !replStat.Healthy {
+		t.Errorf("Expected slave to catch up with master after recovery. Actual: %+v", replStat)
+	}
+}
+
+func TestSlaveHaltsOnMasterDivergence(t *testing.T) {
+	flakyMstr := &flakyMaster{streaming: true}
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	// Slave is ahead of the master that has no changes
+	slaveStore := memory.OpenDB(0)
+	trxn := &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: []byte("DK"), Value: []byte("DV")}
+	chng := &serverpb.ChangeRecord{ChangeNumber: 1, NumberOfTrxns: 1, Trxns: []*serverpb.TrxnRecord{trxn}}
+	if _, err := slaveStore.SaveChanges([]*serverpb.ChangeRecord{chng}); err != nil {
+		t.Fatal(err)
+	}
+
+	flakyMstrCli := newDKVClient(flakyMasterSvcPort)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{flakyMstrCli}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+
+	time.Sleep(500 * time.Millisecond)
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.Healthy {
+		t.Errorf("Expected slave to be unhealthy after diverging from master. Actual: %+v", replStat)
+	}
+}
+
+func TestSlaveCloseTerminatesReplication(t *testing.T) {
+	flakyMstr := &flakyMaster{streaming: true}
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	numGoroutines := runtime.NumGoroutine()
+	for i := 0; i < 5; i++ {
+		slaveStore := memory.OpenDB(0)
+		dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 10*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+		time.Sleep(50 * time.Millisecond)
+		dss.Close()
+		// Subsequent invocations must be no-ops
+		dss.Close()
+	}
+
+	// Allow for the GRPC connections to wind down
+	for wait := 0; runtime.NumGoroutine() > numGoroutines && wait < 20; wait++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if actGoroutines := runtime.NumGoroutine(); actGoroutines > numGoroutines {
+		t.Errorf("Expected no goroutine leaks after closing slave services. Before: %d, After: %d", numGoroutines, actGoroutines)
+	}
+}
+
+func TestSlaveStreamsChangesFromMaster(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "SK", "SV"
+	flakyMstr := &flakyMaster{streaming: true}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+
+	// Changes committed later must be pushed without polling
+	flakyMstr.putKeys(numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+	time.Sleep(200 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+	if numPolls := atomic.LoadUint32(&flakyMstr.numPolls); numPolls != 0 {
+		t.Errorf("Expected no polls for changes while streaming. Actual: %d", numPolls)
+	}
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.ReplicationLag != 0 || replStat.MasterChangeNumber != uint64(2*numKeys) {
+		t.Errorf("Expected slave to catch up with master while streaming. Actual: %+v", replStat)
+	}
+}
+
+func TestSlavePollsWhenStreamBreaks(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "BK", "BV"
+	flakyMstr := &flakyMaster{streaming: true, streamErr: status.Error(codes.Internal, "stream broke")}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	if numPolls := atomic.LoadUint32(&flakyMstr.numPolls); numPolls == 0 {
+		t.Error("Expected slave to poll for changes when stream breaks")
+	}
+}
+
+func TestSlaveCatchesUpWithoutWaitingForPolls(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 50, "CK", "CV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	// Only 5 changes per batch with a single poll in a second
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, time.Second, 5, maxNumBytesRepl)
+	defer dss.Close()
+
+	time.Sleep(1500 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	// 10 full batches followed by the one finding no more changes
+	if numPolls := atomic.LoadUint32(&flakyMstr.numPolls); numPolls < 10 {
+		t.Errorf("Expected atleast 10 polls for catching up with master. Actual: %d", numPolls)
+	}
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.ReplicationLag != 0 {
+		t.Errorf("Expected slave to catch up with master. Actual: %+v", replStat)
+	}
+}
+
+func TestSlaveBootstrapsFromMasterCheckpoint(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 10, "CPK", "CPV"
+	flakyMstr := &flakyMaster{streaming: true}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstr.compact()
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	// Stale keys on slave must not survive the bootstrap
+	slaveStore := memory.OpenDB(0)
+	if err := slaveStore.Put([]byte("StaleKey"), []byte("StaleValue")); err != nil {
+		t.Fatal(err)
+	}
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	if exists, err := slaveStore.Exists([]byte("StaleKey")); err != nil || exists[0] {
+		t.Errorf("Expected stale key to be removed from slave. Exists: %v, Error: %v", exists, err)
+	}
+
+	// Replication must resume from the checkpoint
+	flakyMstr.putKeys(numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+	time.Sleep(300 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+	if numChkpts := atomic.LoadUint32(&flakyMstr.numChkpts); numChkpts != 1 {
+		t.Errorf("Expected a single checkpoint to be retrieved. Actual: %d", numChkpts)
+	}
+	if chngNum, _ := slaveStore.GetLatestAppliedChangeNumber(); chngNum != uint64(2*numKeys) {
+		t.Errorf("Latest applied change number mismatch. Expected: %d, Actual: %d", 2*numKeys, chngNum)
+	}
+}
+
+func TestSlaveRefusesPromotionDuringBootstrap(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 10, "PBK", "PBV"
+	flakyMstr := &flakyMaster{streaming: true, chkptHold: make(chan struct{})}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstr.compact()
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+	time.Sleep(300 * time.Millisecond)
+
+	ctx := context.Background()
+	if res, err := dss.PromoteToMaster(ctx, &serverpb.PromoteToMasterRequest{}); err != nil || res.Status.Code == 0 {
+		t.Errorf("Expected promotion during a bootstrap to be refused. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if dss.Role() != serverpb.ServerRole_Slave {
+		t.Errorf("Expected slave to remain a slave. Actual: %v", dss.Role())
+	}
+
+	// Replication must continue with the bootstrap
+	close(flakyMstr.chkptHold)
+	time.Sleep(300 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	if res, err := dss.PromoteToMaster(ctx, &serverpb.PromoteToMasterRequest{}); err != nil || res.Status.Code != 0 || res.AppliedChangeNumber != uint64(numKeys) {
+		t.Errorf("Expected promotion after the bootstrap to succeed. Response: %+v, Error: %v", res, err)
+	}
+}
+
+func TestSlaveRejectsStaleReads(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "STK", "STV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+	staleSlaveSrvr := grpc.NewServer()
+	serverpb.RegisterDKVServer(staleSlaveSrvr, dss)
+	go staleSlaveSrvr.Serve(listen(staleSlaveSvcPort))
+	defer staleSlaveSrvr.Stop()
+	staleSlaveCli := newDKVClient(staleSlaveSvcPort)
+	defer staleSlaveCli.Close()
+
+	key, expVal := fmt.Sprintf("%s%d", keyPrefix, 1), fmt.Sprintf("%s%d", valPrefix, 1)
+	time.Sleep(300 * time.Millisecond)
+	if res, err := staleSlaveCli.GetWithConsistency([]byte(key), 1); err != nil {
+		t.Errorf("Expected slave in sync with master to serve bounded reads. Error: %v", err)
+	} else if string(res.Value) != expVal {
+		t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expVal, res.Value)
+	}
+	if _, err := staleSlaveCli.GetWithConsistency([]byte(key), 0); err != nil {
+		t.Errorf("Expected slave in sync with master to serve reads bounded by a zero lag. Error: %v", err)
+	}
+
+	flakyMstr.holdBack(5)
+	time.Sleep(300 * time.Millisecond)
+	if _, err := staleSlaveCli.GetWithConsistency([]byte(key), 3); !errors.Is(err, ctl.ErrStaleRead) {
+		t.Errorf("Expected stale read error from lagging slave. Actual: %v", err)
+	}
+	if _, err := staleSlaveCli.GetWithConsistency([]byte(key), 0); !errors.Is(err, ctl.ErrStaleRead) {
+		t.Errorf("Expected stale read error from lagging slave for a zero lag. Actual: %v", err)
+	}
+	if _, err := staleSlaveCli.GetWithConsistency([]byte(key), 10); err != nil {
+		t.Errorf("Expected lagging slave to serve reads within the permissible lag. Error: %v", err)
+	}
+	if res, err := staleSlaveCli.Get([]byte(key)); err != nil || string(res.Value) != expVal {
+		t.Errorf("Expected lagging slave to serve unbounded reads. Value: %s, Error: %v", res.GetValue(), err)
+	}
+	if _, err := staleSlaveCli.GetLinearizable([]byte(key)); !errors.Is(err, ctl.ErrLinearizableReadUnsupported) {
+		t.Errorf("Expected slave to reject linearizable reads. Actual: %v", err)
+	}
+
+	// Lag of the latest poll is no longer trusted once polls are missed
+	atomic.StoreUint32(&flakyMstr.down, 1)
+	time.Sleep(300 * time.Millisecond)
+	dss.replStatMu.Lock()
+	dss.lastPollTime = dss.lastPollTime.Add(-maxMissedPolls * changeStreamHeartbeatInterval)
+	dss.replStatMu.Unlock()
+	if _, err := staleSlaveCli.GetWithConsistency([]byte(key), 10); !errors.Is(err, ctl.ErrStaleRead) {
+		t.Errorf("Expected stale read error from slave missing its polls. Actual: %v", err)
+	}
+}
+
+// corruptStore fails the reads of badKey, as a store with corrupt
+// blocks does
+type corruptStore struct {
+	storage.KVStore
+	badKey string
+}
+
+func (cs *corruptStore) Get(keys ...[]byte) ([][]byte, []bool, error) {
+	for _, key := range keys {
+		if string(key) == cs.badKey {
+			return nil, nil, errors.New("corrupt block")
+		}
+	}
+	return cs.KVStore.Get(keys...)
+}
+
+func TestSlaveServesDetailedMultiGet(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 3, "MGK", "MGV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(&corruptStore{slaveStore, "MGK2"}, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+	time.Sleep(300 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+
+	keys := [][]byte{[]byte("MGK1"), []byte("MGK2"), []byte("MGK3"), []byte("MGK4")}
+	res, err := dss.MultiGet(context.Background(), &serverpb.MultiGetRequest{Keys: keys, Detailed: true})
+	if err != nil || res.Status.Code != 0 || len(res.Results) != len(keys) {
+		t.Fatalf("Unable to MultiGet. Response: %v, Error: %v", res, err)
+	}
+	for i, result := range res.Results {
+		switch i {
+		case 1:
+			if result.Status.Code == 0 {
+				t.Errorf("Expected the read of the corrupt key to fail. Actual: %v", result)
+			}
+		case 3:
+			if result.Status.Code != 0 || result.Found {
+				t.Errorf("Expected the key to be absent. Actual: %v", result)
+			}
+		default:
+			if expVal := fmt.Sprintf("%s%d", valPrefix, i+1); result.Status.Code != 0 || !result.Found || string(result.Value) != expVal {
+				t.Errorf("Result mismatch for key: %s. Expected Value: %s, Actual: %v", keys[i], expVal, result)
+			}
+		}
+	}
+}
+
+func TestSlavePromotesToMaster(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "PRK", "PRV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+	promotedSlaveSrvr := grpc.NewServer()
+	serverpb.RegisterDKVServer(promotedSlaveSrvr, dss)
+	serverpb.RegisterDKVFailoverServer(promotedSlaveSrvr, dss)
+	go promotedSlaveSrvr.Serve(listen(promotedSlaveSvcPort))
+	defer promotedSlaveSrvr.Stop()
+	promotedSlaveCli := newDKVClient(promotedSlaveSvcPort)
+	defer promotedSlaveCli.Close()
+
+	time.Sleep(300 * time.Millisecond)
+	if err := promotedSlaveCli.Put([]byte("hello"), []byte("world")); err == nil {
+		t.Errorf("Expected PUT on slave to fail before its promotion")
+	}
+	if role := dss.Role(); role != serverpb.ServerRole_Slave {
+		t.Errorf("Expected the role to be slave before its promotion. Actual: %v", role)
+	}
+	if chngNum, err := promotedSlaveCli.PromoteToMaster(); err != nil {
+		t.Fatalf("Unable to promote slave to master. Error: %v", err)
+	} else if chngNum != uint64(numKeys) {
+		t.Errorf("Applied change number mismatch. Expected: %d, Actual: %d", numKeys, chngNum)
+	}
+	if chngNum, err := promotedSlaveCli.PromoteToMaster(); err != nil || chngNum != uint64(numKeys) {
+		t.Errorf("Expected repeated promotion to have no effect. Change number: %d, Error: %v", chngNum, err)
+	}
+	if role := dss.Role(); role != serverpb.ServerRole_Master {
+		t.Errorf("Expected the role to be master once promoted. Actual: %v", role)
+	}
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+
+	if err := promotedSlaveCli.Put([]byte("hello"), []byte("world")); err != nil {
+		t.Errorf("Expected PUT on promoted slave to succeed. Error: %v", err)
+	} else if res, err := promotedSlaveCli.Get([]byte("hello")); err != nil || string(res.Value) != "world" {
+		t.Errorf("GET mismatch on promoted slave. Value: %s, Error: %v", res.GetValue(), err)
+	} else if res, err = promotedSlaveCli.GetLinearizable([]byte("hello")); err != nil || string(res.Value) != "world" {
+		t.Errorf("Expected promoted slave to serve linearizable reads. Value: %s, Error: %v", res.GetValue(), err)
+	}
+
+	// Changes on the old master must no longer be replicated
+	flakyMstr.putKeys(numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+	time.Sleep(300 * time.Millisecond)
+	checkSlaveKeyAbsent(t, slaveStore, fmt.Sprintf("%s%d", keyPrefix, numKeys+1))
+}
+
+func TestSlaveAppliesChangesBypassingKeyPolicies(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 3, "KPK", "KPV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	keyPolicies := storage.KeyPolicies{Default: storage.KeyPolicy{Pattern: regexp.MustCompile(`^/`)}}
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithKeyPolicies(keyPolicies))
+	defer dss.Close()
+	time.Sleep(300 * time.Millisecond)
+	// Changes of the master node are applied, even if they violate the policies
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+
+	ctx := context.Background()
+	if _, err := dss.PromoteToMaster(ctx, &serverpb.PromoteToMasterRequest{}); err != nil {
+		t.Fatalf("Unable to promote slave to master. Error: %v", err)
+	}
+	if res, err := dss.Put(ctx, &serverpb.PutRequest{Key: []byte("hello"), Value: []byte("world")}); err != nil || res.Status.Code != int32(serverpb.StatusCode_InvalidKey) {
+		t.Errorf("Expected PUT of an invalid key on promoted slave to be rejected. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if res, err := dss.Delete(ctx, &serverpb.DeleteRequest{Key: []byte(keyPrefix + "1")}); err != nil || res.Status.Code != int32(serverpb.StatusCode_InvalidKey) {
+		t.Errorf("Expected DELETE of an invalid key on promoted slave to be rejected. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+	if res, err := dss.Put(ctx, &serverpb.PutRequest{Key: []byte("/hello"), Value: []byte("world")}); err != nil || res.Status.Code != 0 {
+		t.Errorf("Expected PUT of a valid key on promoted slave to succeed. Status: %+v, Error: %v", res.GetStatus(), err)
+	}
+}
+
+func TestSlavePausesAndResumesReplication(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "PSK", "PSV"
+	flakyMstr := &flakyMaster{streaming: true}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+
+	time.Sleep(300 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+
+	if _, err := dss.PauseReplication(context.Background(), &serverpb.PauseReplicationRequest{}); err != nil {
+		t.Fatalf("Unable to pause replication. Error: %v", err)
+	}
+	flakyMstr.putKeys(numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+	time.Sleep(300 * time.Millisecond)
+	checkSlaveKeyAbsent(t, slaveStore, fmt.Sprintf("%s%d", keyPrefix, numKeys+1))
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); !replStat.Paused || replStat.AppliedChangeNumber != uint64(numKeys) {
+		t.Errorf("Expected paused replication at change number %d. Actual: %+v", numKeys, replStat)
+	}
+
+	if _, err := dss.ResumeReplication(context.Background(), &serverpb.ResumeReplicationRequest{}); err != nil {
+		t.Fatalf("Unable to resume replication. Error: %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+
+	// Pause that is not resumed must be resumed automatically
+	if _, err := dss.PauseReplication(context.Background(), &serverpb.PauseReplicationRequest{AutoResumeAfterSecs: 1}); err != nil {
+		t.Fatalf("Unable to pause replication. Error: %v", err)
+	}
+	flakyMstr.putKeys(2*numKeys+1, 3*numKeys, keyPrefix, valPrefix)
+	time.Sleep(300 * time.Millisecond)
+	checkSlaveKeyAbsent(t, slaveStore, fmt.Sprintf("%s%d", keyPrefix, 2*numKeys+1))
+	time.Sleep(time.Second)
+	checkSlaveKeys(t, slaveStore, 2*numKeys+1, 3*numKeys, keyPrefix, valPrefix)
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.Paused || replStat.ReplicationLag != 0 {
+		t.Errorf("Expected replication to resume and catch up automatically. Actual: %+v", replStat)
+	}
+}
+
+func TestSlaveFailsOverAcrossMasters(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "FOK", "FOV"
+	followerMstr, leaderMstr := &flakyMaster{notLeader: 1}, &flakyMaster{}
+	followerMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	leaderMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	followerMstrSrvr, leaderMstrSrvr := followerMstr.serve(), leaderMstr.serveOn(leaderMasterSvcPort)
+	defer followerMstrSrvr.Stop()
+	defer leaderMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	followerMstrCli, leaderMstrCli := newDKVClient(flakyMasterSvcPort), newDKVClient(leaderMasterSvcPort)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{followerMstrCli, leaderMstrCli}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+
+	// Slave must move away from the master that is not the leader
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.MasterAddr != leaderMstrCli.ServiceAddr() {
+		t.Errorf("Expected slave to replicate from the leader %s. Actual: %s", leaderMstrCli.ServiceAddr(), replStat.MasterAddr)
+	}
+
+	// Slave must move back once the leader is down and the follower takes over
+	atomic.StoreUint32(&leaderMstr.down, 1)
+	atomic.StoreUint32(&followerMstr.notLeader, 0)
+	followerMstr.putKeys(numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+	time.Sleep(2 * time.Second)
+	checkSlaveKeys(t, slaveStore, numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.MasterAddr != followerMstrCli.ServiceAddr() || replStat.ReplicationLag != 0 {
+		t.Errorf("Expected slave to catch up with the new leader %s. Actual: %+v", followerMstrCli.ServiceAddr(), replStat)
+	}
+}
+
+func TestSlaveRejectsChangesExceedingSizeLimits(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 3, "LK", "LV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstr.putKeys(numKeys+1, numKeys+1, keyPrefix, "LargeValue")
+	flakyMstr.putKeys(numKeys+2, numKeys+2, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	sizeLimits := storage.SizeLimits{MaxKeySize: 8, MaxValueSize: 8}
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithSizeLimits(sizeLimits))
+	defer dss.Close()
+
+	time.Sleep(500 * time.Millisecond)
+	// Changes preceding the one exceeding the limits must be applied
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	checkSlaveKeyAbsent(t, slaveStore, fmt.Sprintf("%s%d", keyPrefix, numKeys+1))
+	checkSlaveKeyAbsent(t, slaveStore, fmt.Sprintf("%s%d", keyPrefix, numKeys+2))
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.ReplicationLag != 2 {
+		t.Errorf("Expected slave to not replicate beyond the change exceeding the limits. Actual: %+v", replStat)
+	}
+
+	dss.PromoteToMaster(context.Background(), &serverpb.PromoteToMasterRequest{})
+	if res, err := dss.Put(context.Background(), &serverpb.PutRequest{Key: []byte("LargeKey1"), Value: []byte("V")}); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	} else if res.Status.Code != int32(serverpb.StatusCode_KeyTooLarge) {
+		t.Errorf("Expected the promoted slave to reject a large key. Status: %+v", res.Status)
+	}
+	if res, err := dss.CompareAndSet(context.Background(), &serverpb.CompareAndSetRequest{Key: []byte("CASKey"), NewValue: []byte("LargeValue")}); err != nil {
+		t.Fatalf("Unable to COMPAREANDSET. Error: %v", err)
+	} else if res.Status.Code != int32(serverpb.StatusCode_ValueTooLarge) || res.Updated {
+		t.Errorf("Expected the promoted slave to reject a large value. Status: %+v, Updated: %t", res.Status, res.Updated)
+	}
+	checkSlaveKeyAbsent(t, slaveStore, "CASKey")
+}
+
+func TestSlaveReportsHealth(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "HLK", "HLV"
+	flakyMstr := &flakyMaster{down: 1}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithHealthThresholds(3, 500*time.Millisecond))
+	checkServingStatus(t, dss, grpc_health_v1.HealthCheckResponse_SERVING, "slave yet to exceed the permissible failure time")
+	time.Sleep(time.Second)
+	checkServingStatus(t, dss, grpc_health_v1.HealthCheckResponse_NOT_SERVING, "slave failing to replicate for too long")
+
+	atomic.StoreUint32(&flakyMstr.down, 0)
+	time.Sleep(maxOutageBackoff)
+	checkServingStatus(t, dss, grpc_health_v1.HealthCheckResponse_SERVING, "slave recovered from master outage")
+
+	flakyMstr.holdBack(5)
+	time.Sleep(300 * time.Millisecond)
+	checkServingStatus(t, dss, grpc_health_v1.HealthCheckResponse_NOT_SERVING, "slave lagging beyond the permissible lag")
+
+	dss.PromoteToMaster(context.Background(), &serverpb.PromoteToMasterRequest{})
+	checkServingStatus(t, dss, grpc_health_v1.HealthCheckResponse_SERVING, "promoted slave")
+	dss.Close()
+	checkServingStatus(t, dss, grpc_health_v1.HealthCheckResponse_NOT_SERVING, "closed slave")
+}
+
+func checkServingStatus(t *testing.T, dss *dkvSlaveService, expStatus grpc_health_v1.HealthCheckResponse_ServingStatus, desc string) {
+	if res, err := dss.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil || res.Status != expStatus {
+		t.Errorf("Serving status mismatch for %s. Expected: %v, Actual: %v, Error: %v", desc, expStatus, res.GetStatus(), err)
+	}
+}
+
+func TestSlaveLogsAppliedBatches(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "LGK", "LGV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	core, logs := observer.New(zap.InfoLevel)
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithLogger(zap.New(core)))
+	defer dss.Close()
+
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	applied := logs.FilterMessage("Applied batch of changes from master").All()
+	if len(applied) == 0 {
+		t.Fatal("Expected the applied batch of changes to be logged")
+	}
+	if flds := applied[0].ContextMap(); flds["fromChangeNum"] != uint64(1) || flds["batchSize"] != int64(numKeys) {
+		t.Errorf("Unexpected fields logged for the applied batch. Actual: %v", flds)
+	}
+}
+
+// pollingMaster serves changes only through polls
+type pollingMaster struct {
+	serverpb.DKVReplicationServer
+}
+
+func (pm pollingMaster) StreamChanges(*serverpb.GetChangesRequest, serverpb.DKVReplication_StreamChangesServer) error {
+	return status.Error(codes.Unimplemented, "streaming not supported")
+}
+
+func TestSlaveReplicatesKeysWithPrefix(t *testing.T) {
+	for _, streaming := range []bool{true, false} {
+		masterStore := memory.OpenDB(0)
+		mstrSvc := master.NewStandaloneService(masterStore, masterStore, nil)
+		grpcSrvr := grpc.NewServer()
+		if streaming {
+			serverpb.RegisterDKVReplicationServer(grpcSrvr, mstrSvc)
+		} else {
+			serverpb.RegisterDKVReplicationServer(grpcSrvr, pollingMaster{mstrSvc})
+		}
+		go grpcSrvr.Serve(listen(prefixMasterSvcPort))
+
+		// Matching changes are far apart, so that most of
+		// the batches of 10 changes are wholly skipped
+		for i := 1; i <= 5; i++ {
+			masterStore.Put([]byte(fmt.Sprintf("PFK%d", i)), []byte(fmt.Sprintf("PFV%d", i)))
+			for j := 0; j < 50; j++ {
+				masterStore.Put([]byte(fmt.Sprintf("XK%d", j)), []byte("XV"))
+			}
+		}
+		slaveStore := memory.OpenDB(0)
+		dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(prefixMasterSvcPort)}, 100*time.Millisecond, 10, maxNumBytesRepl, WithKeyPrefix([]byte("PFK")))
+
+		time.Sleep(500 * time.Millisecond)
+		checkSlaveKeys(t, slaveStore, 1, 5, "PFK", "PFV")
+		checkSlaveKeyAbsent(t, slaveStore, "XK0")
+		masterChngNum, _ := masterStore.GetLatestCommittedChangeNumber()
+		if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.ReplicationLag != 0 || replStat.MasterChangeNumber != masterChngNum {
+			t.Errorf("Expected slave to move past the skipped changes. Streaming: %t, Status: %+v", streaming, replStat)
+		}
+		if appldChngNum, _ := slaveStore.GetLatestAppliedChangeNumber(); appldChngNum != masterChngNum {
+			t.Errorf("Expected the skipped changes to be recorded as applied. Streaming: %t, Expected: %d, Actual: %d", streaming, masterChngNum, appldChngNum)
+		}
+		dss.Close()
+		grpcSrvr.Stop()
+		mstrSvc.Close()
+	}
+}
+
+func TestSlaveValidatesPollInterval(t *testing.T) {
+	store := memory.OpenDB(0)
+	newMstrCli := func() *ctl.DKVClient {
+		cli, err := ctl.NewInSecureDKVClient(fmt.Sprintf("%s:%d", dkvSvcHost, flakyMasterSvcPort), ctl.WithNonBlockingDial())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cli
+	}
+
+	mstrCli := newMstrCli()
+	defer mstrCli.Close()
+	if _, err := NewService(store, store, []*ctl.DKVClient{mstrCli}, MinReplPollInterval/2, maxNumChngsRepl, maxNumBytesRepl); err == nil {
+		t.Errorf("Expected poll intervals shorter than %v to be rejected", MinReplPollInterval)
+	}
+	dss, err := NewService(store, store, []*ctl.DKVClient{newMstrCli()}, 250*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	if err != nil {
+		t.Fatalf("Expected sub-second poll intervals to be accepted. Error: %v", err)
+	}
+	dss.Close()
+}
+
+func TestSlaveRejectsPartialReplicationOntoRocksDB(t *testing.T) {
+	store := memory.OpenDB(0)
+	mstrCli, err := ctl.NewInSecureDKVClient(fmt.Sprintf("%s:%d", dkvSvcHost, flakyMasterSvcPort), ctl.WithNonBlockingDial())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mstrCli.Close()
+
+	cfg := config.Default()
+	cfg.Storage.Engine, cfg.Replication.Namespace = "rocksdb", "users"
+	if _, err := NewService(store, store, []*ctl.DKVClient{mstrCli}, 250*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithConfig(cfg)); err == nil {
+		t.Error("Expected the replication of a namespace onto rocksdb to be rejected")
+	}
+	if _, err := NewService(store, store, []*ctl.DKVClient{mstrCli.ForNamespace("users")}, 250*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithConfig(config.Default())); err == nil {
+		t.Error("Expected the replication through a namespaced client onto rocksdb to be rejected")
+	}
+}
+
+func TestSlaveShutdownRetainsAppliedChanges(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 10, "GSK", "GSV"
+	flakyMstr := &flakyMaster{streaming: true}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	dbFolder := slaveDBFolder + "_shutdown"
+	slaveStore := newBadgerDBStore(dbFolder)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	time.Sleep(500 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := dss.Shutdown(ctx); err != nil {
+		t.Fatalf("Unable to shutdown. Error: %v", err)
+	}
+
+	// Restarted slave resumes past the changes applied before the shutdown
+	slaveStore = badger.OpenDB(dbFolder)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	if chngNum, err := slaveStore.GetLatestAppliedChangeNumber(); err != nil || chngNum != uint64(numKeys) {
+		t.Errorf("Expected the applied change number to be retained. Expected: %d, Actual: %d, Error: %v", numKeys, chngNum, err)
+	}
+	dss = newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+	flakyMstr.putKeys(numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, 2*numKeys, keyPrefix, valPrefix)
+}
+
+func TestSlavePersistsReplicationState(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "PSK", "PSV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	replCli, err := ctl.NewInSecureDKVClient(fmt.Sprintf("%s:%d", dkvSvcHost, flakyMasterSvcPort), ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20), ctl.WithReplicaID("slave1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{replCli}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{})
+	if replStat.ReplicaID != "slave1" {
+		t.Errorf("Replica ID mismatch. Expected: slave1, Actual: %s", replStat.ReplicaID)
+	}
+	if state := replStat.PersistedState; state == nil || state.MasterAddr != replCli.ServiceAddr() || state.ReplicaID != "slave1" ||
+		state.AppliedChangeNumber != uint64(numKeys) || state.LastPollTimeMillis == 0 {
+		t.Errorf("Unexpected replication state persisted. Actual: %+v", state)
+	}
+	dss.Close()
+
+	// Slave pointed at another master warns of the one replicated from last
+	otherMstrSrvr := (&flakyMaster{}).serveOn(leaderMasterSvcPort)
+	defer otherMstrSrvr.Stop()
+	core, logs := observer.New(zap.WarnLevel)
+	dss = newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(leaderMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithLogger(zap.New(core)))
+	defer dss.Close()
+	warned := logs.FilterMessage("Configured masters differ from the master node replicated from last").All()
+	if len(warned) != 1 {
+		t.Fatal("Expected a warning for the master differing from the persisted one")
+	}
+	if flds := warned[0].ContextMap(); flds["persistedMasterAddr"] != replCli.ServiceAddr() || flds["appliedChangeNum"] != uint64(numKeys) {
+		t.Errorf("Unexpected fields logged for the differing master. Actual: %v", flds)
+	}
+}
+
+func TestSlaveRejectsCorruptedChanges(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 3, "CRK", "CRV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	for _, chng := range flakyMstr.changes {
+		chng.Checksum = storage.ChangeChecksum(chng.Trxns)
+	}
+	// Value of the second change is corrupted after its checksum is computed
+	flakyMstr.changes[1].Trxns[0].Value = []byte("Corrupted")
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithChangeLog(slaveStore))
+	defer dss.Close()
+
+	time.Sleep(500 * time.Millisecond)
+	// Changes preceding the corrupted one must be applied
+	checkSlaveKeys(t, slaveStore, 1, 1, keyPrefix, valPrefix)
+	checkSlaveKeyAbsent(t, slaveStore, fmt.Sprintf("%s%d", keyPrefix, 2))
+	checkSlaveKeyAbsent(t, slaveStore, fmt.Sprintf("%s%d", keyPrefix, 3))
+	if replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.ReplicationLag != 2 || replStat.NumErrors == 0 {
+		t.Errorf("Expected slave to not replicate beyond the corrupted change. Actual: %+v", replStat)
+	}
+
+	// Checksums of the changes applied match those of the master
+	res, _ := dss.VerifyRange(context.Background(), &serverpb.VerifyRangeRequest{FromChangeNumber: 1, ToChangeNumber: 1})
+	if res.Status.Code != 0 || res.Checksum != flakyMstr.changes[0].Checksum || res.NumberOfTrxns != 1 {
+		t.Errorf("Expected the checksum of the change applied to match that of the master: %#08x. Actual: %+v", flakyMstr.changes[0].Checksum, res)
+	}
+	if res, _ = dss.VerifyRange(context.Background(), &serverpb.VerifyRangeRequest{FromChangeNumber: 1, ToChangeNumber: 3}); res.Status.Code != int32(serverpb.StatusCode_InvalidArgument) {
+		t.Errorf("Expected the range beyond the changes applied to be rejected. Actual: %+v", res.Status)
+	}
+}
+
+func TestSlaveReplicatesFromAnotherSlave(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "CHK", "CHV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	// Regional slave replicates from the master and serves the local slave
+	regionalStore := memory.OpenDB(0)
+	regional := newSlaveService(regionalStore, regionalStore, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithChangeLog(regionalStore))
+	defer regional.Close()
+	regionalSrvr := grpc.NewServer()
+	serverpb.RegisterDKVReplicationServer(regionalSrvr, ReplicationServer(regional))
+	go regionalSrvr.Serve(listen(chainedSlaveSvcPort))
+	defer regionalSrvr.Stop()
+	regionalCli := newDKVClient(chainedSlaveSvcPort)
+	defer regionalCli.Close()
+
+	localStore := memory.OpenDB(0)
+	local := newSlaveService(localStore, localStore, []*ctl.DKVClient{regionalCli}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer local.Close()
+
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, localStore, 1, numKeys, keyPrefix, valPrefix)
+	flakyMstr.putKeys(numKeys+1, 2*numKeys, keyPrefix, valPrefix)
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, localStore, 1, 2*numKeys, keyPrefix, valPrefix)
+	checkReplicationHop(t, regional, 2*numKeys, 2*numKeys, 0, "regional")
+	checkReplicationHop(t, local, 2*numKeys, 2*numKeys, 0, "local")
+
+	// Lag of every hop is relative to the node it replicates from
+	flakyMstr.holdBack(3)
+	time.Sleep(300 * time.Millisecond)
+	checkReplicationHop(t, regional, 2*numKeys, 2*numKeys+3, 3, "regional")
+	checkReplicationHop(t, local, 2*numKeys, 2*numKeys, 0, "local")
+
+	// Slaves that fail over onto a slave lagging behind them must wait for it
+	aheadMstr := &flakyMaster{}
+	aheadMstr.putKeys(1, 3*numKeys, keyPrefix, valPrefix)
+	aheadMstrSrvr := aheadMstr.serveOn(leaderMasterSvcPort)
+	defer aheadMstrSrvr.Stop()
+	aheadStore := memory.OpenDB(0)
+	ahead := newSlaveService(aheadStore, aheadStore, []*ctl.DKVClient{newDKVClient(leaderMasterSvcPort), regionalCli}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer ahead.Close()
+	time.Sleep(300 * time.Millisecond)
+	atomic.StoreUint32(&aheadMstr.down, 1)
+	time.Sleep(500 * time.Millisecond)
+	if replStat, _ := ahead.GetStatus(context.Background(), &serverpb.GetStatusRequest{}); replStat.MasterAddr != regionalCli.ServiceAddr() || !replStat.Healthy {
+		t.Errorf("Expected slave to keep replicating from the slave lagging behind it. Actual: %+v", replStat)
+	}
+	checkReplicationHop(t, ahead, 3*numKeys, 2*numKeys, 0, "ahead")
+
+	// Slaves that can not serve their changes under the change numbers of the master refuse to
+	prefixSlave := newSlaveService(memory.OpenDB(0), memory.OpenDB(0), []*ctl.DKVClient{regionalCli}, time.Second, maxNumChngsRepl, maxNumBytesRepl, WithKeyPrefix([]byte(keyPrefix)))
+	defer prefixSlave.Close()
+	for _, dss := range []*dkvSlaveService{local, prefixSlave} {
+		if res, _ := dss.GetChanges(context.Background(), &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 1}); res.Status.Code == 0 {
+			t.Errorf("Expected slave without a change log or with a key prefix to not serve changes. Actual: %+v", res)
+		}
+	}
+}
+
+func checkReplicationHop(t *testing.T, dss *dkvSlaveService, appldChngNum, masterChngNum int, lag uint64, hop string) {
+	replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{})
+	if replStat.AppliedChangeNumber != uint64(appldChngNum) || replStat.MasterChangeNumber != uint64(masterChngNum) || replStat.ReplicationLag != lag {
+		t.Errorf("Expected the %s slave to have applied %d changes of %d with a lag of %d. Actual: %+v", hop, appldChngNum, masterChngNum, lag, replStat)
+	}
+}
+
+func TestSlaveBootstrapsAfterBulkLoad(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "BLK", "BLV"
+	masterStore := memory.OpenDB(0)
+	mstrSvc := master.NewStandaloneService(masterStore, masterStore, nil, master.WithBulkLoads())
+	defer mstrSvc.Close()
+	grpcSrvr := grpc.NewServer()
+	serverpb.RegisterDKVReplicationServer(grpcSrvr, mstrSvc)
+	serverpb.RegisterDKVBackupRestoreServer(grpcSrvr, mstrSvc)
+	go grpcSrvr.Serve(listen(bulkLoadMstrSvcPort))
+	defer grpcSrvr.Stop()
+	mstrCli := newDKVClient(bulkLoadMstrSvcPort)
+	defer mstrCli.Close()
+
+	for i := 1; i <= numKeys; i++ {
+		masterStore.Put([]byte(fmt.Sprintf("%s%d", keyPrefix, i)), []byte(fmt.Sprintf("%s%d", valPrefix, i)))
+	}
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(bulkLoadMstrSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+	time.Sleep(300 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+
+	i := numKeys
+	res, err := mstrCli.BulkLoad(func() (*serverpb.PutRequest, error) {
+		if i++; i > 3*numKeys {
+			return nil, nil
+		}
+		return &serverpb.PutRequest{Key: []byte(fmt.Sprintf("%s%d", keyPrefix, i)), Value: []byte(fmt.Sprintf("%s%d", valPrefix, i))}, nil
+	})
+	if err != nil || res.NumberOfKeys != uint64(2*numKeys) || res.ChangeNumber != uint64(numKeys+1) {
+		t.Fatalf("Expected %d keys to be bulk loaded as of change number: %d. Response: %+v, Error: %v", 2*numKeys, numKeys+1, res, err)
+	}
+
+	// Loaded keys reach the slave only through a fresh bootstrap
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, 3*numKeys, keyPrefix, valPrefix)
+	masterStore.Put([]byte(fmt.Sprintf("%s%d", keyPrefix, 3*numKeys+1)), []byte(fmt.Sprintf("%s%d", valPrefix, 3*numKeys+1)))
+	time.Sleep(300 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 3*numKeys+1, 3*numKeys+1, keyPrefix, valPrefix)
+	if chngNum, _ := slaveStore.GetLatestAppliedChangeNumber(); chngNum != uint64(numKeys+2) {
+		t.Errorf("Latest applied change number mismatch. Expected: %d, Actual: %d", numKeys+2, chngNum)
+	}
+}
+
+func TestSlaveBootstrapsAfterTruncation(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "TRK", "TRV"
+	masterStore := memory.OpenDB(0)
+	mstrSvc := master.NewStandaloneService(masterStore, masterStore, nil, master.WithChangeLogRetention(storage.RetentionPolicy{MaxChanges: 1, RetainUnconsumed: true}))
+	defer mstrSvc.Close()
+	grpcSrvr := grpc.NewServer()
+	serverpb.RegisterDKVReplicationServer(grpcSrvr, mstrSvc)
+	go grpcSrvr.Serve(listen(truncMstrSvcPort))
+	defer grpcSrvr.Stop()
+	mstrCli := newDKVClient(truncMstrSvcPort)
+	defer mstrCli.Close()
+	newReplCli := func() *ctl.DKVClient {
+		replCli, err := ctl.NewInSecureDKVClient(fmt.Sprintf("%s:%d", dkvSvcHost, truncMstrSvcPort), ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20), ctl.WithReplicaID("slave1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return replCli
+	}
+
+	for i := 1; i <= numKeys; i++ {
+		masterStore.Put([]byte(fmt.Sprintf("%s%d", keyPrefix, i)), []byte(fmt.Sprintf("%s%d", valPrefix, i)))
+	}
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newReplCli()}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	time.Sleep(300 * time.Millisecond)
+	dss.Close()
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	if res, err := mstrCli.ListReplicas(); err != nil || res.MasterChangeNumber != uint64(numKeys) || len(res.Replicas) != 1 || res.Replicas[0].ReplicaID != "slave1" || res.Replicas[0].ReplicationLag != 0 {
+		t.Errorf("Expected the slave to be listed without any lag. Response: %+v, Error: %v", res, err)
+	}
+
+	// Changes the slave is yet to consume are retained as per the policy
+	for i := numKeys + 1; i <= 2*numKeys; i++ {
+		masterStore.Put([]byte(fmt.Sprintf("%s%d", keyPrefix, i)), []byte(fmt.Sprintf("%s%d", valPrefix, i)))
+	}
+	if first, err := mstrCli.TruncateChangeLog(0); err != nil || first != uint64(numKeys+1) {
+		t.Errorf("Expected the changes from %d to be retained. Actual: %d, Error: %v", numKeys+1, first, err)
+	}
+	info, err := mstrCli.GetChangeLogInfo()
+	if err != nil || info.LastChangeNumber != uint64(2*numKeys) || len(info.Replicas) != 1 || info.Replicas[0].ReplicationLag != uint64(numKeys) {
+		t.Errorf("Expected the progress of the slave to be tracked. Info: %+v, Error: %v", info, err)
+	}
+	if first, err := mstrCli.TruncateChangeLog(uint64(2 * numKeys)); err != nil || first != uint64(2*numKeys) {
+		t.Errorf("Expected the changes before %d to be truncated. Actual: %d, Error: %v", 2*numKeys, first, err)
+	}
+
+	// Truncated changes reach the slave only through a fresh bootstrap
+	dss = newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newReplCli()}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+	time.Sleep(500 * time.Millisecond)
+	checkSlaveKeys(t, slaveStore, 1, 2*numKeys, keyPrefix, valPrefix)
+	if chngNum, _ := slaveStore.GetLatestAppliedChangeNumber(); chngNum != uint64(2*numKeys) {
+		t.Errorf("Latest applied change number mismatch. Expected: %d, Actual: %d", 2*numKeys, chngNum)
+	}
+}
+
+// slowApplier takes atleast the given delay for applying a batch of changes
+type slowApplier struct {
+	storage.ChangeApplier
+	delay time.Duration
+}
+
+func (sa *slowApplier) SaveChangeBatch(changes []*serverpb.ChangeRecord) (uint64, error) {
+	time.Sleep(sa.delay)
+	return sa.ChangeApplier.SaveChangeBatch(changes)
+}
+
+func TestSlaveThrottlesSlowApplies(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 300, "THK", "THV"
+	flakyMstr := &flakyMaster{}
+	flakyMstr.putKeys(1, numKeys, keyPrefix, valPrefix)
+	flakyMstrSrvr := flakyMstr.serve()
+	defer flakyMstrSrvr.Stop()
+
+	// Without a threshold, the latency is only reported
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, &slowApplier{slaveStore, 50 * time.Millisecond}, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	time.Sleep(500 * time.Millisecond)
+	replStat, _ := dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{})
+	dss.Close()
+	checkSlaveKeys(t, slaveStore, 1, numKeys, keyPrefix, valPrefix)
+	if replStat.ApplyLatencyMicros < 50000 || replStat.EffectiveBatchSize != maxNumChngsRepl || replStat.EffectivePollIntervalMillis != 100 {
+		t.Errorf("Expected the apply latency to be reported without any throttling. Actual: %+v", replStat)
+	}
+
+	// Batches shrink and slow down while over the threshold
+	slaveStore = memory.OpenDB(0)
+	dss = newSlaveService(slaveStore, &slowApplier{slaveStore, 50 * time.Millisecond}, []*ctl.DKVClient{newDKVClient(flakyMasterSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithApplyLatencyThreshold(20*time.Millisecond))
+	defer dss.Close()
+	time.Sleep(time.Second)
+	replStat, _ = dss.GetStatus(context.Background(), &serverpb.GetStatusRequest{})
+	if replStat.EffectiveBatchSize >= maxNumChngsRepl/4 || replStat.EffectivePollIntervalMillis <= 200 {
+		t.Errorf("Expected the batches to be throttled. Actual: %+v", replStat)
+	}
+	if replStat.ReplicationLag == 0 || replStat.AppliedChangeNumber < maxNumChngsRepl {
+		t.Errorf("Expected the slave to replicate slowly. Actual: %+v", replStat)
+	}
+}
+
+func TestSlaveIteratesLikeMaster(t *testing.T) {
+	masterStore := memory.OpenDB(0)
+	mstrSvc := master.NewStandaloneService(masterStore, masterStore, nil)
+	defer mstrSvc.Close()
+	mstrSrvr := grpc.NewServer()
+	serverpb.RegisterDKVServer(mstrSrvr, mstrSvc)
+	serverpb.RegisterDKVReplicationServer(mstrSrvr, mstrSvc)
+	go mstrSrvr.Serve(listen(iterMstrSvcPort))
+	defer mstrSrvr.Stop()
+	masterStore.Put([]byte("ItKey1"), []byte("ShortVal"))
+	masterStore.Put([]byte("ItKey2"), []byte("MuchLongerVal"))
+
+	slaveStore := memory.OpenDB(0)
+	dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(iterMstrSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl)
+	defer dss.Close()
+	slaveSrvr := grpc.NewServer()
+	serverpb.RegisterDKVServer(slaveSrvr, dss)
+	go slaveSrvr.Serve(listen(iterSlaveSvcPort))
+	defer slaveSrvr.Stop()
+	time.Sleep(500 * time.Millisecond)
+	if appldChngNum, _ := slaveStore.GetLatestAppliedChangeNumber(); appldChngNum != 2 {
+		t.Fatalf("Expected the slave to replicate both the keys. Applied change number: %d", appldChngNum)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		opts     []ctl.IterateOption
+		expPairs string
+	}{
+		{"AllValues", nil, "ItKey1=ShortVal ItKey2=MuchLongerVal "},
+		{"KeysOnly", []ctl.IterateOption{ctl.WithKeysOnly()}, "ItKey1= ItKey2= "},
+		{"MaxValueSize", []ctl.IterateOption{ctl.WithMaxValueSize(8)}, "ItKey1=ShortVal ItKey2=MuchLong(truncated) "},
+	} {
+		for _, port := range []int{iterMstrSvcPort, iterSlaveSvcPort} {
+			client := newDKVClient(port)
+			pairs, err := client.Iterate([]byte("ItKey"), nil, tc.opts...)
+			if err != nil {
+				t.Fatalf("Unable to iterate. Error: %v", err)
+			}
+			actPairs := ""
+			for pair := range pairs {
+				if pair.Err != nil {
+					t.Fatalf("Error during iteration. Error: %v", pair.Err)
+				}
+				actPairs += fmt.Sprintf("%s=%s", pair.Key, pair.Value)
+				if pair.Truncated {
+					actPairs += "(truncated)"
+				}
+				actPairs += " "
+			}
+			client.Close()
+			if actPairs != tc.expPairs {
+				t.Errorf("Iteration mismatch for %s on port %d. Expected: %s, Actual: %s", tc.name, port, tc.expPairs, actPairs)
+			}
+		}
+	}
+}
+
+func TestSlaveReplicatesEmptyAndLargeValues(t *testing.T) {
+	valSizes := map[string]int{"EmptyVal": 0, "4MBVal": 4 << 20, "64MBVal": 64 << 20}
+	// Values are limited to 4MB by the default config
+	sizeLimits := storage.SizeLimits{MaxValueSize: 64 << 20}
+	ctx := context.Background()
+	for _, streaming := range []bool{true, false} {
+		masterStore := memory.OpenDB(0)
+		mstrSvc := master.NewStandaloneService(masterStore, masterStore, nil, master.WithSizeLimits(sizeLimits))
+		grpcSrvr := grpc.NewServer()
+		if streaming {
+			serverpb.RegisterDKVReplicationServer(grpcSrvr, mstrSvc)
+		} else {
+			serverpb.RegisterDKVReplicationServer(grpcSrvr, pollingMaster{mstrSvc})
+		}
+		go grpcSrvr.Serve(listen(largeValMstrSvcPort))
+
+		if res, err := mstrSvc.Put(ctx, &serverpb.PutRequest{Key: []byte("DeletedKey"), Value: []byte("DeletedVal")}); err != nil || res.Status.Code != 0 {
+			t.Fatalf("Unable to put the key to be deleted. Status: %v, Error: %v", res.GetStatus(), err)
+		}
+		for key, valSize := range valSizes {
+			val := make([]byte, valSize)
+			for i := range val {
+				val[i] = byte(i)
+			}
+			if res, err := mstrSvc.Put(ctx, &serverpb.PutRequest{Key: []byte(key), Value: val}); err != nil || res.Status.Code != 0 {
+				t.Fatalf("Expected the master to accept the value of %d bytes. Key: %s, Status: %v, Error: %v", valSize, key, res.GetStatus(), err)
+			}
+		}
+		if res, err := mstrSvc.Delete(ctx, &serverpb.DeleteRequest{Key: []byte("DeletedKey")}); err != nil || res.Status.Code != 0 {
+			t.Fatalf("Unable to delete the key. Status: %v, Error: %v", res.GetStatus(), err)
+		}
+		// Responses are limited to 4MB by default
+		slaveStore := memory.OpenDB(0)
+		dss := newSlaveService(slaveStore, slaveStore, []*ctl.DKVClient{newDKVClient(largeValMstrSvcPort)}, 100*time.Millisecond, maxNumChngsRepl, maxNumBytesRepl, WithSizeLimits(sizeLimits))
+
+		masterChngNum, _ := masterStore.GetLatestCommittedChangeNumber()
+		for i := 0; i < 100; i++ {
+			if appldChngNum, _ := slaveStore.GetLatestAppliedChangeNumber(); appldChngNum == masterChngNum {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		if appldChngNum, _ := slaveStore.GetLatestAppliedChangeNumber(); appldChngNum != masterChngNum {
+			t.Fatalf("Expected the slave to replicate all the changes. Streaming: %t, Expected: %d, Actual: %d", streaming, masterChngNum, appldChngNum)
+		}
+		for key, valSize := range valSizes {
+			vals, found, err := slaveStore.Get([]byte(key))
+			if err != nil || !found[0] || len(vals[0]) != valSize {
+				t.Errorf("Expected the value of %d bytes to be replicated. Streaming: %t, Key: %s, Found: %t, Actual: %d bytes, Error: %v", valSize, streaming, key, found[0], len(vals[0]), err)
+				continue
+			}
+			for i, b := range vals[0] {
+				if b != byte(i) {
+					t.Errorf("Value mismatch at byte %d. Streaming: %t, Key: %s", i, streaming, key)
+					break
+				}
+			}
+		}
+		if exist, _ := slaveStore.Exists([]byte("EmptyVal"), []byte("DeletedKey")); !exist[0] || exist[1] {
+			t.Errorf("Expected the key with the empty value to exist, unlike the deleted key. Streaming: %t, Exist: %v", streaming, exist)
+		}
+		dss.Close()
+		grpcSrvr.Stop()
+		mstrSvc.Close()
+	}
+}
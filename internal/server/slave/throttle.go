@@ -0,0 +1,60 @@
+package slave
+
+import "time"
+
+// Weight of the latest batch in the moving average of apply latencies
+const applyLatencyWeight = 0.2
+
+// applyThrottle adapts the size of the batches of changes retrieved
+// from the master node, along with the delay between them, to the time
+// taken for applying these batches onto the local storage. While the
+// moving average of this time exceeds the threshold, batches are halved
+// and the delay doubled, so that a slow storage is not overwhelmed. Once
+// the average falls below half the threshold, both are restored in the
+// same steps. Nothing is throttled if the threshold is not positive.
+type applyThrottle struct {
+	threshold    time.Duration
+	maxBatchSize uint32
+	minDelay     time.Duration
+	maxDelay     time.Duration
+
+	avgLatency time.Duration
+	batchSize  uint32
+	delay      time.Duration
+}
+
+func newApplyThrottle(threshold time.Duration, maxBatchSize uint32, pollInterval time.Duration) *applyThrottle {
+	return &applyThrottle{threshold: threshold, maxBatchSize: maxBatchSize, minDelay: pollInterval, maxDelay: maxReplPollBackoff, batchSize: maxBatchSize}
+}
+
+// observe records the time taken for applying a batch of changes,
+// adapting the batch size and the delay as per the moving average.
+func (at *applyThrottle) observe(latency time.Duration) {
+	if at.avgLatency == 0 {
+		at.avgLatency = latency
+	} else {
+		at.avgLatency = time.Duration(applyLatencyWeight*float64(latency) + (1-applyLatencyWeight)*float64(at.avgLatency))
+	}
+	if at.threshold <= 0 {
+		return
+	}
+	switch {
+	case at.avgLatency > at.threshold:
+		if at.batchSize /= 2; at.batchSize == 0 {
+			at.batchSize = 1
+		}
+		if at.delay *= 2; at.delay == 0 {
+			at.delay = at.minDelay
+		}
+		if at.delay > at.maxDelay {
+			at.delay = at.maxDelay
+		}
+	case at.avgLatency < at.threshold/2:
+		if at.batchSize *= 2; at.batchSize > at.maxBatchSize || at.batchSize == 0 {
+			at.batchSize = at.maxBatchSize
+		}
+		if at.delay /= 2; at.delay < at.minDelay {
+			at.delay = 0
+		}
+	}
+}
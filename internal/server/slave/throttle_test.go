@@ -0,0 +1,50 @@
+package slave
+
+import (
+	"testing"
+	"time"
+)
+
+func checkThrottle(t *testing.T, at *applyThrottle, expBatchSize uint32, expDelay time.Duration) {
+	t.Helper()
+	if at.batchSize != expBatchSize || at.delay != expDelay {
+		t.Errorf("Expected batch size %d with delay %v. Actual: %d, %v", expBatchSize, expDelay, at.batchSize, at.delay)
+	}
+}
+
+func TestApplyThrottleAdaptsToLatency(t *testing.T) {
+	at := newApplyThrottle(100*time.Millisecond, 10, time.Second)
+	at.observe(50 * time.Millisecond)
+	checkThrottle(t, at, 10, 0)
+
+	// Batches halve and delays double while over the threshold
+	for _, exp := range []struct {
+		batchSize uint32
+		delay     time.Duration
+	}{{5, time.Second}, {2, 2 * time.Second}, {1, 4 * time.Second}, {1, 8 * time.Second}} {
+		at.observe(time.Second)
+		checkThrottle(t, at, exp.batchSize, exp.delay)
+	}
+	for i := 0; i < 10; i++ {
+		at.observe(time.Second)
+	}
+	checkThrottle(t, at, 1, maxReplPollBackoff)
+
+	// Both are restored once the average falls below half the threshold
+	for at.avgLatency >= 50*time.Millisecond {
+		at.observe(0)
+	}
+	for i := 0; i < 10; i++ {
+		at.observe(0)
+	}
+	checkThrottle(t, at, 10, 0)
+}
+
+func TestApplyThrottleDisabled(t *testing.T) {
+	at := newApplyThrottle(0, 10, time.Second)
+	at.observe(time.Minute)
+	checkThrottle(t, at, 10, 0)
+	if at.avgLatency != time.Minute {
+		t.Errorf("Expected the apply latency to be averaged regardless. Actual: %v", at.avgLatency)
+	}
+}
@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// Magic bytes at the beginning of every backup written by WriteBackupFile.
+var backupFileMagic = []byte("DKVBKUP1")
+
+// WriteBackupFile writes the backup produced by the given function
+// into a new file at the given path. The backup is preceded by a header
+// carrying the engine and change number of the given BackupInfo, and
+// followed by the SHA-256 checksum of all the preceding bytes, so that
+// OpenBackupFile can verify it before it is restored.
+func WriteBackupFile(path string, info BackupInfo, write func(io.Writer) error) (err error) {
+	bckpFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := bckpFile.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	wrtr := bufio.NewWriter(bckpFile)
+	hash := sha256.New()
+	hw := io.MultiWriter(wrtr, hash)
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	hw.Write(backupFileMagic)
+	hw.Write(lenBuf[:binary.PutUvarint(lenBuf, uint64(len(info.Engine)))])
+	hw.Write([]byte(info.Engine))
+	hw.Write(lenBuf[:binary.PutUvarint(lenBuf, info.ChangeNumber)])
+	if err = write(hw); err != nil {
+		return err
+	}
+	if _, err = wrtr.Write(hash.Sum(nil)); err != nil {
+		return err
+	}
+	if err = wrtr.Flush(); err != nil {
+		return err
+	}
+	return bckpFile.Sync()
+}
+
+// OpenBackupFile opens the backup at the given path, written by
+// WriteBackupFile using the given engine, and returns a reader over
+// the backup produced by the writing function, along with the info
+// carried by its header. The checksum of the backup is verified
+// before it is returned. For backups lacking a header, such as those
+// written by earlier versions, the entire file is returned as is,
+// leaving their verification to the engine.
+func OpenBackupFile(path, engine string) (io.ReadCloser, *BackupInfo, error) {
+	bckpFile, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	rdr, info, err := verifyBackupFile(bckpFile, engine)
+	if err != nil {
+		bckpFile.Close()
+		return nil, nil, err
+	}
+	return &backupFileReader{rdr, bckpFile}, info, nil
+}
+
+type backupFileReader struct {
+	io.Reader
+	io.Closer
+}
+
+func verifyBackupFile(bckpFile *os.File, engine string) (io.Reader, *BackupInfo, error) {
+	fi, err := bckpFile.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	info := &BackupInfo{Size: uint64(fi.Size())}
+	magic := make([]byte, len(backupFileMagic))
+	if _, err = io.ReadFull(bckpFile, magic); err != nil || !bytes.Equal(magic, backupFileMagic) {
+		_, err = bckpFile.Seek(0, io.SeekStart)
+		return bckpFile, info, err
+	}
+
+	// Verify the checksum over the entire file prior to its header
+	bodyLen := fi.Size() - sha256.Size
+	if bodyLen < int64(len(backupFileMagic)) {
+		return nil, nil, fmt.Errorf("truncated backup: %w", dkverrors.ErrInvalidArgument)
+	}
+	if _, err = bckpFile.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	hash := sha256.New()
+	if _, err = io.CopyN(hash, bckpFile, bodyLen); err != nil {
+		return nil, nil, err
+	}
+	sum := make([]byte, sha256.Size)
+	if _, err = io.ReadFull(bckpFile, sum); err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(sum, hash.Sum(nil)) {
+		return nil, nil, fmt.Errorf("checksum mismatch, the backup is either truncated or corrupt: %w", dkverrors.ErrInvalidArgument)
+	}
+
+	if _, err = bckpFile.Seek(int64(len(backupFileMagic)), io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	hdrRdr := bufio.NewReader(io.LimitReader(bckpFile, bodyLen-int64(len(backupFileMagic))))
+	engLen, err := binary.ReadUvarint(hdrRdr)
+	if err != nil || engLen > uint64(hdrRdr.Buffered()) {
+		return nil, nil, fmt.Errorf("invalid header of backup: %w", dkverrors.ErrInvalidArgument)
+	}
+	eng := make([]byte, engLen)
+	io.ReadFull(hdrRdr, eng)
+	if info.Engine = string(eng); info.Engine != engine {
+		return nil, nil, fmt.Errorf("backup taken by %s cannot be restored onto %s: %w", info.Engine, engine, dkverrors.ErrInvalidArgument)
+	}
+	if info.ChangeNumber, err = binary.ReadUvarint(hdrRdr); err != nil {
+		return nil, nil, fmt.Errorf("invalid header of backup: %w", dkverrors.ErrInvalidArgument)
+	}
+	return hdrRdr, info, nil
+}
+
+// DefaultRestoreParallelism is the number of workers storing the
+// entries of a restore at once, unless configured otherwise.
+const DefaultRestoreParallelism = 4
+
+// RestoreProgress tracks the progress of a restore as its backup is
+// ingested, possibly by many workers at once. Its fields shall be
+// manipulated using atomics, and its methods are no-ops on nil.
+type RestoreProgress struct {
+	// Bytes is the size of the part of the backup ingested so far,
+	// out of TotalBytes. Restores storing the entries one by one
+	// measure the sizes of their keys and values instead.
+	Bytes, TotalBytes uint64
+	// Keys is the number of keys restored so far, if known.
+	Keys uint64
+}
+
+// Add records that the given number of keys, of the given total
+// size, have been restored.
+func (rp *RestoreProgress) Add(keys, bytes uint64) {
+	if rp != nil {
+		atomic.AddUint64(&rp.Keys, keys)
+		atomic.AddUint64(&rp.Bytes, bytes)
+	}
+}
+
+// SetBytes records the size of the part of the backup ingested so
+// far, along with the size of the entire backup.
+func (rp *RestoreProgress) SetBytes(bytes, totalBytes uint64) {
+	if rp != nil {
+		atomic.StoreUint64(&rp.Bytes, bytes)
+		atomic.StoreUint64(&rp.TotalBytes, totalBytes)
+	}
+}
+
+// Reader wraps the given reader over a backup of the given size, so
+// that the bytes read from it are recorded as ingested.
+func (rp *RestoreProgress) Reader(r io.Reader, totalBytes uint64) io.Reader {
+	rp.SetBytes(0, totalBytes)
+	return &progressReader{r, rp}
+}
+
+type progressReader struct {
+	io.Reader
+	progress *RestoreProgress
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.Reader.Read(p)
+	pr.progress.Add(0, uint64(n))
+	return n, err
+}
+
+// PathSize returns the total size of the file or the files of the
+// folder at the given path, ignoring any that cannot be accessed.
+func PathSize(path string) uint64 {
+	var size uint64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size
+}
+
+// putsSize returns the total size of the keys and values of the given puts.
+func putsSize(puts []*serverpb.PutRequest) uint64 {
+	var size uint64
+	for _, put := range puts {
+		size += uint64(len(put.Key) + len(put.Value))
+	}
+	return size
+}
+
+// ingestPuts stores the batches of puts returned by the given function
+// onto the given store using upto the given number of workers, until
+// the function returns no puts. Upon the first failure of either the
+// function or any of the workers, the remaining batches are abandoned
+// and the failure is returned once all the workers stop.
+func ingestPuts(kvs KVStore, parallelism int, progress *RestoreProgress, next func() ([]*serverpb.PutRequest, error)) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	var wg sync.WaitGroup
+	var failOnce sync.Once
+	var firstErr error
+	batches, failed := make(chan []*serverpb.PutRequest), make(chan struct{})
+	fail := func(err error) {
+		failOnce.Do(func() {
+			firstErr = err
+			close(failed)
+		})
+	}
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for puts := range batches {
+				if err := kvs.MultiPut(puts...); err != nil {
+					fail(err)
+					return
+				}
+				progress.Add(uint64(len(puts)), putsSize(puts))
+			}
+		}()
+	}
+
+produce:
+	for {
+		puts, err := next()
+		switch {
+		case err != nil:
+			fail(err)
+			break produce
+		case len(puts) == 0:
+			break produce
+		}
+		select {
+		case batches <- puts:
+		case <-failed:
+			break produce
+		}
+	}
+	close(batches)
+	wg.Wait()
+	return firstErr
+}
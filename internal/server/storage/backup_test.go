@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+func TestBackupFile(t *testing.T) {
+	dir := newTempDir(t)
+	defer os.RemoveAll(dir)
+
+	bckpFile := path.Join(dir, "bckp")
+	err := WriteBackupFile(bckpFile, BackupInfo{Engine: "test", ChangeNumber: 42}, func(w io.Writer) error {
+		_, err := w.Write([]byte("data"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Unable to write backup. Error: %v", err)
+	}
+	if rdr, info, err := OpenBackupFile(bckpFile, "test"); err != nil {
+		t.Errorf("Unable to open backup. Error: %v", err)
+	} else {
+		data, err := ioutil.ReadAll(rdr)
+		rdr.Close()
+		if err != nil || string(data) != "data" || info.Engine != "test" || info.ChangeNumber != 42 {
+			t.Errorf("Backup mismatch. Data: %s, Info: %+v, Error: %v", data, info, err)
+		}
+	}
+	if _, _, err = OpenBackupFile(bckpFile, "other"); err == nil {
+		t.Error("Expected an error on opening the backup of another engine")
+	}
+
+	bckp, _ := ioutil.ReadFile(bckpFile)
+	ioutil.WriteFile(path.Join(dir, "truncated"), bckp[:len(bckp)-1], 0644)
+	bckp[len(bckp)-sha256.Size-1] ^= 0xFF
+	ioutil.WriteFile(path.Join(dir, "corrupt"), bckp, 0644)
+	for _, name := range []string{"truncated", "corrupt"} {
+		if _, _, err = OpenBackupFile(path.Join(dir, name), "test"); err == nil {
+			t.Errorf("Expected an error on opening a %s backup", name)
+		}
+	}
+
+	// Backups lacking headers are returned as is
+	ioutil.WriteFile(path.Join(dir, "legacy"), []byte("legacy data"), 0644)
+	if rdr, _, err := OpenBackupFile(path.Join(dir, "legacy"), "test"); err != nil {
+		t.Errorf("Unable to open backup lacking header. Error: %v", err)
+	} else {
+		data, _ := ioutil.ReadAll(rdr)
+		rdr.Close()
+		if string(data) != "legacy data" {
+			t.Errorf("Backup mismatch. Expected: legacy data, Actual: %s", data)
+		}
+	}
+}
+
+func TestSwapFolder(t *testing.T) {
+	dir := newTempDir(t)
+	defer os.RemoveAll(dir)
+
+	dst := path.Join(dir, "db")
+	os.Mkdir(dst, 0755)
+	ioutil.WriteFile(path.Join(dst, "data"), []byte("old"), 0644)
+	newFolder := func(data string) string {
+		src, err := CreateSiblingFolder(dst, "swap-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.WriteFile(path.Join(src, "data"), []byte(data), 0644)
+		return src
+	}
+	checkData := func(expData string) {
+		if data, _ := ioutil.ReadFile(path.Join(dst, "data")); string(data) != expData {
+			t.Errorf("Data mismatch. Expected: %s, Actual: %s", expData, data)
+		}
+	}
+
+	// The previous folder is moved back should the open fail
+	numOpens := 0
+	err := SwapFolder(newFolder("bad"), dst, func() error {
+		if numOpens++; numOpens == 1 {
+			return errors.New("open failed")
+		}
+		return nil
+	})
+	if err == nil || numOpens != 2 {
+		t.Errorf("Expected the open to be retried after the failed swap. Opens: %d, Error: %v", numOpens, err)
+	}
+	checkData("old")
+
+	if err = SwapFolder(newFolder("new"), dst, func() error { return nil }); err != nil {
+		t.Fatalf("Unable to swap folder. Error: %v", err)
+	}
+	checkData("new")
+	if _, err = os.Stat(dst + prevFolderSuffix); !os.IsNotExist(err) {
+		t.Errorf("Expected the previous folder to be removed. Error: %v", err)
+	}
+}
+
+// failingPutStore is a KVStore whose puts fail beyond a given number of keys
+type failingPutStore struct {
+	KVStore
+	mu      sync.Mutex
+	keys    int
+	maxKeys int
+}
+
+func (fps *failingPutStore) MultiPut(puts ...*serverpb.PutRequest) error {
+	fps.mu.Lock()
+	defer fps.mu.Unlock()
+	if fps.keys+len(puts) > fps.maxKeys {
+		return errors.New("store is full")
+	}
+	fps.keys += len(puts)
+	return nil
+}
+
+func TestIngestPuts(t *testing.T) {
+	batches := func(numBatches int) func() ([]*serverpb.PutRequest, error) {
+		return func() ([]*serverpb.PutRequest, error) {
+			if numBatches == 0 {
+				return nil, nil
+			}
+			numBatches--
+			return []*serverpb.PutRequest{{Key: []byte("key"), Value: []byte("value")}}, nil
+		}
+	}
+
+	fps, progress := &failingPutStore{maxKeys: 100}, new(RestoreProgress)
+	if err := ingestPuts(fps, 4, progress, batches(100)); err != nil {
+		t.Errorf("Unable to ingest puts. Error: %v", err)
+	}
+	if fps.keys != 100 || progress.Keys != 100 || progress.Bytes != 800 {
+		t.Errorf("Progress mismatch. Stored keys: %d, Progress: %+v", fps.keys, progress)
+	}
+
+	fps = &failingPutStore{maxKeys: 10}
+	if err := ingestPuts(fps, 4, nil, batches(100)); err == nil {
+		t.Error("Expected an error on failing to store the puts")
+	}
+	if fps.keys != 10 {
+		t.Errorf("Expected the ingestion to stop once full. Stored keys: %d", fps.keys)
+	}
+
+	errRead := errors.New("unreadable")
+	err := ingestPuts(&failingPutStore{maxKeys: 100}, 4, nil, func() ([]*serverpb.PutRequest, error) { return nil, errRead })
+	if err != errRead {
+		t.Errorf("Expected the read error. Actual: %v", err)
+	}
+}
+
+func newTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "dkv_storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
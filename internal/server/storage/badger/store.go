@@ -7,15 +7,23 @@ import (
 	"encoding/binary"
 	"encoding/gob"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
 	"sync/atomic"
 
 	"github.com/dgraph-io/badger"
 	badger_pb "github.com/dgraph-io/badger/pb"
+	"github.com/dgraph-io/badger/table"
 	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
 )
 
 // DB interface represents the capabilities exposed
@@ -24,6 +32,8 @@ type DB interface {
 	storage.KVStore
 	storage.Backupable
 	storage.ChangeApplier
+	storage.ReplicationStateKeeper
+	storage.StatsProvider
 }
 
 type badgerDB struct {
@@ -41,6 +51,11 @@ type Opts struct {
 	opts badger.Options
 }
 
+// Open initializes a new instance of BadgerDB with the given options.
+func Open(opts *Opts) (DB, error) {
+	return openStore(opts)
+}
+
 // OpenDB initializes a new instance of BadgerDB with default
 // options. It uses the given folder for storing the data files.
 func OpenDB(dbFolder string) DB {
@@ -52,6 +67,52 @@ func OpenDB(dbFolder string) DB {
 	}
 }
 
+// Repair repairs the Badger folder of the given options, which must not
+// be open, by truncating the corrupted tails of its value log files left
+// behind by an unclean shutdown, losing the writes therein. Returns the
+// descriptions of the files thus fixed.
+func Repair(bdbOpts *Opts) ([]string, error) {
+	before, err := valueLogSizes(bdbOpts.opts.ValueDir)
+	if err != nil {
+		return nil, err
+	}
+	db, err := badger.Open(bdbOpts.opts.WithTruncate(true))
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Close(); err != nil {
+		return nil, err
+	}
+	after, err := valueLogSizes(bdbOpts.opts.ValueDir)
+	if err != nil {
+		return nil, err
+	}
+	var fixes []string
+	for name, size := range before {
+		if newSize, present := after[name]; present && newSize < size {
+			fixes = append(fixes, fmt.Sprintf("truncated %d bytes off value log file %s", size-newSize, name))
+		}
+	}
+	sort.Strings(fixes)
+	return fixes, nil
+}
+
+// valueLogSizes returns the sizes of the value log files in the given
+// folder, which is considered empty if missing.
+func valueLogSizes(folder string) (map[string]int64, error) {
+	infos, err := ioutil.ReadDir(folder)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	sizes := make(map[string]int64, len(infos))
+	for _, info := range infos {
+		if strings.HasSuffix(info.Name(), ".vlog") {
+			sizes[info.Name()] = info.Size()
+		}
+	}
+	return sizes, err
+}
+
 // NewOptions initializes an instance of BadgerDB options with
 // default settings. It can be used to customize specific parameters
 // of the underlying Badger storage engine.
@@ -60,6 +121,28 @@ func NewOptions(dbFolder string) *Opts {
 	return &Opts{opts}
 }
 
+// Logger sets the logger onto which the logs of Badger are written.
+func (bdbOpts *Opts) Logger(lgr *zap.Logger) *Opts {
+	bdbOpts.opts = bdbOpts.opts.WithLogger(badgerLogger{lgr.Sugar()})
+	return bdbOpts
+}
+
+// ReadOnly opens Badger in read only mode, in which the keyspace can
+// be read while any mutation fails, like for migrating it elsewhere.
+func (bdbOpts *Opts) ReadOnly(flag bool) *Opts {
+	bdbOpts.opts = bdbOpts.opts.WithReadOnly(flag)
+	return bdbOpts
+}
+
+// badgerLogger adapts the zap logger onto the logger of Badger
+type badgerLogger struct {
+	*zap.SugaredLogger
+}
+
+func (bl badgerLogger) Warningf(format string, args ...interface{}) {
+	bl.Warnf(format, args...)
+}
+
 func openStore(bdbOpts *Opts) (*badgerDB, error) {
 	db, err := badger.Open(bdbOpts.opts)
 	if err != nil {
@@ -73,31 +156,315 @@ func (bdb *badgerDB) Close() error {
 	return nil
 }
 
+func (bdb *badgerDB) Sync() error {
+	return bdb.db.Sync()
+}
+
+// GetStorageStats reports the sizes of the LSM tree and the value log
+// of Badger, along with the number of keys in the tables of the LSM tree,
+// which includes all the versions of the keys that are not yet compacted
+// away. Memtables are flushed onto level 0 alone, hence the latest table
+// written onto any of the other levels marks the latest compaction.
+func (bdb *badgerDB) GetStorageStats() (*storage.Stats, error) {
+	lsmSize, vlogSize := bdb.db.Size()
+	tables := bdb.db.Tables(true)
+	var numKeys uint64
+	numTables := make(map[int]int)
+	compactedFiles := make(map[string]bool)
+	for _, tbl := range tables {
+		numKeys += tbl.KeyCount
+		numTables[tbl.Level]++
+		if tbl.Level > 0 {
+			compactedFiles[table.IDToFilename(tbl.ID)] = true
+		}
+	}
+	engineStats := map[string]string{
+		"badger.lsm-size":   strconv.FormatInt(lsmSize, 10),
+		"badger.vlog-size":  strconv.FormatInt(vlogSize, 10),
+		"badger.num-tables": strconv.Itoa(len(tables)),
+	}
+	for level, num := range numTables {
+		engineStats[fmt.Sprintf("badger.num-tables-level-%d", level)] = strconv.Itoa(num)
+	}
+
+	folders := []string{bdb.opts.opts.Dir}
+	if bdb.opts.opts.ValueDir != bdb.opts.opts.Dir {
+		folders = append(folders, bdb.opts.opts.ValueDir)
+	}
+	isCompacted := func(file string) bool { return compactedFiles[path.Base(file)] }
+	diskUsage, lastCompactionTime, err := storage.DiskUsage(isCompacted, folders...)
+	if err != nil {
+		return nil, err
+	}
+	return &storage.Stats{Engine: "badger", ApproxNumKeys: numKeys, DiskUsage: diskUsage, LastCompactionTime: lastCompactionTime, EngineStats: engineStats}, nil
+}
+
 func (bdb *badgerDB) Put(key []byte, value []byte) error {
 	return bdb.db.Update(func(txn *badger.Txn) error {
 		return txn.Set(key, value)
 	})
 }
 
-func (bdb *badgerDB) Get(keys ...[]byte) ([][]byte, error) {
-	var results [][]byte
-	err := bdb.db.View(func(txn *badger.Txn) error {
+func (bdb *badgerDB) MultiPut(puts ...*serverpb.PutRequest) error {
+	return bdb.db.Update(func(txn *badger.Txn) error {
+		for i, put := range puts {
+			if err := txn.SetEntry(newEntry(put.Key, put.Value, put.ExpireTS)); err != nil {
+				return fmt.Errorf("unable to put entry %d with key %q: %v", i, put.Key, err)
+			}
+		}
+		return nil
+	})
+}
+
+// newEntry creates an entry that Badger natively expires at the given
+// time. Expired entries are neither visible to reads nor retained by
+// the compactions that Badger runs in the background.
+func newEntry(key, value []byte, expireTS uint64) *badger.Entry {
+	return &badger.Entry{Key: key, Value: value, ExpiresAt: expireTS}
+}
+
+func (bdb *badgerDB) CompareAndSet(key, expectedValue, newValue []byte) (bool, error) {
+	for {
+		updated := false
+		err := bdb.db.Update(func(txn *badger.Txn) error {
+			item, err := txn.Get(key)
+			switch {
+			case err == badger.ErrKeyNotFound:
+				if len(expectedValue) != 0 {
+					return nil
+				}
+			case err != nil:
+				return err
+			default:
+				if len(expectedValue) == 0 {
+					return nil
+				}
+				currValue, err := item.ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				if !storage.ValuesMatch(currValue, expectedValue) {
+					return nil
+				}
+			}
+			updated = true
+			return txn.Set(key, newValue)
+		})
+		// Retry the comparison if a concurrent transaction
+		// mutated the same key in the meantime
+		if err == badger.ErrConflict {
+			continue
+		}
+		return updated && err == nil, err
+	}
+}
+
+func (bdb *badgerDB) Increment(key []byte, delta int64) (int64, error) {
+	for {
+		var res int64
+		err := bdb.db.Update(func(txn *badger.Txn) error {
+			var currValue []byte
+			item, err := txn.Get(key)
+			switch {
+			case err == badger.ErrKeyNotFound:
+			case err != nil:
+				return err
+			default:
+				if currValue, err = item.ValueCopy(nil); err != nil {
+					return err
+				}
+			}
+			var resValue []byte
+			if res, resValue, err = storage.IncrementValue(currValue, delta); err != nil {
+				return err
+			}
+			return txn.Set(key, resValue)
+		})
+		// Retry the increment if a concurrent transaction
+		// mutated the same key in the meantime
+		if err == badger.ErrConflict {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return res, nil
+	}
+}
+
+func (bdb *badgerDB) Txn(txnReq *serverpb.TxnRequest) (bool, error) {
+	for {
+		var succeeded bool
+		err := bdb.db.Update(func(txn *badger.Txn) error {
+			values, found := make([][]byte, len(txnReq.Conditions)), make([]bool, len(txnReq.Conditions))
+			for i, cond := range txnReq.Conditions {
+				item, err := txn.Get(cond.Key)
+				switch {
+				case err == badger.ErrKeyNotFound:
+					continue
+				case err != nil:
+					return err
+				}
+				if values[i], err = item.ValueCopy(nil); err != nil {
+					return err
+				}
+				found[i] = true
+			}
+			succeeded = storage.TxnConditionsHold(txnReq, values, found)
+			for i, mut := range storage.TxnMutations(txnReq, succeeded) {
+				var err error
+				if mut.Type == serverpb.TrxnRecord_Delete {
+					err = txn.Delete(mut.Key)
+				} else {
+					err = txn.SetEntry(newEntry(mut.Key, mut.Value, mut.ExpireTS))
+				}
+				if err != nil {
+					return fmt.Errorf("unable to apply mutation %d with key %q: %v", i, mut.Key, err)
+				}
+			}
+			return nil
+		})
+		// Retry the transaction if a concurrent transaction
+		// mutated any of its keys in the meantime
+		if err == badger.ErrConflict {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return succeeded, nil
+	}
+}
+
+func (bdb *badgerDB) Delete(keys ...[]byte) error {
+	return bdb.db.Update(func(txn *badger.Txn) error {
 		for _, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (bdb *badgerDB) Get(keys ...[]byte) ([][]byte, []bool, error) {
+	return bdb.GetWithContext(context.Background(), keys...)
+}
+
+func (bdb *badgerDB) GetWithContext(ctx context.Context, keys ...[]byte) ([][]byte, []bool, error) {
+	results, found := make([][]byte, len(keys)), make([]bool, len(keys))
+	// A read only transaction provides a consistent snapshot
+	err := bdb.db.View(func(txn *badger.Txn) error {
+		for i, key := range keys {
+			if i%storage.ContextCheckInterval == 0 && ctx.Err() != nil {
+				return ctx.Err()
+			}
 			item, err := txn.Get(key)
-			if err != nil {
+			switch {
+			case err == badger.ErrKeyNotFound:
+				continue
+			case err != nil:
 				return err
 			}
-			value, err := item.ValueCopy(nil)
-			if err != nil {
+			if results[i], err = item.ValueCopy(nil); err != nil {
+				return err
+			}
+			found[i] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, found, nil
+}
+
+func (bdb *badgerDB) Exists(keys ...[]byte) ([]bool, error) {
+	results := make([]bool, len(keys))
+	err := bdb.db.View(func(txn *badger.Txn) error {
+		for i, key := range keys {
+			// Values are loaded lazily by Badger
+			switch _, err := txn.Get(key); {
+			case err == badger.ErrKeyNotFound:
+				results[i] = false
+			case err != nil:
 				return err
+			default:
+				results[i] = true
 			}
-			results = append(results, value)
 		}
 		return nil
 	})
 	return results, err
 }
 
+type iter struct {
+	txn      *badger.Txn
+	it       *badger.Iterator
+	prefix   []byte
+	keysOnly bool
+	err      error
+	expireTS uint64
+}
+
+func (bdb *badgerDB) Iterate(keyPrefix, startKey []byte) storage.Iterator {
+	return bdb.iterate(keyPrefix, startKey, false)
+}
+
+// IterateKeys is same as Iterate except that the values are neither
+// prefetched nor read from the value log.
+func (bdb *badgerDB) IterateKeys(keyPrefix, startKey []byte) storage.Iterator {
+	return bdb.iterate(keyPrefix, startKey, true)
+}
+
+func (bdb *badgerDB) iterate(keyPrefix, startKey []byte, keysOnly bool) storage.Iterator {
+	// A read only transaction provides a consistent snapshot
+	txn := bdb.db.NewTransaction(false)
+	itOpts := badger.DefaultIteratorOptions
+	itOpts.Prefix = keyPrefix
+	itOpts.PrefetchValues = !keysOnly
+	it := txn.NewIterator(itOpts)
+	it.Seek(storage.IterationStartKey(keyPrefix, startKey))
+	return &iter{txn: txn, it: it, prefix: keyPrefix, keysOnly: keysOnly}
+}
+
+func (bdbIter *iter) HasNext() bool {
+	// Skip over the internal metadata keys like the change number
+	for bdbIter.it.ValidForPrefix(bdbIter.prefix) && bytes.HasPrefix(bdbIter.it.Item().Key(), metaKeyPrefix) {
+		bdbIter.it.Next()
+	}
+	return bdbIter.err == nil && bdbIter.it.ValidForPrefix(bdbIter.prefix)
+}
+
+func (bdbIter *iter) Next() ([]byte, []byte) {
+	defer bdbIter.it.Next()
+	item := bdbIter.it.Item()
+	key := item.KeyCopy(nil)
+	bdbIter.expireTS = item.ExpiresAt()
+	if bdbIter.keysOnly {
+		return key, nil
+	}
+	val, err := item.ValueCopy(nil)
+	if err != nil {
+		bdbIter.err = err
+	}
+	return key, val
+}
+
+func (bdbIter *iter) ExpireTS() uint64 {
+	return bdbIter.expireTS
+}
+
+func (bdbIter *iter) Err() error {
+	return bdbIter.err
+}
+
+func (bdbIter *iter) Close() error {
+	bdbIter.it.Close()
+	bdbIter.txn.Discard()
+	return nil
+}
+
 func (bdb *badgerDB) GetSnapshot() ([]byte, error) {
 	// TODO: Check if any options need to be set on stream
 	strm := bdb.db.NewStream()
@@ -136,6 +503,9 @@ func (bdb *badgerDB) PutSnapshot(snap []byte) error {
 
 const backupBufSize = 64 << 20
 
+// Engine recorded in the header of the backups of Badger stores.
+const backupEngine = "badger"
+
 func (bdb *badgerDB) BackupTo(file string) error {
 	if err := checksForBackup(file); err != nil {
 		return err
@@ -146,92 +516,149 @@ func (bdb *badgerDB) BackupTo(file string) error {
 	}
 	defer bdb.endGlobalMutation()
 
-	bf, err := os.Create(path.Clean(file))
+	// As with checkpoints, changes committed after this change
+	// number may also get captured by the backup
+	chngNum, err := bdb.GetLatestAppliedChangeNumber()
 	if err != nil {
 		return err
 	}
-
-	defer bf.Close()
-	bw := bufio.NewWriterSize(bf, backupBufSize)
-	if _, err = bdb.db.Backup(bw, 0); err != nil {
-		return err
-	}
-
-	if err = bw.Flush(); err != nil {
-		return err
-	}
-
-	return bf.Sync()
+	return storage.WriteBackupFile(path.Clean(file), storage.BackupInfo{Engine: backupEngine, ChangeNumber: chngNum}, func(w io.Writer) error {
+		bw := bufio.NewWriterSize(w, backupBufSize)
+		if _, err := bdb.db.Backup(bw, 0); err != nil {
+			return err
+		}
+		return bw.Flush()
+	})
 }
 
 const (
-	tempDirPrefx     = "badger-restore-"
-	tempDirValPrefx  = "badger-restore-val-"
+	tempDirPrefx = "badger-restore-"
+	// Writes pending at once while loading backups with the
+	// default restore parallelism
 	maxPendingWrites = 256
 )
 
-func (bdb *badgerDB) RestoreFrom(file string) (err error) {
+func (bdb *badgerDB) RestoreFrom(file string) error {
+	return bdb.RestoreFromParallel(file, storage.DefaultRestoreParallelism, nil)
+}
+
+// RestoreFromParallel is same as RestoreFrom, except that Badger loads
+// the backup with as many writes pending at once as allowed for the
+// given number of workers. The progress is tracked by the bytes of the
+// backup loaded so far.
+func (bdb *badgerDB) RestoreFromParallel(file string, parallelism int, progress *storage.RestoreProgress) error {
 	// 1. Prevent any other backups or restores
-	err = bdb.beginGlobalMutation()
-	if err != nil {
+	if err := bdb.beginGlobalMutation(); err != nil {
 		return err
 	}
 	defer bdb.endGlobalMutation()
 
-	// 2. Close the current DB to prevent further mutations
+	// 2. Verify the backup by restoring it onto a temp folder,
+	// leaving the current DB untouched should this fail
+	restoreFolder, _, err := bdb.restoreToTempFolder(file, parallelism, progress)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(restoreFolder)
+
+	// 3. Close the current DB to prevent further mutations
 	bdb.db.Close()
 
-	// 3. In any case, reopen the current DB
+	// 4. Move the temp folder onto the current DB folder, which is
+	// retained until the restored DB is reopened. In any case, the
+	// DB is reopened from whichever folder remains in place.
+	return storage.SwapFolder(restoreFolder, bdb.opts.opts.Dir, func() error {
+		finalDB, err := openStore(bdb.opts)
+		if err == nil {
+			bdb.db = finalDB.db
+		}
+		return err
+	})
+}
+
+// loadBackup loads the given backup onto the given DB, with upto the
+// given number of writes pending at once. Since Badger panics on
+// loading some malformed backups, such as the truncated ones lacking
+// headers, these panics are returned as errors instead.
+func loadBackup(db *badger.DB, bckp io.Reader, pendingWrites int) (err error) {
 	defer func() {
-		if finalDB, openErr := openStore(bdb.opts); openErr != nil {
-			err = openErr
-		} else {
-			*bdb = *finalDB
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed backup: %v", r)
 		}
 	}()
+	return db.Load(bckp, pendingWrites)
+}
 
-	// 4. Check for the given restore file validity
-	err = checksForRestore(file)
+func (bdb *badgerDB) VerifyBackup(file string) (*storage.BackupInfo, error) {
+	restoreFolder, info, err := bdb.restoreToTempFolder(file, storage.DefaultRestoreParallelism, nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	os.RemoveAll(restoreFolder)
+	return info, nil
+}
+
+// restoreToTempFolder verifies the given backup file by restoring it
+// onto a temp folder alongside the folder of the current DB, which
+// is returned along with the info of the backup.
+func (bdb *badgerDB) restoreToTempFolder(file string, parallelism int, progress *storage.RestoreProgress) (_ string, _ *storage.BackupInfo, err error) {
+	// 1. Check for the given restore file validity
+	if err = checksForRestore(file); err != nil {
+		return "", nil, err
 	}
 
-	// 5. Open the given restore file
-	f, err := os.Open(file)
+	// 2. Open the given restore file, verifying its checksum
+	bckp, info, err := storage.OpenBackupFile(file, backupEngine)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
-	defer f.Close()
+	defer bckp.Close()
 
-	// 6. Create temp folder for the restored data
-	restoreFolder, err := storage.CreateTempFolder(tempDirPrefx)
+	// 3. Create temp folder for the restored data
+	restoreFolder, err := storage.CreateSiblingFolder(bdb.opts.opts.Dir, tempDirPrefx)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(restoreFolder)
+		}
+	}()
 
-	// 7. Create a temp badger DB pointing to the temp folder
+	// 4. Restore data in the file onto a temp badger DB
 	restoredDB, err := openStore(NewOptions(restoreFolder))
 	if err != nil {
-		return err
+		return "", nil, err
+	}
+	defer restoredDB.db.Close()
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	pendingWrites := parallelism * maxPendingWrites / storage.DefaultRestoreParallelism
+	if err = loadBackup(restoredDB.db, progress.Reader(bckp, info.Size), pendingWrites); err != nil {
+		return "", nil, fmt.Errorf("invalid backup of badger store: %v: %w", err, dkverrors.ErrInvalidArgument)
 	}
+	progress.SetBytes(info.Size, info.Size)
 
-	// 8. Restore data in the file onto the temp badger DB
-	err = restoredDB.db.Load(f, maxPendingWrites)
+	// 5. Ensure that the restored data holds every change recorded
+	// by the backup, which is unknown for backups without headers
+	chngNum, err := restoredDB.GetLatestAppliedChangeNumber()
 	if err != nil {
-		return err
+		return "", nil, err
 	}
-
-	// 9. Close the temp badger DB
-	restoredDB.db.Close()
-
-	// 10. Move the temp folders to the actual locations
-	err = storage.RenameFolder(restoreFolder, bdb.opts.opts.Dir)
-
-	// Plain return due to defer function above
-	return
+	if chngNum < info.ChangeNumber {
+		return "", nil, fmt.Errorf("backup holds changes only until %d, short of %d: %w", chngNum, info.ChangeNumber, dkverrors.ErrInvalidArgument)
+	}
+	info.Engine, info.ChangeNumber = backupEngine, chngNum
+	return restoreFolder, info, nil
 }
 
-const changeNumberKey = "_dkv_meta::ChangeNumber"
+const (
+	changeNumberKey     = "_dkv_meta::ChangeNumber"
+	replicationStateKey = "_dkv_meta::ReplicationState"
+)
+
+var metaKeyPrefix = storage.ReservedKeyPrefix
 
 func (bdb *badgerDB) GetLatestAppliedChangeNumber() (uint64, error) {
 	var chngNum uint64
@@ -255,6 +682,30 @@ func (bdb *badgerDB) GetLatestAppliedChangeNumber() (uint64, error) {
 	return chngNum, err
 }
 
+func (bdb *badgerDB) LoadReplicationState() ([]byte, error) {
+	var state []byte
+	err := bdb.db.View(func(txn *badger.Txn) error {
+		stateVal, err := txn.Get([]byte(replicationStateKey))
+		switch {
+		case err == badger.ErrKeyNotFound:
+			return nil
+		case err != nil:
+			return err
+		}
+		state, err = stateVal.ValueCopy(nil)
+		return err
+	})
+	return state, err
+}
+
+func (bdb *badgerDB) SetLatestAppliedChangeNumber(chngNum uint64) error {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], chngNum)
+	return bdb.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(changeNumberKey), buf[:])
+	})
+}
+
 func (bdb *badgerDB) SaveChanges(changes []*serverpb.ChangeRecord) (uint64, error) {
 	var appldChngNum uint64
 	var lastErr error
@@ -289,7 +740,7 @@ func (bdb *badgerDB) SaveChanges(changes []*serverpb.ChangeRecord) (uint64, erro
 		for _, trxnRec := range chng.Trxns {
 			switch trxnRec.Type {
 			case serverpb.TrxnRecord_Put:
-				if lastErr = chngTrxn.Set(trxnRec.Key, trxnRec.Value); lastErr != nil {
+				if lastErr = chngTrxn.SetEntry(newEntry(trxnRec.Key, trxnRec.Value, trxnRec.ExpireTS)); lastErr != nil {
 					break
 				}
 			case serverpb.TrxnRecord_Delete:
@@ -320,6 +771,82 @@ func (bdb *badgerDB) SaveChanges(changes []*serverpb.ChangeRecord) (uint64, erro
 	return appldChngNum, lastErr
 }
 
+func (bdb *badgerDB) SaveChangeBatch(changes []*serverpb.ChangeRecord) (uint64, error) {
+	return bdb.SaveChangeBatchWithState(changes, nil)
+}
+
+// SaveChangeBatchWithState records the given state within every badger
+// transaction that the changes are split into, along with their change
+// number.
+func (bdb *badgerDB) SaveChangeBatchWithState(changes []*serverpb.ChangeRecord, state []byte) (uint64, error) {
+	var appldChngNum uint64
+	for len(changes) > 0 {
+		numSaved, err := bdb.saveChangesInTxn(changes, state)
+		if numSaved > 0 {
+			appldChngNum = changes[numSaved-1].ChangeNumber
+		}
+		if err != nil {
+			return appldChngNum, err
+		}
+		changes = changes[numSaved:]
+	}
+	return appldChngNum, nil
+}
+
+// saveChangesInTxn commits as many of the leading changes as would
+// fit into a single badger transaction, along with the change number
+// of the last of them and the given state, unless nil. Returns the
+// number of changes committed.
+func (bdb *badgerDB) saveChangesInTxn(changes []*serverpb.ChangeRecord, state []byte) (int, error) {
+	chngTrxn := bdb.db.NewTransaction(true)
+	defer chngTrxn.Discard()
+
+	numChngs := 0
+	for _, chng := range changes {
+		var err error
+		for _, trxnRec := range chng.Trxns {
+			switch trxnRec.Type {
+			case serverpb.TrxnRecord_Put:
+				err = chngTrxn.SetEntry(newEntry(trxnRec.Key, trxnRec.Value, trxnRec.ExpireTS))
+			case serverpb.TrxnRecord_Delete:
+				err = chngTrxn.Delete(trxnRec.Key)
+			}
+			if err != nil {
+				break
+			}
+		}
+		// Retry with only the changes that fit, since this transaction
+		// now holds a part of the current change
+		if err == badger.ErrTxnTooBig && numChngs > 0 {
+			chngTrxn.Discard()
+			return bdb.saveChangesInTxn(changes[:numChngs], state)
+		}
+		if err != nil {
+			return 0, err
+		}
+		numChngs++
+	}
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], changes[numChngs-1].ChangeNumber)
+	err := chngTrxn.Set([]byte(changeNumberKey), buf[:])
+	if err == nil && state != nil {
+		err = chngTrxn.Set([]byte(replicationStateKey), state)
+	}
+	// Make room for the change number by leaving out the last change
+	if err == badger.ErrTxnTooBig && numChngs > 1 {
+		chngTrxn.Discard()
+		return bdb.saveChangesInTxn(changes[:numChngs-1], state)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err = chngTrxn.Commit(); err != nil {
+		return 0, err
+	}
+	return numChngs, nil
+}
+
 var errGlobalMutation = errors.New("Another global keyspace mutation is in progress")
 
 func (bdb *badgerDB) hasGlobalMutation() bool {
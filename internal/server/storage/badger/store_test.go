@@ -3,19 +3,28 @@ package badger
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/dgraph-io/badger"
 	badger_pb "github.com/dgraph-io/badger/pb"
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/storagetest"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
 )
 
-const dbFolder = "/tmp/badger_storage_test"
+const (
+	dbFolder          = "/tmp/badger_storage_test"
+	conformanceFolder = "/tmp/badger_conformance_test"
+)
 
 var store *badgerDB
 
@@ -30,48 +39,157 @@ func TestMain(m *testing.M) {
 	}
 }
 
-func TestPutAndGet(t *testing.T) {
-	numKeys := 10
-	for i := 1; i <= numKeys; i++ {
-		key, value := fmt.Sprintf("K%d", i), fmt.Sprintf("V%d", i)
-		if err := store.Put([]byte(key), []byte(value)); err != nil {
-			t.Fatalf("Unable to PUT. Key: %s, Value: %s, Error: %v", key, value, err)
+func TestConformance(t *testing.T) {
+	storagetest.RunConformanceTests(t, func(t *testing.T) (storage.KVStore, func() (storage.KVStore, error)) {
+		if err := exec.Command("rm", "-rf", conformanceFolder).Run(); err != nil {
+			t.Fatal(err)
+		}
+		open := func() (storage.KVStore, error) { return openStore(NewOptions(conformanceFolder)) }
+		kvs, err := open()
+		if err != nil {
+			t.Fatalf("Unable to open Badger. Error: %v", err)
 		}
+		return kvs, open
+	})
+}
+
+func TestRepair(t *testing.T) {
+	repairFolder := dbFolder + "_repair"
+	if err := exec.Command("rm", "-rf", repairFolder).Run(); err != nil {
+		t.Fatal(err)
 	}
+	kvs, err := openStore(NewOptions(repairFolder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = kvs.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	kvs.Close()
 
-	for i := 1; i <= numKeys; i++ {
-		key, expectedValue := fmt.Sprintf("K%d", i), fmt.Sprintf("V%d", i)
-		if results, err := store.Get([]byte(key)); err != nil {
-			t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
-		} else if string(results[0]) != expectedValue {
-			t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expectedValue, results[0])
-		}
+	// Unclean shutdowns leave behind partially written entries
+	vlogFile := path.Join(repairFolder, "000000.vlog")
+	f, err := os.OpenFile(vlogFile, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte(strings.Repeat("X", 100)))
+	f.Close()
+	if _, err = openStore(NewOptions(repairFolder)); err == nil {
+		t.Fatal("Expected Badger with a corrupted value log not to open")
+	}
+	fixes, err := Repair(NewOptions(repairFolder))
+	if err != nil {
+		t.Fatalf("Unable to repair Badger. Error: %v", err)
+	}
+	if len(fixes) != 1 || !strings.Contains(fixes[0], "000000.vlog") {
+		t.Errorf("Expected the value log to be truncated. Fixes: %v", fixes)
+	}
+	if kvs, err = openStore(NewOptions(repairFolder)); err != nil {
+		t.Fatalf("Unable to open Badger once repaired. Error: %v", err)
+	}
+	defer kvs.Close()
+	if vals, found, err := kvs.Get([]byte("key")); err != nil || !found[0] || string(vals[0]) != "value" {
+		t.Errorf("Expected the key to be retained once repaired. Value: %q, Error: %v", vals, err)
+	}
+	if report, err := storage.Verify(context.Background(), kvs); err != nil || report.NumKeys == 0 {
+		t.Errorf("Expected the repaired Badger to be verified. Report: %+v, Error: %v", report, err)
 	}
 }
 
-func TestMultiGet(t *testing.T) {
-	numKeys := 10
-	keys, vals := make([][]byte, numKeys), make([][]byte, numKeys)
+func TestMultiPutIsAtomic(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 5, "MPAKey", "MPAVal"
+	var puts []*serverpb.PutRequest
 	for i := 1; i <= numKeys; i++ {
-		key, value := fmt.Sprintf("MK%d", i), fmt.Sprintf("MV%d", i)
-		if err := store.Put([]byte(key), []byte(value)); err != nil {
-			t.Fatalf("Unable to PUT. Key: %s, Value: %s, Error: %v", key, value, err)
-		} else {
-			keys[i-1] = []byte(key)
-			vals[i-1] = []byte(value)
-		}
+		k, v := fmt.Sprintf("%s_%d", keyPrefix, i), fmt.Sprintf("%s_%d", valPrefix, i)
+		puts = append(puts, &serverpb.PutRequest{Key: []byte(k), Value: []byte(v)})
+	}
+	// An empty key is rejected by Badger
+	puts = append(puts, &serverpb.PutRequest{Key: nil, Value: []byte("Invalid")})
+	if err := store.MultiPut(puts...); err == nil {
+		t.Error("Expected an error for an empty key. But got none")
+	} else if !strings.Contains(err.Error(), fmt.Sprintf("entry %d", numKeys)) {
+		t.Errorf("Expected the error to identify the failed entry. Error: %v", err)
+	}
+	noKeys(t, numKeys, keyPrefix)
+}
+
+func TestConcurrentCompareAndSet(t *testing.T) {
+	key, numThrds := []byte("CASConcKey"), 10
+	var numUpdates uint32
+	var wg sync.WaitGroup
+	for i := 1; i <= numThrds; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			val := []byte(fmt.Sprintf("CASConcVal_%d", i))
+			if updated, err := store.CompareAndSet(key, nil, val); err != nil {
+				t.Error(err)
+			} else if updated {
+				atomic.AddUint32(&numUpdates, 1)
+			}
+		}(i)
 	}
+	wg.Wait()
+	if numUpdates != 1 {
+		t.Errorf("Expected exactly one COMPAREANDSET to succeed. But %d succeeded", numUpdates)
+	}
+}
 
-	checkGetResults(t, keys, vals)
+func TestConcurrentIncrement(t *testing.T) {
+	key, numThrds, numIncrs := []byte("IncrConcKey"), 10, 20
+	var wg sync.WaitGroup
+	for i := 1; i <= numThrds; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numIncrs; j++ {
+				if _, err := store.Increment(key, 1); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if value, err := store.Increment(key, 0); err != nil {
+		t.Fatalf("Unable to INCREMENT. Key: %s, Error: %v", key, err)
+	} else if value != int64(numThrds*numIncrs) {
+		t.Errorf("Expected no INCREMENT to be lost. Expected Value: %d, Actual Value: %d", numThrds*numIncrs, value)
+	}
 }
 
-func TestMissingGet(t *testing.T) {
-	key := "MissingKey"
-	if vals, err := store.Get([]byte(key)); err == nil {
-		t.Errorf("Expected an error since given key must be missing. But got its value: %s", vals[0])
+func TestPutWithExpiry(t *testing.T) {
+	now := uint64(time.Now().Unix())
+	expKey, liveKey := []byte("ExpKey"), []byte("LiveKey")
+	if err := store.MultiPut(&serverpb.PutRequest{Key: expKey, Value: []byte("ExpVal"), ExpireTS: now - 1},
+		&serverpb.PutRequest{Key: liveKey, Value: []byte("LiveVal"), ExpireTS: now + 100}); err != nil {
+		t.Fatalf("Unable to MULTIPUT. Error: %v", err)
+	}
+	checkMissingGetResults(t, [][]byte{expKey})
+	checkGetResults(t, [][]byte{liveKey}, [][]byte{[]byte("LiveVal")})
+
+	iteration := store.Iterate([]byte("LiveKey"), nil)
+	defer iteration.Close()
+	if !iteration.HasNext() {
+		t.Fatal("Expected the live key in iteration")
+	}
+	if key, _ := iteration.Next(); string(key) != string(liveKey) || iteration.ExpireTS() != now+100 {
+		t.Errorf("Iteration mismatch. Expected: %s expiring at %d, Actual: %s expiring at %d", liveKey, now+100, key, iteration.ExpireTS())
 	}
 }
 
+func TestSaveChangesWithExpiry(t *testing.T) {
+	chngNum, _ := store.GetLatestAppliedChangeNumber()
+	expChng, liveChng := newPutChange(chngNum+1, []byte("SCExpKey"), []byte("SCExpVal")), newPutChange(chngNum+2, []byte("SCLiveKey"), []byte("SCLiveVal"))
+	now := uint64(time.Now().Unix())
+	expChng.Trxns[0].ExpireTS, liveChng.Trxns[0].ExpireTS = now-1, now+100
+	if _, err := store.SaveChangeBatch([]*serverpb.ChangeRecord{expChng, liveChng}); err != nil {
+		t.Fatalf("Unable to save changes. Error: %v", err)
+	}
+	checkMissingGetResults(t, [][]byte{[]byte("SCExpKey")})
+	checkGetResults(t, [][]byte{[]byte("SCLiveKey")}, [][]byte{[]byte("SCLiveVal")})
+}
+
 func TestSaveChangesForPutAndDelete(t *testing.T) {
 	if chngNum, err := store.GetLatestAppliedChangeNumber(); err != nil {
 		t.Error(err)
@@ -145,6 +263,84 @@ func TestSaveChangesForInterleavedPutAndDelete(t *testing.T) {
 	}
 }
 
+func TestSaveChangeBatch(t *testing.T) {
+	chngNum, _ := store.GetLatestAppliedChangeNumber()
+	numKeys, keyPref, valPref := 4, "KSCB_", "VSCB_"
+	ks, vs := make([][]byte, numKeys), make([][]byte, numKeys)
+	var chngRecs []*serverpb.ChangeRecord
+	for i := 0; i < numKeys; i++ {
+		ks[i] = []byte(fmt.Sprintf("%s%d", keyPref, i))
+		vs[i] = []byte(fmt.Sprintf("%s%d", valPref, i))
+		chngRecs = append(chngRecs, newPutChange(chngNum+uint64(len(chngRecs)+1), ks[i], vs[i]))
+	}
+	chngRecs = append(chngRecs, newDelChange(chngNum+uint64(len(chngRecs)+1), ks[0]))
+	expChngNum := chngNum + uint64(len(chngRecs))
+
+	if appldChngNum, err := store.SaveChangeBatch(chngRecs); err != nil {
+		t.Fatal(err)
+	} else if appldChngNum != expChngNum {
+		t.Errorf("Change numbers mismatch. Expected: %d, Actual: %d", expChngNum, appldChngNum)
+	}
+	if actChngNum, _ := store.GetLatestAppliedChangeNumber(); actChngNum != expChngNum {
+		t.Errorf("Latest applied change numbers mismatch. Expected: %d, Actual: %d", expChngNum, actChngNum)
+	}
+	checkMissingGetResults(t, ks[:1])
+	checkGetResults(t, ks[1:], vs[1:])
+}
+
+func TestSaveChangeBatchTooBigForTxn(t *testing.T) {
+	chngNum, _ := store.GetLatestAppliedChangeNumber()
+	// Large enough number of changes to exceed the limits of a single transaction
+	numChngs, keyPref, valPref := 200000, "KSCBL_", "VSCBL_"
+	ks, vs := make([][]byte, numChngs), make([][]byte, numChngs)
+	chngRecs := make([]*serverpb.ChangeRecord, numChngs)
+	for i := 0; i < numChngs; i++ {
+		ks[i], vs[i] = []byte(fmt.Sprintf("%s%d", keyPref, i)), []byte(fmt.Sprintf("%s%d", valPref, i))
+		chngRecs[i] = newPutChange(chngNum+uint64(i+1), ks[i], vs[i])
+	}
+	expChngNum := chngNum + uint64(numChngs)
+
+	if appldChngNum, err := store.SaveChangeBatch(chngRecs); err != nil {
+		t.Fatal(err)
+	} else if appldChngNum != expChngNum {
+		t.Errorf("Change numbers mismatch. Expected: %d, Actual: %d", expChngNum, appldChngNum)
+	}
+	if actChngNum, _ := store.GetLatestAppliedChangeNumber(); actChngNum != expChngNum {
+		t.Errorf("Latest applied change numbers mismatch. Expected: %d, Actual: %d", expChngNum, actChngNum)
+	}
+	checkGetResults(t, ks, vs)
+
+	// Remove all these keys so as to not bloat the keyspace for the other tests
+	for i := 0; i < numChngs; i++ {
+		chngRecs[i] = newDelChange(expChngNum+uint64(i+1), ks[i])
+	}
+	if _, err := store.SaveChangeBatch(chngRecs); err != nil {
+		t.Fatal(err)
+	}
+	checkMissingGetResults(t, ks)
+}
+
+func TestSetLatestAppliedChangeNumber(t *testing.T) {
+	chngNum, _ := store.GetLatestAppliedChangeNumber()
+	chkptChngNum := chngNum + 100
+	if err := store.SetLatestAppliedChangeNumber(chkptChngNum); err != nil {
+		t.Fatal(err)
+	}
+	if actChngNum, err := store.GetLatestAppliedChangeNumber(); err != nil {
+		t.Error(err)
+	} else if actChngNum != chkptChngNum {
+		t.Errorf("Change numbers mismatch. Expected: %d, Actual: %d", chkptChngNum, actChngNum)
+	}
+
+	chngRec := newPutChange(chkptChngNum+1, []byte("KSLCN"), []byte("VSLCN"))
+	if _, err := store.SaveChanges([]*serverpb.ChangeRecord{chngRec}); err != nil {
+		t.Fatal(err)
+	}
+	if actChngNum, _ := store.GetLatestAppliedChangeNumber(); actChngNum != chkptChngNum+1 {
+		t.Errorf("Change numbers mismatch. Expected: %d, Actual: %d", chkptChngNum+1, actChngNum)
+	}
+}
+
 func TestBackupFileValidity(t *testing.T) {
 	expectError(t, checksForBackup(""))
 	expectError(t, checksForBackup(dbFolder))
@@ -157,6 +353,56 @@ func TestRestoreFileValidity(t *testing.T) {
 	expectError(t, checksForRestore("/missing/backup.bak"))
 }
 
+func TestIterateWithPrefix(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 10, "IterKey", "IterVal"
+	putKeys(t, numKeys, keyPrefix, valPrefix)
+	putKeys(t, numKeys, "OtherIterKey", "OtherIterVal")
+
+	iteration := store.Iterate([]byte(keyPrefix), nil)
+	defer iteration.Close()
+
+	// Mutations after creating the iterator must not be visible
+	putKeys(t, 2, keyPrefix+"_Late", valPrefix)
+
+	numIterKeys := 0
+	for iteration.HasNext() {
+		key, val := iteration.Next()
+		if !strings.HasPrefix(string(key), keyPrefix+"_") || strings.HasPrefix(string(key), keyPrefix+"_Late") {
+			t.Errorf("Unexpected key during iteration: %s", key)
+		} else if expVal := strings.Replace(string(key), keyPrefix, valPrefix, 1); string(val) != expVal {
+			t.Errorf("Value mismatch for key: %s. Expected: %s, Actual: %s", key, expVal, val)
+		}
+		numIterKeys++
+	}
+	if err := iteration.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if numIterKeys != numKeys {
+		t.Errorf("Incorrect number of keys iterated. Expected: %d, Actual: %d", numKeys, numIterKeys)
+	}
+}
+
+func TestIterateFromStartKey(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 9, "IterStrtKey", "IterStrtVal"
+	putKeys(t, numKeys, keyPrefix, valPrefix)
+
+	startKey := fmt.Sprintf("%s_%d", keyPrefix, 5)
+	iteration := store.Iterate([]byte(keyPrefix), []byte(startKey))
+	defer iteration.Close()
+
+	expKeyIdx := 5
+	for iteration.HasNext() {
+		key, _ := iteration.Next()
+		if expKey := fmt.Sprintf("%s_%d", keyPrefix, expKeyIdx); string(key) != expKey {
+			t.Errorf("Key mismatch during iteration. Expected: %s, Actual: %s", expKey, key)
+		}
+		expKeyIdx++
+	}
+	if expKeyIdx != numKeys+1 {
+		t.Errorf("Expected iteration to end at key index %d, but it ended at %d", numKeys+1, expKeyIdx)
+	}
+}
+
 func TestBackupAndRestore(t *testing.T) {
 	numTrxns := 50
 	keyPrefix, valPrefix := "brKey", "brVal"
@@ -177,6 +423,67 @@ func TestBackupAndRestore(t *testing.T) {
 	}
 }
 
+func TestVerifyBackupBeforeRestore(t *testing.T) {
+	numTrxns := 50
+	keyPrefix, valPrefix := "vbKey", "vbVal"
+	putKeys(t, numTrxns, keyPrefix, valPrefix)
+
+	backupPath := fmt.Sprintf("%s/%s", dbFolder, "verify.bak")
+	if err := store.BackupTo(backupPath); err != nil {
+		t.Fatal(err)
+	}
+	chngNum, _ := store.GetLatestAppliedChangeNumber()
+	if info, err := store.VerifyBackup(backupPath); err != nil {
+		t.Fatal(err)
+	} else if info.Engine != backupEngine || info.ChangeNumber != chngNum {
+		t.Errorf("Backup info mismatch. Expected engine: %s, change number: %d, Actual: %+v", backupEngine, chngNum, info)
+	}
+	missKeyPrefix, missValPrefix := "mvbKey", "mvbVal"
+	putKeys(t, numTrxns, missKeyPrefix, missValPrefix)
+
+	bckp, err := ioutil.ReadFile(backupPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corruptBckp := append([]byte(nil), bckp...)
+	corruptBckp[len(corruptBckp)/2] ^= 0xFF
+	otherEngineBckp := fmt.Sprintf("%s/%s", dbFolder, "rocksdb.bak")
+	if err = storage.WriteBackupFile(otherEngineBckp, storage.BackupInfo{Engine: "rocksdb"}, func(w io.Writer) error {
+		_, err := w.Write(bckp)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	invalidBckps := map[string][]byte{"truncated.bak": bckp[:len(bckp)-10], "corrupt.bak": corruptBckp, "legacy_truncated.bak": bckp[len(bckp)/2:]}
+	for name, data := range invalidBckps {
+		invalidBckp := fmt.Sprintf("%s/%s", dbFolder, name)
+		if err = ioutil.WriteFile(invalidBckp, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	invalidBckps[path.Base(otherEngineBckp)] = nil
+	for name := range invalidBckps {
+		invalidBckp := fmt.Sprintf("%s/%s", dbFolder, name)
+		if _, err = store.VerifyBackup(invalidBckp); err == nil {
+			t.Errorf("Expected an error on verifying the backup: %s", name)
+		}
+		if err = store.RestoreFrom(invalidBckp); err == nil {
+			t.Errorf("Expected an error on restoring the backup: %s", name)
+		}
+		// The existing keys must be left untouched
+		getKeys(t, numTrxns, missKeyPrefix, missValPrefix)
+	}
+
+	if err = store.RestoreFrom(backupPath); err != nil {
+		t.Fatal(err)
+	}
+	getKeys(t, numTrxns, keyPrefix, valPrefix)
+	noKeys(t, numTrxns, missKeyPrefix)
+	if _, err = os.Stat(dbFolder + ".prev"); !os.IsNotExist(err) {
+		t.Errorf("Expected the previous DB folder to be removed after the restore. Error: %v", err)
+	}
+}
+
 func TestGetPutSnapshot(t *testing.T) {
 	numTrxns := 100
 	keyPrefix1, valPrefix1, newValPrefix1 := "firSnapKey", "firSnapVal", "newFirSnapVal"
@@ -336,6 +643,21 @@ func BenchmarkSaveChangesOneByOne(b *testing.B) {
 	}
 }
 
+func BenchmarkSaveChangeBatch(b *testing.B) {
+	batchSize := 100
+	chngRecs := make([]*serverpb.ChangeRecord, 0, batchSize)
+	for i := 0; i < b.N; i++ {
+		key, value := fmt.Sprintf("BSBK%d", i), fmt.Sprintf("BSBV%d", i)
+		chngRecs = append(chngRecs, newPutChange(uint64(i+1), []byte(key), []byte(value)))
+		if len(chngRecs) == batchSize || i == b.N-1 {
+			if _, err := store.SaveChangeBatch(chngRecs); err != nil {
+				b.Fatalf("Unable to SaveChangeBatch for PUTs. Error: %v", err)
+			}
+			chngRecs = chngRecs[:0]
+		}
+	}
+}
+
 func BenchmarkPutNewKeys(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		key, value := fmt.Sprintf("BK%d", i), fmt.Sprintf("BV%d", i)
@@ -364,7 +686,7 @@ func BenchmarkGetKey(b *testing.B) {
 		b.Fatalf("Unable to PUT. Key: %s. Error: %v", key, err)
 	}
 	for i := 0; i < b.N; i++ {
-		if results, err := store.Get([]byte(key)); err != nil {
+		if results, _, err := store.Get([]byte(key)); err != nil {
 			b.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
 		} else if string(results[0]) != val {
 			b.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, val, results[0])
@@ -375,8 +697,10 @@ func BenchmarkGetKey(b *testing.B) {
 func BenchmarkGetMissingKey(b *testing.B) {
 	key := "BMissingKey"
 	for i := 0; i < b.N; i++ {
-		if res, err := store.Get([]byte(key)); err == nil {
-			b.Fatalf("Expected an error on missing key, but got no error. Key: %s, Value: %s", key, res)
+		if res, found, err := store.Get([]byte(key)); err != nil {
+			b.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
+		} else if found[0] {
+			b.Fatalf("Expected missing key, but got its value. Key: %s, Value: %s", key, res[0])
 		}
 	}
 }
@@ -443,7 +767,7 @@ func BenchmarkIteratorBasedIteration(b *testing.B) {
 }
 
 func checkGetResults(t *testing.T, ks, expVs [][]byte) {
-	if results, err := store.Get(ks...); err != nil {
+	if results, _, err := store.Get(ks...); err != nil {
 		t.Error(err)
 	} else {
 		for i, result := range results {
@@ -457,7 +781,9 @@ func checkGetResults(t *testing.T, ks, expVs [][]byte) {
 func noKeys(t *testing.T, numKeys int, keyPrefix string) {
 	for i := 1; i <= numKeys; i++ {
 		key := fmt.Sprintf("%s_%d", keyPrefix, i)
-		if _, err := store.Get([]byte(key)); err == nil {
+		if _, found, err := store.Get([]byte(key)); err != nil {
+			t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
+		} else if found[0] {
 			t.Fatalf("Expected missing key. Key: %s", key)
 		}
 	}
@@ -466,7 +792,7 @@ func noKeys(t *testing.T, numKeys int, keyPrefix string) {
 func getKeys(t *testing.T, numKeys int, keyPrefix, valPrefix string) {
 	for i := 1; i <= numKeys; i++ {
 		key, expectedValue := fmt.Sprintf("%s_%d", keyPrefix, i), fmt.Sprintf("%s_%d", valPrefix, i)
-		if readResults, err := store.Get([]byte(key)); err != nil {
+		if readResults, _, err := store.Get([]byte(key)); err != nil {
 			t.Errorf("Unable to GET. Key: %s, Error: %v", key, err)
 		} else if string(readResults[0]) != expectedValue {
 			t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expectedValue, readResults[0])
@@ -481,7 +807,7 @@ func putKeys(t testing.TB, numKeys int, keyPrefix, valPrefix string) map[string]
 		if err := store.Put([]byte(k), []byte(v)); err != nil {
 			t.Fatal(err)
 		} else {
-			if readResults, err := store.Get([]byte(k)); err != nil {
+			if readResults, _, err := store.Get([]byte(k)); err != nil {
 				t.Fatal(err)
 			} else if string(readResults[0]) != string(v) {
 				t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", k, v, readResults[0])
@@ -495,8 +821,10 @@ func putKeys(t testing.TB, numKeys int, keyPrefix, valPrefix string) map[string]
 
 func checkMissingGetResults(t *testing.T, ks [][]byte) {
 	for _, k := range ks {
-		if result, err := store.Get(k); err == nil {
-			t.Errorf("Expected missing entry for key: %s. But instead found value: %s", k, result)
+		if result, found, err := store.Get(k); err != nil {
+			t.Errorf("Unable to GET. Key: %s, Error: %v", k, err)
+		} else if found[0] {
+			t.Errorf("Expected missing entry for key: %s. But instead found value: %s", k, result[0])
 		}
 	}
 }
@@ -0,0 +1,342 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+// A ChangeIterator represents the capability of a ChangePropagator to
+// read its changes one at a time, so that serving a large batch of them
+// does not require holding all of them in memory at once.
+type ChangeIterator interface {
+	// IterateChanges passes the changes committed since the given
+	// change number onto fn one at a time, until either maxChanges
+	// of them are passed or fn returns false. Fails like LoadChanges
+	// otherwise.
+	IterateChanges(fromChangeNumber uint64, maxChanges int, fn func(*serverpb.ChangeRecord) bool) error
+}
+
+// Number of changes loaded at once by IterateChanges from the stores
+// that are not ChangeIterators.
+const changesLoadBatchSize = 8
+
+// IterateChanges passes the changes of the given ChangePropagator onto
+// fn as per ChangeIterator.IterateChanges. The changes of stores that
+// are not ChangeIterators are loaded a few at a time instead.
+func IterateChanges(cp ChangePropagator, fromChangeNumber uint64, maxChanges int, fn func(*serverpb.ChangeRecord) bool) error {
+	if ci, ok := cp.(ChangeIterator); ok {
+		return ci.IterateChanges(fromChangeNumber, maxChanges, fn)
+	}
+	for maxChanges > 0 {
+		batchSize := changesLoadBatchSize
+		if batchSize > maxChanges {
+			batchSize = maxChanges
+		}
+		chngs, err := cp.LoadChanges(fromChangeNumber, batchSize)
+		if err != nil || len(chngs) == 0 {
+			return err
+		}
+		for _, chng := range chngs {
+			if !fn(chng) {
+				return nil
+			}
+		}
+		lastChngNum := chngs[len(chngs)-1].ChangeNumber
+		if lastChngNum < fromChangeNumber {
+			return nil
+		}
+		fromChangeNumber, maxChanges = lastChngNum+1, maxChanges-len(chngs)
+	}
+	return nil
+}
+
+// LoadChangesResponse serves the given request for changes from the
+// given ChangePropagator, as the GetChanges method of DKVReplication.
+// Changes are filtered by the namespace and the key prefix requested,
+// and limited by the number of bytes requested, with NextChangeNumber
+// conveying where the subsequent changes begin. ErrChangesUnavailable
+// is conveyed through the status of the response, upon which slaves
+// bootstrap themselves from a checkpoint.
+func LoadChangesResponse(cp ChangePropagator, getChngsReq *serverpb.GetChangesRequest) *serverpb.GetChangesResponse {
+	res, _ := StreamChangesResponse(cp, getChngsReq, 0, nil)
+	return res
+}
+
+// StreamChangesResponse serves the given request for changes as does
+// LoadChangesResponse, reading the changes one at a time so that no
+// more of them are held in memory than fit within a response. Given a
+// flush function, as for the StreamChanges method of DKVReplication,
+// the changes read are flushed onto it in responses of up to flushSize
+// bytes instead of being limited to a single response, leaving the rest
+// of them to the response returned. Fails only with the errors of flush.
+//
+// A change that does not fit within a response by itself is served in
+// chunks if the request is Chunked, one chunk per response, and fails
+// the request with ErrTooLarge otherwise.
+func StreamChangesResponse(cp ChangePropagator, getChngsReq *serverpb.GetChangesRequest, flushSize uint64, flush func(*serverpb.GetChangesResponse) error) (*serverpb.GetChangesResponse, error) {
+	latestChngNum, _ := cp.GetLatestCommittedChangeNumber()
+	newRes := func() *serverpb.GetChangesResponse {
+		return &serverpb.GetChangesResponse{Status: &serverpb.Status{}, MasterChangeNumber: latestChngNum}
+	}
+	res := newRes()
+	if getChngsReq.FromChangeNumber > latestChngNum {
+		return res, nil
+	}
+
+	keyPrefix := getChngsReq.KeyPrefix
+	if len(keyPrefix) > 0 && getChngsReq.Namespace != "" {
+		keyPrefix, _ = NamespacedKey(getChngsReq.Namespace, keyPrefix)
+	}
+	// Responses are limited by the size of their changes, leaving room
+	// for their other fields at their largest
+	resLimit := uint64(math.MaxUint64)
+	if getChngsReq.MaxResponseSize > 0 {
+		hdr := &serverpb.GetChangesResponse{Status: res.Status, MasterChangeNumber: latestChngNum, NumberOfChanges: math.MaxUint32, NextChangeNumber: math.MaxUint64, NextChunkOffset: math.MaxUint64}
+		if hdrSize := uint64(proto.Size(hdr)); getChngsReq.MaxResponseSize > hdrSize {
+			resLimit = getChngsReq.MaxResponseSize - hdrSize
+		} else {
+			resLimit = 0
+		}
+	}
+	if flush != nil && flushSize > 0 && flushSize < resLimit {
+		resLimit = flushSize
+	}
+
+	var numChngs int
+	var numBytes, resSize uint64
+	var limitErr, flushErr error
+	err := IterateChanges(cp, getChngsReq.FromChangeNumber, int(getChngsReq.MaxNumberOfChanges), func(chng *serverpb.ChangeRecord) bool {
+		numChngs++
+		chngNum := chng.ChangeNumber
+		if getChngsReq.Namespace != "" {
+			chng = FilterChanges(getChngsReq.Namespace, []*serverpb.ChangeRecord{chng})[0]
+		}
+		if len(keyPrefix) > 0 {
+			prefixChngs := FilterChangesByKeyPrefix(keyPrefix, []*serverpb.ChangeRecord{chng})
+			if len(prefixChngs) == 0 {
+				res.NextChangeNumber = chngNum + 1
+				return true
+			}
+			chng = prefixChngs[0]
+		}
+
+		// Changes that follow are retrieved next, skipped or not
+		chngSize := uint64(proto.Size(chng))
+		if getChngsReq.MaxNumberOfBytes > 0 && numBytes > 0 && numBytes+chngSize > getChngsReq.MaxNumberOfBytes {
+			return false
+		}
+		// Besides the change, its tag and length are sent as well
+		fieldSize := chngSize + 1 + uint64(proto.SizeVarint(chngSize))
+		if len(res.Changes) > 0 && resSize+fieldSize > resLimit {
+			if flush == nil {
+				return false
+			}
+			if flushErr = flush(res); flushErr != nil {
+				return false
+			}
+			res, resSize = newRes(), 0
+		}
+		if fieldSize > resLimit {
+			if !getChngsReq.Chunked {
+				limitErr = fmt.Errorf("change %d of %d bytes %w of a response, %d bytes", chngNum, chngSize, dkverrors.ErrTooLarge, getChngsReq.MaxResponseSize)
+				return false
+			}
+			var fromOffset uint64
+			if chngNum == getChngsReq.FromChangeNumber {
+				fromOffset = getChngsReq.FromChunkOffset
+			}
+			var chunks []*serverpb.ChangeRecord
+			if chunks, limitErr = chunkChange(chng, fromOffset, resLimit); limitErr != nil {
+				return false
+			}
+			numBytes += chngSize
+			for _, chunk := range chunks {
+				if len(res.Changes) > 0 {
+					if flush == nil {
+						return false
+					}
+					if flushErr = flush(res); flushErr != nil {
+						return false
+					}
+					res = newRes()
+				}
+				res.Changes, res.NumberOfChanges = []*serverpb.ChangeRecord{chunk}, 1
+				res.NextChangeNumber, res.NextChunkOffset = chngNum, chunk.ChunkOffset+uint64(len(chunk.Chunk))
+				if res.NextChunkOffset == chunk.ChunkedSize {
+					res.NextChangeNumber, res.NextChunkOffset = chngNum+1, 0
+				}
+			}
+			// Changes that follow are left to the next response
+			resSize = resLimit
+			return true
+		}
+		res.Changes = append(res.Changes, chng)
+		res.NumberOfChanges, res.NextChangeNumber = uint32(len(res.Changes)), chngNum+1
+		numBytes, resSize = numBytes+chngSize, resSize+fieldSize
+		return true
+	})
+	switch {
+	case flushErr != nil:
+		return nil, flushErr
+	case limitErr != nil:
+		return &serverpb.GetChangesResponse{Status: dkverrors.NewStatus(limitErr), MasterChangeNumber: latestChngNum}, nil
+	// Changes already read are served, leaving the error to the next call
+	case err != nil && numChngs == 0:
+		res.Status = dkverrors.NewStatus(err)
+	}
+	return res, nil
+}
+
+// chunkChange splits the marshalled form of the given change into chunk
+// records from the given offset onwards, each of which fits within a
+// response limited to limitSize bytes of changes by itself. Chunks are
+// split at the same offsets regardless of the limit, so that those that
+// follow can be retrieved under another limit.
+func chunkChange(chng *serverpb.ChangeRecord, fromOffset, limitSize uint64) ([]*serverpb.ChangeRecord, error) {
+	data, err := proto.Marshal(chng)
+	if err != nil {
+		return nil, err
+	}
+	size := uint64(len(data))
+	if fromOffset >= size {
+		return nil, fmt.Errorf("offset %d is beyond change %d of %d bytes: %w", fromOffset, chng.ChangeNumber, size, dkverrors.ErrInvalidArgument)
+	}
+	// Besides the chunk, the tags and lengths of the chunk and of the
+	// record are sent along with its other fields
+	hdr := &serverpb.ChangeRecord{ChangeNumber: chng.ChangeNumber, ChunkedSize: size, ChunkOffset: size}
+	overhead := uint64(proto.Size(hdr) + 2*(1+proto.SizeVarint(limitSize)))
+	if limitSize < overhead+minChunkSize {
+		return nil, fmt.Errorf("change %d of %d bytes %w of a response, even in chunks", chng.ChangeNumber, size, dkverrors.ErrTooLarge)
+	}
+	maxChunkSize := (limitSize - overhead) / minChunkSize * minChunkSize
+	var chunks []*serverpb.ChangeRecord
+	for offset := fromOffset; offset < size; {
+		// Chunks end at the multiples of minChunkSize
+		end := (offset/minChunkSize)*minChunkSize + maxChunkSize
+		if end > size {
+			end = size
+		}
+		chunks = append(chunks, &serverpb.ChangeRecord{ChangeNumber: chng.ChangeNumber, ChunkedSize: size, ChunkOffset: offset, Chunk: data[offset:end]})
+		offset = end
+	}
+	return chunks, nil
+}
+
+// Chunks of the changes are sized in multiples of the following size.
+const minChunkSize = 64 << 10
+
+// A ChangeAssembler reassembles the changes served in chunks, as per the
+// Chunked requests for changes, from their chunk records.
+type ChangeAssembler struct {
+	chngNum uint64
+	data    []byte
+	size    uint64
+}
+
+// NextChunkOffset returns the offset within the given change from which
+// its chunks are to be retrieved, which is positive only if some of its
+// chunks are already assembled.
+func (ca *ChangeAssembler) NextChunkOffset(chngNum uint64) uint64 {
+	if chngNum != ca.chngNum {
+		return 0
+	}
+	return uint64(len(ca.data))
+}
+
+// Assemble returns the given changes with their chunk records replaced
+// by the changes reassembled from them, retaining the chunks of the last
+// change until all of its chunks are assembled. Chunks are expected in
+// order, with those not following the chunks assembled failing with
+// ErrMalformedResponse along with the changes preceding them.
+func (ca *ChangeAssembler) Assemble(chngs []*serverpb.ChangeRecord) ([]*serverpb.ChangeRecord, error) {
+	var assembled []*serverpb.ChangeRecord
+	for i, chng := range chngs {
+		if chng.ChunkedSize == 0 {
+			if assembled != nil {
+				assembled = append(assembled, chng)
+			}
+			continue
+		}
+		if assembled == nil {
+			assembled = make([]*serverpb.ChangeRecord, i, len(chngs))
+			copy(assembled, chngs)
+		}
+		// Chunks are retrieved afresh from the beginning, like upon
+		// resuming from another node
+		if chng.ChunkOffset == 0 {
+			ca.chngNum, ca.data, ca.size = chng.ChangeNumber, nil, chng.ChunkedSize
+		}
+		if chng.ChangeNumber != ca.chngNum || chng.ChunkedSize != ca.size || chng.ChunkOffset != uint64(len(ca.data)) || chng.ChunkOffset+uint64(len(chng.Chunk)) > ca.size {
+			err := fmt.Errorf("chunk at offset %d of change %d does not follow the %d bytes of change %d assembled: %w", chng.ChunkOffset, chng.ChangeNumber, len(ca.data), ca.chngNum, dkverrors.ErrMalformedResponse)
+			ca.Reset()
+			return assembled, err
+		}
+		ca.data = append(ca.data, chng.Chunk...)
+		if uint64(len(ca.data)) < ca.size {
+			continue
+		}
+		whole := &serverpb.ChangeRecord{}
+		err := proto.Unmarshal(ca.data, whole)
+		ca.Reset()
+		if err != nil || whole.ChangeNumber != chng.ChangeNumber {
+			return assembled, fmt.Errorf("change %d assembled from its chunks is invalid: %v: %w", chng.ChangeNumber, err, dkverrors.ErrMalformedResponse)
+		}
+		assembled = append(assembled, whole)
+	}
+	if assembled == nil {
+		return chngs, nil
+	}
+	return assembled, nil
+}
+
+// Reset discards the chunks assembled so far.
+func (ca *ChangeAssembler) Reset() {
+	ca.chngNum, ca.data, ca.size = 0, nil, 0
+}
+
+// LimitChangesResponse drops the trailing changes of the given response
+// until it fits within maxSize bytes once marshalled, so that clients
+// are not sent responses larger than they can receive. NextChangeNumber
+// then conveys where the dropped changes begin, for these to be retrieved
+// next. Fails with ErrTooLarge if not even the first change fits.
+func LimitChangesResponse(res *serverpb.GetChangesResponse, maxSize uint64) error {
+	size := uint64(proto.Size(res))
+	if size <= maxSize {
+		return nil
+	}
+	chngs, numChngs := res.Changes, len(res.Changes)
+	for numChngs > 0 && size > maxSize {
+		// Besides the change, its tag and length are dropped as well
+		numChngs--
+		chngSize := uint64(proto.Size(chngs[numChngs]))
+		size -= chngSize + 1 + uint64(proto.SizeVarint(chngSize))
+	}
+	// Other fields, like NextChangeNumber, may grow once the changes are
+	// dropped, hence the response is sized once again
+	for ; numChngs > 0; numChngs-- {
+		res.Changes, res.NumberOfChanges = chngs[:numChngs], uint32(numChngs)
+		res.NextChangeNumber = chngs[numChngs-1].ChangeNumber + 1
+		if uint64(proto.Size(res)) <= maxSize {
+			return nil
+		}
+	}
+	return fmt.Errorf("change %d of %d bytes %w of a response, %d bytes", chngs[0].ChangeNumber, proto.Size(chngs[0]), dkverrors.ErrTooLarge, maxSize)
+}
+
+// LimitChangesBySize retains the longest prefix of the given changes
+// whose total size does not exceed maxNumBytes, while always retaining
+// the first change so that progress can be made.
+func LimitChangesBySize(chngs []*serverpb.ChangeRecord, maxNumBytes uint64) []*serverpb.ChangeRecord {
+	var numBytes uint64
+	for i, chng := range chngs {
+		numBytes += uint64(proto.Size(chng))
+		if numBytes > maxNumBytes && i > 0 {
+			return chngs[:i]
+		}
+	}
+	return chngs
+}
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+func TestLimitChangesBySize(t *testing.T) {
+	var chngs []*serverpb.ChangeRecord
+	for i := 1; i <= 5; i++ {
+		trxn := &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: []byte("key"), Value: make([]byte, 100)}
+		chngs = append(chngs, &serverpb.ChangeRecord{ChangeNumber: uint64(i), NumberOfTrxns: 1, Trxns: []*serverpb.TrxnRecord{trxn}})
+	}
+	chngSize := uint64(proto.Size(chngs[0]))
+	for maxNumBytes, expNumChngs := range map[uint64]int{1: 1, chngSize: 1, 3*chngSize + 1: 3, 10 * chngSize: 5} {
+		if numChngs := len(LimitChangesBySize(chngs, maxNumBytes)); numChngs != expNumChngs {
+			t.Errorf("Change count mismatch for a limit of %d bytes. Expected: %d, Actual: %d", maxNumBytes, expNumChngs, numChngs)
+		}
+	}
+}
+
+func TestLimitChangesResponse(t *testing.T) {
+	newRes := func() *serverpb.GetChangesResponse {
+		res := &serverpb.GetChangesResponse{Status: &serverpb.Status{}, MasterChangeNumber: 5, NumberOfChanges: 5, NextChangeNumber: 6}
+		for i := 1; i <= 5; i++ {
+			trxn := &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: []byte("key"), Value: make([]byte, 3<<20)}
+			res.Changes = append(res.Changes, &serverpb.ChangeRecord{ChangeNumber: uint64(i), NumberOfTrxns: 1, Trxns: []*serverpb.TrxnRecord{trxn}})
+		}
+		return res
+	}
+	for maxSize, expNumChngs := range map[uint64]int{4 << 20: 1, 10 << 20: 3, 64 << 20: 5} {
+		res := newRes()
+		if err := LimitChangesResponse(res, maxSize); err != nil {
+			t.Fatalf("Unable to limit the response to %d bytes. Error: %v", maxSize, err)
+		}
+		if len(res.Changes) != expNumChngs || res.NumberOfChanges != uint32(expNumChngs) || res.NextChangeNumber != uint64(expNumChngs+1) {
+			t.Errorf("Expected %d changes for a limit of %d bytes. Actual: %d changes, next change number: %d", expNumChngs, maxSize, len(res.Changes), res.NextChangeNumber)
+		}
+		if size := proto.Size(res); uint64(size) > maxSize {
+			t.Errorf("Expected the response to fit within %d bytes. Actual: %d", maxSize, size)
+		}
+	}
+
+	if err := LimitChangesResponse(newRes(), 1<<20); !errors.Is(err, dkverrors.ErrTooLarge) {
+		t.Errorf("Expected error: %v. Actual: %v", dkverrors.ErrTooLarge, err)
+	}
+}
+
+// lazyChangeLog creates its changes with large values as they are
+// loaded, counting the changes loaded
+type lazyChangeLog struct {
+	numChngs  uint64
+	valSize   int
+	numLoaded int
+}
+
+func (lcl *lazyChangeLog) GetLatestCommittedChangeNumber() (uint64, error) {
+	return lcl.numChngs, nil
+}
+
+func (lcl *lazyChangeLog) LoadChanges(fromChangeNumber uint64, maxChanges int) ([]*serverpb.ChangeRecord, error) {
+	var chngs []*serverpb.ChangeRecord
+	for chngNum := fromChangeNumber; chngNum <= lcl.numChngs && len(chngs) < maxChanges; chngNum++ {
+		trxn := &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: []byte(fmt.Sprintf("K%d", chngNum)), Value: make([]byte, lcl.valSize)}
+		chngs = append(chngs, &serverpb.ChangeRecord{ChangeNumber: chngNum, NumberOfTrxns: 1, Trxns: []*serverpb.TrxnRecord{trxn}})
+	}
+	lcl.numLoaded += len(chngs)
+	return chngs, nil
+}
+
+func TestLoadChangesResponse(t *testing.T) {
+	// Loaded at once, the changes requested would take up 20GB
+	chngLog := &lazyChangeLog{numChngs: 10000, valSize: 2 << 20}
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	allocBefore := memStats.TotalAlloc
+	res := LoadChangesResponse(chngLog, &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 10000, MaxResponseSize: 16 << 20})
+	runtime.ReadMemStats(&memStats)
+	if alloc := memStats.TotalAlloc - allocBefore; alloc > 64<<20 {
+		t.Errorf("Expected the changes to be loaded within 64MB. Allocated: %d bytes", alloc)
+	}
+	if res.Status.Code != 0 || len(res.Changes) != 7 || res.NumberOfChanges != 7 || res.NextChangeNumber != 8 {
+		t.Errorf("Expected 7 changes within 16MB. Actual: %d changes, next change number: %d, status: %v", len(res.Changes), res.NextChangeNumber, res.Status)
+	}
+	if size := proto.Size(res); size > 16<<20 {
+		t.Errorf("Expected the response to fit within 16MB. Actual: %d", size)
+	}
+	if chngLog.numLoaded > 2*changesLoadBatchSize {
+		t.Errorf("Expected at most %d changes to be loaded. Actual: %d", 2*changesLoadBatchSize, chngLog.numLoaded)
+	}
+
+	res = LoadChangesResponse(chngLog, &serverpb.GetChangesRequest{FromChangeNumber: 8, MaxNumberOfChanges: 10000, MaxNumberOfBytes: 5 << 20})
+	if len(res.Changes) != 2 || res.Changes[0].ChangeNumber != 8 || res.NextChangeNumber != 10 {
+		t.Errorf("Expected 2 changes within 5MB from change 8. Actual: %d changes, next change number: %d", len(res.Changes), res.NextChangeNumber)
+	}
+	res = LoadChangesResponse(chngLog, &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 10000, KeyPrefix: []byte("K2"), MaxResponseSize: 16 << 20})
+	if len(res.Changes) != 7 || res.Changes[0].ChangeNumber != 2 || res.Changes[1].ChangeNumber != 20 || res.NextChangeNumber != 26 {
+		t.Errorf("Expected 7 changes of keys with prefix K2. Actual: %d changes, next change number: %d", len(res.Changes), res.NextChangeNumber)
+	}
+	res = LoadChangesResponse(chngLog, &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 10000, MaxResponseSize: 1 << 20})
+	if res.Status.Code == 0 || len(res.Changes) > 0 {
+		t.Errorf("Expected the change larger than the response to fail. Status: %v", res.Status)
+	}
+}
+
+func TestStreamChangesResponse(t *testing.T) {
+	chngLog := &lazyChangeLog{numChngs: 100, valSize: 2 << 20}
+	nextChngNum := uint64(1)
+	flush := func(res *serverpb.GetChangesResponse) error {
+		if len(res.Changes) == 0 || res.Changes[0].ChangeNumber != nextChngNum {
+			return fmt.Errorf("expected changes from change %d. Actual: %d changes", nextChngNum, len(res.Changes))
+		}
+		if size := proto.Size(res); size > 5<<20 {
+			return fmt.Errorf("expected the response to fit within 5MB. Actual: %d", size)
+		}
+		nextChngNum = res.NextChangeNumber
+		return nil
+	}
+	res, err := StreamChangesResponse(chngLog, &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 100, MaxResponseSize: 16 << 20}, 5<<20, flush)
+	if err == nil {
+		err = flush(res)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextChngNum != 101 {
+		t.Errorf("Expected all the changes to be streamed. Next change number: %d", nextChngNum)
+	}
+
+	flushErr := errors.New("stream closed")
+	if _, err = StreamChangesResponse(chngLog, &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 100}, 5<<20, func(*serverpb.GetChangesResponse) error { return flushErr }); err != flushErr {
+		t.Errorf("Expected error: %v. Actual: %v", flushErr, err)
+	}
+}
+
+// changeSlice serves the given changes, numbered from 1
+type changeSlice []*serverpb.ChangeRecord
+
+func (cs changeSlice) GetLatestCommittedChangeNumber() (uint64, error) {
+	return uint64(len(cs)), nil
+}
+
+func (cs changeSlice) LoadChanges(fromChangeNumber uint64, maxChanges int) ([]*serverpb.ChangeRecord, error) {
+	chngs := cs[fromChangeNumber-1:]
+	if len(chngs) > maxChanges {
+		chngs = chngs[:maxChanges]
+	}
+	return chngs, nil
+}
+
+func TestChunkedChangesResponse(t *testing.T) {
+	valSizes := []int{0, 4 << 20, 10, 64 << 20, 0}
+	var chngLog changeSlice
+	for i, valSize := range valSizes {
+		val := make([]byte, valSize)
+		for j := range val {
+			val[j] = byte(i + j)
+		}
+		trxns := []*serverpb.TrxnRecord{{Type: serverpb.TrxnRecord_Put, Key: []byte(fmt.Sprintf("K%d", i+1)), Value: val}}
+		chngLog = append(chngLog, &serverpb.ChangeRecord{ChangeNumber: uint64(i + 1), NumberOfTrxns: 1, Trxns: trxns, Checksum: ChangeChecksum(trxns)})
+	}
+	expectChanges := func(chngs []*serverpb.ChangeRecord) {
+		if len(chngs) != len(chngLog) {
+			t.Fatalf("Expected %d changes to be assembled. Actual: %d", len(chngLog), len(chngs))
+		}
+		for i, chng := range chngs {
+			trxn := chng.Trxns[0]
+			if err := VerifyChecksum(chng); err != nil || chng.ChangeNumber != uint64(i+1) || trxn.Type != serverpb.TrxnRecord_Put || len(trxn.Value) != valSizes[i] {
+				t.Errorf("Expected change %d with a value of %d bytes. Actual: change %d of type %v with %d bytes, error: %v", i+1, valSizes[i], chng.ChangeNumber, trxn.Type, len(trxn.Value), err)
+			}
+		}
+	}
+
+	// Changes are retrieved one response at a time, with the limit
+	// growing midway through the chunks of the 64MB change
+	var assembler ChangeAssembler
+	var chngs []*serverpb.ChangeRecord
+	fromChngNum, maxResSize, numRes := uint64(1), uint64(4<<20), 0
+	for fromChngNum <= uint64(len(chngLog)) && numRes < 100 {
+		if numRes == 10 {
+			maxResSize = 16 << 20
+		}
+		req := &serverpb.GetChangesRequest{FromChangeNumber: fromChngNum, FromChunkOffset: assembler.NextChunkOffset(fromChngNum), MaxNumberOfChanges: 10, MaxResponseSize: maxResSize, Chunked: true}
+		res := LoadChangesResponse(chngLog, req)
+		if res.Status.Code != 0 {
+			t.Fatalf("Unable to load the changes from change %d. Status: %v", fromChngNum, res.Status)
+		}
+		if size := proto.Size(res); uint64(size) > maxResSize {
+			t.Errorf("Expected the response to fit within %d bytes. Actual: %d", maxResSize, size)
+		}
+		assembled, err := assembler.Assemble(res.Changes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chngs, fromChngNum, numRes = append(chngs, assembled...), res.NextChangeNumber, numRes+1
+	}
+	expectChanges(chngs)
+
+	chngs = nil
+	flush := func(res *serverpb.GetChangesResponse) error {
+		if size := proto.Size(res); size > 4<<20 {
+			return fmt.Errorf("expected the response to fit within 4MB. Actual: %d", size)
+		}
+		assembled, err := assembler.Assemble(res.Changes)
+		chngs = append(chngs, assembled...)
+		return err
+	}
+	res, err := StreamChangesResponse(chngLog, &serverpb.GetChangesRequest{FromChangeNumber: 1, MaxNumberOfChanges: 10, MaxResponseSize: 4 << 20, Chunked: true}, 16<<20, flush)
+	if err == nil {
+		err = flush(res)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectChanges(chngs)
+
+	// Chunks must follow those assembled
+	res = LoadChangesResponse(chngLog, &serverpb.GetChangesRequest{FromChangeNumber: 2, MaxNumberOfChanges: 10, MaxResponseSize: 1 << 20, Chunked: true})
+	if _, err = assembler.Assemble(res.Changes); err != nil {
+		t.Fatal(err)
+	}
+	res = LoadChangesResponse(chngLog, &serverpb.GetChangesRequest{FromChangeNumber: 4, FromChunkOffset: 1 << 20, MaxNumberOfChanges: 10, MaxResponseSize: 1 << 20, Chunked: true})
+	if _, err = assembler.Assemble(res.Changes); !errors.Is(err, dkverrors.ErrMalformedResponse) {
+		t.Errorf("Expected error: %v. Actual: %v", dkverrors.ErrMalformedResponse, err)
+	}
+	if offset := assembler.NextChunkOffset(2); offset != 0 {
+		t.Errorf("Expected the chunks assembled to be discarded. Next chunk offset: %d", offset)
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrChecksumMismatch indicates that the transactions of a change
+// do not match its checksum.
+var ErrChecksumMismatch = dkverrors.ErrChecksumMismatch
+
+// ChangeChecksum computes the CRC-32C of the given transactions, over
+// their types, keys, values and expiry times. It depends solely on the
+// transactions, so that it is the same regardless of the storage engine
+// that records them.
+func ChangeChecksum(trxns []*serverpb.TrxnRecord) uint32 {
+	crc := crc32.New(castagnoliTable)
+	writeTrxns(crc, trxns)
+	return crc.Sum32()
+}
+
+// writeTrxns writes the given transactions onto the given hash, with
+// every key and value preceded by its length so that their boundaries
+// are a part of the checksum.
+func writeTrxns(h hash.Hash32, trxns []*serverpb.TrxnRecord) {
+	buf := make([]byte, 1+3*binary.MaxVarintLen64)
+	for _, trxn := range trxns {
+		buf[0] = byte(trxn.Type)
+		n := 1 + binary.PutUvarint(buf[1:], trxn.ExpireTS)
+		n += binary.PutUvarint(buf[n:], uint64(len(trxn.Key)))
+		h.Write(buf[:n])
+		h.Write(trxn.Key)
+		n = binary.PutUvarint(buf, uint64(len(trxn.Value)))
+		h.Write(buf[:n])
+		h.Write(trxn.Value)
+	}
+}
+
+// VerifyChecksum verifies that the transactions of the given change
+// match its checksum, failing with ErrChecksumMismatch otherwise.
+// Changes without checksums are not verified.
+func VerifyChecksum(chng *serverpb.ChangeRecord) error {
+	if chng.Checksum == 0 {
+		return nil
+	}
+	if checksum := ChangeChecksum(chng.Trxns); checksum != chng.Checksum {
+		return fmt.Errorf("change %d carries checksum %#08x, whereas its transactions have %#08x: %w", chng.ChangeNumber, chng.Checksum, checksum, ErrChecksumMismatch)
+	}
+	return nil
+}
+
+// Number of changes loaded at once while computing range checksums.
+const rangeChecksumBatchSize = 1000
+
+// RangeChecksum computes a rolling CRC-32C over the transactions of all
+// the changes of the given ChangePropagator, whose change numbers lie
+// within the given range, both inclusive. Returns the checksum along
+// with the number of these transactions. Fails with ErrInvalidArgument
+// if the range extends beyond the latest committed change, and with
+// ErrChangesUnavailable if its changes are no longer retained.
+//
+// Since the checksum covers only the transactions, it is the same for
+// nodes whose changes are grouped differently, as long as the range
+// begins and ends on the boundaries of the changes of both the nodes.
+func RangeChecksum(cp ChangePropagator, fromChngNum, toChngNum uint64) (uint32, uint64, error) {
+	latestChngNum, err := cp.GetLatestCommittedChangeNumber()
+	switch {
+	case err != nil:
+		return 0, 0, err
+	case fromChngNum == 0 || fromChngNum > toChngNum:
+		return 0, 0, fmt.Errorf("invalid range of changes from %d to %d: %w", fromChngNum, toChngNum, dkverrors.ErrInvalidArgument)
+	case toChngNum > latestChngNum:
+		return 0, 0, fmt.Errorf("range of changes ends at %d beyond the latest change %d: %w", toChngNum, latestChngNum, dkverrors.ErrInvalidArgument)
+	}
+
+	crc, numTrxns := crc32.New(castagnoliTable), uint64(0)
+	for chngNum := fromChngNum; chngNum <= toChngNum; {
+		chngs, err := cp.LoadChanges(chngNum, rangeChecksumBatchSize)
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(chngs) == 0 {
+			break
+		}
+		for _, chng := range chngs {
+			if chng.ChangeNumber > toChngNum {
+				break
+			}
+			writeTrxns(crc, chng.Trxns)
+			numTrxns += uint64(len(chng.Trxns))
+			chngNum = chng.ChangeNumber + 1
+		}
+		if chngs[len(chngs)-1].ChangeNumber > toChngNum {
+			break
+		}
+	}
+	return crc.Sum32(), numTrxns, nil
+}
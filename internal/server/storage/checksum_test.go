@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// sliceChangeLog serves the given changes, which must be in order
+type sliceChangeLog []*serverpb.ChangeRecord
+
+func (scl sliceChangeLog) GetLatestCommittedChangeNumber() (uint64, error) {
+	return scl[len(scl)-1].ChangeNumber, nil
+}
+
+func (scl sliceChangeLog) LoadChanges(fromChngNum uint64, maxChngs int) ([]*serverpb.ChangeRecord, error) {
+	var chngs []*serverpb.ChangeRecord
+	for _, chng := range scl {
+		if chng.ChangeNumber >= fromChngNum && len(chngs) < maxChngs {
+			chngs = append(chngs, chng)
+		}
+	}
+	return chngs, nil
+}
+
+func put(key, value string) *serverpb.TrxnRecord {
+	return &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: []byte(key), Value: []byte(value)}
+}
+
+func TestChangeChecksum(t *testing.T) {
+	checksum := ChangeChecksum([]*serverpb.TrxnRecord{put("K1", "V1")})
+	others := map[string][]*serverpb.TrxnRecord{
+		"value":       {put("K1", "V2")},
+		"boundary":    {put("K1V", "1")},
+		"type":        {{Type: serverpb.TrxnRecord_Delete, Key: []byte("K1"), Value: []byte("V1")}},
+		"expiry":      {{Type: serverpb.TrxnRecord_Put, Key: []byte("K1"), Value: []byte("V1"), ExpireTS: 1}},
+		"transaction": {put("K1", "V1"), put("K2", "V2")},
+	}
+	for desc, trxns := range others {
+		if ChangeChecksum(trxns) == checksum {
+			t.Errorf("Expected the checksum to change with the %s", desc)
+		}
+	}
+
+	chng := &serverpb.ChangeRecord{ChangeNumber: 7, Trxns: []*serverpb.TrxnRecord{put("K1", "V1")}, Checksum: checksum}
+	if err := VerifyChecksum(chng); err != nil {
+		t.Errorf("Expected the checksum to match. Error: %v", err)
+	}
+	chng.Trxns[0].Value = []byte("V2")
+	if err := VerifyChecksum(chng); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected error: %v on a corrupted change. Actual: %v", ErrChecksumMismatch, err)
+	}
+	chng.Checksum = 0
+	if err := VerifyChecksum(chng); err != nil {
+		t.Errorf("Expected changes without checksums to not be verified. Error: %v", err)
+	}
+}
+
+func TestRangeChecksum(t *testing.T) {
+	master := sliceChangeLog{
+		{ChangeNumber: 1, Trxns: []*serverpb.TrxnRecord{put("K1", "V1")}},
+		{ChangeNumber: 2, Trxns: []*serverpb.TrxnRecord{put("K2", "V2"), put("K3", "V3")}},
+		{ChangeNumber: 4, Trxns: []*serverpb.TrxnRecord{put("K4", "V4")}},
+		{ChangeNumber: 5, Trxns: []*serverpb.TrxnRecord{put("K5", "V5")}},
+	}
+	// Changes are grouped differently, as by slaves applying them in batches
+	slave := sliceChangeLog{
+		{ChangeNumber: 1, Trxns: []*serverpb.TrxnRecord{put("K1", "V1"), put("K2", "V2"), put("K3", "V3")}},
+		{ChangeNumber: 4, Trxns: []*serverpb.TrxnRecord{put("K4", "V4")}},
+		{ChangeNumber: 5, Trxns: []*serverpb.TrxnRecord{put("K5", "V5")}},
+	}
+	mstrChecksum, mstrNumTrxns, err := RangeChecksum(master, 1, 4)
+	if err != nil {
+		t.Fatalf("Unable to compute the checksum. Error: %v", err)
+	}
+	if mstrNumTrxns != 4 {
+		t.Errorf("Expected 4 transactions within the range. Actual: %d", mstrNumTrxns)
+	}
+	if slvChecksum, slvNumTrxns, _ := RangeChecksum(slave, 1, 4); slvChecksum != mstrChecksum || slvNumTrxns != mstrNumTrxns {
+		t.Errorf("Expected the same checksum regardless of the grouping of changes. Master: %#08x, Slave: %#08x", mstrChecksum, slvChecksum)
+	}
+	if checksum, _, _ := RangeChecksum(master, 1, 5); checksum == mstrChecksum {
+		t.Error("Expected the checksum to change with the range")
+	}
+
+	for _, rng := range [][2]uint64{{0, 3}, {3, 2}, {1, 6}} {
+		if _, _, err := RangeChecksum(master, rng[0], rng[1]); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+			t.Errorf("Expected error: %v for the range %v. Actual: %v", dkverrors.ErrInvalidArgument, rng, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// MaxDigestFanOut is the maximum number of ranges into which
+// KeyspaceDigest can split a range of keys.
+const MaxDigestFanOut = 1024
+
+// MaxDigestListedKeys is the maximum number of keys whose digests
+// KeyspaceDigest can list at once.
+const MaxDigestListedKeys = 10000
+
+// KeyspaceDigest computes the digests of the consecutive ranges into
+// which the range of keys of the given request is split, along with the
+// digests of all its keys if they are to be listed. Keys are digested as
+// stored, i.e., across all the namespaces and with their values as they
+// are at rest. The range is split at the split keys of the request if
+// given, in which case every range is digested even if it has no keys.
+// Otherwise it is split into fan out ranges of nearly equal number of
+// keys, which requires the keys to be counted first.
+//
+// Every digest is computed over a single iteration of the keys, hence
+// over a snapshot of the keyspace as per the semantics of Iterate, while
+// holding no more than a digest per range in memory.
+func KeyspaceDigest(ctx context.Context, kvs KVStore, req *serverpb.KeyspaceDigestRequest) ([]*serverpb.KeyRangeDigest, []*serverpb.KeyDigest, error) {
+	if err := validateDigestRequest(req); err != nil {
+		return nil, nil, err
+	}
+	var numKeys uint64
+	if len(req.SplitKeys) == 0 {
+		var err error
+		if numKeys, err = countKeys(ctx, kvs, req.StartKey, req.EndKey); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	iter := iterateRange(ctx, kvs, req.StartKey, req.EndKey)
+	defer iter.Close()
+	var ranges []*serverpb.KeyRangeDigest
+	var keys []*serverpb.KeyDigest
+	rngDgst := newRangeDigester(req.StartKey)
+	splitKeys, fanOut := req.SplitKeys, uint64(req.FanOut)
+	for i := uint64(0); iter.HasNext(); i++ {
+		key, val := iter.Next()
+		if len(req.SplitKeys) > 0 {
+			for len(splitKeys) > 0 && bytes.Compare(key, splitKeys[0]) >= 0 {
+				ranges = append(ranges, rngDgst.finish(splitKeys[0]))
+				rngDgst = newRangeDigester(splitKeys[0])
+				splitKeys = splitKeys[1:]
+			}
+		} else if i > 0 && evenRange(i, numKeys, fanOut) != evenRange(i-1, numKeys, fanOut) {
+			splitKey := append([]byte(nil), key...)
+			ranges = append(ranges, rngDgst.finish(splitKey))
+			rngDgst = newRangeDigester(splitKey)
+		}
+		rngDgst.add(key, val, iter.ExpireTS())
+		if req.ListKeys {
+			if len(keys) >= MaxDigestListedKeys {
+				return nil, nil, fmt.Errorf("range has more than %d keys to list: %w", MaxDigestListedKeys, dkverrors.ErrInvalidArgument)
+			}
+			keys = append(keys, &serverpb.KeyDigest{Key: append([]byte(nil), key...), Digest: valueDigest(val, iter.ExpireTS())})
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, nil, err
+	}
+	for _, splitKey := range splitKeys {
+		ranges = append(ranges, rngDgst.finish(splitKey))
+		rngDgst = newRangeDigester(splitKey)
+	}
+	ranges = append(ranges, rngDgst.finish(req.EndKey))
+	return ranges, keys, nil
+}
+
+func validateDigestRequest(req *serverpb.KeyspaceDigestRequest) error {
+	if len(req.EndKey) > 0 && bytes.Compare(req.StartKey, req.EndKey) >= 0 {
+		return fmt.Errorf("start key must precede the end key: %w", dkverrors.ErrInvalidArgument)
+	}
+	if len(req.SplitKeys) == 0 {
+		if req.FanOut == 0 || req.FanOut > MaxDigestFanOut {
+			return fmt.Errorf("fan out must be between 1 and %d: %w", MaxDigestFanOut, dkverrors.ErrInvalidArgument)
+		}
+		return nil
+	}
+	if len(req.SplitKeys) >= MaxDigestFanOut {
+		return fmt.Errorf("atmost %d split keys can be given: %w", MaxDigestFanOut-1, dkverrors.ErrInvalidArgument)
+	}
+	prevKey := req.StartKey
+	for _, splitKey := range req.SplitKeys {
+		if len(splitKey) == 0 || bytes.Compare(prevKey, splitKey) >= 0 {
+			return fmt.Errorf("split keys must be in ascending order after the start key: %w", dkverrors.ErrInvalidArgument)
+		}
+		prevKey = splitKey
+	}
+	if len(req.EndKey) > 0 && bytes.Compare(prevKey, req.EndKey) >= 0 {
+		return fmt.Errorf("split keys must precede the end key: %w", dkverrors.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// evenRange returns the index of the range of the key at the given index,
+// when the given number of keys are split evenly into fan out ranges. Keys
+// beyond the number of keys, like those written since they were counted,
+// belong to the last range.
+func evenRange(keyIdx, numKeys, fanOut uint64) uint64 {
+	if keyIdx >= numKeys {
+		return fanOut - 1
+	}
+	return keyIdx * fanOut / numKeys
+}
+
+func countKeys(ctx context.Context, kvs KVStore, startKey, endKey []byte) (uint64, error) {
+	iter := iterateRange(ctx, kvs, startKey, endKey)
+	defer iter.Close()
+	var numKeys uint64
+	for iter.HasNext() {
+		iter.Next()
+		numKeys++
+	}
+	return numKeys, iter.Err()
+}
+
+// iterateRange iterates over the keys from the given start key upto,
+// but excluding, the given end key, which is unbounded if empty.
+func iterateRange(ctx context.Context, kvs KVStore, startKey, endKey []byte) Iterator {
+	iter := NewContextIterator(ctx, kvs.Iterate(nil, startKey))
+	if len(endKey) == 0 {
+		return iter
+	}
+	return &rangeIter{Iterator: iter, endKey: endKey}
+}
+
+type rangeIter struct {
+	Iterator
+	endKey []byte
+	key    []byte
+	val    []byte
+	peeked bool
+	done   bool
+}
+
+func (ri *rangeIter) HasNext() bool {
+	if !ri.peeked && !ri.done {
+		if !ri.Iterator.HasNext() {
+			ri.done = true
+		} else if ri.key, ri.val = ri.Iterator.Next(); bytes.Compare(ri.key, ri.endKey) >= 0 {
+			ri.done = true
+		} else {
+			ri.peeked = true
+		}
+	}
+	return ri.peeked
+}
+
+func (ri *rangeIter) Next() ([]byte, []byte) {
+	ri.HasNext()
+	ri.peeked = false
+	return ri.key, ri.val
+}
+
+type rangeDigester struct {
+	startKey []byte
+	sha      hash.Hash
+	numKeys  uint64
+	buf      []byte
+}
+
+func newRangeDigester(startKey []byte) *rangeDigester {
+	return &rangeDigester{startKey: startKey, sha: sha256.New(), buf: make([]byte, binary.MaxVarintLen64)}
+}
+
+// add digests the given entry, with its key and value preceded
+// by their lengths so that their boundaries are digested too.
+func (rd *rangeDigester) add(key, val []byte, expireTS uint64) {
+	rd.sha.Write(rd.buf[:binary.PutUvarint(rd.buf, uint64(len(key)))])
+	rd.sha.Write(key)
+	writeValue(rd.sha, rd.buf, val, expireTS)
+	rd.numKeys++
+}
+
+func (rd *rangeDigester) finish(endKey []byte) *serverpb.KeyRangeDigest {
+	return &serverpb.KeyRangeDigest{StartKey: rd.startKey, EndKey: endKey, Digest: rd.sha.Sum(nil), NumberOfKeys: rd.numKeys}
+}
+
+func valueDigest(val []byte, expireTS uint64) []byte {
+	sha := sha256.New()
+	writeValue(sha, make([]byte, binary.MaxVarintLen64), val, expireTS)
+	return sha.Sum(nil)
+}
+
+func writeValue(h hash.Hash, buf, val []byte, expireTS uint64) {
+	h.Write(buf[:binary.PutUvarint(buf, uint64(len(val)))])
+	h.Write(val)
+	h.Write(buf[:binary.PutUvarint(buf, expireTS)])
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// ErrInvalidKey indicates that the key violates its KeyPolicy.
+var ErrInvalidKey = dkverrors.ErrInvalidKey
+
+// A KeyPolicy captures the constraints that the keys being stored must
+// satisfy, beyond their SizeLimits. The zero KeyPolicy permits every key.
+type KeyPolicy struct {
+	// MaxLength is the maximum length, in bytes, of the keys. Zero
+	// indicates that there is no limit.
+	MaxLength int
+	// RejectEmpty rejects the empty keys.
+	RejectEmpty bool
+	// UTF8 rejects the keys that are not valid UTF-8 strings.
+	UTF8 bool
+	// Pattern, if set, must match every key. Since it matches keys
+	// partially, it must be anchored for matching keys as a whole.
+	Pattern *regexp.Regexp
+	// Validate, if set, is called on every key satisfying the other
+	// constraints, rejecting the key when it returns an error.
+	Validate func(key []byte) error
+}
+
+// Check ensures that the given key satisfies this policy. The returned
+// error wraps ErrInvalidKey.
+func (kp KeyPolicy) Check(key []byte) error {
+	switch {
+	case kp.RejectEmpty && len(key) == 0:
+		return fmt.Errorf("%w - key is empty", ErrInvalidKey)
+	case kp.MaxLength > 0 && len(key) > kp.MaxLength:
+		return fmt.Errorf("%w - length: %d bytes, limit: %d bytes", ErrInvalidKey, len(key), kp.MaxLength)
+	case kp.UTF8 && !utf8.Valid(key):
+		return fmt.Errorf("%w - key is not valid UTF-8", ErrInvalidKey)
+	case kp.Pattern != nil && !kp.Pattern.Match(key):
+		return fmt.Errorf("%w - key does not match the pattern: %s", ErrInvalidKey, kp.Pattern)
+	}
+	if kp.Validate != nil {
+		if err := kp.Validate(key); err != nil {
+			return fmt.Errorf("%w - %v", ErrInvalidKey, err)
+		}
+	}
+	return nil
+}
+
+// KeyPolicies captures the KeyPolicy of every namespace. The zero
+// KeyPolicies permit every key.
+type KeyPolicies struct {
+	// Default is the policy of the namespaces absent in Namespaces,
+	// including the default namespace unless it is present.
+	Default KeyPolicy
+	// Namespaces holds the policies of specific namespaces, which
+	// replace the Default policy rather than adding to it.
+	Namespaces map[string]KeyPolicy
+}
+
+// Check ensures that the given key satisfies the policy of the given
+// namespace. The returned error wraps ErrInvalidKey.
+func (kps KeyPolicies) Check(namespace string, key []byte) error {
+	if kp, present := kps.Namespaces[namespace]; present {
+		return kp.Check(key)
+	}
+	return kps.Default.Check(key)
+}
+
+// CheckTxn ensures that every key mutated by the given transaction
+// satisfies the policy of its namespace.
+func (kps KeyPolicies) CheckTxn(txnReq *serverpb.TxnRequest) error {
+	for _, muts := range [][]*serverpb.TrxnRecord{txnReq.ThenMutations, txnReq.ElseMutations} {
+		for i, mut := range muts {
+			if err := kps.Check(txnReq.Namespace, mut.GetKey()); err != nil {
+				return fmt.Errorf("Txn mutation at index %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+func TestKeyPolicy(t *testing.T) {
+	errReserved := errors.New("reserved key")
+	kp := KeyPolicy{
+		MaxLength:   16,
+		RejectEmpty: true,
+		UTF8:        true,
+		Pattern:     regexp.MustCompile(`^/[a-z/]+$`),
+		Validate: func(key []byte) error {
+			if string(key) == "/reserved" {
+				return errReserved
+			}
+			return nil
+		},
+	}
+	for _, key := range []string{"/users", "/users/alice"} {
+		if err := kp.Check([]byte(key)); err != nil {
+			t.Errorf("Expected key: %q to be valid. Error: %v", key, err)
+		}
+	}
+	for _, key := range []string{"", "/users/alice/cart", "/us\xffers", "users", "/reserved"} {
+		if err := kp.Check([]byte(key)); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Expected key: %q to be invalid. Error: %v", key, err)
+		}
+	}
+	if err := (KeyPolicy{}).Check(nil); err != nil {
+		t.Errorf("Expected the zero policy to permit every key. Error: %v", err)
+	}
+}
+
+func TestKeyPoliciesOfNamespaces(t *testing.T) {
+	kps := KeyPolicies{
+		Default:    KeyPolicy{UTF8: true},
+		Namespaces: map[string]KeyPolicy{"raw": {MaxLength: 4}},
+	}
+	rawKey := []byte{0x0a, 0xff}
+	if err := kps.Check("", rawKey); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected the default policy to reject key: %q. Error: %v", rawKey, err)
+	}
+	if err := kps.Check("users", rawKey); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected the default policy to apply onto other namespaces. Error: %v", err)
+	}
+	if err := kps.Check("raw", rawKey); err != nil {
+		t.Errorf("Expected the policy of the namespace to replace the default one. Error: %v", err)
+	}
+	if err := kps.Check("raw", []byte("rawKey")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected the policy of the namespace to apply. Error: %v", err)
+	}
+
+	txnReq := &serverpb.TxnRequest{
+		Conditions:    []*serverpb.TxnCondition{{Key: rawKey, Type: serverpb.TxnCondition_Absent}},
+		ThenMutations: []*serverpb.TrxnRecord{{Key: []byte("valid"), Type: serverpb.TrxnRecord_Put}},
+	}
+	if err := kps.CheckTxn(txnReq); err != nil {
+		t.Errorf("Expected the keys of the conditions to be exempt. Error: %v", err)
+	}
+	txnReq.ElseMutations = []*serverpb.TrxnRecord{{Key: rawKey, Type: serverpb.TrxnRecord_Delete}}
+	if err := kps.CheckTxn(txnReq); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected the invalid key of the mutation to be rejected. Error: %v", err)
+	}
+}
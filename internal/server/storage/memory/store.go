@@ -0,0 +1,555 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// DB interface represents the capabilities exposed
+// by the underlying in-memory implementation.
+type DB interface {
+	storage.KVStore
+	storage.Backupable
+	storage.ChangePropagator
+	storage.ChangeApplier
+	storage.ReplicationStateKeeper
+	storage.StatsProvider
+	storage.BulkLoader
+	storage.ChangeLogTruncater
+}
+
+// DefaultMaxChangeLogSize is a reasonable number of latest changes
+// retained for replication by an in-memory store.
+const DefaultMaxChangeLogSize = 100000
+
+type memoryDB struct {
+	mu  sync.RWMutex
+	kvs map[string][]byte
+	// Expiry times of only those keys that expire
+	expireTSs map[string]uint64
+	// Change number of the latest committed or applied change
+	chngNum uint64
+	// Latest changes in the order of their change numbers
+	chngLog        []*serverpb.ChangeRecord
+	maxChngLogSize int
+	// Changes before this change number were truncated on demand
+	truncChngNum uint64
+	// State recorded by the latest batch of changes applied
+	replState []byte
+
+	cleanupStop chan struct{}
+	closeOnce   sync.Once
+}
+
+// Interval at which the expired keys are removed from the store
+const expiredKeysCleanupInterval = time.Second
+
+// OpenDB initializes a new instance of an empty in-memory store.
+// Every mutation is recorded as a change in an ordered change log,
+// from which changes can be loaded for replication. Only the latest
+// `maxChngLogSize` changes are retained if it is positive, which bounds
+// the change log alone. The keys themselves are never evicted, hence
+// the memory held grows with the keyspace and callers must bound it on
+// their own. Note that these changes carry no serialised form, hence
+// they can be applied only on stores that apply the individual
+// transaction records.
+func OpenDB(maxChngLogSize int) DB {
+	mdb := &memoryDB{
+		kvs:            make(map[string][]byte),
+		expireTSs:      make(map[string]uint64),
+		maxChngLogSize: maxChngLogSize,
+		cleanupStop:    make(chan struct{}),
+	}
+	go mdb.cleanupExpiredKeysPeriodically()
+	return mdb
+}
+
+func (mdb *memoryDB) Close() error {
+	mdb.closeOnce.Do(func() { close(mdb.cleanupStop) })
+	return nil
+}
+
+// GetStorageStats reports the number of keys held, including those that
+// have expired but are not yet cleaned up. Nothing is held on the disk.
+func (mdb *memoryDB) GetStorageStats() (*storage.Stats, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	engineStats := map[string]string{
+		"memory.num-expiring-keys": strconv.Itoa(len(mdb.expireTSs)),
+		"memory.change-log-size":   strconv.Itoa(len(mdb.chngLog)),
+		"memory.change-number":     strconv.FormatUint(mdb.chngNum, 10),
+	}
+	return &storage.Stats{Engine: "memory", ApproxNumKeys: uint64(len(mdb.kvs)), EngineStats: engineStats}, nil
+}
+
+func (mdb *memoryDB) Put(key []byte, value []byte) error {
+	return mdb.MultiPut(&serverpb.PutRequest{Key: key, Value: value})
+}
+
+func (mdb *memoryDB) MultiPut(puts ...*serverpb.PutRequest) error {
+	if len(puts) == 0 {
+		return nil
+	}
+	trxns := make([]*serverpb.TrxnRecord, len(puts))
+	for i, put := range puts {
+		trxns[i] = &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: put.Key, Value: put.Value, ExpireTS: put.ExpireTS}
+	}
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.commit(trxns)
+	return nil
+}
+
+// BulkLoad applies the given entries without recording them onto the
+// change log.
+func (mdb *memoryDB) BulkLoad(entries ...*serverpb.PutRequest) error {
+	trxns := make([]*serverpb.TrxnRecord, len(entries))
+	for i, entry := range entries {
+		trxns[i] = &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: entry.Key, Value: entry.Value, ExpireTS: entry.ExpireTS}
+	}
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.apply(trxns)
+	return nil
+}
+
+// FinishBulkLoad assigns the next change number to the change marking
+// the load, while discarding the change log that misses the loaded
+// entries. Being in memory, these are as durable as any other entry.
+func (mdb *memoryDB) FinishBulkLoad() (uint64, error) {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.chngNum++
+	mdb.chngLog = nil
+	return mdb.chngNum, nil
+}
+
+func (mdb *memoryDB) CompareAndSet(key, expectedValue, newValue []byte) (bool, error) {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	currValue, present := mdb.get(string(key))
+	switch {
+	case !present && len(expectedValue) != 0:
+		return false, nil
+	case present && (len(expectedValue) == 0 || !storage.ValuesMatch(currValue, expectedValue)):
+		return false, nil
+	}
+	mdb.commit([]*serverpb.TrxnRecord{{Type: serverpb.TrxnRecord_Put, Key: key, Value: newValue}})
+	return true, nil
+}
+
+func (mdb *memoryDB) Increment(key []byte, delta int64) (int64, error) {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	currValue, _ := mdb.get(string(key))
+	res, resValue, err := storage.IncrementValue(currValue, delta)
+	if err != nil {
+		return 0, err
+	}
+	mdb.commit([]*serverpb.TrxnRecord{{Type: serverpb.TrxnRecord_Put, Key: key, Value: resValue}})
+	return res, nil
+}
+
+func (mdb *memoryDB) Txn(txnReq *serverpb.TxnRequest) (bool, error) {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	values, found := make([][]byte, len(txnReq.Conditions)), make([]bool, len(txnReq.Conditions))
+	for i, cond := range txnReq.Conditions {
+		values[i], found[i] = mdb.get(string(cond.Key))
+	}
+	succeeded := storage.TxnConditionsHold(txnReq, values, found)
+	if muts := storage.TxnMutations(txnReq, succeeded); len(muts) > 0 {
+		mdb.commit(muts)
+	}
+	return succeeded, nil
+}
+
+func (mdb *memoryDB) Delete(keys ...[]byte) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	trxns := make([]*serverpb.TrxnRecord, len(keys))
+	for i, key := range keys {
+		trxns[i] = &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Delete, Key: key}
+	}
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.commit(trxns)
+	return nil
+}
+
+func (mdb *memoryDB) Get(keys ...[]byte) ([][]byte, []bool, error) {
+	return mdb.GetWithContext(context.Background(), keys...)
+}
+
+func (mdb *memoryDB) GetWithContext(ctx context.Context, keys ...[]byte) ([][]byte, []bool, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	results, found := make([][]byte, len(keys)), make([]bool, len(keys))
+	for i, key := range keys {
+		if i%storage.ContextCheckInterval == 0 && ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		results[i], found[i] = mdb.get(string(key))
+	}
+	return results, found, nil
+}
+
+func (mdb *memoryDB) Exists(keys ...[]byte) ([]bool, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	results := make([]bool, len(keys))
+	for i, key := range keys {
+		_, results[i] = mdb.get(string(key))
+	}
+	return results, nil
+}
+
+// get must be invoked with the lock held.
+func (mdb *memoryDB) get(key string) ([]byte, bool) {
+	if storage.IsExpired(mdb.expireTSs[key]) {
+		return nil, false
+	}
+	val, present := mdb.kvs[key]
+	return val, present
+}
+
+type iter struct {
+	keys, vals [][]byte
+	expireTSs  []uint64
+	expireTS   uint64
+}
+
+// Iterate captures all the matching entries upfront while holding
+// the lock, so that the iteration happens over a consistent snapshot.
+// Values need not be copied since they are never modified in place.
+func (mdb *memoryDB) Iterate(keyPrefix, startKey []byte) storage.Iterator {
+	return mdb.iterate(keyPrefix, startKey, false)
+}
+
+// IterateKeys is same as Iterate except that the values are not captured.
+func (mdb *memoryDB) IterateKeys(keyPrefix, startKey []byte) storage.Iterator {
+	return mdb.iterate(keyPrefix, startKey, true)
+}
+
+func (mdb *memoryDB) iterate(keyPrefix, startKey []byte, keysOnly bool) storage.Iterator {
+	strtKey, prefix := string(storage.IterationStartKey(keyPrefix, startKey)), string(keyPrefix)
+	mdb.mu.RLock()
+	var keys []string
+	for key := range mdb.kvs {
+		if strings.HasPrefix(key, prefix) && key >= strtKey && !storage.IsExpired(mdb.expireTSs[key]) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	memIter := &iter{keys: make([][]byte, len(keys)), vals: make([][]byte, len(keys)), expireTSs: make([]uint64, len(keys))}
+	for i, key := range keys {
+		memIter.keys[i], memIter.expireTSs[i] = []byte(key), mdb.expireTSs[key]
+		if !keysOnly {
+			memIter.vals[i] = mdb.kvs[key]
+		}
+	}
+	mdb.mu.RUnlock()
+	return memIter
+}
+
+func (memIter *iter) HasNext() bool {
+	return len(memIter.keys) > 0
+}
+
+func (memIter *iter) Next() ([]byte, []byte) {
+	key, val := memIter.keys[0], memIter.vals[0]
+	memIter.expireTS = memIter.expireTSs[0]
+	memIter.keys, memIter.vals, memIter.expireTSs = memIter.keys[1:], memIter.vals[1:], memIter.expireTSs[1:]
+	return key, val
+}
+
+func (memIter *iter) ExpireTS() uint64 {
+	return memIter.expireTS
+}
+
+func (memIter *iter) Err() error {
+	return nil
+}
+
+func (memIter *iter) Close() error {
+	memIter.keys, memIter.vals, memIter.expireTSs = nil, nil, nil
+	return nil
+}
+
+type snapshot struct {
+	KVs       map[string][]byte
+	ExpireTSs map[string]uint64
+}
+
+func (mdb *memoryDB) GetSnapshot() ([]byte, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(snapshot{mdb.kvs, mdb.expireTSs})
+	return buf.Bytes(), err
+}
+
+// PutSnapshot wholly replaces the current keyspace. Since this is not
+// recorded as a change, all the retained changes are discarded as well.
+func (mdb *memoryDB) PutSnapshot(snap []byte) error {
+	data, err := decodeSnapshot(bytes.NewBuffer(snap))
+	if err != nil {
+		return err
+	}
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.kvs, mdb.expireTSs = data.KVs, data.ExpireTSs
+	mdb.chngLog = nil
+	return nil
+}
+
+// Engine recorded in the header of the backups of memory stores.
+const backupEngine = "memory"
+
+func decodeSnapshot(r io.Reader) (*snapshot, error) {
+	data := new(snapshot)
+	if err := gob.NewDecoder(r).Decode(data); err != nil {
+		return nil, err
+	}
+	if data.KVs == nil {
+		data.KVs = make(map[string][]byte)
+	}
+	if data.ExpireTSs == nil {
+		data.ExpireTSs = make(map[string]uint64)
+	}
+	return data, nil
+}
+
+func (mdb *memoryDB) BackupTo(file string) error {
+	if _, err := os.Stat(file); err == nil {
+		return errors.New("given backup file already exists")
+	}
+	chngNum, _ := mdb.GetLatestCommittedChangeNumber()
+	snap, err := mdb.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	return storage.WriteBackupFile(file, storage.BackupInfo{Engine: backupEngine, ChangeNumber: chngNum}, func(w io.Writer) error {
+		_, err := w.Write(snap)
+		return err
+	})
+}
+
+func (mdb *memoryDB) RestoreFrom(file string) error {
+	return mdb.RestoreFromParallel(file, 1, nil)
+}
+
+// RestoreFromParallel is same as RestoreFrom, with the progress tracked
+// by the bytes of the backup decoded so far. Since the backup is decoded
+// as a whole, the given parallelism is of no use.
+func (mdb *memoryDB) RestoreFromParallel(file string, parallelism int, progress *storage.RestoreProgress) error {
+	data, _, err := readBackup(file, progress)
+	if err != nil {
+		return err
+	}
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.kvs, mdb.expireTSs = data.KVs, data.ExpireTSs
+	mdb.chngLog = nil
+	progress.Add(uint64(len(data.KVs)), 0)
+	return nil
+}
+
+func (mdb *memoryDB) VerifyBackup(file string) (*storage.BackupInfo, error) {
+	data, info, err := readBackup(file, nil)
+	if err != nil {
+		return nil, err
+	}
+	info.NumberOfKeys = uint64(len(data.KVs))
+	return info, nil
+}
+
+func readBackup(file string, progress *storage.RestoreProgress) (*snapshot, *storage.BackupInfo, error) {
+	bckp, info, err := storage.OpenBackupFile(file, backupEngine)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer bckp.Close()
+	data, err := decodeSnapshot(progress.Reader(bckp, info.Size))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid backup of memory store: %v: %w", err, dkverrors.ErrInvalidArgument)
+	}
+	return data, info, nil
+}
+
+func (mdb *memoryDB) GetLatestCommittedChangeNumber() (uint64, error) {
+	return mdb.GetLatestAppliedChangeNumber()
+}
+
+func (mdb *memoryDB) LoadChanges(fromChangeNumber uint64, maxChanges int) ([]*serverpb.ChangeRecord, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	if fromChangeNumber > mdb.chngNum || maxChanges <= 0 {
+		return nil, nil
+	}
+	// Change log is contiguous and ends with the latest change
+	firstChngNum := mdb.chngNum - uint64(len(mdb.chngLog)) + 1
+	if fromChangeNumber < firstChngNum {
+		if fromChangeNumber < mdb.truncChngNum {
+			return nil, storage.ErrChangesTruncated
+		}
+		return nil, storage.ErrChangesUnavailable
+	}
+	chngs := mdb.chngLog[fromChangeNumber-firstChngNum:]
+	if len(chngs) > maxChanges {
+		chngs = chngs[:maxChanges]
+	}
+	return append([]*serverpb.ChangeRecord(nil), chngs...), nil
+}
+
+func (mdb *memoryDB) RetainedChanges() (uint64, uint64, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	return mdb.chngNum - uint64(len(mdb.chngLog)) + 1, mdb.chngNum, nil
+}
+
+func (mdb *memoryDB) TruncateChanges(beforeChangeNumber uint64) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	if beforeChangeNumber > mdb.chngNum+1 {
+		beforeChangeNumber = mdb.chngNum + 1
+	}
+	firstChngNum := mdb.chngNum - uint64(len(mdb.chngLog)) + 1
+	if beforeChangeNumber > firstChngNum {
+		mdb.chngLog = append([]*serverpb.ChangeRecord(nil), mdb.chngLog[beforeChangeNumber-firstChngNum:]...)
+	}
+	if beforeChangeNumber > mdb.truncChngNum {
+		mdb.truncChngNum = beforeChangeNumber
+	}
+	return nil
+}
+
+func (mdb *memoryDB) GetLatestAppliedChangeNumber() (uint64, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	return mdb.chngNum, nil
+}
+
+// SetLatestAppliedChangeNumber discards all the retained changes,
+// since the change log must remain contiguous.
+func (mdb *memoryDB) SetLatestAppliedChangeNumber(chngNum uint64) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.chngNum = chngNum
+	mdb.chngLog = nil
+	return nil
+}
+
+// SaveChanges never fails midway, so it is same as SaveChangeBatch.
+func (mdb *memoryDB) SaveChanges(changes []*serverpb.ChangeRecord) (uint64, error) {
+	return mdb.SaveChangeBatch(changes)
+}
+
+func (mdb *memoryDB) SaveChangeBatch(changes []*serverpb.ChangeRecord) (uint64, error) {
+	return mdb.SaveChangeBatchWithState(changes, nil)
+}
+
+func (mdb *memoryDB) SaveChangeBatchWithState(changes []*serverpb.ChangeRecord, state []byte) (uint64, error) {
+	if len(changes) == 0 {
+		return 0, nil
+	}
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	for _, chng := range changes {
+		mdb.apply(chng.Trxns)
+		mdb.record(&serverpb.ChangeRecord{ChangeNumber: chng.ChangeNumber, NumberOfTrxns: uint32(len(chng.Trxns)), Trxns: chng.Trxns})
+	}
+	if state != nil {
+		mdb.replState = append([]byte(nil), state...)
+	}
+	return mdb.chngNum, nil
+}
+
+func (mdb *memoryDB) LoadReplicationState() ([]byte, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	return mdb.replState, nil
+}
+
+// commit applies the given transaction records as a new change. It
+// must be invoked with the write lock held.
+func (mdb *memoryDB) commit(trxns []*serverpb.TrxnRecord) {
+	mdb.apply(trxns)
+	mdb.record(&serverpb.ChangeRecord{ChangeNumber: mdb.chngNum + 1, NumberOfTrxns: uint32(len(trxns)), Trxns: trxns})
+}
+
+func (mdb *memoryDB) apply(trxns []*serverpb.TrxnRecord) {
+	for _, trxn := range trxns {
+		switch trxn.Type {
+		case serverpb.TrxnRecord_Put:
+			// Empty values are retained as non-nil, as for present keys
+			mdb.kvs[string(trxn.Key)] = append([]byte{}, trxn.Value...)
+			if trxn.ExpireTS > 0 {
+				mdb.expireTSs[string(trxn.Key)] = trxn.ExpireTS
+			} else {
+				delete(mdb.expireTSs, string(trxn.Key))
+			}
+		case serverpb.TrxnRecord_Delete:
+			delete(mdb.kvs, string(trxn.Key))
+			delete(mdb.expireTSs, string(trxn.Key))
+		}
+	}
+}
+
+// record appends the given change onto the change log, discarding the
+// oldest change once the log is full. The log is reset if the change
+// does not immediately follow the latest change, so that it remains
+// contiguous.
+func (mdb *memoryDB) record(chng *serverpb.ChangeRecord) {
+	chng.Checksum = storage.ChangeChecksum(chng.Trxns)
+	if chng.ChangeNumber != mdb.chngNum+1 {
+		mdb.chngLog = nil
+	}
+	mdb.chngNum = chng.ChangeNumber
+	mdb.chngLog = append(mdb.chngLog, chng)
+	if mdb.maxChngLogSize > 0 && len(mdb.chngLog) > mdb.maxChngLogSize {
+		mdb.chngLog = mdb.chngLog[len(mdb.chngLog)-mdb.maxChngLogSize:]
+	}
+}
+
+func (mdb *memoryDB) cleanupExpiredKeysPeriodically() {
+	tckr := time.NewTicker(expiredKeysCleanupInterval)
+	defer tckr.Stop()
+	for {
+		select {
+		case <-tckr.C:
+			mdb.cleanupExpiredKeys()
+		case <-mdb.cleanupStop:
+			return
+		}
+	}
+}
+
+// cleanupExpiredKeys removes the expired keys without recording any
+// change, since every store removes them on its own after filtering
+// them out of its reads in the same manner.
+func (mdb *memoryDB) cleanupExpiredKeys() {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	for key, expireTS := range mdb.expireTSs {
+		if storage.IsExpired(expireTS) {
+			delete(mdb.kvs, key)
+			delete(mdb.expireTSs, key)
+		}
+	}
+}
@@ -0,0 +1,463 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/storagetest"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+var store = OpenDB(0)
+
+func TestConformance(t *testing.T) {
+	// Nothing is retained across restarts, hence never reopened
+	storagetest.RunConformanceTests(t, func(t *testing.T) (storage.KVStore, func() (storage.KVStore, error)) {
+		return OpenDB(0), nil
+	})
+}
+
+func TestPutCopiesValue(t *testing.T) {
+	key, value := []byte("CopyKey"), []byte("CopyVal")
+	if err := store.Put(key, value); err != nil {
+		t.Fatalf("Unable to PUT. Key: %s, Value: %s, Error: %v", key, value, err)
+	}
+	value[0] = 'X'
+	if results, _, _ := store.Get(key); string(results[0]) != "CopyVal" {
+		t.Errorf("Expected the stored value to be unaffected by the caller. Actual Value: %s", results[0])
+	}
+}
+
+func TestGetWithCancelledContext(t *testing.T) {
+	key := []byte("CancelledGetKey")
+	if err := store.Put(key, []byte("CancelledGetVal")); err != nil {
+		t.Fatalf("Unable to PUT. Key: %s, Error: %v", key, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if results, _, err := storage.GetWithContext(ctx, store, key); err != nil || string(results[0]) != "CancelledGetVal" {
+		t.Errorf("Unable to GET with context. Values: %q, Error: %v", results, err)
+	}
+	cancel()
+	if _, _, err := storage.GetWithContext(ctx, store, key); err != context.Canceled {
+		t.Errorf("Expected the GET to be abandoned. Error: %v", err)
+	}
+}
+
+func TestIterate(t *testing.T) {
+	numKeys, keyPrefix := 9, "IterKey"
+	for i := 1; i <= numKeys; i++ {
+		key, value := fmt.Sprintf("%s%d", keyPrefix, i), fmt.Sprintf("IterVal%d", i)
+		if err := store.Put([]byte(key), []byte(value)); err != nil {
+			t.Fatalf("Unable to PUT. Key: %s, Value: %s, Error: %v", key, value, err)
+		}
+	}
+	iteration := store.Iterate([]byte(keyPrefix), []byte(keyPrefix+"3"))
+	defer iteration.Close()
+	// Mutations after the iteration begins must not be visible
+	if err := store.Put([]byte(keyPrefix+"5"), []byte("IterNewVal")); err != nil {
+		t.Fatalf("Unable to PUT. Key: %s, Error: %v", keyPrefix+"5", err)
+	}
+	expKeyIdx := 3
+	for iteration.HasNext() {
+		key, val := iteration.Next()
+		expKey, expVal := fmt.Sprintf("%s%d", keyPrefix, expKeyIdx), fmt.Sprintf("IterVal%d", expKeyIdx)
+		if string(key) != expKey || string(val) != expVal {
+			t.Errorf("Iteration mismatch. Expected: %s=%s, Actual: %s=%s", expKey, expVal, key, val)
+		}
+		expKeyIdx++
+	}
+	if err := iteration.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if expKeyIdx != numKeys+1 {
+		t.Errorf("Expected iteration to end at key index %d, but it ended at %d", numKeys+1, expKeyIdx)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	memStore := OpenDB(0)
+	defer memStore.Close()
+	now := uint64(time.Now().Unix())
+	expKey, liveKey := []byte("ExpKey"), []byte("LiveKey")
+	if err := memStore.MultiPut(&serverpb.PutRequest{Key: expKey, Value: []byte("ExpVal"), ExpireTS: now - 1},
+		&serverpb.PutRequest{Key: liveKey, Value: []byte("LiveVal"), ExpireTS: now + 100}); err != nil {
+		t.Fatalf("Unable to MULTIPUT. Error: %v", err)
+	}
+	if results, _, _ := memStore.Get(expKey, liveKey); len(results[0]) != 0 || string(results[1]) != "LiveVal" {
+		t.Errorf("Expected only the live key to be visible. Actual: %q", results)
+	}
+	if results, _ := memStore.Exists(expKey, liveKey); results[0] || !results[1] {
+		t.Errorf("Presence mismatch. Expected: [false true], Actual: %v", results)
+	}
+	iteration := memStore.Iterate(nil, nil)
+	if !iteration.HasNext() {
+		t.Fatal("Expected the live key in iteration")
+	}
+	if key, _ := iteration.Next(); string(key) != string(liveKey) || iteration.ExpireTS() != now+100 {
+		t.Errorf("Iteration mismatch. Expected: %s expiring at %d, Actual: %s expiring at %d", liveKey, now+100, key, iteration.ExpireTS())
+	}
+	if iteration.HasNext() {
+		t.Error("Expected the expired key to be skipped by iteration")
+	}
+	iteration.Close()
+	if updated, _ := memStore.CompareAndSet(expKey, nil, []byte("NewExpVal")); !updated {
+		t.Errorf("Expected the expired key: %s to be set as if absent", expKey)
+	}
+
+	if err := memStore.MultiPut(&serverpb.PutRequest{Key: expKey, Value: []byte("ExpVal"), ExpireTS: now - 1}); err != nil {
+		t.Fatalf("Unable to MULTIPUT. Error: %v", err)
+	}
+	mdb := memStore.(*memoryDB)
+	mdb.cleanupExpiredKeys()
+	if _, present := mdb.kvs[string(expKey)]; present {
+		t.Errorf("Expected the expired key: %s to be removed", expKey)
+	}
+	if _, present := mdb.kvs[string(liveKey)]; !present {
+		t.Errorf("Expected the live key: %s to be retained", liveKey)
+	}
+	// Puts without any expiry clear the existing expiry
+	if err := memStore.Put(liveKey, []byte("LiveVal")); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+	if _, present := mdb.expireTSs[string(liveKey)]; present {
+		t.Errorf("Expected the expiry of key: %s to be cleared", liveKey)
+	}
+}
+
+func TestExpiryReplicates(t *testing.T) {
+	masterStore, slaveStore := OpenDB(0), OpenDB(0)
+	defer masterStore.Close()
+	defer slaveStore.Close()
+	expireTS := uint64(time.Now().Unix()) + 100
+	if err := masterStore.MultiPut(&serverpb.PutRequest{Key: []byte("RepKey"), Value: []byte("RepVal"), ExpireTS: expireTS}); err != nil {
+		t.Fatalf("Unable to MULTIPUT. Error: %v", err)
+	}
+	chngs, err := masterStore.LoadChanges(1, 10)
+	if err != nil {
+		t.Fatalf("Unable to load changes. Error: %v", err)
+	}
+	if _, err := slaveStore.SaveChanges(chngs); err != nil {
+		t.Fatalf("Unable to save changes. Error: %v", err)
+	}
+	snap, err := slaveStore.GetSnapshot()
+	if err != nil {
+		t.Fatalf("Unable to get snapshot. Error: %v", err)
+	}
+	snapStore := OpenDB(0)
+	defer snapStore.Close()
+	if err := snapStore.PutSnapshot(snap); err != nil {
+		t.Fatalf("Unable to put snapshot. Error: %v", err)
+	}
+	for _, memStore := range []DB{slaveStore, snapStore} {
+		if actExpireTS := memStore.(*memoryDB).expireTSs["RepKey"]; actExpireTS != expireTS {
+			t.Errorf("Expiry mismatch. Expected: %d, Actual: %d", expireTS, actExpireTS)
+		}
+	}
+}
+
+func TestIncrementReplicates(t *testing.T) {
+	masterStore, slaveStore := OpenDB(0), OpenDB(0)
+	key := []byte("IncrReplKey")
+	for i := 0; i < 3; i++ {
+		if _, err := masterStore.Increment(key, 2); err != nil {
+			t.Fatalf("Unable to INCREMENT. Error: %v", err)
+		}
+	}
+	chngs, err := masterStore.LoadChanges(1, 10)
+	if err != nil {
+		t.Fatalf("Unable to load changes. Error: %v", err)
+	}
+	if len(chngs) != 3 {
+		t.Fatalf("Expected a change for every INCREMENT. Actual: %d", len(chngs))
+	}
+	if _, err := slaveStore.SaveChanges(chngs); err != nil {
+		t.Fatalf("Unable to save changes. Error: %v", err)
+	}
+	if value, _ := slaveStore.Increment(key, 0); value != 6 {
+		t.Errorf("INCREMENT mismatch on slave. Expected Value: 6, Actual Value: %d", value)
+	}
+}
+
+func TestTxnReplicates(t *testing.T) {
+	masterStore, slaveStore := OpenDB(0), OpenDB(0)
+	txnReq := &serverpb.TxnRequest{
+		Conditions: []*serverpb.TxnCondition{{Type: serverpb.TxnCondition_Absent, Key: []byte("TxnReplKey1")}},
+		ThenMutations: []*serverpb.TrxnRecord{
+			{Type: serverpb.TrxnRecord_Put, Key: []byte("TxnReplKey1"), Value: []byte("TxnReplVal1")},
+			{Type: serverpb.TrxnRecord_Put, Key: []byte("TxnReplKey2"), Value: []byte("TxnReplVal2")},
+		},
+	}
+	if _, err := masterStore.Txn(txnReq); err != nil {
+		t.Fatalf("Unable to TXN. Error: %v", err)
+	}
+	chngs, err := masterStore.LoadChanges(1, 10)
+	if err != nil {
+		t.Fatalf("Unable to load changes. Error: %v", err)
+	}
+	if len(chngs) != 1 || len(chngs[0].Trxns) != 2 {
+		t.Fatalf("Expected a single change with all the mutations of the TXN. Actual: %v", chngs)
+	}
+	if _, err := slaveStore.SaveChanges(chngs); err != nil {
+		t.Fatalf("Unable to save changes. Error: %v", err)
+	}
+	if values, _, _ := slaveStore.Get([]byte("TxnReplKey1"), []byte("TxnReplKey2")); string(values[0]) != "TxnReplVal1" || string(values[1]) != "TxnReplVal2" {
+		t.Errorf("GET mismatch on slave. Values: %q", values)
+	}
+}
+
+func TestLoadChanges(t *testing.T) {
+	memStore := OpenDB(5)
+	numChngs := 8
+	for i := 1; i <= numChngs; i++ {
+		if err := memStore.Put([]byte(fmt.Sprintf("LCK%d", i)), []byte(fmt.Sprintf("LCV%d", i))); err != nil {
+			t.Fatalf("Unable to PUT. Error: %v", err)
+		}
+	}
+	if chngNum, _ := memStore.GetLatestCommittedChangeNumber(); chngNum != uint64(numChngs) {
+		t.Errorf("Change number mismatch. Expected: %d, Actual: %d", numChngs, chngNum)
+	}
+	if _, err := memStore.LoadChanges(3, 10); err != storage.ErrChangesUnavailable {
+		t.Errorf("Expected changes that are no longer retained to be unavailable. Error: %v", err)
+	}
+	chngs, err := memStore.LoadChanges(4, 3)
+	if err != nil {
+		t.Fatalf("Unable to load changes. Error: %v", err)
+	}
+	if len(chngs) != 3 {
+		t.Fatalf("Expected 3 changes. Actual: %d", len(chngs))
+	}
+	for i, chng := range chngs {
+		expKey := fmt.Sprintf("LCK%d", i+4)
+		if chng.ChangeNumber != uint64(i+4) || string(chng.Trxns[0].Key) != expKey {
+			t.Errorf("Change mismatch. Expected: %d=%s, Actual: %d=%s", i+4, expKey, chng.ChangeNumber, chng.Trxns[0].Key)
+		}
+	}
+	if chngs, err := memStore.LoadChanges(uint64(numChngs+1), 10); err != nil || len(chngs) != 0 {
+		t.Errorf("Expected no changes beyond the latest change. Changes: %v, Error: %v", chngs, err)
+	}
+}
+
+func TestTruncateChanges(t *testing.T) {
+	memStore := OpenDB(0)
+	for i := 1; i <= 5; i++ {
+		if err := memStore.Put([]byte(fmt.Sprintf("TCK%d", i)), []byte(fmt.Sprintf("TCV%d", i))); err != nil {
+			t.Fatalf("Unable to PUT. Error: %v", err)
+		}
+	}
+	if err := memStore.TruncateChanges(4); err != nil {
+		t.Fatalf("Unable to truncate changes. Error: %v", err)
+	}
+	if first, last, err := memStore.RetainedChanges(); err != nil || first != 4 || last != 5 {
+		t.Errorf("Retained changes mismatch. Expected: 4-5, Actual: %d-%d, Error: %v", first, last, err)
+	}
+	if _, err := memStore.LoadChanges(3, 10); err != storage.ErrChangesTruncated || !errors.Is(err, storage.ErrChangesUnavailable) {
+		t.Errorf("Expected truncated changes to be unavailable. Error: %v", err)
+	}
+	if chngs, err := memStore.LoadChanges(4, 10); err != nil || len(chngs) != 2 || chngs[0].ChangeNumber != 4 {
+		t.Errorf("Expected the retained changes to load. Changes: %v, Error: %v", chngs, err)
+	}
+	// Changes yet to be committed are never truncated
+	if err := memStore.TruncateChanges(100); err != nil {
+		t.Fatalf("Unable to truncate changes. Error: %v", err)
+	}
+	if first, last, _ := memStore.RetainedChanges(); first != 6 || last != 5 {
+		t.Errorf("Expected no changes to be retained. Actual: %d-%d", first, last)
+	}
+	memStore.Put([]byte("TCK6"), []byte("TCV6"))
+	if chngs, err := memStore.LoadChanges(6, 10); err != nil || len(chngs) != 1 {
+		t.Errorf("Expected the subsequent changes to load. Changes: %v, Error: %v", chngs, err)
+	}
+}
+
+func TestSaveChanges(t *testing.T) {
+	masterStore, slaveStore := OpenDB(0), OpenDB(0)
+	if err := masterStore.Put([]byte("SCK1"), []byte("SCV1")); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+	if err := masterStore.Put([]byte("SCK2"), []byte("SCV2")); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+	if err := masterStore.Delete([]byte("SCK1")); err != nil {
+		t.Fatalf("Unable to DELETE. Error: %v", err)
+	}
+	chngs, err := masterStore.LoadChanges(1, 10)
+	if err != nil {
+		t.Fatalf("Unable to load changes. Error: %v", err)
+	}
+	if appldChngNum, err := slaveStore.SaveChanges(chngs); err != nil {
+		t.Fatalf("Unable to save changes. Error: %v", err)
+	} else if appldChngNum != 3 {
+		t.Errorf("Applied change number mismatch. Expected: 3, Actual: %d", appldChngNum)
+	}
+	if results, _ := slaveStore.Exists([]byte("SCK1"), []byte("SCK2")); results[0] || !results[1] {
+		t.Errorf("Presence mismatch. Expected: [false true], Actual: %v", results)
+	}
+	// Applied changes must be available for further replication
+	if chngs, err := slaveStore.LoadChanges(2, 10); err != nil || len(chngs) != 2 {
+		t.Errorf("Expected 2 changes from the slave. Changes: %v, Error: %v", chngs, err)
+	}
+	if err := slaveStore.SetLatestAppliedChangeNumber(10); err != nil {
+		t.Fatalf("Unable to set the applied change number. Error: %v", err)
+	}
+	if _, err := slaveStore.LoadChanges(2, 10); err != storage.ErrChangesUnavailable {
+		t.Errorf("Expected changes prior to the applied change number to be unavailable. Error: %v", err)
+	}
+}
+
+func TestSnapshotAndBackup(t *testing.T) {
+	srcStore := OpenDB(0)
+	numKeys := 10
+	for i := 1; i <= numKeys; i++ {
+		if err := srcStore.Put([]byte(fmt.Sprintf("SK%d", i)), []byte(fmt.Sprintf("SV%d", i))); err != nil {
+			t.Fatalf("Unable to PUT. Error: %v", err)
+		}
+	}
+	snap, err := srcStore.GetSnapshot()
+	if err != nil {
+		t.Fatalf("Unable to get snapshot. Error: %v", err)
+	}
+	snapStore := OpenDB(0)
+	if err := snapStore.PutSnapshot(snap); err != nil {
+		t.Fatalf("Unable to put snapshot. Error: %v", err)
+	}
+	checkKeys(t, snapStore, numKeys)
+
+	bkpFile := fmt.Sprintf("%s/memory_store_test.bak", os.TempDir())
+	os.Remove(bkpFile)
+	defer os.Remove(bkpFile)
+	if err := srcStore.BackupTo(bkpFile); err != nil {
+		t.Fatalf("Unable to backup. Error: %v", err)
+	}
+	if err := srcStore.BackupTo(bkpFile); err == nil {
+		t.Error("Expected backup onto an existing file to fail")
+	}
+	if info, err := srcStore.VerifyBackup(bkpFile); err != nil || info.Engine != backupEngine || info.NumberOfKeys != uint64(numKeys) {
+		t.Errorf("Unable to verify backup. Info: %+v, Error: %v", info, err)
+	}
+	bkpStore := OpenDB(0)
+	if err := bkpStore.RestoreFrom(bkpFile); err != nil {
+		t.Fatalf("Unable to restore. Error: %v", err)
+	}
+	checkKeys(t, bkpStore, numKeys)
+
+	bkp, err := ioutil.ReadFile(bkpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corruptBkp := append([]byte(nil), bkp...)
+	corruptBkp[len(corruptBkp)/2] ^= 0xFF
+	for name, data := range map[string][]byte{"truncated": bkp[:len(bkp)-1], "corrupt": corruptBkp} {
+		invalidBkpFile := fmt.Sprintf("%s/memory_store_test_%s.bak", os.TempDir(), name)
+		if err := ioutil.WriteFile(invalidBkpFile, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(invalidBkpFile)
+		if _, err := bkpStore.VerifyBackup(invalidBkpFile); err == nil {
+			t.Errorf("Expected an error on verifying a %s backup", name)
+		}
+		if err := bkpStore.RestoreFrom(invalidBkpFile); err == nil {
+			t.Errorf("Expected an error on restoring a %s backup", name)
+		}
+		checkKeys(t, bkpStore, numKeys)
+	}
+}
+
+func TestGetStorageStats(t *testing.T) {
+	store := OpenDB(0)
+	store.Put([]byte("SK1"), []byte("SV1"))
+	store.MultiPut(&serverpb.PutRequest{Key: []byte("SK2"), Value: []byte("SV2"), ExpireTS: uint64(time.Now().Add(time.Hour).Unix())})
+	res, err := storage.NewStorageServer(store).GetStorageStats(context.Background(), &serverpb.GetStorageStatsRequest{})
+	if err != nil || res.Status.Code != 0 {
+		t.Fatalf("Unable to get the storage stats. Response: %v, Error: %v", res, err)
+	}
+	if res.Engine != "memory" || res.ApproximateNumberOfKeys != 2 || res.DiskUsageBytes != 0 || res.LastCompactionTimeMillis != 0 {
+		t.Errorf("Incorrect storage stats: %v", res)
+	}
+	if numExpiring := res.EngineStats["memory.num-expiring-keys"]; numExpiring != "1" {
+		t.Errorf("Expected 1 expiring key. Actual: %s", numExpiring)
+	}
+}
+
+func TestKeyspaceDigest(t *testing.T) {
+	mstrStore, slvStore := OpenDB(0), OpenDB(0)
+	for i := 0; i < 100; i++ {
+		key, value := fmt.Sprintf("DK%02d", i), fmt.Sprintf("DV%02d", i)
+		mstrStore.Put([]byte(key), []byte(value))
+		slvStore.Put([]byte(key), []byte(value))
+	}
+	slvStore.Put([]byte("DK42"), []byte("Corrupted"))
+	ctx := context.Background()
+
+	mstrRngs, _, err := storage.KeyspaceDigest(ctx, mstrStore, &serverpb.KeyspaceDigestRequest{FanOut: 4})
+	if err != nil {
+		t.Fatalf("Unable to compute the keyspace digest. Error: %v", err)
+	}
+	if len(mstrRngs) != 4 || string(mstrRngs[1].StartKey) != "DK25" || mstrRngs[1].NumberOfKeys != 25 {
+		t.Fatalf("Expected the keyspace to be split evenly into 4 ranges. Actual: %v", mstrRngs)
+	}
+	var splitKeys [][]byte
+	for _, rng := range mstrRngs[1:] {
+		splitKeys = append(splitKeys, rng.StartKey)
+	}
+	slvRngs, _, err := storage.KeyspaceDigest(ctx, slvStore, &serverpb.KeyspaceDigestRequest{SplitKeys: splitKeys})
+	if err != nil {
+		t.Fatalf("Unable to compute the keyspace digest. Error: %v", err)
+	}
+	for i, rng := range slvRngs {
+		if same := bytes.Equal(rng.Digest, mstrRngs[i].Digest); same == (i == 1) {
+			t.Errorf("Expected only the range with the corrupted key to differ. Range: %d, Same: %t", i, same)
+		}
+	}
+
+	req := &serverpb.KeyspaceDigestRequest{StartKey: []byte("DK40"), EndKey: []byte("DK45"), FanOut: 1, ListKeys: true}
+	_, mstrKeys, _ := storage.KeyspaceDigest(ctx, mstrStore, req)
+	rngs, slvKeys, err := storage.KeyspaceDigest(ctx, slvStore, req)
+	if err != nil || len(rngs) != 1 || rngs[0].NumberOfKeys != 5 || len(slvKeys) != 5 {
+		t.Fatalf("Expected the 5 keys of the range to be listed. Ranges: %v, Keys: %v, Error: %v", rngs, slvKeys, err)
+	}
+	for i, key := range slvKeys {
+		if same := bytes.Equal(key.Digest, mstrKeys[i].Digest); same == (string(key.Key) == "DK42") {
+			t.Errorf("Expected only the corrupted key to differ. Key: %s, Same: %t", key.Key, same)
+		}
+	}
+
+	invalidReqs := []*serverpb.KeyspaceDigestRequest{
+		{FanOut: 0},
+		{FanOut: storage.MaxDigestFanOut + 1},
+		{StartKey: []byte("DK50"), EndKey: []byte("DK40"), FanOut: 1},
+		{SplitKeys: [][]byte{[]byte("DK50"), []byte("DK40")}},
+		{EndKey: []byte("DK40"), SplitKeys: [][]byte{[]byte("DK50")}},
+	}
+	for _, req := range invalidReqs {
+		if _, _, err := storage.KeyspaceDigest(ctx, mstrStore, req); err == nil {
+			t.Errorf("Expected an error for the invalid request: %v", req)
+		}
+	}
+}
+
+func checkKeys(t *testing.T, memStore DB, numKeys int) {
+	for i := 1; i <= numKeys; i++ {
+		key, expectedValue := fmt.Sprintf("SK%d", i), fmt.Sprintf("SV%d", i)
+		if results, _, err := memStore.Get([]byte(key)); err != nil {
+			t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
+		} else if string(results[0]) != expectedValue {
+			t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expectedValue, results[0])
+		}
+	}
+}
+
+func BenchmarkPutNewKeys(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		key, value := fmt.Sprintf("BK%d", i), fmt.Sprintf("BV%d", i)
+		if err := store.Put([]byte(key), []byte(value)); err != nil {
+			b.Fatalf("Unable to PUT. Key: %s, Value: %s, Error: %v", key, value, err)
+		}
+	}
+}
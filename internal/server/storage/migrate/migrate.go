@@ -0,0 +1,429 @@
+// Package migrate migrates the keyspace of a DKV node from one storage
+// engine onto another, such as from RocksDB onto Badger, while the node
+// is stopped. Keys are copied in batches so that keyspaces larger than
+// the memory of the node can be migrated, and the progress is recorded
+// after every batch so that an interrupted migration resumes where it
+// left off rather than starting over.
+package migrate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+)
+
+const (
+	// Name of the file that records the progress of a migration onto
+	// the folder of the node, whose presence marks it incomplete
+	progressFile = "MIGRATING"
+	// Name of the marker file that records a completed migration onto
+	// the folder of the node
+	markerFile = "MIGRATED"
+)
+
+const (
+	// DefaultBatchSize is the number of keys copied together unless
+	// overridden through WithBatchSize.
+	DefaultBatchSize = 1000
+	// DefaultSampleRate is the ratio of the keys that are verified
+	// using their checksums unless overridden through WithSampleRate.
+	DefaultSampleRate = 100
+)
+
+// ErrIncomplete indicates that the keyspace of the node is being
+// migrated, which must complete before the node is started.
+var ErrIncomplete = errors.New("migration of the keyspace is incomplete")
+
+// A Store is a storage engine that a keyspace is migrated from or onto.
+type Store interface {
+	storage.KVStore
+	storage.ChangeApplier
+}
+
+// Summary describes a completed migration, which is recorded onto the
+// folder of the node.
+type Summary struct {
+	// Engine is the storage engine that the keyspace is migrated onto.
+	Engine string `json:"engine"`
+	// NumKeys is the number of keys copied onto the engine.
+	NumKeys uint64 `json:"numKeys"`
+	// NumVerifiedKeys is the number of keys found in both the engines
+	// once copied, which excludes the keys that expired meanwhile.
+	NumVerifiedKeys uint64 `json:"numVerifiedKeys"`
+	// NumSampledKeys is the number of the verified keys whose entries
+	// are compared using the checksum.
+	NumSampledKeys uint64 `json:"numSampledKeys"`
+	// Checksum is the hex encoded SHA-256 digest of the sampled entries.
+	Checksum string `json:"checksum"`
+	// ChangeNumber is the latest applied change number of the keyspace.
+	ChangeNumber uint64 `json:"changeNumber"`
+	// CompletedAt is the time at which the migration completed.
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// progress records how far a migration has copied the keyspace.
+type progress struct {
+	Engine  string `json:"engine"`
+	LastKey []byte `json:"lastKey,omitempty"`
+	NumKeys uint64 `json:"numKeys"`
+}
+
+type migrateOpts struct {
+	batchSize  int
+	sampleRate int
+	lgr        *zap.Logger
+}
+
+// An Option customizes a migration.
+type Option func(*migrateOpts)
+
+// WithBatchSize sets the number of keys copied together, which bounds
+// the memory held by the migration.
+func WithBatchSize(size int) Option {
+	return func(opts *migrateOpts) {
+		if size > 0 {
+			opts.batchSize = size
+		}
+	}
+}
+
+// WithSampleRate verifies one in every given number of keys using their
+// checksums, such that a rate of one verifies every key.
+func WithSampleRate(rate int) Option {
+	return func(opts *migrateOpts) {
+		if rate > 0 {
+			opts.sampleRate = rate
+		}
+	}
+}
+
+// WithLogger sets the logger onto which the progress is logged.
+func WithLogger(lgr *zap.Logger) Option {
+	return func(opts *migrateOpts) {
+		if lgr != nil {
+			opts.lgr = lgr
+		}
+	}
+}
+
+// Migrate copies the keyspace of the src store onto the dst store of the
+// given engine, whose node stores its data in the given folder. Expired
+// keys are skipped. Once copied, the latest applied change number of the
+// src store is set onto the dst store, so that a slave resumes replicating
+// from its master where it left off. The copy is then verified against the
+// src store, by comparing the number of keys in both along with the
+// checksum of the sampled keys, after which a marker recording the
+// returned summary is written onto the folder.
+//
+// Both the stores must not be mutated otherwise until Migrate returns,
+// like by opening the src store as read only. An interrupted migration
+// is resumed by calling Migrate again with the same stores, while the
+// summary of a completed one is returned as is.
+func Migrate(src, dst Store, engine, folder string, opts ...Option) (*Summary, error) {
+	mOpts := &migrateOpts{batchSize: DefaultBatchSize, sampleRate: DefaultSampleRate, lgr: zap.NewNop()}
+	for _, opt := range opts {
+		opt(mOpts)
+	}
+
+	summ, err := readSummary(folder)
+	switch {
+	case err != nil:
+		return nil, err
+	case summ != nil && summ.Engine != engine:
+		return nil, fmt.Errorf("keyspace is already migrated onto %s rather than %s", summ.Engine, engine)
+	case summ != nil:
+		// Lest the migration was interrupted right after its marker
+		if err = os.Remove(filepath.Join(folder, progressFile)); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return summ, nil
+	}
+
+	prog, err := readProgress(folder)
+	switch {
+	case err != nil:
+		return nil, err
+	case prog == nil:
+		// Recorded ahead of any copy, so that the node refuses to start meanwhile
+		prog = &progress{Engine: engine}
+		if err = writeJSON(folder, progressFile, prog); err != nil {
+			return nil, err
+		}
+	case prog.Engine != engine:
+		return nil, fmt.Errorf("keyspace is being migrated onto %s rather than %s", prog.Engine, engine)
+	default:
+		mOpts.lgr.Info("Resuming the migration", zap.Uint64("numKeys", prog.NumKeys), zap.ByteString("lastKey", prog.LastKey))
+	}
+
+	if err = copyKeys(src, dst, folder, prog, mOpts); err != nil {
+		return nil, err
+	}
+	chngNum, err := src.GetLatestAppliedChangeNumber()
+	if err != nil {
+		return nil, err
+	}
+	if err = dst.SetLatestAppliedChangeNumber(chngNum); err != nil {
+		return nil, err
+	}
+	if err = storage.Sync(dst); err != nil {
+		return nil, err
+	}
+
+	summ, err = verify(src, dst, mOpts)
+	if err != nil {
+		return nil, err
+	}
+	summ.Engine, summ.NumKeys, summ.ChangeNumber, summ.CompletedAt = engine, prog.NumKeys, chngNum, time.Now()
+	if err = writeJSON(folder, markerFile, summ); err != nil {
+		return nil, err
+	}
+	if err = os.Remove(filepath.Join(folder, progressFile)); err != nil {
+		return nil, err
+	}
+	return summ, nil
+}
+
+// copyKeys copies the keys following the last one copied, recording
+// the progress after every batch.
+func copyKeys(src, dst Store, folder string, prog *progress, opts *migrateOpts) error {
+	it := src.Iterate(nil, prog.LastKey)
+	defer it.Close()
+	batch := make([]*serverpb.PutRequest, 0, opts.batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := dst.MultiPut(batch...); err != nil {
+			return err
+		}
+		prog.LastKey = batch[len(batch)-1].Key
+		prog.NumKeys += uint64(len(batch))
+		if err := writeJSON(folder, progressFile, prog); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		opts.lgr.Debug("Copied a batch of keys", zap.Uint64("numKeys", prog.NumKeys))
+		return nil
+	}
+
+	for it.HasNext() {
+		key, val := it.Next()
+		// Iteration begins with the last key copied, if any
+		if prog.LastKey != nil && bytes.Equal(key, prog.LastKey) {
+			continue
+		}
+		if expireTS := it.ExpireTS(); !storage.IsExpired(expireTS) {
+			batch = append(batch, &serverpb.PutRequest{Key: key, Value: val, ExpireTS: expireTS})
+		}
+		if len(batch) == opts.batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	opts.lgr.Info("Copied the keyspace", zap.Uint64("numKeys", prog.NumKeys))
+	return nil
+}
+
+// verify iterates over both the stores together, counting the keys of
+// each and summing up the sampled entries. Keys found in either of the
+// stores alone are skipped if they expired meanwhile.
+func verify(src, dst Store, opts *migrateOpts) (*Summary, error) {
+	srcIt, dstIt := src.Iterate(nil, nil), dst.Iterate(nil, nil)
+	defer srcIt.Close()
+	defer dstIt.Close()
+	srcSum, dstSum := newEntrySum(), newEntrySum()
+	var srcKeys, dstKeys, numSampled uint64
+
+	var srcKey, srcVal, dstKey, dstVal []byte
+	var srcExp, dstExp uint64
+	srcOk, dstOk := false, false
+	nextSrc := func() {
+		if srcOk = srcIt.HasNext(); srcOk {
+			srcKey, srcVal = srcIt.Next()
+			srcExp = srcIt.ExpireTS()
+		}
+	}
+	nextDst := func() {
+		if dstOk = dstIt.HasNext(); dstOk {
+			dstKey, dstVal = dstIt.Next()
+			dstExp = dstIt.ExpireTS()
+		}
+	}
+	nextSrc()
+	nextDst()
+	for srcOk || dstOk {
+		var cmp int
+		switch {
+		case !dstOk:
+			cmp = -1
+		case !srcOk:
+			cmp = 1
+		default:
+			cmp = bytes.Compare(srcKey, dstKey)
+		}
+		switch {
+		case cmp < 0:
+			if !storage.IsExpired(srcExp) {
+				srcKeys++
+			}
+			nextSrc()
+		case cmp > 0:
+			if !storage.IsExpired(dstExp) {
+				dstKeys++
+			}
+			nextDst()
+		default:
+			srcKeys++
+			dstKeys++
+			if crc32.ChecksumIEEE(srcKey)%uint32(opts.sampleRate) == 0 {
+				numSampled++
+				srcSum.add(srcKey, srcVal, srcExp)
+				dstSum.add(dstKey, dstVal, dstExp)
+			}
+			nextSrc()
+			nextDst()
+		}
+	}
+	if err := srcIt.Err(); err != nil {
+		return nil, err
+	}
+	if err := dstIt.Err(); err != nil {
+		return nil, err
+	}
+
+	if srcKeys != dstKeys {
+		return nil, fmt.Errorf("verification failed: source holds %d keys while destination holds %d", srcKeys, dstKeys)
+	}
+	srcChksum, dstChksum := srcSum.hex(), dstSum.hex()
+	if srcChksum != dstChksum {
+		return nil, fmt.Errorf("verification failed: checksum of %d sampled keys is %s in source while %s in destination", numSampled, srcChksum, dstChksum)
+	}
+	opts.lgr.Info("Verified the keyspace", zap.Uint64("numKeys", srcKeys), zap.Uint64("numSampledKeys", numSampled), zap.String("checksum", srcChksum))
+	return &Summary{NumVerifiedKeys: srcKeys, NumSampledKeys: numSampled, Checksum: srcChksum}, nil
+}
+
+type entrySum struct {
+	h hash.Hash
+}
+
+func newEntrySum() *entrySum {
+	return &entrySum{sha256.New()}
+}
+
+// add sums up the given entry, prefixing the key and value with their
+// lengths so that the boundaries of the entries are unambiguous.
+func (es *entrySum) add(key, val []byte, expireTS uint64) {
+	var buf [8]byte
+	for _, data := range [][]byte{key, val} {
+		binary.BigEndian.PutUint64(buf[:], uint64(len(data)))
+		es.h.Write(buf[:])
+		es.h.Write(data)
+	}
+	binary.BigEndian.PutUint64(buf[:], expireTS)
+	es.h.Write(buf[:])
+}
+
+func (es *entrySum) hex() string {
+	return hex.EncodeToString(es.h.Sum(nil))
+}
+
+// Check returns ErrIncomplete if the keyspace of the node whose data is
+// stored in the given folder is being migrated, and an error if it was
+// migrated onto an engine other than the given one. Nodes shall check
+// their folder before opening their storage engine.
+func Check(folder, engine string) error {
+	prog, err := readProgress(folder)
+	switch {
+	case err != nil:
+		return err
+	case prog != nil:
+		return fmt.Errorf("%w: resume migrating onto %s with %d keys copied", ErrIncomplete, prog.Engine, prog.NumKeys)
+	}
+	summ, err := readSummary(folder)
+	switch {
+	case err != nil:
+		return err
+	case summ != nil && summ.Engine != engine:
+		return fmt.Errorf("keyspace is migrated onto %s, hence can not be served by %s", summ.Engine, engine)
+	}
+	return nil
+}
+
+func readProgress(folder string) (*progress, error) {
+	prog := &progress{}
+	if found, err := readJSON(folder, progressFile, prog); !found {
+		return nil, err
+	}
+	return prog, nil
+}
+
+func readSummary(folder string) (*Summary, error) {
+	summ := &Summary{}
+	if found, err := readJSON(folder, markerFile, summ); !found {
+		return nil, err
+	}
+	return summ, nil
+}
+
+func readJSON(folder, name string, v interface{}) (bool, error) {
+	data, err := ioutil.ReadFile(filepath.Join(folder, name))
+	switch {
+	case os.IsNotExist(err):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("unable to parse %s: %v", name, err)
+	}
+	return true, nil
+}
+
+// writeJSON writes the given value wholly before it replaces any
+// previous one, so that an interruption never leaves it partial.
+func writeJSON(folder, name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(folder, name)
+	tmpFile := file + ".tmp"
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile, file)
+	}
+	if err != nil {
+		os.Remove(tmpFile)
+	}
+	return err
+}
@@ -0,0 +1,163 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage/badger"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+const numKeys = 250
+
+func newSource(t *testing.T) Store {
+	t.Helper()
+	src := memory.OpenDB(0)
+	var puts []*serverpb.PutRequest
+	for i := 0; i < numKeys; i++ {
+		put := &serverpb.PutRequest{Key: []byte(fmt.Sprintf("key_%03d", i)), Value: []byte(fmt.Sprintf("val_%d", i))}
+		if i%10 == 0 {
+			put.ExpireTS = uint64(time.Now().Add(time.Hour).Unix())
+		}
+		puts = append(puts, put)
+	}
+	if err := src.MultiPut(puts...); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SetLatestAppliedChangeNumber(42); err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func newFolder(t *testing.T) (string, Store) {
+	t.Helper()
+	folder, err := ioutil.TempDir("", "dkv_migrate_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := badger.Open(badger.NewOptions(filepath.Join(folder, "data")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		dst.Close()
+		os.RemoveAll(folder)
+	})
+	return folder, dst
+}
+
+func checkMigrated(t *testing.T, src, dst Store, summ *Summary) {
+	t.Helper()
+	if summ.Engine != "badger" || summ.NumKeys != numKeys || summ.NumVerifiedKeys != numKeys || summ.ChangeNumber != 42 {
+		t.Errorf("Unexpected summary of the migration: %+v", summ)
+	}
+	if summ.NumSampledKeys != numKeys || summ.Checksum == "" {
+		t.Errorf("Expected every key to be sampled. Actual: %d, Checksum: %q", summ.NumSampledKeys, summ.Checksum)
+	}
+	if chngNum, err := dst.GetLatestAppliedChangeNumber(); err != nil || chngNum != 42 {
+		t.Errorf("Expected the change number to be migrated. Actual: %d, Error: %v", chngNum, err)
+	}
+	srcIt, dstIt := src.Iterate(nil, nil), dst.Iterate(nil, nil)
+	defer srcIt.Close()
+	defer dstIt.Close()
+	for srcIt.HasNext() {
+		if !dstIt.HasNext() {
+			t.Fatal("Expected every key to be migrated")
+		}
+		srcKey, srcVal := srcIt.Next()
+		dstKey, dstVal := dstIt.Next()
+		if string(srcKey) != string(dstKey) || string(srcVal) != string(dstVal) || srcIt.ExpireTS() != dstIt.ExpireTS() {
+			t.Errorf("Expected %s=%s to be migrated. Actual: %s=%s", srcKey, srcVal, dstKey, dstVal)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	src := newSource(t)
+	folder, dst := newFolder(t)
+	summ, err := Migrate(src, dst, "badger", folder, WithBatchSize(16), WithSampleRate(1))
+	if err != nil {
+		t.Fatalf("Unable to migrate. Error: %v", err)
+	}
+	checkMigrated(t, src, dst, summ)
+
+	if err = Check(folder, "badger"); err != nil {
+		t.Errorf("Expected the migrated folder to be served by badger. Error: %v", err)
+	}
+	if err = Check(folder, "rocksdb"); err == nil {
+		t.Error("Expected the migrated folder not to be served by rocksdb")
+	}
+	// Migrating again returns the recorded summary
+	if again, err := Migrate(src, dst, "badger", folder); err != nil || again.Checksum != summ.Checksum {
+		t.Errorf("Expected the recorded summary. Actual: %+v, Error: %v", again, err)
+	}
+}
+
+// failingStore fails the puts once the given number of batches succeed
+type failingStore struct {
+	Store
+	batches int
+}
+
+func (fs *failingStore) MultiPut(puts ...*serverpb.PutRequest) error {
+	if fs.batches == 0 {
+		return errors.New("interrupted")
+	}
+	fs.batches--
+	return fs.Store.MultiPut(puts...)
+}
+
+func TestMigrateResumes(t *testing.T) {
+	src := newSource(t)
+	folder, dst := newFolder(t)
+	if _, err := Migrate(src, &failingStore{dst, 5}, "badger", folder, WithBatchSize(16)); err == nil {
+		t.Fatal("Expected the migration to be interrupted")
+	}
+	if err := Check(folder, "badger"); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("Expected the migration to be incomplete. Error: %v", err)
+	}
+	if _, err := Migrate(src, dst, "rocksdb", folder); err == nil {
+		t.Error("Expected the migration onto another engine to fail")
+	}
+
+	// Only the keys following the copied batches are copied again
+	resumed := &failingStore{dst, (numKeys - 5*16 + 15) / 16}
+	summ, err := Migrate(src, resumed, "badger", folder, WithBatchSize(16), WithSampleRate(1))
+	if err != nil {
+		t.Fatalf("Unable to resume the migration. Error: %v", err)
+	}
+	checkMigrated(t, src, dst, summ)
+	if err = Check(folder, "badger"); err != nil {
+		t.Errorf("Expected the migration to be complete. Error: %v", err)
+	}
+}
+
+// corruptingStore stores every value altered
+type corruptingStore struct {
+	Store
+}
+
+func (cs *corruptingStore) MultiPut(puts ...*serverpb.PutRequest) error {
+	for _, put := range puts {
+		put.Value = append(put.Value, '!')
+	}
+	return cs.Store.MultiPut(puts...)
+}
+
+func TestMigrateVerifies(t *testing.T) {
+	src := newSource(t)
+	folder, dst := newFolder(t)
+	if _, err := Migrate(src, &corruptingStore{dst}, "badger", folder, WithSampleRate(1)); err == nil {
+		t.Fatal("Expected the verification of the altered values to fail")
+	}
+	if err := Check(folder, "badger"); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("Expected the migration to be incomplete. Error: %v", err)
+	}
+}
@@ -0,0 +1,370 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+// Namespaces partition the keyspace amongst multiple tenants. Keys of
+// a namespace are stored under the prefix namespaceMarker followed by
+// the uvarint encoded length of the namespace and the namespace itself.
+// Since the length is a part of every prefix, no prefix is a prefix of
+// another. Keys of the default namespace, which is the empty one, are
+// stored verbatim and hence must not begin with the namespaceMarker.
+// Together these make collisions across namespaces impossible.
+const namespaceMarker = 0x00
+
+// ReservedKeyPrefix is the prefix of the keys under which the storage
+// engines keep their metadata, such as the latest applied change number
+// or the entries that expire. Keys of the default namespace must not
+// begin with it, lest they overwrite this metadata or get mistaken for
+// it while their changes are replicated. Keys of the other namespaces
+// begin with the namespaceMarker, hence never with this prefix.
+var ReservedKeyPrefix = []byte("_dkv_meta::")
+
+// metaKeyspacePrefix is the prefix of the keys under which DKV keeps the
+// metadata of its keyspace, such as the quotas of the namespaces. Unlike
+// the metadata of the storage engines, these keys are replicated along
+// with the changes. Being the prefix of a namespace of zero length, which
+// is never used since the default namespace is stored verbatim, it never
+// collides with the keys of any namespace.
+var metaKeyspacePrefix = []byte{namespaceMarker, 0x00}
+
+// metaKey returns the key of the given metadata of the keyspace.
+func metaKey(name string) []byte {
+	return append(append([]byte(nil), metaKeyspacePrefix...), name...)
+}
+
+// NamespacePrefix returns the prefix under which all the keys of the
+// given namespace are stored, which is empty for the default namespace.
+func NamespacePrefix(namespace string) []byte {
+	if namespace == "" {
+		return nil
+	}
+	prefix := make([]byte, 1+binary.MaxVarintLen64+len(namespace))
+	prefix[0] = namespaceMarker
+	n := 1 + binary.PutUvarint(prefix[1:], uint64(len(namespace)))
+	return append(prefix[:n], namespace...)
+}
+
+// NamespacedKey returns the key under which the given key of the given
+// namespace is stored. Fails with ErrInvalidArgument if the key belongs
+// to the default namespace and begins with either the reserved marker
+// byte or the ReservedKeyPrefix.
+func NamespacedKey(namespace string, key []byte) ([]byte, error) {
+	if namespace == "" {
+		if len(key) > 0 && key[0] == namespaceMarker {
+			return nil, fmt.Errorf("keys of the default namespace must not begin with the byte %#x: %w", namespaceMarker, dkverrors.ErrInvalidArgument)
+		}
+		if bytes.HasPrefix(key, ReservedKeyPrefix) {
+			return nil, fmt.Errorf("keys of the default namespace must not begin with the reserved prefix %q: %w", ReservedKeyPrefix, dkverrors.ErrInvalidArgument)
+		}
+		return key, nil
+	}
+	prefix := NamespacePrefix(namespace)
+	nsKey := make([]byte, len(prefix)+len(key))
+	copy(nsKey, prefix)
+	copy(nsKey[len(prefix):], key)
+	return nsKey, nil
+}
+
+// NamespacedKeys is same as NamespacedKey, but for all the given keys.
+func NamespacedKeys(namespace string, keys ...[]byte) ([][]byte, error) {
+	nsKeys := make([][]byte, len(keys))
+	for i, key := range keys {
+		nsKey, err := NamespacedKey(namespace, key)
+		if err != nil {
+			return nil, err
+		}
+		nsKeys[i] = nsKey
+	}
+	return nsKeys, nil
+}
+
+// NamespacedPuts returns the given entries with their keys replaced by
+// those under which they are stored, as per the namespace of each entry.
+// The given entries themselves are left unmodified.
+func NamespacedPuts(puts ...*serverpb.PutRequest) ([]*serverpb.PutRequest, error) {
+	nsPuts := make([]*serverpb.PutRequest, len(puts))
+	for i, put := range puts {
+		nsKey, err := NamespacedKey(put.Namespace, put.Key)
+		if err != nil {
+			return nil, err
+		}
+		nsPuts[i] = put
+		if put.Namespace != "" {
+			nsPuts[i] = &serverpb.PutRequest{Key: nsKey, Value: put.Value, ExpireTS: put.ExpireTS}
+		}
+	}
+	return nsPuts, nil
+}
+
+// InNamespace checks whether the given stored key belongs to the given
+// namespace, and if so returns the key within this namespace.
+func InNamespace(namespace string, key []byte) ([]byte, bool) {
+	if namespace == "" {
+		return key, len(key) == 0 || key[0] != namespaceMarker
+	}
+	prefix := NamespacePrefix(namespace)
+	if !bytes.HasPrefix(key, prefix) {
+		return nil, false
+	}
+	return key[len(prefix):], true
+}
+
+// IterateNamespace iterates over the keys of the given namespace alone,
+// as per the semantics of KVStore.Iterate, or those of IterateKeys if
+// keysOnly is set. Keys are returned within this namespace, i.e.,
+// without the prefix under which they are stored.
+func IterateNamespace(kvs KVStore, namespace string, keyPrefix, startKey []byte, keysOnly bool) (Iterator, error) {
+	iterate := kvs.Iterate
+	if keysOnly {
+		iterate = func(keyPrefix, startKey []byte) Iterator {
+			return IterateKeys(kvs, keyPrefix, startKey)
+		}
+	}
+	nsPrefix, err := NamespacedKey(namespace, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		// Keys of all the other namespaces sort before those
+		// of the default namespace, so they are skipped over
+		if startKey = IterationStartKey(keyPrefix, startKey); len(startKey) == 0 || startKey[0] == namespaceMarker {
+			startKey = []byte{namespaceMarker + 1}
+		}
+		return iterate(nsPrefix, startKey), nil
+	}
+	nsStartKey, _ := NamespacedKey(namespace, startKey)
+	return &nsIter{iterate(nsPrefix, nsStartKey), len(nsPrefix) - len(keyPrefix)}, nil
+}
+
+type nsIter struct {
+	Iterator
+	prefixLen int
+}
+
+func (ni *nsIter) Next() ([]byte, []byte) {
+	key, val := ni.Iterator.Next()
+	return key[ni.prefixLen:], val
+}
+
+// FilterChanges returns the given change records with their transactions
+// restricted to those on the keys of the given namespace. Since the
+// serialised form of a change record cannot be restricted thus, it is
+// omitted from the returned records, which carry their transactions with
+// the keys as stored along with their checksums. The given records
+// themselves are left unmodified.
+func FilterChanges(namespace string, chngs []*serverpb.ChangeRecord) []*serverpb.ChangeRecord {
+	nsChngs := make([]*serverpb.ChangeRecord, len(chngs))
+	for i, chng := range chngs {
+		nsChng := &serverpb.ChangeRecord{ChangeNumber: chng.ChangeNumber}
+		for _, trxn := range chng.Trxns {
+			if _, present := InNamespace(namespace, trxn.Key); present {
+				nsChng.Trxns = append(nsChng.Trxns, trxn)
+			}
+		}
+		nsChng.NumberOfTrxns = uint32(len(nsChng.Trxns))
+		nsChng.Checksum = ChangeChecksum(nsChng.Trxns)
+		nsChngs[i] = nsChng
+	}
+	return nsChngs
+}
+
+// FilterChangesByKeyPrefix returns the given change records with their
+// transactions restricted to those on the keys, as stored, that begin
+// with the given prefix. Records left without any transactions are
+// dropped. As with FilterChanges, the returned records carry checksums
+// instead of the serialised form and the given records are left
+// unmodified.
+func FilterChangesByKeyPrefix(keyPrefix []byte, chngs []*serverpb.ChangeRecord) []*serverpb.ChangeRecord {
+	var prefixChngs []*serverpb.ChangeRecord
+	for _, chng := range chngs {
+		prefixChng := &serverpb.ChangeRecord{ChangeNumber: chng.ChangeNumber}
+		for _, trxn := range chng.Trxns {
+			if bytes.HasPrefix(trxn.Key, keyPrefix) {
+				prefixChng.Trxns = append(prefixChng.Trxns, trxn)
+			}
+		}
+		if prefixChng.NumberOfTrxns = uint32(len(prefixChng.Trxns)); prefixChng.NumberOfTrxns > 0 {
+			prefixChng.Checksum = ChangeChecksum(prefixChng.Trxns)
+			prefixChngs = append(prefixChngs, prefixChng)
+		}
+	}
+	return prefixChngs
+}
+
+// Magic bytes at the beginning of every namespace backup.
+var nsBackupMagic = []byte("DKVNSBK1")
+
+// Engine recorded in the header of namespace backups.
+const nsBackupEngine = "namespace"
+
+// BackupNamespace backs up the keys of the given namespace from the
+// given store into a single file at the given path, as per the format
+// of WriteBackupFile. Every entry is written as a length delimited
+// PutRequest carrying its key within the namespace, so that the backup
+// can be restored onto any namespace. Such backups carry no change
+// number, since their restores are committed as fresh changes.
+func BackupNamespace(kvs KVStore, namespace, path string) error {
+	iter, err := IterateNamespace(kvs, namespace, nil, nil, false)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	return WriteBackupFile(path, BackupInfo{Engine: nsBackupEngine}, func(w io.Writer) error {
+		if _, err := w.Write(nsBackupMagic); err != nil {
+			return err
+		}
+		lenBuf := make([]byte, binary.MaxVarintLen64)
+		for iter.HasNext() {
+			key, val := iter.Next()
+			if err := writeDelimited(w, lenBuf, &serverpb.PutRequest{Key: key, Value: val, ExpireTS: iter.ExpireTS()}); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	})
+}
+
+// Number of keys deleted or stored in a single batch during restores.
+const nsRestoreBatchSize = 1000
+
+// RestoreNamespace replaces all the keys of the given namespace in the
+// given store with those of the backup at the given path, which must
+// have been written by BackupNamespace. The backup is validated wholly
+// before any key is replaced, after which its keys are stored by upto
+// the given number of workers, recording their progress onto the given
+// RestoreProgress. Note that the replacement itself is not atomic, hence
+// reads of the namespace may observe a partial state while it is in
+// progress, which is retained should any of the workers fail.
+func RestoreNamespace(kvs KVStore, namespace, path string, parallelism int, progress *RestoreProgress) error {
+	puts, _, err := readNamespaceBackup(namespace, path)
+	if err != nil {
+		return err
+	}
+	progress.SetBytes(0, putsSize(puts))
+	if err = deleteNamespace(kvs, namespace); err != nil {
+		return err
+	}
+	return ingestPuts(kvs, parallelism, progress, func() ([]*serverpb.PutRequest, error) {
+		n := nsRestoreBatchSize
+		if n > len(puts) {
+			n = len(puts)
+		}
+		batch := puts[:n]
+		puts = puts[n:]
+		return batch, nil
+	})
+}
+
+// VerifyNamespaceBackup verifies that the given path holds a complete
+// and uncorrupted backup written by BackupNamespace, whose keys can be
+// restored onto the given namespace, and describes it.
+func VerifyNamespaceBackup(namespace, path string) (*BackupInfo, error) {
+	puts, info, err := readNamespaceBackup(namespace, path)
+	if err != nil {
+		return nil, err
+	}
+	info.NumberOfKeys = uint64(len(puts))
+	return info, nil
+}
+
+func readNamespaceBackup(namespace, path string) ([]*serverpb.PutRequest, *BackupInfo, error) {
+	bckpFile, info, err := OpenBackupFile(path, nsBackupEngine)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer bckpFile.Close()
+
+	rdr := bufio.NewReader(bckpFile)
+	magic := make([]byte, len(nsBackupMagic))
+	if _, err = io.ReadFull(rdr, magic); err != nil || !bytes.Equal(magic, nsBackupMagic) {
+		return nil, nil, errors.New("given path is not a backup of a namespace")
+	}
+	var puts []*serverpb.PutRequest
+	for {
+		put := new(serverpb.PutRequest)
+		if err = readDelimited(rdr, put); err == io.EOF {
+			return puts, info, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if put.Key, err = NamespacedKey(namespace, put.Key); err != nil {
+			return nil, nil, err
+		}
+		puts = append(puts, put)
+	}
+}
+
+// writeDelimited writes the given message preceded by its
+// uvarint encoded length, using the given buffer for the latter.
+func writeDelimited(w io.Writer, lenBuf []byte, msg proto.Message) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	n := binary.PutUvarint(lenBuf, uint64(len(data)))
+	if _, err = w.Write(lenBuf[:n]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// readDelimited reads a message written by writeDelimited into the
+// given message. Returns io.EOF only if there are no more messages.
+func readDelimited(r *bufio.Reader, msg proto.Message) error {
+	msgLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, msgLen)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return fmt.Errorf("truncated entry: %v", err)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+func deleteNamespace(kvs KVStore, namespace string) error {
+	keys, err := namespaceKeys(kvs, namespace)
+	return deleteKeys(kvs, keys, err)
+}
+
+func deleteKeys(kvs KVStore, keys [][]byte, err error) error {
+	for err == nil && len(keys) > 0 {
+		n := nsRestoreBatchSize
+		if n > len(keys) {
+			n = len(keys)
+		}
+		err = kvs.Delete(keys[:n]...)
+		keys = keys[n:]
+	}
+	return err
+}
+
+// namespaceKeys returns all the stored keys belonging to the given namespace.
+func namespaceKeys(kvs KVStore, namespace string) ([][]byte, error) {
+	iter, err := IterateNamespace(kvs, namespace, nil, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var keys [][]byte
+	for iter.HasNext() {
+		key, _ := iter.Next()
+		nsKey, _ := NamespacedKey(namespace, key)
+		keys = append(keys, append([]byte(nil), nsKey...))
+	}
+	return keys, iter.Err()
+}
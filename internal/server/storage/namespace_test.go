@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+func TestNamespacedKeysNeverCollide(t *testing.T) {
+	// Pairs whose naive concatenations collide
+	pairs := []struct{ ns, key string }{{"", "abc"}, {"a", "bc"}, {"ab", "c"}, {"abc", ""}}
+	seen := make(map[string]bool)
+	for _, pair := range pairs {
+		nsKey, err := NamespacedKey(pair.ns, []byte(pair.key))
+		if err != nil {
+			t.Fatalf("Unable to compute the key of %q in namespace: %q. Error: %v", pair.key, pair.ns, err)
+		}
+		if seen[string(nsKey)] {
+			t.Errorf("Key %q in namespace: %q collides with another", pair.key, pair.ns)
+		}
+		seen[string(nsKey)] = true
+		for _, other := range pairs {
+			if _, present := InNamespace(other.ns, nsKey); present != (other.ns == pair.ns) {
+				t.Errorf("Namespace mismatch of key %q in namespace: %q against namespace: %q", pair.key, pair.ns, other.ns)
+			}
+		}
+		if key, _ := InNamespace(pair.ns, nsKey); !bytes.Equal(key, []byte(pair.key)) {
+			t.Errorf("Key mismatch in namespace: %q. Expected: %q, Actual: %q", pair.ns, pair.key, key)
+		}
+	}
+
+	nsKey, _ := NamespacedKey("a", []byte("bc"))
+	if _, err := NamespacedKey("", nsKey); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected keys of the default namespace with the reserved prefix to be rejected. Error: %v", err)
+	}
+	metaKey := append(append([]byte(nil), ReservedKeyPrefix...), "ChangeNumber"...)
+	if _, err := NamespacedKey("", metaKey); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected keys of the default namespace with the reserved key prefix to be rejected. Error: %v", err)
+	}
+	if nsKey, err := NamespacedKey("a", metaKey); err != nil || bytes.HasPrefix(nsKey, ReservedKeyPrefix) {
+		t.Errorf("Expected keys of other namespaces to be stored without the reserved key prefix. Key: %q, Error: %v", nsKey, err)
+	}
+}
+
+func TestFilterChangesByKeyPrefix(t *testing.T) {
+	trxn := func(key string) *serverpb.TrxnRecord {
+		return &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: []byte(key)}
+	}
+	chngs := []*serverpb.ChangeRecord{
+		{ChangeNumber: 1, NumberOfTrxns: 2, Trxns: []*serverpb.TrxnRecord{trxn("AK1"), trxn("BK1")}},
+		{ChangeNumber: 2, NumberOfTrxns: 1, Trxns: []*serverpb.TrxnRecord{trxn("BK2")}},
+		{ChangeNumber: 3, NumberOfTrxns: 1, Trxns: []*serverpb.TrxnRecord{trxn("AK2")}},
+	}
+	prefixChngs := FilterChangesByKeyPrefix([]byte("AK"), chngs)
+	if len(prefixChngs) != 2 || prefixChngs[0].ChangeNumber != 1 || prefixChngs[1].ChangeNumber != 3 {
+		t.Fatalf("Expected only the changes on the key prefix. Actual: %v", prefixChngs)
+	}
+	if prefixChngs[0].NumberOfTrxns != 1 || string(prefixChngs[0].Trxns[0].Key) != "AK1" {
+		t.Errorf("Expected only the transactions on the key prefix. Actual: %v", prefixChngs[0])
+	}
+	if chngs[0].NumberOfTrxns != 2 || len(chngs[0].Trxns) != 2 {
+		t.Errorf("Expected the given changes to be left unmodified. Actual: %v", chngs[0])
+	}
+}
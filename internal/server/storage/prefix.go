@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PrefixStats are the number of keys sharing a prefix along with
+// the number of bytes these occupy.
+type PrefixStats struct {
+	NumKeys uint64
+	Size    uint64
+}
+
+// A PrefixEstimator represents the capability of the underlying store
+// to estimate the stats of the keys sharing a prefix from its metadata,
+// within a time that does not depend on the number of keys.
+type PrefixEstimator interface {
+	// EstimatePrefix estimates the stats of the keys with the given
+	// prefix, where the size is that of the files holding these keys.
+	EstimatePrefix(prefix []byte) (*PrefixStats, error)
+}
+
+// ErrPrefixEstimateUnsupported indicates that the store can not estimate
+// the stats of the keys sharing a prefix.
+var ErrPrefixEstimateUnsupported = errors.New("storage engine can not estimate the keys of a prefix")
+
+// EstimatePrefix estimates the stats of the keys with the given prefix
+// if the given store is a PrefixEstimator, failing with
+// ErrPrefixEstimateUnsupported otherwise.
+func EstimatePrefix(kvs KVStore, prefix []byte) (*PrefixStats, error) {
+	if pe, ok := kvs.(PrefixEstimator); ok {
+		return pe.EstimatePrefix(prefix)
+	}
+	return nil, ErrPrefixEstimateUnsupported
+}
+
+// DefaultPrefixRate is the number of keys per second iterated over by
+// CountPrefix, unless specified otherwise.
+const DefaultPrefixRate = 100000
+
+// prefixPacingKeys is the number of keys iterated between the pauses
+const prefixPacingKeys = 100
+
+// CountPrefix computes the exact stats of the keys with the given prefix,
+// where the size is the total length of these keys and their values. Since
+// all these keys are iterated over, atmost the given number of keys are
+// iterated per second, so that serving reads is not starved. The iteration
+// is abandoned once the given context is done.
+func CountPrefix(ctx context.Context, kvs KVStore, prefix []byte, maxKeysPerSec int) (*PrefixStats, error) {
+	iter := NewContextIterator(ctx, kvs.Iterate(prefix, nil))
+	defer iter.Close()
+	stats := &PrefixStats{}
+	startTime := time.Now()
+	for iter.HasNext() {
+		key, val := iter.Next()
+		stats.NumKeys++
+		stats.Size += uint64(len(key) + len(val))
+		if maxKeysPerSec > 0 && stats.NumKeys%prefixPacingKeys == 0 {
+			dueTime := startTime.Add(time.Duration(stats.NumKeys) * time.Second / time.Duration(maxKeysPerSec))
+			if wait := time.Until(dueTime); wait > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(wait):
+				}
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// PrefixEnd returns the smallest key succeeding all the keys with the
+// given prefix, which is nil if there is no such key.
+func PrefixEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return end
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// prefixStore iterates over the keys it is created with
+type prefixStore struct {
+	KVStore
+	keys []string
+}
+
+func (ps *prefixStore) Iterate(keyPrefix, startKey []byte) Iterator {
+	return &sliceIter{keys: ps.keys}
+}
+
+func TestCountPrefix(t *testing.T) {
+	kvs := &prefixStore{}
+	for i := 0; i < 300; i++ {
+		kvs.keys = append(kvs.keys, fmt.Sprintf("K%03d", i))
+	}
+	ctx := context.Background()
+	startTime := time.Now()
+	if stats, err := CountPrefix(ctx, kvs, []byte("K"), 1000); err != nil || stats.NumKeys != 300 || stats.Size != 1200 {
+		t.Errorf("Expected 300 keys of 1200 bytes. Stats: %+v, Error: %v", stats, err)
+	}
+	if elapsed := time.Since(startTime); elapsed < 250*time.Millisecond {
+		t.Errorf("Expected the iteration to be rate limited. Elapsed: %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := CountPrefix(ctx, kvs, []byte("K"), 100); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error: %v once the deadline passes. Actual: %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	for prefix, expEnd := range map[string]string{"": "", "ab": "ac", "a\xff": "b", "\xff\xff": ""} {
+		if end := PrefixEnd([]byte(prefix)); string(end) != expEnd {
+			t.Errorf("End mismatch for prefix: %q. Expected: %q, Actual: %q", prefix, expEnd, end)
+		}
+	}
+}
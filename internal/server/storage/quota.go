@@ -0,0 +1,420 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/zap"
+)
+
+// ErrQuotaExceeded indicates that the mutations would take a namespace
+// beyond its Quota.
+var ErrQuotaExceeded = dkverrors.ErrQuotaExceeded
+
+// A Quota limits the keys of a namespace, along with the bytes these
+// occupy, where the zero value of every limit disables it.
+type Quota struct {
+	// MaxKeys is the maximum number of keys of the namespace.
+	MaxKeys uint64
+	// MaxBytes is the maximum total length of the keys of the namespace,
+	// as stored, along with their values, as per CountPrefix.
+	MaxBytes uint64
+}
+
+// QuotaUsage is the Quota of a namespace along with its usage, which
+// is approximate between the reconciliations of the usage.
+type QuotaUsage struct {
+	Quota
+	PrefixStats
+	// ReconcileTime is the time at which the usage was last reconciled,
+	// which is zero until the first reconciliation completes.
+	ReconcileTime time.Time
+}
+
+// A QuotaMutation is a put or a delete of a key of a namespace, as
+// accounted against the Quota of the namespace. The key and the value
+// are as stored, i.e., the key is prefixed by the namespace.
+type QuotaMutation struct {
+	Namespace string
+	Key       []byte
+	Value     []byte
+	Delete    bool
+}
+
+// DefaultQuotaReconcileInterval is the interval at which the usage of
+// the namespaces is reconciled, unless specified otherwise.
+const DefaultQuotaReconcileInterval = 10 * time.Minute
+
+// Prefix of the metadata keys under which the quotas are retained,
+// followed by their namespaces.
+const quotaKeyPrefix = "quota::"
+
+// Quotas tracks the usage of the namespaces with a Quota, so that the
+// mutations taking them beyond their quotas can be rejected. The usage
+// is tracked as the mutations are admitted through Reserve, and is
+// reconciled periodically by iterating over the keys of the namespaces,
+// which corrects for the keys that expire or are mutated concurrently.
+// The quotas are retained in the store itself, under keys hidden from
+// every namespace, such that they are replicated along with the changes.
+// The quotas retained are reloaded along with every reconciliation, so
+// that those replicated onto the store are eventually enforced as well.
+// It is safe for concurrent use.
+type Quotas struct {
+	kvs KVStore
+	// Store through which the quotas are retained, which is kvs unless
+	// set through RetainThrough
+	writer   KVStore
+	interval time.Duration
+	lgr      *zap.Logger
+	ctx      context.Context
+	stop     context.CancelFunc
+
+	mu      sync.Mutex
+	quotas  map[string]*QuotaUsage
+	started bool
+}
+
+// NewQuotas creates the tracker of the quotas retained in the given
+// store, reconciling their usage at the given interval, which defaults
+// to DefaultQuotaReconcileInterval. It must be started for the usage
+// to be reconciled, until which the namespaces are considered empty.
+func NewQuotas(kvs KVStore, interval time.Duration, lgr *zap.Logger) (*Quotas, error) {
+	if interval <= 0 {
+		interval = DefaultQuotaReconcileInterval
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	q := &Quotas{kvs: kvs, writer: kvs, interval: interval, lgr: lgr, ctx: ctx, stop: stop, quotas: make(map[string]*QuotaUsage)}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// RetainThrough retains the quotas set from now on through the given
+// store rather than the one tracked, such as one that replicates them
+// onto the store tracked by every member of a cluster.
+func (q *Quotas) RetainThrough(writer KVStore) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.writer = writer
+}
+
+// load loads the quotas retained in the store, retaining the usage of
+// the namespaces already tracked and dropping those without a quota.
+func (q *Quotas) load() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	prefix := metaKey(quotaKeyPrefix)
+	iter := q.kvs.Iterate(prefix, nil)
+	defer iter.Close()
+	quotas := make(map[string]*QuotaUsage, len(q.quotas))
+	for iter.HasNext() {
+		key, val := iter.Next()
+		nsQuota := &serverpb.NamespaceQuota{}
+		if err := proto.Unmarshal(val, nsQuota); err != nil {
+			return fmt.Errorf("unable to parse the quota under key %q: %v", key, err)
+		}
+		ns, quota := string(key[len(prefix):]), Quota{MaxKeys: nsQuota.MaxKeys, MaxBytes: nsQuota.MaxBytes}
+		usage, present := q.quotas[ns]
+		if !present {
+			usage = &QuotaUsage{}
+		}
+		usage.Quota, quotas[ns] = quota, usage
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	q.quotas = quotas
+	return nil
+}
+
+// Start reconciles the usage of every namespace with a quota right away
+// and then at every interval in the background, until stopped.
+func (q *Quotas) Start() {
+	q.mu.Lock()
+	q.started = true
+	q.mu.Unlock()
+	go func() {
+		tckr := time.NewTicker(q.interval)
+		defer tckr.Stop()
+		for {
+			if err := q.Reconcile(q.ctx); err != nil && q.ctx.Err() == nil {
+				q.lgr.Warn("Unable to reconcile the usage of the namespaces", zap.Error(err))
+			}
+			select {
+			case <-q.ctx.Done():
+				return
+			case <-tckr.C:
+			}
+		}
+	}()
+}
+
+// Stop stops reconciling the usage of the namespaces.
+func (q *Quotas) Stop() {
+	q.stop()
+}
+
+// Set sets the quota of the given namespace, retaining it in the store.
+// The quota is removed if neither of its limits is set. The usage of
+// the namespace is reconciled in the background, if it is not already
+// tracked and the Quotas are started. Fails with ErrInvalidArgument for
+// the default namespace.
+func (q *Quotas) Set(namespace string, quota Quota) error {
+	if namespace == "" {
+		return fmt.Errorf("default namespace can not have a quota: %w", dkverrors.ErrInvalidArgument)
+	}
+	key := metaKey(quotaKeyPrefix + namespace)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if quota == (Quota{}) {
+		if err := q.writer.Delete(key); err != nil {
+			return err
+		}
+		delete(q.quotas, namespace)
+		return nil
+	}
+	val, err := proto.Marshal(&serverpb.NamespaceQuota{MaxKeys: quota.MaxKeys, MaxBytes: quota.MaxBytes})
+	if err != nil {
+		return err
+	}
+	if err = q.writer.Put(key, val); err != nil {
+		return err
+	}
+	if usage, present := q.quotas[namespace]; present {
+		usage.Quota = quota
+		return nil
+	}
+	q.quotas[namespace] = &QuotaUsage{Quota: quota}
+	if !q.started {
+		return nil
+	}
+	go func() {
+		if err := q.reconcile(q.ctx, namespace); err != nil && q.ctx.Err() == nil {
+			q.lgr.Warn("Unable to reconcile the usage of the namespace", zap.String("namespace", namespace), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Get returns the quota of the given namespace along with its usage,
+// reporting whether the namespace has a quota at all.
+func (q *Quotas) Get(namespace string) (QuotaUsage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if usage, present := q.quotas[namespace]; present {
+		return *usage, true
+	}
+	return QuotaUsage{}, false
+}
+
+// get is same as Get, except that it reports no quotas for nil Quotas.
+func (q *Quotas) get(namespace string) (QuotaUsage, bool) {
+	if q == nil || namespace == "" {
+		return QuotaUsage{}, false
+	}
+	return q.Get(namespace)
+}
+
+// List returns the quotas of all the namespaces along with their usage,
+// by their namespaces.
+func (q *Quotas) List() map[string]QuotaUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usages := make(map[string]QuotaUsage, len(q.quotas))
+	for ns, usage := range q.quotas {
+		usages[ns] = *usage
+	}
+	return usages
+}
+
+// Reconcile reloads the quotas retained in the store and recomputes
+// the usage of every namespace with a quota right away, by iterating
+// over its keys at the default rate of CountPrefix.
+func (q *Quotas) Reconcile(ctx context.Context) error {
+	if err := q.load(); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	nss := make([]string, 0, len(q.quotas))
+	for ns := range q.quotas {
+		nss = append(nss, ns)
+	}
+	q.mu.Unlock()
+	sort.Strings(nss)
+	for _, ns := range nss {
+		if err := q.reconcile(ctx, ns); err != nil {
+			return fmt.Errorf("unable to reconcile the usage of namespace %q: %w", ns, err)
+		}
+	}
+	return nil
+}
+
+func (q *Quotas) reconcile(ctx context.Context, namespace string) error {
+	stats, err := CountPrefix(ctx, q.kvs, NamespacePrefix(namespace), DefaultPrefixRate)
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if usage, present := q.quotas[namespace]; present {
+		usage.PrefixStats, usage.ReconcileTime = *stats, time.Now()
+	}
+	return nil
+}
+
+// A QuotaReservation is the usage reserved by mutations admitted through
+// Reserve, which must be cancelled unless the mutations are applied.
+type QuotaReservation struct {
+	q      *Quotas
+	deltas map[string]*usageDelta
+}
+
+type usageDelta struct {
+	numKeys, size int64
+}
+
+// Reserve admits the given mutations, unless they take any namespace
+// beyond its quota, in which case it fails with ErrQuotaExceeded. The
+// usage of the namespaces is updated as per the current values of the
+// mutated keys, as if the mutations are applied. Mutations that do not
+// add to the usage of a namespace are admitted even if the namespace
+// is beyond its quota. The returned reservation is nil if none of the
+// namespaces has a quota.
+func (q *Quotas) Reserve(muts ...QuotaMutation) (*QuotaReservation, error) {
+	if q == nil {
+		return nil, nil
+	}
+	q.mu.Lock()
+	var limited []QuotaMutation
+	for _, mut := range muts {
+		if _, present := q.quotas[mut.Namespace]; present {
+			limited = append(limited, mut)
+		}
+	}
+	q.mu.Unlock()
+	if len(limited) == 0 {
+		return nil, nil
+	}
+
+	keys := make([][]byte, len(limited))
+	for i, mut := range limited {
+		keys[i] = mut.Key
+	}
+	vals, found, err := q.kvs.Get(keys...)
+	if err != nil {
+		return nil, err
+	}
+	// Sizes of the keys as mutated so far, negative for the absent ones
+	sizes := make(map[string]int64, len(limited))
+	deltas := make(map[string]*usageDelta)
+	for i, mut := range limited {
+		prevSize, present := sizes[string(mut.Key)]
+		if !present {
+			if prevSize = -1; found[i] {
+				prevSize = int64(len(mut.Key) + len(vals[i]))
+			}
+		}
+		size := int64(-1)
+		if !mut.Delete {
+			size = int64(len(mut.Key) + len(mut.Value))
+		}
+		sizes[string(mut.Key)] = size
+		delta := deltas[mut.Namespace]
+		if delta == nil {
+			delta = &usageDelta{}
+			deltas[mut.Namespace] = delta
+		}
+		delta.numKeys += boolToInt64(size >= 0) - boolToInt64(prevSize >= 0)
+		delta.size += maxInt64(size, 0) - maxInt64(prevSize, 0)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for ns, delta := range deltas {
+		usage, present := q.quotas[ns]
+		if !present {
+			continue
+		}
+		if delta.numKeys > 0 && usage.MaxKeys > 0 && usage.NumKeys+uint64(delta.numKeys) > usage.MaxKeys {
+			return nil, fmt.Errorf("namespace %q holds %d keys, limit: %d keys: %w", ns, usage.NumKeys, usage.MaxKeys, ErrQuotaExceeded)
+		}
+		if delta.size > 0 && usage.MaxBytes > 0 && usage.Size+uint64(delta.size) > usage.MaxBytes {
+			return nil, fmt.Errorf("namespace %q holds %d bytes, limit: %d bytes: %w", ns, usage.Size, usage.MaxBytes, ErrQuotaExceeded)
+		}
+	}
+	q.apply(deltas, 1)
+	return &QuotaReservation{q: q, deltas: deltas}, nil
+}
+
+// Cancel releases the usage reserved, as the mutations are not applied.
+// Cancelling a nil reservation does nothing.
+func (qr *QuotaReservation) Cancel() {
+	if qr == nil {
+		return
+	}
+	qr.q.mu.Lock()
+	defer qr.q.mu.Unlock()
+	qr.q.apply(qr.deltas, -1)
+}
+
+// apply adds the given deltas, multiplied by the given sign, onto the
+// usage of the namespaces. It must be called with mu held.
+func (q *Quotas) apply(deltas map[string]*usageDelta, sign int64) {
+	for ns, delta := range deltas {
+		if usage, present := q.quotas[ns]; present {
+			usage.NumKeys = addDelta(usage.NumKeys, sign*delta.numKeys)
+			usage.Size = addDelta(usage.Size, sign*delta.size)
+		}
+	}
+}
+
+// TxnQuotaMutations returns the mutations of the given branch of the
+// given transaction, whose keys are as stored, for reserving them.
+func TxnQuotaMutations(namespace string, nsTxnReq *serverpb.TxnRequest, succeeded bool) []QuotaMutation {
+	var muts []QuotaMutation
+	for _, mut := range TxnMutations(nsTxnReq, succeeded) {
+		muts = append(muts, QuotaMutation{Namespace: namespace, Key: mut.Key, Value: mut.Value, Delete: mut.Type == serverpb.TrxnRecord_Delete})
+	}
+	return muts
+}
+
+// ToNamespaceQuota returns the given usage of the given namespace, as
+// reported through the GRPC methods.
+func ToNamespaceQuota(namespace string, usage QuotaUsage) *serverpb.NamespaceQuota {
+	nsQuota := &serverpb.NamespaceQuota{Namespace: namespace, MaxKeys: usage.MaxKeys, MaxBytes: usage.MaxBytes, NumberOfKeys: usage.NumKeys, SizeBytes: usage.Size}
+	if !usage.ReconcileTime.IsZero() {
+		nsQuota.ReconcileTimeMillis = toMillis(usage.ReconcileTime)
+	}
+	return nsQuota
+}
+
+func addDelta(val uint64, delta int64) uint64 {
+	if delta < 0 && uint64(-delta) > val {
+		return 0
+	}
+	return uint64(int64(val) + delta)
+}
+
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// mapStore holds its keys in a map, iterating over them in their order
+type mapStore struct {
+	KVStore
+	kvs map[string][]byte
+}
+
+func (ms *mapStore) Put(key, value []byte) error {
+	ms.kvs[string(key)] = value
+	return nil
+}
+
+func (ms *mapStore) Delete(keys ...[]byte) error {
+	for _, key := range keys {
+		delete(ms.kvs, string(key))
+	}
+	return nil
+}
+
+func (ms *mapStore) Get(keys ...[]byte) ([][]byte, []bool, error) {
+	vals, found := make([][]byte, len(keys)), make([]bool, len(keys))
+	for i, key := range keys {
+		vals[i], found[i] = ms.kvs[string(key)]
+	}
+	return vals, found, nil
+}
+
+func (ms *mapStore) Iterate(keyPrefix, startKey []byte) Iterator {
+	iter := &kvIter{}
+	for key := range ms.kvs {
+		if strings.HasPrefix(key, string(keyPrefix)) {
+			iter.keys = append(iter.keys, key)
+		}
+	}
+	sort.Strings(iter.keys)
+	for _, key := range iter.keys {
+		iter.vals = append(iter.vals, ms.kvs[key])
+	}
+	return iter
+}
+
+type kvIter struct {
+	sliceIter
+	vals [][]byte
+}
+
+func (ki *kvIter) Next() ([]byte, []byte) {
+	key, val := ki.sliceIter.Next()
+	val, ki.vals = ki.vals[0], ki.vals[1:]
+	return key, val
+}
+
+func nsPut(t *testing.T, ns, key, value string) QuotaMutation {
+	nsKey, err := NamespacedKey(ns, []byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return QuotaMutation{Namespace: ns, Key: nsKey, Value: []byte(value)}
+}
+
+func nsDelete(t *testing.T, ns, key string) QuotaMutation {
+	mut := nsPut(t, ns, key, "")
+	mut.Value, mut.Delete = nil, true
+	return mut
+}
+
+func (ms *mapStore) apply(muts ...QuotaMutation) {
+	for _, mut := range muts {
+		if mut.Delete {
+			delete(ms.kvs, string(mut.Key))
+		} else {
+			ms.kvs[string(mut.Key)] = mut.Value
+		}
+	}
+}
+
+func TestQuotas(t *testing.T) {
+	kvs := &mapStore{kvs: make(map[string][]byte)}
+	quotas, err := NewQuotas(kvs, 0, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer quotas.Stop()
+	if err = quotas.Set("", Quota{MaxKeys: 1}); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected the default namespace to be rejected. Error: %v", err)
+	}
+	if err = quotas.Set("ns", Quota{MaxKeys: 2, MaxBytes: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys of the namespace are 6 bytes long as stored
+	puts := []QuotaMutation{nsPut(t, "ns", "K1", "V1"), nsPut(t, "ns", "K2", "V2"), nsPut(t, "other", "K3", "V3")}
+	if _, err = quotas.Reserve(puts...); err != nil {
+		t.Fatalf("Expected the puts to be admitted. Error: %v", err)
+	}
+	kvs.apply(puts...)
+	if usage, _ := quotas.Get("ns"); usage.NumKeys != 2 || usage.Size != 16 {
+		t.Errorf("Expected the usage of 2 keys and 16 bytes. Actual: %+v", usage.PrefixStats)
+	}
+	if _, err = quotas.Reserve(nsPut(t, "ns", "K4", "V4")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected the put beyond the keys of the quota to be rejected. Error: %v", err)
+	}
+	// Updates add no keys, while the larger value is within the bytes of the quota
+	update := nsPut(t, "ns", "K1", strings.Repeat("V", 40))
+	rsrv, err := quotas.Reserve(update)
+	if err != nil {
+		t.Fatalf("Expected the update to be admitted. Error: %v", err)
+	}
+	if usage, _ := quotas.Get("ns"); usage.NumKeys != 2 || usage.Size != 54 {
+		t.Errorf("Expected the usage of 2 keys and 54 bytes. Actual: %+v", usage.PrefixStats)
+	}
+	rsrv.Cancel()
+	if usage, _ := quotas.Get("ns"); usage.NumKeys != 2 || usage.Size != 16 {
+		t.Errorf("Expected the usage of 2 keys and 16 bytes once cancelled. Actual: %+v", usage.PrefixStats)
+	}
+	if _, err = quotas.Reserve(nsPut(t, "ns", "K1", strings.Repeat("V", 100))); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected the update beyond the bytes of the quota to be rejected. Error: %v", err)
+	}
+
+	// Deletes are admitted even beyond the quota
+	if err = quotas.Set("ns", Quota{MaxKeys: 1}); err != nil {
+		t.Fatal(err)
+	}
+	del := nsDelete(t, "ns", "K2")
+	if _, err = quotas.Reserve(del); err != nil {
+		t.Fatalf("Expected the delete to be admitted. Error: %v", err)
+	}
+	kvs.apply(del)
+	if usage, _ := quotas.Get("ns"); usage.NumKeys != 1 || usage.Size != 8 || usage.MaxKeys != 1 {
+		t.Errorf("Expected the usage of 1 key and 8 bytes. Actual: %+v", usage)
+	}
+
+	// Usage drifts away when the keys are mutated without reservations
+	kvs.apply(nsPut(t, "ns", "K5", "V5"), nsPut(t, "ns", "K6", "V6"))
+	if err = quotas.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if usage, _ := quotas.Get("ns"); usage.NumKeys != 3 || usage.Size != 24 || usage.ReconcileTime.IsZero() {
+		t.Errorf("Expected the reconciled usage of 3 keys and 24 bytes. Actual: %+v", usage)
+	}
+	if rsrv, err = quotas.Reserve(nsPut(t, "unlimited", "K1", "V1")); err != nil || rsrv != nil {
+		t.Errorf("Expected no reservation for a namespace without a quota. Reservation: %v, Error: %v", rsrv, err)
+	}
+}
+
+func TestQuotasRetained(t *testing.T) {
+	kvs := &mapStore{kvs: make(map[string][]byte)}
+	quotas, err := NewQuotas(kvs, 0, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ns, quota := range map[string]Quota{"ns1": {MaxKeys: 10}, "ns2": {MaxBytes: 100}, "ns3": {MaxKeys: 1}} {
+		if err = quotas.Set(ns, quota); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = quotas.Set("ns3", Quota{}); err != nil {
+		t.Fatal(err)
+	}
+	quotas.Stop()
+
+	// Quotas are hidden from every namespace
+	for key := range kvs.kvs {
+		if _, present := InNamespace("", []byte(key)); present {
+			t.Errorf("Expected the quota under key %q to be hidden from the default namespace", key)
+		}
+		if bytes.HasPrefix([]byte(key), NamespacePrefix("ns1")) {
+			t.Errorf("Expected the quota under key %q to be hidden from its namespace", key)
+		}
+	}
+	if quotas, err = NewQuotas(kvs, 0, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	defer quotas.Stop()
+	usages := quotas.List()
+	if len(usages) != 2 || usages["ns1"].Quota != (Quota{MaxKeys: 10}) || usages["ns2"].Quota != (Quota{MaxBytes: 100}) {
+		t.Errorf("Expected the quotas of ns1 and ns2 to be retained. Actual: %+v", usages)
+	}
+}
+
+func TestQuotasRetainedThroughWriter(t *testing.T) {
+	kvs, replica := &mapStore{kvs: make(map[string][]byte)}, &mapStore{kvs: make(map[string][]byte)}
+	quotas, err := NewQuotas(kvs, 0, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer quotas.Stop()
+	replicaQuotas, err := NewQuotas(replica, 0, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replicaQuotas.Stop()
+	// Writes are applied alike onto both the stores
+	quotas.RetainThrough(&replicatingStore{kvs, replica})
+	if err = quotas.Set("ns1", Quota{MaxKeys: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if _, present := replicaQuotas.Get("ns1"); present {
+		t.Error("Expected the quota replicated onto the store to be enforced only once reconciled")
+	}
+	if err = replicaQuotas.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if usage, present := replicaQuotas.Get("ns1"); !present || usage.Quota != (Quota{MaxKeys: 10}) {
+		t.Errorf("Expected the replicated quota to be enforced. Actual: %+v", usage)
+	}
+	if err = quotas.Set("ns1", Quota{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = replicaQuotas.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if usages := replicaQuotas.List(); len(usages) != 0 {
+		t.Errorf("Expected the removed quota to no longer be enforced. Actual: %+v", usages)
+	}
+}
+
+type replicatingStore struct {
+	*mapStore
+	replica *mapStore
+}
+
+func (rs *replicatingStore) Put(key, value []byte) error {
+	rs.replica.Put(key, value)
+	return rs.mapStore.Put(key, value)
+}
+
+func (rs *replicatingStore) Delete(keys ...[]byte) error {
+	rs.replica.Delete(keys...)
+	return rs.mapStore.Delete(keys...)
+}
@@ -1,10 +1,13 @@
 package redis
 
 import (
+	"bytes"
 	"fmt"
-	"strings"
+	"sort"
+	"time"
 
 	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
 	"github.com/go-redis/redis"
 )
 
@@ -42,16 +45,276 @@ func (rdb *redisDBStore) Put(key []byte, value []byte) error {
 	return rdb.db.Set(string(key), value, 0).Err()
 }
 
-func (rdb *redisDBStore) Get(keys ...[]byte) ([][]byte, error) {
+func (rdb *redisDBStore) MultiPut(puts ...*serverpb.PutRequest) error {
+	if len(puts) == 0 {
+		return nil
+	}
+	var pairs []interface{}
+	for _, put := range puts {
+		pairs = append(pairs, string(put.Key), put.Value)
+	}
+	// MSET sets all the given keys atomically while discarding their
+	// expiry times, which are then set within the same transaction
+	_, err := rdb.db.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.MSet(pairs...)
+		for _, put := range puts {
+			if put.ExpireTS > 0 {
+				pipe.ExpireAt(string(put.Key), time.Unix(int64(put.ExpireTS), 0))
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// CompareAndSet is not done via a Lua script since the values are matched
+// once decompressed, and is instead performed as an optimistic transaction
+// that watches the key being set.
+func (rdb *redisDBStore) CompareAndSet(key, expectedValue, newValue []byte) (bool, error) {
+	strKey := string(key)
+	for {
+		var updated bool
+		err := rdb.db.Watch(func(tx *redis.Tx) error {
+			currValue, err := tx.Get(strKey).Bytes()
+			switch {
+			case err != nil && err != redis.Nil:
+				return err
+			case len(expectedValue) == 0 && err == nil:
+				return nil
+			case len(expectedValue) != 0 && (err == redis.Nil || !storage.ValuesMatch(currValue, expectedValue)):
+				return nil
+			}
+			_, err = tx.TxPipelined(func(pipe redis.Pipeliner) error {
+				pipe.Set(strKey, newValue, 0)
+				return nil
+			})
+			updated = err == nil
+			return err
+		}, strKey)
+		// Retry the update if the key got
+		// mutated concurrently in the meantime
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return updated, nil
+	}
+}
+
+// Increment is not done via a Lua script since its numbers cannot
+// represent every 64 bit integer, and is instead performed as an
+// optimistic transaction that watches the key being incremented.
+func (rdb *redisDBStore) Increment(key []byte, delta int64) (int64, error) {
+	strKey := string(key)
+	for {
+		var res int64
+		err := rdb.db.Watch(func(tx *redis.Tx) error {
+			currValue, err := tx.Get(strKey).Bytes()
+			if err != nil && err != redis.Nil {
+				return err
+			}
+			var resValue []byte
+			if res, resValue, err = storage.IncrementValue(currValue, delta); err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(func(pipe redis.Pipeliner) error {
+				pipe.Set(strKey, resValue, 0)
+				return nil
+			})
+			return err
+		}, strKey)
+		// Retry the increment if the key got
+		// mutated concurrently in the meantime
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return res, nil
+	}
+}
+
+// Txn is performed as an optimistic transaction that watches the keys
+// of the conditions, so that its mutations are applied only if none of
+// these keys get mutated after they are checked.
+func (rdb *redisDBStore) Txn(txnReq *serverpb.TxnRequest) (bool, error) {
+	var condKeys []string
+	for _, key := range storage.TxnConditionKeys(txnReq) {
+		condKeys = append(condKeys, string(key))
+	}
+	for {
+		var succeeded bool
+		err := rdb.db.Watch(func(tx *redis.Tx) error {
+			values, found := make([][]byte, len(condKeys)), make([]bool, len(condKeys))
+			for i, key := range condKeys {
+				value, err := tx.Get(key).Bytes()
+				if err != nil && err != redis.Nil {
+					return err
+				}
+				values[i], found[i] = value, err == nil
+			}
+			succeeded = storage.TxnConditionsHold(txnReq, values, found)
+			muts := storage.TxnMutations(txnReq, succeeded)
+			if len(muts) == 0 {
+				return nil
+			}
+			_, err := tx.TxPipelined(func(pipe redis.Pipeliner) error {
+				for _, mut := range muts {
+					switch {
+					case mut.Type == serverpb.TrxnRecord_Delete:
+						pipe.Del(string(mut.Key))
+					case mut.ExpireTS > 0:
+						pipe.Set(string(mut.Key), mut.Value, 0)
+						pipe.ExpireAt(string(mut.Key), time.Unix(int64(mut.ExpireTS), 0))
+					default:
+						pipe.Set(string(mut.Key), mut.Value, 0)
+					}
+				}
+				return nil
+			})
+			return err
+		}, condKeys...)
+		// Retry the transaction if any of the keys
+		// got mutated concurrently in the meantime
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return succeeded, nil
+	}
+}
+
+func (rdb *redisDBStore) Delete(keys ...[]byte) error {
+	var strKeys []string
+	for _, key := range keys {
+		strKeys = append(strKeys, string(key))
+	}
+	return rdb.db.Del(strKeys...).Err()
+}
+
+func (rdb *redisDBStore) Get(keys ...[]byte) ([][]byte, []bool, error) {
 	switch numKeys := len(keys); {
 	case numKeys == 1:
-		val, err := rdb.getSingleKey(keys[0])
-		return [][]byte{val}, err
+		val, found, err := rdb.getSingleKey(keys[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		return [][]byte{val}, []bool{found}, nil
 	default:
 		return rdb.getMultipleKeys(keys)
 	}
 }
 
+func (rdb *redisDBStore) Exists(keys ...[]byte) ([]bool, error) {
+	cmds := make([]*redis.IntCmd, len(keys))
+	if _, err := rdb.db.Pipelined(func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Exists(string(key))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	results := make([]bool, len(keys))
+	for i, cmd := range cmds {
+		results[i] = cmd.Val() == 1
+	}
+	return results, nil
+}
+
+// Lua scripts are executed atomically by Redis, so loading the keys,
+// values and TTLs in a single script ensures a consistent snapshot.
+var iterScript = redis.NewScript(`
+local res = {}
+for _, key in ipairs(redis.call('KEYS', ARGV[1])) do
+	res[#res+1] = key
+	res[#res+1] = redis.call('GET', key)
+	res[#res+1] = redis.call('TTL', key)
+end
+return res
+`)
+
+type iter struct {
+	keys, vals [][]byte
+	expireTSs  []uint64
+	expireTS   uint64
+	err        error
+}
+
+func (rdb *redisDBStore) Iterate(keyPrefix, startKey []byte) storage.Iterator {
+	res, err := iterScript.Run(rdb.db, nil, globEscape(keyPrefix)+"*").Result()
+	if err != nil {
+		return &iter{err: err}
+	}
+	kvs, _ := res.([]interface{})
+	entries := make(map[string][]byte, len(kvs)/3)
+	expireTSs := make(map[string]uint64)
+	now := time.Now().Unix()
+	var keys []string
+	for i := 0; i+2 < len(kvs); i += 3 {
+		key, _ := kvs[i].(string)
+		val, _ := kvs[i+1].(string)
+		keys = append(keys, key)
+		entries[key] = []byte(val)
+		// Keys without any expiry have a negative TTL
+		if ttl, _ := kvs[i+2].(int64); ttl >= 0 {
+			expireTSs[key] = uint64(now + ttl)
+		}
+	}
+	sort.Strings(keys)
+
+	strtKey := string(storage.IterationStartKey(keyPrefix, startKey))
+	redisIter := &iter{}
+	for _, key := range keys {
+		if key >= strtKey {
+			redisIter.keys = append(redisIter.keys, []byte(key))
+			redisIter.vals = append(redisIter.vals, entries[key])
+			redisIter.expireTSs = append(redisIter.expireTSs, expireTSs[key])
+		}
+	}
+	return redisIter
+}
+
+func (redisIter *iter) HasNext() bool {
+	return redisIter.err == nil && len(redisIter.keys) > 0
+}
+
+func (redisIter *iter) Next() ([]byte, []byte) {
+	key, val := redisIter.keys[0], redisIter.vals[0]
+	redisIter.expireTS = redisIter.expireTSs[0]
+	redisIter.keys, redisIter.vals, redisIter.expireTSs = redisIter.keys[1:], redisIter.vals[1:], redisIter.expireTSs[1:]
+	return key, val
+}
+
+func (redisIter *iter) ExpireTS() uint64 {
+	return redisIter.expireTS
+}
+
+func (redisIter *iter) Err() error {
+	return redisIter.err
+}
+
+func (redisIter *iter) Close() error {
+	redisIter.keys, redisIter.vals, redisIter.expireTSs = nil, nil, nil
+	return nil
+}
+
+func globEscape(prefix []byte) string {
+	var buf bytes.Buffer
+	for _, ch := range prefix {
+		if bytes.IndexByte([]byte(`*?[]\`), ch) >= 0 {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(ch)
+	}
+	return buf.String()
+}
+
 func (rdb *redisDBStore) GetSnapshot() ([]byte, error) {
 	return nil, nil
 }
@@ -60,26 +323,33 @@ func (rdb *redisDBStore) PutSnapshot(snap []byte) error {
 	return nil
 }
 
-func (rdb *redisDBStore) getSingleKey(key []byte) ([]byte, error) {
+func (rdb *redisDBStore) getSingleKey(key []byte) ([]byte, bool, error) {
 	val, err := rdb.db.Get(string(key)).Result()
-	if err != nil && !strings.HasSuffix(err.Error(), "nil") {
-		return nil, err
+	switch {
+	case err == redis.Nil:
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
 	}
-	return []byte(val), nil
+	return []byte(val), true, nil
 }
 
-func (rdb *redisDBStore) getMultipleKeys(keys [][]byte) ([][]byte, error) {
+// getMultipleKeys relies on MGET conveying absent keys as nil values.
+func (rdb *redisDBStore) getMultipleKeys(keys [][]byte) ([][]byte, []bool, error) {
 	var strKeys []string
 	for _, key := range keys {
 		strKeys = append(strKeys, string(key))
 	}
+	// MGET reads all the given keys atomically
 	vals, err := rdb.db.MGet(strKeys...).Result()
-	if err != nil && !strings.HasSuffix(err.Error(), "nil") {
-		return nil, err
+	if err != nil && err != redis.Nil {
+		return nil, nil, err
 	}
-	results := make([][]byte, len(vals))
+	results, found := make([][]byte, len(vals)), make([]bool, len(vals))
 	for i, val := range vals {
-		results[i] = []byte(val.(string))
+		if strVal, ok := val.(string); ok {
+			results[i], found[i] = []byte(strVal), true
+		}
 	}
-	return results, nil
+	return results, found, nil
 }
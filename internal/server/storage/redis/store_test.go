@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/storagetest"
 )
 
 var store storage.KVStore
@@ -13,6 +14,8 @@ var store storage.KVStore
 const (
 	dbPort  = 6379
 	dbIndex = 3
+	// Flushed before every conformance test
+	conformanceDBIndex = 4
 )
 
 func TestMain(m *testing.M) {
@@ -26,55 +29,43 @@ func TestMain(m *testing.M) {
 	}
 }
 
-func TestPutAndGet(t *testing.T) {
-	numKeys := 10
-	for i := 1; i <= numKeys; i++ {
-		key, value := fmt.Sprintf("K%d", i), fmt.Sprintf("V%d", i)
-		if err := store.Put([]byte(key), []byte(value)); err != nil {
-			t.Fatalf("Unable to PUT. Key: %s, Value: %s, Error: %v", key, value, err)
+func TestConformance(t *testing.T) {
+	storagetest.RunConformanceTests(t, func(t *testing.T) (storage.KVStore, func() (storage.KVStore, error)) {
+		rdb, err := openStore(dbPort, conformanceDBIndex)
+		if err != nil {
+			t.Fatalf("Unable to connect to Redis. Error: %v", err)
 		}
-	}
-
-	for i := 1; i <= numKeys; i++ {
-		key, expectedValue := fmt.Sprintf("K%d", i), fmt.Sprintf("V%d", i)
-		if results, err := store.Get([]byte(key)); err != nil {
-			t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
-		} else if string(results[0]) != expectedValue {
-			t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expectedValue, results[0])
+		if err = rdb.db.FlushDB().Err(); err != nil {
+			t.Fatalf("Unable to flush Redis. Error: %v", err)
 		}
-	}
+		return rdb, func() (storage.KVStore, error) { return openStore(dbPort, conformanceDBIndex) }
+	})
 }
 
-func TestMultiGet(t *testing.T) {
-	numKeys := 10
-	keys, vals := make([][]byte, numKeys), make([]string, numKeys)
+func TestIterate(t *testing.T) {
+	numKeys, keyPrefix := 9, "IterKey*"
 	for i := 1; i <= numKeys; i++ {
-		key, value := fmt.Sprintf("MK%d", i), fmt.Sprintf("MV%d", i)
+		key, value := fmt.Sprintf("%s%d", keyPrefix, i), fmt.Sprintf("IterVal%d", i)
 		if err := store.Put([]byte(key), []byte(value)); err != nil {
 			t.Fatalf("Unable to PUT. Key: %s, Value: %s, Error: %v", key, value, err)
-		} else {
-			keys[i-1] = []byte(key)
-			vals[i-1] = value
 		}
 	}
-
-	if results, err := store.Get(keys...); err != nil {
-		t.Fatal(err)
-	} else {
-		for i, result := range results {
-			if string(result) != vals[i] {
-				t.Errorf("Multi Get value mismatch. Key: %s, Expected Value: %s, Actual Value: %s", keys[i], vals[i], result)
-			}
+	iteration := store.Iterate([]byte(keyPrefix), []byte(keyPrefix+"3"))
+	defer iteration.Close()
+	expKeyIdx := 3
+	for iteration.HasNext() {
+		key, val := iteration.Next()
+		expKey, expVal := fmt.Sprintf("%s%d", keyPrefix, expKeyIdx), fmt.Sprintf("IterVal%d", expKeyIdx)
+		if string(key) != expKey || string(val) != expVal {
+			t.Errorf("Iteration mismatch. Expected: %s=%s, Actual: %s=%s", expKey, expVal, key, val)
 		}
+		expKeyIdx++
 	}
-}
-
-func TestMissingGet(t *testing.T) {
-	key, expectedValue := "MissingKey", ""
-	if results, err := store.Get([]byte(key)); err != nil {
-		t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
-	} else if string(results[0]) != "" {
-		t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expectedValue, results[0])
+	if err := iteration.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if expKeyIdx != numKeys+1 {
+		t.Errorf("Expected iteration to end at key index %d, but it ended at %d", numKeys+1, expKeyIdx)
 	}
 }
 
@@ -106,7 +97,7 @@ func BenchmarkGetKey(b *testing.B) {
 		b.Fatalf("Unable to PUT. Key: %s. Error: %v", key, err)
 	}
 	for i := 0; i < b.N; i++ {
-		if results, err := store.Get([]byte(key)); err != nil {
+		if results, _, err := store.Get([]byte(key)); err != nil {
 			b.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
 		} else if string(results[0]) != val {
 			b.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, val, results[0])
@@ -117,7 +108,7 @@ func BenchmarkGetKey(b *testing.B) {
 func BenchmarkGetMissingKey(b *testing.B) {
 	key := "BMissingKey"
 	for i := 0; i < b.N; i++ {
-		if _, err := store.Get([]byte(key)); err != nil {
+		if _, _, err := store.Get([]byte(key)); err != nil {
 			b.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
 		}
 	}
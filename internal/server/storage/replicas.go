@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// DefaultReplicaExpiry is the duration after which a ReplicaTracker
+// forgets the replicas that retrieve no changes, unless specified
+// otherwise.
+const DefaultReplicaExpiry = 24 * time.Hour
+
+// ReplicaTracker tracks the progress of the replicas retrieving the
+// changes of a ChangePropagator, as identified by the replica IDs of
+// their requests. Replicas not seen within its expiry are forgotten.
+// It is safe for concurrent use.
+type ReplicaTracker struct {
+	expiry time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	replicas map[string]*serverpb.ReplicaProgress
+}
+
+// NewReplicaTracker creates a tracker that forgets the replicas not
+// seen within the given expiry, which defaults to DefaultReplicaExpiry.
+func NewReplicaTracker(expiry time.Duration) *ReplicaTracker {
+	if expiry <= 0 {
+		expiry = DefaultReplicaExpiry
+	}
+	return &ReplicaTracker{expiry: expiry, now: time.Now, replicas: make(map[string]*serverpb.ReplicaProgress)}
+}
+
+// Seen records the given request for changes, where the changes before
+// its FromChangeNumber are taken to be consumed by its replica. Requests
+// that identify no replica are ignored.
+func (rt *ReplicaTracker) Seen(getChngsReq *serverpb.GetChangesRequest) {
+	if getChngsReq.ReplicaID == "" || getChngsReq.FromChangeNumber == 0 {
+		return
+	}
+	rt.Consumed(getChngsReq.ReplicaID, getChngsReq.FromChangeNumber-1)
+}
+
+// Consumed records that the replica of the given ID has consumed the
+// changes upto the given change number.
+func (rt *ReplicaTracker) Consumed(replicaID string, chngNum uint64) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.replicas[replicaID] = &serverpb.ReplicaProgress{ReplicaID: replicaID, ConsumedChangeNumber: chngNum, LastSeenTimeMillis: rt.now().UnixNano() / int64(time.Millisecond)}
+}
+
+// Replicas returns the progress of the replicas seen within the
+// expiry, in the order of their IDs, with their replication lag
+// computed against the given latest change number.
+func (rt *ReplicaTracker) Replicas(latestChngNum uint64) []*serverpb.ReplicaProgress {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.expire()
+	replicas := make([]*serverpb.ReplicaProgress, 0, len(rt.replicas))
+	for _, replica := range rt.replicas {
+		progress := *replica
+		if latestChngNum > progress.ConsumedChangeNumber {
+			progress.ReplicationLag = latestChngNum - progress.ConsumedChangeNumber
+		}
+		replicas = append(replicas, &progress)
+	}
+	sort.Slice(replicas, func(i, j int) bool { return replicas[i].ReplicaID < replicas[j].ReplicaID })
+	return replicas
+}
+
+// minConsumed returns the least change number consumed amongst the
+// replicas seen within the expiry, and whether there are any.
+func (rt *ReplicaTracker) minConsumed() (uint64, bool) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.expire()
+	var minChngNum uint64
+	found := false
+	for _, replica := range rt.replicas {
+		if !found || replica.ConsumedChangeNumber < minChngNum {
+			minChngNum, found = replica.ConsumedChangeNumber, true
+		}
+	}
+	return minChngNum, found
+}
+
+func (rt *ReplicaTracker) expire() {
+	expiryMillis := rt.now().Add(-rt.expiry).UnixNano() / int64(time.Millisecond)
+	for id, replica := range rt.replicas {
+		if replica.LastSeenTimeMillis < expiryMillis {
+			delete(rt.replicas, id)
+		}
+	}
+}
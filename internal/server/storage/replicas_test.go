@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+func TestReplicaTracker(t *testing.T) {
+	rt := NewReplicaTracker(time.Hour)
+	now := time.Now()
+	rt.now = func() time.Time { return now }
+	rt.Seen(&serverpb.GetChangesRequest{ReplicaID: "slave2", FromChangeNumber: 96})
+	rt.Seen(&serverpb.GetChangesRequest{ReplicaID: "slave1", FromChangeNumber: 51})
+	// Anonymous replicas are not tracked
+	rt.Seen(&serverpb.GetChangesRequest{FromChangeNumber: 11})
+	replicas := rt.Replicas(100)
+	if len(replicas) != 2 || replicas[0].ReplicaID != "slave1" || replicas[0].ConsumedChangeNumber != 50 || replicas[0].ReplicationLag != 50 || replicas[1].ReplicationLag != 5 {
+		t.Errorf("Replicas mismatch. Actual: %v", replicas)
+	}
+
+	now = now.Add(50 * time.Minute)
+	rt.Seen(&serverpb.GetChangesRequest{ReplicaID: "slave2", FromChangeNumber: 101})
+	now = now.Add(20 * time.Minute)
+	if replicas = rt.Replicas(100); len(replicas) != 1 || replicas[0].ReplicaID != "slave2" || replicas[0].ReplicationLag != 0 {
+		t.Errorf("Expected only slave2 to be tracked without any lag. Actual: %v", replicas)
+	}
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// A ChangeLogTruncater represents the capability of a ChangePropagator
+// to report the range of changes it retains, and to discard the oldest
+// of these on demand.
+type ChangeLogTruncater interface {
+	// RetainedChanges returns the change numbers of the oldest change
+	// retained and of the latest change, where the former exceeds the
+	// latter when no changes are retained.
+	RetainedChanges() (uint64, uint64, error)
+	// TruncateChanges discards the changes before the given change
+	// number, upon which LoadChanges fails with ErrChangesTruncated
+	// for them.
+	TruncateChanges(beforeChangeNumber uint64) error
+}
+
+// ErrChangesTruncated indicates that the requested changes are discarded
+// as per the retention policy. It matches ErrChangesUnavailable as well.
+var ErrChangesTruncated = dkverrors.ErrChangesTruncated
+
+// RetentionPolicy governs the changes retained by a ChangeLogRetainer,
+// where the zero value of every limit disables it. Changes beyond any of
+// the limits are truncated, unless these are retained for the replicas.
+type RetentionPolicy struct {
+	// MaxAge is the duration for which changes are retained once they
+	// are committed, which is tracked at the granularity of the Interval.
+	MaxAge time.Duration
+	// MaxChanges is the number of latest changes retained.
+	MaxChanges uint64
+	// RetainUnconsumed retains the changes yet to be consumed by any of
+	// the replicas tracked, even beyond the limits. It is not supported
+	// by the engines purging their changes on their own, like RocksDB.
+	RetainUnconsumed bool
+	// Interval at which changes are truncated, which defaults to
+	// DefaultRetentionInterval.
+	Interval time.Duration
+}
+
+// DefaultRetentionInterval is the interval at which changes are
+// truncated, unless specified otherwise.
+const DefaultRetentionInterval = time.Minute
+
+func (rp RetentionPolicy) limited() bool {
+	return rp.MaxAge > 0 || rp.MaxChanges > 0
+}
+
+// ChangeLogRetainer periodically truncates the changes of a
+// ChangeLogTruncater as per a RetentionPolicy, retaining those yet to
+// be consumed by the replicas of a ReplicaTracker if required. It is
+// safe for concurrent use.
+type ChangeLogRetainer struct {
+	clt      ChangeLogTruncater
+	policy   RetentionPolicy
+	replicas *ReplicaTracker
+	lgr      *zap.Logger
+	now      func() time.Time
+
+	mu sync.Mutex
+	// commits are the latest change numbers sampled at every interval,
+	// in the order of their times, for tracking the age of the changes
+	commits []commitSample
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+type commitSample struct {
+	time    time.Time
+	chngNum uint64
+}
+
+// NewChangeLogRetainer creates a retainer of the changes of the given
+// ChangeLogTruncater as per the given policy, consulting the given
+// ReplicaTracker for the changes consumed by the replicas. It must be
+// started for the changes to be truncated periodically.
+func NewChangeLogRetainer(clt ChangeLogTruncater, policy RetentionPolicy, replicas *ReplicaTracker, lgr *zap.Logger) *ChangeLogRetainer {
+	if policy.Interval <= 0 {
+		policy.Interval = DefaultRetentionInterval
+	}
+	return &ChangeLogRetainer{
+		clt:      clt,
+		policy:   policy,
+		replicas: replicas,
+		lgr:      lgr,
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start truncates the changes at every interval of the policy in the
+// background, until stopped. Nothing is truncated if the policy limits
+// neither the age nor the number of changes.
+func (clr *ChangeLogRetainer) Start() {
+	if !clr.policy.limited() {
+		return
+	}
+	go func() {
+		tckr := time.NewTicker(clr.policy.Interval)
+		defer tckr.Stop()
+		for {
+			select {
+			case <-clr.stopChan:
+				return
+			case <-tckr.C:
+				if _, err := clr.Truncate(); err != nil {
+					clr.lgr.Warn("Unable to truncate the change log", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops truncating the changes periodically.
+func (clr *ChangeLogRetainer) Stop() {
+	clr.stopOnce.Do(func() { close(clr.stopChan) })
+}
+
+// Truncate truncates the changes beyond the limits of the policy right
+// away, returning the change number of the oldest change retained.
+func (clr *ChangeLogRetainer) Truncate() (uint64, error) {
+	firstChngNum, lastChngNum, err := clr.clt.RetainedChanges()
+	if err != nil {
+		return 0, err
+	}
+	clr.mu.Lock()
+	beforeChngNum := clr.truncationPoint(lastChngNum)
+	clr.mu.Unlock()
+	if beforeChngNum <= firstChngNum {
+		return firstChngNum, nil
+	}
+	return clr.TruncateBefore(beforeChngNum)
+}
+
+// truncationPoint computes the change number before which the changes
+// can be truncated, given the latest change number, while sampling it.
+func (clr *ChangeLogRetainer) truncationPoint(lastChngNum uint64) uint64 {
+	now := clr.now()
+	clr.commits = append(clr.commits, commitSample{now, lastChngNum})
+	var beforeChngNum uint64
+	if maxChngs := clr.policy.MaxChanges; maxChngs > 0 && lastChngNum > maxChngs {
+		beforeChngNum = lastChngNum - maxChngs + 1
+	}
+	if clr.policy.MaxAge > 0 {
+		// Changes upto the latest sample older than the maximum age are
+		// older too, hence only that sample and those after are needed
+		cutoff := now.Add(-clr.policy.MaxAge)
+		i := sort.Search(len(clr.commits), func(i int) bool { return clr.commits[i].time.After(cutoff) })
+		if i > 0 {
+			if oldChngNum := clr.commits[i-1].chngNum; oldChngNum+1 > beforeChngNum {
+				beforeChngNum = oldChngNum + 1
+			}
+			clr.commits = clr.commits[i-1:]
+		}
+	} else {
+		clr.commits = clr.commits[len(clr.commits)-1:]
+	}
+	if clr.policy.RetainUnconsumed {
+		if chngNum, found := clr.replicas.minConsumed(); found && chngNum+1 < beforeChngNum {
+			beforeChngNum = chngNum + 1
+		}
+	}
+	return beforeChngNum
+}
+
+// TruncateBefore truncates the changes before the given change number,
+// irrespective of the policy, returning the change number of the oldest
+// change retained. Changes after the latest change are never truncated.
+func (clr *ChangeLogRetainer) TruncateBefore(beforeChngNum uint64) (uint64, error) {
+	firstChngNum, lastChngNum, err := clr.clt.RetainedChanges()
+	if err != nil {
+		return 0, err
+	}
+	if beforeChngNum > lastChngNum+1 {
+		beforeChngNum = lastChngNum + 1
+	}
+	if beforeChngNum <= firstChngNum {
+		return firstChngNum, nil
+	}
+	if err = clr.clt.TruncateChanges(beforeChngNum); err != nil {
+		return 0, err
+	}
+	clr.lgr.Info("Truncated the change log", zap.Uint64("beforeChangeNum", beforeChngNum))
+	return beforeChngNum, nil
+}
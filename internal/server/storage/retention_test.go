@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// truncLog retains the changes from its first change number
+// upto its latest change number
+type truncLog struct {
+	first, last uint64
+}
+
+func (tl *truncLog) RetainedChanges() (uint64, uint64, error) {
+	return tl.first, tl.last, nil
+}
+
+func (tl *truncLog) TruncateChanges(beforeChangeNumber uint64) error {
+	tl.first = beforeChangeNumber
+	return nil
+}
+
+func checkTruncation(t *testing.T, clr *ChangeLogRetainer, expFirst uint64) {
+	t.Helper()
+	if first, err := clr.Truncate(); err != nil || first != expFirst {
+		t.Errorf("Expected changes from %d to be retained. Actual: %d, Error: %v", expFirst, first, err)
+	}
+}
+
+func TestRetainMaxChanges(t *testing.T) {
+	tl := &truncLog{first: 1, last: 100}
+	clr := NewChangeLogRetainer(tl, RetentionPolicy{MaxChanges: 10}, NewReplicaTracker(0), zap.NewNop())
+	checkTruncation(t, clr, 91)
+	tl.last = 105
+	checkTruncation(t, clr, 96)
+	// Forced truncations ignore the policy, but retain the latest change
+	if first, err := clr.TruncateBefore(200); err != nil || first != 106 || tl.first != 106 {
+		t.Errorf("Expected all the changes to be truncated. Actual: %d, Error: %v", first, err)
+	}
+}
+
+func TestRetainMaxAge(t *testing.T) {
+	tl := &truncLog{first: 1, last: 10}
+	clr := NewChangeLogRetainer(tl, RetentionPolicy{MaxAge: time.Hour}, NewReplicaTracker(0), zap.NewNop())
+	now := time.Now()
+	clr.now = func() time.Time { return now }
+	checkTruncation(t, clr, 1)
+	now, tl.last = now.Add(30*time.Minute), 20
+	checkTruncation(t, clr, 1)
+	// Only the changes upto the sample older than an hour are truncated
+	now, tl.last = now.Add(40*time.Minute), 30
+	checkTruncation(t, clr, 11)
+	now = now.Add(time.Hour)
+	checkTruncation(t, clr, 31)
+}
+
+func TestRetainUnconsumed(t *testing.T) {
+	tl := &truncLog{first: 1, last: 100}
+	rt := NewReplicaTracker(time.Hour)
+	now := time.Now()
+	rt.now = func() time.Time { return now }
+	clr := NewChangeLogRetainer(tl, RetentionPolicy{MaxChanges: 10, RetainUnconsumed: true}, rt, zap.NewNop())
+	rt.Consumed("slave1", 50)
+	rt.Consumed("slave2", 95)
+	checkTruncation(t, clr, 51)
+
+	// Replicas not seen within the expiry are no longer waited upon
+	now = now.Add(50 * time.Minute)
+	rt.Consumed("slave2", 98)
+	now = now.Add(20 * time.Minute)
+	checkTruncation(t, clr, 91)
+}
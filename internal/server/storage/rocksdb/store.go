@@ -1,16 +1,28 @@
 package rocksdb
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
 	"github.com/tecbot/gorocksdb"
+	"go.uber.org/zap"
 )
 
 // DB interface represents the capabilities exposed
@@ -19,7 +31,15 @@ type DB interface {
 	storage.KVStore
 	storage.Backupable
 	storage.ChangePropagator
+	storage.ChangeIterator
 	storage.ChangeApplier
+	storage.ReplicationStateKeeper
+	storage.StatsProvider
+	storage.Compacter
+	storage.Flusher
+	storage.BulkLoader
+	storage.ChangeLogTruncater
+	storage.IntegrityVerifier
 }
 
 type rocksDB struct {
@@ -29,8 +49,25 @@ type rocksDB struct {
 	// Indicates a global mutation like backup and restore that
 	// require exclusivity. Shall be manipulated using atomics.
 	globalMutation uint32
+
+	// Sharded locks that serialize the writes on keys belonging to
+	// the same shard, so that the read-modify-write operations like
+	// compare and set are atomic with respect to the plain writes.
+	keyLocks []sync.Mutex
+
+	// Change number of the change marking the latest bulk load, and
+	// whether a bulk load is in progress. Shall be manipulated using
+	// atomics.
+	bulkLoadChngNum uint64
+	bulkLoading     uint32
+
+	// Changes before this change number were truncated on demand.
+	// Shall be manipulated using atomics.
+	truncChngNum uint64
 }
 
+const numKeyLockShards = 256
+
 // Opts holds the various options required for configuring
 // the RocksDB storage engine.
 type Opts struct {
@@ -38,6 +75,8 @@ type Opts struct {
 	rocksDBOpts    *gorocksdb.Options
 	restoreOpts    *gorocksdb.RestoreOptions
 	folderName     string
+	readOnly       bool
+	lgr            *zap.Logger
 }
 
 // OpenDB initializes a new instance of RocksDB with default
@@ -52,6 +91,75 @@ func OpenDB(dbFolder string, cacheSize uint64) DB {
 	}
 }
 
+// Open initializes a new instance of RocksDB with the given options.
+func Open(opts *Opts) (DB, error) {
+	return openStore(opts)
+}
+
+// Repair repairs the RocksDB folder of the given options, which must not
+// be open, recovering as much of its data as possible. Files that can not
+// be recovered are moved into its lost folder. Returns the descriptions
+// of the files thus fixed.
+func Repair(opts *Opts) ([]string, error) {
+	before, err := listFiles(opts.folderName)
+	if err != nil {
+		return nil, err
+	}
+	lostBefore, err := listFiles(path.Join(opts.folderName, "lost"))
+	if err != nil {
+		return nil, err
+	}
+	if err = gorocksdb.RepairDb(opts.folderName, opts.rocksDBOpts); err != nil {
+		return nil, err
+	}
+	after, err := listFiles(opts.folderName)
+	if err != nil {
+		return nil, err
+	}
+	lostAfter, err := listFiles(path.Join(opts.folderName, "lost"))
+	if err != nil {
+		return nil, err
+	}
+	var fixes []string
+	for _, name := range sortedKeys(lostAfter) {
+		if !lostBefore[name] {
+			fixes = append(fixes, fmt.Sprintf("moved unrecoverable file %s into the lost folder", name))
+		}
+	}
+	// Write ahead logs are converted into tables by the repair
+	for _, name := range sortedKeys(before) {
+		if !after[name] && strings.HasSuffix(name, ".log") && !lostAfter[name] {
+			fixes = append(fixes, fmt.Sprintf("recovered write ahead log %s into tables", name))
+		}
+	}
+	return fixes, nil
+}
+
+// listFiles lists the names of the files in the given folder, which is
+// considered empty if missing.
+func listFiles(folder string) (map[string]bool, error) {
+	infos, err := ioutil.ReadDir(folder)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	files := make(map[string]bool, len(infos))
+	for _, info := range infos {
+		if !info.IsDir() {
+			files[info.Name()] = true
+		}
+	}
+	return files, err
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // NewOptions initializes an instance of RocksDB options with
 // default settings. It can be used to customize specific parameters
 // of the underlying RocksDB storage engine.
@@ -59,8 +167,9 @@ func NewOptions() *Opts {
 	bbto := gorocksdb.NewDefaultBlockBasedTableOptions()
 	opts := gorocksdb.NewDefaultOptions()
 	opts.SetBlockBasedTableFactory(bbto)
+	opts.SetCompactionFilter(expiryFilter{})
 	rstOpts := gorocksdb.NewRestoreOptions()
-	return &Opts{blockTableOpts: bbto, rocksDBOpts: opts, restoreOpts: rstOpts}
+	return &Opts{blockTableOpts: bbto, rocksDBOpts: opts, restoreOpts: rstOpts, lgr: zap.NewNop()}
 }
 
 // CacheSize can be used to set the RocksDB block cache size.
@@ -82,6 +191,26 @@ func (rdbOpts *Opts) DBFolder(name string) *Opts {
 	return rdbOpts
 }
 
+// ReadOnly opens RocksDB in read only mode, in which the keyspace can
+// be read while any mutation fails, like for migrating it elsewhere.
+func (rdbOpts *Opts) ReadOnly(flag bool) *Opts {
+	rdbOpts.readOnly = flag
+	return rdbOpts
+}
+
+// ParanoidChecks makes RocksDB check its files aggressively, failing
+// the DB as soon as any of them is found corrupted.
+func (rdbOpts *Opts) ParanoidChecks(flag bool) *Opts {
+	rdbOpts.rocksDBOpts.SetParanoidChecks(flag)
+	return rdbOpts
+}
+
+// Logger sets the logger used for logging the restores of RocksDB.
+func (rdbOpts *Opts) Logger(lgr *zap.Logger) *Opts {
+	rdbOpts.lgr = lgr
+	return rdbOpts
+}
+
 func (rdbOpts *Opts) destroy() {
 	rdbOpts.blockTableOpts.Destroy()
 	rdbOpts.rocksDBOpts.Destroy()
@@ -89,11 +218,28 @@ func (rdbOpts *Opts) destroy() {
 }
 
 func openStore(opts *Opts) (*rocksDB, error) {
-	db, err := gorocksdb.OpenDb(opts.rocksDBOpts, opts.folderName)
+	var db *gorocksdb.DB
+	var err error
+	if opts.readOnly {
+		db, err = gorocksdb.OpenDbForReadOnly(opts.rocksDBOpts, opts.folderName, false)
+	} else {
+		db, err = gorocksdb.OpenDb(opts.rocksDBOpts, opts.folderName)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &rocksDB{db, opts, 0}, nil
+	rdb := &rocksDB{db: db, opts: opts, keyLocks: make([]sync.Mutex, numKeyLockShards)}
+	ro := gorocksdb.NewDefaultReadOptions()
+	defer ro.Destroy()
+	bulkLoadVal, err := rdb.getSingleKey(ro, []byte(bulkLoadChangeNumberKey))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if len(bulkLoadVal) > 0 {
+		rdb.bulkLoadChngNum = binary.BigEndian.Uint64(bulkLoadVal)
+	}
+	return rdb, nil
 }
 
 func (rdb *rocksDB) Close() error {
@@ -102,24 +248,606 @@ func (rdb *rocksDB) Close() error {
 	return nil
 }
 
+// Sync flushes the memtables onto SST files, since writes are
+// already synced onto the WAL as and when they are accepted.
+func (rdb *rocksDB) Sync() error {
+	return rdb.Flush()
+}
+
+// Flush writes the memtables onto SST files, waiting for it to complete.
+func (rdb *rocksDB) Flush() error {
+	fo := gorocksdb.NewDefaultFlushOptions()
+	defer fo.Destroy()
+	fo.SetWait(true)
+	return rdb.db.Flush(fo)
+}
+
+// VerifyIntegrity reads through all the keys of RocksDB while verifying
+// the checksums of the blocks read, without filling the block cache.
+func (rdb *rocksDB) VerifyIntegrity() error {
+	ro := gorocksdb.NewDefaultReadOptions()
+	defer ro.Destroy()
+	ro.SetVerifyChecksums(true)
+	ro.SetFillCache(false)
+	it := rdb.db.NewIterator(ro)
+	defer it.Close()
+	for it.SeekToFirst(); it.Valid(); it.Next() {
+	}
+	return it.Err()
+}
+
+// CompactRange compacts the SST files holding the given range of keys
+// across all the levels. It excludes backups and restores, so that the
+// DB being compacted is not closed by a restore midway.
+func (rdb *rocksDB) CompactRange(startKey, endKey []byte) error {
+	if err := rdb.beginGlobalMutation(); err != nil {
+		return err
+	}
+	defer rdb.endGlobalMutation()
+	rdb.db.CompactRange(gorocksdb.Range{Start: startKey, Limit: endKey})
+	return nil
+}
+
+// Properties of RocksDB reported as its engine specific statistics.
+var statsProperties = []string{
+	"rocksdb.estimate-num-keys",
+	"rocksdb.total-sst-files-size",
+	"rocksdb.live-sst-files-size",
+	"rocksdb.estimate-live-data-size",
+	"rocksdb.estimate-pending-compaction-bytes",
+	"rocksdb.compaction-pending",
+	"rocksdb.num-running-compactions",
+	"rocksdb.num-running-flushes",
+	"rocksdb.cur-size-all-mem-tables",
+	"rocksdb.size-all-mem-tables",
+	"rocksdb.num-immutable-mem-table",
+	"rocksdb.estimate-table-readers-mem",
+	"rocksdb.block-cache-usage",
+	"rocksdb.num-snapshots",
+}
+
+// GetStorageStats reports the properties of RocksDB. Since RocksDB does
+// not track when it compacts, the time of the latest compaction is that
+// of the latest SST file beyond level 0, which only compactions write.
+func (rdb *rocksDB) GetStorageStats() (*storage.Stats, error) {
+	engineStats := make(map[string]string, len(statsProperties))
+	for _, prop := range statsProperties {
+		engineStats[prop] = rdb.db.GetProperty(prop)
+	}
+	numKeys, _ := strconv.ParseUint(engineStats["rocksdb.estimate-num-keys"], 10, 64)
+	compactedFiles := make(map[string]bool)
+	for _, file := range rdb.db.GetLiveFilesMetaData() {
+		if file.Level > 0 {
+			compactedFiles[path.Base(file.Name)] = true
+		}
+	}
+	isCompacted := func(file string) bool { return compactedFiles[path.Base(file)] }
+	diskUsage, lastCompactionTime, err := storage.DiskUsage(isCompacted, rdb.opts.folderName)
+	if err != nil {
+		return nil, err
+	}
+	return &storage.Stats{Engine: "rocksdb", ApproxNumKeys: numKeys, DiskUsage: diskUsage, LastCompactionTime: lastCompactionTime, EngineStats: engineStats}, nil
+}
+
+// EstimatePrefix estimates the stats of the keys with the given prefix,
+// along with those of their expiring keys, from the approximate size of
+// the SST files holding them. The number of keys is then the share of the
+// estimated number of keys in proportion to that size. Hence the keys yet
+// to be flushed onto the SST files are not accounted.
+func (rdb *rocksDB) EstimatePrefix(prefix []byte) (*storage.PrefixStats, error) {
+	numKeys, _ := strconv.ParseUint(rdb.db.GetProperty("rocksdb.estimate-num-keys"), 10, 64)
+	totalSize, _ := strconv.ParseUint(rdb.db.GetProperty("rocksdb.total-sst-files-size"), 10, 64)
+	if len(prefix) == 0 {
+		return &storage.PrefixStats{NumKeys: numKeys, Size: totalSize}, nil
+	}
+	ranges := []gorocksdb.Range{prefixRange(prefix)}
+	// Short prefixes like _dkv may already cover the expiring keys
+	if expPrefix := expiringKey(prefix); !bytes.HasPrefix(expPrefix, prefix) {
+		ranges = append(ranges, prefixRange(expPrefix))
+	}
+	var size uint64
+	for _, rngSize := range rdb.db.GetApproximateSizes(ranges) {
+		size += rngSize
+	}
+	stats := &storage.PrefixStats{Size: size}
+	if totalSize > 0 {
+		stats.NumKeys = uint64(float64(numKeys) * float64(size) / float64(totalSize))
+	}
+	return stats, nil
+}
+
+func prefixRange(prefix []byte) gorocksdb.Range {
+	end := storage.PrefixEnd(prefix)
+	if end == nil {
+		// Approximate for prefixes of only 0xFF bytes, having no successor
+		end = append(append([]byte{}, prefix...), 0xff)
+	}
+	return gorocksdb.Range{Start: prefix, Limit: end}
+}
+
 func (rdb *rocksDB) Put(key []byte, value []byte) error {
+	defer rdb.lockKeys(key)()
+	return rdb.put(key, value)
+}
+
+// put writes the given key while its lock is held by the caller.
+func (rdb *rocksDB) put(key []byte, value []byte) error {
 	wo := gorocksdb.NewDefaultWriteOptions()
 	wo.SetSync(true)
 	defer wo.Destroy()
 	return rdb.db.Put(wo, key, value)
 }
 
-func (rdb *rocksDB) Get(keys ...[]byte) ([][]byte, error) {
+func (rdb *rocksDB) MultiPut(puts ...*serverpb.PutRequest) error {
+	return rdb.MultiPutWithSync(true, puts...)
+}
+
+// MultiPutWithSync writes the given puts through the WAL as always, so
+// that they can be replicated, but fsyncs the WAL only if sync is true.
+// Unsynced puts may hence be lost upon a crash of the node, in which
+// case the changes after the last synced write are lost as a whole.
+func (rdb *rocksDB) MultiPutWithSync(sync bool, puts ...*serverpb.PutRequest) error {
+	defer rdb.lockKeys(keysOf(puts)...)()
+	wb := newPutBatch(puts)
+	defer wb.Destroy()
+	wo := gorocksdb.NewDefaultWriteOptions()
+	wo.SetSync(sync)
+	defer wo.Destroy()
+	return rdb.db.Write(wo, wb)
+}
+
+func keysOf(puts []*serverpb.PutRequest) [][]byte {
+	keys := make([][]byte, len(puts))
+	for i, put := range puts {
+		keys[i] = put.Key
+	}
+	return keys
+}
+
+func newPutBatch(puts []*serverpb.PutRequest) *gorocksdb.WriteBatch {
+	wb := gorocksdb.NewWriteBatch()
+	for _, put := range puts {
+		if put.ExpireTS > 0 {
+			wb.Delete(put.Key)
+			wb.Put(expiringKey(put.Key), expiringValue(put.Value, put.ExpireTS))
+		} else {
+			wb.Put(put.Key, put.Value)
+		}
+	}
+	return wb
+}
+
+// BulkLoad writes the given entries without the WAL, which also holds
+// the changes loaded for replication. These entries are hence lost upon
+// a crash until they are flushed by FinishBulkLoad. The first load
+// marks the bulk load upfront, so that the changes before it are not
+// replicated even if the node crashes midway. Until the load finishes,
+// no changes are loaded at all, since the WAL misses the entries.
+func (rdb *rocksDB) BulkLoad(entries ...*serverpb.PutRequest) error {
+	if atomic.CompareAndSwapUint32(&rdb.bulkLoading, 0, 1) {
+		if err := rdb.markBulkLoad(); err != nil {
+			atomic.StoreUint32(&rdb.bulkLoading, 0)
+			return err
+		}
+	}
+	defer rdb.lockKeys(keysOf(entries)...)()
+	wb := newPutBatch(entries)
+	defer wb.Destroy()
+	wo := gorocksdb.NewDefaultWriteOptions()
+	wo.DisableWAL(true)
+	defer wo.Destroy()
+	return rdb.db.Write(wo, wb)
+}
+
+func (rdb *rocksDB) FinishBulkLoad() (uint64, error) {
+	if err := rdb.Flush(); err != nil {
+		return 0, err
+	}
+	if err := rdb.markBulkLoad(); err != nil {
+		return 0, err
+	}
+	atomic.StoreUint32(&rdb.bulkLoading, 0)
+	return atomic.LoadUint64(&rdb.bulkLoadChngNum), nil
+}
+
+// markBulkLoad records the change number of the write recording it.
+// Writes made concurrently may take this number instead, which is
+// harmless since no changes are loaded during the bulk load anyway.
+func (rdb *rocksDB) markBulkLoad() error {
+	chngNum := rdb.db.GetLatestSequenceNumber() + 1
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], chngNum)
+	wo := gorocksdb.NewDefaultWriteOptions()
+	wo.SetSync(true)
+	defer wo.Destroy()
+	if err := rdb.db.Put(wo, []byte(bulkLoadChangeNumberKey), buf[:]); err != nil {
+		return err
+	}
+	atomic.StoreUint64(&rdb.bulkLoadChngNum, chngNum)
+	return nil
+}
+
+func (rdb *rocksDB) CompareAndSet(key, expectedValue, newValue []byte) (bool, error) {
+	defer rdb.lockKeys(key)()
+
+	snap := rdb.db.NewSnapshot()
+	defer rdb.db.ReleaseSnapshot(snap)
 	ro := gorocksdb.NewDefaultReadOptions()
 	defer ro.Destroy()
+	ro.SetSnapshot(snap)
+	currValues, exists, err := rdb.lookupKeys(ro, [][]byte{key})
+	if err != nil {
+		return false, err
+	}
 
-	switch numKeys := len(keys); {
-	case numKeys == 1:
-		val, err := rdb.getSingleKey(ro, keys[0])
-		return [][]byte{val}, err
-	default:
-		return rdb.getMultipleKeys(ro, keys)
+	if len(expectedValue) == 0 {
+		if exists[0] {
+			return false, nil
+		}
+	} else if !storage.ValuesMatch(currValues[0], expectedValue) {
+		return false, nil
+	}
+
+	if err = rdb.put(key, newValue); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (rdb *rocksDB) Increment(key []byte, delta int64) (int64, error) {
+	// Serialized with the other read-modify-write operations
+	// on the same key using the key locks
+	defer rdb.lockKeys(key)()
+
+	snap := rdb.db.NewSnapshot()
+	defer rdb.db.ReleaseSnapshot(snap)
+	ro := gorocksdb.NewDefaultReadOptions()
+	defer ro.Destroy()
+	ro.SetSnapshot(snap)
+	currValues, _, err := rdb.lookupKeys(ro, [][]byte{key})
+	if err != nil {
+		return 0, err
+	}
+
+	res, resValue, err := storage.IncrementValue(currValues[0], delta)
+	if err != nil {
+		return 0, err
+	}
+	if err = rdb.put(key, resValue); err != nil {
+		return 0, err
+	}
+	return res, nil
+}
+
+// lockKeys acquires the locks of the given keys in the order of their
+// shards, so that concurrent writes on overlapping keys do not deadlock,
+// and returns the function releasing them.
+func (rdb *rocksDB) lockKeys(keys ...[]byte) func() {
+	var locked [numKeyLockShards]bool
+	for _, key := range keys {
+		locked[keyLockShard(key)] = true
+	}
+	for shard := range locked {
+		if locked[shard] {
+			rdb.keyLocks[shard].Lock()
+		}
+	}
+	return func() {
+		for shard := range locked {
+			if locked[shard] {
+				rdb.keyLocks[shard].Unlock()
+			}
+		}
+	}
+}
+
+func keyLockShard(key []byte) uint32 {
+	hash := fnv.New32a()
+	hash.Write(key)
+	return hash.Sum32() % numKeyLockShards
+}
+
+func (rdb *rocksDB) Txn(txnReq *serverpb.TxnRequest) (bool, error) {
+	// Serialized with the other writes on any of the keys involved
+	defer rdb.lockKeys(storage.TxnKeys(txnReq)...)()
+
+	snap := rdb.db.NewSnapshot()
+	defer rdb.db.ReleaseSnapshot(snap)
+	ro := gorocksdb.NewDefaultReadOptions()
+	defer ro.Destroy()
+	ro.SetSnapshot(snap)
+	values, exists, err := rdb.lookupKeys(ro, storage.TxnConditionKeys(txnReq))
+	if err != nil {
+		return false, err
+	}
+	succeeded := storage.TxnConditionsHold(txnReq, values, exists)
+	muts := storage.TxnMutations(txnReq, succeeded)
+	if len(muts) == 0 {
+		return succeeded, nil
+	}
+
+	// A single batch is a single change in the WAL,
+	// hence gets replicated atomically as well
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+	for _, mut := range muts {
+		switch {
+		case mut.Type == serverpb.TrxnRecord_Delete:
+			wb.Delete(mut.Key)
+			wb.Delete(expiringKey(mut.Key))
+		case mut.ExpireTS > 0:
+			wb.Delete(mut.Key)
+			wb.Put(expiringKey(mut.Key), expiringValue(mut.Value, mut.ExpireTS))
+		default:
+			wb.Put(mut.Key, mut.Value)
+		}
+	}
+	wo := gorocksdb.NewDefaultWriteOptions()
+	wo.SetSync(true)
+	defer wo.Destroy()
+	if err = rdb.db.Write(wo, wb); err != nil {
+		return false, err
+	}
+	return succeeded, nil
+}
+
+func (rdb *rocksDB) Delete(keys ...[]byte) error {
+	defer rdb.lockKeys(keys...)()
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+	for _, key := range keys {
+		wb.Delete(key)
+		wb.Delete(expiringKey(key))
+	}
+	wo := gorocksdb.NewDefaultWriteOptions()
+	wo.SetSync(true)
+	defer wo.Destroy()
+	return rdb.db.Write(wo, wb)
+}
+
+func (rdb *rocksDB) Get(keys ...[]byte) ([][]byte, []bool, error) {
+	return rdb.GetWithContext(context.Background(), keys...)
+}
+
+// GetWithContext looks up the keys in batches, so that the context can
+// be checked between the batches.
+func (rdb *rocksDB) GetWithContext(ctx context.Context, keys ...[]byte) ([][]byte, []bool, error) {
+	// All the keys, along with their expiring entries, are looked up
+	// from the same snapshot
+	snap := rdb.db.NewSnapshot()
+	defer rdb.db.ReleaseSnapshot(snap)
+	ro := gorocksdb.NewDefaultReadOptions()
+	defer ro.Destroy()
+	ro.SetSnapshot(snap)
+
+	if len(keys) <= storage.ContextCheckInterval {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		return rdb.lookupKeys(ro, keys)
+	}
+	results, found := make([][]byte, 0, len(keys)), make([]bool, 0, len(keys))
+	for len(keys) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		n := storage.ContextCheckInterval
+		if n > len(keys) {
+			n = len(keys)
+		}
+		batchResults, batchFound, err := rdb.lookupKeys(ro, keys[:n])
+		if err != nil {
+			return nil, nil, err
+		}
+		results, found, keys = append(results, batchResults...), append(found, batchFound...), keys[n:]
+	}
+	return results, found, nil
+}
+
+// lookupKeys loads the values of the given keys along with their
+// presence. Expiring entries are looked up only for the keys absent
+// under their original keys, since the latter take precedence.
+func (rdb *rocksDB) lookupKeys(ro *gorocksdb.ReadOptions, keys [][]byte) ([][]byte, []bool, error) {
+	values, err := rdb.getSlices(ro, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	results, exists := make([][]byte, len(keys)), make([]bool, len(keys))
+	var expKeys [][]byte
+	var expIdxs []int
+	for i, value := range values {
+		if exists[i] = value.Exists(); !exists[i] {
+			expKeys, expIdxs = append(expKeys, expiringKey(keys[i])), append(expIdxs, i)
+		}
+		results[i] = toByteArray(value)
+		value.Free()
+	}
+	if len(expKeys) == 0 {
+		return results, exists, nil
+	}
+
+	expValues, err := rdb.getSlices(ro, expKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	for j, value := range expValues {
+		if value.Exists() {
+			if val, expireTS := parseExpiringValue(value.Data()); !storage.IsExpired(expireTS) {
+				i := expIdxs[j]
+				results[i], exists[i] = byteArrayCopy(val, len(val)), true
+			}
+		}
+		value.Free()
+	}
+	return results, exists, nil
+}
+
+func (rdb *rocksDB) getSlices(ro *gorocksdb.ReadOptions, keys [][]byte) ([]*gorocksdb.Slice, error) {
+	if len(keys) == 1 {
+		value, err := rdb.db.Get(ro, keys[0])
+		if err != nil {
+			return nil, err
+		}
+		return []*gorocksdb.Slice{value}, nil
 	}
+	values, err := rdb.db.MultiGet(ro, keys...)
+	return values, err
+}
+
+// Exists answers presence using an iterator seek instead of a point
+// lookup, so that the associated values are never copied. Note that
+// the RocksDB binding does not expose `KeyMayExist`.
+func (rdb *rocksDB) Exists(keys ...[]byte) ([]bool, error) {
+	snap := rdb.db.NewSnapshot()
+	defer rdb.db.ReleaseSnapshot(snap)
+	ro := gorocksdb.NewDefaultReadOptions()
+	defer ro.Destroy()
+	ro.SetSnapshot(snap)
+	it := rdb.db.NewIterator(ro)
+	defer it.Close()
+
+	results := make([]bool, len(keys))
+	for i, key := range keys {
+		if it.Seek(key); it.Valid() && hasKey(it, key) {
+			results[i] = true
+			continue
+		}
+		// Values are loaded only for the expiring entries
+		expKey := expiringKey(key)
+		if it.Seek(expKey); it.Valid() && hasKey(it, expKey) {
+			val := it.Value()
+			_, expireTS := parseExpiringValue(val.Data())
+			results[i] = !storage.IsExpired(expireTS)
+			val.Free()
+		}
+	}
+	return results, it.Err()
+}
+
+func hasKey(it *gorocksdb.Iterator, key []byte) bool {
+	itKey := it.Key()
+	defer itKey.Free()
+	return bytes.Equal(itKey.Data(), key)
+}
+
+// An iter merges the iteration over the entries under their original
+// keys with that over the expiring entries, in the order of their keys.
+type iter struct {
+	db       *gorocksdb.DB
+	snap     *gorocksdb.Snapshot
+	readOpts *gorocksdb.ReadOptions
+	it       *gorocksdb.Iterator
+	prefix   []byte
+
+	expIt     *gorocksdb.Iterator
+	expPrefix []byte
+	expireTS  uint64
+	keysOnly  bool
+}
+
+func (rdb *rocksDB) Iterate(keyPrefix, startKey []byte) storage.Iterator {
+	return rdb.iterate(keyPrefix, startKey, false)
+}
+
+// IterateKeys is same as Iterate except that the values are not copied
+// out of RocksDB. Values of the expiring entries are still read for
+// their expiry times, which are stored alongside.
+func (rdb *rocksDB) IterateKeys(keyPrefix, startKey []byte) storage.Iterator {
+	return rdb.iterate(keyPrefix, startKey, true)
+}
+
+func (rdb *rocksDB) iterate(keyPrefix, startKey []byte, keysOnly bool) storage.Iterator {
+	snap := rdb.db.NewSnapshot()
+	readOpts := gorocksdb.NewDefaultReadOptions()
+	readOpts.SetSnapshot(snap)
+	strtKey := storage.IterationStartKey(keyPrefix, startKey)
+	it := rdb.db.NewIterator(readOpts)
+	it.Seek(strtKey)
+	expIt := rdb.db.NewIterator(readOpts)
+	expIt.Seek(expiringKey(strtKey))
+	return &iter{db: rdb.db, snap: snap, readOpts: readOpts, it: it, prefix: keyPrefix, expIt: expIt, expPrefix: expiringKey(keyPrefix), keysOnly: keysOnly}
+}
+
+func (rdbIter *iter) HasNext() bool {
+	// Skip over the internal metadata keys like the change number offset
+	for rdbIter.it.ValidForPrefix(rdbIter.prefix) && rdbIter.hasMetaKey() {
+		rdbIter.it.Next()
+	}
+	// Skip over the expiring entries that either expired or are shadowed
+	// by the current entry under its original key. Every other shadowed
+	// entry is skipped once the iteration reaches its original key.
+	for rdbIter.expIt.ValidForPrefix(rdbIter.expPrefix) && rdbIter.skipsExpiringEntry() {
+		rdbIter.expIt.Next()
+	}
+	return rdbIter.it.ValidForPrefix(rdbIter.prefix) || rdbIter.expIt.ValidForPrefix(rdbIter.expPrefix)
+}
+
+func (rdbIter *iter) hasMetaKey() bool {
+	key := rdbIter.it.Key()
+	defer key.Free()
+	return bytes.HasPrefix(key.Data(), metaKeyPrefix)
+}
+
+func (rdbIter *iter) skipsExpiringEntry() bool {
+	val := rdbIter.expIt.Value()
+	_, expireTS := parseExpiringValue(val.Data())
+	val.Free()
+	if storage.IsExpired(expireTS) {
+		return true
+	}
+	return rdbIter.it.ValidForPrefix(rdbIter.prefix) && rdbIter.compareKeys() == 0
+}
+
+// compareKeys compares the original key of the current expiring entry
+// with the current key of the other iteration.
+func (rdbIter *iter) compareKeys() int {
+	expKey, key := rdbIter.expIt.Key(), rdbIter.it.Key()
+	defer expKey.Free()
+	defer key.Free()
+	return bytes.Compare(expKey.Data()[len(expiringKeyPrefix):], key.Data())
+}
+
+func (rdbIter *iter) Next() ([]byte, []byte) {
+	if rdbIter.expIt.ValidForPrefix(rdbIter.expPrefix) && (!rdbIter.it.ValidForPrefix(rdbIter.prefix) || rdbIter.compareKeys() < 0) {
+		defer rdbIter.expIt.Next()
+		key, val := rdbIter.expIt.Key(), rdbIter.expIt.Value()
+		defer key.Free()
+		defer val.Free()
+		origKey := key.Data()[len(expiringKeyPrefix):]
+		value, expireTS := parseExpiringValue(val.Data())
+		rdbIter.expireTS = expireTS
+		if rdbIter.keysOnly {
+			return byteArrayCopy(origKey, len(origKey)), nil
+		}
+		return byteArrayCopy(origKey, len(origKey)), byteArrayCopy(value, len(value))
+	}
+
+	defer rdbIter.it.Next()
+	rdbIter.expireTS = 0
+	key := rdbIter.it.Key()
+	defer key.Free()
+	if rdbIter.keysOnly {
+		return toByteArray(key), nil
+	}
+	val := rdbIter.it.Value()
+	defer val.Free()
+	return toByteArray(key), toByteArray(val)
+}
+
+func (rdbIter *iter) ExpireTS() uint64 {
+	return rdbIter.expireTS
+}
+
+func (rdbIter *iter) Err() error {
+	return rdbIter.it.Err()
+}
+
+func (rdbIter *iter) Close() error {
+	rdbIter.it.Close()
+	rdbIter.expIt.Close()
+	rdbIter.readOpts.Destroy()
+	rdbIter.db.ReleaseSnapshot(rdbIter.snap)
+	return nil
 }
 
 const tempFilePrefix = "rocksdb-sstfile-"
@@ -192,6 +920,13 @@ func (rdb *rocksDB) PutSnapshot(snap []byte) error {
 	return err
 }
 
+const (
+	// Engine recorded in the info file of the backups of RocksDB stores
+	backupEngine = "rocksdb"
+	// File written by BackupTo alongside the files of the backup engine
+	backupInfoFile = "DKV_BACKUP_INFO"
+)
+
 func (rdb *rocksDB) BackupTo(folder string) error {
 	if err := checksForBackup(folder); err != nil {
 		return err
@@ -208,63 +943,185 @@ func (rdb *rocksDB) BackupTo(folder string) error {
 	}
 	defer be.Close()
 
+	// As with checkpoints, changes committed after this change
+	// number may also get captured by the backup. It is adjusted by
+	// the offset as with GetLatestAppliedChangeNumber, so that the
+	// backups of slaves record the change numbers of their master.
+	chngNum, err := adjustedChangeNumber(rdb.db)
+	if err != nil {
+		return err
+	}
+	if err = be.CreateNewBackupFlush(rdb.db, true); err != nil {
+		return err
+	}
 	// Retain only the latest backup in the given folder
-	defer be.PurgeOldBackups(1)
-	return be.CreateNewBackupFlush(rdb.db, true)
+	be.PurgeOldBackups(1)
+
+	// Record the info of the latest backup, which replaces that of
+	// any previous backup in the given folder
+	infoFile := path.Join(folder, backupInfoFile)
+	if err = os.RemoveAll(infoFile); err != nil {
+		return err
+	}
+	return storage.WriteBackupFile(infoFile, storage.BackupInfo{Engine: backupEngine, ChangeNumber: chngNum}, func(io.Writer) error { return nil })
 }
 
 const tempDirPrefix = "rocksdb-restore-"
 
-func (rdb *rocksDB) RestoreFrom(folder string) (err error) {
+func (rdb *rocksDB) RestoreFrom(folder string) error {
+	return rdb.RestoreFromParallel(folder, storage.DefaultRestoreParallelism, nil)
+}
+
+// Interval at which the progress of restores is measured
+const restoreProgressInterval = time.Second
+
+// RestoreFromParallel is same as RestoreFrom, with the progress tracked
+// by the size of the files restored so far. The backup engine of RocksDB
+// restores these files by itself, one at a time, regardless of the given
+// parallelism.
+func (rdb *rocksDB) RestoreFromParallel(folder string, parallelism int, progress *storage.RestoreProgress) error {
 	// 1. Prevent any other backups or restores
-	err = rdb.beginGlobalMutation()
-	if err != nil {
+	if err := rdb.beginGlobalMutation(); err != nil {
 		return err
 	}
 	defer rdb.endGlobalMutation()
 
-	// 2. Close the current DB to prevent further mutations
+	// 2. Verify the backup by restoring it onto a temp folder,
+	// leaving the current DB untouched should this fail
+	restoreFolder, _, err := rdb.restoreToTempFolder(folder, progress)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(restoreFolder)
+
+	// 3. Close the current DB to prevent further mutations
 	rdb.db.Close()
 
-	// 3. In any case, reopen the current DB
-	defer func() {
-		if finalDB, openErr := openStore(rdb.opts); openErr != nil {
-			err = openErr
-		} else {
-			*rdb = *finalDB
+	// 4. Move the temp folder onto the current DB folder, which is
+	// retained until the restored DB is reopened. In any case, the
+	// DB is reopened from whichever folder remains in place.
+	err = storage.SwapFolder(restoreFolder, rdb.opts.folderName, func() error {
+		finalDB, err := openStore(rdb.opts)
+		if err != nil {
+			rdb.opts.lgr.Error("Unable to reopen RocksDB after restore", zap.String("folder", rdb.opts.folderName), zap.Error(err))
+			return err
 		}
-	}()
+		rdb.db = finalDB.db
+		atomic.StoreUint64(&rdb.bulkLoadChngNum, finalDB.bulkLoadChngNum)
+		return nil
+	})
+	if err == nil {
+		rdb.opts.lgr.Info("Restored RocksDB from backup", zap.String("backupFolder", folder), zap.String("folder", rdb.opts.folderName))
+	}
+	return err
+}
 
-	// 4. Check for the given restore folder validity
-	err = checksForRestore(folder)
+func (rdb *rocksDB) VerifyBackup(folder string) (*storage.BackupInfo, error) {
+	restoreFolder, info, err := rdb.restoreToTempFolder(folder, nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	os.RemoveAll(restoreFolder)
+	return info, nil
+}
+
+// restoreToTempFolder verifies the given backup folder by restoring
+// it onto a temp folder alongside the folder of the current DB, which
+// is returned along with the info of the backup.
+func (rdb *rocksDB) restoreToTempFolder(folder string, progress *storage.RestoreProgress) (_ string, _ *storage.BackupInfo, err error) {
+	// 1. Check for the given restore folder validity
+	if err = checksForRestore(folder); err != nil {
+		return "", nil, err
 	}
 
-	// 5. Open the backup engine with the given restore folder
+	// 2. Read the info of the backup, verifying its checksum, which
+	// is absent for backups taken by earlier versions
+	info := new(storage.BackupInfo)
+	if infoRdr, bckpInfo, infoErr := storage.OpenBackupFile(path.Join(folder, backupInfoFile), backupEngine); infoErr == nil {
+		infoRdr.Close()
+		info = bckpInfo
+	} else if !os.IsNotExist(infoErr) {
+		return "", nil, infoErr
+	}
+
+	// 3. Open the backup engine with the given restore folder
 	be, err := rdb.openBackupEngine(folder)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 	defer be.Close()
+	beInfo := be.GetInfo()
+	defer beInfo.Destroy()
+	numBckps := beInfo.GetCount()
+	if numBckps == 0 {
+		return "", nil, fmt.Errorf("no backup of rocksdb store found in %s: %w", folder, dkverrors.ErrInvalidArgument)
+	}
+	info.Size = uint64(beInfo.GetSize(numBckps - 1))
 
-	// 6. Create temp folder for the restored data
-	restoreFolder, err := storage.CreateTempFolder(tempDirPrefix)
+	// 4. Create temp folder for the restored data
+	restoreFolder, err := storage.CreateSiblingFolder(rdb.opts.folderName, tempDirPrefix)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(restoreFolder)
+		}
+	}()
 
-	// 7. Restore DB onto the temp folder
+	// 5. Restore DB onto the temp folder, which verifies the
+	// checksums of the files of the backup
+	stopTracking := trackRestore(restoreFolder, info.Size, progress)
 	err = be.RestoreDBFromLatestBackup(restoreFolder, restoreFolder, rdb.opts.restoreOpts)
+	stopTracking()
 	if err != nil {
-		return err
+		return "", nil, fmt.Errorf("invalid backup of rocksdb store: %v: %w", err, dkverrors.ErrInvalidArgument)
 	}
+	progress.SetBytes(info.Size, info.Size)
 
-	// 8. Move the temp folder to the original DB location
-	err = storage.RenameFolder(restoreFolder, rdb.opts.folderName)
+	// 6. Ensure that the restored DB holds every change recorded
+	// by the backup, which is unknown for backups without info
+	restoredDB, err := gorocksdb.OpenDbForReadOnly(rdb.opts.rocksDBOpts, restoreFolder, false)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid backup of rocksdb store: %v: %w", err, dkverrors.ErrInvalidArgument)
+	}
+	chngNum, err := adjustedChangeNumber(restoredDB)
+	restoredDB.Close()
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid backup of rocksdb store: %v: %w", err, dkverrors.ErrInvalidArgument)
+	}
+	if chngNum < info.ChangeNumber {
+		return "", nil, fmt.Errorf("backup holds changes only until %d, short of %d: %w", chngNum, info.ChangeNumber, dkverrors.ErrInvalidArgument)
+	}
+	info.Engine, info.ChangeNumber = backupEngine, chngNum
+	return restoreFolder, info, nil
+}
 
-	// Plain return due to defer function above
-	return
+// trackRestore periodically records the size of the given folder onto
+// the given progress, until the returned function is called.
+func trackRestore(restoreFolder string, totalBytes uint64, progress *storage.RestoreProgress) func() {
+	if progress == nil {
+		return func() {}
+	}
+	progress.SetBytes(0, totalBytes)
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(restoreProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				progress.SetBytes(storage.PathSize(restoreFolder), totalBytes)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+	}
 }
 
 func (rdb *rocksDB) GetLatestCommittedChangeNumber() (uint64, error) {
@@ -272,24 +1129,188 @@ func (rdb *rocksDB) GetLatestCommittedChangeNumber() (uint64, error) {
 }
 
 func (rdb *rocksDB) LoadChanges(fromChangeNumber uint64, maxChanges int) ([]*serverpb.ChangeRecord, error) {
-	chngIter, err := rdb.db.GetUpdatesSince(fromChangeNumber)
+	var chngs []*serverpb.ChangeRecord
+	err := rdb.IterateChanges(fromChangeNumber, maxChanges, func(chng *serverpb.ChangeRecord) bool {
+		chngs = append(chngs, chng)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
+	return chngs, nil
+}
+
+// IterateChanges reads the changes off the WAL files one write batch at
+// a time, releasing every batch once it is converted.
+func (rdb *rocksDB) IterateChanges(fromChangeNumber uint64, maxChanges int, fn func(*serverpb.ChangeRecord) bool) error {
+	switch {
+	case atomic.LoadUint32(&rdb.bulkLoading) == 1:
+		return nil
+	case fromChangeNumber <= atomic.LoadUint64(&rdb.bulkLoadChngNum):
+		return storage.ErrChangesUnavailable
+	case fromChangeNumber < atomic.LoadUint64(&rdb.truncChngNum):
+		return storage.ErrChangesTruncated
+	}
+	chngIter, err := rdb.db.GetUpdatesSince(fromChangeNumber)
+	if err != nil {
+		return err
+	}
 	defer chngIter.Destroy()
-	i, chngs := 0, make([]*serverpb.ChangeRecord, maxChanges)
-	for i < maxChanges && chngIter.Valid() {
+	for i := 0; i < maxChanges && chngIter.Valid(); i++ {
 		wb, chngNum := chngIter.GetBatch()
-		defer wb.Destroy()
-		chngs[i] = toChangeRecord(wb, chngNum)
-		i++
+		// Sequence numbers begin from 1, so a gap before the first
+		// batch implies that the WAL files holding it are purged
+		if i == 0 && chngNum > fromChangeNumber && chngNum > 1 {
+			wb.Destroy()
+			return storage.ErrChangesUnavailable
+		}
+		chng := toChangeRecord(wb, chngNum)
+		wb.Destroy()
+		if !fn(chng) {
+			return nil
+		}
 		chngIter.Next()
 	}
-	return chngs[0:i:i], nil
+	return nil
+}
+
+// RetainedChanges reports the oldest change still held by the WAL
+// files, unless it precedes the latest bulk load or truncation.
+func (rdb *rocksDB) RetainedChanges() (uint64, uint64, error) {
+	lastChngNum := rdb.db.GetLatestSequenceNumber()
+	firstChngNum := atomic.LoadUint64(&rdb.bulkLoadChngNum) + 1
+	if truncChngNum := atomic.LoadUint64(&rdb.truncChngNum); truncChngNum > firstChngNum {
+		firstChngNum = truncChngNum
+	}
+	chngIter, err := rdb.db.GetUpdatesSince(firstChngNum)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer chngIter.Destroy()
+	if chngIter.Valid() {
+		wb, chngNum := chngIter.GetBatch()
+		wb.Destroy()
+		if chngNum > firstChngNum {
+			firstChngNum = chngNum
+		}
+	} else {
+		firstChngNum = lastChngNum + 1
+	}
+	return firstChngNum, lastChngNum, nil
+}
+
+// TruncateChanges only marks the given change number in memory, below
+// which changes are no longer loaded, since RocksDB purges the WAL files
+// on its own once their entries are flushed. The mark is lost across
+// restarts, upon which the changes still held by the WAL files are
+// loaded again. Likewise, changes can not be retained beyond the WAL
+// files, hence RetainUnconsumed is not supported by RocksDB.
+func (rdb *rocksDB) TruncateChanges(beforeChangeNumber uint64) error {
+	if lastChngNum := rdb.db.GetLatestSequenceNumber(); beforeChangeNumber > lastChngNum+1 {
+		beforeChangeNumber = lastChngNum + 1
+	}
+	for {
+		truncChngNum := atomic.LoadUint64(&rdb.truncChngNum)
+		if beforeChangeNumber <= truncChngNum || atomic.CompareAndSwapUint64(&rdb.truncChngNum, truncChngNum, beforeChangeNumber) {
+			return nil
+		}
+	}
+}
+
+// Changes applied on a slave preserve the sequence numbers of the
+// master as long as the slave applies every change from the very
+// beginning. Whenever a slave instead begins from a checkpoint of
+// the master, the difference between these sequence numbers is
+// recorded under the following key.
+const changeNumberOffsetKey = "_dkv_meta::ChangeNumberOffset"
+
+// Change number of the change marking the latest bulk load, whose
+// entries are missing from the WAL, is recorded under the following key.
+const bulkLoadChangeNumberKey = "_dkv_meta::BulkLoadChangeNumber"
+
+var metaKeyPrefix = storage.ReservedKeyPrefix
+
+// Entries that expire are stored under their original keys prefixed
+// with the following, and with their values prefixed by their 8 byte
+// expiry time. Entries under the original keys never expire and take
+// precedence over these, so that puts without any expiry remain single
+// writes. Expired entries are removed by the compactions that RocksDB
+// runs in the background.
+var expiringKeyPrefix = []byte("_dkv_meta::Expiring::")
+
+const expireTSSize = 8
+
+func expiringKey(key []byte) []byte {
+	expKey := make([]byte, len(expiringKeyPrefix)+len(key))
+	copy(expKey, expiringKeyPrefix)
+	copy(expKey[len(expiringKeyPrefix):], key)
+	return expKey
+}
+
+func expiringValue(value []byte, expireTS uint64) []byte {
+	expVal := make([]byte, expireTSSize+len(value))
+	binary.BigEndian.PutUint64(expVal, expireTS)
+	copy(expVal[expireTSSize:], value)
+	return expVal
+}
+
+func parseExpiringValue(expVal []byte) ([]byte, uint64) {
+	if len(expVal) < expireTSSize {
+		return expVal, 0
+	}
+	return expVal[expireTSSize:], binary.BigEndian.Uint64(expVal)
+}
+
+// expiryFilter removes the expired entries during compactions. Since
+// these removals are not recorded as writes, they neither advance the
+// change numbers nor get replicated. Every replica instead removes
+// them on its own after filtering them out of its reads.
+type expiryFilter struct{}
+
+func (expiryFilter) Name() string {
+	return "dkv.ExpiryFilter"
+}
+
+func (expiryFilter) Filter(level int, key, val []byte) (bool, []byte) {
+	if !bytes.HasPrefix(key, expiringKeyPrefix) {
+		return false, nil
+	}
+	_, expireTS := parseExpiringValue(val)
+	return storage.IsExpired(expireTS), nil
 }
 
 func (rdb *rocksDB) GetLatestAppliedChangeNumber() (uint64, error) {
-	return rdb.db.GetLatestSequenceNumber(), nil
+	return adjustedChangeNumber(rdb.db)
+}
+
+// adjustedChangeNumber returns the latest sequence number of the given
+// DB adjusted by the change number offset recorded in it, if any.
+func adjustedChangeNumber(db *gorocksdb.DB) (uint64, error) {
+	ro := gorocksdb.NewDefaultReadOptions()
+	defer ro.Destroy()
+	offsetVal, err := db.GetBytes(ro, []byte(changeNumberOffsetKey))
+	if err != nil {
+		return 0, err
+	}
+	latestSeqNum := db.GetLatestSequenceNumber()
+	if len(offsetVal) == 0 {
+		return latestSeqNum, nil
+	}
+	offset := int64(binary.BigEndian.Uint64(offsetVal))
+	return uint64(int64(latestSeqNum) + offset), nil
+}
+
+// SetLatestAppliedChangeNumber computes the offset from the sequence
+// number of the write that records this offset itself. It assumes
+// that no other writes happen concurrently, which holds for slaves.
+func (rdb *rocksDB) SetLatestAppliedChangeNumber(chngNum uint64) error {
+	offset := int64(chngNum) - int64(rdb.db.GetLatestSequenceNumber()+1)
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(offset))
+	wo := gorocksdb.NewDefaultWriteOptions()
+	wo.SetSync(true)
+	defer wo.Destroy()
+	return rdb.db.Put(wo, []byte(changeNumberOffsetKey), buf[:])
 }
 
 func (rdb *rocksDB) SaveChanges(changes []*serverpb.ChangeRecord) (uint64, error) {
@@ -298,6 +1319,9 @@ func (rdb *rocksDB) SaveChanges(changes []*serverpb.ChangeRecord) (uint64, error
 	defer wo.Destroy()
 	appldChngNum := uint64(0)
 	for _, chng := range changes {
+		if err := verifySerialisedForm(chng); err != nil {
+			return appldChngNum, err
+		}
 		wb := gorocksdb.WriteBatchFrom(chng.SerialisedForm)
 		defer wb.Destroy()
 		err := rdb.db.Write(wo, wb)
@@ -309,6 +1333,88 @@ func (rdb *rocksDB) SaveChanges(changes []*serverpb.ChangeRecord) (uint64, error
 	return appldChngNum, nil
 }
 
+// SaveChangeBatch merges the write batches of all the given changes
+// into a single write batch. Since the change number is derived from
+// the sequence number that RocksDB advances by the count of records
+// in a write batch, it gets updated atomically along with the changes.
+// Hence none of the changes are applied if any of them are corrupted.
+func (rdb *rocksDB) SaveChangeBatch(changes []*serverpb.ChangeRecord) (uint64, error) {
+	if len(changes) == 0 {
+		return 0, nil
+	}
+	for _, chng := range changes {
+		if err := verifySerialisedForm(chng); err != nil {
+			return 0, err
+		}
+	}
+	wbData, err := mergeWriteBatches(changes)
+	if err != nil {
+		return 0, err
+	}
+	wb := gorocksdb.WriteBatchFrom(wbData)
+	defer wb.Destroy()
+	wo := gorocksdb.NewDefaultWriteOptions()
+	wo.SetSync(true)
+	defer wo.Destroy()
+	if err = rdb.db.Write(wo, wb); err != nil {
+		return 0, err
+	}
+	return changes[len(changes)-1].ChangeNumber, nil
+}
+
+// State of the replication is kept in the following file within the
+// folder of the store rather than under a key, since every write of its
+// own would advance the sequence numbers that the change numbers follow,
+// leaving them misaligned with the change numbers of the master node.
+const replicationStateFile = "DKV_REPLICATION_STATE"
+
+// SaveChangeBatchWithState replaces the file holding the state once the
+// changes are saved, hence a crash in between leaves the state recorded
+// by the previous batch. The file is replaced atomically, though without
+// syncing it, since the state only informs the operators.
+func (rdb *rocksDB) SaveChangeBatchWithState(changes []*serverpb.ChangeRecord, state []byte) (uint64, error) {
+	appldChngNum, err := rdb.SaveChangeBatch(changes)
+	if err != nil || state == nil || appldChngNum == 0 {
+		return appldChngNum, err
+	}
+	stateFile := path.Join(rdb.opts.folderName, replicationStateFile)
+	tmpStateFile := stateFile + ".tmp"
+	if err = ioutil.WriteFile(tmpStateFile, state, 0644); err != nil {
+		return appldChngNum, err
+	}
+	return appldChngNum, os.Rename(tmpStateFile, stateFile)
+}
+
+func (rdb *rocksDB) LoadReplicationState() ([]byte, error) {
+	state, err := ioutil.ReadFile(path.Join(rdb.opts.folderName, replicationStateFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return state, err
+}
+
+// Serialised form of every write batch begins with a header made
+// up of its 8 byte sequence number and 4 byte count of records.
+const writeBatchHeaderSize = 8 + 4
+
+func mergeWriteBatches(changes []*serverpb.ChangeRecord) ([]byte, error) {
+	size := writeBatchHeaderSize
+	for _, chng := range changes {
+		if len(chng.SerialisedForm) < writeBatchHeaderSize {
+			return nil, fmt.Errorf("invalid serialised form of change with change number: %d", chng.ChangeNumber)
+		}
+		size += len(chng.SerialisedForm) - writeBatchHeaderSize
+	}
+	wbData := make([]byte, writeBatchHeaderSize, size)
+	var count uint32
+	for _, chng := range changes {
+		count += binary.LittleEndian.Uint32(chng.SerialisedForm[8:writeBatchHeaderSize])
+		wbData = append(wbData, chng.SerialisedForm[writeBatchHeaderSize:]...)
+	}
+	binary.LittleEndian.PutUint32(wbData[8:writeBatchHeaderSize], count)
+	return wbData, nil
+}
+
 func toChangeRecord(writeBatch *gorocksdb.WriteBatch, changeNum uint64) *serverpb.ChangeRecord {
 	chngRec := &serverpb.ChangeRecord{}
 	chngRec.ChangeNumber = changeNum
@@ -323,9 +1429,27 @@ func toChangeRecord(writeBatch *gorocksdb.WriteBatch, changeNum uint64) *serverp
 		trxns = append(trxns, toTrxnRecord(wbr))
 	}
 	chngRec.Trxns = trxns
+	chngRec.Checksum = storage.ChangeChecksum(trxns)
 	return chngRec
 }
 
+// verifySerialisedForm verifies that the serialised form of the given
+// change, which is what gets applied, matches its checksum as well.
+func verifySerialisedForm(chng *serverpb.ChangeRecord) error {
+	if chng.Checksum == 0 {
+		return nil
+	}
+	var trxns []*serverpb.TrxnRecord
+	wbIter := NewWriteBatchIterator(chng.SerialisedForm)
+	for wbIter.Next() {
+		trxns = append(trxns, toTrxnRecord(wbIter.Record()))
+	}
+	if err := wbIter.Error(); err != nil {
+		return fmt.Errorf("invalid serialised form of change with change number: %d: %v: %w", chng.ChangeNumber, err, storage.ErrChecksumMismatch)
+	}
+	return storage.VerifyChecksum(&serverpb.ChangeRecord{ChangeNumber: chng.ChangeNumber, Trxns: trxns, Checksum: chng.Checksum})
+}
+
 func (rdb *rocksDB) openBackupEngine(folder string) (*gorocksdb.BackupEngine, error) {
 	opts := rdb.opts.rocksDBOpts
 	return gorocksdb.OpenBackupEngine(opts, folder)
@@ -342,6 +1466,13 @@ func toTrxnRecord(wbr *gorocksdb.WriteBatchRecord) *serverpb.TrxnRecord {
 		trxnRec.Type = serverpb.TrxnRecord_Unknown
 	}
 	trxnRec.Key, trxnRec.Value = wbr.Key, wbr.Value
+	// Expiring entries are replicated under their original keys
+	if bytes.HasPrefix(wbr.Key, expiringKeyPrefix) {
+		trxnRec.Key = wbr.Key[len(expiringKeyPrefix):]
+		if trxnRec.Type == serverpb.TrxnRecord_Put {
+			trxnRec.Value, trxnRec.ExpireTS = parseExpiringValue(wbr.Value)
+		}
+	}
 	return trxnRec
 }
 
@@ -365,19 +1496,6 @@ func (rdb *rocksDB) getSingleKey(ro *gorocksdb.ReadOptions, key []byte) ([]byte,
 	return toByteArray(value), nil
 }
 
-func (rdb *rocksDB) getMultipleKeys(ro *gorocksdb.ReadOptions, keys [][]byte) ([][]byte, error) {
-	values, err := rdb.db.MultiGet(ro, keys...)
-	if err != nil {
-		return nil, err
-	}
-	var results [][]byte
-	for _, value := range values {
-		results = append(results, toByteArray(value))
-		value.Free()
-	}
-	return results, nil
-}
-
 var errGlobalMutation = errors.New("Another global keyspace mutation is in progress")
 
 func (rdb *rocksDB) hasGlobalMutation() bool {
@@ -1,14 +1,21 @@
 package rocksdb
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/storagetest"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
 	"github.com/tecbot/gorocksdb"
 )
@@ -16,6 +23,7 @@ import (
 const (
 	createDBFolderIfMissing = true
 	dbFolder                = "/tmp/rocksdb_storage_test"
+	conformanceFolder       = "/tmp/rocksdb_conformance_test"
 	cacheSize               = 3 << 30
 )
 
@@ -32,27 +40,309 @@ func TestMain(m *testing.M) {
 	}
 }
 
-func TestPutAndGet(t *testing.T) {
-	numKeys := 10
-	for i := 1; i <= numKeys; i++ {
-		key, value := fmt.Sprintf("K%d", i), fmt.Sprintf("V%d", i)
-		if err := store.Put([]byte(key), []byte(value)); err != nil {
-			t.Fatalf("Unable to PUT. Key: %s, Value: %s, Error: %v", key, value, err)
+func TestConformance(t *testing.T) {
+	storagetest.RunConformanceTests(t, func(t *testing.T) (storage.KVStore, func() (storage.KVStore, error)) {
+		if err := exec.Command("rm", "-rf", conformanceFolder).Run(); err != nil {
+			t.Fatal(err)
+		}
+		open := func() (storage.KVStore, error) {
+			return openStore(NewOptions().DBFolder(conformanceFolder).CreateDBFolderIfMissing(createDBFolderIfMissing).CacheSize(cacheSize))
 		}
+		kvs, err := open()
+		if err != nil {
+			t.Fatalf("Unable to open RocksDB. Error: %v", err)
+		}
+		return kvs, open
+	})
+}
+
+func TestConcurrentCompareAndSet(t *testing.T) {
+	key, numThrds := []byte("CASConcKey"), 10
+	var numUpdates uint32
+	var wg sync.WaitGroup
+	for i := 1; i <= numThrds; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			val := []byte(fmt.Sprintf("CASConcVal_%d", i))
+			if updated, err := store.CompareAndSet(key, nil, val); err != nil {
+				t.Error(err)
+			} else if updated {
+				atomic.AddUint32(&numUpdates, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if numUpdates != 1 {
+		t.Errorf("Expected exactly one COMPAREANDSET to succeed. But %d succeeded", numUpdates)
 	}
+}
+
+func TestCompareAndSetWithConcurrentPuts(t *testing.T) {
+	testConcurrentPuts(t, []byte("CASPutKey"), store.CompareAndSet)
+}
+
+// testConcurrentPuts puts the versions of the given key one after the
+// other, each once the previous one is set by the given read-modify-write
+// operation onto a value derived from it, which happens concurrently.
+// Were a put to interleave with the operation, the operation would
+// overwrite it with a value derived from the previous version.
+func testConcurrentPuts(t *testing.T, key []byte, rmw func(key, expectedValue, newValue []byte) (bool, error)) {
+	numPuts, numThrds := 100, 2
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+	for i := 0; i < numThrds; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				values, _, err := store.Get(key)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if bytes.HasPrefix(values[0], []byte("Put_")) {
+					if _, err = rmw(key, values[0], append([]byte("Set_"), values[0][4:]...)); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}()
+	}
+	for i := 1; i <= numPuts; i++ {
+		version := fmt.Sprintf("%d", i)
+		if err := store.Put(key, []byte("Put_"+version)); err != nil {
+			t.Fatalf("Unable to PUT. Key: %s, Error: %v", key, err)
+		}
+		for deadline := time.Now().Add(5 * time.Second); ; {
+			values, _, err := store.Get(key)
+			if err != nil {
+				t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
+			}
+			value := string(values[0])
+			if value == "Set_"+version {
+				break
+			}
+			if value != "Put_"+version || time.Now().After(deadline) {
+				t.Fatalf("Expected the latest PUT to not be overwritten by a stale write. Version: %s, Actual Value: %s", version, value)
+			}
+		}
+	}
+}
+
+func TestTxnWithConcurrentPuts(t *testing.T) {
+	testConcurrentPuts(t, []byte("TxnPutKey"), func(key, expectedValue, newValue []byte) (bool, error) {
+		return store.Txn(&serverpb.TxnRequest{
+			Conditions:    []*serverpb.TxnCondition{{Type: serverpb.TxnCondition_Equals, Key: key, Value: expectedValue}},
+			ThenMutations: []*serverpb.TrxnRecord{{Type: serverpb.TrxnRecord_Put, Key: key, Value: newValue}},
+		})
+	})
+}
+
+func TestConcurrentIncrement(t *testing.T) {
+	key, numThrds, numIncrs := []byte("IncrConcKey"), 10, 20
+	var wg sync.WaitGroup
+	for i := 1; i <= numThrds; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numIncrs; j++ {
+				if _, err := store.Increment(key, 1); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if value, err := store.Increment(key, 0); err != nil {
+		t.Fatalf("Unable to INCREMENT. Key: %s, Error: %v", key, err)
+	} else if value != int64(numThrds*numIncrs) {
+		t.Errorf("Expected no INCREMENT to be lost. Expected Value: %d, Actual Value: %d", numThrds*numIncrs, value)
+	}
+}
+
+// TestIncrementWithConcurrentPuts puts the bases of the given key one
+// after the other, each once it is incremented concurrently. Were a put
+// to interleave with an increment, the increment would overwrite it
+// with a value below its base.
+func TestIncrementWithConcurrentPuts(t *testing.T) {
+	key, numPuts, numThrds := []byte("IncrPutKey"), 100, 2
+	const baseStep = 1 << 20
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+	for i := 1; i <= numPuts; i++ {
+		base := int64(i * baseStep)
+		_, baseValue, _ := storage.IncrementValue(nil, base)
+		if err := store.Put(key, baseValue); err != nil {
+			t.Fatalf("Unable to PUT. Key: %s, Error: %v", key, err)
+		}
+		if i == 1 {
+			for j := 0; j < numThrds; j++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						select {
+						case <-done:
+							return
+						default:
+						}
+						if _, err := store.Increment(key, 1); err != nil {
+							t.Error(err)
+							return
+						}
+					}
+				}()
+			}
+		}
+		for deadline := time.Now().Add(5 * time.Second); ; {
+			results, _, err := store.Get(key)
+			if err != nil {
+				t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
+			}
+			value, _, _ := storage.IncrementValue(results[0], 0)
+			if value > base && value < base+baseStep {
+				break
+			}
+			if value < base || value >= base+baseStep || time.Now().After(deadline) {
+				t.Fatalf("Expected the latest PUT to not be overwritten by a stale INCREMENT. Base: %d, Actual Value: %d", base, value)
+			}
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 10, "DelKey", "DelVal"
+	putKeys(t, numKeys, keyPrefix, valPrefix)
+	chngNum, _ := store.GetLatestCommittedChangeNumber()
+	chngNum++ // due to the previous transaction
 
 	for i := 1; i <= numKeys; i++ {
-		key, expectedValue := fmt.Sprintf("K%d", i), fmt.Sprintf("V%d", i)
-		if readResults, err := store.Get([]byte(key)); err != nil {
-			t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
-		} else {
-			if string(readResults[0]) != expectedValue {
-				t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expectedValue, readResults[0])
+		key := fmt.Sprintf("%s_%d", keyPrefix, i)
+		if err := store.Delete([]byte(key)); err != nil {
+			t.Fatalf("Unable to DELETE. Key: %s, Error: %v", key, err)
+		}
+	}
+	noKeys(t, numKeys, keyPrefix)
+
+	if err := store.Delete([]byte("MissingDelKey")); err != nil {
+		t.Errorf("Expected no error while deleting a missing key. Error: %v", err)
+	}
+
+	if chngs, err := store.LoadChanges(chngNum, numKeys); err != nil {
+		t.Fatal(err)
+	} else if len(chngs) != numKeys {
+		t.Errorf("Incorrect number of changes retrieved. Expected: %d, Actual: %d", numKeys, len(chngs))
+	} else {
+		for i, chng := range chngs {
+			trxnRec := chng.Trxns[0]
+			expKey := fmt.Sprintf("%s_%d", keyPrefix, i+1)
+			if trxnRec.Type != serverpb.TrxnRecord_Delete {
+				t.Errorf("Expected transaction type to be Delete but found %s", trxnRec.Type.String())
+			} else if string(trxnRec.Key) != expKey {
+				t.Errorf("Key mismatch. Expected: %s, Actual: %s", expKey, trxnRec.Key)
 			}
 		}
 	}
 }
 
+func TestPutWithExpiry(t *testing.T) {
+	now := uint64(time.Now().Unix())
+	expKey, liveKey, shdwKey := []byte("TTLExpKey"), []byte("TTLLiveKey"), []byte("TTLShdwKey")
+	if err := store.MultiPut(&serverpb.PutRequest{Key: expKey, Value: []byte("ExpVal"), ExpireTS: now - 1},
+		&serverpb.PutRequest{Key: liveKey, Value: []byte("LiveVal"), ExpireTS: now + 100},
+		&serverpb.PutRequest{Key: shdwKey, Value: []byte("ShdwVal"), ExpireTS: now + 100}); err != nil {
+		t.Fatalf("Unable to MULTIPUT. Error: %v", err)
+	}
+	// Puts without any expiry take precedence over the expiring ones
+	if err := store.Put(shdwKey, []byte("NewShdwVal")); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+
+	if results, _, err := store.Get(expKey, liveKey, shdwKey); err != nil {
+		t.Fatalf("Unable to MULTIGET. Error: %v", err)
+	} else if len(results[0]) != 0 || string(results[1]) != "LiveVal" || string(results[2]) != "NewShdwVal" {
+		t.Errorf("MULTIGET mismatch. Expected: [ LiveVal NewShdwVal], Actual: %q", results)
+	}
+	if results, err := store.Exists(expKey, liveKey, shdwKey); err != nil {
+		t.Fatal(err)
+	} else if results[0] || !results[1] || !results[2] {
+		t.Errorf("Presence mismatch. Expected: [false true true], Actual: %v", results)
+	}
+	if updated, _ := store.CompareAndSet(expKey, nil, []byte("NewExpVal")); !updated {
+		t.Errorf("Expected the expired key: %s to be set as if absent", expKey)
+	}
+
+	iteration := store.Iterate([]byte("TTL"), nil)
+	defer iteration.Close()
+	expKVs := []struct {
+		key, val string
+		expireTS uint64
+	}{{"TTLExpKey", "NewExpVal", 0}, {"TTLLiveKey", "LiveVal", now + 100}, {"TTLShdwKey", "NewShdwVal", 0}}
+	for _, expKV := range expKVs {
+		if !iteration.HasNext() {
+			t.Fatalf("Expected key: %s in iteration", expKV.key)
+		}
+		if key, val := iteration.Next(); string(key) != expKV.key || string(val) != expKV.val || iteration.ExpireTS() != expKV.expireTS {
+			t.Errorf("Iteration mismatch. Expected: %s=%s expiring at %d, Actual: %s=%s expiring at %d", expKV.key, expKV.val, expKV.expireTS, key, val, iteration.ExpireTS())
+		}
+	}
+	if iteration.HasNext() {
+		key, _ := iteration.Next()
+		t.Errorf("Expected iteration to end. But found key: %s", key)
+	}
+}
+
+func TestLoadChangesWithExpiry(t *testing.T) {
+	chngNum, _ := store.GetLatestCommittedChangeNumber()
+	chngNum++ // due to the next transaction
+	expireTS := uint64(time.Now().Unix()) + 100
+	if err := store.MultiPut(&serverpb.PutRequest{Key: []byte("LCTTLKey"), Value: []byte("LCTTLVal"), ExpireTS: expireTS}); err != nil {
+		t.Fatalf("Unable to MULTIPUT. Error: %v", err)
+	}
+	chngs, err := store.LoadChanges(chngNum, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Expiring entries are replicated under their original keys
+	for _, trxnRec := range chngs[0].Trxns {
+		if string(trxnRec.Key) != "LCTTLKey" {
+			t.Errorf("Key mismatch. Expected: LCTTLKey, Actual: %s", trxnRec.Key)
+		}
+		if trxnRec.Type == serverpb.TrxnRecord_Put && (string(trxnRec.Value) != "LCTTLVal" || trxnRec.ExpireTS != expireTS) {
+			t.Errorf("Put mismatch. Expected: LCTTLVal expiring at %d, Actual: %s expiring at %d", expireTS, trxnRec.Value, trxnRec.ExpireTS)
+		}
+	}
+}
+
+func TestExpiryFilter(t *testing.T) {
+	now := uint64(time.Now().Unix())
+	filter := expiryFilter{}
+	if remove, _ := filter.Filter(0, expiringKey([]byte("key")), expiringValue([]byte("val"), now-1)); !remove {
+		t.Error("Expected the expired entry to be removed")
+	}
+	if remove, _ := filter.Filter(0, expiringKey([]byte("key")), expiringValue([]byte("val"), now+100)); remove {
+		t.Error("Expected the live entry to be retained")
+	}
+	if remove, _ := filter.Filter(0, []byte("key"), expiringValue([]byte("val"), now-1)); remove {
+		t.Error("Expected the entry that never expires to be retained")
+	}
+}
+
 func TestGetLatestChangeNumber(t *testing.T) {
 	expNumTrxns := uint64(5)
 	beforeChngNum, _ := store.GetLatestCommittedChangeNumber()
@@ -141,6 +431,71 @@ func TestSaveChanges(t *testing.T) {
 	}
 }
 
+func TestSaveChangeBatch(t *testing.T) {
+	numTrxns := 3
+	putKeyPrefix, putValPrefix := "cbKey", "cbVal"
+	putKeys(t, numTrxns, putKeyPrefix, putValPrefix)
+	chngNum, _ := store.GetLatestAppliedChangeNumber()
+	chngNum++ // due to possible previous transaction
+	wbPutKeyPrefix, wbPutValPrefix := "dbKey", "dbVal"
+	chngs := make([]*serverpb.ChangeRecord, numTrxns)
+	for i := 0; i < numTrxns; i++ {
+		wb := gorocksdb.NewWriteBatch()
+		defer wb.Destroy()
+		ks, vs := fmt.Sprintf("%s_%d", wbPutKeyPrefix, i+1), fmt.Sprintf("%s_%d", wbPutValPrefix, i+1)
+		wb.Put([]byte(ks), []byte(vs))
+		delKs := fmt.Sprintf("%s_%d", putKeyPrefix, i+1)
+		wb.Delete([]byte(delKs))
+		chngs[i] = toChangeRecord(wb, chngNum)
+		chngNum += uint64(wb.Count())
+	}
+	// Every change advances the change number by its count of records
+	expChngNum := chngNum - 1
+
+	if actChngNum, err := store.SaveChangeBatch(chngs); err != nil {
+		t.Fatal(err)
+	} else if lastChngNum := chngs[numTrxns-1].ChangeNumber; actChngNum != lastChngNum {
+		t.Errorf("Change numbers mismatch. Expected: %d, Actual: %d", lastChngNum, actChngNum)
+	}
+	if actChngNum, _ := store.GetLatestAppliedChangeNumber(); actChngNum != expChngNum {
+		t.Errorf("Latest applied change numbers mismatch. Expected: %d, Actual: %d", expChngNum, actChngNum)
+	}
+	getKeys(t, numTrxns, wbPutKeyPrefix, wbPutValPrefix)
+	noKeys(t, numTrxns, putKeyPrefix)
+}
+
+func TestSetLatestAppliedChangeNumber(t *testing.T) {
+	chngNum, _ := store.GetLatestAppliedChangeNumber()
+	chkptChngNum := chngNum + 100
+	if err := store.SetLatestAppliedChangeNumber(chkptChngNum); err != nil {
+		t.Fatal(err)
+	}
+	if actChngNum, err := store.GetLatestAppliedChangeNumber(); err != nil {
+		t.Error(err)
+	} else if actChngNum != chkptChngNum {
+		t.Errorf("Change numbers mismatch. Expected: %d, Actual: %d", chkptChngNum, actChngNum)
+	}
+
+	// Subsequent changes must advance from the set change number
+	putKeys(t, 2, "slcnKey", "slcnVal")
+	if actChngNum, _ := store.GetLatestAppliedChangeNumber(); actChngNum != chkptChngNum+2 {
+		t.Errorf("Change numbers mismatch. Expected: %d, Actual: %d", chkptChngNum+2, actChngNum)
+	}
+
+	// Metadata must not be visible during iteration
+	it := store.Iterate([]byte("_dkv_meta"), nil)
+	defer it.Close()
+	if it.HasNext() {
+		key, _ := it.Next()
+		t.Errorf("Expected no metadata keys during iteration. Found: %s", key)
+	}
+
+	// Restore the original numbering for the other tests
+	if err := store.SetLatestAppliedChangeNumber(store.db.GetLatestSequenceNumber() + 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // Following test can be removed once DKV supports bulk writes
 func TestGetUpdatesFromSeqNumForBatches(t *testing.T) {
 	beforeSeq := store.db.GetLatestSequenceNumber()
@@ -205,39 +560,6 @@ func TestGetUpdatesFromSeqNumForBatches(t *testing.T) {
 	}
 }
 
-func TestMultiGet(t *testing.T) {
-	numKeys := 10
-	keys, vals := make([][]byte, numKeys), make([]string, numKeys)
-	for i := 1; i <= numKeys; i++ {
-		key, value := fmt.Sprintf("MK%d", i), fmt.Sprintf("MV%d", i)
-		if err := store.Put([]byte(key), []byte(value)); err != nil {
-			t.Fatalf("Unable to PUT. Key: %s, Value: %s, Error: %v", key, value, err)
-		} else {
-			keys[i-1] = []byte(key)
-			vals[i-1] = value
-		}
-	}
-
-	if results, err := store.Get(keys...); err != nil {
-		t.Fatal(err)
-	} else {
-		for i, result := range results {
-			if string(result) != vals[i] {
-				t.Errorf("Multi Get value mismatch. Key: %s, Expected Value: %s, Actual Value: %s", keys[i], vals[i], result)
-			}
-		}
-	}
-}
-
-func TestMissingGet(t *testing.T) {
-	key, expectedValue := "MissingKey", ""
-	if readResults, err := store.Get([]byte(key)); err != nil {
-		t.Fatal(err)
-	} else if string(readResults[0]) != "" {
-		t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expectedValue, readResults[0])
-	}
-}
-
 func TestBackupFolderValidity(t *testing.T) {
 	expectError(t, checksForBackup(""))
 	expectError(t, checksForBackup("/missing/backup"))
@@ -253,6 +575,56 @@ func TestRestoreFolderValidity(t *testing.T) {
 	expectNoError(t, checksForRestore(dbFolder))
 }
 
+func TestIterateWithPrefix(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 10, "IterKey", "IterVal"
+	putKeys(t, numKeys, keyPrefix, valPrefix)
+	putKeys(t, numKeys, "OtherIterKey", "OtherIterVal")
+
+	iteration := store.Iterate([]byte(keyPrefix), nil)
+	defer iteration.Close()
+
+	// Mutations after creating the iterator must not be visible
+	putKeys(t, 2, keyPrefix+"_Late", valPrefix)
+
+	numIterKeys := 0
+	for iteration.HasNext() {
+		key, val := iteration.Next()
+		if !strings.HasPrefix(string(key), keyPrefix+"_") || strings.HasPrefix(string(key), keyPrefix+"_Late") {
+			t.Errorf("Unexpected key during iteration: %s", key)
+		} else if expVal := strings.Replace(string(key), keyPrefix, valPrefix, 1); string(val) != expVal {
+			t.Errorf("Value mismatch for key: %s. Expected: %s, Actual: %s", key, expVal, val)
+		}
+		numIterKeys++
+	}
+	if err := iteration.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if numIterKeys != numKeys {
+		t.Errorf("Incorrect number of keys iterated. Expected: %d, Actual: %d", numKeys, numIterKeys)
+	}
+}
+
+func TestIterateFromStartKey(t *testing.T) {
+	numKeys, keyPrefix, valPrefix := 9, "IterStrtKey", "IterStrtVal"
+	putKeys(t, numKeys, keyPrefix, valPrefix)
+
+	startKey := fmt.Sprintf("%s_%d", keyPrefix, 5)
+	iteration := store.Iterate([]byte(keyPrefix), []byte(startKey))
+	defer iteration.Close()
+
+	expKeyIdx := 5
+	for iteration.HasNext() {
+		key, _ := iteration.Next()
+		if expKey := fmt.Sprintf("%s_%d", keyPrefix, expKeyIdx); string(key) != expKey {
+			t.Errorf("Key mismatch during iteration. Expected: %s, Actual: %s", expKey, key)
+		}
+		expKeyIdx++
+	}
+	if expKeyIdx != numKeys+1 {
+		t.Errorf("Expected iteration to end at key index %d, but it ended at %d", numKeys+1, expKeyIdx)
+	}
+}
+
 func TestBackupAndRestore(t *testing.T) {
 	numTrxns := 500
 	keyPrefix, valPrefix := "brKey", "brVal"
@@ -273,6 +645,91 @@ func TestBackupAndRestore(t *testing.T) {
 	}
 }
 
+func TestBackupRecordsAppliedChangeNumber(t *testing.T) {
+	putKeys(t, 10, "bacKey", "bacVal")
+	seqNum := store.db.GetLatestSequenceNumber()
+	if err := store.SetLatestAppliedChangeNumber(seqNum + 100); err != nil {
+		t.Fatal(err)
+	}
+	// Restore the original numbering for the other tests
+	defer func() {
+		if err := store.SetLatestAppliedChangeNumber(store.db.GetLatestSequenceNumber() + 1); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	backupPath := fmt.Sprintf("%s/%s", dbFolder, "applied_backup")
+	if err := store.BackupTo(backupPath); err != nil {
+		t.Fatal(err)
+	}
+	appldChngNum, _ := store.GetLatestAppliedChangeNumber()
+	if info, err := store.VerifyBackup(backupPath); err != nil {
+		t.Fatal(err)
+	} else if info.ChangeNumber != appldChngNum {
+		t.Errorf("Expected the backup to record the applied change number. Expected: %d, Actual: %d", appldChngNum, info.ChangeNumber)
+	}
+	if err := store.RestoreFrom(backupPath); err != nil {
+		t.Fatal(err)
+	}
+	getKeys(t, 10, "bacKey", "bacVal")
+}
+
+func TestVerifyBackupBeforeRestore(t *testing.T) {
+	numTrxns := 500
+	keyPrefix, valPrefix := "vbKey", "vbVal"
+	putKeys(t, numTrxns, keyPrefix, valPrefix)
+
+	backupPath := fmt.Sprintf("%s/%s", dbFolder, "verify_backup")
+	if err := store.BackupTo(backupPath); err != nil {
+		t.Fatal(err)
+	}
+	chngNum, _ := store.GetLatestCommittedChangeNumber()
+	if info, err := store.VerifyBackup(backupPath); err != nil {
+		t.Fatal(err)
+	} else if info.Engine != backupEngine || info.ChangeNumber != chngNum || info.Size == 0 {
+		t.Errorf("Backup info mismatch. Expected engine: %s, change number: %d, Actual: %+v", backupEngine, chngNum, info)
+	}
+	missKeyPrefix, missValPrefix := "mvbKey", "mvbVal"
+	putKeys(t, numTrxns, missKeyPrefix, missValPrefix)
+	expectInvalidBackup := func(bckpPath, desc string) {
+		if _, err := store.VerifyBackup(bckpPath); err == nil {
+			t.Errorf("Expected an error on verifying a backup %s", desc)
+		}
+		if err := store.RestoreFrom(bckpPath); err == nil {
+			t.Errorf("Expected an error on restoring a backup %s", desc)
+		}
+		// The existing keys must be left untouched
+		getKeys(t, numTrxns, missKeyPrefix, missValPrefix)
+	}
+
+	emptyPath := fmt.Sprintf("%s/%s", dbFolder, "empty_backup")
+	if err := os.Mkdir(emptyPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	expectInvalidBackup(emptyPath, "without any files")
+
+	infoFile := path.Join(backupPath, backupInfoFile)
+	os.Remove(infoFile)
+	if err := storage.WriteBackupFile(infoFile, storage.BackupInfo{Engine: "badger"}, func(io.Writer) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	expectInvalidBackup(backupPath, "taken by another engine")
+	// Backups lacking info are verified by the backup engine alone
+	os.Remove(infoFile)
+	if _, err := store.VerifyBackup(backupPath); err != nil {
+		t.Errorf("Unable to verify a backup lacking info. Error: %v", err)
+	}
+
+	sstFiles, _ := filepath.Glob(path.Join(backupPath, "shared*", "*.sst"))
+	if len(sstFiles) == 0 {
+		t.Fatal("Expected the backup to hold at least one SST file")
+	}
+	if err := os.Truncate(sstFiles[0], 16); err != nil {
+		t.Fatal(err)
+	}
+	expectInvalidBackup(backupPath, "with a truncated file")
+}
+
 func TestGetPutSnapshot(t *testing.T) {
 	numTrxns := 100
 	keyPrefix1, valPrefix1, newValPrefix1 := "firSnapKey", "firSnapVal", "newFirSnapVal"
@@ -398,6 +855,138 @@ func TestPreventParallelRestores(t *testing.T) {
 	}
 }
 
+func TestCompactRange(t *testing.T) {
+	// Compacted onto a DB of its own, so that the SST files of other tests
+	// do not overlap the ranges compacted
+	folder := dbFolder + "_compaction"
+	os.RemoveAll(folder)
+	db, err := openStore(NewOptions().DBFolder(folder).CreateDBFolderIfMissing(true))
+	if err != nil {
+		t.Fatalf("Unable to open RocksDB. Error: %v", err)
+	}
+	defer os.RemoveAll(folder)
+	defer db.Close()
+
+	// Every batch is flushed onto an SST file of its own at level 0,
+	// fewer than those that trigger compactions automatically. Keys are
+	// overwritten rather than deleted, since deletes also write entries
+	// beyond the ranges compacted.
+	for _, batch := range [][2]string{{"CmpA", "V1"}, {"CmpB", "V1"}, {"CmpA", "V2"}} {
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("%s_%03d", batch[0], i)
+			if err = db.Put([]byte(key), []byte(batch[1])); err != nil {
+				t.Fatalf("Unable to write %s. Error: %v", key, err)
+			}
+		}
+		if err = db.Flush(); err != nil {
+			t.Fatalf("Unable to flush. Error: %v", err)
+		}
+	}
+	numLevel0Files := func(prefix string) (num int) {
+		for _, file := range db.db.GetLiveFilesMetaData() {
+			if file.Level == 0 && bytes.HasPrefix(file.SmallestKey, []byte(prefix)) {
+				num++
+			}
+		}
+		return
+	}
+	if numA, numB := numLevel0Files("CmpA"), numLevel0Files("CmpB"); numA != 2 || numB != 1 {
+		t.Fatalf("Expected 2 and 1 SST files at level 0 for CmpA and CmpB. Actual: %d and %d", numA, numB)
+	}
+
+	if err = db.CompactRange([]byte("CmpA"), []byte("CmpA_999")); err != nil {
+		t.Fatalf("Unable to compact. Error: %v", err)
+	}
+	if numA, numB := numLevel0Files("CmpA"), numLevel0Files("CmpB"); numA != 0 || numB != 1 {
+		t.Errorf("Expected only the SST files of CmpA to be compacted. Files at level 0 of CmpA: %d, CmpB: %d", numA, numB)
+	}
+	if stats, _ := db.GetStorageStats(); stats.LastCompactionTime.IsZero() {
+		t.Error("Expected the time of the compaction to be reported")
+	}
+	if err = db.CompactRange(nil, nil); err != nil {
+		t.Fatalf("Unable to compact. Error: %v", err)
+	}
+	if numB := numLevel0Files("CmpB"); numB != 0 {
+		t.Errorf("Expected the whole keyspace to be compacted. Files at level 0 of CmpB: %d", numB)
+	}
+	if vals, _, _ := db.Get([]byte("CmpA_042"), []byte("CmpB_042")); string(vals[0]) != "V2" || string(vals[1]) != "V1" {
+		t.Errorf("Expected the latest values to be retained across compactions. Values: %q", vals)
+	}
+}
+
+func TestTruncateChanges(t *testing.T) {
+	folder := dbFolder + "_truncation"
+	os.RemoveAll(folder)
+	db, err := openStore(NewOptions().DBFolder(folder).CreateDBFolderIfMissing(true))
+	if err != nil {
+		t.Fatalf("Unable to open RocksDB. Error: %v", err)
+	}
+	defer os.RemoveAll(folder)
+	defer db.Close()
+
+	for i := 1; i <= 5; i++ {
+		if err = db.Put([]byte(fmt.Sprintf("TrcKey%d", i)), []byte("TrcVal")); err != nil {
+			t.Fatalf("Unable to PUT. Error: %v", err)
+		}
+	}
+	if first, last, err := db.RetainedChanges(); err != nil || first != 1 || last != 5 {
+		t.Errorf("Retained changes mismatch. Expected: 1-5, Actual: %d-%d, Error: %v", first, last, err)
+	}
+	if err = db.TruncateChanges(4); err != nil {
+		t.Fatalf("Unable to truncate changes. Error: %v", err)
+	}
+	if first, last, _ := db.RetainedChanges(); first != 4 || last != 5 {
+		t.Errorf("Retained changes mismatch. Expected: 4-5, Actual: %d-%d", first, last)
+	}
+	if _, err = db.LoadChanges(3, 10); err != storage.ErrChangesTruncated {
+		t.Errorf("Expected truncated changes to be unavailable. Error: %v", err)
+	}
+	if chngs, err := db.LoadChanges(4, 10); err != nil || len(chngs) != 2 || chngs[0].ChangeNumber != 4 {
+		t.Errorf("Expected the retained changes to load. Changes: %v, Error: %v", chngs, err)
+	}
+}
+
+func TestEstimatePrefix(t *testing.T) {
+	folder := dbFolder + "_prefix"
+	os.RemoveAll(folder)
+	db, err := openStore(NewOptions().DBFolder(folder).CreateDBFolderIfMissing(true))
+	if err != nil {
+		t.Fatalf("Unable to open RocksDB. Error: %v", err)
+	}
+	defer os.RemoveAll(folder)
+	defer db.Close()
+
+	value := bytes.Repeat([]byte("V"), 1000)
+	for i := 0; i < 1000; i++ {
+		prefix := "PfxA"
+		if i%4 == 0 {
+			prefix = "PfxB"
+		}
+		if err = db.Put([]byte(fmt.Sprintf("%s_%04d", prefix, i)), value); err != nil {
+			t.Fatalf("Unable to PUT. Error: %v", err)
+		}
+	}
+	// Only the keys flushed onto the SST files are estimated
+	if stats, err := db.EstimatePrefix([]byte("PfxA")); err != nil || stats.NumKeys != 0 {
+		t.Errorf("Expected no keys to be estimated before flushing. Stats: %+v, Error: %v", stats, err)
+	}
+	if err = db.Flush(); err != nil {
+		t.Fatalf("Unable to flush. Error: %v", err)
+	}
+	total, err := db.EstimatePrefix(nil)
+	if err != nil || total.NumKeys == 0 || total.Size == 0 {
+		t.Fatalf("Expected the keys to be estimated once flushed. Stats: %+v, Error: %v", total, err)
+	}
+	statsA, _ := db.EstimatePrefix([]byte("PfxA"))
+	statsB, _ := db.EstimatePrefix([]byte("PfxB"))
+	if statsA.Size <= statsB.Size || statsA.Size+statsB.Size > total.Size {
+		t.Errorf("Expected PfxA to occupy more of the total than PfxB. PfxA: %+v, PfxB: %+v, Total: %+v", statsA, statsB, total)
+	}
+	if stats, _ := db.EstimatePrefix([]byte("PfxC")); stats.NumKeys != 0 {
+		t.Errorf("Expected no keys for an absent prefix. Stats: %+v", stats)
+	}
+}
+
 func BenchmarkPutNewKeys(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		key, value := fmt.Sprintf("BK%d", i), fmt.Sprintf("BV%d", i)
@@ -426,7 +1015,7 @@ func BenchmarkGetKey(b *testing.B) {
 		b.Fatalf("Unable to PUT. Key: %s. Error: %v", key, err)
 	}
 	for i := 0; i < b.N; i++ {
-		if readResults, err := store.Get([]byte(key)); err != nil {
+		if readResults, _, err := store.Get([]byte(key)); err != nil {
 			b.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
 		} else if string(readResults[0]) != val {
 			b.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, val, readResults[0])
@@ -437,7 +1026,7 @@ func BenchmarkGetKey(b *testing.B) {
 func BenchmarkGetMissingKey(b *testing.B) {
 	key := "BMissingKey"
 	for i := 0; i < b.N; i++ {
-		if _, err := store.Get([]byte(key)); err != nil {
+		if _, _, err := store.Get([]byte(key)); err != nil {
 			b.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
 		}
 	}
@@ -481,7 +1070,7 @@ func BenchmarkIteration(b *testing.B) {
 func noKeys(t *testing.T, numKeys int, keyPrefix string) {
 	for i := 1; i <= numKeys; i++ {
 		key := fmt.Sprintf("%s_%d", keyPrefix, i)
-		if readResults, err := store.Get([]byte(key)); err != nil {
+		if readResults, _, err := store.Get([]byte(key)); err != nil {
 			t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
 		} else if string(readResults[0]) != "" {
 			t.Errorf("Expected missing for key: %s. But found it with value: %s", key, readResults[0])
@@ -492,7 +1081,7 @@ func noKeys(t *testing.T, numKeys int, keyPrefix string) {
 func getKeys(t *testing.T, numKeys int, keyPrefix, valPrefix string) {
 	for i := 1; i <= numKeys; i++ {
 		key, expectedValue := fmt.Sprintf("%s_%d", keyPrefix, i), fmt.Sprintf("%s_%d", valPrefix, i)
-		if readResults, err := store.Get([]byte(key)); err != nil {
+		if readResults, _, err := store.Get([]byte(key)); err != nil {
 			t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
 		} else if string(readResults[0]) != expectedValue {
 			t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", key, expectedValue, readResults[0])
@@ -507,7 +1096,7 @@ func putKeys(t testing.TB, numKeys int, keyPrefix, valPrefix string) map[string]
 		if err := store.Put([]byte(k), []byte(v)); err != nil {
 			t.Fatal(err)
 		} else {
-			if readResults, err := store.Get([]byte(k)); err != nil {
+			if readResults, _, err := store.Get([]byte(k)); err != nil {
 				t.Fatal(err)
 			} else if string(readResults[0]) != string(v) {
 				t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", k, v, readResults[0])
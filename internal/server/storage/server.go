@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewStorageServer creates a server of the DKVStorage service, which
+// reports the statistics of the given store and compacts or flushes it
+// on demand. Stores lacking the respective capabilities, i.e., those
+// that are not StatsProviders, Compacters, Flushers or PrefixEstimators,
+// fail with the UNIMPLEMENTED code.
+//
+// At most one compaction runs at a time in the background, whose status
+// is reported along with the statistics until the next one starts.
+func NewStorageServer(kvs KVStore, opts ...StorageServerOption) serverpb.DKVStorageServer {
+	ss := &storageServer{kvs: kvs}
+	for _, opt := range opts {
+		opt(ss)
+	}
+	return ss
+}
+
+// A StorageServerOption is used to customize a specific aspect of the
+// server of the DKVStorage service.
+type StorageServerOption func(*storageServer)
+
+// WithQuotas manages the given Quotas through the SetQuota and ListQuotas
+// methods, which otherwise fail with the UNIMPLEMENTED code, and reports
+// the quotas of the namespaces through PrefixStats.
+func WithQuotas(quotas *Quotas) StorageServerOption {
+	return func(ss *storageServer) {
+		ss.quotas = quotas
+	}
+}
+
+type storageServer struct {
+	kvs    KVStore
+	quotas *Quotas
+
+	mu sync.Mutex
+	// compaction is the latest compaction, guarded by mu
+	compaction *serverpb.CompactionStatus
+}
+
+func (ss *storageServer) GetStorageStats(context.Context, *serverpb.GetStorageStatsRequest) (*serverpb.GetStorageStatsResponse, error) {
+	stats, err := GetStorageStats(ss.kvs)
+	switch {
+	case err == ErrStatsUnsupported:
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	case err != nil:
+		return &serverpb.GetStorageStatsResponse{Status: dkverrors.NewStatus(err)}, nil
+	}
+	res := &serverpb.GetStorageStatsResponse{
+		Status:                  &serverpb.Status{},
+		Engine:                  stats.Engine,
+		ApproximateNumberOfKeys: stats.ApproxNumKeys,
+		DiskUsageBytes:          stats.DiskUsage,
+		EngineStats:             stats.EngineStats,
+	}
+	if !stats.LastCompactionTime.IsZero() {
+		res.LastCompactionTimeMillis = toMillis(stats.LastCompactionTime)
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.compaction != nil {
+		cmpctn := *ss.compaction
+		res.Compaction = &cmpctn
+	}
+	return res, nil
+}
+
+func (ss *storageServer) CompactRange(_ context.Context, req *serverpb.CompactRangeRequest) (*serverpb.Status, error) {
+	compacter, ok := ss.kvs.(Compacter)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage engine can not be compacted manually")
+	}
+	if len(req.StartKey) > 0 && len(req.EndKey) > 0 && bytes.Compare(req.StartKey, req.EndKey) > 0 {
+		return dkverrors.NewStatus(fmt.Errorf("start key must not succeed the end key: %w", dkverrors.ErrInvalidArgument)), nil
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.compaction != nil && ss.compaction.Running {
+		startTime := time.Unix(0, ss.compaction.StartTimeMillis*int64(time.Millisecond))
+		return dkverrors.NewStatus(fmt.Errorf("compaction started at %v is running: %w", startTime, dkverrors.ErrCompactionInProgress)), nil
+	}
+	cmpctn := &serverpb.CompactionStatus{StartKey: req.StartKey, EndKey: req.EndKey, Running: true, StartTimeMillis: toMillis(time.Now())}
+	ss.compaction = cmpctn
+	go func() {
+		err := compacter.CompactRange(cmpctn.StartKey, cmpctn.EndKey)
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+		cmpctn.Running, cmpctn.EndTimeMillis = false, toMillis(time.Now())
+		if err != nil {
+			cmpctn.Error = err.Error()
+		}
+	}()
+	return &serverpb.Status{}, nil
+}
+
+func (ss *storageServer) Flush(context.Context, *serverpb.FlushRequest) (*serverpb.Status, error) {
+	flusher, ok := ss.kvs.(Flusher)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage engine can not be flushed manually")
+	}
+	return dkverrors.NewStatus(flusher.Flush()), nil
+}
+
+func (ss *storageServer) PrefixStats(ctx context.Context, req *serverpb.PrefixStatsRequest) (*serverpb.PrefixStatsResponse, error) {
+	prefix, err := NamespacedKey(req.Namespace, req.Prefix)
+	if err != nil {
+		return &serverpb.PrefixStatsResponse{Status: dkverrors.NewStatus(err)}, nil
+	}
+	var stats *PrefixStats
+	if req.Exact {
+		maxKeysPerSec := int(req.MaxKeysPerSecond)
+		if maxKeysPerSec == 0 {
+			maxKeysPerSec = DefaultPrefixRate
+		}
+		stats, err = CountPrefix(ctx, ss.kvs, prefix, maxKeysPerSec)
+	} else {
+		stats, err = EstimatePrefix(ss.kvs, prefix)
+	}
+	switch {
+	case err == ErrPrefixEstimateUnsupported:
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	case err != nil:
+		return &serverpb.PrefixStatsResponse{Status: dkverrors.NewStatus(err)}, nil
+	}
+	res := &serverpb.PrefixStatsResponse{Status: &serverpb.Status{}, NumberOfKeys: stats.NumKeys, SizeBytes: stats.Size, Exact: req.Exact}
+	if usage, present := ss.quotas.get(req.Namespace); present {
+		res.Quota = ToNamespaceQuota(req.Namespace, usage)
+	}
+	return res, nil
+}
+
+func (ss *storageServer) SetQuota(_ context.Context, req *serverpb.SetQuotaRequest) (*serverpb.Status, error) {
+	if ss.quotas == nil {
+		return nil, errQuotasUnsupported
+	}
+	return dkverrors.NewStatus(ss.quotas.Set(req.Namespace, Quota{MaxKeys: req.MaxKeys, MaxBytes: req.MaxBytes})), nil
+}
+
+func (ss *storageServer) ListQuotas(context.Context, *serverpb.ListQuotasRequest) (*serverpb.ListQuotasResponse, error) {
+	if ss.quotas == nil {
+		return nil, errQuotasUnsupported
+	}
+	usages := ss.quotas.List()
+	res := &serverpb.ListQuotasResponse{Status: &serverpb.Status{}}
+	for ns, usage := range usages {
+		res.Quotas = append(res.Quotas, ToNamespaceQuota(ns, usage))
+	}
+	sort.Slice(res.Quotas, func(i, j int) bool { return res.Quotas[i].Namespace < res.Quotas[j].Namespace })
+	return res, nil
+}
+
+var errQuotasUnsupported = status.Error(codes.Unimplemented, "DKV node does not enforce quotas")
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blockingCompacter compacts once released, recording the ranges
+type blockingCompacter struct {
+	KVStore
+	release chan error
+	ranges  chan [2]string
+}
+
+func (bc *blockingCompacter) CompactRange(startKey, endKey []byte) error {
+	bc.ranges <- [2]string{string(startKey), string(endKey)}
+	return <-bc.release
+}
+
+func TestStorageServerWithoutCapabilities(t *testing.T) {
+	// Only the capabilities of the embedded store are checked
+	storSrvr := NewStorageServer(struct{ KVStore }{})
+	ctx := context.Background()
+	_, err := storSrvr.GetStorageStats(ctx, &serverpb.GetStorageStatsRequest{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected the UNIMPLEMENTED code for a store without stats. Actual: %v", err)
+	}
+	if _, err = storSrvr.CompactRange(ctx, &serverpb.CompactRangeRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected the UNIMPLEMENTED code for a store without compactions. Actual: %v", err)
+	}
+	if _, err = storSrvr.Flush(ctx, &serverpb.FlushRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected the UNIMPLEMENTED code for a store without flushes. Actual: %v", err)
+	}
+	if _, err = storSrvr.PrefixStats(ctx, &serverpb.PrefixStatsRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected the UNIMPLEMENTED code for a store without prefix estimates. Actual: %v", err)
+	}
+}
+
+func TestStorageServerCompaction(t *testing.T) {
+	compacter := &blockingCompacter{release: make(chan error), ranges: make(chan [2]string, 1)}
+	storSrvr := NewStorageServer(compacter).(*storageServer)
+	ctx := context.Background()
+	req := &serverpb.CompactRangeRequest{StartKey: []byte("K1"), EndKey: []byte("K5")}
+	if res, err := storSrvr.CompactRange(ctx, req); err != nil || res.Code != 0 {
+		t.Fatalf("Unable to start the compaction. Status: %v, Error: %v", res, err)
+	}
+	if rng := <-compacter.ranges; rng != [2]string{"K1", "K5"} {
+		t.Errorf("Expected the range [K1, K5] to be compacted. Actual: %v", rng)
+	}
+	res, _ := storSrvr.CompactRange(ctx, req)
+	if err := dkverrors.FromStatus(res); !errors.Is(err, dkverrors.ErrCompactionInProgress) {
+		t.Errorf("Expected error: %v while compacting. Actual: %v", dkverrors.ErrCompactionInProgress, err)
+	}
+	if cmpctn := latestCompaction(storSrvr); !cmpctn.Running || cmpctn.StartTimeMillis == 0 {
+		t.Errorf("Expected the compaction to be running. Status: %v", cmpctn)
+	}
+
+	compacter.release <- errors.New("compaction failed")
+	for cmpctn := latestCompaction(storSrvr); cmpctn.Running; cmpctn = latestCompaction(storSrvr) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if cmpctn := latestCompaction(storSrvr); cmpctn.Error != "compaction failed" || cmpctn.EndTimeMillis < cmpctn.StartTimeMillis {
+		t.Errorf("Expected the compaction to have failed. Status: %v", cmpctn)
+	}
+	// Compactions can follow once the previous one completes
+	req = &serverpb.CompactRangeRequest{EndKey: []byte("K5")}
+	if res, err := storSrvr.CompactRange(ctx, req); err != nil || res.Code != 0 {
+		t.Fatalf("Unable to start the compaction. Status: %v, Error: %v", res, err)
+	}
+	<-compacter.ranges
+	compacter.release <- nil
+
+	req = &serverpb.CompactRangeRequest{StartKey: []byte("K5"), EndKey: []byte("K1")}
+	res, _ = storSrvr.CompactRange(ctx, req)
+	if err := dkverrors.FromStatus(res); !errors.Is(err, dkverrors.ErrInvalidArgument) {
+		t.Errorf("Expected error: %v for an inverted range. Actual: %v", dkverrors.ErrInvalidArgument, err)
+	}
+}
+
+func latestCompaction(ss *storageServer) serverpb.CompactionStatus {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return *ss.compaction
+}
+
+func TestStorageServerQuotas(t *testing.T) {
+	ctx := context.Background()
+	setReq := &serverpb.SetQuotaRequest{Namespace: "ns", MaxKeys: 10}
+	if _, err := NewStorageServer(struct{ KVStore }{}).SetQuota(ctx, setReq); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected the UNIMPLEMENTED code without quotas. Actual: %v", err)
+	}
+
+	kvs := &mapStore{kvs: make(map[string][]byte)}
+	quotas, err := NewQuotas(kvs, 0, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer quotas.Stop()
+	storSrvr := NewStorageServer(kvs, WithQuotas(quotas))
+	if res, err := storSrvr.SetQuota(ctx, setReq); err != nil || res.Code != 0 {
+		t.Fatalf("Unable to set the quota. Status: %v, Error: %v", res, err)
+	}
+	kvs.apply(nsPut(t, "ns", "K1", "V1"), nsPut(t, "ns", "K2", "V2"), nsPut(t, "other", "K1", "V1"))
+	if err = quotas.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	res, err := storSrvr.PrefixStats(ctx, &serverpb.PrefixStatsRequest{Namespace: "ns", Prefix: []byte("K"), Exact: true})
+	if err != nil || res.Status.Code != 0 || res.NumberOfKeys != 2 {
+		t.Fatalf("Expected 2 keys in the namespace. Response: %v, Error: %v", res, err)
+	}
+	if quota := res.Quota; quota.GetMaxKeys() != 10 || quota.GetNumberOfKeys() != 2 || quota.GetReconcileTimeMillis() == 0 {
+		t.Errorf("Expected the quota of 10 keys with 2 keys used. Actual: %v", quota)
+	}
+	listRes, err := storSrvr.ListQuotas(ctx, &serverpb.ListQuotasRequest{})
+	if err != nil || len(listRes.Quotas) != 1 || listRes.Quotas[0].Namespace != "ns" {
+		t.Errorf("Expected the quota of namespace ns alone. Response: %v, Error: %v", listRes, err)
+	}
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// A RestoreStage stages the entries being restored onto the local
+// filesystem, so that the keys of a store are replaced only after
+// all of these entries have been received and validated.
+type RestoreStage struct {
+	file   *os.File
+	wrtr   *bufio.Writer
+	lenBuf []byte
+	size   uint64
+}
+
+// NewRestoreStage creates an empty RestoreStage in the temporary
+// folder of the OS. It must be closed once it is no longer needed.
+func NewRestoreStage() (*RestoreStage, error) {
+	file, err := ioutil.TempFile("", "dkv-restore-stage-")
+	if err != nil {
+		return nil, err
+	}
+	return &RestoreStage{file: file, wrtr: bufio.NewWriter(file), lenBuf: make([]byte, binary.MaxVarintLen64)}, nil
+}
+
+// Add stages the given entries, whose keys are within the namespace
+// that they are eventually applied onto.
+func (rs *RestoreStage) Add(puts ...*serverpb.PutRequest) error {
+	for _, put := range puts {
+		if err := writeDelimited(rs.wrtr, rs.lenBuf, put); err != nil {
+			return err
+		}
+	}
+	rs.size += putsSize(puts)
+	return nil
+}
+
+// Apply replaces all the keys of the given namespace in the given
+// store with the staged entries, which are stored by upto the given
+// number of workers recording their progress onto the given
+// RestoreProgress. If the namespace is empty, all the keys of the
+// store across every namespace are replaced instead, in which case
+// the keys of the staged entries are stored verbatim. As with
+// RestoreNamespace, the replacement itself is not atomic.
+func (rs *RestoreStage) Apply(kvs KVStore, namespace string, parallelism int, progress *RestoreProgress) error {
+	if err := rs.wrtr.Flush(); err != nil {
+		return err
+	}
+	if _, err := rs.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	progress.SetBytes(0, rs.size)
+	if namespace == "" {
+		keys, err := storedKeys(kvs)
+		if err = deleteKeys(kvs, keys, err); err != nil {
+			return err
+		}
+	} else if err := deleteNamespace(kvs, namespace); err != nil {
+		return err
+	}
+
+	rdr := bufio.NewReader(rs.file)
+	return ingestPuts(kvs, parallelism, progress, func() ([]*serverpb.PutRequest, error) {
+		var puts []*serverpb.PutRequest
+		for len(puts) < nsRestoreBatchSize {
+			put := new(serverpb.PutRequest)
+			err := readDelimited(rdr, put)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if namespace != "" {
+				put.Key, _ = NamespacedKey(namespace, put.Key)
+			}
+			puts = append(puts, put)
+		}
+		return puts, nil
+	})
+}
+
+// Close discards the staged entries.
+func (rs *RestoreStage) Close() error {
+	rs.file.Close()
+	return os.Remove(rs.file.Name())
+}
+
+// storedKeys returns all the keys of the given store, across every namespace.
+func storedKeys(kvs KVStore) ([][]byte, error) {
+	iter := kvs.Iterate(nil, nil)
+	defer iter.Close()
+	var keys [][]byte
+	for iter.HasNext() {
+		key, _ := iter.Next()
+		keys = append(keys, append([]byte(nil), key...))
+	}
+	return keys, iter.Err()
+}
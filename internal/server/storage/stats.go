@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Stats are the statistics of a storage engine. Fields that
+// are common to all the engines are estimates, whose accuracy varies
+// across the engines.
+type Stats struct {
+	// Engine is the name of the storage engine.
+	Engine string
+	// ApproxNumKeys is the estimated number of keys stored, which may
+	// include the keys deleted or expired but not yet compacted away.
+	ApproxNumKeys uint64
+	// DiskUsage is the total size in bytes of the files of the engine.
+	DiskUsage uint64
+	// LastCompactionTime is when the latest compaction completed, which
+	// is zero if unknown.
+	LastCompactionTime time.Time
+	// EngineStats are the statistics specific to the engine by name.
+	EngineStats map[string]string
+}
+
+// A StatsProvider represents the capability of the underlying store
+// to report the statistics of its storage engine.
+type StatsProvider interface {
+	// GetStorageStats computes the current statistics of the engine.
+	GetStorageStats() (*Stats, error)
+}
+
+// ErrStatsUnsupported indicates that the store reports no statistics.
+var ErrStatsUnsupported = errors.New("storage engine does not report its statistics")
+
+// GetStorageStats computes the statistics of the given store if it is
+// a StatsProvider, failing with ErrStatsUnsupported otherwise.
+func GetStorageStats(kvs KVStore) (*Stats, error) {
+	if sp, ok := kvs.(StatsProvider); ok {
+		return sp.GetStorageStats()
+	}
+	return nil, ErrStatsUnsupported
+}
+
+// DiskUsage computes the total size of the regular files within the
+// given folders, along with the latest modification time of those files
+// that satisfy the given predicate, if any.
+func DiskUsage(isLatest func(file string) bool, folders ...string) (uint64, time.Time, error) {
+	var size uint64
+	var latest time.Time
+	for _, folder := range folders {
+		err := filepath.Walk(folder, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode().IsRegular() {
+				size += uint64(info.Size())
+				if isLatest != nil && isLatest(file) && info.ModTime().After(latest) {
+					latest = info.ModTime()
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+	}
+	return size, latest, nil
+}
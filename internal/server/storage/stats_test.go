@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiskUsage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dkv_disk_usage")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary folder. Error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]int{"000001.sst": 10, "000002.sst": 20, "LOG": 5}
+	for file, size := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, file), make([]byte, size), 0644); err != nil {
+			t.Fatalf("Unable to write %s. Error: %v", file, err)
+		}
+	}
+	latest := time.Now().Add(time.Hour).Truncate(time.Second)
+	os.Chtimes(filepath.Join(dir, "000001.sst"), latest, latest)
+	os.Chtimes(filepath.Join(dir, "LOG"), latest.Add(time.Hour), latest.Add(time.Hour))
+
+	isSST := func(file string) bool { return strings.HasSuffix(file, ".sst") }
+	size, modTime, err := DiskUsage(isSST, dir)
+	if err != nil {
+		t.Fatalf("Unable to compute the disk usage. Error: %v", err)
+	}
+	if size != 35 || !modTime.Equal(latest) {
+		t.Errorf("Expected a disk usage of 35 bytes, with the latest SST at %v. Actual: %d bytes at %v", latest, size, modTime)
+	}
+	if _, _, err = DiskUsage(nil, filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing folder")
+	}
+}
@@ -0,0 +1,657 @@
+// Package storagetest provides the conformance tests for the storage
+// engines backing DKV. Every engine is expected to pass these tests, so
+// that the services built upon the storage interfaces behave alike
+// irrespective of the engine in use.
+package storagetest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// A Factory opens a fresh store for every conformance test, which the
+// test closes once done. Stores that persist their state also return a
+// function that opens the store once again after it is closed, as after
+// a crash, while the others return a nil function instead.
+type Factory func(t *testing.T) (kvs storage.KVStore, reopen func() (storage.KVStore, error))
+
+var conformanceTests = []struct {
+	name string
+	test func(*testing.T, *harness)
+}{
+	{"PutAndGet", testPutAndGet},
+	{"EmptyValueAndMissingKey", testEmptyValueAndMissingKey},
+	{"MultiGet", testMultiGet},
+	{"MultiPut", testMultiPut},
+	{"Exists", testExists},
+	{"CompareAndSet", testCompareAndSet},
+	{"Increment", testIncrement},
+	{"Txn", testTxn},
+	{"MultiGetIsConsistent", testMultiGetIsConsistent},
+	{"Delete", testDelete},
+	{"ChangeNumbersAreMonotonic", testChangeNumbersAreMonotonic},
+	{"SaveChangesIsAtomic", testSaveChangesIsAtomic},
+	{"AppliedChangeNumberSurvivesRestart", testAppliedChangeNumberSurvivesRestart},
+	{"ReplicationStateSurvivesRestart", testReplicationStateSurvivesRestart},
+	{"IterationIsIsolated", testIterationIsIsolated},
+	{"UnsyncedPutsSurviveRestart", testUnsyncedPutsSurviveRestart},
+	{"IterateKeys", testIterateKeys},
+}
+
+// RunConformanceTests runs all the conformance tests as subtests of the
+// given test, each on a store opened through the given factory. Tests
+// of the capabilities the store lacks, like being a ChangeApplier, are
+// skipped.
+func RunConformanceTests(t *testing.T, factory Factory) {
+	for _, ct := range conformanceTests {
+		ct := ct
+		t.Run(ct.name, func(t *testing.T) {
+			kvs, reopen := factory(t)
+			h := &harness{kvs: kvs, reopen: reopen}
+			defer func() { h.kvs.Close() }()
+			ct.test(t, h)
+		})
+	}
+}
+
+type harness struct {
+	kvs    storage.KVStore
+	reopen func() (storage.KVStore, error)
+}
+
+func (h *harness) changePropagator(t *testing.T) storage.ChangePropagator {
+	cp, ok := h.kvs.(storage.ChangePropagator)
+	if !ok {
+		t.Skip("Store is not a ChangePropagator")
+	}
+	return cp
+}
+
+func (h *harness) replicationStateKeeper(t *testing.T) storage.ReplicationStateKeeper {
+	rsk, ok := h.kvs.(storage.ReplicationStateKeeper)
+	if !ok {
+		t.Skip("Store is not a ReplicationStateKeeper")
+	}
+	return rsk
+}
+
+func (h *harness) changeApplier(t *testing.T) storage.ChangeApplier {
+	ca, ok := h.kvs.(storage.ChangeApplier)
+	if !ok {
+		t.Skip("Store is not a ChangeApplier")
+	}
+	return ca
+}
+
+// restart closes the store and opens it once again
+func (h *harness) restart(t *testing.T) {
+	if h.reopen == nil {
+		t.Skip("Store can not be reopened")
+	}
+	if err := h.kvs.Close(); err != nil {
+		t.Fatalf("Unable to close the store. Error: %v", err)
+	}
+	kvs, err := h.reopen()
+	if err != nil {
+		t.Fatalf("Unable to reopen the store. Error: %v", err)
+	}
+	h.kvs = kvs
+}
+
+func putKeys(t *testing.T, kvs storage.KVStore, numKeys int, keyPrefix, valPrefix string) {
+	t.Helper()
+	for i := 1; i <= numKeys; i++ {
+		key, value := fmt.Sprintf("%s%d", keyPrefix, i), fmt.Sprintf("%s%d", valPrefix, i)
+		if err := kvs.Put([]byte(key), []byte(value)); err != nil {
+			t.Fatalf("Unable to PUT. Key: %s, Value: %s, Error: %v", key, value, err)
+		}
+	}
+}
+
+func checkKeys(t *testing.T, kvs storage.KVStore, numKeys int, keyPrefix, valPrefix string) {
+	t.Helper()
+	for i := 1; i <= numKeys; i++ {
+		key, expectedValue := fmt.Sprintf("%s%d", keyPrefix, i), fmt.Sprintf("%s%d", valPrefix, i)
+		if results, found, err := kvs.Get([]byte(key)); err != nil {
+			t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
+		} else if !found[0] || string(results[0]) != expectedValue {
+			t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s, Found: %t", key, expectedValue, results[0], found[0])
+		}
+	}
+}
+
+func newPutChange(chngNum uint64, kvs ...string) *serverpb.ChangeRecord {
+	chng := &serverpb.ChangeRecord{ChangeNumber: chngNum}
+	for i := 0; i+1 < len(kvs); i += 2 {
+		chng.Trxns = append(chng.Trxns, &serverpb.TrxnRecord{Type: serverpb.TrxnRecord_Put, Key: []byte(kvs[i]), Value: []byte(kvs[i+1])})
+	}
+	chng.NumberOfTrxns = uint32(len(chng.Trxns))
+	return chng
+}
+
+func testPutAndGet(t *testing.T, h *harness) {
+	putKeys(t, h.kvs, 10, "PGK", "PGV")
+	checkKeys(t, h.kvs, 10, "PGK", "PGV")
+
+	// Latest value of a key is read after it is overwritten
+	putKeys(t, h.kvs, 5, "PGK", "PGNewV")
+	checkKeys(t, h.kvs, 5, "PGK", "PGNewV")
+}
+
+func testEmptyValueAndMissingKey(t *testing.T, h *harness) {
+	emptyKey, missingKey := []byte("EmptyValKey"), []byte("EmptyValMissingKey")
+	if err := h.kvs.Put(emptyKey, nil); err != nil {
+		t.Fatalf("Unable to PUT. Key: %s, Error: %v", emptyKey, err)
+	}
+	if results, found, err := h.kvs.Get(emptyKey, missingKey); err != nil {
+		t.Fatalf("Unable to MULTIGET. Error: %v", err)
+	} else if len(results) != 2 || len(results[0]) != 0 || !found[0] || found[1] || results[1] != nil {
+		t.Errorf("Expected only key: %s to be found with an empty value. Values: %q, Found: %v", emptyKey, results, found)
+	}
+	if results, err := h.kvs.Exists(emptyKey, missingKey); err != nil {
+		t.Fatalf("Unable to check EXISTS. Error: %v", err)
+	} else if !results[0] || results[1] {
+		t.Errorf("Expected only key: %s to exist. Actual: %v", emptyKey, results)
+	}
+}
+
+func testMultiGet(t *testing.T, h *harness) {
+	numKeys := 10
+	putKeys(t, h.kvs, numKeys, "MGK", "MGV")
+	// Values are read in the order of the keys, including the missing ones
+	keys := [][]byte{[]byte("MGMissingKey")}
+	for i := numKeys; i >= 1; i-- {
+		keys = append(keys, []byte(fmt.Sprintf("MGK%d", i)))
+	}
+	results, found, err := h.kvs.Get(keys...)
+	if err != nil {
+		t.Fatalf("Unable to MULTIGET. Error: %v", err)
+	}
+	if len(results) != len(keys) || len(found) != len(keys) {
+		t.Fatalf("Expected %d values. Actual: %d, Found: %d", len(keys), len(results), len(found))
+	}
+	if found[0] || results[0] != nil {
+		t.Errorf("Expected key: %s to be missing. Actual Value: %s", keys[0], results[0])
+	}
+	for i := 1; i <= numKeys; i++ {
+		if expVal := fmt.Sprintf("MGV%d", numKeys+1-i); !found[i] || string(results[i]) != expVal {
+			t.Errorf("MULTIGET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", keys[i], expVal, results[i])
+		}
+	}
+}
+
+func testMultiPut(t *testing.T, h *harness) {
+	numKeys := 10
+	var puts []*serverpb.PutRequest
+	for i := 1; i <= numKeys; i++ {
+		puts = append(puts, &serverpb.PutRequest{Key: []byte(fmt.Sprintf("MPKey%d", i)), Value: []byte(fmt.Sprintf("MPVal%d", i))})
+	}
+	var fromChngNum uint64
+	cp, isCP := h.kvs.(storage.ChangePropagator)
+	if isCP {
+		chngNum, err := cp.GetLatestCommittedChangeNumber()
+		if err != nil {
+			t.Fatalf("Unable to get the latest change number. Error: %v", err)
+		}
+		fromChngNum = chngNum + 1
+	}
+	if err := h.kvs.MultiPut(puts...); err != nil {
+		t.Fatalf("Unable to MULTIPUT. Error: %v", err)
+	}
+	checkKeys(t, h.kvs, numKeys, "MPKey", "MPVal")
+	if !isCP {
+		return
+	}
+
+	// Puts of a MultiPut are recorded together as a single change
+	chngs, err := cp.LoadChanges(fromChngNum, numKeys)
+	if err != nil {
+		t.Fatalf("Unable to load changes. Error: %v", err)
+	}
+	if len(chngs) != 1 || len(chngs[0].Trxns) != numKeys {
+		t.Fatalf("Expected a single change of %d records. Actual changes: %d", numKeys, len(chngs))
+	}
+	for i, trxn := range chngs[0].Trxns {
+		if trxn.Type != serverpb.TrxnRecord_Put || !bytes.Equal(trxn.Key, puts[i].Key) || !bytes.Equal(trxn.Value, puts[i].Value) {
+			t.Errorf("Change record mismatch. Expected: Put %s=%s, Actual: %s %s=%s", puts[i].Key, puts[i].Value, trxn.Type, trxn.Key, trxn.Value)
+		}
+	}
+}
+
+func testExists(t *testing.T, h *harness) {
+	numKeys := 5
+	putKeys(t, h.kvs, numKeys, "ExistsKey", "ExistsVal")
+	keys := [][]byte{[]byte("MissingExistsKey")}
+	for i := 1; i <= numKeys+1; i++ {
+		keys = append(keys, []byte(fmt.Sprintf("ExistsKey%d", i)))
+	}
+	results, err := h.kvs.Exists(keys...)
+	if err != nil {
+		t.Fatalf("Unable to check EXISTS. Error: %v", err)
+	}
+	if len(results) != len(keys) {
+		t.Fatalf("Expected %d results. Actual: %d", len(keys), len(results))
+	}
+	for i, result := range results {
+		if expResult := i >= 1 && i <= numKeys; result != expResult {
+			t.Errorf("Presence mismatch for key: %s. Expected: %t, Actual: %t", keys[i], expResult, result)
+		}
+	}
+}
+
+func testCompareAndSet(t *testing.T, h *harness) {
+	key, val := []byte("CASKey"), []byte("CASVal")
+	if updated, err := h.kvs.CompareAndSet(key, nil, val); err != nil {
+		t.Fatalf("Unable to COMPAREANDSET. Key: %s, Error: %v", key, err)
+	} else if !updated {
+		t.Errorf("Expected the absent key: %s to be set", key)
+	}
+	if updated, _ := h.kvs.CompareAndSet(key, nil, []byte("CASNewVal")); updated {
+		t.Errorf("Expected the present key: %s to not be set when expecting it to be absent", key)
+	}
+	if updated, _ := h.kvs.CompareAndSet(key, []byte("CASWrongVal"), []byte("CASNewVal")); updated {
+		t.Errorf("Expected the key: %s to not be set for a mismatched expected value", key)
+	}
+	if updated, err := h.kvs.CompareAndSet(key, val, []byte("CASNewVal")); err != nil {
+		t.Fatalf("Unable to COMPAREANDSET. Key: %s, Error: %v", key, err)
+	} else if !updated {
+		t.Errorf("Expected the key: %s to be set for a matching expected value", key)
+	}
+	if results, _, err := h.kvs.Get(key); err != nil {
+		t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
+	} else if string(results[0]) != "CASNewVal" {
+		t.Errorf("GET mismatch. Key: %s, Expected Value: CASNewVal, Actual Value: %s", key, results[0])
+	}
+}
+
+func testIncrement(t *testing.T, h *harness) {
+	// Missing keys start from zero
+	key := []byte("IncrKey")
+	if value, err := h.kvs.Increment(key, 0); err != nil {
+		t.Fatalf("Unable to INCREMENT. Key: %s, Error: %v", key, err)
+	} else if value != 0 {
+		t.Errorf("INCREMENT mismatch for a missing key. Key: %s, Expected Value: 0, Actual Value: %d", key, value)
+	}
+	if value, err := h.kvs.Increment(key, 10); err != nil {
+		t.Fatalf("Unable to INCREMENT. Key: %s, Error: %v", key, err)
+	} else if value != 10 {
+		t.Errorf("INCREMENT mismatch. Key: %s, Expected Value: 10, Actual Value: %d", key, value)
+	}
+	if value, err := h.kvs.Increment(key, -15); err != nil {
+		t.Fatalf("Unable to INCREMENT. Key: %s, Error: %v", key, err)
+	} else if value != -5 {
+		t.Errorf("INCREMENT mismatch. Key: %s, Expected Value: -5, Actual Value: %d", key, value)
+	}
+	if results, _, err := h.kvs.Get(key); err != nil {
+		t.Fatalf("Unable to GET. Key: %s, Error: %v", key, err)
+	} else if value, _, _ := storage.IncrementValue(results[0], 0); len(results[0]) != 8 || value != -5 {
+		t.Errorf("GET mismatch. Key: %s, Expected Value: -5, Actual Value: %q", key, results[0])
+	}
+
+	// Values put as 64 bit integers are incremented alike
+	numKey := []byte("IncrNumKey")
+	_, numVal, _ := storage.IncrementValue(nil, 41)
+	if err := h.kvs.Put(numKey, numVal); err != nil {
+		t.Fatalf("Unable to PUT. Key: %s, Error: %v", numKey, err)
+	}
+	if value, err := h.kvs.Increment(numKey, 1); err != nil || value != 42 {
+		t.Errorf("INCREMENT mismatch. Key: %s, Expected Value: 42, Actual Value: %d, Error: %v", numKey, value, err)
+	}
+
+	// Values of any size other than 8 bytes are left as they are
+	for _, nonNumVal := range []string{"Incr", "IncrNonNumVal"} {
+		nonNumKey := []byte("IncrNonNumKey" + nonNumVal)
+		if err := h.kvs.Put(nonNumKey, []byte(nonNumVal)); err != nil {
+			t.Fatalf("Unable to PUT. Key: %s, Error: %v", nonNumKey, err)
+		}
+		if _, err := h.kvs.Increment(nonNumKey, 1); !errors.Is(err, storage.ErrNonNumericValue) {
+			t.Errorf("Expected error: %v on incrementing a value of %d bytes. Actual: %v", storage.ErrNonNumericValue, len(nonNumVal), err)
+		}
+		if results, _, err := h.kvs.Get(nonNumKey); err != nil {
+			t.Fatalf("Unable to GET. Key: %s, Error: %v", nonNumKey, err)
+		} else if string(results[0]) != nonNumVal {
+			t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual Value: %s", nonNumKey, nonNumVal, results[0])
+		}
+	}
+}
+
+func testTxn(t *testing.T, h *harness) {
+	key1, key2, key3 := []byte("TxnKey1"), []byte("TxnKey2"), []byte("TxnKey3")
+	if err := h.kvs.Put(key1, []byte("TxnVal1")); err != nil {
+		t.Fatalf("Unable to PUT. Key: %s, Error: %v", key1, err)
+	}
+	txnReq := &serverpb.TxnRequest{
+		Conditions: []*serverpb.TxnCondition{
+			{Type: serverpb.TxnCondition_Equals, Key: key1, Value: []byte("TxnVal1")},
+			{Type: serverpb.TxnCondition_Absent, Key: key2},
+		},
+		ThenMutations: []*serverpb.TrxnRecord{
+			{Type: serverpb.TrxnRecord_Put, Key: key2, Value: []byte("TxnVal2")},
+			{Type: serverpb.TrxnRecord_Delete, Key: key1},
+		},
+		ElseMutations: []*serverpb.TrxnRecord{
+			{Type: serverpb.TrxnRecord_Put, Key: key3, Value: []byte("TxnVal3")},
+		},
+	}
+	if succeeded, err := h.kvs.Txn(txnReq); err != nil {
+		t.Fatalf("Unable to TXN. Error: %v", err)
+	} else if !succeeded {
+		t.Errorf("Expected the conditions of the TXN to hold")
+	}
+	// Deletes of the TXN apply along with its puts
+	if values, found, err := h.kvs.Get(key1, key2, key3); err != nil {
+		t.Fatalf("Unable to GET. Error: %v", err)
+	} else if found[0] || !found[1] || string(values[1]) != "TxnVal2" || found[2] {
+		t.Errorf("Expected only the Then mutations to be applied. Values: %q, Found: %v", values, found)
+	}
+	// Neither of the conditions hold any longer
+	if succeeded, err := h.kvs.Txn(txnReq); err != nil {
+		t.Fatalf("Unable to TXN. Error: %v", err)
+	} else if succeeded {
+		t.Errorf("Expected the conditions of the TXN to not hold")
+	}
+	if values, found, err := h.kvs.Get(key2, key3); err != nil {
+		t.Fatalf("Unable to GET. Error: %v", err)
+	} else if string(values[0]) != "TxnVal2" || !found[1] || string(values[1]) != "TxnVal3" {
+		t.Errorf("Expected only the Else mutations to be applied. Values: %q, Found: %v", values, found)
+	}
+
+	// Missing keys equal no value, not even an empty one
+	missingReq := &serverpb.TxnRequest{
+		Conditions:    []*serverpb.TxnCondition{{Type: serverpb.TxnCondition_Equals, Key: []byte("TxnMissingKey"), Value: nil}},
+		ThenMutations: []*serverpb.TrxnRecord{{Type: serverpb.TrxnRecord_Delete, Key: key2}},
+		ElseMutations: []*serverpb.TrxnRecord{{Type: serverpb.TrxnRecord_Delete, Key: key3}},
+	}
+	if succeeded, err := h.kvs.Txn(missingReq); err != nil {
+		t.Fatalf("Unable to TXN. Error: %v", err)
+	} else if succeeded {
+		t.Errorf("Expected the condition on a missing key to not hold")
+	}
+	if _, found, err := h.kvs.Get(key2, key3); err != nil {
+		t.Fatalf("Unable to GET. Error: %v", err)
+	} else if !found[0] || found[1] {
+		t.Errorf("Expected only the Else delete to be applied. Found: %v", found)
+	}
+}
+
+func testMultiGetIsConsistent(t *testing.T, h *harness) {
+	keys := [][]byte{[]byte("ConsistentKey1"), []byte("ConsistentKey2")}
+	numWrites := 1000
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < numWrites; i++ {
+			value := []byte(fmt.Sprintf("ConsistentVal%d", i))
+			if err := h.kvs.MultiPut(&serverpb.PutRequest{Key: keys[0], Value: value}, &serverpb.PutRequest{Key: keys[1], Value: value}); err != nil {
+				t.Errorf("Unable to MULTIPUT. Error: %v", err)
+				return
+			}
+		}
+	}()
+
+	// Both the keys are always written together, hence read alike
+	for writing := true; writing; {
+		select {
+		case <-done:
+			writing = false
+		default:
+		}
+		results, found, err := h.kvs.Get(keys...)
+		if err != nil {
+			<-done
+			t.Fatalf("Unable to MULTIGET. Error: %v", err)
+		}
+		if found[0] != found[1] || !bytes.Equal(results[0], results[1]) {
+			<-done
+			t.Fatalf("Torn MULTIGET. Values: %q, %q", results[0], results[1])
+		}
+	}
+}
+
+func testDelete(t *testing.T, h *harness) {
+	numKeys := 5
+	putKeys(t, h.kvs, numKeys, "DelKey", "DelVal")
+	keys := [][]byte{[]byte("MissingDelKey")}
+	for i := 1; i <= numKeys; i++ {
+		keys = append(keys, []byte(fmt.Sprintf("DelKey%d", i)))
+	}
+	// Missing keys are silently ignored
+	if err := h.kvs.Delete(keys...); err != nil {
+		t.Fatalf("Unable to DELETE. Error: %v", err)
+	}
+	if results, found, err := h.kvs.Get(keys...); err != nil {
+		t.Fatalf("Unable to MULTIGET. Error: %v", err)
+	} else {
+		for i, key := range keys {
+			if found[i] || results[i] != nil {
+				t.Errorf("Expected key: %s to be deleted. Actual Value: %s", key, results[i])
+			}
+		}
+	}
+}
+
+func testChangeNumbersAreMonotonic(t *testing.T, h *harness) {
+	cp := h.changePropagator(t)
+	prevChngNum, err := cp.GetLatestCommittedChangeNumber()
+	if err != nil {
+		t.Fatalf("Unable to get the latest change number. Error: %v", err)
+	}
+	fromChngNum := prevChngNum + 1
+	mutations := []func() error{
+		func() error { return h.kvs.Put([]byte("MonoKey1"), []byte("MonoVal1")) },
+		func() error {
+			return h.kvs.MultiPut(&serverpb.PutRequest{Key: []byte("MonoKey2"), Value: []byte("MonoVal2")}, &serverpb.PutRequest{Key: []byte("MonoKey3"), Value: []byte("MonoVal3")})
+		},
+		func() error { return h.kvs.Delete([]byte("MonoKey1")) },
+		func() error { return h.kvs.Put([]byte("MonoKey1"), []byte("MonoVal4")) },
+	}
+	for i, mutate := range mutations {
+		if err := mutate(); err != nil {
+			t.Fatalf("Unable to perform mutation %d. Error: %v", i, err)
+		}
+		chngNum, err := cp.GetLatestCommittedChangeNumber()
+		if err != nil {
+			t.Fatalf("Unable to get the latest change number. Error: %v", err)
+		}
+		if chngNum <= prevChngNum {
+			t.Errorf("Expected the change number to increase beyond %d after mutation %d. Actual: %d", prevChngNum, i, chngNum)
+		}
+		prevChngNum = chngNum
+	}
+
+	chngs, err := cp.LoadChanges(fromChngNum, 100)
+	if err != nil {
+		t.Fatalf("Unable to load changes. Error: %v", err)
+	}
+	if len(chngs) == 0 {
+		t.Fatal("Expected the mutations to be loaded as changes")
+	}
+	for i := 1; i < len(chngs); i++ {
+		if chngs[i].ChangeNumber <= chngs[i-1].ChangeNumber {
+			t.Errorf("Expected the changes to be loaded in order. Change number %d follows %d", chngs[i].ChangeNumber, chngs[i-1].ChangeNumber)
+		}
+	}
+	if first, last := chngs[0].ChangeNumber, chngs[len(chngs)-1].ChangeNumber; first < fromChngNum || last != prevChngNum {
+		t.Errorf("Expected the changes from %d upto %d. Actual: %d upto %d", fromChngNum, prevChngNum, first, last)
+	}
+}
+
+func testSaveChangesIsAtomic(t *testing.T, h *harness) {
+	ca := h.changeApplier(t)
+	numChngs := 500
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= numChngs; i++ {
+			val := fmt.Sprintf("AtomicVal%d", i)
+			if _, err := ca.SaveChanges([]*serverpb.ChangeRecord{newPutChange(uint64(i), "AtomicKey1", val, "AtomicKey2", val)}); err != nil {
+				t.Errorf("Unable to save change %d. Error: %v", i, err)
+				return
+			}
+		}
+	}()
+
+	// Records of a change are always applied together, hence read alike
+	keys := [][]byte{[]byte("AtomicKey1"), []byte("AtomicKey2")}
+	for applying := true; applying; {
+		select {
+		case <-done:
+			applying = false
+		default:
+		}
+		results, found, err := h.kvs.Get(keys...)
+		if err != nil {
+			<-done
+			t.Fatalf("Unable to MULTIGET. Error: %v", err)
+		}
+		if found[0] != found[1] || !bytes.Equal(results[0], results[1]) {
+			<-done
+			t.Fatalf("Change applied partially. Values: %q, %q", results[0], results[1])
+		}
+	}
+	if chngNum, err := ca.GetLatestAppliedChangeNumber(); err != nil || chngNum != uint64(numChngs) {
+		t.Errorf("Applied change number mismatch. Expected: %d, Actual: %d, Error: %v", numChngs, chngNum, err)
+	}
+}
+
+func testAppliedChangeNumberSurvivesRestart(t *testing.T, h *harness) {
+	ca := h.changeApplier(t)
+	chngs := []*serverpb.ChangeRecord{newPutChange(1, "RestartKey1", "RestartVal1"), newPutChange(2, "RestartKey2", "RestartVal2")}
+	if appldChngNum, err := ca.SaveChangeBatch(chngs); err != nil || appldChngNum != 2 {
+		t.Fatalf("Unable to save changes. Applied change number: %d, Error: %v", appldChngNum, err)
+	}
+	if err := storage.Sync(h.kvs); err != nil {
+		t.Fatalf("Unable to sync the store. Error: %v", err)
+	}
+
+	h.restart(t)
+	ca = h.changeApplier(t)
+	if chngNum, err := ca.GetLatestAppliedChangeNumber(); err != nil || chngNum != 2 {
+		t.Errorf("Applied change number mismatch after restart. Expected: 2, Actual: %d, Error: %v", chngNum, err)
+	}
+	checkKeys(t, h.kvs, 2, "RestartKey", "RestartVal")
+	// Changes following the recovered ones continue to apply
+	if appldChngNum, err := ca.SaveChangeBatch([]*serverpb.ChangeRecord{newPutChange(3, "RestartKey3", "RestartVal3")}); err != nil || appldChngNum != 3 {
+		t.Errorf("Unable to save changes after restart. Applied change number: %d, Error: %v", appldChngNum, err)
+	}
+}
+
+func testReplicationStateSurvivesRestart(t *testing.T, h *harness) {
+	rsk := h.replicationStateKeeper(t)
+	if state, err := rsk.LoadReplicationState(); err != nil || state != nil {
+		t.Fatalf("Expected no replication state on a fresh store. State: %q, Error: %v", state, err)
+	}
+	chngs := []*serverpb.ChangeRecord{newPutChange(1, "StateKey1", "StateVal1")}
+	if appldChngNum, err := rsk.SaveChangeBatchWithState(chngs, []byte("State1")); err != nil || appldChngNum != 1 {
+		t.Fatalf("Unable to save changes. Applied change number: %d, Error: %v", appldChngNum, err)
+	}
+	// Changes saved without a state retain the one recorded earlier
+	chngs = []*serverpb.ChangeRecord{newPutChange(2, "StateKey2", "StateVal2")}
+	if appldChngNum, err := rsk.SaveChangeBatchWithState(chngs, nil); err != nil || appldChngNum != 2 {
+		t.Fatalf("Unable to save changes. Applied change number: %d, Error: %v", appldChngNum, err)
+	}
+	if state, err := rsk.LoadReplicationState(); err != nil || string(state) != "State1" {
+		t.Errorf("Replication state mismatch. Expected: State1, Actual: %q, Error: %v", state, err)
+	}
+	chngs = []*serverpb.ChangeRecord{newPutChange(3, "StateKey3", "StateVal3")}
+	if appldChngNum, err := rsk.SaveChangeBatchWithState(chngs, []byte("State3")); err != nil || appldChngNum != 3 {
+		t.Fatalf("Unable to save changes. Applied change number: %d, Error: %v", appldChngNum, err)
+	}
+	if err := storage.Sync(h.kvs); err != nil {
+		t.Fatalf("Unable to sync the store. Error: %v", err)
+	}
+
+	h.restart(t)
+	rsk = h.replicationStateKeeper(t)
+	if state, err := rsk.LoadReplicationState(); err != nil || string(state) != "State3" {
+		t.Errorf("Replication state mismatch after restart. Expected: State3, Actual: %q, Error: %v", state, err)
+	}
+	checkKeys(t, h.kvs, 3, "StateKey", "StateVal")
+}
+
+func testIterationIsIsolated(t *testing.T, h *harness) {
+	numKeys := 5
+	putKeys(t, h.kvs, numKeys, "IsoKey", "IsoVal")
+	iter := h.kvs.Iterate([]byte("IsoKey"), nil)
+	defer iter.Close()
+
+	// Mutations following the creation of the iterator are not visible
+	putKeys(t, h.kvs, numKeys, "IsoKey", "IsoNewVal")
+	if err := h.kvs.Delete([]byte("IsoKey1")); err != nil {
+		t.Fatalf("Unable to DELETE. Error: %v", err)
+	}
+	if err := h.kvs.Put([]byte("IsoKey0"), []byte("IsoVal0")); err != nil {
+		t.Fatalf("Unable to PUT. Error: %v", err)
+	}
+
+	i := 0
+	for ; iter.HasNext(); i++ {
+		key, val := iter.Next()
+		if i >= numKeys {
+			t.Errorf("Unexpected key: %s iterated", key)
+			continue
+		}
+		if expKey, expVal := fmt.Sprintf("IsoKey%d", i+1), fmt.Sprintf("IsoVal%d", i+1); string(key) != expKey || string(val) != expVal {
+			t.Errorf("Iteration mismatch. Expected: %s=%s, Actual: %s=%s", expKey, expVal, key, val)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Unable to iterate. Error: %v", err)
+	}
+	if i != numKeys {
+		t.Errorf("Expected %d keys to be iterated. Actual: %d", numKeys, i)
+	}
+}
+
+func testUnsyncedPutsSurviveRestart(t *testing.T, h *harness) {
+	numKeys := 10
+	var syncPuts, asyncPuts []*serverpb.PutRequest
+	for i := 1; i <= numKeys; i++ {
+		syncPuts = append(syncPuts, &serverpb.PutRequest{Key: []byte(fmt.Sprintf("SyncKey%d", i)), Value: []byte(fmt.Sprintf("SyncVal%d", i))})
+		asyncPuts = append(asyncPuts, &serverpb.PutRequest{Key: []byte(fmt.Sprintf("AsyncKey%d", i)), Value: []byte(fmt.Sprintf("AsyncVal%d", i))})
+	}
+	if err := storage.MultiPutWithSync(h.kvs, true, syncPuts...); err != nil {
+		t.Fatalf("Unable to MULTIPUT synced keys. Error: %v", err)
+	}
+	if err := storage.MultiPutWithSync(h.kvs, false, asyncPuts...); err != nil {
+		t.Fatalf("Unable to MULTIPUT unsynced keys. Error: %v", err)
+	}
+	// Unsynced puts are read alike, only their durability differs
+	checkKeys(t, h.kvs, numKeys, "SyncKey", "SyncVal")
+	checkKeys(t, h.kvs, numKeys, "AsyncKey", "AsyncVal")
+
+	// Only a crash of the node loses unsynced puts, not a restart
+	h.restart(t)
+	checkKeys(t, h.kvs, numKeys, "SyncKey", "SyncVal")
+	checkKeys(t, h.kvs, numKeys, "AsyncKey", "AsyncVal")
+}
+
+func testIterateKeys(t *testing.T, h *harness) {
+	numKeys := 5
+	putKeys(t, h.kvs, numKeys, "KeysOnlyKey", "KeysOnlyVal")
+	if err := h.kvs.MultiPut(&serverpb.PutRequest{Key: []byte("KeysOnlyKey6"), Value: []byte("KeysOnlyVal6"), ExpireTS: 1}); err != nil {
+		t.Fatalf("Unable to PUT an expired key. Error: %v", err)
+	}
+	iter := storage.IterateKeys(h.kvs, []byte("KeysOnlyKey"), []byte("KeysOnlyKey2"))
+	defer iter.Close()
+
+	// Keys are iterated as per Iterate, skipping the expired ones
+	i := 2
+	for ; iter.HasNext(); i++ {
+		key, val := iter.Next()
+		if expKey := fmt.Sprintf("KeysOnlyKey%d", i); string(key) != expKey || val != nil {
+			t.Errorf("Iteration mismatch. Expected: %s with a nil value, Actual: %s=%q", expKey, key, val)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Unable to iterate. Error: %v", err)
+	}
+	if i != numKeys+1 {
+		t.Errorf("Expected the keys upto KeysOnlyKey%d to be iterated. Actual: upto KeysOnlyKey%d", numKeys, i-1)
+	}
+}
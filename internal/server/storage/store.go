@@ -1,11 +1,18 @@
 package storage
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"time"
 
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
 )
 
@@ -16,10 +23,52 @@ type KVStore interface {
 	io.Closer
 	// Put stores the association between the given key and value
 	Put(key []byte, value []byte) error
-	// Get bulk fetches the associated values for the given keys.
+	// MultiPut stores all the given associations between keys and
+	// values as a single atomic batch. Either all of them are stored
+	// or none of them are. Associations with a non-zero `ExpireTS`
+	// are no longer visible to any of the read operations once this
+	// time passes, and are eventually removed from the store.
+	MultiPut(puts ...*serverpb.PutRequest) error
+	// CompareAndSet atomically associates the given new value with
+	// the given key only if its current value matches the given
+	// expected value, as per ValuesMatch. An empty expected value
+	// indicates that the key must be absent. Returns true only if the
+	// update happened.
+	CompareAndSet(key, expectedValue, newValue []byte) (bool, error)
+	// Increment atomically adds the given delta to the current value
+	// of the given key, interpreted as a big-endian encoded 64 bit
+	// integer, and returns the resulting value. Absent keys are
+	// considered to be zero. Returns ErrNonNumericValue if the current
+	// value is not a numeric one, in which case it is left untouched.
+	Increment(key []byte, delta int64) (int64, error)
+	// Txn atomically checks the conditions of the given transaction on
+	// the current values of their keys, as per TxnConditionsHold, and
+	// applies either its then or its else mutations depending on whether
+	// all of them hold, as a single change. Returns whether all the
+	// conditions held.
+	Txn(txnReq *serverpb.TxnRequest) (bool, error)
+	// Delete removes the given keys along with their associated
+	// values. Keys that are not present are silently ignored.
+	Delete(keys ...[]byte) error
+	// Get bulk fetches the associated values for the given keys,
+	// along with the presence of each of them so that absent keys
+	// can be distinguished from keys associated with empty values.
+	// Absent keys are read as nil values instead of failing the read.
+	// All the keys are read from a single consistent snapshot of the
+	// keyspace, so that a concurrent MultiPut is either wholly visible
+	// or not at all, never torn across the keys read.
 	// Note that during partial failures, any successful results
 	// are discarded and an error is returned instead.
-	Get(keys ...[]byte) ([][]byte, error)
+	Get(keys ...[]byte) ([][]byte, []bool, error)
+	// Exists checks for the presence of each of the given keys
+	// without loading their associated values.
+	Exists(keys ...[]byte) ([]bool, error)
+	// Iterate iterates over all the keys that match the given prefix
+	// in their lexicographical order, beginning with the given start
+	// key if its not empty. Iteration happens over a consistent
+	// snapshot of the keyspace so that concurrent mutations are not
+	// visible. Callers must close the returned iterator once done.
+	Iterate(keyPrefix, startKey []byte) Iterator
 	// GetSnapshot retrieves the entire keyspace representation
 	// with latest value against every key.
 	GetSnapshot() ([]byte, error)
@@ -29,6 +78,302 @@ type KVStore interface {
 	PutSnapshot([]byte) error
 }
 
+// A ContextReader represents the capability of the underlying store
+// to abandon multi-key reads once the context of their request is done,
+// so that reads for clients that gave up waste no further IO.
+type ContextReader interface {
+	// GetWithContext is same as KVStore.Get except that it fails with
+	// the error of the given context once that context is done.
+	GetWithContext(ctx context.Context, keys ...[]byte) ([][]byte, []bool, error)
+}
+
+// ContextCheckInterval is the number of keys read by ContextReaders
+// between successive checks of the context of the read.
+const ContextCheckInterval = 1024
+
+// GetWithContext reads the given keys from the given store as per
+// KVStore.Get, abandoning the read once the given context is done.
+// Stores that are not ContextReaders are read only if the context is
+// not yet done, since their reads can not be abandoned midway.
+func GetWithContext(ctx context.Context, kvs KVStore, keys ...[]byte) ([][]byte, []bool, error) {
+	if cr, ok := kvs.(ContextReader); ok {
+		return cr.GetWithContext(ctx, keys...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return kvs.Get(keys...)
+}
+
+// GetEach is same as GetWithContext except that, should the keys fail
+// to be read together, every key is read on its own so that the keys
+// failing to be read do not fail the reads of the others. The errors
+// of the keys are returned in the same order as the keys, whereas the
+// returned error is set only once the given context is done.
+func GetEach(ctx context.Context, kvs KVStore, keys ...[]byte) ([][]byte, []bool, []error, error) {
+	errs := make([]error, len(keys))
+	vals, found, err := GetWithContext(ctx, kvs, keys...)
+	switch {
+	case err == nil:
+		return vals, found, errs, nil
+	case ctx.Err() != nil:
+		return nil, nil, nil, err
+	case len(keys) == 1:
+		errs[0] = err
+		return [][]byte{nil}, []bool{false}, errs, nil
+	}
+
+	vals, found = make([][]byte, len(keys)), make([]bool, len(keys))
+	for i, key := range keys {
+		val, fnd, err := GetWithContext(ctx, kvs, key)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, nil, ctxErr
+		}
+		if err != nil {
+			errs[i] = err
+		} else {
+			vals[i], found[i] = val[0], fnd[0]
+		}
+	}
+	return vals, found, errs, nil
+}
+
+// A KeyIterator represents the capability of the underlying store to
+// iterate over its keys without reading their values.
+type KeyIterator interface {
+	// IterateKeys is same as KVStore.Iterate except that the returned
+	// iterator returns nil values.
+	IterateKeys(keyPrefix, startKey []byte) Iterator
+}
+
+// IterateKeys iterates over the keys of the given store through
+// KeyIterator.IterateKeys if it is a KeyIterator. Other stores read
+// the values all the same, which are then dropped by the iterator.
+func IterateKeys(kvs KVStore, keyPrefix, startKey []byte) Iterator {
+	if ki, ok := kvs.(KeyIterator); ok {
+		return ki.IterateKeys(keyPrefix, startKey)
+	}
+	return &keysIter{kvs.Iterate(keyPrefix, startKey)}
+}
+
+type keysIter struct {
+	Iterator
+}
+
+func (ki *keysIter) Next() ([]byte, []byte) {
+	key, _ := ki.Iterator.Next()
+	return key, nil
+}
+
+// TruncateValue truncates the given value to the given maximum size,
+// unless it is zero, reporting whether the value was truncated.
+func TruncateValue(value []byte, maxSize uint32) ([]byte, bool) {
+	if maxSize == 0 || uint32(len(value)) <= maxSize {
+		return value, false
+	}
+	return value[:maxSize], true
+}
+
+// A Syncer represents the capability of the underlying store to
+// persist the writes it buffers in memory, so that none of them are
+// lost when the process exits, like during a graceful shutdown.
+type Syncer interface {
+	// Sync persists all the writes accepted so far onto the disk.
+	Sync() error
+}
+
+// Sync persists the writes buffered by the given store, if it is
+// a Syncer, and is a no-op otherwise.
+func Sync(kvs KVStore) error {
+	if syncer, ok := kvs.(Syncer); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// A SyncPutter represents the capability of the underlying store to
+// choose for every batch of puts whether it is persisted onto the disk
+// before the write returns, rather than always following its default.
+type SyncPutter interface {
+	// MultiPutWithSync writes the given puts as per MultiPut, persisting
+	// them onto the disk before returning only if sync is true. Unsynced
+	// puts survive a crash of the process, but not that of the node.
+	MultiPutWithSync(sync bool, puts ...*serverpb.PutRequest) error
+}
+
+// MultiPutWithSync writes the given puts onto the given store through
+// SyncPutter.MultiPutWithSync if it is a SyncPutter. Other stores write
+// them through MultiPut as per their own durability.
+func MultiPutWithSync(kvs KVStore, sync bool, puts ...*serverpb.PutRequest) error {
+	if sp, ok := kvs.(SyncPutter); ok {
+		return sp.MultiPutWithSync(sync, puts...)
+	}
+	return kvs.MultiPut(puts...)
+}
+
+// A Compacter represents the capability of the underlying store to
+// compact ranges of its keys on demand, reclaiming the space held by
+// the keys deleted or overwritten within them.
+type Compacter interface {
+	// CompactRange compacts the keys from the given start key to the
+	// given end key, either end being unbounded if empty. It returns
+	// once the compaction completes.
+	CompactRange(startKey, endKey []byte) error
+}
+
+// A Flusher represents the capability of the underlying store to write
+// the keys it buffers in memory onto its files on demand.
+type Flusher interface {
+	// Flush writes the keys buffered so far onto the files of the store.
+	Flush() error
+}
+
+// A BulkLoader represents the capability of the underlying store to
+// write entries directly onto its key space, without committing them
+// as changes, which is far quicker for loading many keys at once.
+type BulkLoader interface {
+	// BulkLoad writes the given entries as per MultiPut, except that
+	// they are not committed as changes, hence never replicated as such.
+	// They may not be durable until the load is finished.
+	BulkLoad(entries ...*serverpb.PutRequest) error
+	// FinishBulkLoad persists the entries written by BulkLoad and then
+	// commits a single change marking the load, returning its number.
+	// LoadChanges fails with ErrChangesUnavailable for this change and
+	// those before it, so that they are not replicated without the
+	// loaded entries.
+	FinishBulkLoad() (uint64, error)
+}
+
+// ErrBulkLoadUnsupported indicates that the store can not bulk load.
+var ErrBulkLoadUnsupported = errors.New("storage engine does not support bulk loads")
+
+// BulkLoad writes the given entries onto the given store through
+// BulkLoader.BulkLoad, failing with ErrBulkLoadUnsupported if the
+// store is not a BulkLoader.
+func BulkLoad(kvs KVStore, entries ...*serverpb.PutRequest) error {
+	if bl, ok := kvs.(BulkLoader); ok {
+		return bl.BulkLoad(entries...)
+	}
+	return ErrBulkLoadUnsupported
+}
+
+// FinishBulkLoad finishes the load onto the given store through
+// BulkLoader.FinishBulkLoad, failing with ErrBulkLoadUnsupported if
+// the store is not a BulkLoader.
+func FinishBulkLoad(kvs KVStore) (uint64, error) {
+	if bl, ok := kvs.(BulkLoader); ok {
+		return bl.FinishBulkLoad()
+	}
+	return 0, ErrBulkLoadUnsupported
+}
+
+// ErrNonNumericValue indicates that the current value of a key being
+// incremented is not a big-endian encoded 64 bit integer.
+var ErrNonNumericValue = dkverrors.ErrNonNumericValue
+
+// IncrementValue adds the given delta to the given value, interpreted
+// as a big-endian encoded 64 bit integer, and returns the resulting value
+// along with its encoded form. An empty value is considered to be zero.
+func IncrementValue(value []byte, delta int64) (int64, []byte, error) {
+	var curr int64
+	switch len(value) {
+	case 0:
+	case 8:
+		curr = int64(binary.BigEndian.Uint64(value))
+	default:
+		return 0, nil, ErrNonNumericValue
+	}
+	res := curr + delta
+	resVal := make([]byte, 8)
+	binary.BigEndian.PutUint64(resVal, uint64(res))
+	return res, resVal, nil
+}
+
+// SizeLimits captures the maximum permissible sizes, in bytes, of the
+// keys and values being stored. Zero indicates that there is no limit.
+type SizeLimits struct {
+	MaxKeySize   int
+	MaxValueSize int
+}
+
+var (
+	// ErrKeyTooLarge indicates that the key exceeds its maximum permissible size.
+	ErrKeyTooLarge = dkverrors.ErrKeyTooLarge
+	// ErrValueTooLarge indicates that the value exceeds its maximum permissible size.
+	ErrValueTooLarge = dkverrors.ErrValueTooLarge
+)
+
+// Check ensures that the given key and value are within these limits.
+// The returned error wraps either ErrKeyTooLarge or ErrValueTooLarge.
+func (sl SizeLimits) Check(key, value []byte) error {
+	if sl.MaxKeySize > 0 && len(key) > sl.MaxKeySize {
+		return fmt.Errorf("%w - size: %d bytes, limit: %d bytes", ErrKeyTooLarge, len(key), sl.MaxKeySize)
+	}
+	if sl.MaxValueSize > 0 && len(value) > sl.MaxValueSize {
+		return fmt.Errorf("%w - size: %d bytes, limit: %d bytes", ErrValueTooLarge, len(value), sl.MaxValueSize)
+	}
+	return nil
+}
+
+// An Iterator represents the capability of the underlying store
+// to traverse over its keyspace in a sorted order.
+type Iterator interface {
+	io.Closer
+	// HasNext returns true if there are more entries to iterate.
+	HasNext() bool
+	// Next returns the current key and value, and advances the
+	// iterator onto the next entry.
+	Next() ([]byte, []byte)
+	// Err returns the error, if any, that occurred during iteration.
+	Err() error
+	// ExpireTS returns the time, in unix seconds, at which the entry
+	// last returned by Next expires. Zero indicates no expiry.
+	ExpireTS() uint64
+}
+
+// NewContextIterator wraps the given iterator such that the iteration
+// ends once the given context is done, upon which Err returns the error
+// of that context. Callers must still close the returned iterator.
+func NewContextIterator(ctx context.Context, iter Iterator) Iterator {
+	return &ctxIter{Iterator: iter, ctx: ctx}
+}
+
+type ctxIter struct {
+	Iterator
+	ctx context.Context
+	err error
+}
+
+func (ci *ctxIter) HasNext() bool {
+	if ci.err == nil {
+		ci.err = ci.ctx.Err()
+	}
+	return ci.err == nil && ci.Iterator.HasNext()
+}
+
+func (ci *ctxIter) Err() error {
+	if ci.err != nil {
+		return ci.err
+	}
+	return ci.Iterator.Err()
+}
+
+// IsExpired checks if an entry with the given expiry time, in unix
+// seconds, has expired. Entries with zero expiry time never expire.
+func IsExpired(expireTS uint64) bool {
+	return expireTS > 0 && expireTS <= uint64(time.Now().Unix())
+}
+
+// IterationStartKey computes the key from which iteration must begin
+// for the given prefix and start key. Any start key falling before the
+// given prefix is ignored.
+func IterationStartKey(keyPrefix, startKey []byte) []byte {
+	if bytes.Compare(startKey, keyPrefix) > 0 {
+		return startKey
+	}
+	return keyPrefix
+}
+
 // A Backupable represents the capability of the underlying store
 // to be backed up and restored using filesystem as the medium.
 type Backupable interface {
@@ -45,6 +390,45 @@ type Backupable interface {
 	// Note that it is upto the implementation to interpret the
 	// provided path as a file or a folder.
 	RestoreFrom(path string) error
+	// VerifyBackup verifies that the given `path` holds a complete and
+	// uncorrupted backup that can be restored by this implementation,
+	// without modifying the underlying store, and describes it. Note
+	// that RestoreFrom performs the same verification before replacing
+	// the state of the underlying store.
+	VerifyBackup(path string) (*BackupInfo, error)
+}
+
+// A ParallelRestorer represents the capability of the underlying store
+// to ingest its backups using many workers at once, while tracking the
+// progress of its restores.
+type ParallelRestorer interface {
+	// RestoreFromParallel is same as Backupable.RestoreFrom except that
+	// the backup is ingested using upto the given number of workers,
+	// recording the progress onto the given RestoreProgress.
+	RestoreFromParallel(path string, parallelism int, progress *RestoreProgress) error
+}
+
+// RestoreFrom restores the given Backupable from the backup at the given
+// path through ParallelRestorer.RestoreFromParallel if it is one. Other
+// Backupables are restored through RestoreFrom, without any progress.
+func RestoreFrom(br Backupable, path string, parallelism int, progress *RestoreProgress) error {
+	if pr, ok := br.(ParallelRestorer); ok {
+		return pr.RestoreFromParallel(path, parallelism, progress)
+	}
+	return br.RestoreFrom(path)
+}
+
+// BackupInfo describes a backup verified by a Backupable.
+type BackupInfo struct {
+	// Engine is the storage engine that took the backup.
+	Engine string
+	// ChangeNumber is the change number of the store when it was
+	// backed up. Changes committed after this may also be captured.
+	ChangeNumber uint64
+	// NumberOfKeys is the number of keys in the backup, if known.
+	NumberOfKeys uint64
+	// Size is the size of the backup on the filesystem.
+	Size uint64
 }
 
 // A ChangePropagator represents the capability of the underlying
@@ -58,10 +442,16 @@ type ChangePropagator interface {
 	GetLatestCommittedChangeNumber() (uint64, error)
 	// LoadChanges retrieves all the changes committed since the given
 	// `fromChangeNumber`. Also, `maxChanges` can be used to limit the
-	// number of changes returned in the response.
+	// number of changes returned in the response. Returns the error
+	// ErrChangesUnavailable if these changes are no longer retained.
 	LoadChanges(fromChangeNumber uint64, maxChanges int) ([]*serverpb.ChangeRecord, error)
 }
 
+// ErrChangesUnavailable indicates that the requested changes are no
+// longer retained by the ChangePropagator, typically due to them having
+// been compacted away.
+var ErrChangesUnavailable = dkverrors.ErrChangesUnavailable
+
 // A ChangeApplier represents the capability of the underlying store
 // to apply changes directly onto its key space. This is typically
 // used for replication purposes to indicate that the implementor
@@ -79,6 +469,54 @@ type ChangeApplier interface {
 	// changes if any must NOT be applied in order to ensure sequential
 	// consistency.
 	SaveChanges(changes []*serverpb.ChangeRecord) (uint64, error)
+	// SaveChangeBatch is same as SaveChanges except that the given
+	// changes are committed together with the change number of the
+	// last change in a single atomic write. Implementors may split a
+	// batch too large for a single write at change boundaries, as long
+	// as every split is committed in the same manner. This ensures the
+	// latest applied change number is never ahead of the committed
+	// changes, even if the process crashes midway.
+	SaveChangeBatch(changes []*serverpb.ChangeRecord) (uint64, error)
+	// SetLatestAppliedChangeNumber records the given change number as
+	// that of the latest applied change. This is typically used after
+	// the local key space is wholly replaced with a checkpoint of the
+	// master node, so that the changes following this checkpoint can
+	// be applied subsequently.
+	SetLatestAppliedChangeNumber(changeNumber uint64) error
+}
+
+// A ReplicationStateKeeper represents the capability of the underlying
+// store to record the state of the replication of a slave node along
+// with the changes it applies, such as the master node they are applied
+// from, so that this state survives the restarts of the slave node.
+type ReplicationStateKeeper interface {
+	// SaveChangeBatchWithState is same as SaveChangeBatch except that
+	// the given opaque state is recorded along with the changes. Unless
+	// the state is nil, it replaces the state recorded earlier.
+	SaveChangeBatchWithState(changes []*serverpb.ChangeRecord, state []byte) (uint64, error)
+	// LoadReplicationState loads the state recorded by the latest batch
+	// of changes, which is nil if none is recorded yet.
+	LoadReplicationState() ([]byte, error)
+}
+
+// SaveChangeBatchWithState saves the given changes through the given
+// change applier along with the given state, if it is a ReplicationStateKeeper.
+// Other change appliers save the changes through SaveChangeBatch, leaving
+// out the state.
+func SaveChangeBatchWithState(ca ChangeApplier, changes []*serverpb.ChangeRecord, state []byte) (uint64, error) {
+	if rsk, ok := ca.(ReplicationStateKeeper); ok {
+		return rsk.SaveChangeBatchWithState(changes, state)
+	}
+	return ca.SaveChangeBatch(changes)
+}
+
+// LoadReplicationState loads the state recorded by the given change
+// applier if it is a ReplicationStateKeeper, and is nil otherwise.
+func LoadReplicationState(ca ChangeApplier) ([]byte, error) {
+	if rsk, ok := ca.(ReplicationStateKeeper); ok {
+		return rsk.LoadReplicationState()
+	}
+	return nil, nil
 }
 
 // TODO: Following functions should be moved to a util layer ?
@@ -107,11 +545,49 @@ func CreateTempFolder(prefix string) (string, error) {
 	return ioutil.TempDir("", string(tempFolderPrefix))
 }
 
-// RenameFolder moves the given src path onto the given dst path by
-// first removing the dst path and then performing the actual movement.
-func RenameFolder(src, dst string) error {
-	if err := os.RemoveAll(dst); err != nil {
-		return err
+// CreateSiblingFolder creates a temporary folder with the given prefix
+// alongside the given path, i.e., on the same filesystem, so that it
+// can later be moved onto this path using SwapFolder.
+func CreateSiblingFolder(path, prefix string) (string, error) {
+	tempFolderPrefix := time.Now().AppendFormat([]byte(prefix), timeFormatTempPath)
+	return ioutil.TempDir(filepath.Dir(filepath.Clean(path)), string(tempFolderPrefix))
+}
+
+// Suffix of the folder retained by SwapFolder until the swap succeeds.
+const prevFolderSuffix = ".prev"
+
+// SwapFolder moves the given src folder onto the given dst folder,
+// retaining the latter until the given function, which is meant to
+// open the store in the dst folder, succeeds. Should either the move
+// or this function fail, the previous dst folder is moved back before
+// invoking the function once again. Hence the function is invoked at
+// least once irrespective of the outcome of the swap.
+func SwapFolder(src, dst string, open func() error) error {
+	prev := dst + prevFolderSuffix
+	if err := os.RemoveAll(prev); err != nil {
+		return firstError(err, open())
+	}
+	if err := os.Rename(dst, prev); err != nil {
+		return firstError(err, open())
+	}
+	err := os.Rename(src, dst)
+	if err == nil {
+		if err = open(); err == nil {
+			return os.RemoveAll(prev)
+		}
+		os.RemoveAll(dst)
+	}
+	if rbErr := os.Rename(prev, dst); rbErr != nil {
+		return fmt.Errorf("%v, and unable to move back %s: %v", err, prev, rbErr)
+	}
+	return firstError(err, open())
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
 	}
-	return os.Rename(src, dst)
+	return nil
 }
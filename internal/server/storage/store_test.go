@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+// sliceIter iterates over the given keys, recording whether it is closed
+type sliceIter struct {
+	keys   []string
+	closed bool
+}
+
+func (si *sliceIter) HasNext() bool { return len(si.keys) > 0 }
+
+func (si *sliceIter) Next() ([]byte, []byte) {
+	key := si.keys[0]
+	si.keys = si.keys[1:]
+	return []byte(key), nil
+}
+
+func (si *sliceIter) Err() error       { return nil }
+func (si *sliceIter) ExpireTS() uint64 { return 0 }
+
+func (si *sliceIter) Close() error {
+	si.closed = true
+	return nil
+}
+
+func TestContextIterator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	iter := &sliceIter{keys: []string{"K1", "K2", "K3"}}
+	ctxIter := NewContextIterator(ctx, iter)
+	if !ctxIter.HasNext() {
+		t.Fatal("Expected entries to iterate")
+	}
+	if key, _ := ctxIter.Next(); string(key) != "K1" {
+		t.Errorf("Incorrect key. Expected: K1, Actual: %s", key)
+	}
+	cancel()
+	if ctxIter.HasNext() {
+		t.Error("Expected the iteration to end upon cancellation")
+	}
+	if err := ctxIter.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Incorrect error. Expected: %v, Actual: %v", context.Canceled, err)
+	}
+	ctxIter.Close()
+	if !iter.closed {
+		t.Error("Expected the underlying iterator to be closed")
+	}
+}
+
+// ctxlessStore is a KVStore that can not abandon its reads
+type ctxlessStore struct {
+	KVStore
+	reads int
+}
+
+func (cs *ctxlessStore) Get(keys ...[]byte) ([][]byte, []bool, error) {
+	cs.reads++
+	return make([][]byte, len(keys)), make([]bool, len(keys)), nil
+}
+
+func TestGetWithContext(t *testing.T) {
+	kvs := &ctxlessStore{}
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, found, err := GetWithContext(ctx, kvs, []byte("K1"), []byte("K2")); err != nil || len(found) != 2 {
+		t.Errorf("Unable to GET with context. Found: %v, Error: %v", found, err)
+	}
+	cancel()
+	if _, _, err := GetWithContext(ctx, kvs, []byte("K1")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Incorrect error. Expected: %v, Actual: %v", context.Canceled, err)
+	}
+	if kvs.reads != 1 {
+		t.Errorf("Expected the store to be read only before cancellation. Reads: %d", kvs.reads)
+	}
+}
+
+// corruptStore fails the reads of the keys prefixed with "bad"
+type corruptStore struct {
+	KVStore
+}
+
+func (cs *corruptStore) Get(keys ...[]byte) ([][]byte, []bool, error) {
+	vals, found := make([][]byte, len(keys)), make([]bool, len(keys))
+	for i, key := range keys {
+		if bytes.HasPrefix(key, []byte("bad")) {
+			return nil, nil, errors.New("corrupt block")
+		}
+		vals[i], found[i] = append([]byte("V"), key...), true
+	}
+	return vals, found, nil
+}
+
+func TestGetEach(t *testing.T) {
+	kvs := &corruptStore{}
+	keys := [][]byte{[]byte("K1"), []byte("bad1"), []byte("K2"), []byte("bad2")}
+	vals, found, errs, err := GetEach(context.Background(), kvs, keys...)
+	if err != nil {
+		t.Fatalf("Unable to GET each key. Error: %v", err)
+	}
+	for i, key := range keys {
+		if bytes.HasPrefix(key, []byte("bad")) {
+			if errs[i] == nil || found[i] {
+				t.Errorf("Expected the read of %s to fail. Found: %v", key, found[i])
+			}
+		} else if errs[i] != nil || !found[i] || string(vals[i]) != "V"+string(key) {
+			t.Errorf("Expected %s to be read. Value: %s, Error: %v", key, vals[i], errs[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, _, err := GetEach(ctx, kvs, keys...); !errors.Is(err, context.Canceled) {
+		t.Errorf("Incorrect error. Expected: %v, Actual: %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// Keys deleted softly are retained under tombstones, which are kept
+// under the metadata keys of the keyspace beginning with the following
+// prefix, followed by the deleted keys as stored. Like the quotas, the
+// tombstones are hidden from every namespace, yet replicated and backed
+// up along with the keys. Every tombstone expires once its retention
+// window passes, after which the storage engines purge it along with
+// the other expired keys.
+const tombstoneKeyPrefix = "tombstone::"
+
+func tombstoneKey(key []byte) []byte {
+	return append(metaKey(tombstoneKeyPrefix), key...)
+}
+
+// Values of the tombstones begin with the 8 byte deletion time of the
+// key, in unix milliseconds, and its 8 byte expiry time, followed by
+// the value of the key as stored.
+const tombstoneHeaderSize = 8 + 8
+
+// ErrKeyExists indicates that the key being restored from its tombstone
+// is present once again.
+var ErrKeyExists = dkverrors.ErrKeyExists
+
+// Number of times a soft delete or an undelete is attempted before
+// giving up on the concurrent mutations of its key.
+const maxTombstoneAttempts = 3
+
+// errTombstoneConflict indicates that the key kept being mutated
+// concurrently with its soft delete or its undelete.
+var errTombstoneConflict = errors.New("key was mutated concurrently, retry")
+
+// SoftDelete deletes the given key, as stored in the given store, while
+// retaining its value under a tombstone for the given retention window,
+// so that it can be restored through Undelete until then. The deletion
+// and the tombstone are applied as a single transaction through the
+// given function, such as Txn of the store, which is conditioned on the
+// value read for the key. Returns whether the key was present, since
+// absent keys are left to be deleted outright.
+func SoftDelete(kvs KVStore, key []byte, retention time.Duration, apply func(*serverpb.TxnRequest) (bool, error)) (bool, error) {
+	for i := 0; i < maxTombstoneAttempts; i++ {
+		val, expireTS, found, err := getEntry(kvs, key)
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			return false, nil
+		}
+		// Tombstones of the keys expiring earlier need not outlive them
+		delTime := time.Now()
+		tombExpireTS := uint64(delTime.Add(retention + time.Second - 1).Unix())
+		if expireTS > 0 && expireTS < tombExpireTS {
+			tombExpireTS = expireTS
+		}
+		txnReq := &serverpb.TxnRequest{
+			Conditions: []*serverpb.TxnCondition{{Type: serverpb.TxnCondition_Equals, Key: key, Value: val}},
+			ThenMutations: []*serverpb.TrxnRecord{
+				{Type: serverpb.TrxnRecord_Put, Key: tombstoneKey(key), Value: tombstoneValue(val, expireTS, delTime), ExpireTS: tombExpireTS},
+				{Type: serverpb.TrxnRecord_Delete, Key: key},
+			},
+		}
+		if succeeded, err := apply(txnReq); err != nil || succeeded {
+			return succeeded, err
+		}
+	}
+	return false, errTombstoneConflict
+}
+
+// Undelete restores the given key, as stored in the given store, from
+// its tombstone along with its expiry time, and removes the tombstone.
+// As with SoftDelete, these are applied as a single transaction through
+// the given function, which is conditioned on the tombstone and on the
+// absence of the key. Returns the restored value as stored. Fails with
+// ErrKeyNotFound if the key has no tombstone, such as when it was not
+// deleted softly or its retention window passed, and with ErrKeyExists
+// if the key is present.
+func Undelete(kvs KVStore, key []byte, apply func(*serverpb.TxnRequest) (bool, error)) ([]byte, error) {
+	tombKey := tombstoneKey(key)
+	for i := 0; i < maxTombstoneAttempts; i++ {
+		vals, found, err := kvs.Get(tombKey, key)
+		switch {
+		case err != nil:
+			return nil, err
+		case found[1]:
+			return nil, fmt.Errorf("key deleted softly is present once again: %w", ErrKeyExists)
+		case !found[0]:
+			return nil, fmt.Errorf("key has no tombstone within its retention window: %w", dkverrors.ErrKeyNotFound)
+		}
+		val, expireTS, err := parseTombstone(vals[0])
+		if err != nil {
+			return nil, err
+		}
+		if IsExpired(expireTS) {
+			return nil, fmt.Errorf("key deleted softly has expired since: %w", dkverrors.ErrKeyNotFound)
+		}
+		txnReq := &serverpb.TxnRequest{
+			Conditions: []*serverpb.TxnCondition{
+				{Type: serverpb.TxnCondition_Equals, Key: tombKey, Value: vals[0]},
+				{Type: serverpb.TxnCondition_Absent, Key: key},
+			},
+			ThenMutations: []*serverpb.TrxnRecord{
+				{Type: serverpb.TrxnRecord_Put, Key: key, Value: val, ExpireTS: expireTS},
+				{Type: serverpb.TrxnRecord_Delete, Key: tombKey},
+			},
+		}
+		if succeeded, err := apply(txnReq); err != nil || succeeded {
+			return val, err
+		}
+	}
+	return nil, errTombstoneConflict
+}
+
+// getEntry reads the given key along with its expiry time, which only
+// iterators report.
+func getEntry(kvs KVStore, key []byte) ([]byte, uint64, bool, error) {
+	iter := kvs.Iterate(key, nil)
+	defer iter.Close()
+	if !iter.HasNext() {
+		return nil, 0, false, iter.Err()
+	}
+	// Keys extending the given key sort after it
+	itKey, val := iter.Next()
+	if !bytes.Equal(itKey, key) {
+		return nil, 0, false, nil
+	}
+	return val, iter.ExpireTS(), true, nil
+}
+
+func tombstoneValue(value []byte, expireTS uint64, delTime time.Time) []byte {
+	tombVal := make([]byte, tombstoneHeaderSize+len(value))
+	binary.BigEndian.PutUint64(tombVal, uint64(delTime.UnixNano()/int64(time.Millisecond)))
+	binary.BigEndian.PutUint64(tombVal[8:], expireTS)
+	copy(tombVal[tombstoneHeaderSize:], value)
+	return tombVal
+}
+
+// parseTombstone returns the value of the deleted key as stored along
+// with its expiry time.
+func parseTombstone(tombVal []byte) ([]byte, uint64, error) {
+	if len(tombVal) < tombstoneHeaderSize {
+		return nil, 0, fmt.Errorf("tombstone of %d bytes is corrupted", len(tombVal))
+	}
+	return tombVal[tombstoneHeaderSize:], binary.BigEndian.Uint64(tombVal[8:]), nil
+}
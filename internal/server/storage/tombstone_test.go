@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+func (ms *mapStore) Txn(txnReq *serverpb.TxnRequest) (bool, error) {
+	vals, found, _ := ms.Get(TxnConditionKeys(txnReq)...)
+	succeeded := TxnConditionsHold(txnReq, vals, found)
+	for _, mut := range TxnMutations(txnReq, succeeded) {
+		if mut.Type == serverpb.TrxnRecord_Delete {
+			delete(ms.kvs, string(mut.Key))
+		} else {
+			ms.kvs[string(mut.Key)] = mut.Value
+		}
+	}
+	return succeeded, nil
+}
+
+func TestSoftDelete(t *testing.T) {
+	kvs := &mapStore{kvs: map[string][]byte{"K1": []byte("V1")}}
+	if present, err := SoftDelete(kvs, []byte("K2"), time.Hour, kvs.Txn); err != nil || present {
+		t.Errorf("Expected the absent key to be left alone. Present: %v, Error: %v", present, err)
+	}
+	if present, err := SoftDelete(kvs, []byte("K1"), time.Hour, kvs.Txn); err != nil || !present {
+		t.Fatalf("Expected the key to be deleted softly. Present: %v, Error: %v", present, err)
+	}
+	if _, present := kvs.kvs["K1"]; present {
+		t.Error("Expected the key deleted softly to be absent")
+	}
+	// Tombstones are hidden from every namespace
+	for key := range kvs.kvs {
+		if _, present := InNamespace("", []byte(key)); present {
+			t.Errorf("Expected the tombstone under key %q to be hidden from the default namespace", key)
+		}
+	}
+
+	kvs.kvs["K1"] = []byte("V2")
+	if _, err := Undelete(kvs, []byte("K1"), kvs.Txn); !errors.Is(err, ErrKeyExists) {
+		t.Errorf("Expected the present key not to be restored. Error: %v", err)
+	}
+	delete(kvs.kvs, "K1")
+	val, err := Undelete(kvs, []byte("K1"), kvs.Txn)
+	if err != nil || !bytes.Equal(val, []byte("V1")) {
+		t.Fatalf("Expected the key to be restored. Value: %q, Error: %v", val, err)
+	}
+	if val := kvs.kvs["K1"]; !bytes.Equal(val, []byte("V1")) {
+		t.Errorf("Expected the restored value V1. Actual: %q", val)
+	}
+	if len(kvs.kvs) != 1 {
+		t.Errorf("Expected the tombstone to be removed once restored. Keys: %d", len(kvs.kvs))
+	}
+	if _, err = Undelete(kvs, []byte("K2"), kvs.Txn); !errors.Is(err, dkverrors.ErrKeyNotFound) {
+		t.Errorf("Expected the key without a tombstone not to be restored. Error: %v", err)
+	}
+}
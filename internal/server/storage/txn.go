@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/flipkart-incubator/dkv/internal/compression"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// ValidateTxn checks that every condition and mutation of the given
+// transaction has a non empty key and a known type, and that the keys
+// and values of these conform to the given size limits.
+func ValidateTxn(txnReq *serverpb.TxnRequest, sizeLimits SizeLimits) error {
+	for i, cond := range txnReq.Conditions {
+		switch {
+		case cond == nil || len(cond.Key) == 0:
+			return fmt.Errorf("Txn condition at index %d has an empty key: %w", i, dkverrors.ErrInvalidArgument)
+		case cond.Type != serverpb.TxnCondition_Equals && cond.Type != serverpb.TxnCondition_Absent:
+			return fmt.Errorf("Txn condition at index %d has an unknown type: %d: %w", i, cond.Type, dkverrors.ErrInvalidArgument)
+		}
+		if err := sizeLimits.Check(cond.Key, nil); err != nil {
+			return fmt.Errorf("Txn condition at index %d: %w", i, err)
+		}
+	}
+	for _, muts := range [][]*serverpb.TrxnRecord{txnReq.ThenMutations, txnReq.ElseMutations} {
+		for i, mut := range muts {
+			switch {
+			case mut == nil || len(mut.Key) == 0:
+				return fmt.Errorf("Txn mutation at index %d has an empty key: %w", i, dkverrors.ErrInvalidArgument)
+			case mut.Type != serverpb.TrxnRecord_Put && mut.Type != serverpb.TrxnRecord_Delete:
+				return fmt.Errorf("Txn mutation at index %d is neither a Put nor a Delete: %w", i, dkverrors.ErrInvalidArgument)
+			}
+			if err := sizeLimits.Check(mut.Key, mut.Value); err != nil {
+				return fmt.Errorf("Txn mutation at index %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// NamespacedTxn returns a copy of the given transaction whose keys are
+// replaced by those they are stored under, as per NamespacedKey, and
+// whose values put are transformed using the given function, such as
+// for compressing them as they are stored. Values of the conditions are
+// retained as they are, since they are matched as per ValuesMatch.
+func NamespacedTxn(txnReq *serverpb.TxnRequest, transform func([]byte) []byte) (*serverpb.TxnRequest, error) {
+	nsTxnReq := &serverpb.TxnRequest{Conditions: make([]*serverpb.TxnCondition, len(txnReq.Conditions))}
+	for i, cond := range txnReq.Conditions {
+		nsKey, err := NamespacedKey(txnReq.Namespace, cond.Key)
+		if err != nil {
+			return nil, err
+		}
+		nsTxnReq.Conditions[i] = &serverpb.TxnCondition{Type: cond.Type, Key: nsKey, Value: cond.Value}
+	}
+	var err error
+	if nsTxnReq.ThenMutations, err = namespacedMutations(txnReq.Namespace, txnReq.ThenMutations, transform); err != nil {
+		return nil, err
+	}
+	if nsTxnReq.ElseMutations, err = namespacedMutations(txnReq.Namespace, txnReq.ElseMutations, transform); err != nil {
+		return nil, err
+	}
+	return nsTxnReq, nil
+}
+
+func namespacedMutations(namespace string, muts []*serverpb.TrxnRecord, transform func([]byte) []byte) ([]*serverpb.TrxnRecord, error) {
+	nsMuts := make([]*serverpb.TrxnRecord, len(muts))
+	for i, mut := range muts {
+		nsKey, err := NamespacedKey(namespace, mut.Key)
+		if err != nil {
+			return nil, err
+		}
+		nsMuts[i] = &serverpb.TrxnRecord{Type: mut.Type, Key: nsKey, ExpireTS: mut.ExpireTS}
+		if mut.Type == serverpb.TrxnRecord_Put {
+			nsMuts[i].Value = transform(mut.Value)
+		}
+	}
+	return nsMuts, nil
+}
+
+// TxnConditionKeys returns the keys of the conditions of the given
+// transaction, in the order of the conditions.
+func TxnConditionKeys(txnReq *serverpb.TxnRequest) [][]byte {
+	keys := make([][]byte, len(txnReq.Conditions))
+	for i, cond := range txnReq.Conditions {
+		keys[i] = cond.Key
+	}
+	return keys
+}
+
+// TxnKeys returns the keys of all the conditions and mutations of the
+// given transaction, which may repeat.
+func TxnKeys(txnReq *serverpb.TxnRequest) [][]byte {
+	keys := TxnConditionKeys(txnReq)
+	for _, muts := range [][]*serverpb.TrxnRecord{txnReq.ThenMutations, txnReq.ElseMutations} {
+		for _, mut := range muts {
+			keys = append(keys, mut.Key)
+		}
+	}
+	return keys
+}
+
+// TxnConditionsHold checks whether all the conditions of the given
+// transaction hold, given the current values of their keys along with
+// their presence, in the order of the conditions.
+func TxnConditionsHold(txnReq *serverpb.TxnRequest, values [][]byte, found []bool) bool {
+	for i, cond := range txnReq.Conditions {
+		switch cond.Type {
+		case serverpb.TxnCondition_Absent:
+			if found[i] {
+				return false
+			}
+		default:
+			if !found[i] || !ValuesMatch(values[i], cond.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ValuesMatch checks whether the given current value of a key matches the
+// given expected value, which are compared once decompressed. Hence the
+// values compressed at rest match the expected values irrespective of
+// whether the clients compressed them, or of the codec that did.
+func ValuesMatch(currValue, expectedValue []byte) bool {
+	return compression.Equal(currValue, expectedValue)
+}
+
+// TxnMutations returns the mutations of the given transaction that are
+// applied depending on whether all of its conditions hold.
+func TxnMutations(txnReq *serverpb.TxnRequest, succeeded bool) []*serverpb.TrxnRecord {
+	if succeeded {
+		return txnReq.ThenMutations
+	}
+	return txnReq.ElseMutations
+}
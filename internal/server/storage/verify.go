@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// ErrVerificationFailed indicates that the store is unfit to be served,
+// either due to its files being corrupted or due to its change numbers
+// being inconsistent with its keyspace.
+var ErrVerificationFailed = errors.New("storage failed verification")
+
+// An IntegrityVerifier represents the capability of the underlying store
+// to verify the integrity of its files, such as through their checksums.
+type IntegrityVerifier interface {
+	// VerifyIntegrity reads through all the files of the store, failing
+	// on the first corruption found.
+	VerifyIntegrity() error
+}
+
+// VerifyReport describes the store verified by Verify.
+type VerifyReport struct {
+	// NumKeys is the number of keys stored, including the metadata keys
+	// and the keys yet to be purged since they expired.
+	NumKeys uint64
+	// AppliedChangeNumber is the latest change number applied onto the
+	// store, if it is a ChangeApplier.
+	AppliedChangeNumber uint64
+	// CommittedChangeNumber is the latest change number committed onto
+	// the store, if it is a ChangePropagator.
+	CommittedChangeNumber uint64
+}
+
+// Verify checks that the given store can be served, such as upon its
+// startup after an unclean shutdown, so that corruptions are caught
+// before they fail the reads. All the keys of the store are read, which
+// are verified through IntegrityVerifier.VerifyIntegrity if the store is
+// one, after which its change numbers must load. The latest change of a
+// ChangePropagator must load as well, unless it is no longer retained,
+// and carry neither a greater change number nor a mismatching checksum.
+// Fails with ErrVerificationFailed upon any of these checks failing.
+func Verify(ctx context.Context, kvs KVStore) (*VerifyReport, error) {
+	report := &VerifyReport{}
+	var iter Iterator
+	if iv, ok := kvs.(IntegrityVerifier); ok {
+		if err := iv.VerifyIntegrity(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrVerificationFailed, err)
+		}
+		iter = IterateKeys(kvs, nil, nil)
+	} else {
+		iter = kvs.Iterate(nil, nil)
+	}
+	iter = NewContextIterator(ctx, iter)
+	defer iter.Close()
+	for iter.HasNext() {
+		iter.Next()
+		report.NumKeys++
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("%w: unable to read the keys after %d of them: %v", ErrVerificationFailed, report.NumKeys, err)
+	}
+
+	var err error
+	if ca, ok := kvs.(ChangeApplier); ok {
+		if report.AppliedChangeNumber, err = ca.GetLatestAppliedChangeNumber(); err != nil {
+			return nil, fmt.Errorf("%w: unable to load the latest applied change number: %v", ErrVerificationFailed, err)
+		}
+	}
+	if cp, ok := kvs.(ChangePropagator); ok {
+		if report.CommittedChangeNumber, err = cp.GetLatestCommittedChangeNumber(); err != nil {
+			return nil, fmt.Errorf("%w: unable to load the latest committed change number: %v", ErrVerificationFailed, err)
+		}
+		if err = verifyLatestChange(cp, report.CommittedChangeNumber); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrVerificationFailed, err)
+		}
+	}
+	return report, nil
+}
+
+// verifyLatestChange loads the given latest committed change, which may
+// be a part of a change beginning with a lower change number.
+func verifyLatestChange(cp ChangePropagator, latestChngNum uint64) error {
+	if latestChngNum == 0 {
+		return nil
+	}
+	chngs, err := cp.LoadChanges(latestChngNum, 1)
+	switch {
+	case errors.Is(err, ErrChangesUnavailable):
+		return nil
+	case err != nil:
+		return fmt.Errorf("unable to load the latest committed change %d: %v", latestChngNum, err)
+	case len(chngs) == 0:
+		return nil
+	case chngs[0].ChangeNumber > latestChngNum:
+		return fmt.Errorf("change %d is found beyond the latest committed change %d", chngs[0].ChangeNumber, latestChngNum)
+	}
+	return VerifyChecksum(chngs[0])
+}
+
+// Name of the lock file held in the folder of a node while it is served
+const lockFile = "LOCK"
+
+// ErrFolderLocked indicates that the folder of the node is locked by
+// another process, such as another node serving it.
+var ErrFolderLocked = errors.New("folder is locked by another process")
+
+// A FolderLock is an exclusive lock over the folder of a node, which
+// prevents two processes from opening the same store at once. The lock
+// is released by the OS once the process exits, hence a lock left over
+// by a crashed process never blocks its restart.
+type FolderLock struct {
+	file *os.File
+}
+
+// LockFolder locks the given folder exclusively, failing right away with
+// ErrFolderLocked if another process holds the lock. The ID of the locking
+// process is recorded onto the lock file to help identify it.
+func LockFolder(folder string) (*FolderLock, error) {
+	lockPath := filepath.Join(folder, lockFile)
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		defer file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, fmt.Errorf("%w: %s held by process %s", ErrFolderLocked, lockPath, readLockHolder(file))
+		}
+		return nil, err
+	}
+	if err = file.Truncate(0); err == nil {
+		_, err = file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &FolderLock{file}, nil
+}
+
+func readLockHolder(file *os.File) string {
+	buf := make([]byte, 32)
+	if n, _ := file.ReadAt(buf, 0); n > 0 {
+		return string(buf[:n])
+	}
+	return "unknown"
+}
+
+// Unlock releases the lock over the folder.
+func (fl *FolderLock) Unlock() error {
+	return fl.file.Close()
+}
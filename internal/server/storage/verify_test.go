@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// damagedStore fails the verification of its files with the given error
+type damagedStore struct {
+	*mapStore
+	err error
+}
+
+func (ds *damagedStore) VerifyIntegrity() error { return ds.err }
+
+// changeLogStore reports the given changes as committed onto it
+type changeLogStore struct {
+	*mapStore
+	chngs []*serverpb.ChangeRecord
+}
+
+func (cls *changeLogStore) GetLatestCommittedChangeNumber() (uint64, error) {
+	return uint64(len(cls.chngs)), nil
+}
+
+func (cls *changeLogStore) LoadChanges(fromChangeNumber uint64, maxChanges int) ([]*serverpb.ChangeRecord, error) {
+	return cls.chngs[fromChangeNumber-1:][:maxChanges], nil
+}
+
+func TestVerify(t *testing.T) {
+	kvs := &mapStore{kvs: map[string][]byte{"K1": []byte("V1"), "K2": []byte("V2")}}
+	report, err := Verify(context.Background(), kvs)
+	if err != nil || report.NumKeys != 2 {
+		t.Errorf("Expected the store of 2 keys to be verified. Report: %+v, Error: %v", report, err)
+	}
+	if _, err = Verify(context.Background(), &damagedStore{kvs, errors.New("block checksum mismatch")}); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("Expected the corrupted store to fail verification. Error: %v", err)
+	}
+
+	trxns := []*serverpb.TrxnRecord{{Type: serverpb.TrxnRecord_Put, Key: []byte("K1"), Value: []byte("V1")}}
+	chngs := []*serverpb.ChangeRecord{{ChangeNumber: 1, Trxns: trxns, Checksum: ChangeChecksum(trxns)}}
+	if report, err = Verify(context.Background(), &changeLogStore{kvs, chngs}); err != nil || report.CommittedChangeNumber != 1 {
+		t.Errorf("Expected the store with its latest change to be verified. Report: %+v, Error: %v", report, err)
+	}
+	chngs[0].Checksum++
+	if _, err = Verify(context.Background(), &changeLogStore{kvs, chngs}); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("Expected the store with a corrupted latest change to fail verification. Error: %v", err)
+	}
+	chngs[0].Checksum, chngs[0].ChangeNumber = 0, 2
+	if _, err = Verify(context.Background(), &changeLogStore{kvs, chngs}); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("Expected the store with a change beyond the latest change to fail verification. Error: %v", err)
+	}
+}
+
+func TestLockFolder(t *testing.T) {
+	folder, err := ioutil.TempDir("", "dkv_lock_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+	lock, err := LockFolder(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LockFolder(folder); !errors.Is(err, ErrFolderLocked) {
+		t.Errorf("Expected the locked folder not to be locked again. Error: %v", err)
+	}
+	if err = lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if lock, err = LockFolder(folder); err != nil {
+		t.Fatalf("Expected the unlocked folder to be locked again. Error: %v", err)
+	}
+	lock.Unlock()
+}
@@ -22,12 +22,17 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type InternalRaftRequest struct {
-	Put                  *serverpb.PutRequest      `protobuf:"bytes,10,opt,name=put,proto3" json:"put,omitempty"`
-	Get                  *serverpb.GetRequest      `protobuf:"bytes,11,opt,name=get,proto3" json:"get,omitempty"`
-	MultiGet             *serverpb.MultiGetRequest `protobuf:"bytes,12,opt,name=multi_get,json=multiGet,proto3" json:"multi_get,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
-	XXX_unrecognized     []byte                    `json:"-"`
-	XXX_sizecache        int32                     `json:"-"`
+	Put                  *serverpb.PutRequest           `protobuf:"bytes,10,opt,name=put,proto3" json:"put,omitempty"`
+	Get                  *serverpb.GetRequest           `protobuf:"bytes,11,opt,name=get,proto3" json:"get,omitempty"`
+	MultiGet             *serverpb.MultiGetRequest      `protobuf:"bytes,12,opt,name=multi_get,json=multiGet,proto3" json:"multi_get,omitempty"`
+	Delete               *serverpb.DeleteRequest        `protobuf:"bytes,13,opt,name=delete,proto3" json:"delete,omitempty"`
+	MultiPut             *serverpb.MultiPutRequest      `protobuf:"bytes,14,opt,name=multi_put,json=multiPut,proto3" json:"multi_put,omitempty"`
+	CompareAndSet        *serverpb.CompareAndSetRequest `protobuf:"bytes,15,opt,name=compare_and_set,json=compareAndSet,proto3" json:"compare_and_set,omitempty"`
+	Increment            *serverpb.IncrementRequest     `protobuf:"bytes,16,opt,name=increment,proto3" json:"increment,omitempty"`
+	Txn                  *serverpb.TxnRequest           `protobuf:"bytes,17,opt,name=txn,proto3" json:"txn,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                       `json:"-"`
+	XXX_unrecognized     []byte                         `json:"-"`
+	XXX_sizecache        int32                          `json:"-"`
 }
 
 func (m *InternalRaftRequest) Reset()         { *m = InternalRaftRequest{} }
@@ -76,6 +81,41 @@ func (m *InternalRaftRequest) GetMultiGet() *serverpb.MultiGetRequest {
 	return nil
 }
 
+func (m *InternalRaftRequest) GetDelete() *serverpb.DeleteRequest {
+	if m != nil {
+		return m.Delete
+	}
+	return nil
+}
+
+func (m *InternalRaftRequest) GetMultiPut() *serverpb.MultiPutRequest {
+	if m != nil {
+		return m.MultiPut
+	}
+	return nil
+}
+
+func (m *InternalRaftRequest) GetCompareAndSet() *serverpb.CompareAndSetRequest {
+	if m != nil {
+		return m.CompareAndSet
+	}
+	return nil
+}
+
+func (m *InternalRaftRequest) GetIncrement() *serverpb.IncrementRequest {
+	if m != nil {
+		return m.Increment
+	}
+	return nil
+}
+
+func (m *InternalRaftRequest) GetTxn() *serverpb.TxnRequest {
+	if m != nil {
+		return m.Txn
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*InternalRaftRequest)(nil), "dkv.raftpb.InternalRaftRequest")
 }
@@ -85,19 +125,26 @@ func init() {
 }
 
 var fileDescriptor_768e96fdb9339086 = []byte{
-	// 222 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0xd0, 0xc1, 0x4a, 0xc4, 0x30,
-	0x10, 0x80, 0x61, 0x16, 0x41, 0x34, 0xeb, 0xa9, 0x82, 0x14, 0x41, 0x10, 0x41, 0x10, 0xc1, 0x0c,
-	0xe8, 0x4d, 0x10, 0xc1, 0x8b, 0x78, 0x10, 0xa4, 0x47, 0x2f, 0x92, 0x74, 0xa7, 0x35, 0xa4, 0x4d,
-	0xc6, 0xe9, 0xa4, 0xe0, 0x23, 0xf9, 0x96, 0x92, 0xdd, 0x16, 0x15, 0x64, 0xaf, 0x93, 0xef, 0x1f,
-	0xc8, 0xa8, 0x73, 0x17, 0x04, 0x39, 0x98, 0x0e, 0x06, 0xe4, 0x11, 0x19, 0x86, 0xcf, 0x50, 0x03,
-	0x9b, 0x46, 0xc8, 0x02, 0x53, 0xad, 0x89, 0xa3, 0xc4, 0x42, 0xad, 0xfc, 0xa8, 0x37, 0xd3, 0xe3,
-	0x23, 0xf2, 0xed, 0xa4, 0xc9, 0x82, 0x21, 0xb7, 0x31, 0x67, 0x5f, 0x0b, 0x75, 0xf8, 0x34, 0x6d,
-	0xab, 0x4c, 0x23, 0x15, 0x7e, 0x24, 0x1c, 0xa4, 0xb8, 0x54, 0x3b, 0x94, 0xa4, 0x54, 0xa7, 0x8b,
-	0x8b, 0xe5, 0x75, 0xa9, 0xf3, 0xa6, 0xb9, 0xd6, 0x2f, 0x69, 0x66, 0x55, 0x46, 0xd9, 0xb6, 0x28,
-	0xe5, 0xf2, 0x3f, 0xfb, 0x88, 0x3f, 0xb6, 0x45, 0x29, 0x6e, 0xd5, 0x7e, 0x9f, 0x3a, 0x71, 0x6f,
-	0xb9, 0x38, 0x58, 0x17, 0x27, 0x7f, 0x8b, 0xe7, 0xfc, 0xfc, 0x2b, 0xdb, 0xeb, 0xa7, 0xc1, 0xc3,
-	0xfd, 0xeb, 0x5d, 0xeb, 0xe4, 0x3d, 0x59, 0x5d, 0xc7, 0x1e, 0x9a, 0xce, 0x91, 0x37, 0x2c, 0x57,
-	0x2e, 0xd4, 0xc9, 0x1a, 0x89, 0x0c, 0x2b, 0x3f, 0xc2, 0x96, 0xd3, 0xd8, 0xdd, 0xf5, 0x9f, 0x6f,
-	0xbe, 0x03, 0x00, 0x00, 0xff, 0xff, 0x23, 0x77, 0x63, 0x2c, 0x40, 0x01, 0x00, 0x00,
+	// 326 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x92, 0x5f, 0x4b, 0xf3, 0x30,
+	0x14, 0x87, 0x79, 0x19, 0x8c, 0x77, 0x99, 0x73, 0x5a, 0x41, 0x82, 0xa2, 0xc8, 0x40, 0x10, 0xc1,
+	0x06, 0xdc, 0x9d, 0x28, 0xe2, 0x1f, 0x90, 0x09, 0xc2, 0x98, 0x5e, 0x79, 0x33, 0xd2, 0xf4, 0xac,
+	0x86, 0xb6, 0x69, 0x4c, 0x4f, 0xc6, 0xfc, 0x96, 0x7e, 0x24, 0x49, 0xd7, 0xda, 0x55, 0xa6, 0xb7,
+	0xe7, 0x3c, 0xcf, 0xef, 0x24, 0x87, 0x43, 0x8e, 0xa5, 0x42, 0x30, 0x8a, 0x27, 0x2c, 0x07, 0x33,
+	0x07, 0xc3, 0xf2, 0x0f, 0x25, 0x98, 0xe1, 0x33, 0xd4, 0x01, 0x33, 0x5a, 0xf8, 0xda, 0x64, 0x98,
+	0x79, 0x24, 0x8c, 0xe7, 0xfe, 0xb2, 0xba, 0xb7, 0xab, 0xe3, 0xa8, 0xa4, 0x75, 0xc0, 0xb8, 0x96,
+	0x4b, 0x66, 0xf0, 0xd9, 0x22, 0x3b, 0xa3, 0x32, 0x6d, 0xc2, 0x67, 0x38, 0x81, 0x77, 0x0b, 0x39,
+	0x7a, 0xa7, 0xa4, 0xa5, 0x2d, 0x52, 0x72, 0xf4, 0xef, 0xa4, 0x7b, 0x4e, 0x7d, 0x97, 0x54, 0xd9,
+	0xfe, 0xd8, 0x56, 0xd8, 0xc4, 0x41, 0x8e, 0x8d, 0x00, 0x69, 0x77, 0x1d, 0xfb, 0x00, 0x35, 0x1b,
+	0x01, 0x7a, 0x17, 0xa4, 0x93, 0xda, 0x04, 0xe5, 0xd4, 0x19, 0x1b, 0x85, 0x71, 0xd0, 0x34, 0x9e,
+	0x5c, 0x7b, 0x45, 0xfb, 0x9f, 0x96, 0x05, 0x6f, 0x48, 0xda, 0x21, 0x24, 0x80, 0x40, 0x7b, 0x85,
+	0xb8, 0xdf, 0x14, 0xef, 0x8b, 0x5e, 0xa5, 0x95, 0x68, 0x3d, 0xd0, 0x7d, 0x67, 0xf3, 0xd7, 0x81,
+	0x63, 0xfb, 0x63, 0xe0, 0xd8, 0xa2, 0xf7, 0x48, 0xfa, 0x22, 0x4b, 0x35, 0x37, 0x30, 0xe5, 0x2a,
+	0x9c, 0xe6, 0x80, 0xb4, 0x5f, 0x24, 0x0c, 0x9a, 0x09, 0x77, 0x4b, 0xe8, 0x46, 0x85, 0xcf, 0xf5,
+	0xbb, 0x7b, 0x62, 0xb5, 0xea, 0x5d, 0x92, 0x8e, 0x54, 0xc2, 0x40, 0x0a, 0x0a, 0xe9, 0x56, 0x91,
+	0x72, 0xd8, 0x4c, 0x19, 0x55, 0xed, 0x2a, 0xa1, 0x16, 0xdc, 0x8a, 0x71, 0xa1, 0xe8, 0xf6, 0xba,
+	0x15, 0xbf, 0x2c, 0xd4, 0xf7, 0x8a, 0x71, 0xa1, 0x6e, 0xaf, 0x5f, 0xaf, 0x22, 0x89, 0x6f, 0x36,
+	0xf0, 0x45, 0x96, 0xb2, 0x59, 0x22, 0x75, 0xcc, 0x0d, 0x9e, 0x49, 0x25, 0x6c, 0xc0, 0x31, 0x33,
+	0x2c, 0x8c, 0xe7, 0xec, 0x8f, 0x0b, 0x0a, 0xda, 0xc5, 0x69, 0x0c, 0xbf, 0x06, 0x00, 0xa8, 0xa4,
+	0xb2, 0xca, 0x67, 0x02, 0x00, 0x00,
 }
@@ -2,6 +2,7 @@ package sync
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/gob"
 	"errors"
 
@@ -35,18 +36,71 @@ func (dr *dkvReplStore) Save(req []byte) ([]byte, error) {
 		return dr.get(intReq.Get)
 	case intReq.MultiGet != nil:
 		return dr.multiGet(intReq.MultiGet)
+	case intReq.MultiPut != nil:
+		return dr.multiPut(intReq.MultiPut)
+	case intReq.CompareAndSet != nil:
+		return dr.compareAndSet(intReq.CompareAndSet)
+	case intReq.Increment != nil:
+		return dr.increment(intReq.Increment)
+	case intReq.Txn != nil:
+		return dr.txn(intReq.Txn)
+	case intReq.Delete != nil:
+		return dr.delete(intReq.Delete)
 	default:
 		return nil, errors.New("Unknown request to Save in dkv")
 	}
 }
 
 func (dr *dkvReplStore) put(putReq *serverpb.PutRequest) ([]byte, error) {
-	err := dr.kvs.Put(putReq.Key, putReq.Value)
+	// MultiPut also stores the expiry time of the given entry
+	err := dr.kvs.MultiPut(putReq)
+	return nil, err
+}
+
+func (dr *dkvReplStore) multiPut(multiPutReq *serverpb.MultiPutRequest) ([]byte, error) {
+	err := dr.kvs.MultiPut(multiPutReq.PutRequests...)
+	return nil, err
+}
+
+func (dr *dkvReplStore) compareAndSet(casReq *serverpb.CompareAndSetRequest) ([]byte, error) {
+	updated, err := dr.kvs.CompareAndSet(casReq.Key, casReq.ExpectedValue, casReq.NewValue)
+	if err != nil {
+		return nil, err
+	}
+	if updated {
+		return []byte{1}, nil
+	}
+	return []byte{0}, nil
+}
+
+func (dr *dkvReplStore) increment(incReq *serverpb.IncrementRequest) ([]byte, error) {
+	res, err := dr.kvs.Increment(incReq.Key, incReq.Delta)
+	if err != nil {
+		return nil, err
+	}
+	resBts := make([]byte, 8)
+	binary.BigEndian.PutUint64(resBts, uint64(res))
+	return resBts, nil
+}
+
+func (dr *dkvReplStore) txn(txnReq *serverpb.TxnRequest) ([]byte, error) {
+	succeeded, err := dr.kvs.Txn(txnReq)
+	if err != nil {
+		return nil, err
+	}
+	if succeeded {
+		return []byte{1}, nil
+	}
+	return []byte{0}, nil
+}
+
+func (dr *dkvReplStore) delete(delReq *serverpb.DeleteRequest) ([]byte, error) {
+	err := dr.kvs.Delete(delReq.Key)
 	return nil, err
 }
 
 func (dr *dkvReplStore) get(getReq *serverpb.GetRequest) ([]byte, error) {
-	vals, err := dr.kvs.Get(getReq.Key)
+	vals, _, err := dr.kvs.Get(getReq.Key)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +108,7 @@ func (dr *dkvReplStore) get(getReq *serverpb.GetRequest) ([]byte, error) {
 }
 
 func (dr *dkvReplStore) multiGet(multiGetReq *serverpb.MultiGetRequest) ([]byte, error) {
-	vals, err := dr.kvs.Get(multiGetReq.Keys...)
+	vals, _, err := dr.kvs.Get(multiGetReq.Keys...)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +123,13 @@ func gobEncode(data interface{}) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// Close persists the writes buffered by the underlying store before
+// closing it, since the replicator closes its store upon being stopped.
 func (dr *dkvReplStore) Close() error {
+	if err := storage.Sync(dr.kvs); err != nil {
+		dr.kvs.Close()
+		return err
+	}
 	return dr.kvs.Close()
 }
 
@@ -2,10 +2,12 @@ package sync
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/gob"
 	"errors"
 	"testing"
 
+	"github.com/flipkart-incubator/dkv/internal/server/storage"
 	"github.com/flipkart-incubator/dkv/internal/server/sync/raftpb"
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
 	"github.com/flipkart-incubator/nexus/pkg/db"
@@ -25,6 +27,20 @@ func TestDKVReplStoreSave(t *testing.T) {
 	testGet(t, kvs, dkvRepl, []byte("kit"))
 
 	testMultiGet(t, kvs, dkvRepl, []byte("foo"), []byte("hello"), []byte("kit"))
+
+	testMultiPut(t, kvs, dkvRepl, []byte("tic"), []byte("tac"), []byte("ping"), []byte("pong"))
+
+	testCompareAndSet(t, kvs, dkvRepl, []byte("foo"), []byte("bar"), []byte("baz"), true)
+	testCompareAndSet(t, kvs, dkvRepl, []byte("foo"), []byte("bar"), []byte("qux"), false)
+	testCompareAndSet(t, kvs, dkvRepl, []byte("absent"), nil, []byte("present"), true)
+	testCompareAndSet(t, kvs, dkvRepl, []byte("absent"), nil, []byte("again"), false)
+
+	testIncrement(t, dkvRepl, []byte("counter"), 5, 5, nil)
+	testIncrement(t, dkvRepl, []byte("counter"), -7, -2, nil)
+	testIncrement(t, dkvRepl, []byte("foo"), 1, 0, storage.ErrNonNumericValue)
+
+	testDelete(t, kvs, dkvRepl, []byte("kit"))
+	testDelete(t, kvs, dkvRepl, []byte("missing"))
 }
 
 func TestDKVReplStoreClose(t *testing.T) {
@@ -46,7 +62,7 @@ func testPut(t *testing.T, kvs *memStore, dkvRepl db.Store, key, val []byte) {
 		if _, err := dkvRepl.Save(reqBts); err != nil {
 			t.Error(err)
 		} else {
-			if res, err := kvs.Get(key); err != nil {
+			if res, _, err := kvs.Get(key); err != nil {
 				t.Error(err)
 			} else if string(res[0]) != string(val) {
 				t.Errorf("Value mismatch for key: %s. Expected: %s, Actual: %s", key, val, res[0])
@@ -55,6 +71,75 @@ func testPut(t *testing.T, kvs *memStore, dkvRepl db.Store, key, val []byte) {
 	}
 }
 
+func testMultiPut(t *testing.T, kvs *memStore, dkvRepl db.Store, keyVals ...[]byte) {
+	intReq := new(raftpb.InternalRaftRequest)
+	intReq.MultiPut = &serverpb.MultiPutRequest{}
+	for i := 0; i < len(keyVals); i += 2 {
+		putReq := &serverpb.PutRequest{Key: keyVals[i], Value: keyVals[i+1]}
+		intReq.MultiPut.PutRequests = append(intReq.MultiPut.PutRequests, putReq)
+	}
+	if reqBts, err := proto.Marshal(intReq); err != nil {
+		t.Error(err)
+	} else {
+		if _, err := dkvRepl.Save(reqBts); err != nil {
+			t.Error(err)
+		} else {
+			for i := 0; i < len(keyVals); i += 2 {
+				key, val := keyVals[i], keyVals[i+1]
+				if res, _, err := kvs.Get(key); err != nil {
+					t.Error(err)
+				} else if string(res[0]) != string(val) {
+					t.Errorf("Value mismatch for key: %s. Expected: %s, Actual: %s", key, val, res[0])
+				}
+			}
+		}
+	}
+}
+
+func testCompareAndSet(t *testing.T, kvs *memStore, dkvRepl db.Store, key, expVal, newVal []byte, expUpdated bool) {
+	intReq := new(raftpb.InternalRaftRequest)
+	intReq.CompareAndSet = &serverpb.CompareAndSetRequest{Key: key, ExpectedValue: expVal, NewValue: newVal}
+	if reqBts, err := proto.Marshal(intReq); err != nil {
+		t.Error(err)
+	} else {
+		if res, err := dkvRepl.Save(reqBts); err != nil {
+			t.Error(err)
+		} else if updated := res[0] == 1; updated != expUpdated {
+			t.Errorf("CompareAndSet mismatch for key: %s. Expected updated: %t, Actual: %t", key, expUpdated, updated)
+		} else if updated && string(kvs.store[string(key)]) != string(newVal) {
+			t.Errorf("Value mismatch for key: %s. Expected: %s, Actual: %s", key, newVal, kvs.store[string(key)])
+		}
+	}
+}
+
+func testIncrement(t *testing.T, dkvRepl db.Store, key []byte, delta, expValue int64, expErr error) {
+	intReq := new(raftpb.InternalRaftRequest)
+	intReq.Increment = &serverpb.IncrementRequest{Key: key, Delta: delta}
+	if reqBts, err := proto.Marshal(intReq); err != nil {
+		t.Error(err)
+	} else {
+		if res, err := dkvRepl.Save(reqBts); err != expErr {
+			t.Errorf("Increment error mismatch for key: %s. Expected: %v, Actual: %v", key, expErr, err)
+		} else if err == nil && int64(binary.BigEndian.Uint64(res)) != expValue {
+			t.Errorf("Increment mismatch for key: %s. Expected: %d, Actual: %d", key, expValue, int64(binary.BigEndian.Uint64(res)))
+		}
+	}
+}
+
+func testDelete(t *testing.T, kvs *memStore, dkvRepl db.Store, key []byte) {
+	intReq := new(raftpb.InternalRaftRequest)
+	intReq.Delete = &serverpb.DeleteRequest{Key: key}
+	if reqBts, err := proto.Marshal(intReq); err != nil {
+		t.Error(err)
+	} else {
+		if _, err := dkvRepl.Save(reqBts); err != nil {
+			t.Error(err)
+		} else if _, present := kvs.store[string(key)]; present {
+			t.Errorf("Expected key: %s to be deleted but it is still present", key)
+		}
+	}
+}
+
 func testGet(t *testing.T, kvs *memStore, dkvRepl db.Store, key []byte) {
 	intReq := new(raftpb.InternalRaftRequest)
 	intReq.Get = &serverpb.GetRequest{Key: key}
@@ -64,7 +149,7 @@ func testGet(t *testing.T, kvs *memStore, dkvRepl db.Store, key []byte) {
 		if val, err := dkvRepl.Save(reqBts); err != nil {
 			t.Error(err)
 		} else {
-			if kvsVals, err := kvs.Get(key); err != nil {
+			if kvsVals, _, err := kvs.Get(key); err != nil {
 				t.Error(err)
 			} else if string(val) != string(kvsVals[0]) {
 				t.Errorf("Value mismatch for key: %s. Expected: %s, Actual: %s", key, kvsVals[0], val)
@@ -87,7 +172,7 @@ func testMultiGet(t *testing.T, kvs *memStore, dkvRepl db.Store, keys ...[]byte)
 			if err := gob.NewDecoder(buf).Decode(&readResults); err != nil {
 				t.Error(err)
 			} else {
-				if kvsVals, err := kvs.Get(keys...); err != nil {
+				if kvsVals, _, err := kvs.Get(keys...); err != nil {
 					t.Error(err)
 				} else {
 					for i, readResult := range readResults {
@@ -118,17 +203,77 @@ func (ms *memStore) Put(key []byte, value []byte) error {
 	return nil
 }
 
-func (ms *memStore) Get(keys ...[]byte) ([][]byte, error) {
-	rss := make([][]byte, len(keys))
-	for i, key := range keys {
-		storeKey := string(key)
-		if val, present := ms.store[storeKey]; present {
-			rss[i] = val
+func (ms *memStore) MultiPut(puts ...*serverpb.PutRequest) error {
+	for _, put := range puts {
+		if err := ms.Put(put.Key, put.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ms *memStore) CompareAndSet(key, expectedValue, newValue []byte) (bool, error) {
+	storeKey := string(key)
+	currValue, present := ms.store[storeKey]
+	if len(expectedValue) == 0 && present {
+		return false, nil
+	}
+	if len(expectedValue) != 0 && (!present || !bytes.Equal(currValue, expectedValue)) {
+		return false, nil
+	}
+	ms.store[storeKey] = newValue
+	return true, nil
+}
+
+func (ms *memStore) Increment(key []byte, delta int64) (int64, error) {
+	storeKey := string(key)
+	res, resValue, err := storage.IncrementValue(ms.store[storeKey], delta)
+	if err != nil {
+		return 0, err
+	}
+	ms.store[storeKey] = resValue
+	return res, nil
+}
+
+func (ms *memStore) Txn(txnReq *serverpb.TxnRequest) (bool, error) {
+	keys := storage.TxnConditionKeys(txnReq)
+	values, found, _ := ms.Get(keys...)
+	succeeded := storage.TxnConditionsHold(txnReq, values, found)
+	for _, mut := range storage.TxnMutations(txnReq, succeeded) {
+		if mut.Type == serverpb.TrxnRecord_Delete {
+			delete(ms.store, string(mut.Key))
 		} else {
-			return nil, errors.New("Given key not found")
+			ms.store[string(mut.Key)] = mut.Value
 		}
 	}
-	return rss, nil
+	return succeeded, nil
+}
+
+func (ms *memStore) Exists(keys ...[]byte) ([]bool, error) {
+	results := make([]bool, len(keys))
+	for i, key := range keys {
+		_, results[i] = ms.store[string(key)]
+	}
+	return results, nil
+}
+
+func (ms *memStore) Iterate(keyPrefix, startKey []byte) storage.Iterator {
+	return nil
+}
+
+func (ms *memStore) Delete(keys ...[]byte) error {
+	for _, key := range keys {
+		delete(ms.store, string(key))
+	}
+	return nil
+}
+
+func (ms *memStore) Get(keys ...[]byte) ([][]byte, []bool, error) {
+	rss, found := make([][]byte, len(keys)), make([]bool, len(keys))
+	for i, key := range keys {
+		rss[i], found[i] = ms.store[string(key)]
+	}
+	return rss, found, nil
 }
 
 func (ms *memStore) Close() error {
@@ -0,0 +1,230 @@
+// Package tracing traces the calls served by DKV nodes, along with those
+// made by their clients, as OpenTelemetry spans. GRPC methods are traced
+// through interceptors that propagate the trace context across the nodes
+// within the GRPC metadata, while the calls onto the storage engine are
+// traced as child spans of their methods. Spans are recorded only once a
+// trace provider is set up, either through Setup as per the flags of the
+// DKV nodes, or globally by the applications embedding DKVClient.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/golang/protobuf/proto"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/kv"
+	"go.opentelemetry.io/otel/api/standard"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/trace/stdout"
+	"go.opentelemetry.io/otel/plugin/grpctrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const tracerName = "github.com/flipkart-incubator/dkv"
+
+// Tracer of all the spans, which records them only once a trace
+// provider is set up
+var tracer = global.Tracer(tracerName)
+
+var (
+	methodKey   = kv.Key("rpc.method")
+	keysKey     = kv.Key("dkv.keys")
+	changesKey  = kv.Key("dkv.changes")
+	reqBytesKey = kv.Key("dkv.request_bytes")
+	resBytesKey = kv.Key("dkv.response_bytes")
+	statusKey   = kv.Key("dkv.status_code")
+)
+
+// Exporters of the spans accepted by Setup
+const (
+	NoExporter     = "none"
+	StdoutExporter = "stdout"
+	OTLPExporter   = "otlp"
+)
+
+// Config describes how the spans are sampled and exported.
+type Config struct {
+	// Exporter is one of NoExporter, StdoutExporter and OTLPExporter.
+	Exporter string
+	// Endpoint is the address of the OTLP collector onto which the
+	// spans are exported over GRPC.
+	Endpoint string
+	// SampleRatio is the ratio of the traces sampled, among those begun
+	// by this process. Traces sampled by the callers are always sampled.
+	SampleRatio float64
+	// ServiceName names the process in the exported spans.
+	ServiceName string
+}
+
+// Setup sets up the global trace provider that samples and exports the
+// spans as per the given config, which is a no-op for NoExporter. The
+// returned function flushes the spans yet to be exported, which must be
+// called before the process exits.
+func Setup(conf Config) (func(), error) {
+	var proc sdktrace.SpanProcessor
+	var stop func()
+	switch conf.Exporter {
+	case "", NoExporter:
+		return func() {}, nil
+	case StdoutExporter:
+		exp, err := stdout.NewExporter(stdout.Options{})
+		if err != nil {
+			return nil, err
+		}
+		proc = sdktrace.NewSimpleSpanProcessor(exp)
+		stop = proc.Shutdown
+	case OTLPExporter:
+		exp, err := otlp.NewExporter(otlp.WithInsecure(), otlp.WithAddress(conf.Endpoint))
+		if err != nil {
+			return nil, err
+		}
+		bsp, err := sdktrace.NewBatchSpanProcessor(exp)
+		if err != nil {
+			exp.Stop()
+			return nil, err
+		}
+		proc = bsp
+		stop = func() {
+			bsp.Shutdown()
+			exp.Stop()
+		}
+	default:
+		return nil, fmt.Errorf("unknown trace exporter: %s", conf.Exporter)
+	}
+
+	sdkConf := sdktrace.Config{DefaultSampler: sdktrace.ProbabilitySampler(conf.SampleRatio)}
+	if conf.ServiceName != "" {
+		sdkConf.Resource = resource.New(standard.ServiceNameKey.String(conf.ServiceName))
+	}
+	provider, err := sdktrace.NewProvider(sdktrace.WithConfig(sdkConf))
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	provider.RegisterSpanProcessor(proc)
+	global.SetTraceProvider(provider)
+	return stop, nil
+}
+
+// UnaryServerInterceptor traces every unary GRPC method as a span that
+// continues the trace of its caller, if any, recording the number of
+// keys of the request along with the sizes of the request and response.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	traced := grpctrace.UnaryServerInterceptor(tracer)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return traced(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			res, err := handler(ctx, req)
+			annotate(trace.SpanFromContext(ctx), info.FullMethod, req, res)
+			return res, err
+		})
+	}
+}
+
+// StreamServerInterceptor traces every streaming GRPC method as a span
+// that continues the trace of its caller, if any, covering the whole
+// lifetime of the stream.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return grpctrace.StreamServerInterceptor(tracer)
+}
+
+// UnaryClientInterceptor traces every unary GRPC call as a span that
+// continues the trace of its context, if any, which is propagated onto
+// the DKV node within the GRPC metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	traced := grpctrace.UnaryClientInterceptor(tracer)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return traced(ctx, method, req, reply, cc, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil {
+				annotate(trace.SpanFromContext(ctx), method, req, reply)
+			}
+			return err
+		}, opts...)
+	}
+}
+
+// StreamClientInterceptor traces every streaming GRPC call as a span
+// that continues the trace of its context, if any.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return grpctrace.StreamClientInterceptor(tracer)
+}
+
+// annotate records the attributes of the given call onto its span,
+// marking the span failed if the response has a non-zero status code.
+func annotate(span trace.Span, fullMethod string, req, res interface{}) {
+	if !span.IsRecording() {
+		return
+	}
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	span.SetAttributes(methodKey.String(method), reqBytesKey.Int(size(req)), resBytesKey.Int(size(res)))
+	if numKeys, ok := requestKeys(req); ok {
+		span.SetAttributes(keysKey.Int(numKeys))
+	}
+	var status *serverpb.Status
+	switch res := res.(type) {
+	case *serverpb.Status:
+		status = res
+	case interface{ GetStatus() *serverpb.Status }:
+		status = res.GetStatus()
+	}
+	if code := status.GetCode(); code != 0 {
+		span.SetAttributes(statusKey.Int32(code))
+		span.SetStatus(codes.Unknown, status.GetMessage())
+	}
+}
+
+// requestKeys returns the number of keys of the given request, if any.
+func requestKeys(req interface{}) (int, bool) {
+	switch req := req.(type) {
+	case interface{ GetKeys() [][]byte }:
+		return len(req.GetKeys()), true
+	case interface {
+		GetPutRequests() []*serverpb.PutRequest
+	}:
+		return len(req.GetPutRequests()), true
+	case interface{ GetKey() []byte }:
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
+func size(msg interface{}) int {
+	if pm, ok := msg.(proto.Message); ok {
+		return proto.Size(pm)
+	}
+	return 0
+}
+
+// StartSpan starts a span of the given name as a child of the span of the
+// given context, if any, which must be ended through EndSpan.
+func StartSpan(ctx context.Context, name string, attrs ...kv.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartStorageSpan starts a span around the given operation onto the
+// storage engine, which involves the given number of keys.
+func StartStorageSpan(ctx context.Context, op string, numKeys int) (context.Context, trace.Span) {
+	return StartSpan(ctx, "storage."+op, keysKey.Int(numKeys))
+}
+
+// Changes is the attribute recording the number of changes of a span.
+func Changes(numChngs int) kv.KeyValue {
+	return changesKey.Int(numChngs)
+}
+
+// EndSpan ends the given span, marking it failed with the given error,
+// if any.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Unknown, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/kv"
+	"go.opentelemetry.io/otel/api/kv/value"
+	"go.opentelemetry.io/otel/api/trace"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// spanRecorder records the spans as they end
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*export.SpanData
+}
+
+func (sr *spanRecorder) ExportSpan(ctx context.Context, span *export.SpanData) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.spans = append(sr.spans, span)
+}
+
+// span returns the latest span of the given name and kind
+func (sr *spanRecorder) span(t *testing.T, name string, kind trace.SpanKind) *export.SpanData {
+	t.Helper()
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	for i := len(sr.spans) - 1; i >= 0; i-- {
+		if span := sr.spans[i]; span.Name == name && span.SpanKind == kind {
+			return span
+		}
+	}
+	t.Fatalf("Expected span %s of kind %s to be recorded", name, kind)
+	return nil
+}
+
+var recorder = &spanRecorder{}
+
+func TestMain(m *testing.M) {
+	provider, err := sdktrace.NewProvider(sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}), sdktrace.WithSyncer(recorder))
+	if err != nil {
+		panic(err)
+	}
+	global.SetTraceProvider(provider)
+	os.Exit(m.Run())
+}
+
+func attribute(span *export.SpanData, key kv.Key) (value.Value, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return value.Value{}, false
+}
+
+func TestTracePropagation(t *testing.T) {
+	conn, err := grpc.Dial("localhost:8080", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	const method = "/dkv.serverpb.DKV/MultiGet"
+	req := &serverpb.MultiGetRequest{Keys: [][]byte{[]byte("k1"), []byte("k2"), []byte("k3")}}
+	// Served right away by handing the outgoing metadata onto the server
+	server := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		srvrCtx := metadata.NewIncomingContext(context.Background(), md)
+		res, err := UnaryServerInterceptor()(srvrCtx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			_, span := StartStorageSpan(ctx, "MultiGet", 3)
+			EndSpan(span, nil)
+			return &serverpb.MultiGetResponse{Status: &serverpb.Status{}, Values: [][]byte{[]byte("v1"), nil, nil}}, nil
+		})
+		*reply.(*serverpb.MultiGetResponse) = *res.(*serverpb.MultiGetResponse)
+		return err
+	}
+
+	ctx, root := StartSpan(context.Background(), "caller")
+	err = UnaryClientInterceptor()(ctx, method, req, &serverpb.MultiGetResponse{}, conn, server)
+	EndSpan(root, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callerSpan := recorder.span(t, "caller", trace.SpanKindInternal)
+	clientSpan, srvrSpan := recorder.span(t, method, trace.SpanKindClient), recorder.span(t, method, trace.SpanKindServer)
+	storSpan := recorder.span(t, "storage.MultiGet", trace.SpanKindInternal)
+	for i, span := range []*export.SpanData{callerSpan, clientSpan, srvrSpan, storSpan} {
+		if span.SpanContext.TraceID != callerSpan.SpanContext.TraceID {
+			t.Errorf("Expected the trace of the caller to be propagated through to the storage. Span: %s", span.Name)
+		}
+		if i > 0 && span.ParentSpanID != []*export.SpanData{callerSpan, clientSpan, srvrSpan}[i-1].SpanContext.SpanID {
+			t.Errorf("Expected span %s of kind %s to be the child of the preceding span", span.Name, span.SpanKind)
+		}
+	}
+	if !srvrSpan.HasRemoteParent {
+		t.Error("Expected the server span to continue the remote trace of the client")
+	}
+	if numKeys, _ := attribute(storSpan, keysKey); numKeys.AsInt64() != 3 {
+		t.Errorf("Expected the storage span to record 3 keys. Actual: %v", numKeys.AsInt64())
+	}
+	for _, attr := range []kv.Key{keysKey, reqBytesKey, resBytesKey} {
+		if val, present := attribute(clientSpan, attr); !present || val.AsInt64() <= 0 {
+			t.Errorf("Expected the client span to record %s. Actual: %v", attr, val.AsInt64())
+		}
+	}
+	if op, _ := attribute(clientSpan, methodKey); op.AsString() != "MultiGet" {
+		t.Errorf("Expected the client span to record the MultiGet method. Actual: %s", op.AsString())
+	}
+}
+
+func TestErrorStatusMarksSpanFailed(t *testing.T) {
+	const method = "/dkv.serverpb.DKV/Put"
+	_, err := UnaryServerInterceptor()(context.Background(), &serverpb.PutRequest{Key: []byte("k")}, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &serverpb.PutResponse{Status: &serverpb.Status{Code: int32(serverpb.StatusCode_ValueTooLarge), Message: "too large"}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	span := recorder.span(t, method, trace.SpanKindServer)
+	if code, _ := attribute(span, statusKey); code.AsInt32() != int32(serverpb.StatusCode_ValueTooLarge) || span.StatusMessage != "too large" {
+		t.Errorf("Expected the span to record the failed status. Actual: %v, %s", code.AsInt32(), span.StatusMessage)
+	}
+	if numKeys, _ := attribute(span, keysKey); numKeys.AsInt64() != 1 {
+		t.Errorf("Expected the span to record a single key. Actual: %v", numKeys.AsInt64())
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(Config{Exporter: "zipkin"}); err == nil {
+		t.Error("Expected the unknown exporter to be rejected")
+	}
+	if stop, err := Setup(Config{Exporter: NoExporter}); err != nil {
+		t.Errorf("Expected tracing to be disabled. Error: %v", err)
+	} else {
+		stop()
+	}
+}
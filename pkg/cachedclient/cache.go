@@ -0,0 +1,110 @@
+package cachedclient
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lruCache retains upto the given number of the most recently used
+// entries, each for upto the given TTL since it was added. Additions
+// are tagged with the generation of the cache when their values were
+// read, so that values read before an invalidation are never added.
+type lruCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	entries    map[string]*list.Element
+	order      *list.List
+	gen        uint64
+	evictions  uint64
+}
+
+type cacheEntry struct {
+	key    string
+	value  []byte
+	expiry time.Time
+}
+
+func newLRUCache(maxEntries int, ttl time.Duration) *lruCache {
+	return &lruCache{maxEntries: maxEntries, ttl: ttl, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+func (lc *lruCache) get(key string) ([]byte, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	elem, present := lc.entries[key]
+	if !present {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if lc.ttl > 0 && time.Now().After(entry.expiry) {
+		lc.removeElement(elem)
+		return nil, false
+	}
+	lc.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// generation returns the current generation, which must be obtained
+// before reading the values that are to be added.
+func (lc *lruCache) generation() uint64 {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.gen
+}
+
+// add adds the given entry unless the cache has been invalidated since
+// the given generation, evicting the least recently used entry if full.
+func (lc *lruCache) add(gen uint64, key string, value []byte) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if gen != lc.gen {
+		return
+	}
+	entry := &cacheEntry{key: key, value: value, expiry: time.Now().Add(lc.ttl)}
+	if elem, present := lc.entries[key]; present {
+		elem.Value = entry
+		lc.order.MoveToFront(elem)
+		return
+	}
+	lc.entries[key] = lc.order.PushFront(entry)
+	if lc.order.Len() > lc.maxEntries {
+		lc.removeElement(lc.order.Back())
+		lc.evictions++
+	}
+}
+
+// remove removes the entries of the given keys, if any, while moving
+// the cache onto its next generation.
+func (lc *lruCache) remove(keys ...string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.gen++
+	for _, key := range keys {
+		if elem, present := lc.entries[key]; present {
+			lc.removeElement(elem)
+		}
+	}
+}
+
+// purge removes all the entries, while moving the cache onto its
+// next generation.
+func (lc *lruCache) purge() {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.gen++
+	lc.entries = make(map[string]*list.Element)
+	lc.order.Init()
+}
+
+func (lc *lruCache) removeElement(elem *list.Element) {
+	lc.order.Remove(elem)
+	delete(lc.entries, elem.Value.(*cacheEntry).key)
+}
+
+func (lc *lruCache) stats() (numEntries int, evictions uint64) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.order.Len(), lc.evictions
+}
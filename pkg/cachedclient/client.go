@@ -0,0 +1,312 @@
+// Package cachedclient caches the values read through a DKV client in
+// the memory of the calling process, for read heavy callers that can
+// tolerate reading slightly stale values.
+//
+// Cached values are served until they are evicted, expire or are
+// invalidated. Writes made through the Client invalidate the values of
+// their keys right away, whereas writes made elsewhere are invalidated
+// by watching the changes of the DKV master nodes through WatchChanges.
+// Reads that must not be stale are made through the DKV client returned
+// by Bypass instead.
+package cachedclient
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/flipkart-incubator/dkv/pkg/subscriber"
+)
+
+// Default values used by Client unless overridden
+// through one of the Option instances.
+const (
+	DefaultMaxEntries = 10000
+	DefaultTTL        = time.Minute
+)
+
+type opts struct {
+	maxEntries int
+	ttl        time.Duration
+}
+
+// An Option is used to customize a specific aspect of a Client.
+type Option func(*opts)
+
+// WithMaxEntries sets the maximum number of values cached, beyond
+// which the least recently used values are evicted.
+func WithMaxEntries(maxEntries int) Option {
+	return func(o *opts) {
+		o.maxEntries = maxEntries
+	}
+}
+
+// WithTTL sets the duration for which a value is served from the cache
+// after it is read, which bounds the staleness of the values whose
+// changes are missed. Zero retains the values until they are evicted
+// or invalidated.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *opts) {
+		o.ttl = ttl
+	}
+}
+
+// Stats are the statistics of the cache of a Client.
+type Stats struct {
+	// Hits is the number of keys read whose values were cached.
+	Hits uint64
+	// Misses is the number of keys read from the DKV node instead.
+	Misses uint64
+	// Invalidations is the number of keys whose values were invalidated,
+	// whether they were cached or not.
+	Invalidations uint64
+	// Evictions is the number of values evicted to make room for others.
+	Evictions uint64
+	// Entries is the number of values currently cached.
+	Entries int
+}
+
+// A DKVClient is the client of a DKV node whose reads are cached by a
+// Client. The DKV client of the ctl package satisfies it.
+type DKVClient interface {
+	// GetWithCtx returns the value of the given key, failing with
+	// ErrKeyNotFound if it is absent.
+	GetWithCtx(ctx context.Context, key []byte) (*serverpb.GetResponse, error)
+	// MultiGetWithCtx returns the values of the given keys in their
+	// order, which are nil for the absent keys.
+	MultiGetWithCtx(ctx context.Context, keys ...[]byte) ([][]byte, error)
+	// PutWithCtx stores the given key value pair.
+	PutWithCtx(ctx context.Context, key, value []byte) error
+	// DeleteWithCtx deletes the given key.
+	DeleteWithCtx(ctx context.Context, key []byte) error
+	// GetChangesWithCtx returns the given number of changes, upto the
+	// given size, from the given change number onwards.
+	GetChangesWithCtx(ctx context.Context, fromChangeNum uint64, maxNumChanges uint32, maxNumBytes uint64) (*serverpb.GetChangesResponse, error)
+	// KeyInNamespace returns the given key of a change without the
+	// prefix of the namespace of the client, reporting whether it
+	// belongs to this namespace at all.
+	KeyInNamespace(key []byte) ([]byte, bool)
+}
+
+// ErrKeyNotFound is returned by Get for the keys that are absent.
+var ErrKeyNotFound = dkverrors.ErrKeyNotFound
+
+// A Client serves the reads of keys from its cache, falling back on the
+// underlying DKV client for the keys whose values are not cached. Only
+// the values of keys that are present are cached. It is safe for
+// concurrent use.
+type Client struct {
+	dkvClnt DKVClient
+	cache   *lruCache
+	// Shall be manipulated using atomics
+	hits, misses, invalidations uint64
+}
+
+// New creates a Client that caches the values read through the given
+// DKV client, operating on the keys of its namespace.
+func New(dkvClnt DKVClient, options ...Option) (*Client, error) {
+	if dkvClnt == nil {
+		return nil, errors.New("invalid args - param `dkvClnt` is mandatory")
+	}
+	o := &opts{maxEntries: DefaultMaxEntries, ttl: DefaultTTL}
+	for _, opt := range options {
+		opt(o)
+	}
+	if o.maxEntries <= 0 || o.ttl < 0 {
+		return nil, errors.New("invalid args - maximum number of entries must be positive and TTL must not be negative")
+	}
+	return &Client{dkvClnt: dkvClnt, cache: newLRUCache(o.maxEntries, o.ttl)}, nil
+}
+
+// Bypass returns the DKV client given to New, for reads that must not be
+// served from the cache, such as linearizable reads. Writes made through
+// it must be followed by Invalidate, unless changes are being watched.
+func (c *Client) Bypass() DKVClient {
+	return c.dkvClnt
+}
+
+// Get returns the value of the given key from the cache if present,
+// or reads it using the Get method of the underlying DKV client. As
+// with the latter, ErrKeyNotFound is returned for absent keys. The
+// returned value must not be modified.
+func (c *Client) Get(key []byte) (*serverpb.GetResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ctl.DefaultTimeout)
+	defer cancel()
+	return c.GetWithCtx(ctx, key)
+}
+
+// GetWithCtx is same as Get except that the DKV node is read using
+// the given context.
+func (c *Client) GetWithCtx(ctx context.Context, key []byte) (*serverpb.GetResponse, error) {
+	if value, present := c.cache.get(string(key)); present {
+		atomic.AddUint64(&c.hits, 1)
+		return &serverpb.GetResponse{Status: &serverpb.Status{}, Value: value}, nil
+	}
+	atomic.AddUint64(&c.misses, 1)
+	gen := c.cache.generation()
+	res, err := c.dkvClnt.GetWithCtx(ctx, key)
+	if err == nil {
+		c.cache.add(gen, string(key), res.Value)
+	}
+	return res, err
+}
+
+// MultiGet returns the values of the given keys in their order, as per
+// the MultiGet method of the underlying DKV client, which reads only the
+// keys whose values are not cached. The returned values must not be
+// modified.
+func (c *Client) MultiGet(keys ...[]byte) ([][]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ctl.DefaultTimeout)
+	defer cancel()
+	return c.MultiGetWithCtx(ctx, keys...)
+}
+
+// MultiGetWithCtx is same as MultiGet except that the DKV node is read
+// using the given context.
+func (c *Client) MultiGetWithCtx(ctx context.Context, keys ...[]byte) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	var missIdxs []int
+	var missKeys [][]byte
+	for i, key := range keys {
+		value, present := c.cache.get(string(key))
+		if !present {
+			missIdxs, missKeys = append(missIdxs, i), append(missKeys, key)
+		}
+		values[i] = value
+	}
+	atomic.AddUint64(&c.hits, uint64(len(keys)-len(missKeys)))
+	if len(missKeys) == 0 {
+		return values, nil
+	}
+	atomic.AddUint64(&c.misses, uint64(len(missKeys)))
+	gen := c.cache.generation()
+	missValues, err := c.dkvClnt.MultiGetWithCtx(ctx, missKeys...)
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range missValues {
+		if values[missIdxs[i]] = value; value != nil {
+			c.cache.add(gen, string(missKeys[i]), value)
+		}
+	}
+	return values, nil
+}
+
+// Put stores the given key value pair using the Put method of the
+// underlying DKV client, and then invalidates the value of the key.
+func (c *Client) Put(key, value []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ctl.DefaultTimeout)
+	defer cancel()
+	return c.PutWithCtx(ctx, key, value)
+}
+
+// PutWithCtx is same as Put except that the key value pair is stored
+// using the given context.
+func (c *Client) PutWithCtx(ctx context.Context, key, value []byte) error {
+	// Failed writes may still have been applied
+	defer c.Invalidate(key)
+	return c.dkvClnt.PutWithCtx(ctx, key, value)
+}
+
+// Delete deletes the given key using the Delete method of the
+// underlying DKV client, and then invalidates its value.
+func (c *Client) Delete(key []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ctl.DefaultTimeout)
+	defer cancel()
+	return c.DeleteWithCtx(ctx, key)
+}
+
+// DeleteWithCtx is same as Delete except that the key is deleted
+// using the given context.
+func (c *Client) DeleteWithCtx(ctx context.Context, key []byte) error {
+	defer c.Invalidate(key)
+	return c.dkvClnt.DeleteWithCtx(ctx, key)
+}
+
+// Invalidate removes the values of the given keys from the cache. Values
+// of these keys being read concurrently are not cached either.
+func (c *Client) Invalidate(keys ...[]byte) {
+	strKeys := make([]string, len(keys))
+	for i, key := range keys {
+		strKeys[i] = string(key)
+	}
+	c.cache.remove(strKeys...)
+	atomic.AddUint64(&c.invalidations, uint64(len(keys)))
+}
+
+// Purge removes all the values from the cache.
+func (c *Client) Purge() {
+	c.cache.purge()
+}
+
+// Stats returns the current statistics of the cache.
+func (c *Client) Stats() Stats {
+	numEntries, evictions := c.cache.stats()
+	return Stats{
+		Hits:          atomic.LoadUint64(&c.hits),
+		Misses:        atomic.LoadUint64(&c.misses),
+		Invalidations: atomic.LoadUint64(&c.invalidations),
+		Evictions:     evictions,
+		Entries:       numEntries,
+	}
+}
+
+// WatchChanges invalidates the values of the keys changed on the DKV
+// master nodes, as delivered by the given Subscriber, until either the
+// given context is done or the subscription fails. Values cached before
+// the changes are watched are purged, and the changes are delivered from
+// the latest change number of the DKV node of the underlying client,
+// which must hence serve the GetChanges method. Subscribers are best
+// created with a CheckpointStore in memory, since the cache is lost upon
+// restarts anyway.
+//
+// Whenever the changes to be delivered are no longer available, such as
+// after a bulk load onto the master, the cache is purged and the changes
+// are watched afresh.
+func (c *Client) WatchChanges(ctx context.Context, sub *subscriber.Subscriber) error {
+	for {
+		chngNum, err := c.latestChangeNumber(ctx)
+		if err != nil {
+			return err
+		}
+		c.Purge()
+		err = sub.Subscribe(ctx, chngNum+1, c.invalidateChange)
+		if !errors.Is(err, subscriber.ErrChangesUnavailable) {
+			return err
+		}
+	}
+}
+
+func (c *Client) latestChangeNumber(ctx context.Context) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, ctl.DefaultTimeout)
+	defer cancel()
+	// No changes exist beyond the maximum change number
+	res, err := c.dkvClnt.GetChangesWithCtx(ctx, math.MaxUint64, 1, 0)
+	if err != nil {
+		return 0, err
+	}
+	if err = dkverrors.FromStatus(res.Status); err != nil {
+		return 0, err
+	}
+	return res.MasterChangeNumber, nil
+}
+
+// invalidateChange invalidates the values of the keys of the namespace
+// of the underlying client that are changed by the given change.
+func (c *Client) invalidateChange(chng *serverpb.ChangeRecord) error {
+	var keys [][]byte
+	for _, trxn := range chng.Trxns {
+		if key, present := c.dkvClnt.KeyInNamespace(trxn.Key); present {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) > 0 {
+		c.Invalidate(keys...)
+	}
+	return nil
+}
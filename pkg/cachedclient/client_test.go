@@ -0,0 +1,157 @@
+package cachedclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/server/master"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/flipkart-incubator/dkv/pkg/subscriber"
+	"google.golang.org/grpc"
+)
+
+// serveMaster serves an in-memory standalone master, returning
+// its address along with its store
+func serveMaster(t *testing.T) (string, memory.DB, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := memory.OpenDB(0)
+	dkvSvc := master.NewStandaloneService(store, store, store)
+	grpcSrvr := grpc.NewServer()
+	serverpb.RegisterDKVServer(grpcSrvr, dkvSvc)
+	serverpb.RegisterDKVReplicationServer(grpcSrvr, dkvSvc)
+	go grpcSrvr.Serve(lis)
+	return lis.Addr().String(), store, func() {
+		grpcSrvr.Stop()
+		dkvSvc.Close()
+	}
+}
+
+func newClient(t *testing.T, addr string, options ...Option) *Client {
+	dkvClnt, err := ctl.NewInSecureDKVClient(addr, ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := New(dkvClnt, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func checkGet(t *testing.T, client *Client, key, expValue string) {
+	if res, err := client.Get([]byte(key)); err != nil || string(res.Value) != expValue {
+		t.Errorf("GET mismatch. Key: %s, Expected Value: %s, Actual: %+v, Error: %v", key, expValue, res, err)
+	}
+}
+
+func checkStats(t *testing.T, client *Client, hits, misses uint64, numEntries int) {
+	if stats := client.Stats(); stats.Hits != hits || stats.Misses != misses || stats.Entries != numEntries {
+		t.Errorf("Expected %d hits, %d misses and %d entries. Actual: %+v", hits, misses, numEntries, stats)
+	}
+}
+
+func TestClientCachesValues(t *testing.T) {
+	addr, store, stop := serveMaster(t)
+	defer stop()
+	client := newClient(t, addr)
+	defer client.Bypass().(*ctl.DKVClient).Close()
+
+	if err := client.Put([]byte("K1"), []byte("V1")); err != nil {
+		t.Fatal(err)
+	}
+	checkGet(t, client, "K1", "V1")
+	// Writes made elsewhere are not seen until invalidated
+	store.Put([]byte("K1"), []byte("V2"))
+	checkGet(t, client, "K1", "V1")
+	checkStats(t, client, 1, 1, 1)
+	if res, err := client.Bypass().GetWithCtx(context.Background(), []byte("K1")); err != nil || string(res.Value) != "V2" {
+		t.Errorf("Expected reads that bypass the cache to be fresh. Value: %+v, Error: %v", res, err)
+	}
+	client.Invalidate([]byte("K1"))
+	checkGet(t, client, "K1", "V2")
+	if err := client.Put([]byte("K1"), []byte("V3")); err != nil {
+		t.Fatal(err)
+	}
+	checkGet(t, client, "K1", "V3")
+
+	// Only the keys that are not cached are read
+	store.Put([]byte("K2"), []byte("V2"))
+	if values, err := client.MultiGet([]byte("K1"), []byte("K2"), []byte("K3")); err != nil || fmt.Sprintf("%s", values) != "[V3 V2 ]" {
+		t.Errorf("MULTI_GET mismatch. Values: %s, Error: %v", values, err)
+	}
+	checkStats(t, client, 2, 5, 2)
+	if _, err := client.Get([]byte("K3")); err != ErrKeyNotFound {
+		t.Errorf("Expected error: %v for an absent key. Actual: %v", ErrKeyNotFound, err)
+	}
+}
+
+func TestClientEvictsValues(t *testing.T) {
+	addr, store, stop := serveMaster(t)
+	defer stop()
+	client := newClient(t, addr, WithMaxEntries(2), WithTTL(200*time.Millisecond))
+	defer client.Bypass().(*ctl.DKVClient).Close()
+
+	for i := 1; i <= 3; i++ {
+		store.Put([]byte(fmt.Sprintf("K%d", i)), []byte("V1"))
+	}
+	checkGet(t, client, "K1", "V1")
+	checkGet(t, client, "K2", "V1")
+	checkGet(t, client, "K1", "V1")
+	// Least recently used K2 makes room for K3
+	checkGet(t, client, "K3", "V1")
+	store.Put([]byte("K1"), []byte("V2"))
+	store.Put([]byte("K2"), []byte("V2"))
+	checkGet(t, client, "K1", "V1")
+	checkGet(t, client, "K2", "V2")
+	if stats := client.Stats(); stats.Evictions != 2 || stats.Entries != 2 {
+		t.Errorf("Expected 2 evictions. Actual: %+v", stats)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	checkGet(t, client, "K1", "V2")
+}
+
+func TestClientWatchesChanges(t *testing.T) {
+	addr, store, stop := serveMaster(t)
+	defer stop()
+	client := newClient(t, addr)
+	defer client.Bypass().(*ctl.DKVClient).Close()
+	store.Put([]byte("K1"), []byte("V1"))
+	checkGet(t, client, "K1", "V1")
+
+	mstrClnt, err := ctl.NewInSecureDKVClient(addr, ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := subscriber.New([]subscriber.Master{mstrClnt}, subscriber.NewMemoryCheckpointStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	watchErrs := make(chan error, 1)
+	go func() { watchErrs <- client.WatchChanges(ctx, sub) }()
+
+	// Changes made once the subscription begins are invalidated
+	time.Sleep(300 * time.Millisecond)
+	checkGet(t, client, "K1", "V1")
+	store.Put([]byte("K1"), []byte("V2"))
+	time.Sleep(300 * time.Millisecond)
+	checkGet(t, client, "K1", "V2")
+	if stats := client.Stats(); stats.Invalidations != 1 {
+		t.Errorf("Expected the changed key to be invalidated. Actual: %+v", stats)
+	}
+
+	cancel()
+	if err = <-watchErrs; err != context.Canceled {
+		t.Errorf("Expected the watch to be cancelled. Error: %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+// Package errors defines the errors of DKV operations that callers can
+// act upon, along with their mapping onto the status codes that DKV
+// nodes use for conveying them. Callers must check for these errors
+// using errors.Is, since they are usually wrapped with more details.
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrChangesUnavailable indicates that the requested changes are
+	// no longer retained by the master node.
+	ErrChangesUnavailable = errors.New("requested changes are no longer available")
+	// ErrChangesTruncated indicates that the requested changes are
+	// discarded by the master node as per its retention policy. It is
+	// matched by ErrChangesUnavailable as well.
+	ErrChangesTruncated = fmt.Errorf("truncated as per the retention policy: %w", ErrChangesUnavailable)
+	// ErrStaleRead indicates that the slave node lags behind its master
+	// node by more than the permissible lag of the read. Such reads can
+	// instead be served by the master node.
+	ErrStaleRead = errors.New("replication lag of the slave node exceeds the permissible lag")
+	// ErrNotLeader indicates that the master node is not the leader
+	// of its cluster.
+	ErrNotLeader = errors.New("master node is not the leader of its cluster")
+	// ErrNonNumericValue indicates that the current value of the key
+	// being incremented is not a big-endian encoded 64 bit integer.
+	ErrNonNumericValue = errors.New("value is not a big-endian encoded 64 bit integer")
+	// ErrTooLarge indicates that either the key or the value exceeds
+	// its maximum permissible size. It is matched by both ErrKeyTooLarge
+	// and ErrValueTooLarge.
+	ErrTooLarge = errors.New("exceeds the maximum permissible size")
+	// ErrKeyTooLarge indicates that the key exceeds its maximum
+	// permissible size.
+	ErrKeyTooLarge = fmt.Errorf("key %w", ErrTooLarge)
+	// ErrValueTooLarge indicates that the value exceeds its maximum
+	// permissible size.
+	ErrValueTooLarge = fmt.Errorf("value %w", ErrTooLarge)
+	// ErrKeyNotFound indicates that the given key is absent.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrReadOnlyReplica indicates that the DKV node is a slave that
+	// does not permit keyspace mutations, unless it is promoted.
+	ErrReadOnlyReplica = errors.New("DKV slave service does not support keyspace mutations")
+	// ErrInvalidArgument indicates that the request is malformed.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrBackupInProgress indicates that the DKV node is already running
+	// a backup or a restore, which must complete before another begins.
+	ErrBackupInProgress = errors.New("another backup or restore is in progress")
+	// ErrLinearizableReadUnsupported indicates that the DKV node is a
+	// slave that can not serve linearizable reads, unless it is promoted.
+	// Such reads can instead be served by the master node.
+	ErrLinearizableReadUnsupported = errors.New("DKV slave service does not support linearizable reads")
+	// ErrChecksumMismatch indicates that the transactions of a change
+	// do not match its checksum, hence the change is corrupted.
+	ErrChecksumMismatch = errors.New("transactions of the change do not match its checksum")
+	// ErrCompactionInProgress indicates that the DKV node is already
+	// running a manual compaction, which must complete before another.
+	ErrCompactionInProgress = errors.New("another compaction is in progress")
+	// ErrMaintenance indicates that the DKV node is in maintenance mode,
+	// during which it does not permit keyspace mutations.
+	ErrMaintenance = errors.New("DKV node is in maintenance mode, hence does not permit keyspace mutations")
+	// ErrInvalidKey indicates that the key violates the key policy of
+	// the DKV node, such as by not matching the pattern of its namespace.
+	ErrInvalidKey = errors.New("key violates the key policy")
+	// ErrQuotaExceeded indicates that the mutation would take the
+	// namespace beyond its quota of keys or bytes on the DKV node.
+	ErrQuotaExceeded = errors.New("namespace quota exceeded")
+	// ErrKeyExists indicates that the key being restored from its
+	// tombstone is present once again.
+	ErrKeyExists = errors.New("key already exists")
+	// ErrMalformedResponse indicates that the response of the DKV node
+	// does not match its request, such as when it lacks the results of
+	// some of the requested keys. It is detected by the clients, or by
+	// the master node for the results of its RAFT replicator, and is
+	// never conveyed through a status code of its own.
+	ErrMalformedResponse = errors.New("malformed response from the DKV node")
+)
+
+// UnknownStatusCode is the status code of all the failures that
+// are not conveyed using any of the errors of this package.
+const UnknownStatusCode = -1
+
+var errorsByCode = map[serverpb.StatusCode]error{
+	serverpb.StatusCode_ChangesUnavailable:          ErrChangesUnavailable,
+	serverpb.StatusCode_StaleRead:                   ErrStaleRead,
+	serverpb.StatusCode_NotLeader:                   ErrNotLeader,
+	serverpb.StatusCode_NonNumericValue:             ErrNonNumericValue,
+	serverpb.StatusCode_KeyTooLarge:                 ErrKeyTooLarge,
+	serverpb.StatusCode_ValueTooLarge:               ErrValueTooLarge,
+	serverpb.StatusCode_KeyNotFound:                 ErrKeyNotFound,
+	serverpb.StatusCode_ReadOnlyReplica:             ErrReadOnlyReplica,
+	serverpb.StatusCode_InvalidArgument:             ErrInvalidArgument,
+	serverpb.StatusCode_BackupInProgress:            ErrBackupInProgress,
+	serverpb.StatusCode_LinearizableReadUnsupported: ErrLinearizableReadUnsupported,
+	serverpb.StatusCode_ChecksumMismatch:            ErrChecksumMismatch,
+	serverpb.StatusCode_CompactionInProgress:        ErrCompactionInProgress,
+	serverpb.StatusCode_ChangesTruncated:            ErrChangesTruncated,
+	serverpb.StatusCode_Maintenance:                 ErrMaintenance,
+	serverpb.StatusCode_InvalidKey:                  ErrInvalidKey,
+	serverpb.StatusCode_QuotaExceeded:               ErrQuotaExceeded,
+	serverpb.StatusCode_KeyExists:                   ErrKeyExists,
+}
+
+// Codes whose errors wrap those of other codes, which are hence
+// matched ahead of the others.
+var wrappingCodes = []serverpb.StatusCode{serverpb.StatusCode_ChangesTruncated}
+
+// StatusCode returns the status code that conveys the given error,
+// which is UnknownStatusCode if none of the errors of this package
+// match it. Success is conveyed by the zero status code.
+func StatusCode(err error) int32 {
+	if err == nil {
+		return int32(serverpb.StatusCode_Ok)
+	}
+	for _, code := range wrappingCodes {
+		if errors.Is(err, errorsByCode[code]) {
+			return int32(code)
+		}
+	}
+	for code, codeErr := range errorsByCode {
+		if errors.Is(err, codeErr) {
+			return int32(code)
+		}
+	}
+	return UnknownStatusCode
+}
+
+// NewStatus creates the status that conveys the given error, along with
+// the leader hinted by the error, if any.
+func NewStatus(err error) *serverpb.Status {
+	if err == nil {
+		return &serverpb.Status{Code: int32(serverpb.StatusCode_Ok)}
+	}
+	return &serverpb.Status{Code: StatusCode(err), Message: err.Error(), Leader: LeaderHint(err)}
+}
+
+// WithLeaderHint wraps the given error of a follower that rejects a change
+// with the leader of its cluster, which is conveyed by the status created
+// from the returned error. The returned error matches ErrNotLeader.
+func WithLeaderHint(err error, leader *serverpb.LeaderHint) error {
+	if !errors.Is(err, ErrNotLeader) {
+		err = fmt.Errorf("%v: %w", err, ErrNotLeader)
+	}
+	return &leaderHintError{err, leader}
+}
+
+// LeaderHint returns the leader hinted by the given error, which is nil
+// if the error does not hint any.
+func LeaderHint(err error) *serverpb.LeaderHint {
+	var lhe *leaderHintError
+	if errors.As(err, &lhe) {
+		return lhe.leader
+	}
+	return nil
+}
+
+type leaderHintError struct {
+	error
+	leader *serverpb.LeaderHint
+}
+
+func (lhe *leaderHintError) Unwrap() error {
+	return lhe.error
+}
+
+// FromStatus returns the error conveyed by the given status, which is
+// nil for a successful status. The returned error retains the message
+// of the status while matching the error of its code through errors.Is.
+// The leader hinted by the status, if any, is returned by LeaderHint.
+func FromStatus(status *serverpb.Status) error {
+	if status == nil || status.Code == int32(serverpb.StatusCode_Ok) {
+		return nil
+	}
+	if status.Leader != nil {
+		return &leaderHintError{fromStatus(status), status.Leader}
+	}
+	return fromStatus(status)
+}
+
+func fromStatus(status *serverpb.Status) error {
+	codeErr, present := errorsByCode[serverpb.StatusCode(status.Code)]
+	switch {
+	case !present:
+		return errors.New(status.Message)
+	case status.Message == "" || status.Message == codeErr.Error():
+		return codeErr
+	default:
+		return &statusError{codeErr, status.Message}
+	}
+}
+
+// A statusError retains the message of a failed status,
+// while still matching the error of its code.
+type statusError struct {
+	codeErr error
+	msg     string
+}
+
+func (se *statusError) Error() string {
+	return se.msg
+}
+
+func (se *statusError) Unwrap() error {
+	return se.codeErr
+}
+
+// GRPCContextError returns the GRPC status error, of either the Canceled
+// or the DeadlineExceeded code, that conveys the given error of a done
+// context. Returns nil if the given error is not that of a context.
+// Requests abandoned by their clients fail with such errors rather than
+// with any DKV status, which the clients would never receive.
+func GRPCContextError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return nil
+	}
+}
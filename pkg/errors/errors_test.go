@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+func TestStatusRoundTrip(t *testing.T) {
+	for code, codeErr := range errorsByCode {
+		err := fmt.Errorf("unable to serve request: %w", codeErr)
+		status := NewStatus(err)
+		if status.Code != int32(code) {
+			t.Errorf("Status code mismatch for error: %v. Expected: %d, Actual: %d", err, code, status.Code)
+		}
+		actErr := FromStatus(status)
+		if !errors.Is(actErr, codeErr) {
+			t.Errorf("Expected error: %v to match: %v", actErr, codeErr)
+		}
+		if actErr.Error() != err.Error() {
+			t.Errorf("Error message mismatch. Expected: %s, Actual: %s", err.Error(), actErr.Error())
+		}
+	}
+}
+
+func TestUnknownStatus(t *testing.T) {
+	err := errors.New("something went wrong")
+	status := NewStatus(err)
+	if status.Code != UnknownStatusCode {
+		t.Errorf("Status code mismatch. Expected: %d, Actual: %d", UnknownStatusCode, status.Code)
+	}
+	actErr := FromStatus(status)
+	if actErr == nil || actErr.Error() != err.Error() {
+		t.Errorf("Error mismatch. Expected: %v, Actual: %v", err, actErr)
+	}
+	for _, codeErr := range errorsByCode {
+		if errors.Is(actErr, codeErr) {
+			t.Errorf("Expected error: %v to not match: %v", actErr, codeErr)
+		}
+	}
+}
+
+func TestSuccessfulStatus(t *testing.T) {
+	if status := NewStatus(nil); status.Code != int32(serverpb.StatusCode_Ok) {
+		t.Errorf("Expected successful status. Actual: %v", status)
+	}
+	if err := FromStatus(&serverpb.Status{}); err != nil {
+		t.Errorf("Expected no error from a successful status. Actual: %v", err)
+	}
+	if err := FromStatus(nil); err != nil {
+		t.Errorf("Expected no error from a missing status. Actual: %v", err)
+	}
+}
+
+func TestTooLarge(t *testing.T) {
+	keyErr := FromStatus(NewStatus(ErrKeyTooLarge))
+	valErr := FromStatus(NewStatus(ErrValueTooLarge))
+	if !errors.Is(keyErr, ErrTooLarge) || !errors.Is(valErr, ErrTooLarge) {
+		t.Errorf("Expected errors: %v and %v to match: %v", keyErr, valErr, ErrTooLarge)
+	}
+	if errors.Is(keyErr, ErrValueTooLarge) || errors.Is(valErr, ErrKeyTooLarge) {
+		t.Errorf("Expected errors: %v and %v to be distinguishable", keyErr, valErr)
+	}
+}
+
+func TestChangesTruncated(t *testing.T) {
+	status := NewStatus(fmt.Errorf("unable to load changes: %w", ErrChangesTruncated))
+	if status.Code != int32(serverpb.StatusCode_ChangesTruncated) {
+		t.Errorf("Expected the ChangesTruncated code rather than that of the error it wraps. Actual: %d", status.Code)
+	}
+	if err := FromStatus(status); !errors.Is(err, ErrChangesUnavailable) {
+		t.Errorf("Expected error: %v to match: %v", err, ErrChangesUnavailable)
+	}
+}
+
+func TestLeaderHint(t *testing.T) {
+	leader := &serverpb.LeaderHint{NodeId: 2, DkvAddr: "127.0.0.1:9082"}
+	status := NewStatus(WithLeaderHint(errors.New("proposal dropped"), leader))
+	if status.Code != int32(serverpb.StatusCode_NotLeader) || status.Leader != leader {
+		t.Errorf("Expected the NotLeader status with the leader hint. Actual: %v", status)
+	}
+	err := FromStatus(status)
+	if !errors.Is(err, ErrNotLeader) {
+		t.Errorf("Expected error: %v to match: %v", err, ErrNotLeader)
+	}
+	if actLeader := LeaderHint(fmt.Errorf("unable to put: %w", err)); actLeader != leader {
+		t.Errorf("Leader hint mismatch. Expected: %v, Actual: %v", leader, actLeader)
+	}
+	if actLeader := LeaderHint(FromStatus(NewStatus(ErrNotLeader))); actLeader != nil {
+		t.Errorf("Expected no leader hint. Actual: %v", actLeader)
+	}
+}
@@ -24,6 +24,344 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// StatusCode enumerates the error codes of specific failures
+// that callers can act upon. All other failures are conveyed
+// using an error code of -1. The Go errors corresponding to
+// every code are defined in pkg/errors.
+type StatusCode int32
+
+const (
+	// Ok indicates the success of the underlying operation
+	StatusCode_Ok StatusCode = 0
+	// ChangesUnavailable indicates that the requested changes are
+	// no longer retained by the master node
+	StatusCode_ChangesUnavailable StatusCode = 1
+	// StaleRead indicates that the slave node is lagging behind its
+	// master node by more than the permissible lag of the read
+	StatusCode_StaleRead StatusCode = 2
+	// NotLeader indicates that the master node is not the leader of
+	// its cluster and hence its changes must not be replicated
+	StatusCode_NotLeader StatusCode = 3
+	// NonNumericValue indicates that the current value of the key being
+	// incremented is not a big-endian encoded 64 bit integer
+	StatusCode_NonNumericValue StatusCode = 4
+	// KeyTooLarge indicates that the key exceeds the maximum
+	// size permitted by the DKV node
+	StatusCode_KeyTooLarge StatusCode = 5
+	// ValueTooLarge indicates that the value exceeds the maximum
+	// size permitted by the DKV node
+	StatusCode_ValueTooLarge StatusCode = 6
+	// KeyNotFound indicates that the given key is absent
+	StatusCode_KeyNotFound StatusCode = 7
+	// ReadOnlyReplica indicates that the DKV node is a slave that
+	// does not permit keyspace mutations until it is promoted
+	StatusCode_ReadOnlyReplica StatusCode = 8
+	// InvalidArgument indicates that the request is malformed
+	StatusCode_InvalidArgument StatusCode = 9
+	// BackupInProgress indicates that the DKV node is already running
+	// a backup or a restore, which must complete before another begins
+	StatusCode_BackupInProgress StatusCode = 10
+	// LinearizableReadUnsupported indicates that the DKV node is a slave
+	// that can not serve linearizable reads, unless it is promoted
+	StatusCode_LinearizableReadUnsupported StatusCode = 11
+	// ChecksumMismatch indicates that the transactions of a change
+	// do not match its checksum, hence the change is corrupted
+	StatusCode_ChecksumMismatch StatusCode = 12
+	// CompactionInProgress indicates that the DKV node is already running
+	// a manual compaction, which must complete before another begins
+	StatusCode_CompactionInProgress StatusCode = 13
+	// ChangesTruncated indicates that the requested changes are no longer
+	// retained by the master node as per its change log retention policy
+	StatusCode_ChangesTruncated StatusCode = 14
+	// Maintenance indicates that the DKV node is in maintenance mode,
+	// during which it does not permit keyspace mutations
+	StatusCode_Maintenance StatusCode = 15
+	// InvalidKey indicates that the key violates the key policy of the
+	// DKV node, such as by not matching the pattern of its namespace
+	StatusCode_InvalidKey StatusCode = 16
+	// QuotaExceeded indicates that the mutation would take the namespace
+	// beyond its quota of keys or bytes on the DKV node
+	StatusCode_QuotaExceeded StatusCode = 17
+	// KeyExists indicates that the key being restored from its tombstone
+	// is present once again
+	StatusCode_KeyExists StatusCode = 18
+)
+
+var StatusCode_name = map[int32]string{
+	0:  "Ok",
+	1:  "ChangesUnavailable",
+	2:  "StaleRead",
+	3:  "NotLeader",
+	4:  "NonNumericValue",
+	5:  "KeyTooLarge",
+	6:  "ValueTooLarge",
+	7:  "KeyNotFound",
+	8:  "ReadOnlyReplica",
+	9:  "InvalidArgument",
+	10: "BackupInProgress",
+	11: "LinearizableReadUnsupported",
+	12: "ChecksumMismatch",
+	13: "CompactionInProgress",
+	14: "ChangesTruncated",
+	15: "Maintenance",
+	16: "InvalidKey",
+	17: "QuotaExceeded",
+	18: "KeyExists",
+}
+
+var StatusCode_value = map[string]int32{
+	"Ok":                          0,
+	"ChangesUnavailable":          1,
+	"StaleRead":                   2,
+	"NotLeader":                   3,
+	"NonNumericValue":             4,
+	"KeyTooLarge":                 5,
+	"ValueTooLarge":               6,
+	"KeyNotFound":                 7,
+	"ReadOnlyReplica":             8,
+	"InvalidArgument":             9,
+	"BackupInProgress":            10,
+	"LinearizableReadUnsupported": 11,
+	"ChecksumMismatch":            12,
+	"CompactionInProgress":        13,
+	"ChangesTruncated":            14,
+	"Maintenance":                 15,
+	"InvalidKey":                  16,
+	"QuotaExceeded":               17,
+	"KeyExists":                   18,
+}
+
+func (x StatusCode) String() string {
+	return proto.EnumName(StatusCode_name, int32(x))
+}
+
+func (StatusCode) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{0}
+}
+
+type ReadConsistency int32
+
+const (
+	// Sequential reads are served from the local state of the DKV node,
+	// which may not yet reflect the changes committed by its cluster
+	ReadConsistency_Sequential ReadConsistency = 0
+	// Linearizable reads reflect every change committed by the cluster
+	// before the read began, at the cost of a round of RAFT consensus
+	ReadConsistency_Linearizable ReadConsistency = 1
+)
+
+var ReadConsistency_name = map[int32]string{
+	0: "Sequential",
+	1: "Linearizable",
+}
+
+var ReadConsistency_value = map[string]int32{
+	"Sequential":   0,
+	"Linearizable": 1,
+}
+
+func (x ReadConsistency) String() string {
+	return proto.EnumName(ReadConsistency_name, int32(x))
+}
+
+func (ReadConsistency) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{1}
+}
+
+type SyncMode int32
+
+const (
+	// DefaultSync writes as per the default sync mode of the DKV node
+	SyncMode_DefaultSync SyncMode = 0
+	// Sync writes are persisted onto the disk before they are acknowledged
+	SyncMode_Sync SyncMode = 1
+	// Async writes are acknowledged before they are persisted onto the disk,
+	// hence may be lost upon a crash of the DKV node
+	SyncMode_Async SyncMode = 2
+)
+
+var SyncMode_name = map[int32]string{
+	0: "DefaultSync",
+	1: "Sync",
+	2: "Async",
+}
+
+var SyncMode_value = map[string]int32{
+	"DefaultSync": 0,
+	"Sync":        1,
+	"Async":       2,
+}
+
+func (x SyncMode) String() string {
+	return proto.EnumName(SyncMode_name, int32(x))
+}
+
+func (SyncMode) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{2}
+}
+
+type BackupJobState int32
+
+const (
+	// Running indicates that the job is in progress
+	BackupJobState_Running BackupJobState = 0
+	// Done indicates that the job completed successfully
+	BackupJobState_Done BackupJobState = 1
+	// Failed indicates that the job could not be completed
+	BackupJobState_Failed BackupJobState = 2
+)
+
+var BackupJobState_name = map[int32]string{
+	0: "Running",
+	1: "Done",
+	2: "Failed",
+}
+
+var BackupJobState_value = map[string]int32{
+	"Running": 0,
+	"Done":    1,
+	"Failed":  2,
+}
+
+func (x BackupJobState) String() string {
+	return proto.EnumName(BackupJobState_name, int32(x))
+}
+
+func (BackupJobState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{3}
+}
+
+type NodeRole int32
+
+const (
+	// UnknownRole indicates that the role of the node could not be determined
+	NodeRole_UnknownRole NodeRole = 0
+	// Leader indicates that the node leads the cluster
+	NodeRole_Leader NodeRole = 1
+	// Follower indicates that the node follows the leader of the cluster
+	NodeRole_Follower NodeRole = 2
+)
+
+var NodeRole_name = map[int32]string{
+	0: "UnknownRole",
+	1: "Leader",
+	2: "Follower",
+}
+
+var NodeRole_value = map[string]int32{
+	"UnknownRole": 0,
+	"Leader":      1,
+	"Follower":    2,
+}
+
+func (x NodeRole) String() string {
+	return proto.EnumName(NodeRole_name, int32(x))
+}
+
+func (NodeRole) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{4}
+}
+
+type DecommissionState int32
+
+const (
+	// TransferringLeadership indicates that the leadership of the cluster
+	// is being transferred away from the node
+	DecommissionState_TransferringLeadership DecommissionState = 0
+	// CatchingUp indicates that the remaining members are catching up
+	// with the changes of the node
+	DecommissionState_CatchingUp DecommissionState = 1
+	// Removing indicates that the node is being removed from the cluster
+	DecommissionState_Removing DecommissionState = 2
+	// Decommissioned indicates that the node is removed from the cluster
+	DecommissionState_Decommissioned DecommissionState = 3
+	// DecommissionFailed indicates that the node could not be removed,
+	// hence it remains a member of the cluster
+	DecommissionState_DecommissionFailed DecommissionState = 4
+)
+
+var DecommissionState_name = map[int32]string{
+	0: "TransferringLeadership",
+	1: "CatchingUp",
+	2: "Removing",
+	3: "Decommissioned",
+	4: "DecommissionFailed",
+}
+
+var DecommissionState_value = map[string]int32{
+	"TransferringLeadership": 0,
+	"CatchingUp":             1,
+	"Removing":               2,
+	"Decommissioned":         3,
+	"DecommissionFailed":     4,
+}
+
+func (x DecommissionState) String() string {
+	return proto.EnumName(DecommissionState_name, int32(x))
+}
+
+func (DecommissionState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{5}
+}
+
+type ServerRole int32
+
+const (
+	// Standalone indicates that the node serves on its own, without replication
+	ServerRole_Standalone ServerRole = 0
+	// Master indicates that the node takes the writes and serves its changes
+	ServerRole_Master ServerRole = 1
+	// Slave indicates that the node replicates the changes of another node
+	ServerRole_Slave ServerRole = 2
+)
+
+var ServerRole_name = map[int32]string{
+	0: "Standalone",
+	1: "Master",
+	2: "Slave",
+}
+
+var ServerRole_value = map[string]int32{
+	"Standalone": 0,
+	"Master":     1,
+	"Slave":      2,
+}
+
+func (x ServerRole) String() string {
+	return proto.EnumName(ServerRole_name, int32(x))
+}
+
+func (ServerRole) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{6}
+}
+
+type TxnCondition_Type int32
+
+const (
+	// Equals holds when the key is present with the given value.
+	TxnCondition_Equals TxnCondition_Type = 0
+	// Absent holds when the key is not present.
+	TxnCondition_Absent TxnCondition_Type = 1
+)
+
+var TxnCondition_Type_name = map[int32]string{
+	0: "Equals",
+	1: "Absent",
+}
+
+var TxnCondition_Type_value = map[string]int32{
+	"Equals": 0,
+	"Absent": 1,
+}
+
+func (x TxnCondition_Type) String() string {
+	return proto.EnumName(TxnCondition_Type_name, int32(x))
+}
+
+func (TxnCondition_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{21, 0}
+}
+
 type TrxnRecord_TrxnType int32
 
 const (
@@ -49,7 +387,7 @@ func (x TrxnRecord_TrxnType) String() string {
 }
 
 func (TrxnRecord_TrxnType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{10, 0}
+	return fileDescriptor_8ac913527469ef71, []int{44, 0}
 }
 
 type Status struct {
@@ -57,10 +395,13 @@ type Status struct {
 	// A non zero error code is considered to be a failure.
 	Code int32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	// Message captures if any the error message of the failed operation.
-	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Leader identifies the leader of the cluster when the operation
+	// fails with the NotLeader code, if the leader is known.
+	Leader               *LeaderHint `protobuf:"bytes,3,opt,name=leader,proto3" json:"leader,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
 }
 
 func (m *Status) Reset()         { *m = Status{} }
@@ -102,11 +443,78 @@ func (m *Status) GetMessage() string {
 	return ""
 }
 
+func (m *Status) GetLeader() *LeaderHint {
+	if m != nil {
+		return m.Leader
+	}
+	return nil
+}
+
+// LeaderHint identifies the leader of the cluster of a master node
+// that rejects the changes it cannot make as a follower.
+type LeaderHint struct {
+	NodeId uint32 `protobuf:"varint,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	// DkvAddr is the address of the DKV service running on the leader,
+	// which is empty if unknown.
+	DkvAddr              string   `protobuf:"bytes,2,opt,name=dkvAddr,proto3" json:"dkvAddr,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaderHint) Reset()         { *m = LeaderHint{} }
+func (m *LeaderHint) String() string { return proto.CompactTextString(m) }
+func (*LeaderHint) ProtoMessage()    {}
+func (*LeaderHint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{1}
+}
+
+func (m *LeaderHint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaderHint.Unmarshal(m, b)
+}
+func (m *LeaderHint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaderHint.Marshal(b, m, deterministic)
+}
+func (m *LeaderHint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaderHint.Merge(m, src)
+}
+func (m *LeaderHint) XXX_Size() int {
+	return xxx_messageInfo_LeaderHint.Size(m)
+}
+func (m *LeaderHint) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaderHint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaderHint proto.InternalMessageInfo
+
+func (m *LeaderHint) GetNodeId() uint32 {
+	if m != nil {
+		return m.NodeId
+	}
+	return 0
+}
+
+func (m *LeaderHint) GetDkvAddr() string {
+	if m != nil {
+		return m.DkvAddr
+	}
+	return ""
+}
+
 type PutRequest struct {
 	// Key is the key, in bytes, to put into the key value store.
 	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	// Value is the value, in bytes, to associate with the key in the key value store.
-	Value                []byte   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// ExpireTS is the absolute time, in unix seconds, at which the key expires.
+	// Expired keys are no longer visible. Zero indicates that the key never expires.
+	ExpireTS uint64 `protobuf:"varint,3,opt,name=expireTS,proto3" json:"expireTS,omitempty"`
+	// Namespace is the namespace of the key, which isolates it from the keys of all the
+	// other namespaces. The default namespace is used when it is empty.
+	Namespace string `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// SyncMode determines whether the put is persisted onto the disk of the
+	// master node before it is acknowledged.
+	SyncMode             SyncMode `protobuf:"varint,5,opt,name=syncMode,proto3,enum=dkv.serverpb.SyncMode" json:"syncMode,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -116,7 +524,7 @@ func (m *PutRequest) Reset()         { *m = PutRequest{} }
 func (m *PutRequest) String() string { return proto.CompactTextString(m) }
 func (*PutRequest) ProtoMessage()    {}
 func (*PutRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{1}
+	return fileDescriptor_8ac913527469ef71, []int{2}
 }
 
 func (m *PutRequest) XXX_Unmarshal(b []byte) error {
@@ -151,6 +559,27 @@ func (m *PutRequest) GetValue() []byte {
 	return nil
 }
 
+func (m *PutRequest) GetExpireTS() uint64 {
+	if m != nil {
+		return m.ExpireTS
+	}
+	return 0
+}
+
+func (m *PutRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *PutRequest) GetSyncMode() SyncMode {
+	if m != nil {
+		return m.SyncMode
+	}
+	return SyncMode_DefaultSync
+}
+
 type PutResponse struct {
 	// Status indicates the result of the Put operation
 	Status               *Status  `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
@@ -163,7 +592,7 @@ func (m *PutResponse) Reset()         { *m = PutResponse{} }
 func (m *PutResponse) String() string { return proto.CompactTextString(m) }
 func (*PutResponse) ProtoMessage()    {}
 func (*PutResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{2}
+	return fileDescriptor_8ac913527469ef71, []int{3}
 }
 
 func (m *PutResponse) XXX_Unmarshal(b []byte) error {
@@ -191,1125 +620,9137 @@ func (m *PutResponse) GetStatus() *Status {
 	return nil
 }
 
-type GetRequest struct {
-	// Key is the key, in bytes, whose associated value is loaded from the key value store.
-	Key                  []byte   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type MultiPutRequest struct {
+	// PutRequests is the collection of key value pairs that are put
+	// into the key value store as a single atomic batch.
+	PutRequests          []*PutRequest `protobuf:"bytes,1,rep,name=putRequests,proto3" json:"putRequests,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
 
-func (m *GetRequest) Reset()         { *m = GetRequest{} }
-func (m *GetRequest) String() string { return proto.CompactTextString(m) }
-func (*GetRequest) ProtoMessage()    {}
-func (*GetRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{3}
+func (m *MultiPutRequest) Reset()         { *m = MultiPutRequest{} }
+func (m *MultiPutRequest) String() string { return proto.CompactTextString(m) }
+func (*MultiPutRequest) ProtoMessage()    {}
+func (*MultiPutRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{4}
 }
 
-func (m *GetRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetRequest.Unmarshal(m, b)
+func (m *MultiPutRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultiPutRequest.Unmarshal(m, b)
 }
-func (m *GetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetRequest.Marshal(b, m, deterministic)
+func (m *MultiPutRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultiPutRequest.Marshal(b, m, deterministic)
 }
-func (m *GetRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetRequest.Merge(m, src)
+func (m *MultiPutRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultiPutRequest.Merge(m, src)
 }
-func (m *GetRequest) XXX_Size() int {
-	return xxx_messageInfo_GetRequest.Size(m)
+func (m *MultiPutRequest) XXX_Size() int {
+	return xxx_messageInfo_MultiPutRequest.Size(m)
 }
-func (m *GetRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetRequest.DiscardUnknown(m)
+func (m *MultiPutRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultiPutRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetRequest proto.InternalMessageInfo
+var xxx_messageInfo_MultiPutRequest proto.InternalMessageInfo
 
-func (m *GetRequest) GetKey() []byte {
+func (m *MultiPutRequest) GetPutRequests() []*PutRequest {
 	if m != nil {
-		return m.Key
+		return m.PutRequests
 	}
 	return nil
 }
 
-type GetResponse struct {
-	// Status indicates the result of the Get operation
-	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	// Value is the value, in bytes, that is associated with the given key in the key value store.
-	Value                []byte   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+type MultiPutResponse struct {
+	// Status indicates the result of the bulk Put operation
+	Status               *Status  `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetResponse) Reset()         { *m = GetResponse{} }
-func (m *GetResponse) String() string { return proto.CompactTextString(m) }
-func (*GetResponse) ProtoMessage()    {}
-func (*GetResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{4}
+func (m *MultiPutResponse) Reset()         { *m = MultiPutResponse{} }
+func (m *MultiPutResponse) String() string { return proto.CompactTextString(m) }
+func (*MultiPutResponse) ProtoMessage()    {}
+func (*MultiPutResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{5}
 }
 
-func (m *GetResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetResponse.Unmarshal(m, b)
+func (m *MultiPutResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultiPutResponse.Unmarshal(m, b)
 }
-func (m *GetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetResponse.Marshal(b, m, deterministic)
+func (m *MultiPutResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultiPutResponse.Marshal(b, m, deterministic)
 }
-func (m *GetResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetResponse.Merge(m, src)
+func (m *MultiPutResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultiPutResponse.Merge(m, src)
 }
-func (m *GetResponse) XXX_Size() int {
-	return xxx_messageInfo_GetResponse.Size(m)
+func (m *MultiPutResponse) XXX_Size() int {
+	return xxx_messageInfo_MultiPutResponse.Size(m)
 }
-func (m *GetResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetResponse.DiscardUnknown(m)
+func (m *MultiPutResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultiPutResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetResponse proto.InternalMessageInfo
+var xxx_messageInfo_MultiPutResponse proto.InternalMessageInfo
 
-func (m *GetResponse) GetStatus() *Status {
+func (m *MultiPutResponse) GetStatus() *Status {
 	if m != nil {
 		return m.Status
 	}
 	return nil
 }
 
-func (m *GetResponse) GetValue() []byte {
-	if m != nil {
-		return m.Value
-	}
-	return nil
-}
-
-type MultiGetRequest struct {
-	// Keys is the collection of keys whose values are returned from the bulk Get operation.
-	Keys                 [][]byte `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+type DeleteRequest struct {
+	// Key is the key, in bytes, to delete from the key value store.
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Namespace is the namespace of the key, which isolates it from the keys of all the
+	// other namespaces. The default namespace is used when it is empty.
+	Namespace            string   `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *MultiGetRequest) Reset()         { *m = MultiGetRequest{} }
-func (m *MultiGetRequest) String() string { return proto.CompactTextString(m) }
-func (*MultiGetRequest) ProtoMessage()    {}
-func (*MultiGetRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{5}
+func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()    {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{6}
 }
 
-func (m *MultiGetRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_MultiGetRequest.Unmarshal(m, b)
+func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
 }
-func (m *MultiGetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_MultiGetRequest.Marshal(b, m, deterministic)
+func (m *DeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRequest.Marshal(b, m, deterministic)
 }
-func (m *MultiGetRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_MultiGetRequest.Merge(m, src)
+func (m *DeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRequest.Merge(m, src)
 }
-func (m *MultiGetRequest) XXX_Size() int {
-	return xxx_messageInfo_MultiGetRequest.Size(m)
+func (m *DeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRequest.Size(m)
 }
-func (m *MultiGetRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_MultiGetRequest.DiscardUnknown(m)
+func (m *DeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_MultiGetRequest proto.InternalMessageInfo
+var xxx_messageInfo_DeleteRequest proto.InternalMessageInfo
 
-func (m *MultiGetRequest) GetKeys() [][]byte {
+func (m *DeleteRequest) GetKey() []byte {
 	if m != nil {
-		return m.Keys
+		return m.Key
 	}
 	return nil
 }
 
-type MultiGetResponse struct {
-	// Status indicates the result of the bulk Get operation
-	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	// Values are the individual responses of the bulk Get operation.
-	Values               [][]byte `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
+func (m *DeleteRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	// Status indicates the result of the Delete operation
+	Status               *Status  `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *MultiGetResponse) Reset()         { *m = MultiGetResponse{} }
-func (m *MultiGetResponse) String() string { return proto.CompactTextString(m) }
-func (*MultiGetResponse) ProtoMessage()    {}
-func (*MultiGetResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{6}
+func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
+func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteResponse) ProtoMessage()    {}
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{7}
 }
 
-func (m *MultiGetResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_MultiGetResponse.Unmarshal(m, b)
+func (m *DeleteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteResponse.Unmarshal(m, b)
 }
-func (m *MultiGetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_MultiGetResponse.Marshal(b, m, deterministic)
+func (m *DeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteResponse.Marshal(b, m, deterministic)
 }
-func (m *MultiGetResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_MultiGetResponse.Merge(m, src)
+func (m *DeleteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteResponse.Merge(m, src)
 }
-func (m *MultiGetResponse) XXX_Size() int {
-	return xxx_messageInfo_MultiGetResponse.Size(m)
+func (m *DeleteResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteResponse.Size(m)
 }
-func (m *MultiGetResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_MultiGetResponse.DiscardUnknown(m)
+func (m *DeleteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_MultiGetResponse proto.InternalMessageInfo
+var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
 
-func (m *MultiGetResponse) GetStatus() *Status {
+func (m *DeleteResponse) GetStatus() *Status {
 	if m != nil {
 		return m.Status
 	}
 	return nil
 }
 
-func (m *MultiGetResponse) GetValues() [][]byte {
-	if m != nil {
-		return m.Values
-	}
-	return nil
-}
-
-type GetChangesRequest struct {
-	// FromChangeNumber is the starting change number from which to retrieve changes
-	FromChangeNumber uint64 `protobuf:"varint,1,opt,name=fromChangeNumber,proto3" json:"fromChangeNumber,omitempty"`
-	// MaxNumberOfChanges is the maximum number of changes to return from this invocation
-	MaxNumberOfChanges   uint32   `protobuf:"varint,2,opt,name=maxNumberOfChanges,proto3" json:"maxNumberOfChanges,omitempty"`
+type UndeleteRequest struct {
+	// Key is the key, in bytes, to restore from its tombstone.
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Namespace is the namespace of the key, which isolates it from the keys of all the
+	// other namespaces. The default namespace is used when it is empty.
+	Namespace            string   `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetChangesRequest) Reset()         { *m = GetChangesRequest{} }
-func (m *GetChangesRequest) String() string { return proto.CompactTextString(m) }
-func (*GetChangesRequest) ProtoMessage()    {}
-func (*GetChangesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{7}
+func (m *UndeleteRequest) Reset()         { *m = UndeleteRequest{} }
+func (m *UndeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*UndeleteRequest) ProtoMessage()    {}
+func (*UndeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{8}
 }
 
-func (m *GetChangesRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetChangesRequest.Unmarshal(m, b)
+func (m *UndeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UndeleteRequest.Unmarshal(m, b)
 }
-func (m *GetChangesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetChangesRequest.Marshal(b, m, deterministic)
+func (m *UndeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UndeleteRequest.Marshal(b, m, deterministic)
 }
-func (m *GetChangesRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetChangesRequest.Merge(m, src)
+func (m *UndeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UndeleteRequest.Merge(m, src)
 }
-func (m *GetChangesRequest) XXX_Size() int {
-	return xxx_messageInfo_GetChangesRequest.Size(m)
+func (m *UndeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_UndeleteRequest.Size(m)
 }
-func (m *GetChangesRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetChangesRequest.DiscardUnknown(m)
+func (m *UndeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UndeleteRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetChangesRequest proto.InternalMessageInfo
+var xxx_messageInfo_UndeleteRequest proto.InternalMessageInfo
 
-func (m *GetChangesRequest) GetFromChangeNumber() uint64 {
+func (m *UndeleteRequest) GetKey() []byte {
 	if m != nil {
-		return m.FromChangeNumber
+		return m.Key
 	}
-	return 0
+	return nil
 }
 
-func (m *GetChangesRequest) GetMaxNumberOfChanges() uint32 {
+func (m *UndeleteRequest) GetNamespace() string {
 	if m != nil {
-		return m.MaxNumberOfChanges
+		return m.Namespace
 	}
-	return 0
+	return ""
 }
 
-type GetChangesResponse struct {
-	// Status indicates the result of the GetChanges operation
-	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	// MasterChangeNumber indicates the latest change number on master node
-	MasterChangeNumber uint64 `protobuf:"varint,2,opt,name=masterChangeNumber,proto3" json:"masterChangeNumber,omitempty"`
-	// NumberOfChanges indicates the number of change records in the response
-	NumberOfChanges uint32 `protobuf:"varint,3,opt,name=numberOfChanges,proto3" json:"numberOfChanges,omitempty"`
-	// Changes is the collection of change records
-	Changes              []*ChangeRecord `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+type UndeleteResponse struct {
+	// Status indicates the result of the Undelete operation. It carries the KeyNotFound
+	// code if the key has no tombstone, and the KeyExists code if the key is present.
+	Status               *Status  `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetChangesResponse) Reset()         { *m = GetChangesResponse{} }
-func (m *GetChangesResponse) String() string { return proto.CompactTextString(m) }
-func (*GetChangesResponse) ProtoMessage()    {}
-func (*GetChangesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{8}
+func (m *UndeleteResponse) Reset()         { *m = UndeleteResponse{} }
+func (m *UndeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*UndeleteResponse) ProtoMessage()    {}
+func (*UndeleteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{9}
 }
 
-func (m *GetChangesResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetChangesResponse.Unmarshal(m, b)
+func (m *UndeleteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UndeleteResponse.Unmarshal(m, b)
 }
-func (m *GetChangesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetChangesResponse.Marshal(b, m, deterministic)
+func (m *UndeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UndeleteResponse.Marshal(b, m, deterministic)
 }
-func (m *GetChangesResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetChangesResponse.Merge(m, src)
+func (m *UndeleteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UndeleteResponse.Merge(m, src)
 }
-func (m *GetChangesResponse) XXX_Size() int {
-	return xxx_messageInfo_GetChangesResponse.Size(m)
+func (m *UndeleteResponse) XXX_Size() int {
+	return xxx_messageInfo_UndeleteResponse.Size(m)
 }
-func (m *GetChangesResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetChangesResponse.DiscardUnknown(m)
+func (m *UndeleteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UndeleteResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetChangesResponse proto.InternalMessageInfo
+var xxx_messageInfo_UndeleteResponse proto.InternalMessageInfo
 
-func (m *GetChangesResponse) GetStatus() *Status {
+func (m *UndeleteResponse) GetStatus() *Status {
 	if m != nil {
 		return m.Status
 	}
 	return nil
 }
 
-func (m *GetChangesResponse) GetMasterChangeNumber() uint64 {
-	if m != nil {
-		return m.MasterChangeNumber
-	}
-	return 0
-}
-
-func (m *GetChangesResponse) GetNumberOfChanges() uint32 {
-	if m != nil {
-		return m.NumberOfChanges
-	}
-	return 0
-}
-
-func (m *GetChangesResponse) GetChanges() []*ChangeRecord {
-	if m != nil {
-		return m.Changes
-	}
-	return nil
-}
-
-type ChangeRecord struct {
-	// SerialisedForm is the internal byte array representation of this change record
-	SerialisedForm []byte `protobuf:"bytes,1,opt,name=serialisedForm,proto3" json:"serialisedForm,omitempty"`
-	// ChangeNumber indicates the change number of this change record
-	ChangeNumber uint64 `protobuf:"varint,2,opt,name=changeNumber,proto3" json:"changeNumber,omitempty"`
-	// NumberOfTrxns indicates the number of transactions associated with this change record
-	NumberOfTrxns uint32 `protobuf:"varint,3,opt,name=numberOfTrxns,proto3" json:"numberOfTrxns,omitempty"`
-	// Trxns is the collection of transaction records associated with this change record
-	Trxns                []*TrxnRecord `protobuf:"bytes,4,rep,name=trxns,proto3" json:"trxns,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+type GetRequest struct {
+	// Key is the key, in bytes, whose associated value is loaded from the key value store.
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// MaxLag, if positive, is the maximum number of changes by which a slave node
+	// can lag behind its master node for serving this read. Ignored by master nodes.
+	MaxLag uint64 `protobuf:"varint,2,opt,name=maxLag,proto3" json:"maxLag,omitempty"`
+	// Namespace is the namespace of the key, which isolates it from the keys of all the
+	// other namespaces. The default namespace is used when it is empty.
+	Namespace string `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// ReadConsistency is the consistency of the read, defaulting to Sequential.
+	// Linearizable reads are rejected by slave nodes with LinearizableReadUnsupported.
+	ReadConsistency ReadConsistency `protobuf:"varint,4,opt,name=readConsistency,proto3,enum=dkv.serverpb.ReadConsistency" json:"readConsistency,omitempty"`
+	// LagBounded bounds the lag by MaxLag even when it is zero, in which case only
+	// the slave nodes that have caught up with their master node serve this read.
+	LagBounded           bool     `protobuf:"varint,5,opt,name=lagBounded,proto3" json:"lagBounded,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ChangeRecord) Reset()         { *m = ChangeRecord{} }
-func (m *ChangeRecord) String() string { return proto.CompactTextString(m) }
-func (*ChangeRecord) ProtoMessage()    {}
-func (*ChangeRecord) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{9}
+func (m *GetRequest) Reset()         { *m = GetRequest{} }
+func (m *GetRequest) String() string { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()    {}
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{10}
 }
 
-func (m *ChangeRecord) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ChangeRecord.Unmarshal(m, b)
+func (m *GetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetRequest.Unmarshal(m, b)
 }
-func (m *ChangeRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ChangeRecord.Marshal(b, m, deterministic)
+func (m *GetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetRequest.Marshal(b, m, deterministic)
 }
-func (m *ChangeRecord) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ChangeRecord.Merge(m, src)
+func (m *GetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetRequest.Merge(m, src)
 }
-func (m *ChangeRecord) XXX_Size() int {
-	return xxx_messageInfo_ChangeRecord.Size(m)
+func (m *GetRequest) XXX_Size() int {
+	return xxx_messageInfo_GetRequest.Size(m)
 }
-func (m *ChangeRecord) XXX_DiscardUnknown() {
-	xxx_messageInfo_ChangeRecord.DiscardUnknown(m)
+func (m *GetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ChangeRecord proto.InternalMessageInfo
+var xxx_messageInfo_GetRequest proto.InternalMessageInfo
 
-func (m *ChangeRecord) GetSerialisedForm() []byte {
+func (m *GetRequest) GetKey() []byte {
 	if m != nil {
-		return m.SerialisedForm
+		return m.Key
 	}
 	return nil
 }
 
-func (m *ChangeRecord) GetChangeNumber() uint64 {
+func (m *GetRequest) GetMaxLag() uint64 {
 	if m != nil {
-		return m.ChangeNumber
+		return m.MaxLag
 	}
 	return 0
 }
 
-func (m *ChangeRecord) GetNumberOfTrxns() uint32 {
+func (m *GetRequest) GetNamespace() string {
 	if m != nil {
-		return m.NumberOfTrxns
+		return m.Namespace
 	}
-	return 0
+	return ""
 }
 
-func (m *ChangeRecord) GetTrxns() []*TrxnRecord {
+func (m *GetRequest) GetReadConsistency() ReadConsistency {
 	if m != nil {
-		return m.Trxns
+		return m.ReadConsistency
 	}
-	return nil
+	return ReadConsistency_Sequential
 }
 
-type TrxnRecord struct {
-	// Type indicates the type of this transaction - Put, Delete, etc.
-	Type TrxnRecord_TrxnType `protobuf:"varint,1,opt,name=type,proto3,enum=dkv.serverpb.TrxnRecord_TrxnType" json:"type,omitempty"`
-	// Key is the byte array representation of the key associated with this transaction
-	Key []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
-	// Value is the byte array representation of the value associated with this transaction
-	Value                []byte   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+func (m *GetRequest) GetLagBounded() bool {
+	if m != nil {
+		return m.LagBounded
+	}
+	return false
+}
+
+type GetResponse struct {
+	// Status indicates the result of the Get operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Value is the value, in bytes, that is associated with the given key in the key value store.
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Found indicates whether the given key is present in the key value store,
+	// so that absent keys can be distinguished from keys with empty values.
+	Found                bool     `protobuf:"varint,3,opt,name=found,proto3" json:"found,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *TrxnRecord) Reset()         { *m = TrxnRecord{} }
-func (m *TrxnRecord) String() string { return proto.CompactTextString(m) }
-func (*TrxnRecord) ProtoMessage()    {}
-func (*TrxnRecord) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{10}
+func (m *GetResponse) Reset()         { *m = GetResponse{} }
+func (m *GetResponse) String() string { return proto.CompactTextString(m) }
+func (*GetResponse) ProtoMessage()    {}
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{11}
 }
 
-func (m *TrxnRecord) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_TrxnRecord.Unmarshal(m, b)
+func (m *GetResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetResponse.Unmarshal(m, b)
 }
-func (m *TrxnRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_TrxnRecord.Marshal(b, m, deterministic)
+func (m *GetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetResponse.Marshal(b, m, deterministic)
 }
-func (m *TrxnRecord) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_TrxnRecord.Merge(m, src)
+func (m *GetResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetResponse.Merge(m, src)
 }
-func (m *TrxnRecord) XXX_Size() int {
-	return xxx_messageInfo_TrxnRecord.Size(m)
+func (m *GetResponse) XXX_Size() int {
+	return xxx_messageInfo_GetResponse.Size(m)
 }
-func (m *TrxnRecord) XXX_DiscardUnknown() {
-	xxx_messageInfo_TrxnRecord.DiscardUnknown(m)
+func (m *GetResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_TrxnRecord proto.InternalMessageInfo
+var xxx_messageInfo_GetResponse proto.InternalMessageInfo
 
-func (m *TrxnRecord) GetType() TrxnRecord_TrxnType {
+func (m *GetResponse) GetStatus() *Status {
 	if m != nil {
-		return m.Type
+		return m.Status
 	}
-	return TrxnRecord_Unknown
+	return nil
 }
 
-func (m *TrxnRecord) GetKey() []byte {
+func (m *GetResponse) GetValue() []byte {
 	if m != nil {
-		return m.Key
+		return m.Value
 	}
 	return nil
 }
 
-func (m *TrxnRecord) GetValue() []byte {
+func (m *GetResponse) GetFound() bool {
 	if m != nil {
-		return m.Value
+		return m.Found
 	}
-	return nil
+	return false
 }
 
-type BackupRequest struct {
-	// BackupPath indicates a filesystem folder or file used for backing up the keyspace.
-	BackupPath           string   `protobuf:"bytes,1,opt,name=backupPath,proto3" json:"backupPath,omitempty"`
+type MultiGetRequest struct {
+	// Keys is the collection of keys whose values are returned from the bulk Get operation.
+	Keys [][]byte `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	// MaxLag, if positive, is the maximum number of changes by which a slave node
+	// can lag behind its master node for serving this read. Ignored by master nodes.
+	MaxLag uint64 `protobuf:"varint,2,opt,name=maxLag,proto3" json:"maxLag,omitempty"`
+	// Namespace is the namespace of the keys. The default namespace is used when it is empty.
+	Namespace string `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Detailed responds with the results of each of the keys in place of their values, so
+	// that the keys failing to be read do not fail the reads of the others.
+	Detailed bool `protobuf:"varint,4,opt,name=detailed,proto3" json:"detailed,omitempty"`
+	// LagBounded bounds the lag by MaxLag even when it is zero, in which case only
+	// the slave nodes that have caught up with their master node serve this read.
+	LagBounded           bool     `protobuf:"varint,5,opt,name=lagBounded,proto3" json:"lagBounded,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *BackupRequest) Reset()         { *m = BackupRequest{} }
-func (m *BackupRequest) String() string { return proto.CompactTextString(m) }
-func (*BackupRequest) ProtoMessage()    {}
-func (*BackupRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{11}
+func (m *MultiGetRequest) Reset()         { *m = MultiGetRequest{} }
+func (m *MultiGetRequest) String() string { return proto.CompactTextString(m) }
+func (*MultiGetRequest) ProtoMessage()    {}
+func (*MultiGetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{12}
 }
 
-func (m *BackupRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_BackupRequest.Unmarshal(m, b)
+func (m *MultiGetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultiGetRequest.Unmarshal(m, b)
 }
-func (m *BackupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_BackupRequest.Marshal(b, m, deterministic)
+func (m *MultiGetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultiGetRequest.Marshal(b, m, deterministic)
 }
-func (m *BackupRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_BackupRequest.Merge(m, src)
+func (m *MultiGetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultiGetRequest.Merge(m, src)
 }
-func (m *BackupRequest) XXX_Size() int {
-	return xxx_messageInfo_BackupRequest.Size(m)
+func (m *MultiGetRequest) XXX_Size() int {
+	return xxx_messageInfo_MultiGetRequest.Size(m)
 }
-func (m *BackupRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_BackupRequest.DiscardUnknown(m)
+func (m *MultiGetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultiGetRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_BackupRequest proto.InternalMessageInfo
+var xxx_messageInfo_MultiGetRequest proto.InternalMessageInfo
 
-func (m *BackupRequest) GetBackupPath() string {
+func (m *MultiGetRequest) GetKeys() [][]byte {
 	if m != nil {
-		return m.BackupPath
+		return m.Keys
 	}
-	return ""
+	return nil
 }
 
-type RestoreRequest struct {
-	// RestorePath indicates a filesystem folder or file used for restoring the keyspace.
-	RestorePath          string   `protobuf:"bytes,1,opt,name=restorePath,proto3" json:"restorePath,omitempty"`
+func (m *MultiGetRequest) GetMaxLag() uint64 {
+	if m != nil {
+		return m.MaxLag
+	}
+	return 0
+}
+
+func (m *MultiGetRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *MultiGetRequest) GetDetailed() bool {
+	if m != nil {
+		return m.Detailed
+	}
+	return false
+}
+
+func (m *MultiGetRequest) GetLagBounded() bool {
+	if m != nil {
+		return m.LagBounded
+	}
+	return false
+}
+
+type MultiGetResponse struct {
+	// Status indicates the result of the bulk Get operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Values are the individual responses of the bulk Get operation.
+	Values [][]byte `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
+	// Found indicates the presence of each of the given keys, in the order of the request.
+	Found []bool `protobuf:"varint,3,rep,packed,name=found,proto3" json:"found,omitempty"`
+	// Results are the results of each of the given keys, in the order of the request,
+	// which are set in place of the values and found flags for detailed requests. The
+	// status of the bulk Get operation is then set only if it fails as a whole.
+	Results              []*KVResult `protobuf:"bytes,4,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *MultiGetResponse) Reset()         { *m = MultiGetResponse{} }
+func (m *MultiGetResponse) String() string { return proto.CompactTextString(m) }
+func (*MultiGetResponse) ProtoMessage()    {}
+func (*MultiGetResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{13}
+}
+
+func (m *MultiGetResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultiGetResponse.Unmarshal(m, b)
+}
+func (m *MultiGetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultiGetResponse.Marshal(b, m, deterministic)
+}
+func (m *MultiGetResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultiGetResponse.Merge(m, src)
+}
+func (m *MultiGetResponse) XXX_Size() int {
+	return xxx_messageInfo_MultiGetResponse.Size(m)
+}
+func (m *MultiGetResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultiGetResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MultiGetResponse proto.InternalMessageInfo
+
+func (m *MultiGetResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *MultiGetResponse) GetValues() [][]byte {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *MultiGetResponse) GetFound() []bool {
+	if m != nil {
+		return m.Found
+	}
+	return nil
+}
+
+func (m *MultiGetResponse) GetResults() []*KVResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type KVResult struct {
+	// Status indicates the result of reading the key.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Value is the value, in bytes, that is associated with the key.
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Found indicates whether the key is present in the key value store.
+	Found                bool     `protobuf:"varint,3,opt,name=found,proto3" json:"found,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *RestoreRequest) Reset()         { *m = RestoreRequest{} }
-func (m *RestoreRequest) String() string { return proto.CompactTextString(m) }
-func (*RestoreRequest) ProtoMessage()    {}
-func (*RestoreRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{12}
+func (m *KVResult) Reset()         { *m = KVResult{} }
+func (m *KVResult) String() string { return proto.CompactTextString(m) }
+func (*KVResult) ProtoMessage()    {}
+func (*KVResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{14}
 }
 
-func (m *RestoreRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RestoreRequest.Unmarshal(m, b)
+func (m *KVResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KVResult.Unmarshal(m, b)
 }
-func (m *RestoreRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RestoreRequest.Marshal(b, m, deterministic)
+func (m *KVResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KVResult.Marshal(b, m, deterministic)
 }
-func (m *RestoreRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RestoreRequest.Merge(m, src)
+func (m *KVResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KVResult.Merge(m, src)
 }
-func (m *RestoreRequest) XXX_Size() int {
-	return xxx_messageInfo_RestoreRequest.Size(m)
+func (m *KVResult) XXX_Size() int {
+	return xxx_messageInfo_KVResult.Size(m)
 }
-func (m *RestoreRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_RestoreRequest.DiscardUnknown(m)
+func (m *KVResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_KVResult.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_RestoreRequest proto.InternalMessageInfo
+var xxx_messageInfo_KVResult proto.InternalMessageInfo
 
-func (m *RestoreRequest) GetRestorePath() string {
+func (m *KVResult) GetStatus() *Status {
 	if m != nil {
-		return m.RestorePath
+		return m.Status
 	}
-	return ""
+	return nil
 }
 
-type AddNodeRequest struct {
-	// NodeId represents the identifier of the node that needs to
-	// be added to the cluster.
-	NodeId uint32 `protobuf:"varint,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
-	// NodeUrl represents the URL for the Nexus service running on
-	// the node that needs to be added to the cluster. Other Nexus
-	// peers communicate with this node on this service.
-	NodeUrl              string   `protobuf:"bytes,2,opt,name=nodeUrl,proto3" json:"nodeUrl,omitempty"`
+func (m *KVResult) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *KVResult) GetFound() bool {
+	if m != nil {
+		return m.Found
+	}
+	return false
+}
+
+type ExistsRequest struct {
+	// Keys is the collection of keys whose presence is checked in the key value store.
+	Keys [][]byte `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	// Namespace is the namespace of the keys. The default namespace is used when it is empty.
+	Namespace            string   `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *AddNodeRequest) Reset()         { *m = AddNodeRequest{} }
-func (m *AddNodeRequest) String() string { return proto.CompactTextString(m) }
-func (*AddNodeRequest) ProtoMessage()    {}
-func (*AddNodeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{13}
+func (m *ExistsRequest) Reset()         { *m = ExistsRequest{} }
+func (m *ExistsRequest) String() string { return proto.CompactTextString(m) }
+func (*ExistsRequest) ProtoMessage()    {}
+func (*ExistsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{15}
 }
 
-func (m *AddNodeRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_AddNodeRequest.Unmarshal(m, b)
+func (m *ExistsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExistsRequest.Unmarshal(m, b)
 }
-func (m *AddNodeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_AddNodeRequest.Marshal(b, m, deterministic)
+func (m *ExistsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExistsRequest.Marshal(b, m, deterministic)
 }
-func (m *AddNodeRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_AddNodeRequest.Merge(m, src)
+func (m *ExistsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExistsRequest.Merge(m, src)
 }
-func (m *AddNodeRequest) XXX_Size() int {
-	return xxx_messageInfo_AddNodeRequest.Size(m)
+func (m *ExistsRequest) XXX_Size() int {
+	return xxx_messageInfo_ExistsRequest.Size(m)
 }
-func (m *AddNodeRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_AddNodeRequest.DiscardUnknown(m)
+func (m *ExistsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExistsRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_AddNodeRequest proto.InternalMessageInfo
+var xxx_messageInfo_ExistsRequest proto.InternalMessageInfo
 
-func (m *AddNodeRequest) GetNodeId() uint32 {
+func (m *ExistsRequest) GetKeys() [][]byte {
 	if m != nil {
-		return m.NodeId
+		return m.Keys
 	}
-	return 0
+	return nil
 }
 
-func (m *AddNodeRequest) GetNodeUrl() string {
+func (m *ExistsRequest) GetNamespace() string {
 	if m != nil {
-		return m.NodeUrl
+		return m.Namespace
 	}
 	return ""
 }
 
-type RemoveNodeRequest struct {
-	// NodeId represents the identifier of the node that needs to
-	// be removed from the cluster.
-	NodeId               uint32   `protobuf:"varint,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+type ExistsResponse struct {
+	// Status indicates the result of the Exists operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Exists indicates the presence of every given key, in the same order as the keys.
+	Exists               []bool   `protobuf:"varint,2,rep,packed,name=exists,proto3" json:"exists,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *RemoveNodeRequest) Reset()         { *m = RemoveNodeRequest{} }
-func (m *RemoveNodeRequest) String() string { return proto.CompactTextString(m) }
-func (*RemoveNodeRequest) ProtoMessage()    {}
-func (*RemoveNodeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ac913527469ef71, []int{14}
+func (m *ExistsResponse) Reset()         { *m = ExistsResponse{} }
+func (m *ExistsResponse) String() string { return proto.CompactTextString(m) }
+func (*ExistsResponse) ProtoMessage()    {}
+func (*ExistsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{16}
 }
 
-func (m *RemoveNodeRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RemoveNodeRequest.Unmarshal(m, b)
+func (m *ExistsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExistsResponse.Unmarshal(m, b)
 }
-func (m *RemoveNodeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RemoveNodeRequest.Marshal(b, m, deterministic)
+func (m *ExistsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExistsResponse.Marshal(b, m, deterministic)
 }
-func (m *RemoveNodeRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RemoveNodeRequest.Merge(m, src)
+func (m *ExistsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExistsResponse.Merge(m, src)
 }
-func (m *RemoveNodeRequest) XXX_Size() int {
-	return xxx_messageInfo_RemoveNodeRequest.Size(m)
+func (m *ExistsResponse) XXX_Size() int {
+	return xxx_messageInfo_ExistsResponse.Size(m)
 }
-func (m *RemoveNodeRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_RemoveNodeRequest.DiscardUnknown(m)
+func (m *ExistsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExistsResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_RemoveNodeRequest proto.InternalMessageInfo
+var xxx_messageInfo_ExistsResponse proto.InternalMessageInfo
 
-func (m *RemoveNodeRequest) GetNodeId() uint32 {
+func (m *ExistsResponse) GetStatus() *Status {
 	if m != nil {
-		return m.NodeId
+		return m.Status
 	}
-	return 0
+	return nil
 }
 
-func init() {
-	proto.RegisterEnum("dkv.serverpb.TrxnRecord_TrxnType", TrxnRecord_TrxnType_name, TrxnRecord_TrxnType_value)
-	proto.RegisterType((*Status)(nil), "dkv.serverpb.Status")
-	proto.RegisterType((*PutRequest)(nil), "dkv.serverpb.PutRequest")
-	proto.RegisterType((*PutResponse)(nil), "dkv.serverpb.PutResponse")
-	proto.RegisterType((*GetRequest)(nil), "dkv.serverpb.GetRequest")
-	proto.RegisterType((*GetResponse)(nil), "dkv.serverpb.GetResponse")
-	proto.RegisterType((*MultiGetRequest)(nil), "dkv.serverpb.MultiGetRequest")
-	proto.RegisterType((*MultiGetResponse)(nil), "dkv.serverpb.MultiGetResponse")
-	proto.RegisterType((*GetChangesRequest)(nil), "dkv.serverpb.GetChangesRequest")
-	proto.RegisterType((*GetChangesResponse)(nil), "dkv.serverpb.GetChangesResponse")
-	proto.RegisterType((*ChangeRecord)(nil), "dkv.serverpb.ChangeRecord")
-	proto.RegisterType((*TrxnRecord)(nil), "dkv.serverpb.TrxnRecord")
-	proto.RegisterType((*BackupRequest)(nil), "dkv.serverpb.BackupRequest")
-	proto.RegisterType((*RestoreRequest)(nil), "dkv.serverpb.RestoreRequest")
-	proto.RegisterType((*AddNodeRequest)(nil), "dkv.serverpb.AddNodeRequest")
-	proto.RegisterType((*RemoveNodeRequest)(nil), "dkv.serverpb.RemoveNodeRequest")
+func (m *ExistsResponse) GetExists() []bool {
+	if m != nil {
+		return m.Exists
+	}
+	return nil
 }
 
-func init() { proto.RegisterFile("pkg/serverpb/api.proto", fileDescriptor_8ac913527469ef71) }
+type CompareAndSetRequest struct {
+	// Key is the key, in bytes, whose value is compared and set.
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// ExpectedValue is the value, in bytes, that the current value of the key
+	// must match for the new value to be set. An empty expected value means
+	// the new value is set only if the key is absent.
+	ExpectedValue []byte `protobuf:"bytes,2,opt,name=expectedValue,proto3" json:"expectedValue,omitempty"`
+	// NewValue is the value, in bytes, to associate with the key.
+	NewValue []byte `protobuf:"bytes,3,opt,name=newValue,proto3" json:"newValue,omitempty"`
+	// Namespace is the namespace of the key, which isolates it from the keys of all the
+	// other namespaces. The default namespace is used when it is empty.
+	Namespace            string   `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-var fileDescriptor_8ac913527469ef71 = []byte{
-	// 728 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xc6, 0x71, 0x9a, 0xb4, 0x93, 0x9f, 0xa6, 0xa3, 0xaa, 0x0a, 0x01, 0x4a, 0x58, 0x01, 0x8a,
-	0xa0, 0x4a, 0xa5, 0x50, 0x38, 0xb4, 0xe2, 0x40, 0x1b, 0x51, 0x55, 0x11, 0x6d, 0x59, 0xda, 0x08,
-	0x71, 0x73, 0xe2, 0x69, 0x1b, 0x25, 0xb1, 0x8d, 0xbd, 0x0e, 0xcd, 0x4b, 0x20, 0xae, 0xbc, 0x05,
-	0x8f, 0xc1, 0x99, 0x27, 0x42, 0x59, 0x7b, 0x89, 0xed, 0x38, 0x12, 0xea, 0x6d, 0xe7, 0x9b, 0xef,
-	0x9b, 0xf9, 0x36, 0x9e, 0x9d, 0xc0, 0x96, 0x33, 0xbc, 0xde, 0xf5, 0xc8, 0x9d, 0x90, 0xeb, 0xf4,
-	0x76, 0x0d, 0x67, 0xd0, 0x74, 0x5c, 0x5b, 0xd8, 0x58, 0x34, 0x87, 0x93, 0xa6, 0xc2, 0xd9, 0x1b,
-	0xc8, 0x7d, 0x12, 0x86, 0xf0, 0x3d, 0x44, 0xc8, 0xf6, 0x6d, 0x93, 0xaa, 0x5a, 0x5d, 0x6b, 0xac,
-	0x70, 0x79, 0xc6, 0x2a, 0xe4, 0xc7, 0xe4, 0x79, 0xc6, 0x35, 0x55, 0x33, 0x75, 0xad, 0xb1, 0xc6,
-	0x55, 0xc8, 0xf6, 0x00, 0xce, 0x7d, 0xc1, 0xe9, 0xab, 0x4f, 0x9e, 0xc0, 0x0a, 0xe8, 0x43, 0x9a,
-	0x4a, 0x69, 0x91, 0xcf, 0x8e, 0xb8, 0x09, 0x2b, 0x13, 0x63, 0xe4, 0x07, 0xba, 0x22, 0x0f, 0x02,
-	0x76, 0x00, 0x05, 0xa9, 0xf2, 0x1c, 0xdb, 0xf2, 0x08, 0x77, 0x20, 0xe7, 0xc9, 0xe6, 0x52, 0x59,
-	0x68, 0x6d, 0x36, 0xa3, 0xde, 0x9a, 0x81, 0x31, 0x1e, 0x72, 0xd8, 0x36, 0xc0, 0x31, 0x2d, 0x6f,
-	0xc9, 0x3e, 0x42, 0x41, 0xe6, 0xef, 0x52, 0x7c, 0x89, 0xdf, 0x67, 0xb0, 0xfe, 0xc1, 0x1f, 0x89,
-	0x41, 0xa4, 0x2f, 0x42, 0x76, 0x48, 0xd3, 0x59, 0x51, 0xbd, 0x51, 0xe4, 0xf2, 0xcc, 0x3e, 0x43,
-	0x65, 0x4e, 0xbb, 0x53, 0xfb, 0x2d, 0xc8, 0xc9, 0x8e, 0x5e, 0x35, 0x23, 0xeb, 0x86, 0x11, 0xb3,
-	0x61, 0xe3, 0x98, 0xc4, 0xd1, 0x8d, 0x61, 0x5d, 0x93, 0xa7, 0x2c, 0xbc, 0x80, 0xca, 0x95, 0x6b,
-	0x8f, 0x03, 0xf4, 0xd4, 0x1f, 0xf7, 0xc8, 0x95, 0x4d, 0xb2, 0x7c, 0x01, 0xc7, 0x26, 0xe0, 0xd8,
-	0xb8, 0x0d, 0x82, 0xb3, 0xab, 0xb0, 0x90, 0xbc, 0x64, 0x89, 0xa7, 0x64, 0xd8, 0x1f, 0x0d, 0x30,
-	0xda, 0xf1, 0x4e, 0xb7, 0x91, 0x4d, 0x3d, 0x41, 0x6e, 0xcc, 0x62, 0x46, 0x5a, 0x4c, 0xc9, 0x60,
-	0x03, 0xd6, 0xad, 0x84, 0x43, 0x5d, 0x3a, 0x4c, 0xc2, 0xb8, 0x07, 0xf9, 0x7e, 0xc8, 0xc8, 0xd6,
-	0xf5, 0x46, 0xa1, 0x55, 0x8b, 0x1b, 0x09, 0x78, 0x9c, 0xfa, 0xb6, 0x6b, 0x72, 0x45, 0x65, 0xbf,
-	0x34, 0x28, 0x46, 0x33, 0xf8, 0x1c, 0xca, 0x1e, 0xb9, 0x03, 0x63, 0x34, 0xf0, 0xc8, 0x7c, 0x6f,
-	0xbb, 0xe3, 0x70, 0x8e, 0x12, 0x28, 0x32, 0x28, 0xf6, 0x17, 0xaf, 0x10, 0xc3, 0xf0, 0x29, 0x94,
-	0x94, 0xcb, 0x0b, 0xf7, 0xd6, 0x52, 0xd6, 0xe3, 0x20, 0x36, 0x61, 0x45, 0xc8, 0x6c, 0x60, 0xbb,
-	0x1a, 0xb7, 0x3d, 0xe3, 0x84, 0xa6, 0x03, 0x1a, 0xfb, 0xa9, 0x01, 0xcc, 0x51, 0x7c, 0x0d, 0x59,
-	0x31, 0x75, 0x82, 0xc7, 0x59, 0x6e, 0x3d, 0x59, 0xa6, 0x96, 0xc7, 0x8b, 0xa9, 0x43, 0x5c, 0xd2,
-	0xd5, 0x23, 0xc9, 0xa4, 0xbc, 0x4b, 0x3d, 0x3a, 0xe7, 0x3b, 0xb0, 0xaa, 0x94, 0x58, 0x80, 0xfc,
-	0xa5, 0x35, 0xb4, 0xec, 0x6f, 0x56, 0xe5, 0x1e, 0xe6, 0x41, 0x3f, 0xf7, 0x45, 0x45, 0x43, 0x80,
-	0x5c, 0x9b, 0x46, 0x24, 0xa8, 0x92, 0x61, 0xbb, 0x50, 0x3a, 0x34, 0xfa, 0x43, 0xdf, 0x51, 0x03,
-	0xb9, 0x0d, 0xd0, 0x93, 0xc0, 0xb9, 0x21, 0x6e, 0xa4, 0xc7, 0x35, 0x1e, 0x41, 0x58, 0x0b, 0xca,
-	0x9c, 0x3c, 0x61, 0xbb, 0xa4, 0x14, 0x75, 0x28, 0xb8, 0x01, 0x12, 0x91, 0x44, 0x21, 0x76, 0x08,
-	0xe5, 0x77, 0xa6, 0x79, 0x6a, 0x9b, 0xff, 0x34, 0x5b, 0x90, 0xb3, 0x6c, 0x93, 0x4e, 0x4c, 0x49,
-	0x2f, 0xf1, 0x30, 0x9a, 0x2d, 0xa9, 0xd9, 0xe9, 0xd2, 0x1d, 0xa9, 0x25, 0x15, 0x86, 0xec, 0x25,
-	0x6c, 0x70, 0x1a, 0xdb, 0x13, 0xfa, 0x8f, 0x32, 0xad, 0xdf, 0x1a, 0xe8, 0xed, 0x4e, 0x17, 0xf7,
-	0xe5, 0x95, 0x31, 0xf1, 0x85, 0xe6, 0xcb, 0xae, 0x76, 0x3f, 0x25, 0x13, 0x3e, 0x93, 0x7d, 0xd0,
-	0x8f, 0x69, 0x41, 0x3b, 0xdf, 0x1e, 0x49, 0x6d, 0x74, 0x61, 0x9c, 0xc0, 0xaa, 0x5a, 0x22, 0xf8,
-	0x28, 0x4e, 0x4b, 0xec, 0xa0, 0xda, 0xf6, 0xb2, 0x74, 0x50, 0xaa, 0x65, 0x40, 0xb9, 0xdd, 0xe9,
-	0x72, 0x72, 0x46, 0x83, 0xbe, 0x21, 0x06, 0xb6, 0x85, 0x67, 0x72, 0x77, 0xaa, 0x57, 0xf4, 0x78,
-	0xc1, 0x45, 0x7c, 0xc3, 0xd4, 0xea, 0xcb, 0x09, 0x61, 0x8b, 0xef, 0x1a, 0x54, 0xda, 0x9d, 0xae,
-	0x9a, 0x03, 0xf9, 0xdd, 0xf0, 0x00, 0x72, 0x01, 0x80, 0x0f, 0xe2, 0x05, 0x62, 0xe3, 0x52, 0x4b,
-	0x5d, 0x1e, 0xf8, 0x16, 0xf2, 0xaa, 0xce, 0xc3, 0x38, 0x21, 0x3e, 0x3b, 0xe9, 0xf2, 0xd6, 0x0f,
-	0x0d, 0xa0, 0xdd, 0xe9, 0x1e, 0x8d, 0xfc, 0xd9, 0x7a, 0x99, 0x55, 0x0b, 0xc7, 0x27, 0x59, 0x2d,
-	0x3e, 0x55, 0x4b, 0xcc, 0x1c, 0x01, 0xcc, 0x27, 0x27, 0xf9, 0x7b, 0x2d, 0xcc, 0x54, 0x7a, 0x91,
-	0x43, 0xf8, 0xb2, 0xaa, 0xa0, 0x5e, 0x4e, 0xfe, 0xf9, 0xbe, 0xfa, 0x1b, 0x00, 0x00, 0xff, 0xff,
-	0x1d, 0xc3, 0x86, 0x08, 0x96, 0x07, 0x00, 0x00,
+func (m *CompareAndSetRequest) Reset()         { *m = CompareAndSetRequest{} }
+func (m *CompareAndSetRequest) String() string { return proto.CompactTextString(m) }
+func (*CompareAndSetRequest) ProtoMessage()    {}
+func (*CompareAndSetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{17}
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *CompareAndSetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompareAndSetRequest.Unmarshal(m, b)
+}
+func (m *CompareAndSetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompareAndSetRequest.Marshal(b, m, deterministic)
+}
+func (m *CompareAndSetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompareAndSetRequest.Merge(m, src)
+}
+func (m *CompareAndSetRequest) XXX_Size() int {
+	return xxx_messageInfo_CompareAndSetRequest.Size(m)
+}
+func (m *CompareAndSetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompareAndSetRequest.DiscardUnknown(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_CompareAndSetRequest proto.InternalMessageInfo
 
-// DKVClient is the client API for DKV service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type DKVClient interface {
-	// Put puts the given key into the key value store
-	Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error)
-	// Get gets the value associated with the given key from the key value store
-	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
-	// MultiGet gets all the values associated with the given keys from the key value store
-	MultiGet(ctx context.Context, in *MultiGetRequest, opts ...grpc.CallOption) (*MultiGetResponse, error)
+func (m *CompareAndSetRequest) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *CompareAndSetRequest) GetExpectedValue() []byte {
+	if m != nil {
+		return m.ExpectedValue
+	}
+	return nil
+}
+
+func (m *CompareAndSetRequest) GetNewValue() []byte {
+	if m != nil {
+		return m.NewValue
+	}
+	return nil
+}
+
+func (m *CompareAndSetRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type CompareAndSetResponse struct {
+	// Status indicates the result of the CompareAndSet operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Updated indicates whether the new value has been set against the key.
+	Updated              bool     `protobuf:"varint,2,opt,name=updated,proto3" json:"updated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompareAndSetResponse) Reset()         { *m = CompareAndSetResponse{} }
+func (m *CompareAndSetResponse) String() string { return proto.CompactTextString(m) }
+func (*CompareAndSetResponse) ProtoMessage()    {}
+func (*CompareAndSetResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{18}
+}
+
+func (m *CompareAndSetResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompareAndSetResponse.Unmarshal(m, b)
+}
+func (m *CompareAndSetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompareAndSetResponse.Marshal(b, m, deterministic)
+}
+func (m *CompareAndSetResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompareAndSetResponse.Merge(m, src)
+}
+func (m *CompareAndSetResponse) XXX_Size() int {
+	return xxx_messageInfo_CompareAndSetResponse.Size(m)
+}
+func (m *CompareAndSetResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompareAndSetResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompareAndSetResponse proto.InternalMessageInfo
+
+func (m *CompareAndSetResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *CompareAndSetResponse) GetUpdated() bool {
+	if m != nil {
+		return m.Updated
+	}
+	return false
+}
+
+type IncrementRequest struct {
+	// Key is the key, in bytes, whose value is incremented. The value is
+	// interpreted as a big-endian encoded 64 bit integer and is considered
+	// to be zero if the key is absent.
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Delta is the amount by which the value is incremented. A negative
+	// delta decrements the value.
+	Delta int64 `protobuf:"varint,2,opt,name=delta,proto3" json:"delta,omitempty"`
+	// Namespace is the namespace of the key, which isolates it from the keys of all the
+	// other namespaces. The default namespace is used when it is empty.
+	Namespace            string   `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IncrementRequest) Reset()         { *m = IncrementRequest{} }
+func (m *IncrementRequest) String() string { return proto.CompactTextString(m) }
+func (*IncrementRequest) ProtoMessage()    {}
+func (*IncrementRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{19}
+}
+
+func (m *IncrementRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IncrementRequest.Unmarshal(m, b)
+}
+func (m *IncrementRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IncrementRequest.Marshal(b, m, deterministic)
+}
+func (m *IncrementRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IncrementRequest.Merge(m, src)
+}
+func (m *IncrementRequest) XXX_Size() int {
+	return xxx_messageInfo_IncrementRequest.Size(m)
+}
+func (m *IncrementRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_IncrementRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IncrementRequest proto.InternalMessageInfo
+
+func (m *IncrementRequest) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *IncrementRequest) GetDelta() int64 {
+	if m != nil {
+		return m.Delta
+	}
+	return 0
+}
+
+func (m *IncrementRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type IncrementResponse struct {
+	// Status indicates the result of the Increment operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Value is the value of the key after the increment.
+	Value                int64    `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IncrementResponse) Reset()         { *m = IncrementResponse{} }
+func (m *IncrementResponse) String() string { return proto.CompactTextString(m) }
+func (*IncrementResponse) ProtoMessage()    {}
+func (*IncrementResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{20}
+}
+
+func (m *IncrementResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IncrementResponse.Unmarshal(m, b)
+}
+func (m *IncrementResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IncrementResponse.Marshal(b, m, deterministic)
+}
+func (m *IncrementResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IncrementResponse.Merge(m, src)
+}
+func (m *IncrementResponse) XXX_Size() int {
+	return xxx_messageInfo_IncrementResponse.Size(m)
+}
+func (m *IncrementResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_IncrementResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IncrementResponse proto.InternalMessageInfo
+
+func (m *IncrementResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *IncrementResponse) GetValue() int64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type TxnCondition struct {
+	// Type indicates the check performed on the key - Equals, Absent, etc.
+	Type TxnCondition_Type `protobuf:"varint,1,opt,name=type,proto3,enum=dkv.serverpb.TxnCondition_Type" json:"type,omitempty"`
+	// Key is the key, in bytes, whose current value is checked.
+	Key []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	// Value is the value, in bytes, that the key must be associated with for
+	// the Equals check to hold.
+	Value                []byte   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TxnCondition) Reset()         { *m = TxnCondition{} }
+func (m *TxnCondition) String() string { return proto.CompactTextString(m) }
+func (*TxnCondition) ProtoMessage()    {}
+func (*TxnCondition) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{21}
+}
+
+func (m *TxnCondition) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxnCondition.Unmarshal(m, b)
+}
+func (m *TxnCondition) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxnCondition.Marshal(b, m, deterministic)
+}
+func (m *TxnCondition) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxnCondition.Merge(m, src)
+}
+func (m *TxnCondition) XXX_Size() int {
+	return xxx_messageInfo_TxnCondition.Size(m)
+}
+func (m *TxnCondition) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxnCondition.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TxnCondition proto.InternalMessageInfo
+
+func (m *TxnCondition) GetType() TxnCondition_Type {
+	if m != nil {
+		return m.Type
+	}
+	return TxnCondition_Equals
+}
+
+func (m *TxnCondition) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *TxnCondition) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type TxnRequest struct {
+	// Conditions are the checks that must all hold for the thenMutations to be
+	// applied. No conditions always hold.
+	Conditions []*TxnCondition `protobuf:"bytes,1,rep,name=conditions,proto3" json:"conditions,omitempty"`
+	// ThenMutations are the Put and Delete transactions applied when all the
+	// conditions hold. Puts with a non-zero expireTS expire at that time.
+	ThenMutations []*TrxnRecord `protobuf:"bytes,2,rep,name=thenMutations,proto3" json:"thenMutations,omitempty"`
+	// ElseMutations are the Put and Delete transactions applied otherwise.
+	ElseMutations []*TrxnRecord `protobuf:"bytes,3,rep,name=elseMutations,proto3" json:"elseMutations,omitempty"`
+	// Namespace is the namespace of all the keys, which isolates them from the keys of
+	// all the other namespaces. The default namespace is used when it is empty.
+	Namespace            string   `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TxnRequest) Reset()         { *m = TxnRequest{} }
+func (m *TxnRequest) String() string { return proto.CompactTextString(m) }
+func (*TxnRequest) ProtoMessage()    {}
+func (*TxnRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{22}
+}
+
+func (m *TxnRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxnRequest.Unmarshal(m, b)
+}
+func (m *TxnRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxnRequest.Marshal(b, m, deterministic)
+}
+func (m *TxnRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxnRequest.Merge(m, src)
+}
+func (m *TxnRequest) XXX_Size() int {
+	return xxx_messageInfo_TxnRequest.Size(m)
+}
+func (m *TxnRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxnRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TxnRequest proto.InternalMessageInfo
+
+func (m *TxnRequest) GetConditions() []*TxnCondition {
+	if m != nil {
+		return m.Conditions
+	}
+	return nil
+}
+
+func (m *TxnRequest) GetThenMutations() []*TrxnRecord {
+	if m != nil {
+		return m.ThenMutations
+	}
+	return nil
+}
+
+func (m *TxnRequest) GetElseMutations() []*TrxnRecord {
+	if m != nil {
+		return m.ElseMutations
+	}
+	return nil
+}
+
+func (m *TxnRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type TxnResponse struct {
+	// Status indicates the result of the Txn operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Succeeded indicates whether all the conditions held, and hence whether the
+	// thenMutations were applied rather than the elseMutations.
+	Succeeded            bool     `protobuf:"varint,2,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TxnResponse) Reset()         { *m = TxnResponse{} }
+func (m *TxnResponse) String() string { return proto.CompactTextString(m) }
+func (*TxnResponse) ProtoMessage()    {}
+func (*TxnResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{23}
+}
+
+func (m *TxnResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxnResponse.Unmarshal(m, b)
+}
+func (m *TxnResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxnResponse.Marshal(b, m, deterministic)
+}
+func (m *TxnResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxnResponse.Merge(m, src)
+}
+func (m *TxnResponse) XXX_Size() int {
+	return xxx_messageInfo_TxnResponse.Size(m)
+}
+func (m *TxnResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxnResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TxnResponse proto.InternalMessageInfo
+
+func (m *TxnResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *TxnResponse) GetSucceeded() bool {
+	if m != nil {
+		return m.Succeeded
+	}
+	return false
+}
+
+type IterateRequest struct {
+	// KeyPrefix is the prefix, in bytes, that every key of the iteration must match.
+	KeyPrefix []byte `protobuf:"bytes,1,opt,name=keyPrefix,proto3" json:"keyPrefix,omitempty"`
+	// StartKey is the optional key, in bytes, from which the iteration begins.
+	StartKey []byte `protobuf:"bytes,2,opt,name=startKey,proto3" json:"startKey,omitempty"`
+	// Namespace is the namespace whose keys are iterated, without those of any other
+	// namespace. The default namespace is used when it is empty.
+	Namespace string `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// KeysOnly streams the keys alone, without reading their values at all.
+	KeysOnly bool `protobuf:"varint,4,opt,name=keysOnly,proto3" json:"keysOnly,omitempty"`
+	// MaxValueSize is the maximum size, in bytes, of the values streamed, beyond which
+	// they are truncated. Zero indicates no limit.
+	MaxValueSize         uint32   `protobuf:"varint,5,opt,name=maxValueSize,proto3" json:"maxValueSize,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IterateRequest) Reset()         { *m = IterateRequest{} }
+func (m *IterateRequest) String() string { return proto.CompactTextString(m) }
+func (*IterateRequest) ProtoMessage()    {}
+func (*IterateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{24}
+}
+
+func (m *IterateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IterateRequest.Unmarshal(m, b)
+}
+func (m *IterateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IterateRequest.Marshal(b, m, deterministic)
+}
+func (m *IterateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IterateRequest.Merge(m, src)
+}
+func (m *IterateRequest) XXX_Size() int {
+	return xxx_messageInfo_IterateRequest.Size(m)
+}
+func (m *IterateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_IterateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IterateRequest proto.InternalMessageInfo
+
+func (m *IterateRequest) GetKeyPrefix() []byte {
+	if m != nil {
+		return m.KeyPrefix
+	}
+	return nil
+}
+
+func (m *IterateRequest) GetStartKey() []byte {
+	if m != nil {
+		return m.StartKey
+	}
+	return nil
+}
+
+func (m *IterateRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *IterateRequest) GetKeysOnly() bool {
+	if m != nil {
+		return m.KeysOnly
+	}
+	return false
+}
+
+func (m *IterateRequest) GetMaxValueSize() uint32 {
+	if m != nil {
+		return m.MaxValueSize
+	}
+	return 0
+}
+
+type IterateResponse struct {
+	// Status indicates the result of the Iterate operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Key is the key, in bytes, of the current entry of the iteration.
+	Key []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	// Value is the value, in bytes, associated with the current key of the iteration.
+	Value []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	// Truncated indicates that the value is truncated to MaxValueSize of the request.
+	Truncated            bool     `protobuf:"varint,4,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IterateResponse) Reset()         { *m = IterateResponse{} }
+func (m *IterateResponse) String() string { return proto.CompactTextString(m) }
+func (*IterateResponse) ProtoMessage()    {}
+func (*IterateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{25}
+}
+
+func (m *IterateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IterateResponse.Unmarshal(m, b)
+}
+func (m *IterateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IterateResponse.Marshal(b, m, deterministic)
+}
+func (m *IterateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IterateResponse.Merge(m, src)
+}
+func (m *IterateResponse) XXX_Size() int {
+	return xxx_messageInfo_IterateResponse.Size(m)
+}
+func (m *IterateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_IterateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IterateResponse proto.InternalMessageInfo
+
+func (m *IterateResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *IterateResponse) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *IterateResponse) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *IterateResponse) GetTruncated() bool {
+	if m != nil {
+		return m.Truncated
+	}
+	return false
+}
+
+type ListReplicasRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListReplicasRequest) Reset()         { *m = ListReplicasRequest{} }
+func (m *ListReplicasRequest) String() string { return proto.CompactTextString(m) }
+func (*ListReplicasRequest) ProtoMessage()    {}
+func (*ListReplicasRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{26}
+}
+
+func (m *ListReplicasRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReplicasRequest.Unmarshal(m, b)
+}
+func (m *ListReplicasRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReplicasRequest.Marshal(b, m, deterministic)
+}
+func (m *ListReplicasRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReplicasRequest.Merge(m, src)
+}
+func (m *ListReplicasRequest) XXX_Size() int {
+	return xxx_messageInfo_ListReplicasRequest.Size(m)
+}
+func (m *ListReplicasRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReplicasRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReplicasRequest proto.InternalMessageInfo
+
+type ListReplicasResponse struct {
+	// Status indicates the result of the ListReplicas operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// MasterChangeNumber is the change number of the latest change of the master node
+	MasterChangeNumber uint64 `protobuf:"varint,2,opt,name=masterChangeNumber,proto3" json:"masterChangeNumber,omitempty"`
+	// Replicas are the replicas seen by the master node, in the order of their IDs
+	Replicas             []*ReplicaProgress `protobuf:"bytes,3,rep,name=replicas,proto3" json:"replicas,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *ListReplicasResponse) Reset()         { *m = ListReplicasResponse{} }
+func (m *ListReplicasResponse) String() string { return proto.CompactTextString(m) }
+func (*ListReplicasResponse) ProtoMessage()    {}
+func (*ListReplicasResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{27}
+}
+
+func (m *ListReplicasResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReplicasResponse.Unmarshal(m, b)
+}
+func (m *ListReplicasResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReplicasResponse.Marshal(b, m, deterministic)
+}
+func (m *ListReplicasResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReplicasResponse.Merge(m, src)
+}
+func (m *ListReplicasResponse) XXX_Size() int {
+	return xxx_messageInfo_ListReplicasResponse.Size(m)
+}
+func (m *ListReplicasResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReplicasResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReplicasResponse proto.InternalMessageInfo
+
+func (m *ListReplicasResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *ListReplicasResponse) GetMasterChangeNumber() uint64 {
+	if m != nil {
+		return m.MasterChangeNumber
+	}
+	return 0
+}
+
+func (m *ListReplicasResponse) GetReplicas() []*ReplicaProgress {
+	if m != nil {
+		return m.Replicas
+	}
+	return nil
+}
+
+type GetChangeLogInfoRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetChangeLogInfoRequest) Reset()         { *m = GetChangeLogInfoRequest{} }
+func (m *GetChangeLogInfoRequest) String() string { return proto.CompactTextString(m) }
+func (*GetChangeLogInfoRequest) ProtoMessage()    {}
+func (*GetChangeLogInfoRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{28}
+}
+
+func (m *GetChangeLogInfoRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetChangeLogInfoRequest.Unmarshal(m, b)
+}
+func (m *GetChangeLogInfoRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetChangeLogInfoRequest.Marshal(b, m, deterministic)
+}
+func (m *GetChangeLogInfoRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetChangeLogInfoRequest.Merge(m, src)
+}
+func (m *GetChangeLogInfoRequest) XXX_Size() int {
+	return xxx_messageInfo_GetChangeLogInfoRequest.Size(m)
+}
+func (m *GetChangeLogInfoRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetChangeLogInfoRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetChangeLogInfoRequest proto.InternalMessageInfo
+
+type ReplicaProgress struct {
+	// ReplicaID identifies the replica, as given by it in GetChangesRequest
+	ReplicaID string `protobuf:"bytes,1,opt,name=replicaID,proto3" json:"replicaID,omitempty"`
+	// ConsumedChangeNumber is the latest change number consumed by the replica
+	ConsumedChangeNumber uint64 `protobuf:"varint,2,opt,name=consumedChangeNumber,proto3" json:"consumedChangeNumber,omitempty"`
+	// LastSeenTimeMillis is the time, in milliseconds since unix epoch, at which the
+	// replica last retrieved changes
+	LastSeenTimeMillis int64 `protobuf:"varint,3,opt,name=lastSeenTimeMillis,proto3" json:"lastSeenTimeMillis,omitempty"`
+	// ReplicationLag is the number of changes of the master node yet to be consumed by
+	// the replica, as of its latest retrieval
+	ReplicationLag       uint64   `protobuf:"varint,4,opt,name=replicationLag,proto3" json:"replicationLag,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReplicaProgress) Reset()         { *m = ReplicaProgress{} }
+func (m *ReplicaProgress) String() string { return proto.CompactTextString(m) }
+func (*ReplicaProgress) ProtoMessage()    {}
+func (*ReplicaProgress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{29}
+}
+
+func (m *ReplicaProgress) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplicaProgress.Unmarshal(m, b)
+}
+func (m *ReplicaProgress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplicaProgress.Marshal(b, m, deterministic)
+}
+func (m *ReplicaProgress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplicaProgress.Merge(m, src)
+}
+func (m *ReplicaProgress) XXX_Size() int {
+	return xxx_messageInfo_ReplicaProgress.Size(m)
+}
+func (m *ReplicaProgress) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplicaProgress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplicaProgress proto.InternalMessageInfo
+
+func (m *ReplicaProgress) GetReplicaID() string {
+	if m != nil {
+		return m.ReplicaID
+	}
+	return ""
+}
+
+func (m *ReplicaProgress) GetConsumedChangeNumber() uint64 {
+	if m != nil {
+		return m.ConsumedChangeNumber
+	}
+	return 0
+}
+
+func (m *ReplicaProgress) GetLastSeenTimeMillis() int64 {
+	if m != nil {
+		return m.LastSeenTimeMillis
+	}
+	return 0
+}
+
+func (m *ReplicaProgress) GetReplicationLag() uint64 {
+	if m != nil {
+		return m.ReplicationLag
+	}
+	return 0
+}
+
+type GetChangeLogInfoResponse struct {
+	// Status indicates the result of the GetChangeLogInfo operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// FirstChangeNumber is the change number of the oldest change retained, which
+	// exceeds LastChangeNumber when no changes are retained
+	FirstChangeNumber uint64 `protobuf:"varint,2,opt,name=firstChangeNumber,proto3" json:"firstChangeNumber,omitempty"`
+	// LastChangeNumber is the change number of the latest change
+	LastChangeNumber uint64 `protobuf:"varint,3,opt,name=lastChangeNumber,proto3" json:"lastChangeNumber,omitempty"`
+	// Replicas are the replicas whose progress is tracked, in the order of their IDs
+	Replicas             []*ReplicaProgress `protobuf:"bytes,4,rep,name=replicas,proto3" json:"replicas,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *GetChangeLogInfoResponse) Reset()         { *m = GetChangeLogInfoResponse{} }
+func (m *GetChangeLogInfoResponse) String() string { return proto.CompactTextString(m) }
+func (*GetChangeLogInfoResponse) ProtoMessage()    {}
+func (*GetChangeLogInfoResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{30}
+}
+
+func (m *GetChangeLogInfoResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetChangeLogInfoResponse.Unmarshal(m, b)
+}
+func (m *GetChangeLogInfoResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetChangeLogInfoResponse.Marshal(b, m, deterministic)
+}
+func (m *GetChangeLogInfoResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetChangeLogInfoResponse.Merge(m, src)
+}
+func (m *GetChangeLogInfoResponse) XXX_Size() int {
+	return xxx_messageInfo_GetChangeLogInfoResponse.Size(m)
+}
+func (m *GetChangeLogInfoResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetChangeLogInfoResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetChangeLogInfoResponse proto.InternalMessageInfo
+
+func (m *GetChangeLogInfoResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetChangeLogInfoResponse) GetFirstChangeNumber() uint64 {
+	if m != nil {
+		return m.FirstChangeNumber
+	}
+	return 0
+}
+
+func (m *GetChangeLogInfoResponse) GetLastChangeNumber() uint64 {
+	if m != nil {
+		return m.LastChangeNumber
+	}
+	return 0
+}
+
+func (m *GetChangeLogInfoResponse) GetReplicas() []*ReplicaProgress {
+	if m != nil {
+		return m.Replicas
+	}
+	return nil
+}
+
+type TruncateChangeLogRequest struct {
+	// BeforeChangeNumber, if positive, is the change number before which the changes are
+	// discarded irrespective of the retention policy, else the policy is applied right away
+	BeforeChangeNumber   uint64   `protobuf:"varint,1,opt,name=beforeChangeNumber,proto3" json:"beforeChangeNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TruncateChangeLogRequest) Reset()         { *m = TruncateChangeLogRequest{} }
+func (m *TruncateChangeLogRequest) String() string { return proto.CompactTextString(m) }
+func (*TruncateChangeLogRequest) ProtoMessage()    {}
+func (*TruncateChangeLogRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{31}
+}
+
+func (m *TruncateChangeLogRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TruncateChangeLogRequest.Unmarshal(m, b)
+}
+func (m *TruncateChangeLogRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TruncateChangeLogRequest.Marshal(b, m, deterministic)
+}
+func (m *TruncateChangeLogRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TruncateChangeLogRequest.Merge(m, src)
+}
+func (m *TruncateChangeLogRequest) XXX_Size() int {
+	return xxx_messageInfo_TruncateChangeLogRequest.Size(m)
+}
+func (m *TruncateChangeLogRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TruncateChangeLogRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TruncateChangeLogRequest proto.InternalMessageInfo
+
+func (m *TruncateChangeLogRequest) GetBeforeChangeNumber() uint64 {
+	if m != nil {
+		return m.BeforeChangeNumber
+	}
+	return 0
+}
+
+type TruncateChangeLogResponse struct {
+	// Status indicates the result of the TruncateChangeLog operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// FirstChangeNumber is the change number of the oldest change retained after truncation
+	FirstChangeNumber    uint64   `protobuf:"varint,2,opt,name=firstChangeNumber,proto3" json:"firstChangeNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TruncateChangeLogResponse) Reset()         { *m = TruncateChangeLogResponse{} }
+func (m *TruncateChangeLogResponse) String() string { return proto.CompactTextString(m) }
+func (*TruncateChangeLogResponse) ProtoMessage()    {}
+func (*TruncateChangeLogResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{32}
+}
+
+func (m *TruncateChangeLogResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TruncateChangeLogResponse.Unmarshal(m, b)
+}
+func (m *TruncateChangeLogResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TruncateChangeLogResponse.Marshal(b, m, deterministic)
+}
+func (m *TruncateChangeLogResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TruncateChangeLogResponse.Merge(m, src)
+}
+func (m *TruncateChangeLogResponse) XXX_Size() int {
+	return xxx_messageInfo_TruncateChangeLogResponse.Size(m)
+}
+func (m *TruncateChangeLogResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TruncateChangeLogResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TruncateChangeLogResponse proto.InternalMessageInfo
+
+func (m *TruncateChangeLogResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *TruncateChangeLogResponse) GetFirstChangeNumber() uint64 {
+	if m != nil {
+		return m.FirstChangeNumber
+	}
+	return 0
+}
+
+type VerifyRangeRequest struct {
+	// FromChangeNumber is the first change number of the range
+	FromChangeNumber uint64 `protobuf:"varint,1,opt,name=fromChangeNumber,proto3" json:"fromChangeNumber,omitempty"`
+	// ToChangeNumber is the last change number of the range
+	ToChangeNumber       uint64   `protobuf:"varint,2,opt,name=toChangeNumber,proto3" json:"toChangeNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyRangeRequest) Reset()         { *m = VerifyRangeRequest{} }
+func (m *VerifyRangeRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifyRangeRequest) ProtoMessage()    {}
+func (*VerifyRangeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{33}
+}
+
+func (m *VerifyRangeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyRangeRequest.Unmarshal(m, b)
+}
+func (m *VerifyRangeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyRangeRequest.Marshal(b, m, deterministic)
+}
+func (m *VerifyRangeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyRangeRequest.Merge(m, src)
+}
+func (m *VerifyRangeRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifyRangeRequest.Size(m)
+}
+func (m *VerifyRangeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyRangeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyRangeRequest proto.InternalMessageInfo
+
+func (m *VerifyRangeRequest) GetFromChangeNumber() uint64 {
+	if m != nil {
+		return m.FromChangeNumber
+	}
+	return 0
+}
+
+func (m *VerifyRangeRequest) GetToChangeNumber() uint64 {
+	if m != nil {
+		return m.ToChangeNumber
+	}
+	return 0
+}
+
+type KeyspaceDigestRequest struct {
+	// StartKey is the first key of the range digested, which begins with the first key if empty
+	StartKey []byte `protobuf:"bytes,1,opt,name=startKey,proto3" json:"startKey,omitempty"`
+	// EndKey is the key right after the range digested, which extends upto the last key if empty
+	EndKey []byte `protobuf:"bytes,2,opt,name=endKey,proto3" json:"endKey,omitempty"`
+	// FanOut is the number of ranges, of nearly equal number of keys, into which the range
+	// is split. It is used only when the split keys are not given.
+	FanOut uint32 `protobuf:"varint,3,opt,name=fanOut,proto3" json:"fanOut,omitempty"`
+	// SplitKeys are the keys at which the range is split, i.e., the start keys of all the
+	// ranges but the first one, in ascending order.
+	SplitKeys [][]byte `protobuf:"bytes,4,rep,name=splitKeys,proto3" json:"splitKeys,omitempty"`
+	// ListKeys indicates whether the digest of every key is returned as well
+	ListKeys             bool     `protobuf:"varint,5,opt,name=listKeys,proto3" json:"listKeys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyspaceDigestRequest) Reset()         { *m = KeyspaceDigestRequest{} }
+func (m *KeyspaceDigestRequest) String() string { return proto.CompactTextString(m) }
+func (*KeyspaceDigestRequest) ProtoMessage()    {}
+func (*KeyspaceDigestRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{34}
+}
+
+func (m *KeyspaceDigestRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyspaceDigestRequest.Unmarshal(m, b)
+}
+func (m *KeyspaceDigestRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyspaceDigestRequest.Marshal(b, m, deterministic)
+}
+func (m *KeyspaceDigestRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyspaceDigestRequest.Merge(m, src)
+}
+func (m *KeyspaceDigestRequest) XXX_Size() int {
+	return xxx_messageInfo_KeyspaceDigestRequest.Size(m)
+}
+func (m *KeyspaceDigestRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyspaceDigestRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyspaceDigestRequest proto.InternalMessageInfo
+
+func (m *KeyspaceDigestRequest) GetStartKey() []byte {
+	if m != nil {
+		return m.StartKey
+	}
+	return nil
+}
+
+func (m *KeyspaceDigestRequest) GetEndKey() []byte {
+	if m != nil {
+		return m.EndKey
+	}
+	return nil
+}
+
+func (m *KeyspaceDigestRequest) GetFanOut() uint32 {
+	if m != nil {
+		return m.FanOut
+	}
+	return 0
+}
+
+func (m *KeyspaceDigestRequest) GetSplitKeys() [][]byte {
+	if m != nil {
+		return m.SplitKeys
+	}
+	return nil
+}
+
+func (m *KeyspaceDigestRequest) GetListKeys() bool {
+	if m != nil {
+		return m.ListKeys
+	}
+	return false
+}
+
+type KeyRangeDigest struct {
+	// StartKey is the first key of this range, which begins with the first key if empty
+	StartKey []byte `protobuf:"bytes,1,opt,name=startKey,proto3" json:"startKey,omitempty"`
+	// EndKey is the key right after this range, which extends upto the last key if empty
+	EndKey []byte `protobuf:"bytes,2,opt,name=endKey,proto3" json:"endKey,omitempty"`
+	// Digest is the SHA-256 of all the keys of this range along with their values
+	Digest []byte `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+	// NumberOfKeys is the number of keys of this range
+	NumberOfKeys         uint64   `protobuf:"varint,4,opt,name=numberOfKeys,proto3" json:"numberOfKeys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyRangeDigest) Reset()         { *m = KeyRangeDigest{} }
+func (m *KeyRangeDigest) String() string { return proto.CompactTextString(m) }
+func (*KeyRangeDigest) ProtoMessage()    {}
+func (*KeyRangeDigest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{35}
+}
+
+func (m *KeyRangeDigest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyRangeDigest.Unmarshal(m, b)
+}
+func (m *KeyRangeDigest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyRangeDigest.Marshal(b, m, deterministic)
+}
+func (m *KeyRangeDigest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyRangeDigest.Merge(m, src)
+}
+func (m *KeyRangeDigest) XXX_Size() int {
+	return xxx_messageInfo_KeyRangeDigest.Size(m)
+}
+func (m *KeyRangeDigest) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyRangeDigest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyRangeDigest proto.InternalMessageInfo
+
+func (m *KeyRangeDigest) GetStartKey() []byte {
+	if m != nil {
+		return m.StartKey
+	}
+	return nil
+}
+
+func (m *KeyRangeDigest) GetEndKey() []byte {
+	if m != nil {
+		return m.EndKey
+	}
+	return nil
+}
+
+func (m *KeyRangeDigest) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
+func (m *KeyRangeDigest) GetNumberOfKeys() uint64 {
+	if m != nil {
+		return m.NumberOfKeys
+	}
+	return 0
+}
+
+type KeyDigest struct {
+	// Key is the key as stored
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Digest is the SHA-256 of the value of this key along with its expiry
+	Digest               []byte   `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyDigest) Reset()         { *m = KeyDigest{} }
+func (m *KeyDigest) String() string { return proto.CompactTextString(m) }
+func (*KeyDigest) ProtoMessage()    {}
+func (*KeyDigest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{36}
+}
+
+func (m *KeyDigest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyDigest.Unmarshal(m, b)
+}
+func (m *KeyDigest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyDigest.Marshal(b, m, deterministic)
+}
+func (m *KeyDigest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyDigest.Merge(m, src)
+}
+func (m *KeyDigest) XXX_Size() int {
+	return xxx_messageInfo_KeyDigest.Size(m)
+}
+func (m *KeyDigest) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyDigest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyDigest proto.InternalMessageInfo
+
+func (m *KeyDigest) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *KeyDigest) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
+type KeyspaceDigestResponse struct {
+	// Status indicates the result of the KeyspaceDigest operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Ranges are the digests of the consecutive ranges into which the range is split
+	Ranges []*KeyRangeDigest `protobuf:"bytes,2,rep,name=ranges,proto3" json:"ranges,omitempty"`
+	// Keys are the digests of all the keys of the range, when they are listed
+	Keys                 []*KeyDigest `protobuf:"bytes,3,rep,name=keys,proto3" json:"keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *KeyspaceDigestResponse) Reset()         { *m = KeyspaceDigestResponse{} }
+func (m *KeyspaceDigestResponse) String() string { return proto.CompactTextString(m) }
+func (*KeyspaceDigestResponse) ProtoMessage()    {}
+func (*KeyspaceDigestResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{37}
+}
+
+func (m *KeyspaceDigestResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyspaceDigestResponse.Unmarshal(m, b)
+}
+func (m *KeyspaceDigestResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyspaceDigestResponse.Marshal(b, m, deterministic)
+}
+func (m *KeyspaceDigestResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyspaceDigestResponse.Merge(m, src)
+}
+func (m *KeyspaceDigestResponse) XXX_Size() int {
+	return xxx_messageInfo_KeyspaceDigestResponse.Size(m)
+}
+func (m *KeyspaceDigestResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyspaceDigestResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyspaceDigestResponse proto.InternalMessageInfo
+
+func (m *KeyspaceDigestResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *KeyspaceDigestResponse) GetRanges() []*KeyRangeDigest {
+	if m != nil {
+		return m.Ranges
+	}
+	return nil
+}
+
+func (m *KeyspaceDigestResponse) GetKeys() []*KeyDigest {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+type VerifyRangeResponse struct {
+	// Status indicates the result of the VerifyRange operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Checksum is the CRC-32C of the transactions of all the changes within the range
+	Checksum uint32 `protobuf:"varint,2,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	// NumberOfTrxns is the number of transactions of all the changes within the range
+	NumberOfTrxns        uint64   `protobuf:"varint,3,opt,name=numberOfTrxns,proto3" json:"numberOfTrxns,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyRangeResponse) Reset()         { *m = VerifyRangeResponse{} }
+func (m *VerifyRangeResponse) String() string { return proto.CompactTextString(m) }
+func (*VerifyRangeResponse) ProtoMessage()    {}
+func (*VerifyRangeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{38}
+}
+
+func (m *VerifyRangeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyRangeResponse.Unmarshal(m, b)
+}
+func (m *VerifyRangeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyRangeResponse.Marshal(b, m, deterministic)
+}
+func (m *VerifyRangeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyRangeResponse.Merge(m, src)
+}
+func (m *VerifyRangeResponse) XXX_Size() int {
+	return xxx_messageInfo_VerifyRangeResponse.Size(m)
+}
+func (m *VerifyRangeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyRangeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyRangeResponse proto.InternalMessageInfo
+
+func (m *VerifyRangeResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *VerifyRangeResponse) GetChecksum() uint32 {
+	if m != nil {
+		return m.Checksum
+	}
+	return 0
+}
+
+func (m *VerifyRangeResponse) GetNumberOfTrxns() uint64 {
+	if m != nil {
+		return m.NumberOfTrxns
+	}
+	return 0
+}
+
+type GetChangesRequest struct {
+	// FromChangeNumber is the starting change number from which to retrieve changes
+	FromChangeNumber uint64 `protobuf:"varint,1,opt,name=fromChangeNumber,proto3" json:"fromChangeNumber,omitempty"`
+	// MaxNumberOfChanges is the maximum number of changes to return from this invocation
+	MaxNumberOfChanges uint32 `protobuf:"varint,2,opt,name=maxNumberOfChanges,proto3" json:"maxNumberOfChanges,omitempty"`
+	// MaxNumberOfBytes, if positive, limits the total size of changes returned from this
+	// invocation. Atleast one change is always returned irrespective of its size.
+	MaxNumberOfBytes uint64 `protobuf:"varint,3,opt,name=maxNumberOfBytes,proto3" json:"maxNumberOfBytes,omitempty"`
+	// Namespace, if not empty, restricts the transactions of every change record to
+	// those on the keys of this namespace. Such change records carry their transactions
+	// with the keys as stored, but without their serialised form.
+	Namespace string `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// KeyPrefix, if not empty, restricts the transactions of every change record to
+	// those on the keys with this prefix, within the namespace if any. Change records
+	// left without any transactions are skipped altogether, while the change numbers
+	// of the others remain as is.
+	KeyPrefix []byte `protobuf:"bytes,5,opt,name=keyPrefix,proto3" json:"keyPrefix,omitempty"`
+	// ReplicaID, if not empty, identifies the replica retrieving the changes, whose
+	// progress is tracked by the master node for retaining the changes it is yet to
+	// consume. Changes before FromChangeNumber are taken to be consumed.
+	ReplicaID string `protobuf:"bytes,6,opt,name=replicaID,proto3" json:"replicaID,omitempty"`
+	// MaxResponseSize, if positive, limits the size of the response, such as to the
+	// maximum message size received by the client. Changes that do not fit within it
+	// are left for the subsequent invocations, through NextChangeNumber.
+	MaxResponseSize uint64 `protobuf:"varint,7,opt,name=maxResponseSize,proto3" json:"maxResponseSize,omitempty"`
+	// Chunked indicates that the client reassembles the changes that do not fit within
+	// a response by themselves, which are then served in chunks, as per ChangeRecord,
+	// instead of failing with the TooLarge status code.
+	Chunked bool `protobuf:"varint,8,opt,name=chunked,proto3" json:"chunked,omitempty"`
+	// FromChunkOffset is the offset within the chunked change FromChangeNumber from
+	// which to resume retrieving its chunks, as per the NextChunkOffset of the previous
+	// response.
+	FromChunkOffset      uint64   `protobuf:"varint,9,opt,name=fromChunkOffset,proto3" json:"fromChunkOffset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetChangesRequest) Reset()         { *m = GetChangesRequest{} }
+func (m *GetChangesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetChangesRequest) ProtoMessage()    {}
+func (*GetChangesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{39}
+}
+
+func (m *GetChangesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetChangesRequest.Unmarshal(m, b)
+}
+func (m *GetChangesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetChangesRequest.Marshal(b, m, deterministic)
+}
+func (m *GetChangesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetChangesRequest.Merge(m, src)
+}
+func (m *GetChangesRequest) XXX_Size() int {
+	return xxx_messageInfo_GetChangesRequest.Size(m)
+}
+func (m *GetChangesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetChangesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetChangesRequest proto.InternalMessageInfo
+
+func (m *GetChangesRequest) GetFromChangeNumber() uint64 {
+	if m != nil {
+		return m.FromChangeNumber
+	}
+	return 0
+}
+
+func (m *GetChangesRequest) GetMaxNumberOfChanges() uint32 {
+	if m != nil {
+		return m.MaxNumberOfChanges
+	}
+	return 0
+}
+
+func (m *GetChangesRequest) GetMaxNumberOfBytes() uint64 {
+	if m != nil {
+		return m.MaxNumberOfBytes
+	}
+	return 0
+}
+
+func (m *GetChangesRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *GetChangesRequest) GetKeyPrefix() []byte {
+	if m != nil {
+		return m.KeyPrefix
+	}
+	return nil
+}
+
+func (m *GetChangesRequest) GetReplicaID() string {
+	if m != nil {
+		return m.ReplicaID
+	}
+	return ""
+}
+
+func (m *GetChangesRequest) GetMaxResponseSize() uint64 {
+	if m != nil {
+		return m.MaxResponseSize
+	}
+	return 0
+}
+
+func (m *GetChangesRequest) GetChunked() bool {
+	if m != nil {
+		return m.Chunked
+	}
+	return false
+}
+
+func (m *GetChangesRequest) GetFromChunkOffset() uint64 {
+	if m != nil {
+		return m.FromChunkOffset
+	}
+	return 0
+}
+
+type GetChangesResponse struct {
+	// Status indicates the result of the GetChanges operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// MasterChangeNumber indicates the latest change number on master node
+	MasterChangeNumber uint64 `protobuf:"varint,2,opt,name=masterChangeNumber,proto3" json:"masterChangeNumber,omitempty"`
+	// NumberOfChanges indicates the number of change records in the response
+	NumberOfChanges uint32 `protobuf:"varint,3,opt,name=numberOfChanges,proto3" json:"numberOfChanges,omitempty"`
+	// Changes is the collection of change records
+	Changes []*ChangeRecord `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty"`
+	// NextChangeNumber, if positive, is the change number from which the subsequent
+	// changes must be retrieved. It exceeds the change numbers of all the returned
+	// change records when those following them are skipped due to the KeyPrefix.
+	NextChangeNumber uint64 `protobuf:"varint,5,opt,name=nextChangeNumber,proto3" json:"nextChangeNumber,omitempty"`
+	// ServedBySlave indicates that the changes are served by a slave node, in which
+	// case MasterChangeNumber is the latest change number applied by this slave node.
+	// It may then lag behind the change numbers of the nodes replicating from it.
+	ServedBySlave bool `protobuf:"varint,6,opt,name=servedBySlave,proto3" json:"servedBySlave,omitempty"`
+	// NextChunkOffset, if positive, is the offset within the chunked change NextChangeNumber
+	// from which its subsequent chunks must be retrieved, through FromChunkOffset.
+	NextChunkOffset      uint64   `protobuf:"varint,7,opt,name=nextChunkOffset,proto3" json:"nextChunkOffset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetChangesResponse) Reset()         { *m = GetChangesResponse{} }
+func (m *GetChangesResponse) String() string { return proto.CompactTextString(m) }
+func (*GetChangesResponse) ProtoMessage()    {}
+func (*GetChangesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{40}
+}
+
+func (m *GetChangesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetChangesResponse.Unmarshal(m, b)
+}
+func (m *GetChangesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetChangesResponse.Marshal(b, m, deterministic)
+}
+func (m *GetChangesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetChangesResponse.Merge(m, src)
+}
+func (m *GetChangesResponse) XXX_Size() int {
+	return xxx_messageInfo_GetChangesResponse.Size(m)
+}
+func (m *GetChangesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetChangesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetChangesResponse proto.InternalMessageInfo
+
+func (m *GetChangesResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetChangesResponse) GetMasterChangeNumber() uint64 {
+	if m != nil {
+		return m.MasterChangeNumber
+	}
+	return 0
+}
+
+func (m *GetChangesResponse) GetNumberOfChanges() uint32 {
+	if m != nil {
+		return m.NumberOfChanges
+	}
+	return 0
+}
+
+func (m *GetChangesResponse) GetChanges() []*ChangeRecord {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+func (m *GetChangesResponse) GetNextChangeNumber() uint64 {
+	if m != nil {
+		return m.NextChangeNumber
+	}
+	return 0
+}
+
+func (m *GetChangesResponse) GetServedBySlave() bool {
+	if m != nil {
+		return m.ServedBySlave
+	}
+	return false
+}
+
+func (m *GetChangesResponse) GetNextChunkOffset() uint64 {
+	if m != nil {
+		return m.NextChunkOffset
+	}
+	return 0
+}
+
+type GetCheckpointRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCheckpointRequest) Reset()         { *m = GetCheckpointRequest{} }
+func (m *GetCheckpointRequest) String() string { return proto.CompactTextString(m) }
+func (*GetCheckpointRequest) ProtoMessage()    {}
+func (*GetCheckpointRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{41}
+}
+
+func (m *GetCheckpointRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCheckpointRequest.Unmarshal(m, b)
+}
+func (m *GetCheckpointRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCheckpointRequest.Marshal(b, m, deterministic)
+}
+func (m *GetCheckpointRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCheckpointRequest.Merge(m, src)
+}
+func (m *GetCheckpointRequest) XXX_Size() int {
+	return xxx_messageInfo_GetCheckpointRequest.Size(m)
+}
+func (m *GetCheckpointRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCheckpointRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCheckpointRequest proto.InternalMessageInfo
+
+type GetCheckpointResponse struct {
+	// Status indicates the result of the GetCheckpoint operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// ChangeNumber indicates the change number as of which the checkpoint is captured
+	ChangeNumber uint64 `protobuf:"varint,2,opt,name=changeNumber,proto3" json:"changeNumber,omitempty"`
+	// Entries is the collection of key value pairs belonging to the checkpoint
+	Entries              []*PutRequest `protobuf:"bytes,3,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *GetCheckpointResponse) Reset()         { *m = GetCheckpointResponse{} }
+func (m *GetCheckpointResponse) String() string { return proto.CompactTextString(m) }
+func (*GetCheckpointResponse) ProtoMessage()    {}
+func (*GetCheckpointResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{42}
+}
+
+func (m *GetCheckpointResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCheckpointResponse.Unmarshal(m, b)
+}
+func (m *GetCheckpointResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCheckpointResponse.Marshal(b, m, deterministic)
+}
+func (m *GetCheckpointResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCheckpointResponse.Merge(m, src)
+}
+func (m *GetCheckpointResponse) XXX_Size() int {
+	return xxx_messageInfo_GetCheckpointResponse.Size(m)
+}
+func (m *GetCheckpointResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCheckpointResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCheckpointResponse proto.InternalMessageInfo
+
+func (m *GetCheckpointResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetCheckpointResponse) GetChangeNumber() uint64 {
+	if m != nil {
+		return m.ChangeNumber
+	}
+	return 0
+}
+
+func (m *GetCheckpointResponse) GetEntries() []*PutRequest {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type ChangeRecord struct {
+	// SerialisedForm is the internal byte array representation of this change record
+	SerialisedForm []byte `protobuf:"bytes,1,opt,name=serialisedForm,proto3" json:"serialisedForm,omitempty"`
+	// ChangeNumber indicates the change number of this change record
+	ChangeNumber uint64 `protobuf:"varint,2,opt,name=changeNumber,proto3" json:"changeNumber,omitempty"`
+	// NumberOfTrxns indicates the number of transactions associated with this change record
+	NumberOfTrxns uint32 `protobuf:"varint,3,opt,name=numberOfTrxns,proto3" json:"numberOfTrxns,omitempty"`
+	// Trxns is the collection of transaction records associated with this change record
+	Trxns []*TrxnRecord `protobuf:"bytes,4,rep,name=trxns,proto3" json:"trxns,omitempty"`
+	// Checksum is the CRC-32C of the transactions of this change record, computed
+	// by the master node. Zero indicates its absence, i.e., it is not verified.
+	Checksum uint32 `protobuf:"varint,5,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	// ChunkedSize, if positive, indicates that this change record is a chunk of the
+	// change with the ChangeNumber, which is served in chunks for it is too large for
+	// a response. It is then the size of the marshalled change, which is unmarshalled
+	// from all of its chunks concatenated in order. Such chunk records carry no other
+	// fields besides ChunkOffset and Chunk.
+	ChunkedSize uint64 `protobuf:"varint,6,opt,name=chunkedSize,proto3" json:"chunkedSize,omitempty"`
+	// ChunkOffset is the offset of the chunk within the marshalled change.
+	ChunkOffset uint64 `protobuf:"varint,7,opt,name=chunkOffset,proto3" json:"chunkOffset,omitempty"`
+	// Chunk is the chunk of the marshalled change carried by this change record.
+	Chunk                []byte   `protobuf:"bytes,8,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChangeRecord) Reset()         { *m = ChangeRecord{} }
+func (m *ChangeRecord) String() string { return proto.CompactTextString(m) }
+func (*ChangeRecord) ProtoMessage()    {}
+func (*ChangeRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{43}
+}
+
+func (m *ChangeRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChangeRecord.Unmarshal(m, b)
+}
+func (m *ChangeRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChangeRecord.Marshal(b, m, deterministic)
+}
+func (m *ChangeRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChangeRecord.Merge(m, src)
+}
+func (m *ChangeRecord) XXX_Size() int {
+	return xxx_messageInfo_ChangeRecord.Size(m)
+}
+func (m *ChangeRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChangeRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChangeRecord proto.InternalMessageInfo
+
+func (m *ChangeRecord) GetSerialisedForm() []byte {
+	if m != nil {
+		return m.SerialisedForm
+	}
+	return nil
+}
+
+func (m *ChangeRecord) GetChangeNumber() uint64 {
+	if m != nil {
+		return m.ChangeNumber
+	}
+	return 0
+}
+
+func (m *ChangeRecord) GetNumberOfTrxns() uint32 {
+	if m != nil {
+		return m.NumberOfTrxns
+	}
+	return 0
+}
+
+func (m *ChangeRecord) GetTrxns() []*TrxnRecord {
+	if m != nil {
+		return m.Trxns
+	}
+	return nil
+}
+
+func (m *ChangeRecord) GetChecksum() uint32 {
+	if m != nil {
+		return m.Checksum
+	}
+	return 0
+}
+
+func (m *ChangeRecord) GetChunkedSize() uint64 {
+	if m != nil {
+		return m.ChunkedSize
+	}
+	return 0
+}
+
+func (m *ChangeRecord) GetChunkOffset() uint64 {
+	if m != nil {
+		return m.ChunkOffset
+	}
+	return 0
+}
+
+func (m *ChangeRecord) GetChunk() []byte {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+type TrxnRecord struct {
+	// Type indicates the type of this transaction - Put, Delete, etc.
+	Type TrxnRecord_TrxnType `protobuf:"varint,1,opt,name=type,proto3,enum=dkv.serverpb.TrxnRecord_TrxnType" json:"type,omitempty"`
+	// Key is the byte array representation of the key associated with this transaction
+	Key []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	// Value is the byte array representation of the value associated with this transaction
+	Value []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	// ExpireTS is the absolute time, in unix seconds, at which the key of this Put transaction
+	// expires. Zero indicates that the key never expires.
+	ExpireTS             uint64   `protobuf:"varint,4,opt,name=expireTS,proto3" json:"expireTS,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TrxnRecord) Reset()         { *m = TrxnRecord{} }
+func (m *TrxnRecord) String() string { return proto.CompactTextString(m) }
+func (*TrxnRecord) ProtoMessage()    {}
+func (*TrxnRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{44}
+}
+
+func (m *TrxnRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TrxnRecord.Unmarshal(m, b)
+}
+func (m *TrxnRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TrxnRecord.Marshal(b, m, deterministic)
+}
+func (m *TrxnRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TrxnRecord.Merge(m, src)
+}
+func (m *TrxnRecord) XXX_Size() int {
+	return xxx_messageInfo_TrxnRecord.Size(m)
+}
+func (m *TrxnRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_TrxnRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TrxnRecord proto.InternalMessageInfo
+
+func (m *TrxnRecord) GetType() TrxnRecord_TrxnType {
+	if m != nil {
+		return m.Type
+	}
+	return TrxnRecord_Unknown
+}
+
+func (m *TrxnRecord) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *TrxnRecord) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *TrxnRecord) GetExpireTS() uint64 {
+	if m != nil {
+		return m.ExpireTS
+	}
+	return 0
+}
+
+type GetStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStatusRequest) Reset()         { *m = GetStatusRequest{} }
+func (m *GetStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetStatusRequest) ProtoMessage()    {}
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{45}
+}
+
+func (m *GetStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStatusRequest.Unmarshal(m, b)
+}
+func (m *GetStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *GetStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStatusRequest.Merge(m, src)
+}
+func (m *GetStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetStatusRequest.Size(m)
+}
+func (m *GetStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStatusRequest proto.InternalMessageInfo
+
+type GetStatusResponse struct {
+	// Status indicates the result of the GetStatus operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// AppliedChangeNumber is the change number of the latest change applied on the slave node
+	AppliedChangeNumber uint64 `protobuf:"varint,2,opt,name=appliedChangeNumber,proto3" json:"appliedChangeNumber,omitempty"`
+	// MasterChangeNumber is the latest change number of the master node as observed by the slave node
+	MasterChangeNumber uint64 `protobuf:"varint,3,opt,name=masterChangeNumber,proto3" json:"masterChangeNumber,omitempty"`
+	// ReplicationLag is the number of changes on the master node yet to be applied on the slave node
+	ReplicationLag uint64 `protobuf:"varint,4,opt,name=replicationLag,proto3" json:"replicationLag,omitempty"`
+	// LastPollTimeMillis is the time, in milliseconds since unix epoch, of the last successful poll for changes
+	LastPollTimeMillis int64 `protobuf:"varint,5,opt,name=lastPollTimeMillis,proto3" json:"lastPollTimeMillis,omitempty"`
+	// NumErrors is the total number of errors that occurred while replicating from the master node
+	NumErrors uint64 `protobuf:"varint,6,opt,name=numErrors,proto3" json:"numErrors,omitempty"`
+	// Healthy indicates whether replication is active. It is false when replication
+	// is stopped due to an unrecoverable error like a divergence from the master node.
+	Healthy bool `protobuf:"varint,7,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// Paused indicates whether replication is paused on demand
+	Paused bool `protobuf:"varint,8,opt,name=paused,proto3" json:"paused,omitempty"`
+	// MasterAddr is the address of the master node that the slave node currently replicates from
+	MasterAddr string `protobuf:"bytes,9,opt,name=masterAddr,proto3" json:"masterAddr,omitempty"`
+	// ApplyLatencyMicros is the moving average of the time taken by the slave node to apply a batch
+	// of changes onto its storage, in microseconds
+	ApplyLatencyMicros uint64 `protobuf:"varint,10,opt,name=applyLatencyMicros,proto3" json:"applyLatencyMicros,omitempty"`
+	// EffectiveBatchSize is the maximum number of changes currently retrieved in a batch, which
+	// shrinks while the apply latency exceeds its threshold
+	EffectiveBatchSize uint32 `protobuf:"varint,11,opt,name=effectiveBatchSize,proto3" json:"effectiveBatchSize,omitempty"`
+	// EffectivePollIntervalMillis is the interval, in milliseconds, currently between the batches,
+	// which lengthens while the apply latency exceeds its threshold
+	EffectivePollIntervalMillis int64 `protobuf:"varint,12,opt,name=effectivePollIntervalMillis,proto3" json:"effectivePollIntervalMillis,omitempty"`
+	// ReplicaID is the identity under which the slave node retrieves the changes of the master node
+	ReplicaID string `protobuf:"bytes,13,opt,name=replicaID,proto3" json:"replicaID,omitempty"`
+	// PersistedState is the state of the replication as recorded by the local storage of the slave
+	// node along with the changes it applies, which survives its restarts. It is absent unless the
+	// storage engine records it.
+	PersistedState       *ReplicationState `protobuf:"bytes,14,opt,name=persistedState,proto3" json:"persistedState,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *GetStatusResponse) Reset()         { *m = GetStatusResponse{} }
+func (m *GetStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*GetStatusResponse) ProtoMessage()    {}
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{46}
+}
+
+func (m *GetStatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStatusResponse.Unmarshal(m, b)
+}
+func (m *GetStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStatusResponse.Marshal(b, m, deterministic)
+}
+func (m *GetStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStatusResponse.Merge(m, src)
+}
+func (m *GetStatusResponse) XXX_Size() int {
+	return xxx_messageInfo_GetStatusResponse.Size(m)
+}
+func (m *GetStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStatusResponse proto.InternalMessageInfo
+
+func (m *GetStatusResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetStatusResponse) GetAppliedChangeNumber() uint64 {
+	if m != nil {
+		return m.AppliedChangeNumber
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetMasterChangeNumber() uint64 {
+	if m != nil {
+		return m.MasterChangeNumber
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetReplicationLag() uint64 {
+	if m != nil {
+		return m.ReplicationLag
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetLastPollTimeMillis() int64 {
+	if m != nil {
+		return m.LastPollTimeMillis
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetNumErrors() uint64 {
+	if m != nil {
+		return m.NumErrors
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetHealthy() bool {
+	if m != nil {
+		return m.Healthy
+	}
+	return false
+}
+
+func (m *GetStatusResponse) GetPaused() bool {
+	if m != nil {
+		return m.Paused
+	}
+	return false
+}
+
+func (m *GetStatusResponse) GetMasterAddr() string {
+	if m != nil {
+		return m.MasterAddr
+	}
+	return ""
+}
+
+func (m *GetStatusResponse) GetApplyLatencyMicros() uint64 {
+	if m != nil {
+		return m.ApplyLatencyMicros
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetEffectiveBatchSize() uint32 {
+	if m != nil {
+		return m.EffectiveBatchSize
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetEffectivePollIntervalMillis() int64 {
+	if m != nil {
+		return m.EffectivePollIntervalMillis
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetReplicaID() string {
+	if m != nil {
+		return m.ReplicaID
+	}
+	return ""
+}
+
+func (m *GetStatusResponse) GetPersistedState() *ReplicationState {
+	if m != nil {
+		return m.PersistedState
+	}
+	return nil
+}
+
+// ReplicationState is the state of the replication of a slave node as of the latest batch of
+// changes it applied.
+type ReplicationState struct {
+	// MasterAddr is the address of the master node the batch is retrieved from
+	MasterAddr string `protobuf:"bytes,1,opt,name=masterAddr,proto3" json:"masterAddr,omitempty"`
+	// ReplicaID is the identity under which the batch is retrieved
+	ReplicaID string `protobuf:"bytes,2,opt,name=replicaID,proto3" json:"replicaID,omitempty"`
+	// AppliedChangeNumber is the change number of the latest change applied
+	AppliedChangeNumber uint64 `protobuf:"varint,3,opt,name=appliedChangeNumber,proto3" json:"appliedChangeNumber,omitempty"`
+	// LastPollTimeMillis is the time, in milliseconds since unix epoch, at which the batch is applied
+	LastPollTimeMillis   int64    `protobuf:"varint,4,opt,name=lastPollTimeMillis,proto3" json:"lastPollTimeMillis,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReplicationState) Reset()         { *m = ReplicationState{} }
+func (m *ReplicationState) String() string { return proto.CompactTextString(m) }
+func (*ReplicationState) ProtoMessage()    {}
+func (*ReplicationState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{47}
+}
+
+func (m *ReplicationState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplicationState.Unmarshal(m, b)
+}
+func (m *ReplicationState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplicationState.Marshal(b, m, deterministic)
+}
+func (m *ReplicationState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplicationState.Merge(m, src)
+}
+func (m *ReplicationState) XXX_Size() int {
+	return xxx_messageInfo_ReplicationState.Size(m)
+}
+func (m *ReplicationState) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplicationState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplicationState proto.InternalMessageInfo
+
+func (m *ReplicationState) GetMasterAddr() string {
+	if m != nil {
+		return m.MasterAddr
+	}
+	return ""
+}
+
+func (m *ReplicationState) GetReplicaID() string {
+	if m != nil {
+		return m.ReplicaID
+	}
+	return ""
+}
+
+func (m *ReplicationState) GetAppliedChangeNumber() uint64 {
+	if m != nil {
+		return m.AppliedChangeNumber
+	}
+	return 0
+}
+
+func (m *ReplicationState) GetLastPollTimeMillis() int64 {
+	if m != nil {
+		return m.LastPollTimeMillis
+	}
+	return 0
+}
+
+type PauseReplicationRequest struct {
+	// AutoResumeAfterSecs is the duration, in seconds, after which the replication
+	// is automatically resumed. Replication is paused indefinitely when it is zero.
+	AutoResumeAfterSecs  uint32   `protobuf:"varint,1,opt,name=autoResumeAfterSecs,proto3" json:"autoResumeAfterSecs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PauseReplicationRequest) Reset()         { *m = PauseReplicationRequest{} }
+func (m *PauseReplicationRequest) String() string { return proto.CompactTextString(m) }
+func (*PauseReplicationRequest) ProtoMessage()    {}
+func (*PauseReplicationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{48}
+}
+
+func (m *PauseReplicationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PauseReplicationRequest.Unmarshal(m, b)
+}
+func (m *PauseReplicationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PauseReplicationRequest.Marshal(b, m, deterministic)
+}
+func (m *PauseReplicationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PauseReplicationRequest.Merge(m, src)
+}
+func (m *PauseReplicationRequest) XXX_Size() int {
+	return xxx_messageInfo_PauseReplicationRequest.Size(m)
+}
+func (m *PauseReplicationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PauseReplicationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PauseReplicationRequest proto.InternalMessageInfo
+
+func (m *PauseReplicationRequest) GetAutoResumeAfterSecs() uint32 {
+	if m != nil {
+		return m.AutoResumeAfterSecs
+	}
+	return 0
+}
+
+type ResumeReplicationRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResumeReplicationRequest) Reset()         { *m = ResumeReplicationRequest{} }
+func (m *ResumeReplicationRequest) String() string { return proto.CompactTextString(m) }
+func (*ResumeReplicationRequest) ProtoMessage()    {}
+func (*ResumeReplicationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{49}
+}
+
+func (m *ResumeReplicationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResumeReplicationRequest.Unmarshal(m, b)
+}
+func (m *ResumeReplicationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResumeReplicationRequest.Marshal(b, m, deterministic)
+}
+func (m *ResumeReplicationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResumeReplicationRequest.Merge(m, src)
+}
+func (m *ResumeReplicationRequest) XXX_Size() int {
+	return xxx_messageInfo_ResumeReplicationRequest.Size(m)
+}
+func (m *ResumeReplicationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResumeReplicationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResumeReplicationRequest proto.InternalMessageInfo
+
+type PromoteToMasterRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PromoteToMasterRequest) Reset()         { *m = PromoteToMasterRequest{} }
+func (m *PromoteToMasterRequest) String() string { return proto.CompactTextString(m) }
+func (*PromoteToMasterRequest) ProtoMessage()    {}
+func (*PromoteToMasterRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{50}
+}
+
+func (m *PromoteToMasterRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PromoteToMasterRequest.Unmarshal(m, b)
+}
+func (m *PromoteToMasterRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PromoteToMasterRequest.Marshal(b, m, deterministic)
+}
+func (m *PromoteToMasterRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromoteToMasterRequest.Merge(m, src)
+}
+func (m *PromoteToMasterRequest) XXX_Size() int {
+	return xxx_messageInfo_PromoteToMasterRequest.Size(m)
+}
+func (m *PromoteToMasterRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromoteToMasterRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromoteToMasterRequest proto.InternalMessageInfo
+
+type PromoteToMasterResponse struct {
+	// Status indicates the result of the PromoteToMaster operation
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// AppliedChangeNumber is the change number of the latest change applied
+	// on the slave node before its promotion
+	AppliedChangeNumber  uint64   `protobuf:"varint,2,opt,name=appliedChangeNumber,proto3" json:"appliedChangeNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PromoteToMasterResponse) Reset()         { *m = PromoteToMasterResponse{} }
+func (m *PromoteToMasterResponse) String() string { return proto.CompactTextString(m) }
+func (*PromoteToMasterResponse) ProtoMessage()    {}
+func (*PromoteToMasterResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{51}
+}
+
+func (m *PromoteToMasterResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PromoteToMasterResponse.Unmarshal(m, b)
+}
+func (m *PromoteToMasterResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PromoteToMasterResponse.Marshal(b, m, deterministic)
+}
+func (m *PromoteToMasterResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromoteToMasterResponse.Merge(m, src)
+}
+func (m *PromoteToMasterResponse) XXX_Size() int {
+	return xxx_messageInfo_PromoteToMasterResponse.Size(m)
+}
+func (m *PromoteToMasterResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromoteToMasterResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromoteToMasterResponse proto.InternalMessageInfo
+
+func (m *PromoteToMasterResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *PromoteToMasterResponse) GetAppliedChangeNumber() uint64 {
+	if m != nil {
+		return m.AppliedChangeNumber
+	}
+	return 0
+}
+
+type BulkLoadRequest struct {
+	// Entries are written as they are received, as with the entries of MultiPut.
+	Entries              []*PutRequest `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *BulkLoadRequest) Reset()         { *m = BulkLoadRequest{} }
+func (m *BulkLoadRequest) String() string { return proto.CompactTextString(m) }
+func (*BulkLoadRequest) ProtoMessage()    {}
+func (*BulkLoadRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{52}
+}
+
+func (m *BulkLoadRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BulkLoadRequest.Unmarshal(m, b)
+}
+func (m *BulkLoadRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BulkLoadRequest.Marshal(b, m, deterministic)
+}
+func (m *BulkLoadRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkLoadRequest.Merge(m, src)
+}
+func (m *BulkLoadRequest) XXX_Size() int {
+	return xxx_messageInfo_BulkLoadRequest.Size(m)
+}
+func (m *BulkLoadRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkLoadRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkLoadRequest proto.InternalMessageInfo
+
+func (m *BulkLoadRequest) GetEntries() []*PutRequest {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type BulkLoadResponse struct {
+	// Status indicates the result of the BulkLoad operation.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// NumberOfKeys is the number of entries written, which are retained even if
+	// the load fails midway.
+	NumberOfKeys uint64 `protobuf:"varint,2,opt,name=numberOfKeys,proto3" json:"numberOfKeys,omitempty"`
+	// ChangeNumber is that of the change marking the load, zero if none was
+	// committed.
+	ChangeNumber         uint64   `protobuf:"varint,3,opt,name=changeNumber,proto3" json:"changeNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BulkLoadResponse) Reset()         { *m = BulkLoadResponse{} }
+func (m *BulkLoadResponse) String() string { return proto.CompactTextString(m) }
+func (*BulkLoadResponse) ProtoMessage()    {}
+func (*BulkLoadResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{53}
+}
+
+func (m *BulkLoadResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BulkLoadResponse.Unmarshal(m, b)
+}
+func (m *BulkLoadResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BulkLoadResponse.Marshal(b, m, deterministic)
+}
+func (m *BulkLoadResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkLoadResponse.Merge(m, src)
+}
+func (m *BulkLoadResponse) XXX_Size() int {
+	return xxx_messageInfo_BulkLoadResponse.Size(m)
+}
+func (m *BulkLoadResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkLoadResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkLoadResponse proto.InternalMessageInfo
+
+func (m *BulkLoadResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *BulkLoadResponse) GetNumberOfKeys() uint64 {
+	if m != nil {
+		return m.NumberOfKeys
+	}
+	return 0
+}
+
+func (m *BulkLoadResponse) GetChangeNumber() uint64 {
+	if m != nil {
+		return m.ChangeNumber
+	}
+	return 0
+}
+
+type BackupRequest struct {
+	// BackupPath indicates a filesystem folder or file used for backing up the keyspace,
+	// either as a path or a file:// URI. It can also be an object storage URI of the form
+	// s3://<bucket>/<prefix> or gcs://<bucket>/<prefix>, whose credentials are configured
+	// on the server.
+	BackupPath string `protobuf:"bytes,1,opt,name=backupPath,proto3" json:"backupPath,omitempty"`
+	// Namespace, if not empty, restricts the backup to the keys of this namespace.
+	// Such backups are always written as a single file.
+	Namespace            string   `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupRequest) Reset()         { *m = BackupRequest{} }
+func (m *BackupRequest) String() string { return proto.CompactTextString(m) }
+func (*BackupRequest) ProtoMessage()    {}
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{54}
+}
+
+func (m *BackupRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupRequest.Unmarshal(m, b)
+}
+func (m *BackupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupRequest.Marshal(b, m, deterministic)
+}
+func (m *BackupRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupRequest.Merge(m, src)
+}
+func (m *BackupRequest) XXX_Size() int {
+	return xxx_messageInfo_BackupRequest.Size(m)
+}
+func (m *BackupRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupRequest proto.InternalMessageInfo
+
+func (m *BackupRequest) GetBackupPath() string {
+	if m != nil {
+		return m.BackupPath
+	}
+	return ""
+}
+
+func (m *BackupRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type RestoreRequest struct {
+	// RestorePath indicates a filesystem folder or file used for restoring the keyspace,
+	// or an object storage URI, as per the BackupPath of the BackupRequest.
+	RestorePath string `protobuf:"bytes,1,opt,name=restorePath,proto3" json:"restorePath,omitempty"`
+	// Namespace, if not empty, restricts the restore to the keys of this namespace,
+	// which are replaced by those of a backup restricted to a namespace.
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// DryRun, if set, only verifies the backup without replacing any key,
+	// and reports the outcome of the restore through the BackupInfo of
+	// the status of its job.
+	DryRun               bool     `protobuf:"varint,3,opt,name=dryRun,proto3" json:"dryRun,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RestoreRequest) Reset()         { *m = RestoreRequest{} }
+func (m *RestoreRequest) String() string { return proto.CompactTextString(m) }
+func (*RestoreRequest) ProtoMessage()    {}
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{55}
+}
+
+func (m *RestoreRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreRequest.Unmarshal(m, b)
+}
+func (m *RestoreRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreRequest.Marshal(b, m, deterministic)
+}
+func (m *RestoreRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreRequest.Merge(m, src)
+}
+func (m *RestoreRequest) XXX_Size() int {
+	return xxx_messageInfo_RestoreRequest.Size(m)
+}
+func (m *RestoreRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RestoreRequest proto.InternalMessageInfo
+
+func (m *RestoreRequest) GetRestorePath() string {
+	if m != nil {
+		return m.RestorePath
+	}
+	return ""
+}
+
+func (m *RestoreRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *RestoreRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type BackupResponse struct {
+	// Status indicates the result of beginning the job.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// JobID identifies the job on the DKV node.
+	JobID                uint64   `protobuf:"varint,2,opt,name=jobID,proto3" json:"jobID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupResponse) Reset()         { *m = BackupResponse{} }
+func (m *BackupResponse) String() string { return proto.CompactTextString(m) }
+func (*BackupResponse) ProtoMessage()    {}
+func (*BackupResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{56}
+}
+
+func (m *BackupResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupResponse.Unmarshal(m, b)
+}
+func (m *BackupResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupResponse.Marshal(b, m, deterministic)
+}
+func (m *BackupResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupResponse.Merge(m, src)
+}
+func (m *BackupResponse) XXX_Size() int {
+	return xxx_messageInfo_BackupResponse.Size(m)
+}
+func (m *BackupResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupResponse proto.InternalMessageInfo
+
+func (m *BackupResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *BackupResponse) GetJobID() uint64 {
+	if m != nil {
+		return m.JobID
+	}
+	return 0
+}
+
+type GetBackupStatusRequest struct {
+	// JobID identifies the job, as returned when it began.
+	JobID                uint64   `protobuf:"varint,1,opt,name=jobID,proto3" json:"jobID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBackupStatusRequest) Reset()         { *m = GetBackupStatusRequest{} }
+func (m *GetBackupStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetBackupStatusRequest) ProtoMessage()    {}
+func (*GetBackupStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{57}
+}
+
+func (m *GetBackupStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBackupStatusRequest.Unmarshal(m, b)
+}
+func (m *GetBackupStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBackupStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *GetBackupStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBackupStatusRequest.Merge(m, src)
+}
+func (m *GetBackupStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetBackupStatusRequest.Size(m)
+}
+func (m *GetBackupStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBackupStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBackupStatusRequest proto.InternalMessageInfo
+
+func (m *GetBackupStatusRequest) GetJobID() uint64 {
+	if m != nil {
+		return m.JobID
+	}
+	return 0
+}
+
+type GetBackupStatusResponse struct {
+	// Status indicates the result of the GetBackupStatus operation, which
+	// fails for the IDs of jobs unknown to the DKV node.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	JobID  uint64  `protobuf:"varint,2,opt,name=jobID,proto3" json:"jobID,omitempty"`
+	// Restore is set for restore jobs, and not set for backup jobs.
+	Restore bool `protobuf:"varint,3,opt,name=restore,proto3" json:"restore,omitempty"`
+	// Path is the location of the backup.
+	Path      string         `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	Namespace string         `protobuf:"bytes,5,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	State     BackupJobState `protobuf:"varint,6,opt,name=state,proto3,enum=dkv.serverpb.BackupJobState" json:"state,omitempty"`
+	// BytesWritten is the size (in bytes) of the backup written so far onto
+	// the local filesystem, including those staged for object storage. It
+	// is not tracked for restore jobs.
+	BytesWritten uint64 `protobuf:"varint,7,opt,name=bytesWritten,proto3" json:"bytesWritten,omitempty"`
+	// KeysProcessed is the number of keys backed up or restored so far.
+	// It is not tracked for the backups of the entire keyspace, which are
+	// taken by the storage engine, nor for their restores except onto the
+	// in-memory engine, where it is known only once the restore is done.
+	KeysProcessed uint64 `protobuf:"varint,8,opt,name=keysProcessed,proto3" json:"keysProcessed,omitempty"`
+	// Error describes the failure of the job, if it failed.
+	Error string `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	// DryRun is set for restore jobs that only verify their backup.
+	DryRun bool `protobuf:"varint,10,opt,name=dryRun,proto3" json:"dryRun,omitempty"`
+	// BackupInfo describes the backup once it is verified by a dry run.
+	BackupInfo *BackupInfo `protobuf:"bytes,11,opt,name=backupInfo,proto3" json:"backupInfo,omitempty"`
+	// BytesRestored is the size (in bytes) of the part of the backup ingested
+	// so far by a restore job, out of TotalBytes. Restores restricted to a
+	// namespace, or of streamed backups, measure the sizes of the keys and
+	// values stored instead. Neither is tracked for backup jobs.
+	BytesRestored        uint64   `protobuf:"varint,12,opt,name=bytesRestored,proto3" json:"bytesRestored,omitempty"`
+	TotalBytes           uint64   `protobuf:"varint,13,opt,name=totalBytes,proto3" json:"totalBytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBackupStatusResponse) Reset()         { *m = GetBackupStatusResponse{} }
+func (m *GetBackupStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*GetBackupStatusResponse) ProtoMessage()    {}
+func (*GetBackupStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{58}
+}
+
+func (m *GetBackupStatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBackupStatusResponse.Unmarshal(m, b)
+}
+func (m *GetBackupStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBackupStatusResponse.Marshal(b, m, deterministic)
+}
+func (m *GetBackupStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBackupStatusResponse.Merge(m, src)
+}
+func (m *GetBackupStatusResponse) XXX_Size() int {
+	return xxx_messageInfo_GetBackupStatusResponse.Size(m)
+}
+func (m *GetBackupStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBackupStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBackupStatusResponse proto.InternalMessageInfo
+
+func (m *GetBackupStatusResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetBackupStatusResponse) GetJobID() uint64 {
+	if m != nil {
+		return m.JobID
+	}
+	return 0
+}
+
+func (m *GetBackupStatusResponse) GetRestore() bool {
+	if m != nil {
+		return m.Restore
+	}
+	return false
+}
+
+func (m *GetBackupStatusResponse) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *GetBackupStatusResponse) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *GetBackupStatusResponse) GetState() BackupJobState {
+	if m != nil {
+		return m.State
+	}
+	return BackupJobState_Running
+}
+
+func (m *GetBackupStatusResponse) GetBytesWritten() uint64 {
+	if m != nil {
+		return m.BytesWritten
+	}
+	return 0
+}
+
+func (m *GetBackupStatusResponse) GetKeysProcessed() uint64 {
+	if m != nil {
+		return m.KeysProcessed
+	}
+	return 0
+}
+
+func (m *GetBackupStatusResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *GetBackupStatusResponse) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+func (m *GetBackupStatusResponse) GetBackupInfo() *BackupInfo {
+	if m != nil {
+		return m.BackupInfo
+	}
+	return nil
+}
+
+func (m *GetBackupStatusResponse) GetBytesRestored() uint64 {
+	if m != nil {
+		return m.BytesRestored
+	}
+	return 0
+}
+
+func (m *GetBackupStatusResponse) GetTotalBytes() uint64 {
+	if m != nil {
+		return m.TotalBytes
+	}
+	return 0
+}
+
+type BackupInfo struct {
+	// Engine is the storage engine that took the backup, or "namespace"
+	// for the backups of namespaces, which can be restored onto any engine.
+	Engine string `protobuf:"bytes,1,opt,name=engine,proto3" json:"engine,omitempty"`
+	// ChangeNumber is the change number of the keyspace as of the backup.
+	// It is not tracked for the backups of namespaces, whose restores are
+	// committed as fresh changes.
+	ChangeNumber uint64 `protobuf:"varint,2,opt,name=changeNumber,proto3" json:"changeNumber,omitempty"`
+	// CurrentChangeNumber is the change number of the DKV node as of the
+	// verification of the backup.
+	CurrentChangeNumber uint64 `protobuf:"varint,3,opt,name=currentChangeNumber,proto3" json:"currentChangeNumber,omitempty"`
+	// NumberOfKeys is the number of keys in the backup. It is only known
+	// for the backups of namespaces and of in-memory stores.
+	NumberOfKeys uint64 `protobuf:"varint,4,opt,name=numberOfKeys,proto3" json:"numberOfKeys,omitempty"`
+	// Size is the size (in bytes) of the backup.
+	Size                 uint64   `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupInfo) Reset()         { *m = BackupInfo{} }
+func (m *BackupInfo) String() string { return proto.CompactTextString(m) }
+func (*BackupInfo) ProtoMessage()    {}
+func (*BackupInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{59}
+}
+
+func (m *BackupInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupInfo.Unmarshal(m, b)
+}
+func (m *BackupInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupInfo.Marshal(b, m, deterministic)
+}
+func (m *BackupInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupInfo.Merge(m, src)
+}
+func (m *BackupInfo) XXX_Size() int {
+	return xxx_messageInfo_BackupInfo.Size(m)
+}
+func (m *BackupInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupInfo proto.InternalMessageInfo
+
+func (m *BackupInfo) GetEngine() string {
+	if m != nil {
+		return m.Engine
+	}
+	return ""
+}
+
+func (m *BackupInfo) GetChangeNumber() uint64 {
+	if m != nil {
+		return m.ChangeNumber
+	}
+	return 0
+}
+
+func (m *BackupInfo) GetCurrentChangeNumber() uint64 {
+	if m != nil {
+		return m.CurrentChangeNumber
+	}
+	return 0
+}
+
+func (m *BackupInfo) GetNumberOfKeys() uint64 {
+	if m != nil {
+		return m.NumberOfKeys
+	}
+	return 0
+}
+
+func (m *BackupInfo) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type StreamBackupRequest struct {
+	// Namespace, if not empty, restricts the backup to the keys of this namespace.
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamBackupRequest) Reset()         { *m = StreamBackupRequest{} }
+func (m *StreamBackupRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamBackupRequest) ProtoMessage()    {}
+func (*StreamBackupRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{60}
+}
+
+func (m *StreamBackupRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamBackupRequest.Unmarshal(m, b)
+}
+func (m *StreamBackupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamBackupRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamBackupRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamBackupRequest.Merge(m, src)
+}
+func (m *StreamBackupRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamBackupRequest.Size(m)
+}
+func (m *StreamBackupRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamBackupRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamBackupRequest proto.InternalMessageInfo
+
+func (m *StreamBackupRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type BackupHeader struct {
+	// Version of the format of the backup.
+	Version uint32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// ChangeNumber is the latest change committed onto the keyspace before
+	// the backup began, hence the backup reflects atleast all the changes
+	// till this change number. It is zero for stores without a change log.
+	ChangeNumber uint64 `protobuf:"varint,2,opt,name=changeNumber,proto3" json:"changeNumber,omitempty"`
+	// Namespace of the keys in the backup, which is empty for backups of
+	// the entire keyspace.
+	Namespace            string   `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupHeader) Reset()         { *m = BackupHeader{} }
+func (m *BackupHeader) String() string { return proto.CompactTextString(m) }
+func (*BackupHeader) ProtoMessage()    {}
+func (*BackupHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{61}
+}
+
+func (m *BackupHeader) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupHeader.Unmarshal(m, b)
+}
+func (m *BackupHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupHeader.Marshal(b, m, deterministic)
+}
+func (m *BackupHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupHeader.Merge(m, src)
+}
+func (m *BackupHeader) XXX_Size() int {
+	return xxx_messageInfo_BackupHeader.Size(m)
+}
+func (m *BackupHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupHeader proto.InternalMessageInfo
+
+func (m *BackupHeader) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *BackupHeader) GetChangeNumber() uint64 {
+	if m != nil {
+		return m.ChangeNumber
+	}
+	return 0
+}
+
+func (m *BackupHeader) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type BackupChunk struct {
+	// Header is set only on the first chunk, which carries no entries.
+	Header *BackupHeader `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// SequenceNumber of the chunk, starting from zero for the header chunk.
+	SequenceNumber uint64 `protobuf:"varint,2,opt,name=sequenceNumber,proto3" json:"sequenceNumber,omitempty"`
+	// Entries of the keyspace carried by this chunk, with their keys within
+	// the namespace of the backup and their values as stored.
+	Entries []*PutRequest `protobuf:"bytes,3,rep,name=entries,proto3" json:"entries,omitempty"`
+	// Checksum is the CRC-32C of the keys, values and expiry times of the entries.
+	Checksum uint32 `protobuf:"fixed32,4,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	// Last is set only on the final chunk, which carries no entries.
+	Last bool `protobuf:"varint,5,opt,name=last,proto3" json:"last,omitempty"`
+	// NumberOfEntries is the total number of entries in the backup, carried
+	// by the final chunk.
+	NumberOfEntries      uint64   `protobuf:"varint,6,opt,name=numberOfEntries,proto3" json:"numberOfEntries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupChunk) Reset()         { *m = BackupChunk{} }
+func (m *BackupChunk) String() string { return proto.CompactTextString(m) }
+func (*BackupChunk) ProtoMessage()    {}
+func (*BackupChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{62}
+}
+
+func (m *BackupChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupChunk.Unmarshal(m, b)
+}
+func (m *BackupChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupChunk.Marshal(b, m, deterministic)
+}
+func (m *BackupChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupChunk.Merge(m, src)
+}
+func (m *BackupChunk) XXX_Size() int {
+	return xxx_messageInfo_BackupChunk.Size(m)
+}
+func (m *BackupChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupChunk proto.InternalMessageInfo
+
+func (m *BackupChunk) GetHeader() *BackupHeader {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *BackupChunk) GetSequenceNumber() uint64 {
+	if m != nil {
+		return m.SequenceNumber
+	}
+	return 0
+}
+
+func (m *BackupChunk) GetEntries() []*PutRequest {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *BackupChunk) GetChecksum() uint32 {
+	if m != nil {
+		return m.Checksum
+	}
+	return 0
+}
+
+func (m *BackupChunk) GetLast() bool {
+	if m != nil {
+		return m.Last
+	}
+	return false
+}
+
+func (m *BackupChunk) GetNumberOfEntries() uint64 {
+	if m != nil {
+		return m.NumberOfEntries
+	}
+	return 0
+}
+
+type StreamRestoreRequest struct {
+	// Namespace, if not empty, restricts the restore to the keys of this namespace,
+	// which are replaced by those of a backup restricted to a namespace. Only the
+	// namespace given with the first chunk is considered.
+	Namespace            string       `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Chunk                *BackupChunk `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *StreamRestoreRequest) Reset()         { *m = StreamRestoreRequest{} }
+func (m *StreamRestoreRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamRestoreRequest) ProtoMessage()    {}
+func (*StreamRestoreRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{63}
+}
+
+func (m *StreamRestoreRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamRestoreRequest.Unmarshal(m, b)
+}
+func (m *StreamRestoreRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamRestoreRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamRestoreRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamRestoreRequest.Merge(m, src)
+}
+func (m *StreamRestoreRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamRestoreRequest.Size(m)
+}
+func (m *StreamRestoreRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamRestoreRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamRestoreRequest proto.InternalMessageInfo
+
+func (m *StreamRestoreRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *StreamRestoreRequest) GetChunk() *BackupChunk {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+type AddNodeRequest struct {
+	// NodeId represents the identifier of the node that needs to
+	// be added to the cluster.
+	NodeId uint32 `protobuf:"varint,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	// NodeUrl represents the URL for the Nexus service running on
+	// the node that needs to be added to the cluster. Other Nexus
+	// peers communicate with this node on this service.
+	NodeUrl              string   `protobuf:"bytes,2,opt,name=nodeUrl,proto3" json:"nodeUrl,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddNodeRequest) Reset()         { *m = AddNodeRequest{} }
+func (m *AddNodeRequest) String() string { return proto.CompactTextString(m) }
+func (*AddNodeRequest) ProtoMessage()    {}
+func (*AddNodeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{64}
+}
+
+func (m *AddNodeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddNodeRequest.Unmarshal(m, b)
+}
+func (m *AddNodeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddNodeRequest.Marshal(b, m, deterministic)
+}
+func (m *AddNodeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddNodeRequest.Merge(m, src)
+}
+func (m *AddNodeRequest) XXX_Size() int {
+	return xxx_messageInfo_AddNodeRequest.Size(m)
+}
+func (m *AddNodeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddNodeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddNodeRequest proto.InternalMessageInfo
+
+func (m *AddNodeRequest) GetNodeId() uint32 {
+	if m != nil {
+		return m.NodeId
+	}
+	return 0
+}
+
+func (m *AddNodeRequest) GetNodeUrl() string {
+	if m != nil {
+		return m.NodeUrl
+	}
+	return ""
+}
+
+type RemoveNodeRequest struct {
+	// NodeId represents the identifier of the node that needs to
+	// be removed from the cluster.
+	NodeId               uint32   `protobuf:"varint,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveNodeRequest) Reset()         { *m = RemoveNodeRequest{} }
+func (m *RemoveNodeRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveNodeRequest) ProtoMessage()    {}
+func (*RemoveNodeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{65}
+}
+
+func (m *RemoveNodeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveNodeRequest.Unmarshal(m, b)
+}
+func (m *RemoveNodeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveNodeRequest.Marshal(b, m, deterministic)
+}
+func (m *RemoveNodeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveNodeRequest.Merge(m, src)
+}
+func (m *RemoveNodeRequest) XXX_Size() int {
+	return xxx_messageInfo_RemoveNodeRequest.Size(m)
+}
+func (m *RemoveNodeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveNodeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveNodeRequest proto.InternalMessageInfo
+
+func (m *RemoveNodeRequest) GetNodeId() uint32 {
+	if m != nil {
+		return m.NodeId
+	}
+	return 0
+}
+
+type ListNodesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListNodesRequest) Reset()         { *m = ListNodesRequest{} }
+func (m *ListNodesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListNodesRequest) ProtoMessage()    {}
+func (*ListNodesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{66}
+}
+
+func (m *ListNodesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListNodesRequest.Unmarshal(m, b)
+}
+func (m *ListNodesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListNodesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListNodesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListNodesRequest.Merge(m, src)
+}
+func (m *ListNodesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListNodesRequest.Size(m)
+}
+func (m *ListNodesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListNodesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListNodesRequest proto.InternalMessageInfo
+
+type NodeInfo struct {
+	NodeId uint32 `protobuf:"varint,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	// NodeUrl is the URL of the Nexus service running on the node.
+	NodeUrl string   `protobuf:"bytes,2,opt,name=nodeUrl,proto3" json:"nodeUrl,omitempty"`
+	Role    NodeRole `protobuf:"varint,3,opt,name=role,proto3,enum=dkv.serverpb.NodeRole" json:"role,omitempty"`
+	// LastContactTS is the time (in seconds since epoch) at which the
+	// Nexus service of the node was last reached by the current node,
+	// or zero if it has never been reached.
+	LastContactTS uint64 `protobuf:"varint,4,opt,name=lastContactTS,proto3" json:"lastContactTS,omitempty"`
+	// DkvAddr is the address of the DKV service running on the node,
+	// which is empty if unknown.
+	DkvAddr              string   `protobuf:"bytes,5,opt,name=dkvAddr,proto3" json:"dkvAddr,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NodeInfo) Reset()         { *m = NodeInfo{} }
+func (m *NodeInfo) String() string { return proto.CompactTextString(m) }
+func (*NodeInfo) ProtoMessage()    {}
+func (*NodeInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{67}
+}
+
+func (m *NodeInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NodeInfo.Unmarshal(m, b)
+}
+func (m *NodeInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NodeInfo.Marshal(b, m, deterministic)
+}
+func (m *NodeInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeInfo.Merge(m, src)
+}
+func (m *NodeInfo) XXX_Size() int {
+	return xxx_messageInfo_NodeInfo.Size(m)
+}
+func (m *NodeInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodeInfo proto.InternalMessageInfo
+
+func (m *NodeInfo) GetNodeId() uint32 {
+	if m != nil {
+		return m.NodeId
+	}
+	return 0
+}
+
+func (m *NodeInfo) GetNodeUrl() string {
+	if m != nil {
+		return m.NodeUrl
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetRole() NodeRole {
+	if m != nil {
+		return m.Role
+	}
+	return NodeRole_UnknownRole
+}
+
+func (m *NodeInfo) GetLastContactTS() uint64 {
+	if m != nil {
+		return m.LastContactTS
+	}
+	return 0
+}
+
+func (m *NodeInfo) GetDkvAddr() string {
+	if m != nil {
+		return m.DkvAddr
+	}
+	return ""
+}
+
+type ListNodesResponse struct {
+	// Status indicates the result of the ListNodes operation.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// LeaderId is the identifier of the leader of the cluster, or zero
+	// if it is unknown to the current node.
+	LeaderId uint32 `protobuf:"varint,2,opt,name=leaderId,proto3" json:"leaderId,omitempty"`
+	// Nodes are the members of the cluster, in the order of their IDs.
+	Nodes                []*NodeInfo `protobuf:"bytes,3,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ListNodesResponse) Reset()         { *m = ListNodesResponse{} }
+func (m *ListNodesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListNodesResponse) ProtoMessage()    {}
+func (*ListNodesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{68}
+}
+
+func (m *ListNodesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListNodesResponse.Unmarshal(m, b)
+}
+func (m *ListNodesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListNodesResponse.Marshal(b, m, deterministic)
+}
+func (m *ListNodesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListNodesResponse.Merge(m, src)
+}
+func (m *ListNodesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListNodesResponse.Size(m)
+}
+func (m *ListNodesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListNodesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListNodesResponse proto.InternalMessageInfo
+
+func (m *ListNodesResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *ListNodesResponse) GetLeaderId() uint32 {
+	if m != nil {
+		return m.LeaderId
+	}
+	return 0
+}
+
+func (m *ListNodesResponse) GetNodes() []*NodeInfo {
+	if m != nil {
+		return m.Nodes
+	}
+	return nil
+}
+
+type DecommissionNodeRequest struct {
+	// NodeId represents the identifier of the node that needs to
+	// be decommissioned from the cluster.
+	NodeId uint32 `protobuf:"varint,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	// TimeoutSecs, if positive, is the duration (in seconds) within which
+	// the leadership must be transferred away from the node and the
+	// remaining members must catch up with its changes, failing which the
+	// decommission fails without removing the node.
+	TimeoutSecs          uint32   `protobuf:"varint,2,opt,name=timeoutSecs,proto3" json:"timeoutSecs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DecommissionNodeRequest) Reset()         { *m = DecommissionNodeRequest{} }
+func (m *DecommissionNodeRequest) String() string { return proto.CompactTextString(m) }
+func (*DecommissionNodeRequest) ProtoMessage()    {}
+func (*DecommissionNodeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{69}
+}
+
+func (m *DecommissionNodeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DecommissionNodeRequest.Unmarshal(m, b)
+}
+func (m *DecommissionNodeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DecommissionNodeRequest.Marshal(b, m, deterministic)
+}
+func (m *DecommissionNodeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecommissionNodeRequest.Merge(m, src)
+}
+func (m *DecommissionNodeRequest) XXX_Size() int {
+	return xxx_messageInfo_DecommissionNodeRequest.Size(m)
+}
+func (m *DecommissionNodeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecommissionNodeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecommissionNodeRequest proto.InternalMessageInfo
+
+func (m *DecommissionNodeRequest) GetNodeId() uint32 {
+	if m != nil {
+		return m.NodeId
+	}
+	return 0
+}
+
+func (m *DecommissionNodeRequest) GetTimeoutSecs() uint32 {
+	if m != nil {
+		return m.TimeoutSecs
+	}
+	return 0
+}
+
+type GetDecommissionStatusRequest struct {
+	NodeId               uint32   `protobuf:"varint,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetDecommissionStatusRequest) Reset()         { *m = GetDecommissionStatusRequest{} }
+func (m *GetDecommissionStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetDecommissionStatusRequest) ProtoMessage()    {}
+func (*GetDecommissionStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{70}
+}
+
+func (m *GetDecommissionStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetDecommissionStatusRequest.Unmarshal(m, b)
+}
+func (m *GetDecommissionStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetDecommissionStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *GetDecommissionStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetDecommissionStatusRequest.Merge(m, src)
+}
+func (m *GetDecommissionStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetDecommissionStatusRequest.Size(m)
+}
+func (m *GetDecommissionStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetDecommissionStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetDecommissionStatusRequest proto.InternalMessageInfo
+
+func (m *GetDecommissionStatusRequest) GetNodeId() uint32 {
+	if m != nil {
+		return m.NodeId
+	}
+	return 0
+}
+
+type GetDecommissionStatusResponse struct {
+	// Status indicates the result of the GetDecommissionStatus operation.
+	Status *Status           `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	NodeId uint32            `protobuf:"varint,2,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	State  DecommissionState `protobuf:"varint,3,opt,name=state,proto3,enum=dkv.serverpb.DecommissionState" json:"state,omitempty"`
+	// Error is the reason for the failure of the decommission.
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// TargetChangeNumber is the change number of the node that the
+	// remaining members must catch up with.
+	TargetChangeNumber uint64 `protobuf:"varint,5,opt,name=targetChangeNumber,proto3" json:"targetChangeNumber,omitempty"`
+	// MemberChangeNumbers are the latest change numbers of the remaining
+	// members, keyed by their IDs, as last observed while catching up.
+	MemberChangeNumbers  map[uint32]uint64 `protobuf:"bytes,6,rep,name=memberChangeNumbers,proto3" json:"memberChangeNumbers,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *GetDecommissionStatusResponse) Reset()         { *m = GetDecommissionStatusResponse{} }
+func (m *GetDecommissionStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*GetDecommissionStatusResponse) ProtoMessage()    {}
+func (*GetDecommissionStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{71}
+}
+
+func (m *GetDecommissionStatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetDecommissionStatusResponse.Unmarshal(m, b)
+}
+func (m *GetDecommissionStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetDecommissionStatusResponse.Marshal(b, m, deterministic)
+}
+func (m *GetDecommissionStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetDecommissionStatusResponse.Merge(m, src)
+}
+func (m *GetDecommissionStatusResponse) XXX_Size() int {
+	return xxx_messageInfo_GetDecommissionStatusResponse.Size(m)
+}
+func (m *GetDecommissionStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetDecommissionStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetDecommissionStatusResponse proto.InternalMessageInfo
+
+func (m *GetDecommissionStatusResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetDecommissionStatusResponse) GetNodeId() uint32 {
+	if m != nil {
+		return m.NodeId
+	}
+	return 0
+}
+
+func (m *GetDecommissionStatusResponse) GetState() DecommissionState {
+	if m != nil {
+		return m.State
+	}
+	return DecommissionState_TransferringLeadership
+}
+
+func (m *GetDecommissionStatusResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *GetDecommissionStatusResponse) GetTargetChangeNumber() uint64 {
+	if m != nil {
+		return m.TargetChangeNumber
+	}
+	return 0
+}
+
+func (m *GetDecommissionStatusResponse) GetMemberChangeNumbers() map[uint32]uint64 {
+	if m != nil {
+		return m.MemberChangeNumbers
+	}
+	return nil
+}
+
+type ClusterBackupRequest struct {
+	// Location is a filesystem folder or an object storage URI, as per
+	// the BackupPath of the BackupRequest, under which the manifest and
+	// the backups of the members are written. The backups of the members
+	// are written by the members themselves, hence the location must be
+	// reachable from every member.
+	Location             string   `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClusterBackupRequest) Reset()         { *m = ClusterBackupRequest{} }
+func (m *ClusterBackupRequest) String() string { return proto.CompactTextString(m) }
+func (*ClusterBackupRequest) ProtoMessage()    {}
+func (*ClusterBackupRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{72}
+}
+
+func (m *ClusterBackupRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClusterBackupRequest.Unmarshal(m, b)
+}
+func (m *ClusterBackupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClusterBackupRequest.Marshal(b, m, deterministic)
+}
+func (m *ClusterBackupRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterBackupRequest.Merge(m, src)
+}
+func (m *ClusterBackupRequest) XXX_Size() int {
+	return xxx_messageInfo_ClusterBackupRequest.Size(m)
+}
+func (m *ClusterBackupRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterBackupRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterBackupRequest proto.InternalMessageInfo
+
+func (m *ClusterBackupRequest) GetLocation() string {
+	if m != nil {
+		return m.Location
+	}
+	return ""
+}
+
+type ClusterBackupArtifact struct {
+	NodeId uint32 `protobuf:"varint,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	// Location is the location of the backup of the node.
+	Location string `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	// Info describes the backup of the node.
+	Info                 *BackupInfo `protobuf:"bytes,3,opt,name=info,proto3" json:"info,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ClusterBackupArtifact) Reset()         { *m = ClusterBackupArtifact{} }
+func (m *ClusterBackupArtifact) String() string { return proto.CompactTextString(m) }
+func (*ClusterBackupArtifact) ProtoMessage()    {}
+func (*ClusterBackupArtifact) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{73}
+}
+
+func (m *ClusterBackupArtifact) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClusterBackupArtifact.Unmarshal(m, b)
+}
+func (m *ClusterBackupArtifact) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClusterBackupArtifact.Marshal(b, m, deterministic)
+}
+func (m *ClusterBackupArtifact) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterBackupArtifact.Merge(m, src)
+}
+func (m *ClusterBackupArtifact) XXX_Size() int {
+	return xxx_messageInfo_ClusterBackupArtifact.Size(m)
+}
+func (m *ClusterBackupArtifact) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterBackupArtifact.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterBackupArtifact proto.InternalMessageInfo
+
+func (m *ClusterBackupArtifact) GetNodeId() uint32 {
+	if m != nil {
+		return m.NodeId
+	}
+	return 0
+}
+
+func (m *ClusterBackupArtifact) GetLocation() string {
+	if m != nil {
+		return m.Location
+	}
+	return ""
+}
+
+func (m *ClusterBackupArtifact) GetInfo() *BackupInfo {
+	if m != nil {
+		return m.Info
+	}
+	return nil
+}
+
+type ClusterBackupManifest struct {
+	// ChangeNumber is the change number of every member as of its backup.
+	ChangeNumber uint64 `protobuf:"varint,1,opt,name=changeNumber,proto3" json:"changeNumber,omitempty"`
+	// Artifacts are the backups of the members, in the order of their IDs.
+	Artifacts            []*ClusterBackupArtifact `protobuf:"bytes,2,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *ClusterBackupManifest) Reset()         { *m = ClusterBackupManifest{} }
+func (m *ClusterBackupManifest) String() string { return proto.CompactTextString(m) }
+func (*ClusterBackupManifest) ProtoMessage()    {}
+func (*ClusterBackupManifest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{74}
+}
+
+func (m *ClusterBackupManifest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClusterBackupManifest.Unmarshal(m, b)
+}
+func (m *ClusterBackupManifest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClusterBackupManifest.Marshal(b, m, deterministic)
+}
+func (m *ClusterBackupManifest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterBackupManifest.Merge(m, src)
+}
+func (m *ClusterBackupManifest) XXX_Size() int {
+	return xxx_messageInfo_ClusterBackupManifest.Size(m)
+}
+func (m *ClusterBackupManifest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterBackupManifest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterBackupManifest proto.InternalMessageInfo
+
+func (m *ClusterBackupManifest) GetChangeNumber() uint64 {
+	if m != nil {
+		return m.ChangeNumber
+	}
+	return 0
+}
+
+func (m *ClusterBackupManifest) GetArtifacts() []*ClusterBackupArtifact {
+	if m != nil {
+		return m.Artifacts
+	}
+	return nil
+}
+
+type ClusterBackupResponse struct {
+	// Status indicates the result of the ClusterBackup operation.
+	Status               *Status                `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Manifest             *ClusterBackupManifest `protobuf:"bytes,2,opt,name=manifest,proto3" json:"manifest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *ClusterBackupResponse) Reset()         { *m = ClusterBackupResponse{} }
+func (m *ClusterBackupResponse) String() string { return proto.CompactTextString(m) }
+func (*ClusterBackupResponse) ProtoMessage()    {}
+func (*ClusterBackupResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{75}
+}
+
+func (m *ClusterBackupResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClusterBackupResponse.Unmarshal(m, b)
+}
+func (m *ClusterBackupResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClusterBackupResponse.Marshal(b, m, deterministic)
+}
+func (m *ClusterBackupResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterBackupResponse.Merge(m, src)
+}
+func (m *ClusterBackupResponse) XXX_Size() int {
+	return xxx_messageInfo_ClusterBackupResponse.Size(m)
+}
+func (m *ClusterBackupResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterBackupResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterBackupResponse proto.InternalMessageInfo
+
+func (m *ClusterBackupResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *ClusterBackupResponse) GetManifest() *ClusterBackupManifest {
+	if m != nil {
+		return m.Manifest
+	}
+	return nil
+}
+
+type ClusterRestoreRequest struct {
+	// Location is the location given to the ClusterBackup whose
+	// manifest must be restored.
+	Location             string   `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClusterRestoreRequest) Reset()         { *m = ClusterRestoreRequest{} }
+func (m *ClusterRestoreRequest) String() string { return proto.CompactTextString(m) }
+func (*ClusterRestoreRequest) ProtoMessage()    {}
+func (*ClusterRestoreRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{76}
+}
+
+func (m *ClusterRestoreRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClusterRestoreRequest.Unmarshal(m, b)
+}
+func (m *ClusterRestoreRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClusterRestoreRequest.Marshal(b, m, deterministic)
+}
+func (m *ClusterRestoreRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterRestoreRequest.Merge(m, src)
+}
+func (m *ClusterRestoreRequest) XXX_Size() int {
+	return xxx_messageInfo_ClusterRestoreRequest.Size(m)
+}
+func (m *ClusterRestoreRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterRestoreRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterRestoreRequest proto.InternalMessageInfo
+
+func (m *ClusterRestoreRequest) GetLocation() string {
+	if m != nil {
+		return m.Location
+	}
+	return ""
+}
+
+type FenceWritesRequest struct {
+	// FenceId identifies the ClusterBackup or ClusterRestore raising the fence.
+	FenceId string `protobuf:"bytes,1,opt,name=fenceId,proto3" json:"fenceId,omitempty"`
+	// TtlSecs is the duration (in seconds) after which the fence is lifted
+	// by itself, in case the coordinating node fails to lift it.
+	TtlSecs              uint32   `protobuf:"varint,2,opt,name=ttlSecs,proto3" json:"ttlSecs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FenceWritesRequest) Reset()         { *m = FenceWritesRequest{} }
+func (m *FenceWritesRequest) String() string { return proto.CompactTextString(m) }
+func (*FenceWritesRequest) ProtoMessage()    {}
+func (*FenceWritesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{77}
+}
+
+func (m *FenceWritesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FenceWritesRequest.Unmarshal(m, b)
+}
+func (m *FenceWritesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FenceWritesRequest.Marshal(b, m, deterministic)
+}
+func (m *FenceWritesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FenceWritesRequest.Merge(m, src)
+}
+func (m *FenceWritesRequest) XXX_Size() int {
+	return xxx_messageInfo_FenceWritesRequest.Size(m)
+}
+func (m *FenceWritesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FenceWritesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FenceWritesRequest proto.InternalMessageInfo
+
+func (m *FenceWritesRequest) GetFenceId() string {
+	if m != nil {
+		return m.FenceId
+	}
+	return ""
+}
+
+func (m *FenceWritesRequest) GetTtlSecs() uint32 {
+	if m != nil {
+		return m.TtlSecs
+	}
+	return 0
+}
+
+type FenceWritesResponse struct {
+	// Status indicates the result of the FenceWrites operation.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// ChangeNumber is the latest committed change number of the node
+	// once the writes in progress are drained.
+	ChangeNumber         uint64   `protobuf:"varint,2,opt,name=changeNumber,proto3" json:"changeNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FenceWritesResponse) Reset()         { *m = FenceWritesResponse{} }
+func (m *FenceWritesResponse) String() string { return proto.CompactTextString(m) }
+func (*FenceWritesResponse) ProtoMessage()    {}
+func (*FenceWritesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{78}
+}
+
+func (m *FenceWritesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FenceWritesResponse.Unmarshal(m, b)
+}
+func (m *FenceWritesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FenceWritesResponse.Marshal(b, m, deterministic)
+}
+func (m *FenceWritesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FenceWritesResponse.Merge(m, src)
+}
+func (m *FenceWritesResponse) XXX_Size() int {
+	return xxx_messageInfo_FenceWritesResponse.Size(m)
+}
+func (m *FenceWritesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FenceWritesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FenceWritesResponse proto.InternalMessageInfo
+
+func (m *FenceWritesResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *FenceWritesResponse) GetChangeNumber() uint64 {
+	if m != nil {
+		return m.ChangeNumber
+	}
+	return 0
+}
+
+type UnfenceWritesRequest struct {
+	FenceId string `protobuf:"bytes,1,opt,name=fenceId,proto3" json:"fenceId,omitempty"`
+	// RemoveBackups, if set, removes the backups written by the node
+	// under the fence, since the ClusterBackup has failed.
+	RemoveBackups        bool     `protobuf:"varint,2,opt,name=removeBackups,proto3" json:"removeBackups,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnfenceWritesRequest) Reset()         { *m = UnfenceWritesRequest{} }
+func (m *UnfenceWritesRequest) String() string { return proto.CompactTextString(m) }
+func (*UnfenceWritesRequest) ProtoMessage()    {}
+func (*UnfenceWritesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{79}
+}
+
+func (m *UnfenceWritesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnfenceWritesRequest.Unmarshal(m, b)
+}
+func (m *UnfenceWritesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnfenceWritesRequest.Marshal(b, m, deterministic)
+}
+func (m *UnfenceWritesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnfenceWritesRequest.Merge(m, src)
+}
+func (m *UnfenceWritesRequest) XXX_Size() int {
+	return xxx_messageInfo_UnfenceWritesRequest.Size(m)
+}
+func (m *UnfenceWritesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnfenceWritesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnfenceWritesRequest proto.InternalMessageInfo
+
+func (m *UnfenceWritesRequest) GetFenceId() string {
+	if m != nil {
+		return m.FenceId
+	}
+	return ""
+}
+
+func (m *UnfenceWritesRequest) GetRemoveBackups() bool {
+	if m != nil {
+		return m.RemoveBackups
+	}
+	return false
+}
+
+type BackupMemberRequest struct {
+	FenceId string `protobuf:"bytes,1,opt,name=fenceId,proto3" json:"fenceId,omitempty"`
+	// ChangeNumber is the change number as of which the node is backed up.
+	ChangeNumber uint64 `protobuf:"varint,2,opt,name=changeNumber,proto3" json:"changeNumber,omitempty"`
+	// Location is the location of the backup of the node.
+	Location             string   `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupMemberRequest) Reset()         { *m = BackupMemberRequest{} }
+func (m *BackupMemberRequest) String() string { return proto.CompactTextString(m) }
+func (*BackupMemberRequest) ProtoMessage()    {}
+func (*BackupMemberRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{80}
+}
+
+func (m *BackupMemberRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupMemberRequest.Unmarshal(m, b)
+}
+func (m *BackupMemberRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupMemberRequest.Marshal(b, m, deterministic)
+}
+func (m *BackupMemberRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupMemberRequest.Merge(m, src)
+}
+func (m *BackupMemberRequest) XXX_Size() int {
+	return xxx_messageInfo_BackupMemberRequest.Size(m)
+}
+func (m *BackupMemberRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupMemberRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupMemberRequest proto.InternalMessageInfo
+
+func (m *BackupMemberRequest) GetFenceId() string {
+	if m != nil {
+		return m.FenceId
+	}
+	return ""
+}
+
+func (m *BackupMemberRequest) GetChangeNumber() uint64 {
+	if m != nil {
+		return m.ChangeNumber
+	}
+	return 0
+}
+
+func (m *BackupMemberRequest) GetLocation() string {
+	if m != nil {
+		return m.Location
+	}
+	return ""
+}
+
+type BackupMemberResponse struct {
+	// Status indicates the result of the BackupMember operation.
+	Status               *Status     `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Info                 *BackupInfo `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *BackupMemberResponse) Reset()         { *m = BackupMemberResponse{} }
+func (m *BackupMemberResponse) String() string { return proto.CompactTextString(m) }
+func (*BackupMemberResponse) ProtoMessage()    {}
+func (*BackupMemberResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{81}
+}
+
+func (m *BackupMemberResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupMemberResponse.Unmarshal(m, b)
+}
+func (m *BackupMemberResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupMemberResponse.Marshal(b, m, deterministic)
+}
+func (m *BackupMemberResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupMemberResponse.Merge(m, src)
+}
+func (m *BackupMemberResponse) XXX_Size() int {
+	return xxx_messageInfo_BackupMemberResponse.Size(m)
+}
+func (m *BackupMemberResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupMemberResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupMemberResponse proto.InternalMessageInfo
+
+func (m *BackupMemberResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *BackupMemberResponse) GetInfo() *BackupInfo {
+	if m != nil {
+		return m.Info
+	}
+	return nil
+}
+
+type RestoreMemberRequest struct {
+	FenceId string `protobuf:"bytes,1,opt,name=fenceId,proto3" json:"fenceId,omitempty"`
+	// ChangeNumber is the change number of the backup as per the manifest.
+	ChangeNumber uint64 `protobuf:"varint,2,opt,name=changeNumber,proto3" json:"changeNumber,omitempty"`
+	// Location is the location of the backup of the node.
+	Location             string   `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RestoreMemberRequest) Reset()         { *m = RestoreMemberRequest{} }
+func (m *RestoreMemberRequest) String() string { return proto.CompactTextString(m) }
+func (*RestoreMemberRequest) ProtoMessage()    {}
+func (*RestoreMemberRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{82}
+}
+
+func (m *RestoreMemberRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreMemberRequest.Unmarshal(m, b)
+}
+func (m *RestoreMemberRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreMemberRequest.Marshal(b, m, deterministic)
+}
+func (m *RestoreMemberRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreMemberRequest.Merge(m, src)
+}
+func (m *RestoreMemberRequest) XXX_Size() int {
+	return xxx_messageInfo_RestoreMemberRequest.Size(m)
+}
+func (m *RestoreMemberRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreMemberRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RestoreMemberRequest proto.InternalMessageInfo
+
+func (m *RestoreMemberRequest) GetFenceId() string {
+	if m != nil {
+		return m.FenceId
+	}
+	return ""
+}
+
+func (m *RestoreMemberRequest) GetChangeNumber() uint64 {
+	if m != nil {
+		return m.ChangeNumber
+	}
+	return 0
+}
+
+func (m *RestoreMemberRequest) GetLocation() string {
+	if m != nil {
+		return m.Location
+	}
+	return ""
+}
+
+type Limits struct {
+	// MethodRate is the number of calls of every method admitted per second,
+	// beyond the MethodBurst. Zero admits calls without any limit.
+	MethodRate  float64 `protobuf:"fixed64,1,opt,name=methodRate,proto3" json:"methodRate,omitempty"`
+	MethodBurst uint32  `protobuf:"varint,2,opt,name=methodBurst,proto3" json:"methodBurst,omitempty"`
+	// TokenRate is the number of calls carrying every bearer token admitted per
+	// second, across all methods, beyond the TokenBurst. Zero admits calls
+	// without any limit.
+	TokenRate  float64 `protobuf:"fixed64,3,opt,name=tokenRate,proto3" json:"tokenRate,omitempty"`
+	TokenBurst uint32  `protobuf:"varint,4,opt,name=tokenBurst,proto3" json:"tokenBurst,omitempty"`
+	// ReplicationRate is the number of calls of the replication methods admitted
+	// per second, beyond the ReplicationBurst. These methods are exempt from the
+	// other limits, so that slave nodes keep up regardless of the load of clients.
+	// Zero admits calls without any limit.
+	ReplicationRate  float64 `protobuf:"fixed64,5,opt,name=replicationRate,proto3" json:"replicationRate,omitempty"`
+	ReplicationBurst uint32  `protobuf:"varint,6,opt,name=replicationBurst,proto3" json:"replicationBurst,omitempty"`
+	// MaxInFlight is the maximum number of calls, other than those of the
+	// replication methods, served concurrently. Zero admits calls without any limit.
+	MaxInFlight          uint32   `protobuf:"varint,7,opt,name=maxInFlight,proto3" json:"maxInFlight,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Limits) Reset()         { *m = Limits{} }
+func (m *Limits) String() string { return proto.CompactTextString(m) }
+func (*Limits) ProtoMessage()    {}
+func (*Limits) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{83}
+}
+
+func (m *Limits) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Limits.Unmarshal(m, b)
+}
+func (m *Limits) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Limits.Marshal(b, m, deterministic)
+}
+func (m *Limits) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Limits.Merge(m, src)
+}
+func (m *Limits) XXX_Size() int {
+	return xxx_messageInfo_Limits.Size(m)
+}
+func (m *Limits) XXX_DiscardUnknown() {
+	xxx_messageInfo_Limits.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Limits proto.InternalMessageInfo
+
+func (m *Limits) GetMethodRate() float64 {
+	if m != nil {
+		return m.MethodRate
+	}
+	return 0
+}
+
+func (m *Limits) GetMethodBurst() uint32 {
+	if m != nil {
+		return m.MethodBurst
+	}
+	return 0
+}
+
+func (m *Limits) GetTokenRate() float64 {
+	if m != nil {
+		return m.TokenRate
+	}
+	return 0
+}
+
+func (m *Limits) GetTokenBurst() uint32 {
+	if m != nil {
+		return m.TokenBurst
+	}
+	return 0
+}
+
+func (m *Limits) GetReplicationRate() float64 {
+	if m != nil {
+		return m.ReplicationRate
+	}
+	return 0
+}
+
+func (m *Limits) GetReplicationBurst() uint32 {
+	if m != nil {
+		return m.ReplicationBurst
+	}
+	return 0
+}
+
+func (m *Limits) GetMaxInFlight() uint32 {
+	if m != nil {
+		return m.MaxInFlight
+	}
+	return 0
+}
+
+type GetLimitsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetLimitsRequest) Reset()         { *m = GetLimitsRequest{} }
+func (m *GetLimitsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetLimitsRequest) ProtoMessage()    {}
+func (*GetLimitsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{84}
+}
+
+func (m *GetLimitsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLimitsRequest.Unmarshal(m, b)
+}
+func (m *GetLimitsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLimitsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetLimitsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLimitsRequest.Merge(m, src)
+}
+func (m *GetLimitsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetLimitsRequest.Size(m)
+}
+func (m *GetLimitsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLimitsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLimitsRequest proto.InternalMessageInfo
+
+type GetLimitsResponse struct {
+	// Status indicates the result of the GetLimits operation.
+	Status               *Status  `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Limits               *Limits  `protobuf:"bytes,2,opt,name=limits,proto3" json:"limits,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetLimitsResponse) Reset()         { *m = GetLimitsResponse{} }
+func (m *GetLimitsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetLimitsResponse) ProtoMessage()    {}
+func (*GetLimitsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{85}
+}
+
+func (m *GetLimitsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLimitsResponse.Unmarshal(m, b)
+}
+func (m *GetLimitsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLimitsResponse.Marshal(b, m, deterministic)
+}
+func (m *GetLimitsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLimitsResponse.Merge(m, src)
+}
+func (m *GetLimitsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetLimitsResponse.Size(m)
+}
+func (m *GetLimitsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLimitsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLimitsResponse proto.InternalMessageInfo
+
+func (m *GetLimitsResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetLimitsResponse) GetLimits() *Limits {
+	if m != nil {
+		return m.Limits
+	}
+	return nil
+}
+
+type SetLimitsRequest struct {
+	Limits               *Limits  `protobuf:"bytes,1,opt,name=limits,proto3" json:"limits,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetLimitsRequest) Reset()         { *m = SetLimitsRequest{} }
+func (m *SetLimitsRequest) String() string { return proto.CompactTextString(m) }
+func (*SetLimitsRequest) ProtoMessage()    {}
+func (*SetLimitsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{86}
+}
+
+func (m *SetLimitsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetLimitsRequest.Unmarshal(m, b)
+}
+func (m *SetLimitsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetLimitsRequest.Marshal(b, m, deterministic)
+}
+func (m *SetLimitsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetLimitsRequest.Merge(m, src)
+}
+func (m *SetLimitsRequest) XXX_Size() int {
+	return xxx_messageInfo_SetLimitsRequest.Size(m)
+}
+func (m *SetLimitsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetLimitsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetLimitsRequest proto.InternalMessageInfo
+
+func (m *SetLimitsRequest) GetLimits() *Limits {
+	if m != nil {
+		return m.Limits
+	}
+	return nil
+}
+
+type GetStorageStatsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStorageStatsRequest) Reset()         { *m = GetStorageStatsRequest{} }
+func (m *GetStorageStatsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetStorageStatsRequest) ProtoMessage()    {}
+func (*GetStorageStatsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{87}
+}
+
+func (m *GetStorageStatsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStorageStatsRequest.Unmarshal(m, b)
+}
+func (m *GetStorageStatsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStorageStatsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetStorageStatsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStorageStatsRequest.Merge(m, src)
+}
+func (m *GetStorageStatsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetStorageStatsRequest.Size(m)
+}
+func (m *GetStorageStatsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStorageStatsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStorageStatsRequest proto.InternalMessageInfo
+
+type GetStorageStatsResponse struct {
+	// Status indicates the result of the GetStorageStats operation.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Engine is the name of the storage engine, like rocksdb.
+	Engine string `protobuf:"bytes,2,opt,name=engine,proto3" json:"engine,omitempty"`
+	// ApproximateNumberOfKeys is an estimate of the number of keys stored, which
+	// may include the keys that are deleted or expired but not yet compacted.
+	ApproximateNumberOfKeys uint64 `protobuf:"varint,3,opt,name=approximateNumberOfKeys,proto3" json:"approximateNumberOfKeys,omitempty"`
+	// DiskUsageBytes is the total size of the files held by the storage engine.
+	DiskUsageBytes uint64 `protobuf:"varint,4,opt,name=diskUsageBytes,proto3" json:"diskUsageBytes,omitempty"`
+	// LastCompactionTimeMillis is the time in milliseconds since epoch at which
+	// the latest compaction of the storage engine completed, zero if unknown.
+	LastCompactionTimeMillis int64 `protobuf:"varint,5,opt,name=lastCompactionTimeMillis,proto3" json:"lastCompactionTimeMillis,omitempty"`
+	// EngineStats are the statistics specific to the storage engine by name.
+	EngineStats map[string]string `protobuf:"bytes,6,rep,name=engineStats,proto3" json:"engineStats,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Compaction is the status of the latest manual compaction, if any.
+	Compaction           *CompactionStatus `protobuf:"bytes,7,opt,name=compaction,proto3" json:"compaction,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *GetStorageStatsResponse) Reset()         { *m = GetStorageStatsResponse{} }
+func (m *GetStorageStatsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetStorageStatsResponse) ProtoMessage()    {}
+func (*GetStorageStatsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{88}
+}
+
+func (m *GetStorageStatsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStorageStatsResponse.Unmarshal(m, b)
+}
+func (m *GetStorageStatsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStorageStatsResponse.Marshal(b, m, deterministic)
+}
+func (m *GetStorageStatsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStorageStatsResponse.Merge(m, src)
+}
+func (m *GetStorageStatsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetStorageStatsResponse.Size(m)
+}
+func (m *GetStorageStatsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStorageStatsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStorageStatsResponse proto.InternalMessageInfo
+
+func (m *GetStorageStatsResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetStorageStatsResponse) GetEngine() string {
+	if m != nil {
+		return m.Engine
+	}
+	return ""
+}
+
+func (m *GetStorageStatsResponse) GetApproximateNumberOfKeys() uint64 {
+	if m != nil {
+		return m.ApproximateNumberOfKeys
+	}
+	return 0
+}
+
+func (m *GetStorageStatsResponse) GetDiskUsageBytes() uint64 {
+	if m != nil {
+		return m.DiskUsageBytes
+	}
+	return 0
+}
+
+func (m *GetStorageStatsResponse) GetLastCompactionTimeMillis() int64 {
+	if m != nil {
+		return m.LastCompactionTimeMillis
+	}
+	return 0
+}
+
+func (m *GetStorageStatsResponse) GetEngineStats() map[string]string {
+	if m != nil {
+		return m.EngineStats
+	}
+	return nil
+}
+
+func (m *GetStorageStatsResponse) GetCompaction() *CompactionStatus {
+	if m != nil {
+		return m.Compaction
+	}
+	return nil
+}
+
+type CompactRangeRequest struct {
+	// StartKey is the first key of the range to compact, which is unbounded if empty.
+	StartKey []byte `protobuf:"bytes,1,opt,name=startKey,proto3" json:"startKey,omitempty"`
+	// EndKey is the last key of the range to compact, which is unbounded if empty.
+	EndKey               []byte   `protobuf:"bytes,2,opt,name=endKey,proto3" json:"endKey,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompactRangeRequest) Reset()         { *m = CompactRangeRequest{} }
+func (m *CompactRangeRequest) String() string { return proto.CompactTextString(m) }
+func (*CompactRangeRequest) ProtoMessage()    {}
+func (*CompactRangeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{89}
+}
+
+func (m *CompactRangeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompactRangeRequest.Unmarshal(m, b)
+}
+func (m *CompactRangeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompactRangeRequest.Marshal(b, m, deterministic)
+}
+func (m *CompactRangeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompactRangeRequest.Merge(m, src)
+}
+func (m *CompactRangeRequest) XXX_Size() int {
+	return xxx_messageInfo_CompactRangeRequest.Size(m)
+}
+func (m *CompactRangeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompactRangeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompactRangeRequest proto.InternalMessageInfo
+
+func (m *CompactRangeRequest) GetStartKey() []byte {
+	if m != nil {
+		return m.StartKey
+	}
+	return nil
+}
+
+func (m *CompactRangeRequest) GetEndKey() []byte {
+	if m != nil {
+		return m.EndKey
+	}
+	return nil
+}
+
+type CompactionStatus struct {
+	StartKey []byte `protobuf:"bytes,1,opt,name=startKey,proto3" json:"startKey,omitempty"`
+	EndKey   []byte `protobuf:"bytes,2,opt,name=endKey,proto3" json:"endKey,omitempty"`
+	// Running indicates whether the compaction is yet to complete.
+	Running bool `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`
+	// StartTimeMillis and EndTimeMillis are the times in milliseconds since epoch
+	// at which the compaction started and completed, the latter zero if running.
+	StartTimeMillis int64 `protobuf:"varint,4,opt,name=startTimeMillis,proto3" json:"startTimeMillis,omitempty"`
+	EndTimeMillis   int64 `protobuf:"varint,5,opt,name=endTimeMillis,proto3" json:"endTimeMillis,omitempty"`
+	// Error is the reason the compaction failed, empty if it did not.
+	Error                string   `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompactionStatus) Reset()         { *m = CompactionStatus{} }
+func (m *CompactionStatus) String() string { return proto.CompactTextString(m) }
+func (*CompactionStatus) ProtoMessage()    {}
+func (*CompactionStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{90}
+}
+
+func (m *CompactionStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompactionStatus.Unmarshal(m, b)
+}
+func (m *CompactionStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompactionStatus.Marshal(b, m, deterministic)
+}
+func (m *CompactionStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompactionStatus.Merge(m, src)
+}
+func (m *CompactionStatus) XXX_Size() int {
+	return xxx_messageInfo_CompactionStatus.Size(m)
+}
+func (m *CompactionStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompactionStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompactionStatus proto.InternalMessageInfo
+
+func (m *CompactionStatus) GetStartKey() []byte {
+	if m != nil {
+		return m.StartKey
+	}
+	return nil
+}
+
+func (m *CompactionStatus) GetEndKey() []byte {
+	if m != nil {
+		return m.EndKey
+	}
+	return nil
+}
+
+func (m *CompactionStatus) GetRunning() bool {
+	if m != nil {
+		return m.Running
+	}
+	return false
+}
+
+func (m *CompactionStatus) GetStartTimeMillis() int64 {
+	if m != nil {
+		return m.StartTimeMillis
+	}
+	return 0
+}
+
+func (m *CompactionStatus) GetEndTimeMillis() int64 {
+	if m != nil {
+		return m.EndTimeMillis
+	}
+	return 0
+}
+
+func (m *CompactionStatus) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type FlushRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FlushRequest) Reset()         { *m = FlushRequest{} }
+func (m *FlushRequest) String() string { return proto.CompactTextString(m) }
+func (*FlushRequest) ProtoMessage()    {}
+func (*FlushRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{91}
+}
+
+func (m *FlushRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FlushRequest.Unmarshal(m, b)
+}
+func (m *FlushRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FlushRequest.Marshal(b, m, deterministic)
+}
+func (m *FlushRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FlushRequest.Merge(m, src)
+}
+func (m *FlushRequest) XXX_Size() int {
+	return xxx_messageInfo_FlushRequest.Size(m)
+}
+func (m *FlushRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FlushRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FlushRequest proto.InternalMessageInfo
+
+type PrefixStatsRequest struct {
+	// Prefix is the prefix of the keys, which covers all the keys if empty.
+	Prefix []byte `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Exact indicates whether the keys are iterated over instead of being estimated.
+	Exact bool `protobuf:"varint,2,opt,name=exact,proto3" json:"exact,omitempty"`
+	// MaxKeysPerSecond is the number of keys iterated over per second when exact,
+	// which defaults to 100000 if zero.
+	MaxKeysPerSecond uint32 `protobuf:"varint,3,opt,name=maxKeysPerSecond,proto3" json:"maxKeysPerSecond,omitempty"`
+	// Namespace restricts the stats to the keys of the namespace with the prefix,
+	// whose quota is reported as well.
+	Namespace            string   `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PrefixStatsRequest) Reset()         { *m = PrefixStatsRequest{} }
+func (m *PrefixStatsRequest) String() string { return proto.CompactTextString(m) }
+func (*PrefixStatsRequest) ProtoMessage()    {}
+func (*PrefixStatsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{92}
+}
+
+func (m *PrefixStatsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PrefixStatsRequest.Unmarshal(m, b)
+}
+func (m *PrefixStatsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PrefixStatsRequest.Marshal(b, m, deterministic)
+}
+func (m *PrefixStatsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PrefixStatsRequest.Merge(m, src)
+}
+func (m *PrefixStatsRequest) XXX_Size() int {
+	return xxx_messageInfo_PrefixStatsRequest.Size(m)
+}
+func (m *PrefixStatsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PrefixStatsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PrefixStatsRequest proto.InternalMessageInfo
+
+func (m *PrefixStatsRequest) GetPrefix() []byte {
+	if m != nil {
+		return m.Prefix
+	}
+	return nil
+}
+
+func (m *PrefixStatsRequest) GetExact() bool {
+	if m != nil {
+		return m.Exact
+	}
+	return false
+}
+
+func (m *PrefixStatsRequest) GetMaxKeysPerSecond() uint32 {
+	if m != nil {
+		return m.MaxKeysPerSecond
+	}
+	return 0
+}
+
+func (m *PrefixStatsRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type PrefixStatsResponse struct {
+	// Status indicates the result of the PrefixStats operation.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// NumberOfKeys is the number of keys with the prefix.
+	NumberOfKeys uint64 `protobuf:"varint,2,opt,name=numberOfKeys,proto3" json:"numberOfKeys,omitempty"`
+	// SizeBytes is the total length of the keys and their values when exact, or
+	// else the estimated size of the files of the storage engine holding the keys.
+	SizeBytes uint64 `protobuf:"varint,3,opt,name=sizeBytes,proto3" json:"sizeBytes,omitempty"`
+	// Exact indicates whether the keys were iterated over.
+	Exact bool `protobuf:"varint,4,opt,name=exact,proto3" json:"exact,omitempty"`
+	// Quota is the quota of the namespace of the request, along with its usage,
+	// if the namespace has a quota.
+	Quota                *NamespaceQuota `protobuf:"bytes,5,opt,name=quota,proto3" json:"quota,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *PrefixStatsResponse) Reset()         { *m = PrefixStatsResponse{} }
+func (m *PrefixStatsResponse) String() string { return proto.CompactTextString(m) }
+func (*PrefixStatsResponse) ProtoMessage()    {}
+func (*PrefixStatsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{93}
+}
+
+func (m *PrefixStatsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PrefixStatsResponse.Unmarshal(m, b)
+}
+func (m *PrefixStatsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PrefixStatsResponse.Marshal(b, m, deterministic)
+}
+func (m *PrefixStatsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PrefixStatsResponse.Merge(m, src)
+}
+func (m *PrefixStatsResponse) XXX_Size() int {
+	return xxx_messageInfo_PrefixStatsResponse.Size(m)
+}
+func (m *PrefixStatsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PrefixStatsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PrefixStatsResponse proto.InternalMessageInfo
+
+func (m *PrefixStatsResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *PrefixStatsResponse) GetNumberOfKeys() uint64 {
+	if m != nil {
+		return m.NumberOfKeys
+	}
+	return 0
+}
+
+func (m *PrefixStatsResponse) GetSizeBytes() uint64 {
+	if m != nil {
+		return m.SizeBytes
+	}
+	return 0
+}
+
+func (m *PrefixStatsResponse) GetExact() bool {
+	if m != nil {
+		return m.Exact
+	}
+	return false
+}
+
+func (m *PrefixStatsResponse) GetQuota() *NamespaceQuota {
+	if m != nil {
+		return m.Quota
+	}
+	return nil
+}
+
+type NamespaceQuota struct {
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// MaxKeys and MaxBytes are the maximum number of keys of the namespace and the
+	// maximum total length of these keys, as stored, along with their values. Zero
+	// indicates that there is no limit.
+	MaxKeys  uint64 `protobuf:"varint,2,opt,name=maxKeys,proto3" json:"maxKeys,omitempty"`
+	MaxBytes uint64 `protobuf:"varint,3,opt,name=maxBytes,proto3" json:"maxBytes,omitempty"`
+	// NumberOfKeys and SizeBytes are the usage of the namespace, which is tracked
+	// as the keys are mutated and reconciled periodically by iterating over them.
+	NumberOfKeys uint64 `protobuf:"varint,4,opt,name=numberOfKeys,proto3" json:"numberOfKeys,omitempty"`
+	SizeBytes    uint64 `protobuf:"varint,5,opt,name=sizeBytes,proto3" json:"sizeBytes,omitempty"`
+	// ReconcileTimeMillis is the time in milliseconds since epoch at which the usage
+	// was last reconciled, zero if it is yet to be reconciled.
+	ReconcileTimeMillis  int64    `protobuf:"varint,6,opt,name=reconcileTimeMillis,proto3" json:"reconcileTimeMillis,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NamespaceQuota) Reset()         { *m = NamespaceQuota{} }
+func (m *NamespaceQuota) String() string { return proto.CompactTextString(m) }
+func (*NamespaceQuota) ProtoMessage()    {}
+func (*NamespaceQuota) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{94}
+}
+
+func (m *NamespaceQuota) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NamespaceQuota.Unmarshal(m, b)
+}
+func (m *NamespaceQuota) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NamespaceQuota.Marshal(b, m, deterministic)
+}
+func (m *NamespaceQuota) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NamespaceQuota.Merge(m, src)
+}
+func (m *NamespaceQuota) XXX_Size() int {
+	return xxx_messageInfo_NamespaceQuota.Size(m)
+}
+func (m *NamespaceQuota) XXX_DiscardUnknown() {
+	xxx_messageInfo_NamespaceQuota.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NamespaceQuota proto.InternalMessageInfo
+
+func (m *NamespaceQuota) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *NamespaceQuota) GetMaxKeys() uint64 {
+	if m != nil {
+		return m.MaxKeys
+	}
+	return 0
+}
+
+func (m *NamespaceQuota) GetMaxBytes() uint64 {
+	if m != nil {
+		return m.MaxBytes
+	}
+	return 0
+}
+
+func (m *NamespaceQuota) GetNumberOfKeys() uint64 {
+	if m != nil {
+		return m.NumberOfKeys
+	}
+	return 0
+}
+
+func (m *NamespaceQuota) GetSizeBytes() uint64 {
+	if m != nil {
+		return m.SizeBytes
+	}
+	return 0
+}
+
+func (m *NamespaceQuota) GetReconcileTimeMillis() int64 {
+	if m != nil {
+		return m.ReconcileTimeMillis
+	}
+	return 0
+}
+
+type SetQuotaRequest struct {
+	// Namespace is the namespace being limited, which can not be the default one.
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// MaxKeys and MaxBytes are the limits of the namespace, as per NamespaceQuota.
+	// The quota of the namespace is removed when both are zero.
+	MaxKeys              uint64   `protobuf:"varint,2,opt,name=maxKeys,proto3" json:"maxKeys,omitempty"`
+	MaxBytes             uint64   `protobuf:"varint,3,opt,name=maxBytes,proto3" json:"maxBytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetQuotaRequest) Reset()         { *m = SetQuotaRequest{} }
+func (m *SetQuotaRequest) String() string { return proto.CompactTextString(m) }
+func (*SetQuotaRequest) ProtoMessage()    {}
+func (*SetQuotaRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{95}
+}
+
+func (m *SetQuotaRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetQuotaRequest.Unmarshal(m, b)
+}
+func (m *SetQuotaRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetQuotaRequest.Marshal(b, m, deterministic)
+}
+func (m *SetQuotaRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetQuotaRequest.Merge(m, src)
+}
+func (m *SetQuotaRequest) XXX_Size() int {
+	return xxx_messageInfo_SetQuotaRequest.Size(m)
+}
+func (m *SetQuotaRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetQuotaRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetQuotaRequest proto.InternalMessageInfo
+
+func (m *SetQuotaRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *SetQuotaRequest) GetMaxKeys() uint64 {
+	if m != nil {
+		return m.MaxKeys
+	}
+	return 0
+}
+
+func (m *SetQuotaRequest) GetMaxBytes() uint64 {
+	if m != nil {
+		return m.MaxBytes
+	}
+	return 0
+}
+
+type ListQuotasRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListQuotasRequest) Reset()         { *m = ListQuotasRequest{} }
+func (m *ListQuotasRequest) String() string { return proto.CompactTextString(m) }
+func (*ListQuotasRequest) ProtoMessage()    {}
+func (*ListQuotasRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{96}
+}
+
+func (m *ListQuotasRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListQuotasRequest.Unmarshal(m, b)
+}
+func (m *ListQuotasRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListQuotasRequest.Marshal(b, m, deterministic)
+}
+func (m *ListQuotasRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListQuotasRequest.Merge(m, src)
+}
+func (m *ListQuotasRequest) XXX_Size() int {
+	return xxx_messageInfo_ListQuotasRequest.Size(m)
+}
+func (m *ListQuotasRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListQuotasRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListQuotasRequest proto.InternalMessageInfo
+
+type ListQuotasResponse struct {
+	// Status indicates the result of the ListQuotas operation.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Quotas are the quotas of the namespaces, in the order of their names.
+	Quotas               []*NamespaceQuota `protobuf:"bytes,2,rep,name=quotas,proto3" json:"quotas,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ListQuotasResponse) Reset()         { *m = ListQuotasResponse{} }
+func (m *ListQuotasResponse) String() string { return proto.CompactTextString(m) }
+func (*ListQuotasResponse) ProtoMessage()    {}
+func (*ListQuotasResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{97}
+}
+
+func (m *ListQuotasResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListQuotasResponse.Unmarshal(m, b)
+}
+func (m *ListQuotasResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListQuotasResponse.Marshal(b, m, deterministic)
+}
+func (m *ListQuotasResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListQuotasResponse.Merge(m, src)
+}
+func (m *ListQuotasResponse) XXX_Size() int {
+	return xxx_messageInfo_ListQuotasResponse.Size(m)
+}
+func (m *ListQuotasResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListQuotasResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListQuotasResponse proto.InternalMessageInfo
+
+func (m *ListQuotasResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *ListQuotasResponse) GetQuotas() []*NamespaceQuota {
+	if m != nil {
+		return m.Quotas
+	}
+	return nil
+}
+
+type GetServerInfoRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetServerInfoRequest) Reset()         { *m = GetServerInfoRequest{} }
+func (m *GetServerInfoRequest) String() string { return proto.CompactTextString(m) }
+func (*GetServerInfoRequest) ProtoMessage()    {}
+func (*GetServerInfoRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{98}
+}
+
+func (m *GetServerInfoRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetServerInfoRequest.Unmarshal(m, b)
+}
+func (m *GetServerInfoRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetServerInfoRequest.Marshal(b, m, deterministic)
+}
+func (m *GetServerInfoRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetServerInfoRequest.Merge(m, src)
+}
+func (m *GetServerInfoRequest) XXX_Size() int {
+	return xxx_messageInfo_GetServerInfoRequest.Size(m)
+}
+func (m *GetServerInfoRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetServerInfoRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetServerInfoRequest proto.InternalMessageInfo
+
+type GetServerInfoResponse struct {
+	// Status indicates the result of the GetServerInfo operation.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Role is the role in which the node currently serves, which changes
+	// from Slave to Master once a slave node is promoted.
+	Role ServerRole `protobuf:"varint,2,opt,name=role,proto3,enum=dkv.serverpb.ServerRole" json:"role,omitempty"`
+	// StorageEngine is the name of the storage engine, like rocksdb.
+	StorageEngine string `protobuf:"bytes,3,opt,name=storageEngine,proto3" json:"storageEngine,omitempty"`
+	// StorageEngineVersion is the version of the module of the storage engine
+	// the node is built with, empty if unknown.
+	StorageEngineVersion string `protobuf:"bytes,4,opt,name=storageEngineVersion,proto3" json:"storageEngineVersion,omitempty"`
+	// Version is the version of the DKV build.
+	Version string `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
+	// Commit is the commit of the DKV sources built, empty if unknown.
+	Commit string `protobuf:"bytes,6,opt,name=commit,proto3" json:"commit,omitempty"`
+	// UptimeMillis is the time in milliseconds since the node started.
+	UptimeMillis int64 `protobuf:"varint,7,opt,name=uptimeMillis,proto3" json:"uptimeMillis,omitempty"`
+	// ListenAddrs are the addresses on which the node listens, by protocol,
+	// like grpc, http or redis.
+	ListenAddrs map[string]string `protobuf:"bytes,8,rep,name=listenAddrs,proto3" json:"listenAddrs,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Maintenance indicates whether the node is in maintenance mode, for the
+	// reason given by MaintenanceReason.
+	Maintenance          bool     `protobuf:"varint,9,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	MaintenanceReason    string   `protobuf:"bytes,10,opt,name=maintenanceReason,proto3" json:"maintenanceReason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetServerInfoResponse) Reset()         { *m = GetServerInfoResponse{} }
+func (m *GetServerInfoResponse) String() string { return proto.CompactTextString(m) }
+func (*GetServerInfoResponse) ProtoMessage()    {}
+func (*GetServerInfoResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{99}
+}
+
+func (m *GetServerInfoResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetServerInfoResponse.Unmarshal(m, b)
+}
+func (m *GetServerInfoResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetServerInfoResponse.Marshal(b, m, deterministic)
+}
+func (m *GetServerInfoResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetServerInfoResponse.Merge(m, src)
+}
+func (m *GetServerInfoResponse) XXX_Size() int {
+	return xxx_messageInfo_GetServerInfoResponse.Size(m)
+}
+func (m *GetServerInfoResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetServerInfoResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetServerInfoResponse proto.InternalMessageInfo
+
+func (m *GetServerInfoResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetServerInfoResponse) GetRole() ServerRole {
+	if m != nil {
+		return m.Role
+	}
+	return ServerRole_Standalone
+}
+
+func (m *GetServerInfoResponse) GetStorageEngine() string {
+	if m != nil {
+		return m.StorageEngine
+	}
+	return ""
+}
+
+func (m *GetServerInfoResponse) GetStorageEngineVersion() string {
+	if m != nil {
+		return m.StorageEngineVersion
+	}
+	return ""
+}
+
+func (m *GetServerInfoResponse) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *GetServerInfoResponse) GetCommit() string {
+	if m != nil {
+		return m.Commit
+	}
+	return ""
+}
+
+func (m *GetServerInfoResponse) GetUptimeMillis() int64 {
+	if m != nil {
+		return m.UptimeMillis
+	}
+	return 0
+}
+
+func (m *GetServerInfoResponse) GetListenAddrs() map[string]string {
+	if m != nil {
+		return m.ListenAddrs
+	}
+	return nil
+}
+
+func (m *GetServerInfoResponse) GetMaintenance() bool {
+	if m != nil {
+		return m.Maintenance
+	}
+	return false
+}
+
+func (m *GetServerInfoResponse) GetMaintenanceReason() string {
+	if m != nil {
+		return m.MaintenanceReason
+	}
+	return ""
+}
+
+type EnterMaintenanceModeRequest struct {
+	// Reason describes why the node is in maintenance mode, like a compaction,
+	// which is reported by GetServerInfo.
+	Reason               string   `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EnterMaintenanceModeRequest) Reset()         { *m = EnterMaintenanceModeRequest{} }
+func (m *EnterMaintenanceModeRequest) String() string { return proto.CompactTextString(m) }
+func (*EnterMaintenanceModeRequest) ProtoMessage()    {}
+func (*EnterMaintenanceModeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{100}
+}
+
+func (m *EnterMaintenanceModeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnterMaintenanceModeRequest.Unmarshal(m, b)
+}
+func (m *EnterMaintenanceModeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnterMaintenanceModeRequest.Marshal(b, m, deterministic)
+}
+func (m *EnterMaintenanceModeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnterMaintenanceModeRequest.Merge(m, src)
+}
+func (m *EnterMaintenanceModeRequest) XXX_Size() int {
+	return xxx_messageInfo_EnterMaintenanceModeRequest.Size(m)
+}
+func (m *EnterMaintenanceModeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnterMaintenanceModeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnterMaintenanceModeRequest proto.InternalMessageInfo
+
+func (m *EnterMaintenanceModeRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type ExitMaintenanceModeRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExitMaintenanceModeRequest) Reset()         { *m = ExitMaintenanceModeRequest{} }
+func (m *ExitMaintenanceModeRequest) String() string { return proto.CompactTextString(m) }
+func (*ExitMaintenanceModeRequest) ProtoMessage()    {}
+func (*ExitMaintenanceModeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{101}
+}
+
+func (m *ExitMaintenanceModeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExitMaintenanceModeRequest.Unmarshal(m, b)
+}
+func (m *ExitMaintenanceModeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExitMaintenanceModeRequest.Marshal(b, m, deterministic)
+}
+func (m *ExitMaintenanceModeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExitMaintenanceModeRequest.Merge(m, src)
+}
+func (m *ExitMaintenanceModeRequest) XXX_Size() int {
+	return xxx_messageInfo_ExitMaintenanceModeRequest.Size(m)
+}
+func (m *ExitMaintenanceModeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExitMaintenanceModeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExitMaintenanceModeRequest proto.InternalMessageInfo
+
+type ReloadSecurityConfigRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReloadSecurityConfigRequest) Reset()         { *m = ReloadSecurityConfigRequest{} }
+func (m *ReloadSecurityConfigRequest) String() string { return proto.CompactTextString(m) }
+func (*ReloadSecurityConfigRequest) ProtoMessage()    {}
+func (*ReloadSecurityConfigRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{102}
+}
+
+func (m *ReloadSecurityConfigRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReloadSecurityConfigRequest.Unmarshal(m, b)
+}
+func (m *ReloadSecurityConfigRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReloadSecurityConfigRequest.Marshal(b, m, deterministic)
+}
+func (m *ReloadSecurityConfigRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReloadSecurityConfigRequest.Merge(m, src)
+}
+func (m *ReloadSecurityConfigRequest) XXX_Size() int {
+	return xxx_messageInfo_ReloadSecurityConfigRequest.Size(m)
+}
+func (m *ReloadSecurityConfigRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReloadSecurityConfigRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReloadSecurityConfigRequest proto.InternalMessageInfo
+
+type ReloadSecurityConfigResponse struct {
+	// Status indicates the result of the ReloadSecurityConfig operation, which
+	// fails with the InvalidArgument code upon any validation error.
+	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Reloaded lists what was reloaded, among certificate, ca and tokens, leaving
+	// out those whose files are unchanged since they were last loaded.
+	Reloaded []string `protobuf:"bytes,2,rep,name=reloaded,proto3" json:"reloaded,omitempty"`
+	// Errors lists the validation errors of the files, if any.
+	Errors []string `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	// CertificateNotAfter is the time, in seconds since the Unix epoch, at which
+	// the certificate served expires.
+	CertificateNotAfter int64 `protobuf:"varint,4,opt,name=certificateNotAfter,proto3" json:"certificateNotAfter,omitempty"`
+	// NumberOfTokens is the number of tokens accepted.
+	NumberOfTokens       uint32   `protobuf:"varint,5,opt,name=numberOfTokens,proto3" json:"numberOfTokens,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReloadSecurityConfigResponse) Reset()         { *m = ReloadSecurityConfigResponse{} }
+func (m *ReloadSecurityConfigResponse) String() string { return proto.CompactTextString(m) }
+func (*ReloadSecurityConfigResponse) ProtoMessage()    {}
+func (*ReloadSecurityConfigResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ac913527469ef71, []int{103}
+}
+
+func (m *ReloadSecurityConfigResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReloadSecurityConfigResponse.Unmarshal(m, b)
+}
+func (m *ReloadSecurityConfigResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReloadSecurityConfigResponse.Marshal(b, m, deterministic)
+}
+func (m *ReloadSecurityConfigResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReloadSecurityConfigResponse.Merge(m, src)
+}
+func (m *ReloadSecurityConfigResponse) XXX_Size() int {
+	return xxx_messageInfo_ReloadSecurityConfigResponse.Size(m)
+}
+func (m *ReloadSecurityConfigResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReloadSecurityConfigResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReloadSecurityConfigResponse proto.InternalMessageInfo
+
+func (m *ReloadSecurityConfigResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *ReloadSecurityConfigResponse) GetReloaded() []string {
+	if m != nil {
+		return m.Reloaded
+	}
+	return nil
+}
+
+func (m *ReloadSecurityConfigResponse) GetErrors() []string {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+func (m *ReloadSecurityConfigResponse) GetCertificateNotAfter() int64 {
+	if m != nil {
+		return m.CertificateNotAfter
+	}
+	return 0
+}
+
+func (m *ReloadSecurityConfigResponse) GetNumberOfTokens() uint32 {
+	if m != nil {
+		return m.NumberOfTokens
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("dkv.serverpb.StatusCode", StatusCode_name, StatusCode_value)
+	proto.RegisterEnum("dkv.serverpb.ReadConsistency", ReadConsistency_name, ReadConsistency_value)
+	proto.RegisterEnum("dkv.serverpb.SyncMode", SyncMode_name, SyncMode_value)
+	proto.RegisterEnum("dkv.serverpb.BackupJobState", BackupJobState_name, BackupJobState_value)
+	proto.RegisterEnum("dkv.serverpb.NodeRole", NodeRole_name, NodeRole_value)
+	proto.RegisterEnum("dkv.serverpb.DecommissionState", DecommissionState_name, DecommissionState_value)
+	proto.RegisterEnum("dkv.serverpb.ServerRole", ServerRole_name, ServerRole_value)
+	proto.RegisterEnum("dkv.serverpb.TxnCondition_Type", TxnCondition_Type_name, TxnCondition_Type_value)
+	proto.RegisterEnum("dkv.serverpb.TrxnRecord_TrxnType", TrxnRecord_TrxnType_name, TrxnRecord_TrxnType_value)
+	proto.RegisterType((*Status)(nil), "dkv.serverpb.Status")
+	proto.RegisterType((*LeaderHint)(nil), "dkv.serverpb.LeaderHint")
+	proto.RegisterType((*PutRequest)(nil), "dkv.serverpb.PutRequest")
+	proto.RegisterType((*PutResponse)(nil), "dkv.serverpb.PutResponse")
+	proto.RegisterType((*MultiPutRequest)(nil), "dkv.serverpb.MultiPutRequest")
+	proto.RegisterType((*MultiPutResponse)(nil), "dkv.serverpb.MultiPutResponse")
+	proto.RegisterType((*DeleteRequest)(nil), "dkv.serverpb.DeleteRequest")
+	proto.RegisterType((*DeleteResponse)(nil), "dkv.serverpb.DeleteResponse")
+	proto.RegisterType((*UndeleteRequest)(nil), "dkv.serverpb.UndeleteRequest")
+	proto.RegisterType((*UndeleteResponse)(nil), "dkv.serverpb.UndeleteResponse")
+	proto.RegisterType((*GetRequest)(nil), "dkv.serverpb.GetRequest")
+	proto.RegisterType((*GetResponse)(nil), "dkv.serverpb.GetResponse")
+	proto.RegisterType((*MultiGetRequest)(nil), "dkv.serverpb.MultiGetRequest")
+	proto.RegisterType((*MultiGetResponse)(nil), "dkv.serverpb.MultiGetResponse")
+	proto.RegisterType((*KVResult)(nil), "dkv.serverpb.KVResult")
+	proto.RegisterType((*ExistsRequest)(nil), "dkv.serverpb.ExistsRequest")
+	proto.RegisterType((*ExistsResponse)(nil), "dkv.serverpb.ExistsResponse")
+	proto.RegisterType((*CompareAndSetRequest)(nil), "dkv.serverpb.CompareAndSetRequest")
+	proto.RegisterType((*CompareAndSetResponse)(nil), "dkv.serverpb.CompareAndSetResponse")
+	proto.RegisterType((*IncrementRequest)(nil), "dkv.serverpb.IncrementRequest")
+	proto.RegisterType((*IncrementResponse)(nil), "dkv.serverpb.IncrementResponse")
+	proto.RegisterType((*TxnCondition)(nil), "dkv.serverpb.TxnCondition")
+	proto.RegisterType((*TxnRequest)(nil), "dkv.serverpb.TxnRequest")
+	proto.RegisterType((*TxnResponse)(nil), "dkv.serverpb.TxnResponse")
+	proto.RegisterType((*IterateRequest)(nil), "dkv.serverpb.IterateRequest")
+	proto.RegisterType((*IterateResponse)(nil), "dkv.serverpb.IterateResponse")
+	proto.RegisterType((*ListReplicasRequest)(nil), "dkv.serverpb.ListReplicasRequest")
+	proto.RegisterType((*ListReplicasResponse)(nil), "dkv.serverpb.ListReplicasResponse")
+	proto.RegisterType((*GetChangeLogInfoRequest)(nil), "dkv.serverpb.GetChangeLogInfoRequest")
+	proto.RegisterType((*ReplicaProgress)(nil), "dkv.serverpb.ReplicaProgress")
+	proto.RegisterType((*GetChangeLogInfoResponse)(nil), "dkv.serverpb.GetChangeLogInfoResponse")
+	proto.RegisterType((*TruncateChangeLogRequest)(nil), "dkv.serverpb.TruncateChangeLogRequest")
+	proto.RegisterType((*TruncateChangeLogResponse)(nil), "dkv.serverpb.TruncateChangeLogResponse")
+	proto.RegisterType((*VerifyRangeRequest)(nil), "dkv.serverpb.VerifyRangeRequest")
+	proto.RegisterType((*KeyspaceDigestRequest)(nil), "dkv.serverpb.KeyspaceDigestRequest")
+	proto.RegisterType((*KeyRangeDigest)(nil), "dkv.serverpb.KeyRangeDigest")
+	proto.RegisterType((*KeyDigest)(nil), "dkv.serverpb.KeyDigest")
+	proto.RegisterType((*KeyspaceDigestResponse)(nil), "dkv.serverpb.KeyspaceDigestResponse")
+	proto.RegisterType((*VerifyRangeResponse)(nil), "dkv.serverpb.VerifyRangeResponse")
+	proto.RegisterType((*GetChangesRequest)(nil), "dkv.serverpb.GetChangesRequest")
+	proto.RegisterType((*GetChangesResponse)(nil), "dkv.serverpb.GetChangesResponse")
+	proto.RegisterType((*GetCheckpointRequest)(nil), "dkv.serverpb.GetCheckpointRequest")
+	proto.RegisterType((*GetCheckpointResponse)(nil), "dkv.serverpb.GetCheckpointResponse")
+	proto.RegisterType((*ChangeRecord)(nil), "dkv.serverpb.ChangeRecord")
+	proto.RegisterType((*TrxnRecord)(nil), "dkv.serverpb.TrxnRecord")
+	proto.RegisterType((*GetStatusRequest)(nil), "dkv.serverpb.GetStatusRequest")
+	proto.RegisterType((*GetStatusResponse)(nil), "dkv.serverpb.GetStatusResponse")
+	proto.RegisterType((*ReplicationState)(nil), "dkv.serverpb.ReplicationState")
+	proto.RegisterType((*PauseReplicationRequest)(nil), "dkv.serverpb.PauseReplicationRequest")
+	proto.RegisterType((*ResumeReplicationRequest)(nil), "dkv.serverpb.ResumeReplicationRequest")
+	proto.RegisterType((*PromoteToMasterRequest)(nil), "dkv.serverpb.PromoteToMasterRequest")
+	proto.RegisterType((*PromoteToMasterResponse)(nil), "dkv.serverpb.PromoteToMasterResponse")
+	proto.RegisterType((*BulkLoadRequest)(nil), "dkv.serverpb.BulkLoadRequest")
+	proto.RegisterType((*BulkLoadResponse)(nil), "dkv.serverpb.BulkLoadResponse")
+	proto.RegisterType((*BackupRequest)(nil), "dkv.serverpb.BackupRequest")
+	proto.RegisterType((*RestoreRequest)(nil), "dkv.serverpb.RestoreRequest")
+	proto.RegisterType((*BackupResponse)(nil), "dkv.serverpb.BackupResponse")
+	proto.RegisterType((*GetBackupStatusRequest)(nil), "dkv.serverpb.GetBackupStatusRequest")
+	proto.RegisterType((*GetBackupStatusResponse)(nil), "dkv.serverpb.GetBackupStatusResponse")
+	proto.RegisterType((*BackupInfo)(nil), "dkv.serverpb.BackupInfo")
+	proto.RegisterType((*StreamBackupRequest)(nil), "dkv.serverpb.StreamBackupRequest")
+	proto.RegisterType((*BackupHeader)(nil), "dkv.serverpb.BackupHeader")
+	proto.RegisterType((*BackupChunk)(nil), "dkv.serverpb.BackupChunk")
+	proto.RegisterType((*StreamRestoreRequest)(nil), "dkv.serverpb.StreamRestoreRequest")
+	proto.RegisterType((*AddNodeRequest)(nil), "dkv.serverpb.AddNodeRequest")
+	proto.RegisterType((*RemoveNodeRequest)(nil), "dkv.serverpb.RemoveNodeRequest")
+	proto.RegisterType((*ListNodesRequest)(nil), "dkv.serverpb.ListNodesRequest")
+	proto.RegisterType((*NodeInfo)(nil), "dkv.serverpb.NodeInfo")
+	proto.RegisterType((*ListNodesResponse)(nil), "dkv.serverpb.ListNodesResponse")
+	proto.RegisterType((*DecommissionNodeRequest)(nil), "dkv.serverpb.DecommissionNodeRequest")
+	proto.RegisterType((*GetDecommissionStatusRequest)(nil), "dkv.serverpb.GetDecommissionStatusRequest")
+	proto.RegisterType((*GetDecommissionStatusResponse)(nil), "dkv.serverpb.GetDecommissionStatusResponse")
+	proto.RegisterMapType((map[uint32]uint64)(nil), "dkv.serverpb.GetDecommissionStatusResponse.MemberChangeNumbersEntry")
+	proto.RegisterType((*ClusterBackupRequest)(nil), "dkv.serverpb.ClusterBackupRequest")
+	proto.RegisterType((*ClusterBackupArtifact)(nil), "dkv.serverpb.ClusterBackupArtifact")
+	proto.RegisterType((*ClusterBackupManifest)(nil), "dkv.serverpb.ClusterBackupManifest")
+	proto.RegisterType((*ClusterBackupResponse)(nil), "dkv.serverpb.ClusterBackupResponse")
+	proto.RegisterType((*ClusterRestoreRequest)(nil), "dkv.serverpb.ClusterRestoreRequest")
+	proto.RegisterType((*FenceWritesRequest)(nil), "dkv.serverpb.FenceWritesRequest")
+	proto.RegisterType((*FenceWritesResponse)(nil), "dkv.serverpb.FenceWritesResponse")
+	proto.RegisterType((*UnfenceWritesRequest)(nil), "dkv.serverpb.UnfenceWritesRequest")
+	proto.RegisterType((*BackupMemberRequest)(nil), "dkv.serverpb.BackupMemberRequest")
+	proto.RegisterType((*BackupMemberResponse)(nil), "dkv.serverpb.BackupMemberResponse")
+	proto.RegisterType((*RestoreMemberRequest)(nil), "dkv.serverpb.RestoreMemberRequest")
+	proto.RegisterType((*Limits)(nil), "dkv.serverpb.Limits")
+	proto.RegisterType((*GetLimitsRequest)(nil), "dkv.serverpb.GetLimitsRequest")
+	proto.RegisterType((*GetLimitsResponse)(nil), "dkv.serverpb.GetLimitsResponse")
+	proto.RegisterType((*SetLimitsRequest)(nil), "dkv.serverpb.SetLimitsRequest")
+	proto.RegisterType((*GetStorageStatsRequest)(nil), "dkv.serverpb.GetStorageStatsRequest")
+	proto.RegisterType((*GetStorageStatsResponse)(nil), "dkv.serverpb.GetStorageStatsResponse")
+	proto.RegisterMapType((map[string]string)(nil), "dkv.serverpb.GetStorageStatsResponse.EngineStatsEntry")
+	proto.RegisterType((*CompactRangeRequest)(nil), "dkv.serverpb.CompactRangeRequest")
+	proto.RegisterType((*CompactionStatus)(nil), "dkv.serverpb.CompactionStatus")
+	proto.RegisterType((*FlushRequest)(nil), "dkv.serverpb.FlushRequest")
+	proto.RegisterType((*PrefixStatsRequest)(nil), "dkv.serverpb.PrefixStatsRequest")
+	proto.RegisterType((*PrefixStatsResponse)(nil), "dkv.serverpb.PrefixStatsResponse")
+	proto.RegisterType((*NamespaceQuota)(nil), "dkv.serverpb.NamespaceQuota")
+	proto.RegisterType((*SetQuotaRequest)(nil), "dkv.serverpb.SetQuotaRequest")
+	proto.RegisterType((*ListQuotasRequest)(nil), "dkv.serverpb.ListQuotasRequest")
+	proto.RegisterType((*ListQuotasResponse)(nil), "dkv.serverpb.ListQuotasResponse")
+	proto.RegisterType((*GetServerInfoRequest)(nil), "dkv.serverpb.GetServerInfoRequest")
+	proto.RegisterType((*GetServerInfoResponse)(nil), "dkv.serverpb.GetServerInfoResponse")
+	proto.RegisterMapType((map[string]string)(nil), "dkv.serverpb.GetServerInfoResponse.ListenAddrsEntry")
+	proto.RegisterType((*EnterMaintenanceModeRequest)(nil), "dkv.serverpb.EnterMaintenanceModeRequest")
+	proto.RegisterType((*ExitMaintenanceModeRequest)(nil), "dkv.serverpb.ExitMaintenanceModeRequest")
+	proto.RegisterType((*ReloadSecurityConfigRequest)(nil), "dkv.serverpb.ReloadSecurityConfigRequest")
+	proto.RegisterType((*ReloadSecurityConfigResponse)(nil), "dkv.serverpb.ReloadSecurityConfigResponse")
+}
+
+func init() {
+	proto.RegisterFile("pkg/serverpb/api.proto", fileDescriptor_8ac913527469ef71)
+}
+
+var fileDescriptor_8ac913527469ef71 = []byte{
+	// 5026 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x7c, 0x4d, 0x6c, 0xe4, 0x46,
+	0x76, 0xf0, 0xb0, 0xbb, 0xd5, 0x6a, 0x3d, 0xa9, 0x5b, 0x54, 0x49, 0xa3, 0x69, 0xd3, 0xf2, 0xfc,
+	0x70, 0xd6, 0xfb, 0xcd, 0x27, 0x1b, 0xf2, 0x40, 0x63, 0x2f, 0xbc, 0x5e, 0xc4, 0xb6, 0x46, 0x9a,
+	0x91, 0x65, 0xfd, 0xcc, 0x2c, 0xa5, 0x91, 0x9d, 0x0d, 0xb0, 0x01, 0xa7, 0x59, 0x92, 0xb8, 0x62,
+	0x93, 0x6d, 0xb2, 0x5a, 0x56, 0x1b, 0x41, 0xb0, 0x97, 0x04, 0x1b, 0x38, 0xe7, 0xe4, 0x92, 0x00,
+	0xc1, 0x1e, 0x82, 0xcd, 0x29, 0x40, 0x80, 0x9c, 0x16, 0x01, 0x72, 0xca, 0x31, 0x39, 0xe5, 0x07,
+	0x01, 0x72, 0x4a, 0x90, 0x63, 0x2e, 0x39, 0xe4, 0x16, 0x04, 0xf5, 0x43, 0xb2, 0xaa, 0x48, 0xb6,
+	0x34, 0xbd, 0x6b, 0xdf, 0xba, 0x5e, 0xbd, 0x7a, 0xf5, 0xea, 0xd5, 0xab, 0xf7, 0x5e, 0xbd, 0x7a,
+	0x6c, 0x58, 0x1e, 0x9c, 0x9f, 0xbe, 0x93, 0xe0, 0xf8, 0x02, 0xc7, 0x83, 0x97, 0xef, 0xb8, 0x03,
+	0x7f, 0x6d, 0x10, 0x47, 0x24, 0x42, 0x73, 0xde, 0xf9, 0xc5, 0x5a, 0x0a, 0xb7, 0xcf, 0xa0, 0x79,
+	0x48, 0x5c, 0x32, 0x4c, 0x10, 0x82, 0x46, 0x2f, 0xf2, 0x70, 0xd7, 0xb8, 0x6b, 0x3c, 0x98, 0x72,
+	0xd8, 0x6f, 0xd4, 0x85, 0xe9, 0x3e, 0x4e, 0x12, 0xf7, 0x14, 0x77, 0x6b, 0x77, 0x8d, 0x07, 0x33,
+	0x4e, 0xda, 0x44, 0x0f, 0xa1, 0x19, 0x60, 0xd7, 0xc3, 0x71, 0xb7, 0x7e, 0xd7, 0x78, 0x30, 0xbb,
+	0xde, 0x5d, 0x93, 0xc9, 0xae, 0xed, 0xb1, 0xbe, 0x4f, 0xfc, 0x90, 0x38, 0x02, 0xcf, 0xfe, 0x10,
+	0x20, 0x87, 0xa2, 0x65, 0x68, 0x86, 0x91, 0x87, 0x77, 0x3c, 0x36, 0x5f, 0xdb, 0x11, 0x2d, 0x3a,
+	0xa3, 0x77, 0x7e, 0xb1, 0xe1, 0x79, 0x71, 0x3a, 0xa3, 0x68, 0xda, 0x3f, 0x37, 0x00, 0x9e, 0x0f,
+	0x89, 0x83, 0xbf, 0x18, 0xe2, 0x84, 0x20, 0x13, 0xea, 0xe7, 0x78, 0xc4, 0x46, 0xcf, 0x39, 0xf4,
+	0x27, 0x5a, 0x82, 0xa9, 0x0b, 0x37, 0x18, 0x72, 0x56, 0xe7, 0x1c, 0xde, 0x40, 0x16, 0xb4, 0xf0,
+	0xe5, 0xc0, 0x8f, 0xf1, 0xd1, 0x21, 0x63, 0xb5, 0xe1, 0x64, 0x6d, 0xb4, 0x02, 0x33, 0xa1, 0xdb,
+	0xc7, 0xc9, 0xc0, 0xed, 0xe1, 0x6e, 0x83, 0x4d, 0x97, 0x03, 0xd0, 0x3a, 0xb4, 0x92, 0x51, 0xd8,
+	0xdb, 0xa7, 0x42, 0x99, 0xba, 0x6b, 0x3c, 0xe8, 0xac, 0x2f, 0xab, 0x8b, 0x3c, 0x14, 0xbd, 0x4e,
+	0x86, 0x67, 0xff, 0x00, 0x66, 0x19, 0x8f, 0xc9, 0x20, 0x0a, 0x13, 0x8c, 0xde, 0x86, 0x66, 0xc2,
+	0xa4, 0xcb, 0xf8, 0x9c, 0x5d, 0x5f, 0xd2, 0x08, 0xb0, 0x3e, 0x47, 0xe0, 0xd8, 0xfb, 0x30, 0xbf,
+	0x3f, 0x0c, 0x88, 0x2f, 0xad, 0xf2, 0x03, 0x98, 0x1d, 0x64, 0x2d, 0x4a, 0xa5, 0x5e, 0x94, 0x75,
+	0x8e, 0xee, 0xc8, 0xc8, 0xf6, 0xc7, 0x60, 0xe6, 0xe4, 0x26, 0x62, 0xe8, 0x23, 0x68, 0x6f, 0xe1,
+	0x00, 0x13, 0x5c, 0x2d, 0x74, 0x45, 0x84, 0x35, 0x4d, 0x84, 0xf6, 0x87, 0xd0, 0x49, 0x09, 0x4c,
+	0xc4, 0xc0, 0x06, 0xcc, 0xbf, 0x08, 0xbd, 0x5f, 0x89, 0x85, 0x8f, 0xc1, 0xcc, 0x49, 0x4c, 0xc4,
+	0xc4, 0xdf, 0x18, 0x00, 0xdb, 0x78, 0x8c, 0xe2, 0x2d, 0x43, 0xb3, 0xef, 0x5e, 0xee, 0xb9, 0xa7,
+	0x6c, 0xf6, 0x86, 0x23, 0x5a, 0x2a, 0x63, 0x75, 0x5d, 0xbd, 0xb6, 0x61, 0x3e, 0xc6, 0xae, 0xb7,
+	0x19, 0x85, 0x89, 0x9f, 0x10, 0x1c, 0xf6, 0x46, 0x4c, 0x05, 0x3b, 0xeb, 0x6f, 0xa8, 0xdc, 0x38,
+	0x2a, 0x92, 0xa3, 0x8f, 0x42, 0xb7, 0x01, 0x02, 0xf7, 0xf4, 0x71, 0x34, 0x0c, 0x3d, 0xec, 0x31,
+	0x4d, 0x6d, 0x39, 0x12, 0xc4, 0x3e, 0x85, 0x59, 0xc6, 0xfe, 0x24, 0x8b, 0xaf, 0x38, 0x54, 0x4b,
+	0x30, 0x75, 0x42, 0xa9, 0xb3, 0x55, 0xb5, 0x1c, 0xde, 0xb0, 0xff, 0xd8, 0x10, 0x0a, 0x2c, 0x49,
+	0x0b, 0x41, 0xe3, 0x1c, 0x8f, 0xb8, 0xe6, 0xce, 0x39, 0xec, 0xf7, 0x84, 0xf2, 0xb2, 0xa0, 0xe5,
+	0x61, 0xe2, 0xfa, 0x01, 0xf6, 0x98, 0xa0, 0x5a, 0x4e, 0xd6, 0xbe, 0x52, 0x04, 0x7f, 0x66, 0x80,
+	0x99, 0x73, 0x36, 0x91, 0x20, 0x96, 0xa1, 0xc9, 0xd6, 0x9e, 0x74, 0x6b, 0x6c, 0x29, 0xa2, 0x25,
+	0x8b, 0xa2, 0x9e, 0x89, 0x02, 0x3d, 0x84, 0xe9, 0x18, 0x27, 0xc3, 0x80, 0x24, 0xdd, 0x06, 0x3b,
+	0xb3, 0x9a, 0xe9, 0xd8, 0x3d, 0x76, 0x58, 0xb7, 0x93, 0xa2, 0xd9, 0x1e, 0xb4, 0x52, 0xe0, 0x37,
+	0xb8, 0x45, 0x1b, 0xd0, 0x7e, 0x72, 0xe9, 0x27, 0x24, 0x19, 0xb7, 0x3f, 0xe3, 0x0f, 0xd4, 0x31,
+	0x74, 0x52, 0x12, 0x93, 0x0a, 0x12, 0xb3, 0xf1, 0x4c, 0x90, 0x2d, 0x47, 0xb4, 0xec, 0x9f, 0x19,
+	0xb0, 0xb4, 0x19, 0xf5, 0x07, 0x6e, 0x8c, 0x37, 0x42, 0xef, 0x70, 0xdc, 0x81, 0xfb, 0x0e, 0xb4,
+	0xf1, 0xe5, 0x00, 0xf7, 0x08, 0xf6, 0x8e, 0xa5, 0x95, 0xab, 0x40, 0xaa, 0x30, 0x21, 0xfe, 0x92,
+	0x23, 0xd4, 0x19, 0x42, 0xd6, 0x1e, 0x6f, 0xf9, 0xed, 0xdf, 0x86, 0x9b, 0x1a, 0x27, 0x13, 0xad,
+	0xb4, 0x0b, 0xd3, 0xc3, 0x81, 0xe7, 0x12, 0xec, 0x31, 0x06, 0x5b, 0x4e, 0xda, 0xb4, 0x3f, 0x07,
+	0x73, 0x27, 0xec, 0xc5, 0xb8, 0x8f, 0xc3, 0xf1, 0x0e, 0xcd, 0xc3, 0x01, 0x71, 0xd9, 0xe8, 0xba,
+	0xc3, 0x1b, 0xe3, 0x4f, 0x89, 0xfd, 0x19, 0x2c, 0x48, 0x94, 0x7f, 0xf5, 0x23, 0x5f, 0x17, 0xfa,
+	0x64, 0x7f, 0x6d, 0xc0, 0xdc, 0xd1, 0x65, 0xb8, 0x19, 0x85, 0x9e, 0x4f, 0xfc, 0x28, 0x44, 0x8f,
+	0xa0, 0x41, 0x46, 0x03, 0x1e, 0x2f, 0x74, 0xd6, 0xef, 0xa8, 0x24, 0x65, 0xcc, 0xb5, 0xa3, 0xd1,
+	0x00, 0x3b, 0x0c, 0x39, 0x5d, 0x64, 0xad, 0xc4, 0x6b, 0xd7, 0x25, 0xed, 0xb5, 0x6f, 0x43, 0x83,
+	0x8e, 0x42, 0x00, 0xcd, 0x27, 0x5f, 0x0c, 0xdd, 0x20, 0x31, 0x6f, 0xd0, 0xdf, 0x1b, 0x2f, 0x13,
+	0x1c, 0x12, 0xd3, 0xb0, 0xff, 0xd3, 0x00, 0x38, 0xba, 0x0c, 0x73, 0x37, 0x09, 0xbd, 0x74, 0xba,
+	0xd4, 0x4b, 0x5a, 0xd5, 0x1c, 0x39, 0x12, 0x36, 0xfa, 0x10, 0xda, 0xe4, 0x0c, 0x87, 0xfb, 0x43,
+	0xe2, 0xf2, 0xe1, 0xb5, 0x32, 0x27, 0x7b, 0x14, 0xd3, 0xd9, 0x7a, 0x51, 0xec, 0x39, 0x2a, 0x3a,
+	0x1d, 0x8f, 0x83, 0x04, 0xe7, 0xe3, 0xeb, 0x57, 0x8d, 0x57, 0xd0, 0xaf, 0x50, 0xc5, 0xdf, 0x84,
+	0x59, 0xb6, 0xce, 0x89, 0x76, 0x72, 0x05, 0x66, 0x92, 0x61, 0xaf, 0x87, 0xb1, 0x97, 0xa9, 0x60,
+	0x0e, 0xb0, 0x7f, 0x61, 0x40, 0x67, 0x87, 0xe0, 0xd8, 0xcd, 0x9d, 0xeb, 0x0a, 0xcc, 0x9c, 0xe3,
+	0xd1, 0xf3, 0x18, 0x9f, 0xf8, 0x97, 0x42, 0x13, 0x73, 0x00, 0x3d, 0x50, 0x09, 0x71, 0x63, 0xb2,
+	0x9b, 0xed, 0x60, 0xd6, 0xbe, 0xda, 0x76, 0x53, 0xcb, 0xf2, 0x2c, 0x0c, 0x46, 0xa9, 0xed, 0x4e,
+	0xdb, 0xc8, 0x86, 0xb9, 0xbe, 0x7b, 0xc9, 0x8e, 0xe5, 0xa1, 0xff, 0x15, 0x0f, 0xb5, 0xda, 0x8e,
+	0x02, 0xb3, 0x7f, 0xcf, 0x80, 0xf9, 0x8c, 0xd5, 0x89, 0x44, 0x71, 0x4d, 0xc5, 0xa3, 0xeb, 0x20,
+	0xf1, 0x30, 0xec, 0xb1, 0x53, 0xcb, 0x59, 0xcd, 0x01, 0xf6, 0x4d, 0x58, 0xdc, 0xf3, 0x13, 0xe2,
+	0xe0, 0x41, 0xe0, 0xf7, 0xdc, 0xd4, 0x88, 0xda, 0x7f, 0x69, 0xc0, 0x92, 0x0a, 0x9f, 0x88, 0xc7,
+	0x35, 0x40, 0x7d, 0x37, 0x21, 0x38, 0xde, 0x3c, 0x73, 0xc3, 0x53, 0x7c, 0x30, 0xec, 0xbf, 0xc4,
+	0xb1, 0xf0, 0x91, 0x25, 0x3d, 0xe8, 0xfb, 0xd0, 0x8a, 0xc5, 0x8c, 0x42, 0xe9, 0x0a, 0xa1, 0x03,
+	0xeb, 0x7d, 0x1e, 0x47, 0xa7, 0x31, 0x4e, 0x12, 0x27, 0x43, 0xb7, 0x5f, 0x83, 0x5b, 0xdb, 0x98,
+	0x70, 0x6a, 0x7b, 0xd1, 0xe9, 0x4e, 0x78, 0x12, 0xa5, 0x8b, 0xf9, 0xa5, 0x01, 0xf3, 0xda, 0x40,
+	0x2a, 0x15, 0x31, 0x74, 0x67, 0x8b, 0x2d, 0x65, 0xc6, 0xc9, 0x01, 0x68, 0x1d, 0x96, 0x7a, 0x51,
+	0x98, 0x0c, 0xfb, 0xd8, 0x2b, 0xe1, 0xbc, 0xb4, 0x8f, 0xae, 0x35, 0x70, 0x13, 0x72, 0x88, 0x71,
+	0x78, 0xe4, 0xf7, 0xf1, 0xbe, 0x1f, 0x04, 0x7e, 0xc2, 0xb6, 0xa2, 0xee, 0x94, 0xf4, 0xa0, 0xef,
+	0x42, 0x47, 0x4c, 0x48, 0x4f, 0x0d, 0x8d, 0x1d, 0x1a, 0x8c, 0xba, 0x06, 0xb5, 0xff, 0xcd, 0x80,
+	0x6e, 0x71, 0x65, 0x13, 0x6d, 0xc7, 0xdb, 0xb0, 0x70, 0xe2, 0xc7, 0x09, 0x29, 0x59, 0x53, 0xb1,
+	0x03, 0xad, 0x82, 0x19, 0xb8, 0x2a, 0x4c, 0xdc, 0x37, 0x0a, 0x70, 0x65, 0xe3, 0x1a, 0xaf, 0xb6,
+	0x71, 0x9f, 0x42, 0xf7, 0x48, 0xa8, 0x63, 0xb6, 0xc6, 0xf4, 0xf4, 0xae, 0x01, 0x7a, 0x89, 0x4f,
+	0xa2, 0x18, 0x2b, 0x4c, 0x18, 0x5c, 0x7f, 0x8a, 0x3d, 0xf6, 0x97, 0xf0, 0x5a, 0x09, 0xad, 0x6f,
+	0x5e, 0x56, 0xf6, 0x19, 0xa0, 0x63, 0x1c, 0xfb, 0x27, 0x23, 0x87, 0x02, 0x53, 0xf6, 0x57, 0xc1,
+	0x3c, 0x89, 0xa3, 0x7e, 0x09, 0xf3, 0x05, 0x38, 0x55, 0x07, 0x12, 0x95, 0x4c, 0xa6, 0x41, 0xed,
+	0x3f, 0x35, 0xe0, 0xe6, 0x2e, 0x1e, 0x31, 0x2b, 0xb4, 0xe5, 0x9f, 0xe2, 0x24, 0x73, 0xb7, 0xb2,
+	0x31, 0x33, 0x34, 0x63, 0x46, 0x43, 0x94, 0xd0, 0xcb, 0xcd, 0x9c, 0x68, 0x51, 0xf8, 0x89, 0x1b,
+	0x3e, 0x1b, 0x12, 0xb6, 0xb3, 0x6d, 0x47, 0xb4, 0x98, 0x9d, 0x1d, 0x04, 0x3e, 0x1d, 0xcb, 0x37,
+	0x74, 0xce, 0xc9, 0x01, 0x74, 0xa6, 0xc0, 0x4f, 0x78, 0x27, 0x0f, 0x4d, 0xb3, 0xb6, 0xfd, 0x53,
+	0x03, 0x3a, 0xbb, 0x98, 0xcb, 0x81, 0xf3, 0x37, 0x29, 0x63, 0x1e, 0x1b, 0x2d, 0x8c, 0x99, 0x68,
+	0x51, 0xdb, 0x1a, 0x32, 0x41, 0x3c, 0x3b, 0x11, 0xbc, 0x51, 0x21, 0x29, 0x30, 0xfb, 0x3d, 0x98,
+	0xd9, 0xc5, 0x23, 0x31, 0x79, 0xe9, 0xe5, 0x46, 0x90, 0xae, 0xc9, 0xa4, 0xed, 0xbf, 0x30, 0x60,
+	0x59, 0x97, 0xec, 0x44, 0xaa, 0xf3, 0x2e, 0x34, 0x63, 0xba, 0xfc, 0xd4, 0xf1, 0xae, 0x68, 0x91,
+	0xb2, 0x22, 0x1d, 0x47, 0xe0, 0xa2, 0xb7, 0x44, 0xdc, 0xca, 0xed, 0xde, 0xad, 0xc2, 0x18, 0x81,
+	0xce, 0x90, 0xa8, 0xfb, 0x58, 0x54, 0x14, 0x6e, 0x22, 0x46, 0x2d, 0x68, 0xf5, 0xce, 0x70, 0xef,
+	0x3c, 0x19, 0xf6, 0x99, 0x2c, 0xda, 0x4e, 0xd6, 0xa6, 0x11, 0x69, 0x2a, 0x54, 0xea, 0xe9, 0x13,
+	0x71, 0xf4, 0x55, 0xa0, 0xfd, 0xef, 0x35, 0x58, 0xc8, 0x8c, 0x53, 0x32, 0x89, 0xde, 0x33, 0x17,
+	0x71, 0x79, 0x20, 0xa8, 0x0a, 0x42, 0x82, 0x9b, 0x92, 0x1e, 0x4a, 0x5b, 0x82, 0x3e, 0x1e, 0x11,
+	0x9c, 0xb2, 0x56, 0x80, 0x5f, 0x91, 0x0d, 0x51, 0x42, 0x83, 0x29, 0x3d, 0x34, 0x50, 0x1c, 0x44,
+	0x53, 0x77, 0x10, 0x0f, 0x60, 0xbe, 0xef, 0x5e, 0xa6, 0x62, 0x67, 0x5e, 0x7e, 0x9a, 0x31, 0xa1,
+	0x83, 0x69, 0xc8, 0xdc, 0x3b, 0x1b, 0x86, 0xe7, 0xd8, 0xeb, 0xb6, 0x78, 0xc8, 0x2c, 0x9a, 0x94,
+	0x06, 0x97, 0xc6, 0x30, 0x3c, 0x7f, 0x76, 0x72, 0x92, 0x60, 0xd2, 0x9d, 0xe1, 0x34, 0x34, 0xb0,
+	0xfd, 0xf7, 0x35, 0x40, 0xb2, 0x94, 0xbf, 0x15, 0x5f, 0xfc, 0x00, 0xe6, 0x43, 0x6d, 0x57, 0xb8,
+	0x8d, 0xd0, 0xc1, 0xe8, 0x5d, 0xba, 0x44, 0x8e, 0xd1, 0x28, 0x0b, 0x54, 0x39, 0x9e, 0x88, 0x15,
+	0xa7, 0x7b, 0xf9, 0x46, 0x86, 0xf8, 0x52, 0xb5, 0xaf, 0x53, 0x7c, 0x23, 0x75, 0x38, 0x55, 0x46,
+	0x46, 0xcd, 0x7b, 0x3c, 0x3a, 0x0c, 0xdc, 0x0b, 0xcc, 0x36, 0xa4, 0xe5, 0xa8, 0x40, 0xc6, 0x31,
+	0x1b, 0x99, 0x0b, 0x54, 0x6c, 0x8a, 0x06, 0xb6, 0x97, 0x61, 0x89, 0xc9, 0x13, 0xf7, 0xce, 0x07,
+	0x91, 0x9f, 0xdd, 0x58, 0x98, 0x71, 0xd5, 0x3a, 0x26, 0x92, 0xb5, 0x0d, 0x73, 0xbd, 0xa2, 0x94,
+	0x15, 0x18, 0x5a, 0x87, 0x69, 0x1c, 0x92, 0xd8, 0xc7, 0x15, 0xf1, 0xb5, 0x94, 0x04, 0x4b, 0x11,
+	0xed, 0x3f, 0xaf, 0xc1, 0x9c, 0x2c, 0x4d, 0xea, 0x35, 0x12, 0x1c, 0xfb, 0x6e, 0xe0, 0x27, 0xd8,
+	0x7b, 0x1a, 0xc5, 0x7d, 0x61, 0xe8, 0x34, 0xe8, 0xb5, 0x18, 0x2a, 0x3d, 0xf1, 0x6d, 0xed, 0xc4,
+	0xa3, 0x35, 0x98, 0x22, 0xac, 0xb7, 0x71, 0xc5, 0xa5, 0x80, 0xa3, 0x29, 0x36, 0x66, 0x4a, 0xb3,
+	0x31, 0x77, 0x61, 0x56, 0x1c, 0x06, 0x76, 0x82, 0x9a, 0x8c, 0x29, 0x19, 0x94, 0x61, 0x28, 0xdb,
+	0x29, 0x83, 0x68, 0xd0, 0xcb, 0x9a, 0xec, 0x74, 0xcd, 0x39, 0xbc, 0x61, 0xff, 0x35, 0xbd, 0x4d,
+	0x65, 0xbc, 0xa0, 0xf7, 0x94, 0x9b, 0xdd, 0xbd, 0x2a, 0x9e, 0xd9, 0xcf, 0x57, 0xbf, 0xdb, 0x29,
+	0x19, 0xd9, 0x86, 0x9a, 0x91, 0xb5, 0xdf, 0x86, 0x56, 0x4a, 0x15, 0xcd, 0xc2, 0xf4, 0x8b, 0xf0,
+	0x3c, 0x8c, 0xbe, 0x0c, 0xcd, 0x1b, 0x68, 0x1a, 0xea, 0xcf, 0x87, 0xc4, 0x34, 0xe8, 0x2d, 0x90,
+	0xa7, 0x14, 0xcd, 0x9a, 0x8d, 0xc0, 0xdc, 0xc6, 0x44, 0x68, 0x93, 0x50, 0xca, 0xff, 0x6e, 0xc0,
+	0x82, 0x04, 0x9c, 0x48, 0x21, 0x1f, 0xc2, 0xa2, 0x3b, 0x18, 0x04, 0x7e, 0x69, 0x3c, 0x5b, 0xd6,
+	0x55, 0x61, 0x2e, 0xea, 0x95, 0xe6, 0xe2, 0x9a, 0xe1, 0x6c, 0x1a, 0x26, 0x3f, 0x8f, 0x82, 0x40,
+	0x0a, 0x93, 0xa7, 0xf2, 0x30, 0x59, 0xed, 0x61, 0x36, 0x7c, 0xd8, 0x7f, 0x12, 0xc7, 0x51, 0x9c,
+	0x08, 0x0d, 0xc9, 0x01, 0xd4, 0xba, 0x9e, 0x61, 0x37, 0x20, 0x67, 0x23, 0xa6, 0x1b, 0x2d, 0x27,
+	0x6d, 0x52, 0x2f, 0x3f, 0x70, 0x87, 0x49, 0x66, 0x76, 0x45, 0x8b, 0x26, 0xd6, 0x38, 0xf7, 0x2c,
+	0x23, 0x3f, 0xc3, 0x0c, 0xbb, 0x04, 0xa1, 0xfc, 0x51, 0x71, 0x8c, 0xf6, 0x5c, 0x96, 0x8b, 0xdc,
+	0xf7, 0x7b, 0x71, 0x94, 0x74, 0x81, 0xaf, 0xbb, 0xd8, 0x43, 0xf1, 0xf1, 0xc9, 0x09, 0xee, 0x11,
+	0xff, 0x02, 0x3f, 0x76, 0x49, 0xef, 0x8c, 0xa9, 0xf2, 0x2c, 0xf7, 0x5f, 0xc5, 0x1e, 0xf4, 0x31,
+	0xbc, 0x9e, 0x41, 0xe9, 0x52, 0x77, 0x42, 0x82, 0xe3, 0x0b, 0x37, 0x10, 0x82, 0x98, 0x63, 0x82,
+	0x18, 0x87, 0xa2, 0x7a, 0xa6, 0xb6, 0xee, 0x99, 0x9e, 0x42, 0x67, 0x80, 0x63, 0x96, 0x4a, 0xf5,
+	0xa8, 0x12, 0xe0, 0x6e, 0x87, 0xe9, 0xc7, 0xed, 0xd2, 0x78, 0x9c, 0xee, 0x0a, 0xc3, 0x72, 0xb4,
+	0x51, 0xf6, 0x5f, 0x19, 0x60, 0xea, 0x48, 0x9a, 0xf0, 0x8c, 0x82, 0xf0, 0x14, 0xd6, 0x6a, 0x3a,
+	0x6b, 0x15, 0x4a, 0x58, 0x1f, 0xab, 0x84, 0x25, 0xca, 0xd2, 0xa8, 0x52, 0x16, 0x7b, 0x17, 0x6e,
+	0x3d, 0xa7, 0xdb, 0x2c, 0x31, 0x9e, 0xc6, 0x24, 0x74, 0xf2, 0x21, 0x89, 0x1c, 0x4c, 0x6f, 0x6e,
+	0x1b, 0x27, 0x04, 0xc7, 0x87, 0xb8, 0x97, 0x88, 0xb7, 0x9a, 0xb2, 0x2e, 0xdb, 0x82, 0x2e, 0x07,
+	0x15, 0xa9, 0xd9, 0x5d, 0x58, 0x7e, 0x1e, 0x47, 0xfd, 0x88, 0xe0, 0xa3, 0x68, 0x9f, 0xad, 0x3f,
+	0xed, 0x19, 0xc1, 0xad, 0x42, 0xcf, 0xb7, 0x73, 0x64, 0xed, 0x27, 0x30, 0xff, 0x78, 0x18, 0x9c,
+	0xef, 0x45, 0xae, 0x97, 0xae, 0x5a, 0x72, 0x32, 0xc6, 0x75, 0x9d, 0xcc, 0xcf, 0x0c, 0x30, 0x73,
+	0x3a, 0x93, 0xfa, 0x3f, 0x25, 0x4a, 0xaf, 0x15, 0xa3, 0xf4, 0x82, 0x4b, 0xaa, 0x17, 0x5d, 0x92,
+	0xbd, 0x0f, 0xed, 0xc7, 0x6e, 0xef, 0x7c, 0x38, 0x48, 0xd7, 0x73, 0x1b, 0xe0, 0x25, 0x03, 0x3c,
+	0x77, 0xc9, 0x59, 0xaa, 0x80, 0x39, 0xe4, 0x8a, 0x44, 0xef, 0x19, 0x74, 0x1c, 0x9c, 0x90, 0x28,
+	0xce, 0x6e, 0x68, 0x77, 0x61, 0x36, 0xe6, 0x10, 0x89, 0xa0, 0x0c, 0x1a, 0x4f, 0x91, 0xdd, 0x25,
+	0xe2, 0x91, 0x33, 0x0c, 0x45, 0x52, 0x5a, 0xb4, 0xec, 0x23, 0xe8, 0xa4, 0x8c, 0x4f, 0x9a, 0xb1,
+	0xfc, 0x49, 0xf4, 0x52, 0x1c, 0xa2, 0x86, 0xc3, 0x1b, 0xf6, 0x1a, 0x2c, 0x6f, 0x63, 0xc2, 0x09,
+	0x2b, 0x3e, 0x22, 0xc7, 0x37, 0x64, 0xfc, 0x7f, 0xac, 0xc3, 0xad, 0xc2, 0x80, 0x5f, 0x1f, 0x3f,
+	0xd4, 0xfa, 0x0a, 0x51, 0x89, 0xe5, 0xa7, 0x4d, 0x9a, 0x84, 0x1f, 0x50, 0x81, 0xf2, 0xa0, 0xbb,
+	0x31, 0x28, 0x48, 0x72, 0xaa, 0xf8, 0x36, 0x39, 0x95, 0x30, 0x73, 0xd5, 0x64, 0x3e, 0x5a, 0xbb,
+	0x33, 0xf1, 0x25, 0x7c, 0x1a, 0xbd, 0xe4, 0xc6, 0x8a, 0xa3, 0x52, 0x15, 0x7a, 0x49, 0x03, 0xfd,
+	0xcf, 0x62, 0x9f, 0x10, 0x1c, 0x8a, 0xf0, 0x40, 0x81, 0xd1, 0xa8, 0x86, 0xde, 0x98, 0x9e, 0xc7,
+	0x51, 0x0f, 0x27, 0xa9, 0x3b, 0x68, 0x38, 0x2a, 0x90, 0xae, 0x0f, 0x53, 0x8f, 0x22, 0x1c, 0x02,
+	0x6f, 0x48, 0xbb, 0x0b, 0xf2, 0xee, 0xa2, 0xf7, 0x53, 0x2d, 0xa4, 0xb9, 0x18, 0x66, 0xeb, 0x0b,
+	0x07, 0xeb, 0x71, 0xd6, 0xef, 0x48, 0xb8, 0x94, 0x1b, 0xc6, 0x9d, 0x50, 0x43, 0x8f, 0xd9, 0xfb,
+	0x86, 0xa3, 0x02, 0xa9, 0x96, 0x93, 0x88, 0xb8, 0x01, 0xbf, 0xdd, 0xb4, 0x19, 0x8a, 0x04, 0xa1,
+	0xb6, 0x19, 0xf2, 0x09, 0xf8, 0x1d, 0xfa, 0xd4, 0x0f, 0xb1, 0xd0, 0x5f, 0xd1, 0xba, 0x56, 0xd0,
+	0xf7, 0x10, 0x16, 0x7b, 0xc3, 0x38, 0xc6, 0x61, 0x59, 0x9e, 0xa7, 0xac, 0xeb, 0x3a, 0x37, 0x70,
+	0xba, 0xfd, 0x49, 0x9a, 0xf9, 0x6c, 0x38, 0xec, 0xb7, 0xfd, 0x08, 0x16, 0x0f, 0x49, 0x8c, 0xdd,
+	0xbe, 0x7a, 0xa2, 0x15, 0xad, 0x30, 0xf4, 0x13, 0xfb, 0x13, 0x98, 0xe3, 0xe8, 0x9f, 0xb0, 0x87,
+	0x76, 0xaa, 0x71, 0x17, 0xd4, 0x4f, 0x45, 0xa1, 0xb0, 0xdc, 0x69, 0xf3, 0x5a, 0x8b, 0x1d, 0xff,
+	0xd0, 0xf0, 0x3f, 0x06, 0xcc, 0xf2, 0xc9, 0xd8, 0x55, 0x01, 0xad, 0x43, 0xf3, 0x8c, 0xcd, 0x2a,
+	0x4e, 0x88, 0x55, 0xb6, 0xc3, 0x9c, 0x2f, 0x47, 0x60, 0xf2, 0x78, 0xfc, 0x8b, 0x21, 0x0e, 0x7b,
+	0x5a, 0x16, 0x47, 0x85, 0x4e, 0x12, 0xfc, 0x2b, 0x91, 0x34, 0x15, 0xfa, 0xb4, 0x14, 0x49, 0x23,
+	0x68, 0x50, 0x77, 0x28, 0xb2, 0x31, 0xec, 0xb7, 0x7c, 0x81, 0x7b, 0x22, 0xe6, 0x6a, 0x8a, 0xeb,
+	0x90, 0x0a, 0xb6, 0x31, 0x2c, 0xf1, 0xad, 0xd1, 0xac, 0xe3, 0xd8, 0xbd, 0x41, 0xef, 0xa4, 0x91,
+	0x77, 0x8d, 0x89, 0xe7, 0xb5, 0x32, 0xf1, 0x30, 0x49, 0xa6, 0x41, 0xf9, 0x63, 0xe8, 0x6c, 0x78,
+	0xde, 0x41, 0xe4, 0x65, 0x13, 0x8c, 0xa9, 0x99, 0xa0, 0xbf, 0x5e, 0xc4, 0x41, 0x5a, 0x33, 0x21,
+	0x9a, 0xf6, 0x5b, 0xb0, 0xe0, 0xe0, 0x7e, 0x74, 0x81, 0xaf, 0x41, 0x86, 0x46, 0xd3, 0x34, 0x89,
+	0x4d, 0x51, 0xb3, 0x68, 0xfa, 0x17, 0x06, 0xb4, 0x28, 0x20, 0x3d, 0x39, 0xaf, 0x36, 0x3f, 0x5a,
+	0x85, 0x46, 0x1c, 0x05, 0x5c, 0x7b, 0x0a, 0xe5, 0x13, 0x8c, 0xa7, 0x28, 0xc0, 0x0e, 0xc3, 0xa1,
+	0x87, 0x9d, 0x25, 0x4a, 0xa3, 0x90, 0xb8, 0x3d, 0x92, 0xdd, 0x0d, 0x54, 0xa0, 0x5c, 0x1f, 0x32,
+	0xa5, 0xd6, 0x87, 0x7c, 0x6d, 0xc0, 0x82, 0xc4, 0xff, 0xa4, 0x29, 0x1e, 0x5e, 0xad, 0xb2, 0xe3,
+	0xa5, 0x29, 0x9e, 0xb4, 0x8d, 0xde, 0x86, 0x29, 0xba, 0xac, 0x54, 0x05, 0x4b, 0x16, 0xc3, 0xec,
+	0x17, 0x47, 0xb2, 0x0f, 0xe1, 0xd6, 0x16, 0xee, 0x45, 0xfd, 0xbe, 0x9f, 0xd0, 0x03, 0x77, 0x9d,
+	0x6d, 0xbc, 0x0b, 0xb3, 0xc4, 0xef, 0xe3, 0x68, 0x48, 0x58, 0xac, 0xc5, 0xe7, 0x97, 0x41, 0xf6,
+	0xf7, 0x60, 0x65, 0x1b, 0x13, 0x99, 0xae, 0xea, 0xd7, 0xaa, 0x76, 0xf6, 0xe7, 0x75, 0x78, 0xa3,
+	0x62, 0xe0, 0xa4, 0x4f, 0xb8, 0x62, 0x9e, 0x9a, 0xb2, 0x82, 0xf7, 0x52, 0xaf, 0x54, 0x2f, 0x7b,
+	0x13, 0xd4, 0xa7, 0xcf, 0x1c, 0x53, 0xe6, 0x4e, 0x1a, 0xb2, 0x3b, 0x59, 0x03, 0x44, 0xdc, 0xf8,
+	0x14, 0x97, 0xe5, 0x3c, 0x4a, 0x7a, 0xd0, 0x05, 0x2c, 0xf6, 0x31, 0xfd, 0x25, 0x43, 0xe9, 0x21,
+	0xa6, 0xbb, 0xb5, 0xa5, 0xb2, 0x32, 0x56, 0x18, 0x6b, 0xfb, 0x45, 0x32, 0xf4, 0xec, 0x8f, 0x9c,
+	0xb2, 0x09, 0xac, 0xa7, 0xd0, 0xad, 0x1a, 0x20, 0xa7, 0x53, 0xdb, 0x25, 0x45, 0x4a, 0x0d, 0x71,
+	0x25, 0xfe, 0xa0, 0xf6, 0xbe, 0x61, 0xaf, 0xc3, 0xd2, 0x66, 0x30, 0x4c, 0x08, 0x8e, 0x55, 0x93,
+	0x4f, 0x75, 0x32, 0xe2, 0xf1, 0xb4, 0xb0, 0x2a, 0x59, 0xdb, 0x1e, 0xc1, 0x4d, 0x65, 0xcc, 0x46,
+	0x4c, 0xfc, 0x13, 0xb7, 0x57, 0xad, 0x63, 0x32, 0xb1, 0x9a, 0x4a, 0x0c, 0xbd, 0x0d, 0x0d, 0x9f,
+	0x7a, 0xe8, 0xfa, 0x15, 0x1e, 0x9a, 0x61, 0xd9, 0xbf, 0xab, 0x4d, 0xbd, 0xef, 0x86, 0xfe, 0x89,
+	0xc8, 0x39, 0xf7, 0x8a, 0xa9, 0x4c, 0x05, 0x86, 0x36, 0x60, 0xc6, 0x15, 0xac, 0xa6, 0x69, 0xdf,
+	0xfb, 0x5a, 0x16, 0xac, 0x6c, 0x59, 0x4e, 0x3e, 0xca, 0xfe, 0x7d, 0x43, 0x63, 0x60, 0x42, 0x5d,
+	0xfe, 0x08, 0x5a, 0x7d, 0xc1, 0xba, 0x30, 0xcd, 0xe3, 0x38, 0x49, 0x57, 0xe9, 0x64, 0x83, 0xec,
+	0x47, 0x19, 0x1f, 0x9a, 0x3f, 0x18, 0xb7, 0x71, 0x9f, 0x00, 0x7a, 0x4a, 0x1d, 0x1c, 0x8d, 0xbb,
+	0xf2, 0x4c, 0x70, 0x17, 0xa6, 0x4f, 0x28, 0x54, 0x6c, 0xdb, 0x8c, 0x93, 0x36, 0x69, 0x0f, 0x21,
+	0x81, 0x64, 0x17, 0xd2, 0xa6, 0x7d, 0x0a, 0x8b, 0x0a, 0xa5, 0x6f, 0x2a, 0x03, 0x67, 0x1f, 0xc3,
+	0xd2, 0x8b, 0xf0, 0xe4, 0x55, 0x98, 0xfe, 0x0e, 0xb4, 0x63, 0xe6, 0x7d, 0xb8, 0xec, 0x12, 0xf1,
+	0x04, 0xad, 0x02, 0xed, 0x08, 0x16, 0x85, 0x6c, 0xd9, 0x29, 0xba, 0x9a, 0xec, 0x75, 0x62, 0x17,
+	0x59, 0xf6, 0x75, 0x4d, 0xf6, 0x31, 0x2c, 0xa9, 0x13, 0x4e, 0xf8, 0xe2, 0xc5, 0x4f, 0x4b, 0xed,
+	0x5a, 0xa7, 0x65, 0x00, 0x4b, 0x42, 0x3b, 0xbe, 0xad, 0x55, 0xfe, 0xb4, 0x06, 0xcd, 0x3d, 0xbf,
+	0xef, 0x93, 0x84, 0xe5, 0x21, 0x30, 0x39, 0x8b, 0x3c, 0x87, 0xda, 0x66, 0x3a, 0x8f, 0xe1, 0x48,
+	0x10, 0xea, 0x78, 0x78, 0xeb, 0xf1, 0x30, 0x16, 0xa7, 0xa0, 0xed, 0xc8, 0x20, 0xf6, 0x2a, 0x1e,
+	0x9d, 0xe3, 0xd0, 0x49, 0x8d, 0xbb, 0xe1, 0xe4, 0x00, 0x1e, 0x80, 0x9f, 0xe3, 0x90, 0x0f, 0x6f,
+	0xb0, 0xe1, 0x12, 0x84, 0x86, 0x56, 0x52, 0x5a, 0x8b, 0xd1, 0x98, 0x62, 0x34, 0x74, 0x30, 0xcd,
+	0x72, 0x4b, 0x20, 0x4e, 0xaf, 0xc9, 0xe8, 0x15, 0xe0, 0x8c, 0x6b, 0xf7, 0x72, 0x27, 0x7c, 0x1a,
+	0xf8, 0xa7, 0x67, 0x3c, 0xd9, 0xd9, 0x76, 0x64, 0x90, 0x48, 0x0f, 0x72, 0x21, 0xa4, 0x01, 0x4d,
+	0x04, 0x0b, 0x12, 0x6c, 0xc2, 0x9d, 0x6f, 0x06, 0x6c, 0x7c, 0xb7, 0x56, 0x86, 0x2d, 0x68, 0x0b,
+	0x1c, 0x5a, 0x7f, 0x78, 0xa8, 0x31, 0x21, 0x51, 0x30, 0xae, 0x41, 0xa1, 0xcb, 0xee, 0xb1, 0x87,
+	0x24, 0x8a, 0xdd, 0x53, 0x4c, 0x79, 0xc9, 0x16, 0xf3, 0xcf, 0xfc, 0xc6, 0xaa, 0x76, 0x4d, 0xea,
+	0xd1, 0xc5, 0xa5, 0xa8, 0xa6, 0x5c, 0x8a, 0xde, 0x87, 0x5b, 0xee, 0x60, 0x10, 0x47, 0x97, 0x7e,
+	0xdf, 0x25, 0xf8, 0x40, 0xbe, 0xc9, 0xf0, 0x4b, 0x4f, 0x55, 0x37, 0x8d, 0xed, 0x3d, 0x3f, 0x39,
+	0x7f, 0x91, 0xb8, 0xa7, 0x98, 0xdf, 0xcc, 0x44, 0x86, 0x53, 0x85, 0xa2, 0x0f, 0xa0, 0xcb, 0x23,
+	0xbc, 0xfe, 0xc0, 0xed, 0xd1, 0xdd, 0x2d, 0xe4, 0x39, 0x2b, 0xfb, 0xd1, 0xe7, 0x30, 0xcb, 0xf9,
+	0x64, 0x4b, 0x17, 0xae, 0xfe, 0x7b, 0x05, 0x57, 0x5f, 0x26, 0x9f, 0xb5, 0x27, 0xf9, 0x40, 0xee,
+	0xdc, 0x65, 0x52, 0xe8, 0x43, 0x5a, 0x50, 0x94, 0xce, 0xd8, 0x9d, 0x2e, 0xcb, 0x09, 0xe6, 0x1c,
+	0x09, 0x59, 0x4a, 0x23, 0xac, 0x0f, 0xc1, 0xd4, 0x27, 0x90, 0x83, 0x81, 0x99, 0x92, 0x60, 0x60,
+	0x46, 0x0e, 0x06, 0x76, 0x60, 0x51, 0xd0, 0x57, 0x9e, 0xc8, 0x27, 0x78, 0x1b, 0xb6, 0xff, 0xce,
+	0x00, 0x53, 0xe7, 0x75, 0x12, 0x42, 0x2c, 0x7f, 0x31, 0x0c, 0x43, 0x3f, 0x3c, 0xcd, 0xf2, 0x17,
+	0xbc, 0x49, 0x0f, 0x38, 0x1b, 0x5d, 0xc8, 0x3a, 0xea, 0x60, 0xea, 0x12, 0x70, 0xe8, 0x15, 0xb6,
+	0x58, 0x05, 0xe6, 0x01, 0x61, 0x53, 0x0a, 0x08, 0xed, 0x0e, 0xcc, 0x3d, 0x0d, 0x86, 0xc9, 0x59,
+	0xaa, 0xfd, 0x7f, 0x68, 0x00, 0xe2, 0xcf, 0x8f, 0xf2, 0xa1, 0xa0, 0xec, 0x0f, 0xe4, 0x02, 0x26,
+	0xd1, 0x62, 0x44, 0x2f, 0xdd, 0x1e, 0x11, 0x5e, 0x88, 0x37, 0xc4, 0x03, 0x29, 0xd5, 0xd8, 0xe7,
+	0x2c, 0x91, 0x19, 0x89, 0x8a, 0xc9, 0xb6, 0x53, 0x80, 0x5f, 0x51, 0xa9, 0xf5, 0x0f, 0x06, 0x2c,
+	0x2a, 0xec, 0x7c, 0x63, 0xb9, 0x40, 0x5a, 0x6e, 0xe0, 0x7f, 0x85, 0xe5, 0xd7, 0xdc, 0x1c, 0x90,
+	0xaf, 0xb3, 0x21, 0xaf, 0x73, 0x1d, 0xa6, 0xbe, 0x18, 0x46, 0xc4, 0x65, 0x02, 0x2f, 0x3c, 0xb2,
+	0x1f, 0xa4, 0xab, 0xf8, 0x21, 0xc5, 0x71, 0x38, 0xaa, 0xfd, 0x2f, 0x06, 0x74, 0xd4, 0x9e, 0x2b,
+	0xee, 0xb8, 0xf4, 0x73, 0x01, 0xf7, 0x52, 0xe2, 0x3b, 0x6d, 0x52, 0x7d, 0xeb, 0xbb, 0x97, 0x32,
+	0xc7, 0x59, 0xfb, 0x5a, 0x29, 0x12, 0x65, 0xc9, 0x53, 0xfa, 0x92, 0x1f, 0xc2, 0x62, 0x4c, 0xb7,
+	0xa8, 0xe7, 0x07, 0x58, 0xd2, 0xad, 0x26, 0xd3, 0xad, 0xb2, 0x2e, 0x1b, 0xc3, 0xfc, 0x21, 0x26,
+	0x7c, 0xb5, 0xd7, 0xba, 0xbe, 0x4f, 0xb4, 0x34, 0x7b, 0x91, 0x5f, 0x49, 0xd9, 0x3c, 0x99, 0xd5,
+	0xbe, 0x04, 0x24, 0x03, 0x27, 0x2d, 0x9a, 0x60, 0x7b, 0x54, 0x51, 0x34, 0xa1, 0xed, 0xa7, 0xc0,
+	0x15, 0x0f, 0xb9, 0x87, 0x0c, 0x4b, 0x2e, 0xf9, 0xfa, 0xba, 0x01, 0x37, 0xb5, 0x8e, 0x49, 0x63,
+	0x22, 0x76, 0xdd, 0xaf, 0xb1, 0xeb, 0x9f, 0x16, 0x13, 0x71, 0xea, 0xea, 0x85, 0x3f, 0xe1, 0x96,
+	0x99, 0x9b, 0x4a, 0x11, 0xc2, 0xa8, 0x40, 0x5a, 0x5c, 0xa6, 0x00, 0x8e, 0x45, 0x42, 0x8b, 0x9f,
+	0xbf, 0xd2, 0x3e, 0x39, 0xef, 0x25, 0x92, 0x04, 0xa2, 0x49, 0x8d, 0x03, 0xbb, 0xf6, 0x11, 0x61,
+	0x5a, 0x44, 0x8b, 0xea, 0xe0, 0x70, 0x40, 0x72, 0xd5, 0x99, 0x66, 0xaa, 0xa3, 0xc0, 0xd0, 0x31,
+	0xcc, 0x06, 0xac, 0xe6, 0x9e, 0xa6, 0x1b, 0x92, 0x6e, 0x8b, 0x09, 0xfe, 0xdd, 0xa2, 0xb7, 0x29,
+	0x48, 0x71, 0x6d, 0x2f, 0x1f, 0x26, 0x7c, 0x8d, 0x44, 0x88, 0x07, 0x32, 0x7e, 0x48, 0x70, 0xe8,
+	0x86, 0x3d, 0xcc, 0x72, 0xaa, 0x2d, 0x47, 0x06, 0xd1, 0xea, 0x2a, 0xa9, 0xe9, 0x60, 0x37, 0x89,
+	0x78, 0x92, 0x75, 0xc6, 0x29, 0x76, 0x50, 0xdf, 0xa3, 0x4f, 0xf8, 0x4a, 0xbe, 0xe7, 0x3d, 0x78,
+	0xfd, 0x49, 0x48, 0x70, 0xbc, 0x9f, 0x53, 0xde, 0x57, 0xd3, 0x17, 0x31, 0xe7, 0x40, 0xe4, 0x4f,
+	0x79, 0xcb, 0x5e, 0x01, 0xeb, 0xc9, 0xa5, 0x4f, 0xca, 0x47, 0xd9, 0x6f, 0xc0, 0xeb, 0x0e, 0x0e,
+	0x22, 0xd7, 0x3b, 0xc4, 0xbd, 0x61, 0xec, 0x93, 0xd1, 0x66, 0x14, 0x9e, 0xf8, 0x69, 0xe9, 0x9a,
+	0xfd, 0xaf, 0x06, 0xac, 0x94, 0xf7, 0x4f, 0x9a, 0xc7, 0x89, 0x19, 0x35, 0x56, 0xf7, 0x5a, 0xa7,
+	0x81, 0x71, 0xda, 0xa6, 0xfc, 0x63, 0xfe, 0x3e, 0x5a, 0x67, 0x3d, 0xa2, 0xc5, 0x72, 0xbb, 0x98,
+	0x5e, 0x2f, 0x69, 0x94, 0x89, 0x0f, 0x22, 0xc2, 0xde, 0xb6, 0x84, 0x23, 0x2b, 0xeb, 0xa2, 0x21,
+	0x4e, 0xf6, 0xda, 0x4f, 0xa3, 0xdd, 0x44, 0x3c, 0xd9, 0x6b, 0xd0, 0xd5, 0xff, 0xad, 0x01, 0x70,
+	0x06, 0x37, 0x23, 0x0f, 0xa3, 0x26, 0xd4, 0x9e, 0x9d, 0x9b, 0x37, 0xd0, 0x32, 0x20, 0x51, 0x13,
+	0xf2, 0x22, 0x74, 0x2f, 0x5c, 0x3f, 0x70, 0x5f, 0x06, 0xd8, 0x34, 0x50, 0x1b, 0x66, 0x0e, 0x89,
+	0x1b, 0xd0, 0xed, 0xf4, 0xcc, 0x1a, 0x6d, 0x1e, 0x44, 0x84, 0x7f, 0x3a, 0x65, 0xd6, 0xd1, 0x22,
+	0xcc, 0x1f, 0x44, 0xe1, 0xc1, 0xb0, 0x8f, 0x63, 0xbf, 0xc7, 0x2a, 0x64, 0xcd, 0x06, 0x9a, 0x87,
+	0xd9, 0x5d, 0x3c, 0x3a, 0x8a, 0xa2, 0x3d, 0x9a, 0x17, 0x31, 0xa7, 0xd0, 0x02, 0xb4, 0x59, 0x5f,
+	0x06, 0x6a, 0x0a, 0x9c, 0x83, 0x88, 0x3c, 0xa5, 0x5f, 0x02, 0x98, 0xd3, 0x94, 0x12, 0x9d, 0x82,
+	0x16, 0xe1, 0x8a, 0x37, 0x3b, 0xb3, 0x45, 0x81, 0x3b, 0xe1, 0x85, 0x1b, 0xf8, 0xde, 0x46, 0x7c,
+	0x3a, 0xec, 0xd3, 0x6a, 0xeb, 0x19, 0xb4, 0x04, 0x66, 0x7a, 0xa3, 0x49, 0x4b, 0x12, 0x4d, 0x40,
+	0x77, 0xe0, 0xf5, 0x3d, 0x3f, 0xc4, 0x6e, 0xec, 0x7f, 0x45, 0x39, 0xa7, 0xb4, 0x5e, 0x84, 0xc9,
+	0x70, 0x30, 0x88, 0x62, 0x82, 0x3d, 0x73, 0x96, 0x0e, 0xdb, 0x14, 0x29, 0xd7, 0x7d, 0x3f, 0xe9,
+	0xd3, 0x47, 0x5d, 0x73, 0x0e, 0x75, 0x61, 0x29, 0x0f, 0x47, 0x24, 0x82, 0x6d, 0x8e, 0xcf, 0x04,
+	0x92, 0x96, 0x25, 0x7a, 0x66, 0x87, 0xf2, 0x2d, 0xe9, 0x94, 0x39, 0x8f, 0x3a, 0x00, 0x82, 0xc5,
+	0x5d, 0x3c, 0x32, 0x4d, 0xba, 0x56, 0x66, 0xe6, 0x9e, 0x5c, 0xf2, 0xc2, 0x66, 0x73, 0x81, 0xca,
+	0x6c, 0x17, 0x8f, 0xf8, 0x67, 0x0a, 0x26, 0x5a, 0x7d, 0xc4, 0x57, 0x2a, 0x7f, 0x34, 0xd3, 0x01,
+	0x38, 0x64, 0x49, 0x66, 0xe2, 0xbb, 0x81, 0x79, 0x03, 0x99, 0x30, 0x27, 0x2f, 0xc6, 0x34, 0x56,
+	0x1f, 0x42, 0x2b, 0xfd, 0xc0, 0x8b, 0xf2, 0xb0, 0x85, 0x4f, 0xdc, 0x61, 0x40, 0x28, 0xc8, 0xbc,
+	0x81, 0x5a, 0xd0, 0x60, 0xbf, 0x0c, 0x34, 0x03, 0x53, 0x1b, 0xf4, 0xf3, 0x2f, 0xb3, 0xb6, 0xfa,
+	0x08, 0x3a, 0xea, 0xcb, 0x0b, 0x2d, 0x61, 0x70, 0x78, 0x8c, 0xc4, 0xc7, 0x6c, 0x45, 0x21, 0xe6,
+	0x35, 0x0c, 0x4f, 0xd9, 0x87, 0x2b, 0x66, 0x6d, 0xf5, 0x3d, 0x9e, 0x60, 0xa5, 0x76, 0x91, 0x4e,
+	0x23, 0x2a, 0x1e, 0x68, 0x93, 0x97, 0xbc, 0x8b, 0x8d, 0x37, 0xd0, 0x1c, 0xb4, 0x9e, 0x46, 0x41,
+	0x10, 0x7d, 0x89, 0x63, 0xb3, 0xb6, 0x3a, 0x82, 0x85, 0x42, 0x3e, 0x0d, 0x59, 0xb0, 0x7c, 0x14,
+	0xbb, 0x61, 0x72, 0x82, 0xe3, 0xd8, 0x0f, 0x4f, 0xf9, 0xd0, 0xe4, 0xcc, 0x1f, 0x98, 0x37, 0xe8,
+	0x82, 0x37, 0xe9, 0x0e, 0xf8, 0xe1, 0xe9, 0x8b, 0x01, 0x27, 0xc7, 0x52, 0xc3, 0x94, 0xb7, 0x1a,
+	0x42, 0xd0, 0x91, 0xc9, 0x61, 0xcf, 0xac, 0x53, 0xfd, 0x94, 0x61, 0x82, 0xe3, 0xc6, 0xea, 0x23,
+	0x2a, 0xba, 0xd4, 0x96, 0x33, 0x41, 0x12, 0x37, 0xf4, 0xdc, 0x20, 0x0a, 0x05, 0xcb, 0xfc, 0x21,
+	0x97, 0xcb, 0x86, 0xd5, 0x1a, 0x99, 0xb5, 0xf5, 0x3f, 0x6a, 0x42, 0x7d, 0x6b, 0xf7, 0x18, 0x7d,
+	0xc0, 0xea, 0x38, 0x50, 0x65, 0x02, 0xdf, 0x7a, 0xad, 0xa4, 0x47, 0xd8, 0x80, 0x1d, 0x68, 0xa5,
+	0x1f, 0xb4, 0x21, 0xad, 0x60, 0x56, 0xfb, 0x6e, 0xce, 0xba, 0x5d, 0xd5, 0x2d, 0x48, 0x7d, 0x00,
+	0xf5, 0x6d, 0x5c, 0x60, 0x63, 0x1b, 0x57, 0xb1, 0xb1, 0x8d, 0x8b, 0x6c, 0x6c, 0xe3, 0x72, 0x36,
+	0xb6, 0xf1, 0x58, 0x36, 0x64, 0x52, 0x9b, 0xd0, 0xe4, 0x4a, 0x8a, 0x5e, 0x57, 0x31, 0x95, 0x8f,
+	0x74, 0xac, 0x95, 0xf2, 0xce, 0x9c, 0x08, 0xaf, 0x88, 0xd1, 0x89, 0x28, 0xdf, 0xee, 0x59, 0x2b,
+	0xe5, 0x9d, 0xf9, 0xa2, 0xd2, 0xcf, 0xe4, 0xf4, 0x45, 0x69, 0x5f, 0xe0, 0x59, 0xb7, 0xab, 0xba,
+	0x05, 0xa9, 0xcf, 0xa1, 0xad, 0x7c, 0x3d, 0x83, 0xec, 0x92, 0x8b, 0x93, 0xf6, 0x91, 0x8f, 0x75,
+	0x7f, 0x2c, 0x8e, 0xa0, 0xbc, 0x07, 0x33, 0xd9, 0xc7, 0x2d, 0x48, 0x63, 0x43, 0xff, 0x9e, 0xc6,
+	0xba, 0x53, 0xd9, 0x9f, 0xeb, 0xc0, 0xd1, 0x65, 0xa8, 0xeb, 0x40, 0xfe, 0x55, 0x89, 0xf5, 0x5a,
+	0x49, 0x8f, 0x18, 0xfb, 0x09, 0x4c, 0x8b, 0xef, 0x11, 0x90, 0x26, 0x57, 0xf5, 0x8b, 0x0a, 0xeb,
+	0x8d, 0x8a, 0x5e, 0x4e, 0xe7, 0xa1, 0xb1, 0xfe, 0x1f, 0x53, 0xd0, 0xd9, 0xda, 0x3d, 0x16, 0x16,
+	0x98, 0x25, 0x62, 0x9f, 0xb1, 0xef, 0x0d, 0xd3, 0x7a, 0xc1, 0x3b, 0x05, 0x4d, 0x54, 0xeb, 0x47,
+	0xad, 0xbb, 0xd5, 0x08, 0x82, 0xdb, 0x23, 0x68, 0xf3, 0x17, 0xab, 0x5f, 0x1f, 0xcd, 0x87, 0x06,
+	0xfa, 0x11, 0xb4, 0x95, 0xea, 0x3f, 0x7d, 0x9f, 0xcb, 0x6a, 0x06, 0xad, 0xfb, 0x63, 0x71, 0x32,
+	0xda, 0x0e, 0xcc, 0x4a, 0x05, 0xbb, 0x48, 0x63, 0xa7, 0x58, 0x3c, 0x6e, 0xdd, 0x1b, 0x83, 0x21,
+	0xa4, 0xf0, 0x5b, 0xac, 0xd4, 0x5a, 0x2a, 0x58, 0x46, 0xf7, 0x0b, 0x65, 0xc3, 0xc5, 0x42, 0x71,
+	0xeb, 0x3b, 0xe3, 0x91, 0x04, 0x71, 0x17, 0xcc, 0x4c, 0x48, 0xe2, 0xb3, 0x03, 0xf4, 0x66, 0x85,
+	0x10, 0xd5, 0x0f, 0x2e, 0xac, 0xef, 0x5e, 0x85, 0x26, 0xa6, 0xf0, 0x60, 0xa1, 0x50, 0xae, 0x8f,
+	0xbe, 0xab, 0x57, 0xe7, 0x95, 0x7f, 0x1b, 0x60, 0xfd, 0xbf, 0x2b, 0xf1, 0xc4, 0x2c, 0x2f, 0xa8,
+	0x23, 0xcc, 0x3f, 0x65, 0x41, 0xf7, 0xf4, 0xdc, 0x54, 0xe1, 0xf3, 0x17, 0xcb, 0x1e, 0x87, 0xc2,
+	0xc9, 0xae, 0x7b, 0xb0, 0xa4, 0x6a, 0xb9, 0x48, 0x44, 0xec, 0xc1, 0x4c, 0x56, 0xad, 0xa7, 0x1f,
+	0x69, 0xbd, 0xb6, 0xcf, 0xba, 0x53, 0xd9, 0x2f, 0x66, 0xf9, 0xa5, 0x01, 0x37, 0xd5, 0x69, 0xe8,
+	0xcb, 0x61, 0x1c, 0x05, 0xe8, 0x19, 0x98, 0x7a, 0xad, 0x93, 0xbe, 0x3f, 0x15, 0xb5, 0x50, 0x56,
+	0x69, 0xa8, 0x89, 0x7e, 0x08, 0x0b, 0x85, 0x7a, 0x27, 0x7d, 0x37, 0xaa, 0x0a, 0xa2, 0xca, 0x49,
+	0xae, 0xf7, 0x61, 0x76, 0x6b, 0xf7, 0x98, 0xfa, 0xd9, 0xe8, 0x02, 0xc7, 0xe8, 0xc7, 0x30, 0xaf,
+	0xd5, 0x46, 0x21, 0x4d, 0x17, 0xcb, 0x8b, 0xaa, 0xac, 0x37, 0xaf, 0xc0, 0x12, 0xc2, 0xfa, 0xaf,
+	0x06, 0x98, 0x5b, 0xbb, 0xc7, 0xd9, 0xeb, 0x09, 0x2b, 0x45, 0xf9, 0x01, 0x34, 0x39, 0x40, 0x77,
+	0x26, 0xca, 0xa3, 0x54, 0x85, 0x4c, 0x7e, 0x03, 0xa6, 0x53, 0x3a, 0x2b, 0x05, 0x49, 0x48, 0x4f,
+	0x23, 0x15, 0xc3, 0x3f, 0x81, 0xd9, 0x43, 0xe2, 0xc6, 0xe4, 0x3a, 0x0c, 0xac, 0x94, 0x77, 0x0a,
+	0x25, 0xfe, 0x14, 0xe6, 0x18, 0xa5, 0xeb, 0x71, 0x33, 0x9e, 0xd6, 0x8f, 0x61, 0x5e, 0xab, 0x0a,
+	0xd2, 0xb7, 0xa1, 0xbc, 0xca, 0xc8, 0x7a, 0xf3, 0x0a, 0x2c, 0x41, 0xff, 0x00, 0xe6, 0xb8, 0x71,
+	0x16, 0xcb, 0xbe, 0xa7, 0xcb, 0xa6, 0x50, 0x05, 0x62, 0x55, 0x17, 0x0f, 0x3c, 0x34, 0xd0, 0x6e,
+	0x6a, 0xec, 0xd3, 0xc5, 0xdb, 0x65, 0x04, 0xaf, 0xb3, 0x21, 0x0f, 0x28, 0xb1, 0x56, 0x5a, 0xdc,
+	0xa6, 0x87, 0x05, 0x5a, 0xf1, 0x9c, 0x75, 0xbb, 0xaa, 0x9b, 0xaf, 0xf3, 0x81, 0xb1, 0xfe, 0x07,
+	0xd3, 0x00, 0x5b, 0xbb, 0xc7, 0xe2, 0xb5, 0x8c, 0x6a, 0x8b, 0x28, 0x70, 0xd0, 0xf7, 0x47, 0xad,
+	0x7b, 0xa8, 0xd0, 0x96, 0x4d, 0x80, 0xbc, 0xb6, 0x41, 0xf7, 0x68, 0x85, 0xaa, 0x87, 0x0a, 0x22,
+	0x7b, 0x30, 0x93, 0xd5, 0x0c, 0xe8, 0xe6, 0x47, 0x2f, 0x86, 0xb0, 0xee, 0x54, 0xf6, 0x8b, 0xad,
+	0x7c, 0x06, 0xa6, 0xfe, 0xe8, 0xaf, 0x1b, 0x99, 0x8a, 0xa2, 0x80, 0x0a, 0xf6, 0x06, 0x2c, 0x2f,
+	0x53, 0x7c, 0xaa, 0x46, 0xab, 0xd7, 0x7a, 0xcf, 0xe6, 0xa4, 0xdf, 0x7a, 0x85, 0xb7, 0x6f, 0x16,
+	0xbc, 0xc9, 0x0f, 0x9e, 0x85, 0xe0, 0xad, 0xe4, 0x89, 0xda, 0xba, 0x3f, 0x16, 0x47, 0x50, 0xde,
+	0x85, 0x8e, 0xfa, 0x4e, 0x8a, 0xca, 0x87, 0x5d, 0xcb, 0x54, 0x38, 0x30, 0x2b, 0xbd, 0x7a, 0xea,
+	0xf1, 0x41, 0xf1, 0x69, 0xd5, 0xba, 0x37, 0x06, 0x23, 0x0b, 0x81, 0xdb, 0xca, 0x03, 0xa7, 0xbe,
+	0xf4, 0xb2, 0xd7, 0xcf, 0x0a, 0xf6, 0x5e, 0xa4, 0x85, 0x58, 0xfc, 0xb5, 0x4f, 0x3f, 0xd3, 0x25,
+	0xef, 0x9d, 0x96, 0x3d, 0x0e, 0x25, 0xe7, 0x50, 0x79, 0x45, 0xd4, 0x39, 0x2c, 0x7b, 0x62, 0xac,
+	0xf0, 0x35, 0x7f, 0x62, 0xc0, 0xcc, 0xd6, 0xee, 0xb1, 0x78, 0x21, 0xe4, 0x5e, 0x38, 0x7d, 0x2e,
+	0x2c, 0xe8, 0x8b, 0xf2, 0x7a, 0x65, 0xdd, 0xa9, 0xec, 0x17, 0x6c, 0x6e, 0xc0, 0xcc, 0x61, 0x15,
+	0x35, 0xfd, 0x2d, 0xac, 0x82, 0xbd, 0x7f, 0xaa, 0x33, 0x53, 0x21, 0x5e, 0x6e, 0x84, 0x0d, 0x96,
+	0xdf, 0x71, 0x4a, 0x6c, 0x70, 0xc9, 0x0b, 0x99, 0xf5, 0xe6, 0x15, 0x58, 0x82, 0xe3, 0x6d, 0x98,
+	0x93, 0x9f, 0x5b, 0xf4, 0xfd, 0x2a, 0x79, 0x8a, 0xa9, 0xd8, 0xf8, 0xef, 0xc3, 0x14, 0x7b, 0xa3,
+	0x40, 0x5a, 0xf9, 0x9b, 0xfc, 0x70, 0x51, 0xad, 0xd2, 0xd2, 0xf3, 0x81, 0xae, 0xd2, 0xc5, 0x87,
+	0x0e, 0xeb, 0xde, 0x18, 0x0c, 0xb1, 0xae, 0x8f, 0xa0, 0x95, 0xa6, 0xb9, 0x75, 0xf3, 0xad, 0xa5,
+	0xbf, 0x2b, 0x98, 0x7a, 0x06, 0x90, 0xe7, 0xaa, 0x51, 0x89, 0x01, 0x54, 0x52, 0xdb, 0xd6, 0xdd,
+	0x6a, 0x04, 0x11, 0x74, 0xf4, 0x60, 0x7a, 0x6b, 0xf7, 0x98, 0x85, 0xc7, 0x9f, 0xb3, 0xfb, 0x43,
+	0x9e, 0x2e, 0x2d, 0xb9, 0x3f, 0x14, 0x52, 0xd5, 0xd6, 0xfd, 0xb1, 0x38, 0x62, 0x92, 0xbf, 0x35,
+	0xd8, 0x9d, 0x4a, 0x4a, 0x1b, 0xa1, 0xcf, 0x60, 0xa9, 0x2c, 0xa9, 0x89, 0xfe, 0xbf, 0x76, 0xb5,
+	0xae, 0x4e, 0x7c, 0x56, 0x1e, 0xf5, 0xc5, 0x92, 0xb4, 0x27, 0x7a, 0x50, 0xb8, 0xb2, 0x93, 0x57,
+	0x21, 0xbb, 0xfe, 0x3b, 0x2c, 0x16, 0x4c, 0x93, 0xa1, 0xa8, 0x4f, 0xeb, 0x07, 0x8a, 0xe9, 0x51,
+	0x9d, 0xfd, 0x31, 0x29, 0x56, 0x6b, 0xf5, 0x3a, 0xa8, 0x5c, 0x80, 0x8f, 0xe1, 0x47, 0xad, 0x14,
+	0xf1, 0x65, 0x93, 0xfd, 0x6d, 0xd4, 0xa3, 0xff, 0x1b, 0x00, 0x35, 0x9e, 0x41, 0x95, 0x50, 0x4a,
+	0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// DKVClient is the client API for DKV service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DKVClient interface {
+	// Put puts the given key into the key value store
+	Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error)
+	// MultiPut atomically puts all the given keys into the key value store
+	MultiPut(ctx context.Context, in *MultiPutRequest, opts ...grpc.CallOption) (*MultiPutResponse, error)
+	// Get gets the value associated with the given key from the key value store
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	// MultiGet gets all the values associated with the given keys from the key value store
+	MultiGet(ctx context.Context, in *MultiGetRequest, opts ...grpc.CallOption) (*MultiGetResponse, error)
+	// Exists checks for the presence of the given keys in the key value store
+	Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error)
+	// Delete deletes the given key from the key value store
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// Undelete restores the given key from its tombstone, provided it was deleted
+	// while the DKV node retains tombstones and its retention window is yet to pass
+	Undelete(ctx context.Context, in *UndeleteRequest, opts ...grpc.CallOption) (*UndeleteResponse, error)
+	// CompareAndSet atomically sets the value of the given key only if its
+	// current value matches the given expected value
+	CompareAndSet(ctx context.Context, in *CompareAndSetRequest, opts ...grpc.CallOption) (*CompareAndSetResponse, error)
+	// Increment atomically adds the given delta to the numeric value of the
+	// given key and returns the resulting value
+	Increment(ctx context.Context, in *IncrementRequest, opts ...grpc.CallOption) (*IncrementResponse, error)
+	// Txn atomically checks the given conditions on the current values of keys, and
+	// applies either the mutations for when all of them hold or those for otherwise
+	Txn(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// Iterate streams all the key value pairs whose keys match the given prefix
+	Iterate(ctx context.Context, in *IterateRequest, opts ...grpc.CallOption) (DKV_IterateClient, error)
+}
+
+type dKVClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDKVClient(cc grpc.ClientConnInterface) DKVClient {
+	return &dKVClient{cc}
+}
+
+func (c *dKVClient) Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error) {
+	out := new(PutResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/Put", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClient) MultiPut(ctx context.Context, in *MultiPutRequest, opts ...grpc.CallOption) (*MultiPutResponse, error) {
+	out := new(MultiPutResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/MultiPut", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClient) MultiGet(ctx context.Context, in *MultiGetRequest, opts ...grpc.CallOption) (*MultiGetResponse, error) {
+	out := new(MultiGetResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/MultiGet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClient) Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error) {
+	out := new(ExistsResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/Exists", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClient) Undelete(ctx context.Context, in *UndeleteRequest, opts ...grpc.CallOption) (*UndeleteResponse, error) {
+	out := new(UndeleteResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/Undelete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClient) CompareAndSet(ctx context.Context, in *CompareAndSetRequest, opts ...grpc.CallOption) (*CompareAndSetResponse, error) {
+	out := new(CompareAndSetResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/CompareAndSet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClient) Increment(ctx context.Context, in *IncrementRequest, opts ...grpc.CallOption) (*IncrementResponse, error) {
+	out := new(IncrementResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/Increment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClient) Txn(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/Txn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClient) Iterate(ctx context.Context, in *IterateRequest, opts ...grpc.CallOption) (DKV_IterateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DKV_serviceDesc.Streams[0], "/dkv.serverpb.DKV/Iterate", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dKVIterateClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DKV_IterateClient interface {
+	Recv() (*IterateResponse, error)
+	grpc.ClientStream
+}
+
+type dKVIterateClient struct {
+	grpc.ClientStream
+}
+
+func (x *dKVIterateClient) Recv() (*IterateResponse, error) {
+	m := new(IterateResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DKVServer is the server API for DKV service.
+type DKVServer interface {
+	// Put puts the given key into the key value store
+	Put(context.Context, *PutRequest) (*PutResponse, error)
+	// MultiPut atomically puts all the given keys into the key value store
+	MultiPut(context.Context, *MultiPutRequest) (*MultiPutResponse, error)
+	// Get gets the value associated with the given key from the key value store
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	// MultiGet gets all the values associated with the given keys from the key value store
+	MultiGet(context.Context, *MultiGetRequest) (*MultiGetResponse, error)
+	// Exists checks for the presence of the given keys in the key value store
+	Exists(context.Context, *ExistsRequest) (*ExistsResponse, error)
+	// Delete deletes the given key from the key value store
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// Undelete restores the given key from its tombstone, provided it was deleted
+	// while the DKV node retains tombstones and its retention window is yet to pass
+	Undelete(context.Context, *UndeleteRequest) (*UndeleteResponse, error)
+	// CompareAndSet atomically sets the value of the given key only if its
+	// current value matches the given expected value
+	CompareAndSet(context.Context, *CompareAndSetRequest) (*CompareAndSetResponse, error)
+	// Increment atomically adds the given delta to the numeric value of the
+	// given key and returns the resulting value
+	Increment(context.Context, *IncrementRequest) (*IncrementResponse, error)
+	// Txn atomically checks the given conditions on the current values of keys, and
+	// applies either the mutations for when all of them hold or those for otherwise
+	Txn(context.Context, *TxnRequest) (*TxnResponse, error)
+	// Iterate streams all the key value pairs whose keys match the given prefix
+	Iterate(*IterateRequest, DKV_IterateServer) error
+}
+
+// UnimplementedDKVServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVServer struct {
+}
+
+func (*UnimplementedDKVServer) Put(ctx context.Context, req *PutRequest) (*PutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
+}
+func (*UnimplementedDKVServer) MultiPut(ctx context.Context, req *MultiPutRequest) (*MultiPutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MultiPut not implemented")
+}
+func (*UnimplementedDKVServer) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (*UnimplementedDKVServer) MultiGet(ctx context.Context, req *MultiGetRequest) (*MultiGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MultiGet not implemented")
+}
+func (*UnimplementedDKVServer) Exists(ctx context.Context, req *ExistsRequest) (*ExistsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Exists not implemented")
+}
+func (*UnimplementedDKVServer) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (*UnimplementedDKVServer) Undelete(ctx context.Context, req *UndeleteRequest) (*UndeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Undelete not implemented")
+}
+func (*UnimplementedDKVServer) CompareAndSet(ctx context.Context, req *CompareAndSetRequest) (*CompareAndSetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompareAndSet not implemented")
+}
+func (*UnimplementedDKVServer) Increment(ctx context.Context, req *IncrementRequest) (*IncrementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Increment not implemented")
+}
+func (*UnimplementedDKVServer) Txn(ctx context.Context, req *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Txn not implemented")
+}
+func (*UnimplementedDKVServer) Iterate(req *IterateRequest, srv DKV_IterateServer) error {
+	return status.Errorf(codes.Unimplemented, "method Iterate not implemented")
+}
+
+func RegisterDKVServer(s *grpc.Server, srv DKVServer) {
+	s.RegisterService(&_DKV_serviceDesc, srv)
+}
+
+func _DKV_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKV/Put",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVServer).Put(ctx, req.(*PutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKV_MultiPut_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiPutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVServer).MultiPut(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKV/MultiPut",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVServer).MultiPut(ctx, req.(*MultiPutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKV_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKV/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKV_MultiGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVServer).MultiGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKV/MultiGet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVServer).MultiGet(ctx, req.(*MultiGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKV_Exists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVServer).Exists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKV/Exists",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVServer).Exists(ctx, req.(*ExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKV_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKV/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKV_Undelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVServer).Undelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKV/Undelete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVServer).Undelete(ctx, req.(*UndeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKV_CompareAndSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareAndSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVServer).CompareAndSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKV/CompareAndSet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVServer).CompareAndSet(ctx, req.(*CompareAndSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKV_Increment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IncrementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVServer).Increment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKV/Increment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVServer).Increment(ctx, req.(*IncrementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKV_Txn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVServer).Txn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKV/Txn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVServer).Txn(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKV_Iterate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(IterateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DKVServer).Iterate(m, &dKVIterateServer{stream})
+}
+
+type DKV_IterateServer interface {
+	Send(*IterateResponse) error
+	grpc.ServerStream
+}
+
+type dKVIterateServer struct {
+	grpc.ServerStream
+}
+
+func (x *dKVIterateServer) Send(m *IterateResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _DKV_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKV",
+	HandlerType: (*DKVServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Put",
+			Handler:    _DKV_Put_Handler,
+		},
+		{
+			MethodName: "MultiPut",
+			Handler:    _DKV_MultiPut_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _DKV_Get_Handler,
+		},
+		{
+			MethodName: "MultiGet",
+			Handler:    _DKV_MultiGet_Handler,
+		},
+		{
+			MethodName: "Exists",
+			Handler:    _DKV_Exists_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _DKV_Delete_Handler,
+		},
+		{
+			MethodName: "Undelete",
+			Handler:    _DKV_Undelete_Handler,
+		},
+		{
+			MethodName: "CompareAndSet",
+			Handler:    _DKV_CompareAndSet_Handler,
+		},
+		{
+			MethodName: "Increment",
+			Handler:    _DKV_Increment_Handler,
+		},
+		{
+			MethodName: "Txn",
+			Handler:    _DKV_Txn_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Iterate",
+			Handler:       _DKV_Iterate_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/serverpb/api.proto",
+}
+
+// DKVReplicationClient is the client API for DKVReplication service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DKVReplicationClient interface {
+	// GetChanges retrieves all changes from a given change number
+	GetChanges(ctx context.Context, in *GetChangesRequest, opts ...grpc.CallOption) (*GetChangesResponse, error)
+	// StreamChanges streams all changes from a given change number as and
+	// when they are committed. Every response carries a batch of atmost
+	// MaxNumberOfChanges changes, while responses without any changes are
+	// periodically sent as heartbeats carrying the master change number.
+	StreamChanges(ctx context.Context, in *GetChangesRequest, opts ...grpc.CallOption) (DKVReplication_StreamChangesClient, error)
+	// GetCheckpoint streams a consistent checkpoint of the entire keyspace,
+	// typically used for bootstrapping slaves whose required changes are
+	// no longer available. The first response carries only the change number
+	// as of which the checkpoint is captured, while the subsequent responses
+	// carry the key value pairs.
+	GetCheckpoint(ctx context.Context, in *GetCheckpointRequest, opts ...grpc.CallOption) (DKVReplication_GetCheckpointClient, error)
+	// VerifyRange computes a rolling checksum over the transactions of all
+	// the changes within a given range of change numbers. Nodes holding the
+	// same changes compute the same checksum for the same range.
+	VerifyRange(ctx context.Context, in *VerifyRangeRequest, opts ...grpc.CallOption) (*VerifyRangeResponse, error)
+	// KeyspaceDigest computes digests over consecutive ranges of keys, as of a
+	// snapshot of the keyspace, so that the keyspaces of nodes can be compared
+	// range by range without transferring their keys. Nodes holding the same
+	// keys compute the same digests for the same ranges.
+	KeyspaceDigest(ctx context.Context, in *KeyspaceDigestRequest, opts ...grpc.CallOption) (*KeyspaceDigestResponse, error)
+	// GetChangeLogInfo retrieves the range of changes retained by the master node for
+	// GetChanges, along with the progress of the replicas consuming these changes.
+	GetChangeLogInfo(ctx context.Context, in *GetChangeLogInfoRequest, opts ...grpc.CallOption) (*GetChangeLogInfoResponse, error)
+	// TruncateChangeLog discards the changes retained by the master node before the given
+	// change number, or as per its retention policy if none is given. Replicas requesting
+	// the discarded changes fail with the ChangesTruncated code.
+	TruncateChangeLog(ctx context.Context, in *TruncateChangeLogRequest, opts ...grpc.CallOption) (*TruncateChangeLogResponse, error)
+	// ListReplicas lists the replicas that retrieved changes from the master node within
+	// the last day, along with their replication lag from the perspective of the master node.
+	ListReplicas(ctx context.Context, in *ListReplicasRequest, opts ...grpc.CallOption) (*ListReplicasResponse, error)
+}
+
+type dKVReplicationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDKVReplicationClient(cc grpc.ClientConnInterface) DKVReplicationClient {
+	return &dKVReplicationClient{cc}
+}
+
+func (c *dKVReplicationClient) GetChanges(ctx context.Context, in *GetChangesRequest, opts ...grpc.CallOption) (*GetChangesResponse, error) {
+	out := new(GetChangesResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVReplication/GetChanges", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVReplicationClient) StreamChanges(ctx context.Context, in *GetChangesRequest, opts ...grpc.CallOption) (DKVReplication_StreamChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DKVReplication_serviceDesc.Streams[0], "/dkv.serverpb.DKVReplication/StreamChanges", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dKVReplicationStreamChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DKVReplication_StreamChangesClient interface {
+	Recv() (*GetChangesResponse, error)
+	grpc.ClientStream
+}
+
+type dKVReplicationStreamChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *dKVReplicationStreamChangesClient) Recv() (*GetChangesResponse, error) {
+	m := new(GetChangesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dKVReplicationClient) GetCheckpoint(ctx context.Context, in *GetCheckpointRequest, opts ...grpc.CallOption) (DKVReplication_GetCheckpointClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DKVReplication_serviceDesc.Streams[1], "/dkv.serverpb.DKVReplication/GetCheckpoint", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dKVReplicationGetCheckpointClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DKVReplication_GetCheckpointClient interface {
+	Recv() (*GetCheckpointResponse, error)
+	grpc.ClientStream
+}
+
+type dKVReplicationGetCheckpointClient struct {
+	grpc.ClientStream
+}
+
+func (x *dKVReplicationGetCheckpointClient) Recv() (*GetCheckpointResponse, error) {
+	m := new(GetCheckpointResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dKVReplicationClient) VerifyRange(ctx context.Context, in *VerifyRangeRequest, opts ...grpc.CallOption) (*VerifyRangeResponse, error) {
+	out := new(VerifyRangeResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVReplication/VerifyRange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVReplicationClient) KeyspaceDigest(ctx context.Context, in *KeyspaceDigestRequest, opts ...grpc.CallOption) (*KeyspaceDigestResponse, error) {
+	out := new(KeyspaceDigestResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVReplication/KeyspaceDigest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVReplicationClient) GetChangeLogInfo(ctx context.Context, in *GetChangeLogInfoRequest, opts ...grpc.CallOption) (*GetChangeLogInfoResponse, error) {
+	out := new(GetChangeLogInfoResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVReplication/GetChangeLogInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVReplicationClient) TruncateChangeLog(ctx context.Context, in *TruncateChangeLogRequest, opts ...grpc.CallOption) (*TruncateChangeLogResponse, error) {
+	out := new(TruncateChangeLogResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVReplication/TruncateChangeLog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVReplicationClient) ListReplicas(ctx context.Context, in *ListReplicasRequest, opts ...grpc.CallOption) (*ListReplicasResponse, error) {
+	out := new(ListReplicasResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVReplication/ListReplicas", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DKVReplicationServer is the server API for DKVReplication service.
+type DKVReplicationServer interface {
+	// GetChanges retrieves all changes from a given change number
+	GetChanges(context.Context, *GetChangesRequest) (*GetChangesResponse, error)
+	// StreamChanges streams all changes from a given change number as and
+	// when they are committed. Every response carries a batch of atmost
+	// MaxNumberOfChanges changes, while responses without any changes are
+	// periodically sent as heartbeats carrying the master change number.
+	StreamChanges(*GetChangesRequest, DKVReplication_StreamChangesServer) error
+	// GetCheckpoint streams a consistent checkpoint of the entire keyspace,
+	// typically used for bootstrapping slaves whose required changes are
+	// no longer available. The first response carries only the change number
+	// as of which the checkpoint is captured, while the subsequent responses
+	// carry the key value pairs.
+	GetCheckpoint(*GetCheckpointRequest, DKVReplication_GetCheckpointServer) error
+	// VerifyRange computes a rolling checksum over the transactions of all
+	// the changes within a given range of change numbers. Nodes holding the
+	// same changes compute the same checksum for the same range.
+	VerifyRange(context.Context, *VerifyRangeRequest) (*VerifyRangeResponse, error)
+	// KeyspaceDigest computes digests over consecutive ranges of keys, as of a
+	// snapshot of the keyspace, so that the keyspaces of nodes can be compared
+	// range by range without transferring their keys. Nodes holding the same
+	// keys compute the same digests for the same ranges.
+	KeyspaceDigest(context.Context, *KeyspaceDigestRequest) (*KeyspaceDigestResponse, error)
+	// GetChangeLogInfo retrieves the range of changes retained by the master node for
+	// GetChanges, along with the progress of the replicas consuming these changes.
+	GetChangeLogInfo(context.Context, *GetChangeLogInfoRequest) (*GetChangeLogInfoResponse, error)
+	// TruncateChangeLog discards the changes retained by the master node before the given
+	// change number, or as per its retention policy if none is given. Replicas requesting
+	// the discarded changes fail with the ChangesTruncated code.
+	TruncateChangeLog(context.Context, *TruncateChangeLogRequest) (*TruncateChangeLogResponse, error)
+	// ListReplicas lists the replicas that retrieved changes from the master node within
+	// the last day, along with their replication lag from the perspective of the master node.
+	ListReplicas(context.Context, *ListReplicasRequest) (*ListReplicasResponse, error)
+}
+
+// UnimplementedDKVReplicationServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVReplicationServer struct {
+}
+
+func (*UnimplementedDKVReplicationServer) GetChanges(ctx context.Context, req *GetChangesRequest) (*GetChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChanges not implemented")
+}
+func (*UnimplementedDKVReplicationServer) StreamChanges(req *GetChangesRequest, srv DKVReplication_StreamChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamChanges not implemented")
+}
+func (*UnimplementedDKVReplicationServer) GetCheckpoint(req *GetCheckpointRequest, srv DKVReplication_GetCheckpointServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetCheckpoint not implemented")
+}
+func (*UnimplementedDKVReplicationServer) VerifyRange(ctx context.Context, req *VerifyRangeRequest) (*VerifyRangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyRange not implemented")
+}
+func (*UnimplementedDKVReplicationServer) KeyspaceDigest(ctx context.Context, req *KeyspaceDigestRequest) (*KeyspaceDigestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KeyspaceDigest not implemented")
+}
+func (*UnimplementedDKVReplicationServer) GetChangeLogInfo(ctx context.Context, req *GetChangeLogInfoRequest) (*GetChangeLogInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChangeLogInfo not implemented")
+}
+func (*UnimplementedDKVReplicationServer) TruncateChangeLog(ctx context.Context, req *TruncateChangeLogRequest) (*TruncateChangeLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TruncateChangeLog not implemented")
+}
+func (*UnimplementedDKVReplicationServer) ListReplicas(ctx context.Context, req *ListReplicasRequest) (*ListReplicasResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReplicas not implemented")
+}
+
+func RegisterDKVReplicationServer(s *grpc.Server, srv DKVReplicationServer) {
+	s.RegisterService(&_DKVReplication_serviceDesc, srv)
+}
+
+func _DKVReplication_GetChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVReplicationServer).GetChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVReplication/GetChanges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVReplicationServer).GetChanges(ctx, req.(*GetChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVReplication_StreamChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DKVReplicationServer).StreamChanges(m, &dKVReplicationStreamChangesServer{stream})
+}
+
+type DKVReplication_StreamChangesServer interface {
+	Send(*GetChangesResponse) error
+	grpc.ServerStream
+}
+
+type dKVReplicationStreamChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *dKVReplicationStreamChangesServer) Send(m *GetChangesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DKVReplication_GetCheckpoint_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetCheckpointRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DKVReplicationServer).GetCheckpoint(m, &dKVReplicationGetCheckpointServer{stream})
+}
+
+type DKVReplication_GetCheckpointServer interface {
+	Send(*GetCheckpointResponse) error
+	grpc.ServerStream
+}
+
+type dKVReplicationGetCheckpointServer struct {
+	grpc.ServerStream
+}
+
+func (x *dKVReplicationGetCheckpointServer) Send(m *GetCheckpointResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DKVReplication_VerifyRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVReplicationServer).VerifyRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVReplication/VerifyRange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVReplicationServer).VerifyRange(ctx, req.(*VerifyRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVReplication_KeyspaceDigest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyspaceDigestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVReplicationServer).KeyspaceDigest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVReplication/KeyspaceDigest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVReplicationServer).KeyspaceDigest(ctx, req.(*KeyspaceDigestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVReplication_GetChangeLogInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChangeLogInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVReplicationServer).GetChangeLogInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVReplication/GetChangeLogInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVReplicationServer).GetChangeLogInfo(ctx, req.(*GetChangeLogInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVReplication_TruncateChangeLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TruncateChangeLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVReplicationServer).TruncateChangeLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVReplication/TruncateChangeLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVReplicationServer).TruncateChangeLog(ctx, req.(*TruncateChangeLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVReplication_ListReplicas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReplicasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVReplicationServer).ListReplicas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVReplication/ListReplicas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVReplicationServer).ListReplicas(ctx, req.(*ListReplicasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DKVReplication_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVReplication",
+	HandlerType: (*DKVReplicationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetChanges",
+			Handler:    _DKVReplication_GetChanges_Handler,
+		},
+		{
+			MethodName: "VerifyRange",
+			Handler:    _DKVReplication_VerifyRange_Handler,
+		},
+		{
+			MethodName: "KeyspaceDigest",
+			Handler:    _DKVReplication_KeyspaceDigest_Handler,
+		},
+		{
+			MethodName: "GetChangeLogInfo",
+			Handler:    _DKVReplication_GetChangeLogInfo_Handler,
+		},
+		{
+			MethodName: "TruncateChangeLog",
+			Handler:    _DKVReplication_TruncateChangeLog_Handler,
+		},
+		{
+			MethodName: "ListReplicas",
+			Handler:    _DKVReplication_ListReplicas_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamChanges",
+			Handler:       _DKVReplication_StreamChanges_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetCheckpoint",
+			Handler:       _DKVReplication_GetCheckpoint_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/serverpb/api.proto",
+}
+
+// DKVReplicationStatusClient is the client API for DKVReplicationStatus service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DKVReplicationStatusClient interface {
+	// GetStatus retrieves the status of replication on a slave node
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+}
+
+type dKVReplicationStatusClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDKVReplicationStatusClient(cc grpc.ClientConnInterface) DKVReplicationStatusClient {
+	return &dKVReplicationStatusClient{cc}
+}
+
+func (c *dKVReplicationStatusClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVReplicationStatus/GetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DKVReplicationStatusServer is the server API for DKVReplicationStatus service.
+type DKVReplicationStatusServer interface {
+	// GetStatus retrieves the status of replication on a slave node
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
+}
+
+// UnimplementedDKVReplicationStatusServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVReplicationStatusServer struct {
+}
+
+func (*UnimplementedDKVReplicationStatusServer) GetStatus(ctx context.Context, req *GetStatusRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+
+func RegisterDKVReplicationStatusServer(s *grpc.Server, srv DKVReplicationStatusServer) {
+	s.RegisterService(&_DKVReplicationStatus_serviceDesc, srv)
+}
+
+func _DKVReplicationStatus_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVReplicationStatusServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVReplicationStatus/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVReplicationStatusServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DKVReplicationStatus_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVReplicationStatus",
+	HandlerType: (*DKVReplicationStatusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _DKVReplicationStatus_GetStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/serverpb/api.proto",
+}
+
+// DKVReplicationControlClient is the client API for DKVReplicationControl service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DKVReplicationControlClient interface {
+	// PauseReplication pauses the application of changes from the master
+	// node onto a slave node. The pause takes effect only at a batch boundary.
+	PauseReplication(ctx context.Context, in *PauseReplicationRequest, opts ...grpc.CallOption) (*Status, error)
+	// ResumeReplication resumes the application of changes from the
+	// master node onto a slave node that is paused.
+	ResumeReplication(ctx context.Context, in *ResumeReplicationRequest, opts ...grpc.CallOption) (*Status, error)
+}
+
+type dKVReplicationControlClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDKVReplicationControlClient(cc grpc.ClientConnInterface) DKVReplicationControlClient {
+	return &dKVReplicationControlClient{cc}
+}
+
+func (c *dKVReplicationControlClient) PauseReplication(ctx context.Context, in *PauseReplicationRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVReplicationControl/PauseReplication", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVReplicationControlClient) ResumeReplication(ctx context.Context, in *ResumeReplicationRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVReplicationControl/ResumeReplication", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DKVReplicationControlServer is the server API for DKVReplicationControl service.
+type DKVReplicationControlServer interface {
+	// PauseReplication pauses the application of changes from the master
+	// node onto a slave node. The pause takes effect only at a batch boundary.
+	PauseReplication(context.Context, *PauseReplicationRequest) (*Status, error)
+	// ResumeReplication resumes the application of changes from the
+	// master node onto a slave node that is paused.
+	ResumeReplication(context.Context, *ResumeReplicationRequest) (*Status, error)
+}
+
+// UnimplementedDKVReplicationControlServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVReplicationControlServer struct {
+}
+
+func (*UnimplementedDKVReplicationControlServer) PauseReplication(ctx context.Context, req *PauseReplicationRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseReplication not implemented")
+}
+func (*UnimplementedDKVReplicationControlServer) ResumeReplication(ctx context.Context, req *ResumeReplicationRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeReplication not implemented")
+}
+
+func RegisterDKVReplicationControlServer(s *grpc.Server, srv DKVReplicationControlServer) {
+	s.RegisterService(&_DKVReplicationControl_serviceDesc, srv)
+}
+
+func _DKVReplicationControl_PauseReplication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseReplicationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVReplicationControlServer).PauseReplication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVReplicationControl/PauseReplication",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVReplicationControlServer).PauseReplication(ctx, req.(*PauseReplicationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVReplicationControl_ResumeReplication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeReplicationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVReplicationControlServer).ResumeReplication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVReplicationControl/ResumeReplication",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVReplicationControlServer).ResumeReplication(ctx, req.(*ResumeReplicationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DKVReplicationControl_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVReplicationControl",
+	HandlerType: (*DKVReplicationControlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PauseReplication",
+			Handler:    _DKVReplicationControl_PauseReplication_Handler,
+		},
+		{
+			MethodName: "ResumeReplication",
+			Handler:    _DKVReplicationControl_ResumeReplication_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/serverpb/api.proto",
+}
+
+// DKVFailoverClient is the client API for DKVFailover service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DKVFailoverClient interface {
+	// PromoteToMaster stops the replication on a slave node and
+	// switches it into a writable master backed by the same storage.
+	PromoteToMaster(ctx context.Context, in *PromoteToMasterRequest, opts ...grpc.CallOption) (*PromoteToMasterResponse, error)
+}
+
+type dKVFailoverClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDKVFailoverClient(cc grpc.ClientConnInterface) DKVFailoverClient {
+	return &dKVFailoverClient{cc}
+}
+
+func (c *dKVFailoverClient) PromoteToMaster(ctx context.Context, in *PromoteToMasterRequest, opts ...grpc.CallOption) (*PromoteToMasterResponse, error) {
+	out := new(PromoteToMasterResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVFailover/PromoteToMaster", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DKVFailoverServer is the server API for DKVFailover service.
+type DKVFailoverServer interface {
+	// PromoteToMaster stops the replication on a slave node and
+	// switches it into a writable master backed by the same storage.
+	PromoteToMaster(context.Context, *PromoteToMasterRequest) (*PromoteToMasterResponse, error)
+}
+
+// UnimplementedDKVFailoverServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVFailoverServer struct {
+}
+
+func (*UnimplementedDKVFailoverServer) PromoteToMaster(ctx context.Context, req *PromoteToMasterRequest) (*PromoteToMasterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PromoteToMaster not implemented")
+}
+
+func RegisterDKVFailoverServer(s *grpc.Server, srv DKVFailoverServer) {
+	s.RegisterService(&_DKVFailover_serviceDesc, srv)
+}
+
+func _DKVFailover_PromoteToMaster_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromoteToMasterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVFailoverServer).PromoteToMaster(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVFailover/PromoteToMaster",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVFailoverServer).PromoteToMaster(ctx, req.(*PromoteToMasterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DKVFailover_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVFailover",
+	HandlerType: (*DKVFailoverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PromoteToMaster",
+			Handler:    _DKVFailover_PromoteToMaster_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/serverpb/api.proto",
+}
+
+// DKVBackupRestoreClient is the client API for DKVBackupRestore service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DKVBackupRestoreClient interface {
+	// Backup backs up the entire keyspace into the given location, as a
+	// job that must complete before the response. Fails if another job is
+	// in progress.
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*Status, error)
+	// Restore restores the entire keyspace from an existing backup at the
+	// given location, as a job that must complete before the response.
+	// Fails if another job is in progress.
+	Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*Status, error)
+	// StartBackup begins backing up the entire keyspace into the given
+	// location and returns the ID of this backup job, whose progress can
+	// be tracked using GetBackupStatus. Fails if another job is in progress.
+	StartBackup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error)
+	// StartRestore begins restoring the entire keyspace from an existing
+	// backup at the given location and returns the ID of this restore job,
+	// whose progress can be tracked using GetBackupStatus. Fails if another
+	// job is in progress.
+	StartRestore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*BackupResponse, error)
+	// GetBackupStatus reports the progress of the given backup or restore job.
+	GetBackupStatus(ctx context.Context, in *GetBackupStatusRequest, opts ...grpc.CallOption) (*GetBackupStatusResponse, error)
+	// StreamBackup streams a backup of the entire keyspace to the caller
+	// as a sequence of chunks, which begins with a chunk carrying the
+	// header and ends with the last chunk.
+	StreamBackup(ctx context.Context, in *StreamBackupRequest, opts ...grpc.CallOption) (DKVBackupRestore_StreamBackupClient, error)
+	// StreamRestore restores the entire keyspace from the chunks of a
+	// backup streamed by the caller, as received from StreamBackup. The
+	// keyspace is left untouched unless all the chunks are valid.
+	StreamRestore(ctx context.Context, opts ...grpc.CallOption) (DKVBackupRestore_StreamRestoreClient, error)
+	// BulkLoad writes the entries streamed by the caller directly onto the storage
+	// of a standalone master node, bypassing its change log, and commits a single
+	// change marking the load once the stream ends. Slave nodes replicating from
+	// the master then bootstrap themselves from its checkpoint, since the changes
+	// before this marker are no longer available. Fails unless bulk loads are
+	// enabled on the master node, and while another job is in progress.
+	BulkLoad(ctx context.Context, opts ...grpc.CallOption) (DKVBackupRestore_BulkLoadClient, error)
+}
+
+type dKVBackupRestoreClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDKVBackupRestoreClient(cc grpc.ClientConnInterface) DKVBackupRestoreClient {
+	return &dKVBackupRestoreClient{cc}
+}
+
+func (c *dKVBackupRestoreClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVBackupRestore/Backup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVBackupRestoreClient) Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVBackupRestore/Restore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVBackupRestoreClient) StartBackup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error) {
+	out := new(BackupResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVBackupRestore/StartBackup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVBackupRestoreClient) StartRestore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*BackupResponse, error) {
+	out := new(BackupResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVBackupRestore/StartRestore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVBackupRestoreClient) GetBackupStatus(ctx context.Context, in *GetBackupStatusRequest, opts ...grpc.CallOption) (*GetBackupStatusResponse, error) {
+	out := new(GetBackupStatusResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVBackupRestore/GetBackupStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVBackupRestoreClient) StreamBackup(ctx context.Context, in *StreamBackupRequest, opts ...grpc.CallOption) (DKVBackupRestore_StreamBackupClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DKVBackupRestore_serviceDesc.Streams[0], "/dkv.serverpb.DKVBackupRestore/StreamBackup", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dKVBackupRestoreStreamBackupClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DKVBackupRestore_StreamBackupClient interface {
+	Recv() (*BackupChunk, error)
+	grpc.ClientStream
+}
+
+type dKVBackupRestoreStreamBackupClient struct {
+	grpc.ClientStream
+}
+
+func (x *dKVBackupRestoreStreamBackupClient) Recv() (*BackupChunk, error) {
+	m := new(BackupChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dKVBackupRestoreClient) StreamRestore(ctx context.Context, opts ...grpc.CallOption) (DKVBackupRestore_StreamRestoreClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DKVBackupRestore_serviceDesc.Streams[1], "/dkv.serverpb.DKVBackupRestore/StreamRestore", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dKVBackupRestoreStreamRestoreClient{stream}
+	return x, nil
+}
+
+type DKVBackupRestore_StreamRestoreClient interface {
+	Send(*StreamRestoreRequest) error
+	CloseAndRecv() (*Status, error)
+	grpc.ClientStream
+}
+
+type dKVBackupRestoreStreamRestoreClient struct {
+	grpc.ClientStream
+}
+
+func (x *dKVBackupRestoreStreamRestoreClient) Send(m *StreamRestoreRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dKVBackupRestoreStreamRestoreClient) CloseAndRecv() (*Status, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Status)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dKVBackupRestoreClient) BulkLoad(ctx context.Context, opts ...grpc.CallOption) (DKVBackupRestore_BulkLoadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DKVBackupRestore_serviceDesc.Streams[2], "/dkv.serverpb.DKVBackupRestore/BulkLoad", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dKVBackupRestoreBulkLoadClient{stream}
+	return x, nil
+}
+
+type DKVBackupRestore_BulkLoadClient interface {
+	Send(*BulkLoadRequest) error
+	CloseAndRecv() (*BulkLoadResponse, error)
+	grpc.ClientStream
+}
+
+type dKVBackupRestoreBulkLoadClient struct {
+	grpc.ClientStream
+}
+
+func (x *dKVBackupRestoreBulkLoadClient) Send(m *BulkLoadRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dKVBackupRestoreBulkLoadClient) CloseAndRecv() (*BulkLoadResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BulkLoadResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DKVBackupRestoreServer is the server API for DKVBackupRestore service.
+type DKVBackupRestoreServer interface {
+	// Backup backs up the entire keyspace into the given location, as a
+	// job that must complete before the response. Fails if another job is
+	// in progress.
+	Backup(context.Context, *BackupRequest) (*Status, error)
+	// Restore restores the entire keyspace from an existing backup at the
+	// given location, as a job that must complete before the response.
+	// Fails if another job is in progress.
+	Restore(context.Context, *RestoreRequest) (*Status, error)
+	// StartBackup begins backing up the entire keyspace into the given
+	// location and returns the ID of this backup job, whose progress can
+	// be tracked using GetBackupStatus. Fails if another job is in progress.
+	StartBackup(context.Context, *BackupRequest) (*BackupResponse, error)
+	// StartRestore begins restoring the entire keyspace from an existing
+	// backup at the given location and returns the ID of this restore job,
+	// whose progress can be tracked using GetBackupStatus. Fails if another
+	// job is in progress.
+	StartRestore(context.Context, *RestoreRequest) (*BackupResponse, error)
+	// GetBackupStatus reports the progress of the given backup or restore job.
+	GetBackupStatus(context.Context, *GetBackupStatusRequest) (*GetBackupStatusResponse, error)
+	// StreamBackup streams a backup of the entire keyspace to the caller
+	// as a sequence of chunks, which begins with a chunk carrying the
+	// header and ends with the last chunk.
+	StreamBackup(*StreamBackupRequest, DKVBackupRestore_StreamBackupServer) error
+	// StreamRestore restores the entire keyspace from the chunks of a
+	// backup streamed by the caller, as received from StreamBackup. The
+	// keyspace is left untouched unless all the chunks are valid.
+	StreamRestore(DKVBackupRestore_StreamRestoreServer) error
+	// BulkLoad writes the entries streamed by the caller directly onto the storage
+	// of a standalone master node, bypassing its change log, and commits a single
+	// change marking the load once the stream ends. Slave nodes replicating from
+	// the master then bootstrap themselves from its checkpoint, since the changes
+	// before this marker are no longer available. Fails unless bulk loads are
+	// enabled on the master node, and while another job is in progress.
+	BulkLoad(DKVBackupRestore_BulkLoadServer) error
+}
+
+// UnimplementedDKVBackupRestoreServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVBackupRestoreServer struct {
+}
+
+func (*UnimplementedDKVBackupRestoreServer) Backup(ctx context.Context, req *BackupRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+func (*UnimplementedDKVBackupRestoreServer) Restore(ctx context.Context, req *RestoreRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (*UnimplementedDKVBackupRestoreServer) StartBackup(ctx context.Context, req *BackupRequest) (*BackupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartBackup not implemented")
+}
+func (*UnimplementedDKVBackupRestoreServer) StartRestore(ctx context.Context, req *RestoreRequest) (*BackupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartRestore not implemented")
+}
+func (*UnimplementedDKVBackupRestoreServer) GetBackupStatus(ctx context.Context, req *GetBackupStatusRequest) (*GetBackupStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBackupStatus not implemented")
+}
+func (*UnimplementedDKVBackupRestoreServer) StreamBackup(req *StreamBackupRequest, srv DKVBackupRestore_StreamBackupServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBackup not implemented")
+}
+func (*UnimplementedDKVBackupRestoreServer) StreamRestore(srv DKVBackupRestore_StreamRestoreServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRestore not implemented")
+}
+func (*UnimplementedDKVBackupRestoreServer) BulkLoad(srv DKVBackupRestore_BulkLoadServer) error {
+	return status.Errorf(codes.Unimplemented, "method BulkLoad not implemented")
+}
+
+func RegisterDKVBackupRestoreServer(s *grpc.Server, srv DKVBackupRestoreServer) {
+	s.RegisterService(&_DKVBackupRestore_serviceDesc, srv)
+}
+
+func _DKVBackupRestore_Backup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVBackupRestoreServer).Backup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVBackupRestore/Backup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVBackupRestoreServer).Backup(ctx, req.(*BackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVBackupRestore_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVBackupRestoreServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVBackupRestore/Restore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVBackupRestoreServer).Restore(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVBackupRestore_StartBackup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVBackupRestoreServer).StartBackup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVBackupRestore/StartBackup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVBackupRestoreServer).StartBackup(ctx, req.(*BackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVBackupRestore_StartRestore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVBackupRestoreServer).StartRestore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVBackupRestore/StartRestore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVBackupRestoreServer).StartRestore(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVBackupRestore_GetBackupStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBackupStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVBackupRestoreServer).GetBackupStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVBackupRestore/GetBackupStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVBackupRestoreServer).GetBackupStatus(ctx, req.(*GetBackupStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVBackupRestore_StreamBackup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamBackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DKVBackupRestoreServer).StreamBackup(m, &dKVBackupRestoreStreamBackupServer{stream})
+}
+
+type DKVBackupRestore_StreamBackupServer interface {
+	Send(*BackupChunk) error
+	grpc.ServerStream
+}
+
+type dKVBackupRestoreStreamBackupServer struct {
+	grpc.ServerStream
+}
+
+func (x *dKVBackupRestoreStreamBackupServer) Send(m *BackupChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DKVBackupRestore_StreamRestore_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DKVBackupRestoreServer).StreamRestore(&dKVBackupRestoreStreamRestoreServer{stream})
+}
+
+type DKVBackupRestore_StreamRestoreServer interface {
+	SendAndClose(*Status) error
+	Recv() (*StreamRestoreRequest, error)
+	grpc.ServerStream
+}
+
+type dKVBackupRestoreStreamRestoreServer struct {
+	grpc.ServerStream
+}
+
+func (x *dKVBackupRestoreStreamRestoreServer) SendAndClose(m *Status) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dKVBackupRestoreStreamRestoreServer) Recv() (*StreamRestoreRequest, error) {
+	m := new(StreamRestoreRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _DKVBackupRestore_BulkLoad_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DKVBackupRestoreServer).BulkLoad(&dKVBackupRestoreBulkLoadServer{stream})
+}
+
+type DKVBackupRestore_BulkLoadServer interface {
+	SendAndClose(*BulkLoadResponse) error
+	Recv() (*BulkLoadRequest, error)
+	grpc.ServerStream
+}
+
+type dKVBackupRestoreBulkLoadServer struct {
+	grpc.ServerStream
+}
+
+func (x *dKVBackupRestoreBulkLoadServer) SendAndClose(m *BulkLoadResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dKVBackupRestoreBulkLoadServer) Recv() (*BulkLoadRequest, error) {
+	m := new(BulkLoadRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _DKVBackupRestore_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVBackupRestore",
+	HandlerType: (*DKVBackupRestoreServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Backup",
+			Handler:    _DKVBackupRestore_Backup_Handler,
+		},
+		{
+			MethodName: "Restore",
+			Handler:    _DKVBackupRestore_Restore_Handler,
+		},
+		{
+			MethodName: "StartBackup",
+			Handler:    _DKVBackupRestore_StartBackup_Handler,
+		},
+		{
+			MethodName: "StartRestore",
+			Handler:    _DKVBackupRestore_StartRestore_Handler,
+		},
+		{
+			MethodName: "GetBackupStatus",
+			Handler:    _DKVBackupRestore_GetBackupStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamBackup",
+			Handler:       _DKVBackupRestore_StreamBackup_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamRestore",
+			Handler:       _DKVBackupRestore_StreamRestore_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "BulkLoad",
+			Handler:       _DKVBackupRestore_BulkLoad_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pkg/serverpb/api.proto",
+}
+
+// DKVClusterClient is the client API for DKVCluster service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DKVClusterClient interface {
+	// AddNode adds the given DKV node to the cluster that the
+	// current node is a member of.
+	AddNode(ctx context.Context, in *AddNodeRequest, opts ...grpc.CallOption) (*Status, error)
+	// RemoveNode removes the given DKV node from the cluster that
+	// the current node is a member of.
+	RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*Status, error)
+	// ListNodes lists the members of the cluster that the current
+	// node is a member of, along with their roles.
+	ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesResponse, error)
+	// DecommissionNode starts removing the given DKV node from the cluster
+	// once the leadership is transferred away from it, if needed, and the
+	// remaining members have caught up with its changes. Unlike RemoveNode,
+	// it returns once the decommission starts.
+	DecommissionNode(ctx context.Context, in *DecommissionNodeRequest, opts ...grpc.CallOption) (*Status, error)
+	// GetDecommissionStatus retrieves the progress of the latest
+	// decommission of the given DKV node.
+	GetDecommissionStatus(ctx context.Context, in *GetDecommissionStatusRequest, opts ...grpc.CallOption) (*GetDecommissionStatusResponse, error)
+	// ClusterBackup backs up every member of the cluster as of a common
+	// change number, while the writes onto the cluster are fenced, and
+	// returns the manifest of these backups. It must be invoked on the
+	// leader of the cluster and must complete within the deadline of the
+	// call, if any. On any failure, the backups written so far are removed.
+	ClusterBackup(ctx context.Context, in *ClusterBackupRequest, opts ...grpc.CallOption) (*ClusterBackupResponse, error)
+	// ClusterRestore restores every member of a fresh cluster from the
+	// backup of the member with the same ID, as per the manifest written
+	// by ClusterBackup. It must be invoked on the leader of the cluster.
+	ClusterRestore(ctx context.Context, in *ClusterRestoreRequest, opts ...grpc.CallOption) (*Status, error)
+	// FenceWrites rejects the writes onto the current node until
+	// UnfenceWrites is invoked for the same fence, or its TTL elapses,
+	// and returns the latest committed change number of the node. It is
+	// invoked by the node coordinating ClusterBackup and ClusterRestore.
+	FenceWrites(ctx context.Context, in *FenceWritesRequest, opts ...grpc.CallOption) (*FenceWritesResponse, error)
+	// UnfenceWrites lifts the given fence from the current node. It is
+	// invoked by the node coordinating ClusterBackup and ClusterRestore.
+	UnfenceWrites(ctx context.Context, in *UnfenceWritesRequest, opts ...grpc.CallOption) (*Status, error)
+	// BackupMember backs up the current node once it reaches the given
+	// change number, while the given fence is raised. It is invoked by
+	// the node coordinating ClusterBackup.
+	BackupMember(ctx context.Context, in *BackupMemberRequest, opts ...grpc.CallOption) (*BackupMemberResponse, error)
+	// RestoreMember restores the current node from the given backup,
+	// while the given fence is raised. It is invoked by the node
+	// coordinating ClusterRestore.
+	RestoreMember(ctx context.Context, in *RestoreMemberRequest, opts ...grpc.CallOption) (*Status, error)
+}
+
+type dKVClusterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDKVClusterClient(cc grpc.ClientConnInterface) DKVClusterClient {
+	return &dKVClusterClient{cc}
+}
+
+func (c *dKVClusterClient) AddNode(ctx context.Context, in *AddNodeRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/AddNode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClusterClient) RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/RemoveNode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClusterClient) ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesResponse, error) {
+	out := new(ListNodesResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/ListNodes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClusterClient) DecommissionNode(ctx context.Context, in *DecommissionNodeRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/DecommissionNode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClusterClient) GetDecommissionStatus(ctx context.Context, in *GetDecommissionStatusRequest, opts ...grpc.CallOption) (*GetDecommissionStatusResponse, error) {
+	out := new(GetDecommissionStatusResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/GetDecommissionStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClusterClient) ClusterBackup(ctx context.Context, in *ClusterBackupRequest, opts ...grpc.CallOption) (*ClusterBackupResponse, error) {
+	out := new(ClusterBackupResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/ClusterBackup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClusterClient) ClusterRestore(ctx context.Context, in *ClusterRestoreRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/ClusterRestore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClusterClient) FenceWrites(ctx context.Context, in *FenceWritesRequest, opts ...grpc.CallOption) (*FenceWritesResponse, error) {
+	out := new(FenceWritesResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/FenceWrites", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClusterClient) UnfenceWrites(ctx context.Context, in *UnfenceWritesRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/UnfenceWrites", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClusterClient) BackupMember(ctx context.Context, in *BackupMemberRequest, opts ...grpc.CallOption) (*BackupMemberResponse, error) {
+	out := new(BackupMemberResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/BackupMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVClusterClient) RestoreMember(ctx context.Context, in *RestoreMemberRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/RestoreMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DKVClusterServer is the server API for DKVCluster service.
+type DKVClusterServer interface {
+	// AddNode adds the given DKV node to the cluster that the
+	// current node is a member of.
+	AddNode(context.Context, *AddNodeRequest) (*Status, error)
+	// RemoveNode removes the given DKV node from the cluster that
+	// the current node is a member of.
+	RemoveNode(context.Context, *RemoveNodeRequest) (*Status, error)
+	// ListNodes lists the members of the cluster that the current
+	// node is a member of, along with their roles.
+	ListNodes(context.Context, *ListNodesRequest) (*ListNodesResponse, error)
+	// DecommissionNode starts removing the given DKV node from the cluster
+	// once the leadership is transferred away from it, if needed, and the
+	// remaining members have caught up with its changes. Unlike RemoveNode,
+	// it returns once the decommission starts.
+	DecommissionNode(context.Context, *DecommissionNodeRequest) (*Status, error)
+	// GetDecommissionStatus retrieves the progress of the latest
+	// decommission of the given DKV node.
+	GetDecommissionStatus(context.Context, *GetDecommissionStatusRequest) (*GetDecommissionStatusResponse, error)
+	// ClusterBackup backs up every member of the cluster as of a common
+	// change number, while the writes onto the cluster are fenced, and
+	// returns the manifest of these backups. It must be invoked on the
+	// leader of the cluster and must complete within the deadline of the
+	// call, if any. On any failure, the backups written so far are removed.
+	ClusterBackup(context.Context, *ClusterBackupRequest) (*ClusterBackupResponse, error)
+	// ClusterRestore restores every member of a fresh cluster from the
+	// backup of the member with the same ID, as per the manifest written
+	// by ClusterBackup. It must be invoked on the leader of the cluster.
+	ClusterRestore(context.Context, *ClusterRestoreRequest) (*Status, error)
+	// FenceWrites rejects the writes onto the current node until
+	// UnfenceWrites is invoked for the same fence, or its TTL elapses,
+	// and returns the latest committed change number of the node. It is
+	// invoked by the node coordinating ClusterBackup and ClusterRestore.
+	FenceWrites(context.Context, *FenceWritesRequest) (*FenceWritesResponse, error)
+	// UnfenceWrites lifts the given fence from the current node. It is
+	// invoked by the node coordinating ClusterBackup and ClusterRestore.
+	UnfenceWrites(context.Context, *UnfenceWritesRequest) (*Status, error)
+	// BackupMember backs up the current node once it reaches the given
+	// change number, while the given fence is raised. It is invoked by
+	// the node coordinating ClusterBackup.
+	BackupMember(context.Context, *BackupMemberRequest) (*BackupMemberResponse, error)
+	// RestoreMember restores the current node from the given backup,
+	// while the given fence is raised. It is invoked by the node
+	// coordinating ClusterRestore.
+	RestoreMember(context.Context, *RestoreMemberRequest) (*Status, error)
+}
+
+// UnimplementedDKVClusterServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVClusterServer struct {
+}
+
+func (*UnimplementedDKVClusterServer) AddNode(ctx context.Context, req *AddNodeRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddNode not implemented")
+}
+func (*UnimplementedDKVClusterServer) RemoveNode(ctx context.Context, req *RemoveNodeRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveNode not implemented")
+}
+func (*UnimplementedDKVClusterServer) ListNodes(ctx context.Context, req *ListNodesRequest) (*ListNodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNodes not implemented")
+}
+func (*UnimplementedDKVClusterServer) DecommissionNode(ctx context.Context, req *DecommissionNodeRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecommissionNode not implemented")
+}
+func (*UnimplementedDKVClusterServer) GetDecommissionStatus(ctx context.Context, req *GetDecommissionStatusRequest) (*GetDecommissionStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDecommissionStatus not implemented")
+}
+func (*UnimplementedDKVClusterServer) ClusterBackup(ctx context.Context, req *ClusterBackupRequest) (*ClusterBackupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClusterBackup not implemented")
+}
+func (*UnimplementedDKVClusterServer) ClusterRestore(ctx context.Context, req *ClusterRestoreRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClusterRestore not implemented")
+}
+func (*UnimplementedDKVClusterServer) FenceWrites(ctx context.Context, req *FenceWritesRequest) (*FenceWritesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FenceWrites not implemented")
+}
+func (*UnimplementedDKVClusterServer) UnfenceWrites(ctx context.Context, req *UnfenceWritesRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnfenceWrites not implemented")
+}
+func (*UnimplementedDKVClusterServer) BackupMember(ctx context.Context, req *BackupMemberRequest) (*BackupMemberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BackupMember not implemented")
+}
+func (*UnimplementedDKVClusterServer) RestoreMember(ctx context.Context, req *RestoreMemberRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreMember not implemented")
+}
+
+func RegisterDKVClusterServer(s *grpc.Server, srv DKVClusterServer) {
+	s.RegisterService(&_DKVCluster_serviceDesc, srv)
+}
+
+func _DKVCluster_AddNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).AddNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/AddNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).AddNode(ctx, req.(*AddNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVCluster_RemoveNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).RemoveNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/RemoveNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).RemoveNode(ctx, req.(*RemoveNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVCluster_ListNodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).ListNodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/ListNodes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).ListNodes(ctx, req.(*ListNodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVCluster_DecommissionNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecommissionNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).DecommissionNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/DecommissionNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).DecommissionNode(ctx, req.(*DecommissionNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVCluster_GetDecommissionStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDecommissionStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).GetDecommissionStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/GetDecommissionStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).GetDecommissionStatus(ctx, req.(*GetDecommissionStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVCluster_ClusterBackup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClusterBackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).ClusterBackup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/ClusterBackup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).ClusterBackup(ctx, req.(*ClusterBackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVCluster_ClusterRestore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClusterRestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).ClusterRestore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/ClusterRestore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).ClusterRestore(ctx, req.(*ClusterRestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVCluster_FenceWrites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FenceWritesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).FenceWrites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/FenceWrites",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).FenceWrites(ctx, req.(*FenceWritesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVCluster_UnfenceWrites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnfenceWritesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).UnfenceWrites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/UnfenceWrites",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).UnfenceWrites(ctx, req.(*UnfenceWritesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVCluster_BackupMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).BackupMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/BackupMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).BackupMember(ctx, req.(*BackupMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVCluster_RestoreMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVClusterServer).RestoreMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVCluster/RestoreMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVClusterServer).RestoreMember(ctx, req.(*RestoreMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DKVCluster_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVCluster",
+	HandlerType: (*DKVClusterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddNode",
+			Handler:    _DKVCluster_AddNode_Handler,
+		},
+		{
+			MethodName: "RemoveNode",
+			Handler:    _DKVCluster_RemoveNode_Handler,
+		},
+		{
+			MethodName: "ListNodes",
+			Handler:    _DKVCluster_ListNodes_Handler,
+		},
+		{
+			MethodName: "DecommissionNode",
+			Handler:    _DKVCluster_DecommissionNode_Handler,
+		},
+		{
+			MethodName: "GetDecommissionStatus",
+			Handler:    _DKVCluster_GetDecommissionStatus_Handler,
+		},
+		{
+			MethodName: "ClusterBackup",
+			Handler:    _DKVCluster_ClusterBackup_Handler,
+		},
+		{
+			MethodName: "ClusterRestore",
+			Handler:    _DKVCluster_ClusterRestore_Handler,
+		},
+		{
+			MethodName: "FenceWrites",
+			Handler:    _DKVCluster_FenceWrites_Handler,
+		},
+		{
+			MethodName: "UnfenceWrites",
+			Handler:    _DKVCluster_UnfenceWrites_Handler,
+		},
+		{
+			MethodName: "BackupMember",
+			Handler:    _DKVCluster_BackupMember_Handler,
+		},
+		{
+			MethodName: "RestoreMember",
+			Handler:    _DKVCluster_RestoreMember_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/serverpb/api.proto",
+}
+
+// DKVLimitsClient is the client API for DKVLimits service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DKVLimitsClient interface {
+	// GetLimits retrieves the limits on the GRPC calls served by the current node.
+	GetLimits(ctx context.Context, in *GetLimitsRequest, opts ...grpc.CallOption) (*GetLimitsResponse, error)
+	// SetLimits replaces the limits on the GRPC calls served by the current node,
+	// taking effect immediately. These limits are not retained across restarts.
+	SetLimits(ctx context.Context, in *SetLimitsRequest, opts ...grpc.CallOption) (*Status, error)
+}
+
+type dKVLimitsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDKVLimitsClient(cc grpc.ClientConnInterface) DKVLimitsClient {
+	return &dKVLimitsClient{cc}
+}
+
+func (c *dKVLimitsClient) GetLimits(ctx context.Context, in *GetLimitsRequest, opts ...grpc.CallOption) (*GetLimitsResponse, error) {
+	out := new(GetLimitsResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVLimits/GetLimits", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dKVLimitsClient) SetLimits(ctx context.Context, in *SetLimitsRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVLimits/SetLimits", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DKVLimitsServer is the server API for DKVLimits service.
+type DKVLimitsServer interface {
+	// GetLimits retrieves the limits on the GRPC calls served by the current node.
+	GetLimits(context.Context, *GetLimitsRequest) (*GetLimitsResponse, error)
+	// SetLimits replaces the limits on the GRPC calls served by the current node,
+	// taking effect immediately. These limits are not retained across restarts.
+	SetLimits(context.Context, *SetLimitsRequest) (*Status, error)
+}
+
+// UnimplementedDKVLimitsServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVLimitsServer struct {
+}
+
+func (*UnimplementedDKVLimitsServer) GetLimits(ctx context.Context, req *GetLimitsRequest) (*GetLimitsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLimits not implemented")
+}
+func (*UnimplementedDKVLimitsServer) SetLimits(ctx context.Context, req *SetLimitsRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLimits not implemented")
+}
+
+func RegisterDKVLimitsServer(s *grpc.Server, srv DKVLimitsServer) {
+	s.RegisterService(&_DKVLimits_serviceDesc, srv)
+}
+
+func _DKVLimits_GetLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVLimitsServer).GetLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVLimits/GetLimits",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVLimitsServer).GetLimits(ctx, req.(*GetLimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVLimits_SetLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVLimitsServer).SetLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVLimits/SetLimits",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVLimitsServer).SetLimits(ctx, req.(*SetLimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DKVLimits_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVLimits",
+	HandlerType: (*DKVLimitsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLimits",
+			Handler:    _DKVLimits_GetLimits_Handler,
+		},
+		{
+			MethodName: "SetLimits",
+			Handler:    _DKVLimits_SetLimits_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/serverpb/api.proto",
+}
+
+// DKVStorageClient is the client API for DKVStorage service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DKVStorageClient interface {
+	// GetStorageStats retrieves the statistics of the storage engine of the current
+	// node. Fails with the UNIMPLEMENTED code for engines that report no statistics.
+	GetStorageStats(ctx context.Context, in *GetStorageStatsRequest, opts ...grpc.CallOption) (*GetStorageStatsResponse, error)
+	// CompactRange starts compacting the given range of keys of the storage engine
+	// of the current node in the background, responding at once. Its progress is
+	// reported by GetStorageStats. Fails with the CompactionInProgress code while
+	// another compaction is running, and with the UNIMPLEMENTED code for engines
+	// that can not be compacted manually.
+	CompactRange(ctx context.Context, in *CompactRangeRequest, opts ...grpc.CallOption) (*Status, error)
+	// Flush persists the writes buffered in the memory of the storage engine of the
+	// current node onto its files. Fails with the UNIMPLEMENTED code for engines
+	// that can not be flushed manually.
+	Flush(ctx context.Context, in *FlushRequest, opts ...grpc.CallOption) (*Status, error)
+	// PrefixStats reports the number of keys with the given prefix on the current node,
+	// along with the number of bytes these occupy. Unless exact, these are estimated from
+	// the metadata of the storage engine within a bounded time, failing with the
+	// UNIMPLEMENTED code for engines that can not estimate them. Exact stats are computed
+	// by iterating over the keys at a limited rate, until the deadline of the call.
+	PrefixStats(ctx context.Context, in *PrefixStatsRequest, opts ...grpc.CallOption) (*PrefixStatsResponse, error)
+	// SetQuota limits the keys and bytes of a namespace, beyond which the mutations
+	// of the namespace are rejected with the QuotaExceeded code. The quota is retained
+	// across restarts and replicated onto the slave nodes. Fails with the UNIMPLEMENTED
+	// code on the nodes that do not enforce quotas.
+	SetQuota(ctx context.Context, in *SetQuotaRequest, opts ...grpc.CallOption) (*Status, error)
+	// ListQuotas retrieves the quotas of all the namespaces, along with their usage.
+	// Fails with the UNIMPLEMENTED code on the nodes that do not enforce quotas.
+	ListQuotas(ctx context.Context, in *ListQuotasRequest, opts ...grpc.CallOption) (*ListQuotasResponse, error)
+}
+
+type dKVStorageClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDKVStorageClient(cc grpc.ClientConnInterface) DKVStorageClient {
+	return &dKVStorageClient{cc}
+}
+
+func (c *dKVStorageClient) GetStorageStats(ctx context.Context, in *GetStorageStatsRequest, opts ...grpc.CallOption) (*GetStorageStatsResponse, error) {
+	out := new(GetStorageStatsResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVStorage/GetStorageStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type dKVClient struct {
-	cc *grpc.ClientConn
+func (c *dKVStorageClient) CompactRange(ctx context.Context, in *CompactRangeRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVStorage/CompactRange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func NewDKVClient(cc *grpc.ClientConn) DKVClient {
-	return &dKVClient{cc}
+func (c *dKVStorageClient) Flush(ctx context.Context, in *FlushRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVStorage/Flush", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (c *dKVClient) Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error) {
-	out := new(PutResponse)
-	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/Put", in, out, opts...)
+func (c *dKVStorageClient) PrefixStats(ctx context.Context, in *PrefixStatsRequest, opts ...grpc.CallOption) (*PrefixStatsResponse, error) {
+	out := new(PrefixStatsResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVStorage/PrefixStats", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *dKVClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
-	out := new(GetResponse)
-	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/Get", in, out, opts...)
+func (c *dKVStorageClient) SetQuota(ctx context.Context, in *SetQuotaRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVStorage/SetQuota", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *dKVClient) MultiGet(ctx context.Context, in *MultiGetRequest, opts ...grpc.CallOption) (*MultiGetResponse, error) {
-	out := new(MultiGetResponse)
-	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKV/MultiGet", in, out, opts...)
+func (c *dKVStorageClient) ListQuotas(ctx context.Context, in *ListQuotasRequest, opts ...grpc.CallOption) (*ListQuotasResponse, error) {
+	out := new(ListQuotasResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVStorage/ListQuotas", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// DKVServer is the server API for DKV service.
-type DKVServer interface {
-	// Put puts the given key into the key value store
-	Put(context.Context, *PutRequest) (*PutResponse, error)
-	// Get gets the value associated with the given key from the key value store
-	Get(context.Context, *GetRequest) (*GetResponse, error)
-	// MultiGet gets all the values associated with the given keys from the key value store
-	MultiGet(context.Context, *MultiGetRequest) (*MultiGetResponse, error)
+// DKVStorageServer is the server API for DKVStorage service.
+type DKVStorageServer interface {
+	// GetStorageStats retrieves the statistics of the storage engine of the current
+	// node. Fails with the UNIMPLEMENTED code for engines that report no statistics.
+	GetStorageStats(context.Context, *GetStorageStatsRequest) (*GetStorageStatsResponse, error)
+	// CompactRange starts compacting the given range of keys of the storage engine
+	// of the current node in the background, responding at once. Its progress is
+	// reported by GetStorageStats. Fails with the CompactionInProgress code while
+	// another compaction is running, and with the UNIMPLEMENTED code for engines
+	// that can not be compacted manually.
+	CompactRange(context.Context, *CompactRangeRequest) (*Status, error)
+	// Flush persists the writes buffered in the memory of the storage engine of the
+	// current node onto its files. Fails with the UNIMPLEMENTED code for engines
+	// that can not be flushed manually.
+	Flush(context.Context, *FlushRequest) (*Status, error)
+	// PrefixStats reports the number of keys with the given prefix on the current node,
+	// along with the number of bytes these occupy. Unless exact, these are estimated from
+	// the metadata of the storage engine within a bounded time, failing with the
+	// UNIMPLEMENTED code for engines that can not estimate them. Exact stats are computed
+	// by iterating over the keys at a limited rate, until the deadline of the call.
+	PrefixStats(context.Context, *PrefixStatsRequest) (*PrefixStatsResponse, error)
+	// SetQuota limits the keys and bytes of a namespace, beyond which the mutations
+	// of the namespace are rejected with the QuotaExceeded code. The quota is retained
+	// across restarts and replicated onto the slave nodes. Fails with the UNIMPLEMENTED
+	// code on the nodes that do not enforce quotas.
+	SetQuota(context.Context, *SetQuotaRequest) (*Status, error)
+	// ListQuotas retrieves the quotas of all the namespaces, along with their usage.
+	// Fails with the UNIMPLEMENTED code on the nodes that do not enforce quotas.
+	ListQuotas(context.Context, *ListQuotasRequest) (*ListQuotasResponse, error)
 }
 
-// UnimplementedDKVServer can be embedded to have forward compatible implementations.
-type UnimplementedDKVServer struct {
+// UnimplementedDKVStorageServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVStorageServer struct {
 }
 
-func (*UnimplementedDKVServer) Put(ctx context.Context, req *PutRequest) (*PutResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
+func (*UnimplementedDKVStorageServer) GetStorageStats(ctx context.Context, req *GetStorageStatsRequest) (*GetStorageStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStorageStats not implemented")
 }
-func (*UnimplementedDKVServer) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+func (*UnimplementedDKVStorageServer) CompactRange(ctx context.Context, req *CompactRangeRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompactRange not implemented")
 }
-func (*UnimplementedDKVServer) MultiGet(ctx context.Context, req *MultiGetRequest) (*MultiGetResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method MultiGet not implemented")
+func (*UnimplementedDKVStorageServer) Flush(ctx context.Context, req *FlushRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Flush not implemented")
+}
+func (*UnimplementedDKVStorageServer) PrefixStats(ctx context.Context, req *PrefixStatsRequest) (*PrefixStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PrefixStats not implemented")
+}
+func (*UnimplementedDKVStorageServer) SetQuota(ctx context.Context, req *SetQuotaRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetQuota not implemented")
+}
+func (*UnimplementedDKVStorageServer) ListQuotas(ctx context.Context, req *ListQuotasRequest) (*ListQuotasResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListQuotas not implemented")
 }
 
-func RegisterDKVServer(s *grpc.Server, srv DKVServer) {
-	s.RegisterService(&_DKV_serviceDesc, srv)
+func RegisterDKVStorageServer(s *grpc.Server, srv DKVStorageServer) {
+	s.RegisterService(&_DKVStorage_serviceDesc, srv)
 }
 
-func _DKV_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PutRequest)
+func _DKVStorage_GetStorageStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStorageStatsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DKVServer).Put(ctx, in)
+		return srv.(DKVStorageServer).GetStorageStats(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/dkv.serverpb.DKV/Put",
+		FullMethod: "/dkv.serverpb.DKVStorage/GetStorageStats",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DKVServer).Put(ctx, req.(*PutRequest))
+		return srv.(DKVStorageServer).GetStorageStats(ctx, req.(*GetStorageStatsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DKV_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetRequest)
+func _DKVStorage_CompactRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactRangeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DKVServer).Get(ctx, in)
+		return srv.(DKVStorageServer).CompactRange(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/dkv.serverpb.DKV/Get",
+		FullMethod: "/dkv.serverpb.DKVStorage/CompactRange",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DKVServer).Get(ctx, req.(*GetRequest))
+		return srv.(DKVStorageServer).CompactRange(ctx, req.(*CompactRangeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DKV_MultiGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(MultiGetRequest)
+func _DKVStorage_Flush_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlushRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DKVServer).MultiGet(ctx, in)
+		return srv.(DKVStorageServer).Flush(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/dkv.serverpb.DKV/MultiGet",
+		FullMethod: "/dkv.serverpb.DKVStorage/Flush",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DKVServer).MultiGet(ctx, req.(*MultiGetRequest))
+		return srv.(DKVStorageServer).Flush(ctx, req.(*FlushRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _DKV_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "dkv.serverpb.DKV",
-	HandlerType: (*DKVServer)(nil),
+func _DKVStorage_PrefixStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrefixStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVStorageServer).PrefixStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVStorage/PrefixStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVStorageServer).PrefixStats(ctx, req.(*PrefixStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVStorage_SetQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVStorageServer).SetQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVStorage/SetQuota",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVStorageServer).SetQuota(ctx, req.(*SetQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DKVStorage_ListQuotas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListQuotasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DKVStorageServer).ListQuotas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dkv.serverpb.DKVStorage/ListQuotas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DKVStorageServer).ListQuotas(ctx, req.(*ListQuotasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DKVStorage_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVStorage",
+	HandlerType: (*DKVStorageServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Put",
-			Handler:    _DKV_Put_Handler,
+			MethodName: "GetStorageStats",
+			Handler:    _DKVStorage_GetStorageStats_Handler,
 		},
 		{
-			MethodName: "Get",
-			Handler:    _DKV_Get_Handler,
+			MethodName: "CompactRange",
+			Handler:    _DKVStorage_CompactRange_Handler,
 		},
 		{
-			MethodName: "MultiGet",
-			Handler:    _DKV_MultiGet_Handler,
+			MethodName: "Flush",
+			Handler:    _DKVStorage_Flush_Handler,
+		},
+		{
+			MethodName: "PrefixStats",
+			Handler:    _DKVStorage_PrefixStats_Handler,
+		},
+		{
+			MethodName: "SetQuota",
+			Handler:    _DKVStorage_SetQuota_Handler,
+		},
+		{
+			MethodName: "ListQuotas",
+			Handler:    _DKVStorage_ListQuotas_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/serverpb/api.proto",
 }
 
-// DKVReplicationClient is the client API for DKVReplication service.
+// DKVInfoClient is the client API for DKVInfo service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type DKVReplicationClient interface {
-	// GetChanges retrieves all changes from a given change number
-	GetChanges(ctx context.Context, in *GetChangesRequest, opts ...grpc.CallOption) (*GetChangesResponse, error)
+type DKVInfoClient interface {
+	// GetServerInfo describes the current node, like its role, storage engine and
+	// build, so that tools and clients can tell the nodes serving DKV apart.
+	GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error)
 }
 
-type dKVReplicationClient struct {
-	cc *grpc.ClientConn
+type dKVInfoClient struct {
+	cc grpc.ClientConnInterface
 }
 
-func NewDKVReplicationClient(cc *grpc.ClientConn) DKVReplicationClient {
-	return &dKVReplicationClient{cc}
+func NewDKVInfoClient(cc grpc.ClientConnInterface) DKVInfoClient {
+	return &dKVInfoClient{cc}
 }
 
-func (c *dKVReplicationClient) GetChanges(ctx context.Context, in *GetChangesRequest, opts ...grpc.CallOption) (*GetChangesResponse, error) {
-	out := new(GetChangesResponse)
-	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVReplication/GetChanges", in, out, opts...)
+func (c *dKVInfoClient) GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error) {
+	out := new(GetServerInfoResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVInfo/GetServerInfo", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// DKVReplicationServer is the server API for DKVReplication service.
-type DKVReplicationServer interface {
-	// GetChanges retrieves all changes from a given change number
-	GetChanges(context.Context, *GetChangesRequest) (*GetChangesResponse, error)
+// DKVInfoServer is the server API for DKVInfo service.
+type DKVInfoServer interface {
+	// GetServerInfo describes the current node, like its role, storage engine and
+	// build, so that tools and clients can tell the nodes serving DKV apart.
+	GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error)
 }
 
-// UnimplementedDKVReplicationServer can be embedded to have forward compatible implementations.
-type UnimplementedDKVReplicationServer struct {
+// UnimplementedDKVInfoServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVInfoServer struct {
 }
 
-func (*UnimplementedDKVReplicationServer) GetChanges(ctx context.Context, req *GetChangesRequest) (*GetChangesResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetChanges not implemented")
+func (*UnimplementedDKVInfoServer) GetServerInfo(ctx context.Context, req *GetServerInfoRequest) (*GetServerInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerInfo not implemented")
 }
 
-func RegisterDKVReplicationServer(s *grpc.Server, srv DKVReplicationServer) {
-	s.RegisterService(&_DKVReplication_serviceDesc, srv)
+func RegisterDKVInfoServer(s *grpc.Server, srv DKVInfoServer) {
+	s.RegisterService(&_DKVInfo_serviceDesc, srv)
 }
 
-func _DKVReplication_GetChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetChangesRequest)
+func _DKVInfo_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerInfoRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DKVReplicationServer).GetChanges(ctx, in)
+		return srv.(DKVInfoServer).GetServerInfo(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/dkv.serverpb.DKVReplication/GetChanges",
+		FullMethod: "/dkv.serverpb.DKVInfo/GetServerInfo",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DKVReplicationServer).GetChanges(ctx, req.(*GetChangesRequest))
+		return srv.(DKVInfoServer).GetServerInfo(ctx, req.(*GetServerInfoRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _DKVReplication_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "dkv.serverpb.DKVReplication",
-	HandlerType: (*DKVReplicationServer)(nil),
+var _DKVInfo_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVInfo",
+	HandlerType: (*DKVInfoServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "GetChanges",
-			Handler:    _DKVReplication_GetChanges_Handler,
+			MethodName: "GetServerInfo",
+			Handler:    _DKVInfo_GetServerInfo_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/serverpb/api.proto",
 }
 
-// DKVBackupRestoreClient is the client API for DKVBackupRestore service.
+// DKVMaintenanceClient is the client API for DKVMaintenance service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type DKVBackupRestoreClient interface {
-	// Backup backs up the entire keyspace into the given filesystem location.
-	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*Status, error)
-	// Restore restores the entire keyspace from an existing backup at the
-	// given filesystem location.
-	Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*Status, error)
+type DKVMaintenanceClient interface {
+	// EnterMaintenanceMode switches the current node into a read-only state,
+	// in which every keyspace mutation fails with the Maintenance code while
+	// reads are served as usual, and the node is reported as not serving by
+	// the health check. The node remains in maintenance mode across restarts,
+	// until ExitMaintenanceMode is called.
+	EnterMaintenanceMode(ctx context.Context, in *EnterMaintenanceModeRequest, opts ...grpc.CallOption) (*Status, error)
+	// ExitMaintenanceMode lets the current node accept keyspace mutations again.
+	ExitMaintenanceMode(ctx context.Context, in *ExitMaintenanceModeRequest, opts ...grpc.CallOption) (*Status, error)
 }
 
-type dKVBackupRestoreClient struct {
-	cc *grpc.ClientConn
+type dKVMaintenanceClient struct {
+	cc grpc.ClientConnInterface
 }
 
-func NewDKVBackupRestoreClient(cc *grpc.ClientConn) DKVBackupRestoreClient {
-	return &dKVBackupRestoreClient{cc}
+func NewDKVMaintenanceClient(cc grpc.ClientConnInterface) DKVMaintenanceClient {
+	return &dKVMaintenanceClient{cc}
 }
 
-func (c *dKVBackupRestoreClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*Status, error) {
+func (c *dKVMaintenanceClient) EnterMaintenanceMode(ctx context.Context, in *EnterMaintenanceModeRequest, opts ...grpc.CallOption) (*Status, error) {
 	out := new(Status)
-	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVBackupRestore/Backup", in, out, opts...)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVMaintenance/EnterMaintenanceMode", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *dKVBackupRestoreClient) Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*Status, error) {
+func (c *dKVMaintenanceClient) ExitMaintenanceMode(ctx context.Context, in *ExitMaintenanceModeRequest, opts ...grpc.CallOption) (*Status, error) {
 	out := new(Status)
-	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVBackupRestore/Restore", in, out, opts...)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVMaintenance/ExitMaintenanceMode", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// DKVBackupRestoreServer is the server API for DKVBackupRestore service.
-type DKVBackupRestoreServer interface {
-	// Backup backs up the entire keyspace into the given filesystem location.
-	Backup(context.Context, *BackupRequest) (*Status, error)
-	// Restore restores the entire keyspace from an existing backup at the
-	// given filesystem location.
-	Restore(context.Context, *RestoreRequest) (*Status, error)
+// DKVMaintenanceServer is the server API for DKVMaintenance service.
+type DKVMaintenanceServer interface {
+	// EnterMaintenanceMode switches the current node into a read-only state,
+	// in which every keyspace mutation fails with the Maintenance code while
+	// reads are served as usual, and the node is reported as not serving by
+	// the health check. The node remains in maintenance mode across restarts,
+	// until ExitMaintenanceMode is called.
+	EnterMaintenanceMode(context.Context, *EnterMaintenanceModeRequest) (*Status, error)
+	// ExitMaintenanceMode lets the current node accept keyspace mutations again.
+	ExitMaintenanceMode(context.Context, *ExitMaintenanceModeRequest) (*Status, error)
 }
 
-// UnimplementedDKVBackupRestoreServer can be embedded to have forward compatible implementations.
-type UnimplementedDKVBackupRestoreServer struct {
+// UnimplementedDKVMaintenanceServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVMaintenanceServer struct {
 }
 
-func (*UnimplementedDKVBackupRestoreServer) Backup(ctx context.Context, req *BackupRequest) (*Status, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Backup not implemented")
+func (*UnimplementedDKVMaintenanceServer) EnterMaintenanceMode(ctx context.Context, req *EnterMaintenanceModeRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnterMaintenanceMode not implemented")
 }
-func (*UnimplementedDKVBackupRestoreServer) Restore(ctx context.Context, req *RestoreRequest) (*Status, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+func (*UnimplementedDKVMaintenanceServer) ExitMaintenanceMode(ctx context.Context, req *ExitMaintenanceModeRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExitMaintenanceMode not implemented")
 }
 
-func RegisterDKVBackupRestoreServer(s *grpc.Server, srv DKVBackupRestoreServer) {
-	s.RegisterService(&_DKVBackupRestore_serviceDesc, srv)
+func RegisterDKVMaintenanceServer(s *grpc.Server, srv DKVMaintenanceServer) {
+	s.RegisterService(&_DKVMaintenance_serviceDesc, srv)
 }
 
-func _DKVBackupRestore_Backup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BackupRequest)
+func _DKVMaintenance_EnterMaintenanceMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnterMaintenanceModeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DKVBackupRestoreServer).Backup(ctx, in)
+		return srv.(DKVMaintenanceServer).EnterMaintenanceMode(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/dkv.serverpb.DKVBackupRestore/Backup",
+		FullMethod: "/dkv.serverpb.DKVMaintenance/EnterMaintenanceMode",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DKVBackupRestoreServer).Backup(ctx, req.(*BackupRequest))
+		return srv.(DKVMaintenanceServer).EnterMaintenanceMode(ctx, req.(*EnterMaintenanceModeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DKVBackupRestore_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RestoreRequest)
+func _DKVMaintenance_ExitMaintenanceMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExitMaintenanceModeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DKVBackupRestoreServer).Restore(ctx, in)
+		return srv.(DKVMaintenanceServer).ExitMaintenanceMode(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/dkv.serverpb.DKVBackupRestore/Restore",
+		FullMethod: "/dkv.serverpb.DKVMaintenance/ExitMaintenanceMode",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DKVBackupRestoreServer).Restore(ctx, req.(*RestoreRequest))
+		return srv.(DKVMaintenanceServer).ExitMaintenanceMode(ctx, req.(*ExitMaintenanceModeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _DKVBackupRestore_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "dkv.serverpb.DKVBackupRestore",
-	HandlerType: (*DKVBackupRestoreServer)(nil),
+var _DKVMaintenance_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVMaintenance",
+	HandlerType: (*DKVMaintenanceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Backup",
-			Handler:    _DKVBackupRestore_Backup_Handler,
+			MethodName: "EnterMaintenanceMode",
+			Handler:    _DKVMaintenance_EnterMaintenanceMode_Handler,
 		},
 		{
-			MethodName: "Restore",
-			Handler:    _DKVBackupRestore_Restore_Handler,
+			MethodName: "ExitMaintenanceMode",
+			Handler:    _DKVMaintenance_ExitMaintenanceMode_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/serverpb/api.proto",
 }
 
-// DKVClusterClient is the client API for DKVCluster service.
+// DKVSecurityClient is the client API for DKVSecurity service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type DKVClusterClient interface {
-	// AddNode adds the given DKV node to the cluster that the
-	// current node is a member of.
-	AddNode(ctx context.Context, in *AddNodeRequest, opts ...grpc.CallOption) (*Status, error)
-	// RemoveNode removes the given DKV node from the cluster that
-	// the current node is a member of.
-	RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*Status, error)
-}
-
-type dKVClusterClient struct {
-	cc *grpc.ClientConn
+type DKVSecurityClient interface {
+	// ReloadSecurityConfig re-reads the TLS certificate, private key and CA files,
+	// along with the token file, of the current node, so that these can be rotated
+	// without restarting it. The files are validated together, with either all of
+	// them applied or none of them upon any validation error. The TLS files apply to
+	// the connections accepted subsequently, while the existing ones are served as is
+	// until they are closed. The tokens apply to every call made subsequently.
+	ReloadSecurityConfig(ctx context.Context, in *ReloadSecurityConfigRequest, opts ...grpc.CallOption) (*ReloadSecurityConfigResponse, error)
 }
 
-func NewDKVClusterClient(cc *grpc.ClientConn) DKVClusterClient {
-	return &dKVClusterClient{cc}
+type dKVSecurityClient struct {
+	cc grpc.ClientConnInterface
 }
 
-func (c *dKVClusterClient) AddNode(ctx context.Context, in *AddNodeRequest, opts ...grpc.CallOption) (*Status, error) {
-	out := new(Status)
-	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/AddNode", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func NewDKVSecurityClient(cc grpc.ClientConnInterface) DKVSecurityClient {
+	return &dKVSecurityClient{cc}
 }
 
-func (c *dKVClusterClient) RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*Status, error) {
-	out := new(Status)
-	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVCluster/RemoveNode", in, out, opts...)
+func (c *dKVSecurityClient) ReloadSecurityConfig(ctx context.Context, in *ReloadSecurityConfigRequest, opts ...grpc.CallOption) (*ReloadSecurityConfigResponse, error) {
+	out := new(ReloadSecurityConfigResponse)
+	err := c.cc.Invoke(ctx, "/dkv.serverpb.DKVSecurity/ReloadSecurityConfig", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// DKVClusterServer is the server API for DKVCluster service.
-type DKVClusterServer interface {
-	// AddNode adds the given DKV node to the cluster that the
-	// current node is a member of.
-	AddNode(context.Context, *AddNodeRequest) (*Status, error)
-	// RemoveNode removes the given DKV node from the cluster that
-	// the current node is a member of.
-	RemoveNode(context.Context, *RemoveNodeRequest) (*Status, error)
-}
-
-// UnimplementedDKVClusterServer can be embedded to have forward compatible implementations.
-type UnimplementedDKVClusterServer struct {
+// DKVSecurityServer is the server API for DKVSecurity service.
+type DKVSecurityServer interface {
+	// ReloadSecurityConfig re-reads the TLS certificate, private key and CA files,
+	// along with the token file, of the current node, so that these can be rotated
+	// without restarting it. The files are validated together, with either all of
+	// them applied or none of them upon any validation error. The TLS files apply to
+	// the connections accepted subsequently, while the existing ones are served as is
+	// until they are closed. The tokens apply to every call made subsequently.
+	ReloadSecurityConfig(context.Context, *ReloadSecurityConfigRequest) (*ReloadSecurityConfigResponse, error)
 }
 
-func (*UnimplementedDKVClusterServer) AddNode(ctx context.Context, req *AddNodeRequest) (*Status, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method AddNode not implemented")
-}
-func (*UnimplementedDKVClusterServer) RemoveNode(ctx context.Context, req *RemoveNodeRequest) (*Status, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RemoveNode not implemented")
+// UnimplementedDKVSecurityServer can be embedded to have forward compatible implementations.
+type UnimplementedDKVSecurityServer struct {
 }
 
-func RegisterDKVClusterServer(s *grpc.Server, srv DKVClusterServer) {
-	s.RegisterService(&_DKVCluster_serviceDesc, srv)
+func (*UnimplementedDKVSecurityServer) ReloadSecurityConfig(ctx context.Context, req *ReloadSecurityConfigRequest) (*ReloadSecurityConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadSecurityConfig not implemented")
 }
 
-func _DKVCluster_AddNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AddNodeRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(DKVClusterServer).AddNode(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/dkv.serverpb.DKVCluster/AddNode",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DKVClusterServer).AddNode(ctx, req.(*AddNodeRequest))
-	}
-	return interceptor(ctx, in, info, handler)
+func RegisterDKVSecurityServer(s *grpc.Server, srv DKVSecurityServer) {
+	s.RegisterService(&_DKVSecurity_serviceDesc, srv)
 }
 
-func _DKVCluster_RemoveNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RemoveNodeRequest)
+func _DKVSecurity_ReloadSecurityConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadSecurityConfigRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DKVClusterServer).RemoveNode(ctx, in)
+		return srv.(DKVSecurityServer).ReloadSecurityConfig(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/dkv.serverpb.DKVCluster/RemoveNode",
+		FullMethod: "/dkv.serverpb.DKVSecurity/ReloadSecurityConfig",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DKVClusterServer).RemoveNode(ctx, req.(*RemoveNodeRequest))
+		return srv.(DKVSecurityServer).ReloadSecurityConfig(ctx, req.(*ReloadSecurityConfigRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _DKVCluster_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "dkv.serverpb.DKVCluster",
-	HandlerType: (*DKVClusterServer)(nil),
+var _DKVSecurity_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dkv.serverpb.DKVSecurity",
+	HandlerType: (*DKVSecurityServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "AddNode",
-			Handler:    _DKVCluster_AddNode_Handler,
-		},
-		{
-			MethodName: "RemoveNode",
-			Handler:    _DKVCluster_RemoveNode_Handler,
+			MethodName: "ReloadSecurityConfig",
+			Handler:    _DKVSecurity_ReloadSecurityConfig_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
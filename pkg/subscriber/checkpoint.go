@@ -0,0 +1,77 @@
+package subscriber
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A CheckpointStore persists the position of a consumer in the changes
+// of the DKV master nodes, so that the delivery of these changes resumes
+// from that position the next time the consumer subscribes.
+type CheckpointStore interface {
+	// LoadCheckpoint returns the change number of the latest change
+	// handled by the consumer, which is zero if none are handled yet.
+	LoadCheckpoint() (uint64, error)
+	// SaveCheckpoint records the given change number as that of the
+	// latest change handled by the consumer.
+	SaveCheckpoint(changeNum uint64) error
+}
+
+// NewMemoryCheckpointStore creates a CheckpointStore that retains the
+// position of the consumer in memory alone, for consumers whose state
+// is lost upon restart anyway.
+func NewMemoryCheckpointStore() CheckpointStore {
+	return &memCheckpointStore{}
+}
+
+type memCheckpointStore struct {
+	mu        sync.Mutex
+	changeNum uint64
+}
+
+func (mcs *memCheckpointStore) LoadCheckpoint() (uint64, error) {
+	mcs.mu.Lock()
+	defer mcs.mu.Unlock()
+	return mcs.changeNum, nil
+}
+
+func (mcs *memCheckpointStore) SaveCheckpoint(changeNum uint64) error {
+	mcs.mu.Lock()
+	defer mcs.mu.Unlock()
+	mcs.changeNum = changeNum
+	return nil
+}
+
+// NewFileCheckpointStore creates a CheckpointStore that persists the
+// position of the consumer onto the file at the given path. Every
+// checkpoint replaces this file atomically, so that a crash midway
+// retains the previous checkpoint.
+func NewFileCheckpointStore(path string) CheckpointStore {
+	return &fileCheckpointStore{path: path}
+}
+
+type fileCheckpointStore struct {
+	path string
+}
+
+func (fcs *fileCheckpointStore) LoadCheckpoint() (uint64, error) {
+	data, err := ioutil.ReadFile(fcs.path)
+	switch {
+	case os.IsNotExist(err):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func (fcs *fileCheckpointStore) SaveCheckpoint(changeNum uint64) error {
+	tmpPath := fcs.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, []byte(strconv.FormatUint(changeNum, 10)), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, fcs.path)
+}
@@ -0,0 +1,58 @@
+package subscriber_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"github.com/flipkart-incubator/dkv/pkg/subscriber"
+)
+
+// Invalidates the entries of a local cache of DKV keys as and when
+// they are changed on the masters of a DKV cluster.
+func ExampleSubscriber_Subscribe() {
+	var cache sync.Map
+	var masters []subscriber.Master
+	for _, addr := range []string{"dkv-master-1:8080", "dkv-master-2:8080", "dkv-master-3:8080"} {
+		master, err := subscriber.Dial(addr, subscriber.WithAuthToken("cache-invalidator-token"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		masters = append(masters, master)
+	}
+	dialer := func(addr string) (subscriber.Master, error) {
+		return subscriber.Dial(addr, subscriber.WithAuthToken("cache-invalidator-token"))
+	}
+	sub, err := subscriber.New(masters, subscriber.NewFileCheckpointStore("/var/lib/cache/dkv.checkpoint"), subscriber.WithDialer(dialer))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	go func() {
+		<-sigs
+		cancel()
+	}()
+	// Changes are delivered atleast once, which is harmless for invalidations
+	err = sub.Subscribe(ctx, 0, func(chng *serverpb.ChangeRecord) error {
+		for _, trxn := range chng.Trxns {
+			cache.Delete(string(trxn.Key))
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, context.Canceled):
+	case errors.Is(err, subscriber.ErrChangesUnavailable):
+		// Changes missed can not be known, hence the entire cache is stale
+		log.Println("Changes are no longer available, consider clearing the cache")
+	default:
+		log.Fatal(err)
+	}
+}
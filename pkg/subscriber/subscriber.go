@@ -0,0 +1,421 @@
+// Package subscriber delivers the changes made on DKV master nodes onto
+// downstream consumers, such as those invalidating their caches, without
+// them having to replicate these changes like DKV slave nodes do.
+//
+// Changes are streamed from the master nodes in batches and handed over
+// to the consumer one at a time, in the order of their change numbers.
+// Once all the changes of a batch are handled, the position of the
+// consumer is checkpointed onto its CheckpointStore, from where the
+// delivery resumes the next time it subscribes. Hence every change is
+// delivered atleast once, with the changes handled since the latest
+// checkpoint delivered again after a restart.
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	dkverrors "github.com/flipkart-incubator/dkv/pkg/errors"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// A Handler handles a change delivered by a Subscriber. A failure ends
+// the subscription, upon which the change is delivered again once the
+// consumer subscribes anew.
+type Handler func(*serverpb.ChangeRecord) error
+
+// A Master is the client of a DKV master node from which changes are
+// retrieved, as returned by Dial. The DKV client of the ctl package
+// satisfies it as well.
+type Master interface {
+	// ServiceAddr returns the address of the DKV service of the master.
+	ServiceAddr() string
+	// GetChangesWithCtx returns the given number of changes, upto the
+	// given size, from the given change number onwards.
+	GetChangesWithCtx(ctx context.Context, fromChangeNum uint64, maxNumChanges uint32, maxNumBytes uint64) (*serverpb.GetChangesResponse, error)
+	// StreamChangesWithCtx streams the changes from the given change
+	// number onwards in batches of the given number and size.
+	StreamChangesWithCtx(ctx context.Context, fromChangeNum uint64, maxNumChanges uint32, maxNumBytes uint64) (serverpb.DKVReplication_StreamChangesClient, error)
+	// ListNodesWithCtx returns the ID of the leader of the cluster of the
+	// master, along with all of its nodes.
+	ListNodesWithCtx(ctx context.Context) (uint32, []*serverpb.NodeInfo, error)
+	// Close closes the connection to the master.
+	Close() error
+}
+
+// A Dialer creates the Master for communicating with the master node at
+// the given address, such as the leader of the cluster of the masters
+// given to a Subscriber.
+type Dialer func(addr string) (Master, error)
+
+// Default values used by Subscriber unless overridden
+// through one of the Option instances.
+const (
+	DefaultBatchSize     = 1000
+	DefaultBatchBytes    = 16 << 20
+	DefaultRetryInterval = time.Second
+)
+
+// Duration for which the stream of changes from a master node can
+// be silent before it is considered broken. Masters are expected to
+// send heartbeats well within this duration.
+const maxChangeStreamIdleTime = 10 * time.Second
+
+var (
+	// ErrChangesUnavailable indicates that the changes to be delivered
+	// are no longer retained by the master node, following which the
+	// consumer must rebuild its state before subscribing afresh from
+	// the latest change number of the master.
+	ErrChangesUnavailable = dkverrors.ErrChangesUnavailable
+	// ErrMasterDiverged indicates that the change number of the master
+	// node is lesser than that of the changes already delivered, which
+	// happens when the master is restored from an older backup.
+	ErrMasterDiverged = ctl.ErrMasterDiverged
+)
+
+// consumerError is a failure of either the Handler or the CheckpointStore
+// of the consumer, which ends the subscription.
+type consumerError struct {
+	error
+}
+
+type opts struct {
+	batchSize     uint32
+	batchBytes    uint64
+	retryInterval time.Duration
+	dialer        Dialer
+	lgr           *zap.Logger
+}
+
+// An Option is used to customize a specific aspect of a Subscriber.
+type Option func(*opts)
+
+// WithBatchSize sets the maximum number of changes retrieved from
+// the master node in a single batch, and hence checkpointed together.
+func WithBatchSize(maxNumChanges uint32) Option {
+	return func(o *opts) {
+		o.batchSize = maxNumChanges
+	}
+}
+
+// WithBatchBytes sets the maximum total size of the changes retrieved
+// from the master node in a single batch, zero for no limit.
+func WithBatchBytes(maxNumBytes uint64) Option {
+	return func(o *opts) {
+		o.batchBytes = maxNumBytes
+	}
+}
+
+// WithRetryInterval sets the interval at which the changes are retrieved
+// again after a failure, and at which they are polled for from master
+// nodes that do not support streaming them.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(o *opts) {
+		o.retryInterval = interval
+	}
+}
+
+// WithDialer sets the Dialer used for following the leader of the
+// cluster of the master nodes, when it is none of the masters given to
+// the Subscriber. By default such leaders are not followed.
+func WithDialer(dialer Dialer) Option {
+	return func(o *opts) {
+		o.dialer = dialer
+	}
+}
+
+// WithLogger sets the logger used for logging the failures and
+// failovers of the Subscriber. By default nothing is logged.
+func WithLogger(lgr *zap.Logger) Option {
+	return func(o *opts) {
+		o.lgr = lgr
+	}
+}
+
+type dialOpts struct {
+	tls                       bool
+	certFile, keyFile, caFile string
+	authToken                 string
+}
+
+// A DialOption is used to customize the connection made by Dial.
+type DialOption func(*dialOpts)
+
+// WithTLS secures the connection with TLS, verifying the master against
+// the CAs in `caFile` or the system CAs if `caFile` is empty. If both
+// `certFile` and `keyFile` are given, they are presented to the master
+// as the client certificate for mutual TLS.
+func WithTLS(certFile, keyFile, caFile string) DialOption {
+	return func(o *dialOpts) {
+		o.tls, o.certFile, o.keyFile, o.caFile = true, certFile, keyFile, caFile
+	}
+}
+
+// WithAuthToken presents the given bearer token to the master on every
+// call, for masters that require their calls to be authorized.
+func WithAuthToken(token string) DialOption {
+	return func(o *dialOpts) {
+		o.authToken = token
+	}
+}
+
+// Dial creates the Master for the master node at the given address,
+// which is connected to in the background. The connection is insecure
+// unless WithTLS is given.
+func Dial(addr string, options ...DialOption) (Master, error) {
+	o := &dialOpts{}
+	for _, opt := range options {
+		opt(o)
+	}
+	cliOpts := []ctl.DKVClientOption{ctl.WithNonBlockingDial()}
+	if o.authToken != "" {
+		cliOpts = append(cliOpts, ctl.WithAuthToken(o.authToken))
+	}
+	var client *ctl.DKVClient
+	var err error
+	if o.tls {
+		client, err = ctl.NewTLSDKVClient(addr, o.certFile, o.keyFile, o.caFile, cliOpts...)
+	} else {
+		client, err = ctl.NewInSecureDKVClient(addr, cliOpts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// A Subscriber delivers the changes made on the DKV master nodes of
+// a cluster onto a consumer. It is not safe for concurrent subscriptions.
+type Subscriber struct {
+	masters   []Master
+	ckpts     CheckpointStore
+	opts      *opts
+	master    Master
+	masterIdx int
+	polling   bool
+	dialed    map[string]Master
+}
+
+// New creates a Subscriber that retrieves changes from the first of the
+// given candidate master nodes, checkpointing the position of its consumer
+// onto the given CheckpointStore. Whenever the current master turns out to
+// be unreachable, it fails over onto the leader of their cluster as listed
+// by the ListNodes method of the reachable masters, or onto the next one
+// if the leader is unknown.
+func New(masters []Master, ckpts CheckpointStore, options ...Option) (*Subscriber, error) {
+	if len(masters) == 0 || ckpts == nil {
+		return nil, errors.New("invalid args - params `masters` and `ckpts` are mandatory")
+	}
+	for _, master := range masters {
+		if master == nil {
+			return nil, errors.New("invalid args - param `masters` can not have nil clients")
+		}
+	}
+	o := &opts{batchSize: DefaultBatchSize, batchBytes: DefaultBatchBytes, retryInterval: DefaultRetryInterval, lgr: zap.NewNop()}
+	for _, opt := range options {
+		opt(o)
+	}
+	return &Subscriber{masters: masters, ckpts: ckpts, opts: o, master: masters[0], dialed: make(map[string]Master)}, nil
+}
+
+// Subscribe delivers the changes from the given change number onwards
+// to the given handler, until either the given context is done or the
+// handler fails, whose error is then returned, as are the failures of
+// the CheckpointStore. Delivery resumes from the change following the
+// checkpoint of the consumer instead, if it is beyond the given change
+// number. Subscriptions that can not proceed end with
+// ErrChangesUnavailable or ErrMasterDiverged, whereas all the other
+// failures are retried.
+func (s *Subscriber) Subscribe(ctx context.Context, fromChangeNum uint64, handler Handler) error {
+	ckptChngNum, err := s.ckpts.LoadCheckpoint()
+	if err != nil {
+		return err
+	}
+	if ckptChngNum >= fromChangeNum {
+		fromChangeNum = ckptChngNum + 1
+	}
+	for {
+		if s.polling {
+			fromChangeNum, err = s.poll(ctx, fromChangeNum, handler)
+		} else {
+			fromChangeNum, err = s.stream(ctx, fromChangeNum, handler)
+		}
+		var consErr *consumerError
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &consErr):
+			return consErr.error
+		case errors.Is(err, ErrChangesUnavailable), err == ErrMasterDiverged:
+			return err
+		case status.Code(err) == codes.Unimplemented && !s.polling:
+			s.opts.lgr.Warn("Master does not support streaming of changes, falling back to polling", zap.String("masterAddr", s.master.ServiceAddr()), zap.Error(err))
+			s.polling = true
+			continue
+		case status.Code(err) == codes.Unavailable:
+			s.failover(ctx)
+		}
+		s.opts.lgr.Warn("Unable to retrieve changes from master", zap.String("masterAddr", s.master.ServiceAddr()), zap.Uint64("fromChangeNum", fromChangeNum), zap.Duration("retryIn", s.opts.retryInterval), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.opts.retryInterval):
+		}
+	}
+}
+
+// stream delivers the changes streamed from the current master for as
+// long as the stream is healthy, returning the change number from which
+// the delivery must resume.
+func (s *Subscriber) stream(ctx context.Context, fromChngNum uint64, handler Handler) (uint64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	chngsStrm, err := s.master.StreamChangesWithCtx(ctx, fromChngNum, s.opts.batchSize, s.opts.batchBytes)
+	if err != nil {
+		return fromChngNum, err
+	}
+	idleTmr := time.AfterFunc(maxChangeStreamIdleTime, cancel)
+	defer idleTmr.Stop()
+	for {
+		res, err := chngsStrm.Recv()
+		if err != nil {
+			return fromChngNum, err
+		}
+		idleTmr.Reset(maxChangeStreamIdleTime)
+		if fromChngNum, err = s.deliver(res, fromChngNum, handler); err != nil {
+			return fromChngNum, err
+		}
+	}
+}
+
+// poll delivers the changes polled for from the current master, polling
+// right away as long as the consumer is lagging behind.
+func (s *Subscriber) poll(ctx context.Context, fromChngNum uint64, handler Handler) (uint64, error) {
+	for {
+		callCtx, cancel := context.WithTimeout(ctx, ctl.DefaultTimeout)
+		res, err := s.master.GetChangesWithCtx(callCtx, fromChngNum, s.opts.batchSize, s.opts.batchBytes)
+		cancel()
+		if err != nil {
+			return fromChngNum, err
+		}
+		if fromChngNum, err = s.deliver(res, fromChngNum, handler); err != nil {
+			return fromChngNum, err
+		}
+		if res.NumberOfChanges == 0 || res.MasterChangeNumber < fromChngNum {
+			select {
+			case <-ctx.Done():
+				return fromChngNum, ctx.Err()
+			case <-time.After(s.opts.retryInterval):
+			}
+		}
+	}
+}
+
+// deliver hands over the changes of the given batch onto the given
+// handler and checkpoints those that are handled. Changes preceding
+// the given change number are skipped, having been delivered already.
+func (s *Subscriber) deliver(res *serverpb.GetChangesResponse, fromChngNum uint64, handler Handler) (uint64, error) {
+	if err := dkverrors.FromStatus(res.Status); err != nil {
+		return fromChngNum, err
+	}
+	if fromChngNum > 0 && res.MasterChangeNumber < fromChngNum-1 {
+		return fromChngNum, ErrMasterDiverged
+	}
+	nextChngNum := fromChngNum
+	var err error
+	for _, chng := range res.Changes {
+		if chng.ChangeNumber < nextChngNum {
+			continue
+		}
+		if hdlrErr := handler(chng); hdlrErr != nil {
+			err = &consumerError{hdlrErr}
+			break
+		}
+		nextChngNum = chng.ChangeNumber + 1
+	}
+	// Changes handled before the failure, if any, are not delivered again
+	if nextChngNum > fromChngNum {
+		if ckptErr := s.ckpts.SaveCheckpoint(nextChngNum - 1); ckptErr != nil && err == nil {
+			err = &consumerError{ckptErr}
+		}
+	}
+	return nextChngNum, err
+}
+
+// failover switches onto the leader of the cluster as listed by the
+// first of the candidate masters that can be reached, following the
+// current one. Masters that do not list their cluster, or do not know
+// its leader, are switched onto themselves.
+func (s *Subscriber) failover(ctx context.Context) {
+	for i := 1; i <= len(s.masters); i++ {
+		idx := (s.masterIdx + i) % len(s.masters)
+		candidate := s.masters[idx]
+		callCtx, cancel := context.WithTimeout(ctx, ctl.DefaultTimeout)
+		leaderID, nodes, err := candidate.ListNodesWithCtx(callCtx)
+		cancel()
+		if status.Code(err) == codes.Unavailable || ctx.Err() != nil {
+			continue
+		}
+		master := candidate
+		if leader := s.leader(leaderID, nodes); leader != nil {
+			master = leader
+		}
+		if master != s.master {
+			s.opts.lgr.Info("Failing over onto another master", zap.String("masterAddr", master.ServiceAddr()))
+		}
+		s.master, s.masterIdx, s.polling = master, idx, false
+		return
+	}
+}
+
+// leader returns the client of the given leader among the given nodes,
+// which is nil if the address of its DKV service is unknown or can not
+// be dialed.
+func (s *Subscriber) leader(leaderID uint32, nodes []*serverpb.NodeInfo) Master {
+	var ldrAddr string
+	for _, node := range nodes {
+		if leaderID != 0 && node.NodeId == leaderID {
+			ldrAddr = node.DkvAddr
+		}
+	}
+	if ldrAddr == "" {
+		return nil
+	}
+	for _, master := range s.masters {
+		if master.ServiceAddr() == ldrAddr {
+			return master
+		}
+	}
+	if cli, present := s.dialed[ldrAddr]; present || s.opts.dialer == nil {
+		return cli
+	}
+	cli, err := s.opts.dialer(ldrAddr)
+	if err != nil {
+		s.opts.lgr.Warn("Unable to dial the leader", zap.String("leaderAddr", ldrAddr), zap.Error(err))
+		return nil
+	}
+	s.dialed[ldrAddr] = cli
+	return cli
+}
+
+// Close closes the clients of all the master nodes, including the
+// candidate masters given to New.
+func (s *Subscriber) Close() error {
+	var err error
+	for _, master := range s.masters {
+		if closeErr := master.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	for _, cli := range s.dialed {
+		if closeErr := cli.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,198 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/server/master"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc"
+)
+
+func serve(t *testing.T, register func(*grpc.Server)) (string, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcSrvr := grpc.NewServer()
+	register(grpcSrvr)
+	go grpcSrvr.Serve(lis)
+	return lis.Addr().String(), grpcSrvr.Stop
+}
+
+// serveMaster serves an in-memory standalone master
+func serveMaster(t *testing.T) (string, func()) {
+	store := memory.OpenDB(0)
+	dkvSvc := master.NewStandaloneService(store, store, store)
+	addr, stop := serve(t, func(grpcSrvr *grpc.Server) {
+		serverpb.RegisterDKVServer(grpcSrvr, dkvSvc)
+		serverpb.RegisterDKVReplicationServer(grpcSrvr, dkvSvc)
+	})
+	return addr, func() {
+		stop()
+		dkvSvc.Close()
+	}
+}
+
+func newClient(t *testing.T, addr string) *ctl.DKVClient {
+	client, err := ctl.NewInSecureDKVClient(addr, ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20), ctl.WithNonBlockingDial())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func putKeys(t *testing.T, client *ctl.DKVClient, from, to int) {
+	for i := from; i < to; i++ {
+		if err := client.Put([]byte(fmt.Sprintf("K%d", i)), []byte(fmt.Sprintf("V%d", i))); err != nil {
+			t.Fatalf("Unable to PUT. Error: %v", err)
+		}
+	}
+}
+
+// collect subscribes until the given number of keys are delivered,
+// returning these keys
+func collect(t *testing.T, sub *Subscriber, numKeys int) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var keys []string
+	err := sub.Subscribe(ctx, 0, func(chng *serverpb.ChangeRecord) error {
+		for _, trxn := range chng.Trxns {
+			keys = append(keys, string(trxn.Key))
+		}
+		if len(keys) >= numKeys {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("Expected the subscription to be cancelled. Error: %v, Keys: %v", err, keys)
+	}
+	return keys
+}
+
+func checkKeys(t *testing.T, keys []string, from, to int) {
+	var expKeys []string
+	for i := from; i < to; i++ {
+		expKeys = append(expKeys, fmt.Sprintf("K%d", i))
+	}
+	if strings.Join(keys, ",") != strings.Join(expKeys, ",") {
+		t.Errorf("Delivered keys mismatch. Expected: %v, Actual: %v", expKeys, keys)
+	}
+}
+
+func TestSubscribeResumesFromCheckpoint(t *testing.T) {
+	addr, stop := serveMaster(t)
+	defer stop()
+	client := newClient(t, addr)
+	defer client.Close()
+	putKeys(t, client, 0, 10)
+
+	dir, err := ioutil.TempDir("", "dkv_subscriber_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ckpts := NewFileCheckpointStore(path.Join(dir, "checkpoint"))
+	sub, err := New([]Master{newClient(t, addr)}, ckpts, WithBatchSize(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	checkKeys(t, collect(t, sub, 10), 0, 10)
+
+	// Restarted consumers resume past the checkpointed changes
+	putKeys(t, client, 10, 15)
+	sub, err = New([]Master{newClient(t, addr)}, NewFileCheckpointStore(path.Join(dir, "checkpoint")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	checkKeys(t, collect(t, sub, 5), 10, 15)
+}
+
+func TestSubscribeRedeliversUnhandledChanges(t *testing.T) {
+	addr, stop := serveMaster(t)
+	defer stop()
+	client := newClient(t, addr)
+	defer client.Close()
+	putKeys(t, client, 0, 5)
+
+	ckpts := NewMemoryCheckpointStore()
+	sub, err := New([]Master{newClient(t, addr)}, ckpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	errHandler, handled := errors.New("unable to handle"), 0
+	err = sub.Subscribe(context.Background(), 0, func(chng *serverpb.ChangeRecord) error {
+		if handled == 3 {
+			return errHandler
+		}
+		handled++
+		return nil
+	})
+	if err != errHandler {
+		t.Errorf("Expected the failure of the handler. Actual: %v", err)
+	}
+	checkKeys(t, collect(t, sub, 2), 3, 5)
+}
+
+// clusterDirectory lists a cluster led by the given DKV service
+type clusterDirectory struct {
+	serverpb.DKVClusterServer
+	leaderAddr string
+}
+
+func (cd *clusterDirectory) ListNodes(context.Context, *serverpb.ListNodesRequest) (*serverpb.ListNodesResponse, error) {
+	nodes := []*serverpb.NodeInfo{
+		{NodeId: 1, Role: serverpb.NodeRole_Follower},
+		{NodeId: 2, Role: serverpb.NodeRole_Leader, DkvAddr: cd.leaderAddr},
+	}
+	return &serverpb.ListNodesResponse{Status: &serverpb.Status{}, LeaderId: 2, Nodes: nodes}, nil
+}
+
+func TestSubscribeFailsOverOntoLeader(t *testing.T) {
+	addr, stop := serveMaster(t)
+	defer stop()
+	client := newClient(t, addr)
+	defer client.Close()
+	putKeys(t, client, 0, 5)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachableAddr := lis.Addr().String()
+	lis.Close()
+	dirAddr, stopDir := serve(t, func(grpcSrvr *grpc.Server) {
+		serverpb.RegisterDKVClusterServer(grpcSrvr, &clusterDirectory{leaderAddr: addr})
+	})
+	defer stopDir()
+
+	var dialed []string
+	dialer := func(addr string) (Master, error) {
+		dialed = append(dialed, addr)
+		return newClient(t, addr), nil
+	}
+	masters := []Master{newClient(t, unreachableAddr), newClient(t, dirAddr)}
+	sub, err := New(masters, NewMemoryCheckpointStore(), WithDialer(dialer), WithRetryInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	checkKeys(t, collect(t, sub, 5), 0, 5)
+	if len(dialed) != 1 || dialed[0] != addr {
+		t.Errorf("Expected only the leader to be dialed. Actual: %v", dialed)
+	}
+}
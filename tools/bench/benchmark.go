@@ -2,7 +2,11 @@ package bench
 
 import (
 	"flag"
+	"fmt"
 	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // Benchmark represents the behavior required for
@@ -23,20 +27,126 @@ const (
 
 var (
 	valueSizeInBytes uint
+	valueSizeDist    string
 	numHotKeys       uint
+	keySpaceSize     uint
 	batchSize        uint
 )
 
 func init() {
 	flag.UintVar(&valueSizeInBytes, "valueSizeInBytes", 10, "Size of every value in bytes")
+	flag.StringVar(&valueSizeDist, "valueSizeDistribution", "", "Sizes of the values in bytes along with their weights, such as 100:90,4096:10, instead of valueSizeInBytes")
 	flag.UintVar(&numHotKeys, "numHotKeys", 100, "Number of keys that are repeatedly read or updated")
+	flag.UintVar(&keySpaceSize, "keySpaceSize", 1000, "Number of keys that are written in turn by Put requests")
 	flag.UintVar(&batchSize, "batchSize", 10, "Batch size for GetAll requests")
 }
 
-func randomBytes(size uint) []byte {
+func randomBytes(rnd *rand.Rand, size uint) []byte {
 	res := make([]byte, size)
 	for i := 0; i < int(size); i++ {
-		res[i] = byte(rand.Intn(129))
+		res[i] = byte(rnd.Intn(129))
 	}
 	return res
 }
+
+// ValueSizes samples the sizes of the values written by benchmarks.
+type ValueSizes interface {
+	Sample(rnd *rand.Rand) uint
+	String() string
+}
+
+type fixedValueSize uint
+
+// FixedValueSize returns the given size for every value.
+func FixedValueSize(size uint) ValueSizes {
+	return fixedValueSize(size)
+}
+
+func (fvs fixedValueSize) Sample(rnd *rand.Rand) uint {
+	return uint(fvs)
+}
+
+func (fvs fixedValueSize) String() string {
+	return fmt.Sprintf("%d bytes", uint(fvs))
+}
+
+type weightedValueSizes struct {
+	sizes []uint
+	// Cumulative weights of the sizes
+	cumWeights []uint
+}
+
+// WeightedValueSizes samples the given sizes as per their given
+// weights, such that a size of weight 3 is sampled thrice as often
+// as that of weight 1.
+func WeightedValueSizes(weights map[uint]uint) ValueSizes {
+	wvs := &weightedValueSizes{}
+	for size, weight := range weights {
+		if weight > 0 {
+			wvs.sizes = append(wvs.sizes, size)
+		}
+	}
+	if len(wvs.sizes) == 0 {
+		panic("At least one value size must be given a positive weight")
+	}
+	sort.Slice(wvs.sizes, func(i, j int) bool { return wvs.sizes[i] < wvs.sizes[j] })
+	var cumWeight uint
+	for _, size := range wvs.sizes {
+		cumWeight += weights[size]
+		wvs.cumWeights = append(wvs.cumWeights, cumWeight)
+	}
+	return wvs
+}
+
+func (wvs *weightedValueSizes) Sample(rnd *rand.Rand) uint {
+	n := uint(rnd.Int63n(int64(wvs.cumWeights[len(wvs.cumWeights)-1])))
+	return wvs.sizes[sort.Search(len(wvs.cumWeights), func(i int) bool { return wvs.cumWeights[i] > n })]
+}
+
+func (wvs *weightedValueSizes) String() string {
+	var b strings.Builder
+	for i, size := range wvs.sizes {
+		weight := wvs.cumWeights[i]
+		if i > 0 {
+			b.WriteString(",")
+			weight -= wvs.cumWeights[i-1]
+		}
+		fmt.Fprintf(&b, "%d:%d", size, weight)
+	}
+	return b.String() + " (bytes:weight)"
+}
+
+// ParseValueSizes parses the sizes of values along with their weights
+// from the given comma separated pairs of the form <size>:<weight>,
+// such as 100:90,4096:10, as sampled by WeightedValueSizes.
+func ParseValueSizes(dist string) (ValueSizes, error) {
+	weights := make(map[uint]uint)
+	for _, pair := range strings.Split(dist, ",") {
+		sizeWeight := strings.Split(strings.TrimSpace(pair), ":")
+		if len(sizeWeight) != 2 {
+			return nil, fmt.Errorf("value size must be given as <size>:<weight>. Given: %q", pair)
+		}
+		size, err := strconv.ParseUint(sizeWeight[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value size: %q: %v", sizeWeight[0], err)
+		}
+		weight, err := strconv.ParseUint(sizeWeight[1], 10, 32)
+		if err != nil || weight == 0 {
+			return nil, fmt.Errorf("invalid weight of value size: %d: %q", size, sizeWeight[1])
+		}
+		weights[uint(size)] += uint(weight)
+	}
+	return WeightedValueSizes(weights), nil
+}
+
+// defaultValueSizes returns the value sizes given by the flags.
+func defaultValueSizes() ValueSizes {
+	if valueSizeDist == "" {
+		return FixedValueSize(valueSizeInBytes)
+	}
+	valueSizes, err := ParseValueSizes(valueSizeDist)
+	if err != nil {
+		panic(err)
+	}
+	return valueSizes
+}
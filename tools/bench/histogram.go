@@ -0,0 +1,124 @@
+package bench
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	// subBucketBits gives the number of sub buckets of every power of 2
+	// range of latencies, which bounds the relative error of recorded
+	// latencies to under 1/64.
+	subBucketBits      = 7
+	subBucketCount     = 1 << subBucketBits
+	subBucketHalfCount = subBucketCount / 2
+	numLatencyBuckets  = (64-subBucketBits+1)*subBucketHalfCount + subBucketHalfCount
+)
+
+// LatencyHistogram records latencies in nanoseconds into log-linear
+// buckets, taking after HDR histograms. Latencies below 128ns are
+// recorded exactly, and every larger power of 2 range is split into 64
+// buckets. Both recording and computing percentiles are done without
+// allocations. It is not safe for concurrent use.
+type LatencyHistogram struct {
+	counts     [numLatencyBuckets]uint64
+	totalCount uint64
+	max        time.Duration
+}
+
+// HistogramBucket is a non empty bucket of a LatencyHistogram, with the
+// number of latencies recorded up to its upper bound.
+type HistogramBucket struct {
+	UpperBound time.Duration `json:"upperBoundNanos"`
+	Count      uint64        `json:"count"`
+}
+
+// NewLatencyHistogram returns an empty LatencyHistogram.
+func NewLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{}
+}
+
+func bucketIndex(latency time.Duration) int {
+	v := uint64(latency)
+	// Shift that leaves the top subBucketBits bits of the latency
+	shift := 0
+	if v >= subBucketCount {
+		shift = bits.Len64(v) - subBucketBits
+	}
+	return shift*subBucketHalfCount + int(v>>uint(shift))
+}
+
+func bucketUpperBound(index int) time.Duration {
+	shift := 0
+	if index >= subBucketCount {
+		shift = index/subBucketHalfCount - 1
+	}
+	subIndex := uint64(index - shift*subBucketHalfCount)
+	upperBound := (subIndex+1)<<uint(shift) - 1
+	if upperBound > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(upperBound)
+}
+
+// Record adds the given latency to the histogram, treating negative
+// latencies as zero.
+func (lh *LatencyHistogram) Record(latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+	lh.counts[bucketIndex(latency)]++
+	lh.totalCount++
+	if latency > lh.max {
+		lh.max = latency
+	}
+}
+
+// TotalCount is the number of latencies recorded.
+func (lh *LatencyHistogram) TotalCount() uint64 {
+	return lh.totalCount
+}
+
+// Max is the largest latency recorded, which is exact.
+func (lh *LatencyHistogram) Max() time.Duration {
+	return lh.max
+}
+
+// ValueAtPercentile returns the latency at or below which the given
+// percentile of the recorded latencies lie, as the upper bound of the
+// bucket where that percentile falls, but no more than the maximum.
+func (lh *LatencyHistogram) ValueAtPercentile(percentile float64) time.Duration {
+	if lh.totalCount == 0 {
+		return 0
+	}
+	percentile = math.Max(0, math.Min(percentile, 100))
+	// Rounding to the nearest count, as a fraction of the total count
+	// is not exact for percentiles such as 99.9
+	target := uint64(percentile/100*float64(lh.totalCount) + 0.5)
+	if target == 0 {
+		target = 1
+	}
+	var count uint64
+	for i, bucketCount := range lh.counts {
+		if count += bucketCount; count >= target {
+			if upperBound := bucketUpperBound(i); upperBound < lh.max {
+				return upperBound
+			}
+			break
+		}
+	}
+	return lh.max
+}
+
+// Buckets returns the non empty buckets of the histogram in the order
+// of their latencies.
+func (lh *LatencyHistogram) Buckets() []HistogramBucket {
+	var buckets []HistogramBucket
+	for i, bucketCount := range lh.counts {
+		if bucketCount > 0 {
+			buckets = append(buckets, HistogramBucket{UpperBound: bucketUpperBound(i), Count: bucketCount})
+		}
+	}
+	return buckets
+}
@@ -0,0 +1,145 @@
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func checkLatency(t *testing.T, name string, expected, actual time.Duration) {
+	// Buckets are within 1/64 of the latencies recorded in them
+	if relErr := math.Abs(float64(actual-expected)) / float64(expected); relErr > 1.0/64 {
+		t.Errorf("Latency mismatch of %s. Expected: %v, Actual: %v", name, expected, actual)
+	}
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	lh := NewLatencyHistogram()
+	if lat := lh.ValueAtPercentile(99); lat != 0 {
+		t.Errorf("Expected no latency from an empty histogram. Actual: %v", lat)
+	}
+	// Latencies from 1us to 100ms, uniform over a microsecond scale
+	const numLatencies = 100000
+	for i := numLatencies; i > 0; i-- {
+		lh.Record(time.Duration(i) * time.Microsecond)
+	}
+	if lh.TotalCount() != numLatencies || lh.Max() != numLatencies*time.Microsecond {
+		t.Errorf("Expected count: %d, max: %v. Actual count: %d, max: %v", numLatencies, numLatencies*time.Microsecond, lh.TotalCount(), lh.Max())
+	}
+	for _, percentile := range []float64{0.1, 1, 50, 90, 99, 99.9} {
+		expected := time.Duration(percentile/100*numLatencies) * time.Microsecond
+		checkLatency(t, "percentile", expected, lh.ValueAtPercentile(percentile))
+	}
+	if lat := lh.ValueAtPercentile(100); lat != lh.Max() {
+		t.Errorf("Expected the maximum at the 100th percentile. Actual: %v", lat)
+	}
+
+	// Small latencies are recorded exactly, and negative ones as zero
+	lh = NewLatencyHistogram()
+	for _, lat := range []time.Duration{-5, 0, 3, 3, 100} {
+		lh.Record(lat)
+	}
+	for percentile, expected := range map[float64]time.Duration{20: 0, 40: 0, 60: 3, 80: 3, 100: 100} {
+		if actual := lh.ValueAtPercentile(percentile); actual != expected {
+			t.Errorf("Latency mismatch at percentile %.0f. Expected: %v, Actual: %v", percentile, expected, actual)
+		}
+	}
+
+	// A long tail is reported by the high percentiles alone
+	lh = NewLatencyHistogram()
+	for i := 0; i < 999; i++ {
+		lh.Record(time.Millisecond)
+	}
+	lh.Record(time.Second)
+	checkLatency(t, "p99", time.Millisecond, lh.ValueAtPercentile(99))
+	checkLatency(t, "p999", time.Millisecond, lh.ValueAtPercentile(99.9))
+	if lat := lh.ValueAtPercentile(99.99); lat != time.Second {
+		t.Errorf("Expected the tail latency at p9999. Actual: %v", lat)
+	}
+}
+
+func TestLatencyHistogramAllocations(t *testing.T) {
+	lh := NewLatencyHistogram()
+	allocs := testing.AllocsPerRun(1000, func() {
+		lh.Record(1234 * time.Microsecond)
+		lh.ValueAtPercentile(99.9)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations to record latencies. Actual: %f", allocs)
+	}
+}
+
+func TestWriteHistograms(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dkv_bench_hist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	report := &WorkloadReport{Stats: make(map[Operation]*OperationStats)}
+	for op, lats := range map[Operation][]time.Duration{ReadOperation: {10, 10, 20}, WriteOperation: {50}} {
+		stats := &OperationStats{Latencies: NewLatencyHistogram()}
+		for _, lat := range lats {
+			stats.Latencies.Record(lat)
+		}
+		report.Stats[op] = stats
+	}
+
+	csvFile := filepath.Join(dir, "hist.csv")
+	if err := report.WriteHistograms(csvFile); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(csvFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expRecords := [][]string{
+		{"operation", "upperBoundNanos", "count", "percentile"},
+		{"Read", "10", "2", "66.667"},
+		{"Read", "20", "1", "100.000"},
+		{"Write", "50", "1", "100.000"},
+	}
+	if len(records) != len(expRecords) {
+		t.Fatalf("Expected CSV records: %v. Actual: %v", expRecords, records)
+	}
+	for i := range records {
+		for j := range records[i] {
+			if records[i][j] != expRecords[i][j] {
+				t.Errorf("Expected CSV records: %v. Actual: %v", expRecords, records)
+			}
+		}
+	}
+
+	jsonFile := filepath.Join(dir, "hist.json")
+	if err := report.WriteHistograms(jsonFile); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(jsonFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hists map[string]struct {
+		Count   uint64
+		Max     int64 `json:"maxNanos"`
+		Buckets []HistogramBucket
+	}
+	if err := json.Unmarshal(data, &hists); err != nil {
+		t.Fatal(err)
+	}
+	if read := hists["Read"]; read.Count != 3 || read.Max != 20 || len(read.Buckets) != 2 || read.Buckets[0].Count != 2 {
+		t.Errorf("Unexpected JSON histogram of reads: %s", data)
+	}
+	if write := hists["Write"]; write.Count != 1 || write.Max != 50 || len(write.Buckets) != 1 {
+		t.Errorf("Unexpected JSON histogram of writes: %s", data)
+	}
+}
@@ -0,0 +1,214 @@
+package bench
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+)
+
+// LagSample is the change numbers of the master and the slave nodes
+// polled at a given time.
+type LagSample struct {
+	Time            time.Time
+	MasterChangeNum uint64
+	SlaveChangeNum  uint64
+}
+
+// Lag is the number of changes on the master node yet to be applied on
+// the slave node.
+func (ls LagSample) Lag() uint64 {
+	if ls.SlaveChangeNum >= ls.MasterChangeNum {
+		return 0
+	}
+	return ls.MasterChangeNum - ls.SlaveChangeNum
+}
+
+// LagBenchmark drives writes against a master node while polling the
+// replication status of a slave node, so as to record how far behind
+// the slave falls and how soon it catches up once the writes stop.
+// Writes made as fast as possible, that is without a WriteQPS, measure
+// the time taken by the slave to drain a burst of changes.
+type LagBenchmark struct {
+	// NumWrites is the number of PUTs made on the master node
+	NumWrites uint
+	// WriteQPS is the rate of the PUTs, zero for as fast as possible
+	WriteQPS    uint
+	Concurrency uint
+	// Keys and ValueSizes are those of the PUTs
+	Keys       KeyDistribution
+	ValueSizes ValueSizes
+	// PollInterval is the interval at which the change numbers of the
+	// master and the slave nodes are polled
+	PollInterval time.Duration
+	// CatchUpTimeout bounds the time to wait for the slave node to
+	// catch up once the writes stop
+	CatchUpTimeout time.Duration
+}
+
+// LagReport describes a run of a LagBenchmark.
+type LagReport struct {
+	// Writes is the report of the PUTs made on the master node
+	Writes *WorkloadReport
+	// Samples are the change numbers polled in the order of their times
+	Samples    []LagSample
+	PollErrors uint64
+	// MaxLag is the largest lag among the samples
+	MaxLag uint64
+	// CaughtUp indicates whether the slave node applied every change
+	// of the writes within the CatchUpTimeout, taking CatchUpTime
+	// from the end of the writes
+	CaughtUp    bool
+	CatchUpTime time.Duration
+}
+
+// DefaultLagBenchmark returns the LagBenchmark given by the flags,
+// making the given number of writes at the given rate.
+func DefaultLagBenchmark(numWrites, writeQPS, concurrency uint, pollInterval, catchUpTimeout time.Duration) *LagBenchmark {
+	return &LagBenchmark{
+		NumWrites:      numWrites,
+		WriteQPS:       writeQPS,
+		Concurrency:    concurrency,
+		Keys:           UniformKeys(keySpaceSize),
+		ValueSizes:     defaultValueSizes(),
+		PollInterval:   pollInterval,
+		CatchUpTimeout: catchUpTimeout,
+	}
+}
+
+func (lb *LagBenchmark) String() string {
+	writeQPS := "as fast as possible"
+	if lb.WriteQPS > 0 {
+		writeQPS = fmt.Sprintf("at %d QPS", lb.WriteQPS)
+	}
+	return fmt.Sprintf("Writes: %d %s, Keys: %s, Value Size: %s, Poll Interval: %v", lb.NumWrites, writeQPS, lb.Keys, lb.ValueSizes, lb.PollInterval)
+}
+
+// masterChangeNumber reads the latest change number of the master
+// node, without reading any of its changes.
+func masterChangeNumber(ctx context.Context, master *ctl.DKVClient) (uint64, error) {
+	res, err := master.GetChangesWithCtx(ctx, math.MaxUint64, 1, 0)
+	if err != nil {
+		return 0, err
+	}
+	if res.Status.GetCode() != 0 {
+		return 0, fmt.Errorf("unable to read the change number of the master node: %s", res.Status.GetMessage())
+	}
+	return res.MasterChangeNumber, nil
+}
+
+func (lb *LagBenchmark) poll(ctx context.Context, master, slave *ctl.DKVClient) (LagSample, error) {
+	ctx, cancel := context.WithTimeout(ctx, lb.PollInterval+time.Second)
+	defer cancel()
+	// Slave is polled first, so that its lag is not understated
+	status, err := slave.ReplicationStatusWithCtx(ctx)
+	if err != nil {
+		return LagSample{}, err
+	}
+	masterChngNum, err := masterChangeNumber(ctx, master)
+	if err != nil {
+		return LagSample{}, err
+	}
+	return LagSample{Time: time.Now(), MasterChangeNum: masterChngNum, SlaveChangeNum: status.AppliedChangeNumber}, nil
+}
+
+// Run makes the writes of the benchmark on the given master node while
+// polling the given slave node, until the slave node catches up with
+// the writes or the CatchUpTimeout elapses after them.
+func (lb *LagBenchmark) Run(ctx context.Context, master, slave *ctl.DKVClient) *LagReport {
+	report := &LagReport{}
+	var mu sync.Mutex
+	// End of the writes, with the change number of the master node then
+	var writesEnd time.Time
+	var finalChngNum uint64
+	writesDone := make(chan struct{})
+
+	pollCtx, cancelPolls := context.WithCancel(ctx)
+	defer cancelPolls()
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		ticker := time.NewTicker(lb.PollInterval)
+		defer ticker.Stop()
+		var catchUpDeadline <-chan time.Time
+		for {
+			sample, err := lb.poll(pollCtx, master, slave)
+			mu.Lock()
+			end, endChngNum := writesEnd, finalChngNum
+			if err != nil {
+				report.PollErrors++
+			} else {
+				report.Samples = append(report.Samples, sample)
+				if sample.Lag() > report.MaxLag {
+					report.MaxLag = sample.Lag()
+				}
+			}
+			mu.Unlock()
+			if err == nil && !end.IsZero() && sample.SlaveChangeNum >= endChngNum {
+				report.CaughtUp = true
+				// Sample may be taken as the writes end
+				if sample.Time.After(end) {
+					report.CatchUpTime = sample.Time.Sub(end)
+				}
+				return
+			}
+
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-catchUpDeadline:
+				return
+			case <-writesDone:
+				writesDone, catchUpDeadline = nil, time.After(lb.CatchUpTimeout)
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	wl := &Workload{ReadFraction: 0, Keys: lb.Keys, ValueSizes: lb.ValueSizes, Seed: 1}
+	report.Writes = RunWorkload(ctx, master, wl.CreateRequests(lb.NumWrites), lb.Concurrency, lb.WriteQPS, 0)
+	chngNum, err := masterChangeNumber(ctx, master)
+	mu.Lock()
+	if err != nil {
+		// Without the final change number, the slave can not be
+		// known to catch up
+		report.PollErrors++
+		cancelPolls()
+	}
+	writesEnd, finalChngNum = time.Now(), chngNum
+	mu.Unlock()
+	close(writesDone)
+	<-polled
+	return report
+}
+
+// WriteCSV writes the samples of the report as CSV records of the time
+// in unix milliseconds, the change numbers and the lag.
+func (lr *LagReport) WriteCSV(out io.Writer) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"timestampMillis", "masterChangeNumber", "slaveChangeNumber", "lag"})
+	for _, sample := range lr.Samples {
+		w.Write([]string{
+			strconv.FormatInt(sample.Time.UnixNano()/int64(time.Millisecond), 10),
+			strconv.FormatUint(sample.MasterChangeNum, 10),
+			strconv.FormatUint(sample.SlaveChangeNum, 10),
+			strconv.FormatUint(sample.Lag(), 10),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (lr *LagReport) String() string {
+	catchUp := "Not caught up"
+	if lr.CaughtUp {
+		catchUp = fmt.Sprintf("Caught up in %v", lr.CatchUpTime)
+	}
+	return fmt.Sprintf("%sSamples: %d, Poll Errors: %d, Max Lag: %d, %s\n", lr.Writes, len(lr.Samples), lr.PollErrors, lr.MaxLag, catchUp)
+}
@@ -0,0 +1,107 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/server/master"
+	"github.com/flipkart-incubator/dkv/internal/server/slave"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc"
+)
+
+// serve serves the services registered by the given function, returning
+// a client of them along with the function that stops serving.
+func serve(t *testing.T, register func(*grpc.Server)) (*ctl.DKVClient, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcSrv := grpc.NewServer()
+	register(grpcSrv)
+	go grpcSrv.Serve(lis)
+	client, err := ctl.NewInSecureDKVClient(lis.Addr().String(), ctl.WithReadBufSize(1<<20), ctl.WithWriteBufSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, func() {
+		client.Close()
+		grpcSrv.Stop()
+	}
+}
+
+func TestLagBenchmark(t *testing.T) {
+	masterStore := memory.OpenDB(0)
+	masterSvc := master.NewStandaloneService(masterStore, masterStore, masterStore)
+	defer masterSvc.Close()
+	masterCli, stopMaster := serve(t, func(grpcSrv *grpc.Server) {
+		serverpb.RegisterDKVServer(grpcSrv, masterSvc)
+		serverpb.RegisterDKVReplicationServer(grpcSrv, masterSvc)
+	})
+	defer stopMaster()
+
+	slaveStore := memory.OpenDB(0)
+	slaveSvc, err := slave.NewService(slaveStore, slaveStore, []*ctl.DKVClient{masterCli}, time.Second, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slaveSvc.Close()
+	slaveCli, stopSlave := serve(t, func(grpcSrv *grpc.Server) {
+		serverpb.RegisterDKVServer(grpcSrv, slaveSvc)
+		serverpb.RegisterDKVReplicationStatusServer(grpcSrv, slaveSvc)
+	})
+	defer stopSlave()
+
+	lb := &LagBenchmark{
+		NumWrites:      500,
+		Concurrency:    4,
+		Keys:           UniformKeys(workloadKeySpace),
+		ValueSizes:     FixedValueSize(valueSize),
+		PollInterval:   10 * time.Millisecond,
+		CatchUpTimeout: 10 * time.Second,
+	}
+	report := lb.Run(context.Background(), masterCli, slaveCli)
+	if report.Writes.Stats[WriteOperation].Count != 500 || report.Writes.Stats[WriteOperation].Errors != 0 {
+		t.Errorf("Expected 500 writes without errors. Actual: %+v", report.Writes.Stats[WriteOperation])
+	}
+	if !report.CaughtUp || len(report.Samples) == 0 || report.PollErrors != 0 {
+		t.Fatalf("Expected the slave to catch up. Report: %s", report)
+	}
+	last := report.Samples[len(report.Samples)-1]
+	if last.Lag() != 0 || last.MasterChangeNum < 500 {
+		t.Errorf("Expected the last sample to be caught up with every write. Actual: %+v", last)
+	}
+	for i := 1; i < len(report.Samples); i++ {
+		if report.Samples[i].Time.Before(report.Samples[i-1].Time) {
+			t.Errorf("Expected the samples in the order of their times")
+		}
+	}
+	t.Log(report)
+}
+
+func TestLagReportCSV(t *testing.T) {
+	now := time.Unix(1600000000, 5e6)
+	report := &LagReport{Samples: []LagSample{
+		{Time: now, MasterChangeNum: 10, SlaveChangeNum: 4},
+		{Time: now.Add(time.Second), MasterChangeNum: 12, SlaveChangeNum: 12},
+	}}
+	var b bytes.Buffer
+	if err := report.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	expCSV := strings.Join([]string{
+		"timestampMillis,masterChangeNumber,slaveChangeNumber,lag",
+		"1600000000005,10,4,6",
+		"1600000001005,12,12,0",
+		"",
+	}, "\n")
+	if b.String() != expCSV {
+		t.Errorf("CSV mismatch. Expected: %q, Actual: %q", expCSV, b.String())
+	}
+}
@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+)
+
+var (
+	loadBatchSize  uint
+	numLoadSamples uint
+)
+
+func init() {
+	flag.UintVar(&loadBatchSize, "loadBatchSize", 100, "Number of keys written by every MultiPut request while pre-loading the key space")
+	flag.UintVar(&numLoadSamples, "numLoadSamples", 10, "Number of keys read to verify that the key space is loaded, when its pre-load is skipped")
+}
+
+// keySpaceKey is the key of the given index of the key space, which is
+// the key written by the PUT benchmark and read by the GET benchmarks.
+func keySpaceKey(idx uint) []byte {
+	return []byte(fmt.Sprintf("%s%d", ExistingKeyPrefix, idx))
+}
+
+// LoadKeySpace writes every key of the key space of the given size
+// with values of the given sizes, in MultiPut requests of the given
+// number of keys made from the given number of concurrent goroutines.
+// Since the keys written are the same on every load, a failed load can
+// be resumed by loading again.
+func LoadKeySpace(ctx context.Context, client *ctl.DKVClient, keySpaceSize uint, valueSizes ValueSizes, batchSize, concurrency uint) error {
+	if batchSize == 0 {
+		batchSize = 1
+	}
+	if concurrency == 0 {
+		concurrency = 1
+	}
+	numBatches := (keySpaceSize + batchSize - 1) / batchSize
+	var next uint64
+	errs := make(chan error, concurrency)
+	var wg sync.WaitGroup
+	for g := 0; g < int(concurrency); g++ {
+		wg.Add(1)
+		// Every goroutine draws from its own source, which is not safe
+		// for concurrent use
+		go func(rnd *rand.Rand) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				batch := uint(atomic.AddUint64(&next, 1) - 1)
+				if batch >= numBatches {
+					return
+				}
+				var pairs []ctl.KVPair
+				for idx := batch * batchSize; idx < (batch+1)*batchSize && idx < keySpaceSize; idx++ {
+					pairs = append(pairs, ctl.KVPair{Key: keySpaceKey(idx), Value: randomBytes(rnd, valueSizes.Sample(rnd))})
+				}
+				if err := client.MultiPut(pairs...); err != nil {
+					errs <- fmt.Errorf("unable to load keys from %s: %w", pairs[0].Key, err)
+					// Other goroutines stop at their next batch
+					atomic.StoreUint64(&next, uint64(numBatches))
+					return
+				}
+			}
+		}(rand.New(rand.NewSource(int64(g))))
+	}
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// KeySpaceLoaded reads the first and the last keys of the key space of
+// the given size, along with others sampled from it, upto the given
+// number of keys. It reports whether all of them are found, which is
+// taken to mean that the key space was loaded before.
+func KeySpaceLoaded(client *ctl.DKVClient, keySpaceSize, numSamples uint) (bool, error) {
+	if keySpaceSize == 0 {
+		return true, nil
+	}
+	keys := [][]byte{keySpaceKey(0), keySpaceKey(keySpaceSize - 1)}
+	for i := uint(2); i < numSamples; i++ {
+		keys = append(keys, keySpaceKey(uint(rand.Int63n(int64(keySpaceSize)))))
+	}
+	values, err := client.MultiGet(keys...)
+	if err != nil {
+		return false, err
+	}
+	for _, value := range values {
+		if value == nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// DefaultLoadKeySpace pre-loads the default key space with the default
+// value sizes, unless skipped when the key space is found loaded.
+func DefaultLoadKeySpace(ctx context.Context, client *ctl.DKVClient, concurrency uint, skip bool) error {
+	if skip {
+		loaded, err := KeySpaceLoaded(client, keySpaceSize, numLoadSamples)
+		if err != nil {
+			return err
+		}
+		if loaded {
+			return nil
+		}
+		return fmt.Errorf("key space of %d keys is not loaded, hence its pre-load must not be skipped", keySpaceSize)
+	}
+	return LoadKeySpace(ctx, client, keySpaceSize, defaultValueSizes(), loadBatchSize, concurrency)
+}
@@ -0,0 +1,39 @@
+package bench
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLoadKeySpace(t *testing.T) {
+	client, stop := serveDKV(t)
+	defer stop()
+
+	const numKeys = 95
+	if loaded, err := KeySpaceLoaded(client, numKeys, 5); err != nil || loaded {
+		t.Fatalf("Expected the key space to not be loaded. Loaded: %t, Error: %v", loaded, err)
+	}
+	if err := LoadKeySpace(context.Background(), client, numKeys, FixedValueSize(valueSize), 10, 3); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := KeySpaceLoaded(client, numKeys, 5); err != nil || !loaded {
+		t.Fatalf("Expected the key space to be loaded. Loaded: %t, Error: %v", loaded, err)
+	}
+	// Keys read by the GET benchmarks within the key space are all found
+	keys := CreateGetHotKeysBenchmark(numKeys).CreateRequests(numKeys).([][]byte)
+	values, err := client.MultiGet(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, value := range values {
+		if len(value) != valueSize {
+			t.Errorf("Expected a value of size %d for key: %s. Actual: %d", valueSize, keys[i], len(value))
+		}
+	}
+	// Loading is not resumed once cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := LoadKeySpace(ctx, client, numKeys, FixedValueSize(valueSize), 10, 3); err != context.Canceled {
+		t.Errorf("Expected the load to be cancelled. Actual error: %v", err)
+	}
+}
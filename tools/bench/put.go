@@ -2,6 +2,7 @@ package bench
 
 import (
 	"fmt"
+	"math/rand"
 
 	"github.com/flipkart-incubator/dkv/pkg/serverpb"
 )
@@ -28,8 +29,9 @@ func (putBm *putNewKeysBenchmark) APIName() string {
 
 func (putBm *putNewKeysBenchmark) CreateRequests(numRequests uint) interface{} {
 	var putReqs []*serverpb.PutRequest
+	rnd := rand.New(rand.NewSource(1))
 	for i := 0; i < int(numRequests); i++ {
-		key, value := []byte(fmt.Sprintf("%s%d", NewKeyPrefix, i)), randomBytes(putBm.numBytesInValue)
+		key, value := []byte(fmt.Sprintf("%s%d", NewKeyPrefix, i)), randomBytes(rnd, putBm.numBytesInValue)
 		putReqs = append(putReqs, &serverpb.PutRequest{Key: key, Value: value})
 	}
 	return putReqs
@@ -39,37 +41,80 @@ func (putBm *putNewKeysBenchmark) String() string {
 	return fmt.Sprintf("API: %s, Value Size: %d bytes", putBm.APIName(), putBm.numBytesInValue)
 }
 
-type putModifyKeysBenchmark struct {
-	numBytesInValue, numHotKeys uint
+type putExistingKeysBenchmark struct {
+	numKeys    uint
+	valueSizes ValueSizes
+	// Whether the keys are hot keys rather than a key space
+	hotKeys bool
 }
 
 // DefaultPutModifyKeysBenchmark returns an instance of a benchmark
 // that repeatedly calls the PUT API with the default value size and
 // number of hot keys.
 func DefaultPutModifyKeysBenchmark() Benchmark {
-	return CreatePutModifyKeysBenchmark(valueSizeInBytes, numHotKeys)
+	return CreatePutHotKeysBenchmarkWithValueSizes(numHotKeys, defaultValueSizes())
 }
 
 // CreatePutModifyKeysBenchmark returns an instance of a benchmark
 // that repeatedly calls the PUT API with the given value size and
 // number of hot keys.
 func CreatePutModifyKeysBenchmark(numBytesInValue, numHotKeys uint) Benchmark {
-	return &putModifyKeysBenchmark{numBytesInValue, numHotKeys}
+	return CreatePutHotKeysBenchmark(numHotKeys, numBytesInValue)
 }
 
-func (putBm *putModifyKeysBenchmark) APIName() string {
+// CreatePutHotKeysBenchmark returns an instance of a benchmark that
+// repeatedly calls the PUT API on the given number of hot keys, with
+// values of the given size. These are the hot keys read by the GET
+// benchmarks with as many hot keys.
+func CreatePutHotKeysBenchmark(hotKeyCnt, valueSize uint) Benchmark {
+	return CreatePutHotKeysBenchmarkWithValueSizes(hotKeyCnt, FixedValueSize(valueSize))
+}
+
+// CreatePutHotKeysBenchmarkWithValueSizes is same as CreatePutHotKeysBenchmark
+// except that the sizes of the values are sampled from the given sizes.
+func CreatePutHotKeysBenchmarkWithValueSizes(hotKeyCnt uint, valueSizes ValueSizes) Benchmark {
+	return &putExistingKeysBenchmark{hotKeyCnt, valueSizes, true}
+}
+
+// DefaultPutBenchmark returns an instance of a benchmark that calls
+// the PUT API on the keys of the default key space in turn, with the
+// default value sizes.
+func DefaultPutBenchmark() Benchmark {
+	return CreatePutBenchmarkWithValueSizes(keySpaceSize, defaultValueSizes())
+}
+
+// CreatePutBenchmark returns an instance of a benchmark that calls the
+// PUT API on the keys of a key space of the given size in turn, with
+// values of the given size. Once as many requests as the size of the
+// key space are made, the GET benchmarks with as many hot keys, or
+// fewer, read only the written keys.
+func CreatePutBenchmark(keySpaceSize, valueSize uint) Benchmark {
+	return CreatePutBenchmarkWithValueSizes(keySpaceSize, FixedValueSize(valueSize))
+}
+
+// CreatePutBenchmarkWithValueSizes is same as CreatePutBenchmark except
+// that the sizes of the values are sampled from the given sizes.
+func CreatePutBenchmarkWithValueSizes(keySpaceSize uint, valueSizes ValueSizes) Benchmark {
+	return &putExistingKeysBenchmark{keySpaceSize, valueSizes, false}
+}
+
+func (putBm *putExistingKeysBenchmark) APIName() string {
 	return "dkv.serverpb.DKV.Put"
 }
 
-func (putBm *putModifyKeysBenchmark) CreateRequests(numRequests uint) interface{} {
+func (putBm *putExistingKeysBenchmark) CreateRequests(numRequests uint) interface{} {
 	var putReqs []*serverpb.PutRequest
-	for i, j := 0, 0; i < int(numRequests); i, j = i+1, (j+1)%int(putBm.numHotKeys) {
-		key, value := []byte(fmt.Sprintf("%s%d", ExistingKeyPrefix, j)), randomBytes(putBm.numBytesInValue)
+	rnd := rand.New(rand.NewSource(1))
+	for i, j := 0, 0; i < int(numRequests); i, j = i+1, (j+1)%int(putBm.numKeys) {
+		key, value := []byte(fmt.Sprintf("%s%d", ExistingKeyPrefix, j)), randomBytes(rnd, putBm.valueSizes.Sample(rnd))
 		putReqs = append(putReqs, &serverpb.PutRequest{Key: key, Value: value})
 	}
 	return putReqs
 }
 
-func (putBm *putModifyKeysBenchmark) String() string {
-	return fmt.Sprintf("API: %s, Value Size: %d bytes, Hot Keys: %d", putBm.APIName(), putBm.numBytesInValue, putBm.numHotKeys)
+func (putBm *putExistingKeysBenchmark) String() string {
+	if putBm.hotKeys {
+		return fmt.Sprintf("API: %s, Value Size: %s, Hot Keys: %d", putBm.APIName(), putBm.valueSizes, putBm.numKeys)
+	}
+	return fmt.Sprintf("API: %s, Value Size: %s, Key Space: %d", putBm.APIName(), putBm.valueSizes, putBm.numKeys)
 }
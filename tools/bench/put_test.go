@@ -0,0 +1,78 @@
+package bench
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+const (
+	keySpaceCnt = 23
+	valueSize   = 16
+)
+
+func checkPutKeys(t *testing.T, putReqs []*serverpb.PutRequest, numKeys int) {
+	for i, putReq := range putReqs {
+		expKey := fmt.Sprintf("%s%d", ExistingKeyPrefix, i%numKeys)
+		if actKey := string(putReq.Key); expKey != actKey {
+			t.Errorf("Key mismatch. Expected key: %s, Actual key: %s", expKey, actKey)
+		}
+	}
+}
+
+func checkValueSizes(t *testing.T, putReqs []*serverpb.PutRequest, expSizes ...int) {
+	for _, putReq := range putReqs {
+		found := false
+		for _, expSize := range expSizes {
+			found = found || len(putReq.Value) == expSize
+		}
+		if !found {
+			t.Errorf("Value size mismatch for key: %s. Expected sizes: %v, Actual size: %d", putReq.Key, expSizes, len(putReq.Value))
+		}
+	}
+}
+
+func TestPutBenchmark(t *testing.T) {
+	bm := CreatePutBenchmark(keySpaceCnt, valueSize)
+	putReqs := bm.CreateRequests(reqCnt).([]*serverpb.PutRequest)
+	if numPutReqs := len(putReqs); numPutReqs != reqCnt {
+		t.Errorf("Expected number of put requests: %d. Actual: %d", reqCnt, numPutReqs)
+	}
+	checkPutKeys(t, putReqs, keySpaceCnt)
+	checkValueSizes(t, putReqs, valueSize)
+}
+
+func TestPutHotKeysBenchmark(t *testing.T) {
+	bm := CreatePutHotKeysBenchmark(hotKeyCnt, valueSize)
+	putReqs := bm.CreateRequests(reqCnt).([]*serverpb.PutRequest)
+	if numPutReqs := len(putReqs); numPutReqs != reqCnt {
+		t.Errorf("Expected number of put requests: %d. Actual: %d", reqCnt, numPutReqs)
+	}
+	// Written keys are those read by the GET benchmark
+	getReqs := make([][]byte, len(putReqs))
+	for i, putReq := range putReqs {
+		getReqs[i] = putReq.Key
+	}
+	checkKeys(t, getReqs)
+	checkValueSizes(t, putReqs, valueSize)
+}
+
+func TestPutBenchmarkWithValueSizes(t *testing.T) {
+	valueSizes, err := ParseValueSizes("8:3, 64:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bm := CreatePutHotKeysBenchmarkWithValueSizes(hotKeyCnt, valueSizes)
+	putReqs := bm.CreateRequests(reqCnt).([]*serverpb.PutRequest)
+	checkValueSizes(t, putReqs, 8, 64)
+	if expStr := "API: dkv.serverpb.DKV.Put, Value Size: 8:3,64:1 (bytes:weight), Hot Keys: 9"; bm.String() != expStr {
+		t.Errorf("Benchmark mismatch. Expected: %s, Actual: %s", expStr, bm)
+	}
+
+	for _, dist := range []string{"", "8", "8:0", "x:1", "8:y", "8:1:2"} {
+		if _, err := ParseValueSizes(dist); err == nil {
+			t.Errorf("Expected an error for value sizes: %q", dist)
+		}
+	}
+}
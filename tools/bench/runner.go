@@ -0,0 +1,201 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+)
+
+// OperationStats are the outcomes of the requests of a workload of
+// a given operation.
+type OperationStats struct {
+	Count  uint64
+	Errors uint64
+	// Misses are the reads of keys that are not found, which are not
+	// counted as errors
+	Misses uint64
+	// Latencies holds the latency of every request
+	Latencies   *LatencyHistogram
+	P50Latency  time.Duration
+	P90Latency  time.Duration
+	P99Latency  time.Duration
+	P999Latency time.Duration
+	MaxLatency  time.Duration
+}
+
+// WorkloadReport describes a run of a workload, with the outcomes of
+// its requests by their operations.
+type WorkloadReport struct {
+	Duration time.Duration
+	Stats    map[Operation]*OperationStats
+}
+
+// QPS is the rate of the requests completed by the run.
+func (wr *WorkloadReport) QPS() float64 {
+	var count uint64
+	for _, stats := range wr.Stats {
+		count += stats.Count
+	}
+	if wr.Duration <= 0 {
+		return 0
+	}
+	return float64(count) / wr.Duration.Seconds()
+}
+
+func (wr *WorkloadReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Duration: %v, QPS: %.2f\n", wr.Duration, wr.QPS())
+	for _, op := range []Operation{ReadOperation, WriteOperation} {
+		if stats, present := wr.Stats[op]; present {
+			fmt.Fprintf(&b, "%s - Count: %d, Errors: %d, Misses: %d, Latency p50: %v, p99: %v, max: %v\n",
+				op, stats.Count, stats.Errors, stats.Misses, stats.P50Latency, stats.P99Latency, stats.MaxLatency)
+		}
+	}
+	return b.String()
+}
+
+// WriteHistograms dumps the latency histograms of the run to the given
+// file, as JSON if its name ends in .json and as CSV otherwise. Every
+// non empty bucket is written with the percentile of the latencies up
+// to its upper bound, so that runs can be compared later.
+func (wr *WorkloadReport) WriteHistograms(file string) error {
+	type histogram struct {
+		Count   uint64            `json:"count"`
+		Max     time.Duration     `json:"maxNanos"`
+		Buckets []HistogramBucket `json:"buckets"`
+	}
+	var b bytes.Buffer
+	if strings.HasSuffix(strings.ToLower(file), ".json") {
+		hists := make(map[string]*histogram, len(wr.Stats))
+		for op, stats := range wr.Stats {
+			hists[op.String()] = &histogram{stats.Latencies.TotalCount(), stats.Latencies.Max(), stats.Latencies.Buckets()}
+		}
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(hists); err != nil {
+			return err
+		}
+	} else {
+		w := csv.NewWriter(&b)
+		w.Write([]string{"operation", "upperBoundNanos", "count", "percentile"})
+		for _, op := range []Operation{ReadOperation, WriteOperation} {
+			stats, present := wr.Stats[op]
+			if !present {
+				continue
+			}
+			var count uint64
+			for _, bucket := range stats.Latencies.Buckets() {
+				count += bucket.Count
+				percentile := float64(count) * 100 / float64(stats.Latencies.TotalCount())
+				w.Write([]string{op.String(), strconv.FormatInt(int64(bucket.UpperBound), 10),
+					strconv.FormatUint(bucket.Count, 10), strconv.FormatFloat(percentile, 'f', 3, 64)})
+			}
+		}
+		if w.Flush(); w.Error() != nil {
+			return w.Error()
+		}
+	}
+	return ioutil.WriteFile(file, b.Bytes(), 0644)
+}
+
+// RunWorkload makes the given requests in their order using the given
+// DKVClient, from the given number of concurrent goroutines, and at
+// the given rate unless zero. Requests made during the given warm-up
+// duration from the start are excluded from the report. Requests are
+// no longer made once the given context is done, and those in flight
+// are left to complete.
+func RunWorkload(ctx context.Context, client *ctl.DKVClient, reqs []*WorkloadRequest, concurrency, targetQPS uint, warmUp time.Duration) *WorkloadReport {
+	if concurrency == 0 {
+		concurrency = 1
+	}
+	var next uint64
+	var mu sync.Mutex
+	report := &WorkloadReport{Stats: make(map[Operation]*OperationStats)}
+	start := time.Now()
+	measureStart := start.Add(warmUp)
+	var wg sync.WaitGroup
+	for g := 0; g < int(concurrency); g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := atomic.AddUint64(&next, 1) - 1
+				if i >= uint64(len(reqs)) {
+					return
+				}
+				// Every request is scheduled as per the target QPS
+				if targetQPS > 0 {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(time.Until(start.Add(time.Duration(i) * time.Second / time.Duration(targetQPS)))):
+					}
+				}
+				req := reqs[i]
+				reqStart := time.Now()
+				err := makeRequest(client, req)
+				latency := time.Since(reqStart)
+				if reqStart.Before(measureStart) {
+					continue
+				}
+
+				mu.Lock()
+				stats, present := report.Stats[req.Op]
+				if !present {
+					stats = &OperationStats{Latencies: NewLatencyHistogram()}
+					report.Stats[req.Op] = stats
+				}
+				stats.Count++
+				switch {
+				case errors.Is(err, ctl.ErrKeyNotFound):
+					stats.Misses++
+				case err != nil:
+					stats.Errors++
+				}
+				stats.Latencies.Record(latency)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if report.Duration = time.Since(measureStart); report.Duration < 0 {
+		report.Duration = 0
+	}
+	for _, stats := range report.Stats {
+		stats.summarize()
+	}
+	return report
+}
+
+// makeRequest makes the given request within the timeout of the
+// given DKVClient.
+func makeRequest(client *ctl.DKVClient, req *WorkloadRequest) error {
+	switch req.Op {
+	case ReadOperation:
+		_, err := client.Get(req.Key)
+		return err
+	case WriteOperation:
+		return client.Put(req.Key, req.Value)
+	default:
+		return fmt.Errorf("unknown operation: %v", req.Op)
+	}
+}
+
+func (stats *OperationStats) summarize() {
+	stats.P50Latency = stats.Latencies.ValueAtPercentile(50)
+	stats.P90Latency = stats.Latencies.ValueAtPercentile(90)
+	stats.P99Latency = stats.Latencies.ValueAtPercentile(99)
+	stats.P999Latency = stats.Latencies.ValueAtPercentile(99.9)
+	stats.MaxLatency = stats.Latencies.Max()
+}
@@ -0,0 +1,193 @@
+package bench
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Operation is the type of every request of a workload.
+type Operation int
+
+const (
+	// ReadOperation GETs the key of the request
+	ReadOperation Operation = iota
+	// WriteOperation PUTs the value of the request onto its key
+	WriteOperation
+)
+
+func (op Operation) String() string {
+	switch op {
+	case ReadOperation:
+		return "Read"
+	case WriteOperation:
+		return "Write"
+	default:
+		return fmt.Sprintf("Operation(%d)", int(op))
+	}
+}
+
+// WorkloadRequest is a request of a workload, whose value is set only
+// for writes.
+type WorkloadRequest struct {
+	Op    Operation
+	Key   []byte
+	Value []byte
+}
+
+// KeyDistribution samples the keys of the requests of a workload, by
+// their indices in a key space.
+type KeyDistribution interface {
+	// Sampler returns the function sampling the key indices from the
+	// given source, which is built once per worker generating requests
+	// and hence not shared across goroutines.
+	Sampler(rnd *rand.Rand) func() uint
+	String() string
+}
+
+type uniformKeys struct {
+	keySpaceSize uint
+}
+
+// UniformKeys samples every key of the key space of the given size
+// equally often.
+func UniformKeys(keySpaceSize uint) KeyDistribution {
+	if keySpaceSize == 0 {
+		panic("Key space must not be empty")
+	}
+	return &uniformKeys{keySpaceSize}
+}
+
+func (uk *uniformKeys) Sampler(rnd *rand.Rand) func() uint {
+	return func() uint {
+		return uint(rnd.Int63n(int64(uk.keySpaceSize)))
+	}
+}
+
+func (uk *uniformKeys) String() string {
+	return fmt.Sprintf("uniform over %d keys", uk.keySpaceSize)
+}
+
+type zipfianKeys struct {
+	keySpaceSize uint
+	s            float64
+}
+
+// ZipfianKeys samples the keys of the key space of the given size as
+// per the zipfian distribution with the given exponent, which must be
+// greater than 1, such that the keys of lower indices are more popular.
+func ZipfianKeys(keySpaceSize uint, s float64) KeyDistribution {
+	if keySpaceSize == 0 || s <= 1 {
+		panic(fmt.Sprintf("Key space must not be empty and the zipfian exponent must be greater than 1. Given keySpaceSize: %d, exponent: %f", keySpaceSize, s))
+	}
+	return &zipfianKeys{keySpaceSize, s}
+}
+
+func (zk *zipfianKeys) Sampler(rnd *rand.Rand) func() uint {
+	// Zipf is bound to its source, which is that of the worker
+	zipf := rand.NewZipf(rnd, zk.s, 1, uint64(zk.keySpaceSize-1))
+	return func() uint {
+		return uint(zipf.Uint64())
+	}
+}
+
+func (zk *zipfianKeys) String() string {
+	return fmt.Sprintf("zipfian over %d keys with exponent %.2f", zk.keySpaceSize, zk.s)
+}
+
+type hotSetKeys struct {
+	keySpaceSize, hotSetSize uint
+	hotFraction              float64
+}
+
+// HotSetKeys samples the given number of hot keys, which are the first
+// keys of the key space of the given size, for the given fraction of
+// the requests and the remaining keys for the other requests. Keys are
+// sampled uniformly within either set.
+func HotSetKeys(keySpaceSize, hotSetSize uint, hotFraction float64) KeyDistribution {
+	if hotSetSize == 0 || hotSetSize > keySpaceSize || hotFraction < 0 || hotFraction > 1 {
+		panic(fmt.Sprintf("Hot set must be a non empty part of the key space, and hot requests must be a fraction. Given keySpaceSize: %d, hotSetSize: %d, hotFraction: %f", keySpaceSize, hotSetSize, hotFraction))
+	}
+	return &hotSetKeys{keySpaceSize, hotSetSize, hotFraction}
+}
+
+func (hsk *hotSetKeys) Sampler(rnd *rand.Rand) func() uint {
+	return func() uint {
+		if hsk.hotSetSize == hsk.keySpaceSize || rnd.Float64() < hsk.hotFraction {
+			return uint(rnd.Int63n(int64(hsk.hotSetSize)))
+		}
+		return hsk.hotSetSize + uint(rnd.Int63n(int64(hsk.keySpaceSize-hsk.hotSetSize)))
+	}
+}
+
+func (hsk *hotSetKeys) String() string {
+	return fmt.Sprintf("%.0f%% of requests over %d hot keys of %d keys", hsk.hotFraction*100, hsk.hotSetSize, hsk.keySpaceSize)
+}
+
+var (
+	readFraction    float64
+	keyDistribution string
+	zipfianExponent float64
+	hotKeysFraction float64
+)
+
+func init() {
+	flag.Float64Var(&readFraction, "readFraction", 0.9, "Fraction of the requests of a workload that are reads, the rest being writes")
+	flag.StringVar(&keyDistribution, "keyDistribution", "zipfian", "Distribution of the keys of a workload over the key space [uniform|zipfian|hotset]")
+	flag.Float64Var(&zipfianExponent, "zipfianExponent", 1.1, "Exponent of the zipfian distribution of keys, which must be greater than 1")
+	flag.Float64Var(&hotKeysFraction, "hotKeysFraction", 0.8, "Fraction of the requests of a workload on the numHotKeys hot keys of the hotset distribution")
+}
+
+// Workload represents a mix of reads and writes on the keys of a key
+// space, taking after YCSB. Keys carry the ExistingKeyPrefix, hence the
+// key space can be loaded beforehand by the PUT benchmark.
+type Workload struct {
+	// ReadFraction is the fraction of requests that are reads
+	ReadFraction float64
+	Keys         KeyDistribution
+	// ValueSizes samples the sizes of the values written
+	ValueSizes ValueSizes
+	// Seed makes the requests of workloads reproducible
+	Seed int64
+}
+
+// DefaultWorkload returns the workload given by the flags.
+func DefaultWorkload() *Workload {
+	var keys KeyDistribution
+	switch strings.ToLower(strings.TrimSpace(keyDistribution)) {
+	case "uniform":
+		keys = UniformKeys(keySpaceSize)
+	case "zipfian":
+		keys = ZipfianKeys(keySpaceSize, zipfianExponent)
+	case "hotset":
+		keys = HotSetKeys(keySpaceSize, numHotKeys, hotKeysFraction)
+	default:
+		panic(fmt.Sprintf("Unknown key distribution given: '%s'", keyDistribution))
+	}
+	return &Workload{ReadFraction: readFraction, Keys: keys, ValueSizes: defaultValueSizes(), Seed: 1}
+}
+
+// CreateRequests returns the given number of requests of the workload,
+// with the reads interleaved with the writes.
+func (wl *Workload) CreateRequests(numRequests uint) []*WorkloadRequest {
+	rnd := rand.New(rand.NewSource(wl.Seed))
+	sampleKey := wl.Keys.Sampler(rnd)
+	reqs := make([]*WorkloadRequest, numRequests)
+	for i := range reqs {
+		req := &WorkloadRequest{Op: ReadOperation}
+		if rnd.Float64() >= wl.ReadFraction {
+			req.Op = WriteOperation
+		}
+		req.Key = []byte(fmt.Sprintf("%s%d", ExistingKeyPrefix, sampleKey()))
+		if req.Op == WriteOperation {
+			req.Value = randomBytes(rnd, wl.ValueSizes.Sample(rnd))
+		}
+		reqs[i] = req
+	}
+	return reqs
+}
+
+func (wl *Workload) String() string {
+	return fmt.Sprintf("Reads: %.0f%%, Writes: %.0f%%, Keys: %s, Value Size: %s", wl.ReadFraction*100, (1-wl.ReadFraction)*100, wl.Keys, wl.ValueSizes)
+}
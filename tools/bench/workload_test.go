@@ -0,0 +1,171 @@
+package bench
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/ctl"
+	"github.com/flipkart-incubator/dkv/internal/server/master"
+	"github.com/flipkart-incubator/dkv/internal/server/storage/memory"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+	"google.golang.org/grpc"
+)
+
+const (
+	workloadReqCnt   = 10000
+	workloadKeySpace = 100
+)
+
+func keyIndex(t *testing.T, key []byte) int {
+	idx, err := strconv.Atoi(strings.TrimPrefix(string(key), ExistingKeyPrefix))
+	if err != nil || !strings.HasPrefix(string(key), ExistingKeyPrefix) || idx < 0 || idx >= workloadKeySpace {
+		t.Fatalf("Key outside the key space: %s", key)
+	}
+	return idx
+}
+
+func TestWorkload(t *testing.T) {
+	wl := &Workload{ReadFraction: 0.9, Keys: UniformKeys(workloadKeySpace), ValueSizes: FixedValueSize(valueSize), Seed: 7}
+	reqs := wl.CreateRequests(workloadReqCnt)
+	if len(reqs) != workloadReqCnt {
+		t.Fatalf("Expected number of workload requests: %d. Actual: %d", workloadReqCnt, len(reqs))
+	}
+	numReads := 0
+	for _, req := range reqs {
+		keyIndex(t, req.Key)
+		switch req.Op {
+		case ReadOperation:
+			numReads++
+			if req.Value != nil {
+				t.Errorf("Expected no value for the read of key: %s", req.Key)
+			}
+		case WriteOperation:
+			if len(req.Value) != valueSize {
+				t.Errorf("Value size mismatch for key: %s. Expected: %d, Actual: %d", req.Key, valueSize, len(req.Value))
+			}
+		}
+	}
+	if readFrac := float64(numReads) / workloadReqCnt; math.Abs(readFrac-0.9) > 0.02 {
+		t.Errorf("Expected a read fraction of about 0.9. Actual: %f", readFrac)
+	}
+	sameReqs := wl.CreateRequests(workloadReqCnt)
+	for i := range reqs {
+		if reqs[i].Op != sameReqs[i].Op || string(reqs[i].Key) != string(sameReqs[i].Key) || string(reqs[i].Value) != string(sameReqs[i].Value) {
+			t.Fatalf("Expected the requests of the same seed to be reproduced. Mismatch at: %d", i)
+		}
+	}
+}
+
+func TestKeyDistributions(t *testing.T) {
+	checks := []struct {
+		keys  KeyDistribution
+		check func(counts []int) bool
+	}{
+		// Every key is sampled
+		{UniformKeys(workloadKeySpace), func(counts []int) bool {
+			for _, count := range counts {
+				if count == 0 {
+					return false
+				}
+			}
+			return true
+		}},
+		// Popularity falls with the index of the key
+		{ZipfianKeys(workloadKeySpace, 1.1), func(counts []int) bool {
+			return counts[0] > counts[1] && counts[1] > counts[10] && counts[10] > counts[workloadKeySpace-1]
+		}},
+		// Most requests are on the hot keys
+		{HotSetKeys(workloadKeySpace, 10, 0.8), func(counts []int) bool {
+			hot := 0
+			for _, count := range counts[:10] {
+				hot += count
+			}
+			return math.Abs(float64(hot)/workloadReqCnt-0.8) < 0.02 && counts[workloadKeySpace-1] > 0
+		}},
+	}
+	for _, check := range checks {
+		wl := &Workload{ReadFraction: 1, Keys: check.keys, ValueSizes: FixedValueSize(valueSize), Seed: 7}
+		counts := make([]int, workloadKeySpace)
+		for _, req := range wl.CreateRequests(workloadReqCnt) {
+			counts[keyIndex(t, req.Key)]++
+		}
+		if !check.check(counts) {
+			t.Errorf("Unexpected key counts for distribution: %s. Counts: %v", check.keys, counts)
+		}
+	}
+}
+
+// serveDKV serves an in memory standalone DKV service, returning a
+// client of it along with the function that stops it.
+func serveDKV(t *testing.T) (*ctl.DKVClient, func()) {
+	store := memory.OpenDB(0)
+	dkvSvc := master.NewStandaloneService(store, store, store)
+	client, stop := serve(t, func(grpcSrv *grpc.Server) {
+		serverpb.RegisterDKVServer(grpcSrv, dkvSvc)
+	})
+	return client, func() {
+		stop()
+		dkvSvc.Close()
+	}
+}
+
+func TestRunWorkload(t *testing.T) {
+	client, stop := serveDKV(t)
+	defer stop()
+
+	// Half the key space is loaded beforehand
+	for _, putReq := range CreatePutBenchmark(workloadKeySpace/2, valueSize).CreateRequests(workloadKeySpace / 2).([]*serverpb.PutRequest) {
+		if err := client.Put(putReq.Key, putReq.Value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wl := &Workload{ReadFraction: 0.5, Keys: UniformKeys(workloadKeySpace), ValueSizes: FixedValueSize(valueSize), Seed: 7}
+	reqs := wl.CreateRequests(200)
+	expCounts := make(map[Operation]uint64)
+	for _, req := range reqs {
+		expCounts[req.Op]++
+	}
+	report := RunWorkload(context.Background(), client, reqs, 4, 2000, 0)
+	actCounts := make(map[Operation]uint64)
+	for op, stats := range report.Stats {
+		actCounts[op] = stats.Count
+		if stats.Errors != 0 || stats.MaxLatency < stats.P50Latency {
+			t.Errorf("Unexpected stats of %s: %+v", op, stats)
+		}
+	}
+	if !reflect.DeepEqual(expCounts, actCounts) {
+		t.Errorf("Operation counts mismatch. Expected: %v, Actual: %v", expCounts, actCounts)
+	}
+	if misses := report.Stats[ReadOperation].Misses; misses == 0 || misses == expCounts[ReadOperation] {
+		t.Errorf("Expected some of the reads to miss. Actual misses: %d", misses)
+	}
+	// Requests are paced as per the target QPS
+	if qps := report.QPS(); qps > 2200 {
+		t.Errorf("Expected at most about 2000 QPS. Actual: %.2f", qps)
+	}
+	t.Log(report)
+}
+
+func TestRunWorkloadWarmUp(t *testing.T) {
+	client, stop := serveDKV(t)
+	defer stop()
+
+	wl := &Workload{ReadFraction: 0.5, Keys: UniformKeys(workloadKeySpace), ValueSizes: FixedValueSize(valueSize), Seed: 7}
+	// Requests over 400ms, of which the first 200ms are for warming up
+	report := RunWorkload(context.Background(), client, wl.CreateRequests(400), 4, 1000, 200*time.Millisecond)
+	var count uint64
+	for _, stats := range report.Stats {
+		count += stats.Count
+	}
+	if count < 100 || count > 300 {
+		t.Errorf("Expected about half of the requests to be measured. Actual: %d", count)
+	}
+	if report.Duration > 400*time.Millisecond {
+		t.Errorf("Expected the warm-up to be excluded from the duration. Actual: %v", report.Duration)
+	}
+}
@@ -1,6 +1,28 @@
 package version
 
+import "runtime/debug"
+
 var (
 	// Version holds the latest version of the DKV binary
 	Version = "latest"
+	// Commit holds the commit of the sources of the DKV binary
+	Commit = ""
 )
+
+// ModuleVersion returns the version of the given module dependency the
+// running binary is built with, which is empty if it is not known.
+func ModuleVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}